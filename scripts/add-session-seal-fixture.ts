@@ -0,0 +1,33 @@
+/**
+ * Add a session cookie sealed by an SDK to src/lib/session-seal.fixtures.json,
+ * so the session-seal spec checks unsealing against the SDK's own output.
+ * Usage: pnpm tsx scripts/add-session-seal-fixture.ts <sdk> <version> < sealed.txt
+ *
+ * Seal the fixtures' `session` with their `password` and no expiry, e.g.
+ * `sealData(session, { password, ttl: 0 })` from the iron-session version
+ * @workos-inc/authkit-nextjs depends on, or the Go SDK's session sealing.
+ */
+import { readFileSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { isDeepStrictEqual } from 'node:util';
+import { unsealIron } from '../src/lib/session-seal.js';
+
+const [sdk, version] = process.argv.slice(2);
+if (!sdk || !version) {
+  console.error('Usage: pnpm tsx scripts/add-session-seal-fixture.ts <sdk> <version> < sealed.txt');
+  process.exit(1);
+}
+
+const fixturesPath = join(import.meta.dirname, '..', 'src', 'lib', 'session-seal.fixtures.json');
+const fixtures = JSON.parse(readFileSync(fixturesPath, 'utf-8'));
+const sealed = readFileSync(0, 'utf-8').trim();
+
+const result = unsealIron(sealed, fixtures.password);
+if (result.status !== 'valid' || !isDeepStrictEqual(result.data, fixtures.session)) {
+  console.error(`Not a seal of the fixtures' session: ${result.message}`);
+  process.exit(1);
+}
+
+fixtures.sdks = [...fixtures.sdks.filter((s: { sdk: string }) => s.sdk !== sdk), { sdk, version, sealed }];
+writeFileSync(fixturesPath, JSON.stringify(fixtures, null, 2) + '\n');
+console.log(`Added the ${sdk} ${version} seal to src/lib/session-seal.fixtures.json`);
@@ -0,0 +1,13 @@
+/**
+ * Regenerate skills/manifest.json after editing bundled skills.
+ * Usage: pnpm skills:manifest
+ */
+import { writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { buildManifest, MANIFEST_FILE } from '../src/lib/skill-integrity.js';
+
+const skillsDir = join(import.meta.dirname, '..', 'skills');
+const manifest = buildManifest(skillsDir);
+
+writeFileSync(join(skillsDir, MANIFEST_FILE), JSON.stringify(manifest, null, 2) + '\n');
+console.log(`Wrote ${Object.keys(manifest.skills).length} skill(s) to skills/${MANIFEST_FILE}`);
@@ -0,0 +1,27 @@
+/**
+ * Stamp dist/build-info.json with the commit and build date; the version
+ * itself comes from package.json. Runs after `pnpm build`.
+ *
+ * CI can pass WORKOS_CLI_COMMIT and SOURCE_DATE_EPOCH (seconds) for
+ * reproducible builds; otherwise git and the current time are used.
+ */
+import { execFileSync } from 'node:child_process';
+import { writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+
+function gitCommit(): string {
+  try {
+    return execFileSync('git', ['rev-parse', 'HEAD'], { encoding: 'utf-8' }).trim();
+  } catch {
+    return 'unknown';
+  }
+}
+
+const epoch = Number(process.env.SOURCE_DATE_EPOCH);
+const info = {
+  commit: process.env.WORKOS_CLI_COMMIT || gitCommit(),
+  buildDate: (Number.isFinite(epoch) && epoch > 0 ? new Date(epoch * 1000) : new Date()).toISOString(),
+};
+
+writeFileSync(join(import.meta.dirname, '..', 'dist', 'build-info.json'), JSON.stringify(info, null, 2) + '\n');
+console.log(`Wrote dist/build-info.json (${info.commit.slice(0, 12)}, ${info.buildDate})`);
@@ -4,12 +4,15 @@
 if (process.argv.includes('--local') || process.env.INSTALLER_DEV) {
   const { config } = await import('dotenv');
   // bin.ts compiles to dist/bin.js, so go up one level to find .env.local
-  config({ path: new URL('../.env.local', import.meta.url).pathname });
+  const { fileURLToPath } = await import('node:url');
+  // fileURLToPath, not URL.pathname, which is /C:/... on Windows
+  config({ path: fileURLToPath(new URL('../.env.local', import.meta.url)) });
 }
 
 import { satisfies } from 'semver';
 import { red } from './utils/logging.js';
-import { getConfig, getVersion } from './lib/settings.js';
+import { getConfig } from './lib/settings.js';
+import { formatVersion } from './lib/build-info.js';
 
 import yargs from 'yargs';
 import { hideBin } from 'yargs/helpers';
@@ -30,6 +33,13 @@ if (!satisfies(process.version, NODE_VERSION_RANGE)) {
 
 import { isNonInteractiveEnvironment } from './utils/environment.js';
 import clack from './utils/clack.js';
+import { supportsVirtualTerminal } from './utils/windows.js';
+import { parseDuration } from './lib/agent-process.js';
+import { parseFileSize } from './migrate/scan.js';
+import { parseTokenCount } from './lib/agent-estimate.js';
+
+// Consoles that print escape codes literally get plain text (prompts fall back on their own)
+if (!supportsVirtualTerminal()) chalk.level = 0;
 
 /** Apply insecure storage flag if set */
 async function applyInsecureStorage(insecureStorage?: boolean): Promise<void> {
@@ -41,6 +51,33 @@ async function applyInsecureStorage(insecureStorage?: boolean): Promise<void> {
   }
 }
 
+/** Dynamic completion values (IDs, environment and skill names); null to complete commands and options */
+async function completeDynamicValue(words: string[]): Promise<string[] | null> {
+  const { completeValue } = await import('./lib/completion.js');
+  const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+  if (words.includes('--no-cache')) {
+    const { setApiCacheEnabled } = await import('./lib/api-cache.js');
+    setApiCacheEnabled(false);
+  }
+  return completeValue(
+    words,
+    {
+      api: () => {
+        try {
+          return { apiKey: resolveApiKey(), baseUrl: resolveApiBaseUrl() };
+        } catch {
+          return null;
+        }
+      },
+      skills: async () => {
+        const { discoverSkills, getSkillsDir } = await import('./commands/install-skill.js');
+        return discoverSkills(getSkillsDir());
+      },
+    },
+    { zsh: Boolean(process.env.SHELL?.includes('zsh') || process.env.ZSH_NAME) },
+  );
+}
+
 /** Shared insecure-storage option for commands that access credentials */
 const insecureStorageOption = {
   'insecure-storage': {
@@ -50,6 +87,21 @@ const insecureStorageOption = {
   },
 } as const;
 
+/** Options shared by `users export` and `organization export` */
+const directoryExportOptions = {
+  format: { choices: ['jsonl', 'csv'] as const, default: 'jsonl' as const, describe: 'Output format' },
+  out: { type: 'string' as const, describe: 'Output file (default users.jsonl, organizations.csv, ...)' },
+  'updated-since': {
+    type: 'string' as const,
+    describe: 'Only records updated since this lookback (24h, 7d) or ISO date, for incremental exports',
+  },
+  resume: {
+    type: 'boolean' as const,
+    default: false,
+    describe: 'Carry on from where an interrupted export to the same file stopped',
+  },
+};
+
 /**
  * Wrap a command handler with authentication check.
  * Ensures valid auth before executing the handler.
@@ -64,6 +116,76 @@ function withAuth<T>(handler: (argv: T) => Promise<void>): (argv: T) => Promise<
   };
 }
 
+/** `--machine` switches to the protocol first, so nothing (auth included) prints or prompts the human way */
+function withMachineMode<T>(handler: (argv: T) => Promise<void>): (argv: T) => Promise<void> {
+  return async (argv: T) => {
+    if ((argv as { machine?: boolean }).machine) {
+      const { startMachineMode } = await import('./commands/install.js');
+      startMachineMode();
+    }
+    await handler(argv);
+  };
+}
+
+/** A duration flag (`15m`, `90s`, `1h`), parsed to milliseconds */
+function durationOption(flag: string, describe: string) {
+  return {
+    type: 'string' as const,
+    describe,
+    coerce: (value: string) => {
+      const ms = parseDuration(value);
+      if (ms === null || ms <= 0) throw new Error(`${flag} must be a duration like 15m, 90s or 1h`);
+      return ms;
+    },
+  };
+}
+
+/** Shared by `detect`, `migrate` and `verify` */
+const minConfidenceOption = {
+  type: 'number' as const,
+  describe: 'Ignore providers detected with confidence below this (0-1, default 0.35)',
+  coerce: (value: number) => {
+    if (Number.isNaN(value) || value < 0 || value > 1) {
+      throw new Error('--min-confidence must be a number between 0 and 1');
+    }
+    return value;
+  },
+};
+
+/** Shared by `detect`, `migrate` and `verify` */
+const discoveryCacheOption = {
+  type: 'boolean' as const,
+  default: true,
+  describe: 'Reuse cached OIDC issuer lookups (--no-cache fetches them again)',
+};
+
+/** Shared by `detect`, `migrate` and `verify`: includes pick what's walked, excludes then drop from it */
+const scanPathOptions = {
+  include: {
+    type: 'string' as const,
+    array: true,
+    describe: 'Only scan paths matching this glob (repeatable)',
+  },
+  exclude: {
+    type: 'string' as const,
+    array: true,
+    describe: 'Skip paths matching this glob, applied after --include (repeatable)',
+  },
+  'max-file-size': {
+    type: 'string' as const,
+    describe: 'Skip files larger than this, e.g. 500kb or 5mb (default 2mb)',
+    coerce: (value: string) => {
+      const bytes = parseFileSize(value);
+      if (bytes === null || bytes <= 0) throw new Error('--max-file-size must be a size like 500kb, 2mb or 1048576');
+      return bytes;
+    },
+  },
+  'scan-terraform': {
+    type: 'boolean' as const,
+    describe: 'Also read Auth0 clients, callbacks and client IDs from Terraform (.tf) files',
+  },
+};
+
 const installerOptions = {
   direct: {
     alias: 'D',
@@ -120,10 +242,57 @@ const installerOptions = {
     describe: 'Skip post-installation validation (includes build check)',
     type: 'boolean' as const,
   },
+  'max-repair-attempts': {
+    default: 1,
+    describe: 'Turns the agent gets to fix failed post-install verification checks (0 to only report)',
+    type: 'number' as const,
+  },
+  'skip-checks': {
+    default: false,
+    describe: "Don't run the project's formatters and linters on the agent's changes",
+    type: 'boolean' as const,
+  },
+  'no-format': {
+    default: false,
+    describe: "Don't run prettier, goimports or gofmt on the files the installer writes (linters still run)",
+    type: 'boolean' as const,
+  },
   'install-dir': {
     describe: 'Directory to install WorkOS AuthKit in',
     type: 'string' as const,
   },
+  'summary-format': {
+    choices: ['table', 'plain', 'markdown', 'json'] as const,
+    default: 'table' as const,
+    describe: 'How to print the end-of-run summary (markdown pastes into a PR description)',
+  },
+  json: {
+    default: false,
+    describe: 'Print the summary and any error as JSON (same as --summary-format json)',
+    type: 'boolean' as const,
+  },
+  events: {
+    choices: ['ndjson'] as const,
+    describe: 'Stream installer events to stdout as NDJSON, one object per line (other output goes to stderr)',
+  },
+  'events-module': {
+    type: 'string' as const,
+    hidden: true,
+  },
+  'report-path': {
+    type: 'string' as const,
+    describe: 'Where to write the markdown run report, a .md file or a directory (default: .workos/reports/)',
+  },
+  'allow-main': {
+    default: false,
+    describe: 'Commit on a protected or default branch such as main instead of stopping before the commit',
+    type: 'boolean' as const,
+  },
+  'create-pr': {
+    default: false,
+    describe: 'Commit, push the branch and open (or update) its GitHub pull request without asking',
+    type: 'boolean' as const,
+  },
   integration: {
     describe: 'Integration to set up',
     type: 'string' as const,
@@ -133,6 +302,55 @@ const installerOptions = {
     describe: 'Force install packages even if peer dependency checks fail',
     type: 'boolean' as const,
   },
+  'show-diffs': {
+    type: 'boolean' as const,
+    default: false,
+    describe: 'Show a unified diff for each file change (interactive: view, apply or skip each one)',
+  },
+  'dry-run': {
+    type: 'boolean' as const,
+    default: false,
+    describe: 'Preview the changes without writing files, running commands or committing',
+  },
+  'diff-only': {
+    type: 'string' as const,
+    describe: 'Write every change, env and manifest edits included, to this patch file for git apply instead',
+  },
+  'keep-backups': {
+    default: false,
+    describe: 'Keep the original of each edited file as <file>.workos.bak (remove them with workos clean)',
+    type: 'boolean' as const,
+  },
+  yes: {
+    alias: 'y',
+    type: 'boolean' as const,
+    default: false,
+    describe: 'Skip confirmations: run project hooks without asking (migrate: skip the file checklist)',
+  },
+  timeout: durationOption('--timeout', 'Stop the agent after this long, e.g. 15m or 1h (default 30m)'),
+  'idle-timeout': durationOption('--idle-timeout', 'Stop the agent when it sends nothing for this long (default 10m)'),
+  'max-tokens': {
+    type: 'string' as const,
+    describe: 'Token budget for the agent, e.g. 500k or 2m; warns before the run when the estimate is over it',
+    coerce: (value: string) => {
+      const tokens = parseTokenCount(value);
+      if (tokens === null || tokens <= 0) throw new Error('--max-tokens must be a token count like 500000, 500k or 2m');
+      return tokens;
+    },
+  },
+  'compose-service': {
+    type: 'string' as const,
+    describe: 'Docker Compose service the app runs in, optionally with its container port (web or web:3000)',
+  },
+  instructions: {
+    type: 'string' as const,
+    describe: 'File of project conventions to add to the agent prompt (default: .workos/instructions.md)',
+  },
+  'force-reinstall': {
+    default: false,
+    describe: 'Scaffold a new integration even if the project already has AuthKit (default: update it)',
+    type: 'boolean' as const,
+  },
   dashboard: {
     alias: 'd',
     default: false,
@@ -141,22 +359,150 @@ const installerOptions = {
   },
 };
 
-// Check for updates (blocks up to 500ms)
-await checkForUpdates();
+const printingCompletions = process.argv.includes('--get-yargs-completions');
+// Read before yargs parses, so the update notice can be left out too
+const quiet = process.argv.includes('-q') || process.argv.includes('--quiet');
+
+// Check for updates (blocks up to 500ms); never while printing completions, in quiet mode or sending telemetry
+if (!printingCompletions && !quiet && process.argv[2] !== '__telemetry-send') await checkForUpdates();
 
 yargs(hideBin(process.argv))
   .env('WORKOS_INSTALLER')
+  .option('max-retries', {
+    alias: 'retries',
+    type: 'number',
+    global: true,
+    describe: 'Retries for rate limits, transient network errors and 5xx responses (default 3)',
+  })
+  .option('http-timeout', {
+    ...durationOption(
+      '--http-timeout',
+      'Timeout for each WorkOS API request, e.g. 90s (default 30s; longer for exports)',
+    ),
+    global: true,
+  })
+  .option('proxy', {
+    type: 'string',
+    global: true,
+    describe: 'HTTP(S) proxy for WorkOS API and skill registry requests (overrides HTTPS_PROXY/HTTP_PROXY)',
+  })
+  .option('telemetry', {
+    type: 'boolean',
+    global: true,
+    describe: 'Send anonymous usage telemetry; --no-telemetry opts out for this run',
+  })
+  .option('cache', {
+    type: 'boolean',
+    global: true,
+    describe: 'Serve completion and picker lookups from ~/.workos/cache/api; --no-cache always fetches',
+  })
+  .option('quiet', {
+    alias: 'q',
+    type: 'boolean',
+    global: true,
+    describe: 'Print only errors, warnings (to stderr) and the final result',
+  })
+  .option('i-know-this-is-production', {
+    type: 'boolean',
+    global: true,
+    describe: 'Run deletes and other destructive changes in a production environment without typing its name',
+  })
+  .middleware(async (argv) => {
+    await applyInsecureStorage(argv.insecureStorage as boolean | undefined);
+    const { loadTelemetryPreference } = await import('./lib/telemetry-preference.js');
+    // `workos telemetry on|off` answers the first-run question itself
+    await loadTelemetryPreference({
+      telemetry: argv.telemetry,
+      ask: !['telemetry', '__telemetry-send'].includes(String(argv._[0])),
+    });
+    if (!printingCompletions) {
+      const { trackCommand } = await import('./utils/command-telemetry.js');
+      trackCommand(String(argv._[0] ?? 'install'));
+    }
+    if (argv.proxy) {
+      const { setProxyOverride } = await import('./lib/proxy.js');
+      setProxyOverride(argv.proxy);
+    }
+    const { setHttpCommand, setHttpRetries, setHttpTimeout } = await import('./lib/http-retry.js');
+    if (typeof argv.maxRetries === 'number' && !Number.isNaN(argv.maxRetries)) setHttpRetries(argv.maxRetries);
+    // Exports and other slow commands get a longer timeout unless --http-timeout says otherwise
+    setHttpCommand(argv._.map(String).join(' '));
+    if (typeof argv.httpTimeout === 'number') setHttpTimeout(argv.httpTimeout);
+    if (argv.cache === false) {
+      const { setApiCacheEnabled } = await import('./lib/api-cache.js');
+      setApiCacheEnabled(false);
+    }
+    if (argv.quiet) {
+      const { setQuietMode } = await import('./utils/clack.js');
+      setQuietMode(true);
+    }
+    if (argv.iKnowThisIsProduction) {
+      const { setProductionConfirmed } = await import('./lib/environment-mode.js');
+      setProductionConfirmed(true);
+    }
+    // Prompts say which environment they act on; only worth reading the config when they can be shown
+    if (!printingCompletions && process.stdin.isTTY) {
+      const { activePromptPrefix } = await import('./lib/environment-mode.js');
+      const { setPromptPrefix } = await import('./utils/clack.js');
+      setPromptPrefix(activePromptPrefix());
+    }
+  })
+  .completion(
+    'completion',
+    'Print a bash or zsh completion script (add it to ~/.bashrc or ~/.zshrc)',
+    (_current, _argv, completionFilter, done) => {
+      // The words after the program name, as the completion script passes them
+      const words = process.argv.slice(process.argv.indexOf('--get-yargs-completions') + 2);
+      void completeDynamicValue(words).then((values) => {
+        if (values) done(values);
+        else completionFilter((_err, defaults) => done(defaults));
+      });
+    },
+  )
   .command('login', 'Authenticate with WorkOS', insecureStorageOption, async (argv) => {
     await applyInsecureStorage(argv.insecureStorage);
     const { runLogin } = await import('./commands/login.js');
     await runLogin();
     process.exit(0);
   })
-  .command('logout', 'Remove stored credentials', insecureStorageOption, async (argv) => {
-    await applyInsecureStorage(argv.insecureStorage);
-    const { runLogout } = await import('./commands/logout.js');
-    await runLogout();
-  })
+  .command(
+    'logout',
+    'Revoke the session and remove stored credentials',
+    (yargs) =>
+      yargs.options({
+        ...insecureStorageOption,
+        all: { type: 'boolean', default: false, describe: 'Also remove every profile added with `workos env add`' },
+        profile: { type: 'string', describe: 'Remove only this profile and stay logged in' },
+      }),
+    async (argv) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { runLogout } = await import('./commands/logout.js');
+      await runLogout({ all: argv.all, profile: argv.profile });
+    },
+  )
+  .command('cache', 'Manage the lookup cache used by completion and pickers', (yargs) =>
+    yargs
+      .command('clean', 'Remove every cached API lookup', {}, async () => {
+        const { runCacheClean } = await import('./commands/cache.js');
+        runCacheClean();
+      })
+      .demandCommand(1, 'Please specify a cache subcommand')
+      .strict(),
+  )
+  .command(
+    'whoami',
+    'Show the account, team, environment and profile the CLI is using',
+    (yargs) =>
+      yargs.options({
+        ...insecureStorageOption,
+        json: { type: 'boolean', default: false, describe: 'Output as JSON' },
+      }),
+    async (argv) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { runWhoami } = await import('./commands/whoami.js');
+      await runWhoami({ json: argv.json });
+    },
+  )
   .command(
     'install-skill',
     'Install bundled AuthKit skills to coding agents',
@@ -173,11 +519,29 @@ yargs(hideBin(process.argv))
           string: true,
           description: 'Install specific skill(s)',
         })
+        .option('pick', {
+          type: 'boolean',
+          default: false,
+          description: 'Choose the skills to install from a filterable list',
+        })
         .option('agent', {
           alias: 'a',
           type: 'array',
           string: true,
           description: 'Target specific agent(s): claude-code, codex, cursor, goose',
+        })
+        .option('from', {
+          type: 'string',
+          description: 'Install a third-party skill bundle from a directory or https URL',
+        })
+        .option('require-signed', {
+          type: 'boolean',
+          default: false,
+          description: 'Refuse third-party bundles without a valid minisign signature',
+        })
+        .option('public-key', {
+          type: 'string',
+          description: 'Minisign public key for verifying bundle signatures (or WORKOS_SKILLS_PUBLIC_KEY)',
         });
     },
     withAuth(async (argv) => {
@@ -185,80 +549,524 @@ yargs(hideBin(process.argv))
       await runInstallSkill({
         list: argv.list as boolean | undefined,
         skill: argv.skill as string[] | undefined,
+        pick: argv.pick as boolean | undefined,
         agent: argv.agent as string[] | undefined,
+        from: argv.from as string | undefined,
+        requireSigned: argv.requireSigned as boolean | undefined,
+        publicKey: argv.publicKey as string | undefined,
       });
     }),
   )
   .command(
-    'doctor',
-    'Diagnose WorkOS integration issues',
+    'upgrade',
+    'Upgrade the WorkOS CLI to the latest release',
     (yargs) =>
       yargs.options({
-        verbose: {
-          type: 'boolean',
-          default: false,
-          description: 'Include additional diagnostic information',
+        channel: {
+          type: 'string',
+          choices: ['stable', 'beta'],
+          default: 'stable',
+          description: 'Release channel',
         },
-        'skip-api': {
+        check: {
           type: 'boolean',
           default: false,
-          description: 'Skip API calls (offline mode)',
+          description: 'Only report whether an update is available',
         },
-        'skip-ai': {
+      }),
+    async (argv) => {
+      const { runUpgrade } = await import('./commands/upgrade.js');
+      await runUpgrade({ channel: argv.channel as 'stable' | 'beta', check: argv.check });
+    },
+  )
+  .command(
+    'telemetry [action]',
+    'Show or change whether anonymous usage telemetry is sent',
+    (yargs) =>
+      yargs.options(insecureStorageOption).positional('action', {
+        choices: ['on', 'off', 'status', 'show'] as const,
+        default: 'status' as const,
+        describe: 'Turn telemetry on or off, show its status, or show the payloads waiting to be sent',
+      }),
+    async (argv) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { runTelemetry } = await import('./commands/telemetry.js');
+      runTelemetry(argv.action);
+    },
+  )
+  .command(
+    '__telemetry-send',
+    false, // spawned in the background to deliver spooled telemetry
+    {},
+    async () => {
+      const { runTelemetrySend } = await import('./commands/telemetry.js');
+      await runTelemetrySend();
+    },
+  )
+  .command(
+    'version',
+    'Show the CLI version, commit, build date and Node.js runtime',
+    (yargs) => yargs.options({ json: { type: 'boolean', default: false, description: 'Output as JSON' } }),
+    async (argv) => {
+      const { runVersion } = await import('./commands/version.js');
+      runVersion({ json: argv.json });
+    },
+  )
+  .command(
+    'explain [id]',
+    'Explain an error ID (e.g. WOS-API-001): what it means, common causes and fixes',
+    (yargs) =>
+      yargs
+        .positional('id', { type: 'string', describe: 'Error ID; lists every ID when omitted' })
+        .options({ json: { type: 'boolean', default: false, description: 'Output as JSON' } }),
+    async (argv) => {
+      const { runExplain } = await import('./commands/explain.js');
+      runExplain(argv.id, { json: argv.json });
+    },
+  )
+  .command(
+    'schema [name]',
+    'Print the JSON Schema of the plan, events or result output',
+    (yargs) =>
+      yargs.positional('name', {
+        choices: ['plan', 'events', 'result'] as const,
+        describe: 'Output to describe; lists every schema when omitted',
+      }),
+    async (argv) => {
+      const { runSchema } = await import('./commands/schema.js');
+      runSchema(argv.name);
+    },
+  )
+  .command(
+    'providers',
+    'List the auth providers detection and migration support',
+    (yargs) =>
+      yargs.options({
+        json: {
           type: 'boolean',
           default: false,
-          description: 'Skip AI-powered analysis',
+          description: 'Output the detectors and providers as JSON',
         },
+      }),
+    async (argv) => {
+      const { runProviders } = await import('./commands/providers.js');
+      await runProviders({ json: argv.json });
+    },
+  )
+  .command(
+    'detect',
+    'Detect other auth providers and list what needs to change for AuthKit',
+    (yargs) =>
+      yargs.options({
         'install-dir': {
           type: 'string',
           default: process.cwd(),
-          description: 'Project directory to analyze',
+          description: 'Project directory to scan',
         },
         json: {
           type: 'boolean',
           default: false,
-          description: 'Output report as JSON',
+          description: 'Output findings as JSON',
         },
-        copy: {
+        'min-confidence': minConfidenceOption,
+        ...scanPathOptions,
+        cache: discoveryCacheOption,
+        'include-all': {
           type: 'boolean',
           default: false,
-          description: 'Copy report to clipboard',
+          description: 'Keep matches below --min-confidence, flagged as belowThreshold',
+        },
+        since: {
+          type: 'string',
+          description: 'Only scan files changed since this git ref (exits 1 when anything is found)',
+        },
+        'files-from': {
+          type: 'string',
+          description: 'Scan the files listed in this file (- for stdin) instead of walking (exits 1 on findings)',
+        },
+        stdin: {
+          type: 'boolean',
+          default: false,
+          description: 'Read the file list from stdin (same as --files-from -)',
+        },
+        verbose: {
+          type: 'boolean',
+          default: false,
+          description: 'Also list files skipped as too large or binary, and files left out by ignore rules',
+        },
+        repo: {
+          type: 'string',
+          description: 'Scan a shallow clone of this git URL instead (removed afterwards)',
+        },
+        branch: {
+          type: 'string',
+          description: "With --repo, the branch to check out (default: the remote's default branch)",
+        },
+        count: {
+          type: 'boolean',
+          default: false,
+          description: 'Print only the number of findings per provider and a total',
+        },
+        'fail-over': {
+          type: 'number',
+          implies: 'count',
+          description: 'With --count, exit 1 when the total is over this (scanning stops once it is)',
+          coerce: (value: number) => {
+            if (!Number.isInteger(value) || value < 0) {
+              throw new Error('--fail-over must be a whole number of findings');
+            }
+            return value;
+          },
         },
       }),
     async (argv) => {
-      const { handleDoctor } = await import('./commands/doctor.js');
-      await handleDoctor(argv);
+      const { runDetect } = await import('./commands/detect.js');
+      await runDetect({
+        installDir: argv.installDir,
+        json: argv.json,
+        minConfidence: argv.minConfidence,
+        includeAll: argv.includeAll,
+        since: argv.since,
+        filesFrom: argv.stdin ? '-' : argv.filesFrom,
+        include: argv.include,
+        exclude: argv.exclude,
+        cache: argv.cache,
+        maxFileSize: argv.maxFileSize,
+        scanTerraform: argv.scanTerraform,
+        verbose: argv.verbose,
+        repo: argv.repo,
+        branch: argv.branch,
+        count: argv.count,
+        failOver: argv.failOver,
+      });
+    },
+  )
+  .command(
+    'verify',
+    'Fail when a migrated project still references another auth provider (for CI)',
+    (yargs) =>
+      yargs.options({
+        'install-dir': {
+          type: 'string',
+          default: process.cwd(),
+          description: 'Project directory to check',
+        },
+        json: {
+          type: 'boolean',
+          default: false,
+          description: 'Output the result as JSON',
+        },
+        'min-confidence': minConfidenceOption,
+        ...scanPathOptions,
+        cache: discoveryCacheOption,
+      }),
+    async (argv) => {
+      const { runVerify } = await import('./commands/verify.js');
+      await runVerify({
+        installDir: argv.installDir,
+        json: argv.json,
+        minConfidence: argv.minConfidence,
+        include: argv.include,
+        exclude: argv.exclude,
+        cache: argv.cache,
+        maxFileSize: argv.maxFileSize,
+        scanTerraform: argv.scanTerraform,
+      });
+    },
+  )
+  .command(
+    'review <patch>',
+    'Review a --diff-only patch, or pick and apply its changes in the browser with --web',
+    (yargs) =>
+      yargs
+        .positional('patch', { type: 'string', demandOption: true, describe: 'Patch written by --diff-only' })
+        .options({
+          'install-dir': {
+            type: 'string',
+            default: process.cwd(),
+            description: 'Project directory the patch was made for',
+          },
+          web: {
+            type: 'boolean',
+            default: false,
+            description: 'Serve the plan on localhost with per-file diffs and an "Apply selected" button',
+          },
+          open: { type: 'boolean', default: true, description: 'Open the page in the browser (--no-open to skip)' },
+        }),
+    async (argv) => {
+      const { runReview } = await import('./commands/review.js');
+      await runReview(argv.patch, { installDir: argv.installDir, web: argv.web, open: argv.open });
     },
   )
-  .command('env', 'Manage environment configurations', (yargs) =>
+  .command('scan', 'Scan the project for problems', (yargs) =>
     yargs
-      .options(insecureStorageOption)
       .command(
-        'add [name] [apiKey]',
-        'Add an environment configuration',
+        'secrets',
+        'Find WorkOS secrets in tracked and staged files (exits 1 on findings)',
         (yargs) =>
-          yargs
-            .positional('name', { type: 'string', describe: 'Environment name' })
-            .positional('apiKey', { type: 'string', describe: 'WorkOS API key' })
-            .option('client-id', { type: 'string', describe: 'WorkOS client ID' })
-            .option('endpoint', { type: 'string', describe: 'Custom API endpoint' }),
+          yargs.options({
+            'install-dir': {
+              type: 'string',
+              default: process.cwd(),
+              description: 'Directory inside the git repository to scan',
+            },
+            json: {
+              type: 'boolean',
+              default: false,
+              description: 'Output findings as JSON',
+            },
+          }),
         async (argv) => {
-          await applyInsecureStorage(argv.insecureStorage);
-          const { runEnvAdd } = await import('./commands/env.js');
-          await runEnvAdd({
-            name: argv.name,
-            apiKey: argv.apiKey,
-            clientId: argv.clientId,
-            endpoint: argv.endpoint,
-          });
+          const { runScanSecrets } = await import('./commands/scan.js');
+          await runScanSecrets({ installDir: argv.installDir, json: argv.json });
         },
       )
-      .command(
-        'remove <name>',
-        'Remove an environment configuration',
-        (yargs) => yargs.positional('name', { type: 'string', demandOption: true, describe: 'Environment name' }),
-        async (argv) => {
-          await applyInsecureStorage(argv.insecureStorage);
+      .demandCommand(1, 'Please specify a scan subcommand')
+      .strict(),
+  )
+  .command('check', 'Smoke-test a deployed integration', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'app',
+        "Check a deployed app's login, callback, redirect URI, issuer and JWKS (exits 1 on failures)",
+        (yargs) =>
+          yargs.options({
+            url: { type: 'string', demandOption: true, describe: 'Deployed app URL, e.g. https://myapp.example.com' },
+            'client-id': { type: 'string', describe: 'Client ID (defaults to WORKOS_CLIENT_ID or the environment)' },
+            'login-path': { type: 'string', default: '/login', describe: 'Route that starts sign-in' },
+            'callback-path': {
+              type: 'string',
+              describe: 'Callback route (defaults to the redirect_uri the login route sends)',
+            },
+            issuer: { type: 'string', describe: 'Expected token issuer, for a custom auth domain' },
+            json: { type: 'boolean', default: false, describe: 'Output results as JSON' },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runCheckApp } = await import('./commands/check.js');
+          await runCheckApp({
+            url: argv.url,
+            clientId: argv.clientId,
+            loginPath: argv.loginPath,
+            callbackPath: argv.callbackPath,
+            issuer: argv.issuer,
+            json: argv.json,
+            apiKey: resolveApiKey({ apiKey: argv.apiKey }),
+            baseUrl: resolveApiBaseUrl(),
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify a check subcommand')
+      .strict(),
+  )
+  .command('skills', 'Author custom skills and try them before publishing', (yargs) =>
+    yargs
+      .command(
+        'new <id>',
+        'Scaffold a skill directory: SKILL.md, verify.json, skill.json and a validate test',
+        (yargs) =>
+          yargs
+            .positional('id', { type: 'string', demandOption: true, describe: 'Skill id, e.g. my-company-authkit' })
+            .options({
+              from: {
+                type: 'string',
+                describe: 'Bundled skill name or skill directory to copy, e.g. workos-authkit-base',
+              },
+              dir: { type: 'string', describe: 'Directory to create the skill in (default: current directory)' },
+            }),
+        async (argv) => {
+          const { runSkillsNew } = await import('./commands/skills.js');
+          await runSkillsNew({ id: argv.id, from: argv.from, dir: argv.dir });
+        },
+      )
+      .command(
+        'validate [dir]',
+        'Check a skill directory: frontmatter, skill.json, verify.json and unfilled placeholders (exits 1 on errors)',
+        (yargs) =>
+          yargs
+            .positional('dir', { type: 'string', default: '.', describe: 'Skill directory' })
+            .options({ json: { type: 'boolean', default: false, describe: 'Output results as JSON' } }),
+        async (argv) => {
+          const { runSkillsValidate } = await import('./commands/skills.js');
+          await runSkillsValidate({ dir: argv.dir, json: argv.json });
+        },
+      )
+      .command(
+        'run <dir>',
+        'Run the installer against an app with a local, unpublished skill',
+        (yargs) =>
+          yargs
+            .positional('dir', { type: 'string', demandOption: true, describe: 'Skill directory' })
+            .options({
+              ...installerOptions,
+              target: { type: 'string', demandOption: true, describe: 'App directory to run the skill against' },
+            }),
+        withAuth(async (argv) => {
+          const { runSkillsRun } = await import('./commands/skills.js');
+          await runSkillsRun(argv);
+        }),
+      )
+      .demandCommand(1, 'Please specify a skills subcommand')
+      .strict(),
+  )
+  .command('generate', 'Generate project files', (yargs) =>
+    yargs
+      .command(
+        'ci [provider]',
+        'Write a CI workflow that sets the WorkOS secrets and runs `workos doctor`',
+        (yargs) =>
+          yargs
+            .positional('provider', {
+              choices: ['github-actions', 'gitlab-ci'] as const,
+              default: 'github-actions' as const,
+              describe: 'CI to generate for (also --provider)',
+            })
+            .options({
+              'install-dir': {
+                type: 'string',
+                default: process.cwd(),
+                description: 'Project directory (where .env.example is)',
+              },
+              force: {
+                type: 'boolean',
+                default: false,
+                description: 'Overwrite an existing workflow file',
+              },
+              'scan-secrets': {
+                type: 'boolean',
+                default: false,
+                description: 'Also run `workos scan secrets` in the workflow',
+              },
+            }),
+        async (argv) => {
+          const { runGenerateCi } = await import('./commands/generate.js');
+          await runGenerateCi({
+            provider: argv.provider,
+            installDir: argv.installDir,
+            force: argv.force,
+            scanSecrets: argv.scanSecrets,
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify a generate subcommand')
+      .strict(),
+  )
+  .command(
+    'doctor',
+    'Diagnose WorkOS integration issues',
+    (yargs) =>
+      yargs.options({
+        verbose: {
+          type: 'boolean',
+          default: false,
+          description: 'Include additional diagnostic information',
+        },
+        'skip-api': {
+          type: 'boolean',
+          default: false,
+          description: 'Skip API calls (offline mode)',
+        },
+        'skip-ai': {
+          type: 'boolean',
+          default: false,
+          description: 'Skip AI-powered analysis',
+        },
+        'install-dir': {
+          type: 'string',
+          default: process.cwd(),
+          description: 'Project directory to analyze',
+        },
+        json: {
+          type: 'boolean',
+          default: false,
+          description: 'Output report as JSON',
+        },
+        copy: {
+          type: 'boolean',
+          default: false,
+          description: 'Copy report to clipboard',
+        },
+      }),
+    async (argv) => {
+      const { handleDoctor } = await import('./commands/doctor.js');
+      await handleDoctor(argv);
+    },
+  )
+  .command(
+    'status',
+    "Summarize the project's WorkOS setup: skills, SDKs, env keys, environment and redirect URI",
+    (yargs) =>
+      yargs.options({
+        ...insecureStorageOption,
+        'install-dir': {
+          type: 'string',
+          default: process.cwd(),
+          description: 'Project directory to inspect',
+        },
+        remote: {
+          type: 'boolean',
+          default: false,
+          description: 'Also check the redirect URIs registered in WorkOS (the only API call)',
+        },
+        json: { type: 'boolean', default: false, description: 'Output as JSON' },
+      }),
+    async (argv) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { runStatus } = await import('./commands/status.js');
+      await runStatus({ installDir: argv.installDir, json: argv.json, remote: argv.remote });
+    },
+  )
+  .command(
+    'clean',
+    'Remove the <file>.workos.bak originals kept by --keep-backups',
+    (yargs) =>
+      yargs.options({
+        'install-dir': {
+          type: 'string',
+          default: process.cwd(),
+          description: 'Project directory to clean',
+        },
+        'dry-run': { type: 'boolean', default: false, description: 'List the backups without removing them' },
+      }),
+    async (argv) => {
+      const { runClean } = await import('./commands/clean.js');
+      runClean({ installDir: argv.installDir, dryRun: argv.dryRun });
+    },
+  )
+  .command(['env', 'environments'], 'Manage environment configurations', (yargs) =>
+    yargs
+      .options(insecureStorageOption)
+      .command(
+        'add [name] [apiKey]',
+        'Add an environment configuration',
+        (yargs) =>
+          yargs
+            .positional('name', { type: 'string', describe: 'Environment name' })
+            .positional('apiKey', { type: 'string', describe: 'WorkOS API key' })
+            .option('client-id', { type: 'string', describe: 'WorkOS client ID' })
+            .option('endpoint', { type: 'string', describe: 'Custom API endpoint' }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { runEnvAdd } = await import('./commands/env.js');
+          await runEnvAdd({
+            name: argv.name,
+            apiKey: argv.apiKey,
+            clientId: argv.clientId,
+            endpoint: argv.endpoint,
+          });
+        },
+      )
+      .command(
+        'remove <name>',
+        'Remove an environment configuration',
+        (yargs) => yargs.positional('name', { type: 'string', demandOption: true, describe: 'Environment name' }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
           const { runEnvRemove } = await import('./commands/env.js');
           await runEnvRemove(argv.name);
         },
@@ -278,10 +1086,74 @@ yargs(hideBin(process.argv))
         const { runEnvList } = await import('./commands/env.js');
         await runEnvList();
       })
+      .command(
+        'example',
+        'Write or update .env.example from your env file',
+        (yargs) =>
+          yargs.options({
+            'install-dir': {
+              type: 'string',
+              default: process.cwd(),
+              description: 'Project directory',
+            },
+            'env-file': {
+              type: 'string',
+              description: 'Env file to read keys from (default: .env.local, then .env)',
+            },
+          }),
+        async (argv) => {
+          const { runEnvExample } = await import('./commands/env.js');
+          await runEnvExample({ installDir: argv.installDir, envFile: argv.envFile });
+        },
+      )
+      .command(
+        'diff <from> <to>',
+        'Compare the configuration of two environments (exits 1 when they differ)',
+        (yargs) =>
+          yargs
+            .positional('from', { type: 'string', demandOption: true, describe: 'Environment name, e.g. staging' })
+            .positional('to', { type: 'string', demandOption: true, describe: 'Environment name, e.g. production' })
+            .option('json', { type: 'boolean', default: false, describe: 'Output the differences as JSON' }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { runEnvDiff } = await import('./commands/env.js');
+          await runEnvDiff(argv.from, argv.to, { json: argv.json });
+        },
+      )
+      .command(
+        'clone',
+        'Copy redirect URIs, CORS origins, roles, branding and webhooks into another environment',
+        (yargs) =>
+          yargs.options({
+            from: { type: 'string', demandOption: true, describe: 'Environment to copy from' },
+            name: { type: 'string', demandOption: true, describe: 'Environment to copy into, e.g. "QA"' },
+            'api-key': {
+              type: 'string',
+              describe: 'API key of the new environment (created in the dashboard), stored as --name',
+            },
+            'keep-webhook-urls': {
+              type: 'boolean',
+              default: false,
+              describe: 'Copy webhook endpoint URLs as they are instead of pointing them at placeholders',
+            },
+            'dry-run': { type: 'boolean', default: false, describe: 'Print the copy plan without applying it' },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { runEnvClone } = await import('./commands/env.js');
+          await runEnvClone({
+            from: argv.from,
+            name: argv.name,
+            apiKey: argv.apiKey,
+            keepWebhookUrls: argv.keepWebhookUrls,
+            dryRun: argv.dryRun,
+          });
+        },
+      )
       .demandCommand(1, 'Please specify an env subcommand')
       .strict(),
   )
-  .command('organization', 'Manage organizations', (yargs) =>
+  .command(['organization', 'orgs'], 'Manage organizations', (yargs) =>
     yargs
       .options({
         ...insecureStorageOption,
@@ -320,9 +1192,10 @@ yargs(hideBin(process.argv))
         },
       )
       .command(
-        'get <orgId>',
-        'Get an organization by ID',
-        (yargs) => yargs.positional('orgId', { type: 'string', demandOption: true, describe: 'Organization ID' }),
+        'get [orgId]',
+        'Get an organization by ID, or pick one',
+        (yargs) =>
+          yargs.positional('orgId', { type: 'string', describe: 'Organization ID (omit to pick from a list)' }),
         async (argv) => {
           await applyInsecureStorage(argv.insecureStorage);
           const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
@@ -341,6 +1214,8 @@ yargs(hideBin(process.argv))
             before: { type: 'string', describe: 'Cursor for results before a specific item' },
             after: { type: 'string', describe: 'Cursor for results after a specific item' },
             order: { type: 'string', describe: 'Order of results (asc or desc)' },
+            all: { type: 'boolean', default: false, describe: 'Fetch every page (--limit caps the total)' },
+            json: { type: 'boolean', default: false, describe: 'Output as JSON (JSON lines with --all)' },
           }),
         async (argv) => {
           await applyInsecureStorage(argv.insecureStorage);
@@ -348,12 +1223,35 @@ yargs(hideBin(process.argv))
           const { runOrgList } = await import('./commands/organization.js');
           const apiKey = resolveApiKey({ apiKey: argv.apiKey });
           await runOrgList(
-            { domain: argv.domain, limit: argv.limit, before: argv.before, after: argv.after, order: argv.order },
+            {
+              domain: argv.domain,
+              limit: argv.limit,
+              before: argv.before,
+              after: argv.after,
+              order: argv.order,
+              all: argv.all,
+              json: argv.json,
+            },
             apiKey,
             resolveApiBaseUrl(),
           );
         },
       )
+      .command(
+        'export',
+        'Export every organization, with its domains, to CSV or JSON lines',
+        (yargs) => yargs.options(directoryExportOptions),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runOrgExport } = await import('./commands/organization.js');
+          await runOrgExport(
+            { format: argv.format, out: argv.out, updatedSince: argv.updatedSince, resume: argv.resume },
+            resolveApiKey({ apiKey: argv.apiKey }),
+            resolveApiBaseUrl(),
+          );
+        },
+      )
       .command(
         'delete <orgId>',
         'Delete an organization',
@@ -361,24 +1259,26 @@ yargs(hideBin(process.argv))
         async (argv) => {
           await applyInsecureStorage(argv.insecureStorage);
           const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { confirmDestructive } = await import('./lib/environment-mode.js');
           const { runOrgDelete } = await import('./commands/organization.js');
           const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await confirmDestructive(`Delete organization ${argv.orgId}`, { apiKey, baseUrl: resolveApiBaseUrl() });
           await runOrgDelete(argv.orgId, apiKey, resolveApiBaseUrl());
         },
       )
       .demandCommand(1, 'Please specify an organization subcommand')
       .strict(),
   )
-  .command('user', 'Manage users', (yargs) =>
+  .command(['user', 'users'], 'Manage users', (yargs) =>
     yargs
       .options({
         ...insecureStorageOption,
         'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
       })
       .command(
-        'get <userId>',
-        'Get a user by ID',
-        (yargs) => yargs.positional('userId', { type: 'string', demandOption: true, describe: 'User ID' }),
+        'get [userId]',
+        'Get a user by ID, or pick one',
+        (yargs) => yargs.positional('userId', { type: 'string', describe: 'User ID (omit to pick from a list)' }),
         async (argv) => {
           await applyInsecureStorage(argv.insecureStorage);
           const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
@@ -397,6 +1297,8 @@ yargs(hideBin(process.argv))
             before: { type: 'string', describe: 'Cursor for results before a specific item' },
             after: { type: 'string', describe: 'Cursor for results after a specific item' },
             order: { type: 'string', describe: 'Order of results (asc or desc)' },
+            all: { type: 'boolean', default: false, describe: 'Fetch every page (--limit caps the total)' },
+            json: { type: 'boolean', default: false, describe: 'Output as JSON (JSON lines with --all)' },
           }),
         async (argv) => {
           await applyInsecureStorage(argv.insecureStorage);
@@ -410,6 +1312,37 @@ yargs(hideBin(process.argv))
               before: argv.before,
               after: argv.after,
               order: argv.order,
+              all: argv.all,
+              json: argv.json,
+            },
+            resolveApiKey({ apiKey: argv.apiKey }),
+            resolveApiBaseUrl(),
+          );
+        },
+      )
+      .command(
+        'export',
+        'Export every user, with organization memberships, to CSV or JSON lines',
+        (yargs) =>
+          yargs.options({
+            ...directoryExportOptions,
+            memberships: {
+              type: 'boolean',
+              default: true,
+              describe: "Include each user's organization memberships (--no-memberships is faster)",
+            },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runUserExport } = await import('./commands/user.js');
+          await runUserExport(
+            {
+              format: argv.format,
+              out: argv.out,
+              updatedSince: argv.updatedSince,
+              resume: argv.resume,
+              memberships: argv.memberships,
             },
             resolveApiKey({ apiKey: argv.apiKey }),
             resolveApiBaseUrl(),
@@ -452,29 +1385,1246 @@ yargs(hideBin(process.argv))
         async (argv) => {
           await applyInsecureStorage(argv.insecureStorage);
           const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { confirmDestructive } = await import('./lib/environment-mode.js');
           const { runUserDelete } = await import('./commands/user.js');
-          await runUserDelete(argv.userId, resolveApiKey({ apiKey: argv.apiKey }), resolveApiBaseUrl());
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await confirmDestructive(`Delete user ${argv.userId}`, { apiKey, baseUrl: resolveApiBaseUrl() });
+          await runUserDelete(argv.userId, apiKey, resolveApiBaseUrl());
         },
       )
+      .command('factors', 'Manage MFA factors for a user', (yargs) =>
+        yargs
+          .command(
+            'list <user>',
+            "List a user's MFA factors",
+            (yargs) =>
+              yargs
+                .positional('user', { type: 'string', demandOption: true, describe: 'User ID or email' })
+                .options({ json: { type: 'boolean', default: false, describe: 'Output as JSON' } }),
+            async (argv) => {
+              await applyInsecureStorage(argv.insecureStorage);
+              const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+              const { runUserFactorsList } = await import('./commands/user-factors.js');
+              await runUserFactorsList(
+                argv.user,
+                { json: argv.json },
+                resolveApiKey({ apiKey: argv.apiKey }),
+                resolveApiBaseUrl(),
+              );
+            },
+          )
+          .command(
+            'enroll <user>',
+            'Enroll an MFA factor for a user',
+            (yargs) =>
+              yargs.positional('user', { type: 'string', demandOption: true, describe: 'User ID or email' }).options({
+                type: { type: 'string', choices: ['totp'] as const, default: 'totp' as const, describe: 'Factor type' },
+                issuer: { type: 'string', describe: 'Issuer shown in the authenticator app' },
+                label: { type: 'string', describe: 'Account label shown in the authenticator app' },
+                'generate-code': { type: 'boolean', default: false, describe: 'Print the current TOTP code' },
+                qr: { type: 'boolean', default: true, describe: 'Render the QR code (--no-qr to skip)' },
+                json: { type: 'boolean', default: false, describe: 'Output as JSON' },
+              }),
+            async (argv) => {
+              await applyInsecureStorage(argv.insecureStorage);
+              const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+              const { runUserFactorEnroll } = await import('./commands/user-factors.js');
+              await runUserFactorEnroll(
+                argv.user,
+                {
+                  type: argv.type,
+                  issuer: argv.issuer,
+                  label: argv.label,
+                  generateCode: argv.generateCode,
+                  qr: argv.qr,
+                  json: argv.json,
+                },
+                resolveApiKey({ apiKey: argv.apiKey }),
+                resolveApiBaseUrl(),
+              );
+            },
+          )
+          .command(
+            'delete <factorId>',
+            'Delete an MFA factor',
+            (yargs) => yargs.positional('factorId', { type: 'string', demandOption: true, describe: 'Factor ID' }),
+            async (argv) => {
+              await applyInsecureStorage(argv.insecureStorage);
+              const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+              const { confirmDestructive } = await import('./lib/environment-mode.js');
+              const { runUserFactorDelete } = await import('./commands/user-factors.js');
+              const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+              await confirmDestructive(`Delete MFA factor ${argv.factorId}`, { apiKey, baseUrl: resolveApiBaseUrl() });
+              await runUserFactorDelete(argv.factorId, apiKey, resolveApiBaseUrl());
+            },
+          )
+          .command(
+            'code <secret>',
+            'Print the current TOTP code for a factor secret',
+            (yargs) => yargs.positional('secret', { type: 'string', demandOption: true, describe: 'Base32 secret' }),
+            async (argv) => {
+              const { runUserFactorCode } = await import('./commands/user-factors.js');
+              runUserFactorCode(argv.secret);
+            },
+          )
+          .demandCommand(1, 'Please specify a factors subcommand')
+          .strict(),
+      )
       .demandCommand(1, 'Please specify a user subcommand')
       .strict(),
   )
-  .command(
-    'install',
-    'Install WorkOS AuthKit into your project',
-    (yargs) => yargs.options(installerOptions),
-    withAuth(async (argv) => {
-      const { handleInstall } = await import('./commands/install.js');
-      await handleInstall(argv);
-    }),
+  .command('roles', 'Manage environment roles and permissions', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'list',
+        'List roles and their permissions',
+        (yargs) => yargs,
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runRolesList } = await import('./commands/roles.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runRolesList(apiKey, resolveApiBaseUrl());
+        },
+      )
+      .command(
+        'sync <file>',
+        'Create, update and delete roles to match a YAML or JSON file',
+        (yargs) =>
+          yargs
+            .positional('file', { type: 'string', demandOption: true, describe: 'e.g. roles.yaml' })
+            .options({
+              'dry-run': { type: 'boolean', default: false, describe: 'Print the changes without applying them' },
+              'allow-destructive': {
+                type: 'boolean',
+                default: false,
+                describe: 'Delete roles even if they are assigned to memberships',
+              },
+              concurrency: {
+                type: 'number',
+                describe: 'Changes applied in parallel (default 8, at most 32)',
+              },
+              'errors-file': { type: 'string', describe: 'Append failed changes to this file as JSON lines' },
+            }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runRolesSync } = await import('./commands/roles.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runRolesSync(argv.file, {
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+            dryRun: argv.dryRun,
+            allowDestructive: argv.allowDestructive,
+            concurrency: argv.concurrency,
+            errorsFile: argv.errorsFile,
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify a roles subcommand')
+      .strict(),
   )
-  .command(
-    'dashboard',
-    false, // hidden from help
-    (yargs) => yargs.options(installerOptions),
-    withAuth(async (argv) => {
-      const { handleInstall } = await import('./commands/install.js');
-      await handleInstall({ ...argv, dashboard: true });
+  .command('audit-logs', 'Sync Audit Logs schemas and configure log streams', (yargs) => {
+    const auditLogsContext = async (argv: { apiKey?: string; insecureStorage?: boolean }) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+      return { apiKey: resolveApiKey({ apiKey: argv.apiKey }), baseUrl: resolveApiBaseUrl() };
+    };
+    return yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command('schema', 'Pull and push event action schemas', (yargs) =>
+        yargs
+          .command(
+            'pull [file]',
+            'Write the environment\'s actions and metadata schemas to a YAML file',
+            (yargs) => yargs.positional('file', { type: 'string', describe: 'e.g. schema.yaml (prints when omitted)' }),
+            async (argv) => {
+              const { runAuditLogsSchemaPull } = await import('./commands/audit-logs.js');
+              await runAuditLogsSchemaPull(argv.file, await auditLogsContext(argv));
+            },
+          )
+          .command(
+            'push <file>',
+            'Validate a YAML or JSON schema file, show the diff and publish it',
+            (yargs) =>
+              yargs
+                .positional('file', { type: 'string', demandOption: true, describe: 'e.g. schema.yaml' })
+                .options({
+                  'dry-run': { type: 'boolean', default: false, describe: 'Print the changes without applying them' },
+                  'allow-destructive': {
+                    type: 'boolean',
+                    default: false,
+                    describe: 'Remove actions that are not in the file',
+                  },
+                }),
+            async (argv) => {
+              const { runAuditLogsSchemaPush } = await import('./commands/audit-logs.js');
+              await runAuditLogsSchemaPush(argv.file, {
+                ...(await auditLogsContext(argv)),
+                dryRun: argv.dryRun,
+                allowDestructive: argv.allowDestructive,
+              });
+            },
+          )
+          .demandCommand(1, 'Please specify a schema subcommand')
+          .strict(),
+      )
+      .command('exporters', 'Configure where audit logs are streamed', (yargs) =>
+        yargs
+          .command(
+            'set',
+            "Set an organization's log stream from a config file",
+            (yargs) =>
+              yargs.options({
+                type: {
+                  type: 'string',
+                  demandOption: true,
+                  choices: ['datadog', 'splunk', 's3', 'gcs', 'http'],
+                  describe: 'Exporter type',
+                },
+                config: { type: 'string', demandOption: true, describe: 'e.g. exporter.yaml; ${VAR} reads the env' },
+                organization: { type: 'string', demandOption: true, describe: 'Organization ID' },
+                'dry-run': { type: 'boolean', default: false, describe: 'Validate the config without applying it' },
+              }),
+            async (argv) => {
+              const { runAuditLogsExporterSet } = await import('./commands/audit-logs.js');
+              await runAuditLogsExporterSet({
+                ...(await auditLogsContext(argv)),
+                type: argv.type,
+                config: argv.config,
+                organizationId: argv.organization,
+                dryRun: argv.dryRun,
+              });
+            },
+          )
+          .demandCommand(1, 'Please specify an exporters subcommand')
+          .strict(),
+      )
+      .demandCommand(1, 'Please specify an audit-logs subcommand')
+      .strict();
+  })
+  .command('cors', 'Manage the origins allowed to call AuthKit from the browser', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'list',
+        'List allowed CORS origins',
+        (yargs) => yargs.options({ json: { type: 'boolean', default: false, describe: 'Output as JSON' } }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runCorsList } = await import('./commands/cors.js');
+          await runCorsList({ json: argv.json }, resolveApiKey({ apiKey: argv.apiKey }), resolveApiBaseUrl());
+        },
+      )
+      .command(
+        'add <origin>',
+        'Allow an origin (no-op if already allowed)',
+        (yargs) =>
+          yargs.positional('origin', {
+            type: 'string',
+            demandOption: true,
+            describe: 'e.g. http://localhost:5173 or https://*.example.com',
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runCorsAdd } = await import('./commands/cors.js');
+          await runCorsAdd(argv.origin, resolveApiKey({ apiKey: argv.apiKey }), resolveApiBaseUrl());
+        },
+      )
+      .command(
+        'remove <origin>',
+        'Remove an allowed origin',
+        (yargs) => yargs.positional('origin', { type: 'string', demandOption: true, describe: 'Origin to remove' }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { confirmDestructive } = await import('./lib/environment-mode.js');
+          const { runCorsRemove } = await import('./commands/cors.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await confirmDestructive(`Remove CORS origin ${argv.origin}`, { apiKey, baseUrl: resolveApiBaseUrl() });
+          await runCorsRemove(argv.origin, apiKey, resolveApiBaseUrl());
+        },
+      )
+      .demandCommand(1, 'Please specify a cors subcommand')
+      .strict(),
+  )
+  .command('m2m', 'Create machine-to-machine clients and mint their tokens', (yargs) =>
+    yargs
+      .command('clients', 'Manage M2M clients', (yargs) =>
+        yargs
+          .options({
+            ...insecureStorageOption,
+            'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+          })
+          .command(
+            'list',
+            'List M2M clients',
+            (yargs) => yargs.options({ json: { type: 'boolean', default: false, describe: 'Output as JSON' } }),
+            async (argv) => {
+              await applyInsecureStorage(argv.insecureStorage);
+              const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+              const { runM2MClientsList } = await import('./commands/m2m.js');
+              await runM2MClientsList({ json: argv.json }, resolveApiKey({ apiKey: argv.apiKey }), resolveApiBaseUrl());
+            },
+          )
+          .command(
+            'create',
+            'Create an M2M client and print its secret once',
+            (yargs) =>
+              yargs.options({
+                name: { type: 'string', demandOption: true, describe: 'Client name, e.g. billing-service' },
+                scopes: { type: 'string', array: true, describe: 'Scopes (space- or comma-separated)' },
+                org: { type: 'string', describe: 'Organization the client acts for' },
+                json: { type: 'boolean', default: false, describe: 'Output as JSON' },
+              }),
+            async (argv) => {
+              await applyInsecureStorage(argv.insecureStorage);
+              const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+              const { runM2MClientsCreate } = await import('./commands/m2m.js');
+              await runM2MClientsCreate(
+                { name: argv.name, scopes: argv.scopes, org: argv.org, json: argv.json },
+                resolveApiKey({ apiKey: argv.apiKey }),
+                resolveApiBaseUrl(),
+              );
+            },
+          )
+          .command(
+            'delete <id>',
+            'Delete an M2M client',
+            (yargs) => yargs.positional('id', { type: 'string', demandOption: true, describe: 'Client ID or app ID' }),
+            async (argv) => {
+              await applyInsecureStorage(argv.insecureStorage);
+              const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+              const { confirmDestructive } = await import('./lib/environment-mode.js');
+              const { runM2MClientsDelete } = await import('./commands/m2m.js');
+              const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+              await confirmDestructive(`Delete M2M client ${argv.id}`, { apiKey, baseUrl: resolveApiBaseUrl() });
+              await runM2MClientsDelete(argv.id, apiKey, resolveApiBaseUrl());
+            },
+          )
+          .demandCommand(1, 'Please specify an m2m clients subcommand')
+          .strict(),
+      )
+      .command(
+        'token',
+        'Run the client-credentials grant and print the access token',
+        (yargs) =>
+          yargs.options({
+            client: { type: 'string', demandOption: true, describe: 'Client ID' },
+            secret: { type: 'string', describe: 'Client secret (defaults to WORKOS_M2M_CLIENT_SECRET)' },
+            'authkit-domain': {
+              type: 'string',
+              demandOption: true,
+              describe: "The environment's AuthKit domain, e.g. https://example.authkit.app",
+            },
+            scopes: { type: 'string', array: true, describe: 'Scopes to request (default: all granted)' },
+            decode: { type: 'boolean', default: false, describe: 'Also print the token claims' },
+            json: { type: 'boolean', default: false, describe: 'Output as JSON' },
+          }),
+        async (argv) => {
+          const { runM2MToken } = await import('./commands/m2m.js');
+          await runM2MToken({
+            client: argv.client,
+            secret: argv.secret,
+            authkitDomain: argv.authkitDomain,
+            scopes: argv.scopes,
+            decode: argv.decode,
+            json: argv.json,
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify an m2m subcommand')
+      .strict(),
+  )
+  .command('export', 'Export the environment configuration', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'terraform',
+        'Write the environment as Terraform, with the import commands that adopt it',
+        (yargs) =>
+          yargs.options({
+            out: { type: 'string', describe: 'File to write, - for stdout (default workos.tf, or workos.json)' },
+            format: {
+              choices: ['hcl', 'json'] as const,
+              default: 'hcl' as const,
+              describe: 'hcl for the WorkOS Terraform provider, json for a provider-neutral export',
+            },
+            organization: {
+              type: 'array',
+              string: true,
+              describe: 'Export only these organizations and their connections (repeatable)',
+            },
+            'pick-organizations': {
+              type: 'boolean',
+              default: false,
+              describe: 'Choose the organizations to export from a filterable list',
+            },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runExportTerraform } = await import('./commands/export.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runExportTerraform({
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+            format: argv.format,
+            out: argv.out,
+            organizations: argv.organization as string[] | undefined,
+            pickOrganizations: argv.pickOrganizations,
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify an export subcommand')
+      .strict(),
+  )
+  .command('domains', 'Set up custom AuthKit domains', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'setup <domain>',
+        'Register a custom domain and print the DNS records to create',
+        (yargs) =>
+          yargs.positional('domain', { type: 'string', demandOption: true, describe: 'e.g. auth.example.com' }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runDomainsSetup } = await import('./commands/domains.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runDomainsSetup(argv.domain, { apiKey, baseUrl: resolveApiBaseUrl() });
+        },
+      )
+      .command(
+        'verify <domain>',
+        'Check DNS and certificate status for a custom domain',
+        (yargs) =>
+          yargs
+            .positional('domain', { type: 'string', demandOption: true, describe: 'e.g. auth.example.com' })
+            .options({
+              wait: { type: 'boolean', default: false, describe: 'Poll until the domain is active' },
+              timeout: { type: 'number', default: 1800, describe: 'Seconds to wait with --wait' },
+              'install-dir': { type: 'string', describe: 'Project whose env file to update once active' },
+            }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runDomainsVerify } = await import('./commands/domains.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runDomainsVerify(argv.domain, {
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+            wait: argv.wait,
+            timeout: argv.timeout,
+            installDir: argv.installDir,
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify a domains subcommand')
+      .strict(),
+  )
+  .command('sso', 'Set up and test SSO connections', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'create',
+        'Create a SAML connection and print the values for the IdP admin',
+        (yargs) =>
+          yargs.options({
+            org: { type: 'string', demandOption: true, describe: 'Organization ID' },
+            type: {
+              type: 'string',
+              default: 'okta-saml',
+              choices: ['okta-saml', 'entra-saml', 'google-saml', 'generic-saml'],
+              describe: 'Identity provider',
+            },
+            'idp-metadata': { type: 'string', describe: 'IdP metadata URL or XML file to finish the connection' },
+            doc: { type: 'string', describe: 'Write IdP setup instructions to this markdown file' },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runSsoCreate } = await import('./commands/sso.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runSsoCreate({
+            org: argv.org,
+            type: argv.type,
+            idpMetadata: argv.idpMetadata,
+            doc: argv.doc,
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+          });
+        },
+      )
+      .command(
+        'configure <connectionId>',
+        'Finish a SAML connection with the IdP metadata',
+        (yargs) =>
+          yargs
+            .positional('connectionId', { type: 'string', demandOption: true, describe: 'Connection ID' })
+            .options({
+              'idp-metadata': { type: 'string', demandOption: true, describe: 'IdP metadata URL or XML file' },
+            }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runSsoConfigure } = await import('./commands/sso.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runSsoConfigure(argv.connectionId, {
+            idpMetadata: argv.idpMetadata,
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+          });
+        },
+      )
+      .command(
+        'test <connectionId>',
+        'Sign in through a connection and show the profile attribute mapping',
+        (yargs) =>
+          yargs
+            .positional('connectionId', { type: 'string', demandOption: true, describe: 'Connection ID' })
+            .options({
+              'client-id': { type: 'string', describe: 'Client ID (defaults to WORKOS_CLIENT_ID or the environment)' },
+              port: { type: 'number', default: 9004, describe: 'Local callback port' },
+              open: { type: 'boolean', default: true, describe: 'Open the login URL in a browser' },
+            }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runSsoTest } = await import('./commands/sso.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runSsoTest(argv.connectionId, {
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+            clientId: argv.clientId,
+            port: argv.port,
+            open: argv.open,
+          });
+        },
+      )
+      .command(
+        'map-test',
+        'Run a sample SAML assertion through a connection\'s attribute mapping',
+        (yargs) =>
+          yargs.options({
+            connection: { type: 'string', demandOption: true, describe: 'Connection ID' },
+            assertion: {
+              type: 'string',
+              demandOption: true,
+              describe: 'SAML response or assertion file (XML or base64 SAMLResponse)',
+            },
+            mapping: { type: 'string', describe: 'Proposed mapping (JSON) to test instead of the current one' },
+            save: {
+              type: 'boolean',
+              default: false,
+              describe: 'Save the proposed mapping if every required field maps',
+            },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runSsoMapTest } = await import('./commands/sso.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runSsoMapTest({
+            connectionId: argv.connection,
+            assertion: argv.assertion,
+            mapping: argv.mapping,
+            save: argv.save,
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify an sso subcommand')
+      .strict(),
+  )
+  .command('fga', 'Manage Fine-Grained Authorization schemas and warrants', (yargs) => {
+    const fgaContext = async (argv: { apiKey?: string; insecureStorage?: boolean; json?: boolean }) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+      return { apiKey: resolveApiKey({ apiKey: argv.apiKey }), baseUrl: resolveApiBaseUrl(), json: argv.json };
+    };
+    return yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+        json: { type: 'boolean' as const, default: false, describe: 'Output JSON' },
+      })
+      .command('schema', 'Validate, push and pull the FGA schema', (yargs) =>
+        yargs
+          .command(
+            'validate <file>',
+            'Check a schema file locally (exits 1 on errors)',
+            (yargs) => yargs.positional('file', { type: 'string', demandOption: true }),
+            async (argv) => {
+              const { runFgaSchemaValidate } = await import('./commands/fga.js');
+              runFgaSchemaValidate(argv.file, { json: argv.json });
+            },
+          )
+          .command(
+            'push <file>',
+            'Validate, diff against the deployed schema and apply',
+            (yargs) =>
+              yargs
+                .positional('file', { type: 'string', demandOption: true })
+                .options({
+                  yes: { alias: 'y', type: 'boolean', default: false, describe: 'Apply without confirming' },
+                }),
+            async (argv) => {
+              const { runFgaSchemaPush } = await import('./commands/fga.js');
+              await runFgaSchemaPush(argv.file, { ...(await fgaContext(argv)), yes: argv.yes });
+            },
+          )
+          .command(
+            'pull',
+            'Export the deployed schema',
+            (yargs) => yargs.options({ output: { alias: 'o', type: 'string', describe: 'Write to this file' } }),
+            async (argv) => {
+              const { runFgaSchemaPull } = await import('./commands/fga.js');
+              await runFgaSchemaPull({ ...(await fgaContext(argv)), output: argv.output });
+            },
+          )
+          .demandCommand(1, 'Please specify a schema subcommand')
+          .strict(),
+      )
+      .command(
+        'check <subject> <relation> <resource>',
+        'Check a relation, e.g. user:123 viewer document:456 (exits 1 if not authorized)',
+        (yargs) =>
+          yargs
+            .positional('subject', { type: 'string', demandOption: true })
+            .positional('relation', { type: 'string', demandOption: true })
+            .positional('resource', { type: 'string', demandOption: true }),
+        async (argv) => {
+          const { runFgaCheck } = await import('./commands/fga.js');
+          await runFgaCheck(argv.subject, argv.relation, argv.resource, await fgaContext(argv));
+        },
+      )
+      .command(
+        'query <query>',
+        'List accessible objects, e.g. "select document where user:123 is viewer"',
+        (yargs) =>
+          yargs
+            .positional('query', { type: 'string', demandOption: true })
+            .options({ limit: { type: 'number', default: 1000, describe: 'Maximum results' } }),
+        async (argv) => {
+          const { runFgaQuery } = await import('./commands/fga.js');
+          await runFgaQuery(argv.query, { ...(await fgaContext(argv)), limit: argv.limit });
+        },
+      )
+      .command('warrant', 'Create or delete warrants', (yargs) =>
+        yargs
+          .options({ file: { type: 'string', describe: 'JSON array, or one warrant tuple per line' } })
+          .command(
+            'write [warrants..]',
+            'Create warrants',
+            (yargs) =>
+              yargs.positional('warrants', {
+                type: 'string',
+                array: true,
+                describe: '<type>:<id>#<relation>@<type>:<id>',
+              }),
+            async (argv) => {
+              const { runFgaWarrants } = await import('./commands/fga.js');
+              const warrants = (argv.warrants as string[] | undefined) ?? [];
+              await runFgaWarrants('create', warrants, { ...(await fgaContext(argv)), file: argv.file });
+            },
+          )
+          .command(
+            'delete [warrants..]',
+            'Delete warrants',
+            (yargs) =>
+              yargs.positional('warrants', {
+                type: 'string',
+                array: true,
+                describe: '<type>:<id>#<relation>@<type>:<id>',
+              }),
+            async (argv) => {
+              const { confirmDestructive } = await import('./lib/environment-mode.js');
+              const { runFgaWarrants } = await import('./commands/fga.js');
+              const warrants = (argv.warrants as string[] | undefined) ?? [];
+              const context = await fgaContext(argv);
+              await confirmDestructive('Delete FGA warrants', context);
+              await runFgaWarrants('delete', warrants, { ...context, file: argv.file });
+            },
+          )
+          .demandCommand(1, 'Please specify a warrant subcommand')
+          .strict(),
+      )
+      .demandCommand(1, 'Please specify an fga subcommand')
+      .strict();
+  })
+  .command('email-templates', 'Pull, push and preview email templates', (yargs) => {
+    const templatesContext = async (argv: { apiKey?: string; insecureStorage?: boolean }) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+      return { apiKey: resolveApiKey({ apiKey: argv.apiKey }), baseUrl: resolveApiBaseUrl() };
+    };
+    return yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+        dir: { type: 'string' as const, default: 'email-templates', describe: 'Directory of <type>.html files' },
+        template: { type: 'string' as const, describe: 'Only this template, e.g. invitation or magic-link' },
+      })
+      .command(
+        'pull',
+        'Export the deployed templates to local files',
+        (yargs) => yargs,
+        async (argv) => {
+          const { runEmailTemplatesPull } = await import('./commands/email-templates.js');
+          await runEmailTemplatesPull({ ...(await templatesContext(argv)), dir: argv.dir, template: argv.template });
+        },
+      )
+      .command(
+        'push',
+        'Check variables, diff against the deployed templates and upload',
+        (yargs) =>
+          yargs.options({
+            yes: { alias: 'y', type: 'boolean', default: false, describe: 'Apply without confirming' },
+            'allow-unknown-variables': {
+              type: 'boolean',
+              default: false,
+              describe: 'Upload templates that use variables WorkOS does not provide',
+            },
+          }),
+        async (argv) => {
+          const { runEmailTemplatesPush } = await import('./commands/email-templates.js');
+          await runEmailTemplatesPush({
+            ...(await templatesContext(argv)),
+            dir: argv.dir,
+            template: argv.template,
+            yes: argv.yes,
+            allowUnknownVariables: argv.allowUnknownVariables,
+          });
+        },
+      )
+      .command(
+        'preview',
+        'Render a local template with sample variables',
+        (yargs) =>
+          yargs.options({
+            template: { type: 'string', demandOption: true, describe: 'e.g. invitation or magic-link' },
+            'to-file': { alias: 'o', type: 'string', describe: 'Write the HTML here instead of stdout' },
+          }),
+        async (argv) => {
+          const { runEmailTemplatesPreview } = await import('./commands/email-templates.js');
+          runEmailTemplatesPreview({ dir: argv.dir, template: argv.template, toFile: argv.toFile });
+        },
+      )
+      .demandCommand(1, 'Please specify an email-templates subcommand')
+      .strict();
+  })
+  .command('widgets', 'Generate widget tokens for local testing', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'token',
+        'Generate a widget token for a user in an organization',
+        (yargs) =>
+          yargs.options({
+            user: { type: 'string', demandOption: true, describe: 'User ID' },
+            org: { type: 'string', demandOption: true, describe: 'Organization ID' },
+            scopes: {
+              type: 'string',
+              array: true,
+              default: ['widgets:users-table:manage'],
+              describe: 'Widget scopes (space- or comma-separated)',
+            },
+            json: { type: 'boolean', default: false, describe: 'Print the token and expiry as JSON' },
+            serve: { type: 'boolean', default: false, describe: 'Serve a local page rendering the widget' },
+            port: { type: 'number', default: 5174, describe: 'Port for --serve' },
+            open: { type: 'boolean', default: true, describe: 'Open the --serve page in a browser' },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runWidgetsToken } = await import('./commands/widgets.js');
+          const apiKey = resolveApiKey({ apiKey: argv.apiKey });
+          await runWidgetsToken({
+            user: argv.user,
+            org: argv.org,
+            scopes: argv.scopes,
+            json: argv.json,
+            serve: argv.serve,
+            port: argv.port,
+            open: argv.open,
+            apiKey,
+            baseUrl: resolveApiBaseUrl(),
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify a widgets subcommand')
+      .strict(),
+  )
+  .command('session', 'Inspect AuthKit sessions', (yargs) =>
+    yargs
+      .command(
+        'decode',
+        'Unseal a session cookie read from stdin',
+        (yargs) =>
+          yargs.options({
+            'cookie-password': { type: 'string', describe: 'Cookie password (defaults to WORKOS_COOKIE_PASSWORD)' },
+            json: { type: 'boolean', default: false, describe: 'Output as JSON' },
+          }),
+        async (argv) => {
+          const { runSessionDecode } = await import('./commands/session.js');
+          await runSessionDecode({ cookiePassword: argv.cookiePassword, json: argv.json });
+        },
+      )
+      .demandCommand(1, 'Please specify a session subcommand')
+      .strict(),
+  )
+  .command('events', 'Explore the Events API', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'list',
+        'List events, or follow new ones with --follow',
+        (yargs) =>
+          yargs.options({
+            types: { type: 'string', array: true, describe: 'Event types, e.g. dsync.user.updated (comma-separated)' },
+            range: { type: 'string', describe: 'Lookback (30m, 24h, 7d) or <start>..<end> ISO dates' },
+            org: { type: 'string', describe: 'Filter by organization ID' },
+            limit: { type: 'number', describe: 'Limit number of results' },
+            after: { type: 'string', describe: 'Cursor for results after a specific event' },
+            all: { type: 'boolean', default: false, describe: 'Fetch every page (--limit caps the total)' },
+            json: { type: 'boolean', default: false, describe: 'Output as JSON (JSON lines with --all)' },
+            follow: { type: 'boolean', default: false, describe: 'Poll for new events and stream them as JSON lines' },
+            interval: { type: 'number', default: 5, describe: 'Seconds between polls with --follow' },
+            'replay-to': { type: 'string', describe: 'Re-post events to this URL, signed like webhooks' },
+            'webhook-secret': {
+              type: 'string',
+              describe: 'Secret for signing replays (defaults to WORKOS_WEBHOOK_SECRET)',
+            },
+          }),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runEventsList } = await import('./commands/events.js');
+          await runEventsList({
+            types: argv.types,
+            range: argv.range,
+            org: argv.org,
+            limit: argv.limit,
+            after: argv.after,
+            all: argv.all,
+            json: argv.json,
+            follow: argv.follow,
+            interval: argv.interval,
+            replayTo: argv.replayTo,
+            webhookSecret: argv.webhookSecret,
+            apiKey: resolveApiKey({ apiKey: argv.apiKey }),
+            baseUrl: resolveApiBaseUrl(),
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify an events subcommand')
+      .strict(),
+  )
+  .command('logs', 'Query API request logs', (yargs) =>
+    yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'requests',
+        'List recent API requests, or follow new ones with --follow',
+        (yargs) =>
+          yargs
+            .options({
+              since: { type: 'string', describe: 'Lookback (30m, 1h, 7d) or ISO date (default 1h)' },
+              status: { type: 'string', describe: 'Status filter, e.g. 4xx, 404 or 500-599 (comma-separated)' },
+              path: { type: 'string', describe: 'Path filter, * matches anything, e.g. /user_management/*' },
+              limit: { type: 'number', default: 50, describe: 'Maximum number of requests to list' },
+              json: { type: 'boolean', default: false, describe: 'Output JSON lines' },
+              follow: { type: 'boolean', default: false, describe: 'Poll for new requests' },
+              interval: { type: 'number', default: 5, describe: 'Seconds between polls with --follow' },
+            })
+            .command(
+              'get <request-id>',
+              'Show the metadata of one request',
+              (yargs) =>
+                yargs
+                  .positional('request-id', { type: 'string', demandOption: true, describe: 'Request ID' })
+                  .options({
+                    'include-body': {
+                      type: 'boolean',
+                      default: false,
+                      describe: 'Show request and response bodies when the API recorded them',
+                    },
+                  }),
+              async (argv) => {
+                await applyInsecureStorage(argv.insecureStorage);
+                const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+                const { runLogsRequestsGet } = await import('./commands/logs.js');
+                await runLogsRequestsGet(
+                  argv.requestId,
+                  { includeBody: argv.includeBody, json: argv.json },
+                  resolveApiKey({ apiKey: argv.apiKey }),
+                  resolveApiBaseUrl(),
+                );
+              },
+            ),
+        async (argv) => {
+          await applyInsecureStorage(argv.insecureStorage);
+          const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+          const { runLogsRequests } = await import('./commands/logs.js');
+          await runLogsRequests({
+            since: argv.since,
+            status: argv.status,
+            path: argv.path,
+            limit: argv.limit,
+            json: argv.json,
+            follow: argv.follow,
+            interval: argv.interval,
+            apiKey: resolveApiKey({ apiKey: argv.apiKey }),
+            baseUrl: resolveApiBaseUrl(),
+          });
+        },
+      )
+      .demandCommand(1, 'Please specify a logs subcommand')
+      .strict(),
+  )
+  .command('auth', 'Trigger email-based auth flows for testing and manage CI tokens', (yargs) => {
+    const authContext = async (argv: { apiKey?: string; insecureStorage?: boolean }) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+      return { apiKey: resolveApiKey({ apiKey: argv.apiKey }), baseUrl: resolveApiBaseUrl() };
+    };
+    return yargs
+      .options({
+        ...insecureStorageOption,
+        'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+      })
+      .command(
+        'send-magic-link <email>',
+        'Send a Magic Auth code to a user',
+        (yargs) =>
+          yargs.positional('email', { type: 'string', demandOption: true, describe: 'User email' }).options({
+            capture: {
+              type: 'boolean',
+              default: false,
+              describe: 'Print the generated code or link so no real inbox is needed',
+            },
+          }),
+        async (argv) => {
+          const { runSendMagicLink } = await import('./commands/auth.js');
+          await runSendMagicLink({ email: argv.email, capture: argv.capture, ...(await authContext(argv)) });
+        },
+      )
+      .command(
+        'send-password-reset <email>',
+        'Send a password reset email to a user',
+        (yargs) =>
+          yargs.positional('email', { type: 'string', demandOption: true, describe: 'User email' }).options({
+            capture: {
+              type: 'boolean',
+              default: false,
+              describe: 'Print the generated code or link so no real inbox is needed',
+            },
+          }),
+        async (argv) => {
+          const { runSendPasswordReset } = await import('./commands/auth.js');
+          await runSendPasswordReset({ email: argv.email, capture: argv.capture, ...(await authContext(argv)) });
+        },
+      )
+      .command(
+        'create-ci-token',
+        'Mint a scoped, long-lived token for pipelines to pass as WORKOS_CLI_TOKEN',
+        (yargs) =>
+          yargs.options({
+            name: { type: 'string', demandOption: true, describe: 'Name to tell the token apart, e.g. github-actions' },
+            scopes: {
+              type: 'string',
+              demandOption: true,
+              describe: 'Comma-separated, e.g. env:read,redirect_uris:write (see the README for the full list)',
+            },
+            json: { type: 'boolean', default: false, describe: 'Output as JSON' },
+          }),
+        async (argv) => {
+          const { runCiTokenCreate } = await import('./commands/ci-tokens.js');
+          const { apiKey, baseUrl } = await authContext(argv);
+          await runCiTokenCreate({ name: argv.name, scopes: argv.scopes, json: argv.json }, apiKey, baseUrl);
+        },
+      )
+      .command('ci-tokens', 'List and revoke CI tokens', (yargs) =>
+        yargs
+          .command(
+            'list',
+            "List the environment's CI tokens",
+            (yargs) => yargs.options({ json: { type: 'boolean', default: false, describe: 'Output as JSON' } }),
+            async (argv) => {
+              const { runCiTokensList } = await import('./commands/ci-tokens.js');
+              const { apiKey, baseUrl } = await authContext(argv);
+              await runCiTokensList({ json: argv.json }, apiKey, baseUrl);
+            },
+          )
+          .command(
+            'revoke <id>',
+            'Revoke a CI token',
+            (yargs) => yargs.positional('id', { type: 'string', demandOption: true, describe: 'CI token ID' }),
+            async (argv) => {
+              const { confirmDestructive } = await import('./lib/environment-mode.js');
+              const { runCiTokenRevoke } = await import('./commands/ci-tokens.js');
+              const context = await authContext(argv);
+              await confirmDestructive(`Revoke CI token ${argv.id}`, context);
+              await runCiTokenRevoke(argv.id, context.apiKey, context.baseUrl);
+            },
+          )
+          .demandCommand(1, 'Please specify a ci-tokens subcommand')
+          .strict(),
+      )
+      .demandCommand(1, 'Please specify an auth subcommand')
+      .strict();
+  })
+  .command(
+    'dashboard [page] [record]',
+    "Open the WorkOS dashboard on a page of the API key's environment",
+    (yargs) =>
+      yargs
+        .positional('page', {
+          type: 'string',
+          describe: 'users, org, redirect-uris or api-keys (the home page when omitted)',
+        })
+        .positional('record', { type: 'string', describe: 'User ID or email, or organization ID or name' })
+        .options({
+          ...insecureStorageOption,
+          'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+          open: { type: 'boolean', default: true, describe: 'Open the URL in the browser (--no-open prints it)' },
+        }),
+    async (argv) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+      const { runDashboard } = await import('./commands/dashboard.js');
+      await runDashboard({
+        page: argv.page,
+        record: argv.record,
+        open: argv.open,
+        apiKey: resolveApiKey({ apiKey: argv.apiKey }),
+        baseUrl: resolveApiBaseUrl(),
+      });
+    },
+  )
+  .command(
+    'impersonate <user>',
+    'Get a one-time URL to sign in as a user',
+    (yargs) =>
+      yargs
+        .positional('user', { type: 'string', demandOption: true, describe: 'User ID or email' })
+        .options({
+          ...insecureStorageOption,
+          'api-key': { type: 'string' as const, describe: 'WorkOS API key (overrides environment config)' },
+          reason: {
+            type: 'string',
+            demandOption: true,
+            describe: 'Why you are impersonating (recorded in the audit log)',
+          },
+          production: { type: 'boolean', default: false, describe: 'Allow impersonating in a production environment' },
+          open: { type: 'boolean', default: false, describe: 'Open the URL in the default browser' },
+          'browser-profile': { type: 'string', describe: 'Open the URL in this Chrome profile (e.g. "Profile 2")' },
+        }),
+    async (argv) => {
+      await applyInsecureStorage(argv.insecureStorage);
+      const { resolveApiKey, resolveApiBaseUrl } = await import('./lib/api-key.js');
+      const { runImpersonate } = await import('./commands/impersonate.js');
+      await runImpersonate({
+        user: argv.user,
+        reason: argv.reason,
+        production: argv.production,
+        open: argv.open,
+        browserProfile: argv.browserProfile,
+        apiKey: resolveApiKey({ apiKey: argv.apiKey }),
+        baseUrl: resolveApiBaseUrl(),
+      });
+    },
+  )
+  .command(
+    'install',
+    'Install WorkOS AuthKit into your project',
+    (yargs) =>
+      yargs.options({
+        ...installerOptions,
+        machine: {
+          type: 'boolean' as const,
+          conflicts: 'events',
+          describe: 'Drive the installer from another program: JSON messages on stdout, prompt answers on stdin',
+        },
+      }),
+    withMachineMode(
+      withAuth(async (argv) => {
+        const { handleInstall } = await import('./commands/install.js');
+        await handleInstall(argv);
+      }),
+    ),
+  )
+  .command(
+    'init [name]',
+    'Create a new app from an AuthKit starter template',
+    (yargs) =>
+      yargs
+        .positional('name', { type: 'string', describe: 'Project directory name' })
+        .options({
+          ...insecureStorageOption,
+          template: {
+            alias: 't',
+            type: 'string',
+            describe: 'Starter template (see --list; default nextjs-authkit)',
+          },
+          framework: {
+            alias: 'f',
+            type: 'string',
+            describe: 'Framework to start from (next, remix, express, gin, flask); picks the template',
+          },
+          force: {
+            type: 'boolean',
+            default: false,
+            describe: 'Scaffold into a non-empty directory, overwriting files the template also has',
+          },
+          list: {
+            alias: 'l',
+            type: 'boolean',
+            default: false,
+            describe: 'List available templates',
+          },
+        }),
+    async (argv) => {
+      const { runInit } = await import('./commands/init.js');
+      if (argv.list) {
+        await runInit({ list: true });
+        return;
+      }
+      await withAuth(() =>
+        runInit({ name: argv.name, template: argv.template, framework: argv.framework, force: argv.force }),
+      )(argv);
+    },
+  )
+  .command(
+    'migrate',
+    'Migrate from another auth provider to WorkOS AuthKit',
+    (yargs) =>
+      yargs
+        .command(
+          'assess',
+          'Report how much work a migration would be, without changing anything',
+          (yargs) =>
+            yargs.options({
+              'install-dir': {
+                type: 'string',
+                default: process.cwd(),
+                description: 'Project directory to assess',
+              },
+              json: {
+                type: 'boolean',
+                default: false,
+                description: 'Print the assessment as JSON (the reports are written either way)',
+              },
+              'min-confidence': minConfidenceOption,
+              ...scanPathOptions,
+              cache: discoveryCacheOption,
+              'assume-provider-version': {
+                type: 'string',
+                array: true,
+                description: 'Provider SDK major when the manifest does not pin it, e.g. go-oidc@2 (repeatable)',
+              },
+              'report-path': {
+                type: 'string',
+                description: 'Where to write the markdown report, a .md file or a directory; the JSON goes next to it',
+              },
+            }),
+          async (argv) => {
+            const { runMigrateAssess } = await import('./commands/migrate-assess.js');
+            await runMigrateAssess({
+              installDir: argv.installDir,
+              json: argv.json,
+              minConfidence: argv.minConfidence,
+              include: argv.include,
+              exclude: argv.exclude,
+              cache: argv.cache,
+              maxFileSize: argv.maxFileSize,
+              scanTerraform: argv.scanTerraform,
+              assumeProviderVersion: argv.assumeProviderVersion,
+              reportPath: argv.reportPath,
+            });
+          },
+        )
+        .options({
+          ...installerOptions,
+          provider: {
+            type: 'string' as const,
+            describe: 'Force the provider to migrate from (skips auto-detection)',
+          },
+          'min-confidence': minConfidenceOption,
+          ...scanPathOptions,
+          cache: discoveryCacheOption,
+          modules: {
+            type: 'string' as const,
+            array: true,
+            describe: 'Migrate several project directories at once, each in its own process (repeatable)',
+          },
+          'agent-concurrency': {
+            type: 'number' as const,
+            describe: 'With --modules, how many modules (each with its own AI agent) run at once (default 3, max 8)',
+          },
+          'templates-dir': {
+            type: 'string' as const,
+            describe: 'Directory of <provider>/<framework>/<file> templates replacing the code the migration writes',
+          },
+          'assume-provider-version': {
+            type: 'string' as const,
+            array: true,
+            describe: 'Provider SDK major to migrate from when the manifest does not pin it, e.g. go-oidc@2 (repeatable)',
+          },
+          only: {
+            type: 'string' as const,
+            array: true,
+            describe: 'Apply only these changes: code, env, deps, templates (repeatable or comma-separated)',
+          },
+          repo: {
+            type: 'string' as const,
+            describe: 'Migrate a shallow clone of this git URL (needs --push or --diff-only; removed afterwards)',
+          },
+          branch: {
+            type: 'string' as const,
+            describe: "With --repo, the branch to check out and migrate from (default: the remote's default branch)",
+          },
+          push: {
+            type: 'boolean' as const,
+            default: false,
+            describe: 'Commit the migration and push its branch without asking (no pull request)',
+          },
+        }),
+    withAuth(async (argv) => {
+      const { handleMigrate } = await import('./commands/migrate.js');
+      await handleMigrate(argv);
     }),
   )
   .command(
@@ -509,6 +2659,6 @@ yargs(hideBin(process.argv))
   .strict()
   .help()
   .alias('help', 'h')
-  .version(getVersion())
+  .version(formatVersion())
   .alias('version', 'v')
   .wrap(process.stdout.isTTY && process.stdout.columns ? process.stdout.columns : 80).argv;
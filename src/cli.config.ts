@@ -24,6 +24,19 @@ export const config = {
     refreshThresholdMs: 60_000,
   },
 
+  http: {
+    // Retries for 5xx responses, timeouts and connection resets (override with --retries)
+    retries: 3,
+    // Per-attempt request timeout (override with --http-timeout or WORKOS_CLI_HTTP_TIMEOUT)
+    timeoutMs: 30_000,
+    commandTimeoutsMs: {
+      export: 180_000,
+      'env clone': 120_000,
+      'env diff': 120_000,
+      'logs requests': 120_000,
+    },
+  },
+
   nodeVersion: '>=20.20',
 
   logging: {
@@ -57,6 +70,18 @@ export const config = {
       port: 5173,
       callbackPath: '/callback',
     },
+    go: {
+      port: 8080,
+      callbackPath: '/auth/callback',
+    },
+    pythonDjango: {
+      port: 8000,
+      callbackPath: '/auth/callback',
+    },
+    rubyRails: {
+      port: 3000,
+      callbackPath: '/auth/callback',
+    },
   },
 
   legacy: {
@@ -0,0 +1,183 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const mockConfirm = vi.fn();
+vi.mock('../lib/environment-mode.js', () => ({
+  confirmDestructive: (action: string, api: unknown) => mockConfirm(action, api),
+}));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runAuditLogsSchemaPull, runAuditLogsSchemaPush, runAuditLogsExporterSet } = await import('./audit-logs.js');
+
+/** `user.signed_in` as the API returns it */
+const signedIn = {
+  name: 'user.signed_in',
+  schema: {
+    version: 2,
+    targets: [{ type: 'user' }],
+    actor: { metadata: { type: 'object', properties: { role: { type: 'string' } } } },
+    metadata: { type: 'object', properties: { ip_country: { type: 'string' } } },
+  },
+};
+
+const SCHEMA_FILE = `actions:
+  - name: user.signed_in
+    targets:
+      - type: user
+    actor:
+      metadata:
+        role: string
+    metadata:
+      ip_country: string
+  - name: document.shared
+    targets:
+      - type: document
+`;
+
+/** The file without `user.signed_in`, so pushing it removes that action */
+const WITHOUT_SIGNED_IN = `actions:
+  - name: document.shared
+    targets:
+      - type: document
+`;
+
+describe('audit-logs commands', () => {
+  let dir: string;
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    mockConfirm.mockReset();
+    dir = mkdtempSync(join(tmpdir(), 'audit-logs-'));
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('runAuditLogsSchemaPull', () => {
+    it('writes the deployed actions as a schema file', async () => {
+      mockRequest.mockResolvedValue({ data: [signedIn], list_metadata: {} });
+      await runAuditLogsSchemaPull(join(dir, 'audit-logs.yaml'), { apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({ path: '/audit_logs/actions' }));
+      expect(readFileSync(join(dir, 'audit-logs.yaml'), 'utf-8')).toContain('  - name: user.signed_in');
+      expect(consoleOutput.join('\n')).toContain('1 action(s) written');
+    });
+  });
+
+  describe('runAuditLogsSchemaPush', () => {
+    it('shows the plan without publishing on --dry-run', async () => {
+      writeFileSync(join(dir, 'audit-logs.yaml'), SCHEMA_FILE);
+      mockRequest.mockResolvedValue({ data: [signedIn], list_metadata: {} });
+
+      await runAuditLogsSchemaPush(join(dir, 'audit-logs.yaml'), { apiKey: 'sk_test', dryRun: true });
+
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(consoleOutput.join('\n')).toContain('+ document.shared');
+      expect(consoleOutput.join('\n')).toContain('1 to create, 0 to update, 0 to remove, 1 unchanged');
+    });
+
+    it('publishes a schema version for each new action', async () => {
+      writeFileSync(join(dir, 'audit-logs.yaml'), SCHEMA_FILE);
+      mockRequest.mockResolvedValueOnce({ data: [signedIn], list_metadata: {} }).mockResolvedValue(null);
+
+      await runAuditLogsSchemaPush(join(dir, 'audit-logs.yaml'), { apiKey: 'sk_test' });
+
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ method: 'POST', path: '/audit_logs/actions/document.shared/schemas' }),
+      );
+      expect(mockConfirm).not.toHaveBeenCalled();
+      expect(consoleOutput).toContain('Audit log schemas pushed.');
+    });
+
+    it('refuses to remove actions without --allow-destructive', async () => {
+      writeFileSync(join(dir, 'audit-logs.yaml'), WITHOUT_SIGNED_IN);
+      mockRequest.mockResolvedValue({ data: [signedIn], list_metadata: {} });
+
+      await expect(runAuditLogsSchemaPush(join(dir, 'audit-logs.yaml'), { apiKey: 'sk_test' })).rejects.toThrow(
+        'process.exit',
+      );
+
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(errors.join('\n')).toContain('Refusing to remove actions that may have recorded events: user.signed_in.');
+    });
+
+    it('confirms before removing with --allow-destructive', async () => {
+      writeFileSync(join(dir, 'audit-logs.yaml'), WITHOUT_SIGNED_IN);
+      mockRequest.mockResolvedValueOnce({ data: [signedIn], list_metadata: {} }).mockResolvedValue(null);
+
+      await runAuditLogsSchemaPush(join(dir, 'audit-logs.yaml'), { apiKey: 'sk_test', allowDestructive: true });
+
+      expect(mockConfirm).toHaveBeenCalledWith('Remove 1 audit log action(s) not in audit-logs.yaml', {
+        apiKey: 'sk_test',
+        baseUrl: undefined,
+      });
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ method: 'DELETE', path: '/audit_logs/actions/user.signed_in' }),
+      );
+    });
+  });
+
+  describe('runAuditLogsExporterSet', () => {
+    it("sets the organization's stream and masks secrets in the output", async () => {
+      writeFileSync(join(dir, 'exporter.yaml'), 'region: us1\napi_key: dd_secret_123\n');
+      mockRequest.mockResolvedValue(null);
+
+      await runAuditLogsExporterSet({
+        type: 'datadog',
+        config: join(dir, 'exporter.yaml'),
+        organizationId: 'org_123',
+        apiKey: 'sk_test',
+      });
+
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'PUT',
+          path: '/organizations/org_123/audit_log_stream',
+          body: { type: 'datadog', configuration: { region: 'us1', api_key: 'dd_secret_123' } },
+        }),
+      );
+      expect(consoleOutput.join('\n')).toContain('region: us1');
+      expect(consoleOutput.join('\n')).not.toContain('dd_secret_123');
+    });
+
+    it('rejects an unknown exporter type', async () => {
+      const options = { type: 'syslog', config: 'exporter.yaml', organizationId: 'org_123', apiKey: 'sk_test' };
+      await expect(runAuditLogsExporterSet(options)).rejects.toThrow('process.exit');
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('Unknown exporter type "syslog"');
+    });
+  });
+});
@@ -0,0 +1,173 @@
+import chalk from 'chalk';
+import { readFileSync } from 'node:fs';
+import { basename, resolve } from 'node:path';
+import { handleApiError } from '../lib/api-error.js';
+import { writeFileAtomic } from '../lib/atomic-write.js';
+import {
+  applySchemaPlan,
+  describeExporter,
+  EXPORTER_TYPES,
+  formatFileErrors,
+  formatSchemaYaml,
+  listAuditLogActions,
+  parseExporterFile,
+  parseSchemaFile,
+  planSchemaPush,
+  setAuditLogStream,
+  type AuditLogsPlan,
+  type DeployedAction,
+  type ExporterType,
+  type FieldChange,
+} from '../lib/audit-logs.js';
+import { confirmDestructive } from '../lib/environment-mode.js';
+
+interface ApiContext {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+function readFile(file: string): string {
+  try {
+    return readFileSync(resolve(file), 'utf-8');
+  } catch {
+    console.error(chalk.red(`Could not read ${file}`));
+    process.exit(1);
+  }
+}
+
+/**
+ * Write the deployed actions as a schema file, or print it.
+ */
+export async function runAuditLogsSchemaPull(file: string | undefined, api: ApiContext): Promise<void> {
+  let deployed: DeployedAction[];
+  try {
+    deployed = await listAuditLogActions(api);
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  const yaml = formatSchemaYaml(deployed.map((action) => action.definition));
+  if (!file) {
+    process.stdout.write(yaml);
+    return;
+  }
+  writeFileAtomic(resolve(file), yaml);
+  console.log(chalk.green(`${deployed.length} action(s) written to ${file}`));
+}
+
+function describeField(change: FieldChange): string {
+  const where = `${change.where}.${change.key}`;
+  if (!change.from) return chalk.green(`    + ${where}: ${change.to}`);
+  if (!change.to) return chalk.red(`    - ${where}: ${change.from}`);
+  return `    ${where}: ${change.from} → ${change.to}`;
+}
+
+export function formatSchemaPlan(plan: AuditLogsPlan): string {
+  const lines: string[] = [];
+  for (const action of plan.create) {
+    lines.push(chalk.green(`+ ${action.name}`));
+    lines.push(chalk.green(`    targets: ${action.targets.map((t) => t.type).join(', ')}`));
+  }
+  for (const { change } of plan.update) {
+    lines.push(chalk.yellow(`~ ${change.name}`));
+    for (const type of change.addedTargets) lines.push(chalk.green(`    + target ${type}`));
+    for (const type of change.removedTargets) lines.push(chalk.red(`    - target ${type}`));
+    lines.push(...change.fields.map(describeField));
+  }
+  for (const action of plan.remove) {
+    lines.push(chalk.red(`- ${action.definition.name} (schema version ${action.version})`));
+  }
+
+  if (lines.length === 0) return 'Audit log schemas are in sync.';
+  const counts = `${plan.create.length} to create, ${plan.update.length} to update, ${plan.remove.length} to remove`;
+  return [...lines, '', chalk.dim(`${counts}, ${plan.unchanged.length} unchanged`)].join('\n');
+}
+
+export interface SchemaPushOptions extends ApiContext {
+  dryRun?: boolean;
+  /** Remove actions that aren't in the file */
+  allowDestructive?: boolean;
+}
+
+/**
+ * Validate a schema file, show how it differs from the environment and
+ * publish the changes.
+ */
+export async function runAuditLogsSchemaPush(file: string, options: SchemaPushOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  const { actions, errors } = parseSchemaFile(readFile(file), basename(file));
+  if (errors.length > 0) {
+    console.error(chalk.red(formatFileErrors(file, errors)));
+    process.exit(1);
+  }
+
+  let plan: AuditLogsPlan;
+  try {
+    plan = planSchemaPush(actions, await listAuditLogActions(api));
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  console.log(formatSchemaPlan(plan));
+
+  // Events already recorded under an action keep its name, and the API doesn't
+  // say whether there are any, so every removal counts as destructive
+  if (plan.remove.length > 0 && !options.allowDestructive) {
+    const names = plan.remove.map((action) => action.definition.name);
+    console.error(
+      chalk.red(`Refusing to remove actions that may have recorded events: ${names.join(', ')}.`) +
+        ' Add them back to the file, or pass --allow-destructive.',
+    );
+    process.exit(1);
+  }
+
+  if (options.dryRun || plan.create.length + plan.update.length + plan.remove.length === 0) return;
+  if (plan.remove.length > 0) {
+    await confirmDestructive(`Remove ${plan.remove.length} audit log action(s) not in ${basename(file)}`, api);
+  }
+
+  let applied = 0;
+  try {
+    await applySchemaPlan(plan, api, () => applied++);
+  } catch (error) {
+    if (applied > 0) console.error(`${applied} change(s) were applied; push again to apply the rest.`);
+    handleApiError(error);
+  }
+  console.log(chalk.green('Audit log schemas pushed.'));
+}
+
+export interface ExporterSetOptions extends ApiContext {
+  type: string;
+  config: string;
+  organizationId: string;
+  dryRun?: boolean;
+}
+
+/**
+ * Point an organization's audit log stream at an exporter configured in a file.
+ */
+export async function runAuditLogsExporterSet(options: ExporterSetOptions): Promise<void> {
+  if (!EXPORTER_TYPES.includes(options.type as ExporterType)) {
+    console.error(chalk.red(`Unknown exporter type "${options.type}". Use one of ${EXPORTER_TYPES.join(', ')}.`));
+    process.exit(1);
+  }
+  const type = options.type as ExporterType;
+  const { config, errors } = parseExporterFile(readFile(options.config), basename(options.config), type);
+  if (errors.length > 0) {
+    console.error(chalk.red(formatFileErrors(options.config, errors)));
+    process.exit(1);
+  }
+
+  const settings = describeExporter(type, config).map((line) => `  ${line}`);
+  if (options.dryRun) {
+    console.log([`${options.config} is a valid ${type} exporter:`, ...settings].join('\n'));
+    return;
+  }
+
+  try {
+    await setAuditLogStream(options.organizationId, type, config, options);
+  } catch (error) {
+    handleApiError(error);
+  }
+  console.log([chalk.green(`Audit log stream for ${options.organizationId} set to ${type}:`), ...settings].join('\n'));
+}
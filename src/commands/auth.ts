@@ -0,0 +1,73 @@
+import chalk from 'chalk';
+import {
+  captureMagicAuthCode,
+  capturePasswordResetUrl,
+  describeEmailFlowError,
+  sendMagicAuth,
+  sendPasswordReset,
+  type MagicAuth,
+  type PasswordReset,
+} from '../lib/email-flows.js';
+
+export interface AuthSendOptions {
+  email: string;
+  /** Print the generated code/link instead of relying on the inbox */
+  capture?: boolean;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+function fail(error: unknown): never {
+  console.error(chalk.red(describeEmailFlowError(error)));
+  process.exit(1);
+}
+
+function formatExpiry(expiresAt: string): string {
+  return chalk.dim(`Expires ${new Date(expiresAt).toLocaleString()}`);
+}
+
+/**
+ * Create a Magic Auth code for a user; with capture, print the code.
+ */
+export async function runSendMagicLink(options: AuthSendOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  let magicAuth: MagicAuth;
+  try {
+    magicAuth = await sendMagicAuth(options.email, api);
+  } catch (error) {
+    fail(error);
+  }
+
+  console.error(`Magic Auth code sent to ${chalk.bold(magicAuth.email)} ${chalk.dim(`(${magicAuth.id})`)}`);
+  console.error(formatExpiry(magicAuth.expires_at));
+  if (!options.capture) return;
+
+  try {
+    console.log(await captureMagicAuthCode(magicAuth, api));
+  } catch (error) {
+    fail(error);
+  }
+}
+
+/**
+ * Create a password reset for a user; with capture, print the reset link.
+ */
+export async function runSendPasswordReset(options: AuthSendOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  let reset: PasswordReset;
+  try {
+    reset = await sendPasswordReset(options.email, api);
+  } catch (error) {
+    fail(error);
+  }
+
+  console.error(`Password reset sent to ${chalk.bold(reset.email)} ${chalk.dim(`(${reset.id})`)}`);
+  console.error(formatExpiry(reset.expires_at));
+  if (!options.capture) return;
+
+  try {
+    console.log(await capturePasswordResetUrl(reset, api));
+  } catch (error) {
+    fail(error);
+  }
+}
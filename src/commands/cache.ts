@@ -0,0 +1,8 @@
+import chalk from 'chalk';
+import { cleanApiCache, getApiCacheDir } from '../lib/api-cache.js';
+
+export function runCacheClean(): void {
+  cleanApiCache();
+  console.log(chalk.green('Removed cached API lookups'));
+  console.log(chalk.dim(getApiCacheDir()));
+}
@@ -0,0 +1,71 @@
+import chalk from 'chalk';
+import { runAppCheck, type AppCheckReport } from '../lib/app-check.js';
+import { getActiveEnvironment } from '../lib/config-store.js';
+import { isQuietMode } from '../utils/clack.js';
+
+export interface CheckAppOptions {
+  url: string;
+  /** Defaults to WORKOS_CLIENT_ID, then the active environment's client ID */
+  clientId?: string;
+  loginPath?: string;
+  callbackPath?: string;
+  issuer?: string;
+  json?: boolean;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+function printReport(report: AppCheckReport): void {
+  // Quiet: only the failures, on stderr, and the result line
+  const quiet = isQuietMode();
+  if (!quiet) console.log(`Checking ${chalk.bold(report.url)} (${report.clientId})`);
+  for (const probe of report.probes) {
+    if (quiet) {
+      if (!probe.passed) console.error(`${probe.name}: ${probe.detail}`);
+      continue;
+    }
+    const mark = probe.passed ? chalk.green('✓') : chalk.red('✗');
+    console.log(`${mark} ${probe.name.padEnd(12)} ${probe.passed ? probe.detail : chalk.red(probe.detail)}`);
+  }
+  const failures = report.probes.filter((p) => !p.passed).length;
+  console.log(
+    failures === 0
+      ? chalk.green(`All ${report.probes.length} checks passed.`)
+      : chalk.red(`${failures} of ${report.probes.length} checks failed.`),
+  );
+}
+
+/**
+ * Smoke-test a deployed app's AuthKit integration. Exits 1 when a probe
+ * fails, so it can gate a deploy in CI.
+ */
+export async function runCheckApp(options: CheckAppOptions): Promise<void> {
+  const clientId = options.clientId ?? process.env.WORKOS_CLIENT_ID ?? getActiveEnvironment()?.clientId;
+  if (!clientId) {
+    console.error(chalk.red('No client ID configured. Pass --client-id or set WORKOS_CLIENT_ID.'));
+    process.exit(1);
+  }
+  try {
+    new URL(options.url);
+  } catch {
+    console.error(chalk.red(`Invalid --url "${options.url}". Pass the deployed app's URL, e.g. https://app.example.com`));
+    process.exit(1);
+  }
+
+  const report = await runAppCheck({
+    url: options.url,
+    clientId,
+    apiKey: options.apiKey,
+    baseUrl: options.baseUrl,
+    loginPath: options.loginPath,
+    callbackPath: options.callbackPath,
+    issuer: options.issuer,
+  });
+
+  if (options.json) {
+    console.log(JSON.stringify(report, null, 2));
+  } else {
+    printReport(report);
+  }
+  if (!report.passed) process.exit(1);
+}
@@ -0,0 +1,114 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest, WorkOSApiError } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runCiTokenCreate, runCiTokensList, runCiTokenRevoke } = await import('./ci-tokens.js');
+
+const token = {
+  id: 'ci_token_123',
+  name: 'deploy',
+  scopes: ['env:read', 'redirect_uris:write'],
+  created_at: '2026-03-01T12:00:00.000Z',
+  last_used_at: null,
+};
+
+describe('ci-tokens commands', () => {
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+  });
+
+  describe('runCiTokenCreate', () => {
+    it('creates the token and prints it once', async () => {
+      mockRequest.mockResolvedValue({ ...token, token: 'wos_ci_secret' });
+      await runCiTokenCreate({ name: 'deploy', scopes: 'env:read, redirect_uris:write' }, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/cli/ci_tokens',
+          body: { name: 'deploy', scopes: ['env:read', 'redirect_uris:write'] },
+        }),
+      );
+      expect(consoleOutput).toContain('Token:  wos_ci_secret');
+      expect(errors.join('\n')).toContain("pass it as WORKOS_CLI_TOKEN: it won't be shown again");
+    });
+
+    it('rejects unknown scopes before calling the API', async () => {
+      await expect(runCiTokenCreate({ name: 'deploy', scopes: 'env:write' }, 'sk_test')).rejects.toThrow(
+        'process.exit',
+      );
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('Unknown scope(s): env:write');
+    });
+
+    it('says a CI token cannot mint another', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Forbidden', 403));
+      await expect(runCiTokenCreate({ name: 'deploy', scopes: 'env:read' }, 'wos_ci_secret')).rejects.toThrow(
+        'process.exit',
+      );
+      expect(errors.join('\n')).toContain("Forbidden. CI tokens are managed with the environment's API key.");
+    });
+  });
+
+  describe('runCiTokensList', () => {
+    it('lists tokens with when they were last used', async () => {
+      mockRequest.mockResolvedValue({ data: [token], list_metadata: {} });
+      await runCiTokensList({}, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({ path: '/cli/ci_tokens' }));
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('env:read, redirect_uris:write');
+      expect(output).toContain('2026-03-01');
+      expect(output).toContain('never');
+    });
+  });
+
+  describe('runCiTokenRevoke', () => {
+    it('revokes the token', async () => {
+      mockRequest.mockResolvedValue(null);
+      await runCiTokenRevoke('ci_token_123', 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'DELETE', path: '/cli/ci_tokens/ci_token_123' }),
+      );
+      expect(consoleOutput.join('\n')).toContain('Revoked ci_token_123');
+    });
+
+    it('exits 1 for a token that does not exist', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Not Found', 404));
+      await expect(runCiTokenRevoke('ci_token_missing', 'sk_test')).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('No CI token with ID ci_token_missing.');
+    });
+  });
+});
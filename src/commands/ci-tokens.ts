@@ -0,0 +1,91 @@
+import chalk from 'chalk';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import {
+  CI_TOKEN_ENV,
+  createCiToken,
+  listCiTokens,
+  parseScopes,
+  revokeCiToken,
+  type CiToken,
+  type CreatedCiToken,
+} from '../lib/ci-tokens.js';
+import { WorkOSApiError } from '../lib/workos-api.js';
+import { formatTable } from '../utils/table.js';
+
+const API_ERRORS: ApiErrorMessages = {
+  // CI tokens can't manage other tokens
+  forbidden: (message) => `${message}. CI tokens are managed with the environment's API key.`,
+};
+
+export interface CiTokenCreateOptions {
+  name: string;
+  /** Comma-separated, e.g. env:read,redirect_uris:write */
+  scopes: string;
+  json?: boolean;
+}
+
+/**
+ * Mint a CI token for the environment the API key belongs to. The token is
+ * shown this once and not stored anywhere.
+ */
+export async function runCiTokenCreate(options: CiTokenCreateOptions, apiKey: string, baseUrl?: string): Promise<void> {
+  let created: CreatedCiToken;
+  try {
+    created = await createCiToken(options.name, parseScopes(options.scopes), { apiKey, baseUrl });
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  if (options.json) {
+    console.log(JSON.stringify(created, null, 2));
+  } else {
+    console.log(chalk.green(`Created CI token ${created.name}`));
+    console.log(`Scopes: ${created.scopes.join(', ')}`);
+    console.log(`Token:  ${created.token}`);
+  }
+  console.error(chalk.yellow(`Store the token as a secret and pass it as ${CI_TOKEN_ENV}: it won't be shown again.`));
+}
+
+export async function runCiTokensList(options: { json?: boolean }, apiKey: string, baseUrl?: string): Promise<void> {
+  let tokens: CiToken[];
+  try {
+    tokens = await listCiTokens({ apiKey, baseUrl });
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  if (options.json) {
+    console.log(JSON.stringify(tokens, null, 2));
+    return;
+  }
+  if (tokens.length === 0) {
+    console.log('No CI tokens found.');
+    return;
+  }
+  const rows = tokens.map((t) => [
+    t.name,
+    t.scopes.join(', '),
+    t.created_at.slice(0, 10),
+    t.last_used_at ? t.last_used_at.slice(0, 10) : chalk.dim('never'),
+    t.id,
+  ]);
+  console.log(
+    formatTable(
+      [{ header: 'Name' }, { header: 'Scopes' }, { header: 'Created' }, { header: 'Last used' }, { header: 'ID' }],
+      rows,
+    ),
+  );
+}
+
+export async function runCiTokenRevoke(id: string, apiKey: string, baseUrl?: string): Promise<void> {
+  try {
+    await revokeCiToken(id, { apiKey, baseUrl });
+  } catch (error) {
+    if (error instanceof WorkOSApiError && error.statusCode === 404) {
+      console.error(chalk.red(`No CI token with ID ${id}.`));
+      process.exit(1);
+    }
+    handleApiError(error, API_ERRORS);
+  }
+  console.log(chalk.green(`Revoked ${id}; pipelines using it can no longer authenticate.`));
+}
@@ -0,0 +1,25 @@
+import chalk from 'chalk';
+import { resolve } from 'node:path';
+import { BACKUP_SUFFIX, findBackups, removeBackups } from '../lib/file-backups.js';
+
+export interface CleanOptions {
+  installDir: string;
+  /** List the backups without removing them */
+  dryRun?: boolean;
+}
+
+/**
+ * `workos clean`: remove the `<file>.workos.bak` copies `--keep-backups` left.
+ */
+export function runClean(options: CleanOptions): void {
+  const installDir = resolve(options.installDir);
+  const backups = findBackups(installDir);
+  if (backups.length === 0) {
+    console.log(chalk.dim(`No ${BACKUP_SUFFIX} files in ${installDir}`));
+    return;
+  }
+  if (!options.dryRun) removeBackups(installDir, backups);
+  const verb = options.dryRun ? 'Would remove' : 'Removed';
+  console.log(chalk.green(`${verb} ${backups.length} backup(s)`));
+  for (const file of backups) console.log(chalk.dim(`  ${file}`));
+}
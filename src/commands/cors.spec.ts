@@ -0,0 +1,104 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest, WorkOSApiError } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runCorsList, runCorsAdd, runCorsRemove } = await import('./cors.js');
+
+const origins = [
+  { id: 'cors_1', origin: 'https://app.example.com' },
+  { id: 'cors_2', origin: 'https://*.preview.example.com' },
+];
+
+describe('cors commands', () => {
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+  });
+
+  describe('runCorsList', () => {
+    it('prints one origin per line', async () => {
+      mockRequest.mockResolvedValue({ data: origins, list_metadata: {} });
+      await runCorsList({}, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({ path: '/user_management/cors_origins' }));
+      expect(consoleOutput).toEqual(['https://app.example.com', 'https://*.preview.example.com']);
+    });
+  });
+
+  describe('runCorsAdd', () => {
+    it('allows the normalized origin', async () => {
+      mockRequest.mockResolvedValue({ id: 'cors_3', origin: 'https://admin.example.com' });
+      await runCorsAdd('HTTPS://Admin.Example.com/', 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/user_management/cors_origins',
+          body: { origin: 'https://admin.example.com' },
+        }),
+      );
+      expect(consoleOutput.join('\n')).toContain('Allowed https://admin.example.com');
+    });
+
+    it('succeeds when the origin is already allowed', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Origin already exists', 422));
+      await runCorsAdd('https://app.example.com', 'sk_test');
+      expect(consoleOutput).toContain('https://app.example.com is already allowed.');
+    });
+
+    it('rejects an origin with a path before calling the API', async () => {
+      await expect(runCorsAdd('https://app.example.com/login', 'sk_test')).rejects.toThrow('process.exit');
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('has a path, query or fragment');
+    });
+  });
+
+  describe('runCorsRemove', () => {
+    it('deletes the origin by its ID', async () => {
+      mockRequest.mockResolvedValueOnce({ data: origins, list_metadata: {} }).mockResolvedValueOnce(null);
+      await runCorsRemove('https://*.preview.example.com', 'sk_test');
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ method: 'DELETE', path: '/user_management/cors_origins/cors_2' }),
+      );
+      expect(consoleOutput.join('\n')).toContain('Removed https://*.preview.example.com');
+    });
+
+    it('exits 1 when the origin is not in the list', async () => {
+      mockRequest.mockResolvedValue({ data: origins, list_metadata: {} });
+      await expect(runCorsRemove('https://other.example.com', 'sk_test')).rejects.toThrow('process.exit');
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(errors.join('\n')).toContain('https://other.example.com is not in the CORS origins list.');
+    });
+  });
+});
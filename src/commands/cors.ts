@@ -0,0 +1,72 @@
+import chalk from 'chalk';
+import { handleApiError } from '../lib/api-error.js';
+import {
+  createCorsOrigin,
+  deleteCorsOrigin,
+  listCorsOrigins,
+  validateCorsOrigin,
+  type CorsOrigin,
+} from '../lib/cors-origins.js';
+
+function parseOrigin(input: string): string {
+  try {
+    return validateCorsOrigin(input);
+  } catch (error) {
+    console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+    process.exit(1);
+  }
+}
+
+export async function runCorsList(options: { json?: boolean }, apiKey: string, baseUrl?: string): Promise<void> {
+  let origins: CorsOrigin[];
+  try {
+    origins = await listCorsOrigins({ apiKey, baseUrl });
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  if (options.json) {
+    console.log(JSON.stringify(origins, null, 2));
+    return;
+  }
+  if (origins.length === 0) {
+    console.log('No CORS origins configured.');
+    return;
+  }
+  for (const { origin } of origins) console.log(origin);
+}
+
+/**
+ * Allow an origin. Adding one that is already allowed succeeds without
+ * changing anything, so scripts can run this on every setup.
+ */
+export async function runCorsAdd(input: string, apiKey: string, baseUrl?: string): Promise<void> {
+  const origin = parseOrigin(input);
+  try {
+    const { created } = await createCorsOrigin(origin, { apiKey, baseUrl });
+    console.log(created ? chalk.green(`Allowed ${origin}`) : `${origin} is already allowed.`);
+  } catch (error) {
+    handleApiError(error);
+  }
+}
+
+export async function runCorsRemove(input: string, apiKey: string, baseUrl?: string): Promise<void> {
+  const origin = parseOrigin(input);
+  let existing: CorsOrigin | undefined;
+  try {
+    existing = (await listCorsOrigins({ apiKey, baseUrl })).find((o) => o.origin === origin);
+  } catch (error) {
+    handleApiError(error);
+  }
+  if (!existing) {
+    console.error(chalk.red(`${origin} is not in the CORS origins list.`));
+    process.exit(1);
+  }
+
+  try {
+    await deleteCorsOrigin(existing.id, { apiKey, baseUrl });
+  } catch (error) {
+    handleApiError(error);
+  }
+  console.log(chalk.green(`Removed ${origin}`));
+}
@@ -0,0 +1,51 @@
+import chalk from 'chalk';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import { getActiveEnvironment } from '../lib/config-store.js';
+import { parseDashboardPage, resolveDashboardUrl, type DashboardEnvironment } from '../lib/dashboard-links.js';
+import { isProductionTarget } from '../lib/impersonation.js';
+import { openBrowser } from '../utils/browser.js';
+
+export interface DashboardOptions {
+  /** Page to open, e.g. users, org, redirect-uris, api-keys; the home page when absent */
+  page?: string;
+  /** User ID or email, or organization ID or name */
+  record?: string;
+  /** False with --no-open: print the URL only */
+  open?: boolean;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+const API_ERRORS: ApiErrorMessages = { notFound: 'Not found in this environment.' };
+
+/** e.g. "Staging (sandbox, environment_01H…)": which environment the page belongs to */
+function describeEnvironment(environment: DashboardEnvironment, production: boolean): string {
+  const name = environment.name ?? getActiveEnvironment()?.name ?? 'Environment';
+  const kind = production ? chalk.red.bold('production') : chalk.green('sandbox');
+  return `${chalk.bold(name)} (${kind}, ${environment.id})`;
+}
+
+/**
+ * Open the dashboard on a page of the API key's environment. The
+ * environment is printed first, in red for production, so it's clear which
+ * one the page will change.
+ */
+export async function runDashboard(options: DashboardOptions): Promise<void> {
+  let resolved: Awaited<ReturnType<typeof resolveDashboardUrl>>;
+  try {
+    const page = parseDashboardPage(options.page);
+    resolved = await resolveDashboardUrl(page, options.record, { apiKey: options.apiKey, baseUrl: options.baseUrl });
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  const production = isProductionTarget(options.apiKey, getActiveEnvironment());
+  console.error(`Environment: ${describeEnvironment(resolved.environment, production)}`);
+
+  if (options.open !== false && (await openBrowser(resolved.url))) {
+    console.error(chalk.dim(`Opened ${resolved.url}`));
+    return;
+  }
+  if (options.open !== false) console.error(chalk.yellow('Could not open a browser; open this URL:'));
+  console.log(resolved.url);
+}
@@ -0,0 +1,228 @@
+import chalk from 'chalk';
+import { readFile } from 'node:fs/promises';
+import { resolve } from 'node:path';
+import { applyConfidenceThreshold, countFindings, DEFAULT_MIN_CONFIDENCE, detectProviders } from '../migrate/detect.js';
+import { formatIgnoreRule } from '../migrate/ignore-rules.js';
+import { createOidcDiscovery } from '../migrate/oidc-discovery.js';
+import { parseFileList } from '../migrate/scan.js';
+import type { DetectionResult, MigrationFinding } from '../migrate/types.js';
+import { cloneRepository, removeCloneOnExit } from '../lib/remote-repo.js';
+import { redactSecrets } from '../utils/redact.js';
+import { formatTable } from '../utils/table.js';
+
+export interface DetectOptions {
+  installDir: string;
+  json?: boolean;
+  /** Hide providers whose combined confidence is below this (0-1) */
+  minConfidence?: number;
+  /** Keep matches below the threshold, flagged as belowThreshold */
+  includeAll?: boolean;
+  /** Only scan files changed since this git ref; exits 1 when anything is found */
+  since?: string;
+  /** Scan the files listed in this file (`-` for stdin) instead of walking; exits 1 when anything is found */
+  filesFrom?: string;
+  /** Only scan paths matching these globs */
+  include?: string[];
+  /** Skip paths matching these globs, after include */
+  exclude?: string[];
+  /** Reuse cached OIDC discovery lookups; false with --no-cache */
+  cache?: boolean;
+  /** Skip files larger than this many bytes */
+  maxFileSize?: number;
+  /** Also read Auth0 clients from Terraform files */
+  scanTerraform?: boolean;
+  /** Also report files skipped as too large or binary, and which ignore rule left out which file */
+  verbose?: boolean;
+  /** Scan a shallow clone of this repository instead of installDir */
+  repo?: string;
+  /** Branch of the repository to check out */
+  branch?: string;
+  /** Print only the number of findings per provider and the total */
+  count?: boolean;
+  /** With count: exit 1 when the total is over this, and stop scanning once it is */
+  failOver?: number;
+}
+
+async function readStdin(): Promise<string> {
+  const chunks: Buffer[] = [];
+  for await (const chunk of process.stdin) chunks.push(Buffer.from(chunk));
+  return Buffer.concat(chunks).toString('utf-8');
+}
+
+/** Paths from --files-from, NUL- or newline-delimited */
+async function readFileList(source: string): Promise<string[]> {
+  return parseFileList(source === '-' ? await readStdin() : await readFile(resolve(source), 'utf-8'));
+}
+
+function formatPercent(value: number): string {
+  return `${Math.round(value * 100)}%`;
+}
+
+/** Which paths the walk covered, when --include or --exclude narrowed it */
+export function formatScanPaths(paths: NonNullable<DetectionResult['paths']>): string {
+  const parts = [
+    paths.include.length > 0 ? `include ${paths.include.join(', ')}` : 'all paths',
+    ...(paths.exclude.length > 0 ? [`then exclude ${paths.exclude.join(', ')}`] : []),
+  ];
+  return chalk.dim(`Scanned ${paths.files} file(s): ${parts.join(', ')}`);
+}
+
+/** How many files detectors skipped, and which */
+export function formatSkipped(skipped: NonNullable<DetectionResult['skipped']>): string {
+  const lines = [
+    ...(skipped.size.length > 0 ? [`Skipped ${skipped.size.length} file(s) over --max-file-size:`] : []),
+    ...skipped.size.map((file) => `  ${file}`),
+    ...(skipped.binary.length > 0 ? [`Skipped ${skipped.binary.length} binary file(s):`] : []),
+    ...skipped.binary.map((file) => `  ${file}`),
+  ];
+  return chalk.dim(lines.join('\n'));
+}
+
+/** Files each .gitignore or .workosignore rule left out, only the first few per rule */
+export function formatIgnored(ignored: NonNullable<DetectionResult['ignored']>, perRule = 10): string {
+  const byRule = new Map<string, string[]>();
+  for (const { file, rule } of ignored) {
+    const key = formatIgnoreRule(rule);
+    byRule.set(key, [...(byRule.get(key) ?? []), file]);
+  }
+  const lines = [`Left out ${ignored.length} file(s) by ignore rules:`];
+  for (const [rule, files] of byRule) {
+    lines.push(`  ${rule} (${files.length})`);
+    lines.push(...files.slice(0, perRule).map((file) => `    ${file}`));
+    if (files.length > perRule) lines.push(`    … and ${files.length - perRule} more`);
+  }
+  return chalk.dim(lines.join('\n'));
+}
+
+function formatSuppressed(result: DetectionResult): string | null {
+  if (!result.suppressed || result.minConfidence === undefined) return null;
+  const count = `${result.suppressed} weak match${result.suppressed === 1 ? '' : 'es'}`;
+  return chalk.dim(`${count} below ${formatPercent(result.minConfidence)} hidden (use --include-all to show).`);
+}
+
+export function formatDetectionResult(result: DetectionResult): string {
+  const suppressed = formatSuppressed(result);
+  const scope = result.paths ? formatScanPaths(result.paths) : null;
+  if (result.since?.files.length === 0) {
+    return `No files relevant to auth detection changed since ${result.since.ref}.`;
+  }
+  if (result.listed?.files.length === 0) {
+    return `None of the ${result.listed.count} listed file(s) are relevant to auth detection.`;
+  }
+  if (result.matches.length === 0) {
+    const since = result.since
+      ? ` in ${result.since.files.length} file(s) changed since ${result.since.ref}`
+      : result.listed
+        ? ` in ${result.listed.files.length} listed file(s)`
+        : '';
+    return [`No other auth providers detected${since}.`, suppressed, scope].filter(Boolean).join('\n');
+  }
+
+  const sections = result.matches.map((match) => {
+    const weak = match.belowThreshold ? ', below threshold' : '';
+    const score = chalk.dim(`(confidence ${formatPercent(match.confidence)}${weak})`);
+    const title = `${chalk.bold(match.provider)} ${score}`;
+    const table = (findings: MigrationFinding[]) =>
+      formatTable(
+        [{ header: 'Location' }, { header: 'Severity' }, { header: 'Finding' }],
+        findings.map((f) => [
+          f.line ? `${f.file}:${f.line}` : f.file,
+          f.severity === 'warning' ? chalk.yellow(f.severity) : chalk.dim(f.severity),
+          f.manual ? `${f.message} ${chalk.dim('(manual)')}` : f.message,
+        ]),
+      );
+
+    const auth = match.findings.filter((f) => !f.outOfScope);
+    const outOfScope = match.findings.filter((f) => f.outOfScope);
+    const section = `${title}\n${table(auth)}`;
+    if (outOfScope.length === 0) return section;
+    return `${section}\n${chalk.dim('Not part of the auth migration (left unchanged):')}\n${table(outOfScope)}`;
+  });
+
+  if (suppressed) sections.push(suppressed);
+  if (scope) sections.push(scope);
+  return redactSecrets(sections.join('\n\n'));
+}
+
+/** `<provider> <count>` per line, most findings first, then `total <count>` */
+export function formatCounts(counts: ReturnType<typeof countFindings>): string {
+  const providers = Object.entries(counts.providers).sort(([a, x], [b, y]) => y - x || a.localeCompare(b));
+  return [...providers.map(([provider, count]) => `${provider} ${count}`), `total ${counts.total}`].join('\n');
+}
+
+export async function runDetect(options: DetectOptions): Promise<void> {
+  let installDir = resolve(options.installDir);
+  if (options.repo) {
+    if (options.since) {
+      console.error(chalk.red('--since needs history, and a --repo clone is shallow'));
+      process.exit(1);
+    }
+    try {
+      const clone = await cloneRepository(options.repo, { branch: options.branch });
+      removeCloneOnExit(clone);
+      installDir = clone.dir;
+    } catch (error) {
+      console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+      process.exit(1);
+    }
+  }
+
+  const minConfidence = options.minConfidence ?? DEFAULT_MIN_CONFIDENCE;
+  const { failOver } = options;
+  // A gate only needs to know the limit was passed, not by how much
+  const exceeds = (result: DetectionResult) =>
+    failOver !== undefined && countFindings(applyConfidenceThreshold(result, minConfidence)).total > failOver;
+
+  let detected: DetectionResult;
+  try {
+    detected = await detectProviders(installDir, undefined, {
+      since: options.since,
+      ...(options.filesFrom && { files: await readFileList(options.filesFrom) }),
+      include: options.include,
+      exclude: options.exclude,
+      discovery: createOidcDiscovery({ cache: options.cache }),
+      maxFileSize: options.maxFileSize,
+      terraform: options.scanTerraform,
+      ...(options.count && failOver !== undefined && { until: exceeds }),
+    });
+  } catch (error) {
+    console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+    process.exit(1);
+  }
+  const result = applyConfidenceThreshold(detected, minConfidence, {
+    includeAll: options.includeAll,
+  });
+
+  if (options.count) {
+    const counts = countFindings(result);
+    if (options.json) {
+      const output = {
+        ...counts,
+        ...(failOver !== undefined && { failOver }),
+        ...(result.partial && { partial: true }),
+      };
+      console.log(JSON.stringify(output, null, 2));
+    } else {
+      console.log(formatCounts(counts));
+      // On stderr, so stdout is only the counts
+      if (result.partial) {
+        console.error(chalk.dim(`Stopped scanning over --fail-over ${failOver}; the counts are a lower bound.`));
+      }
+    }
+  } else if (options.json) {
+    // Ignore files can leave out whole build directories; only list them when asked
+    const output = options.verbose ? result : { ...result, ignored: undefined };
+    console.log(redactSecrets(JSON.stringify(output, null, 2)));
+  } else {
+    console.log(formatDetectionResult(result));
+    if (options.verbose && result.skipped) console.log(`\n${formatSkipped(result.skipped)}`);
+    if (options.verbose && result.ignored) console.log(`\n${formatIgnored(result.ignored)}`);
+  }
+
+  if (failOver !== undefined) {
+    if (countFindings(result).total > failOver) process.exit(1);
+    return;
+  }
+  // As a PR check or pre-commit hook, newly introduced provider code fails the run
+  if ((options.since || options.filesFrom) && result.matches.length > 0) process.exit(1);
+}
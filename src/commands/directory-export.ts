@@ -0,0 +1,80 @@
+import chalk from 'chalk';
+import { resolve } from 'node:path';
+import {
+  checkpointPath,
+  exportRecords,
+  type ExportFormat,
+  type ExportManifest,
+  type ExportResult,
+  type ExportSource,
+} from '../lib/directory-export.js';
+import { parseSince } from '../lib/request-logs.js';
+import { isQuietMode } from '../utils/clack.js';
+
+export interface DirectoryExportOptions {
+  format?: ExportFormat;
+  /** Defaults to users.jsonl, organizations.csv and so on */
+  out?: string;
+  /** Lookback (24h, 7d) or ISO date */
+  updatedSince?: string;
+  resume?: boolean;
+}
+
+function formatManifest(manifest: ExportManifest): string {
+  const parts = [`${manifest.records} ${manifest.kind}`];
+  if (manifest.memberships !== undefined) parts.push(`${manifest.memberships} memberships`);
+  if (manifest.updatedSince) parts.push(`updated since ${manifest.updatedSince} (${manifest.scanned} read)`);
+  return parts.join(', ');
+}
+
+/**
+ * Export every record from `source`, with a running count on stderr.
+ * Ctrl-C stops after the current page, which --resume carries on from.
+ */
+export async function runDirectoryExport<T extends { id: string }, R extends { id: string }>(
+  source: ExportSource<T, R>,
+  options: DirectoryExportOptions,
+): Promise<void> {
+  const format = options.format ?? 'jsonl';
+  const out = resolve(options.out ?? `${source.kind}.${format}`);
+  let updatedSince: string | undefined;
+  try {
+    updatedSince = options.updatedSince ? parseSince(options.updatedSince) : undefined;
+  } catch (error) {
+    console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+    process.exit(1);
+  }
+
+  const controller = new AbortController();
+  const stop = () => controller.abort();
+  process.once('SIGINT', stop);
+  const live = Boolean(process.stderr.isTTY) && !isQuietMode();
+  let result: ExportResult;
+  try {
+    result = await exportRecords(source, {
+      out,
+      format,
+      updatedSince,
+      resume: options.resume,
+      signal: controller.signal,
+      onPage: ({ records }) => {
+        if (live) process.stderr.write(`\r\x1b[2K${chalk.dim(`Exported ${records} ${source.kind}...`)}`);
+      },
+    });
+  } finally {
+    if (live) process.stderr.write('\r\x1b[2K');
+    process.off('SIGINT', stop);
+  }
+
+  const { manifest } = result;
+  const resumed = result.resumedFrom !== undefined ? chalk.dim(` (resumed after ${result.resumedFrom})`) : '';
+  if (result.interrupted) {
+    console.error(
+      `Stopped after ${formatManifest(manifest)}${resumed}. ` +
+        `Run the same command with --resume to carry on from ${chalk.cyan(checkpointPath(out))}.`,
+    );
+    process.exit(1);
+  }
+  console.log(chalk.green(`Exported ${formatManifest(manifest)} to ${out}`) + resumed);
+  console.log(chalk.dim(`Manifest: ${result.manifestPath}`));
+}
@@ -0,0 +1,156 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+vi.mock('node:dns/promises', () => ({
+  resolveCname: vi.fn(),
+  resolveTxt: vi.fn(),
+}));
+
+const mockConfirm = vi.fn();
+vi.mock('../utils/clack.js', () => ({
+  default: {
+    log: { info: vi.fn(), success: vi.fn() },
+    confirm: (...args: unknown[]) => mockConfirm(...args),
+    isCancel: () => false,
+  },
+}));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const { resolveCname, resolveTxt } = await import('node:dns/promises');
+const mockRequest = vi.mocked(workosRequest);
+
+const { formatDnsRecords, runDomainsSetup, runDomainsVerify } = await import('./domains.js');
+
+const registered = {
+  id: 'custom_domain_01',
+  domain: 'auth.example.com',
+  state: 'pending' as const,
+  dns_records: [
+    { type: 'CNAME' as const, name: 'auth.example.com', value: 'cname.authkit.app' },
+    { type: 'TXT' as const, name: '_workos.auth.example.com', value: 'workos-verification=abc123' },
+  ],
+};
+
+describe('domains commands', () => {
+  let dir: string;
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    mockConfirm.mockReset();
+    vi.mocked(resolveCname).mockReset().mockResolvedValue(['cname.authkit.app']);
+    vi.mocked(resolveTxt).mockReset().mockRejectedValue(new Error('ENODATA'));
+    dir = mkdtempSync(join(tmpdir(), 'domains-'));
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('formatDnsRecords', () => {
+    it('shows what DNS returns for each record', () => {
+      const output = formatDnsRecords({
+        dns: [
+          { record: registered.dns_records[0], found: true, actual: ['cname.authkit.app'] },
+          { record: registered.dns_records[1], found: false, actual: ['workos-verification=old'] },
+        ],
+      });
+      expect(output).toMatch(/CNAME\s+auth\.example\.com\s+cname\.authkit\.app\s+found/);
+      expect(output).toContain('got workos-verification=old');
+    });
+  });
+
+  describe('runDomainsSetup', () => {
+    it('registers the domain and prints the records to create', async () => {
+      mockRequest
+        .mockResolvedValueOnce({ data: [], list_metadata: {} })
+        .mockResolvedValueOnce(registered)
+        .mockResolvedValueOnce({ data: [registered], list_metadata: {} });
+
+      await runDomainsSetup('auth.example.com', { apiKey: 'sk_test' });
+
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'POST', path: '/custom_domains', body: { domain: 'auth.example.com' } }),
+      );
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('Registered auth.example.com');
+      expect(output).toContain('workos-verification=abc123');
+      expect(output).toContain('workos domains verify auth.example.com --wait');
+    });
+
+    it('reuses an existing registration', async () => {
+      mockRequest.mockResolvedValue({ data: [registered], list_metadata: {} });
+      await runDomainsSetup('auth.example.com', { apiKey: 'sk_test' });
+      expect(mockRequest).not.toHaveBeenCalledWith(expect.objectContaining({ method: 'POST' }));
+      expect(consoleOutput).toContain('auth.example.com is already registered (pending)');
+    });
+  });
+
+  describe('runDomainsVerify', () => {
+    it('exits 1 and shows the missing records while DNS is pending', async () => {
+      mockRequest.mockResolvedValue({ data: [registered], list_metadata: {} });
+      await expect(runDomainsVerify('auth.example.com', { apiKey: 'sk_test' })).rejects.toThrow('process.exit');
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('auth.example.com: DNS not propagated');
+      expect(output).toContain('missing');
+      expect(output).toContain('Re-run with --wait');
+    });
+
+    it('prints why provisioning failed', async () => {
+      const failed = { ...registered, state: 'failed', failure_reason: 'CAA record blocks issuance' };
+      mockRequest.mockResolvedValue({ data: [failed], list_metadata: {} });
+      await expect(runDomainsVerify('auth.example.com', { apiKey: 'sk_test' })).rejects.toThrow('process.exit');
+      expect(errors).toContain('CAA record blocks issuance');
+    });
+
+    it('says to run setup for a domain that is not registered', async () => {
+      mockRequest.mockResolvedValue({ data: [], list_metadata: {} });
+      await expect(runDomainsVerify('auth.example.com', { apiKey: 'sk_test' })).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('Run `workos domains setup auth.example.com` first.');
+    });
+
+    it('offers to point the env at an active domain', async () => {
+      writeFileSync(join(dir, '.env.local'), 'WORKOS_REDIRECT_URI=https://acme.authkit.app/callback\n');
+      mockRequest.mockResolvedValue({ data: [{ ...registered, state: 'active' }], list_metadata: {} });
+      mockConfirm.mockResolvedValue(true);
+
+      await runDomainsVerify('auth.example.com', { apiKey: 'sk_test', installDir: dir });
+
+      expect(consoleOutput).toContain('auth.example.com: Active — certificate issued and serving traffic');
+      expect(mockConfirm).toHaveBeenCalledWith({ message: 'Update .env.local to use auth.example.com?' });
+      expect(readFileSync(join(dir, '.env.local'), 'utf-8')).toBe(
+        'WORKOS_REDIRECT_URI=https://auth.example.com/callback\n',
+      );
+    });
+  });
+});
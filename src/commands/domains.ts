@@ -0,0 +1,132 @@
+import chalk from 'chalk';
+import { existsSync, readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { handleApiError } from '../lib/api-error.js';
+import { applyFileEdits } from '../lib/atomic-write.js';
+import {
+  applyEnvDomainUpdates,
+  createCustomDomain,
+  getDomainStatus,
+  proposeEnvDomainUpdates,
+  waitForDomain,
+  type DomainStatus,
+  type EnvDomainUpdate,
+} from '../lib/custom-domains.js';
+import clack from '../utils/clack.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { formatTable } from '../utils/table.js';
+
+/** Env files checked for AuthKit URLs once a domain is active */
+const ENV_FILE_CANDIDATES = ['.env.local', '.env'];
+
+const PHASE_MESSAGES: Record<DomainStatus['phase'], string> = {
+  active: 'Active — certificate issued and serving traffic',
+  'dns-pending': 'DNS not propagated — the records below are missing or wrong',
+  'certificate-provisioning': 'DNS verified — certificate still provisioning',
+  failed: 'Failed',
+};
+
+export function formatDnsRecords(status: Pick<DomainStatus, 'dns'>): string {
+  const rows = status.dns.map(({ record, found, actual }) => [
+    record.type,
+    record.name,
+    record.value,
+    found ? chalk.green('found') : actual.length > 0 ? chalk.yellow(`got ${actual.join(', ')}`) : chalk.red('missing'),
+  ]);
+  return formatTable([{ header: 'Type' }, { header: 'Name' }, { header: 'Value' }, { header: 'DNS' }], rows);
+}
+
+export interface DomainsSetupOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+/**
+ * Register a custom AuthKit domain and print the DNS records to create.
+ */
+export async function runDomainsSetup(domain: string, options: DomainsSetupOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  try {
+    const { domain: registered, created } = await createCustomDomain(domain, api);
+    const registeredMessage = `${domain} is already registered (${registered.state})`;
+    console.log(created ? chalk.green(`Registered ${domain}`) : registeredMessage);
+
+    const status = await getDomainStatus(domain, api);
+    console.log('');
+    console.log('Create these records with your DNS provider:');
+    console.log(formatDnsRecords(status));
+    console.log('');
+    console.log('CNAME records must not be proxied (e.g. Cloudflare "DNS only"). TXT values are case-sensitive.');
+    console.log(`Then run ${chalk.cyan(`workos domains verify ${domain} --wait`)}`);
+  } catch (error) {
+    handleApiError(error);
+  }
+}
+
+export interface DomainsVerifyOptions extends DomainsSetupOptions {
+  wait?: boolean;
+  /** Give up waiting after this many seconds */
+  timeout?: number;
+  installDir?: string;
+}
+
+/**
+ * Check (or wait for) a custom domain to become active, then offer to
+ * point the project's env at it.
+ */
+export async function runDomainsVerify(domain: string, options: DomainsVerifyOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+
+  let status: DomainStatus;
+  try {
+    if (options.wait) {
+      const spinner = clack.spinner();
+      spinner.start(`Waiting for ${domain}`);
+      status = await waitForDomain(domain, api, {
+        timeoutMs: (options.timeout ?? 1800) * 1000,
+        onStatus: (s) => spinner.message(`${domain}: ${PHASE_MESSAGES[s.phase]}`),
+      });
+      spinner.stop(`${domain}: ${PHASE_MESSAGES[status.phase]}`);
+    } else {
+      status = await getDomainStatus(domain, api);
+      console.log(`${domain}: ${PHASE_MESSAGES[status.phase]}`);
+    }
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  if (status.phase === 'failed') {
+    const reason = status.domain.failure_reason || 'Provisioning failed. Check the records and run setup again.';
+    console.error(chalk.red(reason));
+    process.exit(1);
+  }
+
+  if (status.phase !== 'active') {
+    if (status.phase === 'dns-pending') console.log(formatDnsRecords(status));
+    if (!options.wait) console.log(chalk.dim(`Re-run with --wait to poll until ${domain} is active.`));
+    process.exit(1);
+  }
+
+  await offerEnvUpdate(options.installDir ?? process.cwd(), domain);
+}
+
+async function offerEnvUpdate(installDir: string, domain: string): Promise<void> {
+  for (const fileName of ENV_FILE_CANDIDATES) {
+    const path = join(installDir, fileName);
+    if (!existsSync(path)) continue;
+
+    const updates = proposeEnvDomainUpdates(parseEnvFile(readFileSync(path, 'utf-8')), domain);
+    if (updates.length === 0) continue;
+
+    clack.log.info(`${fileName} still points at the default AuthKit domain:\n${formatUpdates(updates)}`);
+    const confirmed = await clack.confirm({ message: `Update ${fileName} to use ${domain}?` });
+    if (clack.isCancel(confirmed) || !confirmed) continue;
+
+    applyFileEdits([{ path, content: (current) => applyEnvDomainUpdates(current ?? '', updates) }]);
+    clack.log.success(`Updated ${fileName}. Remember to update the same values in your deployed environments.`);
+  }
+}
+
+function formatUpdates(updates: EnvDomainUpdate[]): string {
+  return updates.map((u) => `  ${u.key}: ${chalk.dim(u.from)} → ${chalk.cyan(u.to)}`).join('\n');
+}
@@ -0,0 +1,142 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runEmailTemplatesPull, runEmailTemplatesPush, runEmailTemplatesPreview } = await import('./email-templates.js');
+
+const invitation = {
+  type: 'invitation',
+  subject: 'Join {{organization_name}}',
+  html: '<p>{{inviter_email}} invited you: <a href="{{invitation_url}}">accept</a></p>\n',
+};
+const magicAuth = { type: 'magic_auth', subject: 'Your code', html: '<p>{{code}}</p>\n' };
+
+describe('email-templates commands', () => {
+  let dir: string;
+  let consoleOutput: string[];
+  let errors: string[];
+  const isTTY = process.stdin.isTTY;
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    // Nothing prompts; commands that would ask take the non-interactive path
+    process.stdin.isTTY = false;
+    dir = mkdtempSync(join(tmpdir(), 'email-templates-'));
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    process.stdin.isTTY = isTTY;
+    vi.restoreAllMocks();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('runEmailTemplatesPull', () => {
+    it('writes each deployed template with its subject comment', async () => {
+      mockRequest.mockResolvedValue({ data: [invitation, magicAuth], list_metadata: {} });
+      await runEmailTemplatesPull({ apiKey: 'sk_test', dir });
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'GET', path: '/user_management/email_templates' }),
+      );
+      expect(readFileSync(join(dir, 'invitation.html'), 'utf-8')).toBe(
+        `<!-- subject: Join {{organization_name}} -->\n${invitation.html}`,
+      );
+      expect(readFileSync(join(dir, 'magic_auth.html'), 'utf-8')).toContain('{{code}}');
+    });
+
+    it('pulls only the template asked for, by its alias', async () => {
+      mockRequest.mockResolvedValue({ data: [invitation, magicAuth], list_metadata: {} });
+      await runEmailTemplatesPull({ apiKey: 'sk_test', dir, template: 'magic-link' });
+      expect(consoleOutput).toHaveLength(1);
+      expect(consoleOutput[0]).toContain('magic_auth.html');
+    });
+
+    it('rejects an unknown template name before calling the API', async () => {
+      await expect(runEmailTemplatesPull({ apiKey: 'sk_test', dir, template: 'welcome' })).rejects.toThrow(
+        'process.exit',
+      );
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('Unknown template "welcome"');
+    });
+  });
+
+  describe('runEmailTemplatesPush', () => {
+    it('uploads only the templates that changed, with --yes', async () => {
+      writeFileSync(join(dir, 'invitation.html'), `<!-- subject: You're invited -->\n${invitation.html}`);
+      writeFileSync(join(dir, 'magic_auth.html'), `<!-- subject: Your code -->\n${magicAuth.html}`);
+      mockRequest.mockResolvedValueOnce({ data: [invitation, magicAuth], list_metadata: {} }).mockResolvedValue(null);
+
+      await runEmailTemplatesPush({ apiKey: 'sk_test', dir, yes: true });
+
+      expect(mockRequest).toHaveBeenCalledTimes(2);
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({
+          method: 'PUT',
+          path: '/user_management/email_templates/invitation',
+          body: { subject: "You're invited", html: invitation.html },
+        }),
+      );
+      expect(consoleOutput.join('\n')).toContain('Uploaded invitation');
+    });
+
+    it('prints the diff and exits without uploading when it cannot ask', async () => {
+      writeFileSync(join(dir, 'invitation.html'), `<!-- subject: You're invited -->\n${invitation.html}`);
+      mockRequest.mockResolvedValue({ data: [invitation], list_metadata: {} });
+
+      await expect(runEmailTemplatesPush({ apiKey: 'sk_test', dir })).rejects.toThrow('process.exit');
+
+      expect(mockRequest).not.toHaveBeenCalledWith(expect.objectContaining({ method: 'PUT' }));
+      expect(consoleOutput.join('\n')).toContain('Re-run with --yes to apply.');
+    });
+
+    it('refuses variables WorkOS does not fill in', async () => {
+      writeFileSync(join(dir, 'magic_auth.html'), '<!-- subject: Your code -->\n<p>{{code}} for {{first_name}}</p>\n');
+
+      await expect(runEmailTemplatesPush({ apiKey: 'sk_test', dir, yes: true })).rejects.toThrow('process.exit');
+
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('magic_auth.html uses unknown variables: first_name');
+    });
+  });
+
+  describe('runEmailTemplatesPreview', () => {
+    it('renders the template with sample values', () => {
+      writeFileSync(join(dir, 'invitation.html'), `<!-- subject: Join {{organization_name}} -->\n${invitation.html}`);
+      const out = join(dir, 'preview.html');
+
+      runEmailTemplatesPreview({ dir, template: 'invite', toFile: out });
+
+      expect(readFileSync(out, 'utf-8')).toContain('admin@example.com invited you');
+      expect(consoleOutput).toContain('Subject: Join Acme Corp');
+    });
+  });
+});
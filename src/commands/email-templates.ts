@@ -0,0 +1,196 @@
+import chalk from 'chalk';
+import { mkdirSync, readdirSync, readFileSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import { handleApiError } from '../lib/api-error.js';
+import { writeFileAtomic } from '../lib/atomic-write.js';
+import { formatUnifiedDiff } from '../lib/change-preview.js';
+import {
+  listEmailTemplates,
+  parseTemplateFile,
+  renderTemplatePreview,
+  resolveTemplateType,
+  serializeTemplate,
+  templateFileName,
+  TEMPLATE_VARIABLES,
+  unknownTemplateVariables,
+  updateEmailTemplate,
+  type EmailTemplate,
+} from '../lib/email-templates.js';
+import clack from '../utils/clack.js';
+
+export interface EmailTemplatesOptions {
+  apiKey: string;
+  baseUrl?: string;
+  /** Directory holding one `<type>.html` per template */
+  dir: string;
+  /** Only this template, e.g. `invitation` or `magic-link` */
+  template?: string;
+}
+
+function templateType(name: string): string {
+  const type = resolveTemplateType(name);
+  if (!type) {
+    const types = Object.keys(TEMPLATE_VARIABLES).join(', ');
+    handleApiError(new Error(`Unknown template "${name}". Expected one of: ${types}`));
+  }
+  return type;
+}
+
+function readLocalTemplate(dir: string, type: string): EmailTemplate {
+  const path = join(dir, templateFileName(type));
+  try {
+    return parseTemplateFile(type, readFileSync(path, 'utf-8'));
+  } catch (error) {
+    const missing = (error as NodeJS.ErrnoException).code === 'ENOENT';
+    handleApiError(missing ? new Error(`${path} not found. Run \`workos email-templates pull\` first.`) : error);
+  }
+}
+
+/** Local templates to push: every `<type>.html` in the directory, or just the one asked for */
+function readLocalTemplates(dir: string, only?: string): EmailTemplate[] {
+  if (only) return [readLocalTemplate(dir, only)];
+  let files: string[];
+  try {
+    files = readdirSync(dir).filter((f) => f.endsWith('.html'));
+  } catch {
+    handleApiError(new Error(`Could not read ${dir}. Run \`workos email-templates pull\` first.`));
+  }
+  return files.map((file) => {
+    const type = resolveTemplateType(file.replace(/\.html$/, ''));
+    if (!type) handleApiError(new Error(`${join(dir, file)} is not a known template type`));
+    return readLocalTemplate(dir, type);
+  });
+}
+
+/**
+ * Write the deployed templates to `<dir>/<type>.html`.
+ */
+export async function runEmailTemplatesPull(options: EmailTemplatesOptions): Promise<void> {
+  const only = options.template && templateType(options.template);
+  let templates: EmailTemplate[];
+  try {
+    templates = await listEmailTemplates({ apiKey: options.apiKey, baseUrl: options.baseUrl });
+  } catch (error) {
+    handleApiError(error);
+  }
+  if (only) templates = templates.filter((t) => t.type === only);
+  if (templates.length === 0) {
+    console.log(only ? `No ${only} template is customized in this environment.` : 'No email templates found.');
+    return;
+  }
+
+  const dir = resolve(options.dir);
+  mkdirSync(dir, { recursive: true });
+  for (const template of templates) {
+    const path = join(dir, templateFileName(template.type));
+    writeFileAtomic(path, serializeTemplate(template));
+    console.log(chalk.green(`Wrote ${path}`));
+  }
+}
+
+export interface EmailTemplatesPushOptions extends EmailTemplatesOptions {
+  /** Apply without confirmation; required without a TTY */
+  yes?: boolean;
+  /** Upload templates that use variables WorkOS doesn't provide */
+  allowUnknownVariables?: boolean;
+}
+
+/**
+ * Check variables, diff each local template against the deployed one and
+ * upload on confirmation.
+ */
+export async function runEmailTemplatesPush(options: EmailTemplatesPushOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  const dir = resolve(options.dir);
+  const local = readLocalTemplates(dir, options.template && templateType(options.template));
+
+  const unknown = local
+    .map((template) => ({ template, names: unknownTemplateVariables(template) }))
+    .filter(({ names }) => names.length > 0);
+  for (const { template, names } of unknown) {
+    const known = Object.keys(TEMPLATE_VARIABLES[template.type]).join(', ');
+    const message = `${templateFileName(template.type)} uses unknown variables: ${names.join(', ')} (known: ${known})`;
+    console.error(options.allowUnknownVariables ? chalk.yellow(message) : chalk.red(message));
+  }
+  if (unknown.length > 0 && !options.allowUnknownVariables) {
+    console.error('They would render empty. Fix them, or re-run with --allow-unknown-variables.');
+    process.exit(1);
+  }
+
+  let deployed: Map<string, EmailTemplate>;
+  try {
+    deployed = new Map((await listEmailTemplates(api)).map((t) => [t.type, t]));
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  const changed = local.flatMap((template) => {
+    const current = deployed.get(template.type);
+    const before = current ? serializeTemplate(current) : null;
+    const after = serializeTemplate(template);
+    if (before === after) return [];
+    const path = join(dir, templateFileName(template.type));
+    return [{ template, diff: formatUnifiedDiff({ path, before, after }, process.cwd()) }];
+  });
+  if (changed.length === 0) {
+    console.log('Email templates are already up to date.');
+    return;
+  }
+
+  const diff = changed.map((c) => c.diff).join('\n');
+  let apply = options.yes ?? false;
+  if (!apply && process.stdin.isTTY) {
+    console.log(diff);
+    const confirmed = await clack.confirm({ message: `Upload ${changed.length} template(s)?` });
+    apply = !clack.isCancel(confirmed) && confirmed;
+  } else if (!apply) {
+    console.log(`${diff}\n\nRe-run with --yes to apply.`);
+    process.exit(1);
+  } else {
+    console.log(diff);
+  }
+
+  if (!apply) {
+    console.log('Templates not uploaded.');
+    return;
+  }
+
+  for (const { template } of changed) {
+    try {
+      await updateEmailTemplate(template, api);
+    } catch (error) {
+      handleApiError(error);
+    }
+    console.log(chalk.green(`Uploaded ${template.type}`));
+  }
+}
+
+export interface EmailTemplatesPreviewOptions {
+  dir: string;
+  template: string;
+  /** Write the rendered HTML here instead of stdout */
+  toFile?: string;
+}
+
+/**
+ * Render a local template with sample values. The subject goes to stderr
+ * when the HTML goes to stdout, so the output can be piped.
+ */
+export function runEmailTemplatesPreview(options: EmailTemplatesPreviewOptions): void {
+  const template = readLocalTemplate(resolve(options.dir), templateType(options.template));
+  const unknown = unknownTemplateVariables(template);
+  if (unknown.length > 0) {
+    console.error(chalk.yellow(`Unknown variables render empty: ${unknown.join(', ')}`));
+  }
+
+  const { subject, html } = renderTemplatePreview(template);
+  if (!options.toFile) {
+    console.error(chalk.dim(`Subject: ${subject}`));
+    process.stdout.write(html);
+    return;
+  }
+  const path = resolve(options.toFile);
+  writeFileAtomic(path, html);
+  console.log(`Subject: ${subject}`);
+  console.log(chalk.green(`Preview written to ${path}`));
+}
@@ -1,5 +1,5 @@
 import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
-import { mkdtempSync, rmdirSync } from 'node:fs';
+import { mkdtempSync, readFileSync, rmdirSync, rmSync, writeFileSync } from 'node:fs';
 import { join } from 'node:path';
 import { tmpdir } from 'node:os';
 
@@ -39,7 +39,7 @@ vi.mock('node:os', async (importOriginal) => {
 });
 
 const { getConfig, saveConfig, setInsecureConfigStorage, clearConfig } = await import('../lib/config-store.js');
-const { runEnvAdd, runEnvRemove, runEnvSwitch, runEnvList } = await import('./env.js');
+const { runEnvAdd, runEnvRemove, runEnvSwitch, runEnvList, runEnvExample } = await import('./env.js');
 const clack = (await import('../utils/clack.js')).default;
 
 // Spy on process.exit
@@ -160,4 +160,40 @@ describe('env commands', () => {
       await expect(runEnvList()).resolves.not.toThrow();
     });
   });
+
+  describe('runEnvExample', () => {
+    let projectDir: string;
+
+    beforeEach(() => {
+      projectDir = mkdtempSync(join(tmpdir(), 'env-example-test-'));
+    });
+
+    afterEach(() => {
+      rmSync(projectDir, { recursive: true, force: true });
+    });
+
+    it('documents keys from .env.local without copying secrets', async () => {
+      writeFileSync(join(projectDir, '.env.local'), 'WORKOS_API_KEY=sk_test_realkey\nWORKOS_CLIENT_ID=client_123\n');
+
+      await runEnvExample({ installDir: projectDir });
+
+      const example = readFileSync(join(projectDir, '.env.example'), 'utf-8');
+      expect(example).toContain('WORKOS_API_KEY=sk_test_...');
+      expect(example).not.toContain('sk_test_realkey');
+      expect(example).not.toContain('client_123');
+    });
+
+    it('reports when nothing changed', async () => {
+      writeFileSync(join(projectDir, '.env'), 'WORKOS_CLIENT_ID=client_123\n');
+      writeFileSync(join(projectDir, '.env.example'), 'WORKOS_CLIENT_ID=\n');
+
+      await runEnvExample({ installDir: projectDir });
+
+      expect(clack.log.info).toHaveBeenCalledWith(expect.stringContaining('already lists every key'));
+    });
+
+    it('exits when there is no env file', async () => {
+      await expect(runEnvExample({ installDir: projectDir })).rejects.toThrow('process.exit');
+    });
+  });
 });
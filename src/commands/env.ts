@@ -1,10 +1,37 @@
 import chalk from 'chalk';
 import clack from '../utils/clack.js';
+import { pick } from '../utils/fuzzy-picker.js';
 import { getConfig, saveConfig, setInsecureConfigStorage } from '../lib/config-store.js';
 import type { CliConfig, EnvironmentConfig } from '../lib/config-store.js';
+import { existsSync, readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { applyFileEdits } from '../lib/atomic-write.js';
+import {
+  applyClonePlan,
+  CLONED_CATEGORIES,
+  NOT_CLONED,
+  planClone,
+  type ClonePlan,
+} from '../lib/environment-clone.js';
+import {
+  CATEGORY_LABELS,
+  diffEnvironments,
+  fetchEnvironmentConfig,
+  type ConfigChange,
+  type EnvironmentConfigSnapshot,
+  type EnvironmentDiff,
+  type FieldChange,
+} from '../lib/environment-config.js';
+import { WorkOSApiError } from '../lib/workos-api.js';
+import { ENV_EXAMPLE_FILE, envExampleEdit } from '../lib/env-example.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { redactSecrets } from '../utils/redact.js';
 
 const ENV_NAME_REGEX = /^[a-z0-9\-_]+$/;
 
+/** Env files `env example` reads from when --env-file isn't given */
+const ENV_FILE_CANDIDATES = ['.env.local', '.env'];
+
 function validateEnvName(name: string): string | undefined {
   if (!ENV_NAME_REGEX.test(name)) {
     return 'Name must contain only lowercase letters, numbers, hyphens, and underscores';
@@ -12,6 +39,11 @@ function validateEnvName(name: string): string | undefined {
   return undefined;
 }
 
+/** Detect the type from the API key prefix */
+function environmentType(apiKey: string): EnvironmentConfig['type'] {
+  return apiKey.startsWith('sk_test_') ? 'sandbox' : 'production';
+}
+
 function getOrCreateConfig(): CliConfig {
   return getConfig() ?? { environments: {} };
 }
@@ -37,6 +69,7 @@ export async function runEnvAdd(options: {
     const nameResult = await clack.text({
       message: 'Enter a name for the environment (e.g., production, sandbox, local)',
       validate: (value) => validateEnvName(value),
+      flag: 'workos env add <name> <apiKey>',
     });
     if (clack.isCancel(nameResult)) process.exit(0);
     name = nameResult;
@@ -47,6 +80,7 @@ export async function runEnvAdd(options: {
         { value: 'production', label: 'Production' },
         { value: 'sandbox', label: 'Sandbox' },
       ],
+      flag: 'workos env add <name> <apiKey>',
     });
     if (clack.isCancel(typeResult)) process.exit(0);
 
@@ -56,6 +90,7 @@ export async function runEnvAdd(options: {
         if (!value) return 'API key is required';
         return undefined;
       },
+      flag: 'workos env add <name> <apiKey>',
     });
     if (clack.isCancel(apiKeyResult)) process.exit(0);
     apiKey = apiKeyResult;
@@ -88,12 +123,9 @@ export async function runEnvAdd(options: {
   const config = getOrCreateConfig();
   const isFirst = Object.keys(config.environments).length === 0;
 
-  // Detect type from API key prefix
-  const type: 'production' | 'sandbox' = apiKey.startsWith('sk_test_') ? 'sandbox' : 'production';
-
   config.environments[name!] = {
     name: name!,
-    type,
+    type: environmentType(apiKey),
     apiKey,
     ...(clientId && { clientId }),
     ...(endpoint && { endpoint }),
@@ -153,17 +185,18 @@ export async function runEnvSwitch(name?: string): Promise<void> {
     }
   } else {
     // Interactive selection
-    const options = Object.entries(config.environments).map(([key, env]) => {
+    const items = Object.entries(config.environments).map(([key, env]) => {
       let label = key;
       if (env.type === 'sandbox') label += ` [Sandbox]`;
       if (env.endpoint) label += ` [${env.endpoint}]`;
-      if (key === config.activeEnvironment) label += chalk.green(' (active)');
-      return { value: key, label };
+      if (key === config.activeEnvironment) label += ' (active)';
+      return { value: key, label, keywords: [env.name, env.type] };
     });
 
-    const selected = await clack.select({
+    const selected = await pick({
       message: 'Select an environment',
-      options,
+      items,
+      flag: 'workos env switch <name>',
     });
     if (clack.isCancel(selected)) process.exit(0);
     name = selected as string;
@@ -213,3 +246,223 @@ export async function runEnvList(): Promise<void> {
     console.log([marker, name, type.padEnd(typeW), endpoint].join('  '));
   }
 }
+
+export async function runEnvExample(options: { installDir: string; envFile?: string }): Promise<void> {
+  const envFile = options.envFile ?? ENV_FILE_CANDIDATES.find((f) => existsSync(join(options.installDir, f)));
+  if (!envFile || !existsSync(join(options.installDir, envFile))) {
+    clack.log.error(`No env file found. Looked for ${options.envFile ?? ENV_FILE_CANDIDATES.join(', ')}.`);
+    process.exit(1);
+  }
+  if (envFile === ENV_EXAMPLE_FILE) {
+    clack.log.error(`Pass the env file with real values, not ${ENV_EXAMPLE_FILE}.`);
+    process.exit(1);
+  }
+
+  const envVars = parseEnvFile(readFileSync(join(options.installDir, envFile), 'utf-8'));
+  const written = applyFileEdits([envExampleEdit(options.installDir, envVars)]);
+
+  if (written.length > 0) {
+    clack.log.success(`Updated ${chalk.bold(ENV_EXAMPLE_FILE)} with the keys from ${chalk.bold(envFile)}`);
+  } else {
+    clack.log.info(`${ENV_EXAMPLE_FILE} already lists every key in ${envFile}`);
+  }
+}
+
+/** Longest value shown inline in a diff; longer ones, such as template bodies, are cut */
+const MAX_VALUE_WIDTH = 60;
+
+function formatValue(value: unknown): string {
+  if (value === undefined) return chalk.dim('(unset)');
+  const text = typeof value === 'string' ? value : JSON.stringify(value);
+  return text.length > MAX_VALUE_WIDTH ? `${text.slice(0, MAX_VALUE_WIDTH - 1)}…` : text;
+}
+
+function formatItem(value: ConfigChange['value']): string {
+  const fields = Object.entries(value ?? {}).filter(([, v]) => v !== null && !(Array.isArray(v) && v.length === 0));
+  if (fields.length === 0) return '';
+  return chalk.dim(` (${fields.map(([field, v]) => `${field}: ${formatValue(v)}`).join(', ')})`);
+}
+
+function formatFieldChange({ field, from, to }: FieldChange): string {
+  if (Array.isArray(from) && Array.isArray(to)) {
+    const added = to.filter((v) => !from.includes(v)).map((v) => chalk.green(`+${v}`));
+    const removed = from.filter((v) => !to.includes(v)).map((v) => chalk.red(`-${v}`));
+    return `      ${field}: ${[...added, ...removed].join(' ')}`;
+  }
+  return `      ${field}: ${chalk.red(formatValue(from))} → ${chalk.green(formatValue(to))}`;
+}
+
+export function formatEnvironmentDiff(diff: EnvironmentDiff): string {
+  const lines = [chalk.red(`--- ${diff.from}`), chalk.green(`+++ ${diff.to}`)];
+  let total = 0;
+  for (const { label, changes } of diff.categories) {
+    if (changes.length === 0) continue;
+    total += changes.length;
+    lines.push('', chalk.bold(label));
+    for (const change of changes) {
+      if (change.type === 'added') lines.push(chalk.green(`  + ${change.key}`) + formatItem(change.value));
+      if (change.type === 'removed') lines.push(chalk.red(`  - ${change.key}`) + formatItem(change.value));
+      if (change.type === 'modified') {
+        lines.push(chalk.yellow(`  ~ ${change.key}`), ...(change.fields ?? []).map(formatFieldChange));
+      }
+    }
+  }
+  for (const { category, environment, reason } of diff.skipped) {
+    const label = CATEGORY_LABELS[category];
+    lines.push('', chalk.yellow(`${label}: skipped, couldn't read it from ${environment} (${reason})`));
+  }
+  lines.push('', diff.identical ? chalk.green('No differences.') : `${total} difference${total === 1 ? '' : 's'}`);
+  return lines.join('\n');
+}
+
+function getStoredEnvironment(config: CliConfig, name: string): EnvironmentConfig {
+  const env = config.environments[name];
+  if (!env) {
+    const available = Object.keys(config.environments).join(', ');
+    clack.log.error(`Environment "${name}" not found. Available: ${available}`);
+    process.exit(1);
+  }
+  return env;
+}
+
+async function readEnvironmentConfig(name: string, env: EnvironmentConfig): Promise<EnvironmentConfigSnapshot> {
+  try {
+    return await fetchEnvironmentConfig({ apiKey: env.apiKey, baseUrl: env.endpoint });
+  } catch (error) {
+    const message =
+      error instanceof WorkOSApiError && error.statusCode === 401
+        ? `Invalid API key for environment "${name}". Update it with \`workos env add\`.`
+        : error instanceof Error
+          ? error.message
+          : String(error);
+    clack.log.error(message);
+    process.exit(1);
+  }
+}
+
+/**
+ * Compare the configuration of two stored environments. Exits 1 when they
+ * differ, so a deploy can be gated on it.
+ */
+export async function runEnvDiff(from: string, to: string, options: { json?: boolean } = {}): Promise<void> {
+  const config = getConfig();
+  if (!config || Object.keys(config.environments).length === 0) {
+    clack.log.error('No environments configured. Run `workos env add` to get started.');
+    process.exit(1);
+  }
+
+  const [fromConfig, toConfig] = await Promise.all([
+    readEnvironmentConfig(from, getStoredEnvironment(config, from)),
+    readEnvironmentConfig(to, getStoredEnvironment(config, to)),
+  ]);
+  const diff = diffEnvironments({ name: from, config: fromConfig }, { name: to, config: toConfig });
+
+  console.log(redactSecrets(options.json ? JSON.stringify(diff, null, 2) : formatEnvironmentDiff(diff)));
+  if (!diff.identical) process.exit(1);
+}
+
+export function formatClonePlan(plan: ClonePlan, from: string, to: string): string {
+  const lines = [chalk.bold(`Clone ${from} → ${to}`)];
+  for (const category of CLONED_CATEGORIES) {
+    const steps = plan.steps.filter((step) => step.category === category);
+    if (steps.length === 0) continue;
+    lines.push('', chalk.bold(CATEGORY_LABELS[category]));
+    for (const step of steps) {
+      const line = step.action === 'create' ? chalk.green(`  + ${step.key}`) : chalk.yellow(`  ~ ${step.key}`);
+      lines.push(line + (step.note ? chalk.dim(` (${step.note})`) : ''));
+    }
+  }
+  for (const { category, reason } of plan.skipped) {
+    lines.push('', chalk.yellow(`${CATEGORY_LABELS[category]}: skipped (${reason})`));
+  }
+  lines.push('', 'Not copied:', ...NOT_CLONED.map((item) => chalk.dim(`  ${item}`)));
+
+  const creates = plan.steps.filter((step) => step.action === 'create').length;
+  const updates = plan.steps.length - creates;
+  lines.push('', chalk.dim(`${creates} to create, ${updates} to update, ${plan.unchanged} already in ${to}`));
+  return lines.join('\n');
+}
+
+export interface EnvCloneOptions {
+  /** Stored environment to copy from */
+  from: string;
+  /** Environment to copy into: a stored one, or a new one when apiKey is given */
+  name: string;
+  /** API key of an environment created in the dashboard, stored under `name` */
+  apiKey?: string;
+  /** Point webhook endpoints at the source's URLs instead of placeholders */
+  keepWebhookUrls?: boolean;
+  dryRun?: boolean;
+}
+
+/**
+ * Copy the cloneable configuration of one environment into another. There's
+ * no API for creating environments with an API key, so a new one is created
+ * in the dashboard and passed in with its key.
+ */
+export async function runEnvClone(options: EnvCloneOptions): Promise<void> {
+  const config = getConfig();
+  if (!config || Object.keys(config.environments).length === 0) {
+    clack.log.error('No environments configured. Run `workos env add` to get started.');
+    process.exit(1);
+  }
+  const source = getStoredEnvironment(config, options.from);
+
+  // "QA" as a stored environment name
+  const name = options.name
+    .trim()
+    .toLowerCase()
+    .replace(/[^a-z0-9\-_]+/g, '-');
+  const nameError = validateEnvName(name);
+  if (nameError) {
+    clack.log.error(nameError);
+    process.exit(1);
+  }
+  if (name === options.from) {
+    clack.log.error('The new environment needs a different name from the one it is cloned from.');
+    process.exit(1);
+  }
+
+  const stored = config.environments[name];
+  if (!stored && !options.apiKey) {
+    clack.log.error(
+      `Environment "${name}" isn't configured. Create it in the WorkOS dashboard, ` +
+        'then pass its API key with --api-key (environments can only be created there).',
+    );
+    process.exit(1);
+  }
+  const target: EnvironmentConfig = stored ?? {
+    name,
+    type: environmentType(options.apiKey!),
+    apiKey: options.apiKey!,
+    ...(source.endpoint && { endpoint: source.endpoint }),
+  };
+
+  const [sourceConfig, targetConfig] = await Promise.all([
+    readEnvironmentConfig(options.from, source),
+    readEnvironmentConfig(name, target),
+  ]);
+  const plan = planClone(sourceConfig, targetConfig, { keepWebhookUrls: options.keepWebhookUrls });
+  console.log(formatClonePlan(plan, options.from, name));
+  if (options.dryRun) return;
+
+  if (!stored) {
+    config.environments[name] = target;
+    saveConfig(config);
+    clack.log.success(`Environment ${chalk.bold(name)} added`);
+  }
+  if (plan.steps.length === 0) {
+    clack.log.info(`${name} already has everything that can be copied from ${options.from}`);
+    return;
+  }
+
+  const result = await applyClonePlan(plan, { apiKey: target.apiKey, baseUrl: target.endpoint });
+  for (const { step, reason } of result.failed) {
+    clack.log.error(`${CATEGORY_LABELS[step.category]} ${step.key}: ${reason}`);
+  }
+  if (result.failed.length > 0) {
+    clack.log.warn(`Copied ${result.applied.length} of ${plan.steps.length} items into ${name}`);
+    process.exit(1);
+  }
+  clack.log.success(`Copied ${result.applied.length} items into ${chalk.bold(name)}`);
+}
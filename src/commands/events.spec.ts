@@ -0,0 +1,120 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runEventsList } = await import('./events.js');
+
+function event(id: string, type = 'user.created') {
+  return { id, event: type, data: { id: 'user_123' }, created_at: '2026-01-01T00:00:00.000Z' };
+}
+
+describe('events commands', () => {
+  const originalFetch = globalThis.fetch;
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    vi.unstubAllEnvs();
+    globalThis.fetch = originalFetch;
+  });
+
+  describe('runEventsList', () => {
+    it('lists a page of events and the cursor for the next one', async () => {
+      mockRequest.mockResolvedValue({ data: [event('event_1')], list_metadata: { after: 'event_1' } });
+      await runEventsList({ types: ['user.created, user.deleted'], org: 'org_123', apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'GET',
+          path: '/events',
+          params: expect.objectContaining({ events: ['user.created', 'user.deleted'], organization_id: 'org_123' }),
+        }),
+      );
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('event_1');
+      expect(output).toContain('More events: --after event_1');
+    });
+
+    it('follows cursors through every page with --all', async () => {
+      mockRequest
+        .mockResolvedValueOnce({ data: [event('event_1')], list_metadata: { after: 'event_1' } })
+        .mockResolvedValueOnce({ data: [event('event_2')], list_metadata: {} });
+      await runEventsList({ all: true, json: true, apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledTimes(2);
+      expect(consoleOutput.map((line) => JSON.parse(line).id)).toEqual(['event_1', 'event_2']);
+    });
+
+    it('rejects an unreadable --range before calling the API', async () => {
+      await expect(runEventsList({ range: 'last week', apiKey: 'sk_test' })).rejects.toThrow('process.exit');
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('Invalid --range "last week"');
+    });
+
+    it('needs a webhook secret to replay events', async () => {
+      vi.stubEnv('WORKOS_WEBHOOK_SECRET', '');
+      await expect(runEventsList({ replayTo: 'http://localhost:3000/webhooks', apiKey: 'sk_test' })).rejects.toThrow(
+        'process.exit',
+      );
+      expect(errors.join('\n')).toContain('pass --webhook-secret');
+    });
+
+    it('replays each event to the webhook URL, signed', async () => {
+      mockRequest.mockResolvedValue({ data: [event('event_1')], list_metadata: {} });
+      globalThis.fetch = vi.fn().mockResolvedValue(new Response(null, { status: 204 }));
+
+      await runEventsList({ replayTo: 'http://localhost:3000/webhooks', webhookSecret: 'whsec', apiKey: 'sk_test' });
+
+      expect(globalThis.fetch).toHaveBeenCalledWith(
+        'http://localhost:3000/webhooks',
+        expect.objectContaining({
+          method: 'POST',
+          headers: expect.objectContaining({ 'WorkOS-Signature': expect.stringMatching(/^t=\d+, v1=[0-9a-f]{64}$/) }),
+        }),
+      );
+      expect(errors.join('\n')).toContain('204 user.created event_1 → http://localhost:3000/webhooks');
+    });
+
+    it('streams new events with --follow until interrupted', async () => {
+      mockRequest.mockImplementation((async () => {
+        setImmediate(() => process.emit('SIGINT'));
+        return { data: [event('event_1')], list_metadata: {} };
+      }) as never);
+      await runEventsList({ follow: true, apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ params: expect.objectContaining({ range_start: expect.any(String) }) }),
+      );
+      expect(JSON.parse(consoleOutput[0])).toMatchObject({ id: 'event_1' });
+    });
+  });
+});
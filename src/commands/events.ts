@@ -0,0 +1,148 @@
+import chalk from 'chalk';
+import { handleApiError } from '../lib/api-error.js';
+import {
+  eventsRequest,
+  followEvents,
+  listEvents,
+  parseRange,
+  replayEvent,
+  type EventsQuery,
+  type WorkOSEvent,
+} from '../lib/workos-events.js';
+import { paginate } from '../lib/pagination.js';
+import type { WorkOSListResponse } from '../lib/workos-api.js';
+import { createTableStream, formatTable } from '../utils/table.js';
+
+export interface EventsListOptions {
+  types?: string[];
+  /** Lookback (24h) or <start>..<end> */
+  range?: string;
+  org?: string;
+  limit?: number;
+  after?: string;
+  /** Follow cursors through every page; `limit` caps the total */
+  all?: boolean;
+  json?: boolean;
+  /** Poll for new events and stream them as JSON lines */
+  follow?: boolean;
+  /** Seconds between polls with --follow */
+  interval?: number;
+  /** Re-post each event to this URL, signed like a webhook */
+  replayTo?: string;
+  webhookSecret?: string;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+const EVENT_COLUMNS = [{ header: 'ID' }, { header: 'Event' }, { header: 'Created' }];
+
+function eventRow(event: WorkOSEvent): string[] {
+  return [event.id, event.event, event.created_at];
+}
+
+interface ReplayTarget {
+  url: string;
+  secret: string;
+}
+
+async function replay(event: WorkOSEvent, { url, secret }: ReplayTarget): Promise<void> {
+  try {
+    const status = await replayEvent(event, url, secret);
+    const color = status >= 200 && status < 300 ? chalk.green : chalk.red;
+    console.error(`${color(String(status))} ${event.event} ${chalk.dim(event.id)} → ${url}`);
+  } catch (error) {
+    console.error(chalk.red(`Replay of ${event.id} failed: ${error instanceof Error ? error.message : String(error)}`));
+  }
+}
+
+/**
+ * List events from the Events API, optionally following new ones and
+ * replaying them to a local webhook endpoint.
+ */
+export async function runEventsList(options: EventsListOptions): Promise<void> {
+  let target: ReplayTarget | undefined;
+  if (options.replayTo) {
+    const secret = options.webhookSecret || process.env.WORKOS_WEBHOOK_SECRET;
+    if (!secret) {
+      console.error(chalk.red('--replay-to signs events like webhooks; pass --webhook-secret.'));
+      console.error(chalk.dim('Or set WORKOS_WEBHOOK_SECRET to the secret your webhook handler verifies with.'));
+      process.exit(1);
+    }
+    target = { url: options.replayTo, secret };
+  }
+
+  let query: EventsQuery;
+  try {
+    query = {
+      types: options.types?.flatMap((type) => type.split(',')).map((t) => t.trim()).filter(Boolean),
+      ...(options.range ? parseRange(options.range) : {}),
+      organizationId: options.org,
+      limit: options.limit,
+      after: options.after,
+    };
+  } catch (error) {
+    handleApiError(error);
+  }
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+
+  if (options.follow) {
+    // Without a starting point, follow only what happens from now on
+    if (!query.rangeStart && !query.after) query.rangeStart = new Date().toISOString();
+    const controller = new AbortController();
+    process.once('SIGINT', () => controller.abort());
+    console.error(chalk.dim('Following events. Press Ctrl+C to stop.'));
+
+    try {
+      const intervalMs = (options.interval ?? 5) * 1000;
+      for await (const event of followEvents(query, api, { intervalMs, signal: controller.signal })) {
+        console.log(JSON.stringify(event));
+        if (target) await replay(event, target);
+      }
+    } catch (error) {
+      handleApiError(error);
+    }
+    return;
+  }
+
+  if (options.all) {
+    // Stream pages as they arrive; JSON lines so output pipes into jq
+    const printRows = createTableStream(EVENT_COLUMNS);
+    let count = 0;
+    try {
+      for await (const page of paginate<WorkOSEvent>(eventsRequest(query, api), { max: options.limit })) {
+        for (const event of page) {
+          if (target) await replay(event, target);
+          else if (options.json) console.log(JSON.stringify(event));
+        }
+        if (!target && !options.json) printRows(page.map(eventRow));
+        count += page.length;
+      }
+    } catch (error) {
+      handleApiError(error);
+    }
+    if (count === 0 && !options.json) console.log('No events found.');
+    return;
+  }
+
+  let result: WorkOSListResponse<WorkOSEvent>;
+  try {
+    result = await listEvents(query, api);
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  if (target) {
+    for (const event of result.data) await replay(event, target);
+  } else if (options.json) {
+    console.log(JSON.stringify(result, null, 2));
+    return;
+  } else if (result.data.length === 0) {
+    console.log('No events found.');
+  } else {
+    console.log(formatTable(EVENT_COLUMNS, result.data.map(eventRow)));
+  }
+
+  if (result.list_metadata.after) {
+    console.log(chalk.dim(`More events: --after ${result.list_metadata.after}`));
+  }
+}
@@ -0,0 +1,41 @@
+import chalk from 'chalk';
+import {
+  ERROR_CATALOG,
+  formatErrorExplanation,
+  lookupError,
+  similarErrorIds,
+  type ErrorId,
+} from '../utils/error-catalog.js';
+import { formatTable } from '../utils/table.js';
+
+/**
+ * `workos explain [id]`: the catalog entry for an error ID, or every ID
+ * without one. Works offline; the catalog ships with the CLI.
+ */
+export function runExplain(id: string | undefined, options: { json?: boolean } = {}): void {
+  if (!id) {
+    const ids = Object.keys(ERROR_CATALOG) as ErrorId[];
+    if (options.json) {
+      console.log(JSON.stringify(ids.map((entryId) => ({ id: entryId, ...ERROR_CATALOG[entryId] })), null, 2));
+      return;
+    }
+    console.log(
+      formatTable(
+        [{ header: 'ID' }, { header: 'Error' }],
+        ids.map((entryId) => [entryId, ERROR_CATALOG[entryId].title]),
+      ),
+    );
+    return;
+  }
+
+  const found = lookupError(id);
+  if (!found) {
+    console.error(chalk.red(`Unknown error ID: ${id}`));
+    const similar = similarErrorIds(id);
+    if (similar.length > 0) console.error(`Did you mean: ${similar.join(', ')}?`);
+    console.error(chalk.dim('Run `workos explain` to list every ID.'));
+    process.exit(1);
+  }
+  const { id: foundId, entry } = found;
+  console.log(options.json ? JSON.stringify({ id: foundId, ...entry }, null, 2) : formatErrorExplanation(foundId));
+}
@@ -0,0 +1,105 @@
+import chalk from 'chalk';
+import { writeFileSync } from 'node:fs';
+import { handleApiError } from '../lib/api-error.js';
+import { getActiveEnvironment } from '../lib/config-store.js';
+import {
+  fetchEnvironmentSnapshot,
+  renderExportJson,
+  limitToOrganizations,
+  renderTerraform,
+  type EnvironmentSnapshot,
+} from '../lib/terraform-export.js';
+import clack from '../utils/clack.js';
+import { pickMany } from '../utils/fuzzy-picker.js';
+
+export interface ExportTerraformOptions {
+  apiKey: string;
+  baseUrl?: string;
+  /** `hcl` for the Terraform provider, `json` for a provider-neutral export */
+  format: 'hcl' | 'json';
+  /** File to write, `-` for stdout. Defaults to workos.tf or workos.json */
+  out?: string;
+  /** Export only these organizations (and their connections) */
+  organizations?: string[];
+  /** Choose the organizations from a picker */
+  pickOrganizations?: boolean;
+}
+
+/** The environment named in the export header: the stored one, unless WORKOS_API_KEY overrides it */
+function describeSource(baseUrl: string | undefined): string {
+  const env = process.env.WORKOS_API_KEY ? null : getActiveEnvironment();
+  const url = baseUrl ?? 'https://api.workos.com';
+  return env ? `${env.name} (${env.type}, ${url})` : url;
+}
+
+function summarize(snapshot: EnvironmentSnapshot): string {
+  return [
+    `${snapshot.organizations.length} organizations`,
+    `${snapshot.redirectUris.length} redirect URIs`,
+    `${snapshot.roles.length} roles`,
+    `${snapshot.webhookEndpoints.length} webhook endpoints`,
+    `${snapshot.connections.length} SSO connections`,
+  ].join(', ');
+}
+
+/**
+ * Export the environment's configuration as Terraform (with the import
+ * commands that adopt it) or as JSON.
+ */
+export async function runExportTerraform(options: ExportTerraformOptions): Promise<void> {
+  let snapshot: EnvironmentSnapshot;
+  try {
+    snapshot = await fetchEnvironmentSnapshot({ apiKey: options.apiKey, baseUrl: options.baseUrl });
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  let organizations = options.organizations;
+  if (options.pickOrganizations && snapshot.organizations.length > 0) {
+    const picked = await pickMany({
+      message: 'Select organizations to export',
+      items: snapshot.organizations.map((org) => ({
+        value: org.id,
+        label: org.name,
+        hint: org.id,
+        keywords: org.domains,
+      })),
+      initialValues: organizations,
+      flag: '--organization',
+    });
+    if (clack.isCancel(picked)) process.exit(0);
+    organizations = picked as string[];
+  }
+  if (organizations?.length) {
+    const unknown = organizations.filter((id) => !snapshot.organizations.some((org) => org.id === id));
+    if (unknown.length > 0) {
+      console.error(chalk.red(`No organization with ID ${unknown.join(', ')} in this environment.`));
+      process.exit(1);
+    }
+    snapshot = limitToOrganizations(snapshot, organizations);
+  }
+
+  const meta = { exportedAt: new Date(), source: describeSource(options.baseUrl) };
+  const terraform = options.format === 'hcl' ? renderTerraform(snapshot, meta) : null;
+  const content = terraform ? terraform.hcl : renderExportJson(snapshot, meta);
+  const out = options.out ?? (options.format === 'hcl' ? 'workos.tf' : 'workos.json');
+
+  if (out === '-') {
+    process.stdout.write(content);
+    return;
+  }
+  writeFileSync(out, content);
+
+  console.log(chalk.green(`✓ Wrote ${out}`) + chalk.dim(` (${summarize(snapshot)})`));
+  for (const { kind, reason } of snapshot.unreadable) {
+    console.log(chalk.yellow(`  Skipped ${kind}: ${reason}`));
+  }
+  if (terraform && snapshot.connections.length > 0) {
+    console.log(chalk.dim('  SSO connections have no Terraform resource; they are listed in a comment instead.'));
+  }
+  if (terraform && terraform.imports.length > 0) {
+    console.log('\nAdopt the existing resources into Terraform state:\n');
+    for (const command of terraform.imports) console.log(`  ${command}`);
+    console.log();
+  }
+}
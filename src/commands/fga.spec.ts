@@ -0,0 +1,195 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest, WorkOSApiError } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { formatWarrant, runFgaCheck, runFgaSchemaPush, runFgaWarrants } = await import('./fga.js');
+
+const SCHEMA = `version 0.3
+
+type user
+
+type document
+    relation viewer [user]
+`;
+
+describe('fga commands', () => {
+  let consoleOutput: string[];
+  let errors: string[];
+  let exit: ReturnType<typeof vi.spyOn>;
+  const isTTY = process.stdin.isTTY;
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    // Nothing prompts; commands that would ask take the non-interactive path
+    process.stdin.isTTY = false;
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    exit = vi.spyOn(process, 'exit').mockImplementation(((code?: number) => {
+      throw new Error(`process.exit(${code})`);
+    }) as never);
+  });
+
+  afterEach(() => {
+    process.stdin.isTTY = isTTY;
+    vi.restoreAllMocks();
+  });
+
+  describe('formatWarrant', () => {
+    it('prints a warrant as <resource>#<relation>@<subject>', () => {
+      expect(
+        formatWarrant({
+          resource_type: 'document',
+          resource_id: 'doc_1',
+          relation: 'viewer',
+          subject: { resource_type: 'user', resource_id: 'user_1' },
+        }),
+      ).toBe('document:doc_1#viewer@user:user_1');
+    });
+
+    it('keeps the relation of a subject set', () => {
+      expect(
+        formatWarrant({
+          resource_type: 'document',
+          resource_id: 'doc_1',
+          relation: 'owner',
+          subject: { resource_type: 'team', resource_id: 'team_1', relation: 'member' },
+        }),
+      ).toBe('document:doc_1#owner@team:team_1#member');
+    });
+  });
+
+  describe('runFgaWarrants', () => {
+    it('writes the warrants in one batch and lists them', async () => {
+      mockRequest.mockResolvedValue(null);
+      await runFgaWarrants('create', ['document:doc_1#viewer@user:user_1'], { apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/fga/v1/warrants',
+          body: [
+            {
+              op: 'create',
+              resource_type: 'document',
+              resource_id: 'doc_1',
+              relation: 'viewer',
+              subject: { resource_type: 'user', resource_id: 'user_1' },
+            },
+          ],
+        }),
+      );
+      expect(consoleOutput.join('\n')).toContain('document:doc_1#viewer@user:user_1');
+    });
+
+    it('exits 2 without calling the API when no warrants are given', async () => {
+      await expect(runFgaWarrants('delete', [], { apiKey: 'sk_test' })).rejects.toThrow('process.exit(2)');
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('No warrants given.');
+    });
+  });
+
+  describe('runFgaCheck', () => {
+    it('exits 0 when authorized', async () => {
+      mockRequest.mockResolvedValue({ result: 'authorized', is_implicit: true });
+      await expect(runFgaCheck('user:user_1', 'viewer', 'document:doc_1', { apiKey: 'sk_test' })).rejects.toThrow(
+        'process.exit(0)',
+      );
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/fga/v1/check',
+          body: {
+            checks: [
+              {
+                resource_type: 'document',
+                resource_id: 'doc_1',
+                relation: 'viewer',
+                subject: { resource_type: 'user', resource_id: 'user_1' },
+              },
+            ],
+          },
+        }),
+      );
+      expect(consoleOutput.join('\n')).toContain('user:user_1 is viewer of document:doc_1');
+    });
+
+    it('exits 1 when not authorized', async () => {
+      mockRequest.mockResolvedValue({ result: 'not_authorized', is_implicit: false });
+      await expect(
+        runFgaCheck('user:user_1', 'viewer', 'document:doc_1', { apiKey: 'sk_test', json: true }),
+      ).rejects.toThrow('process.exit(1)');
+      expect(JSON.parse(consoleOutput[0])).toMatchObject({ authorized: false });
+    });
+
+    it('exits 2 on an API error', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Unauthorized', 401));
+      await expect(runFgaCheck('user:user_1', 'viewer', 'document:doc_1', { apiKey: 'sk_test' })).rejects.toThrow(
+        'process.exit(2)',
+      );
+      expect(errors.join('\n')).toContain('Invalid API key.');
+    });
+  });
+
+  describe('runFgaSchemaPush', () => {
+    let dir: string;
+    let file: string;
+
+    beforeEach(() => {
+      dir = mkdtempSync(join(tmpdir(), 'fga-schema-'));
+      file = join(dir, 'schema.fga');
+      writeFileSync(file, SCHEMA);
+    });
+
+    afterEach(() => {
+      rmSync(dir, { recursive: true, force: true });
+    });
+
+    it('does nothing when the deployed schema matches', async () => {
+      mockRequest.mockResolvedValue({ schema: SCHEMA });
+      await runFgaSchemaPush(file, { apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(consoleOutput).toContain('Schema is already up to date.');
+    });
+
+    it('prints the plan without applying it unless --yes', async () => {
+      mockRequest.mockResolvedValue({ schema: 'version 0.3\n\ntype user\n' });
+      await expect(runFgaSchemaPush(file, { apiKey: 'sk_test', json: true })).rejects.toThrow('process.exit(1)');
+      expect(mockRequest).not.toHaveBeenCalledWith(expect.objectContaining({ method: 'PUT' }));
+      expect(JSON.parse(consoleOutput[0])).toMatchObject({ changed: true, applied: false });
+    });
+
+    it('applies the schema with --yes', async () => {
+      mockRequest.mockResolvedValueOnce({ schema: 'version 0.3\n\ntype user\n' }).mockResolvedValueOnce(null);
+      await runFgaSchemaPush(file, { apiKey: 'sk_test', json: true, yes: true });
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ method: 'PUT', path: '/fga/v1/schema', body: { schema: SCHEMA } }),
+      );
+      expect(JSON.parse(consoleOutput[0])).toMatchObject({ applied: true });
+      expect(exit).not.toHaveBeenCalled();
+    });
+  });
+});
@@ -0,0 +1,249 @@
+import chalk from 'chalk';
+import { readFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import { writeFileAtomic } from '../lib/atomic-write.js';
+import { formatUnifiedDiff } from '../lib/change-preview.js';
+import {
+  checkFga,
+  formatResource,
+  formatSchemaErrors,
+  getFgaSchema,
+  parseResource,
+  parseWarrantFile,
+  putFgaSchema,
+  queryFga,
+  validateFgaSchema,
+  writeWarrants,
+  type CheckResult,
+  type FgaWarrant,
+  type QueryResult,
+  type WarrantOp,
+} from '../lib/fga.js';
+import { WorkOSApiError } from '../lib/workos-api.js';
+import clack from '../utils/clack.js';
+import { formatTable } from '../utils/table.js';
+
+export interface FgaOptions {
+  apiKey: string;
+  baseUrl?: string;
+  json?: boolean;
+}
+
+/**
+ * Exit 2 on errors; `fga check` uses 1 for "not authorized".
+ */
+function handleError(error: unknown, json?: boolean): never {
+  const message =
+    error instanceof WorkOSApiError && error.statusCode === 401
+      ? 'Invalid API key. Check your environment configuration.'
+      : error instanceof WorkOSApiError && error.statusCode === 422 && error.errors?.length
+        ? error.errors.map((e) => e.message).join(', ')
+        : error instanceof Error
+          ? error.message
+          : 'Unknown error';
+  if (json) {
+    const status = error instanceof WorkOSApiError ? error.statusCode : undefined;
+    console.log(JSON.stringify({ error: { message, status } }));
+  } else {
+    console.error(chalk.red(message));
+  }
+  process.exit(2);
+}
+
+function output(json: boolean | undefined, data: unknown, text: () => string): void {
+  console.log(json ? JSON.stringify(data, null, 2) : text());
+}
+
+function readSchemaFile(file: string, json?: boolean): { path: string; schema: string } {
+  const path = resolve(file);
+  try {
+    return { path, schema: readFileSync(path, 'utf-8') };
+  } catch {
+    handleError(new Error(`Could not read ${file}`), json);
+  }
+}
+
+/**
+ * Validate a schema file locally. Exits 1 when it has errors.
+ */
+export function runFgaSchemaValidate(file: string, options: { json?: boolean }): void {
+  const { schema } = readSchemaFile(file, options.json);
+  const { errors, summary } = validateFgaSchema(schema);
+
+  output(options.json, { valid: errors.length === 0, errors, types: summary.types }, () =>
+    errors.length > 0
+      ? chalk.red(formatSchemaErrors(file, errors))
+      : chalk.green(`${file} is valid (${Object.keys(summary.types).length} types)`),
+  );
+  if (errors.length > 0) process.exit(1);
+}
+
+export interface FgaSchemaPushOptions extends FgaOptions {
+  /** Apply without confirmation; required in --json mode and without a TTY */
+  yes?: boolean;
+}
+
+/**
+ * Validate, diff against the deployed schema and apply on confirmation.
+ */
+export async function runFgaSchemaPush(file: string, options: FgaSchemaPushOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  const { path, schema } = readSchemaFile(file, options.json);
+
+  const { errors } = validateFgaSchema(schema);
+  if (errors.length > 0) {
+    output(options.json, { valid: false, errors, applied: false }, () => chalk.red(formatSchemaErrors(file, errors)));
+    process.exit(1);
+  }
+
+  let deployed: string;
+  try {
+    deployed = await getFgaSchema(api);
+  } catch (error) {
+    handleError(error, options.json);
+  }
+
+  if (deployed.trim() === schema.trim()) {
+    output(options.json, { valid: true, changed: false, applied: false }, () => 'Schema is already up to date.');
+    return;
+  }
+
+  const change = { path, before: deployed ? deployed : null, after: schema };
+  const diff = formatUnifiedDiff(change, process.cwd(), { color: !options.json });
+
+  let apply = options.yes ?? false;
+  if (!apply && !options.json && process.stdin.isTTY) {
+    console.log(diff);
+    const confirmed = await clack.confirm({ message: 'Apply this schema?' });
+    apply = !clack.isCancel(confirmed) && confirmed;
+  } else if (!apply) {
+    const plan = { valid: true, changed: true, applied: false, diff };
+    output(options.json, plan, () => `${diff}\n\nRe-run with --yes to apply.`);
+    process.exit(1);
+  } else if (!options.json) {
+    console.log(diff);
+  }
+
+  if (!apply) {
+    console.log('Schema not applied.');
+    return;
+  }
+
+  try {
+    await putFgaSchema(schema, api);
+  } catch (error) {
+    handleError(error, options.json);
+  }
+  output(options.json, { valid: true, changed: true, applied: true, diff }, () => chalk.green('Schema applied.'));
+}
+
+/**
+ * Print the deployed schema, or write it to a file.
+ */
+export async function runFgaSchemaPull(options: FgaOptions & { output?: string }): Promise<void> {
+  let schema: string;
+  try {
+    schema = await getFgaSchema({ apiKey: options.apiKey, baseUrl: options.baseUrl });
+  } catch (error) {
+    handleError(error, options.json);
+  }
+
+  if (options.output) {
+    const path = resolve(options.output);
+    writeFileAtomic(path, schema.endsWith('\n') ? schema : `${schema}\n`);
+    output(options.json, { path }, () => chalk.green(`Schema written to ${path}`));
+  } else if (options.json) {
+    console.log(JSON.stringify({ schema }, null, 2));
+  } else {
+    process.stdout.write(schema.endsWith('\n') ? schema : `${schema}\n`);
+  }
+}
+
+/**
+ * Ad-hoc check. Exits 0 when authorized and 1 when not, so it can be
+ * used directly in policy tests.
+ */
+export async function runFgaCheck(
+  subjectRef: string,
+  relation: string,
+  resourceRef: string,
+  options: FgaOptions,
+): Promise<void> {
+  let result: CheckResult;
+  try {
+    const subject = parseResource(subjectRef);
+    const resource = parseResource(resourceRef);
+    result = await checkFga(subject, relation, resource, { apiKey: options.apiKey, baseUrl: options.baseUrl });
+  } catch (error) {
+    handleError(error, options.json);
+  }
+
+  const summary = `${subjectRef} ${result.authorized ? 'is' : 'is not'} ${relation} of ${resourceRef}`;
+  output(options.json, { subject: subjectRef, relation, resource: resourceRef, ...result }, () =>
+    result.authorized
+      ? `${chalk.green('✔')} ${summary}${result.is_implicit ? chalk.dim(' (implicit)') : ''}`
+      : `${chalk.red('✖')} ${summary}`,
+  );
+  process.exit(result.authorized ? 0 : 1);
+}
+
+/**
+ * List what a query returns, e.g. `select document where user:123 is viewer`.
+ */
+export async function runFgaQuery(query: string, options: FgaOptions & { limit?: number }): Promise<void> {
+  let results: QueryResult[];
+  try {
+    results = await queryFga(query, { apiKey: options.apiKey, baseUrl: options.baseUrl }, options.limit);
+  } catch (error) {
+    handleError(error, options.json);
+  }
+
+  output(options.json, { query, results }, () => {
+    if (results.length === 0) return 'No results.';
+    const rows = results.map((r) => [
+      `${r.resource_type}:${r.resource_id}`,
+      r.relation,
+      r.is_implicit ? chalk.dim('implicit') : 'direct',
+    ]);
+    return formatTable([{ header: 'Resource' }, { header: 'Relation' }, { header: 'Warrant' }], rows);
+  });
+}
+
+export function formatWarrant(warrant: FgaWarrant): string {
+  const resource = formatResource({ resource_type: warrant.resource_type, resource_id: warrant.resource_id });
+  return `${resource}#${warrant.relation}@${formatResource(warrant.subject)}`;
+}
+
+/**
+ * Create or delete warrants given as `<resource>#<relation>@<subject>`
+ * arguments or read from --file.
+ */
+export async function runFgaWarrants(
+  op: WarrantOp,
+  tuples: string[],
+  options: FgaOptions & { file?: string },
+): Promise<void> {
+  let warrants: FgaWarrant[];
+  try {
+    warrants = [
+      ...(options.file ? parseWarrantFile(readFileSync(resolve(options.file), 'utf-8')) : []),
+      ...parseWarrantFile(tuples.join('\n')),
+    ];
+  } catch (error) {
+    handleError(error, options.json);
+  }
+  if (warrants.length === 0) {
+    handleError(new Error('No warrants given. Pass <resource>#<relation>@<subject> or --file.'), options.json);
+  }
+
+  try {
+    await writeWarrants(op, warrants, { apiKey: options.apiKey, baseUrl: options.baseUrl });
+  } catch (error) {
+    handleError(error, options.json);
+  }
+
+  const verb = op === 'create' ? 'Created' : 'Deleted';
+  output(options.json, { op, count: warrants.length, warrants }, () =>
+    [chalk.green(`${verb} ${warrants.length} warrant(s)`), ...warrants.map((w) => `  ${formatWarrant(w)}`)].join('\n'),
+  );
+}
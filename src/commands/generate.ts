@@ -0,0 +1,63 @@
+import chalk from 'chalk';
+import { existsSync, mkdirSync, writeFileSync } from 'node:fs';
+import { dirname, join, resolve } from 'node:path';
+import { CI_WORKFLOW_PATHS, readNeededEnvVars, renderCiWorkflow, type CiProvider } from '../lib/ci-workflow.js';
+import { getVersion } from '../lib/settings.js';
+import { DetectionEmptyError, exitWithError } from '../utils/errors.js';
+import { getDefaultBranch } from '../utils/git-utils.js';
+
+export interface GenerateCiOptions {
+  provider: CiProvider;
+  installDir: string;
+  /** Overwrite a workflow file that's already there */
+  force?: boolean;
+  /** Add a `workos scan secrets` step */
+  scanSecrets?: boolean;
+}
+
+/**
+ * Write a CI workflow that maps the project's WorkOS variables to CI secrets
+ * and checks the integration with `workos doctor`.
+ */
+export async function runGenerateCi(options: GenerateCiOptions): Promise<void> {
+  const installDir = resolve(options.installDir);
+  const relativePath = CI_WORKFLOW_PATHS[options.provider];
+  const path = join(installDir, relativePath);
+
+  if (existsSync(path) && !options.force) {
+    console.error(chalk.red(`${relativePath} already exists. Pass --force to overwrite it.`));
+    process.exit(1);
+  }
+
+  const vars = readNeededEnvVars(installDir);
+  if (vars.length === 0) {
+    const error = new DetectionEmptyError(
+      'No WorkOS variables found in .env.example, .env.local or .env. Run `workos install` first.',
+    );
+    console.error(chalk.red(error.message));
+    exitWithError(error);
+  }
+
+  const workflow = renderCiWorkflow(options.provider, {
+    vars,
+    scanSecrets: options.scanSecrets,
+    branch: getDefaultBranch(),
+    version: getVersion(),
+  });
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, workflow);
+
+  const where =
+    options.provider === 'gitlab-ci'
+      ? 'as CI/CD variables (Settings → CI/CD → Variables)'
+      : 'as repository secrets (Settings → Secrets and variables → Actions)';
+  console.log(chalk.green(`✓ Wrote ${relativePath}`));
+  console.log(`\nAdd these ${where}:\n`);
+  for (const { key, description } of vars) {
+    console.log(`  ${chalk.cyan(key)}${description ? chalk.dim(`  ${description}`) : ''}`);
+  }
+  if (options.provider === 'gitlab-ci') {
+    console.log(`\nThen include it from .gitlab-ci.yml:\n\n  include:\n    - local: ${relativePath}`);
+  }
+  console.log();
+}
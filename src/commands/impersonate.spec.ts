@@ -0,0 +1,125 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const mockGetActiveEnvironment = vi.fn();
+vi.mock('../lib/config-store.js', () => ({
+  getActiveEnvironment: () => mockGetActiveEnvironment(),
+}));
+
+const mockOpenBrowser = vi.fn();
+vi.mock('../utils/browser.js', () => ({
+  isWsl: () => false,
+  openBrowser: (...args: unknown[]) => mockOpenBrowser(...args),
+}));
+
+const { workosRequest, WorkOSApiError } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runImpersonate } = await import('./impersonate.js');
+
+const user = { id: 'user_123', email: 'ada@acme.com' };
+
+const session = {
+  url: 'https://acme.authkit.app/impersonate?token=once',
+  audit_log_event_id: 'audit_log_event_123',
+  expires_at: '2026-01-01T00:10:00.000Z',
+};
+
+describe('impersonate command', () => {
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    mockGetActiveEnvironment.mockReset().mockReturnValue(null);
+    mockOpenBrowser.mockReset().mockResolvedValue(true);
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+  });
+
+  it('looks up the user by email and prints the sign-in URL', async () => {
+    mockRequest.mockResolvedValueOnce({ data: [user] }).mockResolvedValueOnce(session);
+
+    await runImpersonate({ user: 'Ada@acme.com', reason: 'Ticket 4521', apiKey: 'sk_test_123' });
+
+    expect(mockRequest).toHaveBeenCalledWith(
+      expect.objectContaining({ path: '/user_management/users', params: { email: 'ada@acme.com', limit: 1 } }),
+    );
+    expect(mockRequest).toHaveBeenLastCalledWith(
+      expect.objectContaining({
+        method: 'POST',
+        path: '/user_management/users/user_123/impersonate',
+        body: { reason: 'Ticket 4521' },
+      }),
+    );
+    expect(consoleOutput).toEqual([session.url]);
+    expect(errors.join('\n')).toContain('Audit log event: audit_log_event_123');
+    expect(mockOpenBrowser).not.toHaveBeenCalled();
+  });
+
+  it('requires a reason', async () => {
+    await expect(runImpersonate({ user: 'user_123', reason: '  ', apiKey: 'sk_test_123' })).rejects.toThrow(
+      'process.exit',
+    );
+    expect(mockRequest).not.toHaveBeenCalled();
+    expect(errors.join('\n')).toContain('--reason is required');
+  });
+
+  it('refuses a production environment without --production', async () => {
+    mockGetActiveEnvironment.mockReturnValue({ apiKey: 'sk_prod', type: 'production' });
+    await expect(runImpersonate({ user: 'user_123', reason: 'Ticket 4521', apiKey: 'sk_prod' })).rejects.toThrow(
+      'process.exit',
+    );
+    expect(mockRequest).not.toHaveBeenCalled();
+    expect(errors.join('\n')).toContain('Pass --production to confirm.');
+  });
+
+  it('opens the URL in a Chrome profile with --browser-profile', async () => {
+    mockRequest.mockResolvedValue(session);
+    await runImpersonate({
+      user: 'user_123',
+      reason: 'Ticket 4521',
+      production: true,
+      browserProfile: 'Profile 2',
+      apiKey: 'sk_live_123',
+    });
+    expect(mockOpenBrowser).toHaveBeenCalledWith(session.url, {
+      app: [expect.any(String), '--profile-directory=Profile 2'],
+    });
+  });
+
+  it('explains when impersonation is not enabled', async () => {
+    mockRequest.mockRejectedValue(new WorkOSApiError('Forbidden', 403));
+    await expect(runImpersonate({ user: 'user_123', reason: 'Ticket 4521', apiKey: 'sk_test_123' })).rejects.toThrow(
+      'process.exit',
+    );
+    expect(errors.join('\n')).toContain('Impersonation is not enabled for this environment.');
+  });
+});
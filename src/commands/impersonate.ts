@@ -0,0 +1,69 @@
+import chalk from 'chalk';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import { getActiveEnvironment } from '../lib/config-store.js';
+import {
+  browserProfileApp,
+  createImpersonation,
+  isProductionTarget,
+  resolveUserId,
+  type Impersonation,
+} from '../lib/impersonation.js';
+import { isWsl, openBrowser } from '../utils/browser.js';
+
+export interface ImpersonateOptions {
+  /** User ID or email */
+  user: string;
+  reason: string;
+  /** Required to impersonate in a production environment */
+  production?: boolean;
+  open?: boolean;
+  /** Chrome profile directory to open the URL in; implies --open */
+  browserProfile?: string;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+const API_ERRORS: ApiErrorMessages = {
+  forbidden: 'Impersonation is not enabled for this environment. Enable it in the WorkOS dashboard.',
+  notFound: 'User not found.',
+};
+
+/**
+ * Start an impersonation session for a user and print the one-time sign-in URL.
+ */
+export async function runImpersonate(options: ImpersonateOptions): Promise<void> {
+  const reason = options.reason.trim();
+  if (!reason) {
+    console.error(chalk.red('--reason is required and is recorded in the audit log.'));
+    process.exit(1);
+  }
+
+  if (isProductionTarget(options.apiKey, getActiveEnvironment()) && !options.production) {
+    console.error(chalk.red('Refusing to impersonate a user in a production environment.'));
+    console.error(chalk.dim('Pass --production to confirm.'));
+    process.exit(1);
+  }
+
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  let impersonation: Impersonation;
+  try {
+    const userId = await resolveUserId(options.user, api);
+    impersonation = await createImpersonation({ userId, reason }, api);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  console.log(impersonation.url);
+  console.error(chalk.dim(`Audit log event: ${impersonation.auditLogEventId ?? 'not returned'}`));
+  if (impersonation.expiresAt) {
+    console.error(chalk.dim(`The URL works once and expires ${new Date(impersonation.expiresAt).toLocaleString()}.`));
+  }
+
+  if (options.browserProfile) {
+    // Under WSL the browser is the Windows one
+    const platform = isWsl() ? 'win32' : process.platform;
+    void openBrowser(impersonation.url, { app: browserProfileApp(options.browserProfile, platform) });
+  } else if (options.open) {
+    void openBrowser(impersonation.url);
+  }
+}
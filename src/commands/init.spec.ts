@@ -0,0 +1,123 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { fileURLToPath } from 'node:url';
+import { dashboardSteps, prepareEnvFile, resolveTemplate, substituteProjectName } from './init.js';
+import { bundledTemplateDir, getTemplate, getTemplates } from '../lib/templates.js';
+
+const SKILLS_DIR = fileURLToPath(new URL('../../skills', import.meta.url));
+
+describe('init', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'init-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('templates', () => {
+    it('has a starter for each integration that declares one', async () => {
+      expect((await getTemplates()).map((t) => t.name)).toEqual([
+        'nextjs-authkit',
+        'remix',
+        'express',
+        'flask',
+        'go-gin',
+      ]);
+    });
+
+    it('each template points at a bundled skill', async () => {
+      for (const template of await getTemplates()) {
+        expect(existsSync(join(SKILLS_DIR, template.skill, 'SKILL.md'))).toBe(true);
+      }
+    });
+
+    it('finds templates by name', async () => {
+      expect((await getTemplate('go-gin'))?.port).toBe(8080);
+      expect((await getTemplate('flask'))?.callbackPath).toBe('/callback');
+      expect(await getTemplate('nope')).toBeUndefined();
+    });
+
+    it('each template has a repo or bundled files', async () => {
+      for (const template of await getTemplates()) {
+        const bundled = bundledTemplateDir(template);
+        expect(Boolean(template.repo) !== Boolean(bundled)).toBe(true);
+        if (bundled) expect(existsSync(join(bundled, '.env.example'))).toBe(true);
+      }
+    });
+  });
+
+  describe('resolveTemplate', () => {
+    it('picks the template from --framework', async () => {
+      expect(await resolveTemplate({ framework: 'gin' })).toBe(await getTemplate('go-gin'));
+      expect(await resolveTemplate({ framework: 'Flask' })).toBe(await getTemplate('flask'));
+      expect(await resolveTemplate({})).toBe(await getTemplate('nextjs-authkit'));
+    });
+
+    it('rejects unknown or conflicting choices', async () => {
+      expect(await resolveTemplate({ framework: 'rails' })).toBe('Unknown framework "rails".');
+      expect(await resolveTemplate({ template: 'nope' })).toBe('Unknown template "nope".');
+      expect(await resolveTemplate({ framework: 'express', template: 'go-gin' })).toMatch(/uses the express template/);
+      expect(await resolveTemplate({ framework: 'express', template: 'express' })).toBe(await getTemplate('express'));
+    });
+  });
+
+  describe('dashboardSteps', () => {
+    it('lists the redirect URI to add when it could not be configured', async () => {
+      const flask = (await getTemplate('flask'))!;
+      const steps = dashboardSteps(flask, false);
+
+      expect(steps.join('\n')).toContain('add http://localhost:5000/callback as a redirect URI');
+      expect(dashboardSteps(flask, true)).toHaveLength(1);
+    });
+  });
+
+  describe('substituteProjectName', () => {
+    it('replaces placeholders and the package name', async () => {
+      writeFileSync(join(dir, 'package.json'), JSON.stringify({ name: 'next-authkit-example', version: '1.0.0' }));
+      writeFileSync(join(dir, 'README.md'), '# {{PROJECT_NAME}}\n');
+
+      await substituteProjectName(dir, 'my-app');
+
+      expect(JSON.parse(readFileSync(join(dir, 'package.json'), 'utf-8')).name).toBe('my-app');
+      expect(readFileSync(join(dir, 'README.md'), 'utf-8')).toBe('# my-app\n');
+    });
+
+    it('renames the Go module and its imports', async () => {
+      const module = 'github.com/workos/authkit-go-gin-example';
+      writeFileSync(join(dir, 'go.mod'), `module ${module}\n\ngo 1.22\n`);
+      mkdirSync(join(dir, 'handlers'));
+      writeFileSync(join(dir, 'main.go'), `package main\n\nimport "${module}/handlers"\n`);
+
+      await substituteProjectName(dir, 'my-app');
+
+      expect(readFileSync(join(dir, 'go.mod'), 'utf-8')).toContain('module my-app\n');
+      expect(readFileSync(join(dir, 'main.go'), 'utf-8')).toContain('import "my-app/handlers"');
+    });
+  });
+
+  describe('prepareEnvFile', () => {
+    it('seeds the env file from the example and gitignores it', async () => {
+      const template = (await getTemplate('express'))!;
+      writeFileSync(join(dir, '.env.example'), 'WORKOS_API_KEY=\nPORT=3000\n');
+      writeFileSync(join(dir, '.gitignore'), 'node_modules');
+
+      prepareEnvFile(dir, template);
+
+      expect(readFileSync(join(dir, '.env'), 'utf-8')).toContain('PORT=3000');
+      expect(readFileSync(join(dir, '.gitignore'), 'utf-8')).toBe('node_modules\n.env\n');
+    });
+
+    it('leaves an existing ignore rule alone', async () => {
+      writeFileSync(join(dir, '.gitignore'), '.env*\n');
+
+      prepareEnvFile(dir, (await getTemplate('nextjs-authkit'))!);
+
+      expect(readFileSync(join(dir, '.gitignore'), 'utf-8')).toBe('.env*\n');
+    });
+  });
+});
@@ -0,0 +1,256 @@
+import chalk from 'chalk';
+import { cpSync, existsSync, mkdtempSync, readdirSync, readFileSync, writeFileSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join, relative, resolve } from 'node:path';
+import fg from 'fast-glob';
+import {
+  bundledTemplateDir,
+  getTemplate,
+  getTemplateForFramework,
+  getTemplates,
+  type AppTemplate,
+} from '../lib/templates.js';
+import { resolveStagingCredentials } from '../lib/staging-credentials.js';
+import { writeEnvLocal } from '../lib/env-writer.js';
+import { applyFileEdits, type FileEdit } from '../lib/atomic-write.js';
+import { autoConfigureWorkOSEnvironment } from '../lib/workos-management.js';
+import { execFileNoThrow } from '../utils/exec-file.js';
+import { formatTable } from '../utils/table.js';
+import { WORKOS_DASHBOARD_URL } from '../lib/constants.js';
+import clack from '../utils/clack.js';
+
+export interface InitOptions {
+  name?: string;
+  template?: string;
+  /** Short framework name (next, express, gin, flask, ...), an alternative to `template` */
+  framework?: string;
+  /** Scaffold into a non-empty directory, overwriting files the template also has */
+  force?: boolean;
+  list?: boolean;
+}
+
+const PROJECT_NAME_PATTERN = /^[a-z0-9][a-z0-9._-]*$/;
+const PLACEHOLDERS = ['{{PROJECT_NAME}}', '__PROJECT_NAME__'];
+const TEXT_FILE_GLOB = '**/*.{json,md,ts,tsx,js,jsx,mjs,cjs,go,mod,py,html,yml,yaml,toml,env,example}';
+
+export async function formatTemplateList(): Promise<string> {
+  return formatTable(
+    [{ header: 'Template' }, { header: 'Framework' }, { header: 'Description' }, { header: 'Skill' }],
+    (await getTemplates()).map((t) => [t.name, t.framework, t.description, chalk.dim(t.skill)]),
+  );
+}
+
+/**
+ * Replace project-name placeholders and rename package/module identifiers.
+ */
+export async function substituteProjectName(dir: string, name: string): Promise<void> {
+  const files = await fg(TEXT_FILE_GLOB, { cwd: dir, dot: true, ignore: ['**/node_modules/**', '**/.git/**'] });
+  for (const file of files) {
+    const path = join(dir, file);
+    const content = readFileSync(path, 'utf-8');
+    const replaced = PLACEHOLDERS.reduce((acc, placeholder) => acc.split(placeholder).join(name), content);
+    if (replaced !== content) writeFileSync(path, replaced);
+  }
+
+  const packageJsonPath = join(dir, 'package.json');
+  if (existsSync(packageJsonPath)) {
+    const pkg = JSON.parse(readFileSync(packageJsonPath, 'utf-8'));
+    pkg.name = name;
+    writeFileSync(packageJsonPath, JSON.stringify(pkg, null, 2) + '\n');
+  }
+
+  // Go modules: rename the module and every import of it
+  const goModPath = join(dir, 'go.mod');
+  if (existsSync(goModPath)) {
+    const goMod = readFileSync(goModPath, 'utf-8');
+    const oldModule = /^module\s+(\S+)/m.exec(goMod)?.[1];
+    if (oldModule && oldModule !== name) {
+      writeFileSync(goModPath, goMod.replace(/^module\s+\S+/m, `module ${name}`));
+      for (const file of await fg('**/*.go', { cwd: dir })) {
+        const path = join(dir, file);
+        const content = readFileSync(path, 'utf-8');
+        const replaced = content.split(`"${oldModule}/`).join(`"${name}/`);
+        if (replaced !== content) writeFileSync(path, replaced);
+      }
+    }
+  }
+}
+
+/**
+ * Seed the env file from the template's example and make sure it is gitignored.
+ */
+export function prepareEnvFile(dir: string, template: AppTemplate): void {
+  const edits: FileEdit[] = [];
+
+  const envPath = join(dir, template.envFile);
+  const examplePath = [`${template.envFile}.example`, '.env.example'].map((f) => join(dir, f)).find(existsSync);
+  if (examplePath && !existsSync(envPath)) {
+    edits.push({ path: envPath, content: readFileSync(examplePath, 'utf-8') });
+  }
+
+  const gitignorePath = join(dir, '.gitignore');
+  const gitignore = existsSync(gitignorePath) ? readFileSync(gitignorePath, 'utf-8') : '';
+  const ignored = gitignore.split(/\r?\n/).some((line) => {
+    const entry = line.trim();
+    return entry === template.envFile || entry === `/${template.envFile}` || entry === '.env*';
+  });
+  if (!ignored) {
+    const prefix = gitignore && !gitignore.endsWith('\n') ? '\n' : '';
+    edits.push({ path: gitignorePath, content: `${gitignore}${prefix}${template.envFile}\n` });
+  }
+
+  applyFileEdits(edits);
+}
+
+/**
+ * Pick the template from `--framework` or `--template`. Returns an error
+ * message when neither matches or they disagree.
+ */
+export async function resolveTemplate(
+  options: Pick<InitOptions, 'template' | 'framework'>,
+): Promise<AppTemplate | string> {
+  if (options.framework) {
+    const template = await getTemplateForFramework(options.framework);
+    if (!template) return `Unknown framework "${options.framework}".`;
+    if (options.template && options.template !== template.name) {
+      return `--framework ${options.framework} uses the ${template.name} template, not ${options.template}.`;
+    }
+    return template;
+  }
+  return (await getTemplate(options.template ?? 'nextjs-authkit')) ?? `Unknown template "${options.template}".`;
+}
+
+/**
+ * Copy the template's files into a fresh directory: a shallow clone of its
+ * repo without the history, or the bundled starter.
+ */
+async function fetchTemplate(template: AppTemplate): Promise<string> {
+  const dir = mkdtempSync(join(tmpdir(), 'workos-init-'));
+  const bundled = bundledTemplateDir(template);
+  if (bundled) {
+    cpSync(bundled, dir, { recursive: true });
+    return dir;
+  }
+  const clone = await execFileNoThrow('git', ['clone', '--depth', '1', template.repo!, dir]);
+  if (clone.status !== 0) {
+    rmSync(dir, { recursive: true, force: true });
+    throw new Error(clone.stderr.trim());
+  }
+  rmSync(join(dir, '.git'), { recursive: true, force: true });
+  return dir;
+}
+
+/**
+ * Dashboard settings the app needs, for the next steps. When the installer
+ * couldn't configure them itself they're listed as things to do.
+ */
+export function dashboardSteps(template: AppTemplate, configured: boolean): string[] {
+  const origin = `http://localhost:${template.port}`;
+  const redirectUri = `${origin}${template.callbackPath}`;
+  if (configured) {
+    return [`Redirect URI ${redirectUri} and homepage ${origin} are set in ${WORKOS_DASHBOARD_URL}`];
+  }
+  return [
+    `In ${WORKOS_DASHBOARD_URL}, under Redirects:`,
+    `  add ${redirectUri} as a redirect URI`,
+    `  set the app homepage URL to ${origin}`,
+  ];
+}
+
+async function git(cwd: string, ...args: string[]): Promise<void> {
+  const result = await execFileNoThrow('git', args, { cwd });
+  if (result.status !== 0) {
+    throw new Error(`git ${args[0]} failed: ${result.stderr.trim()}`);
+  }
+}
+
+export async function runInit(options: InitOptions): Promise<void> {
+  if (options.list) {
+    console.log(await formatTemplateList());
+    return;
+  }
+
+  if (!options.name) {
+    console.error(chalk.red('Project name is required: workos init <name> --template <template>'));
+    process.exit(1);
+  }
+  if (!PROJECT_NAME_PATTERN.test(options.name)) {
+    console.error(chalk.red('Project name must be lowercase letters, numbers, ".", "_" or "-".'));
+    process.exit(1);
+  }
+
+  const template = await resolveTemplate(options);
+  if (typeof template === 'string') {
+    console.error(chalk.red(template));
+    console.log(await formatTemplateList());
+    process.exit(1);
+  }
+
+  const targetDir = resolve(options.name);
+  const hadGit = existsSync(join(targetDir, '.git'));
+  if (existsSync(targetDir) && readdirSync(targetDir).length > 0 && !options.force) {
+    console.error(
+      chalk.red(`Directory ${options.name} already exists and is not empty. Pass --force to scaffold into it.`),
+    );
+    process.exit(1);
+  }
+
+  clack.intro(chalk.inverse(`Creating ${options.name} from ${template.name}`));
+
+  const spinner = clack.spinner();
+  spinner.start(template.repo ? `Cloning ${template.repo}` : `Copying the ${template.name} starter`);
+  let sourceDir: string;
+  try {
+    sourceDir = await fetchTemplate(template);
+  } catch (error) {
+    spinner.stop(template.repo ? 'Clone failed' : 'Copy failed');
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+  cpSync(sourceDir, targetDir, { recursive: true, force: true });
+  rmSync(sourceDir, { recursive: true, force: true });
+  spinner.stop(template.repo ? 'Template cloned' : 'Starter copied');
+
+  let dashboardConfigured = false;
+  try {
+    await substituteProjectName(targetDir, options.name);
+    prepareEnvFile(targetDir, template);
+
+    const { clientId, apiKey } = await resolveStagingCredentials();
+    const redirectUri = `http://localhost:${template.port}${template.callbackPath}`;
+    writeEnvLocal(
+      targetDir,
+      { WORKOS_API_KEY: apiKey, WORKOS_CLIENT_ID: clientId, [template.redirectUriEnvVar]: redirectUri },
+      template.envFile,
+    );
+    clack.log.success(`Wrote ${template.envFile}`);
+
+    const configured = await autoConfigureWorkOSEnvironment(apiKey, template.integration, template.port, {
+      redirectUri,
+    });
+    dashboardConfigured = configured !== null;
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+
+  if (hadGit) {
+    clack.log.info('Left the existing git repository alone; review and commit the scaffolded files yourself');
+  } else {
+    try {
+      await git(targetDir, 'init');
+      await git(targetDir, 'add', '-A');
+      await git(targetDir, 'commit', '-m', `Initial commit from ${template.name} template`);
+      clack.log.success('Initialized git repository');
+    } catch (error) {
+      clack.log.warn(`Git setup incomplete: ${error instanceof Error ? error.message : String(error)}`);
+    }
+  }
+
+  const cdPath = relative(process.cwd(), targetDir) || '.';
+  clack.outro(
+    `Next steps:\n\n  ${[`cd ${cdPath}`, ...template.runCommands].join('\n  ')}\n\n` +
+      `Then open http://localhost:${template.port} and sign in.\n\n` +
+      `Dashboard:\n\n  ${dashboardSteps(template, dashboardConfigured).join('\n  ')}`,
+  );
+}
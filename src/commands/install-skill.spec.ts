@@ -3,7 +3,15 @@ import { existsSync, mkdirSync, writeFileSync, rmSync, readFileSync } from 'fs';
 import { join } from 'path';
 import { mkdtempSync } from 'fs';
 import { tmpdir } from 'os';
-import { createAgents, discoverSkills, detectAgents, installSkill, type AgentConfig } from './install-skill.js';
+import {
+  createAgents,
+  discoverSkills,
+  detectAgents,
+  installSkill,
+  verifyBundle,
+  type AgentConfig,
+} from './install-skill.js';
+import { buildManifest } from '../lib/skill-integrity.js';
 
 describe('install-skill', () => {
   let testDir: string;
@@ -195,4 +203,51 @@ describe('install-skill', () => {
       expect(content).toContain('# Updated Skill');
     });
   });
+
+  describe('verifyBundle', () => {
+    beforeEach(() => {
+      mkdirSync(join(skillsDir, 'test-skill'));
+      writeFileSync(join(skillsDir, 'test-skill', 'SKILL.md'), '# Test Skill');
+    });
+
+    it('accepts official skills matching the manifest', async () => {
+      const manifest = buildManifest(skillsDir);
+      const bundle = { dir: skillsDir, source: 'workos', manifest, signature: null, official: true };
+
+      expect(await verifyBundle(bundle, ['test-skill'], {})).toBeNull();
+    });
+
+    it('rejects official skills that were modified', async () => {
+      const manifest = buildManifest(skillsDir);
+      writeFileSync(join(skillsDir, 'test-skill', 'SKILL.md'), '# Tampered');
+      const bundle = { dir: skillsDir, source: 'workos', manifest, signature: null, official: true };
+
+      expect(await verifyBundle(bundle, ['test-skill'], {})).toContain('does not match its manifest');
+    });
+
+    it('rejects unsigned third-party bundles with --require-signed', async () => {
+      const bundle = { dir: skillsDir, source: skillsDir, manifest: null, signature: null, official: false };
+
+      expect(await verifyBundle(bundle, ['test-skill'], { requireSigned: true, publicKey: 'key' })).toContain(
+        'is not signed',
+      );
+    });
+
+    it('rejects a signed bundle without a manifest before checking the signature', async () => {
+      const bundle = { dir: skillsDir, source: skillsDir, manifest: null, signature: 'sig', official: false };
+
+      expect(await verifyBundle(bundle, ['test-skill'], { publicKey: 'key' })).toBe(
+        `Signed bundle from ${skillsDir} has no manifest.json`,
+      );
+    });
+
+    it('rejects a signed bundle whose manifest does not parse', async () => {
+      writeFileSync(join(skillsDir, 'manifest.json'), '{ not json');
+      const bundle = { dir: skillsDir, source: skillsDir, manifest: null, signature: 'sig', official: false };
+
+      expect(await verifyBundle(bundle, ['test-skill'], { publicKey: 'key', requireSigned: true })).toBe(
+        `Signed bundle from ${skillsDir} has an invalid manifest.json`,
+      );
+    });
+  });
 });
@@ -1,9 +1,28 @@
-import { homedir } from 'os';
-import { join, dirname } from 'path';
+import { homedir, tmpdir } from 'os';
+import { join, dirname, resolve } from 'path';
 import { existsSync } from 'fs';
-import { mkdir, copyFile, readdir } from 'fs/promises';
+import { mkdir, copyFile, readdir, readFile, writeFile, mkdtemp } from 'fs/promises';
 import { fileURLToPath } from 'url';
 import chalk from 'chalk';
+import clack from '../utils/clack.js';
+import { pickMany } from '../utils/fuzzy-picker.js';
+import { createProgressBar } from '../utils/progress-bar.js';
+import { attemptsSuffix } from '../lib/http-retry.js';
+import { fetchBundleFile, fetchCachedFile, getSkillCacheDir } from '../lib/skill-download.js';
+import {
+  MANIFEST_FILE,
+  SIGNATURE_FILE,
+  digestSkillFiles,
+  hashSkillFiles,
+  readManifest,
+  readSkillLock,
+  skillLockKey,
+  verifyMinisign,
+  verifySkill,
+  writeSkillLock,
+  type SkillManifest,
+} from '../lib/skill-integrity.js';
+import { exitWithError, RegistryUnreachableError, SkillNotFoundError } from '../utils/errors.js';
 
 export interface AgentConfig {
   name: string;
@@ -44,7 +63,23 @@ export function createAgents(home: string): Record<string, AgentConfig> {
 export interface InstallSkillOptions {
   list?: boolean;
   skill?: string[];
+  /** Choose the skills from a picker instead of installing them all */
+  pick?: boolean;
   agent?: string[];
+  /** Third-party skill bundle: local directory or https URL */
+  from?: string;
+  /** Refuse bundles without a valid minisign signature */
+  requireSigned?: boolean;
+  /** Minisign public key for --require-signed (or WORKOS_SKILLS_PUBLIC_KEY) */
+  publicKey?: string;
+}
+
+export interface SkillBundle {
+  dir: string;
+  source: string;
+  manifest: SkillManifest | null;
+  signature: string | null;
+  official: boolean;
 }
 
 export function getSkillsDir(): string {
@@ -59,6 +94,157 @@ export async function discoverSkills(skillsDir: string): Promise<string[]> {
   return entries.filter((e) => e.isDirectory() && existsSync(join(skillsDir, e.name, 'SKILL.md'))).map((e) => e.name);
 }
 
+function formatMegabytes(bytes: number): string {
+  return `${(bytes / 1024 / 1024).toFixed(1)} MB`;
+}
+
+/**
+ * Download a remote bundle into a temp directory.
+ * Only files listed in the bundle manifest are fetched, and each is checked
+ * against its digest before being written. Files already in the skill cache
+ * aren't downloaded again, and the rest show their progress on stderr.
+ */
+async function downloadBundle(url: string): Promise<string> {
+  const base = url.replace(/\/+$/, '');
+  const { response: manifestRes, attempts } = await fetchBundleFile(`${base}/${MANIFEST_FILE}`);
+  if (!manifestRes.ok) {
+    throw new RegistryUnreachableError(
+      `Could not fetch ${MANIFEST_FILE} from ${base} (HTTP ${manifestRes.status}${attemptsSuffix(attempts)})`,
+    );
+  }
+  const manifestText = await manifestRes.text();
+  const manifest = JSON.parse(manifestText) as SkillManifest;
+
+  const dir = await mkdtemp(join(tmpdir(), 'workos-skills-'));
+  await writeFile(join(dir, MANIFEST_FILE), manifestText);
+
+  const { response: sigRes } = await fetchBundleFile(`${base}/${SIGNATURE_FILE}`);
+  if (sigRes.ok) {
+    await writeFile(join(dir, SIGNATURE_FILE), await sigRes.text());
+  }
+
+  const files = Object.entries(manifest.skills ?? {}).flatMap(([skillName, { files }]) =>
+    Object.entries(files).map(([file, digest]) => ({ skillName, file, digest })),
+  );
+  const progress = { total: files.length, done: 0, errors: 0, skipped: 0, startedAt: Date.now() };
+  const bar = createProgressBar();
+  for (const { skillName, file, digest } of files) {
+    if (file.split('/').includes('..') || skillName.includes('/') || skillName.includes('..')) {
+      throw new Error(`Refusing unsafe path in manifest: ${skillName}/${file}`);
+    }
+    const name = `${skillName}/${file}`;
+    const { content, cached } = await fetchCachedFile(`${base}/${name}`, digest, {
+      name,
+      manifestVersion: manifest.version,
+      onProgress: ({ bytes, total }) => {
+        const size = total ? `${formatMegabytes(bytes)}/${formatMegabytes(total)}` : formatMegabytes(bytes);
+        bar.update({ ...progress, detail: `${name} ${size}` });
+      },
+    });
+    if (cached) progress.skipped++;
+    else progress.done++;
+    bar.update(progress);
+    const target = join(dir, skillName, file);
+    await mkdir(dirname(target), { recursive: true });
+    await writeFile(target, content);
+  }
+  bar.done();
+  if (progress.skipped > 0) {
+    const cached = `${progress.skipped} of ${files.length} file(s) from the skill cache (${getSkillCacheDir()})`;
+    console.log(chalk.dim(cached));
+  }
+
+  return dir;
+}
+
+export async function loadSkillBundle(from?: string): Promise<SkillBundle> {
+  if (!from) {
+    const dir = getSkillsDir();
+    return { dir, source: 'workos', manifest: readManifest(dir), signature: null, official: true };
+  }
+
+  const isUrl = /^https?:\/\//.test(from);
+  if (isUrl && !from.startsWith('https://')) {
+    throw new Error('Remote skill bundles must be served over https');
+  }
+  const dir = isUrl ? await downloadBundle(from) : resolve(from);
+  const sigPath = join(dir, SIGNATURE_FILE);
+  const signature = existsSync(sigPath) ? await readFile(sigPath, 'utf-8') : null;
+
+  return { dir, source: isUrl ? from : dir, manifest: readManifest(dir), signature, official: false };
+}
+
+/**
+ * Check a bundle before anything from it is installed.
+ * Returns an error message, or null when the bundle may be used.
+ */
+export async function verifyBundle(
+  bundle: SkillBundle,
+  skills: string[],
+  options: InstallSkillOptions,
+): Promise<string | null> {
+  if (bundle.official && !bundle.manifest) {
+    return `Bundled skills manifest is missing or invalid (${join(bundle.dir, MANIFEST_FILE)})`;
+  }
+
+  if (bundle.manifest) {
+    for (const skill of skills) {
+      const result = verifySkill(bundle.dir, skill, bundle.manifest);
+      if (!result.ok) {
+        const files = [...result.mismatched, ...result.missing, ...result.unexpected].join(', ');
+        return `Skill "${skill}" does not match its manifest (${files})`;
+      }
+    }
+  }
+
+  // The signature covers the manifest, and the manifest the files; without a valid one it proves nothing
+  if (bundle.signature && !bundle.manifest) {
+    return existsSync(join(bundle.dir, MANIFEST_FILE))
+      ? `Signed bundle from ${bundle.source} has an invalid ${MANIFEST_FILE}`
+      : `Signed bundle from ${bundle.source} has no ${MANIFEST_FILE}`;
+  }
+
+  const publicKey = options.publicKey ?? process.env.WORKOS_SKILLS_PUBLIC_KEY;
+  const signed =
+    !!bundle.signature &&
+    !!publicKey &&
+    verifyMinisign(await readFile(join(bundle.dir, MANIFEST_FILE)), bundle.signature, publicKey);
+
+  if (bundle.signature && publicKey && !signed) {
+    return `Invalid signature for skill bundle from ${bundle.source}`;
+  }
+
+  if (options.requireSigned && !bundle.official) {
+    if (!publicKey) return '--require-signed needs --public-key or WORKOS_SKILLS_PUBLIC_KEY';
+    if (!bundle.signature) return `Skill bundle from ${bundle.source} is not signed`;
+  }
+
+  if (!bundle.official && !signed) {
+    const lock = await readSkillLock();
+    if (!lock.trustedSources.includes(bundle.source)) {
+      console.log(chalk.yellow(`\nUnsigned third-party skills from ${chalk.bold(bundle.source)}:\n`));
+      for (const skill of skills) {
+        for (const file of Object.keys(hashSkillFiles(join(bundle.dir, skill))).sort()) {
+          console.log(`  ${skill}/${file}`);
+        }
+      }
+      console.log();
+
+      const confirmed = await clack.confirm({
+        message: 'These files will be given to your coding agent as instructions. Install them?',
+        initialValue: false,
+      });
+      if (clack.isCancel(confirmed) || !confirmed) {
+        return 'Installation cancelled';
+      }
+      lock.trustedSources.push(bundle.source);
+      await writeSkillLock(lock);
+    }
+  }
+
+  return null;
+}
+
 export function detectAgents(agents: Record<string, AgentConfig>, filter?: string[]): AgentConfig[] {
   const detected: AgentConfig[] = [];
 
@@ -96,7 +282,14 @@ export async function installSkill(
 export async function runInstallSkill(options: InstallSkillOptions): Promise<void> {
   const home = homedir();
   const agents = createAgents(home);
-  const skillsDir = getSkillsDir();
+  let bundle: SkillBundle;
+  try {
+    bundle = await loadSkillBundle(options.from);
+  } catch (error) {
+    console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+    exitWithError(error);
+  }
+  const skillsDir = bundle.dir;
   const skills = await discoverSkills(skillsDir);
 
   if (options.list) {
@@ -108,12 +301,24 @@ export async function runInstallSkill(options: InstallSkillOptions): Promise<voi
     return;
   }
 
-  const targetSkills = options.skill ? skills.filter((s) => options.skill!.includes(s)) : skills;
+  let targetSkills = options.skill ? skills.filter((s) => options.skill!.includes(s)) : skills;
+
+  if (options.pick && targetSkills.length > 1) {
+    const picked = await pickMany({
+      message: 'Select skills to install',
+      items: targetSkills.map((skill) => ({ value: skill, label: skill })),
+      initialValues: targetSkills,
+      flag: '--skill',
+    });
+    if (clack.isCancel(picked)) process.exit(0);
+    targetSkills = picked as string[];
+  }
 
   if (targetSkills.length === 0) {
     console.error(chalk.red('No matching skills found.'));
     console.log('Available skills:', skills.join(', '));
-    process.exit(1);
+    const requested = options.skill ? `No skill named ${options.skill.join(', ')}` : 'The bundle has no skills';
+    exitWithError(new SkillNotFoundError(requested));
   }
 
   const targetAgents = detectAgents(agents, options.agent);
@@ -128,8 +333,16 @@ export async function runInstallSkill(options: InstallSkillOptions): Promise<voi
     process.exit(1);
   }
 
+  const verificationError = await verifyBundle(bundle, targetSkills, options);
+  if (verificationError) {
+    console.error(chalk.red(verificationError));
+    process.exit(1);
+  }
+
   console.log(chalk.bold('\nInstalling skills...\n'));
 
+  const lock = await readSkillLock();
+
   const results: Array<{
     skill: string;
     agent: string;
@@ -138,8 +351,17 @@ export async function runInstallSkill(options: InstallSkillOptions): Promise<voi
   }> = [];
 
   for (const skill of targetSkills) {
+    const digest = digestSkillFiles(hashSkillFiles(join(skillsDir, skill)));
     for (const agent of targetAgents) {
+      const key = skillLockKey(agent.name, skill);
+      const previous = lock.skills[key];
+      if (previous && previous.digest !== digest) {
+        console.log(chalk.dim(`  ${skill} → ${agent.displayName}: content changed since last install`));
+      }
       const result = await installSkill(skillsDir, skill, agent);
+      if (result.success) {
+        lock.skills[key] = { digest, source: bundle.source, installedAt: new Date().toISOString() };
+      }
       results.push({
         skill,
         agent: agent.displayName,
@@ -148,6 +370,8 @@ export async function runInstallSkill(options: InstallSkillOptions): Promise<voi
     }
   }
 
+  await writeSkillLock(lock);
+
   const successful = results.filter((r) => r.success);
   const failed = results.filter((r) => !r.success);
 
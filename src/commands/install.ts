@@ -4,8 +4,16 @@ import { isNonInteractiveEnvironment } from '../utils/environment.js';
 import clack from '../utils/clack.js';
 import chalk from 'chalk';
 import type { ArgumentsCamelCase } from 'yargs';
+import type { MigrationContext } from '../migrate/types.js';
+import type { SummaryFormat } from '../utils/run-summary.js';
+import { exitingWith, exitWithError } from '../utils/errors.js';
+import { reserveStdoutForEvents, type EventsFormat } from '../lib/event-stream.js';
+import { writeMachineMessage, writeMachineResult } from '../lib/installer-proto.js';
+import { isMachineMode, setMachineMode } from '../utils/machine-prompts.js';
+import { getVersion } from '../lib/settings.js';
+import type { LocalSkill } from '../lib/skill-authoring.js';
 
-interface InstallArgs {
+export interface InstallArgs {
   debug?: boolean;
   local?: boolean;
   ci?: boolean;
@@ -19,7 +27,58 @@ interface InstallArgs {
   installDir?: string;
   integration?: string;
   forceInstall?: boolean;
+  forceReinstall?: boolean;
+  instructions?: string;
   dashboard?: boolean;
+  migration?: MigrationContext;
+  showDiffs?: boolean;
+  dryRun?: boolean;
+  /** Write the changes to this patch file instead of the working tree */
+  diffOnly?: string;
+  /** Keep each edited file's original as <file>.workos.bak */
+  keepBackups?: boolean;
+  summaryFormat?: SummaryFormat;
+  /** Same as --summary-format json; also prints a failure as a JSON error object */
+  json?: boolean;
+  /** Stream installer events as NDJSON on stdout */
+  events?: EventsFormat;
+  /** Speak the installer protocol (lib/installer-proto.ts) on stdout and stdin */
+  machine?: boolean;
+  /** Module name on streamed events (set by `migrate --modules` for each child run) */
+  eventsModule?: string;
+  reportPath?: string;
+  createPr?: boolean;
+  /** Commit even on a protected or default branch */
+  allowMain?: boolean;
+  /** Commit and push the branch without opening a PR */
+  push?: boolean;
+  maxRepairAttempts?: number;
+  skipChecks?: boolean;
+  noFormat?: boolean;
+  /** Agent time limits in ms, parsed from durations like `15m` */
+  timeout?: number;
+  idleTimeout?: number;
+  /** Token budget the pre-flight estimate is checked against */
+  maxTokens?: number;
+  /** Docker Compose service the app runs in, e.g. `web` or `web:3000` */
+  composeService?: string;
+  /** Skip confirmations: project hooks run without asking (migrate: no file checklist either) */
+  yes?: boolean;
+  /** Unpublished skill to run instead of the bundled one (`workos skills run`) */
+  localSkill?: LocalSkill;
+}
+
+/**
+ * `--machine`: protocol messages on stdout, everything else on stderr,
+ * prompt answers from stdin, and a closing `result` however the process
+ * exits.
+ */
+export function startMachineMode(): void {
+  if (isMachineMode()) return;
+  reserveStdoutForEvents();
+  setMachineMode(true);
+  writeMachineMessage({ type: 'hello', cliVersion: getVersion() });
+  process.once('exit', (code) => writeMachineResult(code, exitingWith()));
 }
 
 /**
@@ -27,6 +86,8 @@ interface InstallArgs {
  */
 export async function handleInstall(argv: ArgumentsCamelCase<InstallArgs>): Promise<void> {
   const options = { ...argv };
+  if (options.events === 'ndjson') reserveStdoutForEvents();
+  if (options.machine) startMachineMode();
 
   // CI mode validation
   if (options.ci) {
@@ -45,14 +106,14 @@ export async function handleInstall(argv: ArgumentsCamelCase<InstallArgs>): Prom
       clack.log.error('CI mode requires --install-dir (directory to install WorkOS AuthKit in)');
       process.exit(1);
     }
-  } else if (isNonInteractiveEnvironment()) {
+  } else if (!options.machine && isNonInteractiveEnvironment()) {
     clack.intro(chalk.inverse('WorkOS AuthKit Installer'));
     clack.log.error(
       'This installer requires an interactive terminal (TTY) to run.\n' +
         'It appears you are running in a non-interactive environment.\n' +
         'Please run the installer in an interactive terminal.\n\n' +
         'For CI/CD environments, use --ci mode:\n' +
-        '  workos install --ci --api-key sk_xxx --client-id client_xxx',
+        '  workos install --ci --api-key sk_xxx --client-id client_xxx --install-dir .',
     );
     process.exit(1);
   }
@@ -70,6 +131,6 @@ export async function handleInstall(argv: ArgumentsCamelCase<InstallArgs>): Prom
     if (logPath) {
       clack.log.info(`Debug logs: ${logPath}`);
     }
-    process.exit(1);
+    exitWithError(err, { json: options.json || options.summaryFormat === 'json' });
   }
 }
@@ -0,0 +1,85 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../utils/clack.js', () => ({
+  default: {
+    log: { step: vi.fn(), info: vi.fn(), success: vi.fn(), error: vi.fn() },
+    spinner: () => ({ start: vi.fn(), stop: vi.fn() }),
+  },
+}));
+
+const mockSaveCredentials = vi.fn();
+vi.mock('../lib/credentials.js', () => ({
+  getAccessToken: () => null,
+  getCredentials: () => null,
+  isTokenExpired: () => false,
+  saveCredentials: (...args: unknown[]) => mockSaveCredentials(...args),
+}));
+
+vi.mock('../lib/settings.js', () => ({
+  getAuthkitDomain: () => 'https://auth.example.com',
+  getCliAuthClientId: () => 'client_cli',
+}));
+
+vi.mock('../lib/token-refresh.js', () => ({
+  refreshSession: vi.fn(),
+}));
+
+vi.mock('../utils/browser.js', () => ({
+  openBrowser: async () => false,
+}));
+
+const { runLogin } = await import('./login.js');
+const { proxyFetchOptions, setProxyOverride } = await import('../lib/proxy.js');
+
+function jwt(claims: Record<string, unknown>): string {
+  return `eyJhbGciOiJub25lIn0.${Buffer.from(JSON.stringify(claims)).toString('base64url')}.sig`;
+}
+
+const deviceAuth = {
+  device_code: 'device_123',
+  user_code: 'ABCD-EFGH',
+  verification_uri: 'https://auth.example.com/device',
+  verification_uri_complete: 'https://auth.example.com/device?code=ABCD-EFGH',
+  expires_in: 300,
+  interval: 0.001,
+};
+
+describe('runLogin', () => {
+  let mockFetch: ReturnType<typeof vi.fn>;
+
+  beforeEach(() => {
+    mockSaveCredentials.mockReset();
+    mockFetch = vi.spyOn(globalThis, 'fetch') as unknown as ReturnType<typeof vi.fn>;
+    mockFetch
+      .mockResolvedValueOnce(Response.json(deviceAuth))
+      .mockResolvedValueOnce(
+        Response.json({
+          access_token: jwt({ exp: 4102444800 }),
+          id_token: jwt({ sub: 'user_123', email: 'dev@example.com' }),
+          token_type: 'Bearer',
+          expires_in: 300,
+        }),
+      );
+    vi.spyOn(console, 'log').mockImplementation(() => {});
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    setProxyOverride(undefined);
+  });
+
+  it('sends the device authorization and token requests through --proxy', async () => {
+    setProxyOverride('http://proxy.internal:3128');
+
+    await runLogin();
+
+    const [authorize, token] = mockFetch.mock.calls as Array<[string, { dispatcher?: unknown }]>;
+    expect(authorize[0]).toBe('https://auth.example.com/oauth2/device_authorization');
+    expect(token[0]).toBe('https://auth.example.com/oauth2/token');
+    for (const [url, init] of [authorize, token]) {
+      expect(init.dispatcher).toBeDefined();
+      expect(init.dispatcher).toBe(proxyFetchOptions(url).dispatcher);
+    }
+    expect(mockSaveCredentials).toHaveBeenCalledWith(expect.objectContaining({ userId: 'user_123' }));
+  });
+});
@@ -1,8 +1,9 @@
-import open from 'opn';
+import { openBrowser } from '../utils/browser.js';
 import clack from '../utils/clack.js';
-import { saveCredentials, getCredentials, getAccessToken, isTokenExpired, updateTokens } from '../lib/credentials.js';
+import { saveCredentials, getCredentials, getAccessToken, isTokenExpired } from '../lib/credentials.js';
 import { getCliAuthClientId, getAuthkitDomain } from '../lib/settings.js';
-import { refreshAccessToken } from '../lib/token-refresh-client.js';
+import { refreshSession } from '../lib/token-refresh.js';
+import { proxiedFetch } from '../lib/proxy.js';
 
 /**
  * Parse JWT payload
@@ -84,10 +85,8 @@ export async function runLogin(): Promise<void> {
   const existingCreds = getCredentials();
   if (existingCreds?.refreshToken && isTokenExpired(existingCreds)) {
     try {
-      const authkitDomain = getAuthkitDomain();
-      const result = await refreshAccessToken(authkitDomain, clientId);
-      if (result.accessToken && result.expiresAt) {
-        updateTokens(result.accessToken, result.expiresAt, result.refreshToken);
+      const result = await refreshSession(existingCreds.accessToken);
+      if (result.success) {
         clack.log.info(`Already logged in as ${existingCreds.email ?? 'unknown'}`);
         clack.log.info('(Session refreshed)');
         clack.log.info('Run `workos logout` to log out');
@@ -102,7 +101,7 @@ export async function runLogin(): Promise<void> {
 
   const endpoints = getConnectEndpoints();
 
-  const authResponse = await fetch(endpoints.deviceAuthorization, {
+  const authResponse = await proxiedFetch(endpoints.deviceAuthorization, {
     method: 'POST',
     headers: {
       'Content-Type': 'application/x-www-form-urlencoded',
@@ -125,11 +124,9 @@ export async function runLogin(): Promise<void> {
   console.log(`  ${deviceAuth.verification_uri}`);
   console.log(`\nEnter code: ${deviceAuth.user_code}\n`);
 
-  try {
-    open(deviceAuth.verification_uri_complete);
+  // Otherwise the user opens it from the URL above
+  if (await openBrowser(deviceAuth.verification_uri_complete)) {
     clack.log.info('Browser opened automatically');
-  } catch {
-    // User can open manually
   }
 
   const spinner = clack.spinner();
@@ -142,7 +139,7 @@ export async function runLogin(): Promise<void> {
     await sleep(currentInterval);
 
     try {
-      const tokenResponse = await fetch(endpoints.token, {
+      const tokenResponse = await proxiedFetch(endpoints.token, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/x-www-form-urlencoded',
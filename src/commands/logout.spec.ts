@@ -0,0 +1,106 @@
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import type { CliConfig } from '../lib/config-store.js';
+
+vi.mock('../utils/clack.js', () => ({
+  default: {
+    log: { success: vi.fn(), error: vi.fn(), info: vi.fn(), warn: vi.fn() },
+  },
+}));
+
+const mockGetCredentials = vi.fn();
+const mockHasCredentials = vi.fn();
+const mockClearCredentials = vi.fn();
+vi.mock('../lib/credentials.js', () => ({
+  getCredentials: () => mockGetCredentials(),
+  hasCredentials: () => mockHasCredentials(),
+  clearCredentials: () => mockClearCredentials(),
+}));
+
+let config: CliConfig | null;
+const mockClearConfig = vi.fn();
+vi.mock('../lib/config-store.js', () => ({
+  getConfig: () => config,
+  saveConfig: (next: CliConfig) => {
+    config = next;
+  },
+  clearConfig: () => mockClearConfig(),
+}));
+
+vi.mock('../lib/settings.js', () => ({
+  getAuthkitDomain: () => 'https://auth.example.com',
+  getCliAuthClientId: () => 'client_cli',
+}));
+
+const mockRevoke = vi.fn();
+vi.mock('../lib/token-refresh-client.js', () => ({
+  revokeRefreshToken: (...args: unknown[]) => mockRevoke(...args),
+}));
+
+const { runLogout } = await import('./logout.js');
+const clack = (await import('../utils/clack.js')).default;
+
+vi.spyOn(process, 'exit').mockImplementation((() => {
+  throw new Error('process.exit called');
+}) as any);
+
+describe('runLogout', () => {
+  beforeEach(() => {
+    vi.clearAllMocks();
+    mockHasCredentials.mockReturnValue(true);
+    mockGetCredentials.mockReturnValue({
+      accessToken: 'access',
+      refreshToken: 'refresh',
+      expiresAt: Date.now() + 60_000,
+      userId: 'user_123',
+      email: 'dev@example.com',
+    });
+    mockRevoke.mockResolvedValue(null);
+    config = {
+      activeEnvironment: 'staging',
+      environments: {
+        staging: { name: 'staging', type: 'sandbox', apiKey: 'sk_test_1' },
+        prod: { name: 'prod', type: 'production', apiKey: 'sk_live_1' },
+      },
+    };
+  });
+
+  it('revokes the refresh token before clearing credentials', async () => {
+    await runLogout();
+
+    expect(mockRevoke).toHaveBeenCalledWith('https://auth.example.com', 'client_cli', 'refresh');
+    expect(mockClearCredentials).toHaveBeenCalled();
+    expect(mockClearConfig).not.toHaveBeenCalled();
+    expect(clack.log.success).toHaveBeenCalledWith('Logged out from dev@example.com');
+  });
+
+  it('still clears credentials when revocation fails', async () => {
+    mockRevoke.mockResolvedValue('Network error: offline');
+
+    await runLogout();
+
+    expect(mockClearCredentials).toHaveBeenCalled();
+    expect(clack.log.warn).toHaveBeenCalledWith(expect.stringContaining('Network error: offline'));
+  });
+
+  it('removes every profile with --all', async () => {
+    await runLogout({ all: true });
+
+    expect(mockClearCredentials).toHaveBeenCalled();
+    expect(mockClearConfig).toHaveBeenCalled();
+    expect(clack.log.success).toHaveBeenCalledWith('Removed 2 profiles');
+  });
+
+  it('removes only the named profile with --profile', async () => {
+    await runLogout({ profile: 'staging' });
+
+    expect(mockRevoke).not.toHaveBeenCalled();
+    expect(mockClearCredentials).not.toHaveBeenCalled();
+    expect(Object.keys(config!.environments)).toEqual(['prod']);
+    expect(config!.activeEnvironment).toBe('prod');
+  });
+
+  it('exits when the profile does not exist', async () => {
+    await expect(runLogout({ profile: 'missing' })).rejects.toThrow('process.exit called');
+    expect(clack.log.error).toHaveBeenCalledWith(expect.stringContaining('Available: staging, prod'));
+  });
+});
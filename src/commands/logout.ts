@@ -1,18 +1,78 @@
+import chalk from 'chalk';
 import clack from '../utils/clack.js';
 import { clearCredentials, hasCredentials, getCredentials } from '../lib/credentials.js';
+import { clearConfig, getConfig, saveConfig } from '../lib/config-store.js';
+import { getAuthkitDomain, getCliAuthClientId } from '../lib/settings.js';
+import { revokeRefreshToken } from '../lib/token-refresh-client.js';
 
-export async function runLogout(): Promise<void> {
+export interface LogoutOptions {
+  /** Also remove every environment profile added with `workos env add` */
+  all?: boolean;
+  /** Remove only this environment profile and stay logged in */
+  profile?: string;
+}
+
+function removeProfile(name: string): void {
+  const config = getConfig();
+  if (!config?.environments[name]) {
+    const available = Object.keys(config?.environments ?? {});
+    clack.log.error(
+      available.length > 0
+        ? `Profile "${name}" not found. Available: ${available.join(', ')}`
+        : 'No profiles configured. Run `workos env add` to add one.',
+    );
+    process.exit(1);
+  }
+
+  delete config.environments[name];
+  if (config.activeEnvironment === name) {
+    config.activeEnvironment = Object.keys(config.environments)[0];
+    if (config.activeEnvironment) {
+      clack.log.info(`Active profile switched to ${chalk.bold(config.activeEnvironment)}`);
+    }
+  }
+  saveConfig(config);
+  clack.log.success(`Removed profile ${chalk.bold(name)}`);
+}
+
+/**
+ * Revoke the refresh token, then delete the local credentials either way:
+ * a failed revocation shouldn't leave the user logged in.
+ */
+async function endSession(): Promise<void> {
   if (!hasCredentials()) {
     clack.log.info('Not logged in');
     return;
   }
 
   const creds = getCredentials();
+  let revokeError: string | null = null;
+  if (creds?.refreshToken) {
+    revokeError = await revokeRefreshToken(getAuthkitDomain(), getCliAuthClientId(), creds.refreshToken);
+  }
   clearCredentials();
 
+  if (revokeError) {
+    clack.log.warn(`Could not revoke the session on the server (${revokeError}); local credentials were removed`);
+  }
   if (creds?.email) {
     clack.log.success(`Logged out from ${creds.email}`);
   } else {
     clack.log.success('Logged out successfully');
   }
 }
+
+export async function runLogout(options: LogoutOptions = {}): Promise<void> {
+  if (options.profile) {
+    removeProfile(options.profile);
+    return;
+  }
+
+  await endSession();
+
+  if (options.all) {
+    const count = Object.keys(getConfig()?.environments ?? {}).length;
+    clearConfig();
+    if (count > 0) clack.log.success(`Removed ${count} ${count === 1 ? 'profile' : 'profiles'}`);
+  }
+}
@@ -0,0 +1,125 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest, WorkOSApiError } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runLogsRequests, runLogsRequestsGet } = await import('./logs.js');
+
+function log(id: string, status = 200, path = '/user_management/users') {
+  return { id, method: 'GET', path, status, latency_ms: 12, created_at: '2026-01-01T00:00:00Z' };
+}
+
+describe('logs commands', () => {
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+  });
+
+  describe('runLogsRequests', () => {
+    it('lists the requests that match the filters', async () => {
+      mockRequest.mockResolvedValue({
+        data: [log('req_1', 200), log('req_2', 404), log('req_3', 500, '/organizations')],
+        list_metadata: {},
+      });
+      await runLogsRequests({ status: '4xx,5xx', path: '/user_management/*', apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({ method: 'GET', path: '/request_logs' }));
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('req_2');
+      expect(output).not.toContain('req_1');
+      expect(output).not.toContain('req_3');
+    });
+
+    it('prints one JSON object per line with --json', async () => {
+      mockRequest.mockResolvedValue({ data: [log('req_1'), log('req_2')], list_metadata: {} });
+      await runLogsRequests({ json: true, apiKey: 'sk_test' });
+      expect(consoleOutput.map((line) => JSON.parse(line).id)).toEqual(['req_1', 'req_2']);
+    });
+
+    it('says when nothing matched', async () => {
+      mockRequest.mockResolvedValue({ data: [], list_metadata: {} });
+      await runLogsRequests({ apiKey: 'sk_test' });
+      expect(consoleOutput).toEqual(['No requests found.']);
+    });
+
+    it('rejects an unreadable --status before calling the API', async () => {
+      await expect(runLogsRequests({ status: '4x', apiKey: 'sk_test' })).rejects.toThrow('process.exit');
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('Invalid --status "4x"');
+    });
+
+    it('streams new requests with --follow until interrupted', async () => {
+      mockRequest.mockImplementation((async () => {
+        setImmediate(() => process.emit('SIGINT'));
+        return { data: [log('req_1')], list_metadata: {} };
+      }) as never);
+      await runLogsRequests({ follow: true, json: true, apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(JSON.parse(consoleOutput[0])).toMatchObject({ id: 'req_1' });
+      expect(errors.join('\n')).toContain('Following API requests.');
+    });
+  });
+
+  describe('runLogsRequestsGet', () => {
+    it('shows the request with its headers redacted', async () => {
+      mockRequest.mockResolvedValue({
+        ...log('req_1'),
+        request_headers: { authorization: 'Bearer sk_test', 'content-type': 'application/json' },
+        request_body: { email: 'ada@example.com' },
+      });
+      await runLogsRequestsGet('req_1', {}, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({ path: '/request_logs/req_1' }));
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('GET /user_management/users');
+      expect(output).toContain('authorization: [redacted]');
+      expect(output).not.toContain('ada@example.com');
+      expect(output).toContain('pass --include-body to show them');
+    });
+
+    it('shows bodies with --include-body', async () => {
+      mockRequest.mockResolvedValue({ ...log('req_1'), request_body: { email: 'ada@example.com' } });
+      await runLogsRequestsGet('req_1', { includeBody: true }, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(expect.objectContaining({ params: { include_body: 'true' } }));
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('"email": "ada@example.com"');
+      expect(output).toContain('not recorded');
+    });
+
+    it('explains a request that has expired', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Not Found', 404));
+      await expect(runLogsRequestsGet('req_old', {}, 'sk_test')).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('Request not found. Request logs are kept for a limited time.');
+    });
+  });
+});
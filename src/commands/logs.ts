@@ -0,0 +1,152 @@
+import chalk from 'chalk';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import {
+  followRequestLogs,
+  getRequestLog,
+  listRequestLogs,
+  parseSince,
+  parseStatusFilter,
+  type RequestLog,
+  type RequestLogsQuery,
+} from '../lib/request-logs.js';
+import { createTableStream, formatTable } from '../utils/table.js';
+
+export interface LogsRequestsOptions {
+  /** Lookback (1h) or ISO date */
+  since?: string;
+  status?: string;
+  path?: string;
+  limit?: number;
+  json?: boolean;
+  /** Poll for new requests and stream them */
+  follow?: boolean;
+  /** Seconds between polls with --follow */
+  interval?: number;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+const API_ERRORS: ApiErrorMessages = { notFound: 'Request not found. Request logs are kept for a limited time.' };
+
+const LOG_COLUMNS = [
+  { header: 'Time' },
+  { header: 'Method' },
+  { header: 'Path' },
+  { header: 'Status' },
+  { header: 'Request ID' },
+  { header: 'Latency' },
+];
+
+function colorStatus(status: number): string {
+  if (status >= 500) return chalk.red(String(status));
+  if (status >= 400) return chalk.yellow(String(status));
+  return chalk.green(String(status));
+}
+
+function logRow(log: RequestLog): string[] {
+  return [log.created_at, log.method, log.path, colorStatus(log.status), log.id, `${log.latency_ms}ms`];
+}
+
+/**
+ * List recent API requests, or follow new ones with --follow. JSON output
+ * is one request per line so it pipes into jq and incident tooling.
+ */
+export async function runLogsRequests(options: LogsRequestsOptions): Promise<void> {
+  let query: RequestLogsQuery;
+  try {
+    if (options.status) parseStatusFilter(options.status);
+    query = {
+      // Without --since, --follow starts from now and a listing from the last hour
+      rangeStart: parseSince(options.since ?? (options.follow ? '0m' : '1h')),
+      status: options.status,
+      path: options.path,
+    };
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+
+  if (options.follow) {
+    const controller = new AbortController();
+    process.once('SIGINT', () => controller.abort());
+    console.error(chalk.dim('Following API requests. Press Ctrl+C to stop.'));
+
+    const printRows = createTableStream(LOG_COLUMNS);
+    try {
+      const intervalMs = (options.interval ?? 5) * 1000;
+      for await (const log of followRequestLogs(query, api, { intervalMs, signal: controller.signal })) {
+        if (options.json) console.log(JSON.stringify(log));
+        else printRows([logRow(log)]);
+      }
+    } catch (error) {
+      handleApiError(error, API_ERRORS);
+    }
+    return;
+  }
+
+  const printRows = createTableStream(LOG_COLUMNS);
+  let count = 0;
+  try {
+    for await (const page of listRequestLogs(query, api, options.limit ?? 50)) {
+      if (options.json) for (const log of page) console.log(JSON.stringify(log));
+      else printRows(page.map(logRow));
+      count += page.length;
+    }
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+  if (count === 0 && !options.json) console.log('No requests found.');
+}
+
+export async function runLogsRequestsGet(
+  requestId: string,
+  options: { includeBody?: boolean; json?: boolean },
+  apiKey: string,
+  baseUrl?: string,
+): Promise<void> {
+  let log: RequestLog;
+  try {
+    log = await getRequestLog(requestId, { apiKey, baseUrl }, { includeBody: options.includeBody });
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  if (options.json) {
+    console.log(JSON.stringify(log, null, 2));
+    return;
+  }
+
+  const rows = [
+    ['Request ID', log.id],
+    ['Time', log.created_at],
+    ['Request', `${log.method} ${log.path}`],
+    ['Status', colorStatus(log.status)],
+    ['Latency', `${log.latency_ms}ms`],
+    ['IP address', log.ip_address ?? chalk.dim('unknown')],
+    ['User agent', log.user_agent ?? chalk.dim('unknown')],
+  ];
+  console.log(formatTable([{ header: 'Field' }, { header: 'Value' }], rows));
+
+  for (const [title, headers] of [
+    ['Request headers', log.request_headers],
+    ['Response headers', log.response_headers],
+  ] as const) {
+    if (!headers || Object.keys(headers).length === 0) continue;
+    console.log('');
+    console.log(chalk.bold(title));
+    for (const [name, value] of Object.entries(headers)) console.log(`  ${name}: ${value}`);
+  }
+
+  if (!options.includeBody) {
+    console.log(chalk.dim('\nBodies are hidden; pass --include-body to show them.'));
+    return;
+  }
+  for (const [title, body] of [
+    ['Request body', log.request_body],
+    ['Response body', log.response_body],
+  ] as const) {
+    console.log('');
+    console.log(chalk.bold(title));
+    console.log(body === undefined || body === null ? chalk.dim('  not recorded') : JSON.stringify(body, null, 2));
+  }
+}
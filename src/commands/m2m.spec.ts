@@ -0,0 +1,149 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runM2MClientsList, runM2MClientsCreate, runM2MClientsDelete, runM2MToken } = await import('./m2m.js');
+
+const application = {
+  id: 'app_123',
+  client_id: 'client_123',
+  name: 'Billing worker',
+  scopes: ['invoices:read'],
+  organization_id: null,
+  created_at: '2026-01-01T00:00:00.000Z',
+  application_type: 'm2m',
+};
+
+function jwt(claims: Record<string, unknown>): string {
+  const part = (value: unknown) => Buffer.from(JSON.stringify(value)).toString('base64url');
+  return `${part({ alg: 'RS256' })}.${part(claims)}.signature`;
+}
+
+describe('m2m commands', () => {
+  const originalFetch = globalThis.fetch;
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    vi.unstubAllEnvs();
+    globalThis.fetch = originalFetch;
+  });
+
+  describe('runM2MClientsList', () => {
+    it('lists only the M2M applications', async () => {
+      mockRequest.mockResolvedValue({
+        data: [application, { ...application, id: 'app_456', name: 'Web app', application_type: 'oauth' }],
+        list_metadata: {},
+      });
+      await runM2MClientsList({}, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'GET', path: '/connect/applications' }),
+      );
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('Billing worker');
+      expect(output).toContain('invoices:read');
+      expect(output).not.toContain('Web app');
+    });
+  });
+
+  describe('runM2MClientsCreate', () => {
+    it('creates the client and prints its secret once', async () => {
+      mockRequest.mockResolvedValueOnce(application).mockResolvedValueOnce({ secret: 'sk_secret_once' });
+      await runM2MClientsCreate({ name: 'Billing worker', scopes: ['invoices:read, invoices:write'] }, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/connect/applications',
+          body: { name: 'Billing worker', application_type: 'm2m', scopes: ['invoices:read', 'invoices:write'] },
+        }),
+      );
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ method: 'POST', path: '/connect/applications/app_123/client_secrets' }),
+      );
+      expect(consoleOutput).toContain('Client secret: sk_secret_once');
+      expect(errors.join('\n')).toContain("it won't be shown again");
+    });
+  });
+
+  describe('runM2MClientsDelete', () => {
+    it('deletes the client found by its client ID', async () => {
+      mockRequest.mockResolvedValueOnce({ data: [application], list_metadata: {} }).mockResolvedValueOnce(null);
+      await runM2MClientsDelete('client_123', 'sk_test');
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ method: 'DELETE', path: '/connect/applications/app_123' }),
+      );
+      expect(consoleOutput.join('\n')).toContain('Deleted Billing worker (client_123)');
+    });
+
+    it('exits 1 when no client matches', async () => {
+      mockRequest.mockResolvedValue({ data: [application], list_metadata: {} });
+      await expect(runM2MClientsDelete('client_missing', 'sk_test')).rejects.toThrow('process.exit');
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(errors.join('\n')).toContain('No M2M client with ID client_missing.');
+    });
+  });
+
+  describe('runM2MToken', () => {
+    const options = { client: 'client_123', secret: 'sk_secret', authkitDomain: 'https://acme.authkit.app' };
+
+    it('prints the access token and, with --decode, its claims', async () => {
+      const accessToken = jwt({ sub: 'client_123', scope: 'invoices:read' });
+      globalThis.fetch = vi.fn().mockResolvedValue(
+        new Response(JSON.stringify({ access_token: accessToken, expires_in: 3600 }), { status: 200 }),
+      );
+      await runM2MToken({ ...options, scopes: ['invoices:read'], json: true, decode: true });
+      expect(globalThis.fetch).toHaveBeenCalledWith('https://acme.authkit.app/oauth2/token', expect.anything());
+      expect(JSON.parse(consoleOutput[0])).toMatchObject({
+        access_token: accessToken,
+        scopes: ['invoices:read'],
+        claims: { sub: 'client_123' },
+      });
+    });
+
+    it('explains a rejected client secret', async () => {
+      globalThis.fetch = vi.fn().mockResolvedValue(
+        new Response(JSON.stringify({ error: 'invalid_client' }), { status: 401 }),
+      );
+      await expect(runM2MToken(options)).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('Invalid client ID or secret.');
+    });
+
+    it('needs a secret', async () => {
+      vi.stubEnv('WORKOS_M2M_CLIENT_SECRET', '');
+      await expect(runM2MToken({ ...options, secret: undefined })).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('Pass --secret or set WORKOS_M2M_CLIENT_SECRET.');
+    });
+  });
+});
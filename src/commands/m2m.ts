@@ -0,0 +1,148 @@
+import chalk from 'chalk';
+import { handleApiError } from '../lib/api-error.js';
+import {
+  createM2MClient,
+  deleteM2MClient,
+  describeM2MTokenError,
+  listM2MClients,
+  parseScopes,
+  requestClientCredentialsToken,
+  type M2MClient,
+  type M2MToken,
+} from '../lib/m2m.js';
+import { decodeJwtClaims } from '../lib/session-seal.js';
+import { formatTable } from '../utils/table.js';
+
+export async function runM2MClientsList(options: { json?: boolean }, apiKey: string, baseUrl?: string): Promise<void> {
+  let clients: M2MClient[];
+  try {
+    clients = await listM2MClients({ apiKey, baseUrl });
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  if (options.json) {
+    console.log(JSON.stringify(clients, null, 2));
+    return;
+  }
+  if (clients.length === 0) {
+    console.log('No M2M clients found.');
+    return;
+  }
+  const rows = clients.map((c) => [c.name, c.client_id, c.scopes.join(', ') || chalk.dim('none'), c.id]);
+  console.log(formatTable([{ header: 'Name' }, { header: 'Client ID' }, { header: 'Scopes' }, { header: 'ID' }], rows));
+}
+
+export interface M2MClientsCreateOptions {
+  name: string;
+  scopes?: string[];
+  org?: string;
+  json?: boolean;
+}
+
+/**
+ * Create a client and print its secret. The secret is shown this once and
+ * not stored anywhere.
+ */
+export async function runM2MClientsCreate(
+  options: M2MClientsCreateOptions,
+  apiKey: string,
+  baseUrl?: string,
+): Promise<void> {
+  let created: Awaited<ReturnType<typeof createM2MClient>>;
+  try {
+    created = await createM2MClient(
+      { name: options.name, scopes: parseScopes(options.scopes), organizationId: options.org },
+      { apiKey, baseUrl },
+    );
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  const { client, secret } = created;
+  if (options.json) {
+    console.log(JSON.stringify({ ...client, client_secret: secret }, null, 2));
+  } else {
+    console.log(chalk.green(`Created ${client.name}`));
+    console.log(`Client ID:     ${client.client_id}`);
+    console.log(`Client secret: ${secret}`);
+  }
+  console.error(chalk.yellow("Copy the secret now: it won't be shown again and the CLI doesn't store it."));
+}
+
+export async function runM2MClientsDelete(idOrClientId: string, apiKey: string, baseUrl?: string): Promise<void> {
+  let deleted: M2MClient | null;
+  try {
+    deleted = await deleteM2MClient(idOrClientId, { apiKey, baseUrl });
+  } catch (error) {
+    handleApiError(error);
+  }
+  if (!deleted) {
+    console.error(chalk.red(`No M2M client with ID ${idOrClientId}.`));
+    process.exit(1);
+  }
+  console.log(chalk.green(`Deleted ${deleted.name} (${deleted.client_id})`));
+}
+
+export interface M2MTokenOptions {
+  client: string;
+  /** Defaults to WORKOS_M2M_CLIENT_SECRET so it stays out of shell history */
+  secret?: string;
+  authkitDomain: string;
+  scopes?: string[];
+  decode?: boolean;
+  json?: boolean;
+}
+
+/**
+ * Run the client-credentials grant and print the access token, or with
+ * --decode its claims too (the signature isn't verified).
+ */
+export async function runM2MToken(options: M2MTokenOptions): Promise<void> {
+  const secret = options.secret || process.env.WORKOS_M2M_CLIENT_SECRET;
+  if (!secret) {
+    console.error(chalk.red('Pass --secret or set WORKOS_M2M_CLIENT_SECRET.'));
+    process.exit(1);
+  }
+
+  let token: M2MToken;
+  try {
+    token = await requestClientCredentialsToken({
+      authkitDomain: options.authkitDomain,
+      clientId: options.client,
+      clientSecret: secret,
+      scopes: parseScopes(options.scopes),
+    });
+  } catch (error) {
+    console.error(chalk.red(describeM2MTokenError(error)));
+    process.exit(1);
+  }
+
+  const claims = options.decode ? decodeJwtClaims(token.accessToken) : undefined;
+  if (options.json) {
+    console.log(
+      JSON.stringify(
+        {
+          access_token: token.accessToken,
+          expires_at: token.expiresAt?.toISOString() ?? null,
+          scopes: token.scopes,
+          ...(options.decode && { claims }),
+        },
+        null,
+        2,
+      ),
+    );
+    return;
+  }
+
+  console.log(token.accessToken);
+  if (token.expiresAt) console.error(chalk.dim(`Expires ${token.expiresAt.toLocaleString()}`));
+  if (!options.decode) return;
+  if (!claims) {
+    console.error(chalk.yellow('The access token is not a JWT; nothing to decode.'));
+    return;
+  }
+  console.error('');
+  console.error(chalk.bold('Access token claims'));
+  console.error(JSON.stringify(claims, null, 2));
+}
@@ -0,0 +1,116 @@
+import chalk from 'chalk';
+import { mkdirSync, writeFileSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import { performance } from 'node:perf_hooks';
+import {
+  assessmentMarkdown,
+  buildAssessment,
+  CALL_SITE_CLASSES,
+  CALL_SITE_LABELS,
+  type Assessment,
+  type CallSiteClass,
+} from '../migrate/assessment.js';
+import { applyConfidenceThreshold, DEFAULT_MIN_CONFIDENCE, detectProviders } from '../migrate/detect.js';
+import { createOidcDiscovery } from '../migrate/oidc-discovery.js';
+import { parseAssumedVersions } from '../migrate/sdk-versions.js';
+import { OUTPUT_SCHEMA_VERSION } from '../lib/output-schema.js';
+import { resolveReportPath } from '../lib/run-report.js';
+import { relativePosix } from '../utils/paths.js';
+import { redactSecrets } from '../utils/redact.js';
+import { formatTable } from '../utils/table.js';
+import { formatScanPaths } from './detect.js';
+
+export interface MigrateAssessOptions {
+  installDir: string;
+  /** Print the assessment as JSON instead of the overview (the reports are still written) */
+  json?: boolean;
+  minConfidence?: number;
+  include?: string[];
+  exclude?: string[];
+  cache?: boolean;
+  maxFileSize?: number;
+  scanTerraform?: boolean;
+  assumeProviderVersion?: string[];
+  /** Where to write the markdown report, a .md file or a directory; the JSON goes next to it */
+  reportPath?: string;
+}
+
+const CLASS_COLORS: Record<CallSiteClass, (text: string) => string> = {
+  auto: chalk.green,
+  manual: chalk.yellow,
+  'no-equivalent': chalk.red,
+};
+
+function formatAssessment(assessment: Assessment): string {
+  if (assessment.providers.length === 0) return 'No other auth providers detected.';
+  const sections = assessment.providers.map((provider) => {
+    const score = chalk.dim(`(confidence ${Math.round(provider.confidence * 100)}%)`);
+    const totals = CALL_SITE_CLASSES.map((c) => CLASS_COLORS[c](`${provider.totals[c]} ${CALL_SITE_LABELS[c]}`));
+    const lines = [`${chalk.bold(provider.displayName)} ${score}`, totals.join(chalk.dim(' · '))];
+    if (!provider.supported) lines.push(chalk.dim('No migration for this provider yet'));
+    if (provider.apis.length > 0) {
+      lines.push(
+        formatTable(
+          [{ header: 'API' }, { header: 'Call sites' }, { header: 'Classification' }],
+          provider.apis.map((u) => [
+            u.api,
+            String(u.count),
+            CLASS_COLORS[u.classification](CALL_SITE_LABELS[u.classification]),
+          ]),
+        ),
+      );
+    }
+    const env = [
+      ...provider.env.rename.map((r) => `${r.from} → ${r.to}`),
+      ...provider.env.remove.map((name) => `${name} (no longer needed)`),
+    ];
+    if (env.length > 0) lines.push(`Env vars: ${env.join(', ')}`);
+    if (provider.dashboard.length > 0) lines.push(`Dashboard: ${provider.dashboard.join('; ')}`);
+    return lines.join('\n');
+  });
+  return sections.join('\n\n');
+}
+
+/**
+ * Assess a migration without starting one: detect providers, plan each the
+ * way `workos migrate` would, and write the report as markdown and JSON.
+ */
+export async function runMigrateAssess(options: MigrateAssessOptions): Promise<void> {
+  const installDir = resolve(options.installDir);
+  const started = performance.now();
+  let assessment: Assessment;
+  try {
+    const assumedVersions = parseAssumedVersions(options.assumeProviderVersion ?? []);
+    const detected = await detectProviders(installDir, undefined, {
+      include: options.include,
+      exclude: options.exclude,
+      discovery: createOidcDiscovery({ cache: options.cache }),
+      maxFileSize: options.maxFileSize,
+      terraform: options.scanTerraform,
+    });
+    const result = applyConfidenceThreshold(detected, options.minConfidence ?? DEFAULT_MIN_CONFIDENCE);
+    if (result.paths && !options.json) console.log(formatScanPaths(result.paths));
+    assessment = buildAssessment(result, { assumedVersions, durationMs: Math.round(performance.now() - started) });
+  } catch (error) {
+    console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+    process.exit(1);
+  }
+
+  // Evidence and messages quote the project's code, which can hold a hardcoded secret
+  const json = redactSecrets(JSON.stringify({ schemaVersion: OUTPUT_SCHEMA_VERSION, ...assessment }, null, 2));
+  const markdownPath = resolveReportPath(installDir, 'assess', options.reportPath);
+  const jsonPath = markdownPath.replace(/\.md$/, '.json');
+  mkdirSync(dirname(markdownPath), { recursive: true });
+  writeFileSync(markdownPath, redactSecrets(assessmentMarkdown(assessment)));
+  writeFileSync(jsonPath, `${json}\n`);
+
+  if (options.json) {
+    console.log(json);
+    return;
+  }
+  console.log(redactSecrets(formatAssessment(assessment)));
+  for (const warning of assessment.warnings) console.log(chalk.yellow(warning));
+  const shown = (path: string) => relativePosix(installDir, path) || path;
+  const seconds = (assessment.durationMs / 1000).toFixed(1);
+  console.log(chalk.dim(`\nAssessed in ${seconds}s. Report: ${shown(markdownPath)} and ${shown(jsonPath)}`));
+}
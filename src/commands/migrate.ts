@@ -0,0 +1,652 @@
+import chalk from 'chalk';
+import { existsSync } from 'node:fs';
+import path from 'node:path';
+import type { ArgumentsCamelCase } from 'yargs';
+import { auth0ActionsWarning } from '../migrate/detectors/auth0-actions.js';
+import { applyConfidenceThreshold, DEFAULT_MIN_CONFIDENCE, detectProviders } from '../migrate/detect.js';
+import { evidenceLines } from '../migrate/evidence.js';
+import { logoutWarning } from '../migrate/logout.js';
+import { oauthStateLines } from '../migrate/oauth-state.js';
+import { createOidcDiscovery } from '../migrate/oidc-discovery.js';
+import { selectMigration, type MigrationSelection, type SelectMigrationOptions } from '../migrate/plan.js';
+import { getProvider } from '../migrate/providers.js';
+import { parseAssumedVersions } from '../migrate/sdk-versions.js';
+import { scopeClaimLines, scopesNeedingSetup } from '../migrate/scopes-claims.js';
+import { refreshesSessions, refreshTokenLines, SESSION_REFRESH_STEPS } from '../migrate/refresh-tokens.js';
+import { ORGANIZATION_STEPS, tenancyLines } from '../migrate/tenancy.js';
+import { terraformChecklist } from '../migrate/terraform.js';
+import { templateReferenceLines } from '../migrate/template-auth.js';
+import { testReferenceLines } from '../migrate/test-references.js';
+import { loadTemplateOverrides, type TemplateOverrides } from '../migrate/templates.js';
+import { hasWorkLeft, planChanged, reconcilePlan, reconciliationLines } from '../migrate/reconcile.js';
+import { listRegisteredRedirectUris, markRegistered } from '../migrate/redirect-uris.js';
+import {
+  deferredCategories,
+  formatCategories,
+  inScope,
+  MIGRATION_CATEGORIES,
+  parseOnly,
+  type MigrationCategory,
+} from '../migrate/scope.js';
+import {
+  completedCategories,
+  migrationFiles,
+  previousExclusions,
+  previousSteps,
+  readMigrationState,
+  writeMigrationState,
+} from '../migrate/selection.js';
+import type { DetectionResult, MigrationContext, MigrationStep, ProviderMatch, RedirectUri } from '../migrate/types.js';
+import { resolveApiBaseUrl, resolveApiKey } from '../lib/api-key.js';
+import { getActiveEnvironment } from '../lib/config-store.js';
+import { cloneRepository, createMigrationBranch, removeCloneOnExit, type Clone } from '../lib/remote-repo.js';
+import { REPORTS_DIR } from '../lib/run-report.js';
+import { reserveStdoutForEvents } from '../lib/event-stream.js';
+import { exitCodeFor, handleInterrupts, type StopReason } from '../lib/interrupt.js';
+import { moduleCheckpointPath, parseAgentConcurrency, runModules } from '../lib/module-runner.js';
+import clack from '../utils/clack.js';
+import { redactSecrets } from '../utils/redact.js';
+import { relativePosix } from '../utils/paths.js';
+import { exitWithError, UserCancelledError } from '../utils/errors.js';
+import { formatIgnored, formatScanPaths, formatSkipped } from './detect.js';
+import { handleInstall, type InstallArgs } from './install.js';
+
+interface MigrateArgs extends InstallArgs {
+  provider?: string;
+  minConfidence?: number;
+  /** Only scan paths matching these globs */
+  include?: string[];
+  /** Skip paths matching these globs, after include */
+  exclude?: string[];
+  /** Reuse cached OIDC discovery lookups; false with --no-cache */
+  cache?: boolean;
+  /** Skip files larger than this many bytes */
+  maxFileSize?: number;
+  /** Also read Auth0 clients from Terraform files */
+  scanTerraform?: boolean;
+  /** Overrides for the code the migration writes, keyed <provider>/<framework>/<file> */
+  templatesDir?: string;
+  /** Project directories to migrate concurrently, one process each */
+  modules?: string[];
+  /** How many of the modules run at once, each with its own agent */
+  agentConcurrency?: number;
+  /** SDK majors to migrate from, e.g. go-oidc@2, when the manifest doesn't say */
+  assumeProviderVersion?: string[];
+  /** Clone this repository and migrate the clone instead of the working directory */
+  repo?: string;
+  /** Branch of --repo to check out and base the migration on */
+  branch?: string;
+  /** Categories of change to apply (code, env, deps, templates), repeated or comma-separated */
+  only?: string[];
+}
+
+function formatPercent(value: number): string {
+  return `${Math.round(value * 100)}%`;
+}
+
+const ABORT = '__abort';
+
+/**
+ * Show why the provider was picked, then let the user confirm it, switch to
+ * another detected provider or stop. --provider, --yes and CI skip the
+ * question but still print the evidence.
+ */
+async function confirmProvider(
+  result: DetectionResult,
+  selection: MigrationSelection,
+  argv: MigrateArgs,
+  options: SelectMigrationOptions,
+): Promise<MigrationSelection> {
+  const { context } = selection;
+  const evidence = evidenceLines(context.evidence ?? []);
+  if (evidence.length > 0) {
+    clack.log.info(
+      `Strongest ${context.displayName} evidence:\n` + redactSecrets(evidence.map((line) => `  ${line}`).join('\n')),
+    );
+  }
+  if (context.forced || argv.yes || argv.ci) return selection;
+
+  const others = result.matches.filter((m) => m.provider !== context.provider && getProvider(m.provider));
+  const hint = (m: ProviderMatch) =>
+    [formatPercent(m.confidence), ...evidenceLines(m.evidence?.slice(0, 1) ?? []).map(redactSecrets)].join(', ');
+  const choice = await clack.select({
+    message: `Migrate from ${context.displayName} (${formatPercent(context.confidence)} confidence)?`,
+    options: [
+      { value: context.provider, label: `Yes, migrate from ${context.displayName}` },
+      ...others.map((m) => ({
+        value: m.provider,
+        label: `Use ${getProvider(m.provider)!.displayName} instead`,
+        hint: hint(m),
+      })),
+      { value: ABORT, label: 'Abort' },
+    ],
+    flag: '--provider',
+  });
+  if (clack.isCancel(choice) || choice === ABORT) {
+    clack.cancel('Migration cancelled');
+    exitWithError(new UserCancelledError('Migration cancelled'), { json: argv.json || argv.summaryFormat === 'json' });
+  }
+  return choice === context.provider ? selection : selectMigration(result, choice, options);
+}
+
+/**
+ * Let the user leave detected files out of the migration. The choice is
+ * saved so a rerun starts from it; --yes and CI skip the checklist but keep
+ * earlier exclusions.
+ */
+async function chooseExcludedFiles(
+  installDir: string,
+  context: MigrationContext,
+  argv: MigrateArgs,
+  only?: MigrationCategory[],
+): Promise<string[]> {
+  const all = migrationFiles(context.findings);
+  const state = readMigrationState(installDir);
+  const previous = previousExclusions(state, context.provider, all.map(({ file }) => file));
+  // Files outside --only aren't changed this run anyway; their exclusions are kept as they were
+  const files = all.filter(({ file }) => inScope(file, { ...context, only }));
+  const paths = files.map(({ file }) => file);
+  if (files.length === 0 || argv.yes || argv.ci) return previous;
+
+  const selected = await clack.multiselect({
+    message: 'Files to migrate (space to toggle, a to select all/none)',
+    options: files.map(({ file, findings }) => ({ value: file, label: file, hint: `${findings} finding(s)` })),
+    initialValues: paths.filter((file) => !previous.includes(file)),
+    required: false,
+    flag: '--yes',
+  });
+  if (clack.isCancel(selected)) {
+    clack.cancel('Migration cancelled');
+    exitWithError(new UserCancelledError('Migration cancelled'), { json: argv.json || argv.summaryFormat === 'json' });
+  }
+
+  const excluded = [
+    ...previous.filter((file) => !paths.includes(file)),
+    ...paths.filter((file) => !selected.includes(file)),
+  ];
+  writeMigrationState(installDir, {
+    provider: context.provider,
+    excludedFiles: excluded,
+    ...(state?.provider === context.provider && state.steps && { steps: state.steps, plan: state.plan }),
+    ...(state?.provider === context.provider && state.categories && { categories: state.categories }),
+  });
+  return excluded;
+}
+
+/**
+ * The categories this run applies, undefined for all of them. Without
+ * --only, a run after earlier --only runs applies the categories they left.
+ */
+function chooseScope(
+  installDir: string,
+  context: MigrationContext,
+  argv: MigrateArgs,
+  only: MigrationCategory[] | undefined,
+): { only?: MigrationCategory[]; completed: MigrationCategory[] } {
+  const completed = completedCategories(readMigrationState(installDir), context.provider);
+  if (!argv.only?.length && completed.length > 0) {
+    only = MIGRATION_CATEGORIES.filter((c) => !completed.includes(c));
+    clack.log.info(
+      `Earlier runs applied ${formatCategories(completed)}; applying the rest: ${chalk.bold(formatCategories(only))}`,
+    );
+    return { only, completed };
+  }
+  if (!only) return { completed };
+
+  const again = only.filter((c) => completed.includes(c));
+  if (again.length > 0) clack.log.warn(`Applying ${formatCategories(again)} again; an earlier run already did`);
+  const deferred = deferredCategories(only, completed);
+  clack.log.info(
+    `Applying only ${chalk.bold(formatCategories(only))}` +
+      (deferred.length > 0 ? `; ${formatCategories(deferred)} left for a later run` : ''),
+  );
+  return { only, completed };
+}
+
+/**
+ * Reconcile an earlier partly applied or cancelled run with this detection
+ * before resuming it. When the project changed since, the updated plan is
+ * shown and the user resumes with it, starts over or stops; --yes and CI
+ * resume.
+ * @returns The steps to resume with, or none to run the migration afresh
+ */
+async function reconcileResume(
+  installDir: string,
+  context: MigrationContext,
+  excludedFiles: string[],
+  argv: MigrateArgs,
+): Promise<MigrationStep[]> {
+  const state = readMigrationState(installDir);
+  if (previousSteps(state, context.provider).length === 0) return [];
+  const reconciled = reconcilePlan(installDir, state!, context.findings, excludedFiles);
+  const lines = reconciliationLines(reconciled).join('\n');
+  if (!hasWorkLeft(reconciled)) {
+    clack.log.info(`Nothing is left of the partly applied migration; starting a new one:\n${lines}`);
+    return [];
+  }
+  if (!planChanged(reconciled)) {
+    const unfinished = reconciled.steps.filter((step) => step.status !== 'succeeded');
+    clack.log.info(
+      `Resuming a partly applied migration; ${unfinished.length} file(s) left to finish:\n` +
+        unfinished.map((step) => `  ${step.file}${step.reason ? chalk.dim(` (${step.reason})`) : ''}`).join('\n'),
+    );
+    return reconciled.steps;
+  }
+
+  clack.log.warn(`The project changed since the partly applied migration stopped:\n${lines}`);
+  if (argv.yes || argv.ci) return reconciled.steps;
+  const choice = await clack.select({
+    message: 'Resume with the updated plan?',
+    options: [
+      { value: 'resume', label: 'Yes, finish what is left, including new files' },
+      { value: 'restart', label: 'Start over', hint: 'plan from this detection alone' },
+      { value: ABORT, label: 'Abort' },
+    ],
+    flag: '--yes',
+  });
+  if (clack.isCancel(choice) || choice === ABORT) {
+    clack.cancel('Migration cancelled');
+    exitWithError(new UserCancelledError('Migration cancelled'), { json: argv.json || argv.summaryFormat === 'json' });
+  }
+  return choice === 'resume' ? reconciled.steps : [];
+}
+
+/**
+ * Check the old callback URLs against the WorkOS app when an API key is
+ * configured. Without one, or if the lookup fails, they're listed unchecked.
+ */
+async function checkRedirectUris(uris: RedirectUri[], argv: MigrateArgs): Promise<RedirectUri[]> {
+  if (uris.length === 0) return uris;
+
+  let apiKey: string;
+  try {
+    apiKey = resolveApiKey({ apiKey: argv.apiKey });
+  } catch {
+    return uris;
+  }
+  try {
+    return markRegistered(uris, await listRegisteredRedirectUris(apiKey, resolveApiBaseUrl()));
+  } catch (error) {
+    clack.log.warn(
+      `Could not check redirect URIs with WorkOS: ${error instanceof Error ? error.message : String(error)}`,
+    );
+    return uris;
+  }
+}
+
+/**
+ * `--modules`: migrate each directory in its own process and render their
+ * progress together. The children can't prompt, so credentials come from
+ * the flags or the active environment and are passed in the environment
+ * rather than on their command lines. Ctrl-C stops the running modules
+ * and checkpoints the finished ones, which the next run skips.
+ */
+async function migrateModules(argv: ArgumentsCamelCase<MigrateArgs>): Promise<void> {
+  if (argv.events === 'ndjson') reserveStdoutForEvents();
+  clack.intro(chalk.inverse('WorkOS AuthKit Migration'));
+
+  const modules = [...new Set(argv.modules!.map((dir) => path.resolve(dir)))].map((dir) => ({
+    name: relativePosix(process.cwd(), dir) || '.',
+    dir,
+  }));
+  const missing = modules.filter((m) => !existsSync(m.dir));
+  if (missing.length > 0) {
+    clack.log.error(`No such directory: ${missing.map((m) => m.name).join(', ')}`);
+    process.exit(1);
+  }
+
+  let apiKey: string;
+  try {
+    apiKey = resolveApiKey({ apiKey: argv.apiKey });
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+  const clientId = argv.clientId ?? getActiveEnvironment()?.clientId;
+  if (!clientId) {
+    clack.log.error('--modules needs a client ID: pass --client-id or add one with `workos env add --client-id`');
+    process.exit(1);
+  }
+
+  let concurrency: number;
+  try {
+    concurrency = parseAgentConcurrency(argv.agentConcurrency);
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+
+  if (existsSync(moduleCheckpointPath(modules))) {
+    clack.log.info('Resuming a cancelled run: modules it finished are skipped');
+  }
+  clack.log.info(`Migrating ${modules.length} modules: ${modules.map((m) => m.name).join(', ')}`);
+  // The abort reason tells the children which signal to pass on
+  const controller = new AbortController();
+  let stopReason: StopReason = 'interrupt';
+  const interrupts = handleInterrupts((reason) => {
+    stopReason = reason;
+    controller.abort(reason);
+  });
+  const results = await runModules(modules, {
+    args: process.argv.slice(2),
+    env: { WORKOS_INSTALLER_API_KEY: apiKey, WORKOS_INSTALLER_CLIENT_ID: clientId },
+    events: argv.events === 'ndjson',
+    concurrency,
+    signal: controller.signal,
+  });
+  interrupts.dispose();
+
+  const failed = results.filter((r) => !r.success && !r.cancelled);
+  for (const result of failed) {
+    const output = result.stderr.length > 0 ? `\n${chalk.dim(redactSecrets(result.stderr.join('\n')))}` : '';
+    clack.log.error(`${result.module}: ${result.detail}${output}`);
+  }
+  if (interrupts.interrupted()) {
+    const migrated = results.filter((r) => r.success).length;
+    clack.log.warn(
+      `${migrated} of ${results.length} modules migrated before the cancel; run the same command again for the rest`,
+    );
+    clack.outro('Cancelled');
+    process.exit(exitCodeFor(stopReason));
+  }
+  if (failed.length > 0) {
+    clack.outro(`${results.length - failed.length} of ${results.length} modules migrated`);
+    process.exit(1);
+  }
+  clack.outro(`All ${results.length} modules migrated`);
+  process.exit(0);
+}
+
+/** Log `message` as an error and exit 1 */
+function exitWithMessage(message: string): never {
+  clack.log.error(message);
+  process.exit(1);
+}
+
+/**
+ * `--repo`: clone the repository and point the run at the clone. The clone
+ * goes away when the run ends, so the migration has to leave it some other
+ * way: pushed on a new branch (--push) or as a patch (--diff-only).
+ */
+async function migrateInClone(argv: ArgumentsCamelCase<MigrateArgs>): Promise<ArgumentsCamelCase<MigrateArgs>> {
+  if (argv.modules?.length) {
+    exitWithMessage('--repo migrates one project; use --install-dir for a directory inside the repo');
+  }
+  if (argv.push && (argv.dryRun || argv.diffOnly)) {
+    exitWithMessage('--push has nothing to push with --dry-run or --diff-only');
+  }
+  if (!argv.push && !argv.dryRun && !argv.diffOnly) {
+    exitWithMessage('The clone is removed after the run: pass --push to push the migration, or --diff-only <file>');
+  }
+
+  const cwd = process.cwd();
+  let clone: Clone;
+  try {
+    clone = await cloneRepository(argv.repo!, { branch: argv.branch });
+  } catch (error) {
+    exitWithMessage(error instanceof Error ? error.message : String(error));
+  }
+  removeCloneOnExit(clone, (message) => clack.log.warn(message));
+  clack.log.info(`Cloned ${chalk.cyan(clone.url)}${argv.branch ? ` (${argv.branch})` : ''}`);
+  if (argv.push) {
+    try {
+      clack.log.info(`Migrating on branch ${chalk.bold(createMigrationBranch(clone))}`);
+    } catch (error) {
+      const reason = error instanceof Error ? error.message : String(error);
+      exitWithMessage(`Could not create the migration branch: ${reason}`);
+    }
+  }
+  // The installer runs git in the working directory, so the run moves into the clone
+  process.chdir(clone.dir);
+
+  // Paths on the command line stay relative to where it was run, and the report is kept there
+  const fromCwd = (file: string | undefined) => file && path.resolve(cwd, file);
+  return {
+    ...argv,
+    installDir: path.resolve(clone.dir, argv.installDir ?? '.'),
+    diffOnly: fromCwd(argv.diffOnly),
+    reportPath: path.resolve(cwd, argv.reportPath ?? REPORTS_DIR),
+    instructions: fromCwd(argv.instructions),
+    templatesDir: fromCwd(argv.templatesDir),
+  };
+}
+
+/**
+ * Detect the current auth provider and run the installer in migration mode.
+ */
+export async function handleMigrate(argv: ArgumentsCamelCase<MigrateArgs>): Promise<void> {
+  if (argv.modules?.length) {
+    if (argv.diffOnly) {
+      // Every module would write the same patch file
+      clack.log.error('--diff-only takes one project; run it in each module directory instead of using --modules');
+      process.exit(1);
+    }
+    await migrateModules(argv);
+    return;
+  }
+  if (argv.events === 'ndjson') reserveStdoutForEvents();
+
+  clack.intro(chalk.inverse('WorkOS AuthKit Migration'));
+  if (argv.repo) argv = await migrateInClone(argv);
+
+  const installDir = path.resolve(argv.installDir ?? process.cwd());
+
+  // Checked before anything runs, so a broken template doesn't fail the migration halfway
+  let templates: TemplateOverrides | undefined;
+  try {
+    templates = loadTemplateOverrides(installDir, { path: argv.templatesDir });
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+  let assumedVersions: Record<string, number>;
+  try {
+    assumedVersions = parseAssumedVersions(argv.assumeProviderVersion ?? []);
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+  let only: MigrationCategory[] | undefined;
+  try {
+    only = parseOnly(argv.only);
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+  if (templates) {
+    const keys = Object.keys(templates.templates);
+    clack.log.info(`Using ${keys.length} template override(s) from ${chalk.cyan(templates.source)}`);
+  }
+
+  // A forced provider uses its markers however weak they are
+  const detected = await detectProviders(installDir, undefined, {
+    include: argv.include,
+    exclude: argv.exclude,
+    discovery: createOidcDiscovery({ cache: argv.cache }),
+    maxFileSize: argv.maxFileSize,
+    terraform: argv.scanTerraform,
+  });
+  if (detected.paths) clack.log.info(formatScanPaths(detected.paths));
+  if (argv.debug && detected.skipped) clack.log.info(formatSkipped(detected.skipped));
+  if (argv.debug && detected.ignored) clack.log.info(formatIgnored(detected.ignored));
+  const minConfidence = argv.minConfidence ?? DEFAULT_MIN_CONFIDENCE;
+  const result = argv.provider ? detected : applyConfidenceThreshold(detected, minConfidence);
+
+  let selection;
+  try {
+    selection = selectMigration(result, argv.provider, { assumedVersions });
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    if (result.suppressed) {
+      clack.log.info(
+        `${result.suppressed} weak match(es) were below ${Math.round(minConfidence * 100)}% confidence. ` +
+          'Lower --min-confidence or pass --provider to use them.',
+      );
+    }
+    exitWithError(error, { json: argv.json || argv.summaryFormat === 'json' });
+  }
+
+  for (const warning of selection.warnings) {
+    clack.log.warn(warning);
+  }
+  const confirmed = await confirmProvider(result, selection, argv, { assumedVersions });
+  if (confirmed !== selection) {
+    for (const warning of confirmed.warnings) {
+      clack.log.warn(warning);
+    }
+  }
+  const { context } = confirmed;
+
+  const source = argv.provider
+    ? 'selected with --provider'
+    : `${context.forced ? 'chosen from detection' : 'detected'}, ${formatPercent(context.confidence)}`;
+  clack.log.info(
+    `Migrating from ${chalk.bold(context.displayName)} (${source}) with ${context.findings.length} finding(s)`,
+  );
+
+  const scope = chooseScope(installDir, context, argv, only);
+  const applies = (category: MigrationCategory) => !scope.only || scope.only.includes(category);
+
+  const outOfScope = context.findings.filter((f) => f.outOfScope).length;
+  if (outOfScope > 0) {
+    clack.log.info(
+      `${outOfScope} non-auth ${context.displayName} usage(s) are out of scope and will be left unchanged`,
+    );
+  }
+
+  const manual = context.findings.filter((f) => f.manual);
+  if (manual.length > 0) {
+    const locations = manual.map((f) => (f.line ? `${f.file}:${f.line}` : f.file));
+    clack.log.warn(
+      `${manual.length} usage(s) have no drop-in AuthKit replacement and will need manual review:\n` +
+        locations.map((l) => `  ${l}`).join('\n'),
+    );
+  }
+
+  const actionsWarning = auth0ActionsWarning(context.findings);
+  if (actionsWarning) {
+    clack.log.warn(actionsWarning);
+  }
+  const signOutWarning = logoutWarning(context.findings);
+  if (signOutWarning) {
+    clack.log.warn(signOutWarning);
+  }
+
+  const scopeClaims = scopeClaimLines(context.scopes ?? [], context.claims ?? []);
+  if (scopeClaims.length > 0) {
+    const log = scopesNeedingSetup(context.scopes ?? []).length > 0 ? clack.log.warn : clack.log.info;
+    log('Scopes and claims in AuthKit:\n' + scopeClaims.map((line) => `  ${line}`).join('\n'));
+  }
+
+  const tenancy = context.tenancy ?? [];
+  if (tenancy.length > 0) {
+    clack.log.warn(
+      'This app looks multi-tenant; its tenants will map to AuthKit organizations:\n' +
+        tenancyLines(tenancy).map((line) => `  ${line}`).join('\n') +
+        '\nSet up organizations in WorkOS:\n' +
+        ORGANIZATION_STEPS.map((step, i) => `  ${i + 1}. ${step}`).join('\n'),
+    );
+  }
+
+  const refreshTokens = context.refreshTokens ?? [];
+  if (refreshTokens.length > 0) {
+    // Only asking for offline access changes nothing once the scope is dropped
+    const log = refreshesSessions(refreshTokens) ? clack.log.warn : clack.log.info;
+    log(
+      'This app keeps users signed in with refresh tokens, which the default migration does not carry over:\n' +
+        refreshTokenLines(refreshTokens)
+          .map((line) => `  ${line}`)
+          .join('\n') +
+        '\nMove the refresh to AuthKit:\n' +
+        SESSION_REFRESH_STEPS.map((step, i) => `  ${i + 1}. ${step}`).join('\n'),
+    );
+  }
+
+  const oauthState = context.oauthState ?? [];
+  if (oauthState.length > 0) {
+    // A constant state is a CSRF hole the migration closes; say so rather than fix it silently
+    const constant = oauthState.some((use) => use.kind === 'constant');
+    const log = constant ? clack.log.warn : clack.log.info;
+    log(
+      (constant
+        ? 'The login uses a constant OAuth state (no CSRF protection); the migrated login checks a random one:\n'
+        : "The login's state and nonce checks will be kept with AuthKit's state:\n") +
+        oauthStateLines(oauthState)
+          .map((line) => `  ${line}`)
+          .join('\n'),
+    );
+  }
+
+  const templateReferences = context.templateReferences ?? [];
+  if (templateReferences.length > 0 && applies('templates')) {
+    const log = templateReferences.some((r) => r.hosted || r.kind === 'cookie') ? clack.log.warn : clack.log.info;
+    log(
+      'Templates with sign-in links or sign-in state — the agent will repoint these and keep their text:\n' +
+        templateReferenceLines(templateReferences)
+          .map((line) => `  ${line}`)
+          .join('\n'),
+    );
+  }
+
+  const testReferences = context.testReferences ?? [];
+  if (testReferences.length > 0) {
+    clack.log.warn(
+      `Tests referencing ${context.displayName} — update these (the migration leaves them unchanged):\n` +
+        testReferenceLines(testReferences)
+          .map((line) => `  ${line}`)
+          .join('\n'),
+    );
+  }
+
+  const containerEnvFiles = context.containerEnvFiles ?? [];
+  if (containerEnvFiles.length > 0 && applies('env')) {
+    clack.log.info(
+      'Provider env keys in container config will be renamed too:\n' +
+        containerEnvFiles.map((f) => `  ${f.file} ${chalk.dim(`(${f.source})`)}`).join('\n') +
+        '\nRebuild the images and recreate the containers after the migration.',
+    );
+  }
+
+  const redirectUris = await checkRedirectUris(context.redirectUris ?? [], argv);
+  const missing = redirectUris.filter((r) => !r.registered);
+  if (missing.length > 0) {
+    clack.log.warn(
+      'Add these redirect URIs to your WorkOS app (Redirects in the dashboard):\n' +
+        missing.map((r) => `  ${r.uri} ${chalk.dim(`(${r.line ? `${r.file}:${r.line}` : r.file})`)}`).join('\n'),
+    );
+  } else if (redirectUris.length > 0) {
+    clack.log.info(`Redirect URIs already registered: ${redirectUris.map((r) => r.uri).join(', ')}`);
+  }
+
+  const terraformItems = terraformChecklist(context.terraformClients ?? []);
+  if (terraformItems.length > 0) {
+    clack.log.warn(
+      'Terraform configures the Auth0 app with these too; set them in the WorkOS dashboard:\n' +
+        terraformItems.map((item) => `  ${item}`).join('\n'),
+    );
+  }
+
+  const excludedFiles = await chooseExcludedFiles(installDir, context, argv, scope.only);
+  if (excludedFiles.length > 0) {
+    clack.log.info(
+      `Leaving ${excludedFiles.length} file(s) unchanged:\n` + excludedFiles.map((f) => `  ${f}`).join('\n'),
+    );
+  }
+
+  // A partly applied or cancelled earlier run is finished rather than redone
+  const steps = await reconcileResume(installDir, context, excludedFiles, argv);
+
+  await handleInstall({
+    ...argv,
+    installDir,
+    migration: {
+      ...context,
+      excludedFiles,
+      redirectUris,
+      templates,
+      ...(steps.length > 0 && { previousSteps: steps }),
+      ...(scope.only && { only: scope.only }),
+      ...(scope.completed.length > 0 && { completedCategories: scope.completed }),
+    },
+  });
+}
@@ -0,0 +1,80 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { fileURLToPath } from 'node:url';
+import { _setApiCacheDir } from '../lib/api-cache.js';
+import { _resetCassette, REPLAY_ENV } from '../lib/cassette.js';
+import { runOrgCreate, runOrgDelete, runOrgGet, runOrgList } from './organization.js';
+
+const CASSETTE = fileURLToPath(new URL('./organization.cassette.json', import.meta.url));
+
+describe('organization commands against a cassette', () => {
+  const originalFetch = globalThis.fetch;
+  let cacheDir: string;
+  let logged: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    _resetCassette();
+    process.env[REPLAY_ENV] = CASSETTE;
+    // Anything that reaches the network fails the test
+    globalThis.fetch = vi.fn().mockRejectedValue(new Error('network request during replay'));
+    cacheDir = mkdtempSync(join(tmpdir(), 'org-cassette-'));
+    _setApiCacheDir(cacheDir);
+    logged = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => void logged.push(args.map(String).join(' ')));
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => void errors.push(args.map(String).join(' ')));
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    globalThis.fetch = originalFetch;
+    delete process.env[REPLAY_ENV];
+    rmSync(cacheDir, { recursive: true, force: true });
+  });
+
+  it('lists organizations with their domains', async () => {
+    await runOrgList({}, 'sk_test_cassette0001');
+
+    expect(logged.join('\n')).toContain('acme.test');
+    expect(logged.join('\n')).toContain('Globex');
+  });
+
+  it('filters on a domain', async () => {
+    await runOrgList({ domain: 'globex.test', json: true }, 'sk_test_cassette0001');
+
+    expect(JSON.parse(logged[0]).data.map((o: { name: string }) => o.name)).toEqual(['Globex']);
+  });
+
+  it('gets an organization', async () => {
+    await runOrgGet('org_01HTQ5A1Z9P4', 'sk_test_cassette0001');
+
+    expect(JSON.parse(logged[0]).name).toBe('Acme');
+  });
+
+  it('creates an organization with a domain', async () => {
+    await runOrgCreate('Initech', ['initech.test'], 'sk_test_cassette0001');
+
+    expect(logged[0]).toContain('Created organization');
+    expect(JSON.parse(logged[1]).id).toBe('org_01HTQ5C3W6T1');
+  });
+
+  it('deletes an organization', async () => {
+    await runOrgDelete('org_01HTQ5B7R2N8', 'sk_test_cassette0001');
+
+    expect(logged[0]).toContain('Deleted organization org_01HTQ5B7R2N8');
+  });
+
+  it('fails on a create the cassette did not record', async () => {
+    await expect(runOrgCreate('Hooli', [], 'sk_test_cassette0001')).rejects.toThrow('process.exit');
+    expect(errors.join('\n')).toContain(
+      'recorded with a different body or query: POST https://api.workos.com/organizations',
+    );
+    expect(globalThis.fetch).not.toHaveBeenCalled();
+  });
+});
@@ -16,6 +16,13 @@ vi.mock('../lib/workos-api.js', () => ({
   },
 }));
 
+const mockInvalidate = vi.fn();
+vi.mock('../lib/api-cache.js', () => ({
+  invalidateApiCache: (resource: string) => mockInvalidate(resource),
+  cacheScope: () => ({ profile: 'default', environment: 'test' }),
+  cachedLookup: (_resource: string, _scope: unknown, fetcher: () => Promise<unknown>) => fetcher(),
+}));
+
 const { workosRequest } = await import('../lib/workos-api.js');
 const mockRequest = vi.mocked(workosRequest);
 
@@ -66,6 +73,7 @@ describe('organization commands', () => {
           method: 'POST',
           path: '/organizations',
           body: { name: 'Test' },
+          idempotencyKey: expect.any(String),
         }),
       );
     });
@@ -172,6 +180,36 @@ describe('organization commands', () => {
       expect(consoleOutput.some((l) => l.includes('cursor_b'))).toBe(true);
       expect(consoleOutput.some((l) => l.includes('cursor_a'))).toBe(true);
     });
+
+    it('walks every page with --all and prints the header once', async () => {
+      mockRequest
+        .mockResolvedValueOnce({
+          data: [{ id: 'org_1', name: 'First', domains: [] }],
+          list_metadata: { before: null, after: 'org_1' },
+        })
+        .mockResolvedValueOnce({
+          data: [{ id: 'org_2', name: 'Second', domains: [] }],
+          list_metadata: { before: null, after: null },
+        });
+      await runOrgList({ all: true }, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledTimes(2);
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('First');
+      expect(output).toContain('Second');
+      expect(output.match(/Name/g)).toHaveLength(1);
+    });
+
+    it('emits JSON lines with --all --json', async () => {
+      mockRequest.mockResolvedValueOnce({
+        data: [
+          { id: 'org_1', name: 'First', domains: [] },
+          { id: 'org_2', name: 'Second', domains: [] },
+        ],
+        list_metadata: { before: null, after: null },
+      });
+      await runOrgList({ all: true, json: true }, 'sk_test');
+      expect(consoleOutput.map((line) => JSON.parse(line).id)).toEqual(['org_1', 'org_2']);
+    });
   });
 
   describe('runOrgDelete', () => {
@@ -183,5 +221,11 @@ describe('organization commands', () => {
       );
       expect(consoleOutput.some((l) => l.includes('Deleted') && l.includes('org_123'))).toBe(true);
     });
+
+    it('drops cached organization lookups', async () => {
+      mockRequest.mockResolvedValue(null);
+      await runOrgDelete('org_123', 'sk_test');
+      expect(mockInvalidate).toHaveBeenCalledWith('organizations');
+    });
   });
 });
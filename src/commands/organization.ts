@@ -1,7 +1,16 @@
 import chalk from 'chalk';
-import { workosRequest, WorkOSApiError } from '../lib/workos-api.js';
+import { randomUUID } from 'node:crypto';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import { workosRequest } from '../lib/workos-api.js';
 import type { WorkOSListResponse } from '../lib/workos-api.js';
-import { formatTable } from '../utils/table.js';
+import { paginate } from '../lib/pagination.js';
+import { organizationExportSource } from '../lib/directory-export.js';
+import { invalidateApiCache } from '../lib/api-cache.js';
+import { moreOrganizations, organizationChoices, toPickerItem, type LookupChoice } from '../lib/lookups.js';
+import clack from '../utils/clack.js';
+import { pick } from '../utils/fuzzy-picker.js';
+import { createTableStream, formatTable } from '../utils/table.js';
+import { runDirectoryExport, type DirectoryExportOptions } from './directory-export.js';
 
 interface OrganizationDomain {
   id: string;
@@ -32,22 +41,7 @@ export function parseDomainArgs(args: string[]): DomainData[] {
   });
 }
 
-function handleApiError(error: unknown): never {
-  if (error instanceof WorkOSApiError) {
-    if (error.statusCode === 401) {
-      console.error(chalk.red('Invalid API key. Check your environment configuration.'));
-    } else if (error.statusCode === 404) {
-      console.error(chalk.red(`Organization not found.`));
-    } else if (error.statusCode === 422 && error.errors?.length) {
-      console.error(chalk.red(error.errors.map((e) => e.message).join(', ')));
-    } else {
-      console.error(chalk.red(error.message));
-    }
-  } else {
-    console.error(chalk.red(error instanceof Error ? error.message : 'Unknown error'));
-  }
-  process.exit(1);
-}
+const API_ERRORS: ApiErrorMessages = { notFound: 'Organization not found.' };
 
 export async function runOrgCreate(
   name: string,
@@ -68,11 +62,13 @@ export async function runOrgCreate(
       apiKey,
       baseUrl,
       body,
+      idempotencyKey: randomUUID(),
     });
+    invalidateApiCache('organizations');
     console.log(chalk.green('Created organization'));
     console.log(JSON.stringify(org, null, 2));
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
   }
 }
 
@@ -97,14 +93,42 @@ export async function runOrgUpdate(
       baseUrl,
       body,
     });
+    invalidateApiCache('organizations');
     console.log(chalk.green('Updated organization'));
     console.log(JSON.stringify(org, null, 2));
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
   }
 }
 
-export async function runOrgGet(orgId: string, apiKey: string, baseUrl?: string): Promise<void> {
+/**
+ * Pick an organization, filtering on name, ID or domain. The first page
+ * comes from the lookup cache and the rest load as the picker scrolls.
+ * Exits when there's nothing to pick or the user cancels.
+ */
+export async function pickOrganization(apiKey: string, baseUrl: string): Promise<string> {
+  let choices: LookupChoice[];
+  try {
+    choices = await organizationChoices(apiKey, baseUrl);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+  if (choices.length === 0) {
+    console.error(chalk.red('No organizations in this environment.'));
+    process.exit(1);
+  }
+  const selected = await pick({
+    message: 'Select an organization',
+    items: choices.map(toPickerItem),
+    loadMore: moreOrganizations(apiKey, baseUrl, choices),
+    flag: 'workos organization get <orgId>',
+  });
+  if (clack.isCancel(selected)) process.exit(0);
+  return selected as string;
+}
+
+export async function runOrgGet(orgId: string | undefined, apiKey: string, baseUrl?: string): Promise<void> {
+  orgId ??= await pickOrganization(apiKey, baseUrl ?? 'https://api.workos.com');
   try {
     const org = await workosRequest<Organization>({
       method: 'GET',
@@ -114,7 +138,7 @@ export async function runOrgGet(orgId: string, apiKey: string, baseUrl?: string)
     });
     console.log(JSON.stringify(org, null, 2));
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
   }
 }
 
@@ -124,36 +148,64 @@ export interface OrgListOptions {
   before?: string;
   after?: string;
   order?: string;
+  /** Follow cursors through every page; `limit` caps the total */
+  all?: boolean;
+  json?: boolean;
+}
+
+const ORG_COLUMNS = [{ header: 'ID' }, { header: 'Name' }, { header: 'Domains' }];
+
+function orgRow(org: Organization): string[] {
+  return [org.id, org.name, org.domains.map((d) => d.domain).join(', ') || chalk.dim('none')];
 }
 
 export async function runOrgList(options: OrgListOptions, apiKey: string, baseUrl?: string): Promise<void> {
+  const params = {
+    domains: options.domain,
+    limit: options.limit,
+    before: options.before,
+    after: options.after,
+    order: options.order,
+  };
+
+  if (options.all) {
+    // Stream pages as they arrive; JSON lines so output pipes into jq
+    const printRows = createTableStream(ORG_COLUMNS);
+    let count = 0;
+    try {
+      const pages = paginate<Organization>({ path: '/organizations', apiKey, baseUrl, params }, { max: options.limit });
+      for await (const page of pages) {
+        if (options.json) page.forEach((org) => console.log(JSON.stringify(org)));
+        else printRows(page.map(orgRow));
+        count += page.length;
+      }
+    } catch (error) {
+      handleApiError(error, API_ERRORS);
+    }
+    if (count === 0 && !options.json) console.log('No organizations found.');
+    return;
+  }
+
   try {
     const result = await workosRequest<WorkOSListResponse<Organization>>({
       method: 'GET',
       path: '/organizations',
       apiKey,
       baseUrl,
-      params: {
-        domains: options.domain,
-        limit: options.limit,
-        before: options.before,
-        after: options.after,
-        order: options.order,
-      },
+      params,
     });
 
+    if (options.json) {
+      console.log(JSON.stringify(result, null, 2));
+      return;
+    }
+
     if (result.data.length === 0) {
       console.log('No organizations found.');
       return;
     }
 
-    const rows = result.data.map((org) => [
-      org.id,
-      org.name,
-      org.domains.map((d) => d.domain).join(', ') || chalk.dim('none'),
-    ]);
-
-    console.log(formatTable([{ header: 'ID' }, { header: 'Name' }, { header: 'Domains' }], rows));
+    console.log(formatTable(ORG_COLUMNS, result.data.map(orgRow)));
 
     const { before, after } = result.list_metadata;
     if (before && after) {
@@ -164,7 +216,7 @@ export async function runOrgList(options: OrgListOptions, apiKey: string, baseUr
       console.log(chalk.dim(`After: ${after}`));
     }
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
   }
 }
 
@@ -176,8 +228,17 @@ export async function runOrgDelete(orgId: string, apiKey: string, baseUrl?: stri
       apiKey,
       baseUrl,
     });
+    invalidateApiCache('organizations');
     console.log(chalk.green(`Deleted organization ${orgId}`));
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
+  }
+}
+
+export async function runOrgExport(options: DirectoryExportOptions, apiKey: string, baseUrl?: string): Promise<void> {
+  try {
+    await runDirectoryExport(organizationExportSource({ apiKey, baseUrl }), options);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
   }
 }
@@ -0,0 +1,24 @@
+import { describe, it, expect } from 'vitest';
+import { DETECTORS } from '../migrate/detectors/index.js';
+import { formatProvidersListing, listProviders } from './providers.js';
+
+describe('providers', () => {
+  it('lists every registered detector', () => {
+    const listing = listProviders();
+
+    expect(listing.detectors.map((d) => d.name)).toEqual(DETECTORS.map((d) => d.name));
+    for (const detector of listing.detectors) {
+      expect(detector.description).not.toBe('');
+      expect(detector.language).not.toBe('');
+    }
+    expect(listing.providers).toContainEqual({ name: 'auth0', displayName: 'Auth0' });
+  });
+
+  it('formats detectors as a table', () => {
+    const output = formatProvidersListing(listProviders());
+
+    expect(output).toContain('spring-security');
+    expect(output).toContain('java');
+    expect(output).toContain('--provider');
+  });
+});
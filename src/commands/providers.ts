@@ -0,0 +1,33 @@
+import chalk from 'chalk';
+import { DETECTORS } from '../migrate/detectors/index.js';
+import { PROVIDERS } from '../migrate/providers.js';
+import type { ProviderDetector } from '../migrate/types.js';
+import { formatTable } from '../utils/table.js';
+
+export interface ProvidersListing {
+  /** What `workos detect` looks for, in the order detectors run */
+  detectors: Array<{ name: string; language: string; description: string }>;
+  /** Providers `workos migrate --provider` accepts */
+  providers: Array<{ name: string; displayName: string }>;
+}
+
+export function listProviders(detectors: ProviderDetector[] = DETECTORS): ProvidersListing {
+  return {
+    detectors: detectors.map(({ name, language, description }) => ({ name, language, description })),
+    providers: Object.values(PROVIDERS).map(({ name, displayName }) => ({ name, displayName })),
+  };
+}
+
+export function formatProvidersListing(listing: ProvidersListing): string {
+  const detectors = formatTable(
+    [{ header: 'Detector' }, { header: 'Language' }, { header: 'Looks for' }],
+    listing.detectors.map((d) => [d.name, d.language, d.description]),
+  );
+  const providers = listing.providers.map((p) => `  ${p.name.padEnd(10)} ${chalk.dim(p.displayName)}`);
+  return [detectors, '', chalk.bold('Migration providers (--provider):'), ...providers].join('\n');
+}
+
+export async function runProviders(options: { json?: boolean }): Promise<void> {
+  const listing = listProviders();
+  console.log(options.json ? JSON.stringify(listing, null, 2) : formatProvidersListing(listing));
+}
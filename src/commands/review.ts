@@ -0,0 +1,102 @@
+import chalk from 'chalk';
+import { existsSync } from 'node:fs';
+import path from 'node:path';
+import { applyConfidenceThreshold, DEFAULT_MIN_CONFIDENCE, detectProviders } from '../migrate/detect.js';
+import { selectMigration } from '../migrate/plan.js';
+import { applyReviewFiles, loadReviewPlan, type ReviewPlan } from '../lib/review-plan.js';
+import { serveReview, type ReviewServer } from '../lib/review-server.js';
+import { openBrowser } from '../utils/browser.js';
+import { relativePosix } from '../utils/paths.js';
+
+export interface ReviewOptions {
+  installDir?: string;
+  /** Serve the plan as a local page with an "Apply selected" button */
+  web?: boolean;
+  open?: boolean;
+}
+
+/** The provider the patch migrates from, as `migrate` would pick it */
+async function describeProvider(installDir: string): Promise<string | null> {
+  try {
+    const detected = applyConfidenceThreshold(await detectProviders(installDir), DEFAULT_MIN_CONFIDENCE);
+    const { context } = selectMigration(detected);
+    return `${context.displayName} (detected, ${Math.round(context.confidence * 100)}%)`;
+  } catch {
+    return null;
+  }
+}
+
+function printPlan(plan: ReviewPlan, provider: string | null): void {
+  console.log(`Provider: ${provider ?? chalk.dim('none detected')}`);
+  for (const file of plan.files) {
+    const stats = file.change ? chalk.dim(`+${file.added} -${file.removed}`) : chalk.yellow('no longer applies');
+    console.log(`  ${file.path} ${stats}`);
+    if (file.env?.added.length) console.log(chalk.dim(`    adds ${file.env.added.join(', ')}`));
+    if (file.env?.changed.length) console.log(chalk.dim(`    changes ${file.env.changed.join(', ')}`));
+    if (file.env?.removed.length) console.log(chalk.dim(`    removes ${file.env.removed.join(', ')}`));
+  }
+}
+
+/**
+ * Review a `--diff-only` patch: list what it changes, or with --web serve
+ * it as a page where changes can be picked and applied. The server stops
+ * after applying or on Ctrl+C.
+ */
+export async function runReview(patchPath: string, options: ReviewOptions = {}): Promise<void> {
+  const installDir = path.resolve(options.installDir ?? process.cwd());
+  if (!existsSync(patchPath)) {
+    console.error(chalk.red(`No such patch: ${patchPath}. Create one with --diff-only ${patchPath}.`));
+    process.exit(1);
+  }
+
+  let plan: ReviewPlan;
+  try {
+    plan = loadReviewPlan(patchPath, installDir);
+  } catch (error) {
+    console.error(chalk.red(`Could not read ${patchPath}: ${error instanceof Error ? error.message : String(error)}`));
+    process.exit(1);
+  }
+  if (plan.files.length === 0) {
+    console.log('The patch has no changes.');
+    return;
+  }
+  const provider = await describeProvider(installDir);
+
+  if (!options.web) {
+    printPlan(plan, provider);
+    console.log(chalk.dim(`\nApply it with git apply ${patchPath}, or pick changes in the browser with --web.`));
+    return;
+  }
+
+  let server: ReviewServer;
+  try {
+    server = await serveReview(plan, { provider, patchPath: relativePosix(process.cwd(), patchPath) }, (files) =>
+      applyReviewFiles(plan, files),
+    );
+  } catch (error) {
+    console.error(chalk.red(`Could not start the review server: ${error instanceof Error ? error.message : error}`));
+    process.exit(1);
+  }
+
+  console.error(`Reviewing ${plan.files.length} file(s) at ${chalk.cyan(server.url)}`);
+  console.error(chalk.dim('Press Ctrl+C to stop without applying.'));
+  if (options.open !== false) void openBrowser(server.url);
+
+  const outcome = await new Promise<Awaited<typeof server.applied> | null>((resolve) => {
+    const onInterrupt = () => resolve(null);
+    process.once('SIGINT', onInterrupt);
+    void server.applied.then((result) => {
+      process.off('SIGINT', onInterrupt);
+      resolve(result);
+    });
+  });
+  await server.close();
+
+  if (!outcome) {
+    console.error('Stopped without applying changes.');
+    return;
+  }
+  for (const file of outcome.applied) console.log(chalk.green(`Applied ${file}`));
+  for (const file of outcome.skipped) console.log(chalk.yellow(`Skipped ${file} (changed since the patch was made)`));
+  if (outcome.applied.length === 0) console.log('No changes applied.');
+}
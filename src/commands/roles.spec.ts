@@ -0,0 +1,147 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const mockConfirm = vi.fn();
+vi.mock('../lib/environment-mode.js', () => ({
+  confirmDestructive: (action: string, api: unknown) => mockConfirm(action, api),
+}));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runRolesList, runRolesSync } = await import('./roles.js');
+
+const admin = { id: 'role_1', slug: 'admin', name: 'Admin', description: null, permissions: ['posts:write'] };
+const viewer = { id: 'role_2', slug: 'viewer', name: 'Viewer', description: null, permissions: ['posts:read'] };
+
+const ROLES_FILE = `roles:
+  - slug: admin
+    name: Administrator
+    permissions:
+      - posts:write
+      - posts:delete
+  - slug: editor
+    name: Editor
+    permissions: [posts:write]
+`;
+
+type Request = { method: string; path: string; params?: Record<string, string> };
+
+/** Answer the role list and membership lookups; `assigned` roles have a member */
+function mockApi(assigned: string[] = []) {
+  mockRequest.mockImplementation((async (request: Request) => {
+    if (request.path === '/authorization/roles' && request.method === 'GET') {
+      return { data: [admin, viewer], list_metadata: {} };
+    }
+    if (request.path === '/user_management/organization_memberships') {
+      return { data: assigned.includes(request.params?.role_slug ?? '') ? [{ id: 'om_1' }] : [], list_metadata: {} };
+    }
+    return null;
+  }) as never);
+}
+
+describe('roles commands', () => {
+  let dir: string;
+  let file: string;
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    mockConfirm.mockReset();
+    dir = mkdtempSync(join(tmpdir(), 'roles-'));
+    file = join(dir, 'roles.yaml');
+    writeFileSync(file, ROLES_FILE);
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('runRolesList', () => {
+    it('lists roles with their permissions', async () => {
+      mockApi();
+      await runRolesList('sk_test');
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('admin');
+      expect(output).toContain('posts:read');
+    });
+  });
+
+  describe('runRolesSync', () => {
+    it('shows the plan without changing anything on --dry-run', async () => {
+      mockApi();
+      await runRolesSync(file, { apiKey: 'sk_test', dryRun: true });
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('+ editor (Editor)');
+      expect(output).toContain('~ admin');
+      expect(output).toContain('name: Admin → Administrator');
+      expect(output).toContain('- viewer (Viewer)');
+      expect(mockRequest).not.toHaveBeenCalledWith(expect.objectContaining({ method: 'POST' }));
+    });
+
+    it('creates, updates and deletes roles to match the file', async () => {
+      mockApi();
+      await runRolesSync(file, { apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'POST', path: '/authorization/roles', idempotencyKey: expect.any(String) }),
+      );
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'PUT',
+          path: '/authorization/roles/admin',
+          body: { slug: 'admin', name: 'Administrator', permissions: ['posts:write', 'posts:delete'] },
+        }),
+      );
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'DELETE', path: '/authorization/roles/viewer' }),
+      );
+      expect(mockConfirm).toHaveBeenCalledWith('Delete 1 role(s) not in roles.yaml', expect.anything());
+      expect(consoleOutput).toContain('Roles synced.');
+    });
+
+    it('refuses to delete a role that is still assigned', async () => {
+      mockApi(['viewer']);
+      await expect(runRolesSync(file, { apiKey: 'sk_test' })).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('Refusing to delete roles assigned to memberships: viewer.');
+      expect(mockRequest).not.toHaveBeenCalledWith(expect.objectContaining({ method: 'DELETE' }));
+    });
+
+    it('reports a file it cannot parse', async () => {
+      const json = join(dir, 'roles.json');
+      writeFileSync(json, '{"roles": "admin"}');
+      mockApi();
+      await expect(runRolesSync(json, { apiKey: 'sk_test' })).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('roles.json: Expected a top-level "roles" array');
+    });
+  });
+});
@@ -0,0 +1,143 @@
+import chalk from 'chalk';
+import { readFileSync } from 'node:fs';
+import { basename, resolve } from 'node:path';
+import { handleApiError } from '../lib/api-error.js';
+import { parseConcurrency } from '../lib/bulk.js';
+import { confirmDestructive } from '../lib/environment-mode.js';
+import {
+  applyRolesPlan,
+  isRoleAssigned,
+  listRoles,
+  parseRolesFile,
+  planRolesSync,
+  type Role,
+  type RolesPlan,
+} from '../lib/roles.js';
+import { WorkOSApiError } from '../lib/workos-api.js';
+import { createProgressBar } from '../utils/progress-bar.js';
+import { formatTable } from '../utils/table.js';
+
+export async function runRolesList(apiKey: string, baseUrl?: string): Promise<void> {
+  let roles: Role[];
+  try {
+    roles = await listRoles({ apiKey, baseUrl });
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  if (roles.length === 0) {
+    console.log('No roles found.');
+    return;
+  }
+  const rows = roles.map((role) => [role.slug, role.name, role.permissions.join(', ') || chalk.dim('none')]);
+  console.log(formatTable([{ header: 'Slug' }, { header: 'Name' }, { header: 'Permissions' }], rows));
+}
+
+export function formatRolesPlan(plan: RolesPlan): string {
+  const lines: string[] = [];
+  for (const role of plan.create) {
+    lines.push(chalk.green(`+ ${role.slug} (${role.name})`));
+    if (role.permissions.length > 0) lines.push(chalk.green(`    permissions: ${role.permissions.join(', ')}`));
+  }
+  for (const { change } of plan.update) {
+    lines.push(chalk.yellow(`~ ${change.slug}`));
+    if (change.name) lines.push(`    name: ${change.name.from} → ${change.name.to}`);
+    if (change.description) {
+      lines.push(`    description: ${change.description.from ?? '(none)'} → ${change.description.to ?? '(none)'}`);
+    }
+    for (const permission of change.addedPermissions) lines.push(chalk.green(`    + ${permission}`));
+    for (const permission of change.removedPermissions) lines.push(chalk.red(`    - ${permission}`));
+  }
+  for (const role of plan.delete) lines.push(chalk.red(`- ${role.slug} (${role.name})`));
+
+  if (lines.length === 0) return 'Roles are in sync.';
+  const counts = `${plan.create.length} to create, ${plan.update.length} to update, ${plan.delete.length} to delete`;
+  return [...lines, '', chalk.dim(`${counts}, ${plan.unchanged.length} unchanged`)].join('\n');
+}
+
+export interface RolesSyncOptions {
+  apiKey: string;
+  baseUrl?: string;
+  dryRun?: boolean;
+  /** Delete roles that are still assigned to memberships */
+  allowDestructive?: boolean;
+  /** Changes applied in parallel */
+  concurrency?: number;
+  /** Append failed changes here as JSON lines */
+  errorsFile?: string;
+}
+
+/**
+ * Converge the environment's roles on a definition file.
+ */
+export async function runRolesSync(file: string, options: RolesSyncOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+
+  try {
+    parseConcurrency(options.concurrency);
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  let plan: RolesPlan;
+  try {
+    const desired = parseRolesFile(readFileSync(resolve(file), 'utf-8'), basename(file));
+    plan = planRolesSync(desired, await listRoles(api));
+  } catch (error) {
+    if (error instanceof WorkOSApiError) handleApiError(error);
+    console.error(chalk.red(`${file}: ${error instanceof Error ? error.message : String(error)}`));
+    process.exit(1);
+  }
+
+  console.log(formatRolesPlan(plan));
+
+  let assigned: string[];
+  try {
+    const inUse = await Promise.all(plan.delete.map((role) => isRoleAssigned(role.slug, api)));
+    assigned = plan.delete.filter((_, i) => inUse[i]).map((role) => role.slug);
+  } catch (error) {
+    handleApiError(error);
+  }
+
+  if (assigned.length > 0 && !options.allowDestructive) {
+    console.error(
+      chalk.red(`Refusing to delete roles assigned to memberships: ${assigned.join(', ')}.`) +
+        ' Reassign those members, add the roles to the file, or pass --allow-destructive.',
+    );
+    process.exit(1);
+  }
+
+  if (options.dryRun || plan.create.length + plan.update.length + plan.delete.length === 0) return;
+  if (plan.delete.length > 0) {
+    await confirmDestructive(`Delete ${plan.delete.length} role(s) not in ${basename(file)}`, api);
+  }
+
+  const controller = new AbortController();
+  const stop = () => controller.abort();
+  process.once('SIGINT', stop);
+  const progress = createProgressBar();
+  const result = await applyRolesPlan(plan, api, {
+    concurrency: options.concurrency,
+    errorsFile: options.errorsFile && resolve(options.errorsFile),
+    signal: controller.signal,
+    onProgress: progress.update,
+  });
+  progress.done();
+  process.off('SIGINT', stop);
+
+  if (result.failures.length === 0 && !result.interrupted) {
+    console.log(chalk.green('Roles synced.'));
+    return;
+  }
+  for (const failure of result.failures.slice(0, 10)) {
+    console.error(chalk.red(`${failure.key}: ${failure.error}`));
+  }
+  if (result.failures.length > 10) console.error(chalk.red(`...and ${result.failures.length - 10} more`));
+  const where = options.errorsFile ? ` Failures are listed in ${options.errorsFile}.` : '';
+  const stopped = result.interrupted ? 'Stopped' : 'Finished';
+  console.error(
+    `${stopped} after ${result.done} of ${result.total} change(s), ${result.failures.length} failed.${where}` +
+      ' Run the sync again to apply the rest.',
+  );
+  process.exit(1);
+}
@@ -0,0 +1,55 @@
+import chalk from 'chalk';
+import { resolve } from 'node:path';
+import { scanGitSecrets, type SecretFinding } from '../lib/secret-scan.js';
+import { formatTable } from '../utils/table.js';
+import clack from '../utils/clack.js';
+
+export interface ScanSecretsOptions {
+  installDir: string;
+  json?: boolean;
+}
+
+export function formatSecretFindings(findings: SecretFinding[]): string {
+  if (findings.length === 0) {
+    return 'No WorkOS secrets found in tracked or staged files.';
+  }
+
+  const rows = findings.map((f) => [
+    `${f.file}:${f.line}`,
+    f.description,
+    f.source === 'index' ? chalk.yellow('staged') : chalk.dim('tracked'),
+    chalk.dim(f.excerpt),
+  ]);
+  const table = formatTable([{ header: 'Location' }, { header: 'Secret' }, { header: 'In' }, { header: 'Line' }], rows);
+
+  return [
+    table,
+    '',
+    `${chalk.red(`${findings.length} secret${findings.length === 1 ? '' : 's'} found.`)} ` +
+      'Move them to a gitignored env file, unstage with `git rm --cached <file>`, and rotate any key that was pushed.',
+  ].join('\n');
+}
+
+/**
+ * Scan the tracked tree and the index for WorkOS secrets.
+ * Exits non-zero on findings so it can run as a pre-commit hook.
+ */
+export async function runScanSecrets(options: ScanSecretsOptions): Promise<void> {
+  let findings: SecretFinding[];
+  try {
+    findings = await scanGitSecrets(resolve(options.installDir));
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    if (options.json) console.error(JSON.stringify({ error: message }));
+    else clack.log.error(`Secret scan failed: ${message}`);
+    process.exit(2);
+  }
+
+  if (options.json) {
+    console.log(JSON.stringify({ findings }, null, 2));
+  } else {
+    console.log(formatSecretFindings(findings));
+  }
+
+  process.exit(findings.length > 0 ? 1 : 0);
+}
@@ -0,0 +1,27 @@
+import {
+  OUTPUT_SCHEMA_VERSION,
+  outputSchema,
+  SCHEMA_DESCRIPTIONS,
+  SCHEMA_NAMES,
+  type SchemaName,
+} from '../lib/output-schema.js';
+import { formatTable } from '../utils/table.js';
+
+/**
+ * `workos schema [name]`: the JSON Schema of a machine-readable output, or
+ * every schema name without one. The schemas ship with the CLI and match
+ * the `schemaVersion` it writes.
+ */
+export function runSchema(name: SchemaName | undefined): void {
+  if (!name) {
+    console.log(
+      formatTable(
+        [{ header: 'Schema' }, { header: 'Describes' }],
+        SCHEMA_NAMES.map((schema) => [schema, SCHEMA_DESCRIPTIONS[schema]]),
+      ),
+    );
+    console.log(`\nschemaVersion ${OUTPUT_SCHEMA_VERSION}. Run \`workos schema <name>\` to print one.`);
+    return;
+  }
+  console.log(JSON.stringify(outputSchema(name), null, 2));
+}
@@ -0,0 +1,79 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { readFileSync } from 'node:fs';
+import { Readable } from 'node:stream';
+import { runSessionDecode } from './session.js';
+
+/** Cookies sealed like the SDKs do; shared with session-seal.spec.ts */
+const fixtures: Record<'password' | 'valid' | 'expired', string> = JSON.parse(
+  readFileSync(new URL('../lib/session-seal.fixtures.json', import.meta.url), 'utf-8'),
+);
+
+describe('session commands', () => {
+  const stdin = Object.getOwnPropertyDescriptor(process, 'stdin')!;
+  let consoleOutput: string[];
+  let errors: string[];
+
+  /** Pipe `cookie` to the command as if from `pbpaste | workos session decode` */
+  function pipe(cookie: string) {
+    Object.defineProperty(process, 'stdin', { value: Readable.from([cookie]), configurable: true });
+  }
+
+  beforeEach(() => {
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation(((code?: number) => {
+      throw new Error(`process.exit(${code})`);
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    vi.unstubAllEnvs();
+    Object.defineProperty(process, 'stdin', stdin);
+  });
+
+  describe('runSessionDecode', () => {
+    it('shows the user, organization and claims of a valid cookie', async () => {
+      pipe(fixtures.valid);
+      await expect(runSessionDecode({ cookiePassword: fixtures.password })).rejects.toThrow('process.exit(0)');
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('jane@example.com user_01');
+      expect(output).toContain('org_01');
+      expect(output).toContain('present (redacted)');
+      expect(output).toContain('support@example.com — Investigating a ticket');
+      expect(output).toContain('"sid": "session_01"');
+    });
+
+    it('prints the result as JSON with --json', async () => {
+      pipe(fixtures.valid);
+      vi.stubEnv('WORKOS_COOKIE_PASSWORD', fixtures.password);
+      await expect(runSessionDecode({ json: true })).rejects.toThrow('process.exit(0)');
+      expect(JSON.parse(consoleOutput.join('\n'))).toMatchObject({
+        status: 'valid',
+        session: { organizationId: 'org_01', hasRefreshToken: true },
+      });
+    });
+
+    it('exits 1 for an expired cookie', async () => {
+      pipe(fixtures.expired);
+      await expect(runSessionDecode({ cookiePassword: fixtures.password })).rejects.toThrow('process.exit(1)');
+    });
+
+    it('exits 2 when the password does not match', async () => {
+      pipe(fixtures.valid);
+      await expect(runSessionDecode({ cookiePassword: 'x'.repeat(32) })).rejects.toThrow('process.exit(2)');
+    });
+
+    it('needs a cookie password', async () => {
+      vi.stubEnv('WORKOS_COOKIE_PASSWORD', '');
+      await expect(runSessionDecode({})).rejects.toThrow('process.exit(2)');
+      expect(errors.join('\n')).toContain('Pass --cookie-password or set WORKOS_COOKIE_PASSWORD.');
+    });
+  });
+});
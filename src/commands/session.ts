@@ -0,0 +1,76 @@
+import chalk from 'chalk';
+import { describeSession, unsealIron, type UnsealStatus } from '../lib/session-seal.js';
+import { formatTable } from '../utils/table.js';
+
+/** Distinct exit codes so scripts can tell the outcomes apart */
+const EXIT_CODES: Record<UnsealStatus, number> = { valid: 0, expired: 1, 'wrong-password': 2, corrupted: 3 };
+
+async function readStdin(): Promise<string> {
+  const chunks: Buffer[] = [];
+  for await (const chunk of process.stdin) chunks.push(Buffer.from(chunk));
+  return Buffer.concat(chunks).toString('utf-8');
+}
+
+function formatDate(date: Date | null | undefined): string {
+  if (!date) return chalk.dim('none');
+  const expired = date.getTime() <= Date.now();
+  return `${date.toISOString()}${expired ? chalk.red(' (expired)') : ''}`;
+}
+
+export interface SessionDecodeOptions {
+  cookiePassword?: string;
+  json?: boolean;
+}
+
+/**
+ * Unseal an AuthKit session cookie read from stdin and show what's in it.
+ */
+export async function runSessionDecode(options: SessionDecodeOptions): Promise<void> {
+  const password = options.cookiePassword || process.env.WORKOS_COOKIE_PASSWORD;
+  if (!password) {
+    console.error(chalk.red('Pass --cookie-password or set WORKOS_COOKIE_PASSWORD.'));
+    process.exit(2);
+  }
+  if (process.stdin.isTTY) console.error(chalk.dim('Paste the sealed cookie value, then press Ctrl+D.'));
+
+  const result = unsealIron(await readStdin(), password);
+  const session = result.data === undefined ? null : describeSession(result.data);
+
+  if (options.json) {
+    console.log(
+      JSON.stringify(
+        { status: result.status, message: result.message, expiresAt: result.expiresAt ?? null, session },
+        null,
+        2,
+      ),
+    );
+    process.exit(EXIT_CODES[result.status]);
+  }
+
+  const color = result.status === 'valid' ? chalk.green : chalk.red;
+  console.log(color(result.message));
+  if (!session) process.exit(EXIT_CODES[result.status]);
+
+  const { user, impersonator } = session;
+  const rows = [
+    ['User', user ? `${user.email ?? ''} ${chalk.dim(user.id ?? '')}`.trim() : chalk.dim('none')],
+    ['Organization', session.organizationId ?? chalk.dim('none')],
+    ['Cookie expires', formatDate(result.expiresAt)],
+    ['Access token expires', formatDate(session.accessTokenExpiresAt)],
+    ['Refresh token', session.hasRefreshToken ? 'present (redacted)' : chalk.yellow('missing')],
+    [
+      'Impersonator',
+      impersonator
+        ? `${impersonator.email ?? 'unknown'}${impersonator.reason ? ` — ${impersonator.reason}` : ''}`
+        : chalk.dim('none'),
+    ],
+  ];
+  console.log(formatTable([{ header: 'Field' }, { header: 'Value' }], rows));
+
+  if (session.claims) {
+    console.log('');
+    console.log(chalk.bold('Access token claims'));
+    console.log(JSON.stringify(session.claims, null, 2));
+  }
+  process.exit(EXIT_CODES[result.status]);
+}
@@ -0,0 +1,106 @@
+import { existsSync, statSync } from 'node:fs';
+import { join, relative, resolve } from 'node:path';
+import chalk from 'chalk';
+import type { ArgumentsCamelCase } from 'yargs';
+import { scaffoldSkill, validateSkill, type SkillValidation } from '../lib/skill-authoring.js';
+import { discoverSkills, getSkillsDir } from './install-skill.js';
+import { handleInstall, type InstallArgs } from './install.js';
+
+export interface SkillsNewOptions {
+  id: string;
+  /** Bundled skill name or a skill directory to copy */
+  from?: string;
+  /** Where to create the skill directory (default: cwd) */
+  dir?: string;
+}
+
+export interface SkillsValidateOptions {
+  dir: string;
+  json?: boolean;
+}
+
+export interface SkillsRunOptions extends InstallArgs {
+  dir: string;
+  /** App the skill runs against */
+  target: string;
+}
+
+async function bundledSkillNames(): Promise<string[]> {
+  const skillsDir = getSkillsDir();
+  return existsSync(skillsDir) ? discoverSkills(skillsDir) : [];
+}
+
+/** A --from value: a directory on disk, else a bundled skill's name */
+async function resolveBaseSkill(from: string): Promise<string> {
+  if (existsSync(from) && statSync(from).isDirectory()) return resolve(from);
+  const bundled = await bundledSkillNames();
+  if (bundled.includes(from)) return join(getSkillsDir(), from);
+  throw new Error(`No skill "${from}". Pass a skill directory or one of: ${bundled.join(', ')}`);
+}
+
+function printValidation(dir: string, result: SkillValidation): void {
+  for (const warning of result.warnings) console.log(`${chalk.yellow('!')} ${warning}`);
+  for (const error of result.errors) console.log(`${chalk.red('✗')} ${error}`);
+  if (result.errors.length === 0) {
+    console.log(chalk.green(`${result.id ?? dir} is valid.`));
+  } else {
+    console.log(chalk.red(`${result.errors.length} problem${result.errors.length === 1 ? '' : 's'} in ${dir}.`));
+  }
+}
+
+/**
+ * Scaffold a custom skill directory, from the template or a copy of an
+ * existing skill.
+ */
+export async function runSkillsNew(options: SkillsNewOptions): Promise<void> {
+  let dir: string;
+  try {
+    const from = options.from ? await resolveBaseSkill(options.from) : undefined;
+    dir = scaffoldSkill(options.id, { parentDir: resolve(options.dir ?? process.cwd()), from });
+  } catch (error) {
+    console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+    process.exit(1);
+  }
+
+  const shown = relative(process.cwd(), dir) || '.';
+  console.log(chalk.green(`Created ${shown}`));
+  console.log(`  SKILL.md     prompt the agent follows${options.from ? '' : ' (fill in the {{placeholders}})'}`);
+  console.log('  verify.json  checks run against the app after the agent finishes');
+  console.log('  skill.json   id, display name and placeholder docs');
+  console.log('  package.json npm test runs workos skills validate');
+  console.log(`\nTry it: ${chalk.cyan(`workos skills run ${shown} --target ../my-app`)}`);
+}
+
+/** Check a skill directory. Exits 1 on errors, so it can gate CI. */
+export async function runSkillsValidate(options: SkillsValidateOptions): Promise<void> {
+  const result = validateSkill(resolve(options.dir), await bundledSkillNames());
+  if (options.json) {
+    console.log(JSON.stringify({ ...result, valid: result.errors.length === 0 }, null, 2));
+  } else {
+    printValidation(options.dir, result);
+  }
+  if (result.errors.length > 0) process.exit(1);
+}
+
+/**
+ * Run the installer against a target app with a local, unpublished skill in
+ * place of the integration's bundled one. Nothing is published or installed
+ * to coding agents; the skill is handed to this run's agent only.
+ */
+export async function runSkillsRun(options: ArgumentsCamelCase<SkillsRunOptions>): Promise<void> {
+  const dir = resolve(options.dir);
+  const result = validateSkill(dir, await bundledSkillNames());
+  if (result.errors.length > 0 || !result.id) {
+    printValidation(options.dir, result);
+    process.exit(1);
+  }
+  for (const warning of result.warnings) console.log(`${chalk.yellow('!')} ${warning}`);
+
+  const target = resolve(options.target);
+  if (!existsSync(target) || !statSync(target).isDirectory()) {
+    console.error(chalk.red(`--target ${options.target} is not a directory`));
+    process.exit(1);
+  }
+
+  await handleInstall({ ...options, installDir: target, localSkill: { name: result.id, dir } });
+}
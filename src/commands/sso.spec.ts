@@ -0,0 +1,205 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest, WorkOSApiError } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runSsoCreate, runSsoConfigure, runSsoMapTest, formatProfileMapping, formatMappingResult } =
+  await import('./sso.js');
+
+const connection = {
+  id: 'conn_123',
+  organization_id: 'org_123',
+  connection_type: 'OktaSAML',
+  name: 'Acme',
+  state: 'draft',
+  saml: { acs_url: 'https://auth.workos.com/sso/saml/acs/abc', sp_entity_id: 'https://auth.workos.com/abc' },
+};
+
+const ASSERTION = `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">
+  <saml:Subject><saml:NameID>00u123</saml:NameID></saml:Subject>
+  <saml:AttributeStatement>
+    <saml:Attribute Name="mail"><saml:AttributeValue>ada@acme.com</saml:AttributeValue></saml:Attribute>
+    <saml:Attribute Name="givenName"><saml:AttributeValue>Ada</saml:AttributeValue></saml:Attribute>
+    <saml:Attribute Name="department"><saml:AttributeValue>R&amp;D</saml:AttributeValue></saml:Attribute>
+  </saml:AttributeStatement>
+</saml:Assertion>`;
+
+describe('sso commands', () => {
+  let consoleOutput: string[];
+  let errors: string[];
+  const isTTY = process.stdin.isTTY;
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    // Nothing prompts; commands that would ask take the non-interactive path
+    process.stdin.isTTY = false;
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    process.stdin.isTTY = isTTY;
+    vi.restoreAllMocks();
+  });
+
+  describe('runSsoCreate', () => {
+    it('creates the connection and prints the SP values for the IdP admin', async () => {
+      mockRequest.mockResolvedValue(connection);
+      await runSsoCreate({ org: 'org_123', type: 'okta-saml', apiKey: 'sk_test' });
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/connections',
+          body: { organization_id: 'org_123', connection_type: 'OktaSAML' },
+        }),
+      );
+      const output = consoleOutput.join('\n');
+      expect(output).toContain('Give these to the Okta admin:');
+      expect(output).toContain(connection.saml.acs_url);
+      expect(output).toContain('workos sso configure conn_123 --idp-metadata <url-or-file>');
+    });
+
+    it('finishes the connection with the IdP metadata when given', async () => {
+      mockRequest.mockResolvedValueOnce(connection).mockResolvedValueOnce({ ...connection, state: 'active' });
+      await runSsoCreate({
+        org: 'org_123',
+        type: 'okta-saml',
+        idpMetadata: 'https://acme.okta.com/app/abc/sso/saml/metadata',
+        apiKey: 'sk_test',
+      });
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({
+          method: 'PUT',
+          path: '/connections/conn_123',
+          body: { idp_metadata_url: 'https://acme.okta.com/app/abc/sso/saml/metadata' },
+        }),
+      );
+      expect(consoleOutput).toContain('IdP metadata saved');
+    });
+
+    it('rejects an unknown connection type without calling the API', async () => {
+      await expect(runSsoCreate({ org: 'org_123', type: 'okta-oidc', apiKey: 'sk_test' })).rejects.toThrow(
+        'process.exit',
+      );
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('Unknown connection type "okta-oidc"');
+    });
+  });
+
+  describe('runSsoConfigure', () => {
+    it('says the connection was not found on a 404', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Not Found', 404));
+      await expect(
+        runSsoConfigure('conn_missing', { idpMetadata: 'https://acme.okta.com/metadata', apiKey: 'sk_test' }),
+      ).rejects.toThrow('process.exit');
+      expect(errors.join('\n')).toContain('Organization or connection not found.');
+    });
+  });
+
+  describe('formatProfileMapping', () => {
+    it('shows the IdP attribute behind each field and flags missing names', () => {
+      const output = formatProfileMapping({
+        id: 'prof_123',
+        connection_id: 'conn_123',
+        idp_id: '00u123',
+        email: 'ada@acme.com',
+        first_name: 'Ada',
+        raw_attributes: { mail: 'ada@acme.com', givenName: 'Ada' },
+      });
+      expect(output).toMatch(/email\s+ada@acme\.com\s+mail/);
+      expect(output).toContain('Attributes received from the IdP');
+      expect(output).toContain('First or last name is missing');
+    });
+  });
+
+  describe('formatMappingResult', () => {
+    it('lists unmapped attributes and required fields that came out empty', () => {
+      const assertion = { nameId: '00u123', attributes: { mail: ['ada@acme.com'], department: ['R&D'] } };
+      const output = formatMappingResult(assertion, {
+        fields: [
+          { field: 'idp_id', attribute: 'NameID', value: '00u123', required: true },
+          { field: 'email', attribute: 'mail', value: 'ada@acme.com', required: true },
+          { field: 'last_name', attribute: null, value: null, required: true },
+        ],
+        unmapped: ['department'],
+        missing: ['last_name'],
+      });
+      expect(output).toContain('Attributes in the assertion');
+      expect(output).toContain('Not mapped to any profile field: department');
+      expect(output).toContain('Required fields came out empty: last_name');
+    });
+  });
+
+  describe('runSsoMapTest', () => {
+    let dir: string;
+
+    beforeEach(() => {
+      dir = mkdtempSync(join(tmpdir(), 'sso-map-test-'));
+      writeFileSync(join(dir, 'assertion.xml'), ASSERTION);
+    });
+
+    afterEach(() => {
+      rmSync(dir, { recursive: true, force: true });
+    });
+
+    it("runs the assertion through the connection's current mapping", async () => {
+      mockRequest.mockResolvedValue({ attribute_mapping: { email: 'mail', first_name: 'givenName' } });
+      await expect(
+        runSsoMapTest({ connectionId: 'conn_123', assertion: join(dir, 'assertion.xml'), apiKey: 'sk_test' }),
+      ).rejects.toThrow('process.exit');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'GET', path: '/connections/conn_123/attribute_mapping' }),
+      );
+      expect(consoleOutput.join('\n')).toContain('Required fields came out empty: last_name');
+    });
+
+    it('saves a proposed mapping that fills every required field', async () => {
+      const mapping = { email: 'mail', first_name: 'givenName', last_name: 'department' };
+      writeFileSync(join(dir, 'mapping.json'), JSON.stringify(mapping));
+      mockRequest.mockResolvedValue({ attribute_mapping: mapping });
+      await runSsoMapTest({
+        connectionId: 'conn_123',
+        assertion: join(dir, 'assertion.xml'),
+        mapping: join(dir, 'mapping.json'),
+        save: true,
+        apiKey: 'sk_test',
+      });
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'PUT',
+          path: '/connections/conn_123/attribute_mapping',
+          body: { attribute_mapping: mapping },
+        }),
+      );
+      expect(consoleOutput).toContain('Attribute mapping saved to conn_123');
+    });
+  });
+});
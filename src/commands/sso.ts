@@ -0,0 +1,301 @@
+import chalk from 'chalk';
+import { randomBytes } from 'node:crypto';
+import { readFileSync } from 'node:fs';
+import { resolve } from 'node:path';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import { writeFileAtomic } from '../lib/atomic-write.js';
+import { getActiveEnvironment } from '../lib/config-store.js';
+import {
+  applyAttributeMapping,
+  getAttributeMapping,
+  NAME_ID,
+  parseMappingFile,
+  parseSamlAssertion,
+  saveAttributeMapping,
+  type AttributeMapping,
+  type MappingResult,
+  type SamlAssertion,
+} from '../lib/sso-attribute-mapping.js';
+import {
+  buildSsoAuthorizationUrl,
+  configureIdpMetadata,
+  createSsoConnection,
+  exchangeSsoCode,
+  formatIdpSetupDoc,
+  reportProfileMapping,
+  resolveConnectionType,
+  startCallbackServer,
+  type CallbackServer,
+  type SsoConnection,
+  type SsoConnectionType,
+  type SsoProfile,
+} from '../lib/sso-connections.js';
+import { openBrowser } from '../utils/browser.js';
+import clack from '../utils/clack.js';
+import { formatTable } from '../utils/table.js';
+
+const API_ERRORS: ApiErrorMessages = { notFound: 'Organization or connection not found.' };
+
+function printConnection(connection: SsoConnection): void {
+  console.log(`${chalk.bold(connection.id)} (${connection.connection_type}, ${connection.state})`);
+}
+
+export interface SsoCreateOptions {
+  org: string;
+  type: string;
+  /** IdP metadata URL or XML file; prompted for when omitted in a terminal */
+  idpMetadata?: string;
+  /** Write IdP setup instructions to this markdown file */
+  doc?: string;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+/**
+ * Create a SAML connection, print the SP values for the IdP admin and
+ * finish the connection with the IdP's metadata.
+ */
+export async function runSsoCreate(options: SsoCreateOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+
+  let type: SsoConnectionType;
+  let connection: SsoConnection;
+  try {
+    type = resolveConnectionType(options.type);
+    connection = await createSsoConnection(options.org, type, api);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  console.log(chalk.green(`Created ${type.idpName} SAML connection`));
+  printConnection(connection);
+  console.log('');
+  console.log(`Give these to the ${type.idpName} admin:`);
+  console.log(
+    formatTable(
+      [{ header: 'Field' }, { header: 'Value' }],
+      [
+        [type.acsField, connection.saml?.acs_url ?? '-'],
+        [type.entityIdField, connection.saml?.sp_entity_id ?? '-'],
+      ],
+    ),
+  );
+
+  if (options.doc) {
+    const docPath = resolve(options.doc);
+    writeFileAtomic(docPath, formatIdpSetupDoc(connection, type));
+    console.log(chalk.dim(`Setup instructions written to ${docPath}`));
+  }
+
+  let metadata = options.idpMetadata;
+  if (!metadata && process.stdin.isTTY) {
+    const answer = await clack.text({
+      message: `IdP metadata URL or XML file (from ${type.metadataLocation}); leave empty to finish later`,
+    });
+    if (!clack.isCancel(answer) && answer?.trim()) metadata = answer.trim();
+  }
+
+  if (!metadata) {
+    const next = `workos sso configure ${connection.id} --idp-metadata <url-or-file>`;
+    console.log(`Once the admin sends the metadata, run ${chalk.cyan(next)}`);
+    return;
+  }
+
+  await runSsoConfigure(connection.id, { idpMetadata: metadata, apiKey: options.apiKey, baseUrl: options.baseUrl });
+}
+
+export interface SsoConfigureOptions {
+  idpMetadata: string;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+/**
+ * Complete a SAML connection from the IdP metadata URL or file.
+ */
+export async function runSsoConfigure(connectionId: string, options: SsoConfigureOptions): Promise<void> {
+  try {
+    const connection = await configureIdpMetadata(connectionId, options.idpMetadata, {
+      apiKey: options.apiKey,
+      baseUrl: options.baseUrl,
+    });
+    console.log(chalk.green('IdP metadata saved'));
+    printConnection(connection);
+    console.log(`Test the connection with ${chalk.cyan(`workos sso test ${connection.id}`)}`);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+}
+
+export function formatProfileMapping(profile: SsoProfile): string {
+  const report = reportProfileMapping(profile);
+  const rows = report.fields.map(({ field, value, source }) => [
+    field,
+    value ?? chalk.red('(missing)'),
+    source ?? chalk.dim('-'),
+  ]);
+  const lines = [formatTable([{ header: 'Profile field' }, { header: 'Value' }, { header: 'IdP attribute' }], rows)];
+
+  const raw = Object.entries(profile.raw_attributes ?? {});
+  if (raw.length > 0) {
+    lines.push('', chalk.bold('Attributes received from the IdP'));
+    const rawRows = raw.map(([key, value]) => [key, JSON.stringify(value)]);
+    lines.push(formatTable([{ header: 'Attribute' }, { header: 'Value' }], rawRows));
+  }
+  for (const problem of report.problems) lines.push(chalk.yellow(`! ${problem}`));
+  return lines.join('\n');
+}
+
+export interface SsoTestOptions {
+  apiKey: string;
+  baseUrl?: string;
+  /** Defaults to WORKOS_CLIENT_ID, then the active environment's client ID */
+  clientId?: string;
+  /** Local port for the callback; http://localhost:<port>/callback must be an allowed redirect URI */
+  port?: number;
+  /** Open the login URL in a browser */
+  open?: boolean;
+}
+
+/**
+ * Run an SP-initiated login and report the profile the assertion mapped to.
+ */
+export async function runSsoTest(connectionId: string, options: SsoTestOptions): Promise<void> {
+  const clientId = options.clientId ?? process.env.WORKOS_CLIENT_ID ?? getActiveEnvironment()?.clientId;
+  if (!clientId) {
+    console.error(chalk.red('No client ID configured. Pass --client-id or set WORKOS_CLIENT_ID.'));
+    process.exit(1);
+  }
+  const client = { clientId, apiKey: options.apiKey, baseUrl: options.baseUrl };
+
+  const state = randomBytes(16).toString('hex');
+  let callback: CallbackServer;
+  try {
+    callback = await startCallbackServer(options.port ?? 9004, state);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  const url = buildSsoAuthorizationUrl(connectionId, callback.redirectUri, state, client);
+  console.log(`Sign in with a test user at:\n${chalk.cyan(url)}`);
+  console.log(chalk.dim(`${callback.redirectUri} must be an allowed redirect URI for this environment.`));
+  if (options.open !== false) void openBrowser(url);
+
+  const spinner = clack.spinner();
+  spinner.start('Waiting for the SSO login');
+  let profile: SsoProfile;
+  try {
+    const code = await callback.code;
+    profile = await exchangeSsoCode(code, client);
+    spinner.stop('Login completed');
+  } catch (error) {
+    spinner.stop('Login failed');
+    callback.close();
+    handleApiError(error, API_ERRORS);
+  }
+
+  console.log(chalk.green(`Profile ${profile.id} mapped for ${profile.email || '(no email)'}`));
+  console.log(formatProfileMapping(profile));
+  if (reportProfileMapping(profile).problems.length > 0) process.exit(1);
+}
+
+export interface SsoMapTestOptions {
+  connectionId: string;
+  /** SAML response or assertion: XML or the base64 SAMLResponse */
+  assertion: string;
+  /** Proposed mapping to test instead of the connection's current one */
+  mapping?: string;
+  /** Save the proposed mapping to the connection when every required field maps */
+  save?: boolean;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export function formatMappingResult(assertion: SamlAssertion, result: MappingResult): string {
+  const rows = result.fields.map(({ field, attribute, value, required }) => [
+    field,
+    value ?? (required ? chalk.red('(empty)') : chalk.dim('(empty)')),
+    attribute ?? chalk.dim('-'),
+  ]);
+  const lines = [formatTable([{ header: 'Profile field' }, { header: 'Value' }, { header: 'IdP attribute' }], rows)];
+
+  const unmapped = new Set(result.unmapped);
+  const raw = [
+    ...(assertion.nameId ? [[NAME_ID, assertion.nameId]] : []),
+    ...Object.entries(assertion.attributes).map(([name, values]) => [name, JSON.stringify(values)]),
+  ];
+  if (raw.length > 0) {
+    lines.push('', chalk.bold('Attributes in the assertion'));
+    const rawRows = raw.map(([name, value]) => [unmapped.has(name) ? chalk.yellow(name) : name, value]);
+    lines.push(formatTable([{ header: 'Attribute' }, { header: 'Value' }], rawRows));
+  }
+  if (result.unmapped.length > 0) {
+    lines.push(chalk.yellow(`! Not mapped to any profile field: ${result.unmapped.join(', ')}`));
+  }
+  if (result.missing.length > 0) {
+    lines.push(chalk.red(`! Required fields came out empty: ${result.missing.join(', ')}`));
+  }
+  return lines.join('\n');
+}
+
+function readInputFile(file: string): string {
+  try {
+    return readFileSync(resolve(file), 'utf-8');
+  } catch {
+    console.error(chalk.red(`Could not read ${file}`));
+    process.exit(1);
+  }
+}
+
+/**
+ * Run a sample assertion through the connection's attribute mapping, or a
+ * proposed one, and save the proposed mapping once it fills every field.
+ */
+export async function runSsoMapTest(options: SsoMapTestOptions): Promise<void> {
+  const api = { apiKey: options.apiKey, baseUrl: options.baseUrl };
+  if (options.save && !options.mapping) {
+    console.error(chalk.red('--save needs a proposed mapping; pass it with --mapping.'));
+    process.exit(1);
+  }
+
+  let assertion: SamlAssertion;
+  try {
+    assertion = parseSamlAssertion(readInputFile(options.assertion));
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+
+  let mapping: AttributeMapping;
+  if (options.mapping) {
+    const parsed = parseMappingFile(readInputFile(options.mapping));
+    if (parsed.errors.length > 0) {
+      console.error(chalk.red([`${options.mapping}:`, ...parsed.errors.map((e) => `  ${e}`)].join('\n')));
+      process.exit(1);
+    }
+    mapping = parsed.mapping;
+  } else {
+    try {
+      mapping = await getAttributeMapping(options.connectionId, api);
+    } catch (error) {
+      handleApiError(error, API_ERRORS);
+    }
+  }
+
+  const result = applyAttributeMapping(assertion, mapping);
+  const source = options.mapping ? `the mapping in ${options.mapping}` : 'the current mapping';
+  console.log(chalk.dim(`Using ${source} for ${options.connectionId}`));
+  console.log(formatMappingResult(assertion, result));
+
+  if (result.missing.length > 0) {
+    if (options.save) console.error(chalk.red('Not saving a mapping that leaves required fields empty.'));
+    process.exit(1);
+  }
+  if (!options.save) return;
+
+  try {
+    await saveAttributeMapping(options.connectionId, mapping, api);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+  console.log(chalk.green(`Attribute mapping saved to ${options.connectionId}`));
+}
@@ -0,0 +1,135 @@
+import chalk from 'chalk';
+import { existsSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import { resolveApiBaseUrl, resolveApiKey } from '../lib/api-key.js';
+import { getConfig } from '../lib/config-store.js';
+import { readInstallRecord } from '../lib/existing-integration.js';
+import {
+  checkRegisteredRedirects,
+  collectProjectStatus,
+  projectApiKey,
+  readSkillPrompt,
+  type ProjectStatus,
+} from '../lib/project-status.js';
+import { getRegistry } from '../lib/registry.js';
+import { digestSkillFiles, hashSkillFiles, readSkillLock } from '../lib/skill-integrity.js';
+import { discoverSkills, getSkillsDir } from './install-skill.js';
+
+export interface StatusOptions {
+  installDir: string;
+  json?: boolean;
+  /** Also check the redirect URIs registered in WorkOS */
+  remote?: boolean;
+}
+
+async function bundledDigests(skillsDir: string): Promise<Record<string, string>> {
+  if (!existsSync(skillsDir)) return {};
+  const skills = await discoverSkills(skillsDir);
+  return Object.fromEntries(skills.map((skill) => [skill, digestSkillFiles(hashSkillFiles(join(skillsDir, skill)))]));
+}
+
+export function formatProjectStatus(status: ProjectStatus): string {
+  const lines: string[] = [];
+  const row = (label: string, value: string) => lines.push(`${chalk.dim(label.padEnd(13))}${value}`);
+  const none = chalk.dim('none');
+
+  const installed = status.installedAt ? chalk.dim(` (installed ${status.installedAt.slice(0, 10)})`) : '';
+  row('Integration', status.integration ? `${status.integration}${installed}` : none);
+  row('Skill', status.skill ?? none);
+
+  const sdks = status.sdks.map(
+    (sdk) => `${sdk.name} ${sdk.version ?? chalk.dim('unpinned')} ${chalk.dim(sdk.manifest)}`,
+  );
+  row('SDKs', sdks[0] ?? none);
+  for (const sdk of sdks.slice(1)) row('', sdk);
+
+  const { env } = status;
+  const envFiles = env.files.length > 0 ? chalk.dim(` in ${env.files.join(', ')}`) : '';
+  row('Env keys', env.present.length > 0 ? `${env.present.join(', ')}${envFiles}` : none);
+  if (env.missing.length > 0) row('', chalk.yellow(`missing for ${status.skill}: ${env.missing.join(', ')}`));
+
+  const { environment } = status;
+  const mode = environment.type === 'production' ? chalk.red('production') : chalk.green(environment.type ?? '');
+  if (environment.profile) {
+    const { activeProfile, profile } = environment;
+    const mismatch =
+      activeProfile && activeProfile !== profile
+        ? chalk.yellow(` (active profile is ${activeProfile}; run \`workos env switch ${profile}\`)`)
+        : '';
+    row('Environment', `${environment.profile} ${mode}${mismatch}`);
+  } else if (environment.type) {
+    row('Environment', `${mode} ${chalk.dim('(no CLI profile uses these keys; add one with `workos env add`)')}`);
+  } else {
+    row('Environment', none);
+  }
+
+  const { redirect } = status;
+  if (redirect.configured) {
+    const verdict =
+      redirect.matchesDevServer === false
+        ? chalk.yellow(` (dev server is ${new URL(redirect.devCallback!).origin})`)
+        : '';
+    row('Redirect URI', `${redirect.configured}${verdict}`);
+  } else {
+    row('Redirect URI', `${none}${redirect.devCallback ? chalk.dim(` (dev callback ${redirect.devCallback})`) : ''}`);
+  }
+  if (redirect.remoteError) {
+    row('', chalk.yellow(`registered URIs unavailable: ${redirect.remoteError}`));
+  } else if (redirect.registered) {
+    const covered = redirect.registeredCoverDevServer;
+    row(
+      '',
+      covered === null
+        ? chalk.dim(`${redirect.registered.length} registered in WorkOS`)
+        : covered
+          ? chalk.green(`registered in WorkOS for ${redirect.devCallback}`)
+          : chalk.yellow(`${redirect.devCallback} is not registered; run \`workos dashboard redirect-uris\` to add it`),
+    );
+  }
+
+  const pending = status.skills.filter((skill) => skill.updateAvailable);
+  const agents = [...new Set(status.skills.map((skill) => skill.agent))];
+  row('Agent skills', status.skills.length > 0 ? `${status.skills.length} installed for ${agents.join(', ')}` : none);
+  for (const skill of pending) {
+    row('', chalk.yellow(`${skill.skill} (${skill.agent}) has an update: workos install-skill --skill ${skill.skill}`));
+  }
+
+  return lines.join('\n');
+}
+
+/**
+ * `workos status`: what WorkOS setup the project already has. Reads local
+ * files only unless --remote is passed.
+ */
+export async function runStatus(options: StatusOptions): Promise<void> {
+  const installDir = resolve(options.installDir);
+  const skillsDir = getSkillsDir();
+
+  // The registry is only loaded when an earlier install names the integration
+  const integration = readInstallRecord(installDir)?.integration;
+  const skill = integration
+    ? ((await getRegistry()).get(integration)?.config.metadata.skillName ?? integration)
+    : undefined;
+
+  const status = collectProjectStatus(installDir, {
+    skill,
+    skillPrompt: skill ? readSkillPrompt(skillsDir, skill) : undefined,
+    lock: await readSkillLock(),
+    bundledDigests: await bundledDigests(skillsDir),
+    config: getConfig(),
+  });
+
+  if (options.remote) {
+    let apiKey = projectApiKey(installDir);
+    try {
+      apiKey ??= resolveApiKey();
+    } catch (error) {
+      status.redirect.remoteError = error instanceof Error ? error.message : String(error);
+    }
+    if (apiKey) {
+      status.redirect = await checkRegisteredRedirects(status.redirect, { apiKey, baseUrl: resolveApiBaseUrl() });
+    }
+  }
+
+  console.log(options.json ? JSON.stringify(status, null, 2) : formatProjectStatus(status));
+}
@@ -0,0 +1,55 @@
+import chalk from 'chalk';
+import { saveTelemetryChoice } from '../lib/telemetry-preference.js';
+import { isTelemetryEnabled, telemetrySource } from '../utils/telemetry-consent.js';
+import { telemetryClient, TELEMETRY_LOG_PATH } from '../utils/telemetry-client.js';
+import { clearSpool, deliverSpool, readSpool, TELEMETRY_SPOOL_PATH } from '../utils/telemetry-spool.js';
+
+const SOURCES = {
+  flag: '--no-telemetry',
+  env: 'DO_NOT_TRACK / WORKOS_CLI_TELEMETRY',
+  config: 'saved choice',
+  default: 'default',
+};
+
+export function runTelemetry(action: 'on' | 'off' | 'status' | 'show' = 'status'): void {
+  if (action === 'show') {
+    runTelemetryShow();
+    return;
+  }
+  if (action !== 'status') {
+    saveTelemetryChoice(action === 'on');
+  }
+  if (action === 'off') {
+    clearSpool();
+  }
+
+  const state = isTelemetryEnabled() ? chalk.green('on') : chalk.yellow('off');
+  console.log(`Telemetry is ${state} (${SOURCES[telemetrySource()]})`);
+  if (action !== 'status' && telemetrySource() !== 'config') {
+    console.log(chalk.dim(`Saved, but ${SOURCES[telemetrySource()]} overrides the saved choice.`));
+  }
+  console.log(chalk.dim(`Sent payloads are logged to ${TELEMETRY_LOG_PATH}`));
+}
+
+/** `workos telemetry show`: the pending payloads exactly as they will be sent */
+function runTelemetryShow(): void {
+  const entries = readSpool();
+  if (entries.length === 0) {
+    console.error(chalk.dim(`Nothing waiting to be sent (${TELEMETRY_SPOOL_PATH})`));
+    console.error(chalk.dim(`Sent payloads are logged to ${TELEMETRY_LOG_PATH}`));
+    return;
+  }
+  for (const entry of entries) {
+    console.log(chalk.dim(`POST ${entry.gatewayUrl}/telemetry`));
+    console.log(JSON.stringify({ events: entry.events }, null, 2));
+  }
+}
+
+/** The hidden `__telemetry-send` command: deliver the spool, or drop it if telemetry was turned off */
+export async function runTelemetrySend(): Promise<void> {
+  if (!isTelemetryEnabled()) {
+    clearSpool();
+    return;
+  }
+  await deliverSpool((gatewayUrl, events) => telemetryClient.send(gatewayUrl, events));
+}
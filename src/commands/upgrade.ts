@@ -0,0 +1,72 @@
+import chalk from 'chalk';
+import { lte } from 'semver';
+import { getVersion } from '../lib/settings.js';
+import { proxiedFetch } from '../lib/proxy.js';
+import {
+  cleanupPreviousInstalls,
+  detectInstallMethod,
+  fetchRelease,
+  getPackageRoot,
+  getUpgradeCommand,
+  replaceInstallation,
+  verifyIntegrity,
+  type UpdateChannel,
+} from '../lib/self-update.js';
+
+export interface UpgradeOptions {
+  channel: UpdateChannel;
+  /** Report the available version without installing */
+  check?: boolean;
+}
+
+export async function runUpgrade(options: UpgradeOptions): Promise<void> {
+  const currentVersion = getVersion();
+  const packageRoot = getPackageRoot();
+
+  await cleanupPreviousInstalls(packageRoot);
+
+  let release;
+  try {
+    release = await fetchRelease(options.channel);
+  } catch (error) {
+    console.error(chalk.red(`Could not check for updates: ${error instanceof Error ? error.message : String(error)}`));
+    process.exit(1);
+  }
+
+  if (lte(release.version, currentVersion)) {
+    console.log(chalk.green(`Already up to date (${currentVersion}, ${options.channel} channel).`));
+    return;
+  }
+
+  console.log(`Update available: ${chalk.dim(currentVersion)} → ${chalk.cyan(release.version)} (${options.channel})`);
+  if (options.check) return;
+
+  const method = detectInstallMethod(packageRoot);
+  const command = getUpgradeCommand(method, options.channel);
+  if (command) {
+    console.log(`\nInstalled via ${method}. Upgrade with:\n\n  ${chalk.cyan(command)}\n`);
+    return;
+  }
+
+  console.log(chalk.dim(`Downloading ${release.tarball}`));
+  const response = await proxiedFetch(release.tarball);
+  if (!response.ok) {
+    console.error(chalk.red(`Download failed (HTTP ${response.status})`));
+    process.exit(1);
+  }
+  const tarball = Buffer.from(await response.arrayBuffer());
+
+  if (!verifyIntegrity(tarball, release.integrity)) {
+    console.error(chalk.red('Checksum verification failed. The download was discarded.'));
+    process.exit(1);
+  }
+
+  try {
+    await replaceInstallation(packageRoot, tarball);
+  } catch (error) {
+    console.error(chalk.red(`Upgrade failed: ${error instanceof Error ? error.message : String(error)}`));
+    process.exit(1);
+  }
+
+  console.log(chalk.green(`✓ Upgraded to ${release.version}`));
+}
@@ -0,0 +1,123 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runUserFactorsList, runUserFactorEnroll, runUserFactorDelete, runUserFactorCode } =
+  await import('./user-factors.js');
+
+const SECRET = 'GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ';
+const URI = `otpauth://totp/WorkOS:test%40example.com?secret=${SECRET}&issuer=WorkOS`;
+
+const enrolled = {
+  authentication_factor: {
+    id: 'auth_factor_1',
+    type: 'totp',
+    totp: { issuer: 'WorkOS', user: 'test@example.com', secret: SECRET, uri: URI },
+    created_at: '2026-01-01T00:00:00.000Z',
+    updated_at: '2026-01-01T00:00:00.000Z',
+  },
+  authentication_challenge: { id: 'auth_challenge_1' },
+};
+
+describe('user factor commands', () => {
+  let consoleOutput: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    consoleOutput = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+    vi.restoreAllMocks();
+  });
+
+  describe('runUserFactorsList', () => {
+    it("lists the user's factors", async () => {
+      mockRequest.mockResolvedValue({ data: [enrolled.authentication_factor], list_metadata: {} });
+      await runUserFactorsList('user_123', {}, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'GET', path: '/user_management/users/user_123/auth_factors' }),
+      );
+      expect(consoleOutput.some((l) => l.includes('auth_factor_1'))).toBe(true);
+    });
+
+    it('looks up users by email', async () => {
+      mockRequest
+        .mockResolvedValueOnce({ data: [{ id: 'user_123', email: 'test@example.com' }], list_metadata: {} })
+        .mockResolvedValueOnce({ data: [], list_metadata: {} });
+      await runUserFactorsList('test@example.com', {}, 'sk_test');
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ path: '/user_management/users/user_123/auth_factors' }),
+      );
+      expect(consoleOutput).toContain('No factors enrolled for user_123.');
+    });
+  });
+
+  describe('runUserFactorEnroll', () => {
+    it('enrolls a TOTP factor with the issuer and label', async () => {
+      mockRequest.mockResolvedValue(enrolled);
+      await runUserFactorEnroll('user_123', { type: 'totp', issuer: 'Acme', label: 'qa', qr: false }, 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/user_management/users/user_123/auth_factors',
+          body: { type: 'totp', totp_issuer: 'Acme', totp_user: 'qa' },
+        }),
+      );
+      expect(consoleOutput).toContain(`URI: ${URI}`);
+      expect(consoleOutput).toContain(`Secret: ${SECRET}`);
+    });
+
+    it('renders the QR code unless --no-qr', async () => {
+      mockRequest.mockResolvedValue(enrolled);
+      await runUserFactorEnroll('user_123', { type: 'totp' }, 'sk_test');
+      expect(consoleOutput.some((l) => l.includes('█▀▀▀▀▀█'))).toBe(true);
+    });
+
+    it('prints the current code with --generate-code', async () => {
+      vi.useFakeTimers({ now: 59_000 });
+      mockRequest.mockResolvedValue(enrolled);
+      await runUserFactorEnroll('user_123', { type: 'totp', generateCode: true, json: true }, 'sk_test');
+      expect(JSON.parse(consoleOutput.join('\n'))).toMatchObject({ code: '287082' });
+    });
+  });
+
+  describe('runUserFactorDelete', () => {
+    it('deletes the factor', async () => {
+      mockRequest.mockResolvedValue(null);
+      await runUserFactorDelete('auth_factor_1', 'sk_test');
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({ method: 'DELETE', path: '/auth/factors/auth_factor_1' }),
+      );
+      expect(consoleOutput.some((l) => l.includes('Deleted factor auth_factor_1'))).toBe(true);
+    });
+  });
+
+  describe('runUserFactorCode', () => {
+    it('prints only the code', () => {
+      vi.useFakeTimers({ now: 59_000 });
+      runUserFactorCode(SECRET);
+      expect(consoleOutput).toEqual(['287082']);
+    });
+  });
+});
@@ -0,0 +1,155 @@
+import chalk from 'chalk';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import { workosRequest } from '../lib/workos-api.js';
+import type { WorkOSListResponse } from '../lib/workos-api.js';
+import { resolveUserId } from '../lib/impersonation.js';
+import { encodeQr, renderQr } from '../lib/qr-code.js';
+import { totpCode, totpSecondsRemaining } from '../lib/totp.js';
+import { formatTable } from '../utils/table.js';
+
+interface AuthFactor {
+  id: string;
+  type: string;
+  user_id?: string;
+  totp?: { issuer?: string; user?: string; secret?: string; uri?: string; qr_code?: string };
+  created_at: string;
+  updated_at: string;
+}
+
+interface EnrollResponse {
+  authentication_factor: AuthFactor;
+  authentication_challenge: { id: string; expires_at?: string };
+}
+
+/** Factor types the enrollment API accepts for users */
+export const FACTOR_TYPES = ['totp'] as const;
+export type FactorType = (typeof FACTOR_TYPES)[number];
+
+const API_ERRORS: ApiErrorMessages = { notFound: 'User or factor not found.' };
+
+function printCode(secret: string): void {
+  const now = Date.now();
+  const remaining = chalk.dim(`(${totpSecondsRemaining(now)}s left)`);
+  console.log(`Current code: ${chalk.bold(totpCode(secret, now))} ${remaining}`);
+}
+
+export async function runUserFactorsList(
+  user: string,
+  options: { json?: boolean },
+  apiKey: string,
+  baseUrl?: string,
+): Promise<void> {
+  try {
+    const userId = await resolveUserId(user, { apiKey, baseUrl });
+    const result = await workosRequest<WorkOSListResponse<AuthFactor>>({
+      method: 'GET',
+      path: `/user_management/users/${userId}/auth_factors`,
+      apiKey,
+      baseUrl,
+    });
+
+    if (options.json) {
+      console.log(JSON.stringify(result, null, 2));
+      return;
+    }
+    if (result.data.length === 0) {
+      console.log(`No factors enrolled for ${userId}.`);
+      return;
+    }
+    console.log(
+      formatTable(
+        [{ header: 'ID' }, { header: 'Type' }, { header: 'Issuer' }, { header: 'Created' }],
+        result.data.map((factor) => [
+          factor.id,
+          factor.type,
+          factor.totp?.issuer || chalk.dim('-'),
+          factor.created_at,
+        ]),
+      ),
+    );
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+}
+
+export interface FactorEnrollOptions {
+  type: FactorType;
+  issuer?: string;
+  /** Label shown in the authenticator app; the API defaults to the user's email */
+  label?: string;
+  /** Print the current TOTP code, for completing the challenge in tests */
+  generateCode?: boolean;
+  /** Skip the terminal QR code */
+  qr?: boolean;
+  json?: boolean;
+}
+
+export async function runUserFactorEnroll(
+  user: string,
+  options: FactorEnrollOptions,
+  apiKey: string,
+  baseUrl?: string,
+): Promise<void> {
+  try {
+    const userId = await resolveUserId(user, { apiKey, baseUrl });
+    const result = await workosRequest<EnrollResponse>({
+      method: 'POST',
+      path: `/user_management/users/${userId}/auth_factors`,
+      apiKey,
+      baseUrl,
+      body: {
+        type: options.type,
+        ...(options.issuer && { totp_issuer: options.issuer }),
+        ...(options.label && { totp_user: options.label }),
+      },
+    });
+    const factor = result.authentication_factor;
+    const secret = factor.totp?.secret;
+
+    if (options.json) {
+      const code = options.generateCode && secret ? { code: totpCode(secret) } : {};
+      console.log(JSON.stringify({ ...result, ...code }, null, 2));
+      return;
+    }
+
+    console.log(chalk.green(`Enrolled ${factor.type} factor ${factor.id} for ${userId}`));
+    console.log(`Challenge: ${result.authentication_challenge.id}`);
+    if (factor.totp?.uri) {
+      console.log(`URI: ${factor.totp.uri}`);
+      if (options.qr !== false) {
+        console.log('');
+        console.log(renderQr(encodeQr(factor.totp.uri), (line) => chalk.bgWhite.black(line)));
+        console.log('');
+      }
+    }
+    if (secret) {
+      console.log(`Secret: ${secret}`);
+      if (options.generateCode) printCode(secret);
+    }
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+}
+
+export async function runUserFactorDelete(factorId: string, apiKey: string, baseUrl?: string): Promise<void> {
+  try {
+    await workosRequest({
+      method: 'DELETE',
+      path: `/auth/factors/${factorId}`,
+      apiKey,
+      baseUrl,
+    });
+    console.log(chalk.green(`Deleted factor ${factorId}`));
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+}
+
+/** `workos user factors code <secret>`: just the code, so scripts can capture it */
+export function runUserFactorCode(secret: string): void {
+  try {
+    console.log(totpCode(secret));
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+}
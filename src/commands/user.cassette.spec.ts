@@ -0,0 +1,82 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { fileURLToPath } from 'node:url';
+import { _setApiCacheDir } from '../lib/api-cache.js';
+import { _resetCassette, REPLAY_ENV } from '../lib/cassette.js';
+import { runUserDelete, runUserGet, runUserList, runUserUpdate } from './user.js';
+
+const CASSETTE = fileURLToPath(new URL('./user.cassette.json', import.meta.url));
+
+describe('user commands against a cassette', () => {
+  const originalFetch = globalThis.fetch;
+  let cacheDir: string;
+  let logged: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    _resetCassette();
+    process.env[REPLAY_ENV] = CASSETTE;
+    // Anything that reaches the network fails the test
+    globalThis.fetch = vi.fn().mockRejectedValue(new Error('network request during replay'));
+    cacheDir = mkdtempSync(join(tmpdir(), 'user-cassette-'));
+    _setApiCacheDir(cacheDir);
+    logged = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => void logged.push(args.map(String).join(' ')));
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => void errors.push(args.map(String).join(' ')));
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    globalThis.fetch = originalFetch;
+    delete process.env[REPLAY_ENV];
+    rmSync(cacheDir, { recursive: true, force: true });
+  });
+
+  it('lists a page of users with its cursor', async () => {
+    await runUserList({ limit: 2 }, 'sk_test_cassette0001');
+
+    expect(logged.join('\n')).toContain('ada@example.com');
+    expect(logged.join('\n')).toContain('After: user_01HTQ4D9K3M7');
+  });
+
+  it('gets a user', async () => {
+    await runUserGet('user_01HTQ4C5B8X2', 'sk_test_cassette0001');
+
+    expect(JSON.parse(logged[0]).last_name).toBe('Lovelace');
+  });
+
+  it('reports a user that does not exist', async () => {
+    await expect(runUserGet('user_missing', 'sk_test_cassette0001')).rejects.toThrow('process.exit');
+    expect(errors.join('\n')).toContain('User not found.');
+  });
+
+  it('matches an update on its scrubbed body', async () => {
+    await runUserUpdate('user_01HTQ4C5B8X2', 'sk_test_cassette0001', {
+      firstName: 'Augusta',
+      password: 'correct-horse-battery',
+    });
+
+    expect(logged[0]).toContain('Updated user');
+    expect(JSON.parse(logged[1]).first_name).toBe('Augusta');
+  });
+
+  it('deletes a user', async () => {
+    await runUserDelete('user_01HTQ4D9K3M7', 'sk_test_cassette0001');
+
+    expect(logged[0]).toContain('Deleted user user_01HTQ4D9K3M7');
+  });
+
+  it('fails on a request the cassette did not record', async () => {
+    await expect(runUserGet('user_unknown', 'sk_test_cassette0001')).rejects.toThrow('process.exit');
+    expect(errors.join('\n')).toContain(
+      'No recorded response for GET https://api.workos.com/user_management/users/user_unknown',
+    );
+    expect(globalThis.fetch).not.toHaveBeenCalled();
+  });
+});
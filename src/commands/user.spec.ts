@@ -15,6 +15,13 @@ vi.mock('../lib/workos-api.js', () => ({
   },
 }));
 
+const mockInvalidate = vi.fn();
+vi.mock('../lib/api-cache.js', () => ({
+  invalidateApiCache: (resource: string) => mockInvalidate(resource),
+  cacheScope: () => ({ profile: 'default', environment: 'test' }),
+  cachedLookup: (_resource: string, _scope: unknown, fetcher: () => Promise<unknown>) => fetcher(),
+}));
+
 const { workosRequest } = await import('../lib/workos-api.js');
 const mockRequest = vi.mocked(workosRequest);
 
@@ -74,6 +81,17 @@ describe('user commands', () => {
       expect(consoleOutput.some((l) => l.includes('No users found'))).toBe(true);
     });
 
+    it('caps --all at --limit across pages', async () => {
+      const users = (ids: string[]) =>
+        ids.map((id) => ({ id, email: `${id}@example.com`, first_name: '', last_name: '', email_verified: true }));
+      mockRequest
+        .mockResolvedValueOnce({ data: users(['user_1', 'user_2']), list_metadata: { before: null, after: 'user_2' } })
+        .mockResolvedValueOnce({ data: users(['user_3']), list_metadata: { before: null, after: 'user_3' } });
+      await runUserList({ all: true, json: true, limit: 3 }, 'sk_test');
+      expect(consoleOutput.map((line) => JSON.parse(line).id)).toEqual(['user_1', 'user_2', 'user_3']);
+      expect(mockRequest).toHaveBeenCalledTimes(2);
+    });
+
     it('shows pagination cursors when present', async () => {
       mockRequest.mockResolvedValue({
         data: [{ id: 'user_1', email: 'a@b.com', first_name: '', last_name: '', email_verified: false }],
@@ -113,5 +131,11 @@ describe('user commands', () => {
       );
       expect(consoleOutput.some((l) => l.includes('Deleted') && l.includes('user_123'))).toBe(true);
     });
+
+    it('drops cached user lookups', async () => {
+      mockRequest.mockResolvedValue(null);
+      await runUserDelete('user_123', 'sk_test');
+      expect(mockInvalidate).toHaveBeenCalledWith('users');
+    });
   });
 });
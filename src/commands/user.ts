@@ -1,7 +1,15 @@
 import chalk from 'chalk';
-import { workosRequest, WorkOSApiError } from '../lib/workos-api.js';
+import { handleApiError, type ApiErrorMessages } from '../lib/api-error.js';
+import { workosRequest } from '../lib/workos-api.js';
 import type { WorkOSListResponse } from '../lib/workos-api.js';
-import { formatTable } from '../utils/table.js';
+import { paginate } from '../lib/pagination.js';
+import { userExportSource } from '../lib/directory-export.js';
+import { invalidateApiCache } from '../lib/api-cache.js';
+import { moreUsers, toPickerItem, userChoices, type LookupChoice } from '../lib/lookups.js';
+import clack from '../utils/clack.js';
+import { pick } from '../utils/fuzzy-picker.js';
+import { createTableStream, formatTable } from '../utils/table.js';
+import { runDirectoryExport, type DirectoryExportOptions } from './directory-export.js';
 
 interface User {
   id: string;
@@ -13,24 +21,35 @@ interface User {
   updated_at: string;
 }
 
-function handleApiError(error: unknown): never {
-  if (error instanceof WorkOSApiError) {
-    if (error.statusCode === 401) {
-      console.error(chalk.red('Invalid API key. Check your environment configuration.'));
-    } else if (error.statusCode === 404) {
-      console.error(chalk.red('User not found.'));
-    } else if (error.statusCode === 422 && error.errors?.length) {
-      console.error(chalk.red(error.errors.map((e) => e.message).join(', ')));
-    } else {
-      console.error(chalk.red(error.message));
-    }
-  } else {
-    console.error(chalk.red(error instanceof Error ? error.message : 'Unknown error'));
+const API_ERRORS: ApiErrorMessages = { notFound: 'User not found.' };
+
+/**
+ * Pick a user, filtering on email, name or ID, with pages loading as the
+ * picker scrolls. Exits when there's nothing to pick or the user cancels.
+ */
+export async function pickUser(apiKey: string, baseUrl: string): Promise<string> {
+  let choices: LookupChoice[];
+  try {
+    choices = await userChoices(apiKey, baseUrl);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
+  }
+  if (choices.length === 0) {
+    console.error(chalk.red('No users in this environment.'));
+    process.exit(1);
   }
-  process.exit(1);
+  const selected = await pick({
+    message: 'Select a user',
+    items: choices.map(toPickerItem),
+    loadMore: moreUsers(apiKey, baseUrl, choices),
+    flag: 'workos user get <userId>',
+  });
+  if (clack.isCancel(selected)) process.exit(0);
+  return selected as string;
 }
 
-export async function runUserGet(userId: string, apiKey: string, baseUrl?: string): Promise<void> {
+export async function runUserGet(userId: string | undefined, apiKey: string, baseUrl?: string): Promise<void> {
+  userId ??= await pickUser(apiKey, baseUrl ?? 'https://api.workos.com');
   try {
     const user = await workosRequest<User>({
       method: 'GET',
@@ -40,7 +59,7 @@ export async function runUserGet(userId: string, apiKey: string, baseUrl?: strin
     });
     console.log(JSON.stringify(user, null, 2));
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
   }
 }
 
@@ -51,50 +70,77 @@ export interface UserListOptions {
   before?: string;
   after?: string;
   order?: string;
+  /** Follow cursors through every page; `limit` caps the total */
+  all?: boolean;
+  json?: boolean;
+}
+
+const USER_COLUMNS = [
+  { header: 'ID' },
+  { header: 'Email' },
+  { header: 'First Name' },
+  { header: 'Last Name' },
+  { header: 'Verified' },
+];
+
+function userRow(user: User): string[] {
+  return [
+    user.id,
+    user.email,
+    user.first_name || chalk.dim('-'),
+    user.last_name || chalk.dim('-'),
+    user.email_verified ? 'Yes' : 'No',
+  ];
 }
 
 export async function runUserList(options: UserListOptions, apiKey: string, baseUrl?: string): Promise<void> {
+  const params = {
+    email: options.email,
+    organization_id: options.organization,
+    limit: options.limit,
+    before: options.before,
+    after: options.after,
+    order: options.order,
+  };
+
+  if (options.all) {
+    // Stream pages as they arrive; JSON lines so output pipes into jq
+    const printRows = createTableStream(USER_COLUMNS);
+    let count = 0;
+    try {
+      const pages = paginate<User>({ path: '/user_management/users', apiKey, baseUrl, params }, { max: options.limit });
+      for await (const page of pages) {
+        if (options.json) page.forEach((user) => console.log(JSON.stringify(user)));
+        else printRows(page.map(userRow));
+        count += page.length;
+      }
+    } catch (error) {
+      handleApiError(error, API_ERRORS);
+    }
+    if (count === 0 && !options.json) console.log('No users found.');
+    return;
+  }
+
   try {
     const result = await workosRequest<WorkOSListResponse<User>>({
       method: 'GET',
       path: '/user_management/users',
       apiKey,
       baseUrl,
-      params: {
-        email: options.email,
-        organization_id: options.organization,
-        limit: options.limit,
-        before: options.before,
-        after: options.after,
-        order: options.order,
-      },
+      params,
     });
 
+    if (options.json) {
+      console.log(JSON.stringify(result, null, 2));
+      return;
+    }
+
     if (result.data.length === 0) {
       console.log('No users found.');
       return;
     }
 
-    const rows = result.data.map((user) => [
-      user.id,
-      user.email,
-      user.first_name || chalk.dim('-'),
-      user.last_name || chalk.dim('-'),
-      user.email_verified ? 'Yes' : 'No',
-    ]);
-
-    console.log(
-      formatTable(
-        [
-          { header: 'ID' },
-          { header: 'Email' },
-          { header: 'First Name' },
-          { header: 'Last Name' },
-          { header: 'Verified' },
-        ],
-        rows,
-      ),
-    );
+    console.log(formatTable(USER_COLUMNS, result.data.map(userRow)));
 
     const { before, after } = result.list_metadata;
     if (before && after) {
@@ -105,7 +151,7 @@ export async function runUserList(options: UserListOptions, apiKey: string, base
       console.log(chalk.dim(`After: ${after}`));
     }
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
   }
 }
 
@@ -138,10 +184,11 @@ export async function runUserUpdate(
       baseUrl,
       body,
     });
+    invalidateApiCache('users');
     console.log(chalk.green('Updated user'));
     console.log(JSON.stringify(user, null, 2));
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
   }
 }
 
@@ -153,8 +200,22 @@ export async function runUserDelete(userId: string, apiKey: string, baseUrl?: st
       apiKey,
       baseUrl,
     });
+    invalidateApiCache('users');
     console.log(chalk.green(`Deleted user ${userId}`));
   } catch (error) {
-    handleApiError(error);
+    handleApiError(error, API_ERRORS);
+  }
+}
+
+export interface UserExportOptions extends DirectoryExportOptions {
+  /** Look up each user's organization memberships (one request per user) */
+  memberships?: boolean;
+}
+
+export async function runUserExport(options: UserExportOptions, apiKey: string, baseUrl?: string): Promise<void> {
+  try {
+    await runDirectoryExport(userExportSource({ apiKey, baseUrl }, { memberships: options.memberships }), options);
+  } catch (error) {
+    handleApiError(error, API_ERRORS);
   }
 }
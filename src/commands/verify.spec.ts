@@ -0,0 +1,82 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { makeFinding } from '../migrate/findings.test-utils.js';
+import { ANY_PROVIDER, type DetectionResult, type MigrationFinding } from '../migrate/types.js';
+import { formatVerifyResult, lingeringReferences, runVerify } from './verify.js';
+
+const finding = (overrides: Partial<MigrationFinding>): MigrationFinding =>
+  makeFinding({ provider: 'supabase', severity: 'warning', message: 'Supabase auth call', line: 3, ...overrides });
+
+describe('verify', () => {
+  it('counts only auth findings', () => {
+    const result: DetectionResult = {
+      root: '/repo',
+      matches: [
+        {
+          provider: 'supabase',
+          confidence: 0.9,
+          findings: [
+            finding({ code: 'auth' }),
+            finding({ code: 'query', outOfScope: true }),
+            finding({ code: 'tenancy', provider: ANY_PROVIDER }),
+          ],
+        },
+        { provider: 'okta', confidence: 0.5, findings: [finding({ provider: 'okta', outOfScope: true })] },
+      ],
+    };
+
+    const lingering = lingeringReferences(result);
+
+    expect(lingering.map((m) => m.provider)).toEqual(['supabase']);
+    expect(lingering[0].findings.map((f) => f.code)).toEqual(['auth']);
+  });
+
+  it('prints each reference with its location', () => {
+    const output = formatVerifyResult(
+      [{ provider: 'supabase', confidence: 0.9, findings: [finding({}), finding({ file: 'src/db.ts', line: 9 })] }],
+      0.35,
+    );
+
+    expect(output).toContain('src/auth.ts:3');
+    expect(output).toContain('src/db.ts:9');
+    expect(output).toContain('2 references to supabase left');
+    expect(formatVerifyResult([], 0.35)).toContain('No auth provider references left (threshold 35%)');
+  });
+
+  describe('runVerify', () => {
+    let root: string;
+    let logged: string[];
+
+    beforeEach(() => {
+      root = mkdtempSync(join(tmpdir(), 'verify-'));
+      logged = [];
+      vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => void logged.push(args.map(String).join(' ')));
+      vi.spyOn(process, 'exit').mockImplementation(((code: number) => {
+        throw new Error(`process.exit(${code})`);
+      }) as never);
+    });
+
+    afterEach(() => {
+      vi.restoreAllMocks();
+      rmSync(root, { recursive: true, force: true });
+    });
+
+    it('passes a project with no other provider', async () => {
+      const manifest = { dependencies: { '@workos-inc/authkit-nextjs': '^2' } };
+      writeFileSync(join(root, 'package.json'), JSON.stringify(manifest));
+
+      await runVerify({ installDir: root, json: true, cache: false });
+
+      expect(JSON.parse(logged[0])).toMatchObject({ passed: true, providers: [] });
+    });
+
+    it('exits 1 while old-provider references remain', async () => {
+      writeFileSync(join(root, 'package.json'), JSON.stringify({ dependencies: { '@clerk/nextjs': '^5' } }));
+
+      await expect(runVerify({ installDir: root, cache: false })).rejects.toThrow('process.exit(1)');
+      expect(logged.join('\n')).toContain('clerk');
+    });
+  });
+});
@@ -0,0 +1,96 @@
+import chalk from 'chalk';
+import { resolve } from 'node:path';
+import { applyConfidenceThreshold, DEFAULT_MIN_CONFIDENCE, detectProviders } from '../migrate/detect.js';
+import { createOidcDiscovery } from '../migrate/oidc-discovery.js';
+import { ANY_PROVIDER, type DetectionResult, type ProviderMatch } from '../migrate/types.js';
+import { redactSecrets } from '../utils/redact.js';
+import { formatTable } from '../utils/table.js';
+
+/** Old-provider references remain */
+export const EXIT_CODE_REFERENCES = 1;
+/** The scan itself failed, so nothing was verified */
+export const EXIT_CODE_SCAN_FAILED = 2;
+
+export interface VerifyOptions {
+  installDir: string;
+  json?: boolean;
+  /** Providers detected with less confidence than this (0-1) don't fail the check */
+  minConfidence?: number;
+  include?: string[];
+  exclude?: string[];
+  cache?: boolean;
+  maxFileSize?: number;
+  scanTerraform?: boolean;
+}
+
+/**
+ * What fails the check: the auth findings of providers still detected above
+ * the threshold. Usage unrelated to auth (e.g. Supabase database queries)
+ * and findings that hold for any provider (tenancy) don't count.
+ */
+export function lingeringReferences(result: DetectionResult): ProviderMatch[] {
+  return result.matches
+    .map((match) => ({
+      ...match,
+      findings: match.findings.filter((f) => !f.outOfScope && f.provider !== ANY_PROVIDER),
+    }))
+    .filter((match) => match.findings.length > 0);
+}
+
+function formatPercent(value: number): string {
+  return `${Math.round(value * 100)}%`;
+}
+
+export function formatVerifyResult(lingering: ProviderMatch[], minConfidence: number): string {
+  if (lingering.length === 0) {
+    return chalk.green(`✔ No auth provider references left (threshold ${formatPercent(minConfidence)})`);
+  }
+  const sections = lingering.map((match) => {
+    const score = chalk.dim(`(confidence ${formatPercent(match.confidence)})`);
+    const title = `${chalk.red('✖')} ${chalk.bold(match.provider)} ${score}`;
+    const table = formatTable(
+      [{ header: 'Location' }, { header: 'Finding' }],
+      match.findings.map((f) => [f.line ? `${f.file}:${f.line}` : f.file, f.message]),
+    );
+    return `${title}\n${table}`;
+  });
+  const count = lingering.reduce((sum, match) => sum + match.findings.length, 0);
+  sections.push(
+    chalk.red(
+      `${count} reference${count === 1 ? '' : 's'} to ${lingering.map((m) => m.provider).join(', ')} left. ` +
+        'Finish the migration, or exclude the paths with .workosignore or --exclude.',
+    ),
+  );
+  return redactSecrets(sections.join('\n\n'));
+}
+
+/**
+ * `workos verify`: detection as a CI gate for a migrated repo. Exits 0 when
+ * no other auth provider is detected above the threshold, 1 when references
+ * remain, and 2 when the scan couldn't run.
+ */
+export async function runVerify(options: VerifyOptions): Promise<void> {
+  const minConfidence = options.minConfidence ?? DEFAULT_MIN_CONFIDENCE;
+  let detected: DetectionResult;
+  try {
+    detected = await detectProviders(resolve(options.installDir), undefined, {
+      include: options.include,
+      exclude: options.exclude,
+      discovery: createOidcDiscovery({ cache: options.cache }),
+      maxFileSize: options.maxFileSize,
+      terraform: options.scanTerraform,
+    });
+  } catch (error) {
+    console.error(chalk.red(error instanceof Error ? error.message : String(error)));
+    process.exit(EXIT_CODE_SCAN_FAILED);
+  }
+  const lingering = lingeringReferences(applyConfidenceThreshold(detected, minConfidence));
+
+  if (options.json) {
+    const output = { passed: lingering.length === 0, minConfidence, providers: lingering };
+    console.log(redactSecrets(JSON.stringify(output, null, 2)));
+  } else {
+    console.log(formatVerifyResult(lingering, minConfidence));
+  }
+  if (lingering.length > 0) process.exit(EXIT_CODE_REFERENCES);
+}
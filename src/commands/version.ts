@@ -0,0 +1,6 @@
+import { formatVersion, getBuildInfo } from '../lib/build-info.js';
+
+export function runVersion(options: { json?: boolean } = {}): void {
+  const info = getBuildInfo();
+  console.log(options.json ? JSON.stringify(info, null, 2) : formatVersion(info));
+}
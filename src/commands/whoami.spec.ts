@@ -0,0 +1,116 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+const mockGetCredentials = vi.fn();
+vi.mock('../lib/credentials.js', () => ({
+  getCredentials: () => mockGetCredentials(),
+}));
+
+const mockEnsureValidToken = vi.fn();
+vi.mock('../lib/token-refresh.js', () => ({
+  ensureValidToken: () => mockEnsureValidToken(),
+}));
+
+const mockGetActiveEnvironment = vi.fn();
+vi.mock('../lib/config-store.js', () => ({
+  getActiveEnvironment: () => mockGetActiveEnvironment(),
+}));
+
+const { getWhoami } = await import('./whoami.js');
+
+function jwt(claims: Record<string, unknown>): string {
+  return ['header', Buffer.from(JSON.stringify(claims)).toString('base64url'), 'signature'].join('.');
+}
+
+const creds = {
+  accessToken: jwt({ sub: 'user_123', org_id: 'org_team' }),
+  refreshToken: 'refresh',
+  expiresAt: Date.UTC(2030, 0, 1),
+  userId: 'user_123',
+  email: 'dev@example.com',
+};
+
+describe('getWhoami', () => {
+  const originalApiKey = process.env.WORKOS_API_KEY;
+  const originalFetch = globalThis.fetch;
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    delete process.env.WORKOS_API_KEY;
+    delete process.env.WORKOS_CLI_TOKEN;
+    mockGetCredentials.mockReturnValue(creds);
+    mockEnsureValidToken.mockResolvedValue({ success: true, credentials: creds });
+    mockGetActiveEnvironment.mockReturnValue({ name: 'staging', type: 'sandbox', apiKey: 'sk_test_1' });
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+    delete process.env.WORKOS_CLI_TOKEN;
+    if (originalApiKey === undefined) delete process.env.WORKOS_API_KEY;
+    else process.env.WORKOS_API_KEY = originalApiKey;
+  });
+
+  it('reports the user, team, profile and environment mode', async () => {
+    expect(await getWhoami()).toEqual({
+      loggedIn: true,
+      email: 'dev@example.com',
+      userId: 'user_123',
+      teamId: 'org_team',
+      sessionError: null,
+      sessionExpiresAt: '2030-01-01T00:00:00.000Z',
+      profile: 'staging',
+      environment: { type: 'sandbox', endpoint: 'https://api.workos.com', apiKeySource: 'profile' },
+    });
+  });
+
+  it('reports WORKOS_API_KEY overriding the profile', async () => {
+    process.env.WORKOS_API_KEY = 'sk_live_override';
+
+    const result = await getWhoami();
+
+    expect(result.environment).toEqual({
+      type: 'production',
+      endpoint: 'https://api.workos.com',
+      apiKeySource: 'WORKOS_API_KEY',
+    });
+    expect(result.profile).toBe('staging');
+  });
+
+  it('reports an expired session without failing', async () => {
+    mockEnsureValidToken.mockResolvedValue({ success: false, error: 'Session expired' });
+
+    const result = await getWhoami();
+
+    expect(result.loggedIn).toBe(false);
+    expect(result.sessionError).toBe('Session expired');
+    expect(result.email).toBe('dev@example.com');
+  });
+
+  it('reports nothing when logged out with no profile', async () => {
+    mockGetCredentials.mockReturnValue(null);
+    mockGetActiveEnvironment.mockReturnValue(null);
+
+    const result = await getWhoami();
+
+    expect(mockEnsureValidToken).not.toHaveBeenCalled();
+    expect(result).toMatchObject({ loggedIn: false, email: null, teamId: null, profile: null, environment: null });
+  });
+
+  it('reports the CI token and its scopes without reading the session or profile', async () => {
+    process.env.WORKOS_CLI_TOKEN = 'wos_ci_abc';
+    const body = { id: 'ci_1', name: 'github-actions', scopes: ['env:read'], environment_type: 'sandbox' };
+    globalThis.fetch = vi.fn().mockResolvedValue({
+      ok: true,
+      status: 200,
+      json: () => Promise.resolve(body),
+      text: () => Promise.resolve(JSON.stringify(body)),
+    });
+
+    const result = await getWhoami();
+
+    expect(result.ciToken).toEqual({ id: 'ci_1', name: 'github-actions', scopes: ['env:read'] });
+    expect(result.environment).toMatchObject({ type: 'sandbox', apiKeySource: 'WORKOS_CLI_TOKEN' });
+    expect(result.loggedIn).toBe(false);
+    expect(mockGetCredentials).not.toHaveBeenCalled();
+    expect(mockGetActiveEnvironment).not.toHaveBeenCalled();
+  });
+});
@@ -0,0 +1,141 @@
+import chalk from 'chalk';
+import { CI_TOKEN_ENV, describeCiToken, getCiToken } from '../lib/ci-tokens.js';
+import { getActiveEnvironment } from '../lib/config-store.js';
+import { decodeJwtClaims } from '../lib/session-seal.js';
+import { ensureValidToken } from '../lib/token-refresh.js';
+import { getCredentials } from '../lib/credentials.js';
+import { getWorkOSApiUrl } from '../utils/urls.js';
+
+export interface WhoamiOptions {
+  json?: boolean;
+}
+
+export interface WhoamiResult {
+  loggedIn: boolean;
+  email: string | null;
+  userId: string | null;
+  /** WorkOS team (organization) the CLI session belongs to, from the access token */
+  teamId: string | null;
+  /** Why the session can't be used, e.g. the refresh token was revoked */
+  sessionError: string | null;
+  sessionExpiresAt: string | null;
+  /** Active profile from `workos env` */
+  profile: string | null;
+  environment: {
+    type: 'production' | 'sandbox';
+    endpoint: string;
+    /** Where management commands get their API key */
+    apiKeySource: 'WORKOS_API_KEY' | 'WORKOS_CLI_TOKEN' | 'profile';
+  } | null;
+  /** Set when running under WORKOS_CLI_TOKEN: the token's name and scopes, or why they couldn't be read */
+  ciToken?: { id: string | null; name: string | null; scopes: string[]; error?: string };
+}
+
+const DEFAULT_ENDPOINT = 'https://api.workos.com';
+
+/** Under a CI token there's no session or profile; the token describes itself */
+async function getCiTokenWhoami(token: string): Promise<WhoamiResult> {
+  const endpoint = getWorkOSApiUrl();
+  let ciToken: NonNullable<WhoamiResult['ciToken']>;
+  let type: 'production' | 'sandbox' = 'production';
+  try {
+    const described = await describeCiToken({ apiKey: token, baseUrl: endpoint });
+    ciToken = { id: described.id, name: described.name, scopes: described.scopes };
+    if (described.environment_type === 'sandbox') type = 'sandbox';
+  } catch (error) {
+    ciToken = { id: null, name: null, scopes: [], error: error instanceof Error ? error.message : String(error) };
+  }
+  return {
+    loggedIn: false,
+    email: null,
+    userId: null,
+    teamId: null,
+    sessionError: null,
+    sessionExpiresAt: null,
+    profile: null,
+    environment: { type, endpoint, apiKeySource: 'WORKOS_CLI_TOKEN' },
+    ciToken,
+  };
+}
+
+/** Gather what the CLI is acting as without calling any mutating API */
+export async function getWhoami(): Promise<WhoamiResult> {
+  const ciToken = getCiToken();
+  // WORKOS_API_KEY wins over the token, as in resolveApiKey
+  if (ciToken && !process.env.WORKOS_API_KEY) return getCiTokenWhoami(ciToken);
+
+  const stored = getCredentials();
+  const token = stored ? await ensureValidToken() : null;
+  const creds = token?.credentials ?? stored;
+  const claims = decodeJwtClaims(creds?.accessToken);
+
+  const active = getActiveEnvironment();
+  const envKey = process.env.WORKOS_API_KEY;
+  let environment: WhoamiResult['environment'] = null;
+  if (envKey) {
+    environment = {
+      type: envKey.startsWith('sk_test_') ? 'sandbox' : 'production',
+      endpoint: active?.endpoint || DEFAULT_ENDPOINT,
+      apiKeySource: 'WORKOS_API_KEY',
+    };
+  } else if (active) {
+    environment = { type: active.type, endpoint: active.endpoint || DEFAULT_ENDPOINT, apiKeySource: 'profile' };
+  }
+
+  return {
+    loggedIn: !!token?.success,
+    email: creds?.email ?? null,
+    userId: creds?.userId ?? null,
+    teamId: typeof claims?.org_id === 'string' ? claims.org_id : null,
+    sessionError: token && !token.success ? (token.error ?? 'Not authenticated') : null,
+    sessionExpiresAt: creds ? new Date(creds.expiresAt).toISOString() : null,
+    profile: active?.name ?? null,
+    environment,
+  };
+}
+
+export async function runWhoami(options: WhoamiOptions = {}): Promise<void> {
+  const result = await getWhoami();
+
+  if (options.json) {
+    console.log(JSON.stringify(result, null, 2));
+    if ((!result.loggedIn && !result.environment) || result.ciToken?.error) process.exit(1);
+    return;
+  }
+
+  const row = (label: string, value: string) => console.log(`${chalk.dim(label.padEnd(13))}${value}`);
+  const none = chalk.dim('none');
+
+  if (result.ciToken) {
+    const { name, scopes, error } = result.ciToken;
+    const source = `from ${CI_TOKEN_ENV}`;
+    const label = error ? `${chalk.yellow(source)} ${chalk.dim(`(${error})`)}` : `${name} ${chalk.dim(`(${source})`)}`;
+    row('CI token', label);
+    if (!error) row('Scopes', scopes.join(', '));
+    const mode = result.environment!.type === 'production' ? chalk.red('production') : chalk.green('sandbox');
+    row('Environment', `${mode} ${chalk.dim(result.environment!.endpoint)}`);
+    if (error) process.exit(1);
+    return;
+  }
+
+  if (result.loggedIn) {
+    row('User', `${result.email ?? result.userId} ${chalk.dim(`(${result.userId})`)}`);
+  } else if (result.sessionError) {
+    row('User', `${result.email ?? result.userId} ${chalk.yellow(`(${result.sessionError})`)}`);
+  } else {
+    row('User', `${chalk.yellow('not logged in')} ${chalk.dim('(run `workos login`)')}`);
+  }
+  row('Team', result.teamId ?? none);
+
+  if (result.environment) {
+    const mode =
+      result.environment.type === 'production' ? chalk.red('production') : chalk.green(result.environment.type);
+    row('Environment', `${mode} ${chalk.dim(result.environment.endpoint)}`);
+  } else {
+    row('Environment', `${none} ${chalk.dim('(run `workos env add`)')}`);
+  }
+  const overridden = result.environment?.apiKeySource === 'WORKOS_API_KEY';
+  row('Profile', `${result.profile ?? none}${overridden ? chalk.dim(' (overridden by WORKOS_API_KEY)') : ''}`);
+
+  if (!result.loggedIn && !result.environment) process.exit(1);
+}
@@ -0,0 +1,115 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('../lib/workos-api.js', () => ({
+  workosRequest: vi.fn(),
+  WorkOSApiError: class WorkOSApiError extends Error {
+    constructor(
+      message: string,
+      public readonly statusCode: number,
+      public readonly code?: string,
+      public readonly errors?: Array<{ message: string }>,
+    ) {
+      super(message);
+      this.name = 'WorkOSApiError';
+    }
+  },
+}));
+
+const mockOpenBrowser = vi.fn();
+vi.mock('../utils/browser.js', () => ({
+  loopbackHost: () => '127.0.0.1',
+  openBrowser: (url: string) => mockOpenBrowser(url),
+}));
+
+const { workosRequest, WorkOSApiError } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { runWidgetsToken } = await import('./widgets.js');
+
+/** A widget token expiring at 2100-01-01 */
+const token = `eyJhbGciOiJSUzI1NiJ9.${Buffer.from(JSON.stringify({ exp: 4102444800 })).toString('base64url')}.sig`;
+
+describe('widgets commands', () => {
+  let consoleOutput: string[];
+  let errors: string[];
+
+  beforeEach(() => {
+    mockRequest.mockReset();
+    mockOpenBrowser.mockReset();
+    consoleOutput = [];
+    errors = [];
+    vi.spyOn(console, 'log').mockImplementation((...args: unknown[]) => {
+      consoleOutput.push(args.map(String).join(' '));
+    });
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    vi.spyOn(process, 'exit').mockImplementation((() => {
+      throw new Error('process.exit called');
+    }) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+  });
+
+  describe('runWidgetsToken', () => {
+    const options = { user: 'user_123', org: 'org_123', apiKey: 'sk_test' };
+
+    it('prints the token for the user and organization', async () => {
+      mockRequest.mockResolvedValue({ token });
+      await runWidgetsToken({ ...options, scopes: ['widgets:users-table:manage, widgets:sso:manage'] });
+      expect(mockRequest).toHaveBeenCalledWith(
+        expect.objectContaining({
+          method: 'POST',
+          path: '/widgets/token',
+          body: {
+            user_id: 'user_123',
+            organization_id: 'org_123',
+            scopes: ['widgets:users-table:manage', 'widgets:sso:manage'],
+          },
+        }),
+      );
+      expect(consoleOutput).toEqual([token]);
+    });
+
+    it('prints the token and its expiry as JSON with --json', async () => {
+      mockRequest.mockResolvedValue({ token });
+      await runWidgetsToken({ ...options, scopes: ['widgets:sso:manage'], json: true });
+      expect(JSON.parse(consoleOutput.join('\n'))).toEqual({
+        token,
+        expires_at: '2100-01-01T00:00:00.000Z',
+        scopes: ['widgets:sso:manage'],
+      });
+    });
+
+    it('needs at least one scope', async () => {
+      await expect(runWidgetsToken({ ...options, scopes: [] })).rejects.toThrow('process.exit');
+      expect(mockRequest).not.toHaveBeenCalled();
+      expect(errors.join('\n')).toContain('Pass at least one scope with --scopes.');
+    });
+
+    it('lists the valid scopes when the API rejects one', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Invalid scope', 422));
+      const invalid = { ...options, scopes: ['widgets:users:manage'], json: true };
+      await expect(runWidgetsToken(invalid)).rejects.toThrow('process.exit');
+      expect(JSON.parse(consoleOutput[0]).error.message).toContain('Unrecognized: widgets:users:manage.');
+    });
+
+    it('serves a preview page rendering the widget with --serve', async () => {
+      mockRequest.mockResolvedValue({ token });
+      const opened = new Promise<string>((resolve) => mockOpenBrowser.mockImplementation(resolve));
+
+      const running = runWidgetsToken({ ...options, scopes: ['widgets:sso:manage'], serve: true, port: 0 });
+      const url = await opened;
+      const page = await (await fetch(url)).text();
+      process.emit('SIGINT');
+      await running;
+
+      expect(page).toContain('AdminPortalSsoConnection');
+      expect(page).toContain(token);
+      expect(consoleOutput).toEqual([]);
+      expect(errors.join('\n')).toContain(`Widget preview at ${url}`);
+    });
+  });
+});
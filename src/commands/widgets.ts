@@ -0,0 +1,78 @@
+import chalk from 'chalk';
+import {
+  createWidgetToken,
+  describeWidgetTokenError,
+  serveWidgetPage,
+  widgetPageHtml,
+  type WidgetServer,
+  type WidgetToken,
+} from '../lib/widgets.js';
+import { openBrowser } from '../utils/browser.js';
+
+export interface WidgetsTokenOptions {
+  user: string;
+  org: string;
+  scopes: string[];
+  json?: boolean;
+  /** Serve a local page rendering the widget with the token */
+  serve?: boolean;
+  port?: number;
+  open?: boolean;
+  apiKey: string;
+  baseUrl?: string;
+}
+
+function fail(message: string, json?: boolean): never {
+  if (json) console.log(JSON.stringify({ error: { message } }));
+  else console.error(chalk.red(message));
+  process.exit(1);
+}
+
+/**
+ * Generate a widget token for a user in an organization, optionally
+ * serving a preview page that renders the widget with it.
+ */
+export async function runWidgetsToken(options: WidgetsTokenOptions): Promise<void> {
+  const scopes = options.scopes.flatMap((scope) => scope.split(',')).map((s) => s.trim()).filter(Boolean);
+  if (scopes.length === 0) fail('Pass at least one scope with --scopes.', options.json);
+
+  let widgetToken: WidgetToken;
+  try {
+    widgetToken = await createWidgetToken(
+      { userId: options.user, organizationId: options.org, scopes },
+      { apiKey: options.apiKey, baseUrl: options.baseUrl },
+    );
+  } catch (error) {
+    fail(describeWidgetTokenError(error, scopes), options.json);
+  }
+
+  const { token, expiresAt } = widgetToken;
+  if (options.json) {
+    console.log(JSON.stringify({ token, expires_at: expiresAt?.toISOString() ?? null, scopes }, null, 2));
+  } else if (!options.serve) {
+    console.log(token);
+    if (expiresAt) console.error(chalk.dim(`Expires ${expiresAt.toLocaleString()}`));
+  }
+  if (!options.serve) return;
+
+  const apiHostname = options.baseUrl ? new URL(options.baseUrl).hostname : undefined;
+  let server: WidgetServer;
+  try {
+    server = await serveWidgetPage(widgetPageHtml(token, scopes, apiHostname), options.port ?? 5174);
+  } catch (error) {
+    fail(`Could not start the preview server: ${error instanceof Error ? error.message : String(error)}`);
+  }
+
+  console.error(`Widget preview at ${chalk.cyan(server.url)}`);
+  console.error(chalk.dim(`Allow ${server.url} as a CORS origin in the WorkOS dashboard if the widget fails to load.`));
+  if (expiresAt) console.error(chalk.dim(`The token expires ${expiresAt.toLocaleString()}; restart to refresh it.`));
+  console.error(chalk.dim('Press Ctrl+C to stop.'));
+  if (options.open !== false) void openBrowser(server.url);
+
+  await new Promise<void>((resolve) => {
+    process.once('SIGINT', () => {
+      server.close();
+      resolve();
+    });
+  });
+}
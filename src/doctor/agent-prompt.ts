@@ -1,6 +1,8 @@
 import { readFileSync, existsSync } from 'node:fs';
 import { join } from 'node:path';
 import type { LanguageInfo, FrameworkInfo, SdkInfo, EnvironmentInfo, Issue } from './types.js';
+import { isSecretKey } from '../utils/redact.js';
+import { proxiedFetch } from '../lib/proxy.js';
 
 export interface AnalysisContext {
   installDir: string;
@@ -41,7 +43,7 @@ async function fetchSdkReadme(sdkName: string | null): Promise<string | null> {
   try {
     const controller = new AbortController();
     const timeoutId = setTimeout(() => controller.abort(), README_TIMEOUT_MS);
-    const response = await fetch(url, { signal: controller.signal });
+    const response = await proxiedFetch(url, { signal: controller.signal });
     clearTimeout(timeoutId);
 
     if (!response.ok) return null;
@@ -65,7 +67,7 @@ function readEnvFileRedacted(path: string): string | null {
   const content = readFileSafe(path);
   if (!content) return null;
   return content.replace(/^([A-Z_]+)=(.+)$/gm, (_match, key: string, value: string) => {
-    if (key.includes('SECRET') || key.includes('PASSWORD') || key.includes('API_KEY')) {
+    if (isSecretKey(key)) {
       return `${key}=${value.slice(0, 3)}...(redacted)`;
     }
     return `${key}=${value}`;
@@ -1,7 +1,7 @@
 import Anthropic from '@anthropic-ai/sdk';
-import { getLlmGatewayUrl, getAuthkitDomain, getCliAuthClientId, getConfig } from '../../lib/settings.js';
-import { getCredentials, isTokenExpired, updateTokens, diagnoseCredentials } from '../../lib/credentials.js';
-import { refreshAccessToken } from '../../lib/token-refresh-client.js';
+import { getLlmGatewayUrl, getConfig } from '../../lib/settings.js';
+import { getCredentials, diagnoseCredentials } from '../../lib/credentials.js';
+import { ensureValidToken } from '../../lib/token-refresh.js';
 import { buildDoctorPrompt, type AnalysisContext } from '../agent-prompt.js';
 import type { AiAnalysis, AiFinding } from '../types.js';
 
@@ -57,18 +57,9 @@ export function parseAiResponse(text: string): { findings: AiFinding[]; summary:
 }
 
 async function callModel(prompt: string, model: string): Promise<string> {
-  let creds = getCredentials();
-  if (!creds) throw new Error('Not authenticated');
-
-  if (isTokenExpired(creds)) {
-    if (!creds.refreshToken) throw new Error('Session expired — run `workos login` to re-authenticate');
-    const result = await refreshAccessToken(getAuthkitDomain(), getCliAuthClientId());
-    if (!result.success || !result.accessToken || !result.expiresAt) {
-      throw new Error('Session expired — run `workos login` to re-authenticate');
-    }
-    updateTokens(result.accessToken, result.expiresAt, result.refreshToken);
-    creds = getCredentials()!;
-  }
+  const token = await ensureValidToken();
+  if (!token.credentials) throw new Error(token.error ?? 'Not authenticated');
+  const creds = token.credentials;
 
   const client = new Anthropic({
     baseURL: getLlmGatewayUrl(),
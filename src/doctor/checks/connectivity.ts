@@ -1,3 +1,5 @@
+import { classifyNetworkError, describeNetworkProblem } from '../../lib/connectivity.js';
+import { describeProxyError, proxyFetchOptions, redactProxyUrl, resolveProxy } from '../../lib/proxy.js';
 import type { DoctorOptions, ConnectivityInfo } from '../types.js';
 
 export async function checkConnectivity(options: DoctorOptions, baseUrl: string): Promise<ConnectivityInfo> {
@@ -9,31 +11,44 @@ export async function checkConnectivity(options: DoctorOptions, baseUrl: string)
       error: 'Skipped (--skip-api)',
     };
   }
+  const healthUrl = `${baseUrl}/health`;
+  const effective = resolveProxy(healthUrl);
+  const proxy = effective ? { url: redactProxyUrl(effective.url), source: effective.source } : null;
   const startTime = Date.now();
 
   try {
     const controller = new AbortController();
     const timeoutId = setTimeout(() => controller.abort(), 10000);
 
-    const response = await fetch(`${baseUrl}/health`, {
+    const response = await fetch(healthUrl, {
       signal: controller.signal,
-    });
+      ...proxyFetchOptions(healthUrl),
+    } as RequestInit);
 
     clearTimeout(timeoutId);
     const latencyMs = Date.now() - startTime;
 
+    const host = new URL(baseUrl).host;
+    const serverError =
+      response.status >= 500 ? describeNetworkProblem('server', { host, status: response.status }) : undefined;
     return {
       apiReachable: response.ok,
       latencyMs,
       tlsValid: true, // If fetch succeeded over HTTPS, TLS is valid
-      error: response.ok ? undefined : `HTTP ${response.status}`,
+      error: response.ok ? undefined : (serverError ?? `HTTP ${response.status}`),
+      proxy,
     };
   } catch (error) {
+    const kind = classifyNetworkError(error);
+    const cause = error instanceof Error ? (error.cause as { code?: string } | undefined) : undefined;
     return {
       apiReachable: false,
       latencyMs: null,
       tlsValid: false,
-      error: error instanceof Error ? error.message : 'Unknown error',
+      error:
+        describeProxyError(error, effective) ??
+        describeNetworkProblem(kind, { host: new URL(baseUrl).host, detail: cause?.code, timeoutMs: 10000 }),
+      proxy,
     };
   }
 }
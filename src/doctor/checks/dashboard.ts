@@ -1,4 +1,7 @@
+import { corsOriginMatches } from '../../lib/cors-origins.js';
+import { proxiedFetch } from '../../lib/proxy.js';
 import type {
+  CorsOriginComparison,
   CredentialValidation,
   DashboardSettings,
   DashboardFetchResult,
@@ -46,7 +49,7 @@ async function fetchDashboardSettings(apiKey: string, baseUrlOverride: string |
     const redirectUris: string[] = [];
 
     // Single /organizations?limit=1 call — validates credentials AND gets org count
-    const orgsResponse = await fetch(`${baseUrl}/organizations?limit=1`, {
+    const orgsResponse = await proxiedFetch(`${baseUrl}/organizations?limit=1`, {
       headers: { Authorization: `Bearer ${apiKey}` },
       signal: controller.signal,
     });
@@ -84,7 +87,7 @@ async function fetchDashboardSettings(apiKey: string, baseUrlOverride: string |
     organizationCount = orgsData.list_metadata?.total_count ?? orgsData.data?.length ?? 0;
 
     // Fetch environment settings
-    const envResponse = await fetch(`${baseUrl}/environments/current`, {
+    const envResponse = await proxiedFetch(`${baseUrl}/environments/current`, {
       headers: { Authorization: `Bearer ${apiKey}` },
       signal: controller.signal,
     });
@@ -104,8 +107,16 @@ async function fetchDashboardSettings(apiKey: string, baseUrlOverride: string |
       mfa = envData.mfa_policy ?? null;
     }
 
+    const corsResponse = await proxiedFetch(`${baseUrl}/user_management/cors_origins?limit=100`, {
+      headers: { Authorization: `Bearer ${apiKey}` },
+      signal: controller.signal,
+    });
+    const corsOrigins = corsResponse.ok
+      ? ((await corsResponse.json()) as { data?: Array<{ origin: string }> }).data?.map((o) => o.origin) ?? []
+      : null;
+
     return {
-      settings: { redirectUris, authMethods, sessionTimeout, mfa, organizationCount },
+      settings: { redirectUris, authMethods, sessionTimeout, mfa, organizationCount, corsOrigins },
       credentialValidation,
     };
   } catch (err) {
@@ -159,3 +170,13 @@ export function compareRedirectUris(
 
   return { codeUri, dashboardUris, match, source };
 }
+
+/**
+ * Check the SPA dev origin against the allowed CORS origins. 127.0.0.1 and
+ * localhost are different origins to the browser, so they aren't merged.
+ */
+export function compareCorsOrigins(devOrigin: string, allowedOrigins: string[]): CorsOriginComparison {
+  const origin = devOrigin.toLowerCase();
+  const match = allowedOrigins.some((allowed) => corsOriginMatches(allowed.toLowerCase().replace(/\/$/, ''), origin));
+  return { devOrigin, allowedOrigins, match };
+}
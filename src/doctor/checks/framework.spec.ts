@@ -89,6 +89,21 @@ describe('checkFramework - new frameworks', () => {
     expect(result.name).toBe('SvelteKit');
   });
 
+  it('detects a Vite SPA and the origin its dev server runs on', async () => {
+    await writeFile(join(dir, 'package.json'), makePackageJson({ react: '^18.3.0', vite: '^5.4.0' }));
+    await writeFile(join(dir, 'vite.config.ts'), 'export default { server: { port: 3001, https: {} } };\n');
+    const result = await checkFramework({ installDir: dir });
+    expect(result.name).toBe('Vite');
+    expect(result.devOrigin).toBe('https://localhost:3001');
+  });
+
+  it('prefers Vue over Vite', async () => {
+    await writeFile(join(dir, 'package.json'), makePackageJson({ vue: '^3.4.0', vite: '^5.4.0' }));
+    const result = await checkFramework({ installDir: dir });
+    expect(result.name).toBe('Vue.js');
+    expect(result.devOrigin).toBeUndefined();
+  });
+
   it('returns null for no frameworks', async () => {
     await writeFile(join(dir, 'package.json'), makePackageJson({}));
     const result = await checkFramework({ installDir: dir });
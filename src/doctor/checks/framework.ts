@@ -2,6 +2,7 @@ import { existsSync } from 'node:fs';
 import { join } from 'node:path';
 import { readPackageJson, hasPackageInstalled, getPackageVersion } from '../../utils/package-json.js';
 import { detectPort, getCallbackPath } from '../../lib/port-detection.js';
+import { detectDevOrigin, isSpaIntegration } from '../../lib/cors-origins.js';
 import { KNOWN_INTEGRATIONS } from '../../lib/constants.js';
 import type { Integration } from '../../lib/constants.js';
 import type { DoctorOptions, FrameworkInfo } from '../types.js';
@@ -45,6 +46,9 @@ const FRAMEWORKS: FrameworkConfig[] = [
   { package: 'vue', name: 'Vue.js', integration: null, detectVariant: null },
   { package: 'astro', name: 'Astro', integration: null, detectVariant: null },
   { package: 'svelte', name: 'Svelte', integration: null, detectVariant: null },
+  // Plain SPAs last, since most frameworks above also depend on vite
+  { package: 'react-scripts', name: 'Create React App', integration: KNOWN_INTEGRATIONS.react, detectVariant: null },
+  { package: 'vite', name: 'Vite', integration: KNOWN_INTEGRATIONS.react, detectVariant: null },
 ];
 
 export async function checkFramework(options: DoctorOptions): Promise<FrameworkInfo> {
@@ -59,10 +63,12 @@ export async function checkFramework(options: DoctorOptions): Promise<FrameworkI
       // Get expected callback path and port if we have an integration mapping
       let expectedCallbackPath: string | undefined;
       let detectedPort: number | undefined;
+      let devOrigin: string | undefined;
 
       if (config.integration) {
         expectedCallbackPath = getCallbackPath(config.integration);
         detectedPort = detectPort(config.integration, options.installDir);
+        if (isSpaIntegration(config.integration)) devOrigin = detectDevOrigin(detectedPort, options.installDir);
       }
 
       return {
@@ -71,6 +77,7 @@ export async function checkFramework(options: DoctorOptions): Promise<FrameworkI
         variant,
         expectedCallbackPath,
         detectedPort,
+        ...(devOrigin && { devOrigin }),
       };
     }
   }
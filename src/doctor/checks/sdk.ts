@@ -1,6 +1,7 @@
 import { readFile, readdir } from 'node:fs/promises';
 import { join } from 'node:path';
 import { readPackageJson, hasPackageInstalled, getPackageVersion } from '../../utils/package-json.js';
+import { proxiedFetch } from '../../lib/proxy.js';
 import type { DoctorOptions, SdkInfo } from '../types.js';
 
 // AuthKit SDKs - check newer @workos/* scope first, then legacy @workos-inc/*
@@ -76,7 +77,7 @@ async function fetchLatestVersion(packageName: string): Promise<string | null> {
     const controller = new AbortController();
     const timeoutId = setTimeout(() => controller.abort(), 5000);
 
-    const response = await fetch(`https://registry.npmjs.org/${packageName}/latest`, {
+    const response = await proxiedFetch(`https://registry.npmjs.org/${packageName}/latest`, {
       signal: controller.signal,
     });
 
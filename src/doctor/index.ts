@@ -4,7 +4,7 @@ import { checkRuntime } from './checks/runtime.js';
 import { checkLanguage } from './checks/language.js';
 import { checkEnvironment } from './checks/environment.js';
 import { checkConnectivity } from './checks/connectivity.js';
-import { checkDashboardSettings, compareRedirectUris } from './checks/dashboard.js';
+import { checkDashboardSettings, compareCorsOrigins, compareRedirectUris } from './checks/dashboard.js';
 import { checkAuthPatterns } from './checks/auth-patterns.js';
 import { checkAiAnalysis } from './checks/ai-analysis.js';
 import { detectIssues } from './issues.js';
@@ -66,6 +66,11 @@ export async function runDoctor(options: DoctorOptions): Promise<DoctorReport> {
     ? compareRedirectUris(expectedRedirectUri, dashboardResult.settings.redirectUris, redirectUriSource)
     : undefined;
 
+  // SPAs call AuthKit from the browser, so their dev origin must be allowed
+  const allowedOrigins = dashboardResult.settings?.corsOrigins;
+  const corsOrigins =
+    framework.devOrigin && allowedOrigins ? compareCorsOrigins(framework.devOrigin, allowedOrigins) : undefined;
+
   // Build partial report
   const partialReport = {
     version: DOCTOR_VERSION,
@@ -84,6 +89,7 @@ export async function runDoctor(options: DoctorOptions): Promise<DoctorReport> {
     dashboardSettings: dashboardResult.settings ?? undefined,
     dashboardError: dashboardResult.settings ? undefined : dashboardResult.error,
     redirectUris,
+    corsOrigins,
     authPatterns,
     aiAnalysis,
   };
@@ -41,6 +41,12 @@ export const ISSUE_DEFINITIONS = {
     message: 'Redirect URI not found in dashboard configuration',
     docsUrl: 'https://workos.com/docs/authkit/redirect-uri',
   },
+  CORS_ORIGIN_MISSING: {
+    severity: 'warning' as const,
+    message: 'Dev server origin is not an allowed CORS origin',
+    // remediation generated dynamically
+    docsUrl: 'https://workos.com/docs/authkit/cors',
+  },
   PROD_API_CALL_BLOCKED: {
     severity: 'warning' as const,
     message: 'Dashboard settings not fetched (production API key)',
@@ -110,6 +116,17 @@ export function detectIssues(report: Omit<DoctorReport, 'issues' | 'summary'>):
   // Note: Redirect URI mismatch detection disabled - WorkOS API doesn't expose
   // a public endpoint to list configured redirect URIs for verification
 
+  if (report.corsOrigins && !report.corsOrigins.match) {
+    const { devOrigin } = report.corsOrigins;
+    issues.push({
+      code: 'CORS_ORIGIN_MISSING',
+      ...ISSUE_DEFINITIONS.CORS_ORIGIN_MISSING,
+      message: `Dev server origin ${devOrigin} is not an allowed CORS origin`,
+      remediation: `Run: workos cors add ${devOrigin}`,
+      details: { allowedOrigins: report.corsOrigins.allowedOrigins },
+    });
+  }
+
   // Production key warning (no dashboard data)
   if (report.environment.apiKeyType === 'production' && !report.dashboardSettings) {
     issues.push({
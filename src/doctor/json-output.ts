@@ -1,5 +1,6 @@
 import type { DoctorReport } from './types.js';
+import { redactSecrets } from '../utils/redact.js';
 
 export function formatReportAsJson(report: DoctorReport): string {
-  return JSON.stringify(report, null, 2);
+  return redactSecrets(JSON.stringify(report, null, 2));
 }
@@ -2,6 +2,7 @@ import Chalk from 'chalk';
 import type { DoctorReport, Issue } from './types.js';
 import { renderSummaryBox, type SummaryBoxItem } from '../utils/summary-box.js';
 import type { LockExpression } from '../utils/lock-art.js';
+import { redactSecrets } from '../utils/redact.js';
 
 export interface FormatOptions {
   verbose?: boolean;
@@ -59,6 +60,12 @@ export function formatReport(report: DoctorReport, options?: FormatOptions): voi
   } else {
     console.log(`   API:              ${Chalk.red('✗')} ${report.connectivity.error}`);
   }
+  if (report.connectivity.proxy) {
+    const { url, source } = report.connectivity.proxy;
+    console.log(`   Proxy:            ${url} ${Chalk.dim(`(${source})`)}`);
+  } else if (report.connectivity.proxy === null) {
+    console.log(`   Proxy:            ${Chalk.dim('none')}`);
+  }
 
   // Credential validation
   if (report.credentialValidation) {
@@ -95,6 +102,14 @@ export function formatReport(report: DoctorReport, options?: FormatOptions): voi
     console.log(`   ${report.redirectUris.codeUri}`);
   }
 
+  if (report.corsOrigins) {
+    console.log('');
+    console.log('CORS Origin (Dev Server)');
+    const icon = report.corsOrigins.match ? Chalk.green('✓') : Chalk.yellow('!');
+    const status = report.corsOrigins.match ? 'allowed' : 'not in the allowed origins';
+    console.log(`   ${icon} ${report.corsOrigins.devOrigin} ${Chalk.dim(`(${status})`)}`);
+  }
+
   // Auth Patterns
   if (report.authPatterns) {
     console.log('');
@@ -138,9 +153,9 @@ export function formatReport(report: DoctorReport, options?: FormatOptions): voi
         const color =
           finding.severity === 'error' ? Chalk.red : finding.severity === 'warning' ? Chalk.yellow : Chalk.dim;
         console.log(`   ${icon} ${color(finding.title)}`);
-        console.log(`     ${finding.detail}`);
+        console.log(`     ${redactSecrets(finding.detail)}`);
         if (finding.remediation) {
-          console.log(`     ${Chalk.dim('→')} ${finding.remediation}`);
+          console.log(`     ${Chalk.dim('→')} ${redactSecrets(finding.remediation)}`);
         }
         if (finding.filePath) {
           console.log(`     ${Chalk.dim('File:')} ${finding.filePath}`);
@@ -31,6 +31,7 @@ export interface FrameworkInfo {
   variant?: string; // e.g., 'app-router' | 'pages-router'
   expectedCallbackPath?: string; // e.g., '/auth/callback' for Next.js
   detectedPort?: number;
+  devOrigin?: string; // SPA dev server origin that must be an allowed CORS origin
 }
 
 export interface RuntimeInfo {
@@ -65,6 +66,8 @@ export interface ConnectivityInfo {
   latencyMs: number | null;
   tlsValid: boolean;
   error?: string;
+  /** Proxy the API check went through (credentials redacted) */
+  proxy?: { url: string; source: string } | null;
 }
 
 export interface DashboardSettings {
@@ -73,6 +76,7 @@ export interface DashboardSettings {
   sessionTimeout: string | null;
   mfa: 'optional' | 'required' | 'disabled' | null;
   organizationCount: number;
+  corsOrigins: string[] | null; // null when the list couldn't be fetched
 }
 
 export interface RedirectUriComparison {
@@ -82,6 +86,12 @@ export interface RedirectUriComparison {
   source?: 'env' | 'inferred'; // Where the codeUri came from
 }
 
+export interface CorsOriginComparison {
+  devOrigin: string;
+  allowedOrigins: string[];
+  match: boolean;
+}
+
 export interface CredentialValidation {
   valid: boolean;
   clientIdMatch: boolean;
@@ -124,6 +134,7 @@ export interface DoctorReport {
   dashboardSettings?: DashboardSettings;
   dashboardError?: string;
   redirectUris?: RedirectUriComparison;
+  corsOrigins?: CorsOriginComparison;
   credentialValidation?: CredentialValidation;
   authPatterns?: AuthPatternInfo;
   aiAnalysis?: AiAnalysis;
@@ -6,9 +6,11 @@ import { SPINNER_MESSAGE } from '../../lib/framework-config.js';
 import { getOrAskForWorkOSCredentials } from '../../utils/clack-utils.js';
 import { analytics } from '../../utils/analytics.js';
 import { INSTALLER_INTERACTION_EVENT_NAME } from '../../lib/constants.js';
-import { initializeAgent, runAgent } from '../../lib/agent-interface.js';
+import { agentRunError, initializeAgent, runAgent } from '../../lib/agent-interface.js';
 import { autoConfigureWorkOSEnvironment } from '../../lib/workos-management.js';
 import { validateInstallation } from '../../lib/validation/index.js';
+import { updateModeSection } from '../../lib/existing-integration.js';
+import { instructionsSection } from '../../lib/custom-instructions.js';
 
 export const config: FrameworkConfig = {
   metadata: {
@@ -88,6 +90,7 @@ export async function run(options: InstallerOptions): Promise<string> {
     await autoConfigureWorkOSEnvironment(apiKey, config.metadata.integration, port, {
       homepageUrl: options.homepageUrl,
       redirectUri: options.redirectUri,
+      emitter: options.emitter,
     });
   }
 
@@ -108,7 +111,7 @@ export async function run(options: InstallerOptions): Promise<string> {
 The following WorkOS credentials should be configured in appsettings.Development.json:
 - WORKOS_API_KEY: ${apiKey || '(not provided)'}
 - WORKOS_CLIENT_ID: ${clientId}
-- WORKOS_REDIRECT_URI: ${redirectUri}
+- WORKOS_REDIRECT_URI: ${redirectUri}${updateModeSection(options.existingIntegration)}${instructionsSection(options.customInstructions)}
 
 ## Your Task
 
@@ -148,8 +151,7 @@ Begin by invoking the ${skillName} skill.`;
 
   if (agentResult.error) {
     await analytics.shutdown('error');
-    const message = agentResult.errorMessage || agentResult.error;
-    throw new Error(`Agent SDK error: ${message}`);
+    throw agentRunError(agentResult);
   }
 
   // Post-installation validation
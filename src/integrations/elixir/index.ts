@@ -6,8 +6,11 @@ import { SPINNER_MESSAGE } from '../../lib/framework-config.js';
 import { analytics } from '../../utils/analytics.js';
 import { INSTALLER_INTERACTION_EVENT_NAME } from '../../lib/constants.js';
 import { getOrAskForWorkOSCredentials } from '../../utils/clack-utils.js';
-import { initializeAgent, runAgent } from '../../lib/agent-interface.js';
+import { agentRunError, initializeAgent, runAgent } from '../../lib/agent-interface.js';
 import { writeEnvLocal } from '../../lib/env-writer.js';
+import { protectEnvFile } from '../../lib/secret-scan.js';
+import { updateModeSection } from '../../lib/existing-integration.js';
+import { instructionsSection } from '../../lib/custom-instructions.js';
 
 export const config: FrameworkConfig = {
   metadata: {
@@ -93,10 +96,11 @@ export async function run(options: InstallerOptions): Promise<string> {
       WORKOS_CLIENT_ID: clientId,
       WORKOS_REDIRECT_URI: redirectUri,
     });
+    await protectEnvFile(options.installDir, '.env.local', options);
   }
 
   // Build Elixir-specific prompt
-  const integrationPrompt = buildElixirPrompt();
+  const integrationPrompt = buildElixirPrompt(options);
 
   // Initialize and run agent
   const agent = await initializeAgent(
@@ -122,8 +126,7 @@ export async function run(options: InstallerOptions): Promise<string> {
 
   if (agentResult.error) {
     await analytics.shutdown('error');
-    const message = agentResult.errorMessage || agentResult.error;
-    throw new Error(`Agent SDK error: ${message}`);
+    throw agentRunError(agentResult);
   }
 
   // Build summary
@@ -148,7 +151,7 @@ export async function run(options: InstallerOptions): Promise<string> {
   return lines.join('\n');
 }
 
-function buildElixirPrompt(): string {
+function buildElixirPrompt(options: InstallerOptions): string {
   return `You are integrating WorkOS AuthKit into this Elixir/Phoenix application.
 
 ## Project Context
@@ -163,7 +166,7 @@ The following environment variables have been configured in .env.local:
 - WORKOS_CLIENT_ID
 - WORKOS_REDIRECT_URI
 
-Note: For Elixir/Phoenix, these should be read via System.get_env() in config/runtime.exs rather than from .env.local directly.
+Note: For Elixir/Phoenix, these should be read via System.get_env() in config/runtime.exs rather than from .env.local directly.${updateModeSection(options.existingIntegration)}${instructionsSection(options.customInstructions)}
 
 ## Your Task
 
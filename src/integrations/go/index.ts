@@ -1,20 +1,36 @@
 /* Go integration — auto-discovered by registry */
-import { existsSync, readFileSync, writeFileSync } from 'node:fs';
-import { join } from 'node:path';
+import { existsSync, readFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import fg from 'fast-glob';
 import type { FrameworkConfig } from '../../lib/framework-config.js';
 import { SPINNER_MESSAGE } from '../../lib/framework-config.js';
 import type { InstallerOptions } from '../../utils/types.js';
 import { enableDebugLogs } from '../../utils/debug.js';
 import { analytics } from '../../utils/analytics.js';
 import { INSTALLER_INTERACTION_EVENT_NAME } from '../../lib/constants.js';
-import { initializeAgent, runAgent } from '../../lib/agent-interface.js';
+import { agentRunError, initializeAgent, runAgent } from '../../lib/agent-interface.js';
 import { getOrAskForWorkOSCredentials } from '../../utils/clack-utils.js';
 import { autoConfigureWorkOSEnvironment } from '../../lib/workos-management.js';
-import { validateInstallation } from '../../lib/validation/index.js';
+import { detectPort } from '../../lib/port-detection.js';
+import { trackTouchedFiles, validateInstallation } from '../../lib/validation/index.js';
+import { formatAgentEdits, runSkillVerification } from '../../lib/agent-runner.js';
 import { parseEnvFile } from '../../utils/env-parser.js';
+import { applyFileEdits, type FileEdit } from '../../lib/atomic-write.js';
+import { normalizeLineEndings } from '../../utils/line-endings.js';
+import { envExampleEdit } from '../../lib/env-example.js';
+import { protectEnvFile } from '../../lib/secret-scan.js';
+import { skipsFileWrites } from '../../lib/diff-only.js';
+import { rewriteClaimReads } from '../../migrate/go-claims.js';
+import { rewriteGinAuthRoutes } from '../../migrate/gin-routes.js';
+import { SESSION_COOKIE, SESSION_HELPER_FILE, sessionHelperSource } from '../../migrate/gin-sessions.js';
+import { generateCookiePassword } from '../../lib/env-writer.js';
+import { buildMigrationPrompt } from '../../migrate/prompt.js';
+import { refreshesSessions } from '../../migrate/refresh-tokens.js';
+import { templateOverride } from '../../migrate/templates.js';
+import type { MigrationContext, RedirectSource } from '../../migrate/types.js';
+import { updateModeSection } from '../../lib/existing-integration.js';
+import { instructionsSection } from '../../lib/custom-instructions.js';
 
-/** Default port for Go HTTP servers */
-const GO_DEFAULT_PORT = 8080;
 const GO_CALLBACK_PATH = '/auth/callback';
 
 /**
@@ -31,7 +47,7 @@ function detectGoFramework(installDir: string): 'gin' | 'stdlib' {
 
 /**
  * Write environment variables to .env (Go convention, not .env.local).
- * Merges with existing .env if present.
+ * Merges with existing .env if present and documents the keys in .env.example.
  */
 function writeGoEnv(installDir: string, envVars: Record<string, string>): void {
   const envPath = join(installDir, '.env');
@@ -46,7 +62,168 @@ function writeGoEnv(installDir: string, envVars: Record<string, string>): void {
     .map(([key, value]) => `${key}=${value}`)
     .join('\n');
 
-  writeFileSync(envPath, content + '\n');
+  applyFileEdits([{ path: envPath, content: content + '\n' }, envExampleEdit(installDir, envVars)]);
+}
+
+/** How the old oauth2.Config built its callback URL, when the detector could read it */
+function detectedRedirect(migration: MigrationContext | undefined): RedirectSource | undefined {
+  const finding = migration?.findings.find((f) => f.details?.redirectSource);
+  return finding?.details?.redirectSource as RedirectSource | undefined;
+}
+
+/**
+ * The redirect the migrated login builds: the old one, with a provider env
+ * var renamed to the WorkOS var it maps to. A provider var that's no longer
+ * needed and held the whole URL gives way to WORKOS_REDIRECT_URI.
+ */
+function loginRedirect(migration: MigrationContext): RedirectSource | undefined {
+  const redirect = detectedRedirect(migration);
+  if (!redirect?.envVar || !(redirect.envVar in migration.envMapping)) return redirect;
+  const renamed = migration.envMapping[redirect.envVar];
+  if (renamed) return { ...redirect, envVar: renamed };
+  return redirect.path ? redirect : undefined;
+}
+
+/**
+ * The callback URL to register and write to WORKOS_REDIRECT_URI. A migrated
+ * app keeps the URL its old config used, read from .env or the environment
+ * when it came from an env var; otherwise it's the local default.
+ */
+function callbackUrl(installDir: string, port: number, redirect: RedirectSource | undefined): string {
+  const fallback = `http://localhost:${port}${redirect?.path ?? GO_CALLBACK_PATH}`;
+  if (redirect?.url) return redirect.url;
+  if (!redirect?.envVar) return fallback;
+  const envPath = join(installDir, '.env');
+  const env = existsSync(envPath) ? parseEnvFile(readFileSync(envPath, 'utf-8')) : {};
+  const value = env[redirect.envVar] ?? process.env[redirect.envVar];
+  if (!value) return fallback;
+  return redirect.path ? `${value.replace(/\/+$/, '')}${redirect.path}` : value;
+}
+
+/**
+ * Replace the bodies of existing Gin login/callback/logout handlers with the
+ * AuthKit flow. Registrations, middleware and router groups are left as they
+ * are. The hand-rolled session cookie becomes a sealed AuthKit session, with
+ * the sealing helper written into each affected package. Returns one note
+ * per auth route and per session use left for the agent, and the files
+ * written. The project's template overrides replace the built-in code.
+ * Apps that refreshed tokens under the old provider get the helper with
+ * refreshAuthkitSession added. Claim reads in any Go file are pointed at
+ * the AuthKit user (see go-claims.ts).
+ */
+async function rewriteGinAuthHandlers(
+  installDir: string,
+  migration: MigrationContext,
+): Promise<{ notes: string[]; files: string[] }> {
+  const excludedFiles = migration.excludedFiles ?? [];
+  const template = (file: string) => templateOverride(migration.templates, migration.provider, 'gin', file);
+  const handlerTemplates = {
+    login: template('login.go'),
+    callback: template('callback.go'),
+    logout: template('logout.go'),
+  };
+  const helperTemplate = template(SESSION_HELPER_FILE);
+  const refresh = refreshesSessions(migration.refreshTokens ?? []);
+
+  const files = await fg('**/*.go', { cwd: installDir, ignore: ['**/vendor/**', '**/*_test.go', ...excludedFiles] });
+  const edits: FileEdit[] = [];
+  const notes: string[] = [];
+  const helperDirs = new Set<string>();
+
+  for (const file of files.sort()) {
+    const path = join(installDir, file);
+    // Rewritten as LF; applyFileEdits restores CRLF files
+    const source = normalizeLineEndings(readFileSync(path, 'utf-8'));
+    let rewritten = source;
+    let needsSessionHelper = false;
+    if (source.includes('github.com/gin-gonic/gin')) {
+      const result = rewriteGinAuthRoutes(source, { handlerTemplates, redirect: loginRedirect(migration) });
+      for (const { route, role } of result.rewritten) {
+        const where = `${file}:${route.line} ${route.method} ${route.fullPath}`;
+        notes.push(`${where}: ${role} handler body replaced with AuthKit`);
+      }
+      for (const { route, reason } of result.skipped) {
+        notes.push(`${file}:${route.line} ${route.method} ${route.fullPath}: not rewritten (${reason})`);
+      }
+      for (const { line, reason } of result.sessionFollowUps) {
+        notes.push(`${file}:${line}: session not migrated (${reason})`);
+      }
+      if (result.rewritten.length > 0) rewritten = result.source;
+      needsSessionHelper = result.needsSessionHelper;
+    }
+
+    // Handlers outside Gin read claims too, so every file is checked
+    const claims = rewriteClaimReads(rewritten);
+    for (const { line, claim, field } of claims.rewritten) {
+      notes.push(`${file}:${line}: claim ${claim} now read from the AuthKit user (${field})`);
+    }
+    for (const { line, claim, reason } of claims.followUps) {
+      notes.push(`${file}:${line}: ${claim ? `claim ${claim}` : 'claims'} not migrated (${reason})`);
+    }
+    rewritten = claims.source;
+    needsSessionHelper ||= claims.needsSessionHelper;
+    if (rewritten !== source) edits.push({ path, content: rewritten });
+
+    const dir = dirname(file);
+    if (needsSessionHelper && !helperDirs.has(dir)) {
+      helperDirs.add(dir);
+      const helperPath = join(dir, SESSION_HELPER_FILE);
+      if (existsSync(join(installDir, helperPath))) {
+        notes.push(
+          `${helperPath}: already exists; it must define sealAuthkitSession, authkitSessionFromRequest, ` +
+            'the sessionID and currentUser methods and authkitSessionCookie',
+        );
+      } else {
+        const pkg = /^package\s+(\w+)/m.exec(rewritten)?.[1] ?? 'main';
+        edits.push({
+          path: join(installDir, helperPath),
+          content: sessionHelperSource(pkg, helperTemplate, { refresh }),
+        });
+        notes.push(`${helperPath}: added; seals the session into the ${SESSION_COOKIE} cookie`);
+        if (refresh && !helperTemplate) {
+          notes.push(
+            `${helperPath}: refreshAuthkitSession added; call it where the old code refreshed tokens ` +
+              '(when accessTokenExpired reports true) and remove the old refresh handling',
+          );
+        }
+      }
+    }
+  }
+
+  applyFileEdits(edits);
+
+  // The sealed session needs a cookie password; keep one that's already set
+  const envPath = join(installDir, '.env');
+  const env = existsSync(envPath) ? parseEnvFile(readFileSync(envPath, 'utf-8')) : {};
+  if (helperDirs.size > 0 && !env.WORKOS_COOKIE_PASSWORD && !process.env.WORKOS_COOKIE_PASSWORD) {
+    writeGoEnv(installDir, { WORKOS_COOKIE_PASSWORD: generateCookiePassword() });
+  }
+  return { notes, files: edits.map((edit) => edit.path) };
+}
+
+function ginMigrationSection(notes: string[]): string {
+  if (notes.length === 0) return '';
+  return [
+    '',
+    '',
+    '### Auth routes',
+    '',
+    'The existing Gin auth routes were rewritten in place. Keep each route registration, its middleware and its ' +
+      'router group exactly as they are; finish the wiring around them (call `usermanagement.SetAPIKey` in main, ' +
+      'remove the old provider setup and unused imports). Routes marked "not rewritten" still need their handler ' +
+      'body replaced by hand, again without touching the middleware.',
+    '',
+    `The callback now seals the session into the \`${SESSION_COOKIE}\` cookie with the helpers in ` +
+      `\`${SESSION_HELPER_FILE}\` (WORKOS_COOKIE_PASSWORD is in .env). Lines marked "session not migrated" still ` +
+      'use the old plain session cookie; switch them to `authkitSessionFromRequest(c.Request)` and remove the old ' +
+      'cookie entirely. Never store user data in an unsealed cookie.',
+    '',
+    'Reads of ID token claims now come from the AuthKit user (`authSession.currentUser()`, a ' +
+      '`usermanagement.User`). Claims marked "not migrated" have no field on the user: map custom claims to user ' +
+      'metadata or a JWT template, and read organization, role and permissions from the access token.',
+    '',
+    ...notes.map((note) => `- ${note}`),
+  ].join('\n');
 }
 
 export const config: FrameworkConfig = {
@@ -60,6 +237,16 @@ export const config: FrameworkConfig = {
     priority: 50,
     packageManager: 'go',
     manifestFile: 'go.mod',
+    starter: {
+      name: 'go-gin',
+      framework: 'gin',
+      description: 'Go + Gin with AuthKit',
+      repo: 'https://github.com/workos/authkit-go-gin-example.git',
+      callbackPath: '/callback',
+      envFile: '.env',
+      redirectUriEnvVar: 'WORKOS_REDIRECT_URI',
+      runCommands: ['go mod download', 'go run .'],
+    },
     gatherContext: async (options) => {
       return { framework: detectGoFramework(options.installDir) };
     },
@@ -131,11 +318,14 @@ export async function run(options: InstallerOptions): Promise<string> {
 
   // Auto-configure WorkOS environment (redirect URI, CORS)
   const callerHandledConfig = Boolean(options.apiKey || options.clientId);
+  // Port comes from Run(":8080")/ListenAndServe literals, falling back to 8080
+  const port = detectPort(config.metadata.integration, options.installDir);
+  const redirectUri = options.redirectUri || callbackUrl(options.installDir, port, detectedRedirect(options.migration));
   if (!callerHandledConfig && apiKey) {
-    const redirectUri = options.redirectUri || `http://localhost:${GO_DEFAULT_PORT}${GO_CALLBACK_PATH}`;
-    await autoConfigureWorkOSEnvironment(apiKey, config.metadata.integration, GO_DEFAULT_PORT, {
+    await autoConfigureWorkOSEnvironment(apiKey, config.metadata.integration, port, {
       homepageUrl: options.homepageUrl,
       redirectUri,
+      emitter: options.emitter,
     });
   }
 
@@ -144,12 +334,24 @@ export async function run(options: InstallerOptions): Promise<string> {
 
   // Write .env (not .env.local — Go convention)
   if (!callerHandledConfig) {
-    const redirectUri = options.redirectUri || `http://localhost:${GO_DEFAULT_PORT}${GO_CALLBACK_PATH}`;
     writeGoEnv(options.installDir, {
       ...(apiKey ? { WORKOS_API_KEY: apiKey } : {}),
       WORKOS_CLIENT_ID: clientId,
       WORKOS_REDIRECT_URI: redirectUri,
     });
+    await protectEnvFile(options.installDir, '.env', options);
+  }
+
+  // Migrations on Gin: rewrite the old auth handlers deterministically so middleware survives
+  let migrationSection = '';
+  let injectedFiles: string[] = [];
+  if (options.migration) {
+    const { notes, files } =
+      frameworkContext.framework === 'gin' && !skipsFileWrites(options)
+        ? await rewriteGinAuthHandlers(options.installDir, options.migration)
+        : { notes: [], files: [] };
+    injectedFiles = files;
+    migrationSection = `\n\n${buildMigrationPrompt(options.migration)}${ginMigrationSection(notes)}`;
   }
 
   // Set analytics tags
@@ -178,7 +380,7 @@ export async function run(options: InstallerOptions): Promise<string> {
 The following environment variables have been configured in .env:
 - WORKOS_API_KEY
 - WORKOS_CLIENT_ID
-- WORKOS_REDIRECT_URI
+- WORKOS_REDIRECT_URI${migrationSection}${updateModeSection(options.existingIntegration)}${instructionsSection(options.customInstructions)}
 
 ## Your Task
 
@@ -206,6 +408,7 @@ Begin by invoking the ${skillName} skill.`;
     options,
   );
 
+  const touched = trackTouchedFiles(options.emitter);
   const agentResult = await runAgent(
     agent,
     integrationPrompt,
@@ -220,8 +423,7 @@ Begin by invoking the ${skillName} skill.`;
 
   if (agentResult.error) {
     await analytics.shutdown('error');
-    const message = agentResult.errorMessage || agentResult.error;
-    throw new Error(`Agent SDK error: ${message}`);
+    throw agentRunError(agentResult);
   }
 
   // Post-installation validation (gracefully skips — no rules file for Go)
@@ -243,9 +445,16 @@ Begin by invoking the ${skillName} skill.`;
     });
   }
 
+  const verification = options.noValidate ? null : await runSkillVerification(config, options, agent);
+  // The rewritten handlers get the same final formatting pass as the agent's edits
+  await formatAgentEdits(options, touched, injectedFiles);
+
   // Build summary
   const changes = config.ui.getOutroChanges(frameworkContext).filter(Boolean);
-  const nextSteps = config.ui.getOutroNextSteps(frameworkContext).filter(Boolean);
+  const nextSteps = [
+    ...(verification?.checks ?? []).filter((c) => !c.passed).map((c) => `Fix failing check: ${c.name}`),
+    ...config.ui.getOutroNextSteps(frameworkContext),
+  ].filter(Boolean);
 
   const lines: string[] = [
     'Successfully installed WorkOS AuthKit!',
@@ -21,6 +21,15 @@ export const config: FrameworkConfig = {
     language: 'javascript',
     stability: 'stable',
     priority: 100,
+    starter: {
+      name: 'nextjs-authkit',
+      framework: 'next',
+      description: 'Next.js App Router with AuthKit',
+      repo: 'https://github.com/workos/next-authkit-example.git',
+      envFile: '.env.local',
+      redirectUriEnvVar: 'NEXT_PUBLIC_WORKOS_REDIRECT_URI',
+      runCommands: ['npm install', 'npm run dev'],
+    },
     gatherContext: async (options: InstallerOptions) => {
       const router = await getNextJsRouter(options);
       return { router };
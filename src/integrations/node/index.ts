@@ -13,6 +13,16 @@ export const config: FrameworkConfig = {
     language: 'javascript',
     stability: 'experimental',
     priority: 70,
+    starter: {
+      name: 'express',
+      framework: 'express',
+      description: 'Express server with AuthKit sessions',
+      repo: 'https://github.com/workos/authkit-express-example.git',
+      callbackPath: '/callback',
+      envFile: '.env',
+      redirectUriEnvVar: 'WORKOS_REDIRECT_URI',
+      runCommands: ['npm install', 'npm start'],
+    },
   },
 
   detection: {
@@ -0,0 +1,261 @@
+/* Python Django integration — auto-discovered by registry */
+import type { FrameworkConfig } from '../../lib/framework-config.js';
+import { SPINNER_MESSAGE } from '../../lib/framework-config.js';
+import type { InstallerOptions } from '../../utils/types.js';
+import { enableDebugLogs } from '../../utils/debug.js';
+import { analytics } from '../../utils/analytics.js';
+import { INSTALLER_INTERACTION_EVENT_NAME } from '../../lib/constants.js';
+import { agentRunError, initializeAgent, runAgent } from '../../lib/agent-interface.js';
+import { getOrAskForWorkOSCredentials } from '../../utils/clack-utils.js';
+import { autoConfigureWorkOSEnvironment } from '../../lib/workos-management.js';
+import { detectPort, getCallbackPath } from '../../lib/port-detection.js';
+import { trackTouchedFiles, validateInstallation } from '../../lib/validation/index.js';
+import { formatAgentEdits, runSkillVerification } from '../../lib/agent-runner.js';
+import { protectEnvFile } from '../../lib/secret-scan.js';
+import { skipsFileWrites } from '../../lib/diff-only.js';
+import { detectPythonPackageManager, detectPythonProject } from '../../lib/backend-detection.js';
+import { writeDjangoEnv } from '../../lib/backend-env.js';
+import { buildMigrationPrompt } from '../../migrate/prompt.js';
+import { updateModeSection } from '../../lib/existing-integration.js';
+import { instructionsSection } from '../../lib/custom-instructions.js';
+
+export const config: FrameworkConfig = {
+  metadata: {
+    name: 'Python (Django)',
+    integration: 'python-django',
+    docsUrl: 'https://workos.com/docs/authkit/vanilla/python',
+    skillName: 'workos-python-django',
+    language: 'python',
+    stability: 'experimental',
+    priority: 61,
+    packageManager: 'pip',
+    manifestFile: 'manage.py',
+    gatherContext: async (options: InstallerOptions) => {
+      const project = detectPythonProject(options.installDir);
+      const pkgMgr = detectPythonPackageManager(options.installDir);
+      return {
+        packageManager: pkgMgr.name,
+        installCommand: pkgMgr.installCmd,
+        settingsModule: project?.settingsModule,
+        settingsFile: project?.settingsFile,
+        usesDjangoEnviron: project?.dependencies.includes('django-environ') ?? false,
+        project,
+      };
+    },
+  },
+
+  detection: {
+    packageName: 'django',
+    packageDisplayName: 'Django',
+    getVersion: () => undefined,
+    detect: (installDir) => detectPythonProject(installDir)?.framework === 'django',
+  },
+
+  environment: {
+    uploadToHosting: false,
+    requiresApiKey: true,
+    getEnvVars: (apiKey: string, clientId: string) => ({
+      WORKOS_API_KEY: apiKey,
+      WORKOS_CLIENT_ID: clientId,
+    }),
+  },
+
+  analytics: {
+    getTags: (context: any) => ({
+      'python-package-manager': context?.packageManager || 'unknown',
+      'django-settings-found': String(Boolean(context?.settingsFile)),
+    }),
+  },
+
+  prompts: {
+    getAdditionalContextLines: (context: any) => [
+      ...(context?.packageManager ? [`Package manager: ${context.packageManager}`] : []),
+      ...(context?.installCommand ? [`Install command: ${context.installCommand}`] : []),
+      ...(context?.settingsModule ? [`Settings module: ${context.settingsModule}`] : []),
+      ...(context?.settingsFile ? [`Settings file: ${context.settingsFile}`] : []),
+    ],
+  },
+
+  ui: {
+    successMessage: 'WorkOS AuthKit integration complete',
+    getOutroChanges: (context: any) => [
+      'Analyzed your Django project structure',
+      'Installed the WorkOS Python SDK',
+      context?.settingsFile ? `Loaded WorkOS settings from .env in ${context.settingsFile}` : '',
+      'Created login, callback and logout views under /auth/',
+      'Added session middleware and a login_required decorator for protected views',
+    ],
+    getOutroNextSteps: () => [
+      'Run `python manage.py runserver` to test authentication',
+      'Visit http://localhost:8000/auth/login to test the login flow',
+      'Visit the WorkOS Dashboard to manage users and settings',
+    ],
+  },
+};
+
+/**
+ * Run the Django integration.
+ *
+ * Custom flow like Go's: the universal runner assumes package.json and
+ * .env.local. Before the agent runs, .env gets the credentials and the
+ * settings module gets a block that loads them.
+ */
+export async function run(options: InstallerOptions): Promise<string> {
+  if (options.debug) {
+    enableDebugLogs();
+  }
+
+  options.emitter?.emit('status', {
+    message: `Setting up WorkOS AuthKit for ${config.metadata.name}`,
+  });
+
+  analytics.capture(INSTALLER_INTERACTION_EVENT_NAME, {
+    action: 'started agent integration',
+    integration: config.metadata.integration,
+  });
+
+  const { apiKey, clientId } = await getOrAskForWorkOSCredentials(options, config.environment.requiresApiKey);
+
+  // Auto-configure WorkOS environment (redirect URI, CORS)
+  const callerHandledConfig = Boolean(options.apiKey || options.clientId);
+  const port = detectPort(config.metadata.integration, options.installDir);
+  const redirectUri =
+    options.redirectUri || `http://localhost:${port}${getCallbackPath(config.metadata.integration)}`;
+  if (!callerHandledConfig && apiKey) {
+    await autoConfigureWorkOSEnvironment(apiKey, config.metadata.integration, port, {
+      homepageUrl: options.homepageUrl,
+      redirectUri,
+      emitter: options.emitter,
+    });
+  }
+
+  const frameworkContext = config.metadata.gatherContext ? await config.metadata.gatherContext(options) : {};
+
+  // .env plus the settings.py block that reads it
+  let settingsEdited = false;
+  if (!skipsFileWrites(options) && frameworkContext.project) {
+    settingsEdited = writeDjangoEnv(options.installDir, frameworkContext.project, {
+      ...(apiKey ? { WORKOS_API_KEY: apiKey } : {}),
+      WORKOS_CLIENT_ID: clientId,
+      WORKOS_REDIRECT_URI: redirectUri,
+    });
+    await protectEnvFile(options.installDir, '.env', options);
+  }
+
+  const contextTags = config.analytics.getTags(frameworkContext);
+  Object.entries(contextTags).forEach(([key, value]) => {
+    analytics.setTag(key, value);
+  });
+
+  const additionalLines = config.prompts.getAdditionalContextLines
+    ? config.prompts.getAdditionalContextLines(frameworkContext)
+    : [];
+  const migrationSection = options.migration ? `\n\n${buildMigrationPrompt(options.migration)}` : '';
+
+  const settingsNote = settingsEdited
+    ? `\`${frameworkContext.settingsFile}\` now ends with a "WorkOS AuthKit" block that loads .env and defines ` +
+      'WORKOS_API_KEY, WORKOS_CLIENT_ID, WORKOS_REDIRECT_URI and WORKOS_COOKIE_PASSWORD. Keep it; read the values ' +
+      'through `django.conf.settings`, and install the loader it imports if the project lacks it.'
+    : 'Make the settings module read these values from the environment (see the skill).';
+
+  const skillName = config.metadata.skillName!;
+  const integrationPrompt = `You are integrating WorkOS AuthKit into this ${config.metadata.name} application.
+
+## Project Context
+
+- Language: Python
+- Framework: Django
+${additionalLines.map((line) => `- ${line}`).join('\n')}
+
+## Environment
+
+The following environment variables have been configured in .env:
+- WORKOS_API_KEY
+- WORKOS_CLIENT_ID
+- WORKOS_REDIRECT_URI
+- WORKOS_COOKIE_PASSWORD
+
+${settingsNote}${migrationSection}${updateModeSection(options.existingIntegration)}${instructionsSection(options.customInstructions)}
+
+## Your Task
+
+Use the \`${skillName}\` skill to integrate WorkOS AuthKit into this application.
+
+The skill contains step-by-step instructions including:
+1. Fetching the SDK documentation
+2. Installing the SDK
+3. Adding the auth URLs and the login, callback and logout views
+4. Adding the session middleware
+5. Protecting views with the login_required decorator
+6. Verification with manage.py check
+
+Report your progress using [STATUS] prefixes.
+
+Begin by invoking the ${skillName} skill.`;
+
+  const agent = await initializeAgent(
+    {
+      workingDirectory: options.installDir,
+      workOSApiKey: apiKey,
+      workOSApiHost: 'https://api.workos.com',
+    },
+    options,
+  );
+
+  const touched = trackTouchedFiles(options.emitter);
+  const agentResult = await runAgent(
+    agent,
+    integrationPrompt,
+    options,
+    {
+      spinnerMessage: SPINNER_MESSAGE,
+      successMessage: config.ui.successMessage,
+      errorMessage: 'Integration failed',
+    },
+    options.emitter,
+  );
+
+  if (agentResult.error) {
+    await analytics.shutdown('error');
+    throw agentRunError(agentResult);
+  }
+
+  if (!options.noValidate) {
+    options.emitter?.emit('validation:start', { framework: config.metadata.integration });
+
+    const validationResult = await validateInstallation(config.metadata.integration, options.installDir);
+
+    if (validationResult.issues.length > 0) {
+      options.emitter?.emit('validation:issues', { issues: validationResult.issues });
+    }
+
+    options.emitter?.emit('validation:complete', {
+      passed: validationResult.passed,
+      issueCount: validationResult.issues.length,
+      durationMs: validationResult.durationMs,
+    });
+  }
+
+  const verification = options.noValidate ? null : await runSkillVerification(config, options, agent);
+  await formatAgentEdits(options, touched);
+
+  const changes = config.ui.getOutroChanges(frameworkContext).filter(Boolean);
+  const nextSteps = [
+    ...(verification?.checks ?? []).filter((c) => !c.passed).map((c) => `Fix failing check: ${c.name}`),
+    ...config.ui.getOutroNextSteps(frameworkContext),
+  ].filter(Boolean);
+
+  const lines: string[] = [
+    'Successfully installed WorkOS AuthKit!',
+    '',
+    ...(changes.length > 0 ? ['What the agent did:', ...changes.map((c) => `• ${c}`), ''] : []),
+    ...(nextSteps.length > 0 ? ['Next steps:', ...nextSteps.map((s) => `• ${s}`), ''] : []),
+    `Learn more: ${config.metadata.docsUrl}`,
+    '',
+    'Note: This installer uses an LLM agent to analyze and modify your project. Please review the changes made.',
+  ];
+
+  await analytics.shutdown('success');
+
+  return lines.join('\n');
+}
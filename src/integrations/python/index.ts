@@ -1,5 +1,5 @@
-/* Python/Django integration — auto-discovered by registry */
-import { existsSync, readFileSync, writeFileSync } from 'node:fs';
+/* Python integration (FastAPI, Flask, plain Python) — auto-discovered by registry */
+import { existsSync, readFileSync } from 'node:fs';
 import { join } from 'node:path';
 import type { FrameworkConfig } from '../../lib/framework-config.js';
 import type { InstallerOptions } from '../../utils/types.js';
@@ -7,65 +7,24 @@ import { enableDebugLogs } from '../../utils/debug.js';
 import { analytics } from '../../utils/analytics.js';
 import { INSTALLER_INTERACTION_EVENT_NAME } from '../../lib/constants.js';
 import { parseEnvFile } from '../../utils/env-parser.js';
-
-/**
- * Detect which Python package manager the project uses.
- */
-function detectPythonPackageManager(installDir: string): { name: string; installCmd: string } {
-  if (existsSync(join(installDir, 'uv.lock'))) {
-    return { name: 'uv', installCmd: 'uv add' };
-  }
-
-  if (existsSync(join(installDir, 'pyproject.toml'))) {
-    try {
-      const content = readFileSync(join(installDir, 'pyproject.toml'), 'utf-8');
-      if (content.includes('[tool.poetry]')) {
-        return { name: 'poetry', installCmd: 'poetry add' };
-      }
-    } catch {
-      /* ignore */
-    }
-  }
-
-  if (existsSync(join(installDir, 'Pipfile'))) {
-    return { name: 'pipenv', installCmd: 'pipenv install' };
-  }
-
-  return { name: 'pip', installCmd: 'pip install' };
-}
-
-/**
- * Detect if this is a Django project.
- */
-function isDjangoProject(installDir: string): boolean {
-  if (existsSync(join(installDir, 'manage.py'))) return true;
-
-  const pyprojectPath = join(installDir, 'pyproject.toml');
-  if (existsSync(pyprojectPath)) {
-    try {
-      const content = readFileSync(pyprojectPath, 'utf-8');
-      if (/django/i.test(content)) return true;
-    } catch {
-      /* ignore */
-    }
-  }
-
-  const reqPath = join(installDir, 'requirements.txt');
-  if (existsSync(reqPath)) {
-    try {
-      const content = readFileSync(reqPath, 'utf-8');
-      if (/^django/im.test(content)) return true;
-    } catch {
-      /* ignore */
-    }
-  }
-
-  return false;
-}
+import { applyFileEdits } from '../../lib/atomic-write.js';
+import { envExampleEdit } from '../../lib/env-example.js';
+import { protectEnvFile } from '../../lib/secret-scan.js';
+import { skipsFileWrites } from '../../lib/diff-only.js';
+import {
+  detectPythonPackageManager,
+  detectPythonProject,
+  readPythonDependencies,
+} from '../../lib/backend-detection.js';
+import { updateModeSection } from '../../lib/existing-integration.js';
+import { instructionsSection } from '../../lib/custom-instructions.js';
+
+const FRAMEWORK_NAMES: Record<string, string> = { fastapi: 'FastAPI', flask: 'Flask' };
 
 /**
  * Write .env file for Python projects (not .env.local).
- * Merges with existing .env. No cookie password generation.
+ * Merges with existing .env and documents the keys in .env.example.
+ * No cookie password generation.
  */
 function writeEnvFile(installDir: string, envVars: Record<string, string>): void {
   const envPath = join(installDir, '.env');
@@ -84,12 +43,12 @@ function writeEnvFile(installDir: string, envVars: Record<string, string>): void
     .map(([key, value]) => `${key}=${value}`)
     .join('\n');
 
-  writeFileSync(envPath, content + '\n');
+  applyFileEdits([{ path: envPath, content: content + '\n' }, envExampleEdit(installDir, envVars)]);
 }
 
 export const config: FrameworkConfig = {
   metadata: {
-    name: 'Python (Django)',
+    name: 'Python',
     integration: 'python',
     docsUrl: 'https://workos.com/docs/user-management/authkit/vanilla/python',
     skillName: 'workos-python',
@@ -98,12 +57,25 @@ export const config: FrameworkConfig = {
     priority: 60,
     packageManager: 'pip',
     manifestFile: 'pyproject.toml',
+    starter: {
+      name: 'flask',
+      framework: 'flask',
+      description: 'Flask with AuthKit sessions',
+      bundled: 'flask',
+      port: 5000,
+      callbackPath: '/callback',
+      envFile: '.env',
+      redirectUriEnvVar: 'WORKOS_REDIRECT_URI',
+      runCommands: ['pip install -r requirements.txt', 'python app.py'],
+    },
     gatherContext: async (options: InstallerOptions) => {
       const pkgMgr = detectPythonPackageManager(options.installDir);
+      const project = detectPythonProject(options.installDir);
       return {
         packageManager: pkgMgr.name,
         installCommand: pkgMgr.installCmd,
-        isDjango: isDjangoProject(options.installDir),
+        framework: project?.framework,
+        appTarget: project?.appTarget,
       };
     },
   },
@@ -111,8 +83,10 @@ export const config: FrameworkConfig = {
   detection: {
     // Dummy values for FrameworkDetection interface compat — Python doesn't use package.json
     packageName: 'workos',
-    packageDisplayName: 'Python (Django)',
+    packageDisplayName: 'Python',
     getVersion: () => undefined,
+    // Django projects are taken by the python-django integration first
+    detect: (installDir) => readPythonDependencies(installDir) !== undefined,
   },
 
   environment: {
@@ -127,7 +101,7 @@ export const config: FrameworkConfig = {
   analytics: {
     getTags: (context: any) => ({
       'python-package-manager': context?.packageManager || 'unknown',
-      'python-is-django': String(context?.isDjango ?? false),
+      'python-framework': context?.framework || 'none',
     }),
   },
 
@@ -136,7 +110,8 @@ export const config: FrameworkConfig = {
       const lines: string[] = [];
       if (context?.packageManager) lines.push(`Package manager: ${context.packageManager}`);
       if (context?.installCommand) lines.push(`Install command: ${context.installCommand}`);
-      if (context?.isDjango) lines.push('Framework: Django');
+      if (context?.framework) lines.push(`Framework: ${FRAMEWORK_NAMES[context.framework]}`);
+      if (context?.appTarget) lines.push(`App: ${context.appTarget}`);
       return lines;
     },
   },
@@ -144,30 +119,32 @@ export const config: FrameworkConfig = {
   ui: {
     successMessage: 'WorkOS AuthKit integration complete',
     getOutroChanges: () => [
-      'Analyzed your Python/Django project structure',
+      'Analyzed your Python project structure',
       'Installed WorkOS Python SDK',
-      'Created authentication views (login, callback, logout)',
-      'Configured URL routing and environment variables',
+      'Created authentication routes (login, callback, logout)',
+      'Configured environment variables',
     ],
-    getOutroNextSteps: () => [
-      'Run `python manage.py runserver` to test authentication',
-      'Visit http://localhost:8000/auth/login to test the login flow',
+    getOutroNextSteps: (context: any) => [
+      context?.framework === 'fastapi' && context?.appTarget
+        ? `Run \`uvicorn ${context.appTarget} --reload\` to test authentication`
+        : context?.framework === 'flask' && context?.appTarget
+          ? `Run \`flask --app ${context.appTarget} run\` to test authentication`
+          : 'Start your server to test authentication',
+      'Visit /auth/login to test the login flow',
       'Visit the WorkOS Dashboard to manage users and settings',
     ],
   },
 };
 
 /**
- * Build the agent prompt for Python/Django integration.
+ * Build the agent prompt for the Python integration.
  */
-function buildPythonPrompt(frameworkContext: Record<string, any>): string {
-  const contextLines = ['- Framework: Python (Django)'];
-  if (frameworkContext.packageManager) contextLines.push(`- Package manager: ${frameworkContext.packageManager}`);
-  if (frameworkContext.installCommand) contextLines.push(`- Install command: ${frameworkContext.installCommand}`);
+function buildPythonPrompt(frameworkContext: Record<string, any>, options: InstallerOptions): string {
+  const contextLines = config.prompts.getAdditionalContextLines!(frameworkContext).map((line) => `- ${line}`);
 
   const skillName = config.metadata.skillName!;
 
-  return `You are integrating WorkOS AuthKit into this Python/Django application.
+  return `You are integrating WorkOS AuthKit into this Python application.
 
 ## Project Context
 
@@ -177,7 +154,7 @@ ${contextLines.join('\n')}
 
 The following environment variables have been configured in .env:
 - WORKOS_API_KEY
-- WORKOS_CLIENT_ID
+- WORKOS_CLIENT_ID${updateModeSection(options.existingIntegration)}${instructionsSection(options.customInstructions)}
 
 ## Your Task
 
@@ -186,10 +163,9 @@ Use the \`${skillName}\` skill to integrate WorkOS AuthKit into this application
 The skill contains step-by-step instructions including:
 1. Fetching the SDK documentation
 2. Installing the SDK and python-dotenv
-3. Configuring Django settings
-4. Creating authentication views
-5. Setting up URL routing
-6. Adding authentication UI
+3. Loading settings from the environment
+4. Creating authentication routes
+5. Adding authentication UI
 
 Report your progress using [STATUS] prefixes.
 
@@ -207,7 +183,7 @@ export async function run(options: InstallerOptions): Promise<string> {
   }
 
   options.emitter?.emit('status', {
-    message: 'Setting up WorkOS AuthKit for Python (Django)',
+    message: `Setting up WorkOS AuthKit for ${config.metadata.name}`,
   });
 
   const apiKey = options.apiKey || '';
@@ -228,13 +204,16 @@ export async function run(options: InstallerOptions): Promise<string> {
   }
 
   // Write .env (not .env.local) with WorkOS credentials
-  writeEnvFile(options.installDir, {
-    ...(apiKey ? { WORKOS_API_KEY: apiKey } : {}),
-    WORKOS_CLIENT_ID: clientId,
-  });
+  if (!skipsFileWrites(options)) {
+    writeEnvFile(options.installDir, {
+      ...(apiKey ? { WORKOS_API_KEY: apiKey } : {}),
+      WORKOS_CLIENT_ID: clientId,
+    });
+    await protectEnvFile(options.installDir, '.env', options);
+  }
 
   // Build Python-specific prompt
-  const prompt = buildPythonPrompt(frameworkContext);
+  const prompt = buildPythonPrompt(frameworkContext, options);
 
   // Initialize and run agent directly (bypass runAgentInstaller)
   const { initializeAgent, runAgent } = await import('../../lib/agent-interface.js');
@@ -253,7 +232,7 @@ export async function run(options: InstallerOptions): Promise<string> {
     prompt,
     options,
     {
-      spinnerMessage: 'Setting up WorkOS AuthKit for Python/Django...',
+      spinnerMessage: 'Setting up WorkOS AuthKit for Python...',
       successMessage: config.ui.successMessage,
       errorMessage: 'Python integration failed',
     },
@@ -267,7 +246,7 @@ export async function run(options: InstallerOptions): Promise<string> {
 
   // Build completion summary
   const changes = config.ui.getOutroChanges({});
-  const nextSteps = config.ui.getOutroNextSteps({});
+  const nextSteps = config.ui.getOutroNextSteps(frameworkContext);
 
   const lines: string[] = [
     'Successfully installed WorkOS AuthKit!',
@@ -21,6 +21,15 @@ export const config: FrameworkConfig = {
     language: 'javascript',
     stability: 'stable',
     priority: 80,
+    starter: {
+      name: 'remix',
+      framework: 'remix',
+      description: 'Remix / React Router with AuthKit',
+      repo: 'https://github.com/workos/authkit-remix-example.git',
+      envFile: '.env',
+      redirectUriEnvVar: 'WORKOS_REDIRECT_URI',
+      runCommands: ['npm install', 'npm run dev'],
+    },
     gatherContext: async (options: InstallerOptions) => {
       const routerMode = await getReactRouterMode(options);
       return { routerMode };
@@ -0,0 +1,255 @@
+/* Ruby on Rails integration — auto-discovered by registry */
+import type { FrameworkConfig } from '../../lib/framework-config.js';
+import { SPINNER_MESSAGE } from '../../lib/framework-config.js';
+import type { InstallerOptions } from '../../utils/types.js';
+import { enableDebugLogs } from '../../utils/debug.js';
+import { analytics } from '../../utils/analytics.js';
+import { INSTALLER_INTERACTION_EVENT_NAME } from '../../lib/constants.js';
+import { agentRunError, initializeAgent, runAgent } from '../../lib/agent-interface.js';
+import { getOrAskForWorkOSCredentials } from '../../utils/clack-utils.js';
+import { autoConfigureWorkOSEnvironment } from '../../lib/workos-management.js';
+import { detectPort, getCallbackPath } from '../../lib/port-detection.js';
+import { trackTouchedFiles, validateInstallation } from '../../lib/validation/index.js';
+import { formatAgentEdits, runSkillVerification } from '../../lib/agent-runner.js';
+import { protectEnvFile } from '../../lib/secret-scan.js';
+import { skipsFileWrites } from '../../lib/diff-only.js';
+import { detectRailsProject } from '../../lib/backend-detection.js';
+import { writeRailsCredentials, writeRailsDotenv } from '../../lib/backend-env.js';
+import { buildMigrationPrompt } from '../../migrate/prompt.js';
+import { updateModeSection } from '../../lib/existing-integration.js';
+import { instructionsSection } from '../../lib/custom-instructions.js';
+
+export const config: FrameworkConfig = {
+  metadata: {
+    name: 'Ruby on Rails',
+    integration: 'ruby-rails',
+    docsUrl: 'https://workos.com/docs/authkit/vanilla/ruby',
+    skillName: 'workos-ruby-rails',
+    language: 'ruby',
+    stability: 'experimental',
+    priority: 56,
+    packageManager: 'bundle',
+    manifestFile: 'Gemfile',
+    gatherContext: async (options: InstallerOptions) => {
+      const project = detectRailsProject(options.installDir);
+      return { credentials: project?.credentials ?? false, dotenv: project?.dotenv ?? false };
+    },
+  },
+
+  detection: {
+    packageName: 'rails',
+    packageDisplayName: 'Rails',
+    getVersion: () => undefined,
+    detect: (installDir) => detectRailsProject(installDir) !== undefined,
+  },
+
+  environment: {
+    uploadToHosting: false,
+    requiresApiKey: true,
+    getEnvVars: (apiKey: string, clientId: string) => ({
+      WORKOS_API_KEY: apiKey,
+      WORKOS_CLIENT_ID: clientId,
+    }),
+  },
+
+  analytics: {
+    getTags: (context: any) => (context?.secretsStore ? { 'rails-secrets-store': context.secretsStore } : {}),
+  },
+
+  prompts: {},
+
+  ui: {
+    successMessage: 'WorkOS AuthKit integration complete',
+    getOutroChanges: (context: any) => [
+      'Analyzed your Rails project structure',
+      'Installed and configured the WorkOS Ruby SDK',
+      context?.secretsStore === 'credentials' ? 'Added a workos section to your encrypted credentials' : '',
+      'Created AuthController with login, callback and logout',
+      'Added an Authenticated concern for protected controllers',
+    ],
+    getOutroNextSteps: () => [
+      'Start your Rails server with `bin/rails server` to test authentication',
+      'Visit http://localhost:3000/auth/login to test the login flow',
+      'Visit the WorkOS Dashboard to manage users and settings',
+    ],
+  },
+};
+
+/**
+ * Run the Rails integration.
+ *
+ * Secrets go where the app reads them: encrypted credentials when the master
+ * key is available and the app doesn't use dotenv, `.env` otherwise.
+ */
+export async function run(options: InstallerOptions): Promise<string> {
+  if (options.debug) {
+    enableDebugLogs();
+  }
+
+  options.emitter?.emit('status', {
+    message: `Setting up WorkOS AuthKit for ${config.metadata.name}`,
+  });
+
+  analytics.capture(INSTALLER_INTERACTION_EVENT_NAME, {
+    action: 'started agent integration',
+    integration: config.metadata.integration,
+  });
+
+  const { apiKey, clientId } = await getOrAskForWorkOSCredentials(options, config.environment.requiresApiKey);
+
+  // Auto-configure WorkOS environment (redirect URI, CORS)
+  const callerHandledConfig = Boolean(options.apiKey || options.clientId);
+  const port = detectPort(config.metadata.integration, options.installDir);
+  const redirectUri =
+    options.redirectUri || `http://localhost:${port}${getCallbackPath(config.metadata.integration)}`;
+  if (!callerHandledConfig && apiKey) {
+    await autoConfigureWorkOSEnvironment(apiKey, config.metadata.integration, port, {
+      homepageUrl: options.homepageUrl,
+      redirectUri,
+      emitter: options.emitter,
+    });
+  }
+
+  const frameworkContext = config.metadata.gatherContext ? await config.metadata.gatherContext(options) : {};
+
+  // Credentials when Rails can decrypt them and nothing loads .env; .env otherwise
+  let secretsStore: 'credentials' | 'dotenv' = 'dotenv';
+  if (!skipsFileWrites(options)) {
+    // Encrypted credentials can't go in a --diff-only patch; .env can
+    if (frameworkContext.credentials && !frameworkContext.dotenv && !options.diffOnly) {
+      options.emitter?.emit('status', { message: 'Adding WorkOS settings to Rails credentials' });
+      if (await writeRailsCredentials(options.installDir, { apiKey, clientId, redirectUri })) {
+        secretsStore = 'credentials';
+      }
+    }
+    if (secretsStore === 'dotenv') {
+      writeRailsDotenv(options.installDir, {
+        ...(apiKey ? { WORKOS_API_KEY: apiKey } : {}),
+        WORKOS_CLIENT_ID: clientId,
+        WORKOS_REDIRECT_URI: redirectUri,
+      });
+      await protectEnvFile(options.installDir, '.env', options);
+    }
+  }
+  frameworkContext.secretsStore = secretsStore;
+
+  const contextTags = config.analytics.getTags(frameworkContext);
+  Object.entries(contextTags).forEach(([key, value]) => {
+    analytics.setTag(key, value);
+  });
+
+  const environmentSection =
+    secretsStore === 'credentials'
+      ? [
+          'The WorkOS settings are in the encrypted credentials under `workos:` (api_key, client_id, redirect_uri,',
+          'cookie_password). Read them with `Rails.application.credentials.dig(:workos, :api_key)` and so on,',
+          'falling back to the matching WORKOS_* environment variable. Do not create a .env file.',
+        ].join('\n')
+      : [
+          'The following environment variables have been configured in .env:',
+          '- WORKOS_API_KEY',
+          '- WORKOS_CLIENT_ID',
+          '- WORKOS_REDIRECT_URI',
+          '- WORKOS_COOKIE_PASSWORD',
+          '',
+          frameworkContext.dotenv
+            ? 'dotenv-rails is in the Gemfile, so Rails loads .env already.'
+            : 'Add `dotenv-rails` to the development and test groups of the Gemfile so Rails loads .env.',
+        ].join('\n');
+  const migrationSection = options.migration ? `\n\n${buildMigrationPrompt(options.migration)}` : '';
+
+  const skillName = config.metadata.skillName!;
+  const integrationPrompt = `You are integrating WorkOS AuthKit into this ${config.metadata.name} application.
+
+## Project Context
+
+- Language: Ruby
+- Framework: Rails
+- Secrets: ${secretsStore === 'credentials' ? 'encrypted credentials' : '.env'}
+
+## Environment
+
+${environmentSection}${migrationSection}${updateModeSection(options.existingIntegration)}${instructionsSection(options.customInstructions)}
+
+## Your Task
+
+Use the \`${skillName}\` skill to integrate WorkOS AuthKit into this application.
+
+The skill contains step-by-step instructions including:
+1. Fetching the SDK documentation
+2. Installing the WorkOS Ruby gem
+3. Creating the WorkOS initializer
+4. Creating the AuthController and routes
+5. Adding the Authenticated concern for protected controllers
+6. Verification with zeitwerk:check
+
+Report your progress using [STATUS] prefixes.
+
+Begin by invoking the ${skillName} skill.`;
+
+  const agent = await initializeAgent(
+    {
+      workingDirectory: options.installDir,
+      workOSApiKey: apiKey,
+      workOSApiHost: 'https://api.workos.com',
+    },
+    options,
+  );
+
+  const touched = trackTouchedFiles(options.emitter);
+  const agentResult = await runAgent(
+    agent,
+    integrationPrompt,
+    options,
+    {
+      spinnerMessage: SPINNER_MESSAGE,
+      successMessage: config.ui.successMessage,
+      errorMessage: 'Integration failed',
+    },
+    options.emitter,
+  );
+
+  if (agentResult.error) {
+    await analytics.shutdown('error');
+    throw agentRunError(agentResult);
+  }
+
+  if (!options.noValidate) {
+    options.emitter?.emit('validation:start', { framework: config.metadata.integration });
+
+    const validationResult = await validateInstallation(config.metadata.integration, options.installDir);
+
+    if (validationResult.issues.length > 0) {
+      options.emitter?.emit('validation:issues', { issues: validationResult.issues });
+    }
+
+    options.emitter?.emit('validation:complete', {
+      passed: validationResult.passed,
+      issueCount: validationResult.issues.length,
+      durationMs: validationResult.durationMs,
+    });
+  }
+
+  const verification = options.noValidate ? null : await runSkillVerification(config, options, agent);
+  await formatAgentEdits(options, touched);
+
+  const changes = config.ui.getOutroChanges(frameworkContext).filter(Boolean);
+  const nextSteps = [
+    ...(verification?.checks ?? []).filter((c) => !c.passed).map((c) => `Fix failing check: ${c.name}`),
+    ...config.ui.getOutroNextSteps(frameworkContext),
+  ].filter(Boolean);
+
+  const lines: string[] = [
+    'Successfully installed WorkOS AuthKit!',
+    '',
+    ...(changes.length > 0 ? ['What the agent did:', ...changes.map((c) => `• ${c}`), ''] : []),
+    ...(nextSteps.length > 0 ? ['Next steps:', ...nextSteps.map((s) => `• ${s}`), ''] : []),
+    `Learn more: ${config.metadata.docsUrl}`,
+    '',
+    'Note: This installer uses an LLM agent to analyze and modify your project. Please review the changes made.',
+  ];
+
+  await analytics.shutdown('success');
+
+  return lines.join('\n');
+}
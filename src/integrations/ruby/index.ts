@@ -1,17 +1,19 @@
-/* Ruby/Rails integration — auto-discovered by registry */
+/* Ruby integration (Sinatra, Rack, plain Ruby) — auto-discovered by registry */
 import type { FrameworkConfig } from '../../lib/framework-config.js';
 import type { InstallerOptions } from '../../utils/types.js';
 import { enableDebugLogs } from '../../utils/debug.js';
 import { SPINNER_MESSAGE } from '../../lib/framework-config.js';
 import { analytics } from '../../utils/analytics.js';
 import { INSTALLER_INTERACTION_EVENT_NAME } from '../../lib/constants.js';
-import { initializeAgent, runAgent } from '../../lib/agent-interface.js';
+import { agentRunError, initializeAgent, runAgent } from '../../lib/agent-interface.js';
 import { getOrAskForWorkOSCredentials } from '../../utils/clack-utils.js';
 import { autoConfigureWorkOSEnvironment } from '../../lib/workos-management.js';
+import { updateModeSection } from '../../lib/existing-integration.js';
+import { instructionsSection } from '../../lib/custom-instructions.js';
 
 export const config: FrameworkConfig = {
   metadata: {
-    name: 'Ruby (Rails)',
+    name: 'Ruby',
     integration: 'ruby',
     docsUrl: 'https://workos.com/docs/authkit/vanilla/ruby',
     skillName: 'workos-ruby',
@@ -23,8 +25,9 @@ export const config: FrameworkConfig = {
   },
 
   detection: {
-    packageName: 'rails',
-    packageDisplayName: 'Rails',
+    // Rails apps are taken by the ruby-rails integration first
+    packageName: 'workos',
+    packageDisplayName: 'Ruby',
     getVersion: () => undefined,
   },
 
@@ -46,20 +49,19 @@ export const config: FrameworkConfig = {
   ui: {
     successMessage: 'WorkOS AuthKit integration complete',
     getOutroChanges: () => [
-      'Analyzed your Rails project structure',
+      'Analyzed your Ruby project structure',
       'Installed and configured the WorkOS Ruby SDK',
-      'Created authentication controller with login, callback, and logout',
-      'Added authentication routes to config/routes.rb',
+      'Created login, callback, and logout routes',
     ],
     getOutroNextSteps: () => [
-      'Start your Rails server with `rails server` to test authentication',
+      'Start your server to test authentication',
       'Visit the WorkOS Dashboard to manage users and settings',
     ],
   },
 };
 
 /**
- * Custom run function for Ruby/Rails — bypasses runAgentInstaller
+ * Custom run function for Ruby — bypasses runAgentInstaller
  * since that assumes a JS project (package.json, node_modules, .env.local).
  */
 export async function run(options: InstallerOptions): Promise<string> {
@@ -82,20 +84,20 @@ export async function run(options: InstallerOptions): Promise<string> {
   // Auto-configure WorkOS environment (redirect URI, CORS, homepage) if not already done
   const callerHandledConfig = Boolean(options.apiKey || options.clientId);
   if (!callerHandledConfig && apiKey) {
-    const port = 3000; // Rails default
+    const port = 3000; // Puma default
     await autoConfigureWorkOSEnvironment(apiKey, config.metadata.integration, port, {
       homepageUrl: options.homepageUrl,
       redirectUri: options.redirectUri,
+      emitter: options.emitter,
     });
   }
 
   // Build prompt for the agent
   const redirectUri = options.redirectUri || 'http://localhost:3000/auth/callback';
-  const prompt = `You are integrating WorkOS AuthKit into this Ruby on Rails application.
+  const prompt = `You are integrating WorkOS AuthKit into this Ruby application.
 
 ## Project Context
 
-- Framework: Ruby (Rails)
 - Language: Ruby
 
 ## Environment
@@ -103,7 +105,7 @@ export async function run(options: InstallerOptions): Promise<string> {
 The following environment variables should be configured in a .env file:
 - WORKOS_API_KEY=${apiKey ? '(provided)' : '(not set)'}
 - WORKOS_CLIENT_ID=${clientId || '(not set)'}
-- WORKOS_REDIRECT_URI=${redirectUri}
+- WORKOS_REDIRECT_URI=${redirectUri}${updateModeSection(options.existingIntegration)}${instructionsSection(options.customInstructions)}
 
 ## Your Task
 
@@ -112,9 +114,8 @@ Use the \`workos-ruby\` skill to integrate WorkOS AuthKit into this application.
 The skill contains step-by-step instructions including:
 1. Fetching the SDK documentation
 2. Installing the WorkOS Ruby gem
-3. Creating the WorkOS initializer
-4. Creating the AuthController with login, callback, and logout
-5. Adding authentication routes
+3. Configuring the WorkOS client
+4. Adding login, callback, and logout routes
 
 Report your progress using [STATUS] prefixes.
 
@@ -144,8 +145,7 @@ Begin by invoking the workos-ruby skill.`;
 
   if (agentResult.error) {
     await analytics.shutdown('error');
-    const message = agentResult.errorMessage || agentResult.error;
-    throw new Error(`Agent SDK error: ${message}`);
+    throw agentRunError(agentResult);
   }
 
   // Build completion summary
@@ -244,5 +244,24 @@ describe('CLIAdapter', () => {
       expect(output).toContain('Something went wrong');
       consoleSpy.mockRestore();
     });
+
+    it('reports a cancelled run with its changed files instead of a failure box', async () => {
+      await adapter.start();
+      const clack = await import('../../utils/clack.js');
+      const consoleSpy = vi.spyOn(console, 'log');
+
+      emitter.emit('complete', { success: false, cancelled: true, summary: 'Installer cancelled by user' });
+      emitter.emit('cancelled', {
+        changedFiles: ['middleware.ts'],
+        checkpointPath: '/home/.workos/checkpoints/x.json',
+      });
+
+      const output = consoleSpy.mock.calls.map((c) => String(c[0])).join('\n');
+      expect(output).not.toContain('Installation Failed');
+      expect(clack.default.log.warn).toHaveBeenCalledWith('Installer cancelled');
+      expect(clack.default.log.info).toHaveBeenCalledWith(expect.stringContaining('middleware.ts'));
+      expect(clack.default.log.info).toHaveBeenCalledWith(expect.stringContaining('/home/.workos/checkpoints/x.json'));
+      consoleSpy.mockRestore();
+    });
   });
 });
@@ -1,10 +1,30 @@
 import type { InstallerAdapter, AdapterConfig } from './types.js';
 import type { InstallerEventEmitter, InstallerEvents } from '../events.js';
-import clack from '../../utils/clack.js';
+import clack, { isQuietMode } from '../../utils/clack.js';
 import chalk from 'chalk';
 import { getConfig } from '../settings.js';
 import { ProgressTracker } from '../progress-tracker.js';
-import { renderCompletionSummary } from '../../utils/summary-box.js';
+import {
+  buildRunSummary,
+  formatResultLine,
+  formatRunSummary,
+  summaryWarnings,
+  type SummaryFormat,
+} from '../../utils/run-summary.js';
+import { redactSecrets } from '../../utils/redact.js';
+import { logInfo } from '../../utils/debug.js';
+import { relativePosix } from '../../utils/paths.js';
+import { formatEstimate } from '../agent-estimate.js';
+import { addAgentRun, formatUsage, type RunUsage } from '../agent-usage.js';
+import { formatStepBreakdown } from '../migration-steps.js';
+import type { StopReason } from '../interrupt.js';
+
+const STOP_MESSAGES: Record<StopReason, string> = {
+  interrupt: 'Installer cancelled',
+  terminate: 'Installer stopped by SIGTERM',
+  'idle-timeout': 'Installer stopped: the agent went idle',
+  timeout: 'Installer stopped: the agent ran out of time',
+};
 
 /**
  * CLI adapter that renders wizard events via clack.
@@ -16,9 +36,16 @@ export class CLIAdapter implements InstallerAdapter {
   readonly emitter: InstallerEventEmitter;
   private sendEvent: AdapterConfig['sendEvent'];
   private debug: boolean;
+  private summaryFormat: SummaryFormat;
+  private migration: AdapterConfig['migration'];
   private spinner: ReturnType<typeof clack.spinner> | null = null;
   private isStarted = false;
   private progress = new ProgressTracker();
+  private startedAt = Date.now();
+  /** Summed over the main agent run and any repair runs */
+  private usage: RunUsage | undefined;
+  /** Formatters already reported as not installed; formatting runs between agent turns too */
+  private missingFormatters = new Set<string>();
 
   // Store bound handlers for cleanup
   private handlers = new Map<string, (...args: unknown[]) => void>();
@@ -27,9 +54,6 @@ export class CLIAdapter implements InstallerAdapter {
   private isPromptActive = false;
   private pendingLogs: Array<() => void> = [];
 
-  // SIGINT handler for cleanup
-  private sigIntHandler: (() => void) | null = null;
-
   // Long-running agent update interval
   private agentUpdateInterval: NodeJS.Timeout | null = null;
 
@@ -37,6 +61,8 @@ export class CLIAdapter implements InstallerAdapter {
     this.emitter = config.emitter;
     this.sendEvent = config.sendEvent;
     this.debug = config.debug ?? false;
+    this.summaryFormat = config.summaryFormat ?? 'table';
+    this.migration = config.migration;
   }
 
   /**
@@ -61,10 +87,11 @@ export class CLIAdapter implements InstallerAdapter {
   async start(): Promise<void> {
     if (this.isStarted) return;
     this.isStarted = true;
+    this.startedAt = Date.now();
 
     // Show intro
     const config = getConfig();
-    if (config.branding.showAsciiArt) {
+    if (config.branding.showAsciiArt && !isQuietMode()) {
       const art = config.branding.useCompact ? config.branding.compactAsciiArt : config.branding.asciiArt;
       console.log(chalk.cyan(art));
       console.log();
@@ -72,20 +99,6 @@ export class CLIAdapter implements InstallerAdapter {
       clack.intro('Welcome to the WorkOS AuthKit installer');
     }
 
-    // Handle Ctrl+C gracefully
-    const handleSigInt = () => {
-      if (this.spinner) {
-        this.spinner.stop('Cancelled');
-        this.spinner = null;
-      }
-      this.stopAgentUpdates();
-      clack.log.warn('Installer cancelled');
-      clack.outro('Your project was not modified');
-      process.exit(0);
-    };
-    process.on('SIGINT', handleSigInt);
-    this.sigIntHandler = handleSigInt;
-
     // Subscribe to state events for progress tracking
     this.subscribe('state:enter', this.handleStateEnter);
     this.subscribe('state:exit', this.handleStateExit);
@@ -99,18 +112,39 @@ export class CLIAdapter implements InstallerAdapter {
     this.subscribe('credentials:found', this.handleCredentialsFound);
     this.subscribe('credentials:request', this.handleCredentialsRequest);
     this.subscribe('credentials:env:prompt', this.handleEnvScanPrompt);
+    this.subscribe('prompt:request', this.handlePromptRequest);
+    this.subscribe('change:diff', this.handleChangeDiff);
+    this.subscribe('change:summary', this.handleChangeSummary);
+    this.subscribe('change:patch', this.handleChangePatch);
+    this.subscribe('backups:kept', this.handleBackupsKept);
     this.subscribe('device:started', this.handleDeviceStarted);
     this.subscribe('device:success', this.handleDeviceSuccess);
     this.subscribe('staging:fetching', this.handleStagingFetching);
     this.subscribe('staging:success', this.handleStagingSuccess);
     this.subscribe('credentials:env:found', this.handleEnvCredentialsFound);
     this.subscribe('config:complete', this.handleConfigComplete);
+    this.subscribe('env:renamed', this.handleEnvRenamed);
+    this.subscribe('migration:steps', this.handleMigrationSteps);
+    this.subscribe('migration:rollback', this.handleMigrationRollback);
     this.subscribe('agent:start', this.handleAgentStart);
     this.subscribe('agent:progress', this.handleAgentProgress);
+    this.subscribe('agent:estimate', this.handleAgentEstimate);
+    this.subscribe('agent:usage', this.handleAgentUsage);
+    this.subscribe('agent:timeout', this.handleAgentTimeout);
+    this.subscribe('hook:start', this.handleHookStart);
+    this.subscribe('hook:output', this.handleHookOutput);
+    this.subscribe('hook:complete', this.handleHookComplete);
     this.subscribe('validation:start', this.handleValidationStart);
     this.subscribe('validation:issues', this.handleValidationIssues);
     this.subscribe('validation:complete', this.handleValidationComplete);
+    this.subscribe('checks:formatted', this.handleChecksFormatted);
+    this.subscribe('verification:start', this.handleVerificationStart);
+    this.subscribe('verification:check', this.handleVerificationCheck);
+    this.subscribe('verification:complete', this.handleVerificationComplete);
+    this.subscribe('verification:repair', this.handleVerificationRepair);
     this.subscribe('complete', this.handleComplete);
+    this.subscribe('report:written', this.handleReportWritten);
+    this.subscribe('cancelled', this.handleCancelled);
     this.subscribe('error', this.handleError);
     // Branch check events
     this.subscribe('branch:prompt', this.handleBranchPrompt);
@@ -119,6 +153,7 @@ export class CLIAdapter implements InstallerAdapter {
     // Post-install events
     this.subscribe('postinstall:changes', this.handlePostInstallChanges);
     this.subscribe('postinstall:commit:prompt', this.handleCommitPrompt);
+    this.subscribe('postinstall:commit:blocked', this.handleCommitBlocked);
     this.subscribe('postinstall:commit:generating', this.handleCommitGenerating);
     this.subscribe('postinstall:commit:success', this.handleCommitSuccess);
     this.subscribe('postinstall:commit:failed', this.handleCommitFailed);
@@ -127,6 +162,8 @@ export class CLIAdapter implements InstallerAdapter {
     this.subscribe('postinstall:pr:pushing', this.handlePrPushing);
     this.subscribe('postinstall:pr:success', this.handlePrSuccess);
     this.subscribe('postinstall:pr:failed', this.handlePrFailed);
+    this.subscribe('postinstall:pr:skipped', this.handlePrSkipped);
+    this.subscribe('postinstall:push:success', this.handlePushSuccess);
     this.subscribe('postinstall:push:failed', this.handlePushFailed);
     this.subscribe('postinstall:manual', this.handleManualInstructions);
   }
@@ -134,12 +171,6 @@ export class CLIAdapter implements InstallerAdapter {
   async stop(): Promise<void> {
     if (!this.isStarted) return;
 
-    // Remove SIGINT handler
-    if (this.sigIntHandler) {
-      process.off('SIGINT', this.sigIntHandler);
-      this.sigIntHandler = null;
-    }
-
     // Stop agent updates
     this.stopAgentUpdates();
 
@@ -170,10 +201,24 @@ export class CLIAdapter implements InstallerAdapter {
     }
   }
 
+  /**
+   * Stop the active spinner so output can be printed, returning a function
+   * that starts it again. No-op when no spinner is running.
+   */
+  private pauseSpinner(message: string): () => void {
+    if (!this.spinner) return () => {};
+    this.spinner.stop(message);
+    this.spinner = null;
+    return () => {
+      this.spinner = clack.spinner();
+      this.spinner.start('Running AI agent...');
+    };
+  }
+
   /** Debug logging - only outputs when debug mode is enabled */
   private debugLog = (message: string): void => {
     if (this.debug) {
-      console.log(chalk.dim(`[debug] ${message}`));
+      console.log(chalk.dim(`[debug] ${redactSecrets(message)}`));
     }
   };
 
@@ -226,6 +271,7 @@ export class CLIAdapter implements InstallerAdapter {
     const confirmed = await clack.confirm({
       message: `Found ${fileList}. Check for existing WorkOS credentials?`,
       initialValue: true,
+      id: 'env-scan',
     });
     this.isPromptActive = false;
     this.flushPendingLogs();
@@ -235,6 +281,61 @@ export class CLIAdapter implements InstallerAdapter {
     });
   };
 
+  private handlePromptRequest = async ({ id, message, options }: InstallerEvents['prompt:request']): Promise<void> => {
+    if (!options?.length) return;
+    // Prompts can arrive mid-run (e.g. reviewing agent changes); the spinner would redraw over them
+    const resumeSpinner = this.pauseSpinner('Waiting for input');
+    this.isPromptActive = true;
+    const choice = await clack.select({
+      message,
+      options: options.map((option) => ({ value: option, label: option })),
+      id,
+    });
+    this.isPromptActive = false;
+    this.flushPendingLogs();
+    resumeSpinner();
+
+    // Cancelling falls back to the first (most likely) option
+    this.emitter.emit('prompt:response', { id, value: clack.isCancel(choice) ? options[0] : choice });
+  };
+
+  private handleChangeDiff = ({ path, diff }: InstallerEvents['change:diff']): void => {
+    this.queueableLog(() => {
+      const resumeSpinner = this.pauseSpinner('Proposed change');
+      clack.log.step(path);
+      console.log(diff);
+      resumeSpinner();
+    });
+  };
+
+  private handleChangeSummary = ({ text }: InstallerEvents['change:summary']): void => {
+    this.queueableLog(() => clack.log.info(text));
+  };
+
+  private handleChangePatch = ({ path, root, files, redacted }: InstallerEvents['change:patch']): void => {
+    if (files.length === 0) {
+      clack.log.info(`No changes to write; ${chalk.cyan(path)} is empty.`);
+      return;
+    }
+    const patch = relativePosix(root, path);
+    const lines = [
+      `Patch with ${files.length} file(s) written to ${chalk.cyan(path)}. Nothing else was changed.`,
+      ...files.map((file) => chalk.dim(`  ${file}`)),
+      `After review, apply it from ${chalk.cyan(root)} with ${chalk.cyan(`git apply ${patch}`)}.`,
+    ];
+    if (redacted.length > 0) {
+      lines.push(`Secrets are left empty in the patch; fill them in after applying it: ${redacted.join(', ')}`);
+    }
+    clack.log.success(lines.join('\n'));
+  };
+
+  private handleBackupsKept = ({ files }: InstallerEvents['backups:kept']): void => {
+    clack.log.info(
+      `Kept the originals of ${files.length} file(s) as ${chalk.cyan('<file>.workos.bak')}; ` +
+        `remove them with ${chalk.cyan('workos clean')}.`,
+    );
+  };
+
   private handleDeviceStarted = ({ verificationUri, userCode }: InstallerEvents['device:started']): void => {
     clack.log.info(`\nOpen this URL in your browser:\n`);
     console.log(`  ${chalk.cyan(verificationUri)}`);
@@ -276,6 +377,8 @@ export class CLIAdapter implements InstallerAdapter {
     const confirmed = await clack.confirm({
       message: 'Continue anyway?',
       initialValue: false,
+      flag: '--ci',
+      id: 'git-dirty',
     });
     this.isPromptActive = false;
     this.flushPendingLogs();
@@ -293,6 +396,7 @@ export class CLIAdapter implements InstallerAdapter {
     const clientId = await clack.text({
       message: 'Enter your WorkOS Client ID:',
       placeholder: 'client_...',
+      flag: '--client-id',
       validate: (value) => {
         if (!value || value.trim().length === 0) {
           return 'Client ID is required';
@@ -314,6 +418,7 @@ export class CLIAdapter implements InstallerAdapter {
       clack.log.info(chalk.dim('ℹ️ Your API key will be hidden for security and saved to .env.local'));
       const apiKeyResult = await clack.password({
         message: 'Enter your WorkOS API Key:',
+        flag: '--api-key',
         validate: (value) => {
           if (!value || value.trim().length === 0) {
             return 'API Key is required';
@@ -345,6 +450,51 @@ export class CLIAdapter implements InstallerAdapter {
     clack.log.success('Environment configured');
   };
 
+  private handleEnvRenamed = ({ keys, provider }: InstallerEvents['env:renamed']): void => {
+    const kept = chalk.dim(' (reference kept as written)');
+    const lines = keys.map((k) => `  ${k.file}: ${k.from} → ${k.to}${k.reference ? kept : ''}`);
+    clack.log.info(`Renamed env keys:\n${lines.join('\n')}`);
+    const references = [...new Set(keys.filter((k) => k.reference).map((k) => k.to))];
+    if (references.length > 0) {
+      clack.log.warn(
+        `${references.join(', ')} still point to the ${provider} values they referenced. ` +
+          'Store the WorkOS values there (secrets manager or the referenced variable) before deploying.',
+      );
+    }
+    const containerFiles = [...new Set(keys.filter((k) => k.container).map((k) => k.file))];
+    if (containerFiles.length > 0) {
+      clack.log.warn(
+        `Renamed keys in container config (${containerFiles.join(', ')}). ` +
+          'Rebuild the images and recreate the containers (e.g. `docker compose up --build`) to pick them up.',
+      );
+    }
+  };
+
+  private handleMigrationSteps = ({
+    steps,
+    succeeded,
+    failed,
+    partial,
+    statePath,
+  }: InstallerEvents['migration:steps']): void => {
+    const headline = partial
+      ? `Migration partly applied: ${succeeded} of ${steps.length} file(s) changed`
+      : `${failed} file change(s) failed`;
+    // Tool errors can quote the file they failed on
+    clack.log.warn(redactSecrets(`${headline}\n${formatStepBreakdown(steps)}`));
+    if (statePath) {
+      clack.log.info(chalk.dim(`Saved to ${statePath}; run \`workos migrate\` again to finish the remaining files`));
+    }
+  };
+
+  private handleMigrationRollback = ({ restored, removed, kept }: InstallerEvents['migration:rollback']): void => {
+    const undone = [...restored.map((f) => `  ${f} (restored)`), ...removed.map((f) => `  ${f} (removed)`)];
+    if (undone.length > 0) clack.log.info(`Rolled back:\n${undone.join('\n')}`);
+    if (kept.length > 0) {
+      clack.log.warn(`Left in place:\n${kept.map(({ file, reason }) => `  ${file} — ${reason}`).join('\n')}`);
+    }
+  };
+
   private handleAgentStart = (): void => {
     this.spinner = clack.spinner();
     this.spinner.start('Running AI agent...');
@@ -389,15 +539,139 @@ export class CLIAdapter implements InstallerAdapter {
     }
   };
 
-  private handleComplete = ({ success, summary }: InstallerEvents['complete']): void => {
+  private handleChecksFormatted = ({ files, tools, missing = [] }: InstallerEvents['checks:formatted']): void => {
+    // Formatting also runs between agent turns
+    const resumeSpinner = this.pauseSpinner('Formatting');
+    if (files.length > 0) {
+      clack.log.info(`Formatted ${files.length} file(s) with ${tools.join(', ')}: ${chalk.dim(files.join(', '))}`);
+    }
+    for (const tool of missing.filter((t) => !this.missingFormatters.has(t))) {
+      this.missingFormatters.add(tool);
+      clack.log.warn(`${tool} isn't installed, so the files it formats were left as written`);
+    }
+    resumeSpinner();
+  };
+
+  private handleVerificationStart = ({ checks, attempt }: InstallerEvents['verification:start']): void => {
     this.stopAgentUpdates();
+    this.stopSpinner(attempt > 0 ? 'Repair completed' : 'Agent completed');
+    clack.log.step(`Running ${checks} verification check(s)`);
+  };
+
+  private handleVerificationCheck = (check: InstallerEvents['verification:check']): void => {
+    const duration = chalk.dim(`(${(check.durationMs / 1000).toFixed(1)}s)`);
+    if (check.skipped) {
+      clack.log.info(`${check.name} ${chalk.dim(`skipped: ${check.output}`)}`);
+    } else if (check.passed) {
+      clack.log.success(`${check.name} ${duration}`);
+    } else {
+      clack.log.error(`${check.name} ${duration}${check.output ? `\n${chalk.dim(check.output)}` : ''}`);
+    }
+  };
+
+  private handleVerificationComplete = ({ passed, failed }: InstallerEvents['verification:complete']): void => {
+    if (passed) {
+      clack.log.success('All verification checks passed');
+    } else {
+      clack.log.warn(`${failed.length} verification check(s) failed`);
+    }
+  };
+
+  private handleVerificationRepair = ({
+    attempt,
+    maxAttempts,
+    failed,
+  }: InstallerEvents['verification:repair']): void => {
+    clack.log.step(`Asking the agent to fix ${failed.length} failed check(s) (attempt ${attempt}/${maxAttempts})`);
+  };
+
+  private handleHookStart = ({ phase, command }: InstallerEvents['hook:start']): void => {
+    // Post hooks start while the agent spinner is still up
+    this.stopAgentUpdates();
+    this.stopSpinner('Agent finished');
+    clack.log.step(`Running ${phase} hook: ${chalk.cyan(command)}`);
+  };
+
+  private handleHookOutput = ({ text }: InstallerEvents['hook:output']): void => {
+    process.stdout.write(chalk.dim(text));
+  };
+
+  private handleHookComplete = ({
+    command,
+    exitCode,
+    required,
+    durationMs,
+  }: InstallerEvents['hook:complete']): void => {
+    const duration = chalk.dim(`(${(durationMs / 1000).toFixed(1)}s)`);
+    if (exitCode === 0) {
+      clack.log.success(`${command} ${duration}`);
+    } else if (required) {
+      clack.log.error(`${command} exited with code ${exitCode} ${duration}`);
+    } else {
+      clack.log.warn(`${command} exited with code ${exitCode} ${duration}; optional, continuing`);
+    }
+  };
+
+  private handleAgentEstimate = ({ estimate, warning }: InstallerEvents['agent:estimate']): void => {
+    this.queueableLog(() => {
+      const resumeSpinner = this.pauseSpinner('Estimated');
+      clack.log.info(`Estimated agent run: ${formatEstimate(estimate)}`);
+      if (warning) clack.log.warn(warning);
+      resumeSpinner();
+    });
+  };
+
+  private handleAgentUsage = (run: InstallerEvents['agent:usage']): void => {
+    this.usage = addAgentRun(this.usage, run);
+  };
+
+  private handleComplete = ({ success, summary, changedFiles, cancelled }: InstallerEvents['complete']): void => {
+    this.stopAgentUpdates();
+    // Cancelled runs are reported by handleCancelled once the checkpoint is saved
+    if (cancelled) {
+      this.stopSpinner('Cancelled');
+      return;
+    }
     this.stopSpinner(success ? 'Done' : 'Failed');
 
+    const usage = this.usage && { ...this.usage, durationMs: Date.now() - this.startedAt };
+    if (usage) logInfo('Run usage:', formatUsage(usage));
+    const result = buildRunSummary({ success, summary, changedFiles, migration: this.migration, usage });
+    if (isQuietMode() && this.summaryFormat === 'table') {
+      for (const warning of summaryWarnings(result)) clack.log.warn(warning);
+      console.log(formatResultLine(result));
+      return;
+    }
     console.log('');
-    console.log(renderCompletionSummary(success, summary));
+    console.log(formatRunSummary(result, this.summaryFormat));
     console.log('');
   };
 
+  private handleReportWritten = ({ path }: InstallerEvents['report:written']): void => {
+    clack.log.info(`Report written to ${chalk.cyan(path)}`);
+  };
+
+  private handleAgentTimeout = ({ message }: InstallerEvents['agent:timeout']): void => {
+    this.stopAgentUpdates();
+    this.stopSpinner('Timed out');
+    clack.log.error(message);
+  };
+
+  private handleCancelled = ({ changedFiles, checkpointPath, reason }: InstallerEvents['cancelled']): void => {
+    this.stopAgentUpdates();
+    this.stopSpinner('Cancelled');
+    clack.log.warn(STOP_MESSAGES[reason ?? 'interrupt']);
+    if (changedFiles.length > 0) {
+      clack.log.info(`Uncommitted changes in your project:\n${changedFiles.map((f) => `  ${f}`).join('\n')}`);
+      clack.log.info(
+        'To resume, run the same command again; the agent continues from the files already changed.\n' +
+          `To roll back, stash the changes: ${chalk.cyan('git stash push --include-untracked')}`,
+      );
+    }
+    if (checkpointPath) clack.log.info(chalk.dim(`Checkpoint saved to ${checkpointPath}`));
+    clack.outro(changedFiles.length > 0 ? 'Review or discard the changes above before rerunning' : 'No files changed');
+  };
+
   private handleError = ({ message, stack }: InstallerEvents['error']): void => {
     this.stopSpinner('Error');
     this.stopAgentUpdates();
@@ -417,13 +691,23 @@ export class CLIAdapter implements InstallerAdapter {
     }
   };
 
-  private handleBranchPrompt = async ({ branch }: InstallerEvents['branch:prompt']): Promise<void> => {
+  private handleBranchPrompt = async ({
+    branch,
+    reason,
+    newBranch,
+    newBranchExists,
+  }: InstallerEvents['branch:prompt']): Promise<void> => {
     this.isPromptActive = true;
+    const onBranch = `You are on ${chalk.bold(branch)}${reason ? ` (protected: ${reason})` : ''}`;
     const choice = await clack.select({
-      message: `You are on ${chalk.bold(branch)}. Create a feature branch?`,
+      message: newBranchExists
+        ? `${onBranch}, and ${chalk.bold(newBranch)} already exists. Switch to it?`
+        : `${onBranch}. Create a feature branch?`,
+      id: 'branch',
       options: [
-        { value: 'create', label: 'Create feat/add-workos-authkit' },
-        { value: 'continue', label: 'Continue on current branch' },
+        ...(newBranchExists ? [{ value: 'reuse', label: `Switch to ${newBranch}` }] : []),
+        { value: 'create', label: newBranchExists ? `Create a new ${newBranch}-<n>` : `Create ${newBranch}` },
+        { value: 'continue', label: `Continue on ${branch} (changes won't be committed without --allow-main)` },
         { value: 'cancel', label: 'Cancel' },
       ],
     });
@@ -432,6 +716,8 @@ export class CLIAdapter implements InstallerAdapter {
 
     if (clack.isCancel(choice) || choice === 'cancel') {
       this.sendEvent({ type: 'BRANCH_CANCEL' });
+    } else if (choice === 'reuse') {
+      this.sendEvent({ type: 'BRANCH_REUSE' });
     } else if (choice === 'create') {
       this.sendEvent({ type: 'BRANCH_CREATE' });
     } else {
@@ -439,8 +725,8 @@ export class CLIAdapter implements InstallerAdapter {
     }
   };
 
-  private handleBranchCreated = ({ branch }: InstallerEvents['branch:created']): void => {
-    this.queueableLog(() => clack.log.success(`Created branch ${chalk.bold(branch)}`));
+  private handleBranchCreated = ({ branch, reused }: InstallerEvents['branch:created']): void => {
+    this.queueableLog(() => clack.log.success(`${reused ? 'Switched to' : 'Created'} branch ${chalk.bold(branch)}`));
   };
 
   // ===== Post-install Event Handlers =====
@@ -449,11 +735,22 @@ export class CLIAdapter implements InstallerAdapter {
     this.debugLog(`Post-install: ${files.length} changed files detected`);
   };
 
+  private handleCommitBlocked = ({ branch, reason }: InstallerEvents['postinstall:commit:blocked']): void => {
+    this.queueableLog(() =>
+      clack.log.warn(
+        `Not committing to ${chalk.bold(branch)}: ${reason}.\n` +
+          `Create a feature branch (${chalk.cyan('git switch -c <name>')}) and commit there, ` +
+          `or re-run with ${chalk.cyan('--allow-main')} (or set ${chalk.cyan('git config workos.allowMain true')}).`,
+      ),
+    );
+  };
+
   private handleCommitPrompt = async (): Promise<void> => {
     this.isPromptActive = true;
     const confirmed = await clack.confirm({
       message: 'Commit the changes?',
       initialValue: true,
+      id: 'commit',
     });
     this.isPromptActive = false;
     this.flushPendingLogs();
@@ -483,6 +780,7 @@ export class CLIAdapter implements InstallerAdapter {
     const confirmed = await clack.confirm({
       message: 'Create a pull request?',
       initialValue: true,
+      id: 'pull-request',
     });
     this.isPromptActive = false;
     this.flushPendingLogs();
@@ -506,9 +804,13 @@ export class CLIAdapter implements InstallerAdapter {
     }
   };
 
-  private handlePrSuccess = ({ url }: InstallerEvents['postinstall:pr:success']): void => {
-    this.stopSpinner('PR created');
-    clack.log.success(`Pull request created: ${chalk.cyan(url)}`);
+  private handlePrSuccess = ({ url, updated }: InstallerEvents['postinstall:pr:success']): void => {
+    this.stopSpinner(updated ? 'PR updated' : 'PR created');
+    clack.log.success(`Pull request ${updated ? 'updated' : 'created'}: ${chalk.cyan(url)}`);
+  };
+
+  private handlePrSkipped = ({ reason }: InstallerEvents['postinstall:pr:skipped']): void => {
+    clack.log.warn(`Not opening a pull request: ${reason}`);
   };
 
   private handlePrFailed = ({ error }: InstallerEvents['postinstall:pr:failed']): void => {
@@ -516,13 +818,18 @@ export class CLIAdapter implements InstallerAdapter {
     clack.log.error(`PR creation failed: ${error}`);
   };
 
+  private handlePushSuccess = ({ branch }: InstallerEvents['postinstall:push:success']): void => {
+    this.stopSpinner('Pushed');
+    clack.log.success(`Pushed branch ${chalk.bold(branch)}`);
+  };
+
   private handlePushFailed = ({ error }: InstallerEvents['postinstall:push:failed']): void => {
     this.stopSpinner('Push failed');
     clack.log.error(`Push failed: ${error}`);
   };
 
   private handleManualInstructions = ({ instructions }: InstallerEvents['postinstall:manual']): void => {
-    clack.log.info('GitHub CLI not found. Manual steps:');
+    clack.log.info('Open the pull request yourself:');
     console.log(chalk.dim(instructions));
   };
 }
@@ -1,6 +1,7 @@
 import type { InstallerAdapter, AdapterConfig } from './types.js';
 import type { InstallerEventEmitter, InstallerEvents } from '../events.js';
-import { renderCompletionSummary } from '../../utils/summary-box.js';
+import { buildRunSummary, formatRunSummary } from '../../utils/run-summary.js';
+import { addAgentRun, type RunUsage } from '../agent-usage.js';
 
 /**
  * Dashboard adapter that renders wizard events via Ink/React TUI.
@@ -13,7 +14,9 @@ export class DashboardAdapter implements InstallerAdapter {
   private sendEvent: AdapterConfig['sendEvent'];
   private cleanup: (() => void) | null = null;
   private isStarted = false;
-  private completionData: { success: boolean; summary?: string } | null = null;
+  private completionData: { success: boolean; summary?: string; usage?: RunUsage } | null = null;
+  private startedAt = Date.now();
+  private usage: RunUsage | undefined;
 
   constructor(config: AdapterConfig) {
     this.emitter = config.emitter;
@@ -23,6 +26,7 @@ export class DashboardAdapter implements InstallerAdapter {
   async start(): Promise<void> {
     if (this.isStarted) return;
     this.isStarted = true;
+    this.startedAt = Date.now();
 
     // Dynamic imports to avoid loading Ink when not needed
     const { render } = await import('ink');
@@ -51,14 +55,20 @@ export class DashboardAdapter implements InstallerAdapter {
     this.emitter.on('credentials:response', this.handleCredentialsResponse);
 
     // Track completion for post-exit summary
+    this.emitter.on('agent:usage', this.handleAgentUsage);
     this.emitter.on('complete', this.handleComplete);
   }
 
+  private handleAgentUsage = (run: InstallerEvents['agent:usage']): void => {
+    this.usage = addAgentRun(this.usage, run);
+  };
+
   /**
    * Capture completion data for display after exit.
    */
   private handleComplete = ({ success, summary }: InstallerEvents['complete']): void => {
-    this.completionData = { success, summary };
+    const usage = this.usage && { ...this.usage, durationMs: Date.now() - this.startedAt };
+    this.completionData = { success, summary, usage };
   };
 
   async stop(): Promise<void> {
@@ -67,6 +77,7 @@ export class DashboardAdapter implements InstallerAdapter {
     // Unsubscribe from events
     this.emitter.off('confirm:response', this.handleConfirmResponse);
     this.emitter.off('credentials:response', this.handleCredentialsResponse);
+    this.emitter.off('agent:usage', this.handleAgentUsage);
     this.emitter.off('complete', this.handleComplete);
 
     // Run cleanup (unmount Ink, exit fullscreen)
@@ -75,7 +86,7 @@ export class DashboardAdapter implements InstallerAdapter {
 
     if (this.completionData) {
       console.log();
-      console.log(renderCompletionSummary(this.completionData.success, this.completionData.summary));
+      console.log(formatRunSummary(buildRunSummary(this.completionData)));
       console.log();
     }
 
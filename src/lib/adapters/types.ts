@@ -1,4 +1,6 @@
 import type { InstallerEventEmitter } from '../events.js';
+import type { MigrationContext } from '../../migrate/types.js';
+import type { SummaryFormat } from '../../utils/run-summary.js';
 
 /**
  * Configuration passed to adapter constructors.
@@ -15,6 +17,12 @@ export interface AdapterConfig {
 
   /** Enable verbose debug output (stack traces, etc.) */
   debug?: boolean;
+
+  /** How to print the end-of-run summary (default: table) */
+  summaryFormat?: SummaryFormat;
+
+  /** Set for `workos migrate`, so the summary can report what was migrated */
+  migration?: MigrationContext;
 }
 
 /**
@@ -0,0 +1,63 @@
+import { describe, it, expect } from 'vitest';
+import type { MigrationContext } from '../migrate/types.js';
+import {
+  budgetWarning,
+  estimateAgentRun,
+  formatEstimate,
+  parseTokenCount,
+  plannedFiles,
+  totalTokens,
+} from './agent-estimate.js';
+import { makeFinding } from '../migrate/findings.test-utils.js';
+
+describe('agent estimate', () => {
+  it('counts the migration files left in, or a typical install', () => {
+    const migration = {
+      findings: [
+        makeFinding({ file: 'a.ts' }),
+        makeFinding({ file: 'a.ts' }),
+        makeFinding({ file: 'b.ts' }),
+        makeFinding({ file: 'db.ts', outOfScope: true }),
+      ],
+      excludedFiles: ['b.ts'],
+    } as MigrationContext;
+
+    expect(plannedFiles(migration)).toBe(1);
+    expect(plannedFiles(undefined)).toBe(6);
+  });
+
+  it('grows with the plan and the prompt', () => {
+    const small = estimateAgentRun({ prompt: 'x'.repeat(4000), files: 2, model: 'claude-sonnet-4-5' });
+    const large = estimateAgentRun({ prompt: 'x'.repeat(40000), files: 20, model: 'claude-sonnet-4-5' });
+
+    expect(small.calls).toBe(16);
+    expect(large.calls).toBeGreaterThan(small.calls);
+    expect(totalTokens(large)).toBeGreaterThan(totalTokens(small));
+    expect(large.costUsd!).toBeGreaterThan(small.costUsd!);
+    expect(large.durationMs).toBeGreaterThan(small.durationMs);
+  });
+
+  it('leaves the cost out for a model without a known price', () => {
+    const estimate = estimateAgentRun({ prompt: 'hi', files: 1, model: 'local-model' });
+
+    expect(estimate.costUsd).toBeNull();
+    expect(formatEstimate(estimate)).toMatch(/^~13 agent calls, ~[\d.]+k tokens, ~2 minutes for 1 file$/);
+  });
+
+  it('warns only when the estimate is over the budget', () => {
+    const estimate = estimateAgentRun({ prompt: 'hi', files: 1 });
+
+    expect(budgetWarning(estimate, undefined)).toBeNull();
+    expect(budgetWarning(estimate, totalTokens(estimate))).toBeNull();
+    expect(budgetWarning(estimate, 1000)).toContain('over the --max-tokens budget of 1.0k');
+  });
+
+  it.each([
+    ['500000', 500_000],
+    ['500k', 500_000],
+    ['1.5M', 1_500_000],
+    ['lots', null],
+  ])('parses --max-tokens %s', (value, expected) => {
+    expect(parseTokenCount(value)).toBe(expected);
+  });
+});
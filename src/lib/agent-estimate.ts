@@ -0,0 +1,107 @@
+/**
+ * Pre-flight estimate of an agent run: how many model calls it will make,
+ * roughly how many tokens and dollars that is, and how long it will take.
+ *
+ * The estimate is a rule of thumb from the size of the plan (the files a
+ * migration touches, or a typical install) and the prompt, not a promise:
+ * each call re-reads the conversation so far, most of it from the prompt
+ * cache, and validation retries or verification repairs add more. It is
+ * printed before the first agent run, checked against `--max-tokens`, and
+ * confirmed interactively.
+ */
+
+import type { InstallerOptions } from '../utils/types.js';
+import type { MigrationContext } from '../migrate/types.js';
+import { migrationFiles } from '../migrate/selection.js';
+import { isNonInteractiveEnvironment } from '../utils/environment.js';
+import { estimateCost, formatTokens } from './agent-usage.js';
+
+/** Calls any run makes: reading the project, loading the skill, installing the SDK, building */
+const BASE_CALLS = 10;
+/** Reading, editing and re-checking each file */
+const CALLS_PER_FILE = 3;
+/** Files a fresh install typically writes (middleware, callback route, provider, env) */
+const INSTALL_FILES = 6;
+/** System prompt, tool definitions and skill sent with every call */
+const BASE_CONTEXT_TOKENS = 12_000;
+/** Tool results each call adds to the conversation */
+const TOKENS_ADDED_PER_CALL = 1_500;
+const OUTPUT_TOKENS_PER_CALL = 400;
+const MS_PER_CALL = 8_000;
+/** Rough characters per token for English prose and code */
+const CHARS_PER_TOKEN = 4;
+
+export interface AgentEstimate {
+  /** Files the plan expects the agent to change */
+  files: number;
+  calls: number;
+  /** Input tokens, cached and uncached */
+  inputTokens: number;
+  outputTokens: number;
+  /** null when the model's price isn't known */
+  costUsd: number | null;
+  durationMs: number;
+}
+
+/** Files the agent will change: the migration's files left in, or a typical install */
+export function plannedFiles(migration: MigrationContext | undefined): number {
+  if (!migration) return INSTALL_FILES;
+  const excluded = new Set(migration.excludedFiles ?? []);
+  return migrationFiles(migration.findings).filter(({ file }) => !excluded.has(file)).length;
+}
+
+export function estimateAgentRun(input: { prompt: string; files: number; model?: string }): AgentEstimate {
+  const calls = BASE_CALLS + CALLS_PER_FILE * input.files;
+  const firstCall = BASE_CONTEXT_TOKENS + Math.ceil(input.prompt.length / CHARS_PER_TOKEN);
+  // Call i sends the first call's context plus everything the i calls before it added
+  const inputTokens = calls * firstCall + (TOKENS_ADDED_PER_CALL * calls * (calls - 1)) / 2;
+  // What each call adds is written to the prompt cache once and read back by every later call
+  const cacheWrite = firstCall + TOKENS_ADDED_PER_CALL * (calls - 1);
+  const outputTokens = calls * OUTPUT_TOKENS_PER_CALL;
+  const costUsd = estimateCost({
+    model: input.model,
+    input: 0,
+    output: outputTokens,
+    cacheRead: inputTokens - cacheWrite,
+    cacheWrite,
+  });
+  return { files: input.files, calls, inputTokens, outputTokens, costUsd, durationMs: calls * MS_PER_CALL };
+}
+
+/** `--max-tokens` accepts `500000`, `500k` or `1.5m` */
+export function parseTokenCount(value: string): number | null {
+  const match = /^(\d+(?:\.\d+)?)([km])?$/i.exec(value.trim());
+  if (!match) return null;
+  const multiplier = { k: 1_000, m: 1_000_000 }[match[2]?.toLowerCase() as 'k' | 'm'] ?? 1;
+  return Math.round(Number(match[1]) * multiplier);
+}
+
+export function totalTokens(estimate: AgentEstimate): number {
+  return estimate.inputTokens + estimate.outputTokens;
+}
+
+/** "~34 agent calls, ~1.1M tokens (~$0.92), ~5 minutes for 8 files" */
+export function formatEstimate(estimate: AgentEstimate): string {
+  const minutes = Math.max(1, Math.round(estimate.durationMs / 60_000));
+  const cost = estimate.costUsd === null ? '' : ` (~$${estimate.costUsd.toFixed(2)})`;
+  const files = `${estimate.files} ${estimate.files === 1 ? 'file' : 'files'}`;
+  return (
+    `~${estimate.calls} agent calls, ~${formatTokens(totalTokens(estimate))} tokens${cost}, ` +
+    `~${minutes} ${minutes === 1 ? 'minute' : 'minutes'} for ${files}`
+  );
+}
+
+/** The budget warning, or null when the estimate fits (or there is no budget) */
+export function budgetWarning(estimate: AgentEstimate, maxTokens: number | undefined): string | null {
+  if (maxTokens === undefined || totalTokens(estimate) <= maxTokens) return null;
+  return (
+    `The estimate is over the --max-tokens budget of ${formatTokens(maxTokens)}. ` +
+    'Raise the budget, or leave files out of a migration, before continuing.'
+  );
+}
+
+/** Whether to ask before the run: not with --yes, in CI, or when nobody can answer (a --machine driver can) */
+export function shouldConfirmEstimate(options: Pick<InstallerOptions, 'yes' | 'ci' | 'events' | 'machine'>): boolean {
+  if (options.yes || options.ci || options.events === 'ndjson') return false;
+  return Boolean(options.machine) || !isNonInteractiveEnvironment();
+}
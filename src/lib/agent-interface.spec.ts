@@ -68,9 +68,17 @@ vi.mock('../utils/urls.js', () => ({
   getLlmGatewayUrlFromHost: vi.fn(() => 'http://localhost:8000'),
 }));
 
-import { runAgent, type RetryConfig } from './agent-interface.js';
+import {
+  _resetAgentEstimate,
+  AgentErrorType,
+  agentRunError,
+  runAgent,
+  sdkErrorType,
+  type RetryConfig,
+} from './agent-interface.js';
 import { InstallerEventEmitter } from './events.js';
 import type { InstallerOptions } from '../utils/types.js';
+import { toInstallerError } from '../utils/errors.js';
 
 /**
  * Create a mock SDK response that consumes the prompt stream and yields
@@ -131,6 +139,8 @@ function makeOptions(overrides: Partial<InstallerOptions> = {}): InstallerOption
     local: true,
     ci: false,
     skipAuth: true,
+    // Skips the pre-flight confirmation; see the pre-flight tests below
+    yes: true,
     ...overrides,
   };
 }
@@ -265,4 +275,99 @@ describe('runAgent retry loop', () => {
     // Should have been called once, threw, treated as passed
     expect(validateAndFormat).toHaveBeenCalledTimes(1);
   });
+
+  it('reports token usage across retries', async () => {
+    mockQuery.mockImplementation(createMockSDKResponse([{ text: 'First attempt' }, { text: 'Fixed' }]));
+
+    const validateAndFormat = vi.fn().mockResolvedValueOnce('Build failed').mockResolvedValueOnce(null);
+
+    await runAgent(makeAgentConfig(), 'Test prompt', makeOptions(), undefined, emitter, {
+      maxRetries: 2,
+      validateAndFormat,
+    });
+
+    const usageEvents = emittedEvents.filter((e) => e.event === 'agent:usage');
+    expect(usageEvents).toHaveLength(1);
+    expect(usageEvents[0].payload).toMatchObject({
+      backend: 'claude-agent-sdk',
+      tokens: { input: 200, output: 100 },
+      // No reported cost and no price for test-model
+      costUsd: null,
+      retries: 1,
+    });
+  });
+});
+
+describe('runAgent pre-flight estimate', () => {
+  let emitter: InstallerEventEmitter;
+
+  beforeEach(() => {
+    mockQuery.mockReset();
+    mockQuery.mockImplementation(createMockSDKResponse([{ text: 'Done!' }]));
+    emitter = new InstallerEventEmitter();
+    _resetAgentEstimate();
+  });
+
+  it('emits the estimate before the first run only', async () => {
+    const estimates: unknown[] = [];
+    emitter.on('agent:estimate', (payload) => estimates.push(payload));
+
+    await runAgent(makeAgentConfig(), 'Test prompt', makeOptions({ maxTokens: 1000 }), undefined, emitter);
+    await runAgent(makeAgentConfig(), 'Repair prompt', makeOptions(), undefined, emitter);
+
+    expect(estimates).toHaveLength(1);
+    expect(estimates[0]).toMatchObject({
+      estimate: { files: 6, calls: 28 },
+      maxTokens: 1000,
+      warning: expect.stringContaining('--max-tokens'),
+    });
+  });
+
+  it('asks before starting and stops when declined', async () => {
+    emitter.on('prompt:request', ({ id }) => emitter.emit('prompt:response', { id, value: 'Cancel' }));
+
+    await expect(
+      runAgent(makeAgentConfig(), 'Test prompt', makeOptions({ yes: false }), undefined, emitter),
+    ).rejects.toThrow('Cancelled before the agent started');
+    expect(mockQuery).not.toHaveBeenCalled();
+  });
+
+  it('starts the agent when confirmed', async () => {
+    emitter.on('prompt:request', ({ id }) => emitter.emit('prompt:response', { id, value: 'Start the agent' }));
+
+    const result = await runAgent(makeAgentConfig(), 'Test prompt', makeOptions({ yes: false }), undefined, emitter);
+
+    expect(result.error).toBeUndefined();
+    expect(mockQuery).toHaveBeenCalled();
+  });
+});
+
+describe('agent failures', () => {
+  it('tells login, rate limit and refusal failures apart', () => {
+    expect(sdkErrorType('401 authentication_error: invalid bearer token')).toBe(AgentErrorType.AUTH_REQUIRED);
+    expect(sdkErrorType('429 Too Many Requests')).toBe(AgentErrorType.RATE_LIMITED);
+    expect(sdkErrorType('The model stopped with a refusal')).toBe(AgentErrorType.REFUSED);
+    expect(sdkErrorType('overloaded_error')).toBe(AgentErrorType.EXECUTION_ERROR);
+  });
+
+  it('gives each its own error ID and exit code', () => {
+    const errors = [
+      AgentErrorType.AUTH_REQUIRED,
+      AgentErrorType.RATE_LIMITED,
+      AgentErrorType.REFUSED,
+      AgentErrorType.TIMEOUT,
+      AgentErrorType.EXECUTION_ERROR,
+      AgentErrorType.MCP_MISSING,
+    ].map((error) => toInstallerError(agentRunError({ error, errorMessage: 'boom' })));
+
+    expect(errors.map((error) => error.id)).toEqual([
+      'WOS-AGENT-001',
+      'WOS-AGENT-002',
+      'WOS-AGENT-003',
+      'WOS-AGENT-004',
+      'WOS-AGENT-005',
+      'WOS-AGENT-006',
+    ]);
+    expect(errors.map((error) => error.exitCode)).toEqual([6, 10, 11, 124, 1, 1]);
+  });
 });
@@ -4,6 +4,7 @@
  */
 
 import path from 'path';
+import { rmSync } from 'fs';
 import { fileURLToPath } from 'url';
 import { debug, logInfo, logWarn, logError, initLogFile, getLogFilePath } from '../utils/debug.js';
 import type { InstallerOptions } from '../utils/types.js';
@@ -14,9 +15,35 @@ import { getLlmGatewayUrlFromHost } from '../utils/urls.js';
 import { getConfig } from './settings.js';
 import { getCredentials, hasCredentials } from './credentials.js';
 import { ensureValidToken } from './token-refresh.js';
-import type { InstallerEventEmitter } from './events.js';
+import type { InstallerEventEmitter, InstallerEvents } from './events.js';
 import { startCredentialProxy, type CredentialProxyHandle } from './credential-proxy.js';
-import { getAuthkitDomain, getCliAuthClientId } from './settings.js';
+import { readManifest, verifySkill } from './skill-integrity.js';
+import { createLocalSkillPlugin } from './skill-authoring.js';
+import { ChangeReviewer } from './change-preview.js';
+import { isExcludedFile } from '../migrate/selection.js';
+import { relativePosix } from '../utils/paths.js';
+import {
+  CATEGORY_LABELS,
+  formatCategories,
+  inScope,
+  isDependencyCommand,
+  migrationCategory,
+} from '../migrate/scope.js';
+import { getUsageParser, UsageTracker } from './agent-usage.js';
+import { budgetWarning, estimateAgentRun, plannedFiles, shouldConfirmEstimate } from './agent-estimate.js';
+import {
+  AgentAuthRequiredError,
+  AgentRefusedError,
+  InstallerError,
+  RateLimitError,
+  UserCancelledError,
+} from '../utils/errors.js';
+import {
+  createAgentProcessSpawner,
+  DEFAULT_AGENT_IDLE_TIMEOUT_MS,
+  DEFAULT_AGENT_TIMEOUT_MS,
+  startAgentWatchdog,
+} from './agent-process.js';
 
 // File content cache for computing edit diffs
 const fileContentCache = new Map<string, string>();
@@ -24,10 +51,22 @@ const fileContentCache = new Map<string, string>();
 const pendingReads = new Map<string, string>();
 // Track tool start times by tool_use_id for telemetry
 const pendingToolCalls = new Map<string, { toolName: string; startTime: number }>();
+// Track Write/Edit file paths by tool_use_id, to report whether each change landed
+const pendingWrites = new Map<string, string>();
+// Writes refused before they ran (excluded or declined), by file path, with the reason
+const skippedWrites = new Map<string, string>();
 
 // Module-level variable to track proxy handle for cleanup
 let activeProxyHandle: CredentialProxyHandle | null = null;
 
+// The pre-flight estimate is shown before the first agent run only, not before repairs
+let estimateShown = false;
+
+/** @internal For testing only */
+export function _resetAgentEstimate(): void {
+  estimateShown = false;
+}
+
 // Dynamic import cache for ESM module
 let _sdkModule: any = null;
 async function getSDKModule(): Promise<any> {
@@ -64,6 +103,52 @@ export enum AgentErrorType {
   RESOURCE_MISSING = 'INSTALLER_RESOURCE_MISSING',
   /** Agent execution failed (API error, auth error, etc.) */
   EXECUTION_ERROR = 'INSTALLER_EXECUTION_ERROR',
+  /** Agent went idle or ran past its time limit and was stopped */
+  TIMEOUT = 'INSTALLER_TIMEOUT',
+  /** The LLM gateway refused the session; `workos login` is needed */
+  AUTH_REQUIRED = 'INSTALLER_AUTH_REQUIRED',
+  /** The LLM gateway is limiting requests */
+  RATE_LIMITED = 'INSTALLER_RATE_LIMITED',
+  /** The model declined to continue */
+  REFUSED = 'INSTALLER_REFUSED',
+}
+
+const AUTH_ERROR_PATTERN =
+  /\b401\b|unauthori[sz]ed|authentication[_ ]error|invalid (api key|bearer token)|not authenticated|session expired/i;
+const RATE_LIMIT_PATTERN = /\b429\b|rate[_ ]limit|too many requests|usage limit/i;
+const REFUSAL_PATTERN = /\brefusal\b/i;
+
+/** What kind of failure an SDK error message describes */
+export function sdkErrorType(message: string): AgentErrorType {
+  if (AUTH_ERROR_PATTERN.test(message)) return AgentErrorType.AUTH_REQUIRED;
+  if (RATE_LIMIT_PATTERN.test(message)) return AgentErrorType.RATE_LIMITED;
+  if (REFUSAL_PATTERN.test(message)) return AgentErrorType.REFUSED;
+  return AgentErrorType.EXECUTION_ERROR;
+}
+
+/**
+ * The error to throw for a run that returned an error, with its catalog ID:
+ * a login prompt for auth failures, the SDK's message otherwise.
+ */
+export function agentRunError(result: { error?: AgentErrorType; errorMessage?: string }): Error {
+  const message = result.errorMessage || result.error;
+  switch (result.error) {
+    case AgentErrorType.AUTH_REQUIRED:
+      return new AgentAuthRequiredError(
+        `Agent could not authenticate (${message}). Run \`workos login\` to re-authenticate.`,
+      );
+    case AgentErrorType.RATE_LIMITED:
+      return new RateLimitError(`Agent was rate limited (${message}). Wait a few minutes and run again.`);
+    case AgentErrorType.REFUSED:
+      return new AgentRefusedError(`Agent declined to continue (${message}).`);
+    case AgentErrorType.TIMEOUT:
+      return new InstallerError('AGENT_TIMEOUT', `Agent SDK error: ${message}`);
+    case AgentErrorType.MCP_MISSING:
+    case AgentErrorType.RESOURCE_MISSING:
+      return new InstallerError('UNKNOWN_ERROR', `Agent SDK error: ${message}`, { id: 'WOS-AGENT-006' });
+    default:
+      return new InstallerError('UNKNOWN_ERROR', `Agent SDK error: ${message}`, { id: 'WOS-AGENT-005' });
+  }
 }
 
 export type AgentConfig = {
@@ -91,6 +176,9 @@ export type AgentRunConfig = {
   sdkEnv: Record<string, string | undefined>;
 };
 
+/** File-writing tools that --show-diffs / --dry-run review before they run */
+const REVIEWED_TOOLS = ['Write', 'Edit', 'MultiEdit'];
+
 /**
  * Package managers that can be used to run commands.
  * Includes JS and non-JS ecosystem package managers for multi-SDK support.
@@ -362,12 +450,12 @@ export async function initializeAgent(config: AgentConfig, options: InstallerOpt
       // Check/refresh authentication for production (unless skipping auth)
       if (!options.skipAuth && !options.local) {
         if (!hasCredentials()) {
-          throw new Error('Not authenticated. Run `workos login` to authenticate.');
+          throw new AgentAuthRequiredError();
         }
 
         const creds = getCredentials();
         if (!creds) {
-          throw new Error('Not authenticated. Run `workos login` to authenticate.');
+          throw new AgentAuthRequiredError();
         }
 
         // Check if we have refresh token capability and proxy is not disabled
@@ -379,8 +467,6 @@ export async function initializeAgent(config: AgentConfig, options: InstallerOpt
           activeProxyHandle = await startCredentialProxy({
             upstreamUrl: gatewayUrl,
             refresh: {
-              authkitDomain: getAuthkitDomain(),
-              clientId: getCliAuthClientId(),
               refreshThresholdMs: appConfig.proxy.refreshThresholdMs,
               onRefreshSuccess: () => {
                 options.emitter?.emit('status', { message: 'Session extended' });
@@ -415,7 +501,11 @@ export async function initializeAgent(config: AgentConfig, options: InstallerOpt
 
           const refreshResult = await ensureValidToken();
           if (!refreshResult.success) {
-            throw new Error(refreshResult.error || 'Authentication failed');
+            // A token that can't be refreshed needs a new login, whatever the reason
+            const message = refreshResult.error || 'Authentication failed';
+            throw new AgentAuthRequiredError(
+              message.includes('workos login') ? message : `${message}. Run \`workos login\` to re-authenticate.`,
+            );
           }
 
           sdkEnv.ANTHROPIC_BASE_URL = gatewayUrl;
@@ -481,12 +571,69 @@ export async function initializeAgent(config: AgentConfig, options: InstallerOpt
   }
 }
 
+/**
+ * Verify every bundled skill against skills/manifest.json.
+ * @returns An error message, or null when all skills match
+ */
+function checkBundledSkills(skillsDir: string): string | null {
+  const manifest = readManifest(skillsDir);
+  if (!manifest) return `Bundled skills manifest is missing or invalid in ${skillsDir}`;
+
+  for (const skill of Object.keys(manifest.skills)) {
+    const result = verifySkill(skillsDir, skill, manifest);
+    if (!result.ok) {
+      const files = [...result.mismatched, ...result.missing, ...result.unexpected].join(', ');
+      return `Bundled skill "${skill}" does not match its manifest (${files}). Reinstall the CLI.`;
+    }
+  }
+  return null;
+}
+
 /**
  * Execute an agent with the provided prompt and options
  * Handles the full lifecycle via event emissions - adapters handle UI rendering.
  *
  * @returns An object containing any error detected in the agent's output
  */
+/**
+ * Print the estimated calls, tokens and time of the run and, when someone
+ * can answer, ask before starting it. Declining stops the run before the
+ * agent has changed anything.
+ */
+async function confirmAgentEstimate(
+  agentConfig: AgentRunConfig,
+  prompt: string,
+  options: InstallerOptions,
+  emitter?: InstallerEventEmitter,
+): Promise<void> {
+  if (estimateShown) return;
+  estimateShown = true;
+
+  const estimate = estimateAgentRun({ prompt, files: plannedFiles(options.migration), model: agentConfig.model });
+  const warning = budgetWarning(estimate, options.maxTokens) ?? undefined;
+  logInfo('Agent run estimate:', estimate);
+  emitter?.emit('agent:estimate', { estimate, maxTokens: options.maxTokens, ...(warning && { warning }) });
+  if (!emitter || !shouldConfirmEstimate(options)) return;
+
+  const id = 'agent-estimate';
+  const start = 'Start the agent';
+  const answer = await new Promise<string>((resolve) => {
+    const onResponse = ({ id: responseId, value }: InstallerEvents['prompt:response']) => {
+      if (responseId !== id) return;
+      emitter.off('prompt:response', onResponse);
+      resolve(value);
+    };
+    emitter.on('prompt:response', onResponse);
+    emitter.emit('prompt:request', {
+      id,
+      message: warning ? 'Start the agent anyway?' : 'Start the agent?',
+      // Cancelling a prompt picks the first option, so an over-budget run defaults to not starting
+      options: warning ? ['Cancel', start] : [start, 'Cancel'],
+    });
+  });
+  if (answer !== start) throw new UserCancelledError('Cancelled before the agent started');
+}
+
 export async function runAgent(
   agentConfig: AgentRunConfig,
   prompt: string,
@@ -506,6 +653,8 @@ export async function runAgent(
     errorMessage = 'Integration failed',
   } = config ?? {};
 
+  await confirmAgentEstimate(agentConfig, prompt, options, emitter);
+
   const { query } = await getSDKModule();
 
   // Emit progress for adapters to handle (e.g., CLI adapter starts spinner)
@@ -588,58 +737,190 @@ export async function runAgent(
     const pluginPath = path.join(__dirname, '../..');
     logInfo('Loading plugin from:', pluginPath);
 
+    // Skills become agent instructions, so refuse to run with tampered content
+    const skillsDir = path.join(pluginPath, 'skills');
+    const integrityError = checkBundledSkills(skillsDir);
+    if (integrityError) {
+      logError('Skill integrity check failed:', integrityError);
+      return { error: AgentErrorType.EXECUTION_ERROR, errorMessage: integrityError };
+    }
+
+    // `workos skills run`: the unpublished skill is loaded as a second plugin
+    const localPluginPath = options.localSkill ? createLocalSkillPlugin(options.localSkill) : null;
+    if (localPluginPath) logInfo('Loading local skill from:', options.localSkill?.dir);
+
+    // Previewing routes every file write through canUseTool, so writes
+    // must not be pre-approved by permission mode or allowedTools
+    const reviewer =
+      options.showDiffs || options.dryRun
+        ? new ChangeReviewer({
+            cwd: agentConfig.workingDirectory,
+            dryRun: options.dryRun,
+            plan: options.changePlan,
+            diffOnly: Boolean(options.diffOnly),
+            showDiffs: options.showDiffs,
+            interactive: !options.ci,
+            emitter,
+          })
+        : null;
+    // --keep-backups copies each file in canUseTool before the write, so it gates writes too
+    const gated = Boolean(reviewer || options.fileBackups);
+    const gatedTools = options.dryRun ? [...REVIEWED_TOOLS, 'Bash'] : REVIEWED_TOOLS;
+    const allowedTools = gated
+      ? agentConfig.allowedTools.filter((tool) => !gatedTools.includes(tool))
+      : agentConfig.allowedTools;
+
+    // Ctrl-C aborts the agent so it stops editing files
+    const abortController = new AbortController();
+    if (options.abortSignal?.aborted) abortController.abort();
+    options.abortSignal?.addEventListener('abort', () => abortController.abort(), { once: true });
+
+    const watchdog = startAgentWatchdog(
+      {
+        totalMs: options.timeoutMs ?? DEFAULT_AGENT_TIMEOUT_MS,
+        idleMs: options.idleTimeoutMs ?? DEFAULT_AGENT_IDLE_TIMEOUT_MS,
+      },
+      (timeout) => {
+        logError('Agent timed out:', timeout.message);
+        emitter?.emit('agent:timeout', { kind: timeout.kind, message: timeout.message });
+        abortController.abort(timeout);
+      },
+    );
+
+    // Capture stderr from CLI subprocess for debugging
+    const onStderr = (data: string) => {
+      logInfo('CLI stderr:', data);
+      if (options.debug) {
+        debug('CLI stderr:', data);
+      }
+    };
+    const processes = createAgentProcessSpawner(onStderr);
+
     const response = query({
       prompt: createPromptStream(),
       options: {
+        abortController,
         model: agentConfig.model,
         cwd: agentConfig.workingDirectory,
-        permissionMode: 'acceptEdits',
+        permissionMode: gated ? 'default' : 'acceptEdits',
         mcpServers: agentConfig.mcpServers,
         env: agentConfig.sdkEnv,
-        canUseTool: (toolName: string, input: unknown) => {
+        canUseTool: async (toolName: string, input: unknown) => {
           logInfo('canUseTool called:', { toolName, input });
-          const result = installerCanUseTool(toolName, input as Record<string, unknown>);
+          const filePath = (input as { file_path?: unknown }).file_path;
+          const excluded = options.migration?.excludedFiles ?? [];
+          if (
+            REVIEWED_TOOLS.includes(toolName) &&
+            typeof filePath === 'string' &&
+            isExcludedFile(filePath, agentConfig.workingDirectory, excluded)
+          ) {
+            skippedWrites.set(filePath, 'excluded from the migration');
+            return {
+              behavior: 'deny' as const,
+              message: `The user excluded ${filePath} from the migration. Leave it unchanged and continue.`,
+            };
+          }
+          const only = options.migration?.only;
+          if (only && REVIEWED_TOOLS.includes(toolName) && typeof filePath === 'string') {
+            const cwd = agentConfig.workingDirectory;
+            const file = relativePosix(cwd, path.resolve(cwd, filePath));
+            if (!inScope(file, options.migration!)) {
+              skippedWrites.set(filePath, `not in --only ${formatCategories(only)}`);
+              const category = CATEGORY_LABELS[migrationCategory(file, options.migration!)];
+              return {
+                behavior: 'deny' as const,
+                message: `This run changes only ${formatCategories(only)}; ${filePath} is part of the ${category}, which a later run changes. Leave it unchanged and continue.`,
+              };
+            }
+          }
+          const command = (input as { command?: unknown }).command;
+          if (only && !only.includes('deps') && typeof command === 'string' && isDependencyCommand(command)) {
+            return {
+              behavior: 'deny' as const,
+              message: `This run changes only ${formatCategories(only)}; a later run changes the dependencies. Do not install, upgrade or remove packages.`,
+            };
+          }
+          // Time spent answering a review prompt isn't agent time
+          watchdog.pause();
+          const reviewed = await reviewer?.review(toolName, input as Record<string, unknown>);
+          watchdog.resume();
+          if (
+            reviewed?.behavior === 'deny' &&
+            !options.dryRun &&
+            REVIEWED_TOOLS.includes(toolName) &&
+            typeof filePath === 'string'
+          ) {
+            skippedWrites.set(filePath, 'declined in review');
+          }
+          const result = reviewed ?? installerCanUseTool(toolName, input as Record<string, unknown>);
+          if (result.behavior === 'allow' && REVIEWED_TOOLS.includes(toolName) && typeof filePath === 'string') {
+            options.fileBackups?.backup(path.resolve(agentConfig.workingDirectory, filePath));
+          }
           logInfo('canUseTool result:', result);
-          return Promise.resolve(result);
+          return result;
         },
         tools: { type: 'preset', preset: 'claude_code' },
-        allowedTools: agentConfig.allowedTools,
-        plugins: [{ type: 'local', path: pluginPath }],
-        // Capture stderr from CLI subprocess for debugging
-        stderr: (data: string) => {
-          logInfo('CLI stderr:', data);
-          if (options.debug) {
-            debug('CLI stderr:', data);
-          }
-        },
+        allowedTools,
+        plugins: [
+          { type: 'local', path: pluginPath },
+          ...(localPluginPath ? [{ type: 'local' as const, path: localPluginPath }] : []),
+        ],
+        stderr: onStderr,
+        spawnClaudeCodeProcess: processes.spawnAgentProcess,
       },
     });
 
     // Process the async generator
     let sdkError: string | undefined;
-    for await (const message of response) {
-      const messageError = handleSDKMessage(message, options, collectedText, emitter);
-      if (messageError) {
-        sdkError = messageError;
-      }
-      if (message.type === 'result') {
-        resolveCurrentTurn();
-      }
-      try {
-        onMessage?.(message);
-      } catch {
-        /* non-critical */
+    const usage = new UsageTracker(getUsageParser('claude-agent-sdk'));
+    try {
+      for await (const message of response) {
+        watchdog.activity();
+        usage.record(message);
+        const messageError = handleSDKMessage(message, options, collectedText, emitter);
+        if (messageError) {
+          sdkError = messageError;
+        }
+        if (message.type === 'result') {
+          resolveCurrentTurn();
+        }
+        try {
+          onMessage?.(message);
+        } catch {
+          /* non-critical */
+        }
       }
+    } catch (error) {
+      // Aborting for a timeout ends the stream with an abort error
+      if (!watchdog.timedOut()) throw error;
+    } finally {
+      watchdog.stop();
+      // Nothing the agent started may outlive the run
+      await processes.exited();
+      if (localPluginPath) rmSync(localPluginPath, { recursive: true, force: true });
+    }
+
+    if (options.dryRun && reviewer) {
+      emitter?.emit('change:summary', { text: reviewer.formatSummary() });
     }
 
     const durationMs = Date.now() - startTime;
     const outputText = collectedText.join('\n');
 
+    const runUsage = usage.finish(durationMs, retryCount);
+    logInfo(`Agent usage (${runUsage.backend}):`, JSON.stringify(runUsage));
+    emitter?.emit('agent:usage', runUsage);
+
+    const timeout = watchdog.timedOut();
+    if (timeout) {
+      return { error: AgentErrorType.TIMEOUT, errorMessage: timeout.message, retryCount };
+    }
+
     // Check for SDK errors first (e.g., API errors, auth failures)
     // Return error type + message - caller decides whether to throw or emit events
     if (sdkError) {
       logError('Agent SDK error:', sdkError);
-      return { error: AgentErrorType.EXECUTION_ERROR, errorMessage: sdkError };
+      return { error: sdkErrorType(sdkError), errorMessage: sdkError };
     }
 
     // Check for error markers in the agent's output
@@ -686,6 +967,29 @@ export async function runAgent(
   }
 }
 
+/** First line of a tool result's text, for saying why a write failed */
+function toolResultText(content: unknown): string {
+  let text = typeof content === 'string' ? content : '';
+  if (Array.isArray(content)) {
+    for (const item of content) {
+      if (item?.type === 'text' && item.text) text += item.text;
+    }
+  }
+  const line = text.replace(/<\/?tool_use_error>/g, '').trim().split('\n')[0];
+  return line.length > 200 ? `${line.slice(0, 199)}…` : line;
+}
+
+/** Whether a Write/Edit landed, from its tool result */
+function writeResult(path: string, block: { is_error?: unknown; content?: unknown }): InstallerEvents['file:result'] {
+  if (block.is_error !== true) return { path, status: 'succeeded' };
+  const skipped = skippedWrites.get(path);
+  if (skipped) {
+    skippedWrites.delete(path);
+    return { path, status: 'skipped', reason: skipped };
+  }
+  return { path, status: 'failed', reason: toolResultText(block.content) || 'the tool reported an error' };
+}
+
 /**
  * Handle SDK messages and emit events for adapters to render.
  * @returns Error message if this was an error result, undefined otherwise
@@ -748,6 +1052,10 @@ function handleSDKMessage(
               pendingToolCalls.set(toolUseId, { toolName, startTime: Date.now() });
             }
 
+            if (REVIEWED_TOOLS.includes(toolName) && toolUseId && typeof input?.file_path === 'string') {
+              pendingWrites.set(toolUseId, input.file_path);
+            }
+
             // Emit file:write event for Write tool
             if (toolName === 'Write' && input) {
               const filePath = input.file_path as string;
@@ -782,6 +1090,9 @@ function handleSDKMessage(
           }
         }
       }
+      if (message.message?.stop_reason === 'refusal') {
+        return 'The model stopped with a refusal';
+      }
       break;
     }
 
@@ -804,6 +1115,12 @@ function handleSDKMessage(
               pendingToolCalls.delete(toolUseId);
             }
 
+            const writtenPath = pendingWrites.get(toolUseId);
+            if (writtenPath) {
+              emitter?.emit('file:result', writeResult(writtenPath, block));
+              pendingWrites.delete(toolUseId);
+            }
+
             const filePath = pendingReads.get(toolUseId);
             if (filePath) {
               // Extract content from the tool result
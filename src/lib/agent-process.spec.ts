@@ -0,0 +1,88 @@
+import { describe, it, expect, afterEach, vi } from 'vitest';
+import { spawn } from 'node:child_process';
+import { parseDuration, startAgentWatchdog, stopProcessGroup, type AgentTimeoutError } from './agent-process.js';
+
+describe('agent-process', () => {
+  describe('parseDuration', () => {
+    it('parses units and bare minutes', () => {
+      expect(parseDuration('15m')).toBe(15 * 60_000);
+      expect(parseDuration('90s')).toBe(90_000);
+      expect(parseDuration('1h30m')).toBe(90 * 60_000);
+      expect(parseDuration('20')).toBe(20 * 60_000);
+    });
+
+    it('rejects anything else', () => {
+      expect(parseDuration('soon')).toBeNull();
+      expect(parseDuration('15 minutes')).toBeNull();
+      expect(parseDuration('')).toBeNull();
+    });
+  });
+
+  describe('startAgentWatchdog', () => {
+    afterEach(() => {
+      vi.useRealTimers();
+    });
+
+    it('fires the idle timeout when the agent goes quiet', () => {
+      vi.useFakeTimers();
+      const onTimeout = vi.fn();
+      const watchdog = startAgentWatchdog({ totalMs: 60_000, idleMs: 10_000 }, onTimeout);
+
+      vi.advanceTimersByTime(8_000);
+      watchdog.activity();
+      vi.advanceTimersByTime(8_000);
+      expect(onTimeout).not.toHaveBeenCalled();
+
+      vi.advanceTimersByTime(2_000);
+      const error: AgentTimeoutError = onTimeout.mock.calls[0][0];
+      expect(error.kind).toBe('idle');
+      expect(error.message).toBe('Agent produced no output for 0.2 minutes (idle timeout)');
+      expect(watchdog.timedOut()).toBe(error);
+    });
+
+    it('fires the total timeout even while the agent is busy', () => {
+      vi.useFakeTimers();
+      const onTimeout = vi.fn();
+      const watchdog = startAgentWatchdog({ totalMs: 30_000, idleMs: 10_000 }, onTimeout);
+
+      for (let i = 0; i < 6; i++) {
+        vi.advanceTimersByTime(5_000);
+        watchdog.activity();
+      }
+
+      expect(onTimeout).toHaveBeenCalledTimes(1);
+      expect(onTimeout.mock.calls[0][0].kind).toBe('total');
+      expect(onTimeout.mock.calls[0][0].message).toContain('total timeout');
+    });
+
+    it("doesn't count paused time", () => {
+      vi.useFakeTimers();
+      const onTimeout = vi.fn();
+      const watchdog = startAgentWatchdog({ totalMs: 30_000, idleMs: 10_000 }, onTimeout);
+
+      vi.advanceTimersByTime(5_000);
+      watchdog.pause();
+      vi.advanceTimersByTime(60_000);
+      watchdog.resume();
+      vi.advanceTimersByTime(9_000);
+      expect(onTimeout).not.toHaveBeenCalled();
+
+      watchdog.stop();
+      vi.advanceTimersByTime(60_000);
+      expect(onTimeout).not.toHaveBeenCalled();
+    });
+  });
+
+  describe.skipIf(process.platform === 'win32')('stopProcessGroup', () => {
+    it('stops the process and the children it started', async () => {
+      const child = spawn('sh', ['-c', 'sleep 30 & sleep 30; wait'], { detached: true, stdio: 'ignore' });
+      await new Promise((resolve) => child.once('spawn', resolve));
+
+      await stopProcessGroup(child, 1000);
+
+      expect(child.exitCode !== null || child.signalCode !== null).toBe(true);
+      // The whole group is gone, including the backgrounded sleep
+      expect(() => process.kill(-child.pid!, 0)).toThrow();
+    });
+  });
+});
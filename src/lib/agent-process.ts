@@ -0,0 +1,206 @@
+/**
+ * Supervision of the agent's CLI subprocess.
+ *
+ * The agent runs in its own process group so that stopping it (Ctrl-C,
+ * SIGTERM or a timeout) takes down everything it started, not just the
+ * top-level `claude` process, and the installer waits for the group to exit
+ * before it writes the checkpoint.
+ *
+ * Two timeouts guard each agent run: an idle timeout when the agent sends
+ * nothing for a while (a hung tool or a stuck session), and a total timeout
+ * on the run as a whole.
+ */
+
+import { spawn, type ChildProcess } from 'node:child_process';
+
+export const DEFAULT_AGENT_TIMEOUT_MS = 30 * 60 * 1000;
+export const DEFAULT_AGENT_IDLE_TIMEOUT_MS = 10 * 60 * 1000;
+
+/** How long a stopped agent gets to exit after SIGTERM before SIGKILL */
+const KILL_GRACE_MS = 5000;
+
+const DURATION_UNITS: Record<string, number> = { ms: 1, s: 1000, m: 60 * 1000, h: 60 * 60 * 1000 };
+
+/**
+ * Parse a duration like `15m`, `90s`, `1h` or `1h30m` into milliseconds.
+ * A bare number is minutes. Returns null when the value isn't a duration.
+ */
+export function parseDuration(value: string): number | null {
+  const trimmed = value.trim().toLowerCase();
+  if (/^\d+(\.\d+)?$/.test(trimmed)) return Number(trimmed) * DURATION_UNITS.m;
+  if (!/^(\d+(\.\d+)?(ms|s|m|h))+$/.test(trimmed)) return null;
+  let ms = 0;
+  for (const [, amount, , unit] of trimmed.matchAll(/(\d+(\.\d+)?)(ms|s|m|h)/g)) {
+    ms += Number(amount) * DURATION_UNITS[unit];
+  }
+  return ms;
+}
+
+/** `90000` -> `1.5 minutes`, `600000` -> `10 minutes` */
+export function formatMinutes(ms: number): string {
+  const minutes = Math.round((ms / 60_000) * 10) / 10;
+  return `${minutes} ${minutes === 1 ? 'minute' : 'minutes'}`;
+}
+
+export type AgentTimeoutKind = 'idle' | 'total';
+
+export class AgentTimeoutError extends Error {
+  constructor(
+    readonly kind: AgentTimeoutKind,
+    readonly limitMs: number,
+  ) {
+    super(
+      kind === 'idle'
+        ? `Agent produced no output for ${formatMinutes(limitMs)} (idle timeout)`
+        : `Agent run exceeded the ${formatMinutes(limitMs)} limit (total timeout); raise it with --timeout`,
+    );
+    this.name = 'AgentTimeoutError';
+  }
+}
+
+export interface AgentWatchdog {
+  /** The agent sent something; restarts the idle timer */
+  activity(): void;
+  /** Stop the clocks while the user is being asked something (e.g. reviewing a diff) */
+  pause(): void;
+  resume(): void;
+  /** The timeout that fired, if any */
+  timedOut(): AgentTimeoutError | null;
+  stop(): void;
+}
+
+/**
+ * Call `onTimeout` once when the agent goes idle for `idleMs` or runs longer
+ * than `totalMs` overall. Paused time doesn't count toward either.
+ */
+export function startAgentWatchdog(
+  { totalMs, idleMs }: { totalMs: number; idleMs: number },
+  onTimeout: (error: AgentTimeoutError) => void,
+): AgentWatchdog {
+  let fired: AgentTimeoutError | null = null;
+  let totalRemaining = totalMs;
+  let runningSince = Date.now();
+  let paused = false;
+  let totalTimer: NodeJS.Timeout | undefined;
+  let idleTimer: NodeJS.Timeout | undefined;
+
+  const fire = (kind: AgentTimeoutKind, limitMs: number) => {
+    if (fired) return;
+    fired = new AgentTimeoutError(kind, limitMs);
+    clearTimeout(totalTimer);
+    clearTimeout(idleTimer);
+    onTimeout(fired);
+  };
+  const armIdle = () => {
+    clearTimeout(idleTimer);
+    idleTimer = setTimeout(() => fire('idle', idleMs), idleMs);
+    idleTimer.unref();
+  };
+  const armTotal = () => {
+    totalTimer = setTimeout(() => fire('total', totalMs), totalRemaining);
+    totalTimer.unref();
+  };
+
+  armTotal();
+  armIdle();
+
+  return {
+    activity() {
+      if (!paused && !fired) armIdle();
+    },
+    pause() {
+      if (paused || fired) return;
+      paused = true;
+      totalRemaining -= Date.now() - runningSince;
+      clearTimeout(totalTimer);
+      clearTimeout(idleTimer);
+    },
+    resume() {
+      if (!paused || fired) return;
+      paused = false;
+      runningSince = Date.now();
+      armTotal();
+      armIdle();
+    },
+    timedOut: () => fired,
+    stop() {
+      clearTimeout(totalTimer);
+      clearTimeout(idleTimer);
+    },
+  };
+}
+
+export interface AgentProcessSpawnOptions {
+  command: string;
+  args: string[];
+  cwd?: string;
+  env: Record<string, string | undefined>;
+  signal: AbortSignal;
+}
+
+/**
+ * Send `signal` to the process's whole group (the process itself on Windows).
+ */
+function signalGroup(child: ChildProcess, signal: NodeJS.Signals): void {
+  if (!child.pid || child.exitCode !== null || child.signalCode !== null) return;
+  try {
+    if (process.platform === 'win32') child.kill(signal);
+    else process.kill(-child.pid, signal);
+  } catch {
+    // Already gone
+  }
+}
+
+/**
+ * Resolves once the process has exited, escalating to SIGKILL when it
+ * ignores SIGTERM for `graceMs`.
+ */
+export function stopProcessGroup(child: ChildProcess, graceMs = KILL_GRACE_MS): Promise<void> {
+  if (child.exitCode !== null || child.signalCode !== null || !child.pid) return Promise.resolve();
+  return new Promise((resolve) => {
+    const kill = setTimeout(() => signalGroup(child, 'SIGKILL'), graceMs);
+    child.once('exit', () => {
+      clearTimeout(kill);
+      resolve();
+    });
+    signalGroup(child, 'SIGTERM');
+  });
+}
+
+/**
+ * Spawns the agent CLI for the SDK in a process group of its own and stops
+ * the group when the SDK's abort signal fires. `exited()` resolves once the
+ * last spawned process is gone.
+ */
+export function createAgentProcessSpawner(onStderr: (data: string) => void) {
+  let current: ChildProcess | null = null;
+  let stopping: Promise<void> = Promise.resolve();
+
+  const spawnAgentProcess = ({ command, args, cwd, env, signal }: AgentProcessSpawnOptions): ChildProcess => {
+    const child = spawn(command, args, {
+      cwd,
+      env,
+      stdio: ['pipe', 'pipe', 'pipe'],
+      // Own group: the terminal's Ctrl-C doesn't reach it directly, and stopping it reaches its children
+      detached: process.platform !== 'win32',
+    });
+    child.stderr?.on('data', (data: Buffer) => onStderr(data.toString()));
+    const onAbort = () => {
+      stopping = stopProcessGroup(child);
+    };
+    if (signal.aborted) onAbort();
+    else signal.addEventListener('abort', onAbort, { once: true });
+    child.once('exit', () => signal.removeEventListener('abort', onAbort));
+    current = child;
+    return child;
+  };
+
+  return {
+    spawnAgentProcess,
+    /** Stop whatever is still running and wait for it to exit */
+    async exited(): Promise<void> {
+      if (current) await stopProcessGroup(current);
+      await stopping;
+    },
+  };
+}
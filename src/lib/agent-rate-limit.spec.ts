@@ -0,0 +1,51 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import {
+  _resetAgentBackoff,
+  AGENT_BACKOFF_FILE_ENV,
+  agentBackoffDelay,
+  noteAgentRateLimit,
+  noteAgentSuccess,
+} from './agent-rate-limit.js';
+
+describe('agent-rate-limit', () => {
+  const now = 1_000_000;
+  let dir: string;
+
+  beforeEach(() => {
+    _resetAgentBackoff();
+    dir = mkdtempSync(join(tmpdir(), 'agent-backoff-'));
+    delete process.env[AGENT_BACKOFF_FILE_ENV];
+  });
+
+  afterEach(() => {
+    delete process.env[AGENT_BACKOFF_FILE_ENV];
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('waits as long as Retry-After says', () => {
+    expect(noteAgentRateLimit('7', now)).toBe(7_000);
+    expect(agentBackoffDelay(now + 2_000, null)).toBe(5_000);
+    expect(agentBackoffDelay(now + 8_000, null)).toBe(0);
+  });
+
+  it('doubles the wait for each 429 in a row and starts over after a success', () => {
+    expect(noteAgentRateLimit(undefined, now)).toBe(2_000);
+    expect(noteAgentRateLimit(undefined, now)).toBe(4_000);
+    noteAgentSuccess();
+    expect(noteAgentRateLimit(undefined, now)).toBe(2_000);
+  });
+
+  it('shares the wait with other processes through the backoff file', () => {
+    const file = join(dir, 'backoff.json');
+    process.env[AGENT_BACKOFF_FILE_ENV] = file;
+    noteAgentRateLimit('30', now);
+
+    // Another process has none of this one's state, only the file
+    _resetAgentBackoff();
+    expect(agentBackoffDelay(now, file)).toBe(30_000);
+    expect(agentBackoffDelay(now, join(dir, 'missing.json'))).toBe(0);
+  });
+});
@@ -0,0 +1,79 @@
+/**
+ * Rate-limit backoff shared by every agent a run starts.
+ *
+ * The LLM gateway limits the account, not the request, so after one agent
+ * gets a 429 the others would get one too. The credential proxy records
+ * the wait here and holds back every agent request until it's over;
+ * Retry-After decides the wait when the gateway sends it, otherwise it
+ * doubles with each 429 in a row.
+ *
+ * `migrate --modules` runs each module in its own process. The parent points
+ * them all at one file (WORKOS_AGENT_BACKOFF_FILE), so a 429 in one module
+ * pauses the agents of all of them, and the parent doesn't start a queued
+ * module while the pause lasts.
+ */
+
+import { readFileSync } from 'node:fs';
+import { writeFileAtomic } from './atomic-write.js';
+import { parseRetryAfter } from './http-retry.js';
+
+export const AGENT_BACKOFF_FILE_ENV = 'WORKOS_AGENT_BACKOFF_FILE';
+
+const BASE_DELAY_MS = 2_000;
+const MAX_DELAY_MS = 60_000;
+
+/** No agent request is sent before this time, after a 429 */
+let pausedUntil = 0;
+/** 429s in a row, for the backoff when there's no Retry-After */
+let consecutive = 0;
+
+function sharedFile(): string | null {
+  return process.env[AGENT_BACKOFF_FILE_ENV] || null;
+}
+
+function readShared(file: string | null): number {
+  if (!file) return 0;
+  try {
+    const { until } = JSON.parse(readFileSync(file, 'utf-8')) as { until?: unknown };
+    return typeof until === 'number' ? until : 0;
+  } catch {
+    return 0;
+  }
+}
+
+/** How long agent requests are still held back, here or in any process sharing `file` */
+export function agentBackoffDelay(now = Date.now(), file = sharedFile()): number {
+  return Math.max(0, pausedUntil - now, readShared(file) - now);
+}
+
+/**
+ * Record a 429 from the gateway.
+ * @returns How long agent requests are held back, in ms
+ */
+export function noteAgentRateLimit(retryAfter: string | null | undefined, now = Date.now()): number {
+  consecutive++;
+  const seconds = parseRetryAfter(retryAfter, now);
+  const delay = seconds !== undefined ? seconds * 1000 : Math.min(MAX_DELAY_MS, BASE_DELAY_MS * 2 ** (consecutive - 1));
+  pausedUntil = Math.max(pausedUntil, now + delay);
+
+  const file = sharedFile();
+  if (file && pausedUntil > readShared(file)) {
+    try {
+      writeFileAtomic(file, JSON.stringify({ until: pausedUntil }));
+    } catch {
+      // The other processes find out from their own 429s
+    }
+  }
+  return delay;
+}
+
+/** A request got through, so the next 429 starts the backoff over */
+export function noteAgentSuccess(): void {
+  consecutive = 0;
+}
+
+/** @internal For testing only */
+export function _resetAgentBackoff(): void {
+  pausedUntil = 0;
+  consecutive = 0;
+}
@@ -1,5 +1,17 @@
+import { join } from 'node:path';
+import { fileURLToPath } from 'node:url';
 import { SPINNER_MESSAGE, type FrameworkConfig } from './framework-config.js';
-import { validateInstallation, quickCheckValidateAndFormat } from './validation/index.js';
+import {
+  validateInstallation,
+  quickCheckValidateAndFormat,
+  projectCheckValidateAndFormat,
+  formatTouchedFiles,
+  trackTouchedFiles,
+  type TouchedFiles,
+  loadVerificationSpec,
+  runVerificationChecks,
+  type VerificationReport,
+} from './validation/index.js';
 import type { InstallerOptions } from '../utils/types.js';
 import {
   ensurePackageIsInstalled,
@@ -9,11 +21,25 @@ import {
 } from '../utils/clack-utils.js';
 import { analytics } from '../utils/analytics.js';
 import { INSTALLER_INTERACTION_EVENT_NAME } from './constants.js';
-import { initializeAgent, runAgent, type RetryConfig } from './agent-interface.js';
+import {
+  agentRunError,
+  initializeAgent,
+  runAgent,
+  type AgentRunConfig,
+  type RetryConfig,
+} from './agent-interface.js';
 import { uploadEnvironmentVariablesStep } from '../steps/index.js';
 import { autoConfigureWorkOSEnvironment } from './workos-management.js';
 import { detectPort, getCallbackPath } from './port-detection.js';
 import { writeEnvLocal } from './env-writer.js';
+import { protectEnvFile } from './secret-scan.js';
+import { skipsFileWrites } from './diff-only.js';
+import { buildMigrationPrompt } from '../migrate/prompt.js';
+import type { MigrationContext } from '../migrate/types.js';
+import type { ComposeTarget } from './compose.js';
+import { updateModeSection, type ExistingIntegration } from './existing-integration.js';
+import { instructionsSection, type CustomInstructions } from './custom-instructions.js';
+import { relativePosix } from '../utils/paths.js';
 
 /**
  * Universal agent-powered wizard runner.
@@ -57,11 +83,12 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
 
   // Auto-configure WorkOS environment (redirect URI, CORS, homepage)
   // Skip if caller already handled this (prevents duplicate dashboard config output)
-  if (!callerHandledConfig && apiKey && config.environment.requiresApiKey) {
-    const port = detectPort(config.metadata.integration, options.installDir);
+  if (!callerHandledConfig && !options.dryRun && apiKey && config.environment.requiresApiKey) {
+    const port = options.compose?.hostPort ?? detectPort(config.metadata.integration, options.installDir);
     await autoConfigureWorkOSEnvironment(apiKey, config.metadata.integration, port, {
       homepageUrl: options.homepageUrl,
       redirectUri: options.redirectUri,
+      emitter: options.emitter,
     });
   }
 
@@ -69,9 +96,9 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
   const frameworkContext = config.metadata.gatherContext ? await config.metadata.gatherContext(options) : {};
 
   // Write environment variables to .env.local BEFORE agent runs
-  // Skip if caller already handled this (prevents double-writing)
-  if (!callerHandledConfig) {
-    const port = detectPort(config.metadata.integration, options.installDir);
+  // Skip if caller already handled this (prevents double-writing); --diff-only records them in the patch
+  if (!callerHandledConfig && !skipsFileWrites(options)) {
+    const port = options.compose?.hostPort ?? detectPort(config.metadata.integration, options.installDir);
     const callbackPath = getCallbackPath(config.metadata.integration);
     const redirectUri = options.redirectUri || `http://localhost:${port}${callbackPath}`;
 
@@ -79,11 +106,17 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
     const redirectUriKey =
       config.metadata.integration === 'nextjs' ? 'NEXT_PUBLIC_WORKOS_REDIRECT_URI' : 'WORKOS_REDIRECT_URI';
 
-    writeEnvLocal(options.installDir, {
-      ...(apiKey ? { WORKOS_API_KEY: apiKey } : {}),
-      WORKOS_CLIENT_ID: clientId,
-      [redirectUriKey]: redirectUri,
-    });
+    const envFile = options.compose?.envFile ?? '.env.local';
+    writeEnvLocal(
+      options.installDir,
+      {
+        ...(apiKey ? { WORKOS_API_KEY: apiKey } : {}),
+        WORKOS_CLIENT_ID: clientId,
+        [redirectUriKey]: redirectUri,
+      },
+      envFile,
+    );
+    await protectEnvFile(options.installDir, envFile, options);
   }
 
   // Set analytics tags from framework context
@@ -98,8 +131,15 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
     {
       frameworkVersion: frameworkVersion || 'latest',
       typescript: typeScriptDetected,
+      workspacePackage: options.workspaceRoot ? relativePosix(options.workspaceRoot, options.installDir) : undefined,
+      dryRun: options.dryRun,
+      compose: options.compose,
+      skillName: options.localSkill?.name,
     },
     frameworkContext,
+    options.migration,
+    options.existingIntegration,
+    options.customInstructions,
   );
 
   // Initialize and run agent
@@ -113,11 +153,19 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
     options,
   );
 
+  // Formatters (unless --no-format) and linters run on what the agent touched, unless --skip-checks
+  const touched = trackTouchedFiles(options.emitter);
   const retryConfig: RetryConfig | undefined = options.noValidate
     ? undefined
     : {
         maxRetries: options.maxRetries ?? 2,
-        validateAndFormat: quickCheckValidateAndFormat,
+        validateAndFormat: options.skipChecks
+          ? quickCheckValidateAndFormat
+          : projectCheckValidateAndFormat(
+              touched.files,
+              (result) => options.emitter?.emit('checks:formatted', result),
+              { format: !options.noFormat },
+            ),
       };
 
   // Run agent with retry support — agent gets correction prompts on validation failure
@@ -137,8 +185,7 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
   // If agent returned an error, throw so state machine can handle it
   if (agentResult.error) {
     await analytics.shutdown('error');
-    const message = agentResult.errorMessage || agentResult.error;
-    throw new Error(`Agent SDK error: ${message}`);
+    throw agentRunError(agentResult);
   }
 
   // Track retry metrics
@@ -171,6 +218,10 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
     });
   }
 
+  const verification = options.noValidate ? null : await runSkillVerification(config, options, agent);
+  // Last pass covers the final turn and any repair turns
+  await formatAgentEdits(options, touched);
+
   // Build environment variables from WorkOS credentials
   const envVars = config.environment.getEnvVars(apiKey, clientId);
 
@@ -190,6 +241,7 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
   ].filter(Boolean);
 
   const nextSteps = [
+    ...(verification?.checks ?? []).filter((c) => !c.passed).map((c) => `Fix failing check: ${c.name}`),
     ...config.ui.getOutroNextSteps(frameworkContext),
     uploadedEnvVars.length === 0 && config.environment.uploadToHosting
       ? `Upload your WorkOS credentials to your hosting provider`
@@ -203,6 +255,90 @@ export async function runAgentInstaller(config: FrameworkConfig, options: Instal
   return summary;
 }
 
+/**
+ * Apply the project's formatters (prettier, goimports or gofmt) to every
+ * file the agent touched, plus `injected` files the installer wrote itself,
+ * then stop tracking. Skipped with --no-validate, --skip-checks or
+ * --no-format.
+ */
+export async function formatAgentEdits(
+  options: InstallerOptions,
+  touched: TouchedFiles,
+  injected: string[] = [],
+): Promise<void> {
+  touched.stop();
+  if (options.noValidate || options.skipChecks || options.noFormat) return;
+  const result = await formatTouchedFiles(options.installDir, [...injected, ...touched.files()]);
+  if (result.files.length > 0 || result.missing) options.emitter?.emit('checks:formatted', result);
+  if (result.files.length > 0) {
+    analytics.capture(INSTALLER_INTERACTION_EVENT_NAME, {
+      action: 'agent output formatted',
+      files: result.files.length,
+      tools: result.tools.join(','),
+    });
+  }
+}
+
+/**
+ * Run the verification checks the integration's skill declares in
+ * verify.json. When checks fail, the agent gets the failing output and up to
+ * `maxRepairAttempts` (default 1) more turns to fix it; checks re-run after
+ * each. Failing checks don't fail the install, they're reported.
+ *
+ * @returns The last report, or null when the skill declares no checks
+ */
+export async function runSkillVerification(
+  config: FrameworkConfig,
+  options: InstallerOptions,
+  agent: AgentRunConfig,
+): Promise<VerificationReport | null> {
+  const { skillName } = config.metadata;
+  if (!skillName && !options.localSkill) return null;
+  // Bundled skills live at the package root, next to dist/
+  const skillDir =
+    options.localSkill?.dir ?? join(fileURLToPath(new URL('../../skills', import.meta.url)), skillName as string);
+  const spec = loadVerificationSpec(skillDir);
+  if (!spec) return null;
+
+  const maxAttempts = options.maxRepairAttempts ?? 1;
+  const emitter = options.emitter;
+  for (let attempt = 0; ; attempt++) {
+    emitter?.emit('verification:start', { checks: spec.checks.length, attempt });
+    const report = await runVerificationChecks(
+      spec,
+      options.installDir,
+      (check) => emitter?.emit('verification:check', check),
+      { compose: options.compose },
+    );
+    const failed = report.checks.filter((c) => !c.passed).map((c) => c.name);
+    emitter?.emit('verification:complete', { passed: report.passed, failed, attempt, durationMs: report.durationMs });
+
+    if (report.passed || !report.repairPrompt || attempt >= maxAttempts) {
+      analytics.capture(INSTALLER_INTERACTION_EVENT_NAME, {
+        action: 'verification summary',
+        passed: report.passed,
+        repair_attempts: attempt,
+        failed_checks: failed.length,
+      });
+      return report;
+    }
+
+    emitter?.emit('verification:repair', { attempt: attempt + 1, maxAttempts, failed });
+    const repair = await runAgent(
+      agent,
+      report.repairPrompt,
+      options,
+      {
+        spinnerMessage: 'Fixing failed verification checks...',
+        successMessage: 'Repair attempt finished',
+        errorMessage: 'Repair attempt failed',
+      },
+      emitter,
+    );
+    if (repair.error) return report;
+  }
+}
+
 /**
  * Build the integration prompt for the agent.
  * Uses skill-based approach where agent invokes framework-specific skill.
@@ -213,8 +349,19 @@ function buildIntegrationPrompt(
   context: {
     frameworkVersion: string;
     typescript: boolean;
+    /** Package path relative to the monorepo root, when installing into a workspace package */
+    workspacePackage?: string;
+    /** --dry-run: writes and commands are recorded, not executed */
+    dryRun?: boolean;
+    /** Docker Compose service the app runs in */
+    compose?: ComposeTarget;
+    /** Local skill replacing the integration's bundled one (`workos skills run`) */
+    skillName?: string;
   },
   frameworkContext: Record<string, any>,
+  migration?: MigrationContext,
+  existing?: ExistingIntegration,
+  instructions?: CustomInstructions,
 ): string {
   const additionalLines = config.prompts.getAdditionalContextLines
     ? config.prompts.getAdditionalContextLines(frameworkContext)
@@ -223,7 +370,7 @@ function buildIntegrationPrompt(
   const additionalContext =
     additionalLines.length > 0 ? '\n' + additionalLines.map((line) => `- ${line}`).join('\n') : '';
 
-  const skillName = config.metadata.skillName;
+  const skillName = context.skillName ?? config.metadata.skillName;
   if (!skillName) {
     throw new Error(`Framework ${config.metadata.name} missing skillName in config`);
   }
@@ -232,20 +379,41 @@ function buildIntegrationPrompt(
   const redirectUriEnvVar =
     config.metadata.integration === 'nextjs' ? 'NEXT_PUBLIC_WORKOS_REDIRECT_URI' : 'WORKOS_REDIRECT_URI';
 
+  const migrationSection = migration ? `\n\n${buildMigrationPrompt(migration)}` : '';
+
+  const dryRunSection = context.dryRun
+    ? '\n\n## Dry Run\n\nThis is a dry run. File writes and edits are recorded for review but not applied, and ' +
+      'commands are not executed. Make every code change you would make in a real run, exactly once, then stop. ' +
+      'Add dependencies by editing the package manifest directly instead of running the package manager. ' +
+      'Do not try to verify the changes by reading files back or running builds.'
+    : '';
+
+  const workspaceContext = context.workspacePackage
+    ? `\n- Monorepo: this app is the workspace package \`${context.workspacePackage}\`. ` +
+      'Add dependencies to this package (not the workspace root) and keep env and code changes inside it.'
+    : '';
+
+  const envFile = context.compose?.envFile ?? '.env.local';
+  const composeContext = context.compose
+    ? `\n- Docker Compose: the app runs in the \`${context.compose.service}\` service, listening on container port ` +
+      `${context.compose.containerPort} (localhost:${context.compose.hostPort} on the host). ` +
+      `Its env vars come from \`${envFile}\`; don't move them into another env file.`
+    : '';
+
   return `You are integrating WorkOS AuthKit into this ${config.metadata.name} application.
 
 ## Project Context
 
 - Framework: ${config.metadata.name} ${context.frameworkVersion}
-- TypeScript: ${context.typescript ? 'Yes' : 'No'}${additionalContext}
+- TypeScript: ${context.typescript ? 'Yes' : 'No'}${workspaceContext}${composeContext}${additionalContext}
 
 ## Environment
 
-The following environment variables have been configured in .env.local:
+The following environment variables have been configured in ${envFile}:
 - WORKOS_API_KEY
 - WORKOS_CLIENT_ID
 - ${redirectUriEnvVar}
-- WORKOS_COOKIE_PASSWORD
+- WORKOS_COOKIE_PASSWORD${migrationSection}${updateModeSection(existing)}${instructionsSection(instructions)}${dryRunSection}
 
 ## Your Task
 
@@ -0,0 +1,82 @@
+import { describe, it, expect } from 'vitest';
+import { addAgentRun, claudeAgentUsageParser, formatUsage, getUsageParser, UsageTracker } from './agent-usage.js';
+
+function assistant(id: string, usage: Record<string, number>, model = 'claude-sonnet-4-20250514') {
+  return { type: 'assistant', message: { id, model, usage, content: [] } };
+}
+
+describe('agent-usage', () => {
+  describe('UsageTracker', () => {
+    it('counts each API call once and prefers the reported cost', () => {
+      const tracker = new UsageTracker(claudeAgentUsageParser);
+      tracker.record(assistant('msg_1', { input_tokens: 1000, output_tokens: 200, cache_read_input_tokens: 5000 }));
+      // Same call, second content block
+      tracker.record(assistant('msg_1', { input_tokens: 1000, output_tokens: 200, cache_read_input_tokens: 5000 }));
+      tracker.record(assistant('msg_2', { input_tokens: 500, output_tokens: 100 }));
+      tracker.record({ type: 'result', subtype: 'success', total_cost_usd: 0.0123 });
+
+      expect(tracker.finish(60_000, 0)).toEqual({
+        backend: 'claude-agent-sdk',
+        tokens: { input: 1500, output: 300, cacheRead: 5000, cacheWrite: 0 },
+        costUsd: 0.0123,
+        costEstimated: false,
+        durationMs: 60_000,
+        retries: 0,
+      });
+    });
+
+    it('estimates the cost from token prices when none is reported', () => {
+      const tracker = new UsageTracker(claudeAgentUsageParser);
+      tracker.record(assistant('msg_1', { input_tokens: 1_000_000, output_tokens: 100_000 }));
+
+      const usage = tracker.finish(1000, 0);
+      expect(usage.costUsd).toBeCloseTo(4.5);
+      expect(usage.costEstimated).toBe(true);
+    });
+
+    it('leaves usage unknown for backends that report nothing', () => {
+      const tracker = new UsageTracker(getUsageParser('some-other-agent'));
+      tracker.record(assistant('msg_1', { input_tokens: 10, output_tokens: 5 }));
+
+      expect(tracker.finish(1000, 0)).toMatchObject({ tokens: null, costUsd: null });
+    });
+  });
+
+  describe('addAgentRun', () => {
+    it('sums runs and counts later runs as repairs', () => {
+      const run = {
+        backend: 'claude-agent-sdk',
+        tokens: { input: 100, output: 10, cacheRead: 0, cacheWrite: 0 },
+        costUsd: 0.5,
+        costEstimated: false,
+        durationMs: 1000,
+        retries: 1,
+      };
+      const total = addAgentRun(addAgentRun(undefined, run), { ...run, retries: 0 });
+
+      expect(total).toMatchObject({
+        tokens: { input: 200, output: 20 },
+        costUsd: 1,
+        agentRuns: 2,
+        repairAttempts: 2,
+      });
+    });
+  });
+
+  describe('formatUsage', () => {
+    const base = { costEstimated: false, durationMs: 372_000, agentRuns: 1, repairAttempts: 1 };
+
+    it('prints tokens, cost, time and repairs', () => {
+      const tokens = { input: 12_000, output: 12_900, cacheRead: 172_200, cacheWrite: 0 };
+      expect(formatUsage({ ...base, tokens, costUsd: 0.7412, costEstimated: true })).toBe(
+        '184.2k tokens in, 12.9k out, ~$0.74, 6m 12s, 1 repair attempt',
+      );
+    });
+
+    it('says usage is unavailable instead of printing zeros', () => {
+      expect(formatUsage({ ...base, tokens: null, costUsd: null, repairAttempts: 0 })).toBe(
+        'usage unavailable, 6m 12s, 0 repair attempts',
+      );
+    });
+  });
+});
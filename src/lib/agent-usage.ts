@@ -0,0 +1,210 @@
+/**
+ * Token usage and cost of agent runs.
+ *
+ * Each agent backend reports usage in its own message shape, so a small
+ * parser per backend turns messages into token counts. Backends without a
+ * parser (or that report nothing) leave usage unknown, which is reported as
+ * "usage unavailable" rather than zeros.
+ */
+
+export interface TokenCounts {
+  input: number;
+  output: number;
+  /** Input served from the prompt cache */
+  cacheRead: number;
+  /** Input written to the prompt cache */
+  cacheWrite: number;
+}
+
+/** Token counts carried by one backend message */
+export interface UsageSample extends TokenCounts {
+  /** API call the sample belongs to; repeated samples for one call count once */
+  id?: string;
+  model?: string;
+}
+
+export interface UsageParser {
+  backend: string;
+  tokens(message: unknown): UsageSample | null;
+  /** Cost so far in USD, when the backend reports a running total */
+  cost(message: unknown): number | null;
+}
+
+/** Usage of a single agent invocation */
+export interface AgentRunUsage {
+  backend: string;
+  /** null when the backend reported no token counts */
+  tokens: TokenCounts | null;
+  costUsd: number | null;
+  /** Cost computed from token prices rather than reported by the backend */
+  costEstimated: boolean;
+  durationMs: number;
+  /** Validation retries within the run */
+  retries: number;
+}
+
+/** Usage across an install: the main agent run plus any repair runs */
+export interface RunUsage {
+  tokens: TokenCounts | null;
+  costUsd: number | null;
+  costEstimated: boolean;
+  /** Agent time while the install runs; wall time of the whole install once it's finished */
+  durationMs: number;
+  agentRuns: number;
+  /** Validation retries, plus every agent run after the first (verification repairs) */
+  repairAttempts: number;
+}
+
+type JsonObject = Record<string, unknown>;
+
+function num(value: unknown): number {
+  return typeof value === 'number' && Number.isFinite(value) ? value : 0;
+}
+
+/**
+ * Claude Agent SDK: every assistant message carries the usage of its API
+ * call (repeated on each content block of the call), and result messages
+ * carry the session's running cost.
+ */
+export const claudeAgentUsageParser: UsageParser = {
+  backend: 'claude-agent-sdk',
+  tokens(message) {
+    const msg = message as { type?: string; message?: JsonObject };
+    if (msg.type !== 'assistant' || !msg.message?.usage) return null;
+    const usage = msg.message.usage as JsonObject;
+    return {
+      id: typeof msg.message.id === 'string' ? msg.message.id : undefined,
+      model: typeof msg.message.model === 'string' ? msg.message.model : undefined,
+      input: num(usage.input_tokens),
+      output: num(usage.output_tokens),
+      cacheRead: num(usage.cache_read_input_tokens),
+      cacheWrite: num(usage.cache_creation_input_tokens),
+    };
+  },
+  cost(message) {
+    const msg = message as { type?: string; total_cost_usd?: unknown };
+    return msg.type === 'result' && typeof msg.total_cost_usd === 'number' ? msg.total_cost_usd : null;
+  },
+};
+
+const noUsageParser = (backend: string): UsageParser => ({ backend, tokens: () => null, cost: () => null });
+
+const USAGE_PARSERS: Record<string, UsageParser> = {
+  [claudeAgentUsageParser.backend]: claudeAgentUsageParser,
+};
+
+export function getUsageParser(backend: string): UsageParser {
+  return USAGE_PARSERS[backend] ?? noUsageParser(backend);
+}
+
+/** USD per million tokens; first match on the model id wins */
+const MODEL_PRICES: Array<[RegExp, { input: number; output: number }]> = [
+  [/opus-4-[5-9]/, { input: 5, output: 25 }],
+  [/opus/, { input: 15, output: 75 }],
+  [/haiku-4/, { input: 1, output: 5 }],
+  [/haiku/, { input: 0.8, output: 4 }],
+  [/sonnet/, { input: 3, output: 15 }],
+];
+
+export function estimateCost(sample: UsageSample): number | null {
+  const price = MODEL_PRICES.find(([pattern]) => pattern.test(sample.model ?? ''))?.[1];
+  if (!price) return null;
+  // Cache reads bill at a tenth of the input price, cache writes at 1.25x
+  const input = sample.input + sample.cacheRead * 0.1 + sample.cacheWrite * 1.25;
+  return (input * price.input + sample.output * price.output) / 1_000_000;
+}
+
+function addTokens(a: TokenCounts | null, b: TokenCounts): TokenCounts {
+  return {
+    input: (a?.input ?? 0) + b.input,
+    output: (a?.output ?? 0) + b.output,
+    cacheRead: (a?.cacheRead ?? 0) + b.cacheRead,
+    cacheWrite: (a?.cacheWrite ?? 0) + b.cacheWrite,
+  };
+}
+
+/**
+ * Collects usage from one agent invocation's messages.
+ */
+export class UsageTracker {
+  private samples = new Map<string, UsageSample>();
+  private anonymous: UsageSample[] = [];
+  private reportedCost: number | null = null;
+
+  constructor(private parser: UsageParser) {}
+
+  record(message: unknown): void {
+    const sample = this.parser.tokens(message);
+    if (sample) {
+      if (sample.id) this.samples.set(sample.id, sample);
+      else this.anonymous.push(sample);
+    }
+    const cost = this.parser.cost(message);
+    if (cost !== null) this.reportedCost = Math.max(this.reportedCost ?? 0, cost);
+  }
+
+  finish(durationMs: number, retries: number): AgentRunUsage {
+    const samples = [...this.samples.values(), ...this.anonymous];
+    const tokens = samples.reduce<TokenCounts | null>((total, s) => addTokens(total, s), null);
+    let costUsd = this.reportedCost;
+    let costEstimated = false;
+    if (costUsd === null && samples.length > 0) {
+      const estimates = samples.map(estimateCost);
+      if (estimates.every((c) => c !== null)) {
+        costUsd = estimates.reduce<number>((sum, c) => sum + (c ?? 0), 0);
+        costEstimated = true;
+      }
+    }
+    return { backend: this.parser.backend, tokens, costUsd, costEstimated, durationMs, retries };
+  }
+}
+
+/** Fold one agent run into the install's totals */
+export function addAgentRun(total: RunUsage | undefined, run: AgentRunUsage): RunUsage {
+  if (!total) {
+    return {
+      tokens: run.tokens,
+      costUsd: run.costUsd,
+      costEstimated: run.costEstimated,
+      durationMs: run.durationMs,
+      agentRuns: 1,
+      repairAttempts: run.retries,
+    };
+  }
+  return {
+    tokens: run.tokens ? addTokens(total.tokens, run.tokens) : total.tokens,
+    // A run with an unknown cost makes the total unknown too
+    costUsd: total.costUsd !== null && run.costUsd !== null ? total.costUsd + run.costUsd : null,
+    costEstimated: total.costEstimated || run.costEstimated,
+    durationMs: total.durationMs + run.durationMs,
+    agentRuns: total.agentRuns + 1,
+    repairAttempts: total.repairAttempts + 1 + run.retries,
+  };
+}
+
+export function formatTokens(count: number): string {
+  if (count >= 1_000_000) return `${(count / 1_000_000).toFixed(1)}M`;
+  if (count >= 1_000) return `${(count / 1_000).toFixed(1)}k`;
+  return String(count);
+}
+
+function formatDuration(ms: number): string {
+  const seconds = Math.round(ms / 1000);
+  const minutes = Math.floor(seconds / 60);
+  return minutes > 0 ? `${minutes}m ${seconds % 60}s` : `${seconds}s`;
+}
+
+/** One-line usage report, e.g. "184.2k tokens in, 12.9k out, ~$0.74, 6m 12s, 1 repair attempt" */
+export function formatUsage(usage: RunUsage): string {
+  const parts: string[] = [];
+  if (usage.tokens) {
+    const input = usage.tokens.input + usage.tokens.cacheRead + usage.tokens.cacheWrite;
+    parts.push(`${formatTokens(input)} tokens in, ${formatTokens(usage.tokens.output)} out`);
+    if (usage.costUsd !== null) parts.push(`${usage.costEstimated ? '~' : ''}$${usage.costUsd.toFixed(2)}`);
+  } else {
+    parts.push('usage unavailable');
+  }
+  parts.push(formatDuration(usage.durationMs));
+  parts.push(`${usage.repairAttempts} repair ${usage.repairAttempts === 1 ? 'attempt' : 'attempts'}`);
+  return parts.join(', ');
+}
@@ -1,6 +1,6 @@
 import Anthropic from '@anthropic-ai/sdk';
 import { startCredentialProxy } from './credential-proxy.js';
-import { getLlmGatewayUrl, getAuthkitDomain, getCliAuthClientId, getConfig } from './settings.js';
+import { getLlmGatewayUrl, getConfig } from './settings.js';
 import { getCredentials } from './credentials.js';
 import { logInfo, logError } from '../utils/debug.js';
 
@@ -32,8 +32,6 @@ async function withProxy<T>(fn: (client: Anthropic) => Promise<T>): Promise<T> {
   const proxy = await startCredentialProxy({
     upstreamUrl: gatewayUrl,
     refresh: {
-      authkitDomain: getAuthkitDomain(),
-      clientId: getCliAuthClientId(),
       refreshThresholdMs: getConfig().proxy.refreshThresholdMs,
     },
   });
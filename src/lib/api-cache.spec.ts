@@ -0,0 +1,97 @@
+import { afterEach, beforeEach, describe, expect, it, vi } from 'vitest';
+import { mkdtempSync, readdirSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import {
+  _setApiCacheDir,
+  CACHE_TTLS,
+  cachedLookup,
+  cleanApiCache,
+  invalidateApiCache,
+  setApiCacheEnabled,
+  type CacheScope,
+} from './api-cache.js';
+import { WorkOSApiError } from './workos-api.js';
+
+const PROD: CacheScope = { profile: 'prod', environment: 'https://api.workos.com#aaaa' };
+const STAGING: CacheScope = { profile: 'staging', environment: 'https://api.workos.com#bbbb' };
+
+describe('api-cache', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'api-cache-'));
+    _setApiCacheDir(dir);
+    setApiCacheEnabled(true);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('serves a fresh entry without fetching, and refetches once it expires', async () => {
+    const fetcher = vi.fn().mockResolvedValueOnce(['org_1']).mockResolvedValueOnce(['org_2']);
+    let now = 1_000_000;
+
+    expect(await cachedLookup('organizations', PROD, fetcher, () => now)).toEqual(['org_1']);
+    now += CACHE_TTLS.organizations - 1;
+    expect(await cachedLookup('organizations', PROD, fetcher, () => now)).toEqual(['org_1']);
+    now += 1;
+    expect(await cachedLookup('organizations', PROD, fetcher, () => now)).toEqual(['org_2']);
+    expect(fetcher).toHaveBeenCalledTimes(2);
+  });
+
+  it('keeps profiles apart', async () => {
+    await cachedLookup('organizations', PROD, async () => ['prod_org']);
+
+    expect(await cachedLookup('organizations', STAGING, async () => ['staging_org'])).toEqual(['staging_org']);
+    expect(await cachedLookup('organizations', PROD, async () => ['refetched'])).toEqual(['prod_org']);
+  });
+
+  it('discards a corrupt entry and fetches', async () => {
+    await cachedLookup('organizations', PROD, async () => ['org_1']);
+    const [file] = readdirSync(join(dir, 'organizations'));
+    writeFileSync(join(dir, 'organizations', file), '{"version":1,"sto');
+
+    expect(await cachedLookup('organizations', PROD, async () => ['org_2'])).toEqual(['org_2']);
+    expect(await cachedLookup('organizations', PROD, async () => ['org_3'])).toEqual(['org_2']);
+  });
+
+  it('always fetches with --no-cache', async () => {
+    await cachedLookup('organizations', PROD, async () => ['org_1']);
+    setApiCacheEnabled(false);
+
+    expect(await cachedLookup('organizations', PROD, async () => ['org_2'])).toEqual(['org_2']);
+  });
+
+  it('drops one resource on invalidation and everything on clean', async () => {
+    await cachedLookup('organizations', PROD, async () => ['org_1']);
+    await cachedLookup('users', PROD, async () => ['user_1']);
+
+    invalidateApiCache('organizations');
+    expect(await cachedLookup('organizations', PROD, async () => ['org_2'])).toEqual(['org_2']);
+    expect(await cachedLookup('users', PROD, async () => ['user_2'])).toEqual(['user_1']);
+
+    cleanApiCache();
+    expect(await cachedLookup('users', PROD, async () => ['user_3'])).toEqual(['user_3']);
+  });
+
+  it("doesn't cache a failed fetch", async () => {
+    await expect(cachedLookup('users', PROD, () => Promise.reject(new Error('offline')))).rejects.toThrow('offline');
+    expect(await cachedLookup('users', PROD, async () => ['user_1'])).toEqual(['user_1']);
+  });
+
+  it('serves an expired entry when the API is unreachable, and only then', async () => {
+    const stderr = vi.spyOn(console, 'error').mockImplementation(() => {});
+    const later = () => Date.now() + CACHE_TTLS.users + 1;
+    await cachedLookup('users', PROD, async () => ['user_1']);
+
+    const offline = () => Promise.reject(new WorkOSApiError('Failed to connect to WorkOS API', 0, 'offline'));
+    expect(await cachedLookup('users', PROD, offline, later)).toEqual(['user_1']);
+    expect(stderr).toHaveBeenCalledWith(expect.stringContaining('using cached users from 2m ago'));
+
+    const rejected = () => Promise.reject(new WorkOSApiError('Invalid API key', 401));
+    await expect(cachedLookup('users', PROD, rejected, later)).rejects.toThrow('Invalid API key');
+    stderr.mockRestore();
+  });
+});
@@ -0,0 +1,165 @@
+/**
+ * On-disk cache for the read-heavy lookups behind tab completion and
+ * interactive pickers, and for the environment's mode (production or
+ * sandbox) that the destructive-command guard and prompt prefix show
+ * (`~/.workos/cache/api/`).
+ *
+ * Only those code paths read it: commands that show or change data always
+ * go to the API, and commands that change a resource drop its cached
+ * entries. Entries are keyed by the profile (the active `workos env`) and
+ * the environment the API key belongs to, so switching profiles never shows
+ * another account's organizations. A cache file that can't be read or
+ * parsed is removed and the lookup goes to the API, as if it had expired.
+ * Offline, expired entries still answer lookups.
+ */
+
+import { createHash } from 'node:crypto';
+import { mkdirSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { getConfig } from './config-store.js';
+import { noteOffline } from './connectivity.js';
+import { isConnectionError } from './workos-api.js';
+
+const MINUTE = 60 * 1000;
+
+/** How long each resource's lookup is served from the cache */
+export const CACHE_TTLS = {
+  organizations: 5 * MINUTE,
+  users: 2 * MINUTE,
+  environment: 60 * MINUTE,
+} as const;
+
+export type CachedResource = keyof typeof CACHE_TTLS;
+
+/** Bumped when the entry format changes; older entries are discarded */
+const ENTRY_VERSION = 1;
+
+let cacheDir = join(homedir(), '.workos', 'cache', 'api');
+let enabled = true;
+
+/** @internal For testing only */
+export function _setApiCacheDir(dir: string): void {
+  cacheDir = dir;
+}
+
+export function getApiCacheDir(): string {
+  return cacheDir;
+}
+
+/** `--no-cache`: lookups always go to the API (and refresh the cache) */
+export function setApiCacheEnabled(value: boolean): void {
+  enabled = value;
+}
+
+/** Whose data an entry holds */
+export interface CacheScope {
+  /** Active `workos env` profile, or `default` with no config */
+  profile: string;
+  /** API base URL and a fingerprint of the API key */
+  environment: string;
+}
+
+export function cacheScope(apiKey: string, baseUrl: string): CacheScope {
+  const fingerprint = createHash('sha256').update(apiKey).digest('hex').slice(0, 16);
+  return { profile: getConfig()?.activeEnvironment ?? 'default', environment: `${baseUrl}#${fingerprint}` };
+}
+
+interface CacheEntry<T> {
+  version: number;
+  scope: CacheScope;
+  storedAt: number;
+  data: T;
+}
+
+function entryPath(resource: CachedResource, scope: CacheScope): string {
+  const key = createHash('sha256').update(`${scope.profile}\0${scope.environment}`).digest('hex').slice(0, 32);
+  return join(cacheDir, resource, `${key}.json`);
+}
+
+function readEntry<T>(path: string, scope: CacheScope): CacheEntry<T> | null {
+  let raw: string;
+  try {
+    raw = readFileSync(path, 'utf-8');
+  } catch {
+    return null;
+  }
+  try {
+    const entry = JSON.parse(raw) as CacheEntry<T>;
+    const valid =
+      entry?.version === ENTRY_VERSION &&
+      typeof entry.storedAt === 'number' &&
+      entry.scope?.profile === scope.profile &&
+      entry.scope?.environment === scope.environment &&
+      'data' in entry;
+    if (valid) return entry;
+  } catch {
+    // Corrupt; removed below
+  }
+  rmSync(path, { force: true });
+  return null;
+}
+
+function writeEntry<T>(path: string, entry: CacheEntry<T>): void {
+  try {
+    mkdirSync(dirname(path), { recursive: true, mode: 0o700 });
+    writeFileSync(path, JSON.stringify(entry), { mode: 0o600 });
+  } catch {
+    // Caching is best effort
+  }
+}
+
+function formatAge(ms: number): string {
+  const minutes = Math.round(ms / MINUTE);
+  if (minutes < 60) return `${minutes}m`;
+  const hours = Math.round(minutes / 60);
+  return hours < 48 ? `${hours}h` : `${Math.round(hours / 24)}d`;
+}
+
+/**
+ * A lookup served from the cache while it's fresh, otherwise fetched and
+ * stored. A failed fetch isn't cached. When the API can't be reached at
+ * all, an expired entry is served instead, saying so.
+ */
+export async function cachedLookup<T>(
+  resource: CachedResource,
+  scope: CacheScope,
+  fetcher: () => Promise<T>,
+  now: () => number = Date.now,
+): Promise<T> {
+  const path = entryPath(resource, scope);
+  const entry = enabled ? readEntry<T>(path, scope) : null;
+  if (entry && now() - entry.storedAt < CACHE_TTLS[resource]) return entry.data;
+
+  let data: T;
+  try {
+    data = await fetcher();
+  } catch (error) {
+    if (!entry || !isConnectionError(error)) throw error;
+    noteOffline(`using cached ${resource} from ${formatAge(now() - entry.storedAt)} ago`);
+    return entry.data;
+  }
+  writeEntry(path, { version: ENTRY_VERSION, scope, storedAt: now(), data });
+  return data;
+}
+
+/** A fresh cached lookup without fetching, or null; for output that can't wait on the network */
+export function peekCachedLookup<T>(
+  resource: CachedResource,
+  scope: CacheScope,
+  now: () => number = Date.now,
+): T | null {
+  if (!enabled) return null;
+  const entry = readEntry<T>(entryPath(resource, scope), scope);
+  return entry && now() - entry.storedAt < CACHE_TTLS[resource] ? entry.data : null;
+}
+
+/** Drop a resource's cached lookups after a command changes it, for every profile */
+export function invalidateApiCache(resource: CachedResource): void {
+  rmSync(join(cacheDir, resource), { recursive: true, force: true });
+}
+
+/** `workos cache clean`: remove every cached lookup */
+export function cleanApiCache(): void {
+  rmSync(cacheDir, { recursive: true, force: true });
+}
@@ -0,0 +1,60 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { handleApiError } from './api-error.js';
+import { WorkOSApiError } from './workos-api.js';
+
+describe('handleApiError', () => {
+  let errors: string[];
+  let exit: ReturnType<typeof vi.spyOn>;
+
+  beforeEach(() => {
+    errors = [];
+    vi.spyOn(console, 'error').mockImplementation((...args: unknown[]) => {
+      errors.push(args.map(String).join(' '));
+    });
+    exit = vi.spyOn(process, 'exit').mockImplementation((() => undefined) as never);
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+  });
+
+  it('says the API key was rejected on a 401', () => {
+    handleApiError(new WorkOSApiError('Unauthorized', 401), { notFound: 'User not found.' });
+
+    expect(errors[0]).toContain('Invalid API key. Check your environment configuration.');
+    expect(exit).toHaveBeenCalledWith(12);
+  });
+
+  it("uses the command's not-found message on a 404, and the API's without one", () => {
+    handleApiError(new WorkOSApiError('Not Found', 404), { notFound: 'User not found.' });
+    handleApiError(new WorkOSApiError('Not Found', 404));
+
+    expect(errors[0]).toContain('User not found.');
+    expect(errors[2]).toContain('Not Found');
+  });
+
+  it('builds the forbidden message from the API message', () => {
+    handleApiError(new WorkOSApiError('Forbidden', 403), { forbidden: (message) => `${message}. Use the API key.` });
+    handleApiError(new WorkOSApiError('Forbidden', 403), { forbidden: 'Not enabled.' });
+
+    expect(errors[0]).toContain('Forbidden. Use the API key.');
+    expect(errors[2]).toContain('Not enabled.');
+  });
+
+  it('joins validation errors on a 422', () => {
+    handleApiError(
+      new WorkOSApiError('Validation failed', 422, 'invalid', [{ message: 'name is required' }, { message: 'bad email' }]),
+    );
+
+    expect(errors[0]).toContain('name is required, bad email');
+  });
+
+  it('prints the message of anything that is not an API error', () => {
+    handleApiError(new Error('socket hang up'));
+    handleApiError('???');
+
+    expect(errors[0]).toContain('socket hang up');
+    expect(errors[2]).toContain('Unknown error');
+    expect(exit).toHaveBeenCalledWith(1);
+  });
+});
@@ -0,0 +1,36 @@
+import chalk from 'chalk';
+import { exitWithError } from '../utils/errors.js';
+import { WorkOSApiError } from './workos-api.js';
+
+/** What a resource command says for the statuses it knows more about */
+export interface ApiErrorMessages {
+  /** For a 404, e.g. 'User not found.' */
+  notFound?: string;
+  /** For a 403, given the API's message */
+  forbidden?: string | ((message: string) => string);
+}
+
+/**
+ * Report a failed API call the way every resource command does, then exit:
+ * a rejected API key, the command's own not-found and forbidden messages,
+ * validation errors joined, and anything else as its message.
+ */
+export function handleApiError(error: unknown, messages: ApiErrorMessages = {}): never {
+  if (error instanceof WorkOSApiError) {
+    const { forbidden } = messages;
+    if (error.statusCode === 401) {
+      console.error(chalk.red('Invalid API key. Check your environment configuration.'));
+    } else if (error.statusCode === 403 && forbidden) {
+      console.error(chalk.red(typeof forbidden === 'string' ? forbidden : forbidden(error.message)));
+    } else if (error.statusCode === 404 && messages.notFound) {
+      console.error(chalk.red(messages.notFound));
+    } else if (error.statusCode === 422 && error.errors?.length) {
+      console.error(chalk.red(error.errors.map((e) => e.message).join(', ')));
+    } else {
+      console.error(chalk.red(error.message));
+    }
+  } else {
+    console.error(chalk.red(error instanceof Error ? error.message : 'Unknown error'));
+  }
+  exitWithError(error);
+}
@@ -34,6 +34,7 @@ describe('api-key', () => {
     setInsecureConfigStorage(true);
     process.env = { ...originalEnv };
     delete process.env.WORKOS_API_KEY;
+    delete process.env.WORKOS_CLI_TOKEN;
   });
 
   afterEach(() => {
@@ -73,6 +74,18 @@ describe('api-key', () => {
       expect(resolveApiKey()).toBe('sk_stored');
     });
 
+    it('uses WORKOS_CLI_TOKEN over the flag and profile, but not over WORKOS_API_KEY', () => {
+      process.env.WORKOS_CLI_TOKEN = 'wos_ci_token';
+      saveConfig({
+        activeEnvironment: 'prod',
+        environments: { prod: { name: 'prod', type: 'production', apiKey: 'sk_stored' } },
+      });
+      expect(resolveApiKey({ apiKey: 'sk_flag' })).toBe('wos_ci_token');
+
+      process.env.WORKOS_API_KEY = 'sk_env_var';
+      expect(resolveApiKey()).toBe('sk_env_var');
+    });
+
     it('throws when no API key available', () => {
       expect(() => resolveApiKey()).toThrow(/No API key/);
     });
@@ -122,5 +135,16 @@ describe('api-key', () => {
       });
       expect(resolveApiBaseUrl()).toBe('http://localhost:8001');
     });
+
+    it("ignores the profile's endpoint under a CI token", () => {
+      process.env.WORKOS_CLI_TOKEN = 'wos_ci_token';
+      saveConfig({
+        activeEnvironment: 'local',
+        environments: {
+          local: { name: 'local', type: 'sandbox', apiKey: 'sk_test', endpoint: 'http://localhost:8001' },
+        },
+      });
+      expect(resolveApiBaseUrl()).toBe('https://api.workos.com');
+    });
   });
 });
@@ -3,11 +3,14 @@
  *
  * Priority chain:
  * 1. WORKOS_API_KEY environment variable
- * 2. --api-key flag
- * 3. Active environment's stored API key
+ * 2. WORKOS_CLI_TOKEN, a scoped CI token (see ci-tokens.ts)
+ * 3. --api-key flag
+ * 4. Active environment's stored API key
  */
 
+import { getCiToken } from './ci-tokens.js';
 import { getActiveEnvironment } from './config-store.js';
+import { getWorkOSApiUrl } from '../utils/urls.js';
 
 const DEFAULT_BASE_URL = 'https://api.workos.com';
 
@@ -19,6 +22,10 @@ export function resolveApiKey(options?: ApiKeyOptions): string {
   const envVar = process.env.WORKOS_API_KEY;
   if (envVar) return envVar;
 
+  // Pipelines authenticate with the token alone, without profiles or the credentials file
+  const ciToken = getCiToken();
+  if (ciToken) return ciToken;
+
   if (options?.apiKey) return options.apiKey;
 
   const activeEnv = getActiveEnvironment();
@@ -28,6 +35,8 @@ export function resolveApiKey(options?: ApiKeyOptions): string {
 }
 
 export function resolveApiBaseUrl(): string {
+  // A CI token is tied to its environment; the local profiles say nothing about it
+  if (getCiToken()) return getWorkOSApiUrl();
   const activeEnv = getActiveEnvironment();
   return activeEnv?.endpoint || DEFAULT_BASE_URL;
 }
@@ -1,5 +1,6 @@
 import { z } from 'zod';
 import { analytics } from '../utils/analytics.js';
+import { proxiedFetch } from './proxy.js';
 
 export const ApiUserSchema = z.object({
   distinct_id: z.string(),
@@ -42,7 +43,7 @@ class ApiError extends Error {
 export async function fetchUserData(accessToken: string, baseUrl: string): Promise<ApiUser> {
   const endpoint = '/api/users/@me/';
   try {
-    const response = await fetch(`${baseUrl}${endpoint}`, {
+    const response = await proxiedFetch(`${baseUrl}${endpoint}`, {
       headers: {
         Authorization: `Bearer ${accessToken}`,
       },
@@ -67,7 +68,7 @@ export async function fetchUserData(accessToken: string, baseUrl: string): Promi
 export async function fetchProjectData(accessToken: string, projectId: number, baseUrl: string): Promise<ApiProject> {
   const endpoint = `/api/projects/${projectId}/`;
   try {
-    const response = await fetch(`${baseUrl}${endpoint}`, {
+    const response = await proxiedFetch(`${baseUrl}${endpoint}`, {
       headers: {
         Authorization: `Bearer ${accessToken}`,
       },
@@ -0,0 +1,84 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { setHttpRetries } from './http-retry.js';
+import { runAppCheck } from './app-check.js';
+
+const APP = 'https://myapp.example.com';
+const CLIENT_ID = 'client_01ABC';
+const AUTHORIZE = `https://api.workos.com/user_management/authorize?client_id=${CLIENT_ID}&redirect_uri=${encodeURIComponent(`${APP}/auth/callback`)}&response_type=code`;
+
+describe('runAppCheck', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+  let routes: Record<string, () => Response>;
+
+  function json(body: unknown, status = 200) {
+    return new Response(JSON.stringify(body), { status, headers: { 'content-type': 'application/json' } });
+  }
+
+  beforeEach(() => {
+    routes = {
+      [`${APP}/login`]: () => new Response(null, { status: 307, headers: { location: AUTHORIZE } }),
+      [`${APP}/auth/callback?code=workos_cli_smoke_test`]: () => new Response('Invalid code', { status: 400 }),
+      'https://api.workos.com/user_management/redirect_uris?limit=100': () =>
+        json({
+          data: [{ uri: 'http://localhost:3000/auth/callback' }, { uri: `${APP}/auth/callback/` }],
+          list_metadata: { after: null },
+        }),
+      [`https://api.workos.com/user_management/${CLIENT_ID}/.well-known/openid-configuration`]: () =>
+        json({ issuer: `https://api.workos.com/user_management/${CLIENT_ID}` }),
+      [`https://api.workos.com/sso/jwks/${CLIENT_ID}`]: () => json({ keys: [{ kid: 'sso_oidc_key_pair_01' }] }),
+    };
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    mockFetch.mockImplementation(async (url: string) => routes[url]?.() ?? new Response('Not found', { status: 404 }));
+    setHttpRetries(0);
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+  });
+
+  it('passes a correctly deployed app, probing the callback the login route sends', async () => {
+    const report = await runAppCheck({ url: APP, clientId: CLIENT_ID, apiKey: 'sk_test_abc' });
+
+    expect(report.probes.map((p) => [p.name, p.passed])).toEqual([
+      ['login', true],
+      ['callback', true],
+      ['redirect-uri', true],
+      ['issuer', true],
+      ['jwks', true],
+    ]);
+    expect(report.passed).toBe(true);
+    expect(mockFetch).toHaveBeenCalledWith(`${APP}/login`, expect.objectContaining({ redirect: 'manual' }));
+  });
+
+  it('fails on the wrong client ID, a crashing callback and an unregistered redirect URI', async () => {
+    routes[`${APP}/login`] = () =>
+      new Response(null, {
+        status: 302,
+        headers: { location: AUTHORIZE.replace(CLIENT_ID, 'client_01STAGING') },
+      });
+    routes[`${APP}/auth/callback?code=workos_cli_smoke_test`] = () => new Response('Oops', { status: 500 });
+    routes['https://api.workos.com/user_management/redirect_uris?limit=100'] = () =>
+      json({ data: [{ uri: 'http://localhost:3000/auth/callback' }], list_metadata: { after: null } });
+
+    const report = await runAppCheck({ url: APP, clientId: CLIENT_ID, apiKey: 'sk_test_abc' });
+    const byName = Object.fromEntries(report.probes.map((p) => [p.name, p]));
+
+    expect(report.passed).toBe(false);
+    expect(byName.login.detail).toBe(`Sends client_id client_01STAGING, expected ${CLIENT_ID}`);
+    expect(byName.callback.detail).toContain('returned 500 for a bogus code');
+    expect(byName['redirect-uri'].detail).toContain(`${APP}/auth/callback is not a registered redirect URI`);
+    expect(byName.jwks.passed).toBe(true);
+  });
+
+  it('reports a login route that does not redirect and a missing callback', async () => {
+    routes[`${APP}/login`] = () => new Response('<html>', { status: 200 });
+
+    const report = await runAppCheck({ url: APP, clientId: CLIENT_ID, apiKey: 'sk_test_abc' });
+    const byName = Object.fromEntries(report.probes.map((p) => [p.name, p]));
+
+    expect(byName.login.detail).toBe(`${APP}/login returned 200, expected a redirect`);
+    expect(byName.callback.detail).toBe(`${APP}/callback returned 404; the callback route is missing`);
+  });
+});
@@ -0,0 +1,186 @@
+/**
+ * Post-deploy smoke test of a deployed AuthKit app (`workos check app`).
+ *
+ * Each probe hits the live app or WorkOS the way a browser or a token
+ * verifier would: the login route must redirect to the AuthKit authorization
+ * endpoint for the expected client ID, the callback must turn a bogus code
+ * away with a client error rather than crash, the callback the app sends
+ * must be a registered redirect URI, and the issuer and key set tokens are
+ * checked against must answer. Redirects are never followed, so a probe sees
+ * exactly what the app returned.
+ */
+
+import { compareRedirectUris } from '../doctor/checks/dashboard.js';
+import { authkitIssuer } from '../migrate/token-verification.js';
+import { listAll } from './pagination.js';
+
+const DEFAULT_BASE_URL = 'https://api.workos.com';
+const TIMEOUT_MS = 10_000;
+const AUTHORIZE_PATH = '/user_management/authorize';
+/** Never a valid authorization code */
+const BOGUS_CODE = 'workos_cli_smoke_test';
+
+export type AppProbeName = 'login' | 'callback' | 'redirect-uri' | 'issuer' | 'jwks';
+
+export interface AppProbeResult {
+  name: AppProbeName;
+  passed: boolean;
+  detail: string;
+}
+
+export interface AppCheckReport {
+  url: string;
+  clientId: string;
+  passed: boolean;
+  probes: AppProbeResult[];
+}
+
+export interface AppCheckOptions {
+  /** Deployed app, e.g. https://myapp.example.com */
+  url: string;
+  clientId: string;
+  apiKey: string;
+  baseUrl?: string;
+  /** Default /login */
+  loginPath?: string;
+  /** Default: the redirect_uri the login route sends, else /callback */
+  callbackPath?: string;
+  /** Expected token issuer; the AuthKit issuer for the client ID by default, set it for a custom auth domain */
+  issuer?: string;
+}
+
+function appUrl(base: string, path: string): string {
+  return new URL(path, base.endsWith('/') ? base : `${base}/`).toString();
+}
+
+async function get(url: string): Promise<Response> {
+  return fetch(url, { redirect: 'manual', signal: AbortSignal.timeout(TIMEOUT_MS) });
+}
+
+function failed(name: AppProbeName, error: unknown): AppProbeResult {
+  return { name, passed: false, detail: error instanceof Error ? error.message : String(error) };
+}
+
+/** The login route must redirect to AuthKit with the app's client ID; returns the redirect_uri it sent */
+async function probeLogin(
+  loginUrl: string,
+  clientId: string,
+): Promise<{ result: AppProbeResult; redirectUri?: string }> {
+  const response = await get(loginUrl);
+  const location = response.headers.get('location');
+  if (response.status < 300 || response.status >= 400 || !location) {
+    return {
+      result: { name: 'login', passed: false, detail: `${loginUrl} returned ${response.status}, expected a redirect` },
+    };
+  }
+
+  const target = new URL(location, loginUrl);
+  const redirectUri = target.searchParams.get('redirect_uri') ?? undefined;
+  if (!target.pathname.endsWith(AUTHORIZE_PATH)) {
+    return {
+      result: { name: 'login', passed: false, detail: `Redirects to ${target.origin}${target.pathname}, not AuthKit` },
+      redirectUri,
+    };
+  }
+  const sent = target.searchParams.get('client_id');
+  if (sent !== clientId) {
+    return {
+      result: { name: 'login', passed: false, detail: `Sends client_id ${sent ?? '(none)'}, expected ${clientId}` },
+      redirectUri,
+    };
+  }
+  return {
+    result: { name: 'login', passed: true, detail: `Redirects to ${target.origin}${AUTHORIZE_PATH} for ${clientId}` },
+    redirectUri,
+  };
+}
+
+/** A bogus code must get a client error or a redirect to an error page, never a 404 or a 5xx */
+async function probeCallback(callbackUrl: string): Promise<AppProbeResult> {
+  const url = new URL(callbackUrl);
+  url.searchParams.set('code', BOGUS_CODE);
+  const response = await get(url.toString());
+  if (response.status === 404) {
+    return { name: 'callback', passed: false, detail: `${callbackUrl} returned 404; the callback route is missing` };
+  }
+  if (response.status >= 500) {
+    return { name: 'callback', passed: false, detail: `${callbackUrl} returned ${response.status} for a bogus code` };
+  }
+  return { name: 'callback', passed: true, detail: `${callbackUrl} rejects a bogus code with ${response.status}` };
+}
+
+async function probeRedirectUri(callbackUrl: string, options: AppCheckOptions): Promise<AppProbeResult> {
+  const registered = await listAll<{ uri: string }>({
+    path: '/user_management/redirect_uris',
+    apiKey: options.apiKey,
+    baseUrl: options.baseUrl,
+  });
+  const uris = registered.map((r) => r.uri);
+  if (compareRedirectUris(callbackUrl, uris).match) {
+    return { name: 'redirect-uri', passed: true, detail: `${callbackUrl} is registered` };
+  }
+  return {
+    name: 'redirect-uri',
+    passed: false,
+    detail: `${callbackUrl} is not a registered redirect URI (${uris.length} registered); add it in the dashboard (\`workos dashboard redirect-uris\`)`,
+  };
+}
+
+async function probeIssuer(issuer: string): Promise<AppProbeResult> {
+  const discoveryUrl = `${issuer.replace(/\/+$/, '')}/.well-known/openid-configuration`;
+  const response = await get(discoveryUrl);
+  if (!response.ok) return { name: 'issuer', passed: false, detail: `${discoveryUrl} returned ${response.status}` };
+  const document = (await response.json()) as { issuer?: string };
+  if (document.issuer && document.issuer.replace(/\/+$/, '') !== issuer.replace(/\/+$/, '')) {
+    return {
+      name: 'issuer',
+      passed: false,
+      detail: `${discoveryUrl} names issuer ${document.issuer}, expected ${issuer}`,
+    };
+  }
+  return { name: 'issuer', passed: true, detail: `${issuer} is reachable` };
+}
+
+async function probeJwks(jwksUrl: string): Promise<AppProbeResult> {
+  const response = await get(jwksUrl);
+  if (!response.ok) return { name: 'jwks', passed: false, detail: `${jwksUrl} returned ${response.status}` };
+  const keys = ((await response.json()) as { keys?: unknown[] }).keys ?? [];
+  if (keys.length === 0) return { name: 'jwks', passed: false, detail: `${jwksUrl} has no signing keys` };
+  return { name: 'jwks', passed: true, detail: `${jwksUrl} serves ${keys.length} key(s)` };
+}
+
+/** Run every probe; a probe that throws fails on its own without stopping the rest */
+export async function runAppCheck(options: AppCheckOptions): Promise<AppCheckReport> {
+  const baseUrl = (options.baseUrl ?? DEFAULT_BASE_URL).replace(/\/+$/, '');
+  const loginUrl = appUrl(options.url, options.loginPath ?? '/login');
+  const probes: AppProbeResult[] = [];
+
+  let redirectUri: string | undefined;
+  try {
+    const login = await probeLogin(loginUrl, options.clientId);
+    probes.push(login.result);
+    redirectUri = login.redirectUri;
+  } catch (error) {
+    probes.push(failed('login', error));
+  }
+
+  // The callback the deployed app actually sends, unless told otherwise
+  const callbackUrl = options.callbackPath
+    ? appUrl(options.url, options.callbackPath)
+    : (redirectUri ?? appUrl(options.url, '/callback'));
+  const run = async (name: AppProbeName, probe: () => Promise<AppProbeResult>) => {
+    try {
+      probes.push(await probe());
+    } catch (error) {
+      probes.push(failed(name, error));
+    }
+  };
+
+  await run('callback', () => probeCallback(callbackUrl));
+  await run('redirect-uri', () => probeRedirectUri(callbackUrl, options));
+  const issuer = options.issuer ?? authkitIssuer(options.clientId).replace(DEFAULT_BASE_URL, baseUrl);
+  await run('issuer', () => probeIssuer(issuer));
+  await run('jwks', () => probeJwks(`${baseUrl}/sso/jwks/${encodeURIComponent(options.clientId)}`));
+
+  return { url: options.url, clientId: options.clientId, passed: probes.every((p) => p.passed), probes };
+}
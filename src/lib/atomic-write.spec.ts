@@ -0,0 +1,146 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import * as fs from 'node:fs';
+import { existsSync, mkdtempSync, readdirSync, readFileSync, rmSync, statSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { applyFileEdits, recordFileEdits, writeFileAtomic } from './atomic-write.js';
+
+vi.mock('node:fs', async (importOriginal) => {
+  const actual = await importOriginal<typeof import('node:fs')>();
+  return { ...actual, renameSync: vi.fn(actual.renameSync) };
+});
+
+describe('atomic-write', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'atomic-write-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('writeFileAtomic', () => {
+    it('writes the file without leaving temp files behind', () => {
+      writeFileAtomic(join(dir, 'a.txt'), 'hello');
+
+      expect(readFileSync(join(dir, 'a.txt'), 'utf-8')).toBe('hello');
+      expect(readdirSync(dir)).toEqual(['a.txt']);
+    });
+
+    it('applies the mode to new files and keeps the mode of existing ones', () => {
+      writeFileAtomic(join(dir, 'secret.json'), '{}', { mode: 0o600 });
+      expect(statSync(join(dir, 'secret.json')).mode & 0o777).toBe(0o600);
+
+      writeFileSync(join(dir, 'script.sh'), 'echo', { mode: 0o755 });
+      writeFileAtomic(join(dir, 'script.sh'), 'echo hi', { mode: 0o600 });
+      expect(statSync(join(dir, 'script.sh')).mode & 0o777).toBe(0o755);
+    });
+  });
+
+  describe('applyFileEdits', () => {
+    it('computes edits from the current content', () => {
+      writeFileSync(join(dir, '.gitignore'), 'node_modules\n');
+
+      const written = applyFileEdits([
+        { path: join(dir, '.gitignore'), content: (current) => `${current}.env\n` },
+        { path: join(dir, '.env'), content: (current) => (current ?? '') + 'KEY=value\n' },
+      ]);
+
+      expect(written).toHaveLength(2);
+      expect(readFileSync(join(dir, '.gitignore'), 'utf-8')).toBe('node_modules\n.env\n');
+      expect(readFileSync(join(dir, '.env'), 'utf-8')).toBe('KEY=value\n');
+    });
+
+    it('writes CRLF files back with CRLF and hands edits LF content', () => {
+      writeFileSync(join(dir, '.env.example'), '# App\r\nPORT=3000\r\n');
+      const seen: Array<string | null> = [];
+
+      applyFileEdits([
+        {
+          path: join(dir, '.env.example'),
+          content: (current) => {
+            seen.push(current);
+            return `${current}\n# WorkOS\nWORKOS_CLIENT_ID=\n`;
+          },
+        },
+      ]);
+
+      expect(seen).toEqual(['# App\nPORT=3000\n']);
+      expect(readFileSync(join(dir, '.env.example'), 'utf-8')).toBe(
+        '# App\r\nPORT=3000\r\n\r\n# WorkOS\r\nWORKOS_CLIENT_ID=\r\n',
+      );
+    });
+
+    it('leaves a file alone when only its line endings would change', () => {
+      writeFileSync(join(dir, 'a.txt'), 'one\r\ntwo\r\n');
+
+      expect(applyFileEdits([{ path: join(dir, 'a.txt'), content: 'one\ntwo\n' }])).toEqual([]);
+      expect(readFileSync(join(dir, 'a.txt'), 'utf-8')).toBe('one\r\ntwo\r\n');
+    });
+
+    it('skips edits that do not change the file', () => {
+      writeFileSync(join(dir, 'a.txt'), 'same');
+
+      expect(applyFileEdits([{ path: join(dir, 'a.txt'), content: 'same' }])).toEqual([]);
+    });
+
+    it('writes nothing when an edit fails to compute', () => {
+      writeFileSync(join(dir, 'a.txt'), 'original');
+
+      expect(() =>
+        applyFileEdits([
+          { path: join(dir, 'a.txt'), content: 'changed' },
+          {
+            path: join(dir, 'b.txt'),
+            content: () => {
+              throw new Error('cannot compute');
+            },
+          },
+        ]),
+      ).toThrow('cannot compute');
+
+      expect(readFileSync(join(dir, 'a.txt'), 'utf-8')).toBe('original');
+      expect(readdirSync(dir)).toEqual(['a.txt']);
+    });
+
+    it('restores committed files when a later rename fails', () => {
+      writeFileSync(join(dir, 'a.txt'), 'original');
+      const actualRename = vi.mocked(fs.renameSync).getMockImplementation()!;
+      vi.mocked(fs.renameSync)
+        .mockImplementationOnce(actualRename)
+        .mockImplementationOnce(() => {
+          throw new Error('EXDEV');
+        });
+
+      expect(() =>
+        applyFileEdits([
+          { path: join(dir, 'a.txt'), content: 'changed' },
+          { path: join(dir, 'b.txt'), content: 'new' },
+        ]),
+      ).toThrow('EXDEV');
+
+      expect(readFileSync(join(dir, 'a.txt'), 'utf-8')).toBe('original');
+      expect(existsSync(join(dir, 'b.txt'))).toBe(false);
+      expect(readdirSync(dir)).toEqual(['a.txt']);
+    });
+
+    it('hands edits to the recorder instead of writing them while one is set', () => {
+      const recorded: string[] = [];
+      recordFileEdits((edits) => {
+        recorded.push(...edits.map((edit) => edit.path));
+        return recorded;
+      });
+      try {
+        expect(applyFileEdits([{ path: join(dir, 'a.txt'), content: 'new' }])).toEqual([join(dir, 'a.txt')]);
+      } finally {
+        recordFileEdits(null);
+      }
+
+      expect(readdirSync(dir)).toEqual([]);
+      applyFileEdits([{ path: join(dir, 'a.txt'), content: 'new' }]);
+      expect(readFileSync(join(dir, 'a.txt'), 'utf-8')).toBe('new');
+    });
+  });
+});
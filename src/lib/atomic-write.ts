@@ -0,0 +1,181 @@
+/**
+ * All-or-nothing file writes.
+ *
+ * Files are written to a temp file in the same directory and renamed into
+ * place, so readers never see a half-written file. `applyFileEdits` computes
+ * every edit in memory first and only renames once all temp files are
+ * written: if any edit fails, no target file is touched.
+ *
+ * Edits see existing content with LF line endings, and a CRLF file is
+ * written back with CRLF, so injected blocks never mix line endings.
+ *
+ * This covers the installer's own writes (env files, config, init's
+ * scaffolding). The agent's edits go through its file tools and land as it
+ * makes them: it builds and tests against them, and a cancelled migration
+ * keeps them so it can resume (see interrupt.ts).
+ */
+
+import * as fs from 'node:fs';
+import { randomBytes } from 'node:crypto';
+import { basename, dirname, join } from 'node:path';
+import { detectLineEnding, normalizeLineEndings } from '../utils/line-endings.js';
+
+export interface FileEdit {
+  path: string;
+  /** New content, or a function of the current content (LF line endings; null if the file doesn't exist) */
+  content: string | ((current: string | null) => string);
+  /** Mode for newly created files; existing files keep their mode */
+  mode?: number;
+}
+
+interface StagedEdit {
+  path: string;
+  tempPath: string;
+  original: string | null;
+}
+
+function readCurrent(path: string): string | null {
+  try {
+    return fs.readFileSync(path, 'utf-8');
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code === 'ENOENT') return null;
+    throw error;
+  }
+}
+
+function tempPathFor(path: string): string {
+  return join(dirname(path), `.${basename(path)}.${process.pid}.${randomBytes(4).toString('hex')}.tmp`);
+}
+
+function existingMode(path: string): number | undefined {
+  try {
+    return fs.statSync(path).mode & 0o777;
+  } catch {
+    return undefined;
+  }
+}
+
+function writeTemp(path: string, content: string, mode?: number): string {
+  const tempPath = tempPathFor(path);
+  const fd = fs.openSync(tempPath, 'wx', existingMode(path) ?? mode ?? 0o666);
+  try {
+    fs.writeFileSync(fd, content, 'utf-8');
+    fs.fsyncSync(fd);
+  } finally {
+    fs.closeSync(fd);
+  }
+  return tempPath;
+}
+
+function removeQuietly(path: string): void {
+  try {
+    fs.unlinkSync(path);
+  } catch {
+    // Already gone
+  }
+}
+
+/**
+ * Write a file atomically: temp file in the same directory, then rename.
+ */
+export function writeFileAtomic(path: string, content: string, options: { mode?: number } = {}): void {
+  const tempPath = writeTemp(path, content, options.mode);
+  try {
+    fs.renameSync(tempPath, path);
+  } catch (error) {
+    removeQuietly(tempPath);
+    throw error;
+  }
+}
+
+/** Where `applyFileEdits` sends edits instead of the disk, while one is set */
+let editRecorder: ((edits: FileEdit[]) => string[]) | null = null;
+
+/**
+ * Send edits to `recorder` instead of writing them (`--diff-only` records
+ * every edit in its patch), or back to the disk with null.
+ */
+export function recordFileEdits(recorder: ((edits: FileEdit[]) => string[]) | null): void {
+  editRecorder = recorder;
+}
+
+/** Called with each existing file `applyFileEdits` is about to change, while one is set */
+let editBackup: ((path: string) => void) | null = null;
+
+/**
+ * Hand each existing file to `backup` before an edit changes it
+ * (`--keep-backups` copies the original), or stop with null.
+ */
+export function backUpFileEdits(backup: ((path: string) => void) | null): void {
+  editBackup = backup;
+}
+
+/**
+ * The content an edit leaves, given the current content (as read, with its
+ * own line endings; null if the file doesn't exist). Returns `original`
+ * itself when the edit changes nothing but line endings.
+ */
+export function editedContent(edit: FileEdit, original: string | null): string {
+  if (original === null) return typeof edit.content === 'function' ? edit.content(null) : edit.content;
+  const current = normalizeLineEndings(original);
+  const next = typeof edit.content === 'function' ? edit.content(current) : normalizeLineEndings(edit.content);
+  // Unchanged apart from line endings: leave the file alone
+  if (next === current) return original;
+  return normalizeLineEndings(next, detectLineEnding(original));
+}
+
+/**
+ * Apply a set of edits as one unit.
+ *
+ * 1. Compute every new content in memory (a throwing edit aborts before any write)
+ * 2. Write each to a temp file next to its target
+ * 3. Rename all temp files into place
+ *
+ * If a rename fails part-way, files already renamed are restored to their
+ * original content. Edits that don't change a file are skipped. While a
+ * recorder is set (see recordFileEdits), nothing is written.
+ *
+ * @returns Paths that were written
+ */
+export function applyFileEdits(edits: FileEdit[]): string[] {
+  if (editRecorder) return editRecorder(edits);
+
+  const computed = edits
+    .map((edit) => {
+      const original = readCurrent(edit.path);
+      return { ...edit, original, content: editedContent(edit, original) };
+    })
+    .filter((edit) => edit.content !== edit.original);
+
+  for (const edit of computed) {
+    if (edit.original !== null) editBackup?.(edit.path);
+  }
+
+  const staged: StagedEdit[] = [];
+  try {
+    for (const edit of computed) {
+      const tempPath = writeTemp(edit.path, edit.content, edit.mode);
+      staged.push({ path: edit.path, original: edit.original, tempPath });
+    }
+  } catch (error) {
+    staged.forEach((s) => removeQuietly(s.tempPath));
+    throw error;
+  }
+
+  const committed: StagedEdit[] = [];
+  try {
+    for (const edit of staged) {
+      fs.renameSync(edit.tempPath, edit.path);
+      committed.push(edit);
+    }
+  } catch (error) {
+    staged.filter((s) => !committed.includes(s)).forEach((s) => removeQuietly(s.tempPath));
+    for (const edit of committed.reverse()) {
+      if (edit.original === null) removeQuietly(edit.path);
+      else writeFileAtomic(edit.path, edit.original);
+    }
+    throw error;
+  }
+
+  return committed.map((edit) => edit.path);
+}
@@ -0,0 +1,160 @@
+import { describe, it, expect } from 'vitest';
+import {
+  formatSchemaYaml,
+  parseExporterFile,
+  parseSchemaFile,
+  planSchemaPush,
+  type AuditLogActionDefinition,
+  type DeployedAction,
+} from './audit-logs.js';
+
+const YAML = `# Events the app records
+actions:
+  - name: user.signed_in
+    targets:
+      - type: user
+    actor:
+      metadata:
+        role: string
+    metadata:
+      ip_country: "string" # ISO code
+  - name: document.shared
+    targets:
+    - type: document
+      metadata:
+        pages: number
+    - type: team
+`;
+
+function deployed(definition: AuditLogActionDefinition, version = 1): DeployedAction {
+  return { version, definition };
+}
+
+describe('audit-logs', () => {
+  describe('parseSchemaFile', () => {
+    it('reads actions with their targets and metadata', () => {
+      const { actions, errors } = parseSchemaFile(YAML, 'schema.yaml');
+
+      expect(errors).toEqual([]);
+      expect(actions).toEqual([
+        {
+          name: 'user.signed_in',
+          targets: [{ type: 'user', metadata: {} }],
+          actor: { role: 'string' },
+          metadata: { ip_country: 'string' },
+        },
+        {
+          name: 'document.shared',
+          targets: [
+            { type: 'document', metadata: { pages: 'number' } },
+            { type: 'team', metadata: {} },
+          ],
+          actor: {},
+          metadata: {},
+        },
+      ]);
+    });
+
+    it('reports every problem with its line', () => {
+      const yaml = [
+        'actions:',
+        '  - name: user.signed_in',
+        '    metadata:',
+        '      ip: date',
+        '  - name: user.signed_in',
+        '    targets:',
+        '      - type: user',
+        '        lables: {}',
+      ].join('\n');
+
+      expect(parseSchemaFile(yaml, 'schema.yaml').errors).toEqual([
+        { line: 2, message: 'action "user.signed_in" needs at least one target (`targets:` with `- type: ...`)' },
+        { line: 4, message: 'metadata key "ip" must be string, number or boolean' },
+        { line: 5, message: 'duplicate action "user.signed_in"' },
+        { line: 8, message: 'unknown key "lables" in a target of "user.signed_in"' },
+      ]);
+      expect(parseSchemaFile('actions:\n  - name: a\n   targets:\n', 'schema.yaml').errors).toEqual([
+        { line: 3, message: 'unexpected indentation' },
+      ]);
+    });
+
+    it('round-trips what pull writes', () => {
+      const { actions } = parseSchemaFile(YAML, 'schema.yaml');
+
+      expect(parseSchemaFile(formatSchemaYaml(actions), 'schema.yaml')).toEqual({ actions, errors: [] });
+    });
+
+    it('reads JSON files with the same shape', () => {
+      const json = {
+        actions: [{ name: 'user.signed_in', targets: [{ type: 'user' }], actor: { metadata: { role: 'string' } } }],
+      };
+
+      expect(parseSchemaFile(JSON.stringify(json), 'schema.json').actions).toEqual([
+        { name: 'user.signed_in', targets: [{ type: 'user', metadata: {} }], actor: { role: 'string' }, metadata: {} },
+      ]);
+    });
+  });
+
+  describe('planSchemaPush', () => {
+    it('creates, updates and removes actions to match the file', () => {
+      const { actions } = parseSchemaFile(YAML, 'schema.yaml');
+      const current = [
+        deployed({
+          name: 'user.signed_in',
+          targets: [{ type: 'user', metadata: {} }, { type: 'session', metadata: {} }],
+          actor: { role: 'number' },
+          metadata: { ip_country: 'string', user_agent: 'string' },
+        }),
+        deployed({ name: 'user.deleted', targets: [{ type: 'user', metadata: {} }], actor: {}, metadata: {} }, 3),
+      ];
+
+      const plan = planSchemaPush(actions, current);
+
+      expect(plan.create.map((a) => a.name)).toEqual(['document.shared']);
+      expect(plan.update.map(({ change }) => change)).toEqual([
+        {
+          name: 'user.signed_in',
+          addedTargets: [],
+          removedTargets: ['session'],
+          fields: [
+            { where: 'metadata', key: 'user_agent', from: 'string' },
+            { where: 'actor', key: 'role', from: 'number', to: 'string' },
+          ],
+        },
+      ]);
+      expect(plan.remove.map((a) => a.definition.name)).toEqual(['user.deleted']);
+    });
+
+    it('leaves matching actions alone', () => {
+      const { actions } = parseSchemaFile(YAML, 'schema.yaml');
+
+      const plan = planSchemaPush(actions, actions.map((action) => deployed(action)));
+
+      expect(plan).toEqual({ create: [], update: [], remove: [], unchanged: ['user.signed_in', 'document.shared'] });
+    });
+  });
+
+  describe('parseExporterFile', () => {
+    it('fills in environment references', () => {
+      const yaml = 'region: us1\napi_key: ${DATADOG_API_KEY}\n';
+
+      expect(parseExporterFile(yaml, 'exporter.yaml', 'datadog', { DATADOG_API_KEY: 'dd_123' })).toEqual({
+        config: { region: 'us1', api_key: 'dd_123' },
+        errors: [],
+      });
+    });
+
+    it('checks the settings the exporter type needs', () => {
+      const yaml = 'region: mars\napi_key: ${DATADOG_API_KEY}\nsite: x\n';
+
+      expect(parseExporterFile(yaml, 'exporter.yaml', 'datadog', {}).errors).toEqual([
+        { line: 3, message: 'unknown key "site" in datadog settings' },
+        { line: 1, message: '`region` must be one of us1, us3, us5, eu1, ap1, us1-fed' },
+        { line: 2, message: '`api_key` uses ${DATADOG_API_KEY}, which is not set' },
+      ]);
+      expect(parseExporterFile('url: https://hec.example.com\n', 'e.yaml', 'splunk', {}).errors).toEqual([
+        { message: 'splunk needs `token`' },
+      ]);
+    });
+  });
+});
@@ -0,0 +1,685 @@
+/**
+ * Audit Logs schemas and log streams from local files.
+ *
+ * A schema file (YAML or JSON) lists the event actions the app emits and the
+ * metadata each one carries on the event, its actor and its targets. Files
+ * are checked locally first so mistakes come back with line numbers; push
+ * then publishes a new schema version for each action that changed and
+ * removes actions missing from the file.
+ *
+ * An exporter file holds one organization's log stream settings. Values can
+ * reference environment variables as `${NAME}`, so credentials stay out of
+ * the file.
+ */
+
+import { listAll } from './pagination.js';
+import { workosRequest } from './workos-api.js';
+import { REDACTED } from '../utils/redact.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface FileError {
+  /** 1-based; JSON files only have the message */
+  line?: number;
+  message: string;
+}
+
+export type MetadataType = 'string' | 'number' | 'boolean';
+
+/** Metadata key → the type of its value */
+export type MetadataFields = Record<string, MetadataType>;
+
+export interface AuditLogTarget {
+  type: string;
+  metadata: MetadataFields;
+}
+
+export interface AuditLogActionDefinition {
+  name: string;
+  targets: AuditLogTarget[];
+  actor: MetadataFields;
+  metadata: MetadataFields;
+}
+
+const ACTION_PATTERN = /^[a-z0-9][a-z0-9_.-]*$/;
+const TARGET_PATTERN = /^[a-z0-9][a-z0-9_-]*$/;
+const METADATA_TYPES = new Set(['string', 'number', 'boolean']);
+/** Limits the API enforces on event metadata */
+const MAX_METADATA_KEYS = 50;
+const MAX_METADATA_KEY_LENGTH = 40;
+
+// --- YAML subset -----------------------------------------------------------
+
+interface Row {
+  line: number;
+  indent: number;
+  text: string;
+}
+
+class YamlError extends Error {
+  constructor(
+    readonly line: number,
+    message: string,
+  ) {
+    super(message);
+  }
+}
+
+interface YamlDocument {
+  value: unknown;
+  /** Line of each key and list item by path, e.g. `actions.0.targets` */
+  lines: Map<string, number>;
+}
+
+const KEY_PATTERN = /^("[^"]*"|'[^']*'|[^\s"'#][^:]*?)\s*:(?:\s+(.*))?$/;
+
+function isItem(text: string): boolean {
+  return text === '-' || text.startsWith('- ');
+}
+
+function joinPath(path: string, key: string | number): string {
+  return path ? `${path}.${key}` : String(key);
+}
+
+/** Drop a trailing `# comment`, leaving `#` inside quoted strings alone */
+function stripComment(raw: string): string {
+  let quote: string | null = null;
+  for (let i = 0; i < raw.length; i++) {
+    const c = raw[i];
+    if (quote) {
+      if (c === quote) quote = null;
+    } else if ((c === '"' || c === "'") && (i === 0 || /[\s[,:]/.test(raw[i - 1]))) {
+      quote = c;
+    } else if (c === '#' && (i === 0 || /\s/.test(raw[i - 1]))) {
+      return raw.slice(0, i);
+    }
+  }
+  return raw;
+}
+
+function scalar(text: string, line: number): unknown {
+  if (text.startsWith('"')) {
+    try {
+      return JSON.parse(text);
+    } catch {
+      throw new YamlError(line, `unterminated string ${text}`);
+    }
+  }
+  if (text.startsWith("'")) {
+    if (!/^'.*'$/.test(text)) throw new YamlError(line, `unterminated string ${text}`);
+    return text.slice(1, -1).replace(/''/g, "'");
+  }
+  if (text.startsWith('[')) {
+    if (!text.endsWith(']')) throw new YamlError(line, `unterminated list ${text}`);
+    const inner = text.slice(1, -1).trim();
+    return inner ? inner.split(',').map((item) => scalar(item.trim(), line)) : [];
+  }
+  if (text === '{}') return {};
+  if (/^[{&*|>!]/.test(text)) {
+    throw new YamlError(line, `"${text[0]}" isn't supported here; put each key on its own line`);
+  }
+  if (text === 'true' || text === 'false') return text === 'true';
+  if (text === 'null' || text === '~') return null;
+  if (/^-?\d+(\.\d+)?$/.test(text)) return Number(text);
+  return text;
+}
+
+/**
+ * Block mappings, block lists (`- ` items, including `- key: value`),
+ * quoted and plain scalars and `[a, b]` lists: what schema and exporter
+ * files need, with the line of every key kept for error messages.
+ */
+class YamlParser {
+  private i = 0;
+  readonly lines = new Map<string, number>();
+
+  constructor(private readonly rows: Row[]) {}
+
+  document(): unknown {
+    if (this.rows.length === 0) return null;
+    const value = this.block(this.rows[0].indent, '');
+    if (this.i < this.rows.length) throw new YamlError(this.rows[this.i].line, 'unexpected indentation');
+    return value;
+  }
+
+  private block(indent: number, path: string): unknown {
+    return isItem(this.rows[this.i].text) ? this.sequence(indent, path) : this.mapping(indent, path);
+  }
+
+  /** The block under a key or `-` that has nothing after it; null when nothing is nested */
+  private nested(indent: number, path: string): unknown {
+    const next = this.rows[this.i];
+    return next && next.indent > indent ? this.block(next.indent, path) : null;
+  }
+
+  private sequence(indent: number, path: string): unknown[] {
+    const items: unknown[] = [];
+    while (this.i < this.rows.length) {
+      const row = this.rows[this.i];
+      if (row.indent < indent || !isItem(row.text)) break;
+      if (row.indent > indent) throw new YamlError(row.line, 'unexpected indentation');
+      const itemPath = joinPath(path, items.length);
+      this.lines.set(itemPath, row.line);
+      const rest = row.text.slice(1).trim();
+      if (!rest) {
+        this.i++;
+        items.push(this.nested(indent, itemPath));
+      } else if (KEY_PATTERN.test(rest)) {
+        // `- key: value` starts a mapping whose keys line up with `key`
+        const keyIndent = row.indent + row.text.length - rest.length;
+        this.rows[this.i] = { ...row, indent: keyIndent, text: rest };
+        items.push(this.mapping(keyIndent, itemPath));
+      } else {
+        this.i++;
+        items.push(scalar(rest, row.line));
+      }
+    }
+    return items;
+  }
+
+  private mapping(indent: number, path: string): Record<string, unknown> {
+    const map: Record<string, unknown> = {};
+    while (this.i < this.rows.length) {
+      const row = this.rows[this.i];
+      if (row.indent < indent) break;
+      if (row.indent > indent) throw new YamlError(row.line, 'unexpected indentation');
+      if (isItem(row.text)) throw new YamlError(row.line, 'unexpected list item (check indentation)');
+      const pair = KEY_PATTERN.exec(row.text);
+      if (!pair) throw new YamlError(row.line, `expected \`key: value\`, got "${row.text}"`);
+      const key = /^["']/.test(pair[1]) ? pair[1].slice(1, -1) : pair[1];
+      if (Object.hasOwn(map, key)) throw new YamlError(row.line, `duplicate key "${key}"`);
+      const keyPath = joinPath(path, key);
+      this.lines.set(keyPath, row.line);
+      this.i++;
+
+      if (pair[2]) {
+        map[key] = scalar(pair[2], row.line);
+        continue;
+      }
+      // A list may sit at its key's own indentation
+      const next = this.rows[this.i];
+      const compactList = next && next.indent === indent && isItem(next.text);
+      map[key] = compactList ? this.sequence(indent, keyPath) : this.nested(indent, keyPath);
+    }
+    return map;
+  }
+}
+
+function parseYaml(content: string): YamlDocument {
+  const rows: Row[] = [];
+  content.split(/\r?\n/).forEach((raw, index) => {
+    const stripped = stripComment(raw).trimEnd();
+    if (!stripped.trim()) return;
+    const indentation = /^\s*/.exec(stripped)![0];
+    if (indentation.includes('\t')) throw new YamlError(index + 1, 'indent with spaces, not tabs');
+    rows.push({ line: index + 1, indent: indentation.length, text: stripped.trim() });
+  });
+  const parser = new YamlParser(rows);
+  return { value: parser.document(), lines: parser.lines };
+}
+
+/** YAML or JSON; JSON documents have no line numbers */
+function parseDocument(content: string, fileName: string): YamlDocument | FileError {
+  if (fileName.endsWith('.json')) {
+    try {
+      return { value: JSON.parse(content), lines: new Map() };
+    } catch (error) {
+      return { message: `invalid JSON: ${error instanceof Error ? error.message : String(error)}` };
+    }
+  }
+  try {
+    return parseYaml(content);
+  } catch (error) {
+    if (error instanceof YamlError) return { line: error.line, message: error.message };
+    throw error;
+  }
+}
+
+function isMapping(value: unknown): value is Record<string, unknown> {
+  return typeof value === 'object' && value !== null && !Array.isArray(value);
+}
+
+/** The line of `path`, or of the closest parent that has one */
+function lineOf(lines: Map<string, number>, path: string): number | undefined {
+  for (let at = path; at; at = at.includes('.') ? at.slice(0, at.lastIndexOf('.')) : '') {
+    const line = lines.get(at);
+    if (line !== undefined) return line;
+  }
+  return undefined;
+}
+
+class ErrorList {
+  readonly errors: FileError[] = [];
+
+  constructor(private readonly lines: Map<string, number>) {}
+
+  add(path: string, message: string): void {
+    const line = lineOf(this.lines, path);
+    this.errors.push({ ...(line !== undefined && { line }), message });
+  }
+
+  unknownKeys(value: Record<string, unknown>, path: string, known: string[], what: string): void {
+    for (const key of Object.keys(value)) {
+      if (!known.includes(key)) this.add(joinPath(path, key), `unknown key "${key}" in ${what}`);
+    }
+  }
+}
+
+export function formatFileErrors(file: string, errors: FileError[]): string {
+  return errors.map((e) => (e.line ? `${file}:${e.line}: ${e.message}` : `${file}: ${e.message}`)).join('\n');
+}
+
+// --- Schemas ---------------------------------------------------------------
+
+function readMetadata(value: unknown, path: string, what: string, errors: ErrorList): MetadataFields {
+  if (value === null || value === undefined) return {};
+  if (!isMapping(value)) {
+    errors.add(path, `metadata of ${what} must map keys to string, number or boolean`);
+    return {};
+  }
+  const keys = Object.keys(value);
+  if (keys.length > MAX_METADATA_KEYS) {
+    errors.add(path, `${what} has ${keys.length} metadata keys; at most ${MAX_METADATA_KEYS} are allowed`);
+  }
+  const fields: MetadataFields = {};
+  for (const key of keys) {
+    const type = value[key];
+    if (key.length > MAX_METADATA_KEY_LENGTH) {
+      errors.add(joinPath(path, key), `metadata key "${key}" is longer than ${MAX_METADATA_KEY_LENGTH} characters`);
+    } else if (typeof type !== 'string' || !METADATA_TYPES.has(type)) {
+      errors.add(joinPath(path, key), `metadata key "${key}" must be string, number or boolean`);
+    } else {
+      fields[key] = type as MetadataType;
+    }
+  }
+  return fields;
+}
+
+function readTargets(value: unknown, path: string, action: string, errors: ErrorList): AuditLogTarget[] {
+  if (!Array.isArray(value) || value.length === 0) {
+    errors.add(path, `action "${action}" needs at least one target (\`targets:\` with \`- type: ...\`)`);
+    return [];
+  }
+  const targets: AuditLogTarget[] = [];
+  const seen = new Set<string>();
+  value.forEach((raw, index) => {
+    const targetPath = joinPath(path, index);
+    if (!isMapping(raw)) {
+      errors.add(targetPath, `targets of "${action}" must be \`- type: ...\` items`);
+      return;
+    }
+    errors.unknownKeys(raw, targetPath, ['type', 'metadata'], `a target of "${action}"`);
+    const type = raw.type;
+    if (typeof type !== 'string' || !TARGET_PATTERN.test(type)) {
+      errors.add(joinPath(targetPath, 'type'), `a target of "${action}" needs a lowercase \`type\``);
+      return;
+    }
+    if (seen.has(type)) errors.add(joinPath(targetPath, 'type'), `duplicate target "${type}" in "${action}"`);
+    seen.add(type);
+    const metadata = readMetadata(raw.metadata, joinPath(targetPath, 'metadata'), `target "${type}"`, errors);
+    targets.push({ type, metadata });
+  });
+  return targets;
+}
+
+function readActions(document: YamlDocument): { actions: AuditLogActionDefinition[]; errors: FileError[] } {
+  const errors = new ErrorList(document.lines);
+  const { value } = document;
+  if (!isMapping(value) || !Array.isArray(value.actions)) {
+    errors.add('actions', 'expected a top-level `actions:` list');
+    return { actions: [], errors: errors.errors };
+  }
+  errors.unknownKeys(value, '', ['actions'], 'the file');
+
+  const actions: AuditLogActionDefinition[] = [];
+  const seen = new Set<string>();
+  value.actions.forEach((raw, index) => {
+    const path = `actions.${index}`;
+    if (!isMapping(raw)) {
+      errors.add(path, 'expected `- name: ...`');
+      return;
+    }
+    const name = raw.name;
+    if (typeof name !== 'string' || !ACTION_PATTERN.test(name)) {
+      errors.add(joinPath(path, 'name'), 'action needs a lowercase `name`, e.g. user.signed_in');
+      return;
+    }
+    if (seen.has(name)) errors.add(joinPath(path, 'name'), `duplicate action "${name}"`);
+    seen.add(name);
+    errors.unknownKeys(raw, path, ['name', 'targets', 'actor', 'metadata'], `action "${name}"`);
+
+    let actor: MetadataFields = {};
+    if (isMapping(raw.actor)) {
+      errors.unknownKeys(raw.actor, joinPath(path, 'actor'), ['metadata'], `the actor of "${name}"`);
+      actor = readMetadata(raw.actor.metadata, joinPath(path, 'actor.metadata'), `the actor of "${name}"`, errors);
+    } else if (raw.actor !== undefined && raw.actor !== null) {
+      errors.add(joinPath(path, 'actor'), `the actor of "${name}" takes only \`metadata:\``);
+    }
+    actions.push({
+      name,
+      targets: readTargets(raw.targets, joinPath(path, 'targets'), name, errors),
+      actor,
+      metadata: readMetadata(raw.metadata, joinPath(path, 'metadata'), `"${name}"`, errors),
+    });
+  });
+  return { actions, errors: errors.errors };
+}
+
+/**
+ * Parse and validate a schema file, collecting every problem rather than
+ * stopping at the first. `.json` files use the same shape as YAML.
+ */
+export function parseSchemaFile(
+  content: string,
+  fileName: string,
+): { actions: AuditLogActionDefinition[]; errors: FileError[] } {
+  const document = parseDocument(content, fileName);
+  if (!('value' in document)) return { actions: [], errors: [document] };
+  return readActions(document);
+}
+
+function plainYaml(value: string): string {
+  const plain = /^[A-Za-z0-9_./-]+$/.test(value) && !/^(true|false|null|~|-?\d+(\.\d+)?)$/.test(value);
+  return plain ? value : JSON.stringify(value);
+}
+
+function metadataYaml(fields: MetadataFields, indent: string): string[] {
+  const keys = Object.keys(fields);
+  if (keys.length === 0) return [];
+  return [`${indent}metadata:`, ...keys.map((key) => `${indent}  ${plainYaml(key)}: ${fields[key]}`)];
+}
+
+/** The file `schema pull` writes, which `parseSchemaFile` reads back as the same actions */
+export function formatSchemaYaml(actions: AuditLogActionDefinition[]): string {
+  const lines = ['actions:'];
+  for (const action of actions) {
+    lines.push(`  - name: ${plainYaml(action.name)}`, '    targets:');
+    for (const target of action.targets) {
+      lines.push(`      - type: ${plainYaml(target.type)}`, ...metadataYaml(target.metadata, '        '));
+    }
+    if (Object.keys(action.actor).length > 0) lines.push('    actor:', ...metadataYaml(action.actor, '      '));
+    lines.push(...metadataYaml(action.metadata, '    '));
+  }
+  return `${lines.join('\n')}\n`;
+}
+
+interface JsonSchemaObject {
+  type?: string;
+  properties?: Record<string, { type?: string }>;
+}
+
+interface ApiSchema {
+  version: number;
+  targets: Array<{ type: string; metadata?: JsonSchemaObject }>;
+  actor?: { metadata?: JsonSchemaObject };
+  metadata?: JsonSchemaObject;
+}
+
+interface ApiAction {
+  name: string;
+  schema: ApiSchema | null;
+}
+
+export interface DeployedAction {
+  /** Latest schema version; 0 when the action has none */
+  version: number;
+  definition: AuditLogActionDefinition;
+}
+
+function toJsonSchema(fields: MetadataFields): JsonSchemaObject {
+  return { type: 'object', properties: Object.fromEntries(Object.entries(fields).map(([k, t]) => [k, { type: t }])) };
+}
+
+function fromJsonSchema(schema: JsonSchemaObject | undefined): MetadataFields {
+  const fields: MetadataFields = {};
+  for (const [key, property] of Object.entries(schema?.properties ?? {})) {
+    // `integer` validates like a number; anything else is kept as a string
+    const type = property.type === 'integer' ? 'number' : property.type;
+    fields[key] = type && METADATA_TYPES.has(type) ? (type as MetadataType) : 'string';
+  }
+  return fields;
+}
+
+export async function listAuditLogActions(api: ApiOptions): Promise<DeployedAction[]> {
+  const actions = await listAll<ApiAction>({ path: '/audit_logs/actions', ...api });
+  return actions.map(({ name, schema }) => ({
+    version: schema?.version ?? 0,
+    definition: {
+      name,
+      targets: (schema?.targets ?? []).map((t) => ({ type: t.type, metadata: fromJsonSchema(t.metadata) })),
+      actor: fromJsonSchema(schema?.actor?.metadata),
+      metadata: fromJsonSchema(schema?.metadata),
+    },
+  }));
+}
+
+export interface FieldChange {
+  /** `metadata`, `actor` or `target <type>` */
+  where: string;
+  key: string;
+  /** Unset for added keys */
+  from?: MetadataType;
+  /** Unset for removed keys */
+  to?: MetadataType;
+}
+
+export interface ActionChange {
+  name: string;
+  addedTargets: string[];
+  removedTargets: string[];
+  fields: FieldChange[];
+}
+
+export interface AuditLogsPlan {
+  create: AuditLogActionDefinition[];
+  update: Array<{ action: AuditLogActionDefinition; change: ActionChange }>;
+  remove: DeployedAction[];
+  unchanged: string[];
+}
+
+function fieldChanges(where: string, from: MetadataFields, to: MetadataFields): FieldChange[] {
+  const changes: FieldChange[] = [];
+  for (const key of new Set([...Object.keys(from), ...Object.keys(to)])) {
+    if (from[key] === to[key]) continue;
+    changes.push({ where, key, ...(from[key] && { from: from[key] }), ...(to[key] && { to: to[key] }) });
+  }
+  return changes;
+}
+
+function diffAction(deployed: AuditLogActionDefinition, desired: AuditLogActionDefinition): ActionChange {
+  const before = new Map(deployed.targets.map((t) => [t.type, t.metadata]));
+  const after = new Map(desired.targets.map((t) => [t.type, t.metadata]));
+  const fields = [
+    ...fieldChanges('metadata', deployed.metadata, desired.metadata),
+    ...fieldChanges('actor', deployed.actor, desired.actor),
+  ];
+  for (const [type, metadata] of after) {
+    const existing = before.get(type);
+    if (existing) fields.push(...fieldChanges(`target ${type}`, existing, metadata));
+  }
+  return {
+    name: desired.name,
+    addedTargets: [...after.keys()].filter((type) => !before.has(type)),
+    removedTargets: [...before.keys()].filter((type) => !after.has(type)),
+    fields,
+  };
+}
+
+export function planSchemaPush(desired: AuditLogActionDefinition[], deployed: DeployedAction[]): AuditLogsPlan {
+  const byName = new Map(deployed.map((action) => [action.definition.name, action]));
+  const plan: AuditLogsPlan = { create: [], update: [], remove: [], unchanged: [] };
+
+  for (const action of desired) {
+    const existing = byName.get(action.name);
+    if (!existing) {
+      plan.create.push(action);
+      continue;
+    }
+    const change = diffAction(existing.definition, action);
+    const changed = change.addedTargets.length + change.removedTargets.length + change.fields.length > 0;
+    if (changed) plan.update.push({ action, change });
+    else plan.unchanged.push(action.name);
+  }
+
+  const names = new Set(desired.map((action) => action.name));
+  plan.remove = deployed.filter((action) => !names.has(action.definition.name));
+  return plan;
+}
+
+export type SchemaChange =
+  | { kind: 'publish'; action: AuditLogActionDefinition }
+  | { kind: 'remove'; name: string };
+
+/**
+ * Publish a schema version for each created or updated action, then remove
+ * what's gone, in order. Stops at the first failure; `onApplied` has seen
+ * everything that went through, and pushing again plans only what's left.
+ */
+export async function applySchemaPlan(
+  plan: AuditLogsPlan,
+  api: ApiOptions,
+  onApplied: (change: SchemaChange) => void = () => {},
+): Promise<void> {
+  const changes: SchemaChange[] = [
+    ...[...plan.create, ...plan.update.map(({ action }) => action)].map(
+      (action): SchemaChange => ({ kind: 'publish', action }),
+    ),
+    ...plan.remove.map((action): SchemaChange => ({ kind: 'remove', name: action.definition.name })),
+  ];
+  for (const change of changes) {
+    if (change.kind === 'publish') {
+      const { action } = change;
+      await workosRequest({
+        method: 'POST',
+        path: `/audit_logs/actions/${encodeURIComponent(action.name)}/schemas`,
+        body: {
+          targets: action.targets.map((t) => ({ type: t.type, metadata: toJsonSchema(t.metadata) })),
+          actor: { metadata: toJsonSchema(action.actor) },
+          metadata: toJsonSchema(action.metadata),
+        },
+        ...api,
+      });
+    } else {
+      await workosRequest({ method: 'DELETE', path: `/audit_logs/actions/${encodeURIComponent(change.name)}`, ...api });
+    }
+    onApplied(change);
+  }
+}
+
+// --- Exporters -------------------------------------------------------------
+
+export const EXPORTER_TYPES = ['datadog', 'splunk', 's3', 'gcs', 'http'] as const;
+
+export type ExporterType = (typeof EXPORTER_TYPES)[number];
+
+interface ExporterField {
+  required?: boolean;
+  /** Masked when printed */
+  secret?: boolean;
+  /** Allowed values */
+  values?: string[];
+  url?: boolean;
+}
+
+const EXPORTER_FIELDS: Record<ExporterType, Record<string, ExporterField>> = {
+  datadog: {
+    region: { required: true, values: ['us1', 'us3', 'us5', 'eu1', 'ap1', 'us1-fed'] },
+    api_key: { required: true, secret: true },
+  },
+  splunk: {
+    url: { required: true, url: true },
+    token: { required: true, secret: true },
+    index: {},
+  },
+  s3: {
+    bucket: { required: true },
+    region: { required: true },
+    role_arn: { required: true },
+    prefix: {},
+  },
+  gcs: {
+    bucket: { required: true },
+    service_account_key: { required: true, secret: true },
+    prefix: {},
+  },
+  http: {
+    url: { required: true, url: true },
+    authorization: { secret: true },
+  },
+};
+
+export type ExporterConfig = Record<string, string>;
+
+const ENV_REFERENCE_PATTERN = /\$\{([A-Za-z_][A-Za-z0-9_]*)\}/g;
+
+/**
+ * Parse and validate an exporter file for `type`, with `${NAME}` references
+ * filled in from `env`. A reference to an unset variable is an error rather
+ * than an empty value.
+ */
+export function parseExporterFile(
+  content: string,
+  fileName: string,
+  type: ExporterType,
+  env: NodeJS.ProcessEnv = process.env,
+): { config: ExporterConfig; errors: FileError[] } {
+  const document = parseDocument(content, fileName);
+  if (!('value' in document)) return { config: {}, errors: [document] };
+  const errors = new ErrorList(document.lines);
+  const { value } = document;
+  if (!isMapping(value)) {
+    errors.add('', `expected a mapping of ${type} settings`);
+    return { config: {}, errors: errors.errors };
+  }
+
+  const fields = EXPORTER_FIELDS[type];
+  errors.unknownKeys(value, '', Object.keys(fields), `${type} settings`);
+  const config: ExporterConfig = {};
+  for (const [key, field] of Object.entries(fields)) {
+    const raw = value[key];
+    if (raw === undefined || raw === null || raw === '') {
+      if (field.required) errors.add(key, `${type} needs \`${key}\``);
+      continue;
+    }
+    if (typeof raw !== 'string' && typeof raw !== 'number') {
+      errors.add(key, `\`${key}\` must be a single value`);
+      continue;
+    }
+    const setting = String(raw).replace(ENV_REFERENCE_PATTERN, (reference, name: string) => {
+      const resolved = env[name];
+      if (resolved === undefined || resolved === '') errors.add(key, `\`${key}\` uses ${reference}, which is not set`);
+      return resolved ?? '';
+    });
+    if (field.values && !field.values.includes(setting)) {
+      errors.add(key, `\`${key}\` must be one of ${field.values.join(', ')}`);
+    } else if (field.url && !/^https:\/\/[^\s/]+/.test(setting)) {
+      errors.add(key, `\`${key}\` must be an https:// URL`);
+    }
+    config[key] = setting;
+  }
+  return { config, errors: errors.errors };
+}
+
+/** `key: value` lines with secrets masked, for printing what was set */
+export function describeExporter(type: ExporterType, config: ExporterConfig): string[] {
+  const fields = EXPORTER_FIELDS[type];
+  return Object.entries(config).map(([key, value]) => `${key}: ${fields[key]?.secret ? REDACTED : value}`);
+}
+
+/** Point the organization's audit log stream at an exporter, replacing the one it had */
+export async function setAuditLogStream(
+  organizationId: string,
+  type: ExporterType,
+  config: ExporterConfig,
+  api: ApiOptions,
+): Promise<void> {
+  await workosRequest({
+    method: 'PUT',
+    path: `/organizations/${encodeURIComponent(organizationId)}/audit_log_stream`,
+    body: { type, configuration: config },
+    ...api,
+  });
+}
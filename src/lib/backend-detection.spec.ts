@@ -0,0 +1,188 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdirSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { detectPythonProject, detectRailsProject, readPythonDependencies } from './backend-detection.js';
+import { djangoSettingsBlock, generateFernetKey, writeDjangoEnv } from './backend-env.js';
+
+describe('backend-detection', () => {
+  let dir: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(dir, relPath)), { recursive: true });
+    writeFileSync(join(dir, relPath), content);
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'backend-detection-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    vi.unstubAllEnvs();
+  });
+
+  describe('readPythonDependencies', () => {
+    it('reads requirements files, skipping comments and options', () => {
+      write('requirements.txt', '# web\n-r requirements/base.txt\nDjango>=4.2  # LTS\n');
+      write('requirements/base.txt', 'django_environ==0.11\n');
+
+      expect(readPythonDependencies(dir)).toEqual({
+        manifestFile: 'requirements.txt',
+        dependencies: ['django', 'django-environ'],
+      });
+    });
+
+    it('reads PEP 621 and Poetry dependencies from pyproject.toml', () => {
+      write(
+        'pyproject.toml',
+        [
+          '[project]',
+          'dependencies = [',
+          '  "fastapi[standard]>=0.110",',
+          '  "uvicorn",',
+          ']',
+          '',
+          '[tool.poetry.dependencies]',
+          'python = "^3.12"',
+          'Flask = "^3.0"',
+        ].join('\n'),
+      );
+
+      expect(readPythonDependencies(dir)?.dependencies).toEqual(['fastapi', 'flask', 'python', 'uvicorn']);
+    });
+
+    it('reads Pipfile packages', () => {
+      write('Pipfile', '[packages]\nflask = "*"\n\n[dev-packages]\npytest = "*"\n');
+
+      expect(readPythonDependencies(dir)?.dependencies).toEqual(['flask']);
+    });
+
+    it('returns undefined without Python manifests', () => {
+      expect(readPythonDependencies(dir)).toBeUndefined();
+    });
+  });
+
+  describe('detectPythonProject', () => {
+    it('finds the Django settings module from manage.py', () => {
+      write('requirements.txt', 'django\n');
+      write('manage.py', 'import os\nos.environ.setdefault("DJANGO_SETTINGS_MODULE", "config.settings.local")\n');
+      write('config/settings/local.py', 'DEBUG = True\n');
+
+      expect(detectPythonProject(dir)).toMatchObject({
+        framework: 'django',
+        settingsModule: 'config.settings.local',
+        settingsFile: 'config/settings/local.py',
+      });
+    });
+
+    it('falls back to the shallowest settings.py', () => {
+      write('pyproject.toml', '[project]\ndependencies = ["django"]\n');
+      write('mysite/settings.py', '');
+      write('.venv/lib/site/settings.py', '');
+
+      expect(detectPythonProject(dir)).toMatchObject({
+        settingsModule: 'mysite.settings',
+        settingsFile: 'mysite/settings.py',
+      });
+    });
+
+    it('prefers a Flask app factory', () => {
+      write('requirements.txt', 'flask\n');
+      write('app.py', 'from flask import Flask\napp = Flask(__name__)\n');
+      write('myapp/__init__.py', 'def create_app():\n    return Flask(__name__)\n');
+
+      expect(detectPythonProject(dir)).toMatchObject({ framework: 'flask', appTarget: 'myapp:create_app()' });
+    });
+
+    it('finds the FastAPI app object', () => {
+      write('requirements.txt', 'fastapi\nuvicorn\n');
+      write('src/api/main.py', 'from fastapi import FastAPI\n\napi = FastAPI(title="x")\n');
+
+      expect(detectPythonProject(dir)).toMatchObject({ framework: 'fastapi', appTarget: 'src.api.main:api' });
+    });
+
+    it('returns undefined for Python projects without a known framework', () => {
+      write('requirements.txt', 'requests\n');
+
+      expect(detectPythonProject(dir)).toBeUndefined();
+    });
+  });
+
+  describe('detectRailsProject', () => {
+    it('uses credentials when the master key is there', () => {
+      write('Gemfile', 'source "https://rubygems.org"\ngem "rails", "~> 7.1"\n');
+      write('config/credentials.yml.enc', 'encrypted');
+      write('config/master.key', 'key');
+
+      expect(detectRailsProject(dir)).toEqual({ manifestFile: 'Gemfile', credentials: true, dotenv: false });
+    });
+
+    it('cannot use credentials without a master key', () => {
+      vi.stubEnv('RAILS_MASTER_KEY', '');
+      write('Gemfile', "gem 'rails'\ngroup :development do\n  gem 'dotenv-rails'\nend\n");
+      write('config/credentials.yml.enc', 'encrypted');
+
+      expect(detectRailsProject(dir)).toEqual({ manifestFile: 'Gemfile', credentials: false, dotenv: true });
+    });
+
+    it('ignores Gemfiles without Rails', () => {
+      write('Gemfile', 'gem "sinatra"\n');
+
+      expect(detectRailsProject(dir)).toBeUndefined();
+    });
+  });
+
+  describe('writeDjangoEnv', () => {
+    it('writes .env and loads it at the end of settings.py', () => {
+      write('requirements.txt', 'django\n');
+      write('manage.py', 'os.environ.setdefault("DJANGO_SETTINGS_MODULE", "mysite.settings")\n');
+      write('mysite/settings.py', 'DEBUG = True\n');
+      const project = detectPythonProject(dir)!;
+
+      const edited = writeDjangoEnv(dir, project, { WORKOS_API_KEY: 'sk_test_123', WORKOS_CLIENT_ID: 'client_123' });
+
+      const settings = readFileSync(join(dir, 'mysite/settings.py'), 'utf-8');
+      expect(edited).toBe(true);
+      expect(settings).toMatch(/^DEBUG = True\n\n# WorkOS AuthKit/);
+      expect(settings).toContain('load_dotenv(Path(__file__).resolve().parents[1] / ".env")');
+      expect(settings).toContain('WORKOS_API_KEY = os.environ.get("WORKOS_API_KEY")');
+      expect(readFileSync(join(dir, '.env'), 'utf-8')).toMatch(/WORKOS_COOKIE_PASSWORD=[\w-]{43}=/);
+
+      // A rerun leaves the block alone
+      expect(writeDjangoEnv(dir, project, { WORKOS_CLIENT_ID: 'client_123' })).toBe(false);
+      expect(readFileSync(join(dir, 'mysite/settings.py'), 'utf-8')).toBe(settings);
+    });
+
+    it('keeps an existing cookie password', () => {
+      write('.env', 'WORKOS_COOKIE_PASSWORD=existing\n');
+
+      const project = { framework: 'django' as const, manifestFile: 'manage.py', dependencies: [] };
+      writeDjangoEnv(dir, project, { WORKOS_CLIENT_ID: 'client_123' });
+
+      expect(readFileSync(join(dir, '.env'), 'utf-8')).toContain('WORKOS_COOKIE_PASSWORD=existing');
+    });
+  });
+
+  describe('djangoSettingsBlock', () => {
+    const project = { framework: 'django' as const, manifestFile: 'requirements.txt', dependencies: ['django'] };
+
+    it('uses django-environ when the project has it', () => {
+      const block = djangoSettingsBlock('', { ...project, dependencies: ['django', 'django-environ'] }, 2);
+
+      expect(block).toContain('environ.Env.read_env(Path(__file__).resolve().parents[2] / ".env")');
+      expect(block).not.toContain('dotenv');
+    });
+
+    it('adds no loader when settings already load .env', () => {
+      const block = djangoSettingsBlock('from dotenv import load_dotenv\nload_dotenv()\n', project, 1);
+
+      expect(block).not.toContain('load_dotenv');
+      expect(block).toContain('WORKOS_COOKIE_PASSWORD = os.environ.get("WORKOS_COOKIE_PASSWORD")');
+    });
+  });
+
+  it('generates Fernet keys', () => {
+    expect(Buffer.from(generateFernetKey(), 'base64url')).toHaveLength(32);
+  });
+});
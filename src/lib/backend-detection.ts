@@ -0,0 +1,230 @@
+/**
+ * Framework detection for Python and Ruby backends.
+ *
+ * These projects have no package.json, so frameworks are read from their
+ * own manifests: requirements*.txt, pyproject.toml (PEP 621 and Poetry) and
+ * Pipfile for Python, Gemfile for Ruby. Detection also finds what the
+ * integration needs to edit: the Django settings module, the Flask app
+ * factory or FastAPI app, and where Rails keeps its secrets.
+ */
+
+import { existsSync, readFileSync } from 'node:fs';
+import { dirname, join, relative } from 'node:path';
+import fg from 'fast-glob';
+
+export type PythonFramework = 'django' | 'fastapi' | 'flask';
+
+export interface PythonProject {
+  framework: PythonFramework;
+  /** Manifest the framework was found in */
+  manifestFile: string;
+  /** Normalized dependency names (lowercase, `-` separated) */
+  dependencies: string[];
+  /** Django: dotted settings module, e.g. `mysite.settings` */
+  settingsModule?: string;
+  /** Django: settings file relative to the project root */
+  settingsFile?: string;
+  /** Flask/FastAPI: `module:app` or `module:create_app()`, as gunicorn/uvicorn take it */
+  appTarget?: string;
+}
+
+export interface RailsProject {
+  manifestFile: 'Gemfile';
+  /** Encrypted credentials exist and can be decrypted (master key file or RAILS_MASTER_KEY) */
+  credentials: boolean;
+  /** dotenv-rails (or dotenv) is in the Gemfile, so the app reads .env */
+  dotenv: boolean;
+}
+
+const PYTHON_MANIFESTS = ['requirements.txt', 'pyproject.toml', 'Pipfile', 'setup.py'];
+
+const PYTHON_IGNORE = ['**/.venv/**', '**/venv/**', '**/env/**', '**/site-packages/**', '**/node_modules/**'];
+
+function read(path: string): string | null {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return null;
+  }
+}
+
+/** PEP 503 name normalization, minus extras and version specifiers */
+function normalizeName(spec: string): string | null {
+  const match = spec.trim().match(/^([A-Za-z0-9][A-Za-z0-9._-]*)/);
+  return match ? match[1].toLowerCase().replace(/[._]+/g, '-') : null;
+}
+
+function requirementsNames(content: string): string[] {
+  return content
+    .split('\n')
+    .map((line) => line.replace(/#.*/, '').trim())
+    .filter((line) => line && !line.startsWith('-'))
+    .map(normalizeName)
+    .filter((name): name is string => name !== null);
+}
+
+/** Keys of a TOML table, e.g. `[tool.poetry.dependencies]` or Pipfile's `[packages]` */
+function tomlTableKeys(content: string, table: string): string[] {
+  const lines = content.split('\n');
+  const start = lines.findIndex((line) => line.trim() === `[${table}]`);
+  if (start === -1) return [];
+  const names: string[] = [];
+  for (const line of lines.slice(start + 1)) {
+    if (line.trim().startsWith('[')) break;
+    const key = line.match(/^\s*"?([A-Za-z0-9][A-Za-z0-9._-]*)"?\s*=/);
+    const name = key && normalizeName(key[1]);
+    if (name) names.push(name);
+  }
+  return names;
+}
+
+function pyprojectNames(content: string): string[] {
+  const names: string[] = [];
+  // PEP 621: dependencies = ["django>=4.2", ...], possibly over several lines
+  const arrays = content.matchAll(/^\s*dependencies\s*=\s*\[((?:\s*(?:"[^"]*"|'[^']*')\s*,?)*)\s*\]/gm);
+  for (const [, body] of arrays) {
+    for (const [, spec] of body.matchAll(/["']([^"']+)["']/g)) {
+      const name = normalizeName(spec);
+      if (name) names.push(name);
+    }
+  }
+  return [...names, ...tomlTableKeys(content, 'tool.poetry.dependencies')];
+}
+
+/**
+ * Dependencies declared anywhere in the project's Python manifests.
+ * @returns The names and the first manifest found, or undefined for non-Python projects
+ */
+export function readPythonDependencies(
+  installDir: string,
+): { manifestFile: string; dependencies: string[] } | undefined {
+  const manifests = PYTHON_MANIFESTS.filter((file) => existsSync(join(installDir, file)));
+  const requirementFiles = fg.sync(['requirements*.txt', 'requirements/*.txt'], { cwd: installDir });
+  if (manifests.length === 0 && requirementFiles.length === 0) return undefined;
+
+  const names = new Set<string>();
+  for (const file of requirementFiles) {
+    requirementsNames(read(join(installDir, file)) ?? '').forEach((n) => names.add(n));
+  }
+  pyprojectNames(read(join(installDir, 'pyproject.toml')) ?? '').forEach((n) => names.add(n));
+  tomlTableKeys(read(join(installDir, 'Pipfile')) ?? '', 'packages').forEach((n) => names.add(n));
+
+  return { manifestFile: manifests[0] ?? requirementFiles[0], dependencies: [...names].sort() };
+}
+
+/**
+ * Detect which Python package manager the project uses.
+ */
+export function detectPythonPackageManager(installDir: string): { name: string; installCmd: string } {
+  if (existsSync(join(installDir, 'uv.lock'))) {
+    return { name: 'uv', installCmd: 'uv add' };
+  }
+  if ((read(join(installDir, 'pyproject.toml')) ?? '').includes('[tool.poetry]')) {
+    return { name: 'poetry', installCmd: 'poetry add' };
+  }
+  if (existsSync(join(installDir, 'Pipfile'))) {
+    return { name: 'pipenv', installCmd: 'pipenv install' };
+  }
+  return { name: 'pip', installCmd: 'pip install' };
+}
+
+/** `mysite.settings` → `mysite/settings.py`, or the package's `__init__.py` */
+function moduleFile(installDir: string, module: string): string | undefined {
+  const base = module.split('.').join('/');
+  return [`${base}.py`, `${base}/__init__.py`].find((file) => existsSync(join(installDir, file)));
+}
+
+function findDjangoSettings(installDir: string): { settingsModule?: string; settingsFile?: string } {
+  const managePy = read(join(installDir, 'manage.py'));
+  const declared = managePy?.match(/DJANGO_SETTINGS_MODULE['"]\s*,\s*['"]([\w.]+)['"]/)?.[1];
+  if (declared) {
+    return { settingsModule: declared, settingsFile: moduleFile(installDir, declared) };
+  }
+
+  // No manage.py (or a custom one): fall back to the shallowest settings.py
+  const [file] = fg
+    .sync(['*/settings.py', '*/*/settings.py'], { cwd: installDir, ignore: PYTHON_IGNORE })
+    .sort((a, b) => a.split('/').length - b.split('/').length);
+  return file ? { settingsModule: file.replace(/\.py$/, '').split('/').join('.'), settingsFile: file } : {};
+}
+
+/** Dotted module for a source file, e.g. `app/__init__.py` → `app` */
+function moduleName(file: string): string {
+  return file
+    .replace(/(\/__init__)?\.py$/, '')
+    .split('/')
+    .join('.');
+}
+
+/**
+ * Find the app object or factory uvicorn/gunicorn would be pointed at.
+ * Prefers a Flask `create_app()` factory over a module-level app.
+ */
+function findAppTarget(installDir: string, framework: 'fastapi' | 'flask'): string | undefined {
+  const constructor = framework === 'flask' ? 'Flask' : 'FastAPI';
+  const files = fg
+    .sync(['*.py', '*/*.py', 'src/*/*.py'], { cwd: installDir, ignore: PYTHON_IGNORE })
+    .sort((a, b) => a.split('/').length - b.split('/').length);
+
+  let appTarget: string | undefined;
+  for (const file of files) {
+    const source = read(join(installDir, file)) ?? '';
+    if (framework === 'flask' && /^def create_app\(/m.test(source)) return `${moduleName(file)}:create_app()`;
+    const app = source.match(new RegExp(`^(\\w+)\\s*=\\s*${constructor}\\(`, 'm'))?.[1];
+    if (app && !appTarget) appTarget = `${moduleName(file)}:${app}`;
+  }
+  return appTarget;
+}
+
+/**
+ * Detect Django, FastAPI or Flask from the project's Python manifests.
+ * Django also counts when only manage.py is there.
+ */
+export function detectPythonProject(installDir: string): PythonProject | undefined {
+  const manifest = readPythonDependencies(installDir);
+  const hasManagePy = existsSync(join(installDir, 'manage.py'));
+  if (!manifest && !hasManagePy) return undefined;
+
+  const dependencies = manifest?.dependencies ?? [];
+  const manifestFile = manifest?.manifestFile ?? 'manage.py';
+
+  if (dependencies.includes('django') || hasManagePy) {
+    return { framework: 'django', manifestFile, dependencies, ...findDjangoSettings(installDir) };
+  }
+  for (const framework of ['fastapi', 'flask'] as const) {
+    if (dependencies.includes(framework)) {
+      return { framework, manifestFile, dependencies, appTarget: findAppTarget(installDir, framework) };
+    }
+  }
+  return undefined;
+}
+
+/** Gem names from `gem "name"` lines */
+export function readGemfileGems(installDir: string): string[] | undefined {
+  const gemfile = read(join(installDir, 'Gemfile'));
+  if (gemfile === null) return undefined;
+  return [...gemfile.matchAll(/^\s*gem\s+["']([\w-]+)["']/gm)].map(([, name]) => name);
+}
+
+/**
+ * Detect a Rails app from its Gemfile, and whether secrets belong in
+ * encrypted credentials (the Rails default) or a dotenv `.env`.
+ */
+export function detectRailsProject(installDir: string): RailsProject | undefined {
+  const gems = readGemfileGems(installDir);
+  if (!gems || !(gems.includes('rails') || gems.includes('railties'))) return undefined;
+
+  const hasMasterKey = existsSync(join(installDir, 'config', 'master.key')) || Boolean(process.env.RAILS_MASTER_KEY);
+  return {
+    manifestFile: 'Gemfile',
+    credentials: existsSync(join(installDir, 'config', 'credentials.yml.enc')) && hasMasterKey,
+    dotenv: gems.includes('dotenv-rails') || gems.includes('dotenv'),
+  };
+}
+
+/** How many directories up from a file the project root is, for `Path(__file__).resolve().parents[n]` */
+export function parentsToRoot(installDir: string, file: string): number {
+  return relative(installDir, dirname(join(installDir, file)))
+    .split(/[\\/]/)
+    .filter(Boolean).length;
+}
@@ -0,0 +1,160 @@
+/**
+ * Where Django and Rails apps keep WorkOS settings.
+ *
+ * Django reads `.env` through settings.py, so besides the env file the
+ * settings module gets a WorkOS block that loads it (with whatever loader the
+ * project already uses). Rails keeps secrets in encrypted credentials; when
+ * the app can decrypt them and doesn't use dotenv, the values go there
+ * instead of a `.env` Rails would never read.
+ */
+
+import { randomBytes } from 'node:crypto';
+import { existsSync } from 'node:fs';
+import { join } from 'node:path';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { execFileNoThrow } from '../utils/exec-file.js';
+import { applyFileEdits, type FileEdit } from './atomic-write.js';
+import { envExampleEdit } from './env-example.js';
+import { generateCookiePassword } from './env-writer.js';
+import { parentsToRoot, type PythonProject } from './backend-detection.js';
+
+/**
+ * A Fernet key, which is what workos-python's sealed sessions take as the
+ * cookie password (32 random bytes, url-safe base64).
+ */
+export function generateFernetKey(): string {
+  return randomBytes(32).toString('base64url') + '=';
+}
+
+function dotenvEdit(installDir: string, envVars: Record<string, string>, cookiePassword: () => string): FileEdit {
+  return {
+    path: join(installDir, '.env'),
+    content: (current) => {
+      const merged = { ...(current ? parseEnvFile(current) : {}), ...envVars };
+      // Keep an existing password: replacing it signs everyone out
+      if (!merged.WORKOS_COOKIE_PASSWORD) merged.WORKOS_COOKIE_PASSWORD = cookiePassword();
+      return (
+        Object.entries(merged)
+          .map(([key, value]) => `${key}=${value}`)
+          .join('\n') + '\n'
+      );
+    },
+  };
+}
+
+/**
+ * The WorkOS block appended to settings.py. Loads `.env` with the project's
+ * loader: none if settings already load it, django-environ if installed,
+ * python-dotenv otherwise.
+ */
+export function djangoSettingsBlock(settings: string, project: PythonProject, parents: number): string {
+  const envPath = `Path(__file__).resolve().parents[${parents}] / ".env"`;
+  const loadsEnv = /load_dotenv\(|read_env\(/.test(settings);
+
+  const loader = loadsEnv
+    ? []
+    : project.dependencies.includes('django-environ')
+      ? ['import environ  # noqa: E402', '', `environ.Env.read_env(${envPath})`]
+      : ['from dotenv import load_dotenv  # noqa: E402', '', `load_dotenv(${envPath})`];
+
+  return [
+    '',
+    '',
+    '# WorkOS AuthKit (added by the WorkOS CLI)',
+    'import os  # noqa: E402',
+    'from pathlib import Path  # noqa: E402',
+    '',
+    ...loader,
+    ...(loader.length > 0 ? [''] : []),
+    'WORKOS_API_KEY = os.environ.get("WORKOS_API_KEY")',
+    'WORKOS_CLIENT_ID = os.environ.get("WORKOS_CLIENT_ID")',
+    'WORKOS_REDIRECT_URI = os.environ.get("WORKOS_REDIRECT_URI", "http://localhost:8000/auth/callback")',
+    'WORKOS_COOKIE_PASSWORD = os.environ.get("WORKOS_COOKIE_PASSWORD")',
+    '',
+  ].join('\n');
+}
+
+/**
+ * Write `.env` and `.env.example` for a Django project and make the
+ * settings module read them. Settings that already define WORKOS_API_KEY are
+ * left alone.
+ * @returns Whether the settings file was edited
+ */
+export function writeDjangoEnv(installDir: string, project: PythonProject, envVars: Record<string, string>): boolean {
+  const edits: FileEdit[] = [
+    dotenvEdit(installDir, envVars, generateFernetKey),
+    envExampleEdit(installDir, { ...envVars, WORKOS_COOKIE_PASSWORD: '' }),
+  ];
+
+  let editedSettings = false;
+  if (project.settingsFile) {
+    const parents = parentsToRoot(installDir, project.settingsFile);
+    edits.push({
+      path: join(installDir, project.settingsFile),
+      content: (current) => {
+        const settings = current ?? '';
+        if (settings.includes('WORKOS_API_KEY')) return settings;
+        editedSettings = true;
+        return settings.replace(/\n*$/, '') + djangoSettingsBlock(settings, project, parents);
+      },
+    });
+  }
+
+  applyFileEdits(edits);
+  return editedSettings;
+}
+
+/**
+ * Appends a `workos:` section to the decrypted credentials unless one is
+ * there. Values come in through the environment, never the command line.
+ */
+const RAILS_CREDENTIALS_SCRIPT = `
+credentials = Rails.application.credentials
+exit 0 if credentials.config[:workos]
+workos = {
+  "api_key" => ENV["WORKOS_CLI_API_KEY"],
+  "client_id" => ENV["WORKOS_CLI_CLIENT_ID"],
+  "redirect_uri" => ENV["WORKOS_CLI_REDIRECT_URI"],
+  "cookie_password" => ENV["WORKOS_CLI_COOKIE_PASSWORD"],
+}.compact
+section = { "workos" => workos }.to_yaml.delete_prefix("---\\n")
+credentials.write(credentials.read.to_s.rstrip + "\\n\\n" + section)
+`;
+
+/**
+ * Add WorkOS settings to Rails encrypted credentials with `rails runner`,
+ * which decrypts with config/master.key or RAILS_MASTER_KEY.
+ * @returns Whether the credentials were written (or already had a workos section)
+ */
+export async function writeRailsCredentials(
+  installDir: string,
+  values: { apiKey?: string; clientId: string; redirectUri: string },
+): Promise<boolean> {
+  const [command, args]: [string, string[]] = existsSync(join(installDir, 'bin', 'rails'))
+    ? ['bin/rails', ['runner', RAILS_CREDENTIALS_SCRIPT]]
+    : ['bundle', ['exec', 'rails', 'runner', RAILS_CREDENTIALS_SCRIPT]];
+
+  const result = await execFileNoThrow(command, args, {
+    cwd: installDir,
+    timeout: 120_000,
+    env: {
+      ...process.env,
+      ...(values.apiKey ? { WORKOS_CLI_API_KEY: values.apiKey } : {}),
+      WORKOS_CLI_CLIENT_ID: values.clientId,
+      WORKOS_CLI_REDIRECT_URI: values.redirectUri,
+      WORKOS_CLI_COOKIE_PASSWORD: generateCookiePassword(),
+    },
+  });
+  return result.status === 0;
+}
+
+/**
+ * Write `.env` and `.env.example` for a Rails app that reads env vars
+ * (dotenv-rails, or credentials it can't decrypt).
+ */
+export function writeRailsDotenv(installDir: string, envVars: Record<string, string>): void {
+  applyFileEdits([
+    dotenvEdit(installDir, envVars, generateCookiePassword),
+    envExampleEdit(installDir, { ...envVars, WORKOS_COOKIE_PASSWORD: '' }),
+  ]);
+}
@@ -0,0 +1,31 @@
+import { describe, it, expect } from 'vitest';
+import { formatVersion, getBuildInfo, type BuildInfo } from './build-info.js';
+import { getVersion } from './settings.js';
+
+const info: BuildInfo = {
+  version: '1.4.0',
+  commit: '1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b',
+  buildDate: '2026-01-02T03:04:05.000Z',
+  node: 'v22.11.0',
+  platform: 'darwin-arm64',
+};
+
+describe('build-info', () => {
+  it('reads the version from the same constant as the rest of the CLI', () => {
+    const current = getBuildInfo();
+    expect(current.version).toBe(getVersion());
+    expect(current.node).toBe(process.version);
+  });
+
+  it('formats a single line with a short commit', () => {
+    expect(formatVersion(info)).toBe(
+      '1.4.0 (commit 1a2b3c4d5e6f, built 2026-01-02T03:04:05.000Z, node v22.11.0, darwin-arm64)',
+    );
+  });
+
+  it('keeps unknown fields readable when run from source', () => {
+    expect(formatVersion({ ...info, commit: 'unknown', buildDate: 'unknown' })).toContain(
+      'commit unknown, built unknown',
+    );
+  });
+});
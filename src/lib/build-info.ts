@@ -0,0 +1,43 @@
+import { readFileSync } from 'node:fs';
+import { getVersion } from './settings.js';
+
+/** Written next to bin.js by scripts/write-build-info.ts */
+export const BUILD_INFO_FILE = 'build-info.json';
+
+export interface BuildInfo {
+  /** Semantic version from package.json; the one version everything else reads */
+  version: string;
+  /** Git commit the build was made from, or 'unknown' when run from source */
+  commit: string;
+  /** ISO timestamp of the build, or 'unknown' when run from source */
+  buildDate: string;
+  node: string;
+  platform: string;
+}
+
+let cached: BuildInfo | undefined;
+
+export function getBuildInfo(): BuildInfo {
+  if (!cached) {
+    let stamped: Partial<Pick<BuildInfo, 'commit' | 'buildDate'>> = {};
+    try {
+      stamped = JSON.parse(readFileSync(new URL(`../${BUILD_INFO_FILE}`, import.meta.url), 'utf-8'));
+    } catch {
+      // Running from source (tsx) or an unstamped build
+    }
+    cached = {
+      version: getVersion(),
+      commit: stamped.commit ?? 'unknown',
+      buildDate: stamped.buildDate ?? 'unknown',
+      node: process.version,
+      platform: `${process.platform}-${process.arch}`,
+    };
+  }
+  return cached;
+}
+
+/** One line for `--version` and bug reports, e.g. `1.4.0 (commit 1a2b3c4d5e6f, built 2026-01-02T...)` */
+export function formatVersion(info: BuildInfo = getBuildInfo()): string {
+  const commit = info.commit === 'unknown' ? info.commit : info.commit.slice(0, 12);
+  return `${info.version} (commit ${commit}, built ${info.buildDate}, node ${info.node}, ${info.platform})`;
+}
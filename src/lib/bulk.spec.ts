@@ -0,0 +1,102 @@
+import { afterEach, beforeEach, describe, expect, it } from 'vitest';
+import { existsSync, mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { MAX_CONCURRENCY, parseConcurrency, runBulk } from './bulk.js';
+import { WorkOSApiError } from './workos-api.js';
+
+const tick = () => new Promise((resolve) => setTimeout(resolve, 1));
+
+describe('bulk', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'bulk-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('clamps --concurrency and rejects nonsense', () => {
+    expect(parseConcurrency(undefined)).toBe(8);
+    expect(parseConcurrency(1000)).toBe(MAX_CONCURRENCY);
+    expect(() => parseConcurrency(0)).toThrow('Invalid --concurrency 0');
+    expect(() => parseConcurrency(Number.NaN)).toThrow('Invalid --concurrency');
+  });
+
+  it('never runs more than `concurrency` tasks at once', async () => {
+    let running = 0;
+    let peak = 0;
+
+    const result = await runBulk(
+      Array.from({ length: 20 }, (_, i) => i),
+      String,
+      async () => {
+        peak = Math.max(peak, ++running);
+        await tick();
+        running--;
+      },
+      { concurrency: 3 },
+    );
+
+    expect(peak).toBe(3);
+    expect(result).toMatchObject({ total: 20, done: 20, failures: [], interrupted: false });
+  });
+
+  it('keeps going past failures and appends them to the errors file', async () => {
+    const errorsFile = join(dir, 'out', 'errors.jsonl');
+
+    const result = await runBulk(
+      ['a', 'b', 'c'],
+      (item) => item,
+      async (item) => {
+        if (item === 'b') throw new WorkOSApiError('Unprocessable', 422, undefined, [{ message: 'slug taken' }]);
+      },
+      { errorsFile },
+    );
+
+    expect(result.done).toBe(3);
+    expect(result.failures).toEqual([{ key: 'b', error: 'slug taken', status: 422 }]);
+    expect(readFileSync(errorsFile, 'utf-8')).toBe('{"key":"b","error":"slug taken","status":422}\n');
+  });
+
+  it('checkpoints finished tasks on interrupt and skips them on resume', async () => {
+    const checkpointFile = join(dir, 'checkpoint.json');
+    const controller = new AbortController();
+    const first: string[] = [];
+
+    const stopped = await runBulk(
+      ['a', 'b', 'c', 'd'],
+      (item) => item,
+      async (item) => {
+        first.push(item);
+        if (item === 'b') controller.abort();
+      },
+      { concurrency: 1, checkpointFile, signal: controller.signal },
+    );
+
+    expect(stopped.interrupted).toBe(true);
+    expect(JSON.parse(readFileSync(checkpointFile, 'utf-8'))).toEqual({ completed: ['a', 'b'] });
+
+    const second: string[] = [];
+    const resumed = await runBulk(
+      ['a', 'b', 'c', 'd'],
+      (item) => item,
+      async (item) => {
+        second.push(item);
+      },
+      { checkpointFile, resume: true },
+    );
+
+    expect(first).toEqual(['a', 'b']);
+    expect(second).toEqual(['c', 'd']);
+    expect(resumed).toMatchObject({ total: 4, done: 2, skipped: 2, interrupted: false });
+  });
+
+  it('writes no checkpoint unless asked', async () => {
+    await runBulk(['a'], (item) => item, async () => {});
+
+    expect(existsSync(join(dir, 'checkpoint.json'))).toBe(false);
+  });
+});
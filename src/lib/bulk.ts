@@ -0,0 +1,152 @@
+/**
+ * Worker pool for bulk commands such as `roles sync`.
+ *
+ * Tasks run `concurrency` at a time. A failed task is recorded and the run
+ * carries on; with an errors file, each failure is appended to it as one
+ * JSON line when it happens, so the list survives an interrupted run. Rate
+ * limiting is shared through fetchWithRetry (see http-retry.ts): a 429 holds
+ * back every worker rather than each one retrying on its own.
+ *
+ * With a checkpoint file, the keys of finished tasks are saved as the run
+ * goes and once more when it stops, and tasks already in it are skipped on
+ * resume. When the signal aborts, no new task starts; the ones in flight
+ * finish and are checkpointed before runBulk returns.
+ */
+
+import { appendFileSync, existsSync, mkdirSync, readFileSync } from 'node:fs';
+import { dirname } from 'node:path';
+import { writeFileAtomic } from './atomic-write.js';
+import { WorkOSApiError } from './workos-api.js';
+
+/** Enough in-flight requests to hide latency while staying well under the API's rate limits */
+export const DEFAULT_CONCURRENCY = 8;
+export const MAX_CONCURRENCY = 32;
+
+/** How often finished keys are written to the checkpoint while the run goes */
+const CHECKPOINT_INTERVAL_MS = 2_000;
+
+export interface BulkProgress {
+  total: number;
+  /** Finished tasks, failed ones included */
+  done: number;
+  errors: number;
+  /** Finished in an earlier run, per the checkpoint */
+  skipped: number;
+  startedAt: number;
+}
+
+export interface BulkFailure {
+  key: string;
+  error: string;
+  status?: number;
+}
+
+export interface BulkResult extends Omit<BulkProgress, 'errors' | 'startedAt'> {
+  failures: BulkFailure[];
+  /** The signal stopped the run before every task had started */
+  interrupted: boolean;
+}
+
+export interface BulkOptions {
+  concurrency?: number;
+  /** Failures are appended here as JSON lines */
+  errorsFile?: string;
+  /** Keys of finished tasks, for --resume */
+  checkpointFile?: string;
+  /** Skip the tasks the checkpoint file lists as finished */
+  resume?: boolean;
+  signal?: AbortSignal;
+  onProgress?: (progress: BulkProgress) => void;
+}
+
+/** `--concurrency` clamped to 1..MAX_CONCURRENCY; throws on anything that isn't a number */
+export function parseConcurrency(value: number | undefined): number {
+  if (value === undefined) return DEFAULT_CONCURRENCY;
+  if (!Number.isFinite(value) || value < 1) {
+    throw new Error(`Invalid --concurrency ${value}. Use a number from 1 to ${MAX_CONCURRENCY}.`);
+  }
+  return Math.min(MAX_CONCURRENCY, Math.floor(value));
+}
+
+function readCheckpoint(path: string): Set<string> {
+  if (!existsSync(path)) return new Set();
+  const { completed } = JSON.parse(readFileSync(path, 'utf-8')) as { completed?: unknown };
+  return new Set(Array.isArray(completed) ? completed.filter((key): key is string => typeof key === 'string') : []);
+}
+
+function describeFailure(key: string, error: unknown): BulkFailure {
+  if (error instanceof WorkOSApiError) {
+    const detail = error.errors?.length ? error.errors.map((e) => e.message).join(', ') : error.message;
+    return { key, error: detail, status: error.statusCode };
+  }
+  return { key, error: error instanceof Error ? error.message : String(error) };
+}
+
+/**
+ * Run `task` over `items`, `key` naming each one in the checkpoint and the
+ * errors file. Resolves once every started task has settled.
+ */
+export async function runBulk<T>(
+  items: T[],
+  key: (item: T) => string,
+  task: (item: T) => Promise<void>,
+  options: BulkOptions = {},
+): Promise<BulkResult> {
+  const concurrency = parseConcurrency(options.concurrency);
+  const completed =
+    options.checkpointFile && options.resume ? readCheckpoint(options.checkpointFile) : new Set<string>();
+  const pending = items.filter((item) => !completed.has(key(item)));
+  const progress: BulkProgress = {
+    total: items.length,
+    done: 0,
+    errors: 0,
+    skipped: items.length - pending.length,
+    startedAt: Date.now(),
+  };
+  const failures: BulkFailure[] = [];
+
+  if (options.errorsFile) mkdirSync(dirname(options.errorsFile), { recursive: true });
+  let savedAt = Date.now();
+  const saveCheckpoint = () => {
+    if (!options.checkpointFile) return;
+    mkdirSync(dirname(options.checkpointFile), { recursive: true });
+    writeFileAtomic(options.checkpointFile, JSON.stringify({ completed: [...completed] }));
+    savedAt = Date.now();
+  };
+
+  let next = 0;
+  const worker = async () => {
+    while (next < pending.length && !options.signal?.aborted) {
+      const item = pending[next++];
+      const name = key(item);
+      try {
+        await task(item);
+        completed.add(name);
+      } catch (error) {
+        const failure = describeFailure(name, error);
+        failures.push(failure);
+        progress.errors++;
+        if (options.errorsFile) appendFileSync(options.errorsFile, `${JSON.stringify(failure)}\n`);
+      }
+      progress.done++;
+      options.onProgress?.({ ...progress });
+      if (Date.now() - savedAt >= CHECKPOINT_INTERVAL_MS) saveCheckpoint();
+    }
+  };
+
+  options.onProgress?.({ ...progress });
+  try {
+    await Promise.all(Array.from({ length: Math.min(concurrency, pending.length) }, worker));
+  } finally {
+    // Also on interrupt, so --resume starts after the last finished task
+    saveCheckpoint();
+  }
+
+  return {
+    total: progress.total,
+    done: progress.done,
+    skipped: progress.skipped,
+    failures,
+    interrupted: next < pending.length,
+  };
+}
@@ -0,0 +1,213 @@
+/**
+ * Record and replay of API interactions, for demos, bug reports and tests.
+ *
+ * WORKOS_CLI_RECORD=cassette.json saves every API request the CLI sends,
+ * with its response, to a cassette file. WORKOS_CLI_REPLAY=cassette.json
+ * answers requests from that file instead of the network: a request is
+ * matched on its method, URL and body, recorded responses are served in
+ * order (the last one repeats once they run out), and a request with no
+ * recording fails the command with CassetteMismatchError rather than
+ * reaching a real account.
+ *
+ * Request headers aren't recorded, and everything else is scrubbed before
+ * it's written: bearer tokens, API keys, values under secret-looking keys,
+ * and email addresses, which become stable placeholders so requests still
+ * match on replay. Emails in the allowlist (reserved example domains, plus
+ * WORKOS_CLI_SCRUB_ALLOW) are kept; replay scrubs with the allowlist the
+ * cassette was recorded with.
+ */
+
+import { createHash } from 'node:crypto';
+import { readFileSync } from 'node:fs';
+import { writeFileAtomic } from './atomic-write.js';
+import { isSecretKey, REDACTED } from '../utils/redact.js';
+
+export const RECORD_ENV = 'WORKOS_CLI_RECORD';
+export const REPLAY_ENV = 'WORKOS_CLI_REPLAY';
+/** Comma-separated emails or `*` patterns kept as recorded, e.g. `*@acme.test,jane@corp.com` */
+export const SCRUB_ALLOW_ENV = 'WORKOS_CLI_SCRUB_ALLOW';
+
+/** Reserved for documentation and tests (RFC 2606), so never a real person's address */
+const DEFAULT_ALLOW = ['*@example.com', '*@example.org', '*@example.net', '*@*.test', '*@*.example'];
+
+/** Response headers worth replaying; the rest vary per request or identify the account */
+const KEPT_RESPONSE_HEADERS = ['content-type', 'retry-after', 'x-request-id'];
+
+const EMAIL_PATTERN = /[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}/g;
+const API_KEY_PATTERN = /\b(sk_(?:test|live)_)[A-Za-z0-9]{8,}\b/g;
+const BEARER_PATTERN = /\bBearer\s+[A-Za-z0-9._~+/-]+=*/g;
+
+export interface CassetteInteraction {
+  request: { method: string; url: string; body?: unknown };
+  response: { status: number; headers: Record<string, string>; body: string };
+}
+
+export interface Cassette {
+  version: 1;
+  recordedAt: string;
+  /** The allowlist the cassette was scrubbed with */
+  allow: string[];
+  interactions: CassetteInteraction[];
+}
+
+export class CassetteMismatchError extends Error {
+  constructor(method: string, url: string, cassettePath: string, similar: CassetteInteraction[]) {
+    const recorded = similar.map((i) => `${i.request.method} ${i.request.url}`).join(', ');
+    const hint = similar.length > 0 ? `; recorded with a different body or query: ${recorded}` : '';
+    super(`No recorded response for ${method} ${url} in ${cassettePath}${hint}`);
+    this.name = 'CassetteMismatchError';
+  }
+}
+
+function globToRegex(glob: string): RegExp {
+  return new RegExp(`^${glob.replace(/[.+?^${}()|[\]\\]/g, '\\$&').replace(/\*/g, '.*')}$`, 'i');
+}
+
+/** The default allowlist plus WORKOS_CLI_SCRUB_ALLOW */
+export function scrubAllowlist(): string[] {
+  const extra = (process.env[SCRUB_ALLOW_ENV] ?? '').split(',').map((s) => s.trim());
+  return [...DEFAULT_ALLOW, ...extra.filter(Boolean)];
+}
+
+/** A stable stand-in, so the same address scrubs the same way when recording and replaying */
+function placeholderEmail(email: string): string {
+  return `user-${createHash('sha256').update(email.toLowerCase()).digest('hex').slice(0, 8)}@example.com`;
+}
+
+export function scrubText(text: string, allow: string[]): string {
+  const allowed = allow.map(globToRegex);
+  return text
+    .replace(BEARER_PATTERN, `Bearer ${REDACTED}`)
+    .replace(API_KEY_PATTERN, `$1${REDACTED}`)
+    .replace(EMAIL_PATTERN, (email) => (allowed.some((re) => re.test(email)) ? email : placeholderEmail(email)));
+}
+
+/** Scrub every string in a JSON value; values under secret-looking keys are dropped whole */
+export function scrubValue(value: unknown, allow: string[]): unknown {
+  if (typeof value === 'string') return scrubText(value, allow);
+  if (Array.isArray(value)) return value.map((item) => scrubValue(item, allow));
+  if (value === null || typeof value !== 'object') return value;
+  return Object.fromEntries(
+    Object.entries(value).map(([key, item]) => [
+      key,
+      typeof item === 'string' && item && (isSecretKey(key) || key === 'token') ? REDACTED : scrubValue(item, allow),
+    ]),
+  );
+}
+
+function scrubUrl(url: string, allow: string[]): string {
+  const parsed = new URL(url);
+  for (const [key, value] of [...parsed.searchParams]) {
+    parsed.searchParams.set(key, isSecretKey(key) ? REDACTED : scrubText(value, allow));
+  }
+  return scrubText(parsed.toString(), allow);
+}
+
+/** JSON bodies are compared as values, anything else as scrubbed text */
+function scrubBody(body: RequestInit['body'], allow: string[]): unknown {
+  if (body === undefined || body === null) return undefined;
+  const text = String(body);
+  try {
+    return scrubValue(JSON.parse(text), allow);
+  } catch {
+    return scrubText(text, allow);
+  }
+}
+
+function scrubResponseBody(text: string, allow: string[]): string {
+  try {
+    return JSON.stringify(scrubValue(JSON.parse(text), allow));
+  } catch {
+    return scrubText(text, allow);
+  }
+}
+
+function scrubRequest(url: string, init: RequestInit, allow: string[]): CassetteInteraction['request'] {
+  return { method: (init.method ?? 'GET').toUpperCase(), url: scrubUrl(url, allow), body: scrubBody(init.body, allow) };
+}
+
+function requestKey(request: CassetteInteraction['request']): string {
+  return `${request.method} ${request.url} ${JSON.stringify(request.body ?? null)}`;
+}
+
+let recording: { path: string; cassette: Cassette } | null = null;
+let replaying: { path: string; cassette: Cassette; used: Map<string, number> } | null = null;
+
+/** @internal For testing only */
+export function _resetCassette(): void {
+  recording = null;
+  replaying = null;
+}
+
+export function isReplaying(): boolean {
+  return Boolean(process.env[REPLAY_ENV]);
+}
+
+function loadReplay(path: string): NonNullable<typeof replaying> {
+  if (replaying?.path === path) return replaying;
+  let cassette: Cassette;
+  try {
+    cassette = JSON.parse(readFileSync(path, 'utf-8')) as Cassette;
+  } catch (error) {
+    throw new Error(`Could not read cassette ${path}: ${error instanceof Error ? error.message : String(error)}`);
+  }
+  replaying = { path, cassette, used: new Map() };
+  return replaying;
+}
+
+function replay(url: string, init: RequestInit, path: string): Response {
+  const { cassette, used } = loadReplay(path);
+  const allow = cassette.allow ?? DEFAULT_ALLOW;
+  const request = scrubRequest(url, init, allow);
+  const key = requestKey(request);
+  const matches = cassette.interactions.filter((i) => requestKey(i.request) === key);
+  if (matches.length === 0) {
+    const pathname = new URL(request.url).pathname;
+    const similar = cassette.interactions.filter(
+      (i) => i.request.method === request.method && new URL(i.request.url).pathname === pathname,
+    );
+    throw new CassetteMismatchError(request.method, request.url, path, similar.slice(0, 3));
+  }
+  const index = used.get(key) ?? 0;
+  used.set(key, index + 1);
+  const { response } = matches[Math.min(index, matches.length - 1)];
+  const empty = response.status === 204 || response.status === 205 || response.status === 304;
+  return new Response(empty ? null : response.body, { status: response.status, headers: response.headers });
+}
+
+async function record(url: string, init: RequestInit, response: Response, path: string): Promise<void> {
+  if (recording?.path !== path) {
+    recording = {
+      path,
+      cassette: { version: 1, recordedAt: new Date().toISOString(), allow: scrubAllowlist(), interactions: [] },
+    };
+  }
+  const { allow } = recording.cassette;
+  const headers: Record<string, string> = {};
+  for (const name of KEPT_RESPONSE_HEADERS) {
+    const value = response.headers.get(name);
+    if (value) headers[name] = value;
+  }
+  recording.cassette.interactions.push({
+    request: scrubRequest(url, init, allow),
+    response: { status: response.status, headers, body: scrubResponseBody(await response.clone().text(), allow) },
+  });
+  // Written after every interaction, so a command that crashes still leaves what it sent
+  writeFileAtomic(path, `${JSON.stringify(recording.cassette, null, 2)}\n`);
+}
+
+/**
+ * fetch() for API requests: served from the cassette under
+ * WORKOS_CLI_REPLAY, recorded to it under WORKOS_CLI_RECORD, and plain
+ * fetch() otherwise.
+ */
+export async function cassetteFetch(url: string, init: RequestInit = {}): Promise<Response> {
+  const replayPath = process.env[REPLAY_ENV];
+  const recordPath = process.env[RECORD_ENV];
+  if (replayPath && recordPath) throw new Error(`Set ${RECORD_ENV} or ${REPLAY_ENV}, not both`);
+  if (replayPath) return replay(url, init, replayPath);
+
+  const response = await fetch(url, init);
+  if (recordPath) await record(url, init, response, recordPath);
+  return response;
+}
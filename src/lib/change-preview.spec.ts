@@ -0,0 +1,163 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { ChangePlan, ChangeReviewer, formatUnifiedDiff, proposedChange } from './change-preview.js';
+import { createInstallerEventEmitter } from './events.js';
+
+describe('change-preview', () => {
+  let dir: string;
+  const files: Record<string, string> = {};
+  const readCurrent = (path: string) => files[path] ?? null;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'change-preview-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    for (const key of Object.keys(files)) delete files[key];
+  });
+
+  describe('proposedChange', () => {
+    it('treats Write to a missing file as a creation', () => {
+      const change = proposedChange('Write', { file_path: 'app/callback.ts', content: 'x\n' }, '/repo', readCurrent);
+
+      expect(change).toEqual({ path: '/repo/app/callback.ts', before: null, after: 'x\n' });
+    });
+
+    it('applies Edit and MultiEdit to the current content', () => {
+      files['/repo/a.ts'] = 'import auth0 from "auth0";\nauth0.login();\n';
+
+      const edit = { file_path: '/repo/a.ts', old_string: 'auth0.login', new_string: 'signIn' };
+      expect(proposedChange('Edit', edit, '/repo', readCurrent)?.after).toBe('import auth0 from "auth0";\nsignIn();\n');
+
+      const multi = proposedChange(
+        'MultiEdit',
+        {
+          file_path: '/repo/a.ts',
+          edits: [
+            { old_string: 'auth0', new_string: 'workos', replace_all: true },
+            { old_string: '"workos"', new_string: '"@workos-inc/authkit-nextjs"' },
+          ],
+        },
+        '/repo',
+        readCurrent,
+      );
+      expect(multi?.after).toBe('import workos from "@workos-inc/authkit-nextjs";\nworkos.login();\n');
+    });
+
+    it('returns null for edits that do not apply and for other tools', () => {
+      files['/repo/a.ts'] = 'hello\n';
+
+      const edit = { file_path: '/repo/a.ts', old_string: 'nope', new_string: 'x' };
+      expect(proposedChange('Edit', edit, '/repo', readCurrent)).toBeNull();
+      expect(proposedChange('Read', { file_path: '/repo/a.ts' }, '/repo', readCurrent)).toBeNull();
+    });
+  });
+
+  describe('formatUnifiedDiff', () => {
+    it('renders a git-style diff relative to the project', () => {
+      const diff = formatUnifiedDiff({ path: '/repo/a.ts', before: 'one\ntwo\n', after: 'one\nthree\n' }, '/repo', {
+        color: false,
+      });
+
+      expect(diff.split('\n')).toEqual(['--- a/a.ts', '+++ b/a.ts', '@@ -1,2 +1,2 @@', ' one', '-two', '+three']);
+    });
+
+    it('diffs new files against /dev/null', () => {
+      const diff = formatUnifiedDiff({ path: '/repo/new.ts', before: null, after: 'x\n' }, '/repo', { color: false });
+
+      expect(diff).toContain('--- /dev/null');
+      expect(diff).toContain('+x');
+    });
+  });
+
+  describe('ChangePlan', () => {
+    it('records file edits against the recorded content, keeping the first original', () => {
+      const file = join(dir, '.env.local');
+      writeFileSync(file, 'A=1\r\n');
+      const plan = new ChangePlan();
+
+      plan.recordEdits([{ path: file, content: (current) => `${current}B=2\n` }]);
+      plan.recordEdits([{ path: file, content: (current) => `${current}C=3\n` }]);
+
+      expect(readFileSync(file, 'utf-8')).toBe('A=1\r\n');
+      expect(plan.changes()).toEqual([{ path: file, before: 'A=1\r\n', after: 'A=1\r\nB=2\r\nC=3\r\n' }]);
+    });
+
+    it('drops edits that change nothing', () => {
+      const file = join(dir, 'a.ts');
+      writeFileSync(file, 'x\n');
+      const plan = new ChangePlan();
+
+      expect(plan.recordEdits([{ path: file, content: 'x\n' }])).toEqual([]);
+      expect(plan.changes()).toEqual([]);
+    });
+
+    it('is shared with a reviewer, so agent edits see recorded env changes', async () => {
+      const file = join(dir, '.env.local');
+      const plan = new ChangePlan();
+      plan.recordEdits([{ path: file, content: 'WORKOS_CLIENT_ID=client_1\n' }]);
+      const reviewer = new ChangeReviewer({ cwd: dir, dryRun: true, plan });
+
+      await reviewer.review('Edit', { file_path: file, old_string: 'client_1', new_string: 'client_2' });
+
+      expect(plan.changes()).toEqual([{ path: file, before: null, after: 'WORKOS_CLIENT_ID=client_2\n' }]);
+    });
+  });
+
+  describe('ChangeReviewer', () => {
+    it('records dry-run changes without writing and reports the net diff', async () => {
+      const file = join(dir, 'auth.ts');
+      writeFileSync(file, 'const provider = "auth0";\n');
+      const reviewer = new ChangeReviewer({ cwd: dir, dryRun: true });
+
+      const first = await reviewer.review('Edit', { file_path: file, old_string: 'auth0', new_string: 'tmp' });
+      await reviewer.review('Edit', { file_path: file, old_string: 'tmp', new_string: 'workos' });
+
+      expect(first?.behavior).toBe('deny');
+      expect(readFileSync(file, 'utf-8')).toBe('const provider = "auth0";\n');
+      expect(reviewer.recordedChanges()).toEqual([
+        { path: file, before: 'const provider = "auth0";\n', after: 'const provider = "workos";\n' },
+      ]);
+      expect(reviewer.formatSummary()).toContain('1 file(s) would change');
+    });
+
+    it('denies commands in dry-run mode and ignores unrelated tools', async () => {
+      const reviewer = new ChangeReviewer({ cwd: dir, dryRun: true });
+
+      expect((await reviewer.review('Bash', { command: 'npm install' }))?.behavior).toBe('deny');
+      expect(await reviewer.review('Read', { file_path: join(dir, 'x') })).toBeNull();
+    });
+
+    it('skips a change when the user chooses Skip', async () => {
+      const emitter = createInstallerEventEmitter();
+      const choices: string[][] = [];
+      emitter.on('prompt:request', ({ id, options }) => {
+        choices.push(options ?? []);
+        emitter.emit('prompt:response', { id, value: 'Skip' });
+      });
+      const reviewer = new ChangeReviewer({ cwd: dir, showDiffs: true, interactive: true, emitter });
+
+      const result = await reviewer.review('Write', { file_path: join(dir, 'callback.ts'), content: 'x\n' });
+
+      expect(result?.behavior).toBe('deny');
+      expect(choices).toEqual([['Apply', 'View diff', 'Skip']]);
+    });
+
+    it('shows the diff before asking again when the user chooses View diff', async () => {
+      const emitter = createInstallerEventEmitter();
+      const answers = ['View diff', 'Apply'];
+      const diffs: string[] = [];
+      emitter.on('prompt:request', ({ id }) => emitter.emit('prompt:response', { id, value: answers.shift() ?? '' }));
+      emitter.on('change:diff', ({ path }) => diffs.push(path));
+      const reviewer = new ChangeReviewer({ cwd: dir, showDiffs: true, interactive: true, emitter });
+
+      const result = await reviewer.review('Write', { file_path: join(dir, 'callback.ts'), content: 'x\n' });
+
+      expect(result?.behavior).toBe('allow');
+      expect(diffs).toEqual(['callback.ts']);
+    });
+  });
+});
@@ -0,0 +1,298 @@
+/**
+ * Preview agent file changes before they're written.
+ *
+ * With --show-diffs every Write/Edit the agent proposes is turned into a
+ * before/after pair and rendered as a unified diff; interactively the user
+ * can view each diff and apply or skip the change. With --dry-run nothing
+ * is written: changes are applied to an in-memory overlay so later edits to
+ * the same file diff correctly, and the full change set is printed at the end.
+ * With --diff-only the overlay is a ChangePlan shared with the rest of the
+ * run, so env and config writes land in the same change set.
+ */
+
+import { readFileSync } from 'node:fs';
+import { isAbsolute, join } from 'node:path';
+import chalk from 'chalk';
+import { createTwoFilesPatch } from 'diff';
+import type { InstallerEventEmitter, InstallerEvents } from './events.js';
+import { editedContent, type FileEdit } from './atomic-write.js';
+import { relativePosix } from '../utils/paths.js';
+
+export interface ProposedChange {
+  /** Absolute path */
+  path: string;
+  /** Null when the file is being created */
+  before: string | null;
+  after: string;
+}
+
+type PermissionResult =
+  | { behavior: 'allow'; updatedInput: Record<string, unknown> }
+  | { behavior: 'deny'; message: string };
+
+interface EditOperation {
+  old_string: string;
+  new_string: string;
+  replace_all?: boolean;
+}
+
+function applyEdit(content: string, edit: EditOperation): string | null {
+  if (!content.includes(edit.old_string)) return null;
+  return edit.replace_all
+    ? content.split(edit.old_string).join(edit.new_string)
+    : content.replace(edit.old_string, () => edit.new_string);
+}
+
+/**
+ * Compute the before/after contents of a Write, Edit or MultiEdit tool call.
+ * Returns null for other tools and for edits that won't apply (the tool
+ * itself will report those to the agent).
+ */
+export function proposedChange(
+  toolName: string,
+  input: Record<string, unknown>,
+  cwd: string,
+  readCurrent: (path: string) => string | null,
+): ProposedChange | null {
+  const filePath = typeof input.file_path === 'string' ? input.file_path : null;
+  if (!filePath) return null;
+  const path = isAbsolute(filePath) ? filePath : join(cwd, filePath);
+  const before = readCurrent(path);
+
+  if (toolName === 'Write' && typeof input.content === 'string') {
+    return { path, before, after: input.content };
+  }
+
+  const edits: EditOperation[] | null =
+    toolName === 'Edit'
+      ? [input as unknown as EditOperation]
+      : toolName === 'MultiEdit' && Array.isArray(input.edits)
+        ? (input.edits as EditOperation[])
+        : null;
+  if (!edits || before === null) return null;
+
+  let after: string | null = before;
+  for (const edit of edits) {
+    if (typeof edit.old_string !== 'string' || typeof edit.new_string !== 'string') return null;
+    after = applyEdit(after, edit);
+    if (after === null) return null;
+  }
+  return { path, before, after };
+}
+
+function colorizeDiffLine(line: string): string {
+  if (line.startsWith('+++') || line.startsWith('---')) return chalk.bold(line);
+  if (line.startsWith('@@')) return chalk.cyan(line);
+  if (line.startsWith('+')) return chalk.green(line);
+  if (line.startsWith('-')) return chalk.red(line);
+  return line;
+}
+
+/**
+ * Render a change as a git-style unified diff.
+ */
+export function formatUnifiedDiff(change: ProposedChange, cwd: string, options: { color?: boolean } = {}): string {
+  const rel = relativePosix(cwd, change.path) || change.path;
+  const patch = createTwoFilesPatch(
+    change.before === null ? '/dev/null' : `a/${rel}`,
+    `b/${rel}`,
+    change.before ?? '',
+    change.after,
+    undefined,
+    undefined,
+    { context: 3 },
+  );
+  const lines = patch
+    .split('\n')
+    .filter((line) => !line.startsWith('Index:') && !line.startsWith('===='))
+    .filter((line, i, all) => line !== '' || i < all.length - 1);
+  return (options.color === false ? lines : lines.map(colorizeDiffLine)).join('\n');
+}
+
+/** Added/removed line counts, for one-line summaries */
+export function diffStats(change: ProposedChange): { added: number; removed: number } {
+  const lines = formatUnifiedDiff(change, '/', { color: false }).split('\n');
+  return {
+    added: lines.filter((l) => l.startsWith('+') && !l.startsWith('+++')).length,
+    removed: lines.filter((l) => l.startsWith('-') && !l.startsWith('---')).length,
+  };
+}
+
+/**
+ * Changes recorded instead of written: file contents after the changes so
+ * far, and each file's content before the first one.
+ */
+export class ChangePlan {
+  private overlay = new Map<string, string>();
+  private originals = new Map<string, string | null>();
+
+  /** Content with the recorded changes applied; null if the file doesn't exist */
+  read = (path: string): string | null => {
+    const staged = this.overlay.get(path);
+    if (staged !== undefined) return staged;
+    try {
+      return readFileSync(path, 'utf-8');
+    } catch {
+      return null;
+    }
+  };
+
+  record(change: ProposedChange): void {
+    if (!this.originals.has(change.path)) this.originals.set(change.path, change.before);
+    this.overlay.set(change.path, change.after);
+  }
+
+  /** Record edits as `applyFileEdits` would write them (see recordFileEdits) */
+  recordEdits = (edits: FileEdit[]): string[] => {
+    const recorded: string[] = [];
+    for (const edit of edits) {
+      const before = this.read(edit.path);
+      const after = editedContent(edit, before);
+      if (after === before) continue;
+      this.record({ path: edit.path, before, after });
+      recorded.push(edit.path);
+    }
+    return recorded;
+  };
+
+  /** Net change per file */
+  changes(): ProposedChange[] {
+    return [...this.originals.entries()]
+      .map(([path, before]) => ({ path, before, after: this.overlay.get(path) ?? '' }))
+      .filter((change) => change.before !== change.after);
+  }
+}
+
+export interface ChangeReviewOptions {
+  cwd: string;
+  dryRun?: boolean;
+  /** Dry-run changes go here; shared with the run for --diff-only */
+  plan?: ChangePlan;
+  /** --diff-only: the summary points at the patch rather than at --show-diffs */
+  diffOnly?: boolean;
+  showDiffs?: boolean;
+  /** Prompt per change; omitted in CI, where diffs are only printed */
+  interactive?: boolean;
+  emitter?: InstallerEventEmitter;
+}
+
+const APPLY = 'Apply';
+const VIEW_DIFF = 'View diff';
+const SKIP = 'Skip';
+
+/**
+ * Gatekeeper for agent file writes when previewing (see module comment).
+ */
+export class ChangeReviewer {
+  /** Dry-run changes recorded so far */
+  private readonly plan: ChangePlan;
+  private promptCount = 0;
+
+  constructor(private readonly options: ChangeReviewOptions) {
+    this.plan = options.plan ?? new ChangePlan();
+  }
+
+  private show(change: ProposedChange): void {
+    this.options.emitter?.emit('change:diff', {
+      path: relativePosix(this.options.cwd, change.path),
+      diff: formatUnifiedDiff(change, this.options.cwd),
+    });
+  }
+
+  private ask(message: string, choices: string[]): Promise<string> {
+    const { emitter } = this.options;
+    if (!emitter) return Promise.resolve(APPLY);
+    const id = `change-review-${++this.promptCount}`;
+    return new Promise((resolve) => {
+      const onResponse = ({ id: responseId, value }: InstallerEvents['prompt:response']) => {
+        if (responseId !== id) return;
+        emitter.off('prompt:response', onResponse);
+        resolve(value);
+      };
+      emitter.on('prompt:response', onResponse);
+      emitter.emit('prompt:request', { id, message, options: choices });
+    });
+  }
+
+  /**
+   * Decide on a tool call. Returns null for calls this reviewer doesn't
+   * handle, so the regular permission checks apply.
+   */
+  async review(toolName: string, input: Record<string, unknown>): Promise<PermissionResult | null> {
+    if (this.options.dryRun && toolName === 'Bash') {
+      return {
+        behavior: 'deny',
+        message:
+          'Dry run: commands are not executed. Skip builds, add dependencies by editing the manifest ' +
+          '(package.json, requirements.txt, Gemfile, go.mod, ...) instead of installing them, and continue with ' +
+          'the code changes.',
+      };
+    }
+
+    const change = proposedChange(toolName, input, this.options.cwd, this.plan.read);
+    if (!change) return null;
+    const rel = relativePosix(this.options.cwd, change.path);
+
+    if (this.options.dryRun) {
+      this.plan.record(change);
+      if (this.options.showDiffs) this.show(change);
+      return {
+        behavior: 'deny',
+        message:
+          `Dry run: the change to ${rel} was recorded but not written. Continue as if it had been applied; ` +
+          'reading the file will still show the original content. Do not retry this change.',
+      };
+    }
+
+    if (this.options.showDiffs && !this.options.interactive) {
+      this.show(change);
+      return { behavior: 'allow', updatedInput: input };
+    }
+
+    if (this.options.showDiffs) {
+      const { added, removed } = diffStats(change);
+      const summary = `${change.before === null ? 'Create' : 'Change'} ${rel} (+${added} -${removed})`;
+      let choice = await this.ask(`${summary}?`, [APPLY, VIEW_DIFF, SKIP]);
+      if (choice === VIEW_DIFF) {
+        this.show(change);
+        choice = await this.ask(`Apply this change to ${rel}?`, [APPLY, SKIP]);
+      }
+      if (choice === SKIP) {
+        return {
+          behavior: 'deny',
+          message: `The user declined this change to ${rel}. Do not retry it; continue with the remaining steps.`,
+        };
+      }
+    }
+
+    return { behavior: 'allow', updatedInput: input };
+  }
+
+  /** Net change per file recorded in dry-run mode */
+  recordedChanges(): ProposedChange[] {
+    return this.plan.changes();
+  }
+
+  /**
+   * Dry-run summary: each file with its line stats, plus the full diff
+   * when --show-diffs is set.
+   */
+  formatSummary(): string {
+    const changes = this.recordedChanges();
+    if (changes.length === 0) return 'Dry run complete: no file changes were proposed.';
+
+    const files = changes.map((change) => {
+      const { added, removed } = diffStats(change);
+      const rel = relativePosix(this.options.cwd, change.path);
+      return `  ${change.before === null ? chalk.green('new') : 'mod'}  ${rel} ${chalk.dim(`+${added} -${removed}`)}`;
+    });
+    const diffs = this.options.showDiffs ? changes.map((c) => formatUnifiedDiff(c, this.options.cwd)) : [];
+    const hint =
+      this.options.showDiffs || this.options.diffOnly
+        ? []
+        : [chalk.dim('Re-run with --show-diffs to see the full diffs.')];
+
+    const title = `Dry run complete: ${changes.length} file(s) would change. Nothing was written.`;
+    return [title, ...files, ...hint, '', ...diffs].join('\n').trimEnd();
+  }
+}
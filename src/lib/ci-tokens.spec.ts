@@ -0,0 +1,48 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { createCiToken, getCiToken, parseScopes } from './ci-tokens.js';
+
+describe('ci-tokens', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+
+  beforeEach(() => {
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    delete process.env.WORKOS_CLI_TOKEN;
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+    delete process.env.WORKOS_CLI_TOKEN;
+  });
+
+  it('parses comma-separated scopes and rejects unknown ones', () => {
+    expect(parseScopes('env:read, redirect_uris:write,env:read')).toEqual(['env:read', 'redirect_uris:write']);
+    expect(() => parseScopes('env:write')).toThrow(/Unknown scope\(s\): env:write\. Available: env:read/);
+    expect(() => parseScopes(' , ')).toThrow(/at least one scope/);
+  });
+
+  it('reads the token from WORKOS_CLI_TOKEN, ignoring an empty value', () => {
+    expect(getCiToken()).toBeNull();
+    process.env.WORKOS_CLI_TOKEN = '';
+    expect(getCiToken()).toBeNull();
+    process.env.WORKOS_CLI_TOKEN = 'wos_ci_abc';
+    expect(getCiToken()).toBe('wos_ci_abc');
+  });
+
+  it('mints a token with the environment API key', async () => {
+    const created = { id: 'ci_1', name: 'github-actions', scopes: ['env:read'], created_at: '', token: 'wos_ci_abc' };
+    mockFetch.mockResolvedValue({
+      ok: true,
+      status: 201,
+      json: () => Promise.resolve(created),
+      text: () => Promise.resolve(JSON.stringify(created)),
+    });
+
+    expect(await createCiToken('github-actions', ['env:read'], { apiKey: 'sk_test_abc' })).toEqual(created);
+    const [url, init] = mockFetch.mock.calls[0];
+    expect(String(url)).toBe('https://api.workos.com/cli/ci_tokens');
+    expect(init.method).toBe('POST');
+    expect(JSON.parse(init.body)).toEqual({ name: 'github-actions', scopes: ['env:read'] });
+  });
+});
@@ -0,0 +1,77 @@
+/**
+ * Restricted, long-lived credentials for pipelines.
+ *
+ * A CI token is minted with the environment's API key and can only do what
+ * its scopes allow. Pipelines pass it as WORKOS_CLI_TOKEN; every command
+ * that takes an API key accepts it in the key's place, and nothing reads or
+ * writes the credentials file or the profiles. The API checks the scopes,
+ * so a command outside them fails with 403.
+ */
+
+import { listAll } from './pagination.js';
+import { workosRequest } from './workos-api.js';
+
+export const CI_TOKEN_ENV = 'WORKOS_CLI_TOKEN';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+/** Scopes a CI token can be granted, with what each allows */
+export const CI_TOKEN_SCOPES: Record<string, string> = {
+  'env:read': 'Read environment configuration (env diff, whoami)',
+  'redirect_uris:write': 'Register and remove redirect URIs',
+  'cors:write': 'Add and remove CORS origins',
+  'organizations:read': 'List and show organizations',
+  'organizations:write': 'Create, update and delete organizations',
+  'users:read': 'List and show users',
+  'users:write': 'Create, update and delete users',
+  'roles:write': 'Sync roles and permissions',
+};
+
+export interface CiToken {
+  id: string;
+  name: string;
+  scopes: string[];
+  created_at: string;
+  last_used_at?: string | null;
+}
+
+/** Only returned when the token is created */
+export interface CreatedCiToken extends CiToken {
+  token: string;
+}
+
+/** CI token from WORKOS_CLI_TOKEN, if set */
+export function getCiToken(): string | null {
+  return process.env[CI_TOKEN_ENV] || null;
+}
+
+/** `--scopes env:read,redirect_uris:write` to a list; throws on unknown or missing scopes */
+export function parseScopes(value: string): string[] {
+  const scopes = [...new Set(value.split(',').map((s) => s.trim()))].filter(Boolean);
+  if (scopes.length === 0) throw new Error('--scopes needs at least one scope');
+  const unknown = scopes.filter((s) => !(s in CI_TOKEN_SCOPES));
+  if (unknown.length > 0) {
+    throw new Error(`Unknown scope(s): ${unknown.join(', ')}. Available: ${Object.keys(CI_TOKEN_SCOPES).join(', ')}`);
+  }
+  return scopes;
+}
+
+export async function createCiToken(name: string, scopes: string[], options: ApiOptions): Promise<CreatedCiToken> {
+  return workosRequest<CreatedCiToken>({ method: 'POST', path: '/cli/ci_tokens', body: { name, scopes }, ...options });
+}
+
+export async function listCiTokens(options: ApiOptions): Promise<CiToken[]> {
+  return listAll<CiToken>({ path: '/cli/ci_tokens', ...options });
+}
+
+export async function revokeCiToken(id: string, options: ApiOptions): Promise<void> {
+  await workosRequest({ method: 'DELETE', path: `/cli/ci_tokens/${id}`, ...options });
+}
+
+/** The token the request is made with: its name, scopes and environment */
+export async function describeCiToken(options: ApiOptions): Promise<CiToken & { environment_type?: string }> {
+  return workosRequest({ method: 'GET', path: '/cli/ci_tokens/current', ...options });
+}
@@ -0,0 +1,73 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { readNeededEnvVars, renderCiWorkflow } from './ci-workflow.js';
+
+describe('ci-workflow', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'ci-workflow-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('readNeededEnvVars', () => {
+    it('takes the WorkOS keys documented in .env.example', () => {
+      writeFileSync(
+        join(dir, '.env.example'),
+        'DATABASE_URL=\n# WorkOS API key\nWORKOS_API_KEY=sk_test_...\n' +
+          'NEXT_PUBLIC_WORKOS_REDIRECT_URI=http://localhost:3000/callback\n',
+      );
+      writeFileSync(join(dir, '.env.local'), 'WORKOS_CLIENT_ID=client_123\n');
+
+      const vars = readNeededEnvVars(dir);
+
+      expect(vars.map((v) => v.key)).toEqual(['WORKOS_API_KEY', 'NEXT_PUBLIC_WORKOS_REDIRECT_URI']);
+      expect(vars[0]).toMatchObject({ placeholder: 'sk_test_...', description: expect.stringContaining('API key') });
+    });
+
+    it('falls back to the env files without copying their values', () => {
+      writeFileSync(join(dir, '.env.local'), 'WORKOS_API_KEY=sk_test_secret\nWORKOS_CLIENT_ID=client_123\n');
+
+      const vars = readNeededEnvVars(dir);
+
+      expect(vars.map((v) => v.key)).toEqual(['WORKOS_API_KEY', 'WORKOS_CLIENT_ID']);
+      expect(JSON.stringify(vars)).not.toContain('sk_test_secret');
+    });
+
+    it('is empty for a project without WorkOS', () => {
+      expect(readNeededEnvVars(dir)).toEqual([]);
+    });
+  });
+
+  describe('renderCiWorkflow', () => {
+    const vars = [
+      { key: 'WORKOS_API_KEY', description: 'WorkOS API key', placeholder: 'sk_test_...' },
+      { key: 'WORKOS_CLIENT_ID', placeholder: 'client_...' },
+    ];
+
+    it('maps each variable to a repository secret and runs doctor', () => {
+      const workflow = renderCiWorkflow('github-actions', { vars, branch: 'trunk', version: '1.2.3' });
+
+      expect(workflow).toContain('#   WORKOS_API_KEY: WorkOS API key (e.g. sk_test_...)');
+      expect(workflow).toContain('branches: [trunk]');
+      expect(workflow).toContain('      WORKOS_CLIENT_ID: ${{ secrets.WORKOS_CLIENT_ID }}');
+      expect(workflow).toContain('for name in WORKOS_API_KEY WORKOS_CLIENT_ID; do');
+      expect(workflow).toContain('run: npx --yes workos@1.2.3 doctor --json --skip-ai');
+      expect(workflow).not.toContain('scan secrets');
+    });
+
+    it('writes a GitLab job with the optional secret scan', () => {
+      const workflow = renderCiWorkflow('gitlab-ci', { vars, scanSecrets: true, version: '1.2.3' });
+
+      expect(workflow).toContain('#     - local: .gitlab/ci/workos.yml');
+      expect(workflow).toContain('- if: $CI_COMMIT_BRANCH == $CI_DEFAULT_BRANCH');
+      expect(workflow).toContain('    - npx --yes workos@1.2.3 scan secrets\n');
+      expect(workflow).not.toContain('secrets.');
+    });
+  });
+});
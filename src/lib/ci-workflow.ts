@@ -0,0 +1,166 @@
+/**
+ * CI workflows for a project AuthKit was installed into (`workos generate ci`).
+ *
+ * The workflow maps the WorkOS variables the project needs to CI secrets,
+ * fails when one isn't set, and runs `workos doctor --json` (and optionally
+ * `workos scan secrets`) as checks. Which variables are needed comes from
+ * .env.example, where the env writer documents every key it writes; the
+ * real env files are only a fallback, and their values are never read into
+ * the workflow.
+ */
+
+import { existsSync, readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { ENV_EXAMPLE_FILE, toExampleEntry } from './env-example.js';
+
+export const CI_PROVIDERS = ['github-actions', 'gitlab-ci'] as const;
+export type CiProvider = (typeof CI_PROVIDERS)[number];
+
+/** Where each provider's workflow is written, relative to the project */
+export const CI_WORKFLOW_PATHS: Record<CiProvider, string> = {
+  'github-actions': '.github/workflows/workos.yml',
+  // GitLab reads a single .gitlab-ci.yml, so ours is a file for it to include
+  'gitlab-ci': '.gitlab/ci/workos.yml',
+};
+
+/** Env files read when the project has no .env.example */
+const FALLBACK_ENV_FILES = ['.env.local', '.env'];
+
+const WORKOS_KEY_PATTERN = /(^|_)WORKOS_/;
+
+export interface CiEnvVar {
+  key: string;
+  description?: string;
+  /** Example value for the docs; empty for values with nothing safe to show */
+  placeholder: string;
+}
+
+function readKeys(path: string): string[] {
+  if (!existsSync(path)) return [];
+  try {
+    return Object.keys(parseEnvFile(readFileSync(path, 'utf-8')));
+  } catch {
+    return [];
+  }
+}
+
+/**
+ * The WorkOS variables the project uses, in the order they're documented.
+ * Empty when nothing in the project mentions one.
+ */
+export function readNeededEnvVars(installDir: string): CiEnvVar[] {
+  let keys = readKeys(join(installDir, ENV_EXAMPLE_FILE));
+  if (!keys.some((key) => WORKOS_KEY_PATTERN.test(key))) {
+    keys = FALLBACK_ENV_FILES.flatMap((file) => readKeys(join(installDir, file)));
+  }
+  return [...new Set(keys)]
+    .filter((key) => WORKOS_KEY_PATTERN.test(key))
+    .map((key) => {
+      const { value, description } = toExampleEntry(key, '');
+      return { key, description, placeholder: value };
+    });
+}
+
+export interface CiWorkflowOptions {
+  vars: CiEnvVar[];
+  /** Also run `workos scan secrets` */
+  scanSecrets?: boolean;
+  /** Branch whose pushes run the workflow (pull and merge requests always do) */
+  branch?: string;
+  /** `workos` version the workflow runs, so CI matches the local CLI */
+  version: string;
+}
+
+function describeVars(vars: CiEnvVar[]): string[] {
+  return vars.map(({ key, description, placeholder }) => {
+    const example = placeholder ? ` (e.g. ${placeholder})` : '';
+    return `#   ${key}${description ? `: ${description}` : ''}${example}`;
+  });
+}
+
+/** POSIX shell that fails the job, naming each variable that's unset or empty */
+function checkVarsScript(vars: CiEnvVar[], describe: (name: string) => string): string[] {
+  return [
+    'missing=0',
+    `for name in ${vars.map((v) => v.key).join(' ')}; do`,
+    `  if [ -z "$(printenv "$name")" ]; then echo "${describe('$name')}"; missing=1; fi`,
+    'done',
+    'test "$missing" = 0',
+  ];
+}
+
+function indent(lines: string[], depth: number): string {
+  return lines.map((line) => (line ? ' '.repeat(depth) + line : line)).join('\n');
+}
+
+function renderGitHubActions(options: CiWorkflowOptions): string {
+  const { vars, version } = options;
+  const cli = `npx --yes workos@${version}`;
+  const lines = [
+    '# Generated by `workos generate ci github-actions`.',
+    '#',
+    '# Add these repository secrets (Settings → Secrets and variables → Actions):',
+    '#',
+    ...describeVars(vars),
+    '',
+    'name: WorkOS',
+    '',
+    'on:',
+    '  push:',
+    `    branches: [${options.branch ?? 'main'}]`,
+    '  pull_request:',
+    '',
+    'jobs:',
+    '  workos:',
+    '    runs-on: ubuntu-latest',
+    '    env:',
+    ...vars.map(({ key }) => `      ${key}: \${{ secrets.${key} }}`),
+    '    steps:',
+    '      - uses: actions/checkout@v4',
+    '      - uses: actions/setup-node@v4',
+    '        with:',
+    '          node-version: 20',
+    '      - name: Check the WorkOS secrets are set',
+    '        run: |',
+    indent(checkVarsScript(vars, (name) => `::error::Repository secret ${name} is not set`), 10),
+    '      - name: Check the WorkOS integration',
+    `        run: ${cli} doctor --json --skip-ai`,
+  ];
+  if (options.scanSecrets) {
+    lines.push('      - name: Scan for committed WorkOS secrets', `        run: ${cli} scan secrets`);
+  }
+  return lines.join('\n') + '\n';
+}
+
+function renderGitLabCi(options: CiWorkflowOptions): string {
+  const { vars, version } = options;
+  const cli = `npx --yes workos@${version}`;
+  const lines = [
+    '# Generated by `workos generate ci gitlab-ci`. Include it from .gitlab-ci.yml:',
+    '#',
+    '#   include:',
+    `#     - local: ${CI_WORKFLOW_PATHS['gitlab-ci']}`,
+    '#',
+    '# Add these CI/CD variables (Settings → CI/CD → Variables), masked:',
+    '#',
+    ...describeVars(vars),
+    '',
+    'workos:',
+    '  stage: test',
+    '  image: node:20',
+    '  rules:',
+    '    - if: $CI_PIPELINE_SOURCE == "merge_request_event"',
+    `    - if: $CI_COMMIT_BRANCH == ${options.branch ? `"${options.branch}"` : '$CI_DEFAULT_BRANCH'}`,
+    '  script:',
+    '    - |',
+    indent(checkVarsScript(vars, (name) => `CI/CD variable ${name} is not set`), 6),
+    `    - ${cli} doctor --json --skip-ai`,
+  ];
+  if (options.scanSecrets) lines.push(`    - ${cli} scan secrets`);
+  return lines.join('\n') + '\n';
+}
+
+export function renderCiWorkflow(provider: CiProvider, options: CiWorkflowOptions): string {
+  return provider === 'gitlab-ci' ? renderGitLabCi(options) : renderGitHubActions(options);
+}
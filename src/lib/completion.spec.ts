@@ -0,0 +1,64 @@
+import { describe, expect, it, vi } from 'vitest';
+
+vi.mock('./config-store.js', () => ({
+  getConfig: () => ({ activeEnvironment: 'prod', environments: { prod: {}, staging: {} } }),
+}));
+
+const mockOrganizations = vi.fn();
+vi.mock('./lookups.js', () => ({
+  organizationChoices: () => mockOrganizations(),
+  userChoices: async () => [],
+}));
+
+const { completeValue, completionTarget } = await import('./completion.js');
+
+const sources = {
+  api: () => ({ apiKey: 'sk_test', baseUrl: 'https://api.workos.com' }),
+  skills: async () => ['workos-authkit-nextjs', 'workos-authkit-react'],
+};
+
+describe('completion', () => {
+  describe('completionTarget', () => {
+    it('completes IDs for positionals that take one', () => {
+      expect(completionTarget(['organization', 'get', ''])).toBe('organizations');
+      expect(completionTarget(['user', 'delete', 'user_'])).toBe('users');
+      expect(completionTarget(['organization', 'get', 'org_1', ''])).toBeNull();
+      expect(completionTarget(['organization', 'list', ''])).toBeNull();
+    });
+
+    it('completes option values', () => {
+      expect(completionTarget(['widgets', 'token', '--org', ''])).toBe('organizations');
+      expect(completionTarget(['env', 'clone', '--from', 'st'])).toBe('environments');
+      expect(completionTarget(['install-skill', '--from', ''])).toBeNull();
+    });
+
+    it('completes both names for env diff', () => {
+      expect(completionTarget(['env', 'diff', 'prod', ''])).toBe('environments');
+      expect(completionTarget(['env', 'diff', 'prod', 'staging', ''])).toBeNull();
+    });
+  });
+
+  describe('completeValue', () => {
+    it('filters by the typed prefix', async () => {
+      expect(await completeValue(['env', 'switch', 'st'], sources)).toEqual(['staging']);
+      expect(await completeValue(['install-skill', '--skill', 'workos-authkit-r'], sources)).toEqual([
+        'workos-authkit-react',
+      ]);
+    });
+
+    it('adds labels for zsh', async () => {
+      mockOrganizations.mockResolvedValue([{ value: 'org_1', label: 'Acme' }]);
+      expect(await completeValue(['organization', 'get', ''], sources, { zsh: true })).toEqual(['org_1:Acme']);
+    });
+
+    it('completes nothing when the lookup fails or there is no API key', async () => {
+      mockOrganizations.mockRejectedValue(new Error('offline'));
+      expect(await completeValue(['organization', 'get', ''], sources)).toEqual([]);
+      expect(await completeValue(['organization', 'get', ''], { ...sources, api: () => null })).toEqual([]);
+    });
+
+    it('leaves other words to yargs', async () => {
+      expect(await completeValue(['organ'], sources)).toBeNull();
+    });
+  });
+});
@@ -0,0 +1,96 @@
+/**
+ * Dynamic values for `workos completion`: organization and user IDs,
+ * environment names and skill names where a command expects one. API
+ * values come from the lookups cache; anything that fails (no API key, no
+ * network) completes nothing rather than printing an error into the shell.
+ */
+
+import { getConfig } from './config-store.js';
+import { organizationChoices, userChoices, type LookupChoice } from './lookups.js';
+
+export type CompletionTarget = 'organizations' | 'users' | 'environments' | 'skills';
+
+/** `<command> <subcommand> <value>` positionals */
+const POSITIONALS: Record<string, Record<string, CompletionTarget>> = {
+  organization: { get: 'organizations', update: 'organizations', delete: 'organizations' },
+  user: { get: 'users', update: 'users', delete: 'users' },
+  env: { switch: 'environments', remove: 'environments', diff: 'environments' },
+  environments: { switch: 'environments', remove: 'environments', diff: 'environments' },
+};
+
+/** Options taking a value */
+const OPTIONS: Record<string, CompletionTarget> = {
+  '--organization': 'organizations',
+  '--org': 'organizations',
+  '--user': 'users',
+  '--from': 'environments',
+  '--skill': 'skills',
+};
+
+/**
+ * What the word being completed is, from the words typed so far (without
+ * the program name; the last one is the word being completed).
+ */
+export function completionTarget(words: string[]): CompletionTarget | null {
+  const previous = words[words.length - 2];
+  if (previous && OPTIONS[previous]) {
+    // `env clone --from` names an environment; `install-skill --from` is a bundle path
+    if (previous === '--from' && !['env', 'environments'].includes(words[0])) return null;
+    return OPTIONS[previous];
+  }
+  const positionals = words.slice(0, -1).filter((word) => !word.startsWith('-'));
+  const [command, subcommand, ...rest] = positionals;
+  const target = command && subcommand ? POSITIONALS[command]?.[subcommand] : undefined;
+  if (!target) return null;
+  // `env diff <from> <to>` takes two names; the others one
+  const max = subcommand === 'diff' ? 1 : 0;
+  return rest.length <= max ? target : null;
+}
+
+export interface CompletionSources {
+  /** API key and base URL for the active environment; null when none is configured */
+  api: () => { apiKey: string; baseUrl: string } | null;
+  skills: () => Promise<string[]>;
+}
+
+async function choices(target: CompletionTarget, sources: CompletionSources): Promise<LookupChoice[]> {
+  if (target === 'environments') {
+    return Object.keys(getConfig()?.environments ?? {}).map((name) => ({ value: name, label: name }));
+  }
+  if (target === 'skills') {
+    return (await sources.skills()).map((name) => ({ value: name, label: name }));
+  }
+  const api = sources.api();
+  if (!api) return [];
+  return target === 'organizations'
+    ? organizationChoices(api.apiKey, api.baseUrl)
+    : userChoices(api.apiKey, api.baseUrl);
+}
+
+/**
+ * Completions for the current word, or null when it isn't a dynamic value
+ * (yargs then completes commands and options as usual). Values are printed
+ * as `value:label` for zsh's descriptions.
+ */
+export async function completeValue(
+  words: string[],
+  sources: CompletionSources,
+  options: { zsh?: boolean } = {},
+): Promise<string[] | null> {
+  const target = completionTarget(words);
+  if (!target) return null;
+  const current = words[words.length - 1] ?? '';
+  let found: LookupChoice[];
+  try {
+    found = await choices(target, sources);
+  } catch {
+    return [];
+  }
+  return found
+    .filter((choice) => choice.value.startsWith(current))
+    .map((choice) =>
+      options.zsh && choice.label !== choice.value
+        ? `${choice.value.replace(/:/g, '\\:')}:${choice.label}`
+        : choice.value,
+    );
+}
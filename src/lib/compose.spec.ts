@@ -0,0 +1,123 @@
+import { afterEach, beforeEach, describe, expect, it } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { composeArgs, findComposeProject, parseComposeConfig, parseJsonc, parseServiceOption } from './compose.js';
+
+describe('compose', () => {
+  describe('parseJsonc', () => {
+    it('drops comments and trailing commas but keeps slashes inside strings', () => {
+      const text = `{
+        // the app
+        "service": "web", /* block */
+        "url": "http://localhost:3000//x",
+        "dockerComposeFile": ["../compose.yaml",],
+      }`;
+      expect(parseJsonc(text)).toEqual({
+        service: 'web',
+        url: 'http://localhost:3000//x',
+        dockerComposeFile: ['../compose.yaml'],
+      });
+    });
+  });
+
+  describe('parseComposeConfig', () => {
+    it('reads long-form ports and both env_file forms', () => {
+      const services = parseComposeConfig(
+        {
+          services: {
+            web: {
+              ports: [{ target: 3000, published: '8080', protocol: 'tcp' }, { target: 9229 }],
+              env_file: [{ path: '/app/.env.docker', required: true }],
+            },
+            db: { ports: [{ target: 5432, published: 5432, protocol: 'tcp' }], env_file: '/app/db.env' },
+            worker: {},
+          },
+        },
+        '/app',
+      );
+
+      expect(services).toEqual([
+        {
+          name: 'web',
+          ports: [
+            { target: 3000, published: 8080, protocol: 'tcp' },
+            { target: 9229, published: null, protocol: 'tcp' },
+          ],
+          envFiles: ['/app/.env.docker'],
+        },
+        { name: 'db', ports: [{ target: 5432, published: 5432, protocol: 'tcp' }], envFiles: ['/app/db.env'] },
+        { name: 'worker', ports: [], envFiles: [] },
+      ]);
+    });
+  });
+
+  describe('parseServiceOption', () => {
+    it('splits off an optional container port', () => {
+      expect(parseServiceOption('web')).toEqual({ service: 'web' });
+      expect(parseServiceOption('web:3000')).toEqual({ service: 'web', port: 3000 });
+    });
+  });
+
+  describe('findComposeProject', () => {
+    let root: string;
+
+    beforeEach(() => {
+      root = mkdtempSync(join(tmpdir(), 'compose-project-'));
+    });
+
+    afterEach(() => {
+      rmSync(root, { recursive: true, force: true });
+    });
+
+    it('has none without a compose file', () => {
+      expect(findComposeProject(root)).toBeNull();
+    });
+
+    it('finds a compose file at the root', () => {
+      writeFileSync(join(root, 'docker-compose.yml'), 'services: {}\n');
+      expect(findComposeProject(root)).toEqual({
+        dir: root,
+        files: [join(root, 'docker-compose.yml')],
+        source: 'docker-compose.yml',
+      });
+    });
+
+    it('prefers the dev container, resolving its compose files from .devcontainer/', () => {
+      writeFileSync(join(root, 'compose.yaml'), 'services: {}\n');
+      mkdirSync(join(root, '.devcontainer'));
+      const devcontainer = [
+        '{',
+        '  // dev container',
+        '  "dockerComposeFile": ["../compose.yaml", "compose.dev.yaml"],',
+        '  "service": "app",',
+        '}',
+      ];
+      writeFileSync(join(root, '.devcontainer', 'devcontainer.json'), devcontainer.join('\n'));
+
+      const project = findComposeProject(root)!;
+      expect(project).toEqual({
+        dir: root,
+        files: [join(root, 'compose.yaml'), join(root, '.devcontainer', 'compose.dev.yaml')],
+        service: 'app',
+        source: '.devcontainer/devcontainer.json',
+      });
+      expect(composeArgs(project, 'up', '-d', 'app')).toEqual([
+        'compose',
+        '-f',
+        join(root, 'compose.yaml'),
+        '-f',
+        join(root, '.devcontainer', 'compose.dev.yaml'),
+        'up',
+        '-d',
+        'app',
+      ]);
+    });
+
+    it('ignores a dev container that builds an image instead of using compose', () => {
+      mkdirSync(join(root, '.devcontainer'));
+      writeFileSync(join(root, '.devcontainer', 'devcontainer.json'), '{ "image": "node:20" }');
+      expect(findComposeProject(root)).toBeNull();
+    });
+  });
+});
@@ -0,0 +1,230 @@
+/**
+ * Docker Compose and dev container projects, for apps that only run in a
+ * container.
+ *
+ * The app listens on a container port, but the browser (and the WorkOS
+ * redirect URI) reach it on the port compose publishes on the host, and it
+ * reads its env vars from the service's `env_file`, not `.env.local`. The
+ * service is picked with `--compose-service` or from
+ * `.devcontainer/devcontainer.json`; compose itself resolves the files
+ * (`docker compose config`), so overrides, profiles and `${VAR}`
+ * interpolation work as they do for the project.
+ */
+
+import { existsSync, readFileSync } from 'node:fs';
+import { dirname, join, resolve } from 'node:path';
+import { execFileNoThrow } from '../utils/exec-file.js';
+import { relativePosix, toPosixPath } from '../utils/paths.js';
+
+/** Looked up in this order, as `docker compose` does */
+export const COMPOSE_FILES = ['compose.yaml', 'compose.yml', 'docker-compose.yaml', 'docker-compose.yml'];
+
+export const DEVCONTAINER_FILE = join('.devcontainer', 'devcontainer.json');
+
+const COMPOSE_TIMEOUT_MS = 30_000;
+
+export interface ComposeProject {
+  /** Directory compose runs in */
+  dir: string;
+  /** Compose files, absolute */
+  files: string[];
+  /** Service named by the dev container config, if any */
+  service?: string;
+  /** Where the project was found, relative to the install dir */
+  source: string;
+}
+
+export interface ComposePort {
+  /** Port inside the container */
+  target: number;
+  /** Port on the host; null when compose picks one when the service starts */
+  published: number | null;
+  protocol: string;
+}
+
+export interface ComposeService {
+  name: string;
+  ports: ComposePort[];
+  /** env_file entries, absolute */
+  envFiles: string[];
+}
+
+/** A service resolved for the run: where the browser reaches it and where its env goes */
+export interface ComposeTarget {
+  project: ComposeProject;
+  service: string;
+  containerPort: number;
+  hostPort: number;
+  /** The service's first env_file, relative to the install dir; absent when it has none */
+  envFile?: string;
+}
+
+/** JSON with comments and trailing commas, as devcontainer.json allows */
+export function parseJsonc(text: string): unknown {
+  let out = '';
+  for (let i = 0; i < text.length; i++) {
+    const ch = text[i];
+    if (ch === '"') {
+      const start = i;
+      for (i++; i < text.length && text[i] !== '"'; i++) if (text[i] === '\\') i++;
+      out += text.slice(start, i + 1);
+    } else if (ch === '/' && text[i + 1] === '/') {
+      while (i < text.length && text[i] !== '\n') i++;
+      out += '\n';
+    } else if (ch === '/' && text[i + 1] === '*') {
+      const end = text.indexOf('*/', i + 2);
+      i = end === -1 ? text.length : end + 1;
+    } else {
+      out += ch;
+    }
+  }
+  return JSON.parse(out.replace(/,(\s*[}\]])/g, '$1'));
+}
+
+function readDevcontainer(installDir: string): ComposeProject | null {
+  const path = join(installDir, DEVCONTAINER_FILE);
+  if (!existsSync(path)) return null;
+  let config: { dockerComposeFile?: unknown; service?: unknown };
+  try {
+    config = parseJsonc(readFileSync(path, 'utf-8')) as typeof config;
+  } catch {
+    throw new Error(`${DEVCONTAINER_FILE} is not valid JSON`);
+  }
+  const files = [config.dockerComposeFile].flat().filter((f): f is string => typeof f === 'string');
+  if (files.length === 0) return null;
+  // Compose file paths in devcontainer.json are relative to .devcontainer/
+  const absolute = files.map((file) => resolve(dirname(path), file));
+  return {
+    dir: dirname(absolute[0]),
+    files: absolute,
+    ...(typeof config.service === 'string' && { service: config.service }),
+    source: toPosixPath(DEVCONTAINER_FILE),
+  };
+}
+
+/** The project's compose setup: a dev container using compose, or a compose file at the root */
+export function findComposeProject(installDir: string): ComposeProject | null {
+  const devcontainer = readDevcontainer(installDir);
+  if (devcontainer) return devcontainer;
+  const file = COMPOSE_FILES.find((name) => existsSync(join(installDir, name)));
+  return file ? { dir: installDir, files: [join(installDir, file)], source: file } : null;
+}
+
+/** `docker compose -f ...` for a project */
+export function composeArgs(project: ComposeProject, ...args: string[]): string[] {
+  return ['compose', ...project.files.flatMap((file) => ['-f', file]), ...args];
+}
+
+function toPort(value: unknown): number | null {
+  const port = typeof value === 'number' ? value : parseInt(String(value ?? ''), 10);
+  return port > 0 && port < 65536 ? port : null;
+}
+
+/**
+ * Services from `docker compose config --format json`. Ports are in the
+ * long form there; env_file entries are paths or `{ path }` objects,
+ * depending on the compose version.
+ */
+export function parseComposeConfig(config: unknown, dir: string): ComposeService[] {
+  const services = (config as { services?: Record<string, unknown> } | null)?.services ?? {};
+  return Object.entries(services).map(([name, raw]) => {
+    const service = (raw ?? {}) as { ports?: unknown[]; env_file?: unknown };
+    const ports = (service.ports ?? []).flatMap((p): ComposePort[] => {
+      const port = p as { target?: unknown; published?: unknown; protocol?: unknown };
+      const target = toPort(port.target);
+      return target ? [{ target, published: toPort(port.published), protocol: String(port.protocol ?? 'tcp') }] : [];
+    });
+    const envFiles = [service.env_file ?? []]
+      .flat()
+      .map((entry) => (typeof entry === 'string' ? entry : (entry as { path?: unknown } | null)?.path))
+      .filter((path): path is string => typeof path === 'string')
+      .map((path) => resolve(dir, path));
+    return { name, ports, envFiles };
+  });
+}
+
+/** Resolve the project's services with compose itself */
+export async function loadComposeServices(project: ComposeProject): Promise<ComposeService[]> {
+  const result = await execFileNoThrow('docker', composeArgs(project, 'config', '--format', 'json'), {
+    cwd: project.dir,
+    timeout: COMPOSE_TIMEOUT_MS,
+  });
+  if (result.status !== 0) {
+    throw new Error(`docker compose config failed: ${result.stderr.trim() || 'is Docker installed?'}`);
+  }
+  return parseComposeConfig(JSON.parse(result.stdout), project.dir);
+}
+
+/** Host port of a running service's container port, from `docker compose port` */
+export async function runningHostPort(project: ComposeProject, service: string, port: number): Promise<number | null> {
+  const result = await execFileNoThrow('docker', composeArgs(project, 'port', service, String(port)), {
+    cwd: project.dir,
+    timeout: COMPOSE_TIMEOUT_MS,
+  });
+  return result.status === 0 ? toPort(/:(\d+)\s*$/.exec(result.stdout.trim())?.[1]) : null;
+}
+
+/**
+ * `web` or `web:3000`: the service, and optionally which container port the
+ * app listens on when the service publishes several.
+ */
+export function parseServiceOption(value: string): { service: string; port?: number } {
+  const [service, port] = value.split(':');
+  const containerPort = toPort(port);
+  return { service, ...(containerPort && { port: containerPort }) };
+}
+
+/**
+ * Find the service to use and where the browser reaches it. The service is
+ * the one asked for, else the dev container's, else the only one that
+ * publishes a TCP port. Throws when it can't be determined.
+ */
+export async function resolveComposeTarget(
+  installDir: string,
+  project: ComposeProject,
+  requested?: string,
+): Promise<ComposeTarget> {
+  const services = await loadComposeServices(project);
+  const wanted = requested ? parseServiceOption(requested) : project.service ? { service: project.service } : null;
+  const publishing = services.filter((s) => s.ports.some((p) => p.protocol === 'tcp'));
+
+  let service: ComposeService | undefined;
+  if (wanted) {
+    service = services.find((s) => s.name === wanted.service);
+    if (!service) {
+      const names = services.map((s) => s.name).join(', ');
+      throw new Error(`No service "${wanted.service}" in ${project.source} (services: ${names || 'none'})`);
+    }
+  } else if (publishing.length === 1) {
+    service = publishing[0];
+  } else {
+    const names = publishing.map((s) => s.name).join(', ');
+    throw new Error(
+      publishing.length === 0
+        ? `No service in ${project.source} publishes a port`
+        : `Several services in ${project.source} publish ports (${names}); pass --compose-service to choose`,
+    );
+  }
+
+  const tcp = service.ports.filter((p) => p.protocol === 'tcp');
+  const port = wanted?.port ? tcp.find((p) => p.target === wanted.port) : tcp[0];
+  if (!port) {
+    const which = wanted?.port ? `container port ${wanted.port}` : 'a port';
+    throw new Error(`Service "${service.name}" doesn't publish ${which} in ${project.source}`);
+  }
+  const hostPort = port.published ?? (await runningHostPort(project, service.name, port.target));
+  if (!hostPort) {
+    throw new Error(
+      `Service "${service.name}" publishes container port ${port.target} on a random host port; ` +
+        'start it first or give it a fixed one (e.g. "8080:3000")',
+    );
+  }
+
+  return {
+    project,
+    service: service.name,
+    containerPort: port.target,
+    hostPort,
+    ...(service.envFiles[0] && { envFile: relativePosix(installDir, service.envFiles[0]) }),
+  };
+}
@@ -14,6 +14,7 @@ import fs from 'node:fs';
 import path from 'node:path';
 import os from 'node:os';
 import { logWarn } from '../utils/debug.js';
+import { writeFileAtomic } from './atomic-write.js';
 
 export interface EnvironmentConfig {
   name: string;
@@ -26,6 +27,8 @@ export interface EnvironmentConfig {
 export interface CliConfig {
   activeEnvironment?: string;
   environments: Record<string, EnvironmentConfig>;
+  /** Usage telemetry choice from the first-run prompt or `workos telemetry`; unset until asked */
+  telemetry?: boolean;
 }
 
 const SERVICE_NAME = 'workos-cli';
@@ -66,9 +69,7 @@ function writeToFile(config: CliConfig): void {
   if (!fs.existsSync(dir)) {
     fs.mkdirSync(dir, { recursive: true, mode: 0o700 });
   }
-  fs.writeFileSync(getConfigFilePath(), JSON.stringify(config, null, 2), {
-    mode: 0o600,
-  });
+  writeFileAtomic(getConfigFilePath(), JSON.stringify(config, null, 2), { mode: 0o600 });
 }
 
 function deleteFile(): void {
@@ -0,0 +1,77 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { checkConnectivity, classifyNetworkError, describeNetworkProblem } from './connectivity.js';
+
+const fetchFailed = (code: string) =>
+  new TypeError('fetch failed', { cause: Object.assign(new Error(code), { code }) });
+
+describe('connectivity', () => {
+  describe('classifyNetworkError', () => {
+    it('reads the socket error fetch wraps', () => {
+      const online = () => true;
+
+      expect(classifyNetworkError(fetchFailed('ENOTFOUND'), online)).toBe('dns');
+      expect(classifyNetworkError(fetchFailed('ENETUNREACH'), online)).toBe('offline');
+      expect(classifyNetworkError(fetchFailed('UNABLE_TO_GET_ISSUER_CERT_LOCALLY'), online)).toBe('tls');
+      expect(classifyNetworkError(fetchFailed('UND_ERR_CONNECT_TIMEOUT'), online)).toBe('timeout');
+      expect(classifyNetworkError(new DOMException('timed out', 'TimeoutError'), online)).toBe('timeout');
+      expect(classifyNetworkError(fetchFailed('ECONNREFUSED'), online)).toBe('unreachable');
+    });
+
+    it('calls a DNS failure with no network interface offline', () => {
+      expect(classifyNetworkError(fetchFailed('ENOTFOUND'), () => false)).toBe('offline');
+      expect(classifyNetworkError(new TypeError('fetch failed'), () => false)).toBe('offline');
+    });
+  });
+
+  it('points server errors at the status page', () => {
+    expect(describeNetworkProblem('server', { host: 'api.workos.com', status: 503 })).toBe(
+      "The WorkOS API returned HTTP 503. This is on WorkOS's side; check https://status.workos.com for incidents.",
+    );
+  });
+
+  describe('checkConnectivity', () => {
+    const mockFetch = vi.fn();
+    const originalFetch = globalThis.fetch;
+
+    beforeEach(() => {
+      globalThis.fetch = mockFetch;
+      mockFetch.mockReset();
+    });
+
+    afterEach(() => {
+      globalThis.fetch = originalFetch;
+    });
+
+    const online = () => true;
+    const resolves = async () => ({ address: '203.0.113.1', family: 4 });
+
+    it('stops at the first check that fails', async () => {
+      expect(await checkConnectivity('https://api.workos.com', { online: () => false })).toMatchObject({
+        kind: 'offline',
+      });
+
+      const lookup = () => Promise.reject(Object.assign(new Error('getaddrinfo ENOTFOUND'), { code: 'ENOTFOUND' }));
+      expect(await checkConnectivity('https://api.workos.com', { online, lookup })).toEqual({
+        kind: 'dns',
+        message: 'DNS lookup for api.workos.com failed (ENOTFOUND). Check your DNS settings or VPN.',
+      });
+      expect(mockFetch).not.toHaveBeenCalled();
+    });
+
+    it('tells TLS interception from an unhealthy API', async () => {
+      mockFetch.mockRejectedValueOnce(fetchFailed('SELF_SIGNED_CERT_IN_CHAIN'));
+      expect(await checkConnectivity('https://api.workos.com', { online, lookup: resolves })).toMatchObject({
+        kind: 'tls',
+        message: expect.stringContaining('(SELF_SIGNED_CERT_IN_CHAIN)'),
+      });
+
+      mockFetch.mockResolvedValueOnce({ ok: false, status: 502 });
+      expect(await checkConnectivity('https://api.workos.com', { online, lookup: resolves })).toMatchObject({
+        kind: 'server',
+      });
+
+      mockFetch.mockResolvedValueOnce({ ok: true, status: 200 });
+      expect(await checkConnectivity('https://api.workos.com', { online, lookup: resolves })).toBeNull();
+    });
+  });
+});
@@ -0,0 +1,166 @@
+/**
+ * Tell apart the ways a request to WorkOS can fail before an answer comes
+ * back, so the error says what to fix instead of "fetch failed": no
+ * network at all, DNS failing for the API host, a TLS certificate that
+ * isn't trusted (usually a proxy or security product intercepting HTTPS),
+ * a timeout, or the API itself returning 5xx.
+ *
+ * workosRequest classifies the errors it gets; `checkConnectivity` runs the
+ * same checks from scratch as a preflight (`workos doctor`). Commands that
+ * can do without the API call `noteOffline` when they carry on without it.
+ */
+
+import { lookup as dnsLookup } from 'node:dns/promises';
+import { networkInterfaces } from 'node:os';
+import chalk from 'chalk';
+import { errorCode } from './http-retry.js';
+import { describeProxyError, proxyFetchOptions, resolveProxy } from './proxy.js';
+
+export const STATUS_PAGE_URL = 'https://status.workos.com';
+
+export type NetworkProblemKind = 'offline' | 'dns' | 'tls' | 'timeout' | 'unreachable' | 'server';
+
+export interface NetworkProblem {
+  kind: NetworkProblemKind;
+  message: string;
+}
+
+const OFFLINE_CODES = new Set(['ENETUNREACH', 'ENETDOWN', 'EHOSTUNREACH', 'EHOSTDOWN']);
+const DNS_CODES = new Set(['ENOTFOUND', 'EAI_AGAIN', 'EAI_FAIL', 'EAI_NONAME', 'EAI_NODATA']);
+const TIMEOUT_CODES = new Set(['ETIMEDOUT', 'UND_ERR_CONNECT_TIMEOUT', 'UND_ERR_HEADERS_TIMEOUT']);
+
+/** OpenSSL verification failures: the certificate chain doesn't lead to a CA Node trusts */
+function isTlsCode(code: string): boolean {
+  return (
+    /^(ERR_TLS_|ERR_SSL_)/.test(code) ||
+    /CERT|SELF_SIGNED|UNABLE_TO_(GET_ISSUER|VERIFY)/.test(code) ||
+    code === 'EPROTO'
+  );
+}
+
+/** Whether any interface other than loopback has an address */
+export function hasNetworkInterface(interfaces = networkInterfaces()): boolean {
+  return Object.values(interfaces).some((addresses) => addresses?.some((address) => !address.internal));
+}
+
+/**
+ * The kind of failure behind a request that got no response. fetch wraps
+ * the socket error, so its cause is checked too.
+ */
+export function classifyNetworkError(error: unknown, online = hasNetworkInterface): NetworkProblemKind {
+  const errors = [error, error instanceof Error ? error.cause : undefined];
+  for (const candidate of errors) {
+    if (!candidate || typeof candidate !== 'object') continue;
+    const name = (candidate as Error).name;
+    if (name === 'TimeoutError' || name === 'AbortError') return 'timeout';
+    const code = errorCode(candidate as Error);
+    if (!code) continue;
+    if (OFFLINE_CODES.has(code)) return 'offline';
+    if (DNS_CODES.has(code)) return online() ? 'dns' : 'offline';
+    if (TIMEOUT_CODES.has(code)) return 'timeout';
+    if (isTlsCode(code)) return 'tls';
+  }
+  return online() ? 'unreachable' : 'offline';
+}
+
+export interface ProblemContext {
+  /** API host, e.g. api.workos.com */
+  host: string;
+  /** Error code or HTTP status, shown as-is */
+  detail?: string;
+  timeoutMs?: number;
+  status?: number;
+}
+
+/**
+ * What went wrong and what to do about it, in a sentence or two.
+ */
+export function describeNetworkProblem(kind: NetworkProblemKind, context: ProblemContext): string {
+  const detail = context.detail ? ` (${context.detail})` : '';
+  switch (kind) {
+    case 'offline':
+      return 'No network connection. Connect to the internet and try again.';
+    case 'dns':
+      return `DNS lookup for ${context.host} failed${detail}. Check your DNS settings or VPN.`;
+    case 'tls':
+      return (
+        `The TLS certificate presented for ${context.host} isn't trusted${detail}. A proxy or security ` +
+        'product is probably intercepting HTTPS; point NODE_EXTRA_CA_CERTS at its CA certificate.'
+      );
+    case 'timeout': {
+      const after = context.timeoutMs ? ` within ${Math.round(context.timeoutMs / 1000)}s` : '';
+      return `${context.host} didn't answer${after}. Check your connection, or allow longer with --http-timeout.`;
+    }
+    case 'unreachable':
+      return `Could not connect to ${context.host}${detail}. Check your internet connection and firewall.`;
+    case 'server':
+      return (
+        `The WorkOS API returned ${context.status ? `HTTP ${context.status}` : 'a server error'}. ` +
+        `This is on WorkOS's side; check ${STATUS_PAGE_URL} for incidents.`
+      );
+  }
+}
+
+export interface PreflightOptions {
+  timeoutMs?: number;
+  /** @internal For testing */
+  lookup?: (host: string) => Promise<unknown>;
+  online?: () => boolean;
+}
+
+/**
+ * Check, in order, for a network, DNS for the API host, a trusted TLS
+ * connection and a healthy API. Null when everything answers.
+ */
+export async function checkConnectivity(
+  baseUrl: string,
+  options: PreflightOptions = {},
+): Promise<NetworkProblem | null> {
+  const { timeoutMs = 10_000, lookup = dnsLookup, online = hasNetworkInterface } = options;
+  const host = new URL(baseUrl).hostname;
+  const problem = (kind: NetworkProblemKind, context: Omit<ProblemContext, 'host'> = {}): NetworkProblem => ({
+    kind,
+    message: describeNetworkProblem(kind, { host, timeoutMs, ...context }),
+  });
+
+  if (!online()) return problem('offline');
+  const url = `${baseUrl}/health`;
+  const proxy = resolveProxy(url);
+  // Behind a proxy the proxy resolves the host, not this machine
+  if (!proxy) {
+    try {
+      await lookup(host);
+    } catch (error) {
+      return problem(classifyNetworkError(error, online), { detail: errorCode(error as Error) });
+    }
+  }
+
+  let response: Response;
+  try {
+    response = await fetch(url, { signal: AbortSignal.timeout(timeoutMs), ...proxyFetchOptions(url) } as RequestInit);
+  } catch (error) {
+    const proxyReason = describeProxyError(error, proxy);
+    if (proxyReason) return { kind: 'unreachable', message: proxyReason };
+    const cause = error instanceof Error ? (error.cause as Error | undefined) : undefined;
+    return problem(classifyNetworkError(error, online), { detail: cause ? errorCode(cause) : undefined });
+  }
+  if (response.status >= 500) return problem('server', { status: response.status });
+  return null;
+}
+
+let offlineNoted = false;
+
+/**
+ * Say once per run that a command is carrying on without the API. Goes to
+ * stderr so JSON output stays parseable; never while printing completions.
+ */
+export function noteOffline(what: string): void {
+  if (offlineNoted || process.argv.includes('--get-yargs-completions')) return;
+  offlineNoted = true;
+  console.error(chalk.dim(`Can't reach WorkOS; ${what}.`));
+}
+
+/** @internal For testing */
+export function _resetOfflineNotice(): void {
+  offlineNoted = false;
+}
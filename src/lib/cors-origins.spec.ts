@@ -0,0 +1,67 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { corsOriginMatches, detectDevOrigin, validateCorsOrigin } from './cors-origins.js';
+
+describe('cors-origins', () => {
+  describe('validateCorsOrigin', () => {
+    it('normalizes valid origins', () => {
+      expect(validateCorsOrigin('http://localhost:5173/')).toBe('http://localhost:5173');
+      expect(validateCorsOrigin(' HTTPS://App.Example.com ')).toBe('https://app.example.com');
+      expect(validateCorsOrigin('https://*.example.com')).toBe('https://*.example.com');
+      expect(validateCorsOrigin('http://[::1]:3000')).toBe('http://[::1]:3000');
+    });
+
+    it('requires a scheme and rejects paths', () => {
+      expect(() => validateCorsOrigin('localhost:5173')).toThrow('include the scheme');
+      expect(() => validateCorsOrigin('ftp://example.com')).toThrow('must use http or https');
+      expect(() => validateCorsOrigin('https://example.com/app')).toThrow('has a path');
+      expect(() => validateCorsOrigin('https://example.com?x=1')).toThrow('has a path, query or fragment');
+      expect(() => validateCorsOrigin('https://example.com:abc')).toThrow('invalid port');
+    });
+
+    it('only allows a wildcard as the leftmost label of a public domain', () => {
+      expect(() => validateCorsOrigin('https://app-*.example.com')).toThrow('whole leftmost label');
+      expect(() => validateCorsOrigin('https://app.*.example.com')).toThrow('whole leftmost label');
+      expect(() => validateCorsOrigin('https://*.com')).toThrow('needs a domain under it');
+      expect(() => validateCorsOrigin('http://*.dev.localhost')).toThrow('not allowed for localhost');
+      expect(() => validateCorsOrigin('http://*.0.0.1')).toThrow('not allowed for localhost or IP');
+      expect(() => validateCorsOrigin('http://localhost:*')).toThrow('wildcards are not allowed in ports');
+    });
+  });
+
+  it('matches wildcards against subdomains only', () => {
+    expect(corsOriginMatches('https://*.example.com', 'https://app.example.com')).toBe(true);
+    expect(corsOriginMatches('https://*.example.com', 'https://example.com')).toBe(false);
+    expect(corsOriginMatches('https://*.example.com', 'http://app.example.com')).toBe(false);
+    expect(corsOriginMatches('http://localhost:5173', 'http://localhost:5173')).toBe(true);
+    expect(corsOriginMatches('http://localhost:5173', 'http://localhost:3000')).toBe(false);
+  });
+
+  describe('detectDevOrigin', () => {
+    let dir: string;
+
+    beforeEach(() => {
+      dir = mkdtempSync(join(tmpdir(), 'cors-origins-test-'));
+    });
+
+    afterEach(() => {
+      rmSync(dir, { recursive: true, force: true });
+    });
+
+    it('defaults to http', () => {
+      writeFileSync(join(dir, 'vite.config.ts'), 'export default { server: { https: false } };\n');
+      expect(detectDevOrigin(5173, dir)).toBe('http://localhost:5173');
+    });
+
+    it('uses https for basic-ssl Vite configs and CRA HTTPS=true', () => {
+      writeFileSync(join(dir, 'vite.config.ts'), "import basicSsl from '@vitejs/plugin-basic-ssl';\n");
+      expect(detectDevOrigin(5173, dir)).toBe('https://localhost:5173');
+
+      rmSync(join(dir, 'vite.config.ts'));
+      writeFileSync(join(dir, '.env.development'), 'HTTPS=true\n');
+      expect(detectDevOrigin(3000, dir)).toBe('https://localhost:3000');
+    });
+  });
+});
@@ -0,0 +1,135 @@
+/**
+ * CORS origins allowed to call AuthKit from the browser.
+ *
+ * SPA integrations (Vite, Create React App) exchange codes from the page
+ * itself, so the dev server's origin has to be on the environment's list or
+ * sign-in fails with an opaque CORS error.
+ */
+
+import { existsSync, readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { KNOWN_INTEGRATIONS, type Integration } from './constants.js';
+import { listAll } from './pagination.js';
+import { workosRequest, WorkOSApiError } from './workos-api.js';
+
+export interface CorsOrigin {
+  id: string;
+  origin: string;
+}
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+/** Integrations whose browser code talks to AuthKit directly */
+const SPA_INTEGRATIONS: Integration[] = [KNOWN_INTEGRATIONS.react, KNOWN_INTEGRATIONS.vanillaJs];
+
+export function isSpaIntegration(integration: Integration): boolean {
+  return SPA_INTEGRATIONS.includes(integration);
+}
+
+/**
+ * Validate an origin and return it normalized (lowercase scheme and host,
+ * no trailing slash). Throws with a message fit for the terminal.
+ *
+ * A wildcard may only stand for the whole leftmost label of a public host
+ * (`https://*.example.com`), never for a port, localhost or an IP address.
+ */
+export function validateCorsOrigin(input: string): string {
+  const value = input.trim().replace(/\/$/, '');
+  const match = /^([a-z][a-z0-9+.-]*):\/\/([^/?#]*)(.*)$/i.exec(value);
+  if (!match) throw new Error(`"${input}" is not an origin: include the scheme, e.g. https://app.example.com`);
+  const [, scheme, authority, rest] = match;
+  if (rest) throw new Error(`"${input}" has a path, query or fragment; an origin is only scheme://host[:port]`);
+  if (!/^https?$/i.test(scheme)) throw new Error(`"${input}" must use http or https`);
+  if (authority.includes('@')) throw new Error(`"${input}" must not include credentials`);
+
+  const hostPort = /^(\[[^\]]+\]|[^:]+)(?::(.*))?$/.exec(authority);
+  if (!hostPort) throw new Error(`"${input}" has no host`);
+  const [, host, port] = hostPort;
+  if (port !== undefined && !/^\d{1,5}$/.test(port)) {
+    const hint = port.includes('*') ? ' (wildcards are not allowed in ports)' : '';
+    throw new Error(`"${input}" has an invalid port${hint}`);
+  }
+
+  if (host.includes('*')) {
+    const labels = host.split('.');
+    if (labels[0] !== '*' || labels.slice(1).some((l) => l.includes('*'))) {
+      throw new Error(`"${input}": a wildcard must be the whole leftmost label, e.g. https://*.example.com`);
+    }
+    if (labels.length < 3) {
+      throw new Error(`"${input}": a wildcard needs a domain under it, e.g. https://*.example.com`);
+    }
+    if (labels.at(-1) === 'localhost' || labels.slice(1).every((l) => /^\d+$/.test(l))) {
+      throw new Error(`"${input}": wildcards are not allowed for localhost or IP addresses`);
+    }
+  }
+
+  return `${scheme.toLowerCase()}://${host.toLowerCase()}${port !== undefined ? `:${port}` : ''}`;
+}
+
+/** Whether `origin` is allowed by `allowed`, honoring `*.` wildcards */
+export function corsOriginMatches(allowed: string, origin: string): boolean {
+  if (allowed === origin) return true;
+  const wildcard = /^(https?):\/\/\*\.(.+)$/.exec(allowed);
+  if (!wildcard) return false;
+  const [, scheme, domain] = wildcard;
+  return origin.startsWith(`${scheme}://`) && origin.endsWith(`.${domain}`);
+}
+
+function readFile(path: string): string | null {
+  try {
+    return existsSync(path) ? readFileSync(path, 'utf-8') : null;
+  } catch {
+    return null;
+  }
+}
+
+const VITE_CONFIGS = ['vite.config.ts', 'vite.config.js', 'vite.config.mts', 'vite.config.mjs'];
+const CRA_ENV_FILES = ['.env', '.env.local', '.env.development', '.env.development.local'];
+
+/** Whether the dev server serves over https (Vite `server.https`/basic-ssl, CRA `HTTPS=true`) */
+function servesHttps(installDir: string): boolean {
+  for (const file of VITE_CONFIGS) {
+    const content = readFile(join(installDir, file));
+    if (content && /\bhttps\s*:(?!\s*(?:false\b|\/\/))|plugin-basic-ssl|plugin-mkcert/.test(content)) return true;
+  }
+  for (const file of CRA_ENV_FILES) {
+    if (/^\s*HTTPS\s*=\s*["']?true/m.test(readFile(join(installDir, file)) ?? '')) return true;
+  }
+  const scripts = readFile(join(installDir, 'package.json'));
+  return scripts !== null && /HTTPS=true/.test(scripts);
+}
+
+/** The origin the dev server is served from, e.g. `http://localhost:5173` */
+export function detectDevOrigin(port: number, installDir: string): string {
+  return `${servesHttps(installDir) ? 'https' : 'http'}://localhost:${port}`;
+}
+
+export async function listCorsOrigins(options: ApiOptions): Promise<CorsOrigin[]> {
+  return listAll<CorsOrigin>({ path: '/user_management/cors_origins', ...options });
+}
+
+/**
+ * Allow an origin. Returns `created: false` when it was already allowed;
+ * WorkOS answers 409, or 422 "already exists", in that case.
+ */
+export async function createCorsOrigin(origin: string, options: ApiOptions): Promise<{ created: boolean }> {
+  try {
+    await workosRequest({ method: 'POST', path: '/user_management/cors_origins', body: { origin }, ...options });
+    return { created: true };
+  } catch (error) {
+    if (
+      error instanceof WorkOSApiError &&
+      (error.statusCode === 409 || (error.statusCode === 422 && /already exists/i.test(error.message)))
+    ) {
+      return { created: false };
+    }
+    throw error;
+  }
+}
+
+export async function deleteCorsOrigin(id: string, options: ApiOptions): Promise<void> {
+  await workosRequest({ method: 'DELETE', path: `/user_management/cors_origins/${id}`, ...options });
+}
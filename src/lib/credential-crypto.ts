@@ -0,0 +1,100 @@
+/**
+ * Encryption for the credentials file.
+ *
+ * The key is derived from a machine identifier and the OS user, so a copied
+ * credentials file is useless elsewhere. This protects against casual
+ * disclosure (backups, dotfile repos, wrong permissions), not against code
+ * running as the same user on the same machine; the OS keyring remains the
+ * primary store.
+ */
+
+import { createCipheriv, createDecipheriv, randomBytes, scryptSync } from 'node:crypto';
+import { execFileSync } from 'node:child_process';
+import fs from 'node:fs';
+import os from 'node:os';
+
+export interface EncryptedPayload {
+  version: 1;
+  algorithm: 'aes-256-gcm';
+  /** Base64 fields */
+  salt: string;
+  iv: string;
+  tag: string;
+  data: string;
+}
+
+let cachedMachineId: string | undefined;
+
+/** @internal For testing only */
+export function _setMachineId(id: string | undefined): void {
+  cachedMachineId = id;
+}
+
+function readMachineId(): string | null {
+  try {
+    if (process.platform === 'linux') {
+      for (const file of ['/etc/machine-id', '/var/lib/dbus/machine-id']) {
+        if (fs.existsSync(file)) return fs.readFileSync(file, 'utf-8').trim() || null;
+      }
+    } else if (process.platform === 'darwin') {
+      const out = execFileSync('ioreg', ['-rd1', '-c', 'IOPlatformExpertDevice'], { encoding: 'utf-8', timeout: 2000 });
+      return /"IOPlatformUUID"\s*=\s*"([^"]+)"/.exec(out)?.[1] ?? null;
+    } else if (process.platform === 'win32') {
+      const out = execFileSync('reg', ['query', 'HKLM\\SOFTWARE\\Microsoft\\Cryptography', '/v', 'MachineGuid'], {
+        encoding: 'utf-8',
+        timeout: 2000,
+      });
+      return /MachineGuid\s+REG_SZ\s+(\S+)/.exec(out)?.[1] ?? null;
+    }
+  } catch {
+    // Fall through to the hostname
+  }
+  return null;
+}
+
+function machineSecret(): string {
+  cachedMachineId ??= readMachineId() ?? os.hostname();
+  return `${cachedMachineId}:${os.userInfo().username}`;
+}
+
+function deriveKey(salt: Buffer): Buffer {
+  return scryptSync(machineSecret(), salt, 32);
+}
+
+export function encryptString(plaintext: string): EncryptedPayload {
+  const salt = randomBytes(16);
+  const iv = randomBytes(12);
+  const cipher = createCipheriv('aes-256-gcm', deriveKey(salt), iv);
+  const data = Buffer.concat([cipher.update(plaintext, 'utf-8'), cipher.final()]);
+  return {
+    version: 1,
+    algorithm: 'aes-256-gcm',
+    salt: salt.toString('base64'),
+    iv: iv.toString('base64'),
+    tag: cipher.getAuthTag().toString('base64'),
+    data: data.toString('base64'),
+  };
+}
+
+/**
+ * Decrypt a payload. Throws if it was encrypted on another machine or for
+ * another user, or has been tampered with.
+ */
+export function decryptString(payload: EncryptedPayload): string {
+  const decipher = createDecipheriv(
+    'aes-256-gcm',
+    deriveKey(Buffer.from(payload.salt, 'base64')),
+    Buffer.from(payload.iv, 'base64'),
+  );
+  decipher.setAuthTag(Buffer.from(payload.tag, 'base64'));
+  return Buffer.concat([decipher.update(Buffer.from(payload.data, 'base64')), decipher.final()]).toString('utf-8');
+}
+
+export function isEncryptedPayload(value: unknown): value is EncryptedPayload {
+  return (
+    typeof value === 'object' &&
+    value !== null &&
+    (value as EncryptedPayload).algorithm === 'aes-256-gcm' &&
+    typeof (value as EncryptedPayload).data === 'string'
+  );
+}
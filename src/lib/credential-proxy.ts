@@ -1,21 +1,19 @@
 /**
  * Lightweight HTTP proxy that injects credentials from file into requests.
  * Includes lazy token refresh - refreshes proactively when token is expiring soon.
+ * Holds back requests after a 429, for every agent of the run (see agent-rate-limit.ts).
  */
 
 import http from 'node:http';
 import https from 'node:https';
 import { URL } from 'node:url';
 import { logInfo, logError, logWarn } from '../utils/debug.js';
-import { getCredentials, updateTokens, type Credentials } from './credentials.js';
+import { getCredentials, type Credentials } from './credentials.js';
 import { analytics } from '../utils/analytics.js';
-import { refreshAccessToken } from './token-refresh-client.js';
+import { refreshSession } from './token-refresh.js';
+import { agentBackoffDelay, noteAgentRateLimit, noteAgentSuccess } from './agent-rate-limit.js';
 
 export interface RefreshConfig {
-  /** AuthKit domain for refresh endpoint */
-  authkitDomain: string;
-  /** OAuth client ID */
-  clientId: string;
   /** Threshold in ms - refresh when token expires within this window (default: 60000 = 1 min) */
   refreshThresholdMs?: number;
   /** Callback when refresh succeeds */
@@ -65,7 +63,7 @@ async function doRefresh(): Promise<boolean> {
     return false;
   }
 
-  const { authkitDomain, clientId, onRefreshSuccess, onRefreshExpired } = refreshConfig;
+  const { onRefreshSuccess, onRefreshExpired } = refreshConfig;
   const startTime = Date.now();
 
   logInfo('[credential-proxy] Starting token refresh...');
@@ -75,12 +73,10 @@ async function doRefresh(): Promise<boolean> {
     trigger: 'lazy',
   });
 
-  const result = await refreshAccessToken(authkitDomain, clientId);
+  // Shares the refresh (and its lock) with any other command refreshing the same session
+  const result = await refreshSession(getCredentials()?.accessToken);
 
   if (result.success && result.accessToken && result.expiresAt) {
-    // Update credentials file atomically
-    updateTokens(result.accessToken, result.expiresAt, result.refreshToken);
-
     consecutiveFailures = 0;
     const durationMs = Date.now() - startTime;
 
@@ -249,6 +245,13 @@ async function handleRequest(
   useHttps: boolean,
   thresholdMs: number,
 ): Promise<void> {
+  // An agent was rate limited; sending more now would only earn more 429s
+  const backoff = agentBackoffDelay();
+  if (backoff > 0) {
+    logInfo(`[credential-proxy] Rate limited, holding request for ${backoff}ms`);
+    await new Promise((resolve) => setTimeout(resolve, backoff));
+  }
+
   // Get valid credentials, potentially triggering refresh
   const creds = await ensureValidCredentials(thresholdMs);
 
@@ -321,6 +324,13 @@ async function handleRequest(
       }
     }
 
+    if (proxyRes.statusCode === 429) {
+      const delay = noteAgentRateLimit(proxyRes.headers['retry-after']);
+      logWarn(`[credential-proxy] Rate limited by upstream, pausing agent requests for ${delay}ms`);
+    } else if (proxyRes.statusCode && proxyRes.statusCode < 400) {
+      noteAgentSuccess();
+    }
+
     res.writeHead(proxyRes.statusCode || 500, responseHeaders);
     proxyRes.pipe(res);
   });
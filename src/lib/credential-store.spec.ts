@@ -1,5 +1,14 @@
 import { describe, it, expect, beforeEach, afterEach, vi, type Mock } from 'vitest';
-import { existsSync, readFileSync, unlinkSync, mkdtempSync, rmdirSync, writeFileSync, mkdirSync } from 'node:fs';
+import {
+  existsSync,
+  readFileSync,
+  unlinkSync,
+  mkdtempSync,
+  rmdirSync,
+  writeFileSync,
+  mkdirSync,
+  statSync,
+} from 'node:fs';
 import { join } from 'node:path';
 import { tmpdir } from 'node:os';
 
@@ -77,6 +86,7 @@ const {
   updateTokens,
   getCredentialsPath,
 } = await import('./credential-store.js');
+const { _setMachineId } = await import('./credential-crypto.js');
 import type { Credentials } from './credential-store.js';
 
 describe('credential-store', () => {
@@ -89,6 +99,7 @@ describe('credential-store', () => {
     mockKeyring.clear();
     keyringAvailable = true;
     setInsecureStorage(false);
+    _setMachineId('test-machine');
   });
 
   afterEach(() => {
@@ -124,12 +135,14 @@ describe('credential-store', () => {
       expect(creds?.userId).toBe(validCreds.userId);
     });
 
-    it('keeps file as durable fallback alongside keyring', () => {
+    it('keeps an encrypted file as durable fallback alongside keyring', () => {
       saveCredentials(validCreds);
 
       // Both keyring and file should have credentials
       expect(mockKeyring.has('workos-cli:credentials')).toBe(true);
       expect(existsSync(credentialsFile)).toBe(true);
+      expect(readFileSync(credentialsFile, 'utf-8')).not.toContain(validCreds.accessToken);
+      expect(statSync(credentialsFile).mode & 0o777).toBe(0o600);
     });
 
     it('clears from both keyring and file', () => {
@@ -179,20 +192,42 @@ describe('credential-store', () => {
 
       warnSpy.mockRestore();
     });
+
+    it('cannot read a file encrypted on another machine', () => {
+      saveCredentials(validCreds);
+      _setMachineId('other-machine');
+
+      expect(getCredentials()).toBeNull();
+    });
   });
 
   describe('migration (file to keyring)', () => {
-    it('migrates file credentials to keyring on read but keeps file', () => {
-      // Create file credentials directly
+    it('migrates plaintext file credentials to keyring and encrypts the file', () => {
+      // Plaintext file as written by older versions
       mkdirSync(installerDir, { recursive: true });
-      writeFileSync(credentialsFile, JSON.stringify(validCreds));
+      writeFileSync(credentialsFile, JSON.stringify(validCreds), { mode: 0o644 });
 
-      // Read should migrate to keyring AND keep the file
       const creds = getCredentials();
 
       expect(creds?.accessToken).toBe(validCreds.accessToken);
       expect(mockKeyring.has('workos-cli:credentials')).toBe(true);
-      expect(existsSync(credentialsFile)).toBe(true);
+      expect(readFileSync(credentialsFile, 'utf-8')).not.toContain(validCreds.accessToken);
+      expect(statSync(credentialsFile).mode & 0o777).toBe(0o600);
+      expect(getCredentials()?.userId).toBe(validCreds.userId);
+    });
+
+    it('encrypts a plaintext file left next to an existing keyring entry', () => {
+      // Older versions wrote both the keyring and a plaintext file
+      mockKeyring.set('workos-cli:credentials', JSON.stringify(validCreds));
+      mkdirSync(installerDir, { recursive: true });
+      writeFileSync(credentialsFile, JSON.stringify(validCreds), { mode: 0o644 });
+
+      expect(getCredentials()?.accessToken).toBe(validCreds.accessToken);
+
+      expect(readFileSync(credentialsFile, 'utf-8')).not.toContain(validCreds.accessToken);
+      expect(statSync(credentialsFile).mode & 0o777).toBe(0o600);
+      keyringAvailable = false;
+      expect(getCredentials()?.userId).toBe(validCreds.userId);
     });
 
     it('keeps file if keyring unavailable during migration', () => {
@@ -212,12 +247,13 @@ describe('credential-store', () => {
   });
 
   describe('--insecure-storage flag', () => {
-    it('uses file storage when flag is set', () => {
+    it('uses plaintext file storage when flag is set', () => {
       setInsecureStorage(true);
       saveCredentials(validCreds);
 
       expect(existsSync(credentialsFile)).toBe(true);
       expect(mockKeyring.has('workos-cli:credentials')).toBe(false);
+      expect(JSON.parse(readFileSync(credentialsFile, 'utf-8')).accessToken).toBe(validCreds.accessToken);
     });
 
     it('reads only from file when flag is set', () => {
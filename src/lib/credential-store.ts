@@ -2,8 +2,13 @@
  * Credential storage abstraction with keyring support and file fallback.
  *
  * Storage priority:
- * 1. If --insecure-storage: use file only
- * 2. Try keyring, fall back to file with warning if unavailable
+ * 1. If --insecure-storage: use a plaintext file only (containers without a keyring)
+ * 2. Try keyring, fall back to the file with a warning if unavailable
+ *
+ * Outside --insecure-storage the file is encrypted with a machine-derived
+ * key (see credential-crypto.ts) and kept as a durable fallback next to the
+ * keyring. Plaintext files from older versions are re-encrypted and copied
+ * into the keyring on first read.
  */
 
 import { Entry } from '@napi-rs/keyring';
@@ -11,6 +16,8 @@ import fs from 'node:fs';
 import path from 'node:path';
 import os from 'node:os';
 import { logWarn } from '../utils/debug.js';
+import { writeFileAtomic } from './atomic-write.js';
+import { decryptString, encryptString, isEncryptedPayload } from './credential-crypto.js';
 
 export interface StagingCache {
   clientId: string;
@@ -49,25 +56,39 @@ function fileExists(): boolean {
   return fs.existsSync(getCredentialsPath());
 }
 
-function readFromFile(): Credentials | null {
+interface FileCredentials {
+  creds: Credentials;
+  encrypted: boolean;
+}
+
+function readFileCredentials(): FileCredentials | null {
   if (!fileExists()) return null;
   try {
-    const content = fs.readFileSync(getCredentialsPath(), 'utf-8');
-    return JSON.parse(content);
+    const parsed: unknown = JSON.parse(fs.readFileSync(getCredentialsPath(), 'utf-8'));
+    if (isEncryptedPayload(parsed)) {
+      return { creds: JSON.parse(decryptString(parsed)), encrypted: true };
+    }
+    return { creds: parsed as Credentials, encrypted: false };
   } catch (error) {
+    // Also reached when the file was encrypted on another machine or for another user
     logWarn('Failed to read credentials file:', error);
     return null;
   }
 }
 
+function readFromFile(): Credentials | null {
+  return readFileCredentials()?.creds ?? null;
+}
+
 function writeToFile(creds: Credentials): void {
   const dir = getCredentialsDir();
   if (!fs.existsSync(dir)) {
     fs.mkdirSync(dir, { recursive: true, mode: 0o700 });
   }
-  fs.writeFileSync(getCredentialsPath(), JSON.stringify(creds, null, 2), {
-    mode: 0o600,
-  });
+  const content = forceInsecureStorage ? creds : encryptString(JSON.stringify(creds));
+  writeFileAtomic(getCredentialsPath(), JSON.stringify(content, null, 2), { mode: 0o600 });
+  // Atomic writes keep an existing file's mode; older versions could leave it world-readable
+  fs.chmodSync(getCredentialsPath(), 0o600);
 }
 
 function deleteFile(): void {
@@ -125,7 +146,7 @@ function showFallbackWarning(): void {
   fallbackWarningShown = true;
   logWarn(
     'Unable to store credentials in system keyring. Using file storage.',
-    'Credentials saved to ~/.workos/credentials.json',
+    'Credentials saved to ~/.workos/credentials.json (encrypted for this machine)',
     'Use --insecure-storage to suppress this warning.',
   );
 }
@@ -141,12 +162,14 @@ export function getCredentials(): Credentials | null {
   if (forceInsecureStorage) return readFromFile();
 
   const keyringCreds = readFromKeyring();
+  const file = readFileCredentials();
+  // Plaintext from an older version, which also wrote the keyring: replace it with the encrypted form
+  if (file && !file.encrypted) writeToFile(keyringCreds ?? file.creds);
   if (keyringCreds) return keyringCreds;
 
-  const fileCreds = readFromFile();
-  if (fileCreds) {
-    writeToKeyring(fileCreds);
-    return fileCreds;
+  if (file) {
+    writeToKeyring(file.creds);
+    return file.creds;
   }
 
   return null;
@@ -194,14 +217,16 @@ export function diagnoseCredentials(): string[] {
 
   if (filePresent) {
     try {
-      const content = fs.readFileSync(filePath, 'utf-8');
-      const parsed = JSON.parse(content) as Partial<Credentials>;
+      const raw: unknown = JSON.parse(fs.readFileSync(filePath, 'utf-8'));
+      const encrypted = isEncryptedPayload(raw);
+      const parsed = (encrypted ? JSON.parse(decryptString(raw)) : raw) as Partial<Credentials>;
       const expired = parsed.expiresAt ? Date.now() >= parsed.expiresAt : 'unknown';
       lines.push(
-        `file creds: userId=${parsed.userId ?? 'missing'}, expired=${expired}, hasRefreshToken=${!!parsed.refreshToken}`,
+        `file creds: encrypted=${encrypted}, userId=${parsed.userId ?? 'missing'}, expired=${expired}, ` +
+          `hasRefreshToken=${!!parsed.refreshToken}`,
       );
     } catch (e) {
-      lines.push(`file creds: parse error — ${e instanceof Error ? e.message : String(e)}`);
+      lines.push(`file creds: unreadable — ${e instanceof Error ? e.message : String(e)}`);
     }
   }
 
@@ -0,0 +1,131 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import type { CustomDomain } from './custom-domains.js';
+
+vi.mock('./workos-api.js', () => ({
+  workosRequest: vi.fn(),
+}));
+
+vi.mock('node:dns/promises', () => ({
+  resolveCname: vi.fn(),
+  resolveTxt: vi.fn(),
+}));
+
+const { workosRequest } = await import('./workos-api.js');
+const { resolveCname, resolveTxt } = await import('node:dns/promises');
+const mockRequest = vi.mocked(workosRequest);
+
+const {
+  applyEnvDomainUpdates,
+  checkDnsRecords,
+  classifyDomain,
+  createCustomDomain,
+  proposeEnvDomainUpdates,
+} = await import('./custom-domains.js');
+
+const domain: CustomDomain = {
+  id: 'custom_domain_01',
+  domain: 'auth.example.com',
+  state: 'pending',
+  dns_records: [
+    { type: 'CNAME', name: 'auth.example.com', value: 'cname.authkit.app' },
+    { type: 'TXT', name: '_workos.auth.example.com', value: 'workos-verification=abc123' },
+  ],
+};
+
+const api = { apiKey: 'sk_test_123' };
+
+describe('custom-domains', () => {
+  beforeEach(() => {
+    mockRequest.mockReset();
+    vi.mocked(resolveCname).mockReset();
+    vi.mocked(resolveTxt).mockReset();
+  });
+
+  describe('createCustomDomain', () => {
+    it('returns the existing registration instead of creating a duplicate', async () => {
+      mockRequest.mockResolvedValueOnce({ data: [domain], list_metadata: { before: null, after: null } });
+
+      const result = await createCustomDomain('Auth.Example.com', api);
+
+      expect(result).toEqual({ domain, created: false });
+      expect(mockRequest).toHaveBeenCalledTimes(1);
+    });
+
+    it('registers a new domain', async () => {
+      mockRequest
+        .mockResolvedValueOnce({ data: [], list_metadata: { before: null, after: null } })
+        .mockResolvedValueOnce(domain);
+
+      const result = await createCustomDomain('auth.example.com', api);
+
+      expect(result.created).toBe(true);
+      expect(mockRequest).toHaveBeenLastCalledWith(
+        expect.objectContaining({ method: 'POST', path: '/custom_domains', body: { domain: 'auth.example.com' } }),
+      );
+    });
+  });
+
+  describe('checkDnsRecords', () => {
+    it('matches CNAMEs case- and trailing-dot-insensitively and joins TXT chunks', async () => {
+      vi.mocked(resolveCname).mockResolvedValue(['CNAME.authkit.app.']);
+      vi.mocked(resolveTxt).mockResolvedValue([['workos-verification=', 'abc123']]);
+
+      const checks = await checkDnsRecords(domain.dns_records);
+
+      expect(checks.map((c) => c.found)).toEqual([true, true]);
+    });
+
+    it('reports missing records', async () => {
+      vi.mocked(resolveCname).mockRejectedValue(Object.assign(new Error('not found'), { code: 'ENOTFOUND' }));
+      vi.mocked(resolveTxt).mockResolvedValue([['something-else']]);
+
+      const checks = await checkDnsRecords(domain.dns_records);
+
+      expect(checks.map((c) => [c.found, c.actual])).toEqual([
+        [false, []],
+        [false, ['something-else']],
+      ]);
+    });
+  });
+
+  describe('classifyDomain', () => {
+    const record = domain.dns_records[0];
+
+    it('distinguishes DNS propagation from certificate provisioning', () => {
+      expect(classifyDomain(domain, [{ record, found: false, actual: [] }])).toBe('dns-pending');
+      expect(classifyDomain({ ...domain, state: 'provisioning' }, [{ record, found: true, actual: [] }])).toBe(
+        'certificate-provisioning',
+      );
+    });
+
+    it('trusts the API for active and failed', () => {
+      expect(classifyDomain({ ...domain, state: 'active' }, [{ record, found: false, actual: [] }])).toBe('active');
+      expect(classifyDomain({ ...domain, state: 'failed' }, [])).toBe('failed');
+    });
+  });
+
+  describe('env updates', () => {
+    it('moves values on the default AuthKit host to the custom domain', () => {
+      const env = {
+        WORKOS_ISSUER: 'https://fancy-cat-42.authkit.app',
+        WORKOS_REDIRECT_URI: 'http://localhost:3000/callback',
+        OTHER_URL: 'https://fancy-cat-42.authkit.app',
+      };
+
+      expect(proposeEnvDomainUpdates(env, 'auth.example.com')).toEqual([
+        { key: 'WORKOS_ISSUER', from: 'https://fancy-cat-42.authkit.app', to: 'https://auth.example.com' },
+      ]);
+    });
+
+    it('rewrites only the affected lines', () => {
+      const content = '# issuer\nWORKOS_ISSUER="https://fancy-cat-42.authkit.app"\nWORKOS_CLIENT_ID=client_1\n';
+      const updates = [
+        { key: 'WORKOS_ISSUER', from: 'https://fancy-cat-42.authkit.app', to: 'https://auth.example.com' },
+      ];
+
+      expect(applyEnvDomainUpdates(content, updates)).toBe(
+        '# issuer\nWORKOS_ISSUER="https://auth.example.com"\nWORKOS_CLIENT_ID=client_1\n',
+      );
+    });
+  });
+});
@@ -0,0 +1,191 @@
+/**
+ * AuthKit custom domains.
+ *
+ * A custom domain is active once its DNS records resolve and WorkOS has
+ * issued a certificate. Both halves are checked separately so `domains
+ * verify` can tell "DNS not propagated" from "certificate still provisioning".
+ */
+
+import { resolveCname, resolveTxt } from 'node:dns/promises';
+import { workosRequest, type WorkOSListResponse } from './workos-api.js';
+
+export interface DnsRecord {
+  type: 'CNAME' | 'TXT';
+  name: string;
+  value: string;
+}
+
+export interface CustomDomain {
+  id: string;
+  domain: string;
+  state: 'pending' | 'verifying' | 'provisioning' | 'active' | 'failed';
+  dns_records: DnsRecord[];
+  /** Why provisioning failed, when state is 'failed' */
+  failure_reason?: string | null;
+}
+
+export interface DnsRecordCheck {
+  record: DnsRecord;
+  found: boolean;
+  /** What DNS currently returns for the name */
+  actual: string[];
+}
+
+export type DomainPhase = 'active' | 'dns-pending' | 'certificate-provisioning' | 'failed';
+
+export interface DomainStatus {
+  phase: DomainPhase;
+  domain: CustomDomain;
+  dns: DnsRecordCheck[];
+}
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+function normalizeHost(value: string): string {
+  return value.trim().replace(/\.$/, '').toLowerCase();
+}
+
+export async function findCustomDomain(domain: string, api: ApiOptions): Promise<CustomDomain | null> {
+  const result = await workosRequest<WorkOSListResponse<CustomDomain>>({
+    method: 'GET',
+    path: '/custom_domains',
+    params: { domain },
+    ...api,
+  });
+  return result.data.find((d) => normalizeHost(d.domain) === normalizeHost(domain)) ?? null;
+}
+
+/**
+ * Register a custom domain, or return the existing registration.
+ */
+export async function createCustomDomain(
+  domain: string,
+  api: ApiOptions,
+): Promise<{ domain: CustomDomain; created: boolean }> {
+  const existing = await findCustomDomain(domain, api);
+  if (existing) return { domain: existing, created: false };
+
+  const created = await workosRequest<CustomDomain>({
+    method: 'POST',
+    path: '/custom_domains',
+    body: { domain },
+    ...api,
+  });
+  return { domain: created, created: true };
+}
+
+async function lookup(record: DnsRecord): Promise<string[]> {
+  try {
+    if (record.type === 'CNAME') return await resolveCname(record.name);
+    return (await resolveTxt(record.name)).map((chunks) => chunks.join(''));
+  } catch {
+    // ENOTFOUND / ENODATA: nothing published (yet)
+    return [];
+  }
+}
+
+export async function checkDnsRecords(records: DnsRecord[]): Promise<DnsRecordCheck[]> {
+  return Promise.all(
+    records.map(async (record) => {
+      const actual = await lookup(record);
+      const normalize = record.type === 'CNAME' ? normalizeHost : (value: string) => value.trim();
+      const found = actual.some((value) => normalize(value) === normalize(record.value));
+      return { record, found, actual };
+    }),
+  );
+}
+
+/**
+ * Combine the API state with what DNS returns. The API is authoritative
+ * for 'active' and 'failed'; otherwise missing records mean DNS hasn't
+ * propagated and everything else is waiting on the certificate.
+ */
+export function classifyDomain(domain: CustomDomain, dns: DnsRecordCheck[]): DomainPhase {
+  if (domain.state === 'active') return 'active';
+  if (domain.state === 'failed') return 'failed';
+  if (dns.some((check) => !check.found)) return 'dns-pending';
+  return 'certificate-provisioning';
+}
+
+export async function getDomainStatus(domain: string, api: ApiOptions): Promise<DomainStatus> {
+  const found = await findCustomDomain(domain, api);
+  if (!found) {
+    throw new Error(`${domain} is not registered. Run \`workos domains setup ${domain}\` first.`);
+  }
+  const dns = await checkDnsRecords(found.dns_records);
+  return { phase: classifyDomain(found, dns), domain: found, dns };
+}
+
+export interface WaitOptions {
+  intervalMs?: number;
+  timeoutMs?: number;
+  /** Called after every check, e.g. to update a spinner */
+  onStatus?: (status: DomainStatus) => void;
+}
+
+/**
+ * Poll until the domain is active or failed, or the timeout elapses.
+ * Returns the last status seen.
+ */
+export async function waitForDomain(domain: string, api: ApiOptions, options: WaitOptions = {}): Promise<DomainStatus> {
+  const { intervalMs = 15_000, timeoutMs = 30 * 60_000, onStatus } = options;
+  const deadline = Date.now() + timeoutMs;
+
+  for (;;) {
+    const status = await getDomainStatus(domain, api);
+    onStatus?.(status);
+    if (status.phase === 'active' || status.phase === 'failed' || Date.now() + intervalMs > deadline) {
+      return status;
+    }
+    await new Promise((resolve) => setTimeout(resolve, intervalMs));
+  }
+}
+
+/** Env keys that can point at the AuthKit domain */
+const DOMAIN_ENV_KEY_PATTERN = /(REDIRECT_URI|ISSUER|AUTHKIT_DOMAIN|AUTHKIT_URL)$/;
+
+/** Hosts WorkOS serves AuthKit from before a custom domain is set up */
+const WORKOS_AUTH_HOST_PATTERN = /\.authkit\.app$/;
+
+export interface EnvDomainUpdate {
+  key: string;
+  from: string;
+  to: string;
+}
+
+/**
+ * Env values that reference the default AuthKit host and should move to
+ * the custom domain. Values pointing at localhost or the app's own domain
+ * are left alone.
+ */
+export function proposeEnvDomainUpdates(env: Record<string, string>, customDomain: string): EnvDomainUpdate[] {
+  return Object.entries(env).flatMap(([key, value]) => {
+    if (!DOMAIN_ENV_KEY_PATTERN.test(key)) return [];
+    let url: URL;
+    try {
+      url = new URL(value.includes('://') ? value : `https://${value}`);
+    } catch {
+      return [];
+    }
+    if (!WORKOS_AUTH_HOST_PATTERN.test(url.hostname) || url.hostname === customDomain) return [];
+
+    const to = value.replace(url.hostname, customDomain);
+    return [{ key, from: value, to }];
+  });
+}
+
+/**
+ * Apply updates to env file content, changing only the affected lines.
+ */
+export function applyEnvDomainUpdates(content: string, updates: EnvDomainUpdate[]): string {
+  return content
+    .split('\n')
+    .map((line) => {
+      const update = updates.find((u) => new RegExp(`^\\s*(export\\s+)?${u.key}\\s*=`).test(line));
+      return update ? line.replace(update.from, update.to) : line;
+    })
+    .join('\n');
+}
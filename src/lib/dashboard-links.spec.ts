@@ -0,0 +1,70 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { setHttpRetries } from './http-retry.js';
+import { dashboardUrl, parseDashboardPage, resolveDashboardUrl, resolveOrganizationId } from './dashboard-links.js';
+
+const api = { apiKey: 'sk_test_abc', baseUrl: 'https://api.workos.com' };
+
+describe('dashboard-links', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+
+  function respond(body: unknown) {
+    mockFetch.mockResolvedValueOnce({ ok: true, status: 200, text: async () => JSON.stringify(body) });
+  }
+
+  beforeEach(() => {
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    setHttpRetries(0);
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+  });
+
+  it('reads page names and their aliases', () => {
+    expect(parseDashboardPage(undefined)).toBe('home');
+    expect(parseDashboardPage('org')).toBe('organizations');
+    expect(parseDashboardPage('Redirects')).toBe('redirect-uris');
+    expect(() => parseDashboardPage('billing')).toThrow('Unknown dashboard page "billing"');
+  });
+
+  it('builds URLs scoped to the environment', () => {
+    expect(dashboardUrl('environment_01', 'home')).toBe('https://dashboard.workos.com/environment_01');
+    expect(dashboardUrl('environment_01', 'api-keys')).toBe('https://dashboard.workos.com/environment_01/api-keys');
+    expect(dashboardUrl('environment_01', 'users', 'user_01')).toBe(
+      'https://dashboard.workos.com/environment_01/users/user_01',
+    );
+  });
+
+  it("resolves an email to the user's page in the key's environment", async () => {
+    respond({ id: 'environment_01', name: 'Staging' });
+    respond({ data: [{ id: 'user_01', email: 'jane@example.com' }], list_metadata: {} });
+
+    const { url, environment } = await resolveDashboardUrl('users', 'jane@example.com', api);
+
+    expect(url).toBe('https://dashboard.workos.com/environment_01/users/user_01');
+    expect(environment).toEqual({ id: 'environment_01', name: 'Staging' });
+    expect(mockFetch.mock.calls[0][0]).toContain('/environments/current');
+  });
+
+  it('resolves organization names, and refuses ambiguous ones', async () => {
+    const orgs = [
+      { id: 'org_01', name: 'Acme' },
+      { id: 'org_02', name: 'Globex' },
+      { id: 'org_03', name: 'globex' },
+    ];
+    respond({ data: orgs, list_metadata: { after: null } });
+    respond({ data: orgs, list_metadata: { after: null } });
+
+    expect(await resolveOrganizationId('acme', api)).toBe('org_01');
+    await expect(resolveOrganizationId('Globex', api)).rejects.toThrow('2 organizations are named "Globex"');
+    expect(await resolveOrganizationId('org_01HXYZ', api)).toBe('org_01HXYZ');
+    expect(mockFetch).toHaveBeenCalledTimes(2);
+  });
+
+  it("rejects an ID for pages that don't show one record", async () => {
+    await expect(resolveDashboardUrl('api-keys', 'key_01', api)).rejects.toThrow("doesn't take an ID");
+    expect(mockFetch).not.toHaveBeenCalled();
+  });
+});
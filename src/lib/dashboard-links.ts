@@ -0,0 +1,112 @@
+/**
+ * Deep links into the WorkOS dashboard for the environment an API key
+ * belongs to.
+ *
+ * The environment ID comes from the API rather than the local config, so
+ * the page opened is always the environment the key would change, whatever
+ * the dashboard showed last. User emails and organization names are looked
+ * up so links can be built from what's at hand.
+ */
+
+import { getWorkOSDashboardUrl } from '../utils/urls.js';
+import { resolveUserId } from './impersonation.js';
+import { listAll } from './pagination.js';
+import { workosRequest } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export type DashboardPage = 'home' | 'users' | 'organizations' | 'redirect-uris' | 'api-keys';
+
+/** Names accepted on the command line for each page */
+export const DASHBOARD_PAGE_ALIASES: Record<string, DashboardPage> = {
+  home: 'home',
+  users: 'users',
+  user: 'users',
+  org: 'organizations',
+  orgs: 'organizations',
+  organization: 'organizations',
+  organizations: 'organizations',
+  'redirect-uris': 'redirect-uris',
+  redirects: 'redirect-uris',
+  'api-keys': 'api-keys',
+  keys: 'api-keys',
+};
+
+const PAGE_PATHS: Record<DashboardPage, string> = {
+  home: '',
+  users: '/users',
+  organizations: '/organizations',
+  'redirect-uris': '/redirects',
+  'api-keys': '/api-keys',
+};
+
+/** Pages that can show a single record */
+const RECORD_PAGES = new Set<DashboardPage>(['users', 'organizations']);
+
+export interface DashboardEnvironment {
+  id: string;
+  name: string | null;
+}
+
+export function parseDashboardPage(value: string | undefined): DashboardPage {
+  if (!value) return 'home';
+  const page = DASHBOARD_PAGE_ALIASES[value.toLowerCase()];
+  if (!page) {
+    const names = ['users', 'org', 'redirect-uris', 'api-keys'];
+    throw new Error(`Unknown dashboard page "${value}". Use one of: ${names.join(', ')}.`);
+  }
+  return page;
+}
+
+/** The environment the key belongs to */
+export async function getDashboardEnvironment(api: ApiOptions): Promise<DashboardEnvironment> {
+  const environment = await workosRequest<{ id: string; name?: string | null }>({
+    method: 'GET',
+    path: '/environments/current',
+    ...api,
+  });
+  return { id: environment.id, name: environment.name ?? null };
+}
+
+/**
+ * Accept an organization ID or its name; names are matched
+ * case-insensitively and must pick out exactly one organization.
+ */
+export async function resolveOrganizationId(idOrName: string, api: ApiOptions): Promise<string> {
+  if (/^org_[0-9A-Z]+$/i.test(idOrName)) return idOrName;
+
+  const name = idOrName.trim().toLowerCase();
+  const organizations = await listAll<{ id: string; name: string }>({ path: '/organizations', ...api });
+  const matches = organizations.filter((org) => org.name.toLowerCase() === name);
+  if (matches.length === 0) throw new Error(`No organization named "${idOrName}".`);
+  if (matches.length > 1) {
+    throw new Error(
+      `${matches.length} organizations are named "${idOrName}": ${matches.map((org) => org.id).join(', ')}. Pass the ID.`,
+    );
+  }
+  return matches[0].id;
+}
+
+/** Dashboard URL of a page, or of one user or organization on it */
+export function dashboardUrl(environmentId: string, page: DashboardPage, recordId?: string): string {
+  const base = getWorkOSDashboardUrl().replace(/\/+$/, '');
+  const record = recordId && RECORD_PAGES.has(page) ? `/${encodeURIComponent(recordId)}` : '';
+  return `${base}/${encodeURIComponent(environmentId)}${PAGE_PATHS[page]}${record}`;
+}
+
+/** Resolve the record (email or name) and build its URL in the key's environment */
+export async function resolveDashboardUrl(
+  page: DashboardPage,
+  record: string | undefined,
+  api: ApiOptions,
+): Promise<{ url: string; environment: DashboardEnvironment }> {
+  if (record && !RECORD_PAGES.has(page)) throw new Error(`The ${page} page doesn't take an ID.`);
+  const environment = await getDashboardEnvironment(api);
+  let recordId = record;
+  if (record && page === 'users') recordId = await resolveUserId(record, api);
+  if (record && page === 'organizations') recordId = await resolveOrganizationId(record, api);
+  return { url: dashboardUrl(environment.id, page, recordId), environment };
+}
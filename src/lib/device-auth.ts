@@ -6,6 +6,7 @@
  */
 
 import { logInfo, logError } from '../utils/debug.js';
+import { proxiedFetch } from './proxy.js';
 
 export interface DeviceAuthResponse {
   device_code: string;
@@ -91,7 +92,7 @@ export async function requestDeviceCode(options: DeviceAuthOptions): Promise<Dev
   const url = `${options.authkitDomain}/oauth2/device_authorization`;
 
   logInfo('[device-auth] Requesting device code from:', url);
-  const res = await fetch(url, {
+  const res = await proxiedFetch(url, {
     method: 'POST',
     headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
     body: new URLSearchParams({
@@ -132,7 +133,7 @@ export async function pollForToken(
 
     let res: Response;
     try {
-      res = await fetch(tokenUrl, {
+      res = await proxiedFetch(tokenUrl, {
         method: 'POST',
         headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
         body: new URLSearchParams({
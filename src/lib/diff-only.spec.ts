@@ -0,0 +1,130 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { applyFileEdits } from './atomic-write.js';
+import { ChangePlan } from './change-preview.js';
+import {
+  formatPatch,
+  patchRoot,
+  redactEnvSecrets,
+  skipsFileWrites,
+  startDiffOnly,
+  stopDiffOnly,
+  writePatch,
+} from './diff-only.js';
+
+describe('diff-only', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'diff-only-'));
+  });
+
+  afterEach(() => {
+    stopDiffOnly();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('skipsFileWrites', () => {
+    it('skips writes in a dry run unless they go to a patch', () => {
+      expect(skipsFileWrites({ dryRun: true })).toBe(true);
+      expect(skipsFileWrites({ dryRun: true, diffOnly: 'out.patch' })).toBe(false);
+      expect(skipsFileWrites({})).toBe(false);
+    });
+  });
+
+  describe('startDiffOnly', () => {
+    it('records file edits in the plan instead of writing them', () => {
+      const plan = startDiffOnly();
+      applyFileEdits([{ path: join(dir, '.env.local'), content: 'WORKOS_CLIENT_ID=client_1\n' }]);
+
+      expect(existsSync(join(dir, '.env.local'))).toBe(false);
+      expect(plan.changes()).toEqual([
+        { path: join(dir, '.env.local'), before: null, after: 'WORKOS_CLIENT_ID=client_1\n' },
+      ]);
+    });
+  });
+
+  describe('redactEnvSecrets', () => {
+    it('empties secrets the change adds and keeps the ones already there', () => {
+      const { change, keys } = redactEnvSecrets({
+        path: join(dir, '.env.local'),
+        before: 'WORKOS_COOKIE_PASSWORD=existing\n',
+        after: 'WORKOS_COOKIE_PASSWORD=existing\nWORKOS_API_KEY=sk_test_123\nWORKOS_CLIENT_ID=client_1\n',
+      });
+
+      expect(change.after).toBe('WORKOS_COOKIE_PASSWORD=existing\nWORKOS_API_KEY=\nWORKOS_CLIENT_ID=client_1\n');
+      expect(keys).toEqual(['WORKOS_API_KEY']);
+    });
+
+    it('leaves .env.example and other files alone', () => {
+      const change = { path: join(dir, '.env.example'), before: null, after: 'WORKOS_API_KEY=sk_test_123\n' };
+      expect(redactEnvSecrets(change)).toEqual({ change, keys: [] });
+    });
+  });
+
+  describe('patchRoot', () => {
+    it('finds the repository above a workspace package', () => {
+      mkdirSync(join(dir, '.git'));
+      mkdirSync(join(dir, 'apps', 'web'), { recursive: true });
+
+      expect(patchRoot(join(dir, 'apps', 'web'))).toBe(dir);
+    });
+
+    it('falls back to the project outside a repository', () => {
+      expect(patchRoot(dir)).toBe(dir);
+    });
+  });
+
+  describe('formatPatch', () => {
+    it('writes git headers, marking created files', () => {
+      writeFileSync(join(dir, 'package.json'), '{\n  "name": "app"\n}\n');
+      const patch = formatPatch(
+        [
+          { path: join(dir, 'middleware.ts'), before: null, after: 'export { authkitMiddleware };\n' },
+          {
+            path: join(dir, 'package.json'),
+            before: '{\n  "name": "app"\n}\n',
+            after: '{\n  "name": "app",\n  "dependencies": { "@workos-inc/authkit-nextjs": "^2.0.0" }\n}\n',
+          },
+        ],
+        dir,
+      );
+
+      expect(patch).toContain('diff --git a/middleware.ts b/middleware.ts\nnew file mode 100644\n--- /dev/null\n');
+      expect(patch).toContain('+++ b/middleware.ts\n@@ -0,0 +1,1 @@\n+export { authkitMiddleware };\n');
+      expect(patch).toContain('diff --git a/package.json b/package.json\n--- a/package.json\n+++ b/package.json\n');
+      expect(patch).toContain('+  "dependencies": { "@workos-inc/authkit-nextjs": "^2.0.0" }\n');
+      expect(patch.endsWith('\n')).toBe(true);
+    });
+
+    it('is empty without changes', () => {
+      expect(formatPatch([], dir)).toBe('');
+    });
+  });
+
+  describe('writePatch', () => {
+    it('writes root-relative paths with secrets left out', () => {
+      mkdirSync(join(dir, '.git'));
+      const app = join(dir, 'apps', 'web');
+      mkdirSync(app, { recursive: true });
+      const plan = new ChangePlan();
+      plan.recordEdits([{ path: join(app, '.env.local'), content: 'WORKOS_API_KEY=sk_test_123\n' }]);
+
+      const result = writePatch(plan, app, join(dir, 'out.patch'));
+
+      const patch = readFileSync(join(dir, 'out.patch'), 'utf-8');
+      expect(patch).toContain('diff --git a/apps/web/.env.local b/apps/web/.env.local');
+      expect(patch).toContain('+WORKOS_API_KEY=\n');
+      expect(patch).not.toContain('sk_test_123');
+      expect(result).toEqual({
+        path: join(dir, 'out.patch'),
+        root: dir,
+        files: ['apps/web/.env.local'],
+        redacted: ['apps/web/.env.local: WORKOS_API_KEY'],
+      });
+      expect(existsSync(join(app, '.env.local'))).toBe(false);
+    });
+  });
+});
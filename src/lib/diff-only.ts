@@ -0,0 +1,114 @@
+/**
+ * `--diff-only <file.patch>`: run the whole install or migration as a dry
+ * run, record every change it would make (the agent's edits, env files,
+ * `.env.example`, `.gitignore`, dependency manifests, deterministic
+ * rewrites) in one ChangePlan, and write the net result as a single patch
+ * that `git apply` accepts. The working tree is never written and git is
+ * never run, so the patch can go through change control first.
+ *
+ * Secrets have no place in a patch that gets reviewed: values the run would
+ * add for WORKOS_API_KEY and WORKOS_COOKIE_PASSWORD in env files are left
+ * empty, and the summary says which to fill in after applying it.
+ */
+
+import { existsSync } from 'node:fs';
+import { basename, dirname, isAbsolute, join, resolve } from 'node:path';
+import { recordFileEdits, writeFileAtomic } from './atomic-write.js';
+import { ChangePlan, formatUnifiedDiff, type ProposedChange } from './change-preview.js';
+import { relativePosix } from '../utils/paths.js';
+import type { InstallerOptions } from '../utils/types.js';
+
+/** Keys whose new values are kept out of the patch */
+const SECRET_ENV_KEYS = ['WORKOS_API_KEY', 'WORKOS_COOKIE_PASSWORD'];
+
+export interface PatchResult {
+  /** Absolute path of the patch */
+  path: string;
+  /** Directory to run `git apply` in */
+  root: string;
+  /** Changed files, relative to root */
+  files: string[];
+  /** `file: KEY` for each secret left empty */
+  redacted: string[];
+}
+
+/**
+ * Whether a dry run should skip a write rather than record it. With
+ * --diff-only writes are recorded in the patch instead.
+ */
+export function skipsFileWrites(options: Pick<InstallerOptions, 'dryRun' | 'diffOnly'>): boolean {
+  return Boolean(options.dryRun) && !options.diffOnly;
+}
+
+/** Start recording: file edits go to the returned plan until stopDiffOnly */
+export function startDiffOnly(): ChangePlan {
+  const plan = new ChangePlan();
+  recordFileEdits(plan.recordEdits);
+  return plan;
+}
+
+export function stopDiffOnly(): void {
+  recordFileEdits(null);
+}
+
+/** Env files whose secrets are redacted; `.env.example` isn't one */
+export function isEnvFile(path: string): boolean {
+  const name = basename(path);
+  return name === '.env' || (name.startsWith('.env.') && name !== '.env.example');
+}
+
+/** Empty the secret values a change adds to an env file; lines already there are left as they are */
+export function redactEnvSecrets(change: ProposedChange): { change: ProposedChange; keys: string[] } {
+  if (!isEnvFile(change.path)) return { change, keys: [] };
+  const existing = new Set((change.before ?? '').split(/\r?\n/));
+  const keys: string[] = [];
+  const after = change.after
+    .split('\n')
+    .map((line) => {
+      const bare = line.replace(/\r$/, '');
+      const key = SECRET_ENV_KEYS.find((name) => bare.startsWith(`${name}=`));
+      if (!key || bare === `${key}=` || existing.has(bare)) return line;
+      keys.push(key);
+      return line.replace(bare, `${key}=`);
+    })
+    .join('\n');
+  return { change: { ...change, after }, keys };
+}
+
+/**
+ * The repository the patch applies to: the nearest directory with `.git`
+ * (found without running git), or the project itself. `git apply` takes
+ * paths relative to it.
+ */
+export function patchRoot(installDir: string): string {
+  for (let current = installDir; ; ) {
+    if (existsSync(join(current, '.git'))) return current;
+    const parent = dirname(current);
+    if (parent === current) return installDir;
+    current = parent;
+  }
+}
+
+/** A git-style patch of `changes`, paths relative to `cwd` */
+export function formatPatch(changes: ProposedChange[], cwd: string): string {
+  const files = changes.map((change) => {
+    const rel = relativePosix(cwd, change.path);
+    const header = [`diff --git a/${rel} b/${rel}`, ...(change.before === null ? ['new file mode 100644'] : [])];
+    return [...header, formatUnifiedDiff(change, cwd, { color: false })].join('\n');
+  });
+  return files.length === 0 ? '' : files.join('\n') + '\n';
+}
+
+/** Write the plan's changes to `patchPath` (relative to the current directory) */
+export function writePatch(plan: ChangePlan, installDir: string, patchPath: string): PatchResult {
+  const path = isAbsolute(patchPath) ? patchPath : resolve(patchPath);
+  const root = patchRoot(installDir);
+  const redacted: string[] = [];
+  const changes = plan.changes().map((recorded) => {
+    const { change, keys } = redactEnvSecrets(recorded);
+    keys.forEach((key) => redacted.push(`${relativePosix(root, change.path)}: ${key}`));
+    return change;
+  });
+  writeFileAtomic(path, formatPatch(changes, root));
+  return { path, root, files: changes.map((change) => relativePosix(root, change.path)), redacted };
+}
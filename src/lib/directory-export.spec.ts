@@ -0,0 +1,118 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { appendFileSync, existsSync, mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { checkpointPath, csvField, exportRecords, manifestPath, type ExportSource } from './directory-export.js';
+
+interface Item {
+  id: string;
+  name: string;
+  updated_at: string;
+}
+
+const ITEMS: Item[] = [
+  { id: 'org_1', name: 'Acme', updated_at: '2026-01-01T00:00:00.000Z' },
+  { id: 'org_2', name: 'Foo, "Bar"', updated_at: '2026-03-01T00:00:00.000Z' },
+  { id: 'org_3', name: 'Baz', updated_at: '2026-02-01T00:00:00.000Z' },
+];
+
+/** Pages of two, starting after the given ID; `starts` records each cursor asked for */
+function source(starts: Array<string | undefined> = []): ExportSource<Item> {
+  return {
+    kind: 'organizations',
+    pages: async function* (after) {
+      starts.push(after);
+      const rest = after ? ITEMS.slice(ITEMS.findIndex((item) => item.id === after) + 1) : ITEMS;
+      for (let i = 0; i < rest.length; i += 2) yield rest.slice(i, i + 2);
+    },
+    columns: ['id', 'name', 'updated_at'],
+    row: (item) => [item.id, item.name, item.updated_at],
+  };
+}
+
+describe('directory-export', () => {
+  let dir: string;
+  let out: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'directory-export-'));
+    out = join(dir, 'organizations.jsonl');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('writes JSON lines and a manifest with counts and the time range', async () => {
+    const result = await exportRecords(source(), { out, format: 'jsonl' });
+
+    expect(readFileSync(out, 'utf-8').trim().split('\n').map((line) => JSON.parse(line).id)).toEqual([
+      'org_1',
+      'org_2',
+      'org_3',
+    ]);
+    expect(result.interrupted).toBe(false);
+    expect(JSON.parse(readFileSync(manifestPath(out), 'utf-8'))).toMatchObject({
+      kind: 'organizations',
+      records: 3,
+      scanned: 3,
+      range: { from: '2026-01-01T00:00:00.000Z', to: '2026-03-01T00:00:00.000Z' },
+    });
+    expect(existsSync(checkpointPath(out))).toBe(false);
+  });
+
+  it('quotes CSV fields and keeps only records updated since a time', async () => {
+    out = join(dir, 'organizations.csv');
+
+    const { manifest } = await exportRecords(source(), {
+      out,
+      format: 'csv',
+      updatedSince: '2026-02-01T00:00:00.000Z',
+    });
+
+    expect(readFileSync(out, 'utf-8')).toBe(
+      'id,name,updated_at\norg_2,"Foo, ""Bar""",2026-03-01T00:00:00.000Z\norg_3,Baz,2026-02-01T00:00:00.000Z\n',
+    );
+    expect(manifest).toMatchObject({ records: 2, scanned: 3 });
+    expect(csvField(null)).toBe('');
+  });
+
+  it('resumes after the last checkpointed page, dropping a cut-off write', async () => {
+    const controller = new AbortController();
+    const first = await exportRecords(source(), {
+      out,
+      format: 'jsonl',
+      updatedSince: '2025-01-01T00:00:00.000Z',
+      signal: controller.signal,
+      onPage: () => controller.abort(),
+    });
+    expect(first.interrupted).toBe(true);
+    expect(existsSync(manifestPath(out))).toBe(false);
+    appendFileSync(out, '{"id":"org_3","na');
+
+    const starts: Array<string | undefined> = [];
+    const resumed = await exportRecords(source(starts), {
+      out,
+      format: 'jsonl',
+      updatedSince: '2026-06-01T00:00:00.000Z',
+      resume: true,
+    });
+
+    expect(starts).toEqual(['org_2']);
+    expect(resumed.resumedFrom).toBe(2);
+    expect(resumed.manifest).toMatchObject({ records: 3, updatedSince: '2025-01-01T00:00:00.000Z' });
+    expect(readFileSync(out, 'utf-8').trim().split('\n').map((line) => JSON.parse(line).id)).toEqual([
+      'org_1',
+      'org_2',
+      'org_3',
+    ]);
+  });
+
+  it('refuses to resume a checkpoint for another format', async () => {
+    const controller = new AbortController();
+    const stopAfterFirstPage = { signal: controller.signal, onPage: () => controller.abort() };
+    await exportRecords(source(), { out, format: 'jsonl', ...stopAfterFirstPage });
+
+    await expect(exportRecords(source(), { out, format: 'csv', resume: true })).rejects.toThrow('jsonl export');
+  });
+});
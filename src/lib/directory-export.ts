@@ -0,0 +1,330 @@
+/**
+ * `workos users export` and `workos organization export`: every user or
+ * organization in the environment, written to disk a page at a time so
+ * memory stays flat however large the directory is.
+ *
+ * Pages are walked oldest first with `after` cursors (see pagination.ts).
+ * After each page is written, a checkpoint next to the output records the
+ * last ID and the file's length; --resume truncates the file to that length
+ * and carries on from the ID, so an interrupted run neither loses nor
+ * duplicates records. A finished export writes a manifest with its counts
+ * and time range and removes the checkpoint.
+ *
+ * The list endpoints can't filter on update time, so --updated-since still
+ * walks every record and keeps the ones updated since then. A resumed export
+ * keeps the --updated-since it started with.
+ */
+
+import { existsSync, readFileSync, rmSync, truncateSync } from 'node:fs';
+import { open } from 'node:fs/promises';
+import { basename } from 'node:path';
+import { writeFileAtomic } from './atomic-write.js';
+import { DEFAULT_CONCURRENCY } from './bulk.js';
+import { listAll, paginate } from './pagination.js';
+
+export type ExportKind = 'users' | 'organizations';
+export type ExportFormat = 'csv' | 'jsonl';
+
+export const EXPORT_FORMATS: ExportFormat[] = ['jsonl', 'csv'];
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+interface ListItem {
+  id: string;
+  updated_at?: string;
+}
+
+export interface ExportManifest {
+  kind: ExportKind;
+  format: ExportFormat;
+  file: string;
+  /** Records written */
+  records: number;
+  /** Records read, including the ones --updated-since left out */
+  scanned: number;
+  /** Organization memberships included with the users */
+  memberships?: number;
+  updatedSince: string | null;
+  /** Oldest and newest `updated_at` among the records written */
+  range: { from: string | null; to: string | null };
+  startedAt: string;
+  completedAt: string;
+}
+
+type ExportCheckpoint = Omit<ExportManifest, 'completedAt'> & {
+  /** ID of the last record read; the next page starts after it */
+  after: string;
+  /** Length of the output file up to the last full page */
+  bytes: number;
+};
+
+export interface ExportOptions {
+  out: string;
+  format: ExportFormat;
+  /** ISO date; only records updated since then are written */
+  updatedSince?: string;
+  /** Carry on from the checkpoint of an interrupted export to the same file */
+  resume?: boolean;
+  signal?: AbortSignal;
+  onPage?: (progress: { records: number; scanned: number }) => void;
+}
+
+export interface ExportResult {
+  manifest: ExportManifest;
+  manifestPath: string;
+  /** The signal stopped the export; --resume carries on from here */
+  interrupted: boolean;
+  /** Records written by the earlier run this one resumed */
+  resumedFrom?: number;
+}
+
+/**
+ * What's exported for one kind: where its pages come from, how a page is
+ * turned into records, and how a record becomes a CSV row.
+ */
+export interface ExportSource<T extends ListItem, R extends ListItem = T> {
+  kind: ExportKind;
+  pages: (after: string | undefined) => AsyncIterable<T[]>;
+  expand?: (page: T[]) => Promise<R[]>;
+  columns: string[];
+  row: (record: R) => Array<string | number | boolean | null | undefined>;
+  /** Counted into the manifest's `memberships` */
+  memberships?: (record: R) => number;
+}
+
+export function checkpointPath(out: string): string {
+  return `${out}.checkpoint.json`;
+}
+
+export function manifestPath(out: string): string {
+  return `${out}.manifest.json`;
+}
+
+/** One CSV field, quoted when it has to be */
+export function csvField(value: string | number | boolean | null | undefined): string {
+  if (value === null || value === undefined) return '';
+  const text = String(value);
+  return /[",\r\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+}
+
+function csvLine(values: Array<string | number | boolean | null | undefined>): string {
+  return `${values.map(csvField).join(',')}\n`;
+}
+
+function readExportCheckpoint(path: string): ExportCheckpoint | null {
+  if (!existsSync(path)) return null;
+  return JSON.parse(readFileSync(path, 'utf-8')) as ExportCheckpoint;
+}
+
+/** Run `task` over `items`, `concurrency` at a time, keeping their order */
+async function mapConcurrent<T, R>(items: T[], concurrency: number, task: (item: T) => Promise<R>): Promise<R[]> {
+  const results = new Array<R>(items.length);
+  let next = 0;
+  const worker = async () => {
+    while (next < items.length) {
+      const index = next++;
+      results[index] = await task(items[index]);
+    }
+  };
+  await Promise.all(Array.from({ length: Math.min(concurrency, items.length) }, worker));
+  return results;
+}
+
+/**
+ * Write every record from `source` to `options.out`, checkpointing after
+ * each page. Resolves when the list ends or the signal stops the export.
+ */
+export async function exportRecords<T extends ListItem, R extends ListItem = T>(
+  source: ExportSource<T, R>,
+  options: ExportOptions,
+): Promise<ExportResult> {
+  const checkpointFile = checkpointPath(options.out);
+  const previous = options.resume ? readExportCheckpoint(checkpointFile) : null;
+  if (previous && (previous.kind !== source.kind || previous.format !== options.format)) {
+    throw new Error(
+      `${checkpointFile} is for a ${previous.format} export of ${previous.kind}; ` +
+        'pass the same options, or leave out --resume to start over.',
+    );
+  }
+  // A lookback like 24h means a later time on every run, so a resume keeps the one it started with
+  const updatedSince = previous ? previous.updatedSince : (options.updatedSince ?? null);
+
+  const resumedFrom = previous?.records;
+  const state: ExportCheckpoint = previous ?? {
+    kind: source.kind,
+    format: options.format,
+    file: basename(options.out),
+    records: 0,
+    scanned: 0,
+    ...(source.memberships && { memberships: 0 }),
+    updatedSince,
+    range: { from: null, to: null },
+    startedAt: new Date().toISOString(),
+    after: '',
+    bytes: 0,
+  };
+
+  // Anything past the checkpoint is a page that was cut off part-way
+  if (previous) truncateSync(options.out, previous.bytes);
+  const file = await open(options.out, previous ? 'a' : 'w');
+  let interrupted = false;
+  try {
+    if (!previous && options.format === 'csv') {
+      const header = csvLine(source.columns);
+      await file.write(header);
+      state.bytes += Buffer.byteLength(header);
+    }
+
+    for await (const page of source.pages(state.after || undefined)) {
+      const kept = updatedSince ? page.filter((item) => (item.updated_at ?? '') >= updatedSince) : page;
+      const records = source.expand ? await source.expand(kept) : (kept as unknown as R[]);
+      const chunk = records
+        .map((record) => (options.format === 'csv' ? csvLine(source.row(record)) : `${JSON.stringify(record)}\n`))
+        .join('');
+      if (chunk) await file.write(chunk);
+
+      state.bytes += Buffer.byteLength(chunk);
+      state.records += records.length;
+      state.scanned += page.length;
+      state.after = page[page.length - 1].id;
+      for (const record of records) {
+        if (source.memberships) state.memberships = (state.memberships ?? 0) + source.memberships(record);
+        const updated = record.updated_at;
+        if (!updated) continue;
+        if (!state.range.from || updated < state.range.from) state.range.from = updated;
+        if (!state.range.to || updated > state.range.to) state.range.to = updated;
+      }
+      writeFileAtomic(checkpointFile, JSON.stringify(state, null, 2));
+      options.onPage?.({ records: state.records, scanned: state.scanned });
+
+      if (options.signal?.aborted) {
+        interrupted = true;
+        break;
+      }
+    }
+  } finally {
+    await file.close();
+  }
+
+  const { after: _after, bytes: _bytes, ...fields } = state;
+  const manifest: ExportManifest = { ...fields, completedAt: new Date().toISOString() };
+  const path = manifestPath(options.out);
+  if (!interrupted) {
+    writeFileAtomic(path, `${JSON.stringify(manifest, null, 2)}\n`);
+    rmSync(checkpointFile, { force: true });
+  }
+  return { manifest, manifestPath: path, interrupted, ...(resumedFrom !== undefined && { resumedFrom }) };
+}
+
+interface User extends ListItem {
+  email: string;
+  email_verified: boolean;
+  first_name: string | null;
+  last_name: string | null;
+  external_id?: string | null;
+  created_at: string;
+}
+
+interface OrganizationMembership {
+  id: string;
+  organization_id: string;
+  role?: { slug: string };
+  status: string;
+}
+
+export type ExportedUser = User & {
+  organization_memberships?: Array<{ id: string; organization_id: string; role: string | null; status: string }>;
+};
+
+interface Organization extends ListItem {
+  name: string;
+  domains: Array<{ domain: string; state: string }>;
+  external_id?: string | null;
+  created_at: string;
+}
+
+function membershipsCell(user: ExportedUser): string {
+  return (user.organization_memberships ?? [])
+    .map((m) => `${m.organization_id}:${m.role ?? ''}:${m.status}`)
+    .join(';');
+}
+
+/** Users, each with their organization memberships unless `memberships` is false */
+export function userExportSource(
+  api: ApiOptions,
+  options: { memberships?: boolean } = {},
+): ExportSource<User, ExportedUser> {
+  const withMemberships = options.memberships !== false;
+  return {
+    kind: 'users',
+    pages: (after) => paginate<User>({ path: '/user_management/users', ...api, params: { order: 'asc', after } }),
+    ...(withMemberships && {
+      // One lookup per user; run a few at once to hide the latency
+      expand: (page: User[]) =>
+        mapConcurrent(page, DEFAULT_CONCURRENCY, async (user) => {
+          const memberships = await listAll<OrganizationMembership>({
+            path: '/user_management/organization_memberships',
+            ...api,
+            params: { user_id: user.id },
+          });
+          return {
+            ...user,
+            organization_memberships: memberships.map((m) => ({
+              id: m.id,
+              organization_id: m.organization_id,
+              role: m.role?.slug ?? null,
+              status: m.status,
+            })),
+          };
+        }),
+      memberships: (user: ExportedUser) => user.organization_memberships?.length ?? 0,
+    }),
+    columns: [
+      'id',
+      'email',
+      'email_verified',
+      'first_name',
+      'last_name',
+      'external_id',
+      'created_at',
+      'updated_at',
+      ...(withMemberships ? ['organization_memberships'] : []),
+    ],
+    row: (user) => [
+      user.id,
+      user.email,
+      user.email_verified,
+      user.first_name,
+      user.last_name,
+      user.external_id,
+      user.created_at,
+      user.updated_at,
+      ...(withMemberships ? [membershipsCell(user)] : []),
+    ],
+  };
+}
+
+export function organizationExportSource(api: ApiOptions): ExportSource<Organization> {
+  const domains = (org: Organization, state?: string) =>
+    org.domains
+      .filter((d) => !state || d.state === state)
+      .map((d) => d.domain)
+      .join(';');
+  return {
+    kind: 'organizations',
+    pages: (after) => paginate<Organization>({ path: '/organizations', ...api, params: { order: 'asc', after } }),
+    columns: ['id', 'name', 'domains', 'verified_domains', 'external_id', 'created_at', 'updated_at'],
+    row: (org) => [
+      org.id,
+      org.name,
+      domains(org),
+      domains(org, 'verified'),
+      org.external_id,
+      org.created_at,
+      org.updated_at,
+    ],
+  };
+}
@@ -0,0 +1,77 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import {
+  captureMagicAuthCode,
+  capturePasswordResetUrl,
+  describeEmailFlowError,
+  sendMagicAuth,
+  type MagicAuth,
+} from './email-flows.js';
+import { setHttpRetries } from './http-retry.js';
+import { WorkOSApiError } from './workos-api.js';
+
+const api = { apiKey: 'sk_test_abc', baseUrl: 'https://api.workos.com' };
+const MAGIC_AUTH: MagicAuth = {
+  id: 'magic_auth_01',
+  user_id: 'user_01',
+  email: 'jane@example.com',
+  expires_at: '2026-01-01T00:10:00Z',
+};
+
+describe('email-flows', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+
+  function respond(body: unknown) {
+    mockFetch.mockResolvedValueOnce({ ok: true, status: 200, text: async () => JSON.stringify(body) });
+  }
+
+  beforeEach(() => {
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    setHttpRetries(0);
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+  });
+
+  it('creates a Magic Auth code for the email', async () => {
+    respond({ ...MAGIC_AUTH, code: '123456' });
+
+    expect(await sendMagicAuth('jane@example.com', api)).toMatchObject({ id: 'magic_auth_01', code: '123456' });
+    const [url, init] = mockFetch.mock.calls[0];
+    expect(url).toBe('https://api.workos.com/user_management/magic_auth');
+    expect(JSON.parse(init.body)).toEqual({ email: 'jane@example.com' });
+  });
+
+  it('captures the code from the create response without polling', async () => {
+    expect(await captureMagicAuthCode({ ...MAGIC_AUTH, code: '123456' }, api)).toBe('123456');
+    expect(mockFetch).not.toHaveBeenCalled();
+  });
+
+  it('polls until the API returns the code', async () => {
+    respond(MAGIC_AUTH);
+    respond({ ...MAGIC_AUTH, code: '654321' });
+    const sleep = vi.fn(async () => {});
+
+    expect(await captureMagicAuthCode(MAGIC_AUTH, api, { sleep })).toBe('654321');
+    expect(mockFetch.mock.calls[0][0]).toBe('https://api.workos.com/user_management/magic_auth/magic_auth_01');
+    expect(sleep).toHaveBeenCalledTimes(1);
+  });
+
+  it('gives up after the timeout', async () => {
+    const reset = { id: 'password_reset_01', user_id: 'user_01', email: 'jane@example.com', expires_at: '' };
+    respond(reset);
+
+    await expect(capturePasswordResetUrl(reset, api, { timeoutMs: 0, sleep: async () => {} })).rejects.toThrow(
+      'Timed out',
+    );
+  });
+
+  it('surfaces the retry-after value for rate limits', () => {
+    const error = new WorkOSApiError('Too many requests', 429, undefined, undefined, 42);
+
+    expect(describeEmailFlowError(error)).toBe('Rate limited by WorkOS. Try again in 42s.');
+    expect(describeEmailFlowError(new WorkOSApiError('Too many requests', 429))).toContain('Wait a moment');
+  });
+});
@@ -0,0 +1,115 @@
+/**
+ * Trigger Magic Auth and password reset emails for local testing.
+ *
+ * With capture, the generated code or reset link is read back from the API
+ * so flows can be exercised without a real inbox.
+ */
+
+import { workosRequest, WorkOSApiError } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface MagicAuth {
+  id: string;
+  user_id: string;
+  email: string;
+  expires_at: string;
+  code?: string;
+}
+
+export interface PasswordReset {
+  id: string;
+  user_id: string;
+  email: string;
+  expires_at: string;
+  password_reset_token?: string;
+  password_reset_url?: string;
+}
+
+export interface CaptureOptions {
+  timeoutMs?: number;
+  intervalMs?: number;
+  /** Injected by tests */
+  sleep?: (ms: number) => Promise<void>;
+}
+
+const sleep = (ms: number) => new Promise<void>((resolve) => setTimeout(resolve, ms));
+
+export function sendMagicAuth(email: string, api: ApiOptions): Promise<MagicAuth> {
+  return workosRequest<MagicAuth>({ method: 'POST', path: '/user_management/magic_auth', body: { email }, ...api });
+}
+
+export function sendPasswordReset(email: string, api: ApiOptions): Promise<PasswordReset> {
+  return workosRequest<PasswordReset>({
+    method: 'POST',
+    path: '/user_management/password_reset',
+    body: { email },
+    ...api,
+  });
+}
+
+/**
+ * Poll `read` until `pick` returns a value. The create response usually
+ * carries the secret already; polling covers the cases where it doesn't.
+ */
+async function poll<T, R>(
+  read: () => Promise<T>,
+  pick: (value: T) => R | undefined,
+  options: CaptureOptions,
+): Promise<R> {
+  const { timeoutMs = 30_000, intervalMs = 1_000, sleep: wait = sleep } = options;
+  const deadline = Date.now() + timeoutMs;
+  for (;;) {
+    const value = pick(await read());
+    if (value !== undefined) return value;
+    if (Date.now() + intervalMs > deadline) {
+      throw new Error(`Timed out after ${Math.round(timeoutMs / 1000)}s waiting for the API to return it.`);
+    }
+    await wait(intervalMs);
+  }
+}
+
+export async function captureMagicAuthCode(
+  magicAuth: MagicAuth,
+  api: ApiOptions,
+  options: CaptureOptions = {},
+): Promise<string> {
+  if (magicAuth.code) return magicAuth.code;
+  return poll(
+    () => workosRequest<MagicAuth>({ method: 'GET', path: `/user_management/magic_auth/${magicAuth.id}`, ...api }),
+    (value) => value.code,
+    options,
+  );
+}
+
+export async function capturePasswordResetUrl(
+  reset: PasswordReset,
+  api: ApiOptions,
+  options: CaptureOptions = {},
+): Promise<string> {
+  if (reset.password_reset_url) return reset.password_reset_url;
+  return poll(
+    () => workosRequest<PasswordReset>({ method: 'GET', path: `/user_management/password_reset/${reset.id}`, ...api }),
+    (value) => value.password_reset_url,
+    options,
+  );
+}
+
+/**
+ * Turn API errors into a message; rate limits say when to try again.
+ */
+export function describeEmailFlowError(error: unknown): string {
+  if (!(error instanceof WorkOSApiError)) return error instanceof Error ? error.message : String(error);
+  if (error.statusCode === 429) {
+    return error.retryAfter !== undefined
+      ? `Rate limited by WorkOS. Try again in ${error.retryAfter}s.`
+      : 'Rate limited by WorkOS. Wait a moment and try again.';
+  }
+  if (error.statusCode === 401) return 'Invalid API key. Check your environment configuration.';
+  if (error.statusCode === 404) return 'No user with that email.';
+  if (error.statusCode === 422 && error.errors?.length) return error.errors.map((e) => e.message).join(', ');
+  return error.message;
+}
@@ -0,0 +1,70 @@
+import { describe, it, expect } from 'vitest';
+import {
+  findTemplateVariables,
+  parseTemplateFile,
+  renderTemplatePreview,
+  resolveTemplateType,
+  serializeTemplate,
+  unknownTemplateVariables,
+  type EmailTemplate,
+} from './email-templates.js';
+
+const INVITATION: EmailTemplate = {
+  type: 'invitation',
+  subject: 'Join {{organization_name}} on {{app_name}}',
+  html: [
+    '<p>{{#if inviter_email}}{{inviter_email}} invited you{{else}}You were invited{{/if}}.</p>',
+    '<a href="{{invitation_url}}">Accept</a>',
+    '{{#unless expires_in_days}}<p>This invitation does not expire.</p>{{/unless}}',
+    '',
+  ].join('\n'),
+};
+
+describe('email-templates', () => {
+  it('accepts the names people use for templates', () => {
+    expect(resolveTemplateType('invitation')).toBe('invitation');
+    expect(resolveTemplateType('magic-link')).toBe('magic_auth');
+    expect(resolveTemplateType('password-reset')).toBe('password_reset');
+    expect(resolveTemplateType('welcome')).toBeNull();
+  });
+
+  it('round-trips the subject and HTML through one file', () => {
+    const file = serializeTemplate(INVITATION);
+
+    expect(file.split('\n')[0]).toBe('<!-- subject: Join {{organization_name}} on {{app_name}} -->');
+    expect(parseTemplateFile('invitation', file)).toEqual(INVITATION);
+    expect(() => parseTemplateFile('invitation', '<p>Hi</p>')).toThrow('invitation.html must start with');
+  });
+
+  it('lists variables and flags the ones WorkOS does not provide', () => {
+    const template = { ...INVITATION, html: `${INVITATION.html}<p>{{ org_name }} {{{ footer_html }}}</p>` };
+
+    expect(findTemplateVariables(template)).toEqual([
+      'organization_name',
+      'app_name',
+      'inviter_email',
+      'invitation_url',
+      'expires_in_days',
+      'org_name',
+      'footer_html',
+    ]);
+    expect(unknownTemplateVariables(template)).toEqual(['org_name', 'footer_html']);
+    expect(unknownTemplateVariables(INVITATION)).toEqual([]);
+  });
+
+  it('renders with sample values, escaping them in HTML but not the subject', () => {
+    const preview = renderTemplatePreview(INVITATION);
+
+    expect(preview.subject).toBe('Join Acme Corp on Acme');
+    expect(preview.html).toBe(
+      '<p>admin@example.com invited you.</p>\n' +
+        '<a href="https://auth.example.com/invite?token=sample">Accept</a>\n\n',
+    );
+    expect(renderTemplatePreview(INVITATION, {}).html).toContain('<p>You were invited.</p>');
+    const escaped = renderTemplatePreview(
+      { ...INVITATION, html: '{{organization_name}}' },
+      { organization_name: '<R&D>' },
+    );
+    expect(escaped).toEqual({ subject: 'Join <R&D> on ', html: '&lt;R&amp;D&gt;' });
+  });
+});
@@ -0,0 +1,158 @@
+/**
+ * Email templates as local files: export, diff, variable checks and preview.
+ *
+ * Each template is one `<type>.html` file holding the HTML body, with the
+ * subject in a leading `<!-- subject: ... -->` comment so the file still
+ * opens in a browser and diffs as one unit. Templates use `{{variable}}`
+ * placeholders (`{{{variable}}}` unescaped, `{{#if variable}}` blocks); a
+ * placeholder WorkOS doesn't fill in for that template renders empty in
+ * customers' inboxes, so pushes check them against the known variables
+ * first. Previews are rendered locally with sample values.
+ */
+
+import { listAll } from './pagination.js';
+import { workosRequest } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface EmailTemplate {
+  type: string;
+  subject: string;
+  html: string;
+}
+
+const COMMON_VARIABLES: Record<string, string> = {
+  app_name: 'Acme',
+  logo_url: 'https://example.com/logo.png',
+  user_email: 'user@example.com',
+};
+
+/** Variables WorkOS renders into each template, with the sample values previews use */
+export const TEMPLATE_VARIABLES: Record<string, Record<string, string>> = {
+  invitation: {
+    ...COMMON_VARIABLES,
+    organization_name: 'Acme Corp',
+    inviter_email: 'admin@example.com',
+    invitation_url: 'https://auth.example.com/invite?token=sample',
+    expires_in_days: '7',
+  },
+  magic_auth: { ...COMMON_VARIABLES, code: '123456', expires_in_minutes: '10' },
+  password_reset: {
+    ...COMMON_VARIABLES,
+    password_reset_url: 'https://auth.example.com/reset-password?token=sample',
+    expires_in_minutes: '30',
+  },
+  email_verification: { ...COMMON_VARIABLES, code: '123456', expires_in_minutes: '10' },
+};
+
+/** What people call the templates, e.g. `magic-link` for `magic_auth` */
+const TYPE_ALIASES: Record<string, string> = {
+  'magic-link': 'magic_auth',
+  magic_link: 'magic_auth',
+  invite: 'invitation',
+};
+
+/**
+ * The template type for a name given on the command line, or null when it
+ * isn't one.
+ */
+export function resolveTemplateType(name: string): string | null {
+  const normalized = name.trim().toLowerCase();
+  const type = TYPE_ALIASES[normalized] ?? normalized.replace(/-/g, '_');
+  return type in TEMPLATE_VARIABLES ? type : null;
+}
+
+export async function listEmailTemplates(api: ApiOptions): Promise<EmailTemplate[]> {
+  const templates = await listAll<EmailTemplate>({ path: '/user_management/email_templates', ...api });
+  return templates.map(({ type, subject, html }) => ({ type, subject: subject ?? '', html: html ?? '' }));
+}
+
+export async function updateEmailTemplate(template: EmailTemplate, api: ApiOptions): Promise<void> {
+  await workosRequest({
+    method: 'PUT',
+    path: `/user_management/email_templates/${encodeURIComponent(template.type)}`,
+    body: { subject: template.subject, html: template.html },
+    ...api,
+  });
+}
+
+const SUBJECT_COMMENT = /^<!--\s*subject:\s*(.*?)\s*-->\r?\n?/;
+
+export function templateFileName(type: string): string {
+  return `${type}.html`;
+}
+
+/** File contents for a template: the subject comment, then the HTML */
+export function serializeTemplate(template: EmailTemplate): string {
+  const html = template.html.endsWith('\n') ? template.html : `${template.html}\n`;
+  return `<!-- subject: ${template.subject} -->\n${html}`;
+}
+
+export function parseTemplateFile(type: string, content: string): EmailTemplate {
+  const subject = SUBJECT_COMMENT.exec(content);
+  if (!subject) throw new Error(`${templateFileName(type)} must start with a <!-- subject: ... --> comment`);
+  return { type, subject: subject[1], html: content.slice(subject[0].length) };
+}
+
+/** `{{name}}`, `{{{name}}}` and the argument of `{{#if name}}`/`{{#unless name}}` */
+const PLACEHOLDER = /\{\{\{?\s*(?:#(?:if|unless)\s+)?([A-Za-z_][\w.]*)\s*\}?\}\}/g;
+const KEYWORDS = new Set(['else']);
+
+/** Variable names a template uses, in order of first use */
+export function findTemplateVariables(template: EmailTemplate): string[] {
+  const names = new Set<string>();
+  for (const text of [template.subject, template.html]) {
+    for (const match of text.matchAll(PLACEHOLDER)) {
+      if (!KEYWORDS.has(match[1])) names.add(match[1]);
+    }
+  }
+  return [...names];
+}
+
+/** Variables a template uses that WorkOS doesn't provide for its type */
+export function unknownTemplateVariables(template: EmailTemplate): string[] {
+  const known = TEMPLATE_VARIABLES[template.type] ?? {};
+  return findTemplateVariables(template).filter((name) => !(name in known));
+}
+
+function escapeHtml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&#39;');
+}
+
+/** An `{{#if}}`/`{{#unless}}` block with no blocks inside it */
+const INNERMOST_BLOCK = /\{\{#(if|unless)\s+([\w.]+)\s*\}\}((?:(?!\{\{#)[\s\S])*?)\{\{\/\1\s*\}\}/;
+
+function renderText(text: string, values: Record<string, string>, escape: boolean): string {
+  let rendered = text;
+  for (let block = INNERMOST_BLOCK.exec(rendered); block; block = INNERMOST_BLOCK.exec(rendered)) {
+    const [whole, keyword, name, body] = block;
+    const [then, otherwise = ''] = body.split(/\{\{\s*else\s*\}\}/);
+    const truthy = Boolean(values[name]);
+    const kept = (keyword === 'if') === truthy ? then : otherwise;
+    rendered = rendered.slice(0, block.index) + kept + rendered.slice(block.index + whole.length);
+  }
+  return rendered
+    .replace(/\{\{\{\s*([\w.]+)\s*\}\}\}/g, (_, name: string) => values[name] ?? '')
+    .replace(/\{\{\s*([\w.]+)\s*\}\}/g, (_, name: string) =>
+      escape ? escapeHtml(values[name] ?? '') : (values[name] ?? ''),
+    );
+}
+
+/**
+ * Render a template with its type's sample values; unknown variables render
+ * empty, as they would when sent.
+ */
+export function renderTemplatePreview(
+  template: EmailTemplate,
+  values: Record<string, string> = TEMPLATE_VARIABLES[template.type] ?? {},
+): { subject: string; html: string } {
+  return { subject: renderText(template.subject, values, false), html: renderText(template.html, values, true) };
+}
@@ -2,9 +2,8 @@
  * Startup auth guard - ensures valid authentication before command execution.
  */
 
-import { getCredentials, updateTokens, hasCredentials, isTokenExpired } from './credentials.js';
-import { refreshAccessToken } from './token-refresh-client.js';
-import { getCliAuthClientId, getAuthkitDomain } from './settings.js';
+import { getCredentials, hasCredentials, isTokenExpired } from './credentials.js';
+import { refreshSession, tokenExpiresSoon } from './token-refresh.js';
 import { runLogin } from '../commands/login.js';
 import { logInfo } from '../utils/debug.js';
 
@@ -21,7 +20,7 @@ export interface EnsureAuthResult {
  * Ensure valid authentication before command execution.
  *
  * - No credentials: triggers login flow
- * - Expired access token (valid refresh): silently refreshes
+ * - Expired or expiring access token (valid refresh): silently refreshes
  * - Expired refresh token: triggers login flow
  *
  * @returns Result indicating what actions were taken
@@ -53,45 +52,46 @@ export async function ensureAuthenticated(): Promise<EnsureAuthResult> {
     return result;
   }
 
-  // Case 2: Access token still valid
-  if (!isTokenExpired(creds)) {
+  // Case 2: Access token valid for a while yet
+  if (!tokenExpiresSoon(creds)) {
     result.authenticated = true;
     return result;
   }
 
-  // Case 3: Access token expired, try refresh
+  // Case 3: Access token expired or about to, try refresh
   if (creds.refreshToken) {
-    logInfo('[ensure-auth] Access token expired, attempting refresh');
+    logInfo('[ensure-auth] Access token expiring, attempting refresh');
 
-    const clientId = getCliAuthClientId();
-    const authkitDomain = getAuthkitDomain();
+    const refreshResult = await refreshSession(creds.accessToken);
 
-    if (clientId && authkitDomain) {
-      const refreshResult = await refreshAccessToken(authkitDomain, clientId);
-
-      if (refreshResult.success && refreshResult.accessToken && refreshResult.expiresAt) {
-        updateTokens(refreshResult.accessToken, refreshResult.expiresAt, refreshResult.refreshToken);
-        result.tokenRefreshed = true;
-        result.authenticated = true;
-        return result;
-      }
+    if (refreshResult.success) {
+      result.tokenRefreshed = true;
+      result.authenticated = true;
+      return result;
+    }
 
-      // Refresh failed - check if it's recoverable
-      if (refreshResult.errorType === 'invalid_grant') {
-        logInfo('[ensure-auth] Refresh token expired, triggering login');
-        await runLogin();
-        result.loginTriggered = true;
-        result.authenticated = hasCredentials();
-        return result;
-      }
+    // A command that starts just before expiry can still use the current token
+    if (refreshResult.errorType !== 'invalid_grant' && !isTokenExpired(creds)) {
+      logInfo(`[ensure-auth] Refresh failed (${refreshResult.errorType}), current token still valid`);
+      result.authenticated = true;
+      return result;
+    }
 
-      // Network or server error - try login as fallback
-      logInfo(`[ensure-auth] Refresh failed (${refreshResult.errorType}), triggering login`);
+    // Refresh failed - check if it's recoverable
+    if (refreshResult.errorType === 'invalid_grant') {
+      logInfo('[ensure-auth] Refresh token expired, triggering login');
       await runLogin();
       result.loginTriggered = true;
       result.authenticated = hasCredentials();
       return result;
     }
+
+    // Network or server error - try login as fallback
+    logInfo(`[ensure-auth] Refresh failed (${refreshResult.errorType}), triggering login`);
+    await runLogin();
+    result.loginTriggered = true;
+    result.authenticated = hasCredentials();
+    return result;
   }
 
   // Case 4: No refresh token available, must login
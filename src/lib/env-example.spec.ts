@@ -0,0 +1,76 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { mergeEnvExample, toExampleEntry } from './env-example.js';
+import { writeEnvLocal } from './env-writer.js';
+
+describe('toExampleEntry', () => {
+  it('uses placeholders for WorkOS secrets and keeps redirect URIs', () => {
+    expect(toExampleEntry('WORKOS_API_KEY', 'sk_test_abc').value).toBe('sk_test_...');
+    expect(toExampleEntry('WORKOS_COOKIE_PASSWORD', 'abcdef').value).toBe('');
+    expect(toExampleEntry('WORKOS_REDIRECT_URI', 'http://localhost:3000/callback').value).toBe(
+      'http://localhost:3000/callback',
+    );
+  });
+
+  it('blanks values of unknown keys', () => {
+    expect(toExampleEntry('DATABASE_URL', 'postgres://user:pw@db/app')).toEqual({ key: 'DATABASE_URL', value: '' });
+  });
+});
+
+describe('mergeEnvExample', () => {
+  const entries = [toExampleEntry('WORKOS_API_KEY', 'sk_test_abc'), toExampleEntry('WORKOS_CLIENT_ID', 'client_1')];
+
+  it('creates the file with a comment per key', () => {
+    const result = mergeEnvExample(null, entries);
+
+    expect(result.split('\n')).toEqual([
+      expect.stringMatching(/^# WorkOS API key/),
+      'WORKOS_API_KEY=sk_test_...',
+      expect.stringMatching(/^# WorkOS client ID/),
+      'WORKOS_CLIENT_ID=client_...',
+      '',
+    ]);
+  });
+
+  it('preserves existing keys, values and comments', () => {
+    const existing = '# Database\nDATABASE_URL=postgres://localhost/app\n\nWORKOS_CLIENT_ID=client_dev\n';
+
+    const result = mergeEnvExample(existing, entries);
+
+    expect(result.startsWith(existing)).toBe(true);
+    expect(result).toContain('WORKOS_API_KEY=sk_test_...');
+    expect(result.match(/WORKOS_CLIENT_ID=/g)).toHaveLength(1);
+  });
+
+  it('treats commented-out keys as documented', () => {
+    const existing = '# WORKOS_API_KEY=\n# WORKOS_CLIENT_ID=\n';
+
+    expect(mergeEnvExample(existing, entries)).toBe(existing);
+  });
+});
+
+describe('writeEnvLocal', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'env-example-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('keeps real values out of .env.example', () => {
+    writeFileSync(join(dir, '.env.example'), 'PORT=3000\n');
+
+    writeEnvLocal(dir, { WORKOS_API_KEY: 'sk_test_secret', WORKOS_CLIENT_ID: 'client_123' });
+
+    const example = readFileSync(join(dir, '.env.example'), 'utf-8');
+    expect(example.startsWith('PORT=3000\n\n')).toBe(true);
+    expect(example).toContain('WORKOS_COOKIE_PASSWORD=');
+    expect(example).not.toContain('sk_test_secret');
+    expect(readFileSync(join(dir, '.env.local'), 'utf-8')).toContain('WORKOS_API_KEY=sk_test_secret');
+  });
+});
@@ -0,0 +1,93 @@
+/**
+ * .env.example maintenance.
+ *
+ * Real values stay in the gitignored env file; the committed .env.example
+ * lists every key with a placeholder and a short description so teammates
+ * know what to set. Merging never rewrites or reorders existing lines.
+ */
+
+import { join } from 'node:path';
+import { parseEnvFile } from '../utils/env-parser.js';
+import type { FileEdit } from './atomic-write.js';
+
+export const ENV_EXAMPLE_FILE = '.env.example';
+
+interface KnownEnvVar {
+  description: string;
+  /** Placeholder written instead of the real value; omitted = keep the value (non-secret) */
+  placeholder?: string;
+}
+
+const KNOWN_ENV_VARS: Record<string, KnownEnvVar> = {
+  WORKOS_API_KEY: {
+    description: 'WorkOS API key (Dashboard → API Keys). Keep secret.',
+    placeholder: 'sk_test_...',
+  },
+  WORKOS_CLIENT_ID: {
+    description: 'WorkOS client ID for this environment (Dashboard → API Keys)',
+    placeholder: 'client_...',
+  },
+  WORKOS_REDIRECT_URI: {
+    description: 'AuthKit callback URL; must be registered in the WorkOS Dashboard (Redirects)',
+  },
+  NEXT_PUBLIC_WORKOS_REDIRECT_URI: {
+    description: 'AuthKit callback URL; must be registered in the WorkOS Dashboard (Redirects)',
+  },
+  WORKOS_COOKIE_PASSWORD: {
+    description: 'Session cookie encryption key, at least 32 characters. Generate with: openssl rand -hex 16',
+    placeholder: '',
+  },
+};
+
+export interface EnvExampleEntry {
+  key: string;
+  value: string;
+  description?: string;
+}
+
+/**
+ * Example entry for a key. Known WorkOS vars get a description and
+ * placeholder; other values are blanked since they may embed credentials
+ * (e.g. DATABASE_URL).
+ */
+export function toExampleEntry(key: string, value: string): EnvExampleEntry {
+  const known = KNOWN_ENV_VARS[key];
+  if (known) {
+    return { key, value: known.placeholder ?? value, description: known.description };
+  }
+  return { key, value: '' };
+}
+
+/**
+ * Append entries whose keys are missing from an existing .env.example.
+ * Existing keys, values, comments and ordering are preserved.
+ */
+export function mergeEnvExample(existing: string | null, entries: EnvExampleEntry[]): string {
+  const present = new Set(Object.keys(parseEnvFile(existing ?? '')));
+  // Keys commented out (`# KEY=`) count as documented too
+  for (const match of (existing ?? '').matchAll(/^\s*#\s*([A-Z][A-Z0-9_]*)=/gm)) {
+    present.add(match[1]);
+  }
+
+  const missing = entries.filter((entry) => !present.has(entry.key));
+  if (missing.length === 0) return existing ?? '';
+
+  const block = missing
+    .map((entry) => (entry.description ? `# ${entry.description}\n` : '') + `${entry.key}=${entry.value}`)
+    .join('\n');
+
+  if (!existing) return block + '\n';
+  const separator = existing.endsWith('\n\n') ? '' : existing.endsWith('\n') ? '\n' : '\n\n';
+  return `${existing}${separator}${block}\n`;
+}
+
+/**
+ * Edit that documents the given env vars in .env.example, for use with applyFileEdits.
+ */
+export function envExampleEdit(installDir: string, envVars: Record<string, string>): FileEdit {
+  const entries = Object.entries(envVars).map(([key, value]) => toExampleEntry(key, value));
+  return {
+    path: join(installDir, ENV_EXAMPLE_FILE),
+    content: (current) => mergeEnvExample(current, entries),
+  };
+}
@@ -1,6 +1,7 @@
-import { existsSync, readFileSync, writeFileSync } from 'fs';
 import { join } from 'path';
 import { parseEnvFile } from '../utils/env-parser.js';
+import { applyFileEdits, type FileEdit } from './atomic-write.js';
+import { envExampleEdit } from './env-example.js';
 
 interface EnvVars {
   WORKOS_API_KEY?: string;
@@ -15,39 +16,50 @@ interface EnvVars {
  * Returns 32-char hex string (16 random bytes).
  * Uses Web Crypto API available in Node.js 20+
  */
-function generateCookiePassword(): string {
+export function generateCookiePassword(): string {
   const array = new Uint8Array(16);
   crypto.getRandomValues(array);
   return Array.from(array, (byte) => byte.toString(16).padStart(2, '0')).join('');
 }
 
 /**
- * Write environment variables to .env.local before agent runs.
- * Merges with existing .env.local if present (new vars take precedence).
+ * Edit that merges environment variables into an env file.
+ * Merges with the existing file if present (new vars take precedence).
  * Auto-generates WORKOS_COOKIE_PASSWORD if not provided.
+ *
+ * Returned as a FileEdit so callers can apply it together with related
+ * files (e.g. .gitignore) in one all-or-nothing write.
  */
-export function writeEnvLocal(installDir: string, envVars: Partial<EnvVars>): void {
-  const envPath = join(installDir, '.env.local');
+export function envFileEdit(installDir: string, envVars: Partial<EnvVars>, fileName = '.env.local'): FileEdit {
+  return {
+    path: join(installDir, fileName),
+    content: (current) => {
+      // Merge with new vars (new vars take precedence)
+      const merged = { ...(current ? parseEnvFile(current) : {}), ...envVars };
 
-  // Read existing env if present
-  let existingEnv: Record<string, string> = {};
-  if (existsSync(envPath)) {
-    const content = readFileSync(envPath, 'utf-8');
-    existingEnv = parseEnvFile(content);
-  }
+      // Generate cookie password if not provided
+      if (!merged.WORKOS_COOKIE_PASSWORD) {
+        merged.WORKOS_COOKIE_PASSWORD = generateCookiePassword();
+      }
 
-  // Merge with new vars (new vars take precedence)
-  const merged = { ...existingEnv, ...envVars };
-
-  // Generate cookie password if not provided
-  if (!merged.WORKOS_COOKIE_PASSWORD) {
-    merged.WORKOS_COOKIE_PASSWORD = generateCookiePassword();
-  }
-
-  // Write back
-  const content = Object.entries(merged)
-    .map(([key, value]) => `${key}=${value}`)
-    .join('\n');
+      const content = Object.entries(merged)
+        .map(([key, value]) => `${key}=${value}`)
+        .join('\n');
+      return content + '\n';
+    },
+  };
+}
 
-  writeFileSync(envPath, content + '\n');
+/**
+ * Write environment variables to .env.local before agent runs, and document
+ * the keys in .env.example. See envFileEdit for merge behavior.
+ *
+ * @param fileName - Env file to write, for frameworks that don't read .env.local
+ */
+export function writeEnvLocal(installDir: string, envVars: Partial<EnvVars>, fileName = '.env.local'): void {
+  const keys = Object.fromEntries(Object.entries(envVars).filter(([, value]) => value !== undefined));
+  applyFileEdits([
+    envFileEdit(installDir, envVars, fileName),
+    envExampleEdit(installDir, { ...keys, WORKOS_COOKIE_PASSWORD: '' }),
+  ]);
 }
@@ -0,0 +1,121 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import type { EnvironmentConfigSnapshot } from './environment-config.js';
+
+vi.mock('./workos-api.js', async (importOriginal) => ({
+  ...(await importOriginal<typeof import('./workos-api.js')>()),
+  workosRequest: vi.fn(),
+}));
+
+const { workosRequest, WorkOSApiError } = await import('./workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { applyClonePlan, placeholderWebhookUrl, planClone } = await import('./environment-clone.js');
+
+function snapshot(items: Partial<EnvironmentConfigSnapshot['items']> = {}): EnvironmentConfigSnapshot {
+  return {
+    items: {
+      redirectUris: {},
+      corsOrigins: {},
+      roles: {},
+      authentication: {},
+      emailTemplates: {},
+      branding: {},
+      webhookEndpoints: {},
+      ...items,
+    },
+    unreadable: [],
+  };
+}
+
+const production = snapshot({
+  redirectUris: { 'https://app.example.com/callback': { default: true } },
+  corsOrigins: { 'https://app.example.com': {} },
+  roles: {
+    admin: { name: 'Admin', description: null, permissions: ['posts:read', 'posts:write'] },
+    viewer: { name: 'Viewer', description: null, permissions: ['posts:read'] },
+  },
+  webhookEndpoints: { 'https://api.example.com/webhooks': { events: ['user.created'], enabled: true } },
+});
+
+describe('environment-clone', () => {
+  beforeEach(() => {
+    mockRequest.mockReset();
+  });
+
+  it('creates everything a new environment is missing', () => {
+    const plan = planClone(production, snapshot());
+
+    expect(plan.steps.map((s) => `${s.action} ${s.category} ${s.key}`)).toEqual([
+      'create redirectUris https://app.example.com/callback',
+      'create corsOrigins https://app.example.com',
+      'create roles admin',
+      'create roles viewer',
+      'create webhookEndpoints https://placeholder.invalid/api.example.com/webhooks',
+    ]);
+    expect(plan.steps.at(-1)).toMatchObject({
+      note: 'placeholder for https://api.example.com/webhooks',
+      request: { body: { endpoint_url: 'https://placeholder.invalid/api.example.com/webhooks' } },
+    });
+  });
+
+  it('keeps webhook URLs when asked', () => {
+    const plan = planClone(production, snapshot(), { keepWebhookUrls: true });
+
+    expect(plan.steps.find((s) => s.category === 'webhookEndpoints')?.key).toBe('https://api.example.com/webhooks');
+  });
+
+  it('updates roles that differ and never deletes from the target', () => {
+    const target = snapshot({
+      redirectUris: { 'https://app.example.com/callback': { default: false } },
+      roles: {
+        admin: { name: 'Admin', description: null, permissions: ['posts:read'] },
+        viewer: { name: 'Viewer', description: null, permissions: ['posts:read'] },
+        legacy: { name: 'Legacy', description: null, permissions: [] },
+      },
+    });
+
+    const plan = planClone(production, target);
+    const roles = plan.steps.filter((s) => s.category === 'roles');
+
+    expect(roles).toEqual([
+      {
+        category: 'roles',
+        action: 'update',
+        key: 'admin',
+        request: {
+          method: 'PUT',
+          path: '/authorization/roles/admin',
+          body: { slug: 'admin', name: 'Admin', description: undefined, permissions: ['posts:read', 'posts:write'] },
+        },
+      },
+    ]);
+    expect(plan.steps.some((s) => s.key === 'legacy')).toBe(false);
+    expect(plan.unchanged).toBe(2);
+  });
+
+  it('skips categories that could not be read', () => {
+    const source = snapshot({ branding: null });
+    source.unreadable.push({ category: 'branding', reason: 'Not found' });
+
+    expect(planClone(source, snapshot()).skipped).toEqual([{ category: 'branding', reason: 'Not found' }]);
+  });
+
+  it('derives distinct placeholder URLs', () => {
+    expect(placeholderWebhookUrl('https://api.example.com/hooks?team=1')).toBe(
+      'https://placeholder.invalid/api.example.com/hooks-team-1',
+    );
+  });
+
+  it('keeps applying after a step fails', async () => {
+    mockRequest.mockRejectedValueOnce(new WorkOSApiError('Invalid redirect URI', 422)).mockResolvedValue({});
+    const plan = planClone(production, snapshot());
+
+    const result = await applyClonePlan(plan, { apiKey: 'sk_test_qa' });
+
+    expect(result.failed).toEqual([{ step: plan.steps[0], reason: 'Invalid redirect URI' }]);
+    expect(result.applied).toHaveLength(plan.steps.length - 1);
+    expect(mockRequest).toHaveBeenCalledWith(
+      expect.objectContaining({ method: 'POST', path: '/authorization/roles', idempotencyKey: expect.any(String) }),
+    );
+  });
+});
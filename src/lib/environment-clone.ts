@@ -0,0 +1,170 @@
+/**
+ * Copying one environment's configuration into another (`workos env clone`).
+ *
+ * The plan is built from both environments' snapshots: items the target is
+ * missing are created, and roles and branding that differ are updated.
+ * Nothing is deleted from the target, so cloning into an environment that
+ * already has configuration only adds to it. Webhook endpoints are pointed
+ * at placeholder URLs under the reserved `.invalid` domain unless the real
+ * URLs are kept, so a QA environment doesn't deliver events to production
+ * receivers.
+ */
+
+import { randomUUID } from 'node:crypto';
+import { CATEGORY_LABELS, sameValue, type ConfigItem, type EnvironmentConfigSnapshot } from './environment-config.js';
+import { workosRequest } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export const CLONED_CATEGORIES = ['redirectUris', 'corsOrigins', 'roles', 'branding', 'webhookEndpoints'] as const;
+export type ClonedCategory = (typeof CLONED_CATEGORIES)[number];
+
+/** What a clone leaves behind, for the output */
+export const NOT_CLONED = [
+  'API keys and client IDs (the new environment has its own)',
+  'SSO connections and directories',
+  'Organizations, users and memberships',
+  'Authentication settings and email templates',
+  'Webhook signing secrets (each endpoint gets a new one)',
+];
+
+export interface CloneStep {
+  category: ClonedCategory;
+  action: 'create' | 'update';
+  key: string;
+  /** Shown next to the step, e.g. the real URL behind a placeholder */
+  note?: string;
+  request: { method: 'POST' | 'PUT'; path: string; body: Record<string, unknown> };
+}
+
+export interface ClonePlan {
+  steps: CloneStep[];
+  /** Items the target already has as they are in the source */
+  unchanged: number;
+  /** Categories left out because either environment couldn't be read */
+  skipped: Array<{ category: ClonedCategory; reason: string }>;
+}
+
+export interface CloneOptions {
+  /** Create webhook endpoints with the source's URLs instead of placeholders */
+  keepWebhookUrls?: boolean;
+}
+
+/** A URL that can't receive anything, derived from the real one so endpoints stay distinct */
+export function placeholderWebhookUrl(url: string): string {
+  const rest = url.replace(/^[a-z][a-z0-9+.-]*:\/\//i, '').replace(/[^a-zA-Z0-9/._-]+/g, '-');
+  return `https://placeholder.invalid/${rest}`;
+}
+
+function sameItem(a: ConfigItem, b: ConfigItem): boolean {
+  return [...new Set([...Object.keys(a), ...Object.keys(b)])].every((field) => sameValue(a[field], b[field]));
+}
+
+function roleBody(slug: string, role: ConfigItem): Record<string, unknown> {
+  return { slug, name: role.name, description: role.description ?? undefined, permissions: role.permissions };
+}
+
+function planCategory(
+  category: ClonedCategory,
+  source: Record<string, ConfigItem>,
+  target: Record<string, ConfigItem>,
+  options: CloneOptions,
+): { steps: CloneStep[]; unchanged: number } {
+  const steps: CloneStep[] = [];
+  let unchanged = 0;
+
+  for (const [key, item] of Object.entries(source).sort(([a], [b]) => a.localeCompare(b))) {
+    if (category === 'webhookEndpoints') {
+      const url = options.keepWebhookUrls ? key : placeholderWebhookUrl(key);
+      if (url in target) {
+        unchanged++;
+        continue;
+      }
+      steps.push({
+        category,
+        action: 'create',
+        key: url,
+        ...(url !== key && { note: `placeholder for ${key}` }),
+        request: { method: 'POST', path: '/webhook_endpoints', body: { endpoint_url: url, events: item.events } },
+      });
+      continue;
+    }
+
+    const existing = target[key];
+    if (existing && sameItem(existing, item)) {
+      unchanged++;
+      continue;
+    }
+    if (category === 'roles') {
+      const body = roleBody(key, item);
+      steps.push(
+        existing
+          ? { category, action: 'update', key, request: { method: 'PUT', path: `/authorization/roles/${key}`, body } }
+          : { category, action: 'create', key, request: { method: 'POST', path: '/authorization/roles', body } },
+      );
+    } else if (category === 'branding') {
+      steps.push({ category, action: 'update', key, request: { method: 'PUT', path: '/branding', body: item } });
+    } else if (existing) {
+      // Redirect URIs and CORS origins only differ by the default flag, which is set in the dashboard
+      unchanged++;
+    } else {
+      const request =
+        category === 'redirectUris'
+          ? { method: 'POST' as const, path: '/user_management/redirect_uris', body: { uri: key } }
+          : { method: 'POST' as const, path: '/user_management/cors_origins', body: { origin: key } };
+      steps.push({ category, action: 'create', key, request });
+    }
+  }
+  return { steps, unchanged };
+}
+
+export function planClone(
+  source: EnvironmentConfigSnapshot,
+  target: EnvironmentConfigSnapshot,
+  options: CloneOptions = {},
+): ClonePlan {
+  const plan: ClonePlan = { steps: [], unchanged: 0, skipped: [] };
+  for (const category of CLONED_CATEGORIES) {
+    const from = source.items[category];
+    const to = target.items[category];
+    if (!from || !to) {
+      const reason = [...source.unreadable, ...target.unreadable].find((u) => u.category === category)?.reason;
+      plan.skipped.push({ category, reason: reason ?? `couldn't read ${CATEGORY_LABELS[category].toLowerCase()}` });
+      continue;
+    }
+    const { steps, unchanged } = planCategory(category, from, to, options);
+    plan.steps.push(...steps);
+    plan.unchanged += unchanged;
+  }
+  return plan;
+}
+
+export interface CloneResult {
+  applied: CloneStep[];
+  failed: Array<{ step: CloneStep; reason: string }>;
+}
+
+/**
+ * Run every step against the target. A step that fails is reported and the
+ * rest still run, so one rejected URI doesn't leave the clone half done.
+ */
+export async function applyClonePlan(plan: ClonePlan, api: ApiOptions): Promise<CloneResult> {
+  const result: CloneResult = { applied: [], failed: [] };
+  for (const step of plan.steps) {
+    try {
+      await workosRequest({
+        ...step.request,
+        // The key makes a create safe to retry if the first attempt's response is lost
+        ...(step.request.method === 'POST' && { idempotencyKey: randomUUID() }),
+        ...api,
+      });
+      result.applied.push(step);
+    } catch (error) {
+      result.failed.push({ step, reason: error instanceof Error ? error.message : String(error) });
+    }
+  }
+  return result;
+}
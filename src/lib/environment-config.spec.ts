@@ -0,0 +1,116 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import type { EnvironmentConfigSnapshot } from './environment-config.js';
+
+vi.mock('./workos-api.js', async (importOriginal) => ({
+  ...(await importOriginal<typeof import('./workos-api.js')>()),
+  workosRequest: vi.fn(),
+}));
+
+const { workosRequest, WorkOSApiError } = await import('./workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { diffEnvironments, diffItems, fetchEnvironmentConfig } = await import('./environment-config.js');
+
+function snapshot(items: Partial<EnvironmentConfigSnapshot['items']> = {}): EnvironmentConfigSnapshot {
+  return {
+    items: {
+      redirectUris: {},
+      corsOrigins: {},
+      roles: {},
+      authentication: {},
+      emailTemplates: {},
+      branding: {},
+      webhookEndpoints: {},
+      ...items,
+    },
+    unreadable: [],
+  };
+}
+
+describe('environment-config', () => {
+  beforeEach(() => {
+    mockRequest.mockReset();
+  });
+
+  describe('diffItems', () => {
+    it('marks items only on one side as added or removed', () => {
+      const changes = diffItems(
+        { 'http://localhost:3000/callback': { default: true } },
+        { 'https://app.example.com/callback': { default: true } },
+      );
+
+      expect(changes).toEqual([
+        { type: 'removed', key: 'http://localhost:3000/callback', value: { default: true } },
+        { type: 'added', key: 'https://app.example.com/callback', value: { default: true } },
+      ]);
+    });
+
+    it('lists the fields of modified items, ignoring list order', () => {
+      const changes = diffItems(
+        { admin: { name: 'Admin', permissions: ['posts:read', 'posts:write'] } },
+        { admin: { name: 'Administrator', permissions: ['posts:write', 'posts:read'] } },
+      );
+
+      expect(changes).toEqual([
+        { type: 'modified', key: 'admin', fields: [{ field: 'name', from: 'Admin', to: 'Administrator' }] },
+      ]);
+    });
+
+    it('elides secret fields', () => {
+      const changes = diffItems({ settings: { client_secret: 'a' } }, { settings: { client_secret: 'b' } });
+
+      expect(changes[0].fields).toEqual([{ field: 'client_secret', from: '****', to: '****' }]);
+    });
+  });
+
+  it('skips categories one environment could not read', () => {
+    const production = snapshot({ emailTemplates: null });
+    production.unreadable.push({ category: 'emailTemplates', reason: 'Forbidden' });
+
+    const diff = diffEnvironments({ name: 'staging', config: snapshot() }, { name: 'production', config: production });
+
+    expect(diff.identical).toBe(true);
+    expect(diff.categories.map((c) => c.category)).not.toContain('emailTemplates');
+    expect(diff.skipped).toEqual([{ category: 'emailTemplates', environment: 'production', reason: 'Forbidden' }]);
+  });
+
+  it('reports drift between environments', () => {
+    const diff = diffEnvironments(
+      { name: 'staging', config: snapshot({ authentication: { settings: { mfa_required: false } } }) },
+      { name: 'production', config: snapshot({ authentication: { settings: { mfa_required: true } } }) },
+    );
+
+    expect(diff).toMatchObject({ schemaVersion: 1, from: 'staging', to: 'production', identical: false });
+    expect(diff.categories.find((c) => c.category === 'authentication')?.changes).toEqual([
+      { type: 'modified', key: 'settings', fields: [{ field: 'mfa_required', from: false, to: true }] },
+    ]);
+  });
+
+  describe('fetchEnvironmentConfig', () => {
+    it('keys items by what matches across environments and drops IDs', async () => {
+      mockRequest.mockImplementation(async ({ path }) => {
+        if (path === '/user_management/authentication_settings') {
+          return { object: 'authentication_settings', id: 'as_01', mfa_required: true };
+        }
+        if (path === '/authorization/roles') {
+          return { data: [{ id: 'role_01', slug: 'admin', name: 'Admin', description: null, permissions: [] }] };
+        }
+        if (path === '/user_management/cors_origins') throw new WorkOSApiError('Not found', 404);
+        return { data: [], list_metadata: { before: null, after: null } };
+      });
+
+      const config = await fetchEnvironmentConfig({ apiKey: 'sk_test_123' });
+
+      expect(config.items.authentication).toEqual({ settings: { mfa_required: true } });
+      expect(config.items.roles).toEqual({ admin: { name: 'Admin', description: null, permissions: [] } });
+      expect(config.items.corsOrigins).toBeNull();
+      expect(config.unreadable).toEqual([{ category: 'corsOrigins', reason: 'Not found' }]);
+    });
+
+    it('fails on an invalid API key', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Unauthorized', 401));
+
+      await expect(fetchEnvironmentConfig({ apiKey: 'sk_test_bad' })).rejects.toThrow('Unauthorized');
+    });
+  });
+});
@@ -0,0 +1,229 @@
+/**
+ * An environment's configuration as comparable items (`workos env diff`).
+ *
+ * Each category is read into items keyed by what identifies them across
+ * environments (the URI, the role slug, the template type), never by ID,
+ * since IDs differ between environments. Secrets aren't read for webhook
+ * endpoints, and any field that looks secret is elided from a diff: it shows
+ * that the values differ, not what they are.
+ */
+
+import { REDACTED } from '../utils/redact.js';
+import { listAll } from './pagination.js';
+import { listRoles } from './roles.js';
+import { WorkOSApiError, workosRequest } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export const CONFIG_CATEGORIES = [
+  'redirectUris',
+  'corsOrigins',
+  'roles',
+  'authentication',
+  'emailTemplates',
+  'branding',
+  'webhookEndpoints',
+] as const;
+export type ConfigCategory = (typeof CONFIG_CATEGORIES)[number];
+
+export const CATEGORY_LABELS: Record<ConfigCategory, string> = {
+  redirectUris: 'Redirect URIs',
+  corsOrigins: 'CORS origins',
+  roles: 'Roles and permissions',
+  authentication: 'Authentication settings',
+  emailTemplates: 'Email templates',
+  branding: 'Branding',
+  webhookEndpoints: 'Webhook endpoints',
+};
+
+export type ConfigItem = Record<string, unknown>;
+
+export interface EnvironmentConfigSnapshot {
+  /** Items by key, per category; null when the category couldn't be read */
+  items: Record<ConfigCategory, Record<string, ConfigItem> | null>;
+  unreadable: Array<{ category: ConfigCategory; reason: string }>;
+}
+
+/** Fields that identify a resource within one environment only */
+const ENVIRONMENT_FIELDS = new Set(['id', 'object', 'created_at', 'updated_at', 'environment_id']);
+
+const SECRET_FIELD_PATTERN = /secret|token|api_?key|private_?key/i;
+
+function keyBy<T>(list: T[], key: (item: T) => string, item: (item: T) => ConfigItem): Record<string, ConfigItem> {
+  return Object.fromEntries(list.map((entry) => [key(entry), item(entry)]));
+}
+
+function withoutEnvironmentFields(record: Record<string, unknown>): ConfigItem {
+  return Object.fromEntries(Object.entries(record).filter(([field]) => !ENVIRONMENT_FIELDS.has(field)));
+}
+
+const READERS: Record<ConfigCategory, (api: ApiOptions) => Promise<Record<string, ConfigItem>>> = {
+  redirectUris: async (api) =>
+    keyBy(
+      await listAll<{ uri: string; default?: boolean }>({ path: '/user_management/redirect_uris', ...api }),
+      (r) => r.uri,
+      (r) => ({ default: r.default ?? false }),
+    ),
+  corsOrigins: async (api) =>
+    keyBy(
+      await listAll<{ origin: string }>({ path: '/user_management/cors_origins', ...api }),
+      (c) => c.origin,
+      () => ({}),
+    ),
+  roles: async (api) =>
+    keyBy(
+      await listRoles(api),
+      (role) => role.slug,
+      ({ name, description, permissions }) => ({ name, description, permissions }),
+    ),
+  // One item, so each setting shows up as a field of it
+  authentication: async (api) => ({
+    settings: withoutEnvironmentFields(
+      await workosRequest<Record<string, unknown>>({
+        method: 'GET',
+        path: '/user_management/authentication_settings',
+        ...api,
+      }),
+    ),
+  }),
+  emailTemplates: async (api) =>
+    keyBy(
+      await listAll<{ type: string } & Record<string, unknown>>({ path: '/user_management/email_templates', ...api }),
+      (t) => t.type,
+      withoutEnvironmentFields,
+    ),
+  branding: async (api) => ({
+    settings: withoutEnvironmentFields(
+      await workosRequest<Record<string, unknown>>({ method: 'GET', path: '/branding', ...api }),
+    ),
+  }),
+  webhookEndpoints: async (api) =>
+    keyBy(
+      await listAll<{ endpoint_url: string; events: string[]; status: string }>({ path: '/webhook_endpoints', ...api }),
+      (w) => w.endpoint_url,
+      (w) => ({ events: w.events, enabled: w.status !== 'disabled' }),
+    ),
+};
+
+/**
+ * Read every category. One the key can't read is recorded as unreadable, so
+ * the rest can still be compared; an invalid key fails the read.
+ */
+export async function fetchEnvironmentConfig(api: ApiOptions): Promise<EnvironmentConfigSnapshot> {
+  const items = {} as EnvironmentConfigSnapshot['items'];
+  const unreadable: EnvironmentConfigSnapshot['unreadable'] = [];
+  for (const category of CONFIG_CATEGORIES) {
+    try {
+      items[category] = await READERS[category](api);
+    } catch (error) {
+      if (error instanceof WorkOSApiError && error.statusCode === 401) throw error;
+      items[category] = null;
+      unreadable.push({ category, reason: error instanceof Error ? error.message : String(error) });
+    }
+  }
+  return { items, unreadable };
+}
+
+export interface FieldChange {
+  field: string;
+  /** Absent when the field is only set on the other side */
+  from?: unknown;
+  to?: unknown;
+}
+
+export interface ConfigChange {
+  /** added: only in the second environment; removed: only in the first */
+  type: 'added' | 'removed' | 'modified';
+  key: string;
+  /** The item, for additions and removals */
+  value?: ConfigItem;
+  /** The fields that differ, for modifications */
+  fields?: FieldChange[];
+}
+
+export interface EnvironmentDiff {
+  /** Bumped when this shape changes incompatibly */
+  schemaVersion: 1;
+  from: string;
+  to: string;
+  /** No changes in any category that could be compared */
+  identical: boolean;
+  categories: Array<{ category: ConfigCategory; label: string; changes: ConfigChange[] }>;
+  /** Categories left out because one side couldn't be read */
+  skipped: Array<{ category: ConfigCategory; environment: string; reason: string }>;
+}
+
+function normalize(value: unknown): string {
+  return JSON.stringify(Array.isArray(value) ? [...value].sort() : (value ?? null));
+}
+
+/** Whether two field values match; order-insensitive for lists such as permissions and events */
+export function sameValue(a: unknown, b: unknown): boolean {
+  return normalize(a) === normalize(b);
+}
+
+function elide(field: string, value: unknown): unknown {
+  return value !== undefined && SECRET_FIELD_PATTERN.test(field) ? REDACTED : value;
+}
+
+function elideItem(item: ConfigItem): ConfigItem {
+  return Object.fromEntries(Object.entries(item).map(([field, value]) => [field, elide(field, value)]));
+}
+
+function diffFields(from: ConfigItem, to: ConfigItem): FieldChange[] {
+  const fields = [...new Set([...Object.keys(from), ...Object.keys(to)])];
+  return fields
+    .filter((field) => !sameValue(from[field], to[field]))
+    .map((field) => ({
+      field,
+      ...(field in from && { from: elide(field, from[field]) }),
+      ...(field in to && { to: elide(field, to[field]) }),
+    }));
+}
+
+export function diffItems(from: Record<string, ConfigItem>, to: Record<string, ConfigItem>): ConfigChange[] {
+  const keys = [...new Set([...Object.keys(from), ...Object.keys(to)])].sort();
+  const changes: ConfigChange[] = [];
+  for (const key of keys) {
+    if (!(key in to)) {
+      changes.push({ type: 'removed', key, value: elideItem(from[key]) });
+    } else if (!(key in from)) {
+      changes.push({ type: 'added', key, value: elideItem(to[key]) });
+    } else {
+      const fields = diffFields(from[key], to[key]);
+      if (fields.length > 0) changes.push({ type: 'modified', key, fields });
+    }
+  }
+  return changes;
+}
+
+export function diffEnvironments(
+  from: { name: string; config: EnvironmentConfigSnapshot },
+  to: { name: string; config: EnvironmentConfigSnapshot },
+): EnvironmentDiff {
+  const categories: EnvironmentDiff['categories'] = [];
+  const skipped: EnvironmentDiff['skipped'] = [];
+  for (const category of CONFIG_CATEGORIES) {
+    const unreadable = [from, to].flatMap(({ name, config }) =>
+      config.unreadable.filter((u) => u.category === category).map(({ reason }) => ({ environment: name, reason })),
+    );
+    const fromItems = from.config.items[category];
+    const toItems = to.config.items[category];
+    if (unreadable.length > 0 || !fromItems || !toItems) {
+      skipped.push(...unreadable.map((u) => ({ category, ...u })));
+      continue;
+    }
+    categories.push({ category, label: CATEGORY_LABELS[category], changes: diffItems(fromItems, toItems) });
+  }
+  return {
+    schemaVersion: 1,
+    from: from.name,
+    to: to.name,
+    identical: categories.every((c) => c.changes.length === 0),
+    categories,
+    skipped,
+  };
+}
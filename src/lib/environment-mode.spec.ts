@@ -0,0 +1,120 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+
+let testDir: string;
+
+// Mock os.homedir for config-store
+vi.mock('node:os', async (importOriginal) => {
+  const original = await importOriginal<typeof import('node:os')>();
+  return {
+    ...original,
+    default: {
+      ...original,
+      homedir: () => testDir,
+    },
+    homedir: () => testDir,
+  };
+});
+
+const { _setApiCacheDir, setApiCacheEnabled } = await import('./api-cache.js');
+const { setInsecureConfigStorage } = await import('./config-store.js');
+const { setHttpRetries } = await import('./http-retry.js');
+const { confirmDestructive, formatPromptPrefix, resolveEnvironment, setProductionConfirmed } =
+  await import('./environment-mode.js');
+
+describe('environment-mode', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+
+  function mockResponse(status: number, body: unknown): Response {
+    return {
+      ok: status >= 200 && status < 300,
+      status,
+      json: () => Promise.resolve(body),
+      text: () => Promise.resolve(JSON.stringify(body)),
+    } as Response;
+  }
+
+  beforeEach(() => {
+    testDir = mkdtempSync(join(tmpdir(), 'environment-mode-test-'));
+    _setApiCacheDir(join(testDir, 'cache'));
+    setApiCacheEnabled(true);
+    setInsecureConfigStorage(true);
+    setHttpRetries(0);
+    setProductionConfirmed(false);
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+    rmSync(testDir, { recursive: true, force: true });
+  });
+
+  it('takes the mode from the API and caches it', async () => {
+    mockFetch.mockResolvedValue(mockResponse(200, { id: 'environment_1', name: 'Acme Prod', type: 'production' }));
+
+    const api = { apiKey: 'sk_test_looks_like_sandbox' };
+    expect(await resolveEnvironment(api)).toEqual({ id: 'environment_1', name: 'Acme Prod', mode: 'production' });
+    expect(await resolveEnvironment(api)).toEqual({ id: 'environment_1', name: 'Acme Prod', mode: 'production' });
+    expect(mockFetch).toHaveBeenCalledTimes(1);
+  });
+
+  it('falls back to the key prefix when the API cannot say', async () => {
+    mockFetch.mockResolvedValue(mockResponse(404, { message: 'Not found' }));
+
+    expect(await resolveEnvironment({ apiKey: 'sk_live_abc' })).toEqual({
+      id: null,
+      name: 'this environment',
+      mode: 'production',
+    });
+    expect((await resolveEnvironment({ apiKey: 'sk_test_abc' })).mode).toBe('sandbox');
+  });
+
+  it('formats the prompt prefix with the name and mode', () => {
+    expect(formatPromptPrefix({ name: 'Staging', mode: 'sandbox' })).toContain('[Staging · sandbox]');
+  });
+
+  describe('confirmDestructive', () => {
+    let exitSpy: ReturnType<typeof vi.spyOn>;
+    let errorSpy: ReturnType<typeof vi.spyOn>;
+    const isTTY = process.stdin.isTTY;
+
+    beforeEach(() => {
+      process.stdin.isTTY = false;
+      exitSpy = vi.spyOn(process, 'exit').mockImplementation(() => {
+        throw new Error('process.exit');
+      });
+      errorSpy = vi.spyOn(console, 'error').mockImplementation(() => {});
+    });
+
+    afterEach(() => {
+      process.stdin.isTTY = isTTY;
+      exitSpy.mockRestore();
+      errorSpy.mockRestore();
+    });
+
+    it('lets sandboxes through', async () => {
+      mockFetch.mockResolvedValue(mockResponse(200, { id: 'environment_2', name: 'Dev', sandbox: true }));
+
+      await confirmDestructive('Delete organization org_1', { apiKey: 'sk_live_abc' });
+      expect(exitSpy).not.toHaveBeenCalled();
+    });
+
+    it('refuses production without a terminal, unless confirmed by flag', async () => {
+      mockFetch.mockResolvedValue(mockResponse(200, { id: 'environment_1', name: 'Acme Prod', type: 'production' }));
+      const api = { apiKey: 'sk_live_abc' };
+
+      await expect(confirmDestructive('Delete organization org_1', api)).rejects.toThrow('process.exit');
+      expect(exitSpy).toHaveBeenCalledWith(1);
+      expect(String(errorSpy.mock.calls[0][0])).toContain('Delete organization org_1');
+
+      exitSpy.mockClear();
+      setProductionConfirmed(true);
+      await confirmDestructive('Delete organization org_1', api);
+      expect(exitSpy).not.toHaveBeenCalled();
+    });
+  });
+});
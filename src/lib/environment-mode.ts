@@ -0,0 +1,144 @@
+/**
+ * Guardrails for commands that delete or overwrite data.
+ *
+ * The environment's mode (production or sandbox) comes from the API's
+ * environment metadata and is cached, so the check costs one request an
+ * hour. When the API can't say, the key prefix and the profile's type
+ * decide. In production, destructive commands make the user type the
+ * environment's name; scripts pass --i-know-this-is-production instead.
+ * Every interactive prompt is prefixed with the environment and its mode.
+ */
+
+import chalk from 'chalk';
+import { cachedLookup, cacheScope, peekCachedLookup } from './api-cache.js';
+import { getActiveEnvironment, type EnvironmentConfig } from './config-store.js';
+import { noteOffline } from './connectivity.js';
+import { isProductionTarget } from './impersonation.js';
+import { isConnectionError, workosRequest } from './workos-api.js';
+import clack, { getPromptMode, setPromptPrefix } from '../utils/clack.js';
+
+const DEFAULT_BASE_URL = 'https://api.workos.com';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export type EnvironmentMode = 'production' | 'sandbox';
+
+export interface EnvironmentInfo {
+  /** Null when the API couldn't be asked */
+  id: string | null;
+  name: string;
+  mode: EnvironmentMode;
+}
+
+interface EnvironmentMetadata {
+  id: string;
+  name?: string | null;
+  /** Mode as the API reports it; older responses only carry `sandbox` */
+  type?: string | null;
+  sandbox?: boolean | null;
+}
+
+let productionConfirmed = false;
+
+/** `--i-know-this-is-production`: destructive commands run in production without asking */
+export function setProductionConfirmed(value: boolean): void {
+  productionConfirmed = value;
+}
+
+function modeFromMetadata(metadata: EnvironmentMetadata): EnvironmentMode | null {
+  if (metadata.type === 'production' || metadata.type === 'sandbox') return metadata.type;
+  if (typeof metadata.sandbox === 'boolean') return metadata.sandbox ? 'sandbox' : 'production';
+  return null;
+}
+
+function localMode(apiKey: string, activeEnv: EnvironmentConfig | null): EnvironmentMode {
+  return isProductionTarget(apiKey, activeEnv) ? 'production' : 'sandbox';
+}
+
+function fallbackName(activeEnv: EnvironmentConfig | null, apiKey: string): string {
+  return activeEnv?.apiKey === apiKey ? activeEnv.name : 'this environment';
+}
+
+/** e.g. "[Production · production]", red in production and green in a sandbox */
+export function formatPromptPrefix(environment: Pick<EnvironmentInfo, 'name' | 'mode'>): string {
+  const label = `[${environment.name} · ${environment.mode}]`;
+  return environment.mode === 'production' ? chalk.red.bold(label) : chalk.green(label);
+}
+
+/**
+ * The environment the key belongs to and its mode. A key the API rejects,
+ * or an unreachable API, falls back to the key prefix and profile type.
+ */
+export async function resolveEnvironment(api: ApiOptions): Promise<EnvironmentInfo> {
+  const baseUrl = api.baseUrl ?? DEFAULT_BASE_URL;
+  const activeEnv = getActiveEnvironment();
+  let environment: EnvironmentInfo;
+  try {
+    const metadata = await cachedLookup('environment', cacheScope(api.apiKey, baseUrl), async () => {
+      const response = await workosRequest<EnvironmentMetadata>({
+        method: 'GET',
+        path: '/environments/current',
+        ...api,
+      });
+      // Don't cache a response we can't read
+      if (!response?.id) throw new Error('Unexpected environment response');
+      return response;
+    });
+    environment = {
+      id: metadata.id,
+      name: metadata.name || fallbackName(activeEnv, api.apiKey),
+      mode: modeFromMetadata(metadata) ?? localMode(api.apiKey, activeEnv),
+    };
+  } catch (error) {
+    if (isConnectionError(error)) noteOffline('telling production from sandbox by the API key and profile');
+    environment = { id: null, name: fallbackName(activeEnv, api.apiKey), mode: localMode(api.apiKey, activeEnv) };
+  }
+  setPromptPrefix(formatPromptPrefix(environment));
+  return environment;
+}
+
+/**
+ * Prompt prefix for the active profile without a request: the cached API
+ * mode when there is one, else the profile's own type. Null with no profile.
+ */
+export function activePromptPrefix(): string | null {
+  const activeEnv = getActiveEnvironment();
+  if (!activeEnv) return null;
+  const scope = cacheScope(activeEnv.apiKey, activeEnv.endpoint || DEFAULT_BASE_URL);
+  const metadata = peekCachedLookup<EnvironmentMetadata>('environment', scope);
+  return formatPromptPrefix({
+    name: metadata?.name || activeEnv.name,
+    mode: (metadata && modeFromMetadata(metadata)) ?? localMode(activeEnv.apiKey, activeEnv),
+  });
+}
+
+/**
+ * Stop a destructive command in production unless the user types the
+ * environment's name, or passed --i-know-this-is-production. Exits 1 when
+ * it isn't confirmed; sandboxes pass straight through.
+ *
+ * @param action What's about to happen, e.g. "Delete organization org_123"
+ */
+export async function confirmDestructive(action: string, api: ApiOptions): Promise<void> {
+  const environment = await resolveEnvironment(api);
+  if (environment.mode !== 'production' || productionConfirmed) return;
+
+  const target = `production environment ${chalk.bold(environment.name)}`;
+  if (getPromptMode() === 'none') {
+    console.error(chalk.red(`Refusing to run in the ${target}: ${action}.`));
+    console.error(chalk.dim('Pass --i-know-this-is-production to confirm in scripts.'));
+    process.exit(1);
+  }
+
+  const answer = await clack.text({
+    message: `${action} in the ${target}. Type ${chalk.bold(environment.name)} to confirm`,
+    flag: '--i-know-this-is-production',
+  });
+  if (clack.isCancel(answer) || answer.trim() !== environment.name) {
+    console.error(chalk.red('The environment name did not match; nothing was changed.'));
+    process.exit(1);
+  }
+}
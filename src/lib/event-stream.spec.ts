@@ -0,0 +1,38 @@
+import { describe, it, expect } from 'vitest';
+import { createInstallerEventEmitter } from './events.js';
+import { eventRecord, parseEventRecord } from './event-stream.js';
+
+describe('event-stream', () => {
+  it('tags records with the module and leaves out file contents', () => {
+    const record = eventRecord('file:write', { path: 'app/page.tsx', content: 'export default …' }, 'apps/web');
+
+    expect(record).toMatchObject({ event: 'file:write', module: 'apps/web', data: { path: 'app/page.tsx' } });
+    expect(record.data).not.toHaveProperty('content');
+  });
+
+  it('redacts credentials', () => {
+    const record = eventRecord('credentials:response', { apiKey: 'sk_test_a1b2c3d4e5f6', clientId: 'client_01' }, '.');
+
+    expect(JSON.stringify(record)).not.toContain('sk_test_a1b2c3d4e5f6');
+  });
+
+  it('round-trips through a line and ignores other output', () => {
+    const record = eventRecord('agent:start', {}, 'api');
+
+    expect(parseEventRecord(JSON.stringify(record))).toEqual(record);
+    expect(parseEventRecord('◒ Running AI agent...')).toBeNull();
+  });
+
+  it('lets a tap see every event before listeners', () => {
+    const emitter = createInstallerEventEmitter();
+    const seen: string[] = [];
+    const untap = emitter.tap((event) => seen.push(`tap ${event}`));
+    emitter.on('agent:start', () => seen.push('listener'));
+
+    emitter.emit('agent:start', {});
+    untap();
+    emitter.emit('agent:start', {});
+
+    expect(seen).toEqual(['tap agent:start', 'listener', 'listener']);
+  });
+});
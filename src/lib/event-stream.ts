@@ -0,0 +1,84 @@
+/**
+ * `--events ndjson`: installer events as one JSON object per line on stdout,
+ * for scripts and for the multi-module runner. Prompts, spinners and the
+ * summary move to stderr so stdout stays parseable.
+ */
+
+import type { InstallerEventEmitter, InstallerEventName } from './events.js';
+import { OUTPUT_SCHEMA_VERSION } from './output-schema.js';
+import { redactCredentials } from '../utils/redact.js';
+
+export type EventsFormat = 'ndjson';
+
+export interface EventRecord {
+  /** OUTPUT_SCHEMA_VERSION; `workos schema events` describes the record */
+  schemaVersion: number;
+  event: InstallerEventName;
+  /** Which module (project directory) the event is about */
+  module: string;
+  time: string;
+  data: unknown;
+}
+
+/** Payload fields left out of the stream: whole file contents */
+const OMITTED_FIELDS = new Set(['content', 'oldContent', 'newContent']);
+
+let writeLine: ((line: string) => void) | null = null;
+
+/**
+ * Keep stdout for event lines: anything else printed from here on, clack
+ * output included, goes to stderr. Call before the first log line.
+ */
+export function reserveStdoutForEvents(): void {
+  if (writeLine) return;
+  const stdoutWrite = process.stdout.write.bind(process.stdout);
+  writeLine = (line) => {
+    stdoutWrite(`${line}\n`);
+  };
+  process.stdout.write = process.stderr.write.bind(process.stderr) as typeof process.stdout.write;
+}
+
+function serializable(payload: unknown): unknown {
+  if (payload instanceof Error) return { message: payload.message };
+  if (typeof payload !== 'object' || payload === null || Array.isArray(payload)) return payload;
+  return Object.fromEntries(
+    Object.entries(payload)
+      .filter(([key]) => !OMITTED_FIELDS.has(key))
+      .map(([key, value]) => [key, value instanceof Error ? { message: value.message } : value]),
+  );
+}
+
+export function eventRecord(event: InstallerEventName, payload: unknown, module: string): EventRecord {
+  return {
+    schemaVersion: OUTPUT_SCHEMA_VERSION,
+    event,
+    module,
+    time: new Date().toISOString(),
+    data: redactCredentials(serializable(payload)),
+  };
+}
+
+/** Write one line to the real stdout, reserving it first */
+export function writeStdoutLine(line: string): void {
+  reserveStdoutForEvents();
+  writeLine!(line);
+}
+
+export function writeEventRecord(record: EventRecord): void {
+  writeStdoutLine(JSON.stringify(record));
+}
+
+/** Stream every event the emitter sees, tagged with the module */
+export function streamEvents(emitter: InstallerEventEmitter, module: string): () => void {
+  return emitter.tap((event, payload) => writeEventRecord(eventRecord(event, payload, module)));
+}
+
+/** Parse one line of a child's stream; null for anything that isn't an event record */
+export function parseEventRecord(line: string): EventRecord | null {
+  try {
+    const record = JSON.parse(line);
+    return record && typeof record.event === 'string' ? record : null;
+  } catch {
+    return null;
+  }
+}
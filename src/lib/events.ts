@@ -5,13 +5,26 @@ export interface InstallerEvents {
   output: { text: string; isError?: boolean };
   'file:write': { path: string; content: string };
   'file:edit': { path: string; oldContent: string; newContent: string };
+  /** Whether an agent Write/Edit landed; `reason` says why one failed or was skipped */
+  'file:result': { path: string; status: import('../migrate/types.js').MigrationStep['status']; reason?: string };
+  /** Unified diff of a proposed change (--show-diffs / --dry-run) */
+  'change:diff': { path: string; diff: string };
+  /** End-of-run list of changes a dry run would have made */
+  'change:summary': { text: string };
+  /** --diff-only patch written in place of the changes */
+  'change:patch': import('./diff-only.js').PatchResult;
+  /** --keep-backups copies of edited files (`<file>.workos.bak`), relative to the project */
+  'backups:kept': { files: string[] };
   'prompt:request': { id: string; message: string; options?: string[] };
   'prompt:response': { id: string; value: string };
   'confirm:request': { id: string; message: string; warning?: string; files?: string[] };
   'confirm:response': { id: string; confirmed: boolean };
   'credentials:request': { requiresApiKey: boolean };
   'credentials:response': { apiKey: string; clientId: string };
-  complete: { success: boolean; summary?: string };
+  /** `cancelled` is set when the user cancelled (Ctrl-C or a cancelled prompt), not on failures */
+  complete: { success: boolean; summary?: string; changedFiles?: string[]; cancelled?: boolean };
+  /** After a cancelled run has stopped and its checkpoint was written */
+  cancelled: { changedFiles: string[]; checkpointPath: string | null; reason?: import('./interrupt.js').StopReason };
   error: { message: string; stack?: string };
 
   'state:enter': { state: string };
@@ -48,11 +61,36 @@ export interface InstallerEvents {
   'staging:error': { message: string; statusCode?: number };
   'config:start': Record<string, never>;
   'config:complete': Record<string, never>;
+  /** Old provider env keys renamed in place during a migration */
+  'env:renamed': { keys: import('../migrate/types.js').RenamedEnvKey[]; provider: string };
+  /** Per-file outcome of a migration in which something failed, and where it was saved */
+  'migration:steps': import('./migration-steps.js').StepBreakdown & { statePath?: string };
+  /** The files a partly applied migration changed, put back on request */
+  'migration:rollback': import('./migration-steps.js').RollbackResult;
+  /** Redirect URI, CORS origin and homepage set in the WorkOS dashboard */
+  'dashboard:configured': { changes: import('./workos-management.js').DashboardChange[] };
   'agent:start': Record<string, never>;
   'agent:progress': { step: string; detail?: string };
   'agent:success': { summary?: string };
   'agent:failure': { message: string; stack?: string };
   'agent:retry': { attempt: number; maxRetries: number };
+  /** Pre-flight estimate before the first agent run; `warning` when it's over --max-tokens */
+  'agent:estimate': { estimate: import('./agent-estimate.js').AgentEstimate; maxTokens?: number; warning?: string };
+  /** Token usage and cost of one agent invocation (main run or repair) */
+  'agent:usage': import('./agent-usage.js').AgentRunUsage;
+  /** The agent was stopped for going idle or running too long; the run stops like a cancel */
+  'agent:timeout': { kind: import('./agent-process.js').AgentTimeoutKind; message: string };
+
+  // Project hooks from .workos/config.json
+  'hook:start': { phase: import('./project-hooks.js').HookPhase; command: string };
+  'hook:output': { phase: import('./project-hooks.js').HookPhase; text: string };
+  'hook:complete': {
+    phase: import('./project-hooks.js').HookPhase;
+    command: string;
+    exitCode: number;
+    required: boolean;
+    durationMs: number;
+  };
 
   'validation:retry:start': { attempt: number };
   'validation:retry:complete': { attempt: number; passed: boolean };
@@ -61,11 +99,23 @@ export interface InstallerEvents {
   'validation:issues': { issues: import('./validation/types.js').ValidationIssue[] };
   'validation:complete': { passed: boolean; issueCount: number; durationMs: number };
 
+  // Project formatters applied to the agent's files (prettier, gofmt)
+  'checks:formatted': import('./validation/project-checks.js').FormatResult;
+
+  // Post-install verification declared by the skill (verify.json)
+  'verification:start': { checks: number; attempt: number };
+  'verification:check': import('./validation/verification.js').VerificationCheckResult;
+  'verification:complete': { passed: boolean; failed: string[]; attempt: number; durationMs: number };
+  'verification:repair': { attempt: number; maxAttempts: number; failed: string[] };
+  /** Markdown report of the run, relative to the project when it's inside it */
+  'report:written': { path: string };
+
   // Branch check events
   'branch:checking': Record<string, never>;
   'branch:protected': { branch: string };
-  'branch:prompt': { branch: string };
-  'branch:created': { branch: string };
+  /** `reason` says why the branch counts as protected */
+  'branch:prompt': { branch: string; reason?: string; newBranch: string; newBranchExists: boolean };
+  'branch:created': { branch: string; reused?: boolean };
   'branch:create:failed': { error: string };
   'branch:skipped': Record<string, never>;
 
@@ -73,6 +123,8 @@ export interface InstallerEvents {
   'postinstall:changes': { files: string[] };
   'postinstall:nochanges': Record<string, never>;
   'postinstall:commit:prompt': Record<string, never>;
+  /** The commit was skipped because it would land on a protected branch without --allow-main */
+  'postinstall:commit:blocked': { branch: string; reason: string };
   'postinstall:commit:generating': Record<string, never>;
   'postinstall:commit:committing': { message: string };
   'postinstall:commit:success': { message: string };
@@ -81,19 +133,34 @@ export interface InstallerEvents {
   'postinstall:pr:generating': Record<string, never>;
   'postinstall:pr:pushing': Record<string, never>;
   'postinstall:pr:creating': Record<string, never>;
-  'postinstall:pr:success': { url: string };
+  'postinstall:pr:success': { url: string; updated?: boolean };
   'postinstall:pr:failed': { error: string };
+  'postinstall:pr:skipped': { reason: string };
+  'postinstall:push:success': { branch: string };
   'postinstall:push:failed': { error: string };
   'postinstall:manual': { instructions: string };
 }
 
 export type InstallerEventName = keyof InstallerEvents;
 
+export type EventTap = (event: InstallerEventName, payload: unknown) => void;
+
 export class InstallerEventEmitter extends EventEmitter {
+  private taps: EventTap[] = [];
+
   emit<K extends InstallerEventName>(event: K, payload: InstallerEvents[K]): boolean {
+    for (const tap of this.taps) tap(event, payload);
     return super.emit(event, payload);
   }
 
+  /** See every event before its listeners do, e.g. to stream them as NDJSON */
+  tap(listener: EventTap): () => void {
+    this.taps.push(listener);
+    return () => {
+      this.taps = this.taps.filter((t) => t !== listener);
+    };
+  }
+
   on<K extends InstallerEventName>(event: K, listener: (payload: InstallerEvents[K]) => void): this {
     return super.on(event, listener);
   }
@@ -0,0 +1,87 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import {
+  _setInstallsDir,
+  buildUpdatePrompt,
+  detectExistingIntegration,
+  readInstallRecord,
+  updateModeSection,
+  writeInstallRecord,
+} from './existing-integration.js';
+
+describe('existing-integration', () => {
+  let dir: string;
+  let installsDir: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(dir, relPath)), { recursive: true });
+    writeFileSync(join(dir, relPath), content);
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'existing-integration-test-'));
+    installsDir = mkdtempSync(join(tmpdir(), 'existing-integration-installs-'));
+    _setInstallsDir(installsDir);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    rmSync(installsDir, { recursive: true, force: true });
+  });
+
+  it('finds nothing in a fresh project', () => {
+    write('package.json', JSON.stringify({ dependencies: { next: '15.0.0' } }));
+
+    expect(detectExistingIntegration(dir)).toBeUndefined();
+  });
+
+  it('does not count WORKOS_ env keys on their own', () => {
+    write('.env.local', 'WORKOS_API_KEY=sk_test_123\nWORKOS_CLIENT_ID=client_123\n');
+
+    expect(detectExistingIntegration(dir)).toBeUndefined();
+  });
+
+  it('reports the SDK, callback route and env keys of an earlier install', () => {
+    write('package.json', JSON.stringify({ dependencies: { next: '15.0.0', '@workos-inc/authkit-nextjs': '^2.0.0' } }));
+    write('app/callback/route.ts', 'export const GET = handleAuth();\n');
+    write('.env.local', 'WORKOS_CLIENT_ID=client_123\n');
+
+    expect(detectExistingIntegration(dir)?.markers).toEqual([
+      '@workos-inc/authkit-nextjs in package.json',
+      'AuthKit callback handling in app/callback/route.ts',
+      'WORKOS_CLIENT_ID in .env.local',
+    ]);
+  });
+
+  it('finds backend SDKs in their manifests', () => {
+    write('requirements.txt', 'django\nworkos>=5\n');
+    write('go.mod', 'module app\n\nrequire github.com/workos/workos-go/v4 v4.0.0\n');
+
+    expect(detectExistingIntegration(dir)?.markers).toEqual(['workos in requirements.txt', 'workos-go in go.mod']);
+  });
+
+  it('ignores callbacks in dependencies and tests', () => {
+    write('node_modules/@workos-inc/node/index.js', 'authenticateWithCode()');
+    write('src/auth.test.ts', 'authenticateWithCode()');
+
+    expect(detectExistingIntegration(dir)).toBeUndefined();
+  });
+
+  it('remembers a previous install outside the project', () => {
+    writeInstallRecord({ installDir: dir, integration: 'nextjs' });
+
+    expect(readInstallRecord(dir)).toMatchObject({ installDir: dir, integration: 'nextjs' });
+    expect(detectExistingIntegration(dir)?.markers[0]).toMatch(/^previous install of nextjs on \d{4}-\d{2}-\d{2}/);
+  });
+
+  it('builds the update-mode prompt section', () => {
+    const existing = { markers: ['@workos-inc/authkit-nextjs in package.json'] };
+
+    expect(buildUpdatePrompt(existing)).toContain('- @workos-inc/authkit-nextjs in package.json');
+    expect(buildUpdatePrompt(existing)).toContain('Never create another callback route');
+    expect(updateModeSection(existing)).toMatch(/^\n\n## Existing Integration \(update mode\)/);
+    expect(updateModeSection(undefined)).toBe('');
+  });
+});
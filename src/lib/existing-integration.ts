@@ -0,0 +1,204 @@
+/**
+ * Detect an AuthKit integration left by an earlier install, so a rerun
+ * reconciles it instead of scaffolding a second one (duplicate middleware,
+ * a second callback route).
+ *
+ * Evidence, strongest first: the record a successful install leaves under
+ * ~/.workos/installs, a WorkOS SDK in the project's dependencies, and source
+ * files that already handle the AuthKit callback. WORKOS_* env keys are
+ * reported alongside but don't count on their own, since people add those
+ * before a first install too.
+ */
+
+import { createHash } from 'node:crypto';
+import { existsSync, mkdirSync, readFileSync, statSync, writeFileSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
+import fg from 'fast-glob';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { IGNORE_PATTERNS } from './constants.js';
+import { readGemfileGems, readPythonDependencies } from './backend-detection.js';
+import { getVersion } from './settings.js';
+
+export interface InstallRecord {
+  installDir: string;
+  integration?: string;
+  cliVersion: string;
+  installedAt: string;
+}
+
+export interface ExistingIntegration {
+  /** What gave the integration away, e.g. `@workos-inc/authkit-nextjs in package.json` */
+  markers: string[];
+}
+
+/** SDKs outside package.json: manifest file and how it names the WorkOS package */
+const MANIFEST_SDKS: { file: string; pattern: RegExp; name: string }[] = [
+  { file: 'go.mod', pattern: /github\.com\/workos\/workos-go/, name: 'workos-go' },
+  { file: 'composer.json', pattern: /"workos\/workos-php(-laravel)?"/, name: 'workos-php' },
+  { file: 'mix.exs', pattern: /\{:workos,/, name: 'workos (Hex)' },
+  { file: 'build.gradle', pattern: /com\.workos:workos/, name: 'workos-kotlin' },
+  { file: 'build.gradle.kts', pattern: /com\.workos:workos/, name: 'workos-kotlin' },
+];
+
+/** Calls that only show up in code that already completes the AuthKit flow */
+const CALLBACK_PATTERNS = [
+  /\bhandleAuth\(/,
+  /\bauthkitMiddleware\(/,
+  /\bhandleCallbackRoute\(/,
+  /\bauthenticateWithCode\(/,
+  /\bauthenticate_with_code\(/,
+  /\bAuthenticateWithCode\(/,
+];
+
+const SOURCE_GLOBS = ['**/*.{ts,tsx,js,jsx,mjs,py,rb,go,php,ex,kt,cs}'];
+
+/** Skip generated bundles and vendored code when scanning for callbacks */
+const MAX_SOURCE_BYTES = 256 * 1024;
+
+/** Callback files to name before summarizing the rest */
+const MAX_CALLBACK_MARKERS = 3;
+
+let installsDir = join(homedir(), '.workos', 'installs');
+
+/** @internal For testing only */
+export function _setInstallsDir(dir: string): void {
+  installsDir = dir;
+}
+
+/** Per-project install record, outside the repo like checkpoints */
+export function installRecordPath(installDir: string): string {
+  const key = createHash('sha256').update(installDir).digest('hex').slice(0, 16);
+  return join(installsDir, `${key}.json`);
+}
+
+/** Note a successful install, so the next run in this project updates it */
+export function writeInstallRecord(record: Pick<InstallRecord, 'installDir' | 'integration'>): void {
+  const path = installRecordPath(record.installDir);
+  try {
+    mkdirSync(dirname(path), { recursive: true });
+    const full: InstallRecord = { ...record, cliVersion: getVersion(), installedAt: new Date().toISOString() };
+    writeFileSync(path, JSON.stringify(full, null, 2));
+  } catch {
+    // Best effort: without the record, detection falls back to the project itself
+  }
+}
+
+export function readInstallRecord(installDir: string): InstallRecord | null {
+  try {
+    return JSON.parse(readFileSync(installRecordPath(installDir), 'utf-8')) as InstallRecord;
+  } catch {
+    return null;
+  }
+}
+
+function read(path: string): string | null {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return null;
+  }
+}
+
+function sdkMarkers(installDir: string): string[] {
+  const markers: string[] = [];
+
+  const packageJson = read(join(installDir, 'package.json'));
+  if (packageJson) {
+    try {
+      const pkg = JSON.parse(packageJson);
+      const deps = { ...pkg.dependencies, ...pkg.devDependencies };
+      for (const name of Object.keys(deps).filter((n) => n.startsWith('@workos-inc/'))) {
+        markers.push(`${name} in package.json`);
+      }
+    } catch {
+      // Invalid package.json
+    }
+  }
+
+  const python = readPythonDependencies(installDir);
+  if (python?.dependencies.includes('workos')) markers.push(`workos in ${python.manifestFile}`);
+  if (readGemfileGems(installDir)?.includes('workos')) markers.push('workos in Gemfile');
+
+  for (const { file, pattern, name } of MANIFEST_SDKS) {
+    if (pattern.test(read(join(installDir, file)) ?? '')) markers.push(`${name} in ${file}`);
+  }
+  return markers;
+}
+
+function callbackMarkers(installDir: string): string[] {
+  const files = fg.sync(SOURCE_GLOBS, {
+    cwd: installDir,
+    ignore: [...IGNORE_PATTERNS, '**/vendor/**', '**/.venv/**', '**/venv/**', '**/*.spec.*', '**/*.test.*'],
+    deep: 6,
+  });
+
+  const matches = files.sort().filter((file) => {
+    const path = join(installDir, file);
+    if (statSync(path).size > MAX_SOURCE_BYTES) return false;
+    const source = read(path) ?? '';
+    return CALLBACK_PATTERNS.some((pattern) => pattern.test(source));
+  });
+
+  const markers = matches.slice(0, MAX_CALLBACK_MARKERS).map((file) => `AuthKit callback handling in ${file}`);
+  if (matches.length > MAX_CALLBACK_MARKERS) {
+    markers.push(`...and ${matches.length - MAX_CALLBACK_MARKERS} more files`);
+  }
+  return markers;
+}
+
+function envMarkers(installDir: string): string[] {
+  return ['.env.local', '.env'].flatMap((file) => {
+    const path = join(installDir, file);
+    if (!existsSync(path)) return [];
+    const keys = Object.keys(parseEnvFile(read(path) ?? '')).filter((key) => /^(NEXT_PUBLIC_)?WORKOS_/.test(key));
+    return keys.length > 0 ? [`${keys.join(', ')} in ${file}`] : [];
+  });
+}
+
+function previousInstallMarker(record: InstallRecord): string {
+  const integration = record.integration ? ` of ${record.integration}` : '';
+  return `previous install${integration} on ${record.installedAt.slice(0, 10)} (CLI ${record.cliVersion})`;
+}
+
+/**
+ * Look for an earlier AuthKit install in the project.
+ * @returns The evidence found, or undefined when nothing beyond env keys points to one
+ */
+export function detectExistingIntegration(installDir: string): ExistingIntegration | undefined {
+  const record = readInstallRecord(installDir);
+  const decisive = [
+    ...(record ? [previousInstallMarker(record)] : []),
+    ...sdkMarkers(installDir),
+    ...callbackMarkers(installDir),
+  ];
+  if (decisive.length === 0) return undefined;
+
+  return { markers: [...decisive, ...envMarkers(installDir)] };
+}
+
+/**
+ * Prompt section that turns a fresh install into an update/repair.
+ */
+export function buildUpdatePrompt(existing: ExistingIntegration): string {
+  return [
+    '## Existing Integration (update mode)',
+    '',
+    'WorkOS AuthKit is already integrated in this project:',
+    ...existing.markers.map((marker) => `- ${marker}`),
+    '',
+    'Reconcile the existing integration with the skill instead of adding a second one:',
+    '- Find the existing callback route, middleware/proxy, provider and auth UI before writing anything.',
+    '- Update those files in place. Never create another callback route, middleware or provider alongside them.',
+    '- Upgrade the SDK only if the skill requires a newer version; keep the package manager in use.',
+    '- Fix anything missing or broken (env keys, route matching WORKOS_REDIRECT_URI, session handling).',
+    '- Leave customizations that still work as they are, and say what you changed and why.',
+    '',
+    'If everything already matches the skill, make no changes and report that the integration is up to date.',
+  ].join('\n');
+}
+
+/** The update-mode section to append to an integration prompt, or nothing for a fresh install */
+export function updateModeSection(existing: ExistingIntegration | undefined): string {
+  return existing ? `\n\n${buildUpdatePrompt(existing)}` : '';
+}
@@ -0,0 +1,107 @@
+import { describe, it, expect } from 'vitest';
+import { parseResource, parseWarrantFile, validateFgaSchema } from './fga.js';
+
+const SCHEMA = `version 0.3
+
+type user
+
+type team
+    relation member [user]
+
+// Documents belong to users or teams
+type document
+    relation owner [user, team#member]
+    relation viewer [user]
+
+    inherit viewer if
+        any_of
+            relation owner
+            relation member on owner [team]
+`;
+
+describe('fga', () => {
+  describe('validateFgaSchema', () => {
+    it('accepts a valid schema and summarizes its types', () => {
+      const { errors, summary } = validateFgaSchema(SCHEMA);
+
+      expect(errors).toEqual([]);
+      expect(summary).toEqual({
+        version: '0.3',
+        types: { user: [], team: ['member'], document: ['owner', 'viewer'] },
+      });
+    });
+
+    it('reports undefined types and relations with line numbers', () => {
+      const schema = [
+        'version 0.3',
+        'type document',
+        '    relation viewer [usr]',
+        '    inherit editor if',
+        '        relation owner',
+      ].join('\n');
+
+      expect(validateFgaSchema(schema).errors).toEqual([
+        { line: 3, message: 'Unknown type "usr"' },
+        { line: 4, message: 'Relation "editor" is not defined on document' },
+        { line: 5, message: 'Relation "owner" is not defined on document' },
+      ]);
+    });
+
+    it('reports syntax errors', () => {
+      const schema = ['type user', '    relation', 'type doc', '    inherit viewer if', 'typo'].join('\n');
+      const lines = validateFgaSchema(schema).errors.map((e) => e.line);
+
+      expect(lines).toEqual([1, 2, 4, 4, 5]);
+    });
+  });
+
+  describe('parseResource', () => {
+    it('parses type:id with an optional relation', () => {
+      expect(parseResource('user:123')).toEqual({ resource_type: 'user', resource_id: '123' });
+      expect(parseResource('team:eng#member')).toEqual({
+        resource_type: 'team',
+        resource_id: 'eng',
+        relation: 'member',
+      });
+      expect(() => parseResource('user')).toThrow('Expected <type>:<id>');
+    });
+  });
+
+  describe('parseWarrantFile', () => {
+    it('reads warrant tuples, skipping comments', () => {
+      const file = '# viewers\ndocument:456#viewer@user:123\n\ndocument:456#owner@team:eng#member\n';
+      const warrants = parseWarrantFile(file);
+
+      expect(warrants).toEqual([
+        {
+          resource_type: 'document',
+          resource_id: '456',
+          relation: 'viewer',
+          subject: { resource_type: 'user', resource_id: '123' },
+        },
+        {
+          resource_type: 'document',
+          resource_id: '456',
+          relation: 'owner',
+          subject: { resource_type: 'team', resource_id: 'eng', relation: 'member' },
+        },
+      ]);
+    });
+
+    it('reads a JSON array and validates each entry', () => {
+      const warrant = {
+        resource_type: 'document',
+        resource_id: '1',
+        relation: 'viewer',
+        subject: { resource_type: 'user', resource_id: '2' },
+      };
+
+      expect(parseWarrantFile(JSON.stringify([warrant]))).toEqual([warrant]);
+      expect(() => parseWarrantFile('[{"resource_type":"document"}]')).toThrow('Warrant 1 is missing');
+    });
+
+    it('rejects malformed lines with their line number', () => {
+      expect(() => parseWarrantFile('document:1#viewer@user:2\ndocument:1 viewer user:2')).toThrow(/^Line 2/);
+    });
+  });
+});
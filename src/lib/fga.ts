@@ -0,0 +1,330 @@
+/**
+ * Fine-Grained Authorization: schema validation and the FGA API.
+ *
+ * The schema is validated locally before anything is sent, so `fga schema
+ * push` reports mistakes with line numbers instead of a single API error.
+ * The validator covers the structure of the schema language (types,
+ * relations, inheritance rules) and cross-references between them; the API
+ * remains the final authority.
+ */
+
+import { workosRequest, type WorkOSListResponse } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface SchemaError {
+  line: number;
+  message: string;
+}
+
+export interface SchemaSummary {
+  version: string | null;
+  /** Relations declared per resource type */
+  types: Record<string, string[]>;
+}
+
+export interface SchemaValidation {
+  errors: SchemaError[];
+  summary: SchemaSummary;
+}
+
+const NAME = '[a-z][a-z0-9_-]*';
+const NAME_PATTERN = new RegExp(`^${NAME}$`);
+const SUBJECT_REF_PATTERN = new RegExp(`^(${NAME})(?:#(${NAME}))?$`);
+const RULE_PATTERN = new RegExp(`^relation\\s+(${NAME})(?:\\s+on\\s+(${NAME})\\s+\\[\\s*(${NAME})\\s*\\])?$`);
+
+interface Reference {
+  line: number;
+  check: (types: Map<string, Set<string>>) => string | null;
+}
+
+function indentOf(line: string): number {
+  return line.length - line.trimStart().length;
+}
+
+/**
+ * Validate FGA schema text. Errors carry 1-based line numbers.
+ */
+export function validateFgaSchema(text: string): SchemaValidation {
+  const errors: SchemaError[] = [];
+  const types = new Map<string, Set<string>>();
+  const references: Reference[] = [];
+  let version: string | null = null;
+  let currentType: string | null = null;
+  /** Indentation of the open `inherit ... if` line, while reading its rules */
+  let inheritIndent: number | null = null;
+  let inheritHasRules = true;
+  let inheritLine = 0;
+  let inheritOwner = '';
+
+  const closeInherit = () => {
+    if (inheritIndent !== null && !inheritHasRules) {
+      errors.push({ line: inheritLine, message: '`inherit ... if` has no rules' });
+    }
+    inheritIndent = null;
+  };
+
+  text.split('\n').forEach((raw, index) => {
+    const line = index + 1;
+    const content = raw.replace(/\/\/.*$/, '').trimEnd();
+    const statement = content.trim();
+    if (!statement) return;
+
+    if (inheritIndent !== null && indentOf(content) > inheritIndent) {
+      inheritHasRules = true;
+      if (/^(any_of|all_of|none_of)$/.test(statement)) return;
+      const rule = RULE_PATTERN.exec(statement);
+      if (!rule) {
+        errors.push({ line, message: `Expected a rule (\`relation <name>\`, \`any_of\`, ...), got "${statement}"` });
+        return;
+      }
+      const [, relation, via, viaType] = rule;
+      const owner = inheritOwner;
+      references.push({
+        line,
+        check: (all) => {
+          if (!via) return all.get(owner)?.has(relation) ? null : `Relation "${relation}" is not defined on ${owner}`;
+          if (!all.get(owner)?.has(via)) return `Relation "${via}" is not defined on ${owner}`;
+          if (!all.has(viaType)) return `Unknown type "${viaType}"`;
+          return all.get(viaType)!.has(relation) ? null : `Relation "${relation}" is not defined on ${viaType}`;
+        },
+      });
+      return;
+    }
+    closeInherit();
+
+    const [keyword, ...rest] = statement.split(/\s+/);
+
+    if (version === null && keyword !== 'version') {
+      errors.push({ line, message: 'Schema must start with `version <number>`' });
+      version = '';
+    }
+
+    switch (keyword) {
+      case 'version':
+        if (version !== null) {
+          errors.push({ line, message: '`version` must appear once, before any type' });
+        } else if (rest.length !== 1) {
+          errors.push({ line, message: 'Expected `version <number>`' });
+        }
+        version ??= rest[0] ?? '';
+        return;
+
+      case 'type': {
+        const name = rest[0];
+        if (rest.length !== 1 || !NAME_PATTERN.test(name)) {
+          errors.push({ line, message: 'Expected `type <name>` (lowercase letters, digits, - and _)' });
+          currentType = null;
+          return;
+        }
+        if (types.has(name)) errors.push({ line, message: `Type "${name}" is already defined` });
+        types.set(name, types.get(name) ?? new Set());
+        currentType = name;
+        return;
+      }
+
+      case 'relation': {
+        if (!currentType) {
+          errors.push({ line, message: '`relation` must be inside a type' });
+          return;
+        }
+        const match = new RegExp(`^relation\\s+(${NAME})(?:\\s+\\[([^\\]]*)\\])?$`).exec(statement);
+        if (!match) {
+          errors.push({ line, message: 'Expected `relation <name> [<type>, <type>#<relation>, ...]`' });
+          return;
+        }
+        const [, relation, refs] = match;
+        const relations = types.get(currentType)!;
+        if (relations.has(relation)) errors.push({ line, message: `Relation "${relation}" is already defined` });
+        relations.add(relation);
+
+        for (const ref of (refs ?? '').split(',').map((r) => r.trim()).filter(Boolean)) {
+          const parsed = SUBJECT_REF_PATTERN.exec(ref);
+          if (!parsed) {
+            errors.push({ line, message: `Invalid subject type "${ref}"` });
+            continue;
+          }
+          const [, refType, refRelation] = parsed;
+          references.push({
+            line,
+            check: (all) => {
+              if (!all.has(refType)) return `Unknown type "${refType}"`;
+              if (refRelation && !all.get(refType)!.has(refRelation)) {
+                return `Relation "${refRelation}" is not defined on ${refType}`;
+              }
+              return null;
+            },
+          });
+        }
+        return;
+      }
+
+      case 'inherit': {
+        const match = new RegExp(`^inherit\\s+(${NAME})\\s+if$`).exec(statement);
+        if (!currentType || !match) {
+          errors.push({
+            line,
+            message: currentType ? 'Expected `inherit <relation> if`' : '`inherit` must be inside a type',
+          });
+          return;
+        }
+        const relation = match[1];
+        const owner = currentType;
+        references.push({
+          line,
+          check: (all) => (all.get(owner)?.has(relation) ? null : `Relation "${relation}" is not defined on ${owner}`),
+        });
+        inheritIndent = indentOf(content);
+        inheritHasRules = false;
+        inheritLine = line;
+        inheritOwner = owner;
+        return;
+      }
+
+      default:
+        errors.push({ line, message: `Unexpected "${keyword}"` });
+    }
+  });
+  closeInherit();
+
+  for (const reference of references) {
+    const message = reference.check(types);
+    if (message) errors.push({ line: reference.line, message });
+  }
+  errors.sort((a, b) => a.line - b.line);
+
+  return {
+    errors,
+    summary: {
+      version: version || null,
+      types: Object.fromEntries([...types].map(([name, relations]) => [name, [...relations]])),
+    },
+  };
+}
+
+export function formatSchemaErrors(file: string, errors: SchemaError[]): string {
+  return errors.map((e) => `${file}:${e.line}: ${e.message}`).join('\n');
+}
+
+export async function getFgaSchema(api: ApiOptions): Promise<string> {
+  const result = await workosRequest<{ schema: string } | null>({ method: 'GET', path: '/fga/v1/schema', ...api });
+  return result?.schema ?? '';
+}
+
+export async function putFgaSchema(schema: string, api: ApiOptions): Promise<void> {
+  await workosRequest({ method: 'PUT', path: '/fga/v1/schema', body: { schema }, ...api });
+}
+
+export interface FgaSubject {
+  resource_type: string;
+  resource_id: string;
+  relation?: string;
+}
+
+export interface FgaWarrant {
+  resource_type: string;
+  resource_id: string;
+  relation: string;
+  subject: FgaSubject;
+}
+
+/**
+ * Parse `type:id` (or `type:id#relation` for subjects).
+ */
+export function parseResource(ref: string): FgaSubject {
+  const match = /^([^:#\s]+):([^#\s]+)(?:#([^#\s]+))?$/.exec(ref);
+  if (!match) throw new Error(`Expected <type>:<id>, got "${ref}"`);
+  const [, resource_type, resource_id, relation] = match;
+  return relation ? { resource_type, resource_id, relation } : { resource_type, resource_id };
+}
+
+export function formatResource(ref: FgaSubject): string {
+  return `${ref.resource_type}:${ref.resource_id}${ref.relation ? `#${ref.relation}` : ''}`;
+}
+
+/**
+ * Parse a warrant file: a JSON array of warrants, or one
+ * `<resource>#<relation>@<subject>` tuple per line (# comments allowed).
+ */
+export function parseWarrantFile(text: string): FgaWarrant[] {
+  if (text.trimStart().startsWith('[')) {
+    const parsed = JSON.parse(text) as FgaWarrant[];
+    parsed.forEach((w, i) => {
+      if (!w?.resource_type || !w.resource_id || !w.relation || !w.subject?.resource_type || !w.subject.resource_id) {
+        throw new Error(`Warrant ${i + 1} is missing resource_type, resource_id, relation or subject`);
+      }
+    });
+    return parsed;
+  }
+
+  return text.split('\n').flatMap((raw, index) => {
+    const line = raw.replace(/(^|\s)#\s.*$/, '').trim();
+    if (!line) return [];
+    const match = /^(\S+?)#([^@\s]+)@(\S+)$/.exec(line);
+    if (!match) throw new Error(`Line ${index + 1}: expected <type>:<id>#<relation>@<type>:<id>, got "${line}"`);
+    const resource = parseResource(match[1]);
+    return [{ ...resource, relation: match[2], subject: parseResource(match[3]) }];
+  });
+}
+
+export type WarrantOp = 'create' | 'delete';
+
+export async function writeWarrants(op: WarrantOp, warrants: FgaWarrant[], api: ApiOptions): Promise<void> {
+  await workosRequest({
+    method: 'POST',
+    path: '/fga/v1/warrants',
+    body: warrants.map((w) => ({ op, ...w })),
+    ...api,
+  });
+}
+
+export interface CheckResult {
+  authorized: boolean;
+  is_implicit: boolean;
+}
+
+export async function checkFga(
+  subject: FgaSubject,
+  relation: string,
+  resource: FgaSubject,
+  api: ApiOptions,
+): Promise<CheckResult> {
+  const result = await workosRequest<{ result: 'authorized' | 'not_authorized'; is_implicit: boolean }>({
+    method: 'POST',
+    path: '/fga/v1/check',
+    body: { checks: [{ ...resource, relation, subject }] },
+    ...api,
+  });
+  return { authorized: result.result === 'authorized', is_implicit: result.is_implicit };
+}
+
+export interface QueryResult {
+  resource_type: string;
+  resource_id: string;
+  relation: string;
+  warrant: FgaWarrant;
+  is_implicit: boolean;
+}
+
+/**
+ * Run an FGA query (e.g. `select document where user:123 is viewer`),
+ * following pagination up to `limit` results.
+ */
+export async function queryFga(query: string, api: ApiOptions, limit = 1000): Promise<QueryResult[]> {
+  const results: QueryResult[] = [];
+  let after: string | undefined;
+  do {
+    const page = await workosRequest<WorkOSListResponse<QueryResult>>({
+      method: 'GET',
+      path: '/fga/v1/query',
+      params: { q: query, limit: Math.min(100, limit - results.length), after },
+      ...api,
+    });
+    results.push(...page.data);
+    after = page.list_metadata.after ?? undefined;
+  } while (after && results.length < limit);
+  return results;
+}
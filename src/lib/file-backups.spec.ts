@@ -0,0 +1,70 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { existsSync, mkdirSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { applyFileEdits } from './atomic-write.js';
+import { FileBackups, findBackups, removeBackups, startFileBackups, stopFileBackups } from './file-backups.js';
+
+describe('file-backups', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'file-backups-'));
+    execFileSync('git', ['init', '-q'], { cwd: dir });
+  });
+
+  afterEach(() => {
+    stopFileBackups();
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('copies a file once, before its first change', () => {
+    writeFileSync(join(dir, 'auth.ts'), 'old');
+    const backups = new FileBackups(dir);
+
+    backups.backup('auth.ts');
+    writeFileSync(join(dir, 'auth.ts'), 'new');
+    backups.backup('auth.ts');
+    backups.backup('created.ts');
+
+    expect(readFileSync(join(dir, 'auth.ts.workos.bak'), 'utf-8')).toBe('old');
+    expect(backups.files()).toEqual(['auth.ts.workos.bak']);
+    expect(readFileSync(join(dir, '.git/info/exclude'), 'utf-8')).toContain('*.workos.bak');
+  });
+
+  it("keeps an earlier run's backup", () => {
+    writeFileSync(join(dir, 'auth.ts'), 'second');
+    writeFileSync(join(dir, 'auth.ts.workos.bak'), 'first');
+
+    new FileBackups(dir).backup(join(dir, 'auth.ts'));
+
+    expect(readFileSync(join(dir, 'auth.ts.workos.bak'), 'utf-8')).toBe('first');
+  });
+
+  it("backs up files the installer's own edits change", () => {
+    writeFileSync(join(dir, '.env.local'), 'A=1\n');
+    const backups = startFileBackups(dir);
+
+    applyFileEdits([
+      { path: join(dir, '.env.local'), content: 'A=2\n' },
+      { path: join(dir, '.env.example'), content: 'A=\n' },
+    ]);
+
+    expect(backups.files()).toEqual(['.env.local.workos.bak']);
+    expect(readFileSync(join(dir, '.env.local.workos.bak'), 'utf-8')).toBe('A=1\n');
+  });
+
+  it('finds and removes backups outside node_modules', () => {
+    mkdirSync(join(dir, 'src'));
+    mkdirSync(join(dir, 'node_modules'));
+    writeFileSync(join(dir, 'src/auth.ts.workos.bak'), '');
+    writeFileSync(join(dir, 'node_modules/x.workos.bak'), '');
+
+    const found = findBackups(dir);
+    removeBackups(dir, found);
+
+    expect(found).toEqual(['src/auth.ts.workos.bak']);
+    expect(existsSync(join(dir, 'src/auth.ts.workos.bak'))).toBe(false);
+  });
+});
@@ -0,0 +1,106 @@
+/**
+ * `--keep-backups`: the version of each file before the run, as
+ * `<file>.workos.bak` next to it, for side-by-side review of a migration.
+ *
+ * A file is copied before its first change, by the agent or by the
+ * installer's own edits. A backup left by an earlier run is kept, since it
+ * holds the version from before that run. Backups are kept out of git with
+ * a managed block in the clone's `info/exclude`: a `.gitignore` only covers
+ * its own directory, and the team's `.gitignore` shouldn't change for files
+ * that exist in one checkout. Scans skip them, so a backup of the old auth
+ * code is never detected as a provider. `workos clean` removes them.
+ */
+
+import { execFileSync } from 'node:child_process';
+import { copyFileSync, existsSync, mkdirSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { dirname, isAbsolute, join, resolve } from 'node:path';
+import fg from 'fast-glob';
+import { relativePosix } from '../utils/paths.js';
+import { backUpFileEdits } from './atomic-write.js';
+
+export const BACKUP_SUFFIX = '.workos.bak';
+
+/** Glob for the backups, for walks that must leave them out */
+export const BACKUP_GLOB = `**/*${BACKUP_SUFFIX}`;
+
+const EXCLUDE_START = '# >>> workos --keep-backups';
+const EXCLUDE_END = '# <<< workos --keep-backups';
+
+export function isBackupFile(path: string): boolean {
+  return path.endsWith(BACKUP_SUFFIX);
+}
+
+/** The clone's `info/exclude`, or null outside a git repo */
+function excludeFile(cwd: string): string | null {
+  try {
+    const path = execFileSync('git', ['rev-parse', '--git-path', 'info/exclude'], {
+      cwd,
+      stdio: ['ignore', 'pipe', 'ignore'],
+    })
+      .toString()
+      .trim();
+    return isAbsolute(path) ? path : join(cwd, path);
+  } catch {
+    return null;
+  }
+}
+
+/** Add the managed block that ignores backups to git's info/exclude, once */
+export function ignoreBackups(cwd: string): void {
+  const path = excludeFile(cwd);
+  if (!path) return;
+  const current = existsSync(path) ? readFileSync(path, 'utf-8') : '';
+  if (current.includes(EXCLUDE_START)) return;
+  const block = [EXCLUDE_START, `*${BACKUP_SUFFIX}`, EXCLUDE_END].join('\n');
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, `${current}${current && !current.endsWith('\n') ? '\n' : ''}${block}\n`);
+}
+
+/**
+ * Copies each file to `<file>.workos.bak` before the run first changes it.
+ */
+export class FileBackups {
+  private seen = new Set<string>();
+  private written: string[] = [];
+
+  constructor(private root: string) {}
+
+  /** Back up a file that's about to change; files that don't exist yet have nothing to keep */
+  backup(path: string): void {
+    const absolute = resolve(this.root, path);
+    if (this.seen.has(absolute) || isBackupFile(absolute)) return;
+    this.seen.add(absolute);
+    if (!existsSync(absolute) || existsSync(absolute + BACKUP_SUFFIX)) return;
+    if (this.written.length === 0) ignoreBackups(this.root);
+    copyFileSync(absolute, absolute + BACKUP_SUFFIX);
+    this.written.push(relativePosix(this.root, absolute + BACKUP_SUFFIX));
+  }
+
+  /** Backups this run wrote, relative to the project */
+  files(): string[] {
+    return [...this.written];
+  }
+}
+
+/** Start backing up every file the installer's own edits change, until stopFileBackups */
+export function startFileBackups(root: string): FileBackups {
+  const backups = new FileBackups(root);
+  backUpFileEdits((path) => backups.backup(path));
+  return backups;
+}
+
+export function stopFileBackups(): void {
+  backUpFileEdits(null);
+}
+
+/** Backups in the project, relative to it */
+export function findBackups(root: string): string[] {
+  return fg
+    .sync(BACKUP_GLOB, { cwd: root, dot: true, onlyFiles: true, ignore: ['**/node_modules/**', '**/.git/**'] })
+    .sort();
+}
+
+/** Delete the given backups (relative to root) */
+export function removeBackups(root: string, files: string[]): void {
+  for (const file of files) rmSync(join(root, file), { force: true });
+}
@@ -60,6 +60,34 @@ export interface FrameworkMetadata {
 
   /** Primary manifest file (e.g., 'pyproject.toml', 'Gemfile'). Optional for JS integrations. */
   manifestFile?: string;
+
+  /** Starter app `workos init` scaffolds with this integration's skill, if it has one */
+  starter?: StarterTemplate;
+}
+
+/**
+ * Starter app for `workos init`: a WorkOS example repo, or a starter bundled
+ * in `templates/` when there's no example repo.
+ */
+export interface StarterTemplate {
+  /** Template name for `workos init --template` */
+  name: string;
+  /** Short framework name for `workos init --framework` */
+  framework: string;
+  description: string;
+  /** Git URL of the template repository */
+  repo?: string;
+  /** Directory under `templates/` in this package, for starters without an example repo */
+  bundled?: string;
+  /** Defaults to the integration's port in the CLI settings */
+  port?: number;
+  /** Defaults to the integration's callback path in the CLI settings */
+  callbackPath?: string;
+  /** Env file the app reads */
+  envFile: string;
+  redirectUriEnvVar: string;
+  /** Commands to run the app after scaffolding */
+  runCommands: string[];
 }
 
 /**
@@ -77,6 +105,12 @@ export interface FrameworkDetection {
 
   /** Optional: Convert version to analytics bucket (e.g., "15.x") */
   getVersionBucket?: (version: string) => string;
+
+  /**
+   * Optional: Whether the project uses this framework, for non-JS integrations
+   * that need more than the manifest file to exist (e.g. Django in requirements.txt)
+   */
+  detect?: (installDir: string) => boolean;
 }
 
 /**
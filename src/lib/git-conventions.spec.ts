@@ -0,0 +1,87 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import {
+  invalidRefReason,
+  loadGitConventions,
+  nextFreeBranchName,
+  renderTemplate,
+  templateVariables,
+} from './git-conventions.js';
+
+describe('git-conventions', () => {
+  let dir: string;
+
+  function writeConfig(config: unknown, root = dir) {
+    mkdirSync(join(root, '.workos'), { recursive: true });
+    writeFileSync(join(root, '.workos', 'config.json'), JSON.stringify(config));
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'git-conventions-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('loadGitConventions', () => {
+    it('reads the templates, from the workspace root when the project has none', () => {
+      writeConfig({ git: { branchTemplate: '{user}/auth-{skill}', commitTemplate: 'AUTH-1: {summary}' } });
+      const pkg = join(dir, 'apps', 'web');
+      mkdirSync(pkg, { recursive: true });
+
+      expect(loadGitConventions(pkg, { workspaceRoot: dir })).toEqual({
+        source: join(dir, '.workos', 'config.json'),
+        branchTemplate: '{user}/auth-{skill}',
+        commitTemplate: 'AUTH-1: {summary}',
+      });
+      expect(loadGitConventions(pkg)).toBeUndefined();
+    });
+
+    it('rejects branch templates git would refuse and unknown variables', () => {
+      writeConfig({ git: { branchTemplate: 'feat/add auth' } });
+      expect(() => loadGitConventions(dir)).toThrow('"git.branchTemplate" in');
+      expect(() => loadGitConventions(dir)).toThrow('it contains " "');
+
+      writeConfig({ git: { branchTemplate: 'feat/{ticket}' } });
+      expect(() => loadGitConventions(dir)).toThrow('unknown variable {ticket}');
+
+      writeConfig({ git: { branchTemplate: 'feat/{summary}' } });
+      expect(() => loadGitConventions(dir)).toThrow('unknown variable {summary}');
+
+      writeConfig({ git: { commitTemplate: 42 } });
+      expect(() => loadGitConventions(dir)).toThrow('must be a string');
+    });
+  });
+
+  it('follows git check-ref-format', () => {
+    expect(invalidRefReason('jane/auth-workos-authkit-nextjs-2024-05-01')).toBeNull();
+    expect(invalidRefReason('feat..auth')).toBe('it contains ".."');
+    expect(invalidRefReason('feat/auth.lock')).toBe('"auth.lock" ends with ".lock"');
+    expect(invalidRefReason('feat/.auth')).toBe('".auth" starts with "."');
+    expect(invalidRefReason('feat/')).toBe('it has an empty path component');
+    expect(invalidRefReason('feat~1')).toBe('it contains "~"');
+    expect(invalidRefReason('a@{b')).toBe('it contains "@{"');
+  });
+
+  it('renders variables as slugs', () => {
+    const variables = templateVariables('Workos AuthKit/Next', { now: new Date('2024-05-01T12:00:00Z') });
+
+    expect(variables.skill).toBe('workos-authkit-next');
+    expect(variables.date).toBe('2024-05-01');
+    expect(invalidRefReason(variables.user)).toBeNull();
+    expect(renderTemplate('{skill}-{date}: {summary}', { ...variables, summary: 'feat: add auth' })).toBe(
+      'workos-authkit-next-2024-05-01: feat: add auth',
+    );
+    expect(templateVariables(undefined).skill).toBe('workos-authkit');
+  });
+
+  it('appends the first free numeric suffix', () => {
+    const taken = new Set(['feat/auth', 'feat/auth-2']);
+
+    expect(nextFreeBranchName('feat/auth', (name) => taken.has(name))).toBe('feat/auth-3');
+    expect(nextFreeBranchName('feat/other', (name) => taken.has(name))).toBe('feat/other');
+  });
+});
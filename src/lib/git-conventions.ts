@@ -0,0 +1,193 @@
+/**
+ * Branch and commit naming from `.workos/config.json`, for repos whose
+ * conventions aren't `feat/add-workos-authkit`.
+ *
+ * ```json
+ * {
+ *   "git": {
+ *     "branchTemplate": "{user}/auth-{skill}-{date}",
+ *     "commitTemplate": "AUTH-123: {summary}"
+ *   }
+ * }
+ * ```
+ *
+ * Templates can use `{skill}` (the integration's skill, e.g.
+ * `workos-authkit-nextjs`), `{date}` (YYYY-MM-DD) and `{user}` (git's
+ * user.name as a slug); commit templates can also use `{summary}`, the
+ * generated commit message. Both are checked before the run starts, so a
+ * branch name git would reject fails up front rather than after the agent.
+ */
+
+import { execFileSync } from 'node:child_process';
+import { readFileSync } from 'node:fs';
+import { userInfo } from 'node:os';
+import { join } from 'node:path';
+
+export const GIT_CONFIG_FILE = join('.workos', 'config.json');
+
+export const DEFAULT_BRANCH_TEMPLATE = 'feat/add-workos-authkit';
+
+const BRANCH_VARIABLES = ['skill', 'date', 'user'];
+const COMMIT_VARIABLES = [...BRANCH_VARIABLES, 'summary'];
+
+const VARIABLE_PATTERN = /\{(\w+)\}/g;
+
+export interface GitConventions {
+  /** Config file the templates came from */
+  source: string;
+  branchTemplate?: string;
+  commitTemplate?: string;
+}
+
+export interface TemplateVariables {
+  skill: string;
+  date: string;
+  user: string;
+  summary?: string;
+}
+
+function checkVariables(template: string, allowed: string[], where: string): void {
+  const unknown = [...template.matchAll(VARIABLE_PATTERN)].map((m) => m[1]).find((name) => !allowed.includes(name));
+  if (unknown) {
+    throw new Error(`${where} uses unknown variable {${unknown}}; use ${allowed.map((v) => `{${v}}`).join(', ')}`);
+  }
+}
+
+/**
+ * Why git would refuse `name` as a branch, following `git check-ref-format`,
+ * or null when it's fine.
+ */
+export function invalidRefReason(name: string): string | null {
+  if (!name) return 'it is empty';
+  // eslint-disable-next-line no-control-regex
+  const bad = name.match(/[\s~^:?*[\\\x00-\x1f\x7f]/);
+  if (bad) return `it contains ${JSON.stringify(bad[0])}`;
+  if (name.includes('..')) return 'it contains ".."';
+  if (name.includes('@{')) return 'it contains "@{"';
+  if (name === '@') return 'it is "@"';
+  if (name.startsWith('/') || name.endsWith('/') || name.includes('//')) return 'it has an empty path component';
+  if (name.endsWith('.')) return 'it ends with "."';
+  if (name.startsWith('-')) return 'it starts with "-"';
+  for (const part of name.split('/')) {
+    if (part.startsWith('.')) return `"${part}" starts with "."`;
+    if (part.endsWith('.lock')) return `"${part}" ends with ".lock"`;
+  }
+  return null;
+}
+
+/** Fill in a template's variables; unknown ones are left as written */
+export function renderTemplate(template: string, variables: TemplateVariables): string {
+  return template.replace(VARIABLE_PATTERN, (whole, name: string) => {
+    const value = (variables as unknown as Record<string, string | undefined>)[name];
+    return value ?? whole;
+  });
+}
+
+/** Lowercase, with anything but letters, digits, `.` and `_` turned into single dashes */
+function slug(value: string): string {
+  return value
+    .toLowerCase()
+    .replace(/[^a-z0-9._]+/g, '-')
+    .replace(/^[-.]+|[-.]+$/g, '');
+}
+
+/**
+ * Check a branch template: its variables must exist, and a name rendered
+ * from it must be a valid ref. Variables are filled with sample values, as
+ * they only ever render to slugs and dates.
+ */
+function checkBranchTemplate(template: string, where: string): void {
+  checkVariables(template, BRANCH_VARIABLES, where);
+  const reason = invalidRefReason(renderTemplate(template, { skill: 'skill', date: '2024-01-01', user: 'user' }));
+  if (reason) throw new Error(`${where} is not a valid git branch name: ${reason}`);
+}
+
+function checkCommitTemplate(template: string, where: string): void {
+  checkVariables(template, COMMIT_VARIABLES, where);
+  if (!template.trim()) throw new Error(`${where} must not be empty`);
+}
+
+function readConventions(path: string): GitConventions | undefined {
+  let config: unknown;
+  try {
+    config = JSON.parse(readFileSync(path, 'utf-8'));
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code === 'ENOENT') throw error;
+    throw new Error(`${path} is not valid JSON`);
+  }
+  const git = (config as { git?: unknown } | null)?.git;
+  if (git === undefined) return undefined;
+  if (!git || typeof git !== 'object') throw new Error(`"git" in ${path} must be an object`);
+
+  const { branchTemplate, commitTemplate } = git as { branchTemplate?: unknown; commitTemplate?: unknown };
+  for (const [key, value] of [
+    ['branchTemplate', branchTemplate],
+    ['commitTemplate', commitTemplate],
+  ] as const) {
+    if (value !== undefined && typeof value !== 'string') throw new Error(`"git.${key}" in ${path} must be a string`);
+  }
+  if (branchTemplate !== undefined) checkBranchTemplate(branchTemplate as string, `"git.branchTemplate" in ${path}`);
+  if (commitTemplate !== undefined) checkCommitTemplate(commitTemplate as string, `"git.commitTemplate" in ${path}`);
+  if (branchTemplate === undefined && commitTemplate === undefined) return undefined;
+  return {
+    source: path,
+    ...(branchTemplate !== undefined && { branchTemplate: branchTemplate as string }),
+    ...(commitTemplate !== undefined && { commitTemplate: commitTemplate as string }),
+  };
+}
+
+/**
+ * Find the project's git conventions: `.workos/config.json` in the project,
+ * then at the workspace root. Throws on templates that can't be used.
+ */
+export function loadGitConventions(
+  installDir: string,
+  options: { workspaceRoot?: string } = {},
+): GitConventions | undefined {
+  for (const dir of [installDir, options.workspaceRoot].filter((d): d is string => Boolean(d))) {
+    try {
+      return readConventions(join(dir, GIT_CONFIG_FILE));
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'ENOENT') throw error;
+    }
+  }
+  return undefined;
+}
+
+function gitUserName(cwd?: string): string | null {
+  try {
+    return execFileSync('git', ['config', 'user.name'], { cwd, stdio: ['ignore', 'pipe', 'ignore'] })
+      .toString()
+      .trim();
+  } catch {
+    return null;
+  }
+}
+
+/** The variables for this run; `skill` falls back to `workos-authkit` before an integration is known */
+export function templateVariables(
+  skill: string | undefined,
+  options: { cwd?: string; now?: Date } = {},
+): TemplateVariables {
+  let user = slug(gitUserName(options.cwd) ?? '');
+  if (!user) {
+    try {
+      user = slug(userInfo().username);
+    } catch {
+      // No passwd entry, e.g. in some containers
+    }
+  }
+  return {
+    skill: slug(skill ?? '') || 'workos-authkit',
+    date: (options.now ?? new Date()).toISOString().slice(0, 10),
+    user: user || 'user',
+  };
+}
+
+/** The first of `name`, `name-2`, `name-3`, ... that `exists` says is free */
+export function nextFreeBranchName(name: string, exists: (name: string) => boolean): string {
+  if (!exists(name)) return name;
+  let n = 2;
+  while (exists(`${name}-${n}`)) n++;
+  return `${name}-${n}`;
+}
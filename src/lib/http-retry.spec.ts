@@ -0,0 +1,186 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { createServer, type IncomingMessage, type Server, type ServerResponse } from 'node:http';
+import type { AddressInfo } from 'node:net';
+import {
+  backoffDelay,
+  fetchWithRetry,
+  getHttpRetries,
+  HttpRetryError,
+  isIdempotentRequest,
+  isRetryableError,
+  isRetryableStatus,
+} from './http-retry.js';
+
+describe('http-retry', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+
+  beforeEach(() => {
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    vi.spyOn(Math, 'random').mockReturnValue(0);
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+    vi.restoreAllMocks();
+  });
+
+  it('classifies statuses and errors', () => {
+    expect([429, 500, 502, 503, 599].every(isRetryableStatus)).toBe(true);
+    expect([400, 401, 404, 409].some(isRetryableStatus)).toBe(false);
+
+    expect(isRetryableError(new DOMException('timed out', 'TimeoutError'))).toBe(true);
+    expect(isRetryableError(new TypeError('fetch failed', { cause: { code: 'ECONNRESET' } }))).toBe(true);
+    expect(isRetryableError(new TypeError('fetch failed', { cause: { code: 'ENOTFOUND' } }))).toBe(false);
+    expect(isRetryableError(new TypeError('Invalid URL'))).toBe(false);
+  });
+
+  it('caps the exponential backoff', () => {
+    vi.spyOn(Math, 'random').mockReturnValue(0.999999);
+
+    expect(Math.round(backoffDelay(1, 500, 8000))).toBe(500);
+    expect(Math.round(backoffDelay(3, 500, 8000))).toBe(2000);
+    expect(Math.round(backoffDelay(10, 500, 8000))).toBe(8000);
+  });
+
+  it('returns the last retryable response once attempts run out', async () => {
+    mockFetch.mockResolvedValue({ ok: false, status: 502 });
+
+    const { response, attempts } = await fetchWithRetry('https://example.com', {}, { retries: 2 });
+
+    expect(response.status).toBe(502);
+    expect(attempts).toBe(3);
+    expect(mockFetch).toHaveBeenCalledTimes(3);
+  });
+
+  it('throws with the attempt count when no response arrives', async () => {
+    mockFetch.mockRejectedValue(new TypeError('fetch failed'));
+
+    const error = await fetchWithRetry('https://example.com', {}, { retries: 1 }).catch((e: unknown) => e);
+
+    expect(error).toBeInstanceOf(HttpRetryError);
+    expect((error as HttpRetryError).attempts).toBe(2);
+  });
+
+  it('does not retry non-transient errors', async () => {
+    mockFetch.mockRejectedValue(new TypeError('Invalid URL'));
+
+    await expect(fetchWithRetry('nope', {}, { retries: 3 })).rejects.toThrow('Invalid URL');
+    expect(mockFetch).toHaveBeenCalledTimes(1);
+  });
+
+  it('treats POSTs as idempotent only with a key', () => {
+    expect(isIdempotentRequest({})).toBe(true);
+    expect(isIdempotentRequest({ method: 'delete' })).toBe(true);
+    expect(isIdempotentRequest({ method: 'POST' })).toBe(false);
+    expect(isIdempotentRequest({ method: 'POST', headers: { 'Idempotency-Key': 'k' } })).toBe(true);
+  });
+
+  it('does not retry a POST without an idempotency key', async () => {
+    mockFetch.mockResolvedValue({ ok: false, status: 503 });
+
+    const { attempts } = await fetchWithRetry('https://example.com', { method: 'POST' }, { retries: 3 });
+
+    expect(attempts).toBe(1);
+  });
+
+  it('retries a POST whose connection was refused', async () => {
+    mockFetch
+      .mockRejectedValueOnce(new TypeError('fetch failed', { cause: { code: 'ECONNREFUSED' } }))
+      .mockResolvedValueOnce({ ok: true, status: 201 });
+
+    const { attempts } = await fetchWithRetry('https://example.com', { method: 'POST' }, { retries: 1 });
+
+    expect(attempts).toBe(2);
+  });
+
+  it('reads the retry count from WORKOS_CLI_MAX_RETRIES', () => {
+    vi.stubEnv('WORKOS_CLI_MAX_RETRIES', '7');
+    expect(getHttpRetries()).toBe(7);
+    vi.unstubAllEnvs();
+  });
+
+  describe('against a server', () => {
+    let server: Server;
+    let url: string;
+    let handler: (req: IncomingMessage, res: ServerResponse) => void;
+
+    beforeEach(async () => {
+      globalThis.fetch = originalFetch;
+      server = createServer((req, res) => handler(req, res));
+      await new Promise<void>((resolve) => server.listen(0, '127.0.0.1', resolve));
+      url = `http://127.0.0.1:${(server.address() as AddressInfo).port}/`;
+    });
+
+    afterEach(async () => {
+      await new Promise((resolve) => server.close(resolve));
+    });
+
+    it('waits for Retry-After before retrying a 429', async () => {
+      const seen: number[] = [];
+      handler = (_req, res) => {
+        seen.push(Date.now());
+        if (seen.length === 1) {
+          res.writeHead(429, { 'Retry-After': '1' }).end();
+        } else {
+          res.writeHead(200).end('ok');
+        }
+      };
+
+      const { response, attempts } = await fetchWithRetry(url, {}, { retries: 2, baseDelayMs: 1 });
+
+      expect(response.status).toBe(200);
+      expect(attempts).toBe(2);
+      expect(seen[1] - seen[0]).toBeGreaterThanOrEqual(950);
+    });
+
+    it('holds back other requests while a 429 waits', async () => {
+      const seen: Array<[string, number]> = [];
+      handler = (req, res) => {
+        seen.push([req.url!, Date.now()]);
+        if (seen.length === 1) res.writeHead(429, { 'Retry-After': '1' }).end();
+        else res.writeHead(200).end('ok');
+      };
+
+      const limited = fetchWithRetry(`${url}a`, {}, { retries: 2, baseDelayMs: 1 });
+      await new Promise((resolve) => setTimeout(resolve, 100));
+      await fetchWithRetry(`${url}b`, {}, { retries: 0 });
+      await limited;
+
+      const other = seen.find(([path]) => path === '/b')!;
+      expect(other[1] - seen[0][1]).toBeGreaterThanOrEqual(950);
+    });
+
+    it('stops when Retry-After exceeds the time budget', async () => {
+      let hits = 0;
+      handler = (_req, res) => {
+        hits++;
+        res.writeHead(429, { 'Retry-After': '120' }).end();
+      };
+
+      const { response, attempts } = await fetchWithRetry(url, {}, { retries: 5, maxRetryTimeMs: 1_000 });
+
+      expect(response.status).toBe(429);
+      expect(attempts).toBe(1);
+      expect(hits).toBe(1);
+    });
+
+    it('retries a keyed POST through 5xx responses', async () => {
+      const keys: Array<string | undefined> = [];
+      handler = (req, res) => {
+        keys.push(req.headers['idempotency-key'] as string | undefined);
+        res.writeHead(keys.length < 3 ? 502 : 201).end();
+      };
+
+      const { response } = await fetchWithRetry(
+        url,
+        { method: 'POST', headers: { 'Idempotency-Key': 'abc' }, body: '{}' },
+        { retries: 3, baseDelayMs: 1 },
+      );
+
+      expect(response.status).toBe(201);
+      expect(keys).toEqual(['abc', 'abc', 'abc']);
+    });
+  });
+});
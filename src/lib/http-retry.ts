@@ -0,0 +1,248 @@
+/**
+ * Retry transient HTTP failures with exponential backoff and jitter.
+ *
+ * 429 and 5xx responses, timeouts and connection errors are retried;
+ * anything else (including other 4xx) is returned or thrown on the first
+ * attempt. Retry-After is honored, and retries stop once the total time
+ * budget is spent. Only idempotent requests are retried after they may
+ * have reached the server: POSTs need an Idempotency-Key header, otherwise
+ * they are only retried when the connection was never made.
+ *
+ * The retry count defaults to config `http.retries` and can be overridden
+ * with WORKOS_CLI_MAX_RETRIES or the global --max-retries flag. The
+ * per-attempt timeout is config `http.timeoutMs`, raised for commands in
+ * `http.commandTimeoutsMs` that make slow calls (exports), and overridden
+ * by WORKOS_CLI_HTTP_TIMEOUT or --http-timeout. Requests go through the
+ * configured proxy, if any (see proxy.ts).
+ *
+ * A retried 429 holds back every request the process sends until its wait
+ * is over, not just the one that got it: the rate limit is per API key, so
+ * the parallel workers of a bulk command back off together.
+ *
+ * Requests go through cassetteFetch, so WORKOS_CLI_RECORD and
+ * WORKOS_CLI_REPLAY apply to them (see cassette.ts); replayed retries
+ * don't wait.
+ */
+
+import { cassetteFetch, CassetteMismatchError, isReplaying } from './cassette.js';
+import { describeProxyError, proxyFetchOptions, resolveProxy } from './proxy.js';
+import { getConfig } from './settings.js';
+import { logWarn } from '../utils/debug.js';
+
+let retriesOverride: number | undefined;
+let timeoutOverride: number | undefined;
+/** The running command, e.g. `export terraform`, for its timeout override */
+let command = '';
+/** No request is sent before this time, after a 429 */
+let throttledUntil = 0;
+
+/** Set from the global --max-retries flag */
+export function setHttpRetries(retries: number): void {
+  retriesOverride = Math.max(0, Math.floor(retries));
+}
+
+export function getHttpRetries(): number {
+  if (retriesOverride !== undefined) return retriesOverride;
+  const fromEnv = Number.parseInt(process.env.WORKOS_CLI_MAX_RETRIES ?? '', 10);
+  return Number.isNaN(fromEnv) ? getConfig().http.retries : Math.max(0, fromEnv);
+}
+
+/** Set from the global --http-timeout flag */
+export function setHttpTimeout(ms: number): void {
+  timeoutOverride = Math.max(1, Math.floor(ms));
+}
+
+/** Set by bin.ts before the command runs */
+export function setHttpCommand(name: string): void {
+  command = name;
+}
+
+/**
+ * Per-attempt timeout for the running command. WORKOS_CLI_HTTP_TIMEOUT is
+ * in seconds.
+ */
+export function getHttpTimeout(): number {
+  if (timeoutOverride !== undefined) return timeoutOverride;
+  const fromEnv = Number.parseFloat(process.env.WORKOS_CLI_HTTP_TIMEOUT ?? '');
+  if (fromEnv > 0) return Math.round(fromEnv * 1000);
+  const { timeoutMs, commandTimeoutsMs } = getConfig().http;
+  // The longest matching prefix wins, so `export` covers `export terraform`
+  const match = Object.keys(commandTimeoutsMs)
+    .filter((prefix) => command === prefix || command.startsWith(`${prefix} `))
+    .sort((a, b) => b.length - a.length)[0];
+  return match ? commandTimeoutsMs[match] : timeoutMs;
+}
+
+export interface RetryOptions {
+  /** Retries after the first attempt */
+  retries?: number;
+  baseDelayMs?: number;
+  maxDelayMs?: number;
+  /** Per-attempt timeout */
+  timeoutMs?: number;
+  /** No retry is started once this much time has passed since the first attempt */
+  maxRetryTimeMs?: number;
+}
+
+export interface RetryResult {
+  response: Response;
+  attempts: number;
+}
+
+/**
+ * Thrown when every attempt failed without a response.
+ */
+export class HttpRetryError extends Error {
+  constructor(
+    message: string,
+    public readonly attempts: number,
+    cause: unknown,
+  ) {
+    super(message, { cause });
+    this.name = 'HttpRetryError';
+  }
+}
+
+const RETRYABLE_ERROR_CODES = new Set([
+  'ECONNRESET',
+  'ECONNREFUSED',
+  'ETIMEDOUT',
+  'EPIPE',
+  'EAI_AGAIN',
+  'UND_ERR_SOCKET',
+  'UND_ERR_CONNECT_TIMEOUT',
+  'UND_ERR_HEADERS_TIMEOUT',
+]);
+
+/** Failures where the request never reached the server, so even a POST is safe to resend */
+const NOT_SENT_ERROR_CODES = new Set(['ECONNREFUSED', 'EAI_AGAIN', 'UND_ERR_CONNECT_TIMEOUT']);
+
+const IDEMPOTENT_METHODS = new Set(['GET', 'HEAD', 'OPTIONS', 'PUT', 'DELETE']);
+
+export function isRetryableStatus(status: number): boolean {
+  return status === 429 || (status >= 500 && status <= 599);
+}
+
+/** Safe to send twice: an idempotent method, or a POST carrying an idempotency key */
+export function isIdempotentRequest(init: RequestInit): boolean {
+  if (IDEMPOTENT_METHODS.has((init.method ?? 'GET').toUpperCase())) return true;
+  return new Headers(init.headers).has('Idempotency-Key');
+}
+
+export function errorCode(error: Error): string | undefined {
+  return (error as { code?: string }).code ?? (error.cause as { code?: string } | undefined)?.code;
+}
+
+/**
+ * fetch() rejects with a TypeError wrapping the socket error; timeouts
+ * surface as AbortError/TimeoutError.
+ */
+export function isRetryableError(error: unknown): boolean {
+  if (!(error instanceof Error)) return false;
+  // Missing proxy credentials won't fix themselves
+  if (/Proxy response \(407\)/.test((error.cause as Error | undefined)?.message ?? error.message)) return false;
+  if (error.name === 'AbortError' || error.name === 'TimeoutError') return true;
+  const code = errorCode(error);
+  if (code) return RETRYABLE_ERROR_CODES.has(code);
+  // undici reports connection failures as a bare "fetch failed"
+  return error instanceof TypeError && error.message === 'fetch failed';
+}
+
+/** How long requests are still held back after the last 429 */
+export function throttleDelay(now = Date.now()): number {
+  return Math.max(0, throttledUntil - now);
+}
+
+/** Full jitter: a random delay up to the exponential backoff for this attempt */
+export function backoffDelay(attempt: number, baseDelayMs: number, maxDelayMs: number): number {
+  return Math.random() * Math.min(maxDelayMs, baseDelayMs * 2 ** (attempt - 1));
+}
+
+/**
+ * Retry-After is either a number of seconds or an HTTP date.
+ */
+export function parseRetryAfter(value: string | null | undefined, now = Date.now()): number | undefined {
+  if (!value) return undefined;
+  if (/^\d+$/.test(value.trim())) return Number(value.trim());
+  const date = Date.parse(value);
+  return Number.isNaN(date) ? undefined : Math.max(0, Math.ceil((date - now) / 1000));
+}
+
+function describeError(error: unknown, url: string): string {
+  if (!(error instanceof Error)) return String(error);
+  // Checked first: undici reports proxy tunnel failures as AbortError
+  const proxyReason = describeProxyError(error, resolveProxy(url));
+  if (proxyReason) return proxyReason;
+  const code = errorCode(error);
+  if (error.name === 'TimeoutError' || error.name === 'AbortError') return 'timed out';
+  return code ?? error.message;
+}
+
+/**
+ * fetch() with retries. After the last attempt a retryable response is
+ * returned as-is (callers already handle error statuses); if no response
+ * was ever received an HttpRetryError is thrown.
+ */
+export async function fetchWithRetry(
+  url: string,
+  init: RequestInit = {},
+  options: RetryOptions = {},
+): Promise<RetryResult> {
+  const {
+    retries = getHttpRetries(),
+    baseDelayMs = 500,
+    maxDelayMs = 8_000,
+    timeoutMs = getHttpTimeout(),
+    // Long enough to retry at least once after an attempt that timed out
+    maxRetryTimeMs = Math.max(60_000, timeoutMs * 2),
+  } = options;
+  const maxAttempts = retries + 1;
+  const idempotent = isIdempotentRequest(init);
+  const deadline = Date.now() + maxRetryTimeMs;
+
+  for (let attempt = 1; ; attempt++) {
+    const throttled = throttleDelay();
+    if (throttled > 0) await new Promise((resolve) => setTimeout(resolve, throttled));
+
+    const timeout = AbortSignal.timeout(timeoutMs);
+    const signal = init.signal ? AbortSignal.any([init.signal, timeout]) : timeout;
+
+    let reason: string;
+    let delay = backoffDelay(attempt, baseDelayMs, maxDelayMs);
+    try {
+      const response = await cassetteFetch(url, { ...init, signal, ...proxyFetchOptions(url) } as RequestInit);
+      if (!isRetryableStatus(response.status) || !idempotent || attempt >= maxAttempts) {
+        return { response, attempts: attempt };
+      }
+      delay = Math.max(delay, (parseRetryAfter(response.headers?.get('retry-after')) ?? 0) * 1000);
+      // Waiting would overrun the budget; hand the response back so the caller can report it
+      if (Date.now() + delay > deadline) return { response, attempts: attempt };
+      if (isReplaying()) delay = 0;
+      if (response.status === 429) throttledUntil = Math.max(throttledUntil, Date.now() + delay);
+      reason = `HTTP ${response.status}`;
+    } catch (error) {
+      // A request the cassette has no answer for must not look like a network blip
+      if (error instanceof CassetteMismatchError) throw error;
+      // A caller-initiated abort is not transient; a non-idempotent request may already have been applied
+      const resendable = idempotent || (error instanceof Error && NOT_SENT_ERROR_CODES.has(errorCode(error) ?? ''));
+      if (
+        init.signal?.aborted ||
+        !isRetryableError(error) ||
+        !resendable ||
+        attempt >= maxAttempts ||
+        Date.now() + delay > deadline
+      ) {
+        throw new HttpRetryError(describeError(error, url), attempt, error);
+      }
+      reason = describeError(error, url);
+    }
+
+    logWarn(`[http] ${url} failed (${reason}), retrying in ${Math.round(delay)}ms (attempt ${attempt}/${maxAttempts})`);
+    await new Promise((resolve) => setTimeout(resolve, delay));
+  }
+}
+
+/** " after 3 attempts", or nothing when the first attempt was final */
+export function attemptsSuffix(attempts: number): string {
+  return attempts > 1 ? ` after ${attempts} attempts` : '';
+}
@@ -0,0 +1,80 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { setHttpRetries } from './http-retry.js';
+import { browserProfileApp, createImpersonation, isProductionTarget, resolveUserId } from './impersonation.js';
+
+const api = { apiKey: 'sk_test_abc', baseUrl: 'https://api.workos.com' };
+
+describe('impersonation', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+
+  function respond(body: unknown) {
+    mockFetch.mockResolvedValueOnce({ ok: true, status: 200, text: async () => JSON.stringify(body) });
+  }
+
+  beforeEach(() => {
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    setHttpRetries(0);
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+  });
+
+  describe('resolveUserId', () => {
+    it('passes IDs through without a request', async () => {
+      expect(await resolveUserId('user_01', api)).toBe('user_01');
+      expect(mockFetch).not.toHaveBeenCalled();
+    });
+
+    it('looks up users by email', async () => {
+      respond({ data: [{ id: 'user_01', email: 'jane@example.com' }], list_metadata: {} });
+
+      expect(await resolveUserId('Jane@Example.com', api)).toBe('user_01');
+      expect(mockFetch.mock.calls[0][0]).toContain('email=jane%40example.com');
+    });
+
+    it('fails when no user has the email', async () => {
+      respond({ data: [], list_metadata: {} });
+
+      await expect(resolveUserId('nobody@example.com', api)).rejects.toThrow('No user with email');
+    });
+  });
+
+  it('sends the reason and returns the URL and audit log event', async () => {
+    respond({ url: 'https://auth.example.com/impersonate?token=t', audit_log_event_id: 'evt_01' });
+
+    const result = await createImpersonation({ userId: 'user_01', reason: 'ticket 1234' }, api);
+
+    expect(result).toEqual({
+      url: 'https://auth.example.com/impersonate?token=t',
+      auditLogEventId: 'evt_01',
+      expiresAt: null,
+    });
+    const [url, init] = mockFetch.mock.calls[0];
+    expect(url).toBe('https://api.workos.com/user_management/users/user_01/impersonate');
+    expect(JSON.parse(init.body)).toEqual({ reason: 'ticket 1234' });
+  });
+});
+
+describe('isProductionTarget', () => {
+  it('goes by the key prefix first', () => {
+    expect(isProductionTarget('sk_live_abc', null)).toBe(true);
+    expect(isProductionTarget('sk_test_abc', { name: 'prod', type: 'production', apiKey: 'sk_test_abc' })).toBe(false);
+  });
+
+  it('falls back to the active environment type for its own key', () => {
+    const env = { name: 'prod', type: 'production' as const, apiKey: 'key_abc' };
+
+    expect(isProductionTarget('key_abc', env)).toBe(true);
+    expect(isProductionTarget('key_other', env)).toBe(false);
+  });
+});
+
+describe('browserProfileApp', () => {
+  it('opens Chrome with the profile directory', () => {
+    expect(browserProfileApp('Profile 2', 'darwin')).toEqual(['google chrome', '--profile-directory=Profile 2']);
+    expect(browserProfileApp('Default', 'linux')[0]).toBe('google-chrome');
+  });
+});
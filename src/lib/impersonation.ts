@@ -0,0 +1,81 @@
+/**
+ * User impersonation for support engineers.
+ *
+ * The API returns a one-time authorization URL that signs the caller in as
+ * the user, and records an audit log event with the given reason.
+ */
+
+import type { EnvironmentConfig } from './config-store.js';
+import { workosRequest, type WorkOSListResponse } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface Impersonation {
+  url: string;
+  auditLogEventId: string | null;
+  expiresAt: string | null;
+}
+
+/**
+ * Accept a user ID or an email; emails are looked up so the caller doesn't
+ * have to find the ID first.
+ */
+export async function resolveUserId(idOrEmail: string, api: ApiOptions): Promise<string> {
+  if (!idOrEmail.includes('@')) return idOrEmail;
+
+  const result = await workosRequest<WorkOSListResponse<{ id: string; email: string }>>({
+    method: 'GET',
+    path: '/user_management/users',
+    params: { email: idOrEmail.toLowerCase(), limit: 1 },
+    ...api,
+  });
+  const user = result.data[0];
+  if (!user) throw new Error(`No user with email ${idOrEmail}.`);
+  return user.id;
+}
+
+export async function createImpersonation(
+  options: { userId: string; reason: string },
+  api: ApiOptions,
+): Promise<Impersonation> {
+  const result = await workosRequest<{ url: string; audit_log_event_id?: string; expires_at?: string }>({
+    method: 'POST',
+    path: `/user_management/users/${options.userId}/impersonate`,
+    body: { reason: options.reason },
+    ...api,
+  });
+  return {
+    url: result.url,
+    auditLogEventId: result.audit_log_event_id ?? null,
+    expiresAt: result.expires_at ?? null,
+  };
+}
+
+/**
+ * Whether the key targets production. A live key is production regardless
+ * of which environment is active (WORKOS_API_KEY and --api-key bypass it).
+ */
+export function isProductionTarget(apiKey: string, activeEnv: EnvironmentConfig | null | undefined): boolean {
+  if (apiKey.startsWith('sk_live_')) return true;
+  if (apiKey.startsWith('sk_test_')) return false;
+  return activeEnv?.apiKey === apiKey && activeEnv.type === 'production';
+}
+
+/** Chrome's executable name per platform, as opn expects it */
+function chromeApp(platform: NodeJS.Platform): string {
+  if (platform === 'darwin') return 'google chrome';
+  if (platform === 'win32') return 'chrome';
+  return 'google-chrome';
+}
+
+/**
+ * opn `app` option opening a URL in a Chrome profile. The profile is the
+ * directory name shown at chrome://version (e.g. "Profile 2"), not the
+ * display name.
+ */
+export function browserProfileApp(profile: string, platform: NodeJS.Platform = process.platform): string[] {
+  return [chromeApp(platform), `--profile-directory=${profile}`];
+}
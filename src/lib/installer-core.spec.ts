@@ -3,6 +3,7 @@ import { createActor, fromPromise } from 'xstate';
 import { installerMachine } from './installer-core.js';
 import { createInstallerEventEmitter } from './events.js';
 import type { InstallerOptions } from '../utils/types.js';
+import { DirtyWorkingTreeError } from '../utils/errors.js';
 import type {
   DetectionOutput,
   GitCheckOutput,
@@ -25,7 +26,7 @@ const baseMockActors = {
     branch: 'main',
     isProtected: false,
   })),
-  createBranch: fromPromise<{ branch: string }, { name: string; fallbackName: string }>(async ({ input }) => ({
+  createBranch: fromPromise<{ branch: string }, { name: string; reuse: boolean }>(async ({ input }) => ({
     branch: input.name,
   })),
   configureEnvironment: fromPromise<void, { context: InstallerMachineContext }>(async () => {}),
@@ -233,7 +234,7 @@ describe('InstallerCore State Machine', () => {
       dirtyActor.stop();
     });
 
-    it('cancels wizard when user declines git confirmation', async () => {
+    it('stops with a dirty working tree error when user declines git confirmation', async () => {
       const emitter = createInstallerEventEmitter();
       const options: InstallerOptions = {
         debug: false,
@@ -268,7 +269,8 @@ describe('InstallerCore State Machine', () => {
       dirtyActor.send({ type: 'GIT_CANCELLED' });
       await new Promise((r) => setTimeout(r, 50));
 
-      expect(dirtyActor.getSnapshot().value).toBe('cancelled');
+      expect(dirtyActor.getSnapshot().value).toBe('error');
+      expect(dirtyActor.getSnapshot().context.error).toBeInstanceOf(DirtyWorkingTreeError);
       dirtyActor.stop();
     });
   });
@@ -379,4 +381,73 @@ describe('InstallerCore State Machine', () => {
       actor.stop();
     });
   });
+
+  describe('pushing with --push', () => {
+    function createPushActor(push: () => Promise<void>) {
+      const emitter = createInstallerEventEmitter();
+      const options: InstallerOptions = {
+        debug: false,
+        forceInstall: false,
+        installDir: '/test/project',
+        default: false,
+        local: true,
+        ci: false,
+        skipAuth: true,
+        dashboard: false,
+        emitter,
+        apiKey: 'sk_test_123',
+        clientId: 'client_123',
+        push: true,
+        allowMain: true,
+      };
+      const machine = installerMachine.provide({
+        actors: {
+          ...baseMockActors,
+          detectChanges: fromPromise<{ hasChanges: boolean; files: string[] }, void>(async () => ({
+            hasChanges: true,
+            files: ['src/auth.ts'],
+          })),
+          generateCommitMessage: fromPromise<string, { integration: string; files: string[]; direct?: boolean }>(
+            async () => 'Migrate to AuthKit',
+          ),
+          commitChanges: fromPromise<void, { message: string; cwd: string }>(async () => {}),
+          pushBranch: fromPromise<void, { cwd: string }>(push),
+        },
+      });
+      return { actor: createActor(machine, { input: { emitter, options } }), emitter };
+    }
+
+    it('completes once the branch is pushed', async () => {
+      const { actor, emitter } = createPushActor(async () => {});
+      const pushed: string[] = [];
+      emitter.on('postinstall:push:success', ({ branch }) => pushed.push(branch));
+
+      actor.start();
+      actor.send({ type: 'START' });
+      await new Promise((r) => setTimeout(r, 200));
+
+      expect(actor.getSnapshot().value).toBe('complete');
+      expect(pushed).toHaveLength(1);
+      actor.stop();
+    });
+
+    it('fails the run when the push fails, since nothing else keeps the migration', async () => {
+      const { actor, emitter } = createPushActor(async () => {
+        throw new Error('remote rejected');
+      });
+      const failures: string[] = [];
+      emitter.on('postinstall:push:failed', ({ error }) => failures.push(error));
+      // Prevent Node EventEmitter from throwing on 'error' event
+      emitter.on('error', () => {});
+
+      actor.start();
+      actor.send({ type: 'START' });
+      await new Promise((r) => setTimeout(r, 200));
+
+      expect(actor.getSnapshot().value).toBe('error');
+      expect(actor.getSnapshot().context.error?.message).toBe('remote rejected');
+      expect(failures).toEqual(['remote rejected']);
+      actor.stop();
+    });
+  });
 });
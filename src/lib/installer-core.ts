@@ -10,12 +10,33 @@ import type {
   DiscoveryResult,
   CredentialSource,
   BranchCheckOutput,
+  CreateBranchInput,
 } from './installer-core.types.js';
 import type { InstallerOptions } from '../utils/types.js';
 import type { DeviceAuthResult, DeviceAuthResponse } from './device-auth.js';
 import type { StagingCredentials } from './staging-api.js';
 import { getManualPrInstructions } from './post-install.js';
-import { hasGhCli } from '../utils/git-utils.js';
+import { getCurrentBranch, hasGhCli, protectedBranchReason } from '../utils/git-utils.js';
+import { getOriginRemote, getPullRequestBlocker } from './pull-request.js';
+import { DetectionEmptyError, DirtyWorkingTreeError } from '../utils/errors.js';
+import { DEFAULT_BRANCH_TEMPLATE } from './git-conventions.js';
+
+/** How `--create-pr` could reach GitHub from this run */
+function gitHubAccess(context: InstallerMachineContext) {
+  return { hasGh: hasGhCli(), token: process.env.GITHUB_TOKEN, cwd: context.options.installDir };
+}
+
+/**
+ * The protected branch a commit would land on, checked when committing
+ * since the branch may have changed since the branch check. Null with
+ * --allow-main or on a feature branch.
+ */
+function commitBlocker(context: InstallerMachineContext): { branch: string; reason: string } | null {
+  if (context.options.allowMain) return null;
+  const branch = getCurrentBranch(context.options.installDir);
+  const reason = branch && branch !== 'HEAD' ? protectedBranchReason(branch, context.options.installDir) : null;
+  return branch && reason ? { branch, reason } : null;
+}
 
 export const installerMachine = setup({
   types: {
@@ -75,11 +96,16 @@ export const installerMachine = setup({
     emitBranchProtected: ({ context }) => {
       if (context.currentBranch) {
         context.emitter.emit('branch:protected', { branch: context.currentBranch });
-        context.emitter.emit('branch:prompt', { branch: context.currentBranch });
+        context.emitter.emit('branch:prompt', {
+          branch: context.currentBranch,
+          ...(context.protectedBranchReason && { reason: context.protectedBranchReason }),
+          newBranch: context.newBranch ?? DEFAULT_BRANCH_TEMPLATE,
+          newBranchExists: context.newBranchExists ?? false,
+        });
       }
     },
-    emitBranchCreated: ({ context }, params: { branch: string }) => {
-      context.emitter.emit('branch:created', { branch: params.branch });
+    emitBranchCreated: ({ context }, params: { branch: string; reused?: boolean }) => {
+      context.emitter.emit('branch:created', params);
     },
     emitBranchCreateFailed: ({ context }) => {
       const message = context.error?.message ?? 'Failed to create branch';
@@ -94,6 +120,9 @@ export const installerMachine = setup({
         const doneEvent = event as unknown as { output: BranchCheckOutput };
         return doneEvent.output?.isProtected ?? false;
       },
+      protectedBranchReason: ({ event }) => (event as unknown as { output: BranchCheckOutput }).output?.reason,
+      newBranch: ({ event }) => (event as unknown as { output: BranchCheckOutput }).output?.newBranch,
+      newBranchExists: ({ event }) => (event as unknown as { output: BranchCheckOutput }).output?.newBranchExists,
     }),
     emitCredentialsGathering: ({ context }) => {
       const requiresApiKey = ['nextjs', 'tanstack-start', 'react-router'].includes(context.integration ?? '');
@@ -199,7 +228,7 @@ export const installerMachine = setup({
     }),
 
     emitCancelled: ({ context }) => {
-      context.emitter.emit('complete', { success: false, summary: 'Installer cancelled by user' });
+      context.emitter.emit('complete', { success: false, cancelled: true, summary: 'Installer cancelled by user' });
     },
     emitError: ({ context }) => {
       const message = context.error?.message ?? 'An unexpected error occurred';
@@ -219,6 +248,10 @@ export const installerMachine = setup({
     emitNoChanges: ({ context }) => {
       context.emitter.emit('postinstall:nochanges', {});
     },
+    emitCommitBlocked: ({ context }) => {
+      const blocker = commitBlocker(context);
+      if (blocker) context.emitter.emit('postinstall:commit:blocked', blocker);
+    },
     emitCommitPrompt: ({ context }) => {
       context.emitter.emit('postinstall:commit:prompt', {});
     },
@@ -256,6 +289,9 @@ export const installerMachine = setup({
     emitPushing: ({ context }) => {
       context.emitter.emit('postinstall:pr:pushing', {});
     },
+    emitPushed: ({ context }) => {
+      context.emitter.emit('postinstall:push:success', { branch: context.currentBranch ?? 'HEAD' });
+    },
     emitPushFailed: ({ context }) => {
       const message = context.error?.message ?? 'Push failed';
       context.emitter.emit('postinstall:push:failed', { error: message });
@@ -265,12 +301,20 @@ export const installerMachine = setup({
     },
     assignPrUrl: assign({
       prUrl: ({ event }) => {
-        const doneEvent = event as unknown as { output: string };
-        return doneEvent.output;
+        const doneEvent = event as unknown as { output: { url: string } };
+        return doneEvent.output.url;
+      },
+      prUpdated: ({ event }) => {
+        const doneEvent = event as unknown as { output: { updated: boolean } };
+        return doneEvent.output.updated;
       },
     }),
     emitPrCreated: ({ context }) => {
-      context.emitter.emit('postinstall:pr:success', { url: context.prUrl ?? '' });
+      context.emitter.emit('postinstall:pr:success', { url: context.prUrl ?? '', updated: context.prUpdated });
+    },
+    emitPrSkipped: ({ context }) => {
+      const reason = getPullRequestBlocker(gitHubAccess(context)) ?? 'the pull request could not be opened';
+      context.emitter.emit('postinstall:pr:skipped', { reason });
     },
     emitPrFailed: ({ context }) => {
       const message = context.error?.message ?? 'PR creation failed';
@@ -283,7 +327,7 @@ export const installerMachine = setup({
     },
     emitComplete: ({ context }) => {
       const summary = context.agentSummary ?? 'WorkOS AuthKit installed successfully!';
-      context.emitter.emit('complete', { success: true, summary });
+      context.emitter.emit('complete', { success: true, summary, changedFiles: context.changedFiles });
     },
   },
 
@@ -294,6 +338,14 @@ export const installerMachine = setup({
     hasIntegration: ({ context }) => context.integration !== undefined,
     shouldSkipPostInstall: ({ context }) => context.options.noCommit === true,
     hasGhCli: () => hasGhCli(),
+    createPrRequested: ({ context }) => context.options.createPr === true,
+    pushOnly: ({ context }) => context.options.push === true && context.options.createPr !== true,
+    shouldCreatePr: ({ context }) =>
+      context.options.createPr === true && getPullRequestBlocker(gitHubAccess(context)) === null,
+    originIsNotGitHub: ({ context }) => {
+      const origin = getOriginRemote(context.options.installDir);
+      return origin !== null && origin.github === null;
+    },
   },
 
   actors: {
@@ -335,7 +387,7 @@ export const installerMachine = setup({
     checkBranch: fromPromise<BranchCheckOutput, void>(async () => {
       throw new Error('checkBranch not implemented - provide via machine.provide()');
     }),
-    createBranch: fromPromise<{ branch: string }, { name: string; fallbackName: string }>(async () => {
+    createBranch: fromPromise<{ branch: string; reused?: boolean }, CreateBranchInput>(async () => {
       throw new Error('createBranch not implemented - provide via machine.provide()');
     }),
     // Post-install actors
@@ -357,7 +409,7 @@ export const installerMachine = setup({
     pushBranch: fromPromise<void, { cwd: string }>(async () => {
       throw new Error('pushBranch not implemented - provide via machine.provide()');
     }),
-    createPr: fromPromise<string, { title: string; body: string; cwd: string }>(async () => {
+    createPr: fromPromise<{ url: string; updated: boolean }, { title: string; body: string; cwd: string }>(async () => {
       throw new Error('createPr not implemented - provide via machine.provide()');
     }),
   },
@@ -489,9 +541,13 @@ export const installerMachine = setup({
                   target: 'done',
                   actions: ['emitGitConfirmed'],
                 },
+                // Not a cancel: automation needs to tell the two apart
                 GIT_CANCELLED: {
-                  target: '#installer.cancelled',
-                  actions: ['emitGitCancelled'],
+                  target: '#installer.error',
+                  actions: [
+                    'emitGitCancelled',
+                    assign({ error: ({ context }) => new DirtyWorkingTreeError(context.gitDirtyFiles) }),
+                  ],
                 },
               },
             },
@@ -530,6 +586,9 @@ export const installerMachine = setup({
                 BRANCH_CREATE: {
                   target: 'creating',
                 },
+                BRANCH_REUSE: {
+                  target: 'creating',
+                },
                 BRANCH_CONTINUE: {
                   target: 'done',
                 },
@@ -542,16 +601,16 @@ export const installerMachine = setup({
               invoke: {
                 id: 'createBranch',
                 src: 'createBranch',
-                input: () => ({
-                  name: 'feat/add-workos-authkit',
-                  fallbackName: `feat/add-workos-authkit-${Date.now()}`,
+                input: ({ context, event }) => ({
+                  name: context.newBranch ?? DEFAULT_BRANCH_TEMPLATE,
+                  reuse: event.type === 'BRANCH_REUSE',
                 }),
                 onDone: {
                   target: 'done',
                   actions: [
                     {
                       type: 'emitBranchCreated',
-                      params: ({ event }) => ({ branch: (event.output as { branch: string }).branch }),
+                      params: ({ event }) => event.output as { branch: string; reused?: boolean },
                     },
                   ],
                 },
@@ -577,7 +636,7 @@ export const installerMachine = setup({
         {
           target: 'error',
           actions: [
-            assign({ error: () => new Error('Could not detect framework integration') }),
+            assign({ error: () => new DetectionEmptyError() }),
             { type: 'emitStateExit', params: { state: 'preparing' } },
           ],
         },
@@ -881,6 +940,22 @@ export const installerMachine = setup({
             id: 'detectChanges',
             src: 'detectChanges',
             onDone: [
+              {
+                // Stop before git would refuse the commit (or land it on main)
+                target: 'done',
+                guard: ({ context, event }) =>
+                  (event.output as { hasChanges: boolean; files: string[] }).hasChanges &&
+                  commitBlocker(context) !== null,
+                actions: ['assignChangedFiles', 'emitChangesDetected', 'emitCommitBlocked'],
+              },
+              {
+                // --create-pr and --push already asked for the commit
+                target: 'generatingCommitMessage',
+                guard: ({ context, event }) =>
+                  (context.options.createPr === true || context.options.push === true) &&
+                  (event.output as { hasChanges: boolean; files: string[] }).hasChanges,
+                actions: ['assignChangedFiles', 'emitChangesDetected'],
+              },
               {
                 target: 'promptingCommit',
                 guard: ({ event }) => (event.output as { hasChanges: boolean; files: string[] }).hasChanges,
@@ -943,6 +1018,26 @@ export const installerMachine = setup({
 
         checkingGhCli: {
           always: [
+            {
+              // --push without --create-pr: the branch is all that was asked for
+              target: 'pushing',
+              guard: 'pushOnly',
+            },
+            {
+              // No GitHub to open a PR on, and the manual steps would point there
+              target: 'done',
+              guard: 'originIsNotGitHub',
+              actions: ['emitPrSkipped'],
+            },
+            {
+              target: 'generatingPrDescription',
+              guard: 'shouldCreatePr',
+            },
+            {
+              target: 'showingManualInstructions',
+              guard: 'createPrRequested',
+              actions: ['emitPrSkipped'],
+            },
             {
               target: 'promptingPr',
               guard: 'hasGhCli',
@@ -986,11 +1081,12 @@ export const installerMachine = setup({
             id: 'pushBranch',
             src: 'pushBranch',
             input: ({ context }) => ({ cwd: context.options.installDir }),
-            onDone: { target: 'creatingPr' },
-            onError: {
-              target: 'showingManualInstructions',
-              actions: ['assignError', 'emitPushFailed'],
-            },
+            onDone: [{ target: 'done', guard: 'pushOnly', actions: ['emitPushed'] }, { target: 'creatingPr' }],
+            onError: [
+              // With only --push asked for, the run failed if the branch isn't on the remote
+              { target: '#installer.error', guard: 'pushOnly', actions: ['assignError', 'emitPushFailed'] },
+              { target: 'showingManualInstructions', actions: ['assignError', 'emitPushFailed'] },
+            ],
           },
         },
 
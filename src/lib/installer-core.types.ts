@@ -49,6 +49,12 @@ export interface InstallerMachineContext {
   currentBranch?: string;
   /** Whether current branch is protected */
   isProtectedBranch?: boolean;
+  /** Why it is protected: git config, origin's default branch, or its name */
+  protectedBranchReason?: string;
+  /** Feature branch offered instead of a protected one, from the project's branch template */
+  newBranch?: string;
+  /** Whether that branch already exists, so it can be switched to */
+  newBranchExists?: boolean;
   /** Files changed during agent execution (for post-install) */
   changedFiles?: string[];
   /** AI-generated commit message */
@@ -57,6 +63,8 @@ export interface InstallerMachineContext {
   prDescription?: string;
   /** URL of created PR */
   prUrl?: string;
+  /** Whether the branch already had a PR, which was updated instead */
+  prUpdated?: boolean;
   /** Summary message from agent execution */
   agentSummary?: string;
 }
@@ -86,6 +94,7 @@ export type InstallerMachineEvent =
   | { type: 'RETRY_AUTH' }
   // Branch check events
   | { type: 'BRANCH_CREATE' }
+  | { type: 'BRANCH_REUSE' }
   | { type: 'BRANCH_CONTINUE' }
   | { type: 'BRANCH_CANCEL' }
   // Post-install events
@@ -124,4 +133,19 @@ export interface AgentOutput {
 export interface BranchCheckOutput {
   branch: string | null;
   isProtected: boolean;
+  /** Why the branch counts as protected, for the prompt */
+  reason?: string;
+  /** Feature branch to offer when the current one is protected */
+  newBranch?: string;
+  newBranchExists?: boolean;
+}
+
+/**
+ * Input to the create branch actor: `reuse` switches to an existing
+ * `name`; otherwise a new branch is created, with a numeric suffix when
+ * `name` is taken.
+ */
+export interface CreateBranchInput {
+  name: string;
+  reuse: boolean;
 }
@@ -0,0 +1,130 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { readFileSync } from 'node:fs';
+import { PassThrough } from 'node:stream';
+import { createInstallerEventEmitter } from './events.js';
+import {
+  _resetMachineResult,
+  _setMachineClock,
+  _setMachineOutput,
+  PROTOCOL_VERSION,
+  streamMachineMessages,
+  writeMachineMessage,
+  writeMachineResult,
+} from './installer-proto.js';
+import clack from '../utils/clack.js';
+import { _setMachineInput, machinePrompt, setMachineMode } from '../utils/machine-prompts.js';
+import { PLAIN_CANCEL } from '../utils/plain-prompts.js';
+
+const GOLDEN = new URL('./installer-proto.golden.ndjson', import.meta.url);
+
+describe('installer-proto', () => {
+  let lines: string[];
+  let input: PassThrough;
+
+  /** Answer as soon as `id` is asked */
+  const answerWhenAsked = (id: string, ...answers: unknown[]) => {
+    const write = (line: string) => {
+      lines.push(line);
+      const message = JSON.parse(line);
+      if ((message.type === 'prompt' || message.type === 'prompt:invalid') && message.id === id && answers.length) {
+        setImmediate(() => input.write(`${JSON.stringify(answers.shift())}\n`));
+      }
+    };
+    _setMachineOutput(write);
+  };
+
+  beforeEach(() => {
+    lines = [];
+    input = new PassThrough();
+    _setMachineInput(input);
+    _setMachineClock(() => '2024-05-01T12:00:00.000Z');
+    _setMachineOutput((line) => lines.push(line));
+    _resetMachineResult();
+    setMachineMode(true);
+  });
+
+  afterEach(() => {
+    setMachineMode(false);
+    _setMachineInput(process.stdin);
+    _setMachineOutput(() => {});
+  });
+
+  it('streams a full run as the golden messages', async () => {
+    const emitter = createInstallerEventEmitter();
+    streamMachineMessages(emitter, '/repo');
+    writeMachineMessage({ type: 'hello', cliVersion: '1.2.3' });
+
+    emitter.emit('auth:checking', {});
+    emitter.emit('auth:success', {});
+    emitter.emit('detection:start', {});
+    emitter.emit('detection:complete', { integration: 'nextjs' });
+    emitter.emit('agent:estimate', {
+      estimate: { files: 4, calls: 12, inputTokens: 90000, outputTokens: 8000, costUsd: 0.39, durationMs: 180000 },
+    });
+    emitter.emit('state:enter', { state: 'runningAgent' });
+
+    answerWhenAsked('agent-estimate', { id: 'agent-estimate', value: 'Start the agent' });
+    const start = await clack.select({
+      message: 'Start the agent?',
+      options: [
+        { value: 'Start the agent', label: 'Start the agent' },
+        { value: 'Cancel', label: 'Cancel' },
+      ],
+      id: 'agent-estimate',
+    });
+    expect(start).toBe('Start the agent');
+
+    emitter.emit('config:start', {});
+    emitter.emit('config:complete', {});
+    emitter.emit('agent:start', {});
+    emitter.emit('file:write', { path: '/repo/app/callback/route.ts', content: 'export const GET = handleAuth();' });
+    emitter.emit('file:edit', { path: '/repo/middleware.ts', oldContent: '', newContent: 'export default authkit();' });
+    emitter.emit('agent:success', { summary: 'Added AuthKit' });
+    emitter.emit('validation:start', { framework: 'nextjs' });
+    emitter.emit('validation:complete', { passed: true, issueCount: 0, durationMs: 1200 });
+    emitter.emit('verification:start', { checks: 2, attempt: 1 });
+    emitter.emit('verification:complete', { passed: true, failed: [], attempt: 1, durationMs: 4000 });
+
+    answerWhenAsked('commit', { id: 'commit', value: 'yes' }, { id: 'commit', value: true });
+    expect(await clack.confirm({ message: 'Commit the changes?', initialValue: true, id: 'commit' })).toBe(true);
+
+    emitter.emit('postinstall:commit:generating', {});
+    emitter.emit('postinstall:commit:success', { message: 'feat: add WorkOS AuthKit' });
+    emitter.emit('postinstall:pr:skipped', { reason: 'No GitHub remote' });
+    writeMachineResult(0, null);
+
+    expect(`${lines.join('\n')}\n`).toBe(readFileSync(GOLDEN, 'utf-8'));
+    expect(lines.map((line) => JSON.parse(line).v)).toEqual(lines.map(() => PROTOCOL_VERSION));
+  });
+
+  it('keeps answers that arrive before their prompt, and cancels when stdin closes', async () => {
+    input.write('{"id":"client-id","value":"client_123"}\n');
+    input.write('not json\n');
+
+    const clientId = await clack.text({ message: 'Enter your WorkOS Client ID:', flag: '--client-id' });
+    expect(clientId).toBe('client_123');
+    expect(JSON.parse(lines[0])).toMatchObject({ type: 'prompt', id: 'client-id', kind: 'text', flag: '--client-id' });
+
+    const pending = machinePrompt('confirm', { message: 'Create a pull request?', id: 'pull-request' });
+    input.end();
+    expect(await pending).toBe(PLAIN_CANCEL);
+    expect(lines.map((line) => JSON.parse(line).type)).toContain('prompt:invalid');
+  });
+
+  it('reports a failed run with its error code, once', () => {
+    writeMachineResult(6, { code: 'VERIFICATION_FAILED', message: 'Verification check(s) still failing: callback' });
+    writeMachineResult(0, null);
+
+    expect(lines.map((line) => JSON.parse(line))).toEqual([
+      {
+        v: PROTOCOL_VERSION,
+        time: '2024-05-01T12:00:00.000Z',
+        type: 'result',
+        status: 'failed',
+        exitCode: 6,
+        code: 'VERIFICATION_FAILED',
+        message: 'Verification check(s) still failing: callback',
+      },
+    ]);
+  });
+});
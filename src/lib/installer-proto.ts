@@ -0,0 +1,303 @@
+/**
+ * `--machine`: the installer's protocol for programs that drive it, such
+ * as bootstrap tools that shell out to `workos install`.
+ *
+ * stdout carries one JSON message per line and nothing else; people-facing
+ * output goes to stderr. When the installer needs an answer it sends a
+ * `prompt` message and reads the answer from stdin as one JSON line:
+ *
+ * ```
+ * → {"v":1,"type":"prompt","id":"branch","kind":"select","message":"...","options":[{"value":"create",...}]}
+ * ← {"id":"branch","value":"create"}
+ * ```
+ *
+ * Confirms take `true` or `false`, multiselects an array of option values,
+ * and `{"id":"...","cancel":true}` cancels a prompt. An answer that doesn't
+ * fit gets a `prompt:invalid` message and the prompt keeps waiting; closing
+ * stdin cancels whatever is still waiting.
+ *
+ * Unlike `--events ndjson`, which streams internal installer events as they
+ * are, these messages are a contract. Changes are additive only: new
+ * message types, new fields and new values (a new `step`, say) can appear
+ * in any release, so consumers must ignore what they don't know. Removing
+ * or changing anything would bump PROTOCOL_VERSION, which every message
+ * carries as `v`.
+ */
+
+import { isAbsolute } from 'node:path';
+import type { InstallerEventEmitter, InstallerEventName, InstallerEvents } from './events.js';
+import { writeStdoutLine } from './event-stream.js';
+import { redactCredentials } from '../utils/redact.js';
+import { relativePosix } from '../utils/paths.js';
+
+export const PROTOCOL_VERSION = 1;
+
+/** The parts of a run reported as `step` messages, in the order they usually happen */
+export type MachineStep =
+  | 'authenticate'
+  | 'detect'
+  | 'configure'
+  | 'agent'
+  | 'validate'
+  | 'verify'
+  | 'commit'
+  | 'pull-request';
+
+export type MachineStepStatus = 'started' | 'finished' | 'failed' | 'skipped';
+
+export type MachinePromptKind = 'confirm' | 'select' | 'multiselect' | 'text' | 'password';
+
+export interface MachinePromptOption {
+  /** What to answer with to pick this option */
+  value: string | number | boolean;
+  label: string;
+  hint?: string;
+}
+
+interface Envelope {
+  /** PROTOCOL_VERSION */
+  v: number;
+  /** ISO 8601 */
+  time: string;
+}
+
+/** First message of every run */
+export interface HelloMessage extends Envelope {
+  type: 'hello';
+  cliVersion: string;
+}
+
+/** What the agent is about to do, sent before it starts */
+export interface PlanMessage extends Envelope {
+  type: 'plan';
+  /** Detected or given with --integration; null when unknown */
+  integration: string | null;
+  /** Files the plan expects the agent to change */
+  files: number;
+  estimate: { calls: number; inputTokens: number; outputTokens: number; costUsd: number | null; durationMs: number };
+}
+
+/** An answer is needed on stdin */
+export interface PromptMessage extends Envelope {
+  type: 'prompt';
+  /** Stable for a given question, e.g. `branch` or `client-id`; answers name it */
+  id: string;
+  kind: MachinePromptKind;
+  message: string;
+  options?: MachinePromptOption[];
+  initial?: unknown;
+  /** Flag that answers the question up front, e.g. `--client-id` */
+  flag?: string;
+}
+
+/** An answer on stdin couldn't be used; the prompt is still waiting */
+export interface PromptInvalidMessage extends Envelope {
+  type: 'prompt:invalid';
+  /** null when the line couldn't be matched to a prompt */
+  id: string | null;
+  message: string;
+}
+
+export interface StepMessage extends Envelope {
+  type: 'step';
+  step: MachineStep;
+  status: MachineStepStatus;
+  message?: string;
+}
+
+/** The agent wrote or edited a file */
+export interface FileMessage extends Envelope {
+  type: 'file';
+  /** Relative to the install directory, with forward slashes */
+  path: string;
+  action: 'write' | 'edit';
+}
+
+/** Checks against the running app after the agent finished; sent for each attempt */
+export interface VerificationMessage extends Envelope {
+  type: 'verification';
+  passed: boolean;
+  /** Names of the checks that failed */
+  failed: string[];
+  attempt: number;
+}
+
+/** Last message of every run */
+export interface ResultMessage extends Envelope {
+  type: 'result';
+  status: 'success' | 'failed' | 'cancelled';
+  /** The process's exit code */
+  exitCode: number;
+  /** Stable error code on failure, e.g. `VERIFICATION_FAILED` */
+  code?: string;
+  message?: string;
+  changedFiles?: string[];
+}
+
+export type MachineMessage =
+  | HelloMessage
+  | PlanMessage
+  | PromptMessage
+  | PromptInvalidMessage
+  | StepMessage
+  | FileMessage
+  | VerificationMessage
+  | ResultMessage;
+
+type WithoutEnvelope<M> = M extends MachineMessage ? Omit<M, keyof Envelope> : never;
+
+/** A message before its envelope is added */
+export type MachineMessageBody = WithoutEnvelope<MachineMessage>;
+
+/** An answer read from stdin */
+export interface MachineAnswer {
+  id: string;
+  value?: unknown;
+  cancel?: boolean;
+}
+
+let clock = () => new Date().toISOString();
+
+/** @internal For testing only */
+export function _setMachineClock(now: () => string): void {
+  clock = now;
+}
+
+let writeMessageLine: (line: string) => void = writeStdoutLine;
+
+/** @internal For testing only */
+export function _setMachineOutput(write: (line: string) => void): void {
+  writeMessageLine = write;
+}
+
+export function writeMachineMessage(body: MachineMessageBody): void {
+  const message = { v: PROTOCOL_VERSION, time: clock(), ...body };
+  writeMessageLine(JSON.stringify(redactCredentials(message)));
+}
+
+const STEP_EVENTS: Partial<Record<InstallerEventName, [MachineStep, MachineStepStatus]>> = {
+  'auth:checking': ['authenticate', 'started'],
+  'auth:success': ['authenticate', 'finished'],
+  'auth:failure': ['authenticate', 'failed'],
+  'detection:start': ['detect', 'started'],
+  'detection:complete': ['detect', 'finished'],
+  'detection:none': ['detect', 'failed'],
+  'config:start': ['configure', 'started'],
+  'config:complete': ['configure', 'finished'],
+  'agent:start': ['agent', 'started'],
+  'agent:success': ['agent', 'finished'],
+  'agent:failure': ['agent', 'failed'],
+  'validation:start': ['validate', 'started'],
+  'verification:start': ['verify', 'started'],
+  'postinstall:commit:generating': ['commit', 'started'],
+  'postinstall:commit:success': ['commit', 'finished'],
+  'postinstall:commit:failed': ['commit', 'failed'],
+  'postinstall:pr:generating': ['pull-request', 'started'],
+  'postinstall:pr:success': ['pull-request', 'finished'],
+  'postinstall:pr:failed': ['pull-request', 'failed'],
+  'postinstall:pr:skipped': ['pull-request', 'skipped'],
+};
+
+/** The `message` of a step event, where it has one */
+function stepMessage(event: InstallerEventName, payload: unknown): string | undefined {
+  const data = (payload ?? {}) as { message?: string; error?: string; reason?: string; url?: string };
+  if (event === 'postinstall:pr:success') return data.url;
+  return data.message ?? data.error ?? data.reason;
+}
+
+/**
+ * Turns installer events into protocol messages. What the installer emits
+ * internally can change; this mapping is where the protocol stays put.
+ */
+export class MachineTranslator {
+  private integration: string | null = null;
+  private readonly files = new Set<string>();
+
+  constructor(private readonly installDir: string) {}
+
+  /** Files the agent wrote or edited so far */
+  changedFiles(): string[] {
+    return [...this.files];
+  }
+
+  translate<K extends InstallerEventName>(event: K, payload: InstallerEvents[K]): MachineMessageBody[] {
+    if (event === 'detection:complete') {
+      this.integration = (payload as InstallerEvents['detection:complete']).integration;
+    }
+    const step = STEP_EVENTS[event];
+    if (step) {
+      const message = stepMessage(event, payload);
+      return [{ type: 'step', step: step[0], status: step[1], ...(message && { message }) }];
+    }
+
+    switch (event) {
+      case 'agent:estimate': {
+        const { files, ...estimate } = (payload as InstallerEvents['agent:estimate']).estimate;
+        return [{ type: 'plan', integration: this.integration, files, estimate }];
+      }
+      case 'file:write':
+      case 'file:edit': {
+        const { path } = payload as { path: string };
+        const relative = isAbsolute(path) ? relativePosix(this.installDir, path) : path;
+        this.files.add(relative);
+        return [{ type: 'file', path: relative, action: event === 'file:write' ? 'write' : 'edit' }];
+      }
+      case 'validation:complete': {
+        const { passed, issueCount } = payload as InstallerEvents['validation:complete'];
+        if (passed) return [{ type: 'step', step: 'validate', status: 'finished' }];
+        const message = `${issueCount} issue${issueCount === 1 ? '' : 's'}`;
+        return [{ type: 'step', step: 'validate', status: 'failed', message }];
+      }
+      case 'verification:complete': {
+        const { passed, failed, attempt } = payload as InstallerEvents['verification:complete'];
+        return [
+          { type: 'verification', passed, failed, attempt },
+          { type: 'step', step: 'verify', status: passed ? 'finished' : 'failed' },
+        ];
+      }
+      default:
+        return [];
+    }
+  }
+}
+
+let active: MachineTranslator | null = null;
+
+/** Stream the run as protocol messages; the `result` comes from {@link writeMachineResult} */
+export function streamMachineMessages(emitter: InstallerEventEmitter, installDir: string): () => void {
+  const translator = new MachineTranslator(installDir);
+  active = translator;
+  return emitter.tap((event, payload) => {
+    for (const message of translator.translate(event, payload as never)) writeMachineMessage(message);
+  });
+}
+
+const CANCEL_EXIT_CODES = [130, 143];
+
+/**
+ * The closing `result`, from the exit code and the error the process is
+ * exiting with. Written once, from the process's exit handler, so every way
+ * out of the run ends the stream the same way.
+ */
+export function writeMachineResult(exitCode: number, error?: { code: string; message: string } | null): void {
+  if (resultWritten) return;
+  resultWritten = true;
+  const status = exitCode === 0 ? 'success' : CANCEL_EXIT_CODES.includes(exitCode) ? 'cancelled' : 'failed';
+  const changedFiles = active?.changedFiles() ?? [];
+  active = null;
+  writeMachineMessage({
+    type: 'result',
+    status,
+    exitCode,
+    ...(exitCode !== 0 && error && { code: error.code, message: error.message }),
+    ...(changedFiles.length > 0 && { changedFiles }),
+  });
+}
+
+let resultWritten = false;
+
+/** @internal For testing only */
+export function _resetMachineResult(): void {
+  resultWritten = false;
+  active = null;
+}
@@ -0,0 +1,98 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  EXIT_CODE_CANCELLED,
+  EXIT_CODE_TERMINATED,
+  _setCheckpointDir,
+  checkpointPath,
+  handleInterrupts,
+  writeCheckpoint,
+} from './interrupt.js';
+
+describe('interrupt', () => {
+  describe('handleInterrupts', () => {
+    let stderr: ReturnType<typeof vi.spyOn>;
+    let exit: ReturnType<typeof vi.spyOn>;
+
+    beforeEach(() => {
+      stderr = vi.spyOn(process.stderr, 'write').mockImplementation(() => true);
+      exit = vi.spyOn(process, 'exit').mockImplementation((() => undefined) as never);
+    });
+
+    afterEach(() => {
+      stderr.mockRestore();
+      exit.mockRestore();
+    });
+
+    it('cancels on the first SIGINT and hard-exits on the second', () => {
+      const onCancel = vi.fn();
+      const handle = handleInterrupts(onCancel);
+
+      process.emit('SIGINT');
+      expect(onCancel).toHaveBeenCalledTimes(1);
+      expect(handle.signal.aborted).toBe(true);
+      expect(handle.interrupted()).toBe(true);
+      expect(exit).not.toHaveBeenCalled();
+
+      process.emit('SIGINT');
+      expect(exit).toHaveBeenCalledWith(EXIT_CODE_CANCELLED);
+      expect(onCancel).toHaveBeenCalledTimes(1);
+
+      handle.dispose();
+    });
+
+    it('cancels on SIGTERM too, with its own exit code', () => {
+      const onCancel = vi.fn();
+      const handle = handleInterrupts(onCancel);
+
+      process.emit('SIGTERM');
+      expect(onCancel).toHaveBeenCalledWith('terminate');
+      expect(handle.signal.aborted).toBe(true);
+
+      process.emit('SIGTERM');
+      expect(exit).toHaveBeenCalledWith(EXIT_CODE_TERMINATED);
+
+      handle.dispose();
+    });
+
+    it('stops listening once disposed', () => {
+      const onCancel = vi.fn();
+      const before = process.listenerCount('SIGINT');
+      const handle = handleInterrupts(onCancel);
+      handle.dispose();
+
+      expect(process.listenerCount('SIGINT')).toBe(before);
+      expect(handle.interrupted()).toBe(false);
+    });
+  });
+
+  describe('writeCheckpoint', () => {
+    let testDir: string;
+
+    beforeEach(() => {
+      testDir = mkdtempSync(join(tmpdir(), 'checkpoint-test-'));
+      _setCheckpointDir(join(testDir, 'checkpoints'));
+    });
+
+    afterEach(() => {
+      rmSync(testDir, { recursive: true, force: true });
+    });
+
+    it('records where the run stopped per project', () => {
+      const path = writeCheckpoint({
+        installDir: '/projects/app',
+        integration: 'nextjs',
+        state: 'runningAgent',
+        changedFiles: ['middleware.ts'],
+      });
+
+      expect(path).toBe(checkpointPath('/projects/app'));
+      expect(checkpointPath('/projects/other')).not.toBe(path);
+      const saved = JSON.parse(readFileSync(path!, 'utf-8'));
+      expect(saved).toMatchObject({ state: 'runningAgent', changedFiles: ['middleware.ts'] });
+      expect(saved.cancelledAt).toEqual(expect.any(String));
+    });
+  });
+});
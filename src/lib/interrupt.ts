@@ -0,0 +1,119 @@
+/**
+ * Ctrl-C and SIGTERM handling for long runs.
+ *
+ * The first SIGINT or SIGTERM cancels: the state machine stops scheduling
+ * steps, the agent's process group is stopped and waited for, and the caller
+ * saves a checkpoint before exiting with the signal's exit code. A second
+ * signal exits immediately. Agent timeouts stop the run the same way.
+ *
+ * The installer's own writes go through applyFileEdits, which is
+ * synchronous, so a signal is only ever handled between writes: an
+ * in-flight write always finishes before cancellation starts.
+ */
+
+import { createHash } from 'node:crypto';
+import { mkdirSync, writeFileSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
+
+/** Exit code for a run the user cancelled (128 + SIGINT, as shells report it) */
+export const EXIT_CODE_CANCELLED = 130;
+/** Exit code for a run stopped by SIGTERM (128 + SIGTERM) */
+export const EXIT_CODE_TERMINATED = 143;
+/** Exit code for a run whose agent timed out, as timeout(1) uses */
+export const EXIT_CODE_TIMEOUT = 124;
+
+/** Why a run stopped before finishing */
+export type StopReason = 'interrupt' | 'terminate' | 'idle-timeout' | 'timeout';
+
+const EXIT_CODES: Record<StopReason, number> = {
+  interrupt: EXIT_CODE_CANCELLED,
+  terminate: EXIT_CODE_TERMINATED,
+  'idle-timeout': EXIT_CODE_TIMEOUT,
+  timeout: EXIT_CODE_TIMEOUT,
+};
+
+export function exitCodeFor(reason: StopReason): number {
+  return EXIT_CODES[reason];
+}
+
+export interface InterruptHandle {
+  /** Aborted on the first SIGINT or SIGTERM */
+  signal: AbortSignal;
+  /** True once the first signal arrived */
+  interrupted(): boolean;
+  dispose(): void;
+}
+
+/**
+ * Call `onCancel` on the first SIGINT or SIGTERM and hard-exit on the second.
+ */
+export function handleInterrupts(onCancel: (reason: 'interrupt' | 'terminate') => void): InterruptHandle {
+  const controller = new AbortController();
+  const handler = (reason: 'interrupt' | 'terminate') => () => {
+    if (controller.signal.aborted) {
+      process.stderr.write('\nForce quit\n');
+      process.exit(exitCodeFor(reason));
+    } else {
+      controller.abort(new Error(reason === 'interrupt' ? 'Cancelled by user' : 'Terminated'));
+      process.stderr.write(
+        reason === 'interrupt'
+          ? '\nCancelling... press Ctrl-C again to quit immediately\n'
+          : '\nReceived SIGTERM, stopping the agent...\n',
+      );
+      onCancel(reason);
+    }
+  };
+  const onSigint = handler('interrupt');
+  const onSigterm = handler('terminate');
+  process.on('SIGINT', onSigint);
+  process.on('SIGTERM', onSigterm);
+
+  return {
+    signal: controller.signal,
+    interrupted: () => controller.signal.aborted,
+    dispose: () => {
+      process.off('SIGINT', onSigint);
+      process.off('SIGTERM', onSigterm);
+    },
+  };
+}
+
+export interface Checkpoint {
+  installDir: string;
+  integration?: string;
+  /** Last installer state entered before the cancel */
+  state: string | null;
+  /** Uncommitted files at the time of the cancel, from git */
+  changedFiles: string[];
+  reason?: StopReason;
+  cancelledAt: string;
+}
+
+let checkpointDir = join(homedir(), '.workos', 'checkpoints');
+
+/** @internal For testing only */
+export function _setCheckpointDir(dir: string): void {
+  checkpointDir = dir;
+}
+
+/** Per-project checkpoint path, outside the repo like migration selections */
+export function checkpointPath(installDir: string): string {
+  const key = createHash('sha256').update(installDir).digest('hex').slice(0, 16);
+  return join(checkpointDir, `${key}.json`);
+}
+
+/**
+ * Record where a cancelled run stopped.
+ * @returns The checkpoint path, or null if it couldn't be written
+ */
+export function writeCheckpoint(checkpoint: Omit<Checkpoint, 'cancelledAt'>): string | null {
+  const path = checkpointPath(checkpoint.installDir);
+  try {
+    mkdirSync(dirname(path), { recursive: true });
+    writeFileSync(path, JSON.stringify({ ...checkpoint, cancelledAt: new Date().toISOString() }, null, 2));
+    return path;
+  } catch {
+    return null;
+  }
+}
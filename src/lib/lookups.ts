@@ -0,0 +1,106 @@
+/**
+ * Read-only lookups for tab completion and interactive pickers. They list
+ * the first page (enough to complete from) and are served from the API
+ * cache, so repeated completions don't wait on the network; pickers fetch
+ * the pages after it as they scroll.
+ */
+
+import { cachedLookup, cacheScope } from './api-cache.js';
+import { workosRequest, type WorkOSListResponse } from './workos-api.js';
+import type { PickerItem, PickerPage } from '../utils/fuzzy-picker.js';
+
+/** Enough to pick from; the rest are a `list --all` away */
+const PAGE_SIZE = 100;
+
+export interface LookupChoice {
+  /** What gets completed or returned, e.g. an organization ID */
+  value: string;
+  /** Shown next to the value, e.g. the organization name */
+  label: string;
+  /** Also matched when filtering a picker, e.g. the organization's domains */
+  keywords?: string[];
+}
+
+interface RawOrganization {
+  id: string;
+  name: string;
+  domains?: Array<{ domain: string }>;
+}
+
+interface RawUser {
+  id: string;
+  email: string;
+  first_name?: string | null;
+  last_name?: string | null;
+}
+
+async function fetchOrganizations(apiKey: string, baseUrl: string, after?: string): Promise<LookupChoice[]> {
+  const page = await workosRequest<WorkOSListResponse<RawOrganization>>({
+    method: 'GET',
+    path: '/organizations',
+    apiKey,
+    baseUrl,
+    params: { limit: PAGE_SIZE, after },
+  });
+  return page.data.map((org) => ({
+    value: org.id,
+    label: org.name,
+    keywords: (org.domains ?? []).map((d) => d.domain),
+  }));
+}
+
+async function fetchUsers(apiKey: string, baseUrl: string, after?: string): Promise<LookupChoice[]> {
+  const page = await workosRequest<WorkOSListResponse<RawUser>>({
+    method: 'GET',
+    path: '/user_management/users',
+    apiKey,
+    baseUrl,
+    params: { limit: PAGE_SIZE, after },
+  });
+  return page.data.map((user) => ({
+    value: user.id,
+    label: user.email,
+    keywords: [[user.first_name, user.last_name].filter(Boolean).join(' ')].filter(Boolean),
+  }));
+}
+
+export async function organizationChoices(apiKey: string, baseUrl: string): Promise<LookupChoice[]> {
+  return cachedLookup('organizations', cacheScope(apiKey, baseUrl), () => fetchOrganizations(apiKey, baseUrl));
+}
+
+export async function userChoices(apiKey: string, baseUrl: string): Promise<LookupChoice[]> {
+  return cachedLookup('users', cacheScope(apiKey, baseUrl), () => fetchUsers(apiKey, baseUrl));
+}
+
+type LoadMore = () => Promise<PickerPage<string>>;
+
+/** A choice as a picker item, with the value as its hint */
+export function toPickerItem(choice: LookupChoice): PickerItem<string> {
+  return { value: choice.value, label: choice.label, hint: choice.value, keywords: choice.keywords };
+}
+
+/**
+ * Loader for the pages after `first`, or undefined when `first` was short
+ * of a full page. WorkOS cursors are object IDs, so each page starts after
+ * the last ID loaded.
+ */
+function pagesAfter(
+  first: LookupChoice[],
+  fetchPage: (after: string) => Promise<LookupChoice[]>,
+): LoadMore | undefined {
+  if (first.length < PAGE_SIZE) return undefined;
+  let last = first[first.length - 1].value;
+  return async () => {
+    const choices = await fetchPage(last);
+    if (choices.length > 0) last = choices[choices.length - 1].value;
+    return { items: choices.map(toPickerItem), done: choices.length < PAGE_SIZE };
+  };
+}
+
+export function moreOrganizations(apiKey: string, baseUrl: string, first: LookupChoice[]): LoadMore | undefined {
+  return pagesAfter(first, (after) => fetchOrganizations(apiKey, baseUrl, after));
+}
+
+export function moreUsers(apiKey: string, baseUrl: string, first: LookupChoice[]): LoadMore | undefined {
+  return pagesAfter(first, (after) => fetchUsers(apiKey, baseUrl, after));
+}
@@ -0,0 +1,108 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { setHttpRetries } from './http-retry.js';
+import { createM2MClient, describeM2MTokenError, parseScopes, requestClientCredentialsToken } from './m2m.js';
+
+const api = { apiKey: 'sk_test_abc', baseUrl: 'https://api.workos.com' };
+
+function jwt(claims: Record<string, unknown>): string {
+  return ['e30', Buffer.from(JSON.stringify(claims)).toString('base64url'), 'sig'].join('.');
+}
+
+describe('m2m', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+
+  function respond(body: unknown, status = 200) {
+    mockFetch.mockResolvedValueOnce({
+      ok: status < 400,
+      status,
+      text: async () => JSON.stringify(body),
+      json: async () => body,
+    });
+  }
+
+  beforeEach(() => {
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    setHttpRetries(0);
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+  });
+
+  it('splits space- and comma-separated scopes', () => {
+    expect(parseScopes(['invoices:read,invoices:write', 'users:read  invoices:read'])).toEqual([
+      'invoices:read',
+      'invoices:write',
+      'users:read',
+    ]);
+    expect(parseScopes()).toEqual([]);
+  });
+
+  it('creates an m2m application and then its secret', async () => {
+    respond({
+      id: 'conn_app_01',
+      client_id: 'client_01',
+      name: 'billing-service',
+      application_type: 'm2m',
+      scopes: ['invoices:read'],
+      created_at: '2026-01-01T00:00:00.000Z',
+    });
+    respond({ secret: 'shhh' });
+
+    const { client, secret } = await createM2MClient({ name: 'billing-service', scopes: ['invoices:read'] }, api);
+
+    expect(secret).toBe('shhh');
+    expect(client).toMatchObject({ client_id: 'client_01', organization_id: null });
+    expect(JSON.parse(mockFetch.mock.calls[0][1].body)).toEqual({
+      name: 'billing-service',
+      application_type: 'm2m',
+      scopes: ['invoices:read'],
+    });
+    expect(mockFetch.mock.calls[1][0]).toBe('https://api.workos.com/connect/applications/conn_app_01/client_secrets');
+  });
+
+  describe('requestClientCredentialsToken', () => {
+    const input = {
+      authkitDomain: 'https://example.authkit.app/',
+      clientId: 'client_01',
+      clientSecret: 'shhh',
+      scopes: ['invoices:read'],
+    };
+
+    it('posts the grant and reads the expiry', async () => {
+      respond({ access_token: jwt({ sub: 'client_01' }), expires_in: 3600, scope: 'invoices:read' });
+
+      const token = await requestClientCredentialsToken(input);
+
+      const [url, init] = mockFetch.mock.calls[0];
+      expect(url).toBe('https://example.authkit.app/oauth2/token');
+      expect(Object.fromEntries(init.body)).toEqual({
+        grant_type: 'client_credentials',
+        client_id: 'client_01',
+        client_secret: 'shhh',
+        scope: 'invoices:read',
+      });
+      expect(token.scopes).toEqual(['invoices:read']);
+      expect(token.expiresAt!.getTime()).toBeGreaterThan(Date.now() + 3500 * 1000);
+    });
+
+    it('falls back to the exp claim', async () => {
+      respond({ access_token: jwt({ exp: 2000000000 }) });
+
+      expect((await requestClientCredentialsToken({ ...input, scopes: [] })).expiresAt).toEqual(
+        new Date(2000000000 * 1000),
+      );
+      expect(Object.fromEntries(mockFetch.mock.calls[0][1].body)).not.toHaveProperty('scope');
+    });
+
+    it('reports OAuth errors', async () => {
+      respond({ error: 'invalid_client', error_description: 'Client authentication failed' }, 401);
+
+      const error = await requestClientCredentialsToken(input).catch((e: unknown) => e);
+
+      expect(describeM2MTokenError(error)).toBe('Invalid client ID or secret. Check --client and the secret.');
+    });
+  });
+});
@@ -0,0 +1,154 @@
+/**
+ * Machine-to-machine clients and the client-credentials grant, for testing
+ * M2M auth without scratch scripts.
+ *
+ * Client secrets are returned once, when the client is created, and the
+ * CLI never stores them; `m2m token` takes the secret on every call.
+ */
+
+import { decodeJwtClaims } from './session-seal.js';
+import { listAll } from './pagination.js';
+import { proxiedFetch } from './proxy.js';
+import { workosRequest } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface M2MClient {
+  id: string;
+  client_id: string;
+  name: string;
+  scopes: string[];
+  organization_id: string | null;
+  created_at: string;
+}
+
+interface ConnectApplication extends M2MClient {
+  application_type: string;
+}
+
+export interface M2MToken {
+  accessToken: string;
+  /** From `expires_in`, or the token's `exp` claim */
+  expiresAt: Date | null;
+  scopes: string[];
+}
+
+/** A failed token request, with the OAuth error code when the server sent one */
+export class M2MTokenError extends Error {
+  constructor(
+    message: string,
+    public readonly statusCode: number,
+    public readonly code?: string,
+  ) {
+    super(message);
+    this.name = 'M2MTokenError';
+  }
+}
+
+/** Split `--scopes` values given space- or comma-separated */
+export function parseScopes(values: string[] = []): string[] {
+  return [...new Set(values.flatMap((value) => value.split(/[\s,]+/)).filter(Boolean))];
+}
+
+function toClient(app: ConnectApplication): M2MClient {
+  return {
+    id: app.id,
+    client_id: app.client_id,
+    name: app.name,
+    scopes: app.scopes ?? [],
+    organization_id: app.organization_id ?? null,
+    created_at: app.created_at,
+  };
+}
+
+export async function listM2MClients(options: ApiOptions): Promise<M2MClient[]> {
+  const apps = await listAll<ConnectApplication>({ path: '/connect/applications', ...options });
+  return apps.filter((app) => app.application_type === 'm2m').map(toClient);
+}
+
+/** Create a client and its secret. The secret can't be read again later. */
+export async function createM2MClient(
+  input: { name: string; scopes: string[]; organizationId?: string },
+  options: ApiOptions,
+): Promise<{ client: M2MClient; secret: string }> {
+  const app = await workosRequest<ConnectApplication>({
+    method: 'POST',
+    path: '/connect/applications',
+    body: {
+      name: input.name,
+      application_type: 'm2m',
+      scopes: input.scopes,
+      ...(input.organizationId && { organization_id: input.organizationId }),
+    },
+    ...options,
+  });
+  const { secret } = await workosRequest<{ secret: string }>({
+    method: 'POST',
+    path: `/connect/applications/${app.id}/client_secrets`,
+    ...options,
+  });
+  return { client: toClient(app), secret };
+}
+
+/** Delete by application ID or client ID */
+export async function deleteM2MClient(idOrClientId: string, options: ApiOptions): Promise<M2MClient | null> {
+  const client = (await listM2MClients(options)).find((c) => c.id === idOrClientId || c.client_id === idOrClientId);
+  if (!client) return null;
+  await workosRequest({ method: 'DELETE', path: `/connect/applications/${client.id}`, ...options });
+  return client;
+}
+
+/** Run the client-credentials grant against the environment's AuthKit domain */
+export async function requestClientCredentialsToken(input: {
+  authkitDomain: string;
+  clientId: string;
+  clientSecret: string;
+  scopes: string[];
+}): Promise<M2MToken> {
+  const response = await proxiedFetch(`${input.authkitDomain.replace(/\/$/, '')}/oauth2/token`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+    body: new URLSearchParams({
+      grant_type: 'client_credentials',
+      client_id: input.clientId,
+      client_secret: input.clientSecret,
+      ...(input.scopes.length > 0 && { scope: input.scopes.join(' ') }),
+    }),
+  });
+
+  let data: Record<string, unknown>;
+  try {
+    data = (await response.json()) as Record<string, unknown>;
+  } catch {
+    throw new M2MTokenError(`Unexpected response from ${input.authkitDomain} (${response.status})`, response.status);
+  }
+  if (!response.ok || typeof data.access_token !== 'string') {
+    const code = typeof data.error === 'string' ? data.error : undefined;
+    const description = typeof data.error_description === 'string' ? data.error_description : undefined;
+    throw new M2MTokenError(description ?? code ?? `Token request failed (${response.status})`, response.status, code);
+  }
+
+  const claims = decodeJwtClaims(data.access_token);
+  const expiresAt =
+    typeof data.expires_in === 'number'
+      ? new Date(Date.now() + data.expires_in * 1000)
+      : typeof claims?.exp === 'number'
+        ? new Date(claims.exp * 1000)
+        : null;
+  return {
+    accessToken: data.access_token,
+    expiresAt,
+    scopes: typeof data.scope === 'string' ? parseScopes([data.scope]) : input.scopes,
+  };
+}
+
+/** Turn token errors into something actionable */
+export function describeM2MTokenError(error: unknown): string {
+  if (!(error instanceof M2MTokenError)) return error instanceof Error ? error.message : String(error);
+  if (error.code === 'invalid_client') return 'Invalid client ID or secret. Check --client and the secret.';
+  if (error.code === 'invalid_scope') return `${error.message}. Request only scopes granted to the client.`;
+  return error.message;
+}
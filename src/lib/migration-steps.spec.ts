@@ -0,0 +1,156 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { existsSync, mkdtempSync, readFileSync, realpathSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { createInstallerEventEmitter, type InstallerEventEmitter } from './events.js';
+import { formatStepBreakdown, MigrationStepRecorder } from './migration-steps.js';
+import type { MigrationContext } from '../migrate/types.js';
+import { makeFinding } from '../migrate/findings.test-utils.js';
+
+function git(args: string[], cwd: string): void {
+  execFileSync('git', args, { cwd, stdio: 'ignore' });
+}
+
+function migration(extra: Partial<MigrationContext> = {}): MigrationContext {
+  return {
+    provider: 'auth0',
+    displayName: 'Auth0',
+    forced: false,
+    confidence: 0.9,
+    envMapping: {},
+    guidance: [],
+    findings: [],
+    ...extra,
+  };
+}
+
+describe('migration-steps', () => {
+  let dir: string;
+  let emitter: InstallerEventEmitter;
+
+  beforeEach(() => {
+    dir = realpathSync(mkdtempSync(join(tmpdir(), 'migration-steps-')));
+    emitter = createInstallerEventEmitter();
+    git(['init', '-b', 'main'], dir);
+    git(['config', 'user.email', 'dev@example.com'], dir);
+    git(['config', 'user.name', 'Dev'], dir);
+    writeFileSync(join(dir, 'auth.ts'), 'auth0\n');
+    writeFileSync(join(dir, 'routes.ts'), 'auth0\n');
+    writeFileSync(join(dir, 'wip.ts'), 'committed\n');
+    writeFileSync(join(dir, '.gitignore'), '.env\n');
+    git(['add', '-A'], dir);
+    git(['commit', '-m', 'init'], dir);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('keeps the last result for each file and lists what was skipped', () => {
+    const context = migration({
+      findings: [
+        makeFinding({ file: 'auth.ts' }),
+        makeFinding({ file: 'routes.ts' }),
+        makeFinding({ file: 'db.ts', outOfScope: true }),
+      ],
+      excludedFiles: ['legacy.ts'],
+    });
+    const recorder = new MigrationStepRecorder(emitter, dir, context);
+
+    emitter.emit('file:result', { path: join(dir, 'auth.ts'), status: 'failed', reason: 'String not found' });
+    emitter.emit('file:result', { path: join(dir, 'auth.ts'), status: 'succeeded' });
+    emitter.emit('file:result', { path: 'callback.ts', status: 'succeeded' });
+
+    expect(recorder.breakdown()).toEqual({
+      steps: [
+        { file: 'auth.ts', status: 'succeeded' },
+        { file: 'callback.ts', status: 'succeeded' },
+        { file: 'legacy.ts', status: 'skipped', reason: 'excluded from the migration' },
+      ],
+      succeeded: 2,
+      failed: 0,
+      skipped: 1,
+      partial: false,
+    });
+    expect(recorder.breakdown('Agent failed').steps).toContainEqual({
+      file: 'routes.ts',
+      status: 'skipped',
+      reason: 'not reached before the run failed',
+    });
+    expect(recorder.breakdown('Agent failed').partial).toBe(true);
+  });
+
+  it('counts files an earlier run changed when resuming it', () => {
+    const context = migration({
+      previousSteps: [
+        { file: 'auth.ts', status: 'succeeded' },
+        { file: 'routes.ts', status: 'failed', reason: 'String not found' },
+      ],
+    });
+    const recorder = new MigrationStepRecorder(emitter, dir, context);
+
+    emitter.emit('file:result', { path: join(dir, 'routes.ts'), status: 'failed', reason: 'File not read yet' });
+
+    const breakdown = recorder.breakdown();
+    expect(breakdown.steps).toEqual([
+      { file: 'routes.ts', status: 'failed', reason: 'File not read yet' },
+      { file: 'auth.ts', status: 'succeeded', reason: 'changed by an earlier run' },
+    ]);
+    expect(breakdown.partial).toBe(true);
+  });
+
+  it('rolls back what the run changed and keeps what git has no copy of', () => {
+    writeFileSync(join(dir, 'wip.ts'), 'uncommitted\n');
+    writeFileSync(join(dir, '.env'), 'AUTH0_DOMAIN=acme\n');
+    const recorder = new MigrationStepRecorder(emitter, dir, migration());
+
+    for (const [file, content] of [
+      ['auth.ts', 'authkit\n'],
+      ['callback.ts', 'callback\n'],
+      ['wip.ts', 'authkit\n'],
+      ['.env', 'WORKOS_CLIENT_ID=client_123\n'],
+    ]) {
+      writeFileSync(join(dir, file), content);
+      emitter.emit('file:result', { path: join(dir, file), status: 'succeeded' });
+    }
+    emitter.emit('file:result', { path: join(dir, 'routes.ts'), status: 'failed', reason: 'String not found' });
+
+    expect(recorder.canRollback()).toBe(true);
+    expect(recorder.rollback()).toEqual({
+      restored: ['auth.ts'],
+      removed: ['callback.ts'],
+      kept: [
+        { file: 'wip.ts', reason: 'had uncommitted changes before the run' },
+        { file: '.env', reason: 'ignored by git, so there is no earlier version to restore' },
+      ],
+    });
+    expect(readFileSync(join(dir, 'auth.ts'), 'utf-8')).toBe('auth0\n');
+    expect(existsSync(join(dir, 'callback.ts'))).toBe(false);
+    expect(readFileSync(join(dir, 'wip.ts'), 'utf-8')).toBe('authkit\n');
+  });
+
+  it('cannot roll back outside a git repository', () => {
+    rmSync(join(dir, '.git'), { recursive: true, force: true });
+    const recorder = new MigrationStepRecorder(emitter, dir, migration());
+
+    emitter.emit('file:result', { path: join(dir, 'auth.ts'), status: 'succeeded' });
+
+    expect(recorder.canRollback()).toBe(false);
+    expect(recorder.rollback()).toEqual({ restored: [], removed: [], kept: [] });
+    expect(existsSync(join(dir, 'auth.ts'))).toBe(true);
+  });
+
+  it('groups the breakdown by outcome', () => {
+    expect(
+      formatStepBreakdown([
+        { file: 'auth.ts', status: 'succeeded' },
+        { file: 'routes.ts', status: 'failed', reason: 'String not found' },
+        { file: 'legacy.ts', status: 'skipped', reason: 'excluded from the migration' },
+      ]),
+    ).toBe(
+      'Changed (1):\n  auth.ts\nFailed (1):\n  routes.ts — String not found\n' +
+        'Skipped (1):\n  legacy.ts — excluded from the migration',
+    );
+  });
+});
@@ -0,0 +1,182 @@
+/**
+ * Per-file outcome of a migration, so a run where some edits landed and
+ * others didn't says which is which instead of ending on a generic error.
+ *
+ * The agent's Write/Edit results arrive as `file:result` events. The last
+ * result for a file decides its outcome, since the agent often retries an
+ * edit that failed. Excluded files, and files with findings a failed run
+ * never reached, are listed as skipped. When the run resumes a partly
+ * applied or cancelled one, the files that landed then are counted too.
+ *
+ * A partly applied run can be rolled back: files that were clean before the
+ * run are restored from git and files it created are removed. Files that
+ * already had uncommitted changes are kept, since git has no copy of them.
+ */
+
+import { execFileSync } from 'node:child_process';
+import { realpathSync, rmSync } from 'node:fs';
+import { isAbsolute, join } from 'node:path';
+import type { InstallerEventEmitter } from './events.js';
+import type { MigrationContext, MigrationStep } from '../migrate/types.js';
+import { relativePosix } from '../utils/paths.js';
+
+export interface StepBreakdown {
+  steps: MigrationStep[];
+  succeeded: number;
+  failed: number;
+  skipped: number;
+  /** Some files changed and others didn't: the project is half migrated */
+  partial: boolean;
+}
+
+export interface RollbackResult {
+  restored: string[];
+  removed: string[];
+  kept: Array<{ file: string; reason: string }>;
+}
+
+function git(args: string[], cwd: string): string {
+  return execFileSync('git', args, { cwd, stdio: ['ignore', 'pipe', 'ignore'] }).toString();
+}
+
+function succeeds(args: string[], cwd: string): boolean {
+  try {
+    git(args, cwd);
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+/** Uncommitted and untracked files as absolute paths, or null outside a git repo */
+export function dirtyFiles(cwd: string): Set<string> | null {
+  let top: string;
+  let status: string;
+  try {
+    top = git(['rev-parse', '--show-toplevel'], cwd).trim();
+    status = git(['status', '--porcelain', '-z', '--untracked-files=all'], cwd);
+  } catch {
+    return null;
+  }
+  const files = new Set<string>();
+  const entries = status.split('\0');
+  for (let i = 0; i < entries.length; i++) {
+    const entry = entries[i];
+    if (entry.length < 4) continue;
+    files.add(join(top, entry.slice(3)));
+    // Renames and copies are followed by the original path
+    if (/^[RC]/.test(entry)) files.add(join(top, entries[++i]));
+  }
+  return files;
+}
+
+/**
+ * Collects the outcome of each file for one migration run.
+ */
+export class MigrationStepRecorder {
+  private results = new Map<string, MigrationStep>();
+  /** Dirty files before the run; null outside a git repo, where nothing can be rolled back */
+  private baseline: Set<string> | null;
+
+  constructor(
+    emitter: InstallerEventEmitter,
+    private installDir: string,
+    private migration: MigrationContext,
+  ) {
+    this.baseline = dirtyFiles(installDir);
+    emitter.on('file:result', ({ path, status, reason }) => {
+      const file = relativePosix(installDir, isAbsolute(path) ? path : join(installDir, path));
+      this.results.set(file, { file, status, ...(reason && { reason }) });
+    });
+  }
+
+  /** Whether the files the run changed can be put back */
+  canRollback(): boolean {
+    return this.baseline !== null;
+  }
+
+  /**
+   * Every file the migration set out to change, with its outcome.
+   * @param runError Why the run itself failed, if it did
+   * @param unreached Why files with findings the run never got to are skipped
+   */
+  breakdown(runError?: string, unreached = 'not reached before the run failed'): StepBreakdown {
+    const steps = [...this.results.values()];
+    const seen = new Set(steps.map((step) => step.file));
+    const add = (step: MigrationStep) => {
+      if (seen.has(step.file)) return;
+      seen.add(step.file);
+      steps.push(step);
+    };
+    const skip = (file: string, reason: string) => add({ file, status: 'skipped', reason });
+    // A resumed run only touches what the last one left, so what landed then still counts
+    for (const step of this.migration.previousSteps ?? []) {
+      if (step.status !== 'succeeded') continue;
+      add({ file: step.file, status: 'succeeded', reason: 'changed by an earlier run' });
+    }
+    for (const file of this.migration.excludedFiles ?? []) skip(file, 'excluded from the migration');
+    // After a successful run, findings the agent left alone were its call
+    if (runError) {
+      for (const finding of this.migration.findings.filter((f) => !f.outOfScope)) {
+        skip(finding.file, unreached);
+      }
+    }
+
+    const count = (status: MigrationStep['status']) => steps.filter((step) => step.status === status).length;
+    const succeeded = count('succeeded');
+    const failed = count('failed');
+    return {
+      steps,
+      succeeded,
+      failed,
+      skipped: count('skipped'),
+      partial: succeeded > 0 && (failed > 0 || Boolean(runError)),
+    };
+  }
+
+  /**
+   * Undo the files the run changed.
+   */
+  rollback(): RollbackResult {
+    const result: RollbackResult = { restored: [], removed: [], kept: [] };
+    const baseline = this.baseline;
+    if (!baseline) return result;
+
+    // git reports paths under the real directory, so compare against that
+    const root = realpathSync(this.installDir);
+    for (const { file } of [...this.results.values()].filter((step) => step.status === 'succeeded')) {
+      const path = join(root, file);
+      if (baseline.has(path)) {
+        result.kept.push({ file, reason: 'had uncommitted changes before the run' });
+      } else if (succeeds(['ls-files', '--error-unmatch', '--', path], this.installDir)) {
+        git(['checkout', '--', path], this.installDir);
+        result.restored.push(file);
+      } else if (succeeds(['check-ignore', '-q', '--', path], this.installDir)) {
+        // Ignored files never show up as untracked, so it may have existed before the run
+        result.kept.push({ file, reason: 'ignored by git, so there is no earlier version to restore' });
+      } else {
+        rmSync(path, { force: true });
+        result.removed.push(file);
+      }
+    }
+    return result;
+  }
+}
+
+const STATUS_LABELS: Record<MigrationStep['status'], string> = {
+  succeeded: 'Changed',
+  failed: 'Failed',
+  skipped: 'Skipped',
+};
+
+/** Steps grouped by outcome, one file per line with why it failed or was skipped */
+export function formatStepBreakdown(steps: MigrationStep[]): string {
+  const sections: string[] = [];
+  for (const status of ['succeeded', 'failed', 'skipped'] as const) {
+    const group = steps.filter((step) => step.status === status);
+    if (group.length === 0) continue;
+    const lines = group.map((step) => `  ${step.file}${step.reason ? ` — ${step.reason}` : ''}`);
+    sections.push(`${STATUS_LABELS[status]} (${group.length}):\n${lines.join('\n')}`);
+  }
+  return sections.join('\n');
+}
@@ -0,0 +1,78 @@
+import { describe, it, expect } from 'vitest';
+import { childArgs, describeEvent, ModuleProgress, parseAgentConcurrency } from './module-runner.js';
+
+describe('module-runner', () => {
+  it('replaces the per-module options in the forwarded arguments', () => {
+    const args = childArgs(
+      ['migrate', '--modules', 'apps/web', 'apps/api', '--yes', '--install-dir=.', '--events', 'ndjson', '--no-ci'],
+      { name: 'apps/web', dir: '/repo/apps/web' },
+    );
+
+    expect(args).toEqual([
+      'migrate',
+      '--yes',
+      '--install-dir',
+      '/repo/apps/web',
+      '--events',
+      'ndjson',
+      '--events-module',
+      'apps/web',
+      '--ci',
+    ]);
+  });
+
+  it('keeps --agent-concurrency to the parent', () => {
+    const args = childArgs(['migrate', '--modules', 'a', 'b', '--agent-concurrency', '2', '--yes'], {
+      name: 'a',
+      dir: '/repo/a',
+    });
+
+    expect(args.slice(0, 2)).toEqual(['migrate', '--yes']);
+  });
+
+  it('bounds --agent-concurrency', () => {
+    expect(parseAgentConcurrency(undefined)).toBe(3);
+    expect(parseAgentConcurrency(2.5)).toBe(2);
+    expect(parseAgentConcurrency(50)).toBe(8);
+    expect(() => parseAgentConcurrency(0)).toThrow('Use a number from 1 to 8');
+  });
+
+  it('describes the events worth showing', () => {
+    const record = { module: 'api', time: '2026-01-01T00:00:00.000Z' };
+
+    expect(describeEvent({ ...record, event: 'agent:progress', data: { step: 'Edit', detail: 'app.ts' } })).toBe(
+      'Edit: app.ts',
+    );
+    expect(describeEvent({ ...record, event: 'state:enter', data: { state: 'runAgent' } })).toBeNull();
+  });
+
+  it('prints whole prefixed lines and drops repeats', () => {
+    let output = '';
+    const progress = new ModuleProgress(['apps/web', 'api'], { grouped: false, write: (text) => (output += text) });
+
+    progress.update('api', 'running', 'Running AI agent');
+    progress.update('api', 'running', 'Running AI agent');
+    progress.update('apps/web', 'failed', 'Agent failed');
+
+    const lines = output.split('\n').filter(Boolean);
+    expect(lines).toHaveLength(2);
+    expect(lines[0]).toMatch(/^.*\[.*api {5}.*\].* Running AI agent$/);
+    expect(lines[1]).toContain('Agent failed');
+  });
+
+  it('redraws one line per module in place', () => {
+    const frames: string[] = [];
+    const progress = new ModuleProgress(['web', 'api'], {
+      grouped: true,
+      columns: 30,
+      write: (text) => frames.push(text),
+    });
+
+    progress.update('api', 'running', 'A step description too long for the terminal');
+
+    expect(frames).toHaveLength(2);
+    expect(frames[1].startsWith('\x1b[2F')).toBe(true);
+    expect(frames[1].split('\n').filter(Boolean)).toHaveLength(2);
+    expect(frames[1]).toContain('…');
+  });
+});
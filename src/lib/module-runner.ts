@@ -0,0 +1,376 @@
+/**
+ * Running one command against several modules (project directories) at
+ * once, e.g. `workos migrate --modules apps/web apps/api`.
+ *
+ * Each module runs in its own CLI process with `--events ndjson`, so the
+ * installers never share a terminal, a working directory or prompt state.
+ * The parent is the only writer to the terminal: it renders a grouped view
+ * with one live line per module on a TTY, and whole prefixed lines
+ * otherwise; under `--events ndjson` it passes the children's event lines
+ * through, each tagged with its module.
+ *
+ * `--agent-concurrency` bounds how many modules (and so agents) run at once.
+ * The children share one rate-limit backoff (see agent-rate-limit.ts), and
+ * no queued module starts while it holds the agents back. When the signal
+ * aborts, no new module starts and each running child gets the signal
+ * once: it stops its agent, writes its own checkpoint and exits. The
+ * children run in process groups of their own, so the terminal's Ctrl-C
+ * reaches them only through the parent. The modules that finished are
+ * checkpointed, and running the same command again skips them.
+ */
+
+import { spawn, type ChildProcess } from 'node:child_process';
+import { randomBytes } from 'node:crypto';
+import { existsSync, mkdirSync, readFileSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createInterface } from 'node:readline';
+import chalk from 'chalk';
+import { AGENT_BACKOFF_FILE_ENV, agentBackoffDelay } from './agent-rate-limit.js';
+import { writeFileAtomic } from './atomic-write.js';
+import { parseEventRecord, writeEventRecord, type EventRecord } from './event-stream.js';
+import { checkpointPath, EXIT_CODE_CANCELLED, EXIT_CODE_TERMINATED, type StopReason } from './interrupt.js';
+import { hasCursorControl } from '../utils/clack.js';
+
+/** Agent runs are heavy; more than this at once mostly competes for the same machine */
+export const DEFAULT_AGENT_CONCURRENCY = 3;
+export const MAX_AGENT_CONCURRENCY = 8;
+
+/** How often a module waiting out a rate limit checks whether it's over, or the run was cancelled */
+const BACKOFF_POLL_MS = 1_000;
+
+/** stderr lines kept per module to show when it fails */
+const STDERR_TAIL_LINES = 20;
+
+/** Options the runner sets for each child, dropped from the forwarded arguments */
+const CHILD_OPTIONS = ['modules', 'install-dir', 'events', 'events-module', 'ci', 'agent-concurrency'];
+
+const COLORS = [chalk.cyan, chalk.magenta, chalk.yellow, chalk.blue, chalk.green, chalk.red];
+
+export interface ModuleRun {
+  /** Name shown and put on events, the directory relative to where the command ran */
+  name: string;
+  dir: string;
+}
+
+export interface ModuleResult {
+  module: string;
+  success: boolean;
+  /** Stopped by the signal, or never started because of it */
+  cancelled?: boolean;
+  /** Finished in an earlier, cancelled run, per the checkpoint */
+  skipped?: boolean;
+  /** Last step or error, for the closing summary */
+  detail: string;
+  stderr: string[];
+}
+
+/** `--agent-concurrency`: how many modules, each with its own agent, run at once */
+export function parseAgentConcurrency(value: number | undefined): number {
+  if (value === undefined) return DEFAULT_AGENT_CONCURRENCY;
+  if (!Number.isFinite(value) || value < 1) {
+    throw new Error(`Invalid --agent-concurrency ${value}. Use a number from 1 to ${MAX_AGENT_CONCURRENCY}.`);
+  }
+  return Math.min(MAX_AGENT_CONCURRENCY, Math.floor(value));
+}
+
+/** A module's latest step, as one line of text; null for events not worth showing */
+export function describeEvent(record: EventRecord): string | null {
+  const data = (record.data ?? {}) as Record<string, unknown>;
+  switch (record.event) {
+    case 'status':
+      return String(data.message);
+    case 'detection:complete':
+      return `Detected ${String(data.integration)}`;
+    case 'config:complete':
+      return 'Environment configured';
+    case 'agent:start':
+      return 'Running AI agent';
+    case 'agent:progress':
+      return data.detail ? `${String(data.step)}: ${String(data.detail)}` : String(data.step);
+    case 'validation:start':
+      return 'Validating the build';
+    case 'verification:start':
+      return `Running ${String(data.checks)} verification check(s)`;
+    case 'hook:start':
+      return `Running ${String(data.phase)} hook: ${String(data.command)}`;
+    case 'complete':
+      return data.success ? 'Done' : 'Failed';
+    case 'error':
+    case 'agent:failure':
+      return String(data.message);
+    default:
+      return null;
+  }
+}
+
+/**
+ * The arguments a child gets: the parent's own, minus the options set per
+ * child (with their values), plus that module's directory.
+ */
+export function childArgs(args: string[], module: ModuleRun): string[] {
+  const forwarded: string[] = [];
+  for (let i = 0; i < args.length; i++) {
+    const match = /^--(?:no-)?([^=]+)(=.*)?$/.exec(args[i]);
+    if (!match || !CHILD_OPTIONS.includes(match[1])) {
+      forwarded.push(args[i]);
+      continue;
+    }
+    // Skip the option's values: `--modules a b`, not `--modules=a`
+    while (!match[2] && i + 1 < args.length && !args[i + 1].startsWith('-')) i++;
+  }
+  return [...forwarded, '--install-dir', module.dir, '--events', 'ndjson', '--events-module', module.name, '--ci'];
+}
+
+export type ModuleState = 'queued' | 'running' | 'done' | 'failed' | 'cancelled';
+
+interface ModuleLine {
+  state: ModuleState;
+  step: string;
+}
+
+const STATE_SYMBOLS: Record<ModuleState, string> = {
+  queued: chalk.dim('·'),
+  running: chalk.cyan('◒'),
+  done: chalk.green('✔'),
+  failed: chalk.red('✖'),
+  cancelled: chalk.yellow('■'),
+};
+
+/**
+ * The only thing writing to the terminal while modules run. Grouped mode
+ * redraws one line per module in place; line mode prints each update as a
+ * whole prefixed line, so concurrent modules never split each other's output.
+ */
+export class ModuleProgress {
+  private lines = new Map<string, ModuleLine>();
+  private drawn = 0;
+  private readonly width: number;
+
+  constructor(
+    modules: string[],
+    private readonly options: { grouped: boolean; write?: (text: string) => void; columns?: number },
+  ) {
+    this.width = Math.max(...modules.map((m) => m.length));
+    modules.forEach((m) => this.lines.set(m, { state: 'queued', step: 'Waiting' }));
+    if (options.grouped) this.draw();
+  }
+
+  private write(text: string): void {
+    (this.options.write ?? ((t: string) => process.stdout.write(t)))(text);
+  }
+
+  private label(module: string): string {
+    const index = [...this.lines.keys()].indexOf(module);
+    return COLORS[index % COLORS.length](module.padEnd(this.width));
+  }
+
+  update(module: string, state: ModuleState, step: string): void {
+    const line = this.lines.get(module);
+    if (!line || (line.state === state && line.step === step)) return;
+    line.state = state;
+    line.step = step;
+    if (this.options.grouped) {
+      this.draw();
+    } else {
+      this.write(`${chalk.dim('[')}${this.label(module)}${chalk.dim(']')} ${STATE_SYMBOLS[state]} ${step}\n`);
+    }
+  }
+
+  private draw(): void {
+    const columns = this.options.columns ?? process.stdout.columns ?? 80;
+    let frame = this.drawn > 0 ? `\x1b[${this.drawn}F` : '';
+    for (const [module, { state, step }] of this.lines) {
+      // Long steps are cut so no line wraps and throws off the redraw
+      const room = Math.max(columns - this.width - 6, 10);
+      const text = step.length > room ? `${step.slice(0, room - 1)}…` : step;
+      frame += `\x1b[2K  ${this.label(module)}  ${STATE_SYMBOLS[state]} ${text}\n`;
+    }
+    this.drawn = this.lines.size;
+    this.write(frame);
+  }
+}
+
+export interface RunModulesOptions {
+  /** The parent's arguments after the executable and script, e.g. process.argv.slice(2) */
+  args: string[];
+  /** Extra environment for the children, e.g. credentials kept off the command line */
+  env?: Record<string, string>;
+  /** Pass event lines through instead of rendering progress */
+  events?: boolean;
+  /** Modules running at once, from --agent-concurrency */
+  concurrency?: number;
+  /** Stops the run; its reason, a StopReason, picks the signal the children get */
+  signal?: AbortSignal;
+}
+
+interface ModuleCheckpoint {
+  /** Directories of the modules finished before the cancel */
+  completed: string[];
+  reason?: StopReason;
+  cancelledAt: string;
+}
+
+/** The checkpoint of a cancelled `--modules` run, one per set of modules */
+export function moduleCheckpointPath(modules: ModuleRun[]): string {
+  return checkpointPath(`modules:${modules.map((m) => m.dir).sort().join('\n')}`);
+}
+
+function readModuleCheckpoint(path: string): Set<string> {
+  if (!existsSync(path)) return new Set();
+  try {
+    const { completed } = JSON.parse(readFileSync(path, 'utf-8')) as Partial<ModuleCheckpoint>;
+    return new Set(Array.isArray(completed) ? completed.filter((dir): dir is string => typeof dir === 'string') : []);
+  } catch {
+    return new Set();
+  }
+}
+
+/** The signal a child gets when the run stops: a timeout stops it the way SIGTERM would */
+function childSignal(signal: AbortSignal): NodeJS.Signals {
+  return signal.reason === 'interrupt' ? 'SIGINT' : 'SIGTERM';
+}
+
+function runModule(
+  module: ModuleRun,
+  options: RunModulesOptions & { running: Set<ChildProcess> },
+  progress: ModuleProgress | null,
+) {
+  return new Promise<ModuleResult>((resolve) => {
+    const child = spawn(process.execPath, [...process.execArgv, process.argv[1], ...childArgs(options.args, module)], {
+      env: { ...process.env, ...options.env },
+      stdio: ['ignore', 'pipe', 'pipe'],
+      // Own group: a Ctrl-C reaches the child once, through the parent
+      detached: process.platform !== 'win32',
+    });
+    options.running.add(child);
+    const result: ModuleResult = { module: module.name, success: false, detail: 'Exited early', stderr: [] };
+    progress?.update(module.name, 'running', 'Starting');
+
+    const onAbort = () => {
+      progress?.update(module.name, 'running', 'Stopping the agent');
+      child.kill(childSignal(options.signal!));
+    };
+    options.signal?.addEventListener('abort', onAbort, { once: true });
+
+    createInterface({ input: child.stdout! }).on('line', (line) => {
+      const record = parseEventRecord(line);
+      if (!record) return;
+      if (options.events) {
+        writeEventRecord({ ...record, module: module.name });
+      }
+      // The final state comes from the exit code; a failed run keeps its last error as the detail
+      const step = record.event === 'complete' ? null : describeEvent(record);
+      if (step) {
+        result.detail = step;
+        progress?.update(module.name, 'running', step);
+      }
+    });
+    createInterface({ input: child.stderr! }).on('line', (line) => {
+      result.stderr.push(line);
+      if (result.stderr.length > STDERR_TAIL_LINES) result.stderr.shift();
+    });
+
+    child.on('error', (error) => {
+      result.detail = error.message;
+    });
+    child.on('close', (code) => {
+      options.running.delete(child);
+      options.signal?.removeEventListener('abort', onAbort);
+      result.success = code === 0;
+      result.cancelled = code === EXIT_CODE_CANCELLED || code === EXIT_CODE_TERMINATED;
+      if (result.success) result.detail = 'Done';
+      else if (result.cancelled) result.detail = `Cancelled during: ${result.detail}`;
+      progress?.update(module.name, result.success ? 'done' : result.cancelled ? 'cancelled' : 'failed', result.detail);
+      resolve(result);
+    });
+  });
+}
+
+/** Wait while the children's agents are rate limited; false when the run was cancelled meanwhile */
+async function waitOutBackoff(file: string, signal: AbortSignal | undefined, onWait: () => void): Promise<boolean> {
+  let waited = false;
+  while (!signal?.aborted) {
+    const delay = agentBackoffDelay(Date.now(), file);
+    if (delay <= 0) return true;
+    if (!waited) onWait();
+    waited = true;
+    await new Promise((resolve) => setTimeout(resolve, Math.min(delay, BACKOFF_POLL_MS)));
+  }
+  return false;
+}
+
+/**
+ * Run every module, at most `concurrency` at a time; results are in module
+ * order. Resolves once every started module has exited.
+ */
+export async function runModules(modules: ModuleRun[], options: RunModulesOptions): Promise<ModuleResult[]> {
+  const checkpoint = moduleCheckpointPath(modules);
+  const completed = readModuleCheckpoint(checkpoint);
+  const progress = options.events
+    ? null
+    : new ModuleProgress(modules.map((m) => m.name), {
+        grouped: hasCursorControl(),
+      });
+  const results: (ModuleResult | undefined)[] = new Array(modules.length);
+  const pending: number[] = [];
+  modules.forEach((m, index) => {
+    if (!completed.has(m.dir)) {
+      pending.push(index);
+      return;
+    }
+    results[index] = { module: m.name, success: true, skipped: true, detail: 'Migrated before the cancel', stderr: [] };
+    progress?.update(m.name, 'done', 'Migrated before the cancel');
+  });
+
+  const backoffFile = join(tmpdir(), `workos-agent-backoff-${process.pid}-${randomBytes(4).toString('hex')}.json`);
+  const running = new Set<ChildProcess>();
+  const childOptions = { ...options, env: { ...options.env, [AGENT_BACKOFF_FILE_ENV]: backoffFile }, running };
+  // A second Ctrl-C exits the parent at once; the children get it too, so they don't outlive it
+  const onExit = () => running.forEach((child) => child.kill(childSignal(options.signal!)));
+  if (options.signal) process.on('exit', onExit);
+
+  let next = 0;
+  const worker = async () => {
+    while (next < pending.length && !options.signal?.aborted) {
+      const index = pending[next++];
+      const module = modules[index];
+      const clear = await waitOutBackoff(backoffFile, options.signal, () =>
+        progress?.update(module.name, 'queued', 'Waiting: agents are rate limited'),
+      );
+      if (!clear) break;
+      const result = await runModule(module, childOptions, progress);
+      results[index] = result;
+      if (result.success) completed.add(module.dir);
+    }
+  };
+  const concurrency = Math.min(parseAgentConcurrency(options.concurrency), pending.length);
+  try {
+    await Promise.all(Array.from({ length: concurrency }, worker));
+  } finally {
+    process.off('exit', onExit);
+    rmSync(backoffFile, { force: true });
+  }
+
+  const notStarted = (m: ModuleRun): ModuleResult => {
+    progress?.update(m.name, 'cancelled', 'Not started');
+    return { module: m.name, success: false, cancelled: true, detail: 'Not started', stderr: [] };
+  };
+  const finished = modules.map((m, index) => results[index] ?? notStarted(m));
+  if (options.signal?.aborted) {
+    const saved: ModuleCheckpoint = {
+      completed: [...completed],
+      reason: options.signal.reason as StopReason,
+      cancelledAt: new Date().toISOString(),
+    };
+    try {
+      mkdirSync(dirname(checkpoint), { recursive: true });
+      writeFileAtomic(checkpoint, JSON.stringify(saved, null, 2));
+    } catch {
+      // Without it the next run migrates every module again
+    }
+  } else {
+    rmSync(checkpoint, { force: true });
+  }
+  return finished;
+}
@@ -0,0 +1,92 @@
+import { describe, it, expect } from 'vitest';
+import { buildAssessment } from '../migrate/assessment.js';
+import { errorJson } from '../utils/errors.js';
+import { buildRunSummary, formatRunSummary } from '../utils/run-summary.js';
+import { eventRecord } from './event-stream.js';
+import { EVENT_NAMES, OUTPUT_SCHEMA_VERSION, outputSchema, type JsonSchema } from './output-schema.js';
+
+/**
+ * Fields of `value` the schema doesn't describe, and required fields it's
+ * missing. Stricter than the schemas themselves, which allow new fields, so
+ * that an output field added without a schema change fails here.
+ */
+function drift(schema: JsonSchema, value: unknown, path = '$'): string[] {
+  if (Array.isArray(schema.anyOf)) {
+    const options = (schema.anyOf as JsonSchema[]).map((option) => drift(option, value, path));
+    return options.find((problems) => problems.length === 0) ?? options[0];
+  }
+  if (schema.type === 'null') return value === null ? [] : [`${path}: expected null`];
+  if (schema.type === 'array') {
+    if (!Array.isArray(value)) return [`${path}: expected an array`];
+    return value.flatMap((item, i) => drift(schema.items as JsonSchema, item, `${path}[${i}]`));
+  }
+  if (schema.type !== 'object') return [];
+  if (typeof value !== 'object' || value === null) return [`${path}: expected an object`];
+  const properties = (schema.properties ?? {}) as Record<string, JsonSchema>;
+  const required = (schema.required ?? []) as string[];
+  return [
+    ...required.filter((key) => !(key in value)).map((key) => `${path}.${key}: missing`),
+    ...Object.entries(value).flatMap(([key, field]) =>
+      key in properties ? drift(properties[key], field, `${path}.${key}`) : [`${path}.${key}: not in the schema`],
+    ),
+  ];
+}
+
+function eventData(name: string): JsonSchema {
+  const branches = outputSchema('events').allOf as Array<{ if: JsonSchema; then: JsonSchema }>;
+  const branch = branches.find((b) => (b.if.properties as Record<string, JsonSchema>).event.const === name)!;
+  return (branch.then.properties as Record<string, JsonSchema>).data;
+}
+
+describe('output-schema', () => {
+  it('versions every schema with the schemaVersion the CLI writes', () => {
+    for (const name of ['plan', 'events', 'result'] as const) {
+      expect(outputSchema(name).$id).toBe(`urn:workos-cli:schema:${name}:${OUTPUT_SCHEMA_VERSION}`);
+    }
+    expect(eventRecord('agent:start', {}, '.').schemaVersion).toBe(OUTPUT_SCHEMA_VERSION);
+  });
+
+  it('describes the run summary and the error result', () => {
+    const [summary, error] = outputSchema('result').oneOf as JsonSchema[];
+    const output = JSON.parse(
+      formatRunSummary(buildRunSummary({ success: true, changedFiles: ['src/auth.ts'], summary: 'Done!' }), 'json'),
+    );
+
+    expect(drift(summary, output)).toEqual([]);
+    expect(drift(error, errorJson(new Error('Boom')))).toEqual([]);
+  });
+
+  it('describes the assessment', () => {
+    const assessment = buildAssessment({
+      root: '/project',
+      matches: [
+        {
+          provider: 'auth0',
+          confidence: 0.8,
+          findings: [
+            {
+              provider: 'auth0',
+              code: 'auth0-env',
+              severity: 'info',
+              message: 'Auth0 env var',
+              file: '.env',
+              confidence: 0.5,
+              details: { envVar: 'AUTH0_CLIENT_ID' },
+            },
+          ],
+        },
+      ],
+    });
+
+    expect(drift(outputSchema('plan'), { schemaVersion: OUTPUT_SCHEMA_VERSION, ...assessment })).toEqual([]);
+  });
+
+  it('describes event records and their data', () => {
+    const record = eventRecord('file:write', { path: 'app/page.tsx', content: 'export default …' }, 'apps/web');
+
+    expect(drift(outputSchema('events'), record)).toEqual([]);
+    expect(drift(eventData('file:write'), record.data)).toEqual([]);
+    expect(drift(eventData('error'), eventRecord('error', { message: 'Boom' }, '.').data)).toEqual([]);
+    expect(EVENT_NAMES).toContain('postinstall:manual');
+  });
+});
@@ -0,0 +1,371 @@
+/**
+ * JSON Schemas for the CLI's machine-readable output, printed by
+ * `workos schema <name>`:
+ *
+ * - `plan`: `workos migrate assess --json` and its `.json` report
+ * - `events`: each line of `--events ndjson`
+ * - `result`: the final `--json` object of `install` and `migrate`, a run
+ *   summary or an error
+ *
+ * Every one of them carries `schemaVersion`. New fields can appear without
+ * a bump, so validators should allow unknown properties; removing or
+ * changing a field bumps OUTPUT_SCHEMA_VERSION and every schema with it.
+ */
+
+import type { InstallerEventName } from './events.js';
+
+export const OUTPUT_SCHEMA_VERSION = 1;
+
+export const SCHEMA_NAMES = ['plan', 'events', 'result'] as const;
+export type SchemaName = (typeof SCHEMA_NAMES)[number];
+
+export const SCHEMA_DESCRIPTIONS: Record<SchemaName, string> = {
+  plan: 'workos migrate assess --json, and the .json assessment report',
+  events: 'Each line of --events ndjson',
+  result: 'The final --json object of install and migrate: a run summary, or an error',
+};
+
+export type JsonSchema = Record<string, unknown>;
+
+const string: JsonSchema = { type: 'string' };
+const number: JsonSchema = { type: 'number' };
+const integer: JsonSchema = { type: 'integer' };
+const boolean: JsonSchema = { type: 'boolean' };
+const dateTime: JsonSchema = { type: 'string', format: 'date-time' };
+
+function array(items: JsonSchema): JsonSchema {
+  return { type: 'array', items };
+}
+
+function nullable(schema: JsonSchema): JsonSchema {
+  return { anyOf: [schema, { type: 'null' }] };
+}
+
+function oneOf(...values: string[]): JsonSchema {
+  return { enum: values };
+}
+
+/** An object with these required and optional properties; others may be added in later versions */
+function object(required: Record<string, JsonSchema>, optional: Record<string, JsonSchema> = {}): JsonSchema {
+  return {
+    type: 'object',
+    properties: { ...required, ...optional },
+    ...(Object.keys(required).length > 0 && { required: Object.keys(required) }),
+  };
+}
+
+const strings = array(string);
+const empty = object({});
+const message = object({ message: string });
+const schemaVersion: JsonSchema = { const: OUTPUT_SCHEMA_VERSION };
+
+function document(name: SchemaName, title: string, schema: JsonSchema): JsonSchema {
+  return {
+    $schema: 'https://json-schema.org/draft/2020-12/schema',
+    $id: `urn:workos-cli:schema:${name}:${OUTPUT_SCHEMA_VERSION}`,
+    title,
+    ...schema,
+  };
+}
+
+// plan
+
+const callSiteClass = oneOf('auto', 'manual', 'no-equivalent');
+
+const apiUsage = object(
+  {
+    api: string,
+    code: string,
+    classification: callSiteClass,
+    count: integer,
+    locations: strings,
+    description: string,
+  },
+  { remediation: string },
+);
+
+const providerAssessment = object({
+  provider: string,
+  displayName: string,
+  confidence: number,
+  supported: boolean,
+  apis: array(apiUsage),
+  totals: object({ auto: integer, manual: integer, 'no-equivalent': integer }),
+  files: strings,
+  env: object({
+    rename: array(object({ from: string, to: string })),
+    remove: strings,
+    review: strings,
+    workos: strings,
+  }),
+  dashboard: strings,
+  outOfScope: integer,
+  templates: integer,
+  tests: integer,
+});
+
+const PLAN = document(
+  'plan',
+  'Migration assessment',
+  object({
+    schemaVersion,
+    root: string,
+    generatedAt: dateTime,
+    durationMs: number,
+    providers: array(providerAssessment),
+    warnings: strings,
+  }),
+);
+
+// events
+
+const tokenCounts = object({ input: number, output: number, cacheRead: number, cacheWrite: number });
+const migrationStep = object({ file: string, status: oneOf('succeeded', 'failed', 'skipped') }, { reason: string });
+const hookPhase = oneOf('pre', 'post');
+const verificationCheck = object(
+  { name: string, type: string, passed: boolean, durationMs: number },
+  { skipped: boolean, output: string },
+);
+const dashboardChange = object({
+  setting: oneOf('Redirect URI', 'CORS origin', 'Homepage URL'),
+  value: string,
+  created: boolean,
+});
+
+/**
+ * The `data` of each event. File contents (`content`, `oldContent`,
+ * `newContent`) are left out of the stream, and errors become `{ message }`.
+ */
+const EVENT_DATA: Record<InstallerEventName, JsonSchema> = {
+  status: message,
+  output: object({ text: string }, { isError: boolean }),
+  'file:write': object({ path: string }),
+  'file:edit': object({ path: string }),
+  'file:result': object({ path: string, status: oneOf('succeeded', 'failed', 'skipped') }, { reason: string }),
+  'change:diff': object({ path: string, diff: string }),
+  'change:summary': object({ text: string }),
+  'change:patch': object({ path: string, root: string, files: strings, redacted: strings }),
+  'backups:kept': object({ files: strings }),
+  'prompt:request': object({ id: string, message: string }, { options: strings }),
+  'prompt:response': object({ id: string, value: string }),
+  'confirm:request': object({ id: string, message: string }, { warning: string, files: strings }),
+  'confirm:response': object({ id: string, confirmed: boolean }),
+  'credentials:request': object({ requiresApiKey: boolean }),
+  'credentials:response': object({ apiKey: string, clientId: string }),
+  complete: object({ success: boolean }, { summary: string, changedFiles: strings, cancelled: boolean }),
+  cancelled: object(
+    { changedFiles: strings, checkpointPath: nullable(string) },
+    { reason: oneOf('interrupt', 'terminate', 'idle-timeout', 'timeout') },
+  ),
+  error: object({ message: string }, { stack: string }),
+  'state:enter': object({ state: string }),
+  'state:exit': object({ state: string }),
+  'auth:checking': empty,
+  'auth:required': empty,
+  'auth:success': empty,
+  'auth:failure': message,
+  'detection:start': empty,
+  'detection:complete': object({ integration: string }),
+  'detection:none': empty,
+  'git:checking': empty,
+  'git:clean': empty,
+  'git:dirty': object({ files: strings }),
+  'git:dirty:confirmed': empty,
+  'git:dirty:cancelled': empty,
+  'credentials:gathering': object({ requiresApiKey: boolean }),
+  'credentials:found': empty,
+  'credentials:env:detected': object({ files: strings }),
+  'credentials:env:prompt': object({ files: strings }),
+  'credentials:env:scanning': empty,
+  'credentials:env:found': object({ sourcePath: string }),
+  'credentials:env:notfound': empty,
+  'device:started': object({ verificationUri: string, verificationUriComplete: string, userCode: string }),
+  'device:polling': empty,
+  'device:success': object({}, { email: string }),
+  'device:timeout': empty,
+  'device:error': message,
+  'staging:fetching': empty,
+  'staging:success': empty,
+  'staging:error': object({ message: string }, { statusCode: integer }),
+  'config:start': empty,
+  'config:complete': empty,
+  'env:renamed': object({
+    keys: array(object({ file: string, from: string, to: string, reference: boolean }, { container: boolean })),
+    provider: string,
+  }),
+  'migration:steps': object(
+    { steps: array(migrationStep), succeeded: integer, failed: integer, skipped: integer, partial: boolean },
+    { statePath: string },
+  ),
+  'migration:rollback': object({
+    restored: strings,
+    removed: strings,
+    kept: array(object({ file: string, reason: string })),
+  }),
+  'dashboard:configured': object({ changes: array(dashboardChange) }),
+  'agent:start': empty,
+  'agent:progress': object({ step: string }, { detail: string }),
+  'agent:success': object({}, { summary: string }),
+  'agent:failure': object({ message: string }, { stack: string }),
+  'agent:retry': object({ attempt: integer, maxRetries: integer }),
+  'agent:estimate': object(
+    {
+      estimate: object({
+        files: integer,
+        calls: integer,
+        inputTokens: number,
+        outputTokens: number,
+        costUsd: nullable(number),
+        durationMs: number,
+      }),
+    },
+    { maxTokens: integer, warning: string },
+  ),
+  'agent:usage': object({
+    backend: string,
+    tokens: nullable(tokenCounts),
+    costUsd: nullable(number),
+    costEstimated: boolean,
+    durationMs: number,
+    retries: integer,
+  }),
+  'agent:timeout': object({ kind: oneOf('idle', 'total'), message: string }),
+  'hook:start': object({ phase: hookPhase, command: string }),
+  'hook:output': object({ phase: hookPhase, text: string }),
+  'hook:complete': object({
+    phase: hookPhase,
+    command: string,
+    exitCode: integer,
+    required: boolean,
+    durationMs: number,
+  }),
+  'validation:retry:start': object({ attempt: integer }),
+  'validation:retry:complete': object({ attempt: integer, passed: boolean }),
+  'validation:start': object({ framework: string }),
+  'validation:issues': object({
+    issues: array(
+      object(
+        { type: oneOf('package', 'env', 'file', 'pattern'), severity: oneOf('error', 'warning'), message: string },
+        { hint: string },
+      ),
+    ),
+  }),
+  'validation:complete': object({ passed: boolean, issueCount: integer, durationMs: number }),
+  'checks:formatted': object({ files: strings, tools: strings }, { missing: strings }),
+  'verification:start': object({ checks: integer, attempt: integer }),
+  'verification:check': verificationCheck,
+  'verification:complete': object({ passed: boolean, failed: strings, attempt: integer, durationMs: number }),
+  'verification:repair': object({ attempt: integer, maxAttempts: integer, failed: strings }),
+  'report:written': object({ path: string }),
+  'branch:checking': empty,
+  'branch:protected': object({ branch: string }),
+  'branch:prompt': object({ branch: string, newBranch: string, newBranchExists: boolean }, { reason: string }),
+  'branch:created': object({ branch: string }, { reused: boolean }),
+  'branch:create:failed': object({ error: string }),
+  'branch:skipped': empty,
+  'postinstall:changes': object({ files: strings }),
+  'postinstall:nochanges': empty,
+  'postinstall:commit:prompt': empty,
+  'postinstall:commit:blocked': object({ branch: string, reason: string }),
+  'postinstall:commit:generating': empty,
+  'postinstall:commit:committing': object({ message: string }),
+  'postinstall:commit:success': object({ message: string }),
+  'postinstall:commit:failed': object({ error: string }),
+  'postinstall:pr:prompt': empty,
+  'postinstall:pr:generating': empty,
+  'postinstall:pr:pushing': empty,
+  'postinstall:pr:creating': empty,
+  'postinstall:pr:success': object({ url: string }, { updated: boolean }),
+  'postinstall:pr:failed': object({ error: string }),
+  'postinstall:pr:skipped': object({ reason: string }),
+  'postinstall:push:success': object({ branch: string }),
+  'postinstall:push:failed': object({ error: string }),
+  'postinstall:manual': object({ instructions: string }),
+};
+
+/** Every event the installer can emit */
+export const EVENT_NAMES = Object.keys(EVENT_DATA) as InstallerEventName[];
+
+const EVENTS = document('events', 'Installer event record', {
+  ...object({
+    schemaVersion,
+    event: { enum: EVENT_NAMES },
+    module: string,
+    time: dateTime,
+    data: {},
+  }),
+  allOf: EVENT_NAMES.map((name) => ({
+    if: { properties: { event: { const: name } } },
+    then: { properties: { data: EVENT_DATA[name] } },
+  })),
+});
+
+// result
+
+const usage = object({
+  tokens: nullable(tokenCounts),
+  costUsd: nullable(number),
+  costEstimated: boolean,
+  durationMs: number,
+  agentRuns: integer,
+  repairAttempts: integer,
+});
+
+const runSummary = object(
+  {
+    schemaVersion,
+    success: boolean,
+    title: string,
+    changedFiles: strings,
+    excludedFiles: strings,
+    manualChanges: strings,
+    redirectUris: array(object({ uri: string }, { registered: boolean })),
+    scopes: array(
+      object({ scope: string, support: oneOf('supported', 'organization', 'roles', 'unsupported') }, { note: string }),
+    ),
+    claims: array(object({ claim: string, authkit: nullable(string) }, { note: string })),
+    testReferences: strings,
+    templateReferences: strings,
+    warnings: strings,
+    nextSteps: strings,
+    docsUrl: string,
+  },
+  {
+    message: string,
+    migratedFrom: string,
+    detection: object({
+      confidence: number,
+      forced: boolean,
+      evidence: array(object({ file: string, message: string, confidence: number }, { line: integer, envVar: string })),
+    }),
+    deferred: array(oneOf('code', 'env', 'deps', 'templates')),
+    usage,
+    files: object({ created: strings, modified: strings, deleted: strings }),
+    envVars: array(object({ file: string, keys: strings })),
+    dashboard: array(dashboardChange),
+    checks: array(verificationCheck),
+  },
+);
+
+const runError = object({
+  schemaVersion,
+  error: object({
+    code: string,
+    id: { type: 'string', pattern: '^WOS-[A-Z]+-\\d{3}$' },
+    exitCode: integer,
+    message: string,
+    hints: strings,
+  }),
+});
+
+const RESULT = document('result', 'Run result', { oneOf: [runSummary, runError] });
+
+const SCHEMAS: Record<SchemaName, JsonSchema> = { plan: PLAN, events: EVENTS, result: RESULT };
+
+export function isSchemaName(name: string): name is SchemaName {
+  return (SCHEMA_NAMES as readonly string[]).includes(name);
+}
+
+export function outputSchema(name: SchemaName): JsonSchema {
+  return SCHEMAS[name];
+}
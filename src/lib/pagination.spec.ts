@@ -0,0 +1,57 @@
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+
+vi.mock('./workos-api.js', () => ({ workosRequest: vi.fn() }));
+
+const { workosRequest } = await import('./workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+const { paginate } = await import('./pagination.js');
+
+function page(ids: string[], after: string | null = null) {
+  return { data: ids.map((id) => ({ id })), list_metadata: { before: null, after } };
+}
+
+async function collect(pages: AsyncGenerator<Array<{ id: string }>>): Promise<string[][]> {
+  const seen: string[][] = [];
+  for await (const items of pages) seen.push(items.map((item) => item.id));
+  return seen;
+}
+
+describe('paginate', () => {
+  beforeEach(() => {
+    mockRequest.mockReset();
+  });
+
+  it('follows after cursors until the list ends', async () => {
+    mockRequest.mockResolvedValueOnce(page(['a', 'b'], 'b')).mockResolvedValueOnce(page(['c']));
+
+    const pages = await collect(paginate({ path: '/organizations', apiKey: 'sk_test', params: { order: 'desc' } }));
+
+    expect(pages).toEqual([['a', 'b'], ['c']]);
+    expect(mockRequest).toHaveBeenLastCalledWith(
+      expect.objectContaining({ method: 'GET', params: expect.objectContaining({ after: 'b', order: 'desc' }) }),
+    );
+  });
+
+  it('caps the total and shrinks the last page request', async () => {
+    mockRequest.mockResolvedValue(page(Array.from({ length: 100 }, (_, i) => `id_${i}`), 'next'));
+
+    const pages = await collect(paginate({ path: '/organizations', apiKey: 'sk_test' }, { max: 150 }));
+
+    expect(pages.flat()).toHaveLength(150);
+    expect(mockRequest).toHaveBeenCalledTimes(2);
+    expect(mockRequest.mock.calls.map(([request]) => request.params?.limit)).toEqual([100, 50]);
+  });
+
+  it('starts from a given cursor and ignores before', async () => {
+    mockRequest.mockResolvedValueOnce(page([]));
+
+    const pages = await collect(
+      paginate({ path: '/organizations', apiKey: 'sk_test', params: { after: 'start', before: 'x' } }),
+    );
+
+    expect(pages).toEqual([]);
+    expect(mockRequest).toHaveBeenCalledWith(
+      expect.objectContaining({ params: expect.objectContaining({ after: 'start', before: undefined }) }),
+    );
+  });
+});
@@ -0,0 +1,51 @@
+/**
+ * Cursor pagination over WorkOS list endpoints.
+ */
+
+import { workosRequest, type WorkOSListResponse, type WorkOSRequestOptions } from './workos-api.js';
+
+/** Largest page the list endpoints accept */
+const MAX_PAGE_SIZE = 100;
+
+export interface PaginateOptions {
+  /** Stop after this many results in total */
+  max?: number;
+}
+
+/**
+ * Yield one page of results at a time, following `after` cursors until the
+ * list ends or `max` results have been returned. Pages are yielded as they
+ * arrive so callers can stream output instead of buffering everything.
+ */
+export async function* paginate<T>(
+  request: Omit<WorkOSRequestOptions, 'method'>,
+  options: PaginateOptions = {},
+): AsyncGenerator<T[]> {
+  let remaining = options.max ?? Infinity;
+  let after = request.params?.after;
+
+  while (remaining > 0) {
+    const page = await workosRequest<WorkOSListResponse<T>>({
+      ...request,
+      method: 'GET',
+      // Walking forward; a `before` cursor would make the API page backwards
+      params: { ...request.params, before: undefined, limit: Math.min(MAX_PAGE_SIZE, remaining), after },
+    });
+    const data = page.data.slice(0, remaining);
+    if (data.length > 0) yield data;
+    remaining -= data.length;
+
+    if (!page.list_metadata.after || page.data.length === 0) return;
+    after = page.list_metadata.after;
+  }
+}
+
+/** Every result across all pages, for lists small enough to hold at once */
+export async function listAll<T>(
+  request: Omit<WorkOSRequestOptions, 'method'>,
+  options: PaginateOptions = {},
+): Promise<T[]> {
+  const all: T[] = [];
+  for await (const page of paginate<T>(request, options)) all.push(...page);
+  return all;
+}
@@ -0,0 +1,113 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { detectPort, detectPortCandidates, resolvePort } from './port-detection.js';
+
+describe('port-detection', () => {
+  let dir: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(dir, relPath)), { recursive: true });
+    writeFileSync(join(dir, relPath), content);
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'port-detection-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('detectPort', () => {
+    it('falls back to the framework default', () => {
+      expect(detectPort('react', dir)).toBe(5173);
+      expect(detectPort('go', dir)).toBe(8080);
+      expect(detectPort('python', dir)).toBe(3000);
+    });
+
+    it('reads server.port from vite config', () => {
+      write('vite.config.ts', 'export default defineConfig({ server: { host: true, port: 5180 } });');
+
+      expect(detectPort('react', dir)).toBe(5180);
+    });
+
+    it('reads the port from astro config', () => {
+      write('astro.config.mjs', "export default defineConfig({ server: { port: '4321' } });");
+
+      expect(detectPort('vanilla-js', dir)).toBe(4321);
+    });
+
+    it('reads -p, --port and PORT= from package.json scripts', () => {
+      write('package.json', JSON.stringify({ scripts: { dev: 'next dev -p 4000' } }));
+      expect(detectPort('nextjs', dir)).toBe(4000);
+
+      write('package.json', JSON.stringify({ scripts: { dev: 'vite --port=5001' } }));
+      expect(detectPort('react', dir)).toBe(5001);
+
+      write('package.json', JSON.stringify({ scripts: { start: 'PORT=4100 node server.js' } }));
+      expect(detectPort('node', dir)).toBe(4100);
+    });
+
+    it('reads the web process from a Procfile', () => {
+      write('Procfile', 'release: ./migrate\nweb: bundle exec puma -p 3100\n');
+
+      expect(detectPort('ruby', dir)).toBe(3100);
+    });
+
+    it('reads Run and ListenAndServe addresses from Go sources', () => {
+      write('go.mod', 'module example.com/app\n');
+      write('main.go', 'func main() {\n\tr := gin.Default()\n\tr.Run(":9090")\n}\n');
+      expect(detectPort('go', dir)).toBe(9090);
+
+      write('main.go', 'func main() {\n\tlog.Fatal(http.ListenAndServe("localhost:8000", mux))\n}\n');
+      expect(detectPort('go', dir)).toBe(8000);
+    });
+
+    it('ignores Addr fields outside http.Server', () => {
+      write('go.mod', 'module example.com/app\n');
+      write('main.go', 'rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})\n');
+
+      expect(detectPort('go', dir)).toBe(8080);
+    });
+  });
+
+  describe('detectPortCandidates', () => {
+    it('lists each distinct port with its source, most specific first', () => {
+      write('vite.config.ts', 'export default { server: { port: 5173 } };');
+      write('package.json', JSON.stringify({ scripts: { dev: 'vite --port 5173', serve: 'serve -p 8000' } }));
+      write('Procfile.dev', 'web: npm run dev -- --port 3001\n');
+
+      expect(detectPortCandidates(dir)).toEqual([
+        { port: 5173, source: 'vite.config.ts' },
+        { port: 8000, source: 'package.json (scripts.serve)' },
+        { port: 3001, source: 'Procfile.dev' },
+      ]);
+    });
+  });
+
+  describe('resolvePort', () => {
+    it('only asks when there are several candidates', async () => {
+      const choose = vi.fn().mockResolvedValue(8000);
+
+      write('package.json', JSON.stringify({ scripts: { dev: 'vite --port 5001' } }));
+      expect(await resolvePort('react', dir, choose)).toBe(5001);
+      expect(choose).not.toHaveBeenCalled();
+
+      write('package.json', JSON.stringify({ scripts: { dev: 'vite --port 5001', serve: 'serve -p 8000' } }));
+      expect(await resolvePort('react', dir, choose)).toBe(8000);
+      expect(choose).toHaveBeenCalledWith([
+        { port: 5001, source: 'package.json (scripts.dev)' },
+        { port: 8000, source: 'package.json (scripts.serve)' },
+      ]);
+    });
+
+    it('uses the most specific candidate without a chooser', async () => {
+      write('vite.config.ts', 'export default { server: { port: 5200 } };');
+      write('package.json', JSON.stringify({ scripts: { serve: 'serve -p 8000' } }));
+
+      expect(await resolvePort('react', dir)).toBe(5200);
+    });
+  });
+});
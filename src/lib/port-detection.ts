@@ -1,6 +1,7 @@
 import * as fs from 'node:fs';
 import { join } from 'node:path';
-import type { Integration } from './constants.js';
+import fg from 'fast-glob';
+import { IGNORE_PATTERNS, type Integration } from './constants.js';
 import { getConfig } from './settings.js';
 
 const settings = getConfig();
@@ -11,12 +12,42 @@ const INTEGRATION_TO_SETTINGS_KEY: Record<Integration, string> = {
   'tanstack-start': 'tanstackStart',
   'react-router': 'reactRouter',
   'vanilla-js': 'vanillaJs',
+  go: 'go',
+  'python-django': 'pythonDjango',
+  'ruby-rails': 'rubyRails',
 };
 
 const DEFAULT_PORT = 3000;
 const DEFAULT_CALLBACK_PATH = '/auth/callback';
 
-function getDefaultPort(integration: Integration): number {
+/** Framework config files that can set the dev server port, in priority order */
+const FRAMEWORK_CONFIG_FILES = [
+  'vite.config.ts',
+  'vite.config.mts',
+  'vite.config.js',
+  'vite.config.mjs',
+  'astro.config.mjs',
+  'astro.config.ts',
+  'astro.config.js',
+  'app.config.ts',
+  'app.config.js',
+];
+
+/** package.json scripts that start a dev server, in priority order */
+const DEV_SCRIPTS = ['dev', 'start', 'serve'];
+
+const PROCFILES = ['Procfile.dev', 'Procfile'];
+
+/**
+ * A port found in project config, with where it came from.
+ */
+export interface PortCandidate {
+  port: number;
+  /** File (and key) the port was read from, e.g. `package.json (scripts.dev)` */
+  source: string;
+}
+
+export function getDefaultPort(integration: Integration): number {
   const settingsKey = INTEGRATION_TO_SETTINGS_KEY[integration];
   return settings.frameworks[settingsKey]?.port ?? DEFAULT_PORT;
 }
@@ -26,100 +57,123 @@ export function getCallbackPath(integration: Integration): string {
   return settings.frameworks[settingsKey]?.callbackPath ?? DEFAULT_CALLBACK_PATH;
 }
 
-/**
- * Parse port from Vite config file.
- * Looks for server.port in vite.config.{ts,js,mjs}
- */
-function parseViteConfigPort(configPath: string): number | null {
+function readFile(path: string): string | null {
   try {
-    const content = fs.readFileSync(configPath, 'utf-8');
-    // Match: port: 3000 or port: "3000" or port: '3000'
-    const portMatch = content.match(/port\s*:\s*['"]?(\d+)['"]?/);
-    if (portMatch) {
-      return parseInt(portMatch[1], 10);
-    }
+    return fs.readFileSync(path, 'utf-8');
   } catch {
-    // File doesn't exist or can't be read
+    return null;
   }
-  return null;
+}
+
+function toPort(value: string | undefined): number | null {
+  const port = value ? parseInt(value, 10) : NaN;
+  return port > 0 && port < 65536 ? port : null;
 }
 
 /**
- * Parse port from Next.js package.json scripts.
- * Next.js uses: "dev": "next dev -p 4000" or --port 4000
+ * Parse port from framework config files.
+ * Vite and Astro use server.port; TanStack Start (Vinxi) uses server: { port: N }
  */
-function parseNextConfigPort(installDir: string): number | null {
-  try {
-    const packageJsonPath = join(installDir, 'package.json');
-    const content = fs.readFileSync(packageJsonPath, 'utf-8');
-    const packageJson = JSON.parse(content);
-
-    const devScript = packageJson.scripts?.dev || '';
-    // Match: -p 4000, --port 4000, --port=4000
-    const portMatch = devScript.match(/-p\s+(\d+)|--port[=\s]+(\d+)/);
-    if (portMatch) {
-      return parseInt(portMatch[1] || portMatch[2], 10);
-    }
-  } catch {
-    // Can't read package.json
-  }
-  return null;
+function parseFrameworkConfigPort(content: string): number | null {
+  // Prefer a port inside a server block, then any `port: N`
+  const serverMatch = content.match(/server\s*:\s*\{[^}]*?port\s*:\s*['"]?(\d+)['"]?/);
+  if (serverMatch) return toPort(serverMatch[1]);
+  // Match: port: 3000 or port: "3000" or port: '3000'
+  const portMatch = content.match(/\bport\s*:\s*['"]?(\d+)['"]?/);
+  return toPort(portMatch?.[1]);
 }
 
 /**
- * Parse port from TanStack Start app.config.ts.
- * Uses Vinxi: server: { port: N }
+ * Parse port from a dev command.
+ * Matches: -p 4000, --port 4000, --port=4000, PORT=4000
  */
-function parseTanStackPort(installDir: string): number | null {
-  const configPaths = [join(installDir, 'app.config.ts'), join(installDir, 'app.config.js')];
+function parseCommandPort(command: string): number | null {
+  const match = command.match(/(?:^|\s)(?:-p\s+|--port[=\s]+)(\d+)|\bPORT=(\d+)/);
+  return toPort(match?.[1] || match?.[2]);
+}
 
-  for (const configPath of configPaths) {
+/**
+ * Parse port from Go sources.
+ * Matches: r.Run(":8080"), http.ListenAndServe(":8080", ...), http.Server{Addr: ":8080"}
+ */
+function parseGoPort(content: string): number | null {
+  const match = content.match(
+    /(?:\.Run|ListenAndServe(?:TLS)?)\(\s*"[\w.-]*:(\d+)"|http\.Server\s*\{[^}]*?Addr\s*:\s*"[\w.-]*:(\d+)"/,
+  );
+  return toPort(match?.[1] || match?.[2]);
+}
+
+/**
+ * Collect every dev server port the project configures.
+ * Candidates are ordered by how specific the source is (framework config,
+ * package.json scripts, Procfile, Go sources) and deduplicated by port.
+ */
+export function detectPortCandidates(installDir: string): PortCandidate[] {
+  const candidates: PortCandidate[] = [];
+  const add = (port: number | null, source: string) => {
+    if (port && !candidates.some((c) => c.port === port)) candidates.push({ port, source });
+  };
+
+  for (const file of FRAMEWORK_CONFIG_FILES) {
+    const content = readFile(join(installDir, file));
+    if (content) add(parseFrameworkConfigPort(content), file);
+  }
+
+  const packageJson = readFile(join(installDir, 'package.json'));
+  if (packageJson) {
     try {
-      const content = fs.readFileSync(configPath, 'utf-8');
-      // Match server config with port
-      const portMatch = content.match(/server\s*:\s*\{[^}]*port\s*:\s*(\d+)/);
-      if (portMatch) {
-        return parseInt(portMatch[1], 10);
+      const scripts: Record<string, string> = JSON.parse(packageJson).scripts ?? {};
+      for (const name of DEV_SCRIPTS) {
+        if (typeof scripts[name] === 'string') add(parseCommandPort(scripts[name]), `package.json (scripts.${name})`);
       }
     } catch {
-      // Config file doesn't exist
+      // Invalid package.json
     }
   }
-  return null;
+
+  for (const file of PROCFILES) {
+    const content = readFile(join(installDir, file));
+    if (!content) continue;
+    for (const line of content.split(/\r?\n/)) {
+      const command = /^\s*web\s*:\s*(.*)$/.exec(line)?.[1];
+      if (command) add(parseCommandPort(command), file);
+    }
+  }
+
+  if (fs.existsSync(join(installDir, 'go.mod'))) {
+    const goFiles = fg.sync(['*.go', 'cmd/**/*.go'], {
+      cwd: installDir,
+      ignore: [...IGNORE_PATTERNS, '**/*_test.go'],
+      deep: 4,
+    });
+    for (const file of goFiles.sort()) {
+      const content = readFile(join(installDir, file));
+      if (content) add(parseGoPort(content), file);
+    }
+  }
+
+  return candidates;
 }
 
 /**
  * Detect the dev server port for a framework.
- * Checks config files first, falls back to framework default.
+ * Uses the most specific configured port, falls back to framework default.
  */
 export function detectPort(integration: Integration, installDir: string): number {
-  let detectedPort: number | null = null;
-
-  switch (integration) {
-    case 'nextjs':
-      detectedPort = parseNextConfigPort(installDir);
-      break;
-
-    case 'tanstack-start':
-      detectedPort = parseTanStackPort(installDir);
-      break;
-
-    case 'react':
-    case 'react-router':
-    case 'vanilla-js': {
-      // Vite-based frameworks
-      const viteConfigs = [
-        join(installDir, 'vite.config.ts'),
-        join(installDir, 'vite.config.js'),
-        join(installDir, 'vite.config.mjs'),
-      ];
-      for (const configPath of viteConfigs) {
-        detectedPort = parseViteConfigPort(configPath);
-        if (detectedPort) break;
-      }
-      break;
-    }
-  }
+  return detectPortCandidates(installDir)[0]?.port ?? getDefaultPort(integration);
+}
 
-  return detectedPort ?? getDefaultPort(integration);
+/**
+ * Resolve the dev server port, asking the user when the project configures
+ * more than one. Without a chooser (e.g. CI), the most specific candidate wins.
+ */
+export async function resolvePort(
+  integration: Integration,
+  installDir: string,
+  choose?: (candidates: PortCandidate[]) => Promise<number>,
+): Promise<number> {
+  const candidates = detectPortCandidates(installDir);
+  if (candidates.length === 0) return getDefaultPort(integration);
+  if (candidates.length === 1 || !choose) return candidates[0].port;
+  return choose(candidates);
 }
@@ -0,0 +1,115 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { InstallerEventEmitter } from './events.js';
+import { loadProjectHooks, runProjectHooks, unconfirmedHooksError, type ProjectHooks } from './project-hooks.js';
+
+describe('project-hooks', () => {
+  let dir: string;
+
+  function writeConfig(config: unknown, root = dir) {
+    mkdirSync(join(root, '.workos'), { recursive: true });
+    writeFileSync(join(root, '.workos', 'config.json'), typeof config === 'string' ? config : JSON.stringify(config));
+  }
+
+  function hooks(post: ProjectHooks['post']): ProjectHooks {
+    return { source: join(dir, '.workos', 'config.json'), cwd: dir, pre: [], post };
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'project-hooks-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('loadProjectHooks', () => {
+    it('reads pre and post hooks; strings are required', () => {
+      writeConfig({ hooks: { pre: ['./backup.sh'], post: ['gofmt -w .', { run: 'make docs', required: false }] } });
+
+      expect(loadProjectHooks(dir)).toEqual({
+        source: join(dir, '.workos', 'config.json'),
+        cwd: dir,
+        pre: [{ command: './backup.sh', required: true }],
+        post: [
+          { command: 'gofmt -w .', required: true },
+          { command: 'make docs', required: false },
+        ],
+      });
+    });
+
+    it('falls back to the workspace root and runs hooks there', () => {
+      writeConfig({ hooks: { post: ['pnpm prettier --write .'] } });
+      const pkg = join(dir, 'apps', 'web');
+      mkdirSync(pkg, { recursive: true });
+
+      expect(loadProjectHooks(pkg, { workspaceRoot: dir })?.cwd).toBe(dir);
+    });
+
+    it('has no hooks without a config or a hooks key', () => {
+      expect(loadProjectHooks(dir)).toBeUndefined();
+      writeConfig({ other: true });
+      expect(loadProjectHooks(dir)).toBeUndefined();
+    });
+
+    it('rejects configs it cannot use', () => {
+      writeConfig('{ hooks: ');
+      expect(() => loadProjectHooks(dir)).toThrow('is not valid JSON');
+
+      writeConfig({ hooks: { post: 'gofmt -w .' } });
+      expect(() => loadProjectHooks(dir)).toThrow('"hooks.post"');
+
+      writeConfig({ hooks: { post: [{ command: 'gofmt -w .' }] } });
+      expect(() => loadProjectHooks(dir)).toThrow('"hooks.post[0]"');
+    });
+  });
+
+  describe('runProjectHooks', () => {
+    it('runs hooks in the project and streams their output', async () => {
+      const emitter = new InstallerEventEmitter();
+      const output: string[] = [];
+      emitter.on('hook:output', ({ text }) => output.push(text));
+
+      await runProjectHooks(hooks([{ command: 'echo formatted && touch done.txt', required: true }]), 'post', emitter);
+
+      expect(output.join('')).toContain('formatted');
+      expect(existsSync(join(dir, 'done.txt'))).toBe(true);
+    });
+
+    it('fails on a required hook and continues past an optional one', async () => {
+      const emitter = new InstallerEventEmitter();
+      const completed: number[] = [];
+      emitter.on('hook:complete', ({ exitCode }) => completed.push(exitCode));
+
+      await expect(
+        runProjectHooks(
+          hooks([
+            { command: 'exit 2', required: false },
+            { command: 'exit 3', required: true },
+            { command: 'echo never', required: true },
+          ]),
+          'post',
+          emitter,
+        ),
+      ).rejects.toThrow('Required post hook failed with exit code 3: exit 3');
+      expect(completed).toEqual([2, 3]);
+    });
+  });
+
+  describe('unconfirmedHooksError', () => {
+    it('stops a CI run without --yes when a hook is required', () => {
+      const config = hooks([{ command: 'make docs', required: false }]);
+      config.pre = [{ command: './backup-db.sh', required: true }];
+
+      expect(unconfirmedHooksError(config)).toBe(
+        "Required project hooks can't run in CI without --yes (pre: ./backup-db.sh); pass --yes to run them",
+      );
+    });
+
+    it('lets a CI run skip optional hooks', () => {
+      expect(unconfirmedHooksError(hooks([{ command: 'make docs', required: false }]))).toBeNull();
+    });
+  });
+});
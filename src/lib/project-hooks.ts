@@ -0,0 +1,155 @@
+/**
+ * Project hooks: shell commands from `.workos/config.json` that run before
+ * the agent starts (`pre`, e.g. backing up a database) and after it
+ * finishes successfully (`post`, e.g. `gofmt -w .` or codegen).
+ *
+ * ```json
+ * {
+ *   "hooks": {
+ *     "pre": ["./scripts/backup-db.sh"],
+ *     "post": ["gofmt -w .", { "run": "make docs", "required": false }]
+ *   }
+ * }
+ * ```
+ *
+ * Hooks run in the directory that holds `.workos/`. A hook is required
+ * unless it sets `"required": false`; a required hook that exits non-zero
+ * fails the run. Repos can put anything in there, so the commands are
+ * shown and confirmed before the run unless `--yes`.
+ */
+
+import { spawn } from 'node:child_process';
+import { readFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import type { InstallerEventEmitter } from './events.js';
+
+export const HOOKS_CONFIG_FILE = join('.workos', 'config.json');
+
+export const HOOK_PHASES = ['pre', 'post'] as const;
+export type HookPhase = (typeof HOOK_PHASES)[number];
+
+export interface ProjectHook {
+  command: string;
+  required: boolean;
+}
+
+export interface ProjectHooks {
+  /** Config file the hooks came from */
+  source: string;
+  /** Where the hooks run: the directory containing `.workos/` */
+  cwd: string;
+  pre: ProjectHook[];
+  post: ProjectHook[];
+}
+
+function parseHook(entry: unknown, where: string): ProjectHook {
+  if (typeof entry === 'string' && entry.trim()) {
+    return { command: entry.trim(), required: true };
+  }
+  if (entry && typeof entry === 'object') {
+    const { run, required } = entry as { run?: unknown; required?: unknown };
+    if (typeof run === 'string' && run.trim() && (required === undefined || typeof required === 'boolean')) {
+      return { command: run.trim(), required: required ?? true };
+    }
+  }
+  throw new Error(`${where} must be a command string or { "run": "...", "required": false }`);
+}
+
+function readHooks(path: string): ProjectHooks | undefined {
+  let config: unknown;
+  try {
+    config = JSON.parse(readFileSync(path, 'utf-8'));
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code === 'ENOENT') throw error;
+    throw new Error(`${path} is not valid JSON`);
+  }
+  const hooks = (config as { hooks?: unknown } | null)?.hooks;
+  if (hooks === undefined) return undefined;
+  if (!hooks || typeof hooks !== 'object') throw new Error(`"hooks" in ${path} must be an object`);
+
+  const byPhase = {} as Record<HookPhase, ProjectHook[]>;
+  for (const phase of HOOK_PHASES) {
+    const entries = (hooks as Record<string, unknown>)[phase] ?? [];
+    if (!Array.isArray(entries)) throw new Error(`"hooks.${phase}" in ${path} must be an array`);
+    byPhase[phase] = entries.map((entry, i) => parseHook(entry, `"hooks.${phase}[${i}]" in ${path}`));
+  }
+  if (byPhase.pre.length === 0 && byPhase.post.length === 0) return undefined;
+  return { source: path, cwd: dirname(dirname(path)), ...byPhase };
+}
+
+/**
+ * Find the project's hooks: `.workos/config.json` in the project, then at
+ * the workspace root. Throws on a config that can't be used, so a typo
+ * doesn't silently skip a required hook.
+ */
+export function loadProjectHooks(
+  installDir: string,
+  options: { workspaceRoot?: string } = {},
+): ProjectHooks | undefined {
+  for (const dir of [installDir, options.workspaceRoot].filter((d): d is string => Boolean(d))) {
+    try {
+      return readHooks(join(dir, HOOKS_CONFIG_FILE));
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'ENOENT') throw error;
+    }
+  }
+  return undefined;
+}
+
+/**
+ * Why a run that can't confirm the hooks (CI without --yes) has to stop:
+ * optional hooks can be skipped, a required one can't. Null when skipping
+ * them all is fine.
+ */
+export function unconfirmedHooksError(hooks: ProjectHooks): string | null {
+  const required = HOOK_PHASES.flatMap((phase) =>
+    hooks[phase].filter((hook) => hook.required).map((hook) => `${phase}: ${hook.command}`),
+  );
+  if (required.length === 0) return null;
+  return `Required project hooks can't run in CI without --yes (${required.join(', ')}); pass --yes to run them`;
+}
+
+function runHook(hook: ProjectHook, phase: HookPhase, cwd: string, emitter?: InstallerEventEmitter) {
+  return new Promise<number>((resolve) => {
+    const proc = spawn(hook.command, {
+      cwd,
+      shell: true,
+      env: { ...process.env, WORKOS_HOOK_PHASE: phase },
+    });
+    const stream = (data: Buffer) => emitter?.emit('hook:output', { phase, text: data.toString() });
+    proc.stdout?.on('data', stream);
+    proc.stderr?.on('data', stream);
+    proc.on('close', (code) => resolve(code ?? 1));
+    proc.on('error', (error) => {
+      emitter?.emit('hook:output', { phase, text: `${error.message}\n` });
+      resolve(1);
+    });
+  });
+}
+
+/**
+ * Run one phase's hooks in order, streaming their output. Stops at the
+ * first required hook that fails and throws; optional failures are
+ * reported and the rest still run.
+ */
+export async function runProjectHooks(
+  hooks: ProjectHooks,
+  phase: HookPhase,
+  emitter?: InstallerEventEmitter,
+): Promise<void> {
+  for (const hook of hooks[phase]) {
+    emitter?.emit('hook:start', { phase, command: hook.command });
+    const startTime = Date.now();
+    const exitCode = await runHook(hook, phase, hooks.cwd, emitter);
+    emitter?.emit('hook:complete', {
+      phase,
+      command: hook.command,
+      exitCode,
+      required: hook.required,
+      durationMs: Date.now() - startTime,
+    });
+    if (exitCode !== 0 && hook.required) {
+      throw new Error(`Required ${phase} hook failed with exit code ${exitCode}: ${hook.command}`);
+    }
+  }
+}
@@ -0,0 +1,96 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { _setInstallsDir, writeInstallRecord } from './existing-integration.js';
+import { collectProjectStatus, expectedEnvKeys, findSdks, redirectUriCovers } from './project-status.js';
+
+describe('project-status', () => {
+  let dir: string;
+  let installsDir: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(dir, relPath)), { recursive: true });
+    writeFileSync(join(dir, relPath), content);
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'project-status-test-'));
+    installsDir = mkdtempSync(join(tmpdir(), 'project-status-installs-'));
+    _setInstallsDir(installsDir);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+    rmSync(installsDir, { recursive: true, force: true });
+  });
+
+  it('finds SDKs with installed or declared versions across manifests', () => {
+    write('package.json', JSON.stringify({ dependencies: { '@workos-inc/authkit-nextjs': '^2.0.0', next: '15.0.0' } }));
+    write('node_modules/@workos-inc/authkit-nextjs/package.json', JSON.stringify({ version: '2.3.1' }));
+    write('server/go.mod', 'module app\n\nrequire github.com/workos/workos-go/v4 v4.21.0\n');
+    write('a/b/c/d/requirements.txt', 'workos==5.0.0\n');
+
+    expect(findSdks(dir)).toEqual([
+      { name: '@workos-inc/authkit-nextjs', version: '2.3.1', manifest: 'package.json' },
+      { name: 'github.com/workos/workos-go/v4', version: '4.21.0', manifest: 'server/go.mod' },
+    ]);
+  });
+
+  it('counts prefixed env keys once, under the first name a skill uses', () => {
+    const prompt = 'Set `WORKOS_CLIENT_ID` (or `VITE_WORKOS_CLIENT_ID` with Vite) and WORKOS_REDIRECT_URI.';
+
+    expect(expectedEnvKeys(prompt)).toEqual(['WORKOS_CLIENT_ID', 'WORKOS_REDIRECT_URI']);
+  });
+
+  it('matches registered redirect URIs, with a wildcard', () => {
+    expect(redirectUriCovers('http://localhost:3000/callback', 'http://localhost:3000/callback')).toBe(true);
+    expect(redirectUriCovers('https://*.example.com/callback', 'https://app.example.com/callback')).toBe(true);
+    expect(redirectUriCovers('http://localhost:3000/callback', 'http://localhost:5173/callback')).toBe(false);
+  });
+
+  it('summarizes env keys, the bound profile, the redirect URI and skill updates', () => {
+    writeInstallRecord({ installDir: dir, integration: 'nextjs' });
+    write('package.json', JSON.stringify({ scripts: { dev: 'next dev -p 4000' } }));
+    write(
+      '.env.local',
+      'WORKOS_API_KEY=sk_test_abc\nWORKOS_CLIENT_ID=client_1\nWORKOS_REDIRECT_URI=http://localhost:3000/callback\n',
+    );
+
+    const status = collectProjectStatus(dir, {
+      skill: 'workos-authkit-nextjs',
+      skillPrompt: 'Needs WORKOS_API_KEY, WORKOS_CLIENT_ID and WORKOS_COOKIE_PASSWORD.',
+      lock: {
+        version: 1,
+        trustedSources: [],
+        skills: {
+          'claude-code/workos-authkit-nextjs': { digest: 'old', source: 'workos', installedAt: '2026-01-01' },
+          'cursor/workos-authkit-nextjs': { digest: 'new', source: 'workos', installedAt: '2026-01-01' },
+        },
+      },
+      bundledDigests: { 'workos-authkit-nextjs': 'new' },
+      config: {
+        activeEnvironment: 'production',
+        environments: {
+          production: { name: 'production', type: 'production', apiKey: 'sk_live_x' },
+          dev: { name: 'dev', type: 'sandbox', apiKey: 'sk_test_other', clientId: 'client_1' },
+        },
+      },
+    });
+
+    expect(status.integration).toBe('nextjs');
+    expect(status.env).toEqual({
+      files: ['.env.local'],
+      present: ['WORKOS_API_KEY', 'WORKOS_CLIENT_ID', 'WORKOS_REDIRECT_URI'],
+      expected: ['WORKOS_API_KEY', 'WORKOS_CLIENT_ID', 'WORKOS_COOKIE_PASSWORD'],
+      missing: ['WORKOS_COOKIE_PASSWORD'],
+    });
+    expect(status.environment).toEqual({ profile: 'dev', type: 'sandbox', activeProfile: 'production' });
+    expect(status.redirect.devCallback).toMatch(/^http:\/\/localhost:4000\//);
+    expect(status.redirect.matchesDevServer).toBe(false);
+    expect(status.skills.map((skill) => [skill.agent, skill.updateAvailable])).toEqual([
+      ['claude-code', true],
+      ['cursor', false],
+    ]);
+  });
+});
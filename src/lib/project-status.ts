@@ -0,0 +1,359 @@
+/**
+ * What WorkOS setup a project already has (`workos status`), read from disk
+ * only: the install record, dependency manifests, env files, the skill
+ * lockfile and the CLI's profiles. The redirect URIs registered in WorkOS
+ * are the one remote check, and the command only makes it with --remote.
+ *
+ * Manifests are found with a walk bounded by depth and count, so a large
+ * monorepo answers as fast as a small app.
+ */
+
+import { existsSync, readFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import fg from 'fast-glob';
+import { parseEnvFile } from '../utils/env-parser.js';
+import type { CliConfig } from './config-store.js';
+import { IGNORE_PATTERNS, type Integration } from './constants.js';
+import { listAll } from './pagination.js';
+import { readInstallRecord } from './existing-integration.js';
+import { detectPortCandidates, getCallbackPath, detectPort } from './port-detection.js';
+import type { SkillLock } from './skill-integrity.js';
+
+/** Levels below the project searched for manifests (workspace packages, `server/`, `apps/*`) */
+const MANIFEST_DEPTH = 3;
+
+/** Manifests read before the walk stops */
+const MAX_MANIFESTS = 50;
+
+const ENV_FILES = ['.env', '.env.local', '.env.development', '.env.development.local'];
+
+/** Framework prefixes that expose a variable to the browser */
+const ENV_PREFIX = /^(NEXT_PUBLIC_|VITE_|REACT_APP_|PUBLIC_)/;
+
+const WORKOS_ENV_KEY = /\b(?:NEXT_PUBLIC_|VITE_|REACT_APP_|PUBLIC_)?WORKOS_[A-Z][A-Z0-9_]*[A-Z0-9]\b/g;
+
+/** WorkOS SDKs outside package.json: manifest, and the pattern naming the package and its version */
+const MANIFEST_SDKS: { file: string; pattern: RegExp }[] = [
+  { file: 'go.mod', pattern: /(?<name>github\.com\/workos\/workos-go(?:\/v\d+)?)\s+v?(?<version>[\w.+-]+)/ },
+  { file: 'requirements.txt', pattern: /^(?<name>workos)(?![\w-])\s*(?:[=~><!]=?\s*(?<version>[\d.]+))?/m },
+  { file: 'pyproject.toml', pattern: /["'](?<name>workos)\s*(?:[=~><!]=?\s*(?<version>[\d.]+))?[^"']*["']/ },
+  { file: 'Gemfile.lock', pattern: /^ {4}(?<name>workos) \((?<version>[^)]+)\)/m },
+  { file: 'Gemfile', pattern: /gem\s+['"](?<name>workos)['"](?:\s*,\s*['"][~>=<\s]*(?<version>[\d.]+)['"])?/ },
+  { file: 'composer.json', pattern: /"(?<name>workos\/workos-php(?:-laravel)?)"\s*:\s*"(?<version>[^"]+)"/ },
+  { file: 'mix.exs', pattern: /\{:(?<name>workos),\s*"(?<version>[^"]+)"/ },
+  { file: 'build.gradle', pattern: /(?<name>com\.workos:workos):(?<version>[\w.+-]+)/ },
+  { file: 'build.gradle.kts', pattern: /(?<name>com\.workos:workos):(?<version>[\w.+-]+)/ },
+  { file: '*.csproj', pattern: /Include="(?<name>WorkOS\.net)"\s+Version="(?<version>[^"]+)"/ },
+];
+
+export interface InstalledSkillStatus {
+  /** Coding agent the skill is installed for, e.g. `claude-code` */
+  agent: string;
+  skill: string;
+  source: string;
+  installedAt: string;
+  /** The CLI ships different content for this skill than what's installed */
+  updateAvailable: boolean;
+}
+
+export interface SdkStatus {
+  name: string;
+  /** Installed version when it can be read, else the declared range; null when unpinned */
+  version: string | null;
+  /** Manifest it was found in, relative to the project */
+  manifest: string;
+}
+
+export interface EnvStatus {
+  /** Env files read, relative to the project */
+  files: string[];
+  /** WORKOS_* keys set in them */
+  present: string[];
+  /** Keys the project's skill uses, null when the skill isn't known */
+  expected: string[] | null;
+  /** Expected keys with no value under any framework prefix */
+  missing: string[];
+}
+
+export interface EnvironmentBinding {
+  /** CLI profile whose API key or client ID the project's env files use */
+  profile: string | null;
+  type: 'production' | 'sandbox' | null;
+  /** The profile `workos` commands use right now */
+  activeProfile: string | null;
+}
+
+export interface RedirectStatus {
+  /** Callback URL of the dev server, from the detected port */
+  devCallback: string | null;
+  /** WORKOS_REDIRECT_URI from the env files */
+  configured: string | null;
+  /** Whether `configured` is on the dev server's origin; null when either is unknown */
+  matchesDevServer: boolean | null;
+  /** Redirect URIs registered in the environment, with --remote */
+  registered?: string[];
+  /** Whether one of them accepts `devCallback` */
+  registeredCoverDevServer?: boolean | null;
+  /** Why the registered URIs couldn't be read */
+  remoteError?: string;
+}
+
+export interface ProjectStatus {
+  installDir: string;
+  /** Integration from the last `workos install` in this project */
+  integration: string | null;
+  installedAt: string | null;
+  skill: string | null;
+  skills: InstalledSkillStatus[];
+  sdks: SdkStatus[];
+  env: EnvStatus;
+  environment: EnvironmentBinding;
+  redirect: RedirectStatus;
+}
+
+export interface ProjectStatusInputs {
+  /** The integration's skill name, when the integration is known */
+  skill?: string;
+  /** Text of that skill's SKILL.md, to find the env keys it uses */
+  skillPrompt?: string;
+  lock: SkillLock;
+  /** Digest of each skill the CLI ships, keyed by skill name */
+  bundledDigests: Record<string, string>;
+  config: CliConfig | null;
+}
+
+function read(path: string): string | null {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return null;
+  }
+}
+
+/** Manifests in the project, shallowest first */
+function findManifests(installDir: string, names: string[]): string[] {
+  const files = fg.sync(
+    names.map((name) => `**/${name}`),
+    {
+      cwd: installDir,
+      ignore: [...IGNORE_PATTERNS, '**/vendor/**', '**/.venv/**', '**/venv/**'],
+      deep: MANIFEST_DEPTH,
+    },
+  );
+  return files
+    .sort((a, b) => a.split('/').length - b.split('/').length || a.localeCompare(b))
+    .slice(0, MAX_MANIFESTS);
+}
+
+/** Version npm installed, from node_modules next to the manifest or above it */
+function installedNpmVersion(installDir: string, manifestDir: string, name: string): string | null {
+  let dir = join(installDir, manifestDir);
+  while (dir.startsWith(installDir)) {
+    const pkg = read(join(dir, 'node_modules', name, 'package.json'));
+    if (pkg) {
+      try {
+        return (JSON.parse(pkg) as { version?: string }).version ?? null;
+      } catch {
+        return null;
+      }
+    }
+    if (dir === installDir) break;
+    dir = dirname(dir);
+  }
+  return null;
+}
+
+export function findSdks(installDir: string): SdkStatus[] {
+  const sdks: SdkStatus[] = [];
+  const patterns = new Map(MANIFEST_SDKS.map(({ file, pattern }) => [file, pattern]));
+  for (const manifest of findManifests(installDir, ['package.json', ...patterns.keys()])) {
+    const content = read(join(installDir, manifest)) ?? '';
+    const file = manifest.split('/').pop()!;
+    if (file === 'package.json') {
+      try {
+        const pkg = JSON.parse(content) as { dependencies?: object; devDependencies?: object };
+        const deps: Record<string, string> = { ...pkg.dependencies, ...pkg.devDependencies };
+        for (const [name, range] of Object.entries(deps)) {
+          if (!/^@workos(-inc)?\//.test(name)) continue;
+          const version = installedNpmVersion(installDir, dirname(manifest), name) ?? range;
+          sdks.push({ name, version, manifest });
+        }
+      } catch {
+        // Invalid package.json
+      }
+      continue;
+    }
+    const pattern = patterns.get(file) ?? (file.endsWith('.csproj') ? patterns.get('*.csproj') : undefined);
+    const match = pattern?.exec(content);
+    if (match?.groups) sdks.push({ name: match.groups.name, version: match.groups.version ?? null, manifest });
+  }
+  // Gemfile.lock pins what the Gemfile declares
+  return sdks.filter(
+    (sdk) =>
+      !sdk.manifest.endsWith('Gemfile') ||
+      !sdks.some((other) => other.manifest === `${sdk.manifest}.lock` && other.name === sdk.name),
+  );
+}
+
+/** A key without its framework prefix, so VITE_WORKOS_CLIENT_ID satisfies WORKOS_CLIENT_ID */
+function baseKey(key: string): string {
+  return key.replace(ENV_PREFIX, '');
+}
+
+/**
+ * Env keys a skill's prompt tells the agent to set, in the order it names
+ * them. Prefixed variants of one key (WORKOS_CLIENT_ID, VITE_WORKOS_CLIENT_ID)
+ * count once, under the first name used.
+ */
+export function expectedEnvKeys(skillPrompt: string): string[] {
+  const keys = new Map<string, string>();
+  for (const [key] of skillPrompt.matchAll(WORKOS_ENV_KEY)) {
+    if (!keys.has(baseKey(key))) keys.set(baseKey(key), key);
+  }
+  return [...keys.values()];
+}
+
+function readEnvFiles(installDir: string): { files: string[]; values: Record<string, string> } {
+  const files: string[] = [];
+  const values: Record<string, string> = {};
+  for (const file of ENV_FILES) {
+    const content = read(join(installDir, file));
+    if (content === null) continue;
+    files.push(file);
+    // Later files override earlier ones, as frameworks load them
+    for (const [key, value] of Object.entries(parseEnvFile(content))) {
+      if (baseKey(key).startsWith('WORKOS_')) values[key] = value;
+    }
+  }
+  return { files, values };
+}
+
+/** The value of a key under any framework prefix */
+function envValue(values: Record<string, string>, key: string): string | null {
+  const found = Object.keys(values).find((candidate) => baseKey(candidate) === key && values[candidate]);
+  return found ? values[found] : null;
+}
+
+function bindEnvironment(values: Record<string, string>, config: CliConfig | null): EnvironmentBinding {
+  const apiKey = envValue(values, 'WORKOS_API_KEY');
+  const clientId = envValue(values, 'WORKOS_CLIENT_ID');
+  const [profile, bound] =
+    Object.entries(config?.environments ?? {}).find(
+      ([, env]) => (apiKey && env.apiKey === apiKey) || (clientId && env.clientId === clientId),
+    ) ?? [];
+  const keyType = apiKey?.startsWith('sk_test_') ? 'sandbox' : apiKey?.startsWith('sk_live_') ? 'production' : null;
+  return {
+    profile: profile ?? null,
+    type: bound?.type ?? keyType,
+    activeProfile: config?.activeEnvironment ?? null,
+  };
+}
+
+function redirectStatus(
+  installDir: string,
+  integration: string | null,
+  values: Record<string, string>,
+): RedirectStatus {
+  // Without an integration, only a port the project configures says where the dev server runs
+  const port = integration
+    ? detectPort(integration as Integration, installDir)
+    : detectPortCandidates(installDir)[0]?.port;
+  const devCallback = port ? `http://localhost:${port}${getCallbackPath(integration as Integration)}` : null;
+  const configured = envValue(values, 'WORKOS_REDIRECT_URI');
+  const matchesDevServer = devCallback && configured ? sameOrigin(configured, devCallback) : null;
+  return { devCallback, configured, matchesDevServer };
+}
+
+function sameOrigin(a: string, b: string): boolean {
+  try {
+    const [left, right] = [new URL(a), new URL(b)];
+    const local = (host: string) => (host === '127.0.0.1' ? 'localhost' : host);
+    return left.protocol === right.protocol && local(left.host) === local(right.host);
+  } catch {
+    return false;
+  }
+}
+
+/**
+ * Whether a registered redirect URI accepts the dev server's callback. WorkOS
+ * allows one `*` wildcard, which matches within a single host label or
+ * path segment.
+ */
+export function redirectUriCovers(registered: string, callback: string): boolean {
+  const pattern = registered
+    .split('*')
+    .map((part) => part.replace(/[.+?^${}()|[\]\\]/g, '\\$&'))
+    .join('[^./]*');
+  return new RegExp(`^${pattern}/?$`).test(callback.replace(/\/$/, ''));
+}
+
+function installedSkills(lock: SkillLock, bundledDigests: Record<string, string>): InstalledSkillStatus[] {
+  return Object.entries(lock.skills)
+    .map(([key, entry]) => {
+      const [agent, skill] = key.split('/');
+      const bundled = bundledDigests[skill];
+      return {
+        agent,
+        skill,
+        source: entry.source,
+        installedAt: entry.installedAt,
+        // Only skills from the CLI's own bundle can be compared with it
+        updateAvailable: bundled !== undefined && entry.source === 'workos' && bundled !== entry.digest,
+      };
+    })
+    .sort((a, b) => a.skill.localeCompare(b.skill) || a.agent.localeCompare(b.agent));
+}
+
+export function collectProjectStatus(installDir: string, inputs: ProjectStatusInputs): ProjectStatus {
+  const record = readInstallRecord(installDir);
+  const integration = record?.integration ?? null;
+  const { files, values } = readEnvFiles(installDir);
+  const present = Object.keys(values).filter((key) => values[key]);
+
+  const expected = inputs.skillPrompt ? expectedEnvKeys(inputs.skillPrompt) : null;
+  const missing = (expected ?? []).filter((key) => envValue(values, baseKey(key)) === null);
+
+  return {
+    installDir,
+    integration,
+    installedAt: record?.installedAt ?? null,
+    skill: inputs.skill ?? null,
+    skills: installedSkills(inputs.lock, inputs.bundledDigests),
+    sdks: findSdks(installDir),
+    env: { files, present, expected, missing },
+    environment: bindEnvironment(values, inputs.config),
+    redirect: redirectStatus(installDir, integration, values),
+  };
+}
+
+/** The skill prompt of a skill the CLI ships, or undefined */
+export function readSkillPrompt(skillsDir: string, skill: string): string | undefined {
+  const path = join(skillsDir, skill, 'SKILL.md');
+  return existsSync(path) ? (read(path) ?? undefined) : undefined;
+}
+
+/** The API key the project's env files give the app, so --remote reads the environment the app uses */
+export function projectApiKey(installDir: string): string | null {
+  return envValue(readEnvFiles(installDir).values, 'WORKOS_API_KEY');
+}
+
+/**
+ * Add the environment's registered redirect URIs to the status, and whether
+ * they accept the dev server's callback. A failed read is recorded rather
+ * than thrown, so the local status still prints.
+ */
+export async function checkRegisteredRedirects(
+  redirect: RedirectStatus,
+  api: { apiKey: string; baseUrl?: string },
+): Promise<RedirectStatus> {
+  try {
+    const registered = (await listAll<{ uri: string }>({ path: '/user_management/redirect_uris', ...api })).map(
+      (r) => r.uri,
+    );
+    const { devCallback } = redirect;
+    const registeredCoverDevServer = devCallback ? registered.some((uri) => redirectUriCovers(uri, devCallback)) : null;
+    return { ...redirect, registered, registeredCoverDevServer };
+  } catch (error) {
+    return { ...redirect, remoteError: error instanceof Error ? error.message : String(error) };
+  }
+}
@@ -0,0 +1,129 @@
+/**
+ * HTTP(S) proxy support for the CLI's own requests.
+ *
+ * Node's fetch ignores HTTP_PROXY/HTTPS_PROXY, so requests are routed
+ * through an undici ProxyAgent when a proxy applies. The global --proxy flag
+ * overrides the environment and applies to every host; otherwise
+ * HTTPS_PROXY/HTTP_PROXY (either case) are used unless NO_PROXY matches.
+ */
+
+import { ProxyAgent } from 'undici';
+
+export interface EffectiveProxy {
+  url: string;
+  source: '--proxy' | 'HTTPS_PROXY' | 'HTTP_PROXY';
+}
+
+let proxyOverride: string | undefined;
+
+/** Set from the global --proxy flag; undefined clears it */
+export function setProxyOverride(url: string | undefined): void {
+  if (url !== undefined && !URL.canParse(url)) throw new Error(`Invalid --proxy URL: ${url}`);
+  proxyOverride = url;
+}
+
+function readEnv(env: NodeJS.ProcessEnv, name: string): string | undefined {
+  return env[name] || env[name.toLowerCase()] || undefined;
+}
+
+/**
+ * Whether NO_PROXY excludes the target. Entries are hostnames or domain
+ * suffixes (`example.com` and `.example.com` both match subdomains),
+ * optionally with a port; `*` disables proxying entirely.
+ */
+export function matchesNoProxy(target: URL, noProxy: string | undefined): boolean {
+  if (!noProxy) return false;
+  const host = target.hostname.toLowerCase();
+  const port = target.port || (target.protocol === 'https:' ? '443' : '80');
+
+  return noProxy
+    .split(/[\s,]+/)
+    .filter(Boolean)
+    .some((entry) => {
+      if (entry === '*') return true;
+      const [entryHost, entryPort] = entry.toLowerCase().replace(/^\*?\./, '').split(':');
+      if (entryPort && entryPort !== port) return false;
+      return host === entryHost || host.endsWith(`.${entryHost}`);
+    });
+}
+
+/**
+ * The proxy a request to `target` goes through, if any.
+ */
+export function resolveProxy(target: string, env: NodeJS.ProcessEnv = process.env): EffectiveProxy | null {
+  if (proxyOverride) return { url: proxyOverride, source: '--proxy' };
+
+  let url: URL;
+  try {
+    url = new URL(target);
+  } catch {
+    // Not a URL; fetch reports that itself
+    return null;
+  }
+  if (matchesNoProxy(url, readEnv(env, 'NO_PROXY'))) return null;
+
+  const https = url.protocol === 'https:' ? readEnv(env, 'HTTPS_PROXY') : undefined;
+  if (https) return { url: https, source: 'HTTPS_PROXY' };
+  const http = readEnv(env, 'HTTP_PROXY');
+  return http ? { url: http, source: 'HTTP_PROXY' } : null;
+}
+
+const agents = new Map<string, ProxyAgent>();
+
+/**
+ * fetch() options routing `target` through its proxy; empty when no proxy
+ * applies.
+ */
+export function proxyFetchOptions(target: string): { dispatcher?: ProxyAgent } {
+  const proxy = resolveProxy(target);
+  if (!proxy) return {};
+  let agent = agents.get(proxy.url);
+  if (!agent) {
+    agent = new ProxyAgent(proxy.url);
+    agents.set(proxy.url, agent);
+  }
+  return { dispatcher: agent };
+}
+
+/**
+ * fetch() through the target's proxy, for requests that don't go through
+ * fetchWithRetry (OAuth, the npm registry, telemetry).
+ */
+export function proxiedFetch(url: string, init: RequestInit = {}): Promise<Response> {
+  return fetch(url, { ...init, ...proxyFetchOptions(url) } as RequestInit);
+}
+
+/** Credentials stripped, for logs and doctor output */
+export function redactProxyUrl(url: string): string {
+  try {
+    const parsed = new URL(url);
+    if (parsed.username || parsed.password) {
+      parsed.username = '***';
+      parsed.password = '';
+    }
+    return parsed.toString().replace(/\/$/, '');
+  } catch {
+    return url;
+  }
+}
+
+/**
+ * Explain a failed request when a proxy was involved, telling a refused
+ * proxy connection apart from a proxy that couldn't reach the upstream.
+ * Returns null when the error has nothing to do with the proxy.
+ */
+export function describeProxyError(error: unknown, proxy: EffectiveProxy | null): string | null {
+  if (!proxy || !(error instanceof Error)) return null;
+  const cause = error.cause as { code?: string; message?: string } | undefined;
+  const code = (error as { code?: string }).code ?? cause?.code;
+  const message = cause?.message ?? error.message;
+  const where = `${redactProxyUrl(proxy.url)} (from ${proxy.source})`;
+
+  if (code === 'ECONNREFUSED' || code === 'ENOTFOUND') {
+    return `could not connect to proxy ${where}: ${code}. Check the proxy address or unset ${proxy.source}`;
+  }
+  const status = /Proxy response \((\d+)\)/.exec(message)?.[1];
+  if (status === '407') return `proxy ${where} requires authentication (407). Add credentials to the proxy URL`;
+  if (status) return `proxy ${where} could not reach the upstream (HTTP ${status}); the service may be down`;
+  return null;
+}
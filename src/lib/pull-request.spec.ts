@@ -0,0 +1,163 @@
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import {
+  FORK_REMOTE,
+  getPullRequestBlocker,
+  parseGitHubRemote,
+  pushForPullRequest,
+  upsertPullRequest,
+  type GitHubRequest,
+} from './pull-request.js';
+
+vi.mock('node:child_process', () => ({
+  execFile: vi.fn(),
+  execFileSync: vi.fn(),
+}));
+
+vi.mock('../utils/git-utils.js', () => ({
+  getCurrentBranch: vi.fn(() => 'workos/authkit'),
+  getDefaultBranch: vi.fn(() => 'main'),
+}));
+
+const mockExecFileSync = vi.mocked(execFileSync);
+
+/** `git` calls keyed by their arguments; a function result throws or returns */
+function fakeGit(handlers: Record<string, () => string>) {
+  mockExecFileSync.mockImplementation(((_cmd: string, args: string[]) => {
+    const handler = handlers[args.join(' ')];
+    return Buffer.from(handler ? handler() : '');
+  }) as unknown as typeof execFileSync);
+}
+
+function pushError(stderr: string) {
+  return () => {
+    throw Object.assign(new Error('Command failed'), { stderr: Buffer.from(stderr) });
+  };
+}
+
+describe('pull-request', () => {
+  beforeEach(() => {
+    mockExecFileSync.mockReset();
+  });
+
+  describe('parseGitHubRemote', () => {
+    it('reads owner and repo from GitHub remotes', () => {
+      expect(parseGitHubRemote('git@github.com:acme/app.git')).toEqual({ owner: 'acme', repo: 'app' });
+      expect(parseGitHubRemote('https://github.com/acme/app')).toEqual({ owner: 'acme', repo: 'app' });
+      expect(parseGitHubRemote('ssh://git@github.com/acme/my.app.git')).toEqual({ owner: 'acme', repo: 'my.app' });
+    });
+
+    it('ignores other hosts', () => {
+      expect(parseGitHubRemote('git@gitlab.com:acme/app.git')).toBeNull();
+      expect(parseGitHubRemote('https://github.com.example.com/acme/app')).toBeNull();
+    });
+  });
+
+  describe('getPullRequestBlocker', () => {
+    it('skips remotes that are not on GitHub', () => {
+      fakeGit({ 'remote get-url origin': () => 'git@gitlab.com:acme/app.git' });
+
+      expect(getPullRequestBlocker({ hasGh: true })).toMatch(/origin isn't on GitHub/);
+    });
+
+    it('needs gh or GITHUB_TOKEN', () => {
+      fakeGit({ 'remote get-url origin': () => 'git@github.com:acme/app.git' });
+
+      expect(getPullRequestBlocker({ hasGh: false })).toMatch(/GITHUB_TOKEN/);
+      expect(getPullRequestBlocker({ hasGh: false, token: 'ghp_x' })).toBeNull();
+    });
+  });
+
+  describe('pushForPullRequest', () => {
+    const origin = { owner: 'acme', repo: 'app' };
+
+    it('pushes to origin when it can', async () => {
+      fakeGit({});
+      const confirmFork = vi.fn();
+
+      const pushed = await pushForPullRequest({ origin, branch: 'workos/authkit', request: vi.fn(), confirmFork });
+
+      expect(pushed).toEqual({ owner: 'acme', branch: 'workos/authkit' });
+      expect(confirmFork).not.toHaveBeenCalled();
+    });
+
+    it('pushes to a fork when origin refuses and the user agrees', async () => {
+      fakeGit({
+        'remote get-url origin': () => 'git@github.com:acme/app.git',
+        'push -u origin HEAD': pushError('ERROR: Permission to acme/app.git denied to octocat.'),
+      });
+      const request = vi.fn(async () => ({
+        owner: { login: 'octocat' },
+        clone_url: 'https://github.com/octocat/app.git',
+        ssh_url: 'git@github.com:octocat/app.git',
+      }));
+
+      const pushed = await pushForPullRequest({
+        origin,
+        branch: 'workos/authkit',
+        request,
+        confirmFork: async () => true,
+      });
+
+      expect(pushed).toEqual({ owner: 'octocat', branch: 'workos/authkit' });
+      expect(request).toHaveBeenCalledWith('POST', '/repos/acme/app/forks', {});
+      expect(mockExecFileSync).toHaveBeenCalledWith(
+        'git',
+        ['remote', 'add', FORK_REMOTE, 'git@github.com:octocat/app.git'],
+        expect.anything(),
+      );
+      expect(mockExecFileSync).toHaveBeenCalledWith('git', ['push', '-u', FORK_REMOTE, 'HEAD'], expect.anything());
+    });
+
+    it('keeps the push error when the fork is declined', async () => {
+      fakeGit({ 'push -u origin HEAD': pushError('remote: Permission to acme/app.git denied to octocat.') });
+      const request = vi.fn();
+
+      await expect(
+        pushForPullRequest({ origin, branch: 'workos/authkit', request, confirmFork: async () => false }),
+      ).rejects.toThrow(/No push access to origin/);
+      expect(request).not.toHaveBeenCalled();
+    });
+  });
+
+  describe('upsertPullRequest', () => {
+    const options = {
+      repo: { owner: 'acme', repo: 'app' },
+      head: { owner: 'octocat', branch: 'workos/authkit' },
+      base: 'main',
+      title: 'Add WorkOS AuthKit',
+      body: 'Report',
+    };
+
+    it('opens a PR when the branch has none', async () => {
+      const request = vi.fn<GitHubRequest>(async (method) =>
+        method === 'GET' ? [] : { number: 3, html_url: 'https://github.com/acme/app/pull/3' },
+      );
+
+      const result = await upsertPullRequest(request, options);
+
+      expect(result).toEqual({ url: 'https://github.com/acme/app/pull/3', updated: false });
+      expect(request).toHaveBeenCalledWith('POST', '/repos/acme/app/pulls', {
+        title: 'Add WorkOS AuthKit',
+        body: 'Report',
+        head: 'octocat:workos/authkit',
+        base: 'main',
+      });
+    });
+
+    it('updates the PR already open for the branch', async () => {
+      const pr = { number: 7, html_url: 'https://github.com/acme/app/pull/7' };
+      const request = vi.fn<GitHubRequest>(async (method) => (method === 'GET' ? [pr] : pr));
+
+      const result = await upsertPullRequest(request, options);
+
+      expect(result).toEqual({ url: 'https://github.com/acme/app/pull/7', updated: true });
+      expect(request).toHaveBeenCalledWith('GET', '/repos/acme/app/pulls?state=open&head=octocat%3Aworkos%2Fauthkit');
+      expect(request).toHaveBeenCalledWith('PATCH', '/repos/acme/app/pulls/7', {
+        title: 'Add WorkOS AuthKit',
+        body: 'Report',
+      });
+      expect(request).not.toHaveBeenCalledWith('POST', expect.anything(), expect.anything());
+    });
+  });
+});
@@ -0,0 +1,216 @@
+/**
+ * Pull requests for an install branch (`--create-pr`, or yes at the PR
+ * prompt): push the branch and open its PR on GitHub, or update the PR when
+ * the branch already has one.
+ *
+ * GitHub is reached through `gh api` when the GitHub CLI is installed, so
+ * its login is used, and otherwise through the REST API with GITHUB_TOKEN.
+ * When origin doesn't take the push, the branch can go to a fork instead and
+ * the PR is opened from there.
+ */
+
+import { execFile, execFileSync } from 'node:child_process';
+import { fetchWithRetry } from './http-retry.js';
+import { getCurrentBranch, getDefaultBranch } from '../utils/git-utils.js';
+
+const GITHUB_API = 'https://api.github.com';
+export const FORK_REMOTE = 'workos-fork';
+
+/** scp-style (`git@github.com:`) or URL-style (`https://`, `ssh://`, `git://`) remotes on github.com */
+const GITHUB_REMOTE_PATTERN = /^(?:[a-z+]+:\/\/(?:[^@/]+@)?|[^@/]+@)github\.com[:/]([^/]+)\/([^/]+?)(?:\.git)?\/?$/;
+
+export interface GitHubRepo {
+  owner: string;
+  repo: string;
+}
+
+/**
+ * `git@github.com:acme/app.git`, `https://github.com/acme/app`,
+ * `ssh://git@github.com/acme/app.git` -> `{ owner: 'acme', repo: 'app' }`.
+ * Null for anything not on github.com.
+ */
+export function parseGitHubRemote(url: string): GitHubRepo | null {
+  const match = GITHUB_REMOTE_PATTERN.exec(url.trim());
+  return match ? { owner: match[1], repo: match[2] } : null;
+}
+
+/**
+ * The origin remote's URL and its GitHub repo, or null without an origin.
+ */
+export function getOriginRemote(cwd?: string): { url: string; github: GitHubRepo | null } | null {
+  try {
+    const url = execFileSync('git', ['remote', 'get-url', 'origin'], { cwd, stdio: ['ignore', 'pipe', 'ignore'] })
+      .toString()
+      .trim();
+    return { url, github: parseGitHubRemote(url) };
+  } catch {
+    return null;
+  }
+}
+
+/** A GitHub REST call; resolves to the parsed JSON response */
+export type GitHubRequest = (method: 'GET' | 'POST' | 'PATCH', path: string, body?: object) => Promise<unknown>;
+
+function ghApi(cwd?: string): GitHubRequest {
+  return (method, path, body) =>
+    new Promise((resolve, reject) => {
+      const args = ['api', '-X', method, path, ...(body ? ['--input', '-'] : [])];
+      const child = execFile('gh', args, { cwd }, (error, stdout, stderr) => {
+        if (error) reject(new Error(stderr.trim() || error.message));
+        else resolve(stdout.trim() ? JSON.parse(stdout) : null);
+      });
+      child.stdin?.end(body ? JSON.stringify(body) : undefined);
+    });
+}
+
+function tokenApi(token: string): GitHubRequest {
+  return async (method, path, body) => {
+    const { response } = await fetchWithRetry(`${GITHUB_API}${path}`, {
+      method,
+      headers: {
+        Authorization: `Bearer ${token}`,
+        Accept: 'application/vnd.github+json',
+        'Content-Type': 'application/json',
+      },
+      body: body ? JSON.stringify(body) : undefined,
+    });
+    const data = (await response.json().catch(() => null)) as { message?: string } | null;
+    if (!response.ok) throw new Error(`GitHub API ${response.status}: ${data?.message ?? response.statusText}`);
+    return data;
+  };
+}
+
+/**
+ * How to reach GitHub: the gh CLI when it's installed, else GITHUB_TOKEN.
+ * Null when neither is available.
+ */
+export function getGitHubClient(options: { hasGh: boolean; token?: string; cwd?: string }): GitHubRequest | null {
+  if (options.hasGh) return ghApi(options.cwd);
+  if (options.token) return tokenApi(options.token);
+  return null;
+}
+
+/**
+ * Why a PR can't be opened for the current branch, or null when it can.
+ */
+export function getPullRequestBlocker(options: { hasGh: boolean; token?: string; cwd?: string }): string | null {
+  const origin = getOriginRemote(options.cwd);
+  if (!origin) return 'there is no origin remote to push to';
+  if (!origin.github) return "origin isn't on GitHub; push the branch and open the pull request yourself";
+  const branch = getCurrentBranch();
+  if (!branch || branch === getDefaultBranch()) {
+    return `the changes were committed to ${branch ?? 'a detached HEAD'}, not a feature branch`;
+  }
+  if (!options.hasGh && !options.token) return 'neither the GitHub CLI (gh) nor GITHUB_TOKEN is available';
+  return null;
+}
+
+/** origin refused the push for lack of access */
+export class PushAccessError extends Error {
+  constructor(
+    readonly remote: string,
+    detail: string,
+  ) {
+    super(`No push access to ${remote}: ${detail}`);
+    this.name = 'PushAccessError';
+  }
+}
+
+const ACCESS_DENIED_PATTERN = /permission to .* denied|403|access denied|not allowed to push/i;
+
+export function pushToRemote(remote: string, cwd?: string): void {
+  try {
+    execFileSync('git', ['push', '-u', remote, 'HEAD'], { cwd, stdio: 'pipe' });
+  } catch (error) {
+    const stderr = (error as { stderr?: Buffer }).stderr?.toString().trim() ?? '';
+    if (ACCESS_DENIED_PATTERN.test(stderr)) throw new PushAccessError(remote, stderr.split('\n')[0]);
+    throw new Error(stderr || (error instanceof Error ? error.message : String(error)));
+  }
+}
+
+/**
+ * Fork `upstream` to the authenticated user and add the fork as a remote.
+ * Returns the fork's owner.
+ */
+export async function forkRepository(request: GitHubRequest, upstream: GitHubRepo, cwd?: string): Promise<string> {
+  const fork = (await request('POST', `/repos/${upstream.owner}/${upstream.repo}/forks`, {})) as {
+    owner: { login: string };
+    clone_url: string;
+    ssh_url: string;
+  };
+  const originUrl = getOriginRemote(cwd)?.url ?? '';
+  // Push to the fork the same way origin is pushed to
+  const url = originUrl.startsWith('https://') ? fork.clone_url : fork.ssh_url;
+  try {
+    execFileSync('git', ['remote', 'add', FORK_REMOTE, url], { cwd, stdio: 'ignore' });
+  } catch {
+    execFileSync('git', ['remote', 'set-url', FORK_REMOTE, url], { cwd, stdio: 'ignore' });
+  }
+  return fork.owner.login;
+}
+
+/** Where the install branch was pushed */
+export interface PushedBranch {
+  /** Owner of the repo holding the branch: origin's, or the fork's */
+  owner: string;
+  branch: string;
+}
+
+/**
+ * Push HEAD to origin. When origin refuses it and `confirmFork` agrees,
+ * fork the repo and push there instead; GitHub takes a moment to create a
+ * fork, so the first pushes to it may be retried.
+ */
+export async function pushForPullRequest(options: {
+  origin: GitHubRepo;
+  branch: string;
+  request: GitHubRequest | null;
+  confirmFork: () => Promise<boolean>;
+  cwd?: string;
+  retryDelayMs?: number;
+}): Promise<PushedBranch> {
+  const { origin, branch, request, cwd, retryDelayMs = 3000 } = options;
+  try {
+    pushToRemote('origin', cwd);
+    return { owner: origin.owner, branch };
+  } catch (error) {
+    if (!(error instanceof PushAccessError) || !request || !(await options.confirmFork())) throw error;
+  }
+
+  const owner = await forkRepository(request, origin, cwd);
+  for (let attempt = 1; ; attempt++) {
+    try {
+      pushToRemote(FORK_REMOTE, cwd);
+      return { owner, branch };
+    } catch (error) {
+      if (attempt >= 5) throw error;
+      await new Promise((resolve) => setTimeout(resolve, retryDelayMs));
+    }
+  }
+}
+
+interface PullRequest {
+  number: number;
+  html_url: string;
+}
+
+/**
+ * Open the PR for `head`, or update the title and body of the one already
+ * open for it.
+ */
+export async function upsertPullRequest(
+  request: GitHubRequest,
+  options: { repo: GitHubRepo; head: PushedBranch; base: string; title: string; body: string },
+): Promise<{ url: string; updated: boolean }> {
+  const { repo, head, base, title, body } = options;
+  const path = `/repos/${repo.owner}/${repo.repo}/pulls`;
+  const headRef = `${head.owner}:${head.branch}`;
+
+  const open = (await request('GET', `${path}?state=open&head=${encodeURIComponent(headRef)}`)) as PullRequest[];
+  if (open.length > 0) {
+    const pr = (await request('PATCH', `${path}/${open[0].number}`, { title, body })) as PullRequest;
+    return { url: pr.html_url, updated: true };
+  }
+  const pr = (await request('POST', path, { title, body, head: headRef, base })) as PullRequest;
+  return { url: pr.html_url, updated: false };
+}
@@ -0,0 +1,61 @@
+import { describe, it, expect } from 'vitest';
+import { encodeQr, renderQr } from './qr-code.js';
+
+const URI = 'otpauth://totp/WorkOS:test%40example.com?secret=JBSWY3DPEHPK3PXP&issuer=WorkOS';
+
+/** The 7x7 finder pattern with its top-left corner at (x, y) */
+function finderAt(modules: boolean[][], x: number, y: number): string {
+  return modules
+    .slice(y, y + 7)
+    .map((row) => row.slice(x, x + 7).map((dark) => (dark ? '#' : '.')).join(''))
+    .join('\n');
+}
+
+const FINDER = ['#######', '#.....#', '#.###.#', '#.###.#', '#.###.#', '#.....#', '#######'].join('\n');
+
+describe('encodeQr', () => {
+  it('picks the smallest version that fits', () => {
+    expect(encodeQr('hello')).toHaveLength(21);
+    expect(encodeQr(URI)).toHaveLength(37);
+  });
+
+  it('draws the three finder patterns', () => {
+    const modules = encodeQr(URI);
+    const far = modules.length - 7;
+    expect(finderAt(modules, 0, 0)).toBe(FINDER);
+    expect(finderAt(modules, far, 0)).toBe(FINDER);
+    expect(finderAt(modules, 0, far)).toBe(FINDER);
+  });
+
+  it('draws the timing patterns and the dark module', () => {
+    const modules = encodeQr(URI);
+    for (let i = 8; i < modules.length - 8; i++) {
+      expect(modules[6][i]).toBe(i % 2 === 0);
+      expect(modules[i][6]).toBe(i % 2 === 0);
+    }
+    expect(modules[modules.length - 8][8]).toBe(true);
+  });
+
+  it('is deterministic', () => {
+    expect(encodeQr(URI)).toEqual(encodeQr(URI));
+  });
+
+  it('refuses text too long for version 10', () => {
+    expect(() => encodeQr('x'.repeat(300))).toThrow('Too long');
+  });
+});
+
+describe('renderQr', () => {
+  it('packs two rows per line inside a quiet zone', () => {
+    const lines = renderQr(encodeQr('hello')).split('\n');
+    // 21 modules + 2 on each side = 25 rows, two per line
+    expect(lines).toHaveLength(13);
+    expect(lines.every((line) => line.length === 25)).toBe(true);
+    expect(lines[0].trim()).toBe('');
+    expect(lines[1].slice(2, 9)).toBe('█▀▀▀▀▀█');
+  });
+
+  it('paints each line', () => {
+    expect(renderQr(encodeQr('hello'), (line) => `[${line}]`).split('\n')[0]).toMatch(/^\[ +\]$/);
+  });
+});
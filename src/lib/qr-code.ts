@@ -0,0 +1,346 @@
+/**
+ * Minimal QR code encoder for printing otpauth:// URIs in the terminal.
+ *
+ * Byte mode, error correction level M, versions 1-10 (up to 213 bytes,
+ * plenty for a TOTP enrollment URI). The structure follows ISO/IEC 18004:
+ * data and Reed-Solomon codewords are interleaved into blocks, placed in the
+ * zigzag order around the finder, timing and alignment patterns, and the
+ * mask with the lowest penalty is kept.
+ */
+
+/** Error correction codewords per block for level M, by version */
+const ECC_CODEWORDS_PER_BLOCK = [0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26];
+/** Error correction blocks for level M, by version */
+const ECC_BLOCKS = [0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5];
+const MAX_VERSION = 10;
+/** Format information bits for level M */
+const ECC_LEVEL_M = 0b00;
+
+type Grid = boolean[][];
+
+function bit(value: number, index: number): boolean {
+  return ((value >>> index) & 1) !== 0;
+}
+
+/** Modules available for data and error correction in a version */
+function rawDataModules(version: number): number {
+  let result = (16 * version + 128) * version + 64;
+  if (version >= 2) {
+    const alignments = Math.floor(version / 7) + 2;
+    result -= (25 * alignments - 10) * alignments - 55;
+    if (version >= 7) result -= 36;
+  }
+  return result;
+}
+
+function dataCodewords(version: number): number {
+  return Math.floor(rawDataModules(version) / 8) - ECC_CODEWORDS_PER_BLOCK[version] * ECC_BLOCKS[version];
+}
+
+function alignmentPositions(version: number): number[] {
+  if (version === 1) return [];
+  const count = Math.floor(version / 7) + 2;
+  const size = version * 4 + 17;
+  const step = Math.ceil((version * 4 + 4) / (count * 2 - 2)) * 2;
+  const positions = [6];
+  for (let pos = size - 7; positions.length < count; pos -= step) positions.splice(1, 0, pos);
+  return positions;
+}
+
+// GF(256) arithmetic with the QR polynomial x^8 + x^4 + x^3 + x^2 + 1
+function gfMultiply(x: number, y: number): number {
+  let z = 0;
+  for (let i = 7; i >= 0; i--) {
+    z = (z << 1) ^ ((z >>> 7) * 0x11d);
+    z ^= ((y >>> i) & 1) * x;
+  }
+  return z;
+}
+
+function reedSolomonDivisor(degree: number): number[] {
+  const result = new Array<number>(degree).fill(0);
+  result[degree - 1] = 1;
+  let root = 1;
+  for (let i = 0; i < degree; i++) {
+    for (let j = 0; j < result.length; j++) {
+      result[j] = gfMultiply(result[j], root);
+      if (j + 1 < result.length) result[j] ^= result[j + 1];
+    }
+    root = gfMultiply(root, 0x02);
+  }
+  return result;
+}
+
+function reedSolomonRemainder(data: number[], divisor: number[]): number[] {
+  const result = new Array<number>(divisor.length).fill(0);
+  for (const byte of data) {
+    const factor = byte ^ (result.shift() as number);
+    result.push(0);
+    divisor.forEach((coefficient, i) => (result[i] ^= gfMultiply(coefficient, factor)));
+  }
+  return result;
+}
+
+/** Mode, length, data, terminator and padding, as codewords */
+function encodeData(bytes: Uint8Array, version: number): number[] {
+  const capacityBits = dataCodewords(version) * 8;
+  const bits: number[] = [];
+  const append = (value: number, length: number) => {
+    for (let i = length - 1; i >= 0; i--) bits.push((value >>> i) & 1);
+  };
+  append(0b0100, 4);
+  append(bytes.length, version < 10 ? 8 : 16);
+  bytes.forEach((byte) => append(byte, 8));
+  append(0, Math.min(4, capacityBits - bits.length));
+  append(0, (8 - (bits.length % 8)) % 8);
+  for (let pad = 0xec; bits.length < capacityBits; pad ^= 0xec ^ 0x11) append(pad, 8);
+
+  const codewords: number[] = [];
+  for (let i = 0; i < bits.length; i += 8) {
+    codewords.push(bits.slice(i, i + 8).reduce((byte, b) => (byte << 1) | b, 0));
+  }
+  return codewords;
+}
+
+/** Split into blocks, add error correction to each and interleave */
+function addErrorCorrection(data: number[], version: number): number[] {
+  const blockCount = ECC_BLOCKS[version];
+  const eccLength = ECC_CODEWORDS_PER_BLOCK[version];
+  const rawCodewords = Math.floor(rawDataModules(version) / 8);
+  const shortBlocks = blockCount - (rawCodewords % blockCount);
+  const shortBlockLength = Math.floor(rawCodewords / blockCount);
+  const divisor = reedSolomonDivisor(eccLength);
+
+  const blocks: number[][] = [];
+  for (let i = 0, offset = 0; i < blockCount; i++) {
+    const block = data.slice(offset, offset + shortBlockLength - eccLength + (i < shortBlocks ? 0 : 1));
+    offset += block.length;
+    const ecc = reedSolomonRemainder(block, divisor);
+    // Short blocks get a placeholder so every block lines up for interleaving
+    if (i < shortBlocks) block.push(0);
+    blocks.push(block.concat(ecc));
+  }
+
+  const result: number[] = [];
+  for (let i = 0; i < blocks[0].length; i++) {
+    blocks.forEach((block, j) => {
+      if (i !== shortBlockLength - eccLength || j >= shortBlocks) result.push(block[i]);
+    });
+  }
+  return result;
+}
+
+class QrBuilder {
+  readonly size: number;
+  readonly modules: Grid;
+  private readonly reserved: Grid;
+
+  constructor(readonly version: number) {
+    this.size = version * 4 + 17;
+    this.modules = Array.from({ length: this.size }, () => new Array<boolean>(this.size).fill(false));
+    this.reserved = Array.from({ length: this.size }, () => new Array<boolean>(this.size).fill(false));
+  }
+
+  private setFunction(x: number, y: number, dark: boolean): void {
+    this.modules[y][x] = dark;
+    this.reserved[y][x] = true;
+  }
+
+  drawFunctionPatterns(): void {
+    for (let i = 0; i < this.size; i++) {
+      this.setFunction(6, i, i % 2 === 0);
+      this.setFunction(i, 6, i % 2 === 0);
+    }
+    this.drawFinder(3, 3);
+    this.drawFinder(this.size - 4, 3);
+    this.drawFinder(3, this.size - 4);
+
+    const positions = alignmentPositions(this.version);
+    const last = positions.length - 1;
+    positions.forEach((x, i) =>
+      positions.forEach((y, j) => {
+        // The three corners are taken by finder patterns
+        if ((i === 0 && j === 0) || (i === 0 && j === last) || (i === last && j === 0)) return;
+        this.drawAlignment(x, y);
+      }),
+    );
+
+    this.drawFormatBits(0);
+    this.drawVersion();
+  }
+
+  private drawFinder(cx: number, cy: number): void {
+    for (let dy = -4; dy <= 4; dy++) {
+      for (let dx = -4; dx <= 4; dx++) {
+        const x = cx + dx;
+        const y = cy + dy;
+        if (x < 0 || x >= this.size || y < 0 || y >= this.size) continue;
+        const distance = Math.max(Math.abs(dx), Math.abs(dy));
+        this.setFunction(x, y, distance !== 2 && distance !== 4);
+      }
+    }
+  }
+
+  private drawAlignment(cx: number, cy: number): void {
+    for (let dy = -2; dy <= 2; dy++) {
+      for (let dx = -2; dx <= 2; dx++) {
+        this.setFunction(cx + dx, cy + dy, Math.max(Math.abs(dx), Math.abs(dy)) !== 1);
+      }
+    }
+  }
+
+  drawFormatBits(mask: number): void {
+    const data = (ECC_LEVEL_M << 3) | mask;
+    let remainder = data;
+    for (let i = 0; i < 10; i++) remainder = (remainder << 1) ^ ((remainder >>> 9) * 0x537);
+    const bits = ((data << 10) | remainder) ^ 0x5412;
+
+    for (let i = 0; i <= 5; i++) this.setFunction(8, i, bit(bits, i));
+    this.setFunction(8, 7, bit(bits, 6));
+    this.setFunction(8, 8, bit(bits, 7));
+    this.setFunction(7, 8, bit(bits, 8));
+    for (let i = 9; i < 15; i++) this.setFunction(14 - i, 8, bit(bits, i));
+
+    for (let i = 0; i < 8; i++) this.setFunction(this.size - 1 - i, 8, bit(bits, i));
+    for (let i = 8; i < 15; i++) this.setFunction(8, this.size - 15 + i, bit(bits, i));
+    this.setFunction(8, this.size - 8, true);
+  }
+
+  private drawVersion(): void {
+    if (this.version < 7) return;
+    let remainder = this.version;
+    for (let i = 0; i < 12; i++) remainder = (remainder << 1) ^ ((remainder >>> 11) * 0x1f25);
+    const bits = (this.version << 12) | remainder;
+    for (let i = 0; i < 18; i++) {
+      const a = this.size - 11 + (i % 3);
+      const b = Math.floor(i / 3);
+      this.setFunction(a, b, bit(bits, i));
+      this.setFunction(b, a, bit(bits, i));
+    }
+  }
+
+  /** Place codewords in the zigzag from the bottom-right corner, skipping function patterns */
+  drawCodewords(codewords: number[]): void {
+    let i = 0;
+    for (let right = this.size - 1; right >= 1; right -= 2) {
+      if (right === 6) right = 5;
+      for (let vert = 0; vert < this.size; vert++) {
+        for (let j = 0; j < 2; j++) {
+          const x = right - j;
+          const upward = ((right + 1) & 2) === 0;
+          const y = upward ? this.size - 1 - vert : vert;
+          if (!this.reserved[y][x] && i < codewords.length * 8) {
+            this.modules[y][x] = bit(codewords[i >>> 3], 7 - (i & 7));
+            i++;
+          }
+        }
+      }
+    }
+  }
+
+  /** XOR a mask pattern over the data modules; applying it twice undoes it */
+  applyMask(mask: number): void {
+    for (let y = 0; y < this.size; y++) {
+      for (let x = 0; x < this.size; x++) {
+        if (this.reserved[y][x]) continue;
+        const conditions = [
+          (x + y) % 2 === 0,
+          y % 2 === 0,
+          x % 3 === 0,
+          (x + y) % 3 === 0,
+          (Math.floor(x / 3) + Math.floor(y / 2)) % 2 === 0,
+          ((x * y) % 2) + ((x * y) % 3) === 0,
+          (((x * y) % 2) + ((x * y) % 3)) % 2 === 0,
+          (((x + y) % 2) + ((x * y) % 3)) % 2 === 0,
+        ];
+        if (conditions[mask]) this.modules[y][x] = !this.modules[y][x];
+      }
+    }
+  }
+
+  /** Runs of 5+ same-colour modules, 2x2 blocks and dark/light imbalance (penalty rules 1, 2 and 4) */
+  penalty(): number {
+    let score = 0;
+    const grid = this.modules;
+    for (let a = 0; a < this.size; a++) {
+      let rowRun = 1;
+      let columnRun = 1;
+      for (let b = 1; b < this.size; b++) {
+        rowRun = grid[a][b] === grid[a][b - 1] ? rowRun + 1 : 1;
+        if (rowRun === 5) score += 3;
+        else if (rowRun > 5) score += 1;
+        columnRun = grid[b][a] === grid[b - 1][a] ? columnRun + 1 : 1;
+        if (columnRun === 5) score += 3;
+        else if (columnRun > 5) score += 1;
+      }
+    }
+    let dark = 0;
+    for (let y = 0; y < this.size; y++) {
+      for (let x = 0; x < this.size; x++) {
+        if (grid[y][x]) dark++;
+        if (
+          x > 0 &&
+          y > 0 &&
+          grid[y][x] === grid[y][x - 1] &&
+          grid[y][x] === grid[y - 1][x] &&
+          grid[y][x] === grid[y - 1][x - 1]
+        ) {
+          score += 3;
+        }
+      }
+    }
+    const total = this.size * this.size;
+    score += Math.floor(Math.abs(dark * 20 - total * 10) / total) * 10;
+    return score;
+  }
+}
+
+/** The module grid for `text` (true is dark), without the quiet zone */
+export function encodeQr(text: string): Grid {
+  const bytes = new TextEncoder().encode(text);
+  let version = 1;
+  while (version <= MAX_VERSION && dataCodewords(version) < bytes.length + (version < 10 ? 2 : 3)) version++;
+  if (version > MAX_VERSION) throw new Error(`Too long for a terminal QR code (${bytes.length} bytes)`);
+
+  const qr = new QrBuilder(version);
+  qr.drawFunctionPatterns();
+  qr.drawCodewords(addErrorCorrection(encodeData(bytes, version), version));
+
+  let best = 0;
+  let bestPenalty = Infinity;
+  for (let mask = 0; mask < 8; mask++) {
+    qr.applyMask(mask);
+    qr.drawFormatBits(mask);
+    const penalty = qr.penalty();
+    if (penalty < bestPenalty) {
+      best = mask;
+      bestPenalty = penalty;
+    }
+    qr.applyMask(mask);
+  }
+  qr.applyMask(best);
+  qr.drawFormatBits(best);
+  return qr.modules;
+}
+
+/**
+ * Two module rows per line with half-block characters, dark on light with a
+ * quiet zone, so it scans on dark terminal themes too. `paint` colours each
+ * line (black on white); without colour the blocks are the terminal's
+ * foreground colour.
+ */
+export function renderQr(modules: Grid, paint: (line: string) => string = (line) => line, quietZone = 2): string {
+  const size = modules.length + quietZone * 2;
+  const dark = (x: number, y: number) => modules[y - quietZone]?.[x - quietZone] === true;
+  const lines: string[] = [];
+  for (let y = 0; y < size; y += 2) {
+    let line = '';
+    for (let x = 0; x < size; x++) {
+      const top = dark(x, y);
+      const bottom = y + 1 < size && dark(x, y + 1);
+      line += top && bottom ? '█' : top ? '▀' : bottom ? '▄' : ' ';
+    }
+    lines.push(paint(line));
+  }
+  return lines.join('\n');
+}
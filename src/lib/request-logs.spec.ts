@@ -0,0 +1,114 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { setHttpRetries } from './http-retry.js';
+import {
+  followRequestLogs,
+  getRequestLog,
+  listRequestLogs,
+  parseSince,
+  parseStatusFilter,
+  pathMatcher,
+  type RequestLog,
+} from './request-logs.js';
+
+const api = { apiKey: 'sk_test_abc', baseUrl: 'https://api.workos.com' };
+
+function log(id: string, status = 200, path = '/user_management/users'): RequestLog {
+  return { id, method: 'GET', path, status, latency_ms: 12, created_at: '2026-01-01T00:00:00Z' };
+}
+
+describe('request-logs', () => {
+  it('parses --since lookbacks and dates', () => {
+    const now = Date.parse('2026-01-02T00:00:00Z');
+
+    expect(parseSince('1h', now)).toBe('2026-01-01T23:00:00.000Z');
+    expect(parseSince('2026-01-01', now)).toBe('2026-01-01T00:00:00.000Z');
+    expect(() => parseSince('yesterday', now)).toThrow('Invalid date');
+  });
+
+  it('matches status classes, codes and ranges', () => {
+    const matches = parseStatusFilter('4xx, 500-502');
+
+    expect([200, 404, 429, 500, 502, 503].filter(matches)).toEqual([404, 429, 500, 502]);
+    expect(parseStatusFilter('201')(201)).toBe(true);
+    expect(() => parseStatusFilter('4x')).toThrow('Invalid --status "4x"');
+  });
+
+  it('matches path globs, ignoring the query string', () => {
+    const matches = pathMatcher('/user_management/*');
+
+    expect(matches('/user_management/users?limit=10')).toBe(true);
+    expect(matches('/organizations')).toBe(false);
+    expect(pathMatcher('/sso/profile')('/sso/profiles')).toBe(false);
+  });
+
+  describe('API', () => {
+    const mockFetch = vi.fn();
+    const originalFetch = globalThis.fetch;
+
+    function respond(body: unknown) {
+      mockFetch.mockResolvedValueOnce({ ok: true, status: 200, text: async () => JSON.stringify(body) });
+    }
+
+    function respondPage(data: RequestLog[], after: string | null = null) {
+      respond({ data, list_metadata: { before: null, after } });
+    }
+
+    beforeEach(() => {
+      globalThis.fetch = mockFetch;
+      mockFetch.mockReset();
+      setHttpRetries(0);
+    });
+
+    afterEach(() => {
+      globalThis.fetch = originalFetch;
+    });
+
+    it('filters across pages until the limit is filled', async () => {
+      respondPage([log('req_1'), log('req_2', 404)], 'req_2');
+      respondPage([log('req_3', 422, '/organizations'), log('req_4', 401), log('req_5', 400)], 'req_5');
+
+      const pages: RequestLog[][] = [];
+      for await (const page of listRequestLogs({ status: '4xx', path: '/user_management/*' }, api, 2)) {
+        pages.push(page);
+      }
+
+      expect(pages.map((page) => page.map((l) => l.id))).toEqual([['req_2'], ['req_4']]);
+      expect(mockFetch).toHaveBeenCalledTimes(2);
+    });
+
+    it('redacts credentials and leaves bodies out unless asked', async () => {
+      const detail = {
+        ...log('req_1'),
+        request_headers: { Authorization: 'Bearer sk_test_abc', 'Content-Type': 'application/json' },
+        request_body: { email: 'jane@example.com' },
+      };
+      respond(detail);
+      respond(detail);
+
+      const hidden = await getRequestLog('req_1', api);
+      const shown = await getRequestLog('req_1', api, { includeBody: true });
+
+      expect(hidden.request_headers).toEqual({ Authorization: '[redacted]', 'Content-Type': 'application/json' });
+      expect(hidden).not.toHaveProperty('request_body');
+      expect(shown.request_body).toEqual({ email: 'jane@example.com' });
+      expect(mockFetch.mock.calls[1][0]).toContain('include_body=true');
+    });
+
+    it('follows new requests from the last cursor', async () => {
+      const controller = new AbortController();
+      respondPage([log('req_1', 500)]);
+      respondPage([log('req_2', 200), log('req_3', 503)]);
+      const sleep = vi.fn(async () => {
+        if (sleep.mock.calls.length === 2) controller.abort();
+      });
+
+      const seen: string[] = [];
+      for await (const entry of followRequestLogs({ status: '5xx' }, api, { signal: controller.signal, sleep })) {
+        seen.push(entry.id);
+      }
+
+      expect(seen).toEqual(['req_1', 'req_3']);
+      expect(mockFetch.mock.calls[1][0]).toContain('after=req_1');
+    });
+  });
+});
@@ -0,0 +1,167 @@
+/**
+ * API request logs: recent requests made with the environment's keys, for
+ * debugging without the dashboard.
+ *
+ * `--status` and `--path` are applied to each page as it arrives, so a
+ * filtered listing may read several pages to fill its limit. Bodies are
+ * only returned when asked for, and credentials in headers never are.
+ */
+
+import { parseRange } from './workos-events.js';
+import { paginate } from './pagination.js';
+import { workosRequest, type WorkOSListResponse, type WorkOSRequestOptions } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface RequestLog {
+  id: string;
+  method: string;
+  path: string;
+  status: number;
+  latency_ms: number;
+  created_at: string;
+  ip_address?: string | null;
+  user_agent?: string | null;
+  request_headers?: Record<string, string>;
+  response_headers?: Record<string, string>;
+  request_body?: unknown;
+  response_body?: unknown;
+}
+
+export interface RequestLogsQuery {
+  rangeStart?: string;
+  /** e.g. `4xx`, `404`, `500-599`, comma-separated */
+  status?: string;
+  /** Glob where `*` matches anything, e.g. `/user_management/*` */
+  path?: string;
+  after?: string;
+}
+
+/** `--since` is a lookback (`30m`, `1h`, `7d`) or an ISO date */
+export function parseSince(since: string, now = Date.now()): string {
+  const value = since.trim();
+  const { rangeStart } = /^\d+[mhd]$/.test(value) ? parseRange(value, now) : parseRange(`${value}..`, now);
+  if (!rangeStart) throw new Error(`Invalid --since "${since}". Use a lookback like 1h or an ISO date.`);
+  return rangeStart;
+}
+
+/** Predicate for `--status`; throws on anything it can't read */
+export function parseStatusFilter(filter: string): (status: number) => boolean {
+  const checks = filter.split(',').map((part) => {
+    const value = part.trim().toLowerCase();
+    const klass = /^([1-5])xx$/.exec(value);
+    if (klass) return (status: number) => Math.floor(status / 100) === Number(klass[1]);
+    const range = /^(\d{3})-(\d{3})$/.exec(value);
+    if (range) return (status: number) => status >= Number(range[1]) && status <= Number(range[2]);
+    if (/^\d{3}$/.test(value)) return (status: number) => status === Number(value);
+    throw new Error(`Invalid --status "${part}". Use a class like 4xx, a code like 404 or a range like 500-599.`);
+  });
+  return (status) => checks.some((check) => check(status));
+}
+
+export function pathMatcher(glob: string): (path: string) => boolean {
+  const pattern = new RegExp(`^${glob.split('*').map((s) => s.replace(/[.+?^${}()|[\]\\]/g, '\\$&')).join('.*')}$`);
+  return (path) => pattern.test(path.split('?')[0]);
+}
+
+function matcher(query: RequestLogsQuery): (log: RequestLog) => boolean {
+  const status = query.status ? parseStatusFilter(query.status) : () => true;
+  const path = query.path ? pathMatcher(query.path) : () => true;
+  return (log) => status(log.status) && path(log.path);
+}
+
+function requestLogsRequest(query: RequestLogsQuery, api: ApiOptions): Omit<WorkOSRequestOptions, 'method'> {
+  return { path: '/request_logs', params: { range_start: query.rangeStart, after: query.after }, ...api };
+}
+
+/** Yield matching logs a page at a time until `limit` have been found */
+export async function* listRequestLogs(
+  query: RequestLogsQuery,
+  api: ApiOptions,
+  limit = Infinity,
+): AsyncGenerator<RequestLog[]> {
+  const matches = matcher(query);
+  let remaining = limit;
+  for await (const page of paginate<RequestLog>(requestLogsRequest(query, api))) {
+    const data = page.filter(matches).slice(0, remaining);
+    if (data.length > 0) yield data;
+    remaining -= data.length;
+    if (remaining <= 0) return;
+  }
+}
+
+/** Authorization and cookies are shown as `[redacted]` */
+const SENSITIVE_HEADER = /^(authorization|cookie|set-cookie|x-api-key)$/i;
+
+function redactHeaders(headers: Record<string, string> | undefined): Record<string, string> | undefined {
+  if (!headers) return undefined;
+  return Object.fromEntries(
+    Object.entries(headers).map(([name, value]) => [name, SENSITIVE_HEADER.test(name) ? '[redacted]' : value]),
+  );
+}
+
+/** One request with headers redacted, and bodies only when `includeBody` */
+export async function getRequestLog(
+  requestId: string,
+  api: ApiOptions,
+  options: { includeBody?: boolean } = {},
+): Promise<RequestLog> {
+  const log = await workosRequest<RequestLog>({
+    method: 'GET',
+    path: `/request_logs/${encodeURIComponent(requestId)}`,
+    ...(options.includeBody && { params: { include_body: 'true' } }),
+    ...api,
+  });
+  const { request_body, response_body, ...rest } = log;
+  return {
+    ...rest,
+    request_headers: redactHeaders(log.request_headers),
+    response_headers: redactHeaders(log.response_headers),
+    ...(options.includeBody && { request_body, response_body }),
+  };
+}
+
+export interface FollowOptions {
+  intervalMs?: number;
+  signal?: AbortSignal;
+  /** Injected by tests */
+  sleep?: (ms: number, signal?: AbortSignal) => Promise<void>;
+}
+
+function sleep(ms: number, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve) => {
+    const timer = setTimeout(resolve, ms);
+    signal?.addEventListener('abort', () => {
+      clearTimeout(timer);
+      resolve();
+    });
+  });
+}
+
+/**
+ * Yield matching logs as they arrive, paging forward from the query's
+ * cursor and polling once caught up, until the signal aborts.
+ */
+export async function* followRequestLogs(
+  query: RequestLogsQuery,
+  api: ApiOptions,
+  options: FollowOptions = {},
+): AsyncGenerator<RequestLog> {
+  const { intervalMs = 5_000, signal, sleep: wait = sleep } = options;
+  const matches = matcher(query);
+  let after = query.after;
+
+  while (!signal?.aborted) {
+    const page = await workosRequest<WorkOSListResponse<RequestLog>>({
+      method: 'GET',
+      ...requestLogsRequest({ ...query, after }, api),
+    });
+    for (const log of page.data) if (matches(log)) yield log;
+
+    after = page.list_metadata.after ?? page.data.at(-1)?.id ?? after;
+    if (!page.list_metadata.after) await wait(intervalMs, signal);
+  }
+}
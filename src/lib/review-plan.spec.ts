@@ -0,0 +1,80 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { formatPatch } from './diff-only.js';
+import { applyReviewFiles, envKeyChanges, loadReviewPlan } from './review-plan.js';
+
+describe('review-plan', () => {
+  let dir: string;
+  let patchPath: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'review-plan-'));
+    mkdirSync(join(dir, '.git'));
+    writeFileSync(join(dir, 'app.ts'), 'import { auth0 } from "./auth0";\nexport default auth0;\n');
+    writeFileSync(join(dir, '.env.local'), 'AUTH0_DOMAIN=tenant.auth0.com\nAPP_NAME=demo\n');
+    const patch = formatPatch(
+      [
+        {
+          path: join(dir, 'app.ts'),
+          before: readFileSync(join(dir, 'app.ts'), 'utf-8'),
+          after: 'import { authkit } from "./authkit";\nexport default authkit;\n',
+        },
+        {
+          path: join(dir, '.env.local'),
+          before: readFileSync(join(dir, '.env.local'), 'utf-8'),
+          after: 'WORKOS_CLIENT_ID=client_1\nAPP_NAME=demo-app\nWORKOS_API_KEY=\n',
+        },
+        { path: join(dir, 'src/authkit.ts'), before: null, after: 'export const authkit = {};\n' },
+      ],
+      dir,
+    );
+    patchPath = join(dir, 'migration.patch');
+    writeFileSync(patchPath, patch);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('recomputes each file of the patch against the tree', () => {
+    const plan = loadReviewPlan(patchPath, dir);
+
+    expect(plan.root).toBe(dir);
+    expect(plan.files.map((f) => [f.path, f.added, f.removed])).toEqual([
+      ['app.ts', 2, 2],
+      ['.env.local', 3, 2],
+      ['src/authkit.ts', 1, 0],
+    ]);
+    expect(plan.files[1].env).toEqual({
+      added: ['WORKOS_CLIENT_ID', 'WORKOS_API_KEY'],
+      changed: ['APP_NAME'],
+      removed: ['AUTH0_DOMAIN'],
+    });
+    expect(plan.files[2].change?.before).toBeNull();
+  });
+
+  it('marks files the patch no longer applies to', () => {
+    writeFileSync(join(dir, 'app.ts'), 'export default {};\n');
+
+    const [app] = loadReviewPlan(patchPath, dir).files;
+
+    expect(app).toMatchObject({ path: 'app.ts', change: null, added: 2, removed: 2 });
+  });
+
+  it('applies only the selected files and skips ones edited since', () => {
+    const plan = loadReviewPlan(patchPath, dir);
+    writeFileSync(join(dir, '.env.local'), 'APP_NAME=edited\n');
+
+    const result = applyReviewFiles(plan, ['src/authkit.ts', '.env.local']);
+
+    expect(result).toEqual({ applied: ['src/authkit.ts'], skipped: ['.env.local'] });
+    expect(readFileSync(join(dir, 'src/authkit.ts'), 'utf-8')).toBe('export const authkit = {};\n');
+    expect(readFileSync(join(dir, 'app.ts'), 'utf-8')).toContain('auth0');
+  });
+
+  it('treats every key of a new env file as added', () => {
+    expect(envKeyChanges(null, 'A=1\n')).toEqual({ added: ['A'], changed: [], removed: [] });
+  });
+});
@@ -0,0 +1,120 @@
+/**
+ * `workos review`: the changes a `--diff-only` patch would make, per file,
+ * recomputed against the working tree so each one can be applied on its
+ * own. Env files also get a key-level summary; like the patch, it never
+ * shows values.
+ */
+
+import { mkdirSync, readFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import { applyPatch, parsePatch, type StructuredPatch } from 'diff';
+import { writeFileAtomic } from './atomic-write.js';
+import { diffStats, formatUnifiedDiff, type ProposedChange } from './change-preview.js';
+import { isEnvFile, patchRoot } from './diff-only.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+
+export interface EnvKeyChanges {
+  added: string[];
+  changed: string[];
+  removed: string[];
+}
+
+export interface ReviewFile {
+  /** Relative to the patch root */
+  path: string;
+  /** Null when the patch no longer applies to the file */
+  change: ProposedChange | null;
+  added: number;
+  removed: number;
+  env?: EnvKeyChanges;
+}
+
+export interface ReviewPlan {
+  /** Directory the patch paths are relative to */
+  root: string;
+  files: ReviewFile[];
+}
+
+function readCurrent(path: string): string | null {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return null;
+  }
+}
+
+function stripPrefix(name: string | undefined): string | null {
+  if (!name || name === '/dev/null') return null;
+  return name.replace(/^[ab]\//, '');
+}
+
+/** Keys an env file change adds, changes and removes */
+export function envKeyChanges(before: string | null, after: string): EnvKeyChanges {
+  const old = parseEnvFile(before ?? '');
+  const next = parseEnvFile(after);
+  return {
+    added: Object.keys(next).filter((key) => !(key in old)),
+    changed: Object.keys(next).filter((key) => key in old && old[key] !== next[key]),
+    removed: Object.keys(old).filter((key) => !(key in next)),
+  };
+}
+
+function reviewFile(root: string, patch: StructuredPatch): ReviewFile | null {
+  const path = stripPrefix(patch.newFileName) ?? stripPrefix(patch.oldFileName);
+  if (!path) return null;
+  const absolute = join(root, path);
+  const isNew = stripPrefix(patch.oldFileName) === null;
+  const before = isNew ? null : readCurrent(absolute);
+  const after = isNew && readCurrent(absolute) !== null ? false : applyPatch(before ?? '', patch);
+  if (after === false) {
+    const counts = patch.hunks.flatMap((hunk) => hunk.lines);
+    return {
+      path,
+      change: null,
+      added: counts.filter((line) => line.startsWith('+')).length,
+      removed: counts.filter((line) => line.startsWith('-')).length,
+    };
+  }
+
+  const change = { path: absolute, before, after };
+  return {
+    path,
+    change,
+    ...diffStats(change),
+    ...(isEnvFile(path) && { env: envKeyChanges(before, after) }),
+  };
+}
+
+/** Parse a `--diff-only` patch and recompute its changes against the project */
+export function loadReviewPlan(patchPath: string, installDir: string): ReviewPlan {
+  const root = patchRoot(installDir);
+  const files = parsePatch(readFileSync(patchPath, 'utf-8'))
+    .map((patch) => reviewFile(root, patch))
+    .filter((file): file is ReviewFile => file !== null);
+  return { root, files };
+}
+
+/** The file's change as a plain unified diff, or null when it no longer applies */
+export function reviewDiff(plan: ReviewPlan, file: ReviewFile): string | null {
+  return file.change ? formatUnifiedDiff(file.change, plan.root, { color: false }) : null;
+}
+
+/**
+ * Write the selected files. A file that changed since the plan was loaded
+ * is skipped, so applying never overwrites edits made in the meantime.
+ */
+export function applyReviewFiles(plan: ReviewPlan, paths: string[]): { applied: string[]; skipped: string[] } {
+  const applied: string[] = [];
+  const skipped: string[] = [];
+  for (const path of paths) {
+    const change = plan.files.find((file) => file.path === path)?.change;
+    if (!change || readCurrent(change.path) !== change.before) {
+      skipped.push(path);
+      continue;
+    }
+    mkdirSync(dirname(change.path), { recursive: true });
+    writeFileAtomic(change.path, change.after);
+    applied.push(path);
+  }
+  return { applied, skipped };
+}
@@ -0,0 +1,54 @@
+import { describe, it, expect } from 'vitest';
+import { reviewPageHtml, serveReview } from './review-server.js';
+import type { ReviewPlan } from './review-plan.js';
+
+const PLAN: ReviewPlan = {
+  root: '/project',
+  files: [
+    {
+      path: 'app.ts',
+      change: { path: '/project/app.ts', before: 'a\n', after: 'b\n' },
+      added: 1,
+      removed: 1,
+    },
+    { path: 'lib/<old>.ts', change: null, added: 3, removed: 0 },
+  ],
+};
+const INFO = { provider: 'Auth0 (detected, 92%)', patchPath: 'migration.patch' };
+
+describe('review-server', () => {
+  it('renders diffs, escapes paths and disables files that no longer apply', () => {
+    const html = reviewPageHtml(PLAN, INFO, 'tok');
+
+    expect(html).toContain('Provider: <strong>Auth0 (detected, 92%)</strong>');
+    expect(html).toContain('<span class="del">-a</span>\n<span class="add">+b</span>');
+    expect(html).toContain('value="lib/&lt;old&gt;.ts" disabled');
+    expect(html).toContain("'X-Review-Token': \"tok\"");
+  });
+
+  it('requires the token and applies the selection once', async () => {
+    const calls: string[][] = [];
+    const server = await serveReview(PLAN, INFO, (files) => {
+      calls.push(files);
+      return { applied: files, skipped: [] };
+    });
+    try {
+      const { origin, searchParams } = new URL(server.url);
+      const token = searchParams.get('token')!;
+      expect(origin).toMatch(/^http:\/\/127\.0\.0\.1:\d+$/);
+      expect((await fetch(origin)).status).toBe(403);
+      expect((await fetch(server.url)).status).toBe(200);
+
+      const apply = (headers: Record<string, string>) =>
+        fetch(`${origin}/apply`, { method: 'POST', headers, body: JSON.stringify({ files: ['app.ts'] }) });
+      expect((await apply({})).status).toBe(403);
+      expect(await (await apply({ 'X-Review-Token': token })).json()).toEqual({ applied: ['app.ts'], skipped: [] });
+      await apply({ 'X-Review-Token': token });
+
+      expect(calls).toEqual([['app.ts']]);
+      await expect(server.applied).resolves.toEqual({ applied: ['app.ts'], skipped: [] });
+    } finally {
+      await server.close();
+    }
+  });
+});
@@ -0,0 +1,223 @@
+/**
+ * Local page for `workos review --web`: the plan's provider, per-file diffs
+ * and env key changes, with an "Apply selected" button that posts the
+ * chosen files back to the CLI.
+ *
+ * The server listens on 127.0.0.1 only, on a random port. Requests must
+ * carry the random token from the URL it opens, and a Host of that
+ * address, so neither other sites in the browser (a custom header needs a
+ * CORS preflight the server never grants) nor DNS rebinding can apply
+ * changes.
+ */
+
+import { randomBytes } from 'node:crypto';
+import http from 'node:http';
+import { reviewDiff, type ReviewPlan } from './review-plan.js';
+
+export interface ReviewPageInfo {
+  /** e.g. "Auth0 (detected, 92%)"; null when detection found nothing */
+  provider: string | null;
+  patchPath: string;
+}
+
+export interface ApplyResult {
+  applied: string[];
+  skipped: string[];
+}
+
+export interface ReviewServer {
+  url: string;
+  /** Resolves once the result of "Apply selected" has been sent to the page */
+  applied: Promise<ApplyResult>;
+  /** Shut the server down; resolves once it has stopped */
+  close: () => Promise<void>;
+}
+
+const MAX_BODY_BYTES = 1024 * 1024;
+
+function escapeHtml(value: string): string {
+  return value.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
+
+function diffLineClass(line: string): string {
+  if (line.startsWith('+++') || line.startsWith('---')) return 'meta';
+  if (line.startsWith('@@')) return 'hunk';
+  if (line.startsWith('+')) return 'add';
+  if (line.startsWith('-')) return 'del';
+  return '';
+}
+
+function renderDiff(diff: string): string {
+  return diff
+    .split('\n')
+    .map((line) => `<span class="${diffLineClass(line)}">${escapeHtml(line)}</span>`)
+    .join('\n');
+}
+
+function renderEnv(env: NonNullable<ReviewPlan['files'][number]['env']>): string {
+  const groups = [
+    ['Added', env.added],
+    ['Changed', env.changed],
+    ['Removed', env.removed],
+  ] as const;
+  const items = groups
+    .filter(([, keys]) => keys.length > 0)
+    .map(([label, keys]) => `<li>${label}: ${keys.map((k) => `<code>${escapeHtml(k)}</code>`).join(', ')}</li>`);
+  return items.length > 0 ? `<ul class="env">${items.join('')}</ul>` : '';
+}
+
+/** The review page; `token` is posted back with the selection */
+export function reviewPageHtml(plan: ReviewPlan, info: ReviewPageInfo, token: string): string {
+  const files = plan.files
+    .map((file) => {
+      const diff = reviewDiff(plan, file);
+      const status = diff ? `+${file.added} −${file.removed}` : 'no longer applies';
+      return `
+      <section class="file">
+        <label>
+          <input type="checkbox" name="file" value="${escapeHtml(file.path)}" ${diff ? 'checked' : 'disabled'} />
+          <code>${escapeHtml(file.path)}</code> <span class="stats">${status}</span>
+        </label>
+        ${file.env ? renderEnv(file.env) : ''}
+        ${diff ? `<details><summary>Diff</summary><pre>${renderDiff(diff)}</pre></details>` : ''}
+      </section>`;
+    })
+    .join('');
+
+  return `<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8" />
+    <title>WorkOS review · ${escapeHtml(info.patchPath)}</title>
+    <style>
+      body { font: 14px/1.5 system-ui, sans-serif; max-width: 1000px; margin: 32px auto; padding: 0 16px; }
+      .file { border: 1px solid #ddd; border-radius: 6px; padding: 8px 12px; margin: 8px 0; }
+      .stats { color: #666; margin-left: 8px; }
+      pre { overflow-x: auto; background: #f7f7f7; padding: 8px; }
+      .add { color: #1a7f37; } .del { color: #cf222e; } .hunk { color: #0969da; } .meta { font-weight: bold; }
+      .env { margin: 4px 0 0 24px; color: #444; }
+      button { font-size: 14px; padding: 6px 14px; }
+    </style>
+  </head>
+  <body>
+    <h1>Review changes</h1>
+    <p>Provider: <strong>${escapeHtml(info.provider ?? 'none detected')}</strong>
+      · Patch: <code>${escapeHtml(info.patchPath)}</code> · ${plan.files.length} file(s)</p>
+    <p>Env values for secrets are left empty; fill them in after applying.</p>
+    <form id="review">${files}
+      <p><button type="submit">Apply selected</button> <span id="result"></span></p>
+    </form>
+    <script>
+      const form = document.getElementById('review');
+      form.addEventListener('submit', async (event) => {
+        event.preventDefault();
+        const files = [...form.querySelectorAll('input[name=file]:checked')].map((input) => input.value);
+        const result = document.getElementById('result');
+        form.querySelector('button').disabled = true;
+        try {
+          const response = await fetch('/apply', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json', 'X-Review-Token': ${JSON.stringify(token)} },
+            body: JSON.stringify({ files }),
+          });
+          const { applied, skipped } = await response.json();
+          result.textContent = 'Applied ' + applied.length + ' file(s)' +
+            (skipped.length ? ', skipped ' + skipped.join(', ') : '') + '. You can close this tab.';
+        } catch (error) {
+          result.textContent = 'Could not reach the CLI: ' + error.message;
+        }
+      });
+    </script>
+  </body>
+</html>
+`;
+}
+
+function readBody(req: http.IncomingMessage): Promise<string> {
+  return new Promise((resolve, reject) => {
+    let body = '';
+    req.setEncoding('utf-8');
+    req.on('data', (chunk: string) => {
+      body += chunk;
+      if (body.length > MAX_BODY_BYTES) reject(new Error('Request body too large'));
+    });
+    req.on('end', () => resolve(body));
+    req.on('error', reject);
+  });
+}
+
+/**
+ * Serve the review page. `onApply` runs once, for the first valid post;
+ * later posts get the same result. The server doesn't close itself.
+ */
+export async function serveReview(
+  plan: ReviewPlan,
+  info: ReviewPageInfo,
+  onApply: (files: string[]) => ApplyResult,
+): Promise<ReviewServer> {
+  const token = randomBytes(16).toString('hex');
+  let host = '';
+  let applied: ApplyResult | null = null;
+  let resolveApplied: (result: ApplyResult) => void = () => {};
+  const appliedPromise = new Promise<ApplyResult>((resolve) => (resolveApplied = resolve));
+
+  const server = http.createServer(async (req, res) => {
+    const url = new URL(req.url ?? '/', 'http://127.0.0.1');
+    if (req.headers.host !== host) {
+      res.writeHead(403).end();
+      return;
+    }
+
+    if (req.method === 'GET' && url.pathname === '/') {
+      if (url.searchParams.get('token') !== token) {
+        res.writeHead(403).end('Open the URL the CLI printed.');
+        return;
+      }
+      res.writeHead(200, { 'Content-Type': 'text/html; charset=utf-8', 'Cache-Control': 'no-store' });
+      res.end(reviewPageHtml(plan, info, token));
+      return;
+    }
+
+    if (req.method === 'POST' && url.pathname === '/apply') {
+      if (req.headers['x-review-token'] !== token) {
+        res.writeHead(403).end();
+        return;
+      }
+      let files: unknown;
+      try {
+        files = (JSON.parse(await readBody(req)) as { files?: unknown }).files;
+      } catch {
+        res.writeHead(400).end();
+        return;
+      }
+      if (!Array.isArray(files) || !files.every((f) => typeof f === 'string')) {
+        res.writeHead(400).end();
+        return;
+      }
+      const result = (applied ??= onApply(files));
+      res.writeHead(200, { 'Content-Type': 'application/json' });
+      res.end(JSON.stringify(result), () => resolveApplied(result));
+      return;
+    }
+
+    res.writeHead(404).end();
+  });
+
+  await new Promise<void>((resolve, reject) => {
+    server.once('error', reject);
+    server.listen(0, '127.0.0.1', resolve);
+  });
+  const address = server.address();
+  const port = address && typeof address === 'object' ? address.port : 0;
+  host = `127.0.0.1:${port}`;
+
+  return {
+    url: `http://${host}/?token=${token}`,
+    applied: appliedPromise,
+    close: () =>
+      new Promise<void>((resolve) => {
+        server.close(() => resolve());
+        server.closeAllConnections();
+      }),
+  };
+}
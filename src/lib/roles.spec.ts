@@ -0,0 +1,77 @@
+import { describe, it, expect } from 'vitest';
+import { parseRolesFile, parseRolesYaml, planRolesSync, type Role } from './roles.js';
+
+const YAML = `# RBAC roles, reviewed like code
+roles:
+  - slug: admin
+    name: Admin
+    description: "Full access"
+    permissions:
+      - posts:read
+      - posts:write
+  - slug: viewer
+    name: Viewer
+    permissions: [posts:read]
+`;
+
+function role(overrides: Partial<Role> & Pick<Role, 'slug'>): Role {
+  return { id: `role_${overrides.slug}`, name: overrides.slug, description: null, permissions: [], ...overrides };
+}
+
+describe('roles', () => {
+  describe('parseRolesYaml', () => {
+    it('reads block and flow permission lists', () => {
+      expect(parseRolesYaml(YAML)).toEqual([
+        { slug: 'admin', name: 'Admin', description: 'Full access', permissions: ['posts:read', 'posts:write'] },
+        { slug: 'viewer', name: 'Viewer', permissions: ['posts:read'] },
+      ]);
+    });
+
+    it('reports problems with line numbers', () => {
+      expect(() => parseRolesYaml('roles:\n  - slug: admin\n    nmae: Admin\n')).toThrow('Line 3: unknown key "nmae"');
+      expect(() => parseRolesYaml('roles:\n  - slug: admin\n')).toThrow('Line 2: role "admin" needs a `name`');
+      expect(() => parseRolesYaml('- slug: admin\n')).toThrow('Line 1');
+    });
+
+    it('rejects duplicate slugs', () => {
+      const yaml = 'roles:\n  - slug: admin\n    name: A\n  - slug: admin\n    name: B\n';
+
+      expect(() => parseRolesYaml(yaml)).toThrow('Line 4: duplicate role "admin"');
+    });
+  });
+
+  it('reads JSON files with the same shape', () => {
+    const roles = [{ slug: 'viewer', name: 'Viewer', permissions: ['posts:read'] }];
+
+    expect(parseRolesFile(JSON.stringify({ roles }), 'roles.json')).toEqual(roles);
+  });
+
+  describe('planRolesSync', () => {
+    it('creates, updates and deletes to converge on the file', () => {
+      const desired = parseRolesYaml(YAML);
+      const current = [
+        role({ slug: 'admin', name: 'Admin', description: 'Full access', permissions: ['posts:read', 'posts:delete'] }),
+        role({ slug: 'legacy', name: 'Legacy' }),
+      ];
+
+      const plan = planRolesSync(desired, current);
+
+      expect(plan.create.map((r) => r.slug)).toEqual(['viewer']);
+      expect(plan.update).toHaveLength(1);
+      expect(plan.update[0].change).toEqual({
+        slug: 'admin',
+        addedPermissions: ['posts:write'],
+        removedPermissions: ['posts:delete'],
+      });
+      expect(plan.delete.map((r) => r.slug)).toEqual(['legacy']);
+    });
+
+    it('leaves matching roles alone', () => {
+      const current = [role({ slug: 'viewer', name: 'Viewer', permissions: ['posts:read'] })];
+
+      const plan = planRolesSync([{ slug: 'viewer', name: 'Viewer', permissions: ['posts:read'] }], current);
+
+      expect(plan).toEqual({ create: [], update: [], delete: [], unchanged: ['viewer'] });
+    });
+  });
+});
@@ -0,0 +1,255 @@
+/**
+ * Sync environment roles from a local definition file.
+ *
+ * The file (YAML or JSON) lists role slugs, names and permissions. The plan
+ * converges the environment on the file: roles missing from the file are
+ * deleted, which is refused for roles still assigned to memberships unless
+ * the caller explicitly allows it.
+ */
+
+import { randomUUID } from 'node:crypto';
+import { runBulk, type BulkOptions, type BulkResult } from './bulk.js';
+import { workosRequest, type WorkOSListResponse } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface RoleDefinition {
+  slug: string;
+  name: string;
+  description?: string;
+  permissions: string[];
+}
+
+export interface Role {
+  id: string;
+  slug: string;
+  name: string;
+  description: string | null;
+  /** Permission slugs */
+  permissions: string[];
+}
+
+const SLUG_PATTERN = /^[a-z0-9][a-z0-9_:-]*$/;
+const ROLE_KEYS = new Set(['slug', 'name', 'description', 'permissions']);
+
+function unquote(value: string): string {
+  const trimmed = value.trim();
+  const quoted = /^(['"])(.*)\1$/.exec(trimmed);
+  return quoted ? quoted[2] : trimmed;
+}
+
+/**
+ * Parse the YAML subset role files use:
+ *
+ *   roles:
+ *     - slug: admin
+ *       name: Admin
+ *       permissions:
+ *         - posts:write
+ *     - slug: viewer
+ *       name: Viewer
+ *       permissions: [posts:read]
+ */
+export function parseRolesYaml(content: string): RoleDefinition[] {
+  const roles: Array<Partial<RoleDefinition> & { line: number }> = [];
+  let inRoles = false;
+  let itemIndent = -1;
+  let inPermissions = false;
+
+  content.split(/\r?\n/).forEach((raw, index) => {
+    const line = index + 1;
+    const stripped = raw.replace(/(^|\s)#.*$/, '').trimEnd();
+    if (!stripped.trim()) return;
+    const indent = stripped.length - stripped.trimStart().length;
+    let text = stripped.trim();
+
+    if (indent === 0) {
+      if (!/^roles\s*:$/.test(text)) throw new Error(`Line ${line}: expected a top-level \`roles:\` list`);
+      inRoles = true;
+      return;
+    }
+    if (!inRoles) throw new Error(`Line ${line}: expected a top-level \`roles:\` list`);
+
+    const current = roles[roles.length - 1];
+    if (inPermissions && current && indent > itemIndent && text.startsWith('- ')) {
+      current.permissions!.push(unquote(text.slice(2)));
+      return;
+    }
+
+    if (text === '-' || text.startsWith('- ')) {
+      if (roles.length > 0 && indent !== itemIndent) {
+        throw new Error(`Line ${line}: unexpected list item (check indentation)`);
+      }
+      roles.push({ line, permissions: [] });
+      itemIndent = indent;
+      inPermissions = false;
+      text = text.slice(1).trim();
+      if (!text) return;
+    } else if (!current || indent <= itemIndent) {
+      throw new Error(`Line ${line}: expected \`- slug: ...\``);
+    }
+
+    const role = roles[roles.length - 1];
+    const pair = /^([a-z_]+)\s*:\s*(.*)$/.exec(text);
+    if (!pair) throw new Error(`Line ${line}: expected \`key: value\`, got "${text}"`);
+    const [, key, value] = pair;
+    if (!ROLE_KEYS.has(key)) throw new Error(`Line ${line}: unknown key "${key}"`);
+
+    inPermissions = false;
+    if (key === 'permissions') {
+      if (!value) {
+        inPermissions = true;
+      } else if (/^\[.*\]$/.test(value)) {
+        role.permissions = value.slice(1, -1).split(',').map(unquote).filter(Boolean);
+      } else {
+        throw new Error(`Line ${line}: permissions must be a list`);
+      }
+    } else {
+      role[key as 'slug' | 'name' | 'description'] = unquote(value);
+    }
+  });
+
+  return validateRoles(roles, (role) => `Line ${role.line}`);
+}
+
+function validateRoles<T extends Partial<RoleDefinition>>(
+  roles: T[],
+  where: (role: T, index: number) => string,
+): RoleDefinition[] {
+  const seen = new Set<string>();
+  return roles.map((role, index): RoleDefinition => {
+    if (!role.slug || !SLUG_PATTERN.test(role.slug)) {
+      throw new Error(`${where(role, index)}: role needs a lowercase \`slug\``);
+    }
+    if (seen.has(role.slug)) throw new Error(`${where(role, index)}: duplicate role "${role.slug}"`);
+    seen.add(role.slug);
+    if (!role.name) throw new Error(`${where(role, index)}: role "${role.slug}" needs a \`name\``);
+    const permissions = role.permissions ?? [];
+    if (!Array.isArray(permissions) || permissions.some((p) => typeof p !== 'string')) {
+      throw new Error(`${where(role, index)}: permissions of "${role.slug}" must be a list of slugs`);
+    }
+    return {
+      slug: role.slug,
+      name: role.name,
+      ...(role.description ? { description: role.description } : {}),
+      permissions: [...new Set(permissions)],
+    };
+  });
+}
+
+/**
+ * Parse a roles file; `.json` files use the same shape as YAML
+ * (`{ "roles": [...] }`).
+ */
+export function parseRolesFile(content: string, fileName: string): RoleDefinition[] {
+  if (!fileName.endsWith('.json')) return parseRolesYaml(content);
+  const parsed = JSON.parse(content) as { roles?: Partial<RoleDefinition>[] };
+  if (!Array.isArray(parsed.roles)) throw new Error('Expected a top-level "roles" array');
+  return validateRoles(parsed.roles, (_role, index) => `Role ${index + 1}`);
+}
+
+export async function listRoles(api: ApiOptions): Promise<Role[]> {
+  const result = await workosRequest<WorkOSListResponse<Role>>({ method: 'GET', path: '/authorization/roles', ...api });
+  return result.data;
+}
+
+export interface RoleChange {
+  slug: string;
+  name?: { from: string; to: string };
+  description?: { from: string | null; to: string | null };
+  addedPermissions: string[];
+  removedPermissions: string[];
+}
+
+export interface RolesPlan {
+  create: RoleDefinition[];
+  update: Array<{ role: RoleDefinition; change: RoleChange }>;
+  delete: Role[];
+  unchanged: string[];
+}
+
+export function planRolesSync(desired: RoleDefinition[], current: Role[]): RolesPlan {
+  const bySlug = new Map(current.map((role) => [role.slug, role]));
+  const plan: RolesPlan = { create: [], update: [], delete: [], unchanged: [] };
+
+  for (const role of desired) {
+    const existing = bySlug.get(role.slug);
+    if (!existing) {
+      plan.create.push(role);
+      continue;
+    }
+    const change: RoleChange = {
+      slug: role.slug,
+      addedPermissions: role.permissions.filter((p) => !existing.permissions.includes(p)),
+      removedPermissions: existing.permissions.filter((p) => !role.permissions.includes(p)),
+    };
+    if (existing.name !== role.name) change.name = { from: existing.name, to: role.name };
+    const description = role.description || null;
+    if ((existing.description || null) !== description) {
+      change.description = { from: existing.description || null, to: description };
+    }
+
+    const changed =
+      change.name || change.description || change.addedPermissions.length > 0 || change.removedPermissions.length > 0;
+    if (changed) plan.update.push({ role, change });
+    else plan.unchanged.push(role.slug);
+  }
+
+  const desiredSlugs = new Set(desired.map((role) => role.slug));
+  plan.delete = current.filter((role) => !desiredSlugs.has(role.slug));
+  return plan;
+}
+
+/**
+ * Whether any organization membership currently has the role.
+ */
+export async function isRoleAssigned(slug: string, api: ApiOptions): Promise<boolean> {
+  const result = await workosRequest<WorkOSListResponse<unknown>>({
+    method: 'GET',
+    path: '/user_management/organization_memberships',
+    params: { role_slug: slug, limit: 1 },
+    ...api,
+  });
+  return result.data.length > 0;
+}
+
+/**
+ * Apply the plan's creates, updates and deletes on a worker pool. A failed
+ * change doesn't stop the others; rerunning the sync plans only what's left.
+ */
+export function applyRolesPlan(plan: RolesPlan, api: ApiOptions, options: BulkOptions = {}): Promise<BulkResult> {
+  const changes = [
+    ...plan.create.map((role) => ({ action: 'create' as const, slug: role.slug, role })),
+    ...plan.update.map(({ role }) => ({ action: 'update' as const, slug: role.slug, role })),
+    ...plan.delete.map((role) => ({ action: 'delete' as const, slug: role.slug })),
+  ];
+  return runBulk(
+    changes,
+    (change) => `${change.action} ${change.slug}`,
+    async (change) => {
+      if (change.action === 'create') {
+        // The key makes the create safe to retry if the first attempt's response is lost
+        await workosRequest({
+          method: 'POST',
+          path: '/authorization/roles',
+          body: { ...change.role },
+          idempotencyKey: randomUUID(),
+          ...api,
+        });
+      } else if (change.action === 'update') {
+        await workosRequest({
+          method: 'PUT',
+          path: `/authorization/roles/${change.slug}`,
+          body: { ...change.role },
+          ...api,
+        });
+      } else {
+        await workosRequest({ method: 'DELETE', path: `/authorization/roles/${change.slug}`, ...api });
+      }
+    },
+    options,
+  );
+}
@@ -0,0 +1,92 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { createInstallerEventEmitter } from './events.js';
+import { RunReportRecorder, resolveReportPath } from './run-report.js';
+
+vi.mock('../utils/git-utils.js', () => ({
+  getHeadCommit: () => 'abc123',
+  getChangesSince: (ref: string | null) =>
+    ref === 'abc123' ? { created: ['app/callback/route.ts'], modified: ['package.json'], deleted: [] } : null,
+}));
+
+describe('run-report', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'run-report-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('resolveReportPath', () => {
+    const now = new Date('2026-03-01T09:30:15.123Z');
+
+    it('defaults to .workos/reports with the command and time', () => {
+      expect(resolveReportPath('/app', 'migrate', undefined, now)).toBe(
+        join('/app', '.workos', 'reports', 'migrate-2026-03-01T09-30-15.md'),
+      );
+    });
+
+    it('takes a file or a directory', () => {
+      expect(resolveReportPath('/app', 'install', 'docs/auth.md', now)).toBe(join('/app', 'docs', 'auth.md'));
+      expect(resolveReportPath('/app', 'install', '/tmp/reports', now)).toBe(
+        join('/tmp/reports', 'install-2026-03-01T09-30-15.md'),
+      );
+    });
+  });
+
+  describe('RunReportRecorder', () => {
+    it('reports what the run changed, without env values', () => {
+      writeFileSync(join(dir, '.env.local'), 'DATABASE_URL=postgres://localhost\n');
+      const emitter = createInstallerEventEmitter();
+      const recorder = new RunReportRecorder(emitter, dir, 'install');
+
+      writeFileSync(
+        join(dir, '.env.local'),
+        'DATABASE_URL=postgres://localhost\nWORKOS_API_KEY=sk_test_secret\nWORKOS_CLIENT_ID=client_123\n',
+      );
+      emitter.emit('dashboard:configured', {
+        changes: [
+          { setting: 'Redirect URI', value: 'http://localhost:3000/callback', created: true },
+          { setting: 'CORS origin', value: 'http://localhost:3000', created: false },
+        ],
+      });
+      emitter.emit('verification:check', {
+        name: 'build',
+        type: 'build',
+        passed: false,
+        output: 'boom',
+        durationMs: 1,
+      });
+      emitter.emit('verification:check', { name: 'build', type: 'build', passed: true, durationMs: 4200 });
+
+      const report = recorder.render({ success: true, summary: 'Added AuthKit' }, new Date('2026-03-01T09:30:15Z'));
+
+      expect(report).toMatch(/^# WorkOS install report\n/);
+      expect(report).toContain('### Files created\n\n- `app/callback/route.ts`');
+      expect(report).toContain('### Files modified\n\n- `package.json`');
+      expect(report).toContain('- `.env.local`: `WORKOS_API_KEY`, `WORKOS_CLIENT_ID`');
+      expect(report).not.toContain('sk_test_secret');
+      expect(report).not.toContain('DATABASE_URL');
+      expect(report).toContain('- Redirect URI `http://localhost:3000/callback`\n');
+      expect(report).toContain('- CORS origin `http://localhost:3000` (already set)');
+      expect(report).toContain('- [x] build: passed (4.2s)');
+      expect(report).not.toContain('boom');
+    });
+
+    it('writes the report into the project', () => {
+      const recorder = new RunReportRecorder(createInstallerEventEmitter(), dir, 'migrate');
+
+      const path = recorder.write({ success: false, summary: 'Agent failed' });
+
+      expect(path).toMatch(/^\.workos\/reports\/migrate-.+\.md$/);
+      const content = readFileSync(join(dir, path), 'utf-8');
+      expect(content).toContain('# WorkOS migration report');
+      expect(content).toContain('## Installation Failed');
+    });
+  });
+});
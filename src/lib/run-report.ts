@@ -0,0 +1,130 @@
+/**
+ * Markdown report of an install or migration, for attaching to the PR:
+ * files created and modified, env vars added, dashboard settings, what's
+ * left to do by hand and the verification results.
+ *
+ * The recorder is started before the run so it can tell what the run
+ * changed: it notes the commit the run started from and the keys already in
+ * the env files, then collects dashboard and verification events. Env var
+ * values never make it into the report.
+ */
+
+import { existsSync, mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { dirname, join, resolve } from 'node:path';
+import { addAgentRun, type RunUsage } from './agent-usage.js';
+import type { InstallerEventEmitter } from './events.js';
+import type { VerificationCheckResult } from './validation/verification.js';
+import type { DashboardChange } from './workos-management.js';
+import { parseEnvFile } from '../utils/env-parser.js';
+import { getChangesSince, getHeadCommit } from '../utils/git-utils.js';
+import { relativePosix } from '../utils/paths.js';
+import {
+  buildRunSummary,
+  formatRunSummary,
+  type EnvVarChange,
+  type RunSummary,
+  type RunSummaryInput,
+} from '../utils/run-summary.js';
+
+export const REPORTS_DIR = join('.workos', 'reports');
+
+/** `assess` writes its own report (see migrate/assessment.ts) under the same naming */
+export type ReportCommand = 'install' | 'migrate' | 'assess';
+
+const ENV_FILES = ['.env', '.env.local'];
+
+/** What the run itself knows at the end; the recorder adds the rest */
+export type ReportInput = Pick<RunSummaryInput, 'success' | 'summary' | 'changedFiles' | 'migration'>;
+
+function readEnvKeys(installDir: string): Map<string, Set<string>> {
+  const keys = new Map<string, Set<string>>();
+  for (const file of ENV_FILES) {
+    const path = join(installDir, file);
+    if (!existsSync(path)) continue;
+    try {
+      keys.set(file, new Set(Object.keys(parseEnvFile(readFileSync(path, 'utf-8')))));
+    } catch {
+      // Unreadable env file; report nothing for it
+    }
+  }
+  return keys;
+}
+
+/**
+ * Where the report goes: `.workos/reports/<command>-<timestamp>.md` in the
+ * project, or `--report-path` (a `.md` file, or a directory to put it in).
+ */
+export function resolveReportPath(installDir: string, command: ReportCommand, reportPath?: string, now = new Date()) {
+  const name = `${command}-${now.toISOString().slice(0, 19).replace(/:/g, '-')}.md`;
+  if (!reportPath) return join(installDir, REPORTS_DIR, name);
+  const path = resolve(installDir, reportPath);
+  return path.endsWith('.md') ? path : join(path, name);
+}
+
+export class RunReportRecorder {
+  private startCommit = getHeadCommit();
+  private envBefore: Map<string, Set<string>>;
+  private dashboard: DashboardChange[] = [];
+  private checks = new Map<string, VerificationCheckResult>();
+  private usage: RunUsage | undefined;
+
+  constructor(
+    emitter: InstallerEventEmitter,
+    private installDir: string,
+    readonly command: ReportCommand,
+  ) {
+    this.envBefore = readEnvKeys(installDir);
+    emitter.on('dashboard:configured', ({ changes }) => this.dashboard.push(...changes));
+    // Repair runs re-run the checks; the last result of each is the one that counts
+    emitter.on('verification:check', (check) => this.checks.set(check.name, check));
+    emitter.on('agent:usage', (run) => {
+      this.usage = addAgentRun(this.usage, run);
+    });
+  }
+
+  private envVarsAdded(): EnvVarChange[] {
+    const added: EnvVarChange[] = [];
+    for (const [file, keys] of readEnvKeys(this.installDir)) {
+      const before = this.envBefore.get(file) ?? new Set();
+      const newKeys = [...keys].filter((key) => !before.has(key));
+      if (newKeys.length > 0) added.push({ file, keys: newKeys });
+    }
+    return added;
+  }
+
+  /** Checks whose last run failed */
+  failedChecks(): string[] {
+    return [...this.checks.values()].filter((check) => !check.passed).map((check) => check.name);
+  }
+
+  summary(input: ReportInput): RunSummary {
+    return buildRunSummary({
+      ...input,
+      usage: this.usage,
+      files: getChangesSince(this.startCommit) ?? undefined,
+      envVars: this.envVarsAdded(),
+      dashboard: this.dashboard,
+      checks: [...this.checks.values()],
+    });
+  }
+
+  /** The report body, also embedded in the PR description */
+  markdown(input: ReportInput): string {
+    return formatRunSummary(this.summary(input), 'markdown');
+  }
+
+  /** The report as a standalone markdown document */
+  render(input: ReportInput, now = new Date()): string {
+    const title = this.command === 'migrate' ? 'WorkOS migration report' : 'WorkOS install report';
+    return `# ${title}\n\n<sub>\`workos ${this.command}\`, ${now.toISOString()}</sub>\n\n${this.markdown(input)}\n`;
+  }
+
+  /** Write the report and return its path relative to the project */
+  write(input: ReportInput, reportPath?: string): string {
+    const now = new Date();
+    const path = resolveReportPath(this.installDir, this.command, reportPath, now);
+    mkdirSync(dirname(path), { recursive: true });
+    writeFileSync(path, this.render(input, now));
+    return relativePosix(this.installDir, path) || path;
+  }
+}
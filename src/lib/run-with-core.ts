@@ -1,9 +1,10 @@
 import { createActor, fromPromise } from 'xstate';
-import open from 'opn';
 import { existsSync, readFileSync } from 'fs';
 import { join } from 'path';
 import { installerMachine } from './installer-core.js';
-import { createInstallerEventEmitter } from './events.js';
+import { createInstallerEventEmitter, type InstallerEventEmitter, type InstallerEvents } from './events.js';
+import { streamEvents } from './event-stream.js';
+import { streamMachineMessages } from './installer-proto.js';
 import { CLIAdapter } from './adapters/cli-adapter.js';
 import { DashboardAdapter } from './adapters/dashboard-adapter.js';
 import type { InstallerAdapter } from './adapters/types.js';
@@ -14,32 +15,39 @@ import type {
   GitCheckOutput,
   AgentOutput,
   BranchCheckOutput,
+  CreateBranchInput,
 } from './installer-core.types.js';
 import type { Integration } from './constants.js';
 import { parseEnvFile } from '../utils/env-parser.js';
+import { renameProviderEnvKeys } from '../migrate/env-rename.js';
+import { finishedCategories, formatCategories } from '../migrate/scope.js';
+import { migrationStatePath, readMigrationState, snapshotPlan, writeMigrationState } from '../migrate/selection.js';
 import { enableDebugLogs, initLogFile, logInfo, logError } from '../utils/debug.js';
 
-import {
-  getAccessToken,
-  getCredentials,
-  saveCredentials,
-  getStagingCredentials,
-  saveStagingCredentials,
-} from './credentials.js';
-import { getConfig, saveConfig, getActiveEnvironment } from './config-store.js';
+import { getCredentials, saveCredentials } from './credentials.js';
+import { ensureValidToken } from './token-refresh.js';
 import { checkForEnvFiles, discoverCredentials } from './credential-discovery.js';
 import { requestDeviceCode, pollForToken } from './device-auth.js';
-import { fetchStagingCredentials as fetchStagingCredentialsApi } from './staging-api.js';
+import { resolveStagingCredentials } from './staging-credentials.js';
 import { getCliAuthClientId, getAuthkitDomain } from './settings.js';
 import { analytics } from '../utils/analytics.js';
 import { getVersion } from './settings.js';
 import { getLlmGatewayUrlFromHost } from '../utils/urls.js';
 import { isInGitRepo, getUncommittedOrUntrackedFiles } from '../utils/clack-utils.js';
+import {
+  AgentAuthRequiredError,
+  exitWithError,
+  PartialMigrationError,
+  stoppedRunError,
+  VerificationFailedError,
+} from '../utils/errors.js';
 import {
   getCurrentBranch,
-  isProtectedBranch,
+  protectedBranchReason,
   createBranch as createGitBranch,
   branchExists,
+  checkoutBranch,
+  getDefaultBranch,
   hasGhCli,
 } from '../utils/git-utils.js';
 import { detectChanges, stageAndCommit, pushBranch as pushGitBranch, createPullRequest } from './post-install.js';
@@ -48,9 +56,29 @@ import {
   generatePrDescription as generatePrDescriptionAi,
 } from './ai-content.js';
 import { autoConfigureWorkOSEnvironment } from './workos-management.js';
-import { detectPort, getCallbackPath } from './port-detection.js';
+import { detectDevOrigin, isSpaIntegration } from './cors-origins.js';
+import { getCallbackPath, resolvePort, type PortCandidate } from './port-detection.js';
 import { writeEnvLocal } from './env-writer.js';
+import { protectEnvFile } from './secret-scan.js';
+import { skipsFileWrites, startDiffOnly, stopDiffOnly, writePatch } from './diff-only.js';
+import { startFileBackups, stopFileBackups } from './file-backups.js';
 import { getRegistry } from './registry.js';
+import { handleInterrupts, writeCheckpoint, type Checkpoint, type StopReason } from './interrupt.js';
+import { writeInstallRecord } from './existing-integration.js';
+import { runProjectHooks } from './project-hooks.js';
+import { DEFAULT_BRANCH_TEMPLATE, nextFreeBranchName, renderTemplate, templateVariables } from './git-conventions.js';
+import { RunReportRecorder } from './run-report.js';
+import { MigrationStepRecorder } from './migration-steps.js';
+import { relativePosix } from '../utils/paths.js';
+import { openBrowser } from '../utils/browser.js';
+import {
+  getGitHubClient,
+  getOriginRemote,
+  pushForPullRequest,
+  upsertPullRequest,
+  type GitHubRepo,
+  type PushedBranch,
+} from './pull-request.js';
 
 async function runIntegrationInstallerFn(integration: Integration, options: InstallerOptions): Promise<string> {
   const registry = await getRegistry();
@@ -79,6 +107,163 @@ function readExistingCredentials(installDir: string): { apiKey?: string; clientI
   }
 }
 
+/**
+ * Ask which dev server port the app uses when project config names several.
+ * Rendered by whichever adapter handles `prompt:request`.
+ */
+function askForPort(emitter: InstallerEventEmitter, candidates: PortCandidate[]): Promise<number> {
+  const id = 'dev-server-port';
+  const options = candidates.map((c) => `${c.port} (${c.source})`);
+
+  return new Promise((resolve) => {
+    const onResponse = ({ id: responseId, value }: InstallerEvents['prompt:response']) => {
+      if (responseId !== id) return;
+      emitter.off('prompt:response', onResponse);
+      resolve(candidates[options.indexOf(value)]?.port ?? candidates[0].port);
+    };
+    emitter.on('prompt:response', onResponse);
+    emitter.emit('prompt:request', { id, message: 'Which port does your dev server use?', options });
+  });
+}
+
+/**
+ * Ask whether to push to a fork when origin refuses the push. Accepted with
+ * `--yes`; declined in CI, where nobody can answer.
+ */
+function confirmForkPush(emitter: InstallerEventEmitter, origin: GitHubRepo, options: InstallerOptions) {
+  if (options.yes) return Promise.resolve(true);
+  if (options.ci) return Promise.resolve(false);
+  const id = 'push-to-fork';
+  const fork = 'Push to a fork and open the pull request from there';
+
+  return new Promise<boolean>((resolve) => {
+    const onResponse = ({ id: responseId, value }: InstallerEvents['prompt:response']) => {
+      if (responseId !== id) return;
+      emitter.off('prompt:response', onResponse);
+      resolve(value === fork);
+    };
+    emitter.on('prompt:response', onResponse);
+    emitter.emit('prompt:request', {
+      id,
+      message: `You can't push to ${origin.owner}/${origin.repo}.`,
+      options: [fork, 'Skip the pull request'],
+    });
+  });
+}
+
+const KEEP_CHANGES = 'Keep them and finish the failed files on the next run';
+const ROLL_BACK = 'Roll back the files that changed';
+
+/**
+ * Ask whether to keep the files a partly applied migration changed. Kept
+ * with `--yes`, in CI and when streaming events, where the next run picks
+ * up the failed files.
+ */
+function confirmRollback(emitter: InstallerEventEmitter, options: InstallerOptions): Promise<boolean> {
+  if (options.yes || options.ci || options.dashboard || options.events === 'ndjson') return Promise.resolve(false);
+  const id = 'partial-migration';
+
+  return new Promise<boolean>((resolve) => {
+    const onResponse = ({ id: responseId, value }: InstallerEvents['prompt:response']) => {
+      if (responseId !== id) return;
+      emitter.off('prompt:response', onResponse);
+      resolve(value === ROLL_BACK);
+    };
+    emitter.on('prompt:response', onResponse);
+    emitter.emit('prompt:request', {
+      id,
+      message: 'The migration was only partly applied. What should happen to the files it changed?',
+      options: [KEEP_CHANGES, ROLL_BACK],
+    });
+  });
+}
+
+/**
+ * Report and save what became of each file once a migration stops, and
+ * offer to roll back one that was only partly applied.
+ * @returns The error to exit with when changes were left in place
+ */
+async function settleMigrationSteps(
+  recorder: MigrationStepRecorder,
+  emitter: InstallerEventEmitter,
+  options: InstallerOptions,
+  runError: unknown,
+): Promise<Error | null> {
+  const { installDir, migration } = options;
+  const reason = runError ? (runError instanceof Error ? runError.message : String(runError)) : undefined;
+  const breakdown = recorder.breakdown(reason);
+  // This run's categories count as done only once it lands
+  const completed = migration!.completedCategories;
+  const state = {
+    provider: migration!.provider,
+    excludedFiles: migration!.excludedFiles ?? [],
+    ...(completed && completed.length > 0 && { categories: completed }),
+  };
+  if (breakdown.failed === 0 && !runError) {
+    // Nothing left for a rerun to finish, except the categories --only left out
+    const categories = finishedCategories(migration!);
+    const previous = readMigrationState(installDir);
+    if (categories || previous?.steps || previous?.categories) {
+      writeMigrationState(installDir, { ...state, categories });
+    }
+    return null;
+  }
+
+  let statePath: string | undefined;
+  const plan = snapshotPlan(installDir, migration!.findings, breakdown.steps);
+  try {
+    writeMigrationState(installDir, { ...state, steps: breakdown.steps, plan });
+    statePath = migrationStatePath(installDir);
+  } catch (error) {
+    logError('Failed to save migration state:', error instanceof Error ? error.message : String(error));
+  }
+  if (breakdown.failed > 0 || breakdown.partial) emitter.emit('migration:steps', { ...breakdown, statePath });
+  if (!breakdown.partial) return null;
+
+  if (recorder.canRollback() && (await confirmRollback(emitter, options))) {
+    try {
+      const rollback = recorder.rollback();
+      emitter.emit('migration:rollback', rollback);
+      const undone = new Set([...rollback.restored, ...rollback.removed]);
+      const steps = breakdown.steps.map((step) =>
+        undone.has(step.file) ? { file: step.file, status: 'skipped' as const, reason: 'rolled back' } : step,
+      );
+      // Hashed again: the rollback changed the files back
+      writeMigrationState(installDir, { ...state, steps, plan: snapshotPlan(installDir, migration!.findings, steps) });
+      // Files kept, or changed by an earlier run, still leave the project half migrated
+      const remaining = steps.filter((step) => step.status === 'succeeded').length;
+      if (remaining === 0) {
+        return runError ? null : new Error(`Migration rolled back after ${breakdown.failed} file(s) failed`);
+      }
+      return new PartialMigrationError(remaining, breakdown.failed, { cause: runError ?? undefined });
+    } catch (error) {
+      logError('Rollback failed:', error instanceof Error ? error.message : String(error));
+    }
+  }
+  return new PartialMigrationError(breakdown.succeeded, breakdown.failed, { cause: runError ?? undefined });
+}
+
+/**
+ * Save what became of each file when a migration is cancelled, so the next
+ * run can reconcile its plan with the project and finish the rest.
+ */
+function checkpointMigrationSteps(recorder: MigrationStepRecorder, options: InstallerOptions): void {
+  const { installDir, migration } = options;
+  const { steps } = recorder.breakdown('cancelled', 'not reached before the run was cancelled');
+  const completed = migration!.completedCategories;
+  try {
+    writeMigrationState(installDir, {
+      provider: migration!.provider,
+      excludedFiles: migration!.excludedFiles ?? [],
+      ...(completed && completed.length > 0 && { categories: completed }),
+      steps,
+      plan: snapshotPlan(installDir, migration!.findings, steps),
+    });
+  } catch (error) {
+    logError('Failed to save migration state:', error instanceof Error ? error.message : String(error));
+  }
+}
+
 async function detectIntegrationFn(options: Pick<InstallerOptions, 'installDir'>): Promise<Integration | undefined> {
   const registry = await getRegistry();
   const configs = registry.detectionOrder();
@@ -94,6 +279,12 @@ async function detectIntegrationFn(options: Pick<InstallerOptions, 'installDir'>
   return undefined;
 }
 
+/** The integration's skill, for {skill} in branch and commit templates */
+async function skillFor(integration: string | undefined): Promise<string | undefined> {
+  if (!integration) return undefined;
+  return (await getRegistry()).get(integration)?.config.metadata.skillName ?? integration;
+}
+
 /**
  * Detect if a single integration matches the project.
  * Uses package.json detection for JS integrations, manifest files for others.
@@ -151,7 +342,10 @@ async function detectSingleIntegration(
     }
   }
 
-  // For non-JS integrations, check manifest files
+  // For non-JS integrations, ask the integration, then fall back to its manifest file
+  if (config.detection.detect) {
+    return config.detection.detect(options.installDir);
+  }
   if (config.metadata.manifestFile) {
     return existsSync(join(options.installDir, config.metadata.manifestFile));
   }
@@ -184,8 +378,31 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
     apiKey: options.apiKey || existingCreds.apiKey,
     clientId: options.clientId || existingCreds.clientId,
   };
+  // --diff-only: from here on every file edit is recorded for the patch instead of written
+  if (augmentedOptions.diffOnly) augmentedOptions.changePlan = startDiffOnly();
+  // --keep-backups: each file is copied before its first change; a dry run changes nothing
+  if (augmentedOptions.keepBackups && !augmentedOptions.dryRun) {
+    augmentedOptions.fileBackups = startFileBackups(augmentedOptions.installDir);
+  }
 
   const emitter = createInstallerEventEmitter();
+  if (augmentedOptions.events === 'ndjson') {
+    streamEvents(
+      emitter,
+      augmentedOptions.eventsModule ?? (relativePosix(process.cwd(), augmentedOptions.installDir) || '.'),
+    );
+  }
+  if (augmentedOptions.machine) streamMachineMessages(emitter, augmentedOptions.installDir);
+  const report = new RunReportRecorder(
+    emitter,
+    augmentedOptions.installDir,
+    augmentedOptions.migration ? 'migrate' : 'install',
+  );
+  // Per-file outcomes of a migration, for when only some of it lands; dry runs write nothing
+  const steps =
+    augmentedOptions.migration && !augmentedOptions.dryRun && !augmentedOptions.diffOnly
+      ? new MigrationStepRecorder(emitter, augmentedOptions.installDir, augmentedOptions.migration)
+      : null;
   let actor: ReturnType<typeof createActor<typeof installerMachine>> | null = null;
 
   const sendEvent = (event: { type: string; [key: string]: unknown }) => {
@@ -196,23 +413,33 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
 
   const adapter: InstallerAdapter = options.dashboard
     ? new DashboardAdapter({ emitter, sendEvent, debug: augmentedOptions.debug })
-    : new CLIAdapter({ emitter, sendEvent, debug: augmentedOptions.debug });
+    : new CLIAdapter({
+        emitter,
+        sendEvent,
+        debug: augmentedOptions.debug,
+        summaryFormat: augmentedOptions.summaryFormat,
+        migration: augmentedOptions.migration,
+      });
+
+  // Where the install branch was pushed (origin or a fork), for opening its PR
+  let pushedBranch: PushedBranch | null = null;
+  const gitHub = (cwd: string) => getGitHubClient({ hasGh: hasGhCli(), token: process.env.GITHUB_TOKEN, cwd });
 
   const machineWithActors = installerMachine.provide({
     actors: {
       checkAuthentication: fromPromise(async () => {
-        const token = getAccessToken();
-        if (!token) {
+        if (!getCredentials()) {
           // This should rarely happen since bin.ts handles auth first
           // But keep as safety net for programmatic usage
-          throw new Error('Not authenticated. Run `workos login` first.');
+          throw new AgentAuthRequiredError('Not authenticated. Run `workos login` first.');
         }
+        const token = await ensureValidToken();
+        if (!token.success) throw new AgentAuthRequiredError(token.error);
 
         // Set telemetry from existing credentials
         const creds = getCredentials();
         if (creds) {
           analytics.setAccessToken(creds.accessToken);
-          analytics.setDistinctId(creds.userId);
         }
         return true;
       }),
@@ -238,24 +465,71 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
           throw new Error('Missing integration or credentials');
         }
 
-        const port = detectPort(integration, installerOptions.installDir);
+        // In Docker Compose the browser reaches the app on the port compose publishes
+        const { compose } = installerOptions;
+        const port =
+          compose?.hostPort ??
+          (await resolvePort(
+            integration,
+            installerOptions.installDir,
+            installerOptions.ci ? undefined : (candidates) => askForPort(emitter, candidates),
+          ));
+        logInfo(`Using dev server port ${port}`);
         const callbackPath = getCallbackPath(integration);
         const redirectUri = installerOptions.redirectUri || `http://localhost:${port}${callbackPath}`;
 
+        if (skipsFileWrites(installerOptions)) {
+          logInfo('Dry run: skipping WorkOS environment configuration and env file writes');
+          return;
+        }
+
         const requiresApiKey = ['nextjs', 'tanstack-start', 'react-router'].includes(integration);
-        if (credentials.apiKey && requiresApiKey) {
+        // SPAs don't need the key at runtime, but their browser code needs the dev origin allowed
+        const spa = isSpaIntegration(integration);
+        // --diff-only records the env files but leaves the WorkOS environment alone
+        if (credentials.apiKey && (requiresApiKey || spa) && !installerOptions.dryRun) {
           await autoConfigureWorkOSEnvironment(credentials.apiKey, integration, port, {
             homepageUrl: installerOptions.homepageUrl,
             redirectUri: installerOptions.redirectUri,
+            ...(spa && { corsOrigin: detectDevOrigin(port, installerOptions.installDir) }),
+            emitter,
           });
         }
 
+        const { migration } = installerOptions;
+        if (migration?.only && !migration.only.includes('env')) {
+          logInfo(`Skipping env file changes: this run applies only ${formatCategories(migration.only)}`);
+          return;
+        }
+        if (migration) {
+          // Before the agent runs, so it sees the new names and never rewrites the values
+          migration.renamedEnv = renameProviderEnvKeys(
+            installerOptions.installDir,
+            migration.envMapping,
+            migration.excludedFiles,
+            migration.containerEnvFiles,
+          );
+          if (migration.renamedEnv.length > 0) {
+            emitter.emit('env:renamed', { keys: migration.renamedEnv, provider: migration.displayName });
+          }
+        }
+
         const redirectUriKey = integration === 'nextjs' ? 'NEXT_PUBLIC_WORKOS_REDIRECT_URI' : 'WORKOS_REDIRECT_URI';
 
-        writeEnvLocal(installerOptions.installDir, {
-          ...(credentials.apiKey ? { WORKOS_API_KEY: credentials.apiKey } : {}),
-          WORKOS_CLIENT_ID: credentials.clientId,
-          [redirectUriKey]: redirectUri,
+        const envFile = compose?.envFile ?? '.env.local';
+        writeEnvLocal(
+          installerOptions.installDir,
+          {
+            ...(credentials.apiKey ? { WORKOS_API_KEY: credentials.apiKey } : {}),
+            WORKOS_CLIENT_ID: credentials.clientId,
+            [redirectUriKey]: redirectUri,
+          },
+          envFile,
+        );
+        await protectEnvFile(installerOptions.installDir, envFile, {
+          emitter,
+          ci: installerOptions.ci,
+          dryRun: installerOptions.dryRun,
         });
       }),
 
@@ -274,7 +548,10 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
             clientId: credentials?.clientId,
             emitter: context.emitter,
           };
+          const hooks = installerOptions.projectHooks;
+          if (hooks) await runProjectHooks(hooks, 'pre', context.emitter);
           const summary = await runIntegrationInstallerFn(integration, agentOptions);
+          if (hooks) await runProjectHooks(hooks, 'post', context.emitter);
           return {
             success: true,
             summary: summary || `Successfully installed WorkOS AuthKit for ${integration}!`,
@@ -297,8 +574,8 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
       }),
 
       checkStoredAuth: fromPromise(async () => {
-        const token = getAccessToken();
-        return token !== null;
+        const token = await ensureValidToken();
+        return token.success;
       }),
 
       runDeviceAuth: fromPromise(async ({ input }) => {
@@ -321,13 +598,8 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
           userCode: deviceAuth.user_code,
         });
 
-        // Open browser
-        try {
-          const { default: openFn } = await import('opn');
-          await openFn(deviceAuth.verification_uri_complete);
-        } catch {
-          // User can open manually
-        }
+        // Open browser; the user can open it manually if this fails
+        await openBrowser(deviceAuth.verification_uri_complete);
 
         const result = await pollForToken(deviceAuth.device_code, {
           clientId,
@@ -347,57 +619,38 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
         return { result, deviceAuth };
       }),
 
-      fetchStagingCredentials: fromPromise(async () => {
-        const activeEnv = getActiveEnvironment();
-        if (activeEnv?.clientId && activeEnv?.apiKey) {
-          return { clientId: activeEnv.clientId, apiKey: activeEnv.apiKey };
-        }
-
-        const cached = getStagingCredentials();
-        if (cached) return cached;
-
-        const token = getAccessToken();
-        if (!token) throw new Error('No access token available');
-
-        const staging = await fetchStagingCredentialsApi(token);
-        saveStagingCredentials(staging);
-
-        try {
-          const config = getConfig() ?? { environments: {} };
-          if (!config.environments['default']) {
-            config.environments['default'] = {
-              name: 'default',
-              type: staging.apiKey.startsWith('sk_test_') ? 'sandbox' : 'production',
-              apiKey: staging.apiKey,
-              clientId: staging.clientId,
-            };
-            if (!config.activeEnvironment) {
-              config.activeEnvironment = 'default';
-            }
-            saveConfig(config);
-          }
-        } catch {
-          // Don't block install if config-store write fails
-        }
-
-        return staging;
-      }),
+      fetchStagingCredentials: fromPromise(() => resolveStagingCredentials()),
 
       // Branch check actors
       checkBranch: fromPromise<BranchCheckOutput, void>(async () => {
         const branch = getCurrentBranch();
-        if (!branch) {
+        // A dry run doesn't commit, so there's no need to leave a protected branch
+        if (!branch || augmentedOptions.dryRun) {
           return { branch: null, isProtected: false };
         }
-        return {
-          branch,
-          isProtected: isProtectedBranch(branch),
-        };
+        // --allow-main commits where it is, so there's nothing to offer instead
+        const reason = augmentedOptions.allowMain ? null : protectedBranchReason(branch, augmentedOptions.installDir);
+        if (!reason) {
+          return { branch, isProtected: false };
+        }
+        const template = augmentedOptions.gitConventions?.branchTemplate ?? DEFAULT_BRANCH_TEMPLATE;
+        // This runs alongside detection, so {skill} needs its own look at the project
+        const integration = template.includes('{skill}')
+          ? (augmentedOptions.integration ?? (await detectIntegrationFn({ installDir: augmentedOptions.installDir })))
+          : undefined;
+        const newBranch = renderTemplate(
+          template,
+          templateVariables(await skillFor(integration), { cwd: augmentedOptions.installDir }),
+        );
+        return { branch, isProtected: true, reason, newBranch, newBranchExists: branchExists(newBranch) };
       }),
 
-      createBranch: fromPromise<{ branch: string }, { name: string; fallbackName: string }>(async ({ input }) => {
-        const { name, fallbackName } = input;
-        const targetBranch = branchExists(name) ? fallbackName : name;
+      createBranch: fromPromise<{ branch: string; reused?: boolean }, CreateBranchInput>(async ({ input }) => {
+        if (input.reuse) {
+          checkoutBranch(input.name);
+          return { branch: input.name, reused: true };
+        }
+        const targetBranch = nextFreeBranchName(input.name, branchExists);
         createGitBranch(targetBranch);
         return { branch: targetBranch };
       }),
@@ -409,7 +662,11 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
 
       generateCommitMessage: fromPromise<string, { integration: string; files: string[]; direct?: boolean }>(
         async ({ input }) => {
-          return generateCommitMessageAi(input.integration, input.files, { direct: input.direct });
+          const summary = await generateCommitMessageAi(input.integration, input.files, { direct: input.direct });
+          const template = augmentedOptions.gitConventions?.commitTemplate;
+          if (!template) return summary;
+          const variables = templateVariables(await skillFor(input.integration), { cwd: augmentedOptions.installDir });
+          return renderTemplate(template, { ...variables, summary });
         },
       ),
 
@@ -421,16 +678,53 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
         string,
         { integration: string; files: string[]; commitMessage: string; direct?: boolean }
       >(async ({ input }) => {
-        return generatePrDescriptionAi(input.integration, input.files, input.commitMessage, { direct: input.direct });
+        const description = await generatePrDescriptionAi(input.integration, input.files, input.commitMessage, {
+          direct: input.direct,
+        });
+        // Same summary as the report written at the end of the run
+        const summary = report.markdown({
+          success: true,
+          summary: actor?.getSnapshot().context.agentSummary,
+          changedFiles: input.files,
+          migration: augmentedOptions.migration,
+        });
+        const skill = (await getRegistry()).get(input.integration)?.config.metadata.skillName;
+        const source = skill ? `\n\nGenerated by \`workos ${report.command}\` with the \`${skill}\` skill.` : '';
+        return `${description}${source}\n\n---\n\n${summary}`;
       }),
 
       pushBranch: fromPromise<void, { cwd: string }>(async ({ input }) => {
-        pushGitBranch(input.cwd);
+        const origin = getOriginRemote(input.cwd)?.github;
+        const branch = getCurrentBranch();
+        if (!origin || !branch) {
+          pushGitBranch(input.cwd);
+          return;
+        }
+        pushedBranch = await pushForPullRequest({
+          origin,
+          branch,
+          request: gitHub(input.cwd),
+          confirmFork: () => confirmForkPush(emitter, origin, augmentedOptions),
+          cwd: input.cwd,
+        });
       }),
 
-      createPr: fromPromise<string, { title: string; body: string; cwd: string }>(async ({ input }) => {
-        return createPullRequest(input.title, input.body, input.cwd);
-      }),
+      createPr: fromPromise<{ url: string; updated: boolean }, { title: string; body: string; cwd: string }>(
+        async ({ input }) => {
+          const origin = getOriginRemote(input.cwd)?.github;
+          const request = gitHub(input.cwd);
+          if (!origin || !pushedBranch || !request) {
+            return { url: createPullRequest(input.title, input.body, input.cwd), updated: false };
+          }
+          return upsertPullRequest(request, {
+            repo: origin,
+            head: pushedBranch,
+            base: getDefaultBranch(),
+            title: input.title,
+            body: input.body,
+          });
+        },
+      ),
     },
   });
 
@@ -446,7 +740,7 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
         inspectUrl = msg;
         console.log = originalLog;
         console.log(`Opening XState inspector: ${inspectUrl}`);
-        void open(inspectUrl);
+        void openBrowser(inspectUrl);
       } else {
         originalLog.apply(console, args);
       }
@@ -473,13 +767,60 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
 
   let installerStatus: 'success' | 'error' | 'cancelled' = 'success';
 
-  // Handle ctrl+c by sending CANCEL to state machine for graceful shutdown
-  const handleSigint = () => {
+  // First ctrl+c (or SIGTERM) sends CANCEL to the state machine and aborts the agent; a second one hard-exits
+  let stopReason: StopReason = 'interrupt';
+  const interrupts = handleInterrupts((reason) => {
+    stopReason = reason;
     installerStatus = 'cancelled';
     actor?.send({ type: 'CANCEL' });
-  };
-  process.on('SIGINT', handleSigint);
+  });
+  // Context holds this options object, so the agent sees the signal
+  augmentedOptions.abortSignal = interrupts.signal;
+
+  // A timed-out agent has already been stopped; the run stops like a cancel, with its own exit code
+  emitter.on('agent:timeout', ({ kind }) => {
+    stopReason = kind === 'idle' ? 'idle-timeout' : 'timeout';
+    installerStatus = 'cancelled';
+    actor?.send({ type: 'CANCEL' });
+  });
+
+  // Cancelled runs get a checkpoint instead; a dry run writes nothing
+  emitter.on('complete', ({ success, summary, changedFiles, cancelled }) => {
+    if (cancelled || augmentedOptions.dryRun) return;
+    try {
+      const path = report.write(
+        { success, summary, changedFiles, migration: augmentedOptions.migration },
+        augmentedOptions.reportPath,
+      );
+      logInfo(`Run report written to ${path}`);
+      emitter.emit('report:written', { path });
+    } catch (error) {
+      logError('Failed to write run report:', error instanceof Error ? error.message : String(error));
+    }
+  });
 
+  // The patch is what a --diff-only run produces, so failing to write it fails the run
+  let patchError = null as Error | null;
+  emitter.on('complete', ({ success, cancelled }) => {
+    const { changePlan, diffOnly } = augmentedOptions;
+    if (!changePlan || !diffOnly || cancelled || !success) return;
+    try {
+      emitter.emit('change:patch', writePatch(changePlan, augmentedOptions.installDir, diffOnly));
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      logError('Failed to write patch:', message);
+      patchError = new Error(`Failed to write ${diffOnly}: ${message}`);
+    }
+  });
+
+  // Last step the run reached, for the checkpoint
+  let lastState: string | null = null;
+  emitter.on('state:enter', ({ state }) => {
+    if (state !== 'cancelled') lastState = state;
+  });
+
+  let runError: unknown = null;
+  let partialError: Error | null = null;
   try {
     await new Promise<void>((resolve, reject) => {
       actor!.subscribe({
@@ -507,11 +848,65 @@ export async function runWithCore(options: InstallerOptions): Promise<void> {
     });
   } catch (error) {
     installerStatus = 'error';
+    runError = error;
     logError('Wizard failed with error:', error instanceof Error ? error.stack || error.message : String(error));
-    throw error;
   } finally {
-    process.off('SIGINT', handleSigint);
+    interrupts.dispose();
+    if (augmentedOptions.diffOnly) stopDiffOnly();
+    if (augmentedOptions.fileBackups) {
+      stopFileBackups();
+      const files = augmentedOptions.fileBackups.files();
+      if (files.length > 0) emitter.emit('backups:kept', { files });
+    }
+    if (installerStatus === 'cancelled') {
+      const integration = actor?.getSnapshot().context.integration ?? augmentedOptions.integration;
+      reportCancelled(emitter, {
+        installDir: augmentedOptions.installDir,
+        integration,
+        state: lastState,
+        reason: stopReason,
+      });
+      if (steps) checkpointMigrationSteps(steps, augmentedOptions);
+    } else if (steps) {
+      partialError = await settleMigrationSteps(steps, emitter, augmentedOptions, runError);
+    }
     await analytics.shutdown(installerStatus);
     await adapter.stop();
   }
+  if (runError) throw partialError ?? runError;
+
+  if (installerStatus === 'cancelled') {
+    exitWithError(stoppedRunError(stopReason), { json: augmentedOptions.summaryFormat === 'json' });
+  }
+  if (patchError) throw patchError;
+
+  // A partly applied migration is finished by rerunning it, not updated as an install
+  if (partialError) throw partialError;
+
+  // The next run in this project updates this integration instead of adding another
+  if (!augmentedOptions.dryRun) {
+    const integration = actor?.getSnapshot().context.integration ?? augmentedOptions.integration;
+    writeInstallRecord({ installDir: augmentedOptions.installDir, integration });
+  }
+
+  // Failing checks are reported, not fatal, but automation still needs to see them
+  const failedChecks = report.failedChecks();
+  if (failedChecks.length > 0) throw new VerificationFailedError(failedChecks);
+}
+
+/**
+ * Checkpoint a cancelled run and tell the user what it left behind.
+ */
+function reportCancelled(
+  emitter: InstallerEventEmitter,
+  stoppedAt: Omit<Checkpoint, 'changedFiles' | 'cancelledAt'>,
+): void {
+  let changedFiles: string[] = [];
+  try {
+    changedFiles = detectChanges().files;
+  } catch {
+    // Not a git repo; nothing to list
+  }
+  const checkpointPath = writeCheckpoint({ ...stoppedAt, changedFiles });
+  emitter.emit('cancelled', { changedFiles, checkpointPath, reason: stoppedAt.reason });
 }
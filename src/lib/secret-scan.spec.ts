@@ -0,0 +1,155 @@
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { createInstallerEventEmitter } from './events.js';
+import {
+  addToGitignore,
+  envFileExposure,
+  matchSecretPatterns,
+  parseGitGrepOutput,
+  protectEnvFile,
+  scanGitSecrets,
+} from './secret-scan.js';
+
+vi.mock('../utils/clack.js', () => ({
+  default: {
+    log: { warn: vi.fn(), success: vi.fn() },
+  },
+}));
+
+const API_KEY = 'sk_test_a1B2c3D4e5F6g7H8i9J0k1L2';
+
+describe('secret-scan', () => {
+  let dir: string;
+
+  function git(...args: string[]) {
+    execFileSync('git', args, { cwd: dir, stdio: 'ignore' });
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'secret-scan-test-'));
+    git('init', '-q');
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('matchSecretPatterns', () => {
+    it('matches API keys, client secrets and cookie passwords', () => {
+      expect(matchSecretPatterns(`WORKOS_API_KEY=${API_KEY}`).map((p) => p.id)).toEqual(['workos-api-key']);
+      expect(matchSecretPatterns('AUTH0_CLIENT_SECRET="abcdefgh12345678"').map((p) => p.id)).toEqual([
+        'client-secret',
+      ]);
+      expect(matchSecretPatterns('WORKOS_COOKIE_PASSWORD=0123456789abcdef0123456789abcdef').map((p) => p.id)).toEqual(
+        ['cookie-password'],
+      );
+    });
+
+    it('ignores placeholders and env references', () => {
+      expect(matchSecretPatterns('WORKOS_API_KEY=sk_test_...')).toEqual([]);
+      expect(matchSecretPatterns('WORKOS_COOKIE_PASSWORD=')).toEqual([]);
+      expect(matchSecretPatterns('cookiePassword: process.env.WORKOS_COOKIE_PASSWORD,')).toEqual([]);
+      expect(matchSecretPatterns('client-secret: ${GOOGLE_CLIENT_SECRET}')).toEqual([]);
+    });
+  });
+
+  describe('parseGitGrepOutput', () => {
+    it('parses NUL-separated records and redacts the excerpt', () => {
+      const findings = parseGitGrepOutput(`config/.env\x002\x00WORKOS_API_KEY=${API_KEY}\n`, 'index');
+
+      expect(findings).toHaveLength(1);
+      expect(findings[0]).toMatchObject({ file: 'config/.env', line: 2, pattern: 'workos-api-key', source: 'index' });
+      expect(findings[0].excerpt).not.toContain(API_KEY);
+    });
+  });
+
+  describe('scanGitSecrets', () => {
+    it('finds secrets in staged and tracked files but not untracked ones', async () => {
+      writeFileSync(join(dir, '.env'), `WORKOS_API_KEY=${API_KEY}\n`);
+      writeFileSync(join(dir, 'untracked.env'), `WORKOS_API_KEY=${API_KEY}\n`);
+      git('add', '.env');
+
+      const findings = await scanGitSecrets(dir);
+
+      expect(findings.map((f) => `${f.file}:${f.source}`)).toEqual(['.env:index']);
+    });
+
+    it('reports secrets staged but since removed from the working tree', async () => {
+      writeFileSync(join(dir, 'app.ts'), `const key = '${API_KEY}';\n`);
+      git('add', 'app.ts');
+      writeFileSync(join(dir, 'app.ts'), 'const key = process.env.WORKOS_API_KEY;\n');
+
+      const findings = await scanGitSecrets(dir);
+
+      expect(findings).toHaveLength(1);
+      expect(findings[0].source).toBe('index');
+    });
+
+    it('returns nothing for a clean repository', async () => {
+      writeFileSync(join(dir, '.env.example'), 'WORKOS_API_KEY=sk_test_...\nWORKOS_COOKIE_PASSWORD=\n');
+      git('add', '.env.example');
+
+      expect(await scanGitSecrets(dir)).toEqual([]);
+    });
+
+    it('throws outside a git repository', async () => {
+      rmSync(join(dir, '.git'), { recursive: true, force: true });
+
+      await expect(scanGitSecrets(dir)).rejects.toThrow();
+    });
+  });
+
+  describe('envFileExposure', () => {
+    it('distinguishes tracked, unignored and ignored files', async () => {
+      writeFileSync(join(dir, '.env'), 'A=1\n');
+      expect(await envFileExposure(dir, '.env')).toBe('unignored');
+
+      writeFileSync(join(dir, '.gitignore'), '.env\n');
+      expect(await envFileExposure(dir, '.env')).toBeNull();
+
+      git('add', '-f', '.env');
+      expect(await envFileExposure(dir, '.env')).toBe('tracked');
+    });
+  });
+
+  describe('addToGitignore', () => {
+    it('appends once, preserving existing content', () => {
+      writeFileSync(join(dir, '.gitignore'), 'node_modules');
+
+      addToGitignore(dir, '.env');
+      addToGitignore(dir, '.env');
+
+      expect(readFileSync(join(dir, '.gitignore'), 'utf-8')).toBe('node_modules\n.env\n');
+    });
+  });
+
+  describe('protectEnvFile', () => {
+    it('gitignores and untracks a tracked env file when accepted', async () => {
+      writeFileSync(join(dir, '.env'), `WORKOS_API_KEY=${API_KEY}\n`);
+      git('add', '.env');
+      const emitter = createInstallerEventEmitter();
+      emitter.on('prompt:request', ({ id, options }) => emitter.emit('prompt:response', { id, value: options![0] }));
+
+      await protectEnvFile(dir, '.env', { emitter });
+
+      expect(readFileSync(join(dir, '.gitignore'), 'utf-8')).toBe('.env\n');
+      expect(await envFileExposure(dir, '.env')).toBeNull();
+      expect(readFileSync(join(dir, '.env'), 'utf-8')).toContain(API_KEY);
+    });
+
+    it('only warns in CI', async () => {
+      writeFileSync(join(dir, '.env.local'), `WORKOS_API_KEY=${API_KEY}\n`);
+      const emitter = createInstallerEventEmitter();
+      const onPrompt = vi.fn();
+      emitter.on('prompt:request', onPrompt);
+
+      await protectEnvFile(dir, '.env.local', { emitter, ci: true });
+
+      expect(onPrompt).not.toHaveBeenCalled();
+      expect(await envFileExposure(dir, '.env.local')).toBe('unignored');
+    });
+  });
+});
@@ -0,0 +1,206 @@
+/**
+ * Keep WorkOS secrets out of git.
+ *
+ * `scanGitSecrets` greps the tracked tree and the index for API keys,
+ * client secrets and cookie passwords (used by `workos scan secrets`, e.g.
+ * as a pre-commit hook). `protectEnvFile` runs after the installer writes
+ * an env file and offers to gitignore it when git would pick it up.
+ */
+
+import { join } from 'node:path';
+import { applyFileEdits } from './atomic-write.js';
+import type { InstallerEventEmitter, InstallerEvents } from './events.js';
+import { execFileNoThrow } from '../utils/exec-file.js';
+import { redactSecrets } from '../utils/redact.js';
+import clack from '../utils/clack.js';
+
+export interface SecretPattern {
+  id: string;
+  description: string;
+  pattern: RegExp;
+}
+
+/** Values that reference a secret rather than contain it */
+const NOT_A_LITERAL = String.raw`(?!\$|process\.|import\.meta|os\.|env\(|getenv|System\.)`;
+
+export const SECRET_PATTERNS: SecretPattern[] = [
+  {
+    id: 'workos-api-key',
+    description: 'WorkOS API key',
+    pattern: /\bsk_(?:live|test)_[A-Za-z0-9]{16,}/,
+  },
+  {
+    id: 'client-secret',
+    description: 'OAuth client secret',
+    pattern: new RegExp(
+      String.raw`\b(?:[A-Z0-9_]*CLIENT_SECRET|client[_-]secret)["']?\s*[=:]\s*["']?${NOT_A_LITERAL}[^\s"'#,;]{8,}`,
+    ),
+  },
+  {
+    id: 'cookie-password',
+    description: 'WorkOS cookie password',
+    pattern: new RegExp(String.raw`\bWORKOS_COOKIE_PASSWORD["']?\s*[=:]\s*["']?${NOT_A_LITERAL}[^\s"'#,;]{8,}`),
+  },
+];
+
+/** Cheap ERE prefilter for git grep; SECRET_PATTERNS decide the actual findings */
+const GIT_GREP_PREFILTER = 'sk_(live|test)_|CLIENT_SECRET|client[_-]secret|WORKOS_COOKIE_PASSWORD';
+
+export interface SecretFinding {
+  file: string;
+  line: number;
+  pattern: string;
+  description: string;
+  /** Where the secret was found: working tree copy of a tracked file, or staged content */
+  source: 'tracked' | 'index';
+  /** The matching line with the secret redacted */
+  excerpt: string;
+}
+
+/**
+ * Match a single line against SECRET_PATTERNS.
+ */
+export function matchSecretPatterns(text: string): SecretPattern[] {
+  return SECRET_PATTERNS.filter(({ pattern }) => pattern.test(text));
+}
+
+function redactLine(text: string): string {
+  const redacted = SECRET_PATTERNS.reduce(
+    (line, { pattern }) => line.replace(new RegExp(pattern.source, 'g'), (match) => match.slice(0, 12) + '****'),
+    text,
+  );
+  return redactSecrets(redacted.trim()).slice(0, 160);
+}
+
+/**
+ * Parse `git grep -n -z` output (`path\0line\0content\n`).
+ */
+export function parseGitGrepOutput(output: string, source: SecretFinding['source']): SecretFinding[] {
+  return output
+    .split('\n')
+    .filter(Boolean)
+    .flatMap((record) => {
+      const [file, lineNumber, ...rest] = record.split('\0');
+      const text = rest.join('\0');
+      return matchSecretPatterns(text).map((pattern) => ({
+        file,
+        line: Number(lineNumber),
+        pattern: pattern.id,
+        description: pattern.description,
+        source,
+        excerpt: redactLine(text),
+      }));
+    });
+}
+
+/**
+ * Scan tracked files and staged content for secrets.
+ *
+ * @throws If `cwd` is not inside a git repository
+ */
+export async function scanGitSecrets(cwd: string): Promise<SecretFinding[]> {
+  const findings: SecretFinding[] = [];
+  const seen = new Set<string>();
+
+  for (const source of ['index', 'tracked'] as const) {
+    const args = ['grep', '-I', '-n', '-z', '-E', '-e', GIT_GREP_PREFILTER];
+    if (source === 'index') args.splice(1, 0, '--cached');
+
+    // git grep exits 1 when nothing matches
+    const result = await execFileNoThrow('git', args, { cwd });
+    if (result.status > 1) {
+      throw new Error(result.stderr.trim() || 'git grep failed');
+    }
+
+    for (const finding of parseGitGrepOutput(result.stdout, source)) {
+      // The same line staged and in the working tree is reported once
+      const key = `${finding.file}:${finding.line}:${finding.pattern}`;
+      if (seen.has(key)) continue;
+      seen.add(key);
+      findings.push(finding);
+    }
+  }
+
+  return findings;
+}
+
+/**
+ * How git sees an env file: already tracked, present but not ignored, or
+ * safely ignored (null). Also null outside a git repository.
+ */
+export async function envFileExposure(installDir: string, fileName: string): Promise<'tracked' | 'unignored' | null> {
+  const tracked = await execFileNoThrow('git', ['ls-files', '--error-unmatch', '--', fileName], { cwd: installDir });
+  if (tracked.status === 0) return 'tracked';
+
+  // check-ignore: 0 = ignored, 1 = not ignored, 128 = not a repo
+  const ignored = await execFileNoThrow('git', ['check-ignore', '-q', '--', fileName], { cwd: installDir });
+  return ignored.status === 1 ? 'unignored' : null;
+}
+
+/**
+ * Append an entry to .gitignore (created if missing).
+ */
+export function addToGitignore(installDir: string, entry: string): void {
+  applyFileEdits([
+    {
+      path: join(installDir, '.gitignore'),
+      content: (current) => {
+        if (current?.split(/\r?\n/).some((line) => line.trim() === entry)) return current;
+        if (!current) return `${entry}\n`;
+        return `${current}${current.endsWith('\n') ? '' : '\n'}${entry}\n`;
+      },
+    },
+  ]);
+}
+
+function askToIgnore(emitter: InstallerEventEmitter, message: string, options: string[]): Promise<string> {
+  const id = 'gitignore-env-file';
+  return new Promise((resolve) => {
+    const onResponse = ({ id: responseId, value }: InstallerEvents['prompt:response']) => {
+      if (responseId !== id) return;
+      emitter.off('prompt:response', onResponse);
+      resolve(value);
+    };
+    emitter.on('prompt:response', onResponse);
+    emitter.emit('prompt:request', { id, message, options });
+  });
+}
+
+/**
+ * After writing secrets to `fileName`, make sure git won't commit it.
+ * Offers to gitignore the file (and untrack it if it's already tracked);
+ * in CI, in a dry run, or without an emitter to prompt through, only warns.
+ */
+export async function protectEnvFile(
+  installDir: string,
+  fileName: string,
+  options: { emitter?: InstallerEventEmitter; ci?: boolean; dryRun?: boolean } = {},
+): Promise<void> {
+  const exposure = await envFileExposure(installDir, fileName);
+  if (!exposure) return;
+
+  const problem =
+    exposure === 'tracked'
+      ? `${fileName} contains WorkOS secrets and is tracked by git.`
+      : `${fileName} contains WorkOS secrets and is not covered by .gitignore.`;
+
+  // A --diff-only run records env files but must not change git, so there it only warns too
+  if (options.ci || !options.emitter || options.dryRun) {
+    clack.log.warn(`${problem} Add it to .gitignore before committing.`);
+    return;
+  }
+
+  const ignore = exposure === 'tracked' ? 'Add to .gitignore and stop tracking it' : 'Add to .gitignore';
+  const choice = await askToIgnore(options.emitter, `${problem} What should we do?`, [ignore, 'Leave it']);
+  if (choice !== ignore) {
+    clack.log.warn(`${fileName} may be committed with your secrets. Run \`workos scan secrets\` before pushing.`);
+    return;
+  }
+
+  addToGitignore(installDir, fileName);
+  if (exposure === 'tracked') {
+    // Removes the file from the index only; the copy on disk is kept
+    await execFileNoThrow('git', ['rm', '--cached', '--quiet', '--', fileName], { cwd: installDir });
+  }
+  clack.log.success(`Added ${fileName} to .gitignore`);
+}
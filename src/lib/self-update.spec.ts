@@ -0,0 +1,159 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { createHash } from 'node:crypto';
+import { existsSync, mkdirSync, mkdtempSync, readFileSync, rmSync, statSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { gzipSync } from 'node:zlib';
+import {
+  detectInstallMethod,
+  extractTarball,
+  fetchRelease,
+  getUpgradeCommand,
+  replaceInstallation,
+  verifyIntegrity,
+} from './self-update.js';
+
+const mockFetch = vi.fn();
+vi.stubGlobal('fetch', mockFetch);
+
+/** Build a gzipped ustar archive the way `npm pack` lays it out */
+function createTarball(files: Record<string, string>, mode = 0o644): Buffer {
+  const blocks: Buffer[] = [];
+  for (const [name, content] of Object.entries(files)) {
+    const data = Buffer.from(content);
+    const header = Buffer.alloc(512);
+    header.write(`package/${name}`, 0);
+    header.write(mode.toString(8).padStart(7, '0'), 100);
+    header.write(data.length.toString(8).padStart(11, '0'), 124);
+    header.write('0', 156);
+    header.write('ustar', 257);
+    blocks.push(header, data, Buffer.alloc(Math.ceil(data.length / 512) * 512 - data.length));
+  }
+  blocks.push(Buffer.alloc(1024));
+  return gzipSync(Buffer.concat(blocks));
+}
+
+function sri(content: Buffer): string {
+  return `sha512-${createHash('sha512').update(content).digest('base64')}`;
+}
+
+describe('self-update', () => {
+  let testDir: string;
+
+  beforeEach(() => {
+    vi.clearAllMocks();
+    testDir = mkdtempSync(join(tmpdir(), 'self-update-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(testDir, { recursive: true, force: true });
+  });
+
+  describe('detectInstallMethod', () => {
+    it.each([
+      ['/home/dev/.npm/_npx/abc123/node_modules/workos', 'npx'],
+      ['/opt/homebrew/Cellar/workos/0.7.2/libexec/lib/node_modules/workos', 'brew'],
+      ['/home/dev/.local/share/pnpm/global/5/node_modules/workos', 'pnpm'],
+      ['/home/dev/.bun/install/global/node_modules/workos', 'bun'],
+      ['/home/dev/.config/yarn/global/node_modules/workos', 'yarn'],
+      ['/usr/local/lib/node_modules/workos', 'npm'],
+      ['/opt/workos-cli', 'standalone'],
+    ])('%s -> %s', (packageRoot, expected) => {
+      expect(detectInstallMethod(packageRoot)).toBe(expected);
+    });
+  });
+
+  describe('getUpgradeCommand', () => {
+    it('uses the channel dist-tag', () => {
+      expect(getUpgradeCommand('npm', 'stable')).toBe('npm install -g workos@latest');
+      expect(getUpgradeCommand('pnpm', 'beta')).toBe('pnpm add -g workos@beta');
+    });
+
+    it('returns null for standalone installs', () => {
+      expect(getUpgradeCommand('standalone', 'stable')).toBeNull();
+    });
+  });
+
+  describe('fetchRelease', () => {
+    const packument = {
+      'dist-tags': { latest: '0.8.0', beta: '0.9.0-beta.1' },
+      versions: {
+        '0.8.0': { dist: { tarball: 'https://registry.npmjs.org/workos/-/workos-0.8.0.tgz', integrity: 'sha512-a' } },
+        '0.9.0-beta.1': {
+          dist: { tarball: 'https://registry.npmjs.org/workos/-/workos-0.9.0-beta.1.tgz', integrity: 'sha512-b' },
+        },
+      },
+    };
+
+    it('resolves the release for a channel', async () => {
+      mockFetch.mockResolvedValueOnce({ ok: true, json: async () => packument });
+
+      const release = await fetchRelease('beta');
+
+      expect(release.version).toBe('0.9.0-beta.1');
+      expect(release.integrity).toBe('sha512-b');
+    });
+
+    it('throws when the feed is unavailable', async () => {
+      mockFetch.mockResolvedValueOnce({ ok: false, status: 503 });
+
+      await expect(fetchRelease('stable')).rejects.toThrow('HTTP 503');
+    });
+  });
+
+  describe('verifyIntegrity', () => {
+    it('accepts matching content and rejects anything else', () => {
+      const content = Buffer.from('release');
+      expect(verifyIntegrity(content, sri(content))).toBe(true);
+      expect(verifyIntegrity(Buffer.from('tampered'), sri(content))).toBe(false);
+      expect(verifyIntegrity(content, 'md5-abc')).toBe(false);
+    });
+  });
+
+  describe('extractTarball', () => {
+    it('strips the package/ prefix and keeps file modes', async () => {
+      const dest = join(testDir, 'out');
+      await extractTarball(createTarball({ 'dist/bin.js': '#!/usr/bin/env node' }, 0o755), dest);
+
+      expect(readFileSync(join(dest, 'dist/bin.js'), 'utf-8')).toBe('#!/usr/bin/env node');
+      if (process.platform !== 'win32') {
+        expect(statSync(join(dest, 'dist/bin.js')).mode & 0o777).toBe(0o755);
+      }
+    });
+
+    it('refuses entries that escape the destination', async () => {
+      await expect(extractTarball(createTarball({ '../../evil.js': 'x' }), join(testDir, 'out'))).rejects.toThrow(
+        'outside destination',
+      );
+    });
+  });
+
+  describe('replaceInstallation', () => {
+    const pkg = (version: string, deps: Record<string, string> = {}) =>
+      JSON.stringify({ name: 'workos', version, dependencies: deps });
+
+    it('swaps in the new version and keeps unchanged dependencies', async () => {
+      const root = join(testDir, 'workos');
+      mkdirSync(join(root, 'node_modules', 'chalk'), { recursive: true });
+      writeFileSync(join(root, 'package.json'), pkg('0.7.0', { chalk: '^5.0.0' }));
+
+      await replaceInstallation(root, createTarball({ 'package.json': pkg('0.8.0', { chalk: '^5.0.0' }) }));
+
+      expect(JSON.parse(readFileSync(join(root, 'package.json'), 'utf-8')).version).toBe('0.8.0');
+      expect(existsSync(join(root, 'node_modules', 'chalk'))).toBe(true);
+    });
+
+    it('leaves the current install intact when dependencies changed', async () => {
+      const root = join(testDir, 'workos');
+      mkdirSync(join(root, 'node_modules'), { recursive: true });
+      writeFileSync(join(root, 'package.json'), pkg('0.7.0', { chalk: '^5.0.0' }));
+
+      await expect(
+        replaceInstallation(root, createTarball({ 'package.json': pkg('0.8.0', { chalk: '^6.0.0' }) })),
+      ).rejects.toThrow('Dependencies changed');
+
+      expect(JSON.parse(readFileSync(join(root, 'package.json'), 'utf-8')).version).toBe('0.7.0');
+      expect(existsSync(join(root, 'node_modules'))).toBe(true);
+    });
+  });
+});
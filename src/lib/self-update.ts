@@ -0,0 +1,232 @@
+/**
+ * Self-update support for `workos upgrade`.
+ *
+ * Releases are published to npm, so the npm registry is the release feed:
+ * dist-tags map to channels and each version carries its tarball URL and
+ * SRI integrity hash. Package-manager installs are upgraded by that manager;
+ * standalone installs (an unpacked tarball) are replaced in place.
+ */
+
+import { createHash } from 'node:crypto';
+import { existsSync, readFileSync } from 'node:fs';
+import { mkdir, readdir, rename, rm, writeFile } from 'node:fs/promises';
+import path from 'node:path';
+import { fileURLToPath } from 'node:url';
+import { gunzipSync } from 'node:zlib';
+import { proxiedFetch } from './proxy.js';
+
+const NPM_PACKAGE_URL = 'https://registry.npmjs.org/workos';
+const PACKAGE_NAME = 'workos';
+
+export type UpdateChannel = 'stable' | 'beta';
+
+export const CHANNEL_DIST_TAGS: Record<UpdateChannel, string> = {
+  stable: 'latest',
+  beta: 'beta',
+};
+
+export type InstallMethod = 'npx' | 'brew' | 'npm' | 'pnpm' | 'yarn' | 'bun' | 'standalone';
+
+export interface ReleaseInfo {
+  version: string;
+  tarball: string;
+  integrity: string;
+}
+
+interface NpmPackument {
+  'dist-tags': Record<string, string>;
+  versions: Record<string, { dist: { tarball: string; integrity?: string } }>;
+}
+
+/**
+ * Resolve the newest release on a channel.
+ * Beta falls back to latest when no beta has been published.
+ */
+export async function fetchRelease(channel: UpdateChannel): Promise<ReleaseInfo> {
+  const response = await proxiedFetch(NPM_PACKAGE_URL, {
+    headers: { Accept: 'application/vnd.npm.install-v1+json' },
+    signal: AbortSignal.timeout(10_000),
+  });
+  if (!response.ok) {
+    throw new Error(`Release feed returned HTTP ${response.status}`);
+  }
+
+  const packument = (await response.json()) as NpmPackument;
+  const version = packument['dist-tags'][CHANNEL_DIST_TAGS[channel]] ?? packument['dist-tags'].latest;
+  const dist = version ? packument.versions[version]?.dist : undefined;
+  if (!version || !dist?.integrity) {
+    throw new Error(`No release with an integrity hash found for channel "${channel}"`);
+  }
+
+  return { version, tarball: dist.tarball, integrity: dist.integrity };
+}
+
+/**
+ * Root of the running installation (dist/lib/ -> package root).
+ */
+export function getPackageRoot(): string {
+  return path.join(path.dirname(fileURLToPath(import.meta.url)), '..', '..');
+}
+
+/**
+ * Work out how the CLI was installed from where it lives on disk.
+ */
+export function detectInstallMethod(packageRoot: string): InstallMethod {
+  const p = packageRoot.split(path.sep).join('/');
+
+  if (p.includes('/_npx/')) return 'npx';
+  if (p.includes('/Cellar/') || p.includes('/homebrew/') || p.includes('/linuxbrew/')) return 'brew';
+  if (p.includes('/.pnpm/') || p.includes('/pnpm/global/')) return 'pnpm';
+  if (p.includes('/.bun/')) return 'bun';
+  if (p.includes('/yarn/global/') || p.includes('/.config/yarn/')) return 'yarn';
+  if (p.includes('/node_modules/')) return 'npm';
+  return 'standalone';
+}
+
+/**
+ * Command users should run when a package manager owns the installation.
+ */
+export function getUpgradeCommand(method: InstallMethod, channel: UpdateChannel): string | null {
+  const spec = `${PACKAGE_NAME}@${CHANNEL_DIST_TAGS[channel]}`;
+
+  switch (method) {
+    case 'npx':
+      return `npx ${spec}`;
+    case 'brew':
+      return `brew upgrade ${PACKAGE_NAME}`;
+    case 'npm':
+      return `npm install -g ${spec}`;
+    case 'pnpm':
+      return `pnpm add -g ${spec}`;
+    case 'yarn':
+      return `yarn global add ${spec}`;
+    case 'bun':
+      return `bun add -g ${spec}`;
+    case 'standalone':
+      return null;
+  }
+}
+
+/**
+ * Check a download against an SRI string (e.g. "sha512-<base64>").
+ */
+export function verifyIntegrity(content: Buffer, integrity: string): boolean {
+  return integrity.split(/\s+/).some((entry) => {
+    const [algorithm, expected] = [entry.slice(0, entry.indexOf('-')), entry.slice(entry.indexOf('-') + 1)];
+    if (!['sha256', 'sha384', 'sha512'].includes(algorithm) || !expected) return false;
+    return createHash(algorithm).update(content).digest('base64') === expected;
+  });
+}
+
+function readTarString(header: Buffer, start: number, length: number): string {
+  const field = header.subarray(start, start + length);
+  const end = field.indexOf(0);
+  return field.subarray(0, end === -1 ? length : end).toString('utf-8');
+}
+
+/**
+ * Extract an npm package tarball (gzipped ustar, entries under `package/`).
+ */
+export async function extractTarball(tgz: Buffer, destDir: string): Promise<void> {
+  const tar = gunzipSync(tgz);
+  const root = path.resolve(destDir);
+  let offset = 0;
+
+  while (offset + 512 <= tar.length) {
+    const header = tar.subarray(offset, offset + 512);
+    if (header.every((b) => b === 0)) break;
+
+    const name = readTarString(header, 0, 100);
+    const prefix = readTarString(header, 345, 155);
+    const mode = parseInt(readTarString(header, 100, 8).trim() || '644', 8);
+    const size = parseInt(readTarString(header, 124, 12).trim() || '0', 8);
+    const type = String.fromCharCode(header[156] || 48);
+    offset += 512;
+
+    const fullName = prefix ? `${prefix}/${name}` : name;
+    const relName = fullName.replace(/^package\//, '');
+    const target = path.resolve(root, relName);
+    if (target !== root && !target.startsWith(root + path.sep)) {
+      throw new Error(`Refusing to extract outside destination: ${fullName}`);
+    }
+
+    if (type === '0') {
+      await mkdir(path.dirname(target), { recursive: true });
+      await writeFile(target, tar.subarray(offset, offset + size), { mode: mode & 0o777 });
+    } else if (type === '5') {
+      await mkdir(target, { recursive: true });
+    }
+
+    offset += Math.ceil(size / 512) * 512;
+  }
+}
+
+function readDependencies(packageRoot: string): string {
+  const pkg = JSON.parse(readFileSync(path.join(packageRoot, 'package.json'), 'utf-8')) as {
+    dependencies?: Record<string, string>;
+  };
+  return JSON.stringify(pkg.dependencies ?? {});
+}
+
+/**
+ * Remove leftovers from earlier upgrades. On Windows the previous install
+ * can stay locked until the old process exits, so cleanup is deferred.
+ */
+export async function cleanupPreviousInstalls(packageRoot: string): Promise<void> {
+  const parent = path.dirname(packageRoot);
+  const base = path.basename(packageRoot);
+  try {
+    for (const entry of await readdir(parent)) {
+      if (entry.startsWith(`${base}.old-`)) {
+        await rm(path.join(parent, entry), { recursive: true, force: true }).catch(() => {});
+      }
+    }
+  } catch {
+    // Best effort
+  }
+}
+
+/**
+ * Replace a standalone installation with a verified tarball.
+ *
+ * The new version is unpacked next to the current one and swapped in with
+ * renames, so an interrupted upgrade leaves a working install behind.
+ * Installed dependencies are carried over only when they are unchanged.
+ */
+export async function replaceInstallation(packageRoot: string, tgz: Buffer): Promise<void> {
+  const stamp = Date.now();
+  const staging = `${packageRoot}.new-${stamp}`;
+  const backup = `${packageRoot}.old-${stamp}`;
+
+  try {
+    await extractTarball(tgz, staging);
+
+    const modules = path.join(packageRoot, 'node_modules');
+    if (existsSync(modules)) {
+      if (readDependencies(staging) !== readDependencies(packageRoot)) {
+        throw new Error('Dependencies changed in this release; reinstall the CLI with your package manager');
+      }
+      await rename(modules, path.join(staging, 'node_modules'));
+    }
+
+    await rename(packageRoot, backup);
+    try {
+      await rename(staging, packageRoot);
+    } catch (error) {
+      await rename(backup, packageRoot);
+      throw error;
+    }
+  } catch (error) {
+    // Restore dependencies if they were moved before the swap failed
+    const movedModules = path.join(staging, 'node_modules');
+    if (existsSync(movedModules) && !existsSync(path.join(packageRoot, 'node_modules'))) {
+      await rename(movedModules, path.join(packageRoot, 'node_modules')).catch(() => {});
+    }
+    await rm(staging, { recursive: true, force: true });
+    throw error;
+  }
+
+  if (process.platform !== 'win32') {
+    await rm(backup, { recursive: true, force: true });
+  }
+}
@@ -0,0 +1,97 @@
+import { describe, it, expect } from 'vitest';
+import { readFileSync } from 'node:fs';
+import { describeSession, normalizeSealedValue, unsealIron } from './session-seal.js';
+
+interface SdkSeal {
+  /** Package that sealed it, e.g. @workos-inc/authkit-nextjs */
+  sdk: string;
+  version: string;
+  sealed: string;
+}
+
+/**
+ * Seals in the iron-session format, made by the CLI's own sealer, plus the
+ * same `session` sealed by each SDK (see scripts/add-session-seal-fixture.ts).
+ */
+const fixtures: Record<'password' | 'valid' | 'expired' | 'noExpiry' | 'invalidPayload', string> & {
+  session: unknown;
+  sdks: SdkSeal[];
+} = JSON.parse(readFileSync(new URL('./session-seal.fixtures.json', import.meta.url), 'utf-8'));
+
+const NOW = Date.parse('2026-01-01T00:00:00Z');
+
+describe('session-seal', () => {
+  it('unseals a valid session', () => {
+    const result = unsealIron(fixtures.valid, fixtures.password, NOW);
+
+    expect(result.status).toBe('valid');
+    expect(result.expiresAt).toEqual(new Date('2100-01-01T00:00:00Z'));
+    expect(result.data).toEqual(fixtures.session);
+    expect(describeSession(result.data)).toEqual({
+      user: { id: 'user_01', email: 'jane@example.com' },
+      organizationId: 'org_01',
+      claims: expect.objectContaining({ sid: 'session_01', role: 'admin', permissions: ['posts:write'] }),
+      accessTokenExpiresAt: new Date('2100-01-01T00:00:00Z'),
+      hasRefreshToken: true,
+      impersonator: { email: 'support@example.com', reason: 'Investigating a ticket' },
+    });
+  });
+
+  for (const { sdk, version, sealed } of fixtures.sdks) {
+    it(`unseals the session sealed by ${sdk} ${version}`, () => {
+      const result = unsealIron(sealed, fixtures.password, NOW);
+
+      expect(result.status).toBe('valid');
+      expect(result.data).toEqual(fixtures.session);
+      expect(describeSession(result.data).claims).toMatchObject({ sid: 'session_01', org_id: 'org_01' });
+    });
+  }
+
+  it('accepts seals without an expiry', () => {
+    expect(unsealIron(fixtures.noExpiry, fixtures.password, NOW)).toMatchObject({ status: 'valid', expiresAt: null });
+  });
+
+  it('reports expired seals but still shows their contents', () => {
+    const result = unsealIron(fixtures.expired, fixtures.password, NOW);
+
+    expect(result.status).toBe('expired');
+    expect(result.message).toContain('2023-11-14');
+    expect(describeSession(result.data).impersonator).toBeNull();
+  });
+
+  it('reports a wrong password', () => {
+    expect(unsealIron(fixtures.valid, 'a-different-password-that-is-32-chars', NOW).status).toBe('wrong-password');
+    expect(unsealIron(fixtures.valid, 'short', NOW).message).toContain('at least 32 characters');
+  });
+
+  it('reports a modified value as failing the integrity check', () => {
+    const parts = fixtures.valid.split('*');
+    parts[4] = (parts[4][0] === 'A' ? 'B' : 'A') + parts[4].slice(1);
+
+    expect(unsealIron(parts.join('*'), fixtures.password, NOW).status).toBe('wrong-password');
+  });
+
+  it('reports corrupted payloads', () => {
+    expect(unsealIron(fixtures.valid.slice(0, 80), fixtures.password, NOW).status).toBe('corrupted');
+    expect(unsealIron('not a cookie', fixtures.password, NOW).status).toBe('corrupted');
+    // Sealed correctly, but the plaintext isn't JSON
+    expect(unsealIron(fixtures.invalidPayload, fixtures.password, NOW)).toMatchObject({
+      status: 'corrupted',
+      message: expect.stringContaining('could not be decrypted'),
+    });
+  });
+
+  it('normalizes pasted cookie values', () => {
+    const bare = fixtures.valid.replace(/~2$/, '');
+
+    expect(normalizeSealedValue(fixtures.valid)).toBe(bare);
+    expect(normalizeSealedValue(`wos-session=${encodeURIComponent(fixtures.valid)}; Path=/`)).toBe(bare);
+    expect(normalizeSealedValue(`  ${fixtures.valid}\n`)).toBe(bare);
+  });
+
+  it('never exposes the refresh token', () => {
+    const session = describeSession(unsealIron(fixtures.valid, fixtures.password, NOW).data);
+
+    expect(JSON.stringify(session)).not.toContain('refresh_secret_value');
+  });
+});
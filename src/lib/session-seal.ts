@@ -0,0 +1,149 @@
+/**
+ * Unseal AuthKit session cookies.
+ *
+ * The SDKs seal the session with Iron (`Fe26.2`, as implemented by
+ * iron-session): AES-256-CBC for the payload, HMAC-SHA256 over everything
+ * before the MAC, both keyed with PBKDF2-SHA1 (1 iteration) from the cookie
+ * password and a per-seal salt. iron-session appends `~<version>`.
+ *
+ *   Fe26.2*<password id>*<enc salt>*<iv>*<ciphertext>*<expiry ms>*<hmac salt>*<hmac>
+ *
+ * Any change to that format in the SDKs has to be mirrored here; the spec
+ * pins it with fixed fixtures.
+ */
+
+import { createDecipheriv, createHmac, pbkdf2Sync, timingSafeEqual } from 'node:crypto';
+
+const MAC_PREFIX = 'Fe26.2';
+/** Same clock skew iron-session allows */
+const TIMESTAMP_SKEW_MS = 60_000;
+const MIN_PASSWORD_LENGTH = 32;
+
+export type UnsealStatus = 'valid' | 'expired' | 'wrong-password' | 'corrupted';
+
+export interface UnsealResult {
+  status: UnsealStatus;
+  message: string;
+  /** Present for valid and expired seals */
+  data?: unknown;
+  /** Seal expiry; null when the seal has none */
+  expiresAt?: Date | null;
+}
+
+function deriveKey(password: string, salt: string): Buffer {
+  return pbkdf2Sync(password, salt, 1, 32, 'sha1');
+}
+
+/**
+ * Accept a bare sealed value, the `~2` iron-session suffix, a URL-encoded
+ * value or a whole `name=value` cookie pair.
+ */
+export function normalizeSealedValue(input: string): string {
+  let value = input.trim();
+  const pair = /^[\w-]+=(.*)$/s.exec(value);
+  if (pair && !value.startsWith(MAC_PREFIX)) value = pair[1];
+  value = value.replace(/;.*$/s, '');
+  if (value.includes('%')) {
+    try {
+      value = decodeURIComponent(value);
+    } catch {
+      // Leave it; unsealing reports it as corrupted
+    }
+  }
+  return value.replace(/~\d+$/, '');
+}
+
+/**
+ * Unseal an Iron seal, telling a wrong password apart from a damaged value.
+ * Expired seals are still decrypted so their contents can be inspected.
+ */
+export function unsealIron(input: string, password: string, now = Date.now()): UnsealResult {
+  if (password.length < MIN_PASSWORD_LENGTH) {
+    return { status: 'wrong-password', message: `Cookie password must be at least ${MIN_PASSWORD_LENGTH} characters` };
+  }
+
+  const parts = normalizeSealedValue(input).split('*');
+  if (parts.length !== 8 || parts[0] !== MAC_PREFIX) {
+    return {
+      status: 'corrupted',
+      message: `Not an Iron seal (expected 8 "*"-separated parts starting with ${MAC_PREFIX})`,
+    };
+  }
+  const [, , encryptionSalt, ivB64, encryptedB64, expiration, hmacSalt, hmac] = parts;
+
+  const macBase = parts.slice(0, 6).join('*');
+  const expected = createHmac('sha256', deriveKey(password, hmacSalt)).update(macBase).digest();
+  const actual = Buffer.from(hmac, 'base64url');
+  if (actual.length !== expected.length || !timingSafeEqual(actual, expected)) {
+    return {
+      status: 'wrong-password',
+      message: 'Integrity check failed: the cookie password is wrong (or the value was modified)',
+    };
+  }
+
+  // The MAC matched, so anything that fails from here on was sealed broken
+  let data: unknown;
+  try {
+    const key = deriveKey(password, encryptionSalt);
+    const decipher = createDecipheriv('aes-256-cbc', key, Buffer.from(ivB64, 'base64url'));
+    const plaintext = Buffer.concat([decipher.update(Buffer.from(encryptedB64, 'base64url')), decipher.final()]);
+    data = JSON.parse(plaintext.toString('utf-8'));
+  } catch (error) {
+    return {
+      status: 'corrupted',
+      message: `Payload could not be decrypted: ${error instanceof Error ? error.message : String(error)}`,
+    };
+  }
+
+  const expiresAt = expiration ? new Date(Number(expiration)) : null;
+  if (expiresAt && Number.isNaN(expiresAt.getTime())) {
+    return { status: 'corrupted', message: `Invalid expiration "${expiration}"` };
+  }
+  if (expiresAt && expiresAt.getTime() <= now - TIMESTAMP_SKEW_MS) {
+    return { status: 'expired', message: `Session cookie expired ${expiresAt.toISOString()}`, data, expiresAt };
+  }
+  return { status: 'valid', message: 'Session cookie is valid', data, expiresAt };
+}
+
+export interface DecodedSession {
+  user: { id?: string; email?: string } | null;
+  organizationId: string | null;
+  /** Access token claims (signature not verified) */
+  claims: Record<string, unknown> | null;
+  accessTokenExpiresAt: Date | null;
+  hasRefreshToken: boolean;
+  impersonator: { email?: string; reason?: string | null } | null;
+}
+
+export function decodeJwtClaims(token: unknown): Record<string, unknown> | null {
+  if (typeof token !== 'string') return null;
+  try {
+    const payload = JSON.parse(Buffer.from(token.split('.')[1] ?? '', 'base64url').toString('utf-8'));
+    return payload && typeof payload === 'object' ? (payload as Record<string, unknown>) : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Pull the interesting parts out of an unsealed AuthKit session. The
+ * refresh token itself is never returned.
+ */
+export function describeSession(data: unknown): DecodedSession {
+  const session = (data && typeof data === 'object' ? data : {}) as Record<string, unknown>;
+  const claims = decodeJwtClaims(session.accessToken);
+  const user = session.user && typeof session.user === 'object' ? (session.user as Record<string, unknown>) : null;
+  const impersonator =
+    session.impersonator && typeof session.impersonator === 'object'
+      ? (session.impersonator as DecodedSession['impersonator'])
+      : null;
+
+  return {
+    user: user ? { id: user.id as string | undefined, email: user.email as string | undefined } : null,
+    organizationId: (session.organizationId as string | undefined) ?? (claims?.org_id as string | undefined) ?? null,
+    claims,
+    accessTokenExpiresAt: typeof claims?.exp === 'number' ? new Date(claims.exp * 1000) : null,
+    hasRefreshToken: typeof session.refreshToken === 'string' && session.refreshToken.length > 0,
+    impersonator,
+  };
+}
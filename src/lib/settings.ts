@@ -22,6 +22,13 @@ export interface InstallerConfig {
   proxy: {
     refreshThresholdMs: number;
   };
+  http: {
+    retries: number;
+    /** Per-attempt request timeout */
+    timeoutMs: number;
+    /** Longer timeouts for commands (and their subcommands) that make slow calls */
+    commandTimeoutsMs: Record<string, number>;
+  };
   nodeVersion: string;
   logging: {
     debugMode: boolean;
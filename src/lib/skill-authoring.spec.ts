@@ -0,0 +1,96 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdirSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import {
+  createLocalSkillPlugin,
+  parseSkillFrontmatter,
+  scaffoldSkill,
+  setSkillName,
+  validateSkill,
+} from './skill-authoring.js';
+
+describe('skill authoring', () => {
+  let root: string;
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'skill-authoring-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('scaffolds a template skill that fails validation until its placeholders are filled', () => {
+    const dir = scaffoldSkill('my-company-authkit', { parentDir: root });
+
+    const manifest = JSON.parse(readFileSync(join(dir, 'skill.json'), 'utf-8'));
+    expect(manifest).toMatchObject({ id: 'my-company-authkit', name: 'My Company Authkit', version: '0.1.0' });
+    expect(Object.keys(manifest.placeholders)).toContain('sdk-package');
+    expect(JSON.parse(readFileSync(join(dir, 'package.json'), 'utf-8')).scripts.test).toBe('workos skills validate .');
+
+    const result = validateSkill(dir);
+    expect(result.id).toBe('my-company-authkit');
+    expect(result.warnings).toEqual([]);
+    expect(result.errors).toContain(
+      'SKILL.md:3: unfilled placeholder {{company}} (Your company or team name, as the agent should refer to it)',
+    );
+
+    const skillFile = join(dir, 'SKILL.md');
+    writeFileSync(skillFile, readFileSync(skillFile, 'utf-8').replace(/\{\{[a-z-]+\}\}/g, 'filled'));
+    expect(validateSkill(dir).errors).toEqual([]);
+  });
+
+  it('copies a base skill under a new name and adds an example verify.json', () => {
+    const base = join(root, 'workos-authkit-base');
+    mkdirSync(base);
+    const frontmatter = '---\nname: workos-authkit-base\ndescription: Base reference\n---\n';
+    writeFileSync(join(base, 'SKILL.md'), `${frontmatter}\n# Base\n`);
+
+    const dir = scaffoldSkill('acme-authkit', { parentDir: join(root, 'out'), from: base });
+
+    const content = readFileSync(join(dir, 'SKILL.md'), 'utf-8');
+    expect(parseSkillFrontmatter(content)).toEqual({ name: 'acme-authkit', description: 'Base reference' });
+    expect(content).toContain('\n# Base\n');
+    expect(JSON.parse(readFileSync(join(dir, 'skill.json'), 'utf-8'))).toMatchObject({
+      base: 'workos-authkit-base',
+      placeholders: {},
+    });
+    expect(existsSync(join(dir, 'verify.json'))).toBe(true);
+    expect(validateSkill(dir, ['workos-authkit-base']).errors).toEqual([]);
+    expect(() => scaffoldSkill('acme-authkit', { parentDir: join(root, 'out'), from: base })).toThrow(/already exists/);
+  });
+
+  it('reports frontmatter, manifest and verify.json problems', () => {
+    const dir = join(root, 'broken');
+    mkdirSync(dir);
+    writeFileSync(join(dir, 'SKILL.md'), '---\nname: workos-authkit-nextjs\n---\n');
+    writeFileSync(join(dir, 'skill.json'), JSON.stringify({ id: 'other', placeholders: {} }));
+    writeFileSync(join(dir, 'verify.json'), '{ checks: [] }');
+
+    expect(validateSkill(dir, ['workos-authkit-nextjs']).errors).toEqual([
+      'Skill name "workos-authkit-nextjs" is a bundled skill; give the copy its own name',
+      'SKILL.md frontmatter has no description',
+      'skill.json id "other" doesn\'t match name "workos-authkit-nextjs"',
+      expect.stringMatching(/JSON/),
+    ]);
+  });
+
+  it('sets the name in existing frontmatter and packages a local plugin without authoring files', () => {
+    expect(setSkillName('---\ndescription: d\n---\nbody', 'x')).toBe('---\nname: x\ndescription: d\n---\nbody');
+    expect(setSkillName('# No frontmatter\n', 'x')).toBe('---\nname: x\n---\n\n# No frontmatter\n');
+
+    const dir = scaffoldSkill('local-skill', { parentDir: root });
+    const plugin = createLocalSkillPlugin({ name: 'local-skill', dir });
+    try {
+      expect(JSON.parse(readFileSync(join(plugin, '.claude-plugin', 'plugin.json'), 'utf-8')).name).toBe(
+        'local-local-skill',
+      );
+      expect(existsSync(join(plugin, 'skills', 'local-skill', 'SKILL.md'))).toBe(true);
+      expect(existsSync(join(plugin, 'skills', 'local-skill', 'verify.json'))).toBe(true);
+      expect(existsSync(join(plugin, 'skills', 'local-skill', 'skill.json'))).toBe(false);
+    } finally {
+      rmSync(plugin, { recursive: true, force: true });
+    }
+  });
+});
@@ -0,0 +1,289 @@
+/**
+ * Authoring custom skills: scaffold a skill directory, validate it, and
+ * package it as a local plugin so `workos skills run` can hand an
+ * unpublished skill to the agent.
+ *
+ * A skill directory holds the prompt (`SKILL.md`, whose frontmatter `name`
+ * is the skill's id), an optional `verify.json`, and a `skill.json`
+ * manifest documenting the `{{placeholders}}` its templates still contain.
+ */
+
+import { cpSync, existsSync, mkdirSync, mkdtempSync, readdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { basename, join, relative, sep } from 'node:path';
+import { loadVerificationSpec, VERIFY_FILE } from './validation/verification.js';
+
+export const SKILL_FILE = 'SKILL.md';
+export const SKILL_MANIFEST_FILE = 'skill.json';
+
+/** Files that describe the skill directory rather than instruct the agent */
+const AUTHORING_FILES = new Set([SKILL_MANIFEST_FILE, 'package.json', 'node_modules']);
+
+const SKILL_ID = /^[a-z0-9]+(?:-[a-z0-9]+)*$/;
+const PLACEHOLDER = /\{\{\s*([a-z0-9-]+)\s*\}\}/g;
+
+export interface SkillAuthoringManifest {
+  id: string;
+  /** Display name */
+  name: string;
+  description: string;
+  version: string;
+  /** Skill the scaffold was copied from */
+  base?: string;
+  /** `{{key}}` markers in the templates and what to replace each with */
+  placeholders: Record<string, string>;
+}
+
+export interface LocalSkill {
+  /** Skill id, the frontmatter `name` the agent invokes */
+  name: string;
+  /** Absolute path of the skill directory */
+  dir: string;
+}
+
+export interface ScaffoldOptions {
+  /** Directory the skill directory is created in */
+  parentDir: string;
+  /** Skill directory to copy instead of starting from the template */
+  from?: string;
+}
+
+export interface SkillValidation {
+  id: string | null;
+  errors: string[];
+  warnings: string[];
+}
+
+const TEMPLATE_PLACEHOLDERS: Record<string, string> = {
+  company: 'Your company or team name, as the agent should refer to it',
+  framework: 'Framework the skill targets, e.g. Next.js or Express',
+  'sdk-package': 'SDK package the agent installs, e.g. @workos-inc/authkit-nextjs',
+  'callback-route': 'Route that handles the AuthKit callback, e.g. /auth/callback',
+  'house-rules': 'Conventions the agent must follow in your codebases (file layout, lint rules, review asks)',
+};
+
+function skillTemplate(id: string): string {
+  return `---
+name: ${id}
+description: AuthKit integration following {{company}} conventions for {{framework}} apps.
+---
+
+# ${id}
+
+<!--
+  Replace each double-brace placeholder before publishing; skill.json
+  describes them, and \`workos skills validate\` fails while any are left.
+-->
+
+## Step 1: Fetch the SDK documentation
+
+WebFetch the README for \`{{sdk-package}}\` from npmjs.com or GitHub. The README
+is the source of truth for install commands, imports and API usage.
+
+## Step 2: Install the SDK
+
+Install \`{{sdk-package}}\` with the project's package manager. Don't change
+the lockfile format or package manager.
+
+## Step 3: Create the callback route
+
+Create the AuthKit callback at \`{{callback-route}}\`. It must match
+WORKOS_REDIRECT_URI in the project's env file.
+
+## Step 4: Protect routes
+
+Add the SDK's middleware or session check so signed-out users are sent to
+AuthKit, following the README.
+
+## Step 5: Add sign-in UI
+
+Add sign-in and sign-out controls to the home page.
+
+## House rules
+
+{{house-rules}}
+
+## Verification
+
+Run the project's typecheck and build before reporting success. Report
+progress with [STATUS] prefixes.
+`;
+}
+
+/** Typecheck plus a route check: `workos skills run` runs these against the target */
+const EXAMPLE_VERIFY_SPEC = {
+  checks: [
+    { type: 'typecheck' },
+    { type: 'route', name: 'Home page renders or redirects to AuthKit', path: '/', status: [200, 302, 307] },
+  ],
+  devServer: { script: 'dev' },
+};
+
+function writeJson(file: string, value: unknown): void {
+  writeFileSync(file, `${JSON.stringify(value, null, 2)}\n`);
+}
+
+/** Frontmatter fields of a SKILL.md, or null when it has no frontmatter block */
+export function parseSkillFrontmatter(content: string): Record<string, string> | null {
+  const match = /^---\r?\n([\s\S]*?)\r?\n---/.exec(content);
+  if (!match) return null;
+  const fields: Record<string, string> = {};
+  for (const line of match[1].split('\n')) {
+    const field = /^([A-Za-z][\w-]*):\s*(.*)$/.exec(line);
+    if (field) fields[field[1]] = field[2].trim().replace(/^(['"])(.*)\1$/, '$2');
+  }
+  return fields;
+}
+
+/** Rewrite (or add) the frontmatter `name` so a copied skill gets its own id */
+export function setSkillName(content: string, id: string): string {
+  const match = /^---\r?\n([\s\S]*?)\r?\n---/.exec(content);
+  if (!match) return `---\nname: ${id}\n---\n\n${content}`;
+  const lines = match[1].split('\n');
+  const index = lines.findIndex((line) => /^name:/.test(line));
+  if (index === -1) lines.unshift(`name: ${id}`);
+  else lines[index] = `name: ${id}`;
+  return `---\n${lines.join('\n')}\n---${content.slice(match[0].length)}`;
+}
+
+/**
+ * Create `<parentDir>/<id>` from the template or a copy of another skill.
+ * Copies keep their prompt and checks; only the id changes. Either way the
+ * directory gets a skill.json, an example verify.json when it has none, and
+ * a package.json whose `test` script validates the skill.
+ *
+ * @returns The created skill directory
+ */
+export function scaffoldSkill(id: string, options: ScaffoldOptions): string {
+  if (!SKILL_ID.test(id)) {
+    throw new Error(`Invalid skill id "${id}". Use lowercase letters, digits and dashes, e.g. my-company-authkit`);
+  }
+  const dir = join(options.parentDir, id);
+  if (existsSync(dir)) throw new Error(`${dir} already exists`);
+
+  let description = `AuthKit integration following {{company}} conventions for {{framework}} apps.`;
+  let placeholders: Record<string, string> = TEMPLATE_PLACEHOLDERS;
+  if (options.from) {
+    const baseSkill = join(options.from, SKILL_FILE);
+    if (!existsSync(baseSkill)) throw new Error(`${options.from} is not a skill directory (no ${SKILL_FILE})`);
+    cpSync(options.from, dir, { recursive: true, filter: (src) => !AUTHORING_FILES.has(basename(src)) });
+    const content = readFileSync(baseSkill, 'utf-8');
+    writeFileSync(join(dir, SKILL_FILE), setSkillName(content, id));
+    description = parseSkillFrontmatter(content)?.description ?? description;
+    placeholders = {};
+  } else {
+    mkdirSync(dir, { recursive: true });
+    writeFileSync(join(dir, SKILL_FILE), skillTemplate(id));
+  }
+
+  if (!existsSync(join(dir, VERIFY_FILE))) writeJson(join(dir, VERIFY_FILE), EXAMPLE_VERIFY_SPEC);
+  const manifest: SkillAuthoringManifest = {
+    id,
+    name: id
+      .split('-')
+      .map((word) => word.charAt(0).toUpperCase() + word.slice(1))
+      .join(' '),
+    description,
+    version: '0.1.0',
+    ...(options.from && { base: basename(options.from) }),
+    placeholders,
+  };
+  writeJson(join(dir, SKILL_MANIFEST_FILE), manifest);
+  writeJson(join(dir, 'package.json'), {
+    name: id,
+    version: manifest.version,
+    private: true,
+    scripts: { test: 'workos skills validate .' },
+  });
+  return dir;
+}
+
+/** Prompt files (markdown) in a skill directory, relative POSIX paths */
+function promptFiles(dir: string, root = dir): string[] {
+  const files: string[] = [];
+  for (const entry of readdirSync(dir, { withFileTypes: true })) {
+    if (AUTHORING_FILES.has(entry.name)) continue;
+    const full = join(dir, entry.name);
+    if (entry.isDirectory()) files.push(...promptFiles(full, root));
+    else if (entry.name.endsWith('.md')) files.push(relative(root, full).split(sep).join('/'));
+  }
+  return files.sort();
+}
+
+/**
+ * Check a skill directory before it's run or published: the frontmatter, the
+ * manifest's id, verify.json, and leftover `{{placeholders}}`.
+ *
+ * @param bundledSkills Bundled skill names a custom skill must not shadow
+ */
+export function validateSkill(dir: string, bundledSkills: string[] = []): SkillValidation {
+  const errors: string[] = [];
+  const warnings: string[] = [];
+  const skillFile = join(dir, SKILL_FILE);
+  if (!existsSync(skillFile)) return { id: null, errors: [`No ${SKILL_FILE} in ${dir}`], warnings };
+
+  const content = readFileSync(skillFile, 'utf-8');
+  const frontmatter = parseSkillFrontmatter(content);
+  const id = frontmatter?.name || null;
+  if (!frontmatter) errors.push(`${SKILL_FILE} has no frontmatter (--- name/description ---)`);
+  else if (!id) errors.push(`${SKILL_FILE} frontmatter has no name`);
+  else if (!SKILL_ID.test(id)) errors.push(`Skill name "${id}" must be lowercase letters, digits and dashes`);
+  else if (bundledSkills.includes(id)) errors.push(`Skill name "${id}" is a bundled skill; give the copy its own name`);
+  if (frontmatter && !frontmatter.description) errors.push(`${SKILL_FILE} frontmatter has no description`);
+
+  let manifest: SkillAuthoringManifest | null = null;
+  const manifestFile = join(dir, SKILL_MANIFEST_FILE);
+  if (!existsSync(manifestFile)) {
+    warnings.push(`No ${SKILL_MANIFEST_FILE}; placeholders are undocumented`);
+  } else {
+    try {
+      manifest = JSON.parse(readFileSync(manifestFile, 'utf-8')) as SkillAuthoringManifest;
+      if (id && manifest.id !== id) {
+        errors.push(`${SKILL_MANIFEST_FILE} id "${manifest.id}" doesn't match name "${id}"`);
+      }
+    } catch (error) {
+      const message = error instanceof Error ? error.message : String(error);
+      errors.push(`${SKILL_MANIFEST_FILE} is not valid JSON: ${message}`);
+    }
+  }
+
+  try {
+    const spec = loadVerificationSpec(dir);
+    if (!spec || spec.checks.length === 0) warnings.push(`No ${VERIFY_FILE} checks; runs won't be verified`);
+  } catch (error) {
+    errors.push(error instanceof Error ? error.message : String(error));
+  }
+
+  for (const file of promptFiles(dir)) {
+    readFileSync(join(dir, file), 'utf-8')
+      .split('\n')
+      .forEach((line, index) => {
+        for (const match of line.matchAll(PLACEHOLDER)) {
+          const hint = manifest?.placeholders?.[match[1]];
+          errors.push(`${file}:${index + 1}: unfilled placeholder {{${match[1]}}}${hint ? ` (${hint})` : ''}`);
+        }
+      });
+  }
+
+  return { id, errors, warnings };
+}
+
+/**
+ * Package a local skill as a throwaway agent plugin
+ * (`<tmp>/.claude-plugin/plugin.json` plus `skills/<name>`), loaded next to
+ * the bundled plugin. The caller removes the directory when the run ends.
+ */
+export function createLocalSkillPlugin(skill: LocalSkill): string {
+  const pluginDir = mkdtempSync(join(tmpdir(), 'workos-skill-'));
+  mkdirSync(join(pluginDir, '.claude-plugin'));
+  writeJson(join(pluginDir, '.claude-plugin', 'plugin.json'), {
+    name: `local-${skill.name}`,
+    version: '0.0.0',
+    description: `Local skill ${skill.name} (workos skills run)`,
+  });
+  cpSync(skill.dir, join(pluginDir, 'skills', skill.name), {
+    recursive: true,
+    filter: (src) => !AUTHORING_FILES.has(basename(src)),
+  });
+  return pluginDir;
+}
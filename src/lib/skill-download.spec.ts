@@ -0,0 +1,99 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { existsSync, mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { setHttpRetries } from './http-retry.js';
+import { sha256 } from './skill-integrity.js';
+import { _setSkillCacheDir, fetchCachedFile, skillCachePath } from './skill-download.js';
+
+const URL = 'https://skills.example.com/bundle/workos-auth/SKILL.md';
+const CONTENT = '# WorkOS auth\n\nUse AuthKit for sign-in.\n';
+const DIGEST = sha256(CONTENT);
+const OPTIONS = { name: 'workos-auth/SKILL.md', manifestVersion: 1 };
+
+/** A body that sends the first bytes, then drops like a reset connection */
+function dropped(text: string, after: number): ReadableStream<Uint8Array> {
+  let sent = false;
+  return new ReadableStream({
+    pull(controller) {
+      if (sent) controller.error(Object.assign(new TypeError('terminated'), { cause: { code: 'UND_ERR_SOCKET' } }));
+      else controller.enqueue(new TextEncoder().encode(text.slice(0, after)));
+      sent = true;
+    },
+  });
+}
+
+describe('fetchCachedFile', () => {
+  const mockFetch = vi.fn();
+  const originalFetch = globalThis.fetch;
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'skill-download-test-'));
+    _setSkillCacheDir(dir);
+    globalThis.fetch = mockFetch;
+    mockFetch.mockReset();
+    setHttpRetries(1);
+  });
+
+  afterEach(() => {
+    globalThis.fetch = originalFetch;
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('resumes a dropped download with a range request and caches the file', async () => {
+    mockFetch
+      .mockResolvedValueOnce(new Response(dropped(CONTENT, 10), { status: 200 }))
+      .mockImplementationOnce(async (_url: string, init: RequestInit) => {
+        const start = Number(/bytes=(\d+)-/.exec(new Headers(init.headers).get('range') ?? '')![1]);
+        return new Response(CONTENT.slice(start), {
+          status: 206,
+          headers: { 'content-range': `bytes ${start}-${CONTENT.length - 1}/${CONTENT.length}` },
+        });
+      });
+    const progress: number[] = [];
+
+    const result = await fetchCachedFile(URL, DIGEST, { ...OPTIONS, onProgress: ({ bytes }) => progress.push(bytes) });
+
+    expect(result.content.toString()).toBe(CONTENT);
+    expect(result.cached).toBe(false);
+    expect(new Headers(mockFetch.mock.calls[1][1].headers).get('range')).toBe('bytes=10-');
+    expect(progress.at(-1)).toBe(CONTENT.length);
+    expect(readFileSync(skillCachePath(1, DIGEST), 'utf-8')).toBe(CONTENT);
+    expect(existsSync(`${skillCachePath(1, DIGEST)}.part`)).toBe(false);
+
+    // Installing from the bundle again doesn't download it
+    mockFetch.mockClear();
+    expect((await fetchCachedFile(URL, DIGEST, OPTIONS)).cached).toBe(true);
+    expect(mockFetch).not.toHaveBeenCalled();
+  });
+
+  it('starts over when the server ignores the range', async () => {
+    mockFetch
+      .mockResolvedValueOnce(new Response(dropped(CONTENT, 10), { status: 200 }))
+      .mockResolvedValueOnce(new Response(CONTENT, { status: 200 }));
+
+    expect((await fetchCachedFile(URL, DIGEST, OPTIONS)).content.toString()).toBe(CONTENT);
+  });
+
+  it('downloads a file that fails its checksum once more, then fails', async () => {
+    mockFetch
+      .mockResolvedValueOnce(new Response('tampered', { status: 200 }))
+      .mockResolvedValueOnce(new Response(CONTENT, { status: 200 }));
+
+    expect((await fetchCachedFile(URL, DIGEST, OPTIONS)).content.toString()).toBe(CONTENT);
+
+    rmSync(dir, { recursive: true, force: true });
+    mockFetch.mockReset();
+    mockFetch.mockImplementation(async () => new Response('tampered', { status: 200 }));
+
+    await expect(fetchCachedFile(URL, DIGEST, OPTIONS)).rejects.toThrow('Digest mismatch for workos-auth/SKILL.md');
+    expect(mockFetch).toHaveBeenCalledTimes(2);
+    expect(existsSync(skillCachePath(1, DIGEST))).toBe(false);
+  });
+
+  it('refuses a digest that is not a checksum', async () => {
+    await expect(fetchCachedFile(URL, '../../escape', OPTIONS)).rejects.toThrow('Invalid digest');
+    expect(mockFetch).not.toHaveBeenCalled();
+  });
+});
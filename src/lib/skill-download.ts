@@ -0,0 +1,169 @@
+/**
+ * Downloads for remote skill bundles (`install-skill --from <url>`).
+ *
+ * Each file is cached under `~/.workos/cache/skills/`, keyed by the
+ * manifest version and the file's checksum, so installing from the same
+ * bundle again downloads only the files that changed. A file is written to
+ * `<entry>.part` while it downloads; when the connection drops, the next
+ * attempt asks for the rest with a Range request, and a server that ignores
+ * the range sends the whole file again. A file that doesn't match its
+ * checksum is discarded and downloaded once more from scratch before the
+ * download fails.
+ *
+ * Requests go through fetchWithRetry, so 429 and 5xx responses back off
+ * (holding back the other requests too) and Retry-After is honored.
+ */
+
+import {
+  appendFileSync,
+  existsSync,
+  mkdirSync,
+  readFileSync,
+  renameSync,
+  rmSync,
+  statSync,
+  writeFileSync,
+} from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
+import {
+  attemptsSuffix,
+  fetchWithRetry,
+  getHttpRetries,
+  HttpRetryError,
+  isRetryableError,
+  type RetryResult,
+} from './http-retry.js';
+import { sha256 } from './skill-integrity.js';
+import { logWarn } from '../utils/debug.js';
+import { RegistryUnreachableError } from '../utils/errors.js';
+
+let cacheDir = join(homedir(), '.workos', 'cache', 'skills');
+
+/** @internal For testing only */
+export function _setSkillCacheDir(dir: string): void {
+  cacheDir = dir;
+}
+
+export function getSkillCacheDir(): string {
+  return cacheDir;
+}
+
+/** Where a file with this checksum is cached; the version comes from a remote manifest, so it's coerced */
+export function skillCachePath(manifestVersion: number, digest: string): string {
+  return join(cacheDir, `v${Math.floor(Number(manifestVersion)) || 1}`, digest);
+}
+
+/** fetchWithRetry with the URL and attempt count in connection errors */
+export async function fetchBundleFile(url: string, init?: RequestInit): Promise<RetryResult> {
+  try {
+    return await fetchWithRetry(url, init);
+  } catch (error) {
+    if (!(error instanceof HttpRetryError)) throw error;
+    throw new RegistryUnreachableError(`Could not fetch ${url} (${error.message}${attemptsSuffix(error.attempts)})`, {
+      cause: error,
+    });
+  }
+}
+
+export interface DownloadProgress {
+  /** Bytes downloaded so far, including a resumed part */
+  bytes: number;
+  /** The file's size, when the server sent it */
+  total: number | null;
+}
+
+export interface CachedFileOptions {
+  /** How the file is named in errors, e.g. `workos-auth/SKILL.md` */
+  name: string;
+  manifestVersion: number;
+  onProgress?: (progress: DownloadProgress) => void;
+}
+
+/** Where a 206 response's range starts, from `Content-Range: bytes 100-999/1000` */
+function rangeStart(response: Response): number | null {
+  const match = /^bytes (\d+)-/.exec(response.headers.get('content-range') ?? '');
+  return match ? Number(match[1]) : null;
+}
+
+/**
+ * Download into the part file, continuing from what it already holds.
+ * An interrupted body is resumed up to the retry count.
+ */
+async function downloadPart(url: string, part: string, options: CachedFileOptions): Promise<Buffer> {
+  const retries = getHttpRetries();
+  for (let attempt = 1; ; attempt++) {
+    const offset = existsSync(part) ? statSync(part).size : 0;
+    const { response, attempts } = await fetchBundleFile(
+      url,
+      offset > 0 ? { headers: { Range: `bytes=${offset}-` } } : undefined,
+    );
+    // The part is already whole or no longer matches the file, or the server sent another range
+    const unusable = response.status === 416 || (response.status === 206 && rangeStart(response) !== offset);
+    if (unusable && offset > 0 && attempt <= retries) {
+      rmSync(part, { force: true });
+      continue;
+    }
+    if (!response.ok) {
+      throw new RegistryUnreachableError(
+        `Could not fetch ${options.name} (HTTP ${response.status}${attemptsSuffix(attempts)})`,
+      );
+    }
+
+    const resumed = response.status === 206;
+    mkdirSync(dirname(part), { recursive: true });
+    if (!resumed) writeFileSync(part, '');
+    let bytes = resumed ? offset : 0;
+    const length = Number(response.headers.get('content-length'));
+    const total = length > 0 ? bytes + length : null;
+    options.onProgress?.({ bytes, total });
+    const reader = response.body?.getReader();
+    try {
+      while (reader) {
+        const { done, value } = await reader.read();
+        if (done) break;
+        appendFileSync(part, value);
+        bytes += value.length;
+        options.onProgress?.({ bytes, total });
+      }
+      return readFileSync(part);
+    } catch (error) {
+      const reason = error instanceof Error ? error.message : String(error);
+      if (attempt > retries || !isRetryableError(error)) {
+        throw new RegistryUnreachableError(`Download of ${options.name} was interrupted (${reason})`, { cause: error });
+      }
+      logWarn(`[skills] ${url} interrupted after ${bytes} bytes (${reason}), resuming (attempt ${attempt}/${retries})`);
+    }
+  }
+}
+
+/**
+ * A bundle file with this checksum, from the cache or downloaded into it.
+ * @returns The contents, and whether they came from the cache
+ */
+export async function fetchCachedFile(
+  url: string,
+  digest: string,
+  options: CachedFileOptions,
+): Promise<{ content: Buffer; cached: boolean }> {
+  // The digest names the cache entry, so it can't be allowed to be a path
+  if (!/^[0-9a-f]{64}$/.test(digest)) throw new Error(`Invalid digest for ${options.name} in manifest`);
+  const entry = skillCachePath(options.manifestVersion, digest);
+  if (existsSync(entry)) {
+    const content = readFileSync(entry);
+    if (sha256(content) === digest) return { content, cached: true };
+    rmSync(entry, { force: true });
+  }
+
+  const part = `${entry}.part`;
+  for (let attempt = 1; attempt <= 2; attempt++) {
+    const content = await downloadPart(url, part, options);
+    if (sha256(content) === digest) {
+      renameSync(part, entry);
+      return { content, cached: false };
+    }
+    rmSync(part, { force: true });
+    if (attempt === 1) logWarn(`[skills] ${url} does not match its checksum, downloading it again`);
+  }
+  throw new Error(`Digest mismatch for ${options.name}`);
+}
@@ -0,0 +1,149 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { createHash, generateKeyPairSync, randomBytes, sign } from 'node:crypto';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { fileURLToPath } from 'node:url';
+import {
+  buildManifest,
+  digestSkillFiles,
+  hashSkillFiles,
+  readManifest,
+  readSkillLock,
+  verifyMinisign,
+  verifySkill,
+  writeSkillLock,
+} from './skill-integrity.js';
+import { getVersion } from './settings.js';
+
+const BUNDLED_SKILLS_DIR = fileURLToPath(new URL('../../skills', import.meta.url));
+
+function createMinisignKey() {
+  const { publicKey, privateKey } = generateKeyPairSync('ed25519');
+  const keyId = randomBytes(8);
+  const rawPublicKey = publicKey.export({ format: 'der', type: 'spki' }).subarray(-32);
+  const pub = Buffer.concat([Buffer.from('Ed'), keyId, rawPublicKey]).toString('base64');
+
+  const signFile = (content: Buffer, trustedComment = 'timestamp:0') => {
+    const prehashed = createHash('blake2b512').update(content).digest();
+    const signature = sign(null, prehashed, privateKey);
+    const globalSignature = sign(null, Buffer.concat([signature, Buffer.from(trustedComment)]), privateKey);
+    return [
+      'untrusted comment: signature from minisign secret key',
+      Buffer.concat([Buffer.from('ED'), keyId, signature]).toString('base64'),
+      `trusted comment: ${trustedComment}`,
+      globalSignature.toString('base64'),
+    ].join('\n');
+  };
+
+  return { pub: `untrusted comment: minisign public key\n${pub}\n`, signFile };
+}
+
+describe('skill-integrity', () => {
+  let testDir: string;
+
+  beforeEach(() => {
+    testDir = mkdtempSync(join(tmpdir(), 'skill-integrity-test-'));
+    mkdirSync(join(testDir, 'my-skill', 'refs'), { recursive: true });
+    writeFileSync(join(testDir, 'my-skill', 'SKILL.md'), '# My Skill');
+    writeFileSync(join(testDir, 'my-skill', 'refs', 'notes.md'), 'notes');
+  });
+
+  afterEach(() => {
+    rmSync(testDir, { recursive: true, force: true });
+  });
+
+  describe('bundled manifest', () => {
+    it('matches the bundled skills (run `pnpm skills:manifest` after editing skills)', () => {
+      expect(readManifest(BUNDLED_SKILLS_DIR)).toEqual(buildManifest(BUNDLED_SKILLS_DIR));
+    });
+  });
+
+  describe('hashSkillFiles', () => {
+    it('hashes nested files with POSIX relative paths', () => {
+      const files = hashSkillFiles(join(testDir, 'my-skill'));
+      expect(Object.keys(files).sort()).toEqual(['SKILL.md', 'refs/notes.md']);
+      expect(files['SKILL.md']).toBe(createHash('sha256').update('# My Skill').digest('hex'));
+    });
+
+    it('produces a digest that changes with content', () => {
+      const before = digestSkillFiles(hashSkillFiles(join(testDir, 'my-skill')));
+      writeFileSync(join(testDir, 'my-skill', 'SKILL.md'), '# Changed');
+      expect(digestSkillFiles(hashSkillFiles(join(testDir, 'my-skill')))).not.toBe(before);
+    });
+  });
+
+  describe('verifySkill', () => {
+    it('passes for untouched skills', () => {
+      const manifest = buildManifest(testDir);
+      expect(verifySkill(testDir, 'my-skill', manifest).ok).toBe(true);
+    });
+
+    it('reports modified and added files', () => {
+      const manifest = buildManifest(testDir);
+      writeFileSync(join(testDir, 'my-skill', 'SKILL.md'), '# Tampered');
+      writeFileSync(join(testDir, 'my-skill', 'extra.md'), 'extra');
+
+      const result = verifySkill(testDir, 'my-skill', manifest);
+      expect(result.ok).toBe(false);
+      expect(result.mismatched).toEqual(['SKILL.md']);
+      expect(result.unexpected).toEqual(['extra.md']);
+    });
+
+    it('fails for skills missing from the manifest', () => {
+      expect(verifySkill(testDir, 'my-skill', { version: 1, skills: {} }).ok).toBe(false);
+    });
+  });
+
+  describe('verifyMinisign', () => {
+    it('accepts a valid signature', () => {
+      const { pub, signFile } = createMinisignKey();
+      const content = Buffer.from('{"version":1}');
+      expect(verifyMinisign(content, signFile(content), pub)).toBe(true);
+    });
+
+    it('rejects tampered content', () => {
+      const { pub, signFile } = createMinisignKey();
+      const signature = signFile(Buffer.from('{"version":1}'));
+      expect(verifyMinisign(Buffer.from('{"version":2}'), signature, pub)).toBe(false);
+    });
+
+    it('rejects signatures from another key', () => {
+      const content = Buffer.from('{"version":1}');
+      const signature = createMinisignKey().signFile(content);
+      expect(verifyMinisign(content, signature, createMinisignKey().pub)).toBe(false);
+    });
+
+    it('rejects a modified trusted comment', () => {
+      const { pub, signFile } = createMinisignKey();
+      const content = Buffer.from('{"version":1}');
+      const signature = signFile(content).replace('timestamp:0', 'timestamp:1');
+      expect(verifyMinisign(content, signature, pub)).toBe(false);
+    });
+
+    it('returns false for malformed input', () => {
+      expect(verifyMinisign(Buffer.from('x'), 'garbage', 'garbage')).toBe(false);
+    });
+  });
+
+  describe('lockfile', () => {
+    it('round-trips entries and defaults when missing', async () => {
+      const lockPath = join(testDir, 'nested', 'skills-lock.json');
+      expect(await readSkillLock(lockPath)).toEqual({ version: 1, skills: {}, trustedSources: [] });
+
+      await writeSkillLock(
+        {
+          version: 1,
+          skills: { 'codex/my-skill': { digest: 'abc', source: 'workos', installedAt: '2026-01-01T00:00:00.000Z' } },
+          trustedSources: ['https://example.com/skills'],
+        },
+        lockPath,
+      );
+
+      const lock = await readSkillLock(lockPath);
+      expect(lock.skills['codex/my-skill'].digest).toBe('abc');
+      expect(lock.trustedSources).toEqual(['https://example.com/skills']);
+      expect(lock.cliVersion).toBe(getVersion());
+    });
+  });
+});
@@ -0,0 +1,220 @@
+/**
+ * Skill bundle integrity verification.
+ *
+ * Skills are prompt files handed to an agent with write access to the user's
+ * project, so their content is verified before use:
+ * - Official (bundled) skills must match the digests in `skills/manifest.json`
+ * - Third-party bundles may ship a minisign signature over their manifest
+ * - Installed skills are recorded in a lockfile so content changes are detected
+ */
+
+import { createHash, createPublicKey, verify } from 'node:crypto';
+import { existsSync, readFileSync, readdirSync } from 'node:fs';
+import { mkdir, readFile, writeFile } from 'node:fs/promises';
+import { homedir } from 'node:os';
+import { join, relative, sep } from 'node:path';
+import { getVersion } from './settings.js';
+
+export const MANIFEST_FILE = 'manifest.json';
+export const SIGNATURE_FILE = 'manifest.json.minisig';
+
+export interface SkillManifest {
+  version: 1;
+  skills: Record<string, { files: Record<string, string> }>;
+}
+
+export interface SkillVerification {
+  ok: boolean;
+  /** Files whose content does not match the manifest */
+  mismatched: string[];
+  /** Files listed in the manifest but not present */
+  missing: string[];
+  /** Files present but not listed in the manifest */
+  unexpected: string[];
+}
+
+export interface SkillLockEntry {
+  digest: string;
+  source: string;
+  installedAt: string;
+}
+
+export interface SkillLock {
+  version: 1;
+  /** CLI version that last wrote the lock */
+  cliVersion?: string;
+  skills: Record<string, SkillLockEntry>;
+  /** Third-party sources the user already confirmed */
+  trustedSources: string[];
+}
+
+export function sha256(content: Buffer | string): string {
+  return createHash('sha256').update(content).digest('hex');
+}
+
+/**
+ * Hash every file in a skill directory, keyed by POSIX-style relative path.
+ */
+export function hashSkillFiles(skillDir: string): Record<string, string> {
+  const files: Record<string, string> = {};
+
+  function walk(dir: string) {
+    for (const entry of readdirSync(dir, { withFileTypes: true })) {
+      const fullPath = join(dir, entry.name);
+      if (entry.isDirectory()) {
+        walk(fullPath);
+      } else if (entry.isFile()) {
+        const relPath = relative(skillDir, fullPath).split(sep).join('/');
+        files[relPath] = sha256(readFileSync(fullPath));
+      }
+    }
+  }
+
+  walk(skillDir);
+  return files;
+}
+
+/**
+ * Single digest for a skill's content, stable across file ordering.
+ */
+export function digestSkillFiles(files: Record<string, string>): string {
+  const lines = Object.keys(files)
+    .sort()
+    .map((path) => `${path}:${files[path]}`);
+  return sha256(lines.join('\n'));
+}
+
+export function readManifest(bundleDir: string): SkillManifest | null {
+  const manifestPath = join(bundleDir, MANIFEST_FILE);
+  if (!existsSync(manifestPath)) return null;
+  try {
+    const parsed = JSON.parse(readFileSync(manifestPath, 'utf-8')) as SkillManifest;
+    if (parsed.version !== 1 || typeof parsed.skills !== 'object') return null;
+    return parsed;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Build a manifest for every skill directory (containing SKILL.md) in a bundle.
+ */
+export function buildManifest(bundleDir: string): SkillManifest {
+  const skills: SkillManifest['skills'] = {};
+  const dirs = readdirSync(bundleDir, { withFileTypes: true })
+    .filter((e) => e.isDirectory() && existsSync(join(bundleDir, e.name, 'SKILL.md')))
+    .map((e) => e.name)
+    .sort();
+
+  for (const name of dirs) {
+    skills[name] = { files: hashSkillFiles(join(bundleDir, name)) };
+  }
+  return { version: 1, skills };
+}
+
+export function verifySkill(bundleDir: string, skillName: string, manifest: SkillManifest): SkillVerification {
+  const expected = manifest.skills[skillName]?.files ?? {};
+  const actual = hashSkillFiles(join(bundleDir, skillName));
+
+  const mismatched = Object.keys(expected).filter((f) => f in actual && actual[f] !== expected[f]);
+  const missing = Object.keys(expected).filter((f) => !(f in actual));
+  const unexpected = Object.keys(actual).filter((f) => !(f in expected));
+
+  const listed = skillName in manifest.skills;
+  return {
+    ok: listed && mismatched.length === 0 && missing.length === 0 && unexpected.length === 0,
+    mismatched,
+    missing: listed ? missing : ['SKILL.md'],
+    unexpected,
+  };
+}
+
+// --- Minisign signatures ---
+
+// DER prefix for an Ed25519 SubjectPublicKeyInfo; the raw 32-byte key follows
+const ED25519_SPKI_PREFIX = Buffer.from('302a300506032b6570032100', 'hex');
+
+function lastBase64Line(text: string): Buffer {
+  const lines = text
+    .split('\n')
+    .map((l) => l.trim())
+    .filter((l) => l && !l.startsWith('untrusted comment:'));
+  return Buffer.from(lines[0] ?? '', 'base64');
+}
+
+/**
+ * Verify a minisign detached signature.
+ * Supports both legacy ("Ed") and prehashed ("ED", BLAKE2b-512) signatures,
+ * and checks the global signature over the trusted comment.
+ *
+ * @param publicKey - minisign public key (base64 line or full .pub file contents)
+ */
+export function verifyMinisign(content: Buffer, signatureText: string, publicKey: string): boolean {
+  try {
+    const pk = lastBase64Line(publicKey);
+    if (pk.length !== 42 || pk.subarray(0, 2).toString() !== 'Ed') return false;
+    const keyId = pk.subarray(2, 10);
+    const key = createPublicKey({
+      key: Buffer.concat([ED25519_SPKI_PREFIX, pk.subarray(10)]),
+      format: 'der',
+      type: 'spki',
+    });
+
+    const lines = signatureText
+      .split('\n')
+      .map((l) => l.trim())
+      .filter(Boolean);
+    const sigLine = lines.find((l) => !l.includes(':'));
+    const trustedLine = lines.find((l) => l.startsWith('trusted comment: '));
+    const globalLine = trustedLine ? lines[lines.indexOf(trustedLine) + 1] : undefined;
+    if (!sigLine || !trustedLine || !globalLine) return false;
+
+    const sig = Buffer.from(sigLine, 'base64');
+    if (sig.length !== 74) return false;
+    const algorithm = sig.subarray(0, 2).toString();
+    if (!sig.subarray(2, 10).equals(keyId)) return false;
+    const signature = sig.subarray(10);
+
+    const message = algorithm === 'ED' ? createHash('blake2b512').update(content).digest() : content;
+    if (algorithm !== 'Ed' && algorithm !== 'ED') return false;
+    if (!verify(null, message, key, signature)) return false;
+
+    const trustedComment = Buffer.from(trustedLine.slice('trusted comment: '.length));
+    const globalSignature = Buffer.from(globalLine, 'base64');
+    return verify(null, Buffer.concat([signature, trustedComment]), key, globalSignature);
+  } catch {
+    return false;
+  }
+}
+
+// --- Lockfile ---
+
+export function getSkillLockPath(): string {
+  return join(homedir(), '.workos', 'skills-lock.json');
+}
+
+export async function readSkillLock(lockPath = getSkillLockPath()): Promise<SkillLock> {
+  try {
+    const parsed = JSON.parse(await readFile(lockPath, 'utf-8')) as SkillLock;
+    return {
+      version: 1,
+      cliVersion: parsed.cliVersion,
+      skills: parsed.skills ?? {},
+      trustedSources: parsed.trustedSources ?? [],
+    };
+  } catch {
+    return { version: 1, skills: {}, trustedSources: [] };
+  }
+}
+
+export async function writeSkillLock(lock: SkillLock, lockPath = getSkillLockPath()): Promise<void> {
+  await mkdir(join(lockPath, '..'), { recursive: true });
+  await writeFile(lockPath, JSON.stringify({ ...lock, cliVersion: getVersion() }, null, 2) + '\n');
+}
+
+/**
+ * Lock key for a skill installed into a given agent's skills directory.
+ */
+export function skillLockKey(agent: string, skillName: string): string {
+  return `${agent}/${skillName}`;
+}
@@ -0,0 +1,97 @@
+import { describe, it, expect } from 'vitest';
+import { applyAttributeMapping, parseMappingFile, parseSamlAssertion } from './sso-attribute-mapping.js';
+
+const RESPONSE = `<?xml version="1.0" encoding="UTF-8"?>
+<saml2p:Response xmlns:saml2p="urn:oasis:names:tc:SAML:2.0:protocol">
+  <saml2:Assertion xmlns:saml2="urn:oasis:names:tc:SAML:2.0:assertion">
+    <saml2:Subject>
+      <saml2:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">jane@acme.com</saml2:NameID>
+    </saml2:Subject>
+    <saml2:AttributeStatement>
+      <saml2:Attribute Name="user.email" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:basic">
+        <saml2:AttributeValue xsi:type="xs:string">jane@acme.com</saml2:AttributeValue>
+      </saml2:Attribute>
+      <saml2:Attribute Name="firstName">
+        <saml2:AttributeValue>Jane</saml2:AttributeValue>
+      </saml2:Attribute>
+      <saml2:Attribute Name="lastName"><saml2:AttributeValue/></saml2:Attribute>
+      <saml2:Attribute Name="groups">
+        <saml2:AttributeValue>R&amp;D</saml2:AttributeValue>
+        <saml2:AttributeValue><![CDATA[Admins]]></saml2:AttributeValue>
+      </saml2:Attribute>
+      <saml2:Attribute Name="department"/>
+    </saml2:AttributeStatement>
+  </saml2:Assertion>
+</saml2p:Response>`;
+
+describe('sso-attribute-mapping', () => {
+  describe('parseSamlAssertion', () => {
+    it('reads the subject and every attribute value', () => {
+      expect(parseSamlAssertion(RESPONSE)).toEqual({
+        nameId: 'jane@acme.com',
+        attributes: {
+          'user.email': ['jane@acme.com'],
+          firstName: ['Jane'],
+          lastName: [],
+          groups: ['R&D', 'Admins'],
+          department: [],
+        },
+      });
+    });
+
+    it('decodes a base64 SAMLResponse, bare or as a captured form body', () => {
+      const encoded = Buffer.from(RESPONSE).toString('base64');
+      const form = new URLSearchParams({ SAMLResponse: encoded, RelayState: 'abc' }).toString();
+
+      expect(parseSamlAssertion(encoded)).toEqual(parseSamlAssertion(RESPONSE));
+      expect(parseSamlAssertion(form)).toEqual(parseSamlAssertion(RESPONSE));
+    });
+
+    it('explains what it cannot read', () => {
+      expect(() => parseSamlAssertion('not an assertion')).toThrow(/base64-encoded SAMLResponse/);
+      expect(() => parseSamlAssertion('<Response><EncryptedAssertion/></Response>')).toThrow(/encrypted/);
+      expect(() => parseSamlAssertion('<EntityDescriptor/>')).toThrow(/Assertion element/);
+    });
+  });
+
+  describe('applyAttributeMapping', () => {
+    it('flags unmapped attributes and empty required fields', () => {
+      const assertion = parseSamlAssertion(RESPONSE);
+
+      const result = applyAttributeMapping(assertion, {
+        email: 'user.email',
+        first_name: 'firstName',
+        last_name: 'lastName',
+        team: 'team',
+      });
+
+      expect(result.fields).toEqual([
+        { field: 'idp_id', attribute: 'NameID', value: 'jane@acme.com', required: true },
+        { field: 'email', attribute: 'user.email', value: 'jane@acme.com', required: true },
+        { field: 'first_name', attribute: 'firstName', value: 'Jane', required: true },
+        { field: 'last_name', attribute: 'lastName', value: null, required: true },
+        { field: 'team', attribute: 'team', value: null, required: false },
+      ]);
+      expect(result.unmapped).toEqual(['groups', 'department']);
+      expect(result.missing).toEqual(['last_name']);
+    });
+  });
+
+  describe('parseMappingFile', () => {
+    it('reports every problem in the file', () => {
+      const file = JSON.stringify({ first_name: 'firstName', 'Last Name': 'lastName', groups: 3 });
+
+      expect(parseMappingFile(file)).toEqual({
+        mapping: { first_name: 'firstName' },
+        errors: [
+          '"Last Name" is not a profile field name (use lowercase snake_case)',
+          '"groups" must map to an attribute name',
+          '`email` is not mapped',
+        ],
+      });
+      expect(parseMappingFile('[]').errors).toEqual([
+        'expected an object mapping profile fields to IdP attribute names',
+      ]);
+    });
+  });
+});
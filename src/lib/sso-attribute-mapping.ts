@@ -0,0 +1,183 @@
+/**
+ * `sso map-test`: run a SAML assertion through a connection's attribute
+ * mapping without a login.
+ *
+ * The assertion is a file holding the XML, or the base64 SAMLResponse as
+ * captured from the dashboard or a browser's network tab. The mapping is
+ * evaluated here the way WorkOS applies it (first value of the mapped
+ * attribute, NameID for the IdP user ID unless mapped otherwise), so a
+ * proposed mapping can be checked before it's saved to the connection.
+ */
+
+import { workosRequest } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+/** Profile field → IdP attribute name */
+export type AttributeMapping = Record<string, string>;
+
+/** Maps a profile field to the assertion's subject rather than an attribute */
+export const NAME_ID = 'NameID';
+
+/** Profile fields every connection has to fill */
+export const REQUIRED_PROFILE_FIELDS = ['idp_id', 'email', 'first_name', 'last_name'] as const;
+
+const isRequired = (field: string) => (REQUIRED_PROFILE_FIELDS as readonly string[]).includes(field);
+
+export interface SamlAssertion {
+  nameId: string | null;
+  /** Attribute name → its values, in document order */
+  attributes: Record<string, string[]>;
+}
+
+function decodeXmlText(text: string): string {
+  return text
+    .replace(/<!\[CDATA\[([\s\S]*?)\]\]>/g, '$1')
+    .replace(/&#x([0-9a-f]+);/gi, (_, hex: string) => String.fromCodePoint(parseInt(hex, 16)))
+    .replace(/&#(\d+);/g, (_, dec: string) => String.fromCodePoint(parseInt(dec, 10)))
+    .replace(/&quot;/g, '"')
+    .replace(/&apos;/g, "'")
+    .replace(/&lt;/g, '<')
+    .replace(/&gt;/g, '>')
+    .replace(/&amp;/g, '&')
+    .trim();
+}
+
+/** The assertion XML from a file holding XML, a base64 SAMLResponse, or a captured form body */
+function assertionXml(content: string): string {
+  let text = content.trim();
+  if (text.startsWith('<')) return text;
+
+  if (/(^|&)SAMLResponse=/.test(text)) {
+    text = new URLSearchParams(text).get('SAMLResponse') ?? '';
+  } else if (text.includes('%')) {
+    try {
+      text = decodeURIComponent(text);
+    } catch {
+      // Not URL-encoded after all; base64 decoding below reports it
+    }
+  }
+  const decoded = Buffer.from(text.replace(/\s+/g, ''), 'base64').toString('utf-8').trim();
+  if (!decoded.startsWith('<')) {
+    throw new Error('Expected SAML assertion XML or a base64-encoded SAMLResponse.');
+  }
+  return decoded;
+}
+
+/**
+ * Read the subject and attributes from a SAML response or assertion.
+ */
+export function parseSamlAssertion(content: string): SamlAssertion {
+  const xml = assertionXml(content);
+  if (/<(\w+:)?EncryptedAssertion\b/.test(xml)) {
+    throw new Error(
+      'The assertion is encrypted. Capture it from the dashboard, where WorkOS shows it decrypted, ' +
+        'or turn off assertion encryption while testing.',
+    );
+  }
+  if (!/<(\w+:)?Assertion\b/.test(xml)) {
+    throw new Error("The XML doesn't contain a SAML Assertion element.");
+  }
+
+  const nameId = /<(?:\w+:)?NameID\b[^>]*>([\s\S]*?)<\/(?:\w+:)?NameID>/.exec(xml);
+  const attributes: Record<string, string[]> = {};
+  const attributePattern = /<(?:\w+:)?Attribute\b([^>]*?)(?:\/>|>([\s\S]*?)<\/(?:\w+:)?Attribute>)/g;
+  for (const [, attrs, body = ''] of xml.matchAll(attributePattern)) {
+    const name = /\bName="([^"]*)"/.exec(attrs);
+    if (!name) continue;
+    const values = [...body.matchAll(/<(?:\w+:)?AttributeValue\b[^>]*>([\s\S]*?)<\/(?:\w+:)?AttributeValue>/g)];
+    (attributes[decodeXmlText(name[1])] ??= []).push(...values.map(([, value]) => decodeXmlText(value)));
+  }
+  return { nameId: nameId ? decodeXmlText(nameId[1]) : null, attributes };
+}
+
+/**
+ * Check a mapping file: a JSON object from profile field to IdP attribute.
+ * Returns every problem rather than stopping at the first.
+ */
+export function parseMappingFile(content: string): { mapping: AttributeMapping; errors: string[] } {
+  let parsed: unknown;
+  try {
+    parsed = JSON.parse(content);
+  } catch (error) {
+    return { mapping: {}, errors: [`not valid JSON: ${error instanceof Error ? error.message : String(error)}`] };
+  }
+  if (!parsed || typeof parsed !== 'object' || Array.isArray(parsed)) {
+    return { mapping: {}, errors: ['expected an object mapping profile fields to IdP attribute names'] };
+  }
+
+  const mapping: AttributeMapping = {};
+  const errors: string[] = [];
+  for (const [field, attribute] of Object.entries(parsed)) {
+    if (!/^[a-z][a-z0-9_]*$/.test(field)) {
+      errors.push(`"${field}" is not a profile field name (use lowercase snake_case)`);
+    } else if (typeof attribute !== 'string' || !attribute.trim()) {
+      errors.push(`"${field}" must map to an attribute name`);
+    } else {
+      mapping[field] = attribute.trim();
+    }
+  }
+  if (!('email' in parsed)) errors.push('`email` is not mapped');
+  return { mapping, errors };
+}
+
+export interface MappedField {
+  field: string;
+  /** The IdP attribute the field reads, or null when the mapping has none */
+  attribute: string | null;
+  value: string | null;
+  required: boolean;
+}
+
+export interface MappingResult {
+  fields: MappedField[];
+  /** Attributes in the assertion no field reads */
+  unmapped: string[];
+  /** Required fields that came out empty */
+  missing: string[];
+}
+
+/**
+ * Evaluate `mapping` against an assertion.
+ */
+export function applyAttributeMapping(assertion: SamlAssertion, mapping: AttributeMapping): MappingResult {
+  const custom = Object.keys(mapping).filter((field) => !isRequired(field));
+  const fields = [...REQUIRED_PROFILE_FIELDS, ...custom].map((field): MappedField => {
+    const attribute = mapping[field] ?? (field === 'idp_id' ? NAME_ID : null);
+    const value = attribute === NAME_ID ? assertion.nameId : attribute ? assertion.attributes[attribute]?.[0] : null;
+    return { field, attribute, value: value || null, required: isRequired(field) };
+  });
+
+  const used = new Set(fields.map((field) => field.attribute));
+  return {
+    fields,
+    unmapped: Object.keys(assertion.attributes).filter((name) => !used.has(name)),
+    missing: fields.filter((field) => field.required && !field.value).map((field) => field.field),
+  };
+}
+
+export async function getAttributeMapping(connectionId: string, api: ApiOptions): Promise<AttributeMapping> {
+  const result = await workosRequest<{ attribute_mapping: AttributeMapping }>({
+    method: 'GET',
+    path: `/connections/${connectionId}/attribute_mapping`,
+    ...api,
+  });
+  return result.attribute_mapping ?? {};
+}
+
+export async function saveAttributeMapping(
+  connectionId: string,
+  mapping: AttributeMapping,
+  api: ApiOptions,
+): Promise<AttributeMapping> {
+  const result = await workosRequest<{ attribute_mapping: AttributeMapping }>({
+    method: 'PUT',
+    path: `/connections/${connectionId}/attribute_mapping`,
+    body: { attribute_mapping: mapping },
+    ...api,
+  });
+  return result.attribute_mapping ?? mapping;
+}
@@ -0,0 +1,128 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import {
+  buildSsoAuthorizationUrl,
+  formatIdpSetupDoc,
+  idpMetadataBody,
+  reportProfileMapping,
+  resolveConnectionType,
+  startCallbackServer,
+  type SsoConnection,
+  type SsoProfile,
+} from './sso-connections.js';
+
+const connection: SsoConnection = {
+  id: 'conn_01',
+  organization_id: 'org_01',
+  connection_type: 'OktaSAML',
+  name: 'Acme',
+  state: 'draft',
+  saml: { acs_url: 'https://auth.workos.com/sso/saml/acs/abc', sp_entity_id: 'https://auth.workos.com/abc' },
+};
+
+const profile: SsoProfile = {
+  id: 'prof_01',
+  connection_id: 'conn_01',
+  idp_id: '00u1',
+  email: 'jane@acme.com',
+  first_name: 'Jane',
+  last_name: 'Doe',
+  raw_attributes: { 'user.email': 'jane@acme.com', firstName: 'Jane', lastName: 'Doe' },
+};
+
+describe('sso-connections', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'sso-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('rejects unknown connection types with the supported list', () => {
+    expect(resolveConnectionType('okta-saml').connectionType).toBe('OktaSAML');
+    expect(() => resolveConnectionType('okta')).toThrow(/okta-saml/);
+  });
+
+  describe('idpMetadataBody', () => {
+    it('passes URLs through', () => {
+      expect(idpMetadataBody('https://acme.okta.com/metadata')).toEqual({
+        idp_metadata_url: 'https://acme.okta.com/metadata',
+      });
+    });
+
+    it('reads metadata XML from a file', () => {
+      const file = join(dir, 'metadata.xml');
+      writeFileSync(file, '<md:EntityDescriptor entityID="http://www.okta.com/abc"></md:EntityDescriptor>');
+
+      expect(idpMetadataBody(file).idp_metadata_xml).toContain('EntityDescriptor');
+    });
+
+    it('rejects files that are not SAML metadata', () => {
+      const file = join(dir, 'cert.pem');
+      writeFileSync(file, '-----BEGIN CERTIFICATE-----');
+
+      expect(() => idpMetadataBody(file)).toThrow(/doesn't look like SAML metadata/);
+    });
+  });
+
+  it('includes the SP values in the setup doc', () => {
+    const doc = formatIdpSetupDoc(connection, resolveConnectionType('okta-saml'));
+
+    expect(doc).toContain('| Single sign-on URL | `https://auth.workos.com/sso/saml/acs/abc` |');
+    expect(doc).toContain('| Audience URI (SP Entity ID) | `https://auth.workos.com/abc` |');
+  });
+
+  it('builds an SP-initiated authorization URL', () => {
+    const url = new URL(
+      buildSsoAuthorizationUrl('conn_01', 'http://localhost:9004/callback', 'st', { clientId: 'client_01' }),
+    );
+
+    expect(url.origin + url.pathname).toBe('https://api.workos.com/sso/authorize');
+    expect(Object.fromEntries(url.searchParams)).toEqual({
+      connection: 'conn_01',
+      client_id: 'client_01',
+      redirect_uri: 'http://localhost:9004/callback',
+      response_type: 'code',
+      state: 'st',
+    });
+  });
+
+  describe('reportProfileMapping', () => {
+    it('shows which IdP attribute each field came from', () => {
+      const report = reportProfileMapping(profile);
+
+      expect(report.fields.find((f) => f.field === 'email')?.source).toBe('user.email');
+      expect(report.problems).toEqual([]);
+    });
+
+    it('flags a username mapped to email', () => {
+      const report = reportProfileMapping({ ...profile, email: 'jdoe', last_name: null });
+
+      expect(report.problems).toHaveLength(2);
+      expect(report.problems[0]).toContain('"jdoe" is not an email address');
+    });
+  });
+
+  describe('startCallbackServer', () => {
+    it('resolves with the code when the state matches', async () => {
+      const server = await startCallbackServer(0, 'expected');
+
+      await fetch(`${server.redirectUri}?code=abc&state=expected`);
+
+      await expect(server.code).resolves.toBe('abc');
+    });
+
+    it('rejects with the error description from WorkOS', async () => {
+      const server = await startCallbackServer(0, 'expected');
+
+      await fetch(`${server.redirectUri}?error=access_denied&error_description=User%20not%20assigned`);
+
+      await expect(server.code).rejects.toThrow('User not assigned');
+    });
+  });
+});
@@ -0,0 +1,322 @@
+/**
+ * SSO connection setup and testing.
+ *
+ * `sso create` registers a SAML connection for an organization and prints
+ * the service provider (SP) values the customer's IT admin enters in their
+ * identity provider (IdP); the IdP metadata then completes the connection.
+ * `sso test` runs an SP-initiated login against a local callback and shows
+ * the profile WorkOS mapped from the assertion.
+ */
+
+import { readFileSync } from 'node:fs';
+import http from 'node:http';
+import { workosRequest } from './workos-api.js';
+import { loopbackHost } from '../utils/browser.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface SsoConnectionType {
+  /** WorkOS connection_type value */
+  connectionType: string;
+  /** IdP name for messages and the setup doc */
+  idpName: string;
+  /** Where the admin creates the SAML app in the IdP */
+  appLocation: string;
+  /** IdP field names for the ACS URL and SP entity ID */
+  acsField: string;
+  entityIdField: string;
+  /** Where the admin finds the metadata URL once the app exists */
+  metadataLocation: string;
+}
+
+/** Connection types `sso create --type` accepts */
+export const SSO_CONNECTION_TYPES: Record<string, SsoConnectionType> = {
+  'okta-saml': {
+    connectionType: 'OktaSAML',
+    idpName: 'Okta',
+    appLocation: 'Applications → Create App Integration → SAML 2.0',
+    acsField: 'Single sign-on URL',
+    entityIdField: 'Audience URI (SP Entity ID)',
+    metadataLocation: 'the application\'s Sign On tab → "Metadata URL"',
+  },
+  'entra-saml': {
+    connectionType: 'AzureSAML',
+    idpName: 'Microsoft Entra ID',
+    appLocation: 'Enterprise applications → New application → Create your own application (non-gallery)',
+    acsField: 'Reply URL (Assertion Consumer Service URL)',
+    entityIdField: 'Identifier (Entity ID)',
+    metadataLocation: 'Single sign-on → SAML Certificates → "App Federation Metadata Url"',
+  },
+  'google-saml': {
+    connectionType: 'GoogleSAML',
+    idpName: 'Google Workspace',
+    appLocation: 'Admin console → Apps → Web and mobile apps → Add custom SAML app',
+    acsField: 'ACS URL',
+    entityIdField: 'Entity ID',
+    metadataLocation: 'the "Download metadata" step (Google provides a file, not a URL)',
+  },
+  'generic-saml': {
+    connectionType: 'GenericSAML',
+    idpName: 'your identity provider',
+    appLocation: "the IdP's SAML application settings",
+    acsField: 'Assertion Consumer Service (ACS) URL',
+    entityIdField: 'SP Entity ID / Audience',
+    metadataLocation: "the IdP's SAML metadata URL or XML download",
+  },
+};
+
+export interface SsoConnection {
+  id: string;
+  organization_id: string;
+  connection_type: string;
+  name: string;
+  state: 'draft' | 'validating' | 'active' | 'inactive';
+  /** SP values the IdP needs; present for SAML connections */
+  saml?: {
+    acs_url: string;
+    sp_entity_id: string;
+  };
+}
+
+export interface SsoProfile {
+  id: string;
+  connection_id: string;
+  organization_id?: string | null;
+  idp_id: string;
+  email: string;
+  first_name?: string | null;
+  last_name?: string | null;
+  raw_attributes?: Record<string, unknown>;
+}
+
+export function resolveConnectionType(type: string): SsoConnectionType {
+  const resolved = SSO_CONNECTION_TYPES[type];
+  if (!resolved) {
+    throw new Error(`Unknown connection type "${type}". Use one of: ${Object.keys(SSO_CONNECTION_TYPES).join(', ')}`);
+  }
+  return resolved;
+}
+
+export async function createSsoConnection(
+  organizationId: string,
+  type: SsoConnectionType,
+  api: ApiOptions,
+): Promise<SsoConnection> {
+  return workosRequest<SsoConnection>({
+    method: 'POST',
+    path: '/connections',
+    body: { organization_id: organizationId, connection_type: type.connectionType },
+    ...api,
+  });
+}
+
+export async function getSsoConnection(connectionId: string, api: ApiOptions): Promise<SsoConnection> {
+  return workosRequest<SsoConnection>({ method: 'GET', path: `/connections/${connectionId}`, ...api });
+}
+
+/**
+ * Turn a metadata URL or file path into the request body that completes a
+ * SAML connection. Files are checked for an EntityDescriptor so a pasted
+ * certificate or HTML login page fails here rather than in the API.
+ */
+export function idpMetadataBody(source: string): Record<string, string> {
+  if (/^https?:\/\//i.test(source)) return { idp_metadata_url: source };
+
+  let xml: string;
+  try {
+    xml = readFileSync(source, 'utf-8');
+  } catch {
+    throw new Error(`Could not read IdP metadata file ${source}`);
+  }
+  if (!/<(\w+:)?EntityDescriptor\b/.test(xml)) {
+    throw new Error(`${source} doesn't look like SAML metadata (no EntityDescriptor element)`);
+  }
+  return { idp_metadata_xml: xml };
+}
+
+export async function configureIdpMetadata(
+  connectionId: string,
+  source: string,
+  api: ApiOptions,
+): Promise<SsoConnection> {
+  return workosRequest<SsoConnection>({
+    method: 'PUT',
+    path: `/connections/${connectionId}`,
+    body: idpMetadataBody(source),
+    ...api,
+  });
+}
+
+/**
+ * Markdown instructions for the customer's IT admin.
+ */
+export function formatIdpSetupDoc(connection: SsoConnection, type: SsoConnectionType): string {
+  const acsUrl = connection.saml?.acs_url ?? '(not available)';
+  const entityId = connection.saml?.sp_entity_id ?? '(not available)';
+  return [
+    `# SAML setup for ${type.idpName}`,
+    '',
+    `Connection: \`${connection.id}\``,
+    '',
+    '## 1. Create the SAML application',
+    '',
+    `In ${type.idpName}, go to ${type.appLocation}.`,
+    '',
+    '## 2. Enter the service provider details',
+    '',
+    '| Field | Value |',
+    '| --- | --- |',
+    `| ${type.acsField} | \`${acsUrl}\` |`,
+    `| ${type.entityIdField} | \`${entityId}\` |`,
+    '| Name ID format | EmailAddress |',
+    '',
+    '## 3. Map attributes',
+    '',
+    '| Attribute | Value |',
+    '| --- | --- |',
+    "| `email` | User's primary email |",
+    "| `firstName` | User's first name |",
+    "| `lastName` | User's last name |",
+    '| `id` | Stable, unique user identifier |',
+    '',
+    "Map `email` to the user's sign-in email, not a secondary or display address.",
+    '',
+    '## 4. Assign users and share the metadata',
+    '',
+    'Assign the users or groups who should have access, then send back the metadata from',
+    `${type.metadataLocation}.`,
+    '',
+  ].join('\n');
+}
+
+export interface SsoTestClient extends ApiOptions {
+  clientId: string;
+}
+
+export function buildSsoAuthorizationUrl(
+  connectionId: string,
+  redirectUri: string,
+  state: string,
+  client: Pick<SsoTestClient, 'clientId' | 'baseUrl'>,
+): string {
+  const url = new URL('/sso/authorize', client.baseUrl ?? 'https://api.workos.com');
+  url.searchParams.set('connection', connectionId);
+  url.searchParams.set('client_id', client.clientId);
+  url.searchParams.set('redirect_uri', redirectUri);
+  url.searchParams.set('response_type', 'code');
+  url.searchParams.set('state', state);
+  return url.toString();
+}
+
+export async function exchangeSsoCode(code: string, client: SsoTestClient): Promise<SsoProfile> {
+  const result = await workosRequest<{ profile: SsoProfile }>({
+    method: 'POST',
+    path: '/sso/token',
+    body: { client_id: client.clientId, client_secret: client.apiKey, grant_type: 'authorization_code', code },
+    apiKey: client.apiKey,
+    baseUrl: client.baseUrl,
+  });
+  return result.profile;
+}
+
+export interface CallbackServer {
+  redirectUri: string;
+  /** Resolves with the authorization code, rejects on an IdP/WorkOS error or timeout */
+  code: Promise<string>;
+  close: () => void;
+}
+
+/**
+ * Listen on localhost for the SSO redirect and hand back the code. Under WSL
+ * the server listens on every interface so the Windows browser reaches it.
+ */
+export async function startCallbackServer(
+  port: number,
+  state: string,
+  timeoutMs = 5 * 60_000,
+): Promise<CallbackServer> {
+  let settle: { resolve: (code: string) => void; reject: (error: Error) => void } | undefined;
+  const code = new Promise<string>((resolve, reject) => {
+    settle = { resolve, reject };
+  });
+
+  const server = http.createServer((req, res) => {
+    const url = new URL(req.url ?? '/', `http://localhost:${port}`);
+    if (url.pathname !== '/callback') {
+      res.writeHead(404).end();
+      return;
+    }
+    const error = url.searchParams.get('error');
+    const received = url.searchParams.get('code');
+    res.writeHead(200, { 'Content-Type': 'text/plain' });
+    res.end('SSO test complete. You can close this tab and return to the terminal.');
+
+    if (error) {
+      settle?.reject(new Error(url.searchParams.get('error_description') || error));
+    } else if (url.searchParams.get('state') !== state) {
+      settle?.reject(new Error('State mismatch in SSO callback; ignoring the response.'));
+    } else if (received) {
+      settle?.resolve(received);
+    } else {
+      settle?.reject(new Error('SSO callback did not include a code.'));
+    }
+  });
+
+  await new Promise<void>((resolve, reject) => {
+    server.once('error', reject);
+    server.listen(port, loopbackHost(), resolve);
+  });
+  const address = server.address();
+  const listeningPort = address && typeof address === 'object' ? address.port : port;
+
+  const timer = setTimeout(() => {
+    settle?.reject(new Error('Timed out waiting for the SSO login to complete.'));
+  }, timeoutMs);
+  const close = () => {
+    clearTimeout(timer);
+    server.close();
+  };
+  code.then(close, close);
+
+  return { redirectUri: `http://localhost:${listeningPort}/callback`, code, close };
+}
+
+export interface ProfileMappingReport {
+  /** Normalized profile fields with the raw attribute each came from */
+  fields: Array<{ field: string; value: string | null; source: string | null }>;
+  problems: string[];
+}
+
+const EMAIL_PATTERN = /^[^@\s]+@[^@\s]+\.[^@\s]+$/;
+
+function findSource(raw: Record<string, unknown>, value: string | null | undefined): string | null {
+  if (!value) return null;
+  const match = Object.entries(raw).find(([, v]) => v === value || (Array.isArray(v) && v.includes(value)));
+  return match?.[0] ?? null;
+}
+
+/**
+ * Show where each profile field came from and flag the usual mapping
+ * mistakes: missing or malformed email, and missing names.
+ */
+export function reportProfileMapping(profile: SsoProfile): ProfileMappingReport {
+  const raw = profile.raw_attributes ?? {};
+  const fields = (['email', 'first_name', 'last_name', 'idp_id'] as const).map((field) => {
+    const value = profile[field] ?? null;
+    return { field, value, source: findSource(raw, value) };
+  });
+
+  const problems: string[] = [];
+  if (!profile.email) {
+    problems.push("No email was mapped. Map the IdP user's email to the `email` attribute.");
+  } else if (!EMAIL_PATTERN.test(profile.email)) {
+    problems.push(`Email "${profile.email}" is not an email address. Check which IdP field is mapped to \`email\`.`);
+  }
+  if (!profile.first_name || !profile.last_name) {
+    problems.push('First or last name is missing. Map `firstName` and `lastName` in the IdP.');
+  }
+  return { fields, problems };
+}
@@ -6,6 +6,7 @@
  */
 
 import { logInfo, logError } from '../utils/debug.js';
+import { proxiedFetch } from './proxy.js';
 
 export interface StagingCredentials {
   clientId: string;
@@ -38,7 +39,7 @@ export async function fetchStagingCredentials(accessToken: string): Promise<Stag
 
   logInfo('[staging-api] Fetching credentials from:', STAGING_API_URL);
   try {
-    const res = await fetch(STAGING_API_URL, {
+    const res = await proxiedFetch(STAGING_API_URL, {
       headers: { Authorization: `Bearer ${accessToken}` },
       signal: controller.signal,
     });
@@ -0,0 +1,43 @@
+import { getStagingCredentials, saveStagingCredentials } from './credentials.js';
+import { getConfig, saveConfig, getActiveEnvironment } from './config-store.js';
+import { fetchStagingCredentials, type StagingCredentials } from './staging-api.js';
+import { withSessionToken } from './token-refresh.js';
+
+/**
+ * Resolve credentials for the user's development environment.
+ * Prefers the active configured environment, then cached staging credentials,
+ * then fetches them with the logged-in user's token and saves them as the
+ * `default` environment.
+ */
+export async function resolveStagingCredentials(): Promise<StagingCredentials> {
+  const activeEnv = getActiveEnvironment();
+  if (activeEnv?.clientId && activeEnv?.apiKey) {
+    return { clientId: activeEnv.clientId, apiKey: activeEnv.apiKey };
+  }
+
+  const cached = getStagingCredentials();
+  if (cached) return cached;
+
+  const staging = await withSessionToken(fetchStagingCredentials);
+  saveStagingCredentials(staging);
+
+  try {
+    const config = getConfig() ?? { environments: {} };
+    if (!config.environments['default']) {
+      config.environments['default'] = {
+        name: 'default',
+        type: staging.apiKey.startsWith('sk_test_') ? 'sandbox' : 'production',
+        apiKey: staging.apiKey,
+        clientId: staging.clientId,
+      };
+      if (!config.activeEnvironment) {
+        config.activeEnvironment = 'default';
+      }
+      saveConfig(config);
+    }
+  } catch {
+    // Don't block callers if config-store write fails
+  }
+
+  return staging;
+}
@@ -0,0 +1,54 @@
+/**
+ * The saved telemetry choice: loaded at startup, asked for once on the
+ * first interactive run, and changed with `workos telemetry on|off`.
+ */
+
+import chalk from 'chalk';
+import clack, { getPromptMode } from '../utils/clack.js';
+import { isNonInteractiveEnvironment } from '../utils/environment.js';
+import { getConfig, saveConfig } from './config-store.js';
+import { disableTelemetryForRun, envTelemetrySetting, setSavedTelemetryChoice } from '../utils/telemetry-consent.js';
+import { TELEMETRY_LOG_PATH } from '../utils/telemetry-client.js';
+
+export function saveTelemetryChoice(enabled: boolean): void {
+  const config = getConfig() ?? { environments: {} };
+  saveConfig({ ...config, telemetry: enabled });
+  setSavedTelemetryChoice(enabled);
+}
+
+/**
+ * Apply `--no-telemetry` and the saved choice. With no saved choice, ask
+ * once when a person is at the terminal; an answer from the flag or the
+ * environment is not saved, and nothing is asked when they already decide.
+ */
+export async function loadTelemetryPreference(options: { telemetry?: boolean; ask?: boolean }): Promise<void> {
+  if (options.telemetry === false) {
+    disableTelemetryForRun();
+    return;
+  }
+
+  const saved = getConfig()?.telemetry;
+  if (saved !== undefined) {
+    setSavedTelemetryChoice(saved);
+    return;
+  }
+  if (options.ask === false || envTelemetrySetting() !== undefined) return;
+  if (isNonInteractiveEnvironment() || getPromptMode() === 'none') return;
+
+  clack.log.info(
+    'The WorkOS CLI can send anonymous usage data to help improve it: the command name, how long it took, ' +
+      'whether it succeeded (and the error class if not), the CLI version and your OS and architecture, plus ' +
+      'framework and token counts for installs. No code, file paths, emails, credentials or WorkOS IDs are sent. ' +
+      `Events are sent in the background after a command exits; \`workos telemetry show\` prints what's pending ` +
+      `and each sent payload is written to ${chalk.dim(TELEMETRY_LOG_PATH)}.`,
+  );
+  const enabled = await clack.confirm({ message: 'Share anonymous usage data?', initialValue: true });
+  if (clack.isCancel(enabled)) return;
+
+  saveTelemetryChoice(enabled);
+  clack.log.info(
+    chalk.dim(
+      'Change this any time with `workos telemetry on|off`, per run with --no-telemetry or WORKOS_CLI_TELEMETRY=0.',
+    ),
+  );
+}
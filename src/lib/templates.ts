@@ -0,0 +1,59 @@
+/**
+ * Starter app templates for `workos init`.
+ *
+ * Each template is declared by an installer integration (`metadata.starter`)
+ * and read from the integration registry, so the skill, ports, callback
+ * paths and env conventions come from the same place the installer gets
+ * them.
+ */
+
+import { join } from 'node:path';
+import { getCallbackPath, getDefaultPort } from './port-detection.js';
+import { getRegistry } from './registry.js';
+import { getPackageRoot } from './self-update.js';
+import type { Integration } from './constants.js';
+import type { StarterTemplate } from './framework-config.js';
+
+export interface AppTemplate extends StarterTemplate {
+  /** Installer integration this template corresponds to */
+  integration: Integration;
+  /** Bundled skill with the integration instructions for this framework */
+  skill: string;
+  port: number;
+  callbackPath: string;
+}
+
+let templates: Promise<AppTemplate[]> | null = null;
+
+/** Every integration's starter, in the registry's priority order */
+export function getTemplates(): Promise<AppTemplate[]> {
+  templates ??= getRegistry().then((registry) =>
+    registry.all().flatMap(({ metadata }) => {
+      const { starter, integration, skillName } = metadata;
+      if (!starter || !skillName) return [];
+      return [
+        {
+          ...starter,
+          integration,
+          skill: skillName,
+          port: starter.port ?? getDefaultPort(integration),
+          callbackPath: starter.callbackPath ?? getCallbackPath(integration),
+        },
+      ];
+    }),
+  );
+  return templates;
+}
+
+export async function getTemplate(name: string): Promise<AppTemplate | undefined> {
+  return (await getTemplates()).find((t) => t.name === name);
+}
+
+export async function getTemplateForFramework(framework: string): Promise<AppTemplate | undefined> {
+  return (await getTemplates()).find((t) => t.framework === framework.toLowerCase());
+}
+
+/** Where a bundled template's files live */
+export function bundledTemplateDir(template: AppTemplate): string | undefined {
+  return template.bundled ? join(getPackageRoot(), 'templates', template.bundled) : undefined;
+}
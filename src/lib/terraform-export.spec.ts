@@ -0,0 +1,137 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import type { EnvironmentSnapshot } from './terraform-export.js';
+
+vi.mock('./workos-api.js', async (importOriginal) => ({
+  ...(await importOriginal<typeof import('./workos-api.js')>()),
+  workosRequest: vi.fn(),
+}));
+
+const { workosRequest, WorkOSApiError } = await import('./workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+
+const { fetchEnvironmentSnapshot, hclString, limitToOrganizations, renderExportJson, renderTerraform, terraformName } =
+  await import('./terraform-export.js');
+
+const meta = { exportedAt: new Date('2026-01-02T03:04:05Z'), source: 'https://api.workos.com' };
+
+function list<T>(data: T[]) {
+  return { data, list_metadata: { before: null, after: null } };
+}
+
+function snapshot(overrides: Partial<EnvironmentSnapshot> = {}): EnvironmentSnapshot {
+  return {
+    organizations: [],
+    connections: [],
+    redirectUris: [],
+    roles: [],
+    webhookEndpoints: [],
+    unreadable: [],
+    ...overrides,
+  };
+}
+
+describe('terraform-export', () => {
+  beforeEach(() => {
+    mockRequest.mockReset();
+  });
+
+  it('escapes interpolation in HCL strings', () => {
+    expect(hclString('Acme "Inc"')).toBe('"Acme \\"Inc\\""');
+    expect(hclString('${var.x} %{if}')).toBe('"$${var.x} %%{if}"');
+  });
+
+  it('derives unique resource names', () => {
+    const used = new Set<string>();
+
+    expect(terraformName('Acme, Inc.', used)).toBe('acme_inc');
+    expect(terraformName('acme inc', used)).toBe('acme_inc_2');
+    expect(terraformName('42 Labs', used)).toBe('r_42_labs');
+  });
+
+  it('renders resources with their import commands', () => {
+    const { hcl, imports } = renderTerraform(
+      snapshot({
+        organizations: [{ id: 'org_01', name: 'Acme', domains: ['acme.com'] }],
+        redirectUris: [{ id: 'ru_01', uri: 'http://localhost:3000/callback', default: true }],
+        roles: [{ slug: 'admin', name: 'Admin', description: null, permissions: ['posts:write'] }],
+      }),
+      meta,
+    );
+
+    expect(hcl).toContain('source = "workos/workos"');
+    expect(hcl).toContain('resource "workos_organization" "acme" {\n  name    = "Acme"\n  domains = ["acme.com"]\n}');
+    expect(hcl).toContain('resource "workos_role" "admin" {');
+    expect(hcl).not.toContain('description');
+    expect(imports).toEqual([
+      'terraform import workos_organization.acme org_01',
+      'terraform import workos_redirect_uri.localhost_3000_callback ru_01',
+      'terraform import workos_role.admin admin',
+    ]);
+    expect(hcl).toContain('#   terraform import workos_role.admin admin');
+  });
+
+  it('lists connections and unreadable kinds in comments', () => {
+    const { hcl, imports } = renderTerraform(
+      snapshot({
+        connections: [
+          { id: 'conn_01', name: 'Okta', connectionType: 'OktaSAML', organizationId: 'org_01', state: 'active' },
+        ],
+        unreadable: [{ kind: 'webhook endpoints', reason: 'Forbidden' }],
+      }),
+      meta,
+    );
+
+    expect(hcl).not.toContain('resource "workos_connection"');
+    expect(hcl).toContain('#   conn_01 "Okta" (OktaSAML, active, organization org_01)');
+    expect(hcl).toContain('#   webhook endpoints: Forbidden');
+    expect(imports).toEqual([]);
+  });
+
+  it('exports JSON with the same snapshot', () => {
+    const exported = JSON.parse(renderExportJson(snapshot({ roles: [] }), meta));
+
+    expect(exported).toMatchObject({ exportedAt: '2026-01-02T03:04:05.000Z', roles: [], unreadable: [] });
+  });
+
+  it('narrows an export to some organizations and their connections', () => {
+    const limited = limitToOrganizations(
+      snapshot({
+        organizations: [
+          { id: 'org_1', name: 'Acme', domains: [] },
+          { id: 'org_2', name: 'Globex', domains: [] },
+        ],
+        connections: [
+          { id: 'conn_1', name: 'Acme SSO', connectionType: 'OktaSAML', organizationId: 'org_1', state: 'active' },
+          { id: 'conn_2', name: 'Globex SSO', connectionType: 'OktaSAML', organizationId: 'org_2', state: 'active' },
+        ],
+        roles: [{ slug: 'admin' } as EnvironmentSnapshot['roles'][number]],
+      }),
+      ['org_1'],
+    );
+
+    expect(limited.organizations.map((o) => o.id)).toEqual(['org_1']);
+    expect(limited.connections.map((c) => c.id)).toEqual(['conn_1']);
+    expect(limited.roles).toHaveLength(1);
+  });
+
+  describe('fetchEnvironmentSnapshot', () => {
+    it('records kinds the key cannot read and keeps the rest', async () => {
+      mockRequest.mockImplementation(async ({ path }) => {
+        if (path === '/organizations') return list([{ id: 'org_01', name: 'Acme', domains: [{ domain: 'acme.com' }] }]);
+        if (path === '/webhook_endpoints') throw new WorkOSApiError('Forbidden', 403);
+        return list([]);
+      });
+
+      const result = await fetchEnvironmentSnapshot({ apiKey: 'sk_test_123' });
+
+      expect(result.organizations).toEqual([{ id: 'org_01', name: 'Acme', domains: ['acme.com'] }]);
+      expect(result.unreadable).toEqual([{ kind: 'webhook endpoints', reason: 'Forbidden' }]);
+    });
+
+    it('fails on an invalid API key', async () => {
+      mockRequest.mockRejectedValue(new WorkOSApiError('Unauthorized', 401));
+
+      await expect(fetchEnvironmentSnapshot({ apiKey: 'sk_test_bad' })).rejects.toThrow('Unauthorized');
+    });
+  });
+});
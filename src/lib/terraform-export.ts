@@ -0,0 +1,315 @@
+/**
+ * Export of an environment's configuration for infrastructure as code
+ * (`workos export terraform`).
+ *
+ * The environment is read through the API into a snapshot, which renders as
+ * HCL for the WorkOS Terraform provider, with the `terraform import` commands
+ * that adopt the existing resources into state, or as plain JSON. Resources
+ * the provider can't manage, such as SSO connections (set up with the
+ * customer's IdP), are listed in a comment block instead of being dropped,
+ * and so are resource kinds the API key couldn't read.
+ */
+
+import { listRoles } from './roles.js';
+import { listAll } from './pagination.js';
+import { WorkOSApiError } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface ExportedOrganization {
+  id: string;
+  name: string;
+  domains: string[];
+}
+
+export interface ExportedConnection {
+  id: string;
+  name: string;
+  connectionType: string;
+  organizationId: string | null;
+  state: string;
+}
+
+export interface ExportedRedirectUri {
+  id: string | null;
+  uri: string;
+  default: boolean;
+}
+
+export interface ExportedRole {
+  slug: string;
+  name: string;
+  description: string | null;
+  permissions: string[];
+}
+
+export interface ExportedWebhookEndpoint {
+  id: string;
+  url: string;
+  events: string[];
+  enabled: boolean;
+}
+
+export interface EnvironmentSnapshot {
+  organizations: ExportedOrganization[];
+  connections: ExportedConnection[];
+  redirectUris: ExportedRedirectUri[];
+  roles: ExportedRole[];
+  webhookEndpoints: ExportedWebhookEndpoint[];
+  /** Resource kinds that couldn't be read, and why */
+  unreadable: Array<{ kind: string; reason: string }>;
+}
+
+interface RawOrganization {
+  id: string;
+  name: string;
+  domains: Array<{ domain: string }>;
+}
+
+interface RawConnection {
+  id: string;
+  name: string;
+  connection_type: string;
+  organization_id?: string;
+  state: string;
+}
+
+interface RawRedirectUri {
+  id?: string;
+  uri: string;
+  default?: boolean;
+}
+
+interface RawWebhookEndpoint {
+  id: string;
+  endpoint_url: string;
+  events: string[];
+  status: string;
+}
+
+/**
+ * Read every exported resource kind. A kind the key can't read (or the
+ * environment doesn't have) is recorded as unreadable; an invalid key fails
+ * the export.
+ */
+export async function fetchEnvironmentSnapshot(api: ApiOptions): Promise<EnvironmentSnapshot> {
+  const unreadable: EnvironmentSnapshot['unreadable'] = [];
+  async function read<T>(kind: string, load: () => Promise<T[]>): Promise<T[]> {
+    try {
+      return await load();
+    } catch (error) {
+      if (error instanceof WorkOSApiError && error.statusCode === 401) throw error;
+      unreadable.push({ kind, reason: error instanceof Error ? error.message : String(error) });
+      return [];
+    }
+  }
+
+  const organizations = await read('organizations', async () =>
+    (await listAll<RawOrganization>({ path: '/organizations', ...api })).map((org) => ({
+      id: org.id,
+      name: org.name,
+      domains: org.domains.map((d) => d.domain),
+    })),
+  );
+  const connections = await read('connections', async () =>
+    (await listAll<RawConnection>({ path: '/connections', ...api })).map((c) => ({
+      id: c.id,
+      name: c.name,
+      connectionType: c.connection_type,
+      organizationId: c.organization_id ?? null,
+      state: c.state,
+    })),
+  );
+  const redirectUris = await read('redirect URIs', async () =>
+    (await listAll<RawRedirectUri>({ path: '/user_management/redirect_uris', ...api })).map((r) => ({
+      id: r.id ?? null,
+      uri: r.uri,
+      default: r.default ?? false,
+    })),
+  );
+  const roles = await read('roles', async () =>
+    (await listRoles(api)).map(({ slug, name, description, permissions }) => ({
+      slug,
+      name,
+      description,
+      permissions,
+    })),
+  );
+  const webhookEndpoints = await read('webhook endpoints', async () =>
+    (await listAll<RawWebhookEndpoint>({ path: '/webhook_endpoints', ...api })).map((w) => ({
+      id: w.id,
+      url: w.endpoint_url,
+      events: w.events,
+      enabled: w.status !== 'disabled',
+    })),
+  );
+
+  return { organizations, connections, redirectUris, roles, webhookEndpoints, unreadable };
+}
+
+/**
+ * The snapshot narrowed to some organizations and their SSO connections.
+ * Environment-wide settings (redirect URIs, roles, webhooks) stay, since
+ * every organization relies on them.
+ */
+export function limitToOrganizations(snapshot: EnvironmentSnapshot, ids: string[]): EnvironmentSnapshot {
+  const keep = new Set(ids);
+  return {
+    ...snapshot,
+    organizations: snapshot.organizations.filter((org) => keep.has(org.id)),
+    connections: snapshot.connections.filter((c) => !c.organizationId || keep.has(c.organizationId)),
+  };
+}
+
+/** HCL string literal; `${` and `%{` would otherwise start interpolation */
+export function hclString(value: string): string {
+  return JSON.stringify(value).replace(/\$\{/g, '$$$${').replace(/%\{/g, '%%{');
+}
+
+function hclValue(value: string | boolean | string[]): string {
+  if (typeof value === 'boolean') return String(value);
+  if (Array.isArray(value)) return `[${value.map(hclString).join(', ')}]`;
+  return hclString(value);
+}
+
+/**
+ * Terraform resource name for a label: lowercase letters, digits and
+ * underscores, starting with a letter, unique within `used`.
+ */
+export function terraformName(label: string, used: Set<string>): string {
+  let base = label
+    .toLowerCase()
+    .replace(/[^a-z0-9]+/g, '_')
+    .replace(/^_+|_+$/g, '');
+  if (!/^[a-z]/.test(base)) base = `r_${base}`.replace(/_+$/, '');
+  let name = base;
+  for (let n = 2; used.has(name); n++) name = `${base}_${n}`;
+  used.add(name);
+  return name;
+}
+
+interface TerraformResource {
+  type: string;
+  name: string;
+  /** ID `terraform import` takes */
+  importId: string;
+  attributes: Array<[string, string | boolean | string[] | null | undefined]>;
+}
+
+function renderResource({ type, name, attributes }: TerraformResource): string {
+  const set = attributes.filter((entry): entry is [string, string | boolean | string[]] => entry[1] != null);
+  // Aligned like `terraform fmt`
+  const width = Math.max(0, ...set.map(([key]) => key.length));
+  const body = set.map(([key, value]) => `  ${key.padEnd(width)} = ${hclValue(value)}`);
+  return [`resource "${type}" "${name}" {`, ...body, '}'].join('\n');
+}
+
+function withoutScheme(url: string): string {
+  return url.replace(/^[a-z][a-z0-9+.-]*:\/\//i, '');
+}
+
+function terraformResources(snapshot: EnvironmentSnapshot): TerraformResource[] {
+  const resources: TerraformResource[] = [];
+  const names = new Map<string, Set<string>>();
+  const add = (type: string, label: string, importId: string, attributes: TerraformResource['attributes']) => {
+    const used = names.get(type) ?? new Set<string>();
+    names.set(type, used);
+    resources.push({ type, name: terraformName(label, used), importId, attributes });
+  };
+
+  for (const org of snapshot.organizations) {
+    add('workos_organization', org.name, org.id, [
+      ['name', org.name],
+      ['domains', org.domains.length > 0 ? org.domains : null],
+    ]);
+  }
+  for (const redirect of snapshot.redirectUris) {
+    add('workos_redirect_uri', withoutScheme(redirect.uri), redirect.id ?? redirect.uri, [
+      ['uri', redirect.uri],
+      ['default', redirect.default || null],
+    ]);
+  }
+  for (const role of snapshot.roles) {
+    add('workos_role', role.slug, role.slug, [
+      ['slug', role.slug],
+      ['name', role.name],
+      ['description', role.description],
+      ['permissions', role.permissions],
+    ]);
+  }
+  for (const webhook of snapshot.webhookEndpoints) {
+    add('workos_webhook_endpoint', withoutScheme(webhook.url), webhook.id, [
+      ['url', webhook.url],
+      ['events', webhook.events],
+      ['enabled', webhook.enabled],
+    ]);
+  }
+  return resources;
+}
+
+export interface TerraformExport {
+  hcl: string;
+  /** `terraform import` commands, one per resource */
+  imports: string[];
+}
+
+export function renderTerraform(
+  snapshot: EnvironmentSnapshot,
+  meta: { exportedAt: Date; source: string },
+): TerraformExport {
+  const resources = terraformResources(snapshot);
+  const imports = resources.map((r) => `terraform import ${r.type}.${r.name} ${r.importId}`);
+
+  const sections = [
+    `# Exported by \`workos export terraform\` from ${meta.source} at ${meta.exportedAt.toISOString()}.\n` +
+      '# Run the import commands at the end of this file so Terraform adopts these\n' +
+      '# resources instead of creating them again.',
+    [
+      'terraform {',
+      '  required_providers {',
+      '    workos = {',
+      '      source = "workos/workos"',
+      '    }',
+      '  }',
+      '}',
+    ].join('\n'),
+    ...resources.map(renderResource),
+  ];
+
+  const unsupported = snapshot.connections.map(
+    (c) =>
+      `#   ${c.id} ${hclString(c.name)} (${c.connectionType}, ${c.state}` +
+      `${c.organizationId ? `, organization ${c.organizationId}` : ''})`,
+  );
+  if (unsupported.length > 0) {
+    sections.push(
+      [
+        '# SSO connections: the provider has no resource for these, since each is set',
+        "# up with the customer's identity provider (Admin Portal or dashboard).",
+        ...unsupported,
+      ].join('\n'),
+    );
+  }
+  if (snapshot.unreadable.length > 0) {
+    sections.push(
+      [
+        "# Not exported, because the API key couldn't read them:",
+        ...snapshot.unreadable.map(({ kind, reason }) => `#   ${kind}: ${reason}`),
+      ].join('\n'),
+    );
+  }
+  if (imports.length > 0) {
+    sections.push(['# Import commands:', ...imports.map((command) => `#   ${command}`)].join('\n'));
+  }
+
+  return { hcl: sections.join('\n\n') + '\n', imports };
+}
+
+/** The provider-neutral export (`--format json`) */
+export function renderExportJson(snapshot: EnvironmentSnapshot, meta: { exportedAt: Date; source: string }): string {
+  const doc = { exportedAt: meta.exportedAt.toISOString(), source: meta.source, ...snapshot };
+  return JSON.stringify(doc, null, 2) + '\n';
+}
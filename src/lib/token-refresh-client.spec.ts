@@ -0,0 +1,44 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+vi.mock('./credentials.js', () => ({
+  getCredentials: () => ({ accessToken: 'access', refreshToken: 'refresh_123', expiresAt: 0, userId: 'user_123' }),
+}));
+
+const { refreshAccessToken } = await import('./token-refresh-client.js');
+const { proxyFetchOptions, setProxyOverride } = await import('./proxy.js');
+
+describe('refreshAccessToken', () => {
+  let mockFetch: ReturnType<typeof vi.fn>;
+
+  beforeEach(() => {
+    mockFetch = vi.spyOn(globalThis, 'fetch') as unknown as ReturnType<typeof vi.fn>;
+    mockFetch.mockResolvedValue(Response.json({ access_token: 'new_access', token_type: 'Bearer', expires_in: 300 }));
+    vi.stubEnv('HTTPS_PROXY', '');
+    vi.stubEnv('HTTP_PROXY', '');
+  });
+
+  afterEach(() => {
+    vi.restoreAllMocks();
+    vi.unstubAllEnvs();
+    setProxyOverride(undefined);
+  });
+
+  it('sends the refresh request through --proxy', async () => {
+    setProxyOverride('http://proxy.internal:3128');
+
+    const result = await refreshAccessToken('https://auth.example.com', 'client_cli');
+
+    expect(result.success).toBe(true);
+    const [url, init] = mockFetch.mock.calls[0] as [string, { dispatcher?: unknown }];
+    expect(url).toBe('https://auth.example.com/oauth2/token');
+    expect(init.dispatcher).toBeDefined();
+    expect(init.dispatcher).toBe(proxyFetchOptions(url).dispatcher);
+  });
+
+  it('connects directly without a proxy', async () => {
+    await refreshAccessToken('https://auth.example.com', 'client_cli');
+
+    const [, init] = mockFetch.mock.calls[0] as [string, { dispatcher?: unknown }];
+    expect(init.dispatcher).toBeUndefined();
+  });
+});
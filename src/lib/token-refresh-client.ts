@@ -4,6 +4,7 @@
 
 import { logInfo, logError } from '../utils/debug.js';
 import { getCredentials } from './credentials.js';
+import { proxiedFetch } from './proxy.js';
 
 export interface RefreshResult {
   success: boolean;
@@ -53,7 +54,7 @@ export async function refreshAccessToken(authkitDomain: string, clientId: string
     const controller = new AbortController();
     const timeout = setTimeout(() => controller.abort(), REFRESH_TIMEOUT_MS);
 
-    const response = await fetch(tokenUrl, {
+    const response = await proxiedFetch(tokenUrl, {
       method: 'POST',
       headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
       body: new URLSearchParams({
@@ -115,6 +116,49 @@ export async function refreshAccessToken(authkitDomain: string, clientId: string
   }
 }
 
+/**
+ * Revoke a refresh token at the AuthKit revocation endpoint (RFC 7009), so
+ * a copy of it can't be used after logout. The endpoint answers 200 for
+ * tokens that are already invalid.
+ *
+ * @returns null on success, otherwise why the revocation failed
+ */
+export async function revokeRefreshToken(
+  authkitDomain: string,
+  clientId: string,
+  refreshToken: string,
+): Promise<string | null> {
+  const controller = new AbortController();
+  const timeout = setTimeout(() => controller.abort(), REFRESH_TIMEOUT_MS);
+
+  try {
+    const response = await proxiedFetch(`${authkitDomain}/oauth2/revoke`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/x-www-form-urlencoded' },
+      body: new URLSearchParams({
+        token: refreshToken,
+        token_type_hint: 'refresh_token',
+        client_id: clientId,
+      }),
+      signal: controller.signal,
+    });
+
+    if (response.ok) {
+      logInfo('[token-refresh] Refresh token revoked');
+      return null;
+    }
+    const errorData = (await response.json().catch(() => ({}))) as Partial<TokenErrorResponse>;
+    logError('[token-refresh] Revocation failed:', response.status, errorData.error);
+    return errorData.error_description || errorData.error || `HTTP ${response.status}`;
+  } catch (error) {
+    if (error instanceof Error && error.name === 'AbortError') return 'Revocation timed out';
+    logError('[token-refresh] Revocation network error:', error);
+    return `Network error: ${(error as Error).message}`;
+  } finally {
+    clearTimeout(timeout);
+  }
+}
+
 /**
  * Check if token needs refresh (expires within threshold).
  */
@@ -1,5 +1,5 @@
 import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
-import { existsSync, unlinkSync, mkdtempSync, rmdirSync } from 'node:fs';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
 import { join } from 'node:path';
 import { tmpdir } from 'node:os';
 import type { Credentials } from './credentials.js';
@@ -7,7 +7,6 @@ import type { Credentials } from './credentials.js';
 // Create a mock home directory for all tests
 let testDir: string;
 let installerDir: string;
-let credentialsFile: string;
 
 // Mock os.homedir BEFORE importing credentials module
 vi.mock('node:os', async (importOriginal) => {
@@ -27,33 +26,30 @@ vi.mock('../utils/debug.js', () => ({
   debug: vi.fn(),
   logInfo: vi.fn(),
   logWarn: vi.fn(),
+  logError: vi.fn(),
+}));
+
+vi.mock('./settings.js', () => ({
+  getCliAuthClientId: () => 'client_test',
+  getAuthkitDomain: () => 'https://auth.test.com',
 }));
 
 // Import after mocks are set up
-const { saveCredentials, clearCredentials, setInsecureStorage } = await import('./credentials.js');
-const { ensureValidToken } = await import('./token-refresh.js');
+const { saveCredentials, getCredentials, setInsecureStorage } = await import('./credentials.js');
+const { ensureValidToken, refreshSession, withSessionToken } = await import('./token-refresh.js');
 
 describe('token-refresh', () => {
   beforeEach(() => {
     testDir = mkdtempSync(join(tmpdir(), 'token-refresh-test-'));
     installerDir = join(testDir, '.workos');
-    credentialsFile = join(installerDir, 'credentials.json');
     vi.clearAllMocks();
     // Force file-based storage for these tests
     setInsecureStorage(true);
   });
 
   afterEach(() => {
-    // Clean up
-    if (existsSync(credentialsFile)) {
-      unlinkSync(credentialsFile);
-    }
-    if (existsSync(installerDir)) {
-      rmdirSync(installerDir);
-    }
-    if (existsSync(testDir)) {
-      rmdirSync(testDir);
-    }
+    vi.unstubAllGlobals();
+    rmSync(testDir, { recursive: true, force: true });
   });
 
   const validCreds: Credentials = {
@@ -105,4 +101,148 @@ describe('token-refresh', () => {
       expect(result.credentials?.email).toBe('test@example.com');
     });
   });
+
+  const refreshableCreds: Credentials = {
+    ...expiredCreds,
+    refreshToken: 'refresh_1',
+  };
+
+  function tokenResponse(accessToken: string, refreshToken: string) {
+    return new Response(JSON.stringify({ access_token: accessToken, refresh_token: refreshToken, expires_in: 3600 }), {
+      status: 200,
+    });
+  }
+
+  describe('refresh', () => {
+    it('refreshes an expired token and persists the rotated tokens', async () => {
+      saveCredentials(refreshableCreds);
+      const fetchMock = vi.fn(async () => tokenResponse('access_2', 'refresh_2'));
+      vi.stubGlobal('fetch', fetchMock);
+
+      const result = await ensureValidToken();
+
+      expect(result.success).toBe(true);
+      expect(result.credentials?.accessToken).toBe('access_2');
+      expect(getCredentials()).toMatchObject({ accessToken: 'access_2', refreshToken: 'refresh_2' });
+      expect(fetchMock).toHaveBeenCalledWith('https://auth.test.com/oauth2/token', expect.anything());
+    });
+
+    it('refreshes a token that is about to expire', async () => {
+      saveCredentials({ ...refreshableCreds, expiresAt: Date.now() + 30_000 });
+      vi.stubGlobal('fetch', vi.fn(async () => tokenResponse('access_2', 'refresh_2')));
+
+      const result = await ensureValidToken();
+
+      expect(result.credentials?.accessToken).toBe('access_2');
+    });
+
+    it('shares one refresh between concurrent callers', async () => {
+      saveCredentials(refreshableCreds);
+      let resolveFetch: (response: Response) => void = () => {};
+      const fetchMock = vi.fn(() => new Promise<Response>((resolve) => (resolveFetch = resolve)));
+      vi.stubGlobal('fetch', fetchMock);
+
+      const calls = Promise.all([
+        ensureValidToken(),
+        ensureValidToken(),
+        withSessionToken(async (token) => token),
+        refreshSession(refreshableCreds.accessToken),
+      ]);
+      await vi.waitFor(() => expect(fetchMock).toHaveBeenCalled());
+      resolveFetch(tokenResponse('access_2', 'refresh_2'));
+      const [first, second, token, refreshed] = await calls;
+
+      expect(fetchMock).toHaveBeenCalledOnce();
+      expect(first.credentials?.accessToken).toBe('access_2');
+      expect(second.credentials?.accessToken).toBe('access_2');
+      expect(token).toBe('access_2');
+      expect(refreshed.accessToken).toBe('access_2');
+    });
+
+    it('waits for another process holding the lock and reuses its tokens', async () => {
+      saveCredentials(refreshableCreds);
+      const fetchMock = vi.fn(async () => tokenResponse('access_3', 'refresh_3'));
+      vi.stubGlobal('fetch', fetchMock);
+      const lock = join(installerDir, 'refresh.lock');
+      writeFileSync(lock, '');
+
+      const pending = refreshSession(refreshableCreds.accessToken);
+      // The other process finishes its refresh and releases the lock
+      saveCredentials({ ...refreshableCreds, accessToken: 'access_2', expiresAt: Date.now() + 3600_000 });
+      rmSync(lock);
+      const result = await pending;
+
+      expect(fetchMock).not.toHaveBeenCalled();
+      expect(result).toMatchObject({ success: true, accessToken: 'access_2' });
+    });
+
+    it('takes over a lock left behind by a crashed process', async () => {
+      saveCredentials(refreshableCreds);
+      vi.stubGlobal('fetch', vi.fn(async () => tokenResponse('access_2', 'refresh_2')));
+      const lock = join(installerDir, 'refresh.lock');
+      writeFileSync(lock, '');
+      const { utimesSync } = await import('node:fs');
+      const old = new Date(Date.now() - 5 * 60 * 1000);
+      utimesSync(lock, old, old);
+
+      const result = await refreshSession(refreshableCreds.accessToken);
+
+      expect(result.accessToken).toBe('access_2');
+    });
+
+    it('reports an expired session when the refresh token is revoked', async () => {
+      saveCredentials(refreshableCreds);
+      vi.stubGlobal(
+        'fetch',
+        vi.fn(async () => new Response(JSON.stringify({ error: 'invalid_grant' }), { status: 400 })),
+      );
+
+      const result = await ensureValidToken();
+
+      expect(result.success).toBe(false);
+      expect(result.error).toBe('Session expired. Run `workos login` to re-authenticate.');
+    });
+  });
+
+  describe('withSessionToken', () => {
+    it('refreshes and retries once when the token is rejected', async () => {
+      saveCredentials({ ...validCreds, refreshToken: 'refresh_1' });
+      vi.stubGlobal('fetch', vi.fn(async () => tokenResponse('access_2', 'refresh_2')));
+      const call = vi.fn(async (token: string) => {
+        if (token === validCreds.accessToken) throw Object.assign(new Error('Unauthorized'), { statusCode: 401 });
+        return 'ok';
+      });
+
+      await expect(withSessionToken(call)).resolves.toBe('ok');
+      expect(call).toHaveBeenCalledTimes(2);
+      expect(call).toHaveBeenLastCalledWith('access_2');
+    });
+
+    it('replaces a 401 with a login prompt when the refresh token is revoked', async () => {
+      saveCredentials({ ...validCreds, refreshToken: 'refresh_1' });
+      vi.stubGlobal(
+        'fetch',
+        vi.fn(async () => new Response(JSON.stringify({ error: 'invalid_grant' }), { status: 400 })),
+      );
+      const call = vi.fn(async () => {
+        throw Object.assign(new Error('Authentication expired. Please log in again.'), { statusCode: 401 });
+      });
+
+      await expect(withSessionToken(call)).rejects.toThrow('Run `workos login`');
+      expect(call).toHaveBeenCalledOnce();
+    });
+
+    it('does not refresh on other errors', async () => {
+      saveCredentials({ ...validCreds, refreshToken: 'refresh_1' });
+      const fetchMock = vi.fn();
+      vi.stubGlobal('fetch', fetchMock);
+
+      await expect(
+        withSessionToken(async () => {
+          throw Object.assign(new Error('Forbidden'), { statusCode: 403 });
+        }),
+      ).rejects.toThrow('Forbidden');
+      expect(fetchMock).not.toHaveBeenCalled();
+    });
+  });
 });
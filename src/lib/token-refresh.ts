@@ -1,5 +1,12 @@
-import { getCredentials, isTokenExpired, Credentials } from './credentials.js';
-import { logInfo } from '../utils/debug.js';
+import { closeSync, mkdirSync, openSync, rmSync, statSync } from 'node:fs';
+import os from 'node:os';
+import path from 'node:path';
+import { getCredentials, isTokenExpired, updateTokens, Credentials } from './credentials.js';
+import { refreshAccessToken, type RefreshResult } from './token-refresh-client.js';
+import { getAuthkitDomain, getCliAuthClientId } from './settings.js';
+import { logInfo, logWarn } from '../utils/debug.js';
+
+export const SESSION_EXPIRED_MESSAGE = 'Session expired. Run `workos login` to re-authenticate.';
 
 export interface TokenValidationResult {
   success: boolean;
@@ -7,10 +14,100 @@ export interface TokenValidationResult {
   error?: string;
 }
 
+/** Refresh this long before the access token expires */
+const REFRESH_THRESHOLD_MS = 2 * 60 * 1000;
+/** A lock older than this was left by a crashed process (refreshes time out after 30s) */
+const LOCK_STALE_MS = 60_000;
+const LOCK_POLL_MS = 100;
+
+let inflight: Promise<RefreshResult> | null = null;
+
+/** Whether the access token has expired or will within the refresh window */
+export function tokenExpiresSoon(creds: Credentials): boolean {
+  return Date.now() + REFRESH_THRESHOLD_MS >= creds.expiresAt;
+}
+
+function lockPath(): string {
+  return path.join(os.homedir(), '.workos', 'refresh.lock');
+}
+
+/**
+ * Take the cross-process refresh lock. If the lock can't be created at all
+ * (read-only home), refresh without it rather than fail.
+ */
+async function acquireLock(): Promise<() => void> {
+  const file = lockPath();
+  for (;;) {
+    try {
+      mkdirSync(path.dirname(file), { recursive: true, mode: 0o700 });
+      closeSync(openSync(file, 'wx'));
+      return () => rmSync(file, { force: true });
+    } catch (error) {
+      if ((error as NodeJS.ErrnoException).code !== 'EEXIST') {
+        logWarn('[token-refresh] Could not take refresh lock:', error);
+        return () => {};
+      }
+    }
+
+    try {
+      if (Date.now() - statSync(file).mtimeMs > LOCK_STALE_MS) {
+        logWarn('[token-refresh] Removing stale refresh lock');
+        rmSync(file, { force: true });
+        continue;
+      }
+    } catch {
+      // Released between the open and the stat; try again right away
+      continue;
+    }
+    await new Promise((resolve) => setTimeout(resolve, LOCK_POLL_MS));
+  }
+}
+
+async function lockedRefresh(staleToken?: string): Promise<RefreshResult> {
+  const release = await acquireLock();
+  try {
+    // Another process may have refreshed while we waited for the lock
+    const latest = getCredentials();
+    if (latest && staleToken && latest.accessToken !== staleToken && !tokenExpiresSoon(latest)) {
+      logInfo('[token-refresh] Session already refreshed by another process');
+      return {
+        success: true,
+        accessToken: latest.accessToken,
+        refreshToken: latest.refreshToken,
+        expiresAt: latest.expiresAt,
+      };
+    }
+
+    const result = await refreshAccessToken(getAuthkitDomain(), getCliAuthClientId());
+    if (result.success && result.accessToken && result.expiresAt) {
+      updateTokens(result.accessToken, result.expiresAt, result.refreshToken);
+    }
+    return result;
+  } finally {
+    release();
+  }
+}
+
 /**
- * Check if the current token is valid.
- * If expired, returns an error prompting re-authentication.
- * No refresh is attempted - refresh tokens are not stored for security.
+ * Refresh the session and persist the new tokens.
+ *
+ * Refresh tokens rotate, so two refreshes with the same token would log the
+ * user out. Concurrent callers in this process share one request, and a lock
+ * file next to the credentials serializes refreshes across processes.
+ *
+ * @param staleToken - Access token the caller found expired or rejected. If
+ *   another refresh has already replaced it, those tokens are returned as is.
+ */
+export function refreshSession(staleToken?: string): Promise<RefreshResult> {
+  inflight ??= lockedRefresh(staleToken).finally(() => {
+    inflight = null;
+  });
+  return inflight;
+}
+
+/**
+ * Check if the current token is valid, refreshing it when it has expired or
+ * is about to. A revoked refresh token means the user has to log in again.
  */
 export async function ensureValidToken(): Promise<TokenValidationResult> {
   const creds = getCredentials();
@@ -23,14 +120,51 @@ export async function ensureValidToken(): Promise<TokenValidationResult> {
   logInfo(`[ensureValidToken] Token expiresAt: ${new Date(creds.expiresAt).toISOString()}`);
   logInfo(`[ensureValidToken] Current time: ${new Date().toISOString()}`);
 
-  if (isTokenExpired(creds)) {
+  if (!tokenExpiresSoon(creds)) {
+    logInfo('[ensureValidToken] Token valid');
+    return { success: true, credentials: creds };
+  }
+
+  if (!creds.refreshToken) {
+    if (!isTokenExpired(creds)) return { success: true, credentials: creds };
     logInfo('[ensureValidToken] Token expired, re-authentication required');
-    return {
-      success: false,
-      error: 'Session expired. Run `workos login` to re-authenticate.',
-    };
+    return { success: false, error: SESSION_EXPIRED_MESSAGE };
   }
 
-  logInfo('[ensureValidToken] Token valid');
-  return { success: true, credentials: creds };
+  const result = await refreshSession(creds.accessToken);
+  if (result.success) {
+    return { success: true, credentials: getCredentials() ?? creds };
+  }
+  if (result.errorType === 'invalid_grant') {
+    return { success: false, error: SESSION_EXPIRED_MESSAGE };
+  }
+  // The current token still works; the next call tries the refresh again
+  if (!isTokenExpired(creds)) return { success: true, credentials: creds };
+  return { success: false, error: result.error };
+}
+
+function isUnauthorized(error: unknown): boolean {
+  return (error as { statusCode?: number } | null)?.statusCode === 401;
+}
+
+/**
+ * Call an API with the session's access token. An error with a 401
+ * `statusCode` refreshes the session and retries once.
+ */
+export async function withSessionToken<T>(fn: (accessToken: string) => Promise<T>): Promise<T> {
+  const token = await ensureValidToken();
+  if (!token.success || !token.credentials) throw new Error(token.error ?? 'Not authenticated');
+
+  try {
+    return await fn(token.credentials.accessToken);
+  } catch (error) {
+    if (!isUnauthorized(error) || !token.credentials.refreshToken) throw error;
+
+    logInfo('[token-refresh] Access token rejected, refreshing session');
+    const result = await refreshSession(token.credentials.accessToken);
+    if (!result.success || !result.accessToken) {
+      throw new Error(result.errorType === 'invalid_grant' ? SESSION_EXPIRED_MESSAGE : result.error);
+    }
+    return fn(result.accessToken);
+  }
 }
@@ -0,0 +1,46 @@
+import { describe, it, expect } from 'vitest';
+import { base32Decode, totpCode, totpSecondsRemaining } from './totp.js';
+
+// RFC 6238 appendix B: the SHA-1 secret "12345678901234567890", base32-encoded
+const SECRET = 'GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ';
+
+describe('base32Decode', () => {
+  it('decodes RFC 4648 base32', () => {
+    expect(base32Decode(SECRET).toString()).toBe('12345678901234567890');
+  });
+
+  it('ignores case, spaces and padding', () => {
+    expect(base32Decode('mzxw 6===').toString()).toBe('foo');
+  });
+
+  it('rejects characters outside the alphabet', () => {
+    expect(() => base32Decode('ABC1')).toThrow('Invalid base32 character "1"');
+  });
+});
+
+describe('totpCode', () => {
+  it.each([
+    [59, '94287082'],
+    [1111111109, '07081804'],
+    [1234567890, '89005924'],
+    [2000000000, '69279037'],
+  ])('matches the RFC 6238 vector at T=%i', (seconds, code) => {
+    expect(totpCode(SECRET, seconds * 1000, { digits: 8 })).toBe(code);
+  });
+
+  it('defaults to 6 digits', () => {
+    expect(totpCode(SECRET, 59_000)).toBe('287082');
+  });
+
+  it('keeps the same code within a 30 second step', () => {
+    expect(totpCode(SECRET, 30_000)).toBe(totpCode(SECRET, 59_999));
+    expect(totpCode(SECRET, 60_000)).not.toBe(totpCode(SECRET, 59_999));
+  });
+});
+
+describe('totpSecondsRemaining', () => {
+  it('counts down to the next step', () => {
+    expect(totpSecondsRemaining(0)).toBe(30);
+    expect(totpSecondsRemaining(59_000)).toBe(1);
+  });
+});
@@ -0,0 +1,52 @@
+/**
+ * TOTP codes (RFC 6238) from an enrolled factor's secret, so integration
+ * tests can complete MFA challenges for test users without an authenticator
+ * app. Matches what WorkOS enrolls: HMAC-SHA1, 30 second steps, 6 digits.
+ */
+
+import { createHmac } from 'node:crypto';
+
+const BASE32_ALPHABET = 'ABCDEFGHIJKLMNOPQRSTUVWXYZ234567';
+
+export interface TotpOptions {
+  /** Seconds per code */
+  period?: number;
+  digits?: number;
+}
+
+/** Decode an RFC 4648 base32 secret; case, spaces and padding are ignored */
+export function base32Decode(secret: string): Buffer {
+  const clean = secret.replace(/[\s=-]/g, '').toUpperCase();
+  const bytes: number[] = [];
+  let buffer = 0;
+  let bits = 0;
+  for (const char of clean) {
+    const value = BASE32_ALPHABET.indexOf(char);
+    if (value === -1) throw new Error(`Invalid base32 character "${char}" in TOTP secret`);
+    buffer = (buffer << 5) | value;
+    bits += 5;
+    if (bits >= 8) {
+      bits -= 8;
+      bytes.push((buffer >>> bits) & 0xff);
+    }
+  }
+  return Buffer.from(bytes);
+}
+
+/** The code for `now` (ms since the epoch) */
+export function totpCode(secret: string, now = Date.now(), options: TotpOptions = {}): string {
+  const period = options.period ?? 30;
+  const digits = options.digits ?? 6;
+  const counter = Buffer.alloc(8);
+  counter.writeBigUInt64BE(BigInt(Math.floor(now / 1000 / period)));
+
+  const hmac = createHmac('sha1', base32Decode(secret)).update(counter).digest();
+  const offset = hmac[hmac.length - 1] & 0x0f;
+  const binary = hmac.readUInt32BE(offset) & 0x7fffffff;
+  return String(binary % 10 ** digits).padStart(digits, '0');
+}
+
+/** Seconds until the code for `now` stops being valid */
+export function totpSecondsRemaining(now = Date.now(), period = 30): number {
+  return period - (Math.floor(now / 1000) % period);
+}
@@ -3,6 +3,8 @@ import { existsSync, readdirSync } from 'fs';
 import { readFile } from 'fs/promises';
 import { join } from 'path';
 import type { ValidationIssue } from './types.js';
+import { findWorkspaceRoot } from '../workspaces.js';
+import { spawnTarget } from '../../utils/windows.js';
 
 export interface BuildResult {
   success: boolean;
@@ -29,9 +31,11 @@ export async function runBuildValidation(projectDir: string, timeoutMs: number =
 
   return new Promise((resolve) => {
     const args = pm === 'npm' ? ['run', 'build'] : ['build'];
-    const proc = spawn(pm, args, {
+    const target = spawnTarget(pm, args);
+    const proc = spawn(target.command, target.args, {
       cwd: projectDir,
       timeout: timeoutMs,
+      windowsVerbatimArguments: target.windowsVerbatimArguments,
     });
 
     let stdout = '';
@@ -99,8 +103,10 @@ export async function runBuildValidation(projectDir: string, timeoutMs: number =
 }
 
 export function detectPackageManager(projectDir: string): 'pnpm' | 'yarn' | 'npm' {
-  if (existsSync(join(projectDir, 'pnpm-lock.yaml'))) return 'pnpm';
-  if (existsSync(join(projectDir, 'yarn.lock'))) return 'yarn';
+  // Monorepo packages share the workspace root's lockfile
+  const dirs = [projectDir, findWorkspaceRoot(projectDir)].filter((dir): dir is string => dir !== null);
+  if (dirs.some((dir) => existsSync(join(dir, 'pnpm-lock.yaml')))) return 'pnpm';
+  if (dirs.some((dir) => existsSync(join(dir, 'yarn.lock')))) return 'yarn';
   return 'npm';
 }
 
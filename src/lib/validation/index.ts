@@ -7,7 +7,30 @@ export {
   type ValidateOptions,
 } from './validator.js';
 export { runBuildValidation, type BuildResult } from './build-validator.js';
-export { runQuickChecks, runTypecheckValidation, quickCheckValidateAndFormat } from './quick-checks.js';
+export {
+  runQuickChecks,
+  runTypecheckValidation,
+  quickCheckValidateAndFormat,
+  projectCheckValidateAndFormat,
+} from './quick-checks.js';
+export {
+  detectProjectChecks,
+  formatTouchedFiles,
+  runLintCheck,
+  trackTouchedFiles,
+  type FormatResult,
+  type ProjectChecks,
+  type TouchedFiles,
+} from './project-checks.js';
+export {
+  loadVerificationSpec,
+  runVerificationChecks,
+  VERIFY_FILE,
+  type VerificationCheck,
+  type VerificationCheckResult,
+  type VerificationReport,
+  type VerificationSpec,
+} from './verification.js';
 export type {
   ValidationResult,
   ValidationRules,
@@ -0,0 +1,208 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdtempSync, writeFileSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { EventEmitter } from 'node:events';
+
+vi.mock('child_process', () => ({
+  spawn: vi.fn(),
+}));
+
+import { spawn } from 'child_process';
+import { detectProjectChecks, formatTouchedFiles, runLintCheck, trackTouchedFiles } from './project-checks.js';
+import { createInstallerEventEmitter } from '../events.js';
+
+const mockSpawn = vi.mocked(spawn);
+
+/** Mock process that runs `effect` before closing, like a formatter writing files */
+function createMockProcess(exitCode: number, output = '', effect?: () => void) {
+  const proc = new EventEmitter() as any;
+  proc.stdout = new EventEmitter();
+  proc.stderr = new EventEmitter();
+
+  setTimeout(() => {
+    effect?.();
+    if (output) proc.stdout.emit('data', Buffer.from(output));
+    proc.emit('close', exitCode);
+  }, 10);
+
+  return proc;
+}
+
+/** Mock spawn of a command that isn't installed */
+function missingProcess(command: string) {
+  const proc = new EventEmitter() as any;
+  setTimeout(() => proc.emit('error', Object.assign(new Error(`spawn ${command} ENOENT`), { code: 'ENOENT' })), 10);
+  return proc;
+}
+
+describe('project checks', () => {
+  let testDir: string;
+
+  const writePackageJson = (pkg: object) => writeFileSync(join(testDir, 'package.json'), JSON.stringify(pkg));
+
+  beforeEach(() => {
+    testDir = mkdtempSync(join(tmpdir(), 'project-checks-test-'));
+    vi.clearAllMocks();
+  });
+
+  afterEach(() => {
+    rmSync(testDir, { recursive: true, force: true });
+  });
+
+  describe('detectProjectChecks', () => {
+    it('finds prettier and eslint from dependencies plus config or scripts', () => {
+      writePackageJson({
+        scripts: { lint: 'next lint', format: 'prettier --write .' },
+        devDependencies: { prettier: '^3.0.0', eslint: '^9.0.0' },
+      });
+
+      const checks = detectProjectChecks(testDir);
+
+      expect(checks.formatters.map((t) => t.name)).toEqual(['prettier']);
+      expect(checks.linters.map((t) => t.name)).toEqual(['eslint']);
+      expect(checks.linters[0].command(['app/page.tsx'])).toEqual(['npx', 'eslint', 'app/page.tsx']);
+    });
+
+    it('ignores tools that are configured but not installed', () => {
+      writePackageJson({ scripts: { lint: 'eslint .' } });
+      writeFileSync(join(testDir, '.prettierrc'), '{}');
+
+      expect(detectProjectChecks(testDir)).toEqual({ formatters: [], linters: [] });
+    });
+
+    it('uses goimports (or gofmt) for Go modules and golangci-lint when configured, scoped to packages', () => {
+      writeFileSync(join(testDir, 'go.mod'), 'module example.com/app\n');
+      writeFileSync(join(testDir, '.golangci.yml'), 'linters: {}\n');
+
+      const checks = detectProjectChecks(testDir);
+
+      expect(checks.formatters.map((t) => t.name)).toEqual(['goimports']);
+      expect(checks.formatters[0].fallback?.name).toBe('gofmt');
+      expect(checks.linters[0].command(['auth/login.go', 'auth/callback.go', 'main.go'])).toEqual([
+        'golangci-lint',
+        'run',
+        './auth',
+        '.',
+      ]);
+    });
+  });
+
+  describe('formatTouchedFiles', () => {
+    beforeEach(() => {
+      writePackageJson({ devDependencies: { prettier: '^3.0.0' }, prettier: {} });
+      writeFileSync(join(testDir, 'middleware.ts'), 'export  default 1');
+      writeFileSync(join(testDir, 'page.tsx'), 'export default 2;\n');
+      writeFileSync(join(testDir, 'README.md'), '# app\n');
+    });
+
+    it('reports only the files the formatter changed', async () => {
+      mockSpawn.mockImplementationOnce(
+        () =>
+          createMockProcess(0, '', () => writeFileSync(join(testDir, 'middleware.ts'), 'export default 1;\n')) as any,
+      );
+
+      const result = await formatTouchedFiles(testDir, [
+        join(testDir, 'middleware.ts'),
+        join(testDir, 'page.tsx'),
+        join(testDir, 'README.md'),
+        '/elsewhere/file.ts',
+      ]);
+
+      expect(mockSpawn).toHaveBeenCalledWith(
+        'npx',
+        ['prettier', '--write', '--ignore-unknown', 'middleware.ts', 'page.tsx'],
+        expect.objectContaining({ cwd: testDir }),
+      );
+      expect(result).toEqual({ files: ['middleware.ts'], tools: ['prettier'] });
+      expect(readFileSync(join(testDir, 'middleware.ts'), 'utf-8')).toBe('export default 1;\n');
+    });
+
+    it('skips a formatter that fails', async () => {
+      mockSpawn.mockImplementationOnce(() => createMockProcess(2, 'SyntaxError: Unexpected token') as any);
+
+      const result = await formatTouchedFiles(testDir, ['middleware.ts']);
+
+      expect(result).toEqual({ files: [], tools: [] });
+    });
+
+    it('falls back to gofmt without goimports, and reports a formatter that is missing', async () => {
+      writeFileSync(join(testDir, 'go.mod'), 'module example.com/app\n');
+      writeFileSync(join(testDir, 'main.go'), 'package main\nfunc main() {\n  println()\n}\n');
+      mockSpawn
+        .mockImplementationOnce(() => missingProcess('npx') as any)
+        .mockImplementationOnce(() => missingProcess('goimports') as any)
+        .mockImplementationOnce(
+          () =>
+            createMockProcess(0, '', () =>
+              writeFileSync(join(testDir, 'main.go'), 'package main\n\nfunc main() {\n\tprintln()\n}\n'),
+            ) as any,
+        );
+
+      const result = await formatTouchedFiles(testDir, ['main.go', 'middleware.ts']);
+
+      expect(mockSpawn).toHaveBeenCalledWith('gofmt', ['-w', 'main.go'], expect.anything());
+      expect(result).toEqual({ files: ['main.go'], tools: ['gofmt'], missing: ['prettier'] });
+    });
+
+    it('does nothing when the agent touched no matching files', async () => {
+      const result = await formatTouchedFiles(testDir, ['README.md']);
+
+      expect(mockSpawn).not.toHaveBeenCalled();
+      expect(result.files).toEqual([]);
+    });
+  });
+
+  describe('runLintCheck', () => {
+    beforeEach(() => {
+      writePackageJson({ devDependencies: { eslint: '^9.0.0' } });
+      writeFileSync(join(testDir, 'eslint.config.mjs'), 'export default [];\n');
+      writeFileSync(join(testDir, 'middleware.ts'), 'import { unused } from "x";\n');
+    });
+
+    it('puts lint output for touched files in the agent prompt', async () => {
+      mockSpawn.mockImplementationOnce(
+        () =>
+          createMockProcess(
+            1,
+            "middleware.ts\n  1:10  error  'unused' is defined but never used  @typescript-eslint/no-unused-vars",
+          ) as any,
+      );
+
+      const result = await runLintCheck(testDir, ['middleware.ts']);
+
+      expect(result.passed).toBe(false);
+      expect(result.phase).toBe('lint');
+      expect(result.agentPrompt).toContain("'unused' is defined but never used");
+      expect(result.issues[0].message).toContain('eslint');
+    });
+
+    it('passes when the linter is not installed', async () => {
+      mockSpawn.mockImplementationOnce(() => {
+        const proc = new EventEmitter() as any;
+        setTimeout(() => proc.emit('error', Object.assign(new Error('spawn npx ENOENT'), { code: 'ENOENT' })), 10);
+        return proc;
+      });
+
+      const result = await runLintCheck(testDir, ['middleware.ts']);
+
+      expect(result.passed).toBe(true);
+      expect(result.agentPrompt).toBeNull();
+    });
+  });
+
+  describe('trackTouchedFiles', () => {
+    it('collects written and edited paths until stopped', () => {
+      const emitter = createInstallerEventEmitter();
+      const touched = trackTouchedFiles(emitter);
+
+      emitter.emit('file:write', { path: '/app/middleware.ts', content: '' });
+      emitter.emit('file:edit', { path: '/app/page.tsx', oldContent: 'a', newContent: 'b' });
+      emitter.emit('file:edit', { path: '/app/page.tsx', oldContent: 'b', newContent: 'c' });
+      touched.stop();
+      emitter.emit('file:write', { path: '/app/late.ts', content: '' });
+
+      expect(touched.files()).toEqual(['/app/middleware.ts', '/app/page.tsx']);
+    });
+  });
+});
@@ -0,0 +1,281 @@
+import { spawn } from 'child_process';
+import { createHash } from 'crypto';
+import { existsSync, readFileSync } from 'fs';
+import { dirname, isAbsolute, join, relative } from 'path';
+import type { InstallerEventEmitter } from '../events.js';
+import type { QuickCheckResult, ValidationIssue } from './types.js';
+import { spawnTarget } from '../../utils/windows.js';
+
+const TOOL_TIMEOUT_MS = 60_000;
+const OUTPUT_LIMIT = 3000;
+
+const JS_SOURCE = /\.(m|c)?(j|t)sx?$|\.(vue|svelte|astro)$/;
+const GO_SOURCE = /\.go$/;
+
+const PRETTIER_CONFIGS = [
+  '.prettierrc',
+  '.prettierrc.json',
+  '.prettierrc.yaml',
+  '.prettierrc.yml',
+  '.prettierrc.js',
+  '.prettierrc.cjs',
+  '.prettierrc.mjs',
+  'prettier.config.js',
+  'prettier.config.cjs',
+  'prettier.config.mjs',
+];
+const ESLINT_CONFIGS = [
+  'eslint.config.js',
+  'eslint.config.mjs',
+  'eslint.config.cjs',
+  'eslint.config.ts',
+  '.eslintrc',
+  '.eslintrc.js',
+  '.eslintrc.cjs',
+  '.eslintrc.json',
+  '.eslintrc.yaml',
+  '.eslintrc.yml',
+];
+const GOLANGCI_CONFIGS = ['.golangci.yml', '.golangci.yaml', '.golangci.toml', '.golangci.json'];
+
+/** A formatter or linter, run on a subset of the agent's files */
+export interface ProjectTool {
+  name: string;
+  /** Which touched files the tool is given */
+  matches: RegExp;
+  /** Full command line for the given project-relative files */
+  command: (files: string[]) => string[];
+  /** Tried instead when this tool isn't installed */
+  fallback?: ProjectTool;
+}
+
+export interface ProjectChecks {
+  /** Rewrite files in place; never fail the run */
+  formatters: ProjectTool[];
+  /** Report problems for the repair prompt */
+  linters: ProjectTool[];
+}
+
+/** `./dir` for a file's package directory, `.` at the module root */
+function goPackage(file: string): string {
+  const dir = dirname(file);
+  return dir === '.' ? '.' : `./${dir}`;
+}
+
+interface PackageJson {
+  scripts?: Record<string, string>;
+  dependencies?: Record<string, string>;
+  devDependencies?: Record<string, string>;
+  prettier?: unknown;
+}
+
+function readPackageJson(projectDir: string): PackageJson | null {
+  try {
+    return JSON.parse(readFileSync(join(projectDir, 'package.json'), 'utf-8')) as PackageJson;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Work out which formatters and linters the project's own CI would run,
+ * from package.json scripts and dependencies and tool config files. A tool
+ * only counts when it's installed, so npx never has to download anything.
+ */
+export function detectProjectChecks(projectDir: string): ProjectChecks {
+  const checks: ProjectChecks = { formatters: [], linters: [] };
+  const has = (files: string[]) => files.some((f) => existsSync(join(projectDir, f)));
+
+  const pkg = readPackageJson(projectDir);
+  if (pkg) {
+    const deps = { ...pkg.dependencies, ...pkg.devDependencies };
+    const scripts = Object.values(pkg.scripts ?? {}).join('\n');
+
+    if (deps.prettier && (has(PRETTIER_CONFIGS) || pkg.prettier || /\bprettier\b/.test(scripts))) {
+      checks.formatters.push({
+        name: 'prettier',
+        matches: JS_SOURCE,
+        command: (files) => ['npx', 'prettier', '--write', '--ignore-unknown', ...files],
+      });
+    }
+    if (deps.eslint && (has(ESLINT_CONFIGS) || /\b(eslint|next lint)\b/.test(scripts))) {
+      checks.linters.push({
+        name: 'eslint',
+        matches: JS_SOURCE,
+        command: (files) => ['npx', 'eslint', ...files],
+      });
+    }
+  }
+
+  if (existsSync(join(projectDir, 'go.mod'))) {
+    // goimports is gofmt plus import grouping; plain gofmt ships with Go
+    checks.formatters.push({
+      name: 'goimports',
+      matches: GO_SOURCE,
+      command: (files) => ['goimports', '-w', ...files],
+      fallback: { name: 'gofmt', matches: GO_SOURCE, command: (files) => ['gofmt', '-w', ...files] },
+    });
+    if (has(GOLANGCI_CONFIGS)) {
+      checks.linters.push({
+        name: 'golangci-lint',
+        matches: GO_SOURCE,
+        // Go lints whole packages, so scope to the touched files' packages
+        command: (files) => ['golangci-lint', 'run', ...new Set(files.map(goPackage))],
+      });
+    }
+  }
+
+  return checks;
+}
+
+function runTool(command: string[], cwd: string): Promise<{ exitCode: number; output: string; missing: boolean }> {
+  return new Promise((resolve) => {
+    const target = spawnTarget(command[0], command.slice(1));
+    const proc = spawn(target.command, target.args, {
+      cwd,
+      timeout: TOOL_TIMEOUT_MS,
+      windowsVerbatimArguments: target.windowsVerbatimArguments,
+    });
+    let output = '';
+    proc.stdout?.on('data', (data: Buffer) => (output += data.toString()));
+    proc.stderr?.on('data', (data: Buffer) => (output += data.toString()));
+    proc.on('close', (code) => resolve({ exitCode: code ?? 1, output, missing: false }));
+    proc.on('error', (error: NodeJS.ErrnoException) =>
+      resolve({ exitCode: 1, output: error.message, missing: error.code === 'ENOENT' }),
+    );
+  });
+}
+
+function hashFile(path: string): string | null {
+  try {
+    return createHash('sha256').update(readFileSync(path)).digest('hex');
+  } catch {
+    return null;
+  }
+}
+
+/** Files the agent wrote, relative to the project and still on disk */
+function scopeFiles(projectDir: string, files: Iterable<string>): string[] {
+  const scoped = new Set<string>();
+  for (const file of files) {
+    const rel = isAbsolute(file) ? relative(projectDir, file) : file;
+    if (rel.startsWith('..') || isAbsolute(rel) || !existsSync(join(projectDir, rel))) continue;
+    scoped.add(rel);
+  }
+  return [...scoped].sort();
+}
+
+export interface FormatResult {
+  /** Project-relative files whose content the formatters changed */
+  files: string[];
+  tools: string[];
+  /** Formatters that had files to format but aren't installed */
+  missing?: string[];
+}
+
+/**
+ * Run the project's formatters on the files the agent touched. Formatting
+ * is applied directly, not sent back to the agent; a formatter that isn't
+ * installed (after its fallback) or fails (e.g. on a syntax error) is
+ * skipped.
+ */
+export async function formatTouchedFiles(
+  projectDir: string,
+  files: Iterable<string>,
+  checks: ProjectChecks = detectProjectChecks(projectDir),
+): Promise<FormatResult> {
+  const scoped = scopeFiles(projectDir, files);
+  const changed = new Set<string>();
+  const tools: string[] = [];
+  const missing: string[] = [];
+
+  for (const formatter of checks.formatters) {
+    const targets = scoped.filter((f) => formatter.matches.test(f));
+    if (targets.length === 0) continue;
+
+    const before = new Map(targets.map((f) => [f, hashFile(join(projectDir, f))]));
+    let tool = formatter;
+    let result = await runTool(tool.command(targets), projectDir);
+    while (result.missing && tool.fallback) {
+      tool = tool.fallback;
+      result = await runTool(tool.command(targets), projectDir);
+    }
+    if (result.missing) missing.push(tool.name);
+    if (result.exitCode !== 0) continue;
+
+    const reformatted = targets.filter((f) => hashFile(join(projectDir, f)) !== before.get(f));
+    reformatted.forEach((f) => changed.add(f));
+    if (reformatted.length > 0) tools.push(tool.name);
+  }
+
+  return { files: [...changed].sort(), tools, ...(missing.length > 0 && { missing }) };
+}
+
+/**
+ * Lint only the files the agent touched, so existing problems elsewhere in
+ * the project don't reach the repair prompt. Missing linters pass.
+ */
+export async function runLintCheck(
+  projectDir: string,
+  files: Iterable<string>,
+  checks: ProjectChecks = detectProjectChecks(projectDir),
+): Promise<QuickCheckResult> {
+  const startTime = Date.now();
+  const scoped = scopeFiles(projectDir, files);
+  const failures: { name: string; output: string }[] = [];
+
+  for (const linter of checks.linters) {
+    const targets = scoped.filter((f) => linter.matches.test(f));
+    if (targets.length === 0) continue;
+
+    const { exitCode, output, missing } = await runTool(linter.command(targets), projectDir);
+    if (exitCode !== 0 && !missing) {
+      const trimmed = output.trim();
+      failures.push({
+        name: linter.name,
+        output: trimmed.length > OUTPUT_LIMIT ? `${trimmed.slice(0, OUTPUT_LIMIT)}\n...` : trimmed,
+      });
+    }
+  }
+
+  const issues: ValidationIssue[] = failures.map(({ name }) => ({
+    type: 'file',
+    severity: 'error',
+    message: `${name} reported problems in files the installer changed`,
+    hint: `Run ${name} on the changed files to see them`,
+  }));
+
+  return {
+    passed: failures.length === 0,
+    phase: 'lint',
+    issues,
+    agentPrompt:
+      failures.length === 0
+        ? null
+        : `The project's linters reported problems in files you changed:\n\n${failures
+            .map((f) => `${f.name}:\n${f.output}`)
+            .join('\n\n')}\n\nFix these lint errors (for example, remove unused imports).`,
+    durationMs: Date.now() - startTime,
+  };
+}
+
+export interface TouchedFiles {
+  /** Paths the agent has written or edited so far */
+  files(): string[];
+  stop(): void;
+}
+
+/** Collect the paths of the agent's Write/Edit calls from installer events */
+export function trackTouchedFiles(emitter: InstallerEventEmitter | undefined): TouchedFiles {
+  const paths = new Set<string>();
+  const onWrite = ({ path }: { path: string }) => paths.add(path);
+  emitter?.on('file:write', onWrite);
+  emitter?.on('file:edit', onWrite);
+  return {
+    files: () => [...paths],
+    stop: () => {
+      emitter?.off('file:write', onWrite);
+      emitter?.off('file:edit', onWrite);
+    },
+  };
+}
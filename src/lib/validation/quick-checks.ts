@@ -3,17 +3,20 @@ import { readFile } from 'fs/promises';
 import { join } from 'path';
 import type { QuickCheckResult, QuickChecksOutput, ValidationIssue } from './types.js';
 import { detectBuildCommand, detectPackageManager, parseBuildErrors } from './build-validator.js';
+import { formatTouchedFiles, runLintCheck, type FormatResult } from './project-checks.js';
+import { spawnTarget } from '../../utils/windows.js';
 
 const DEFAULT_TYPECHECK_TIMEOUT_MS = 30_000;
 const DEFAULT_BUILD_TIMEOUT_MS = 60_000;
 
 /**
- * Run fast deterministic checks: typecheck first, then build.
+ * Run fast deterministic checks: typecheck first, then lint the given
+ * files (when any), then build.
  * Short-circuits: if typecheck fails, skip build (build will fail too).
  */
 export async function runQuickChecks(
   projectDir: string,
-  options?: { skipBuild?: boolean; timeoutMs?: number },
+  options?: { skipBuild?: boolean; timeoutMs?: number; files?: string[] },
 ): Promise<QuickChecksOutput> {
   const startTime = Date.now();
   const results: QuickCheckResult[] = [];
@@ -21,6 +24,10 @@ export async function runQuickChecks(
   const typecheckResult = await runTypecheckValidation(projectDir, options?.timeoutMs ?? DEFAULT_TYPECHECK_TIMEOUT_MS);
   results.push(typecheckResult);
 
+  if (options?.files?.length) {
+    results.push(await runLintCheck(projectDir, options.files));
+  }
+
   if (typecheckResult.passed && !options?.skipBuild) {
     results.push(await runBuildQuickCheck(projectDir, options?.timeoutMs ?? DEFAULT_BUILD_TIMEOUT_MS));
   }
@@ -92,7 +99,10 @@ export async function runTypecheckValidation(
   };
 }
 
-async function runBuildQuickCheck(projectDir: string, timeoutMs: number): Promise<QuickCheckResult> {
+/**
+ * Run the project's build script, parsing errors for the agent.
+ */
+export async function runBuildQuickCheck(projectDir: string, timeoutMs: number): Promise<QuickCheckResult> {
   const startTime = Date.now();
   const buildCmd = await detectBuildCommand(projectDir);
 
@@ -214,6 +224,28 @@ export async function quickCheckValidateAndFormat(workingDirectory: string): Pro
   return result.passed ? null : result.agentRetryPrompt;
 }
 
+/**
+ * Like quickCheckValidateAndFormat, but first runs the project's formatters
+ * on the files the agent touched (unless `format` is false) and lints them
+ * along with the typecheck.
+ */
+export function projectCheckValidateAndFormat(
+  touchedFiles: () => string[],
+  onFormatted?: (result: FormatResult) => void,
+  { format = true }: { format?: boolean } = {},
+): (workingDirectory: string) => Promise<string | null> {
+  return async (workingDirectory) => {
+    const files = touchedFiles();
+    if (format) {
+      const formatted = await formatTouchedFiles(workingDirectory, files);
+      if (formatted.files.length > 0 || formatted.missing) onFormatted?.(formatted);
+    }
+
+    const result = await runQuickChecks(workingDirectory, { files });
+    return result.passed ? null : result.agentRetryPrompt;
+  };
+}
+
 function spawnCommand(
   command: string,
   args: string[],
@@ -221,9 +253,11 @@ function spawnCommand(
   timeoutMs: number,
 ): Promise<{ exitCode: number; stdout: string; stderr: string }> {
   return new Promise((resolve) => {
-    const proc = spawn(command, args, {
+    const target = spawnTarget(command, args);
+    const proc = spawn(target.command, target.args, {
       cwd,
       timeout: timeoutMs,
+      windowsVerbatimArguments: target.windowsVerbatimArguments,
     });
 
     let stdout = '';
@@ -0,0 +1,153 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, writeFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import { loadVerificationSpec, runVerificationChecks, type VerificationSpec } from './verification.js';
+
+const node = process.execPath;
+
+// Answers 302 on /login and 404 elsewhere, on the port passed as argv[1]
+const SERVER_SCRIPT = `
+require('http')
+  .createServer((req, res) => {
+    res.writeHead(req.url === '/login' ? 302 : 404, { location: '/authorize' });
+    res.end();
+  })
+  .listen(Number(process.argv[1]));
+`;
+
+describe('loadVerificationSpec', () => {
+  let skillDir: string;
+
+  beforeEach(() => {
+    skillDir = mkdtempSync(join(tmpdir(), 'verify-spec-'));
+  });
+
+  afterEach(() => {
+    rmSync(skillDir, { recursive: true, force: true });
+  });
+
+  it('returns null when the skill has no verify.json', () => {
+    expect(loadVerificationSpec(skillDir)).toBeNull();
+  });
+
+  it('fills in defaults', () => {
+    writeFileSync(
+      join(skillDir, 'verify.json'),
+      JSON.stringify({
+        checks: [{ type: 'route', path: '/login', status: 302 }],
+        devServer: { script: 'dev' },
+      }),
+    );
+
+    const spec = loadVerificationSpec(skillDir);
+
+    expect(spec?.checks[0]).toEqual({ type: 'route', method: 'GET', path: '/login', status: 302 });
+    expect(spec?.devServer?.readyTimeoutMs).toBe(60_000);
+  });
+
+  it('throws on an unknown check type', () => {
+    writeFileSync(join(skillDir, 'verify.json'), JSON.stringify({ checks: [{ type: 'lint' }] }));
+
+    expect(() => loadVerificationSpec(skillDir)).toThrow(/Invalid verify.json/);
+  });
+
+  it('throws when devServer has both script and command', () => {
+    writeFileSync(
+      join(skillDir, 'verify.json'),
+      JSON.stringify({ checks: [{ type: 'build' }], devServer: { script: 'dev', command: ['node', 'server.js'] } }),
+    );
+
+    expect(() => loadVerificationSpec(skillDir)).toThrow(/exactly one of script or command/);
+  });
+});
+
+describe('runVerificationChecks', () => {
+  let projectDir: string;
+
+  beforeEach(() => {
+    projectDir = mkdtempSync(join(tmpdir(), 'verify-run-'));
+  });
+
+  afterEach(() => {
+    rmSync(projectDir, { recursive: true, force: true });
+  });
+
+  it('passes with no repair prompt when every command succeeds', async () => {
+    const spec: VerificationSpec = {
+      checks: [{ type: 'command', name: 'exits cleanly', command: [node, '-e', 'process.exit(0)'] }],
+    };
+
+    const report = await runVerificationChecks(spec, projectDir);
+
+    expect(report.passed).toBe(true);
+    expect(report.checks).toEqual([expect.objectContaining({ name: 'exits cleanly', passed: true })]);
+    expect(report.repairPrompt).toBeNull();
+  });
+
+  it('reports failed command output in the repair prompt', async () => {
+    const spec: VerificationSpec = {
+      checks: [
+        { type: 'command', name: 'ok', command: [node, '-e', 'process.exit(0)'] },
+        {
+          type: 'command',
+          name: 'broken',
+          command: [node, '-e', 'console.error("undefined: workos"); process.exit(2)'],
+        },
+      ],
+    };
+    const seen: string[] = [];
+
+    const report = await runVerificationChecks(spec, projectDir, (check) => seen.push(check.name));
+
+    expect(report.passed).toBe(false);
+    expect(seen).toEqual(['ok', 'broken']);
+    expect(report.checks[1].output).toContain('exited with 2');
+    expect(report.repairPrompt).toContain('### broken');
+    expect(report.repairPrompt).toContain('undefined: workos');
+    expect(report.repairPrompt).not.toContain('### ok');
+  });
+
+  it('probes routes against a dev server on a free port', async () => {
+    const spec: VerificationSpec = {
+      checks: [
+        { type: 'route', method: 'GET', path: '/login', status: [302, 307] },
+        { type: 'route', method: 'GET', path: '/dashboard', status: 200 },
+      ],
+      devServer: { command: [node, '-e', SERVER_SCRIPT, '{port}'], readyTimeoutMs: 10_000 },
+    };
+
+    const report = await runVerificationChecks(spec, projectDir);
+
+    expect(report.checks.map((c) => [c.name, c.passed])).toEqual([
+      ['GET /login returns 302 or 307', true],
+      ['GET /dashboard returns 200', false],
+    ]);
+    expect(report.checks[1].output).toContain('returned 404');
+  });
+
+  it('fails route checks when the dev server exits', async () => {
+    const spec: VerificationSpec = {
+      checks: [{ type: 'route', method: 'GET', path: '/', status: 200 }],
+      devServer: {
+        command: [node, '-e', 'console.error("missing WORKOS_API_KEY"); process.exit(1)'],
+        readyTimeoutMs: 10_000,
+      },
+    };
+
+    const report = await runVerificationChecks(spec, projectDir);
+
+    expect(report.passed).toBe(false);
+    expect(report.checks[0].output).toContain('exited with code 1');
+    expect(report.checks[0].output).toContain('missing WORKOS_API_KEY');
+  });
+
+  it('skips route checks when no dev server is declared', async () => {
+    const spec: VerificationSpec = { checks: [{ type: 'route', method: 'GET', path: '/', status: 200 }] };
+
+    const report = await runVerificationChecks(spec, projectDir);
+
+    expect(report.passed).toBe(true);
+    expect(report.checks[0]).toMatchObject({ passed: true, skipped: true });
+  });
+});
@@ -0,0 +1,370 @@
+import { spawn, spawnSync, type ChildProcess } from 'child_process';
+import { existsSync, readFileSync } from 'fs';
+import { createServer } from 'net';
+import { join } from 'path';
+import { z } from 'zod';
+import { detectPackageManager } from './build-validator.js';
+import { runBuildQuickCheck, runTypecheckValidation } from './quick-checks.js';
+import { killProcessTree, spawnTarget } from '../../utils/windows.js';
+import { composeArgs, type ComposeTarget } from '../compose.js';
+
+/** Optional file next to a skill's SKILL.md declaring how to verify an install */
+export const VERIFY_FILE = 'verify.json';
+
+const DEFAULT_COMMAND_TIMEOUT_MS = 120_000;
+const OUTPUT_LIMIT = 2000;
+
+/** Starting a compose service can mean pulling or building its image first */
+const COMPOSE_READY_TIMEOUT_MS = 180_000;
+
+const checkSchema = z.discriminatedUnion('type', [
+  z.object({ type: z.literal('typecheck'), name: z.string().optional() }),
+  z.object({ type: z.literal('build'), name: z.string().optional() }),
+  z.object({
+    type: z.literal('command'),
+    name: z.string(),
+    command: z.array(z.string()).min(1),
+    timeoutMs: z.number().int().positive().optional(),
+  }),
+  z.object({
+    type: z.literal('route'),
+    name: z.string().optional(),
+    method: z.enum(['GET', 'HEAD', 'POST']).default('GET'),
+    path: z.string().startsWith('/'),
+    /** Expected status, or any of several */
+    status: z.union([z.number().int(), z.array(z.number().int()).min(1)]),
+  }),
+]);
+
+const specSchema = z.object({
+  checks: z.array(checkSchema).min(1),
+  /** How to boot the app for route checks; `{port}` in the command is replaced and PORT is set */
+  devServer: z
+    .object({
+      /** package.json script, run with the project's package manager */
+      script: z.string().optional(),
+      command: z.array(z.string()).min(1).optional(),
+      readyTimeoutMs: z.number().int().positive().default(60_000),
+    })
+    .refine((s) => !!s.script !== !!s.command, 'devServer needs exactly one of script or command')
+    .optional(),
+});
+
+export type VerificationCheck = z.infer<typeof checkSchema>;
+export type VerificationSpec = z.infer<typeof specSchema>;
+
+export interface VerificationCheckResult {
+  name: string;
+  type: VerificationCheck['type'];
+  passed: boolean;
+  /** Not run, e.g. a route check when the dev server didn't start */
+  skipped?: boolean;
+  /** Failure details for the user and the repair prompt */
+  output?: string;
+  durationMs: number;
+}
+
+export interface VerificationReport {
+  passed: boolean;
+  checks: VerificationCheckResult[];
+  /** Agent prompt describing the failed checks, or null when everything passed */
+  repairPrompt: string | null;
+  durationMs: number;
+}
+
+/**
+ * Read a skill's verification spec. Returns null when the skill declares none;
+ * throws on a malformed file so skill authors find out in CI.
+ */
+export function loadVerificationSpec(skillDir: string): VerificationSpec | null {
+  const file = join(skillDir, VERIFY_FILE);
+  if (!existsSync(file)) return null;
+  const result = specSchema.safeParse(JSON.parse(readFileSync(file, 'utf-8')));
+  if (!result.success) {
+    throw new Error(`Invalid ${VERIFY_FILE} in ${skillDir}: ${result.error.issues.map((i) => i.message).join('; ')}`);
+  }
+  return result.data;
+}
+
+function checkName(check: VerificationCheck): string {
+  if (check.name) return check.name;
+  switch (check.type) {
+    case 'typecheck':
+      return 'Typecheck passes';
+    case 'build':
+      return 'Build succeeds';
+    case 'route': {
+      const statuses = Array.isArray(check.status) ? check.status.join(' or ') : check.status;
+      return `${check.method} ${check.path} returns ${statuses}`;
+    }
+    default:
+      return check.command.join(' ');
+  }
+}
+
+function tail(output: string): string {
+  const trimmed = output.trim();
+  return trimmed.length > OUTPUT_LIMIT ? `...${trimmed.slice(-OUTPUT_LIMIT)}` : trimmed;
+}
+
+function runCommand(command: string[], cwd: string, timeoutMs: number): Promise<{ exitCode: number; output: string }> {
+  return new Promise((resolve) => {
+    const target = spawnTarget(command[0], command.slice(1));
+    const proc = spawn(target.command, target.args, {
+      cwd,
+      timeout: timeoutMs,
+      windowsVerbatimArguments: target.windowsVerbatimArguments,
+    });
+    let output = '';
+    proc.stdout?.on('data', (data: Buffer) => (output += data.toString()));
+    proc.stderr?.on('data', (data: Buffer) => (output += data.toString()));
+    proc.on('close', (code) => resolve({ exitCode: code ?? 1, output }));
+    proc.on('error', (error) => resolve({ exitCode: 1, output: output + error.message }));
+  });
+}
+
+/** An unused local port for the dev server */
+export function findFreePort(): Promise<number> {
+  return new Promise((resolve, reject) => {
+    const server = createServer();
+    server.once('error', reject);
+    server.listen(0, () => {
+      const address = server.address();
+      const port = typeof address === 'object' && address ? address.port : 0;
+      server.close(() => resolve(port));
+    });
+  });
+}
+
+type CheckOutcome = 'passed' | 'skipped' | 'output';
+type DevServerSpec = NonNullable<VerificationSpec['devServer']>;
+
+interface DevServer {
+  baseUrl: string;
+  /** Combined stdout/stderr so far */
+  logs(): string;
+  stop(): void;
+}
+
+async function startDevServer(devServer: DevServerSpec, projectDir: string): Promise<DevServer> {
+  const port = await findFreePort();
+  const command = devServer.script
+    ? [detectPackageManager(projectDir), 'run', devServer.script]
+    : devServer.command!.map((arg) => arg.replaceAll('{port}', String(port)));
+
+  let logs = '';
+  let exited: string | null = null;
+  // Own process group, so stopping it also stops the watchers it spawns
+  const target = spawnTarget(command[0], command.slice(1));
+  const proc: ChildProcess = spawn(target.command, target.args, {
+    cwd: projectDir,
+    env: { ...process.env, PORT: String(port) },
+    detached: process.platform !== 'win32',
+    windowsVerbatimArguments: target.windowsVerbatimArguments,
+  });
+  proc.stdout?.on('data', (data: Buffer) => (logs += data.toString()));
+  proc.stderr?.on('data', (data: Buffer) => (logs += data.toString()));
+  proc.on('close', (code) => (exited = `exited with code ${code}`));
+  proc.on('error', (error) => (exited = error.message));
+
+  const stop = () => {
+    if (proc.exitCode !== null || !proc.pid) return;
+    try {
+      if (process.platform === 'win32') killProcessTree(proc.pid);
+      else process.kill(-proc.pid, 'SIGTERM');
+    } catch {
+      // Already gone
+    }
+  };
+
+  const baseUrl = `http://localhost:${port}`;
+  if (await waitUntilAnswering(baseUrl, devServer.readyTimeoutMs, () => exited !== null)) {
+    return { baseUrl, logs: () => logs, stop };
+  }
+
+  stop();
+  const reason = exited ?? `did not answer on port ${port} within ${devServer.readyTimeoutMs / 1000}s`;
+  throw new Error(`Dev server (${command.join(' ')}) ${reason}\n\n${tail(logs)}`);
+}
+
+/** Whether anything answers HTTP at the URL, whatever the status */
+async function answers(baseUrl: string): Promise<boolean> {
+  try {
+    await fetch(baseUrl, { redirect: 'manual', signal: AbortSignal.timeout(2000) });
+    return true;
+  } catch {
+    return false;
+  }
+}
+
+/** Poll until something answers at the URL; false on timeout or once `gaveUp` says so */
+async function waitUntilAnswering(baseUrl: string, timeoutMs: number, gaveUp: () => boolean): Promise<boolean> {
+  const deadline = Date.now() + timeoutMs;
+  while (Date.now() < deadline && !gaveUp()) {
+    if (await answers(baseUrl)) return true;
+    await new Promise((resolve) => setTimeout(resolve, 500));
+  }
+  return false;
+}
+
+/**
+ * Probe a compose service on its published host port. One that's already
+ * up is used as it is; otherwise it's started with `docker compose up -d`
+ * and stopped again afterwards.
+ */
+async function startComposeService(target: ComposeTarget, readyTimeoutMs: number): Promise<DevServer> {
+  const docker = (...args: string[]) =>
+    spawnSync('docker', composeArgs(target.project, ...args), { cwd: target.project.dir, encoding: 'utf-8' });
+  const logs = () => {
+    const result = docker('logs', '--no-color', '--tail', '200', target.service);
+    return `${result.stdout ?? ''}${result.stderr ?? ''}`;
+  };
+  const baseUrl = `http://localhost:${target.hostPort}`;
+  if (await answers(baseUrl)) {
+    return { baseUrl, logs, stop: () => {} };
+  }
+
+  const up = docker('up', '-d', target.service);
+  if (up.status !== 0) {
+    const output = `${up.stdout ?? ''}${up.stderr ?? ''}` || (up.error?.message ?? '');
+    throw new Error(`docker compose up ${target.service} failed:\n\n${tail(output)}`);
+  }
+  const stop = () => {
+    docker('stop', target.service);
+  };
+  const timeoutMs = Math.max(readyTimeoutMs, COMPOSE_READY_TIMEOUT_MS);
+  if (await waitUntilAnswering(baseUrl, timeoutMs, () => false)) {
+    return { baseUrl, logs, stop };
+  }
+
+  const output = logs();
+  stop();
+  throw new Error(
+    `Compose service ${target.service} did not answer on port ${target.hostPort} within ${timeoutMs / 1000}s` +
+      `\n\n${tail(output)}`,
+  );
+}
+
+async function probeRoute(
+  check: Extract<VerificationCheck, { type: 'route' }>,
+  server: DevServer,
+): Promise<Pick<VerificationCheckResult, CheckOutcome>> {
+  const expected = Array.isArray(check.status) ? check.status : [check.status];
+  try {
+    const res = await fetch(`${server.baseUrl}${check.path}`, {
+      method: check.method,
+      redirect: 'manual',
+      signal: AbortSignal.timeout(30_000),
+    });
+    if (expected.includes(res.status)) return { passed: true };
+    const location = res.headers.get('location');
+    return {
+      passed: false,
+      output:
+        `${check.method} ${check.path} returned ${res.status}${location ? ` (Location: ${location})` : ''}, ` +
+        `expected ${expected.join(' or ')}\n\nDev server output:\n${tail(server.logs())}`,
+    };
+  } catch (error) {
+    return { passed: false, output: `${check.method} ${check.path} failed: ${(error as Error).message}` };
+  }
+}
+
+async function runCheck(
+  check: Exclude<VerificationCheck, { type: 'route' }>,
+  projectDir: string,
+): Promise<Pick<VerificationCheckResult, CheckOutcome>> {
+  switch (check.type) {
+    case 'typecheck': {
+      const result = await runTypecheckValidation(projectDir);
+      return { passed: result.passed, output: result.agentPrompt ?? undefined };
+    }
+    case 'build': {
+      const result = await runBuildQuickCheck(projectDir, DEFAULT_COMMAND_TIMEOUT_MS);
+      return { passed: result.passed, output: result.agentPrompt ?? undefined };
+    }
+    default: {
+      const timeoutMs = check.timeoutMs ?? DEFAULT_COMMAND_TIMEOUT_MS;
+      const { exitCode, output } = await runCommand(check.command, projectDir, timeoutMs);
+      return exitCode === 0
+        ? { passed: true }
+        : { passed: false, output: `\`${check.command.join(' ')}\` exited with ${exitCode}:\n\n${tail(output)}` };
+    }
+  }
+}
+
+function formatRepairPrompt(failed: VerificationCheckResult[]): string {
+  const sections = failed.map((c) => `### ${c.name}\n\n${c.output ?? 'Failed with no output.'}`);
+  return [
+    'The WorkOS AuthKit integration is in place, but these post-install verification checks failed:',
+    '',
+    sections.join('\n\n'),
+    '',
+    'Fix the causes in the project without removing the AuthKit integration. Do not disable or skip the checks.',
+  ].join('\n');
+}
+
+/**
+ * Run a skill's verification checks in order. Route checks share one dev
+ * server, started on a free port after the other checks and stopped before
+ * returning, or the project's compose service on its published port. A
+ * server that fails to start fails every route check.
+ */
+export async function runVerificationChecks(
+  spec: VerificationSpec,
+  projectDir: string,
+  onCheck?: (result: VerificationCheckResult) => void,
+  options: { compose?: ComposeTarget } = {},
+): Promise<VerificationReport> {
+  const startTime = Date.now();
+  const checks: VerificationCheckResult[] = [];
+  const record = (check: VerificationCheck, started: number, result: Pick<VerificationCheckResult, CheckOutcome>) => {
+    const entry = { name: checkName(check), type: check.type, ...result, durationMs: Date.now() - started };
+    checks.push(entry);
+    onCheck?.(entry);
+  };
+
+  for (const check of spec.checks) {
+    if (check.type === 'route') continue;
+    const started = Date.now();
+    record(check, started, await runCheck(check, projectDir));
+  }
+
+  const routes = spec.checks.filter((c): c is Extract<VerificationCheck, { type: 'route' }> => c.type === 'route');
+  if (routes.length > 0) {
+    let server: DevServer | null = null;
+    let startError: string | undefined;
+    if (options.compose || spec.devServer) {
+      try {
+        server = options.compose
+          ? await startComposeService(options.compose, spec.devServer?.readyTimeoutMs ?? 0)
+          : await startDevServer(spec.devServer!, projectDir);
+      } catch (error) {
+        startError = (error as Error).message;
+      }
+    }
+
+    try {
+      for (const check of routes) {
+        const started = Date.now();
+        if (server) {
+          record(check, started, await probeRoute(check, server));
+        } else if (startError) {
+          record(check, started, { passed: false, output: startError });
+        } else {
+          // Nothing to probe against; not the project's fault
+          record(check, started, { passed: true, skipped: true, output: 'No devServer declared' });
+        }
+      }
+    } finally {
+      server?.stop();
+    }
+  }
+
+  const failed = checks.filter((c) => !c.passed);
+  return {
+    passed: failed.length === 0,
+    checks,
+    repairPrompt: failed.length > 0 ? formatRepairPrompt(failed) : null,
+    durationMs: Date.now() - startTime,
+  };
+}
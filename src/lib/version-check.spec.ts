@@ -1,4 +1,7 @@
 import { describe, it, expect, beforeEach, vi } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
 
 // Mock fetch globally
 const mockFetch = vi.fn();
@@ -15,13 +18,18 @@ vi.mock('./settings.js', () => ({
   getVersion: vi.fn(() => '0.3.0'),
 }));
 
-const { checkForUpdates, _resetWarningState } = await import('./version-check.js');
+const { checkForUpdates, _resetWarningState, _setCachePath } = await import('./version-check.js');
 const { yellow, dim } = await import('../utils/logging.js');
 
 describe('version-check', () => {
+  const cacheDir = mkdtempSync(join(tmpdir(), 'version-check-test-'));
+  const cachePath = join(cacheDir, 'update-check.json');
+
   beforeEach(() => {
     vi.clearAllMocks();
     _resetWarningState();
+    rmSync(cachePath, { force: true });
+    _setCachePath(cachePath);
   });
 
   it('shows warning when outdated', async () => {
@@ -33,7 +41,7 @@ describe('version-check', () => {
     await checkForUpdates();
 
     expect(yellow).toHaveBeenCalledWith(expect.stringContaining('0.3.0 → 0.4.0'));
-    expect(dim).toHaveBeenCalledWith('Run: npx workos@latest');
+    expect(dim).toHaveBeenCalledWith('Run: workos upgrade');
   });
 
   it('no warning when up to date', async () => {
@@ -112,4 +120,58 @@ describe('version-check', () => {
 
     expect(yellow).toHaveBeenCalledTimes(1);
   });
+
+  it('skips the check when checked within the last day', async () => {
+    writeFileSync(
+      cachePath,
+      JSON.stringify({ lastChecked: Date.now() - 60_000, latestVersion: '0.4.0', currentVersion: '0.3.0' }),
+    );
+
+    await checkForUpdates();
+
+    expect(mockFetch).not.toHaveBeenCalled();
+    expect(yellow).not.toHaveBeenCalled();
+  });
+
+  it('checks again when the cache was written by another CLI version', async () => {
+    writeFileSync(
+      cachePath,
+      JSON.stringify({ lastChecked: Date.now() - 60_000, latestVersion: '0.3.0', currentVersion: '0.2.0' }),
+    );
+    mockFetch.mockResolvedValueOnce({
+      ok: true,
+      json: async () => ({ version: '0.4.0' }),
+    });
+
+    await checkForUpdates();
+
+    expect(mockFetch).toHaveBeenCalledTimes(1);
+    expect(yellow).toHaveBeenCalled();
+  });
+
+  it('checks again once the cache is a day old', async () => {
+    writeFileSync(cachePath, JSON.stringify({ lastChecked: Date.now() - 25 * 60 * 60 * 1000, latestVersion: '0.3.0' }));
+    mockFetch.mockResolvedValueOnce({
+      ok: true,
+      json: async () => ({ version: '0.4.0' }),
+    });
+
+    await checkForUpdates();
+
+    expect(mockFetch).toHaveBeenCalledTimes(1);
+    expect(yellow).toHaveBeenCalled();
+  });
+
+  it('records the check so the next run is skipped', async () => {
+    mockFetch.mockResolvedValue({
+      ok: true,
+      json: async () => ({ version: '0.3.0' }),
+    });
+
+    await checkForUpdates();
+    _resetWarningState();
+    await checkForUpdates();
+
+    expect(mockFetch).toHaveBeenCalledTimes(1);
+  });
 });
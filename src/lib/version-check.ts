@@ -1,26 +1,61 @@
+import { mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
 import { lt, valid } from 'semver';
 import { yellow, dim } from '../utils/logging.js';
 import { getVersion } from './settings.js';
+import { proxiedFetch } from './proxy.js';
 
 const NPM_REGISTRY_URL = 'https://registry.npmjs.org/workos/latest';
 const TIMEOUT_MS = 500;
+const CHECK_INTERVAL_MS = 24 * 60 * 60 * 1000;
 
 let hasWarned = false;
+let cachePath = join(homedir(), '.workos', 'update-check.json');
 
 interface NpmPackageInfo {
   version: string;
 }
 
+interface UpdateCheckCache {
+  lastChecked: number;
+  latestVersion: string;
+  /** Version that ran the check; an upgrade or downgrade invalidates the cache */
+  currentVersion?: string;
+}
+
+function readCache(): UpdateCheckCache | null {
+  try {
+    return JSON.parse(readFileSync(cachePath, 'utf-8')) as UpdateCheckCache;
+  } catch {
+    return null;
+  }
+}
+
+function writeCache(cache: UpdateCheckCache): void {
+  try {
+    mkdirSync(dirname(cachePath), { recursive: true });
+    writeFileSync(cachePath, JSON.stringify(cache));
+  } catch {
+    // Caching is best effort
+  }
+}
+
 /**
  * Check npm registry for latest version and warn if outdated.
+ * Checks at most once a day (cached in ~/.workos/update-check.json).
  * Runs asynchronously, fails silently on any error.
  * Safe to call without awaiting (fire-and-forget).
  */
 export async function checkForUpdates(): Promise<void> {
   if (hasWarned) return;
 
+  const currentVersion = getVersion();
+  const cache = readCache();
+  if (cache && cache.currentVersion === currentVersion && Date.now() - cache.lastChecked < CHECK_INTERVAL_MS) return;
+
   try {
-    const response = await fetch(NPM_REGISTRY_URL, {
+    const response = await proxiedFetch(NPM_REGISTRY_URL, {
       signal: AbortSignal.timeout(TIMEOUT_MS),
     });
 
@@ -28,16 +63,17 @@ export async function checkForUpdates(): Promise<void> {
 
     const data = (await response.json()) as NpmPackageInfo;
     const latestVersion = data.version;
-    const currentVersion = getVersion();
 
     // Validate both versions are valid semver
     if (!valid(latestVersion) || !valid(currentVersion)) return;
 
+    writeCache({ lastChecked: Date.now(), latestVersion, currentVersion });
+
     // Only warn if current < latest
     if (lt(currentVersion, latestVersion)) {
       hasWarned = true;
       yellow(`Update available: ${currentVersion} → ${latestVersion}`);
-      dim(`Run: npx workos@latest`);
+      dim(`Run: workos upgrade`);
     }
   } catch {
     // Silently ignore all errors (timeout, network, parse, etc.)
@@ -51,3 +87,11 @@ export async function checkForUpdates(): Promise<void> {
 export function _resetWarningState(): void {
   hasWarned = false;
 }
+
+/**
+ * Override the cache location (for testing).
+ * @internal
+ */
+export function _setCachePath(path: string): void {
+  cachePath = path;
+}
@@ -0,0 +1,91 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { setHttpRetries } from './http-retry.js';
+import {
+  createWidgetToken,
+  describeWidgetTokenError,
+  serveWidgetPage,
+  tokenExpiry,
+  widgetPageHtml,
+} from './widgets.js';
+import { WorkOSApiError } from './workos-api.js';
+
+function jwt(payload: Record<string, unknown>): string {
+  return ['header', Buffer.from(JSON.stringify(payload)).toString('base64url'), 'signature'].join('.');
+}
+
+describe('widgets', () => {
+  it('reads the expiry from the token', () => {
+    expect(tokenExpiry(jwt({ exp: 1_900_000_000 }))).toEqual(new Date(1_900_000_000_000));
+    expect(tokenExpiry(jwt({}))).toBeNull();
+    expect(tokenExpiry('not-a-jwt')).toBeNull();
+  });
+
+  describe('createWidgetToken', () => {
+    const mockFetch = vi.fn();
+    const originalFetch = globalThis.fetch;
+
+    beforeEach(() => {
+      globalThis.fetch = mockFetch;
+      mockFetch.mockReset();
+      setHttpRetries(0);
+    });
+
+    afterEach(() => {
+      globalThis.fetch = originalFetch;
+    });
+
+    it('posts the user, organization and scopes', async () => {
+      const token = jwt({ exp: 1_900_000_000 });
+      mockFetch.mockResolvedValue({ ok: true, status: 200, text: async () => JSON.stringify({ token }) });
+
+      const result = await createWidgetToken(
+        { userId: 'user_01', organizationId: 'org_01', scopes: ['widgets:users-table:manage'] },
+        { apiKey: 'sk_test', baseUrl: 'https://api.workos.com' },
+      );
+
+      expect(result).toEqual({ token, expiresAt: new Date(1_900_000_000_000) });
+      const [url, init] = mockFetch.mock.calls[0];
+      expect(url).toBe('https://api.workos.com/widgets/token');
+      expect(JSON.parse(init.body)).toEqual({
+        user_id: 'user_01',
+        organization_id: 'org_01',
+        scopes: ['widgets:users-table:manage'],
+      });
+    });
+  });
+
+  describe('describeWidgetTokenError', () => {
+    it('explains invalid scopes', () => {
+      const error = new WorkOSApiError('Validation failed', 422, 'invalid_request', [{ message: 'Invalid scope' }]);
+
+      const message = describeWidgetTokenError(error, ['widgets:users:manage']);
+      expect(message).toContain('Unrecognized: widgets:users:manage');
+      expect(message).toContain('widgets:users-table:manage');
+    });
+
+    it('explains expired tokens, missing memberships and unknown IDs', () => {
+      expect(describeWidgetTokenError(new WorkOSApiError('Session token expired', 400), [])).toContain('expired');
+      expect(describeWidgetTokenError(new WorkOSApiError('User is not a member', 422), [])).toContain('membership');
+      expect(describeWidgetTokenError(new WorkOSApiError('Not found', 404), [])).toContain('--user and --org');
+    });
+  });
+
+  it('renders the widget matching the scope with the token', () => {
+    const html = widgetPageHtml('tok</script>', ['widgets:sso:manage'], 'api.workos.com');
+
+    expect(html).toContain('"component":"AdminPortalSsoConnection"');
+    expect(html).toContain('tok\\u003c/script>');
+    expect(html).not.toContain('tok</script>');
+  });
+
+  it('serves the page locally', async () => {
+    const server = await serveWidgetPage('<p>widget</p>', 0);
+    try {
+      const page = await fetch(server.url);
+      expect(await page.text()).toBe('<p>widget</p>');
+      expect((await fetch(`${server.url}/other`)).status).toBe(404);
+    } finally {
+      server.close();
+    }
+  });
+});
@@ -0,0 +1,141 @@
+/**
+ * Widget tokens and a throwaway page for trying widgets locally.
+ *
+ * `workos widgets token --serve` renders the widget matching the token's
+ * scope from a CDN build so it can be checked without an app. The page's
+ * origin still has to be allowed as a CORS origin in the environment.
+ */
+
+import http from 'node:http';
+import { workosRequest, WorkOSApiError } from './workos-api.js';
+import { loopbackHost } from '../utils/browser.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+/** Scope -> widget component rendered by the local page */
+export const WIDGET_SCOPES: Record<string, string> = {
+  'widgets:users-table:manage': 'UsersManagement',
+  'widgets:sso:manage': 'AdminPortalSsoConnection',
+  'widgets:domain-verification:manage': 'AdminPortalDomainVerification',
+  'widgets:api-keys:manage': 'ApiKeys',
+};
+
+export interface WidgetToken {
+  token: string;
+  /** From the token's `exp` claim, if present */
+  expiresAt: Date | null;
+}
+
+/** Read `exp` from the JWT payload; the signature is not verified */
+export function tokenExpiry(token: string): Date | null {
+  try {
+    const payload = JSON.parse(Buffer.from(token.split('.')[1] ?? '', 'base64url').toString('utf-8')) as {
+      exp?: number;
+    };
+    return typeof payload.exp === 'number' ? new Date(payload.exp * 1000) : null;
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Turn widget token API errors into something actionable.
+ */
+export function describeWidgetTokenError(error: unknown, scopes: string[]): string {
+  if (!(error instanceof WorkOSApiError)) return error instanceof Error ? error.message : String(error);
+  const detail = [error.message, ...(error.errors ?? []).map((e) => e.message)].join(' ').toLowerCase();
+
+  if (error.statusCode === 401) return 'Invalid API key. Check your environment configuration.';
+  if (error.statusCode === 404) return 'User or organization not found. Check the --user and --org IDs.';
+  if (detail.includes('scope')) {
+    const unknown = scopes.filter((scope) => !(scope in WIDGET_SCOPES));
+    const hint = unknown.length > 0 ? ` Unrecognized: ${unknown.join(', ')}.` : '';
+    return `Invalid widget scope.${hint} Valid scopes: ${Object.keys(WIDGET_SCOPES).join(', ')}.`;
+  }
+  if (detail.includes('membership') || detail.includes('not a member')) {
+    return 'The user is not a member of that organization. Add an organization membership first.';
+  }
+  if (detail.includes('expired')) return 'The widget token has expired. Generate a new one.';
+  if (error.statusCode === 422 && error.errors?.length) return error.errors.map((e) => e.message).join(', ');
+  return error.message;
+}
+
+export async function createWidgetToken(
+  options: { userId: string; organizationId: string; scopes: string[] },
+  api: ApiOptions,
+): Promise<WidgetToken> {
+  const result = await workosRequest<{ token: string }>({
+    method: 'POST',
+    path: '/widgets/token',
+    body: { user_id: options.userId, organization_id: options.organizationId, scopes: options.scopes },
+    ...api,
+  });
+  return { token: result.token, expiresAt: tokenExpiry(result.token) };
+}
+
+function escapeHtml(value: string): string {
+  return value.replace(/[&<>"']/g, (ch) => `&#${ch.charCodeAt(0)};`);
+}
+
+/**
+ * Standalone page rendering the widget for the first recognized scope.
+ */
+export function widgetPageHtml(token: string, scopes: string[], apiHostname?: string): string {
+  const component = scopes.map((scope) => WIDGET_SCOPES[scope]).find(Boolean) ?? 'UsersManagement';
+  const config = JSON.stringify({ token, component, apiHostname }).replace(/</g, '\\u003c');
+
+  return `<!doctype html>
+<html lang="en">
+  <head>
+    <meta charset="utf-8" />
+    <title>${escapeHtml(component)} · WorkOS widget preview</title>
+    <link rel="stylesheet" href="https://esm.sh/@radix-ui/themes/styles.css" />
+    <link rel="stylesheet" href="https://esm.sh/@workos-inc/widgets/styles.css" />
+  </head>
+  <body>
+    <div id="root" style="max-width: 960px; margin: 48px auto"></div>
+    <script type="module">
+      import { createElement as h } from 'https://esm.sh/react@19';
+      import { createRoot } from 'https://esm.sh/react-dom@19/client';
+      import * as widgets from 'https://esm.sh/@workos-inc/widgets?deps=react@19,react-dom@19';
+
+      const { token, component, apiHostname } = ${config};
+      createRoot(document.getElementById('root')).render(
+        h(widgets.WorkOsWidgets, apiHostname ? { apiHostname } : {}, h(widgets[component], { authToken: token })),
+      );
+    </script>
+  </body>
+</html>
+`;
+}
+
+export interface WidgetServer {
+  url: string;
+  close: () => void;
+}
+
+/**
+ * Serve the preview page on 127.0.0.1. Port 0 picks a free port.
+ */
+export async function serveWidgetPage(html: string, port: number): Promise<WidgetServer> {
+  const server = http.createServer((req, res) => {
+    if ((req.url ?? '/').split('?')[0] !== '/') {
+      res.writeHead(404).end();
+      return;
+    }
+    res.writeHead(200, { 'Content-Type': 'text/html; charset=utf-8', 'Cache-Control': 'no-store' });
+    res.end(html);
+  });
+
+  await new Promise<void>((resolve, reject) => {
+    server.once('error', reject);
+    server.listen(port, loopbackHost(), resolve);
+  });
+  const address = server.address();
+  const listeningPort = address && typeof address === 'object' ? address.port : port;
+
+  return { url: `http://localhost:${listeningPort}`, close: () => server.close() };
+}
@@ -1,6 +1,7 @@
 import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
 
-const { workosRequest, WorkOSApiError } = await import('./workos-api.js');
+const { parseRetryAfter, workosRequest, WorkOSApiError } = await import('./workos-api.js');
+const { setHttpRetries } = await import('./http-retry.js');
 
 describe('workos-api', () => {
   const mockFetch = vi.fn();
@@ -9,6 +10,7 @@ describe('workos-api', () => {
   beforeEach(() => {
     globalThis.fetch = mockFetch;
     mockFetch.mockReset();
+    setHttpRetries(0);
   });
 
   afterEach(() => {
@@ -72,6 +74,17 @@ describe('workos-api', () => {
       expect(calledUrl).not.toContain('empty');
     });
 
+    it('repeats array query params', async () => {
+      mockFetch.mockResolvedValue(mockResponse(200, { data: [] }));
+      await workosRequest({
+        method: 'GET',
+        path: '/events',
+        apiKey: 'sk_test',
+        params: { events: ['dsync.user.created', 'dsync.user.updated'] },
+      });
+      expect(mockFetch.mock.calls[0][0]).toContain('?events=dsync.user.created&events=dsync.user.updated');
+    });
+
     it('sends JSON body for POST requests', async () => {
       mockFetch.mockResolvedValue(mockResponse(201, { id: 'org_123', name: 'Test' }));
       await workosRequest({
@@ -155,11 +168,134 @@ describe('workos-api', () => {
       }
     });
 
+    it('reads Retry-After on 429', async () => {
+      mockFetch.mockResolvedValue({
+        ...mockResponse(429, { message: 'Too many requests' }, false),
+        headers: new Headers({ 'Retry-After': '30' }),
+      });
+      const error = await workosRequest({ method: 'GET', path: '/organizations', apiKey: 'sk_test' }).catch((e) => e);
+      expect(error).toMatchObject({ statusCode: 429, retryAfter: 30 });
+    });
+
+    it('includes the request ID of a failed response', async () => {
+      mockFetch.mockResolvedValue({
+        ...mockResponse(500, { message: 'Internal error' }, false),
+        headers: new Headers({ 'X-Request-Id': 'req_123' }),
+      });
+      const error = await workosRequest({ method: 'GET', path: '/organizations', apiKey: 'sk_test' }).catch((e) => e);
+      expect(error).toMatchObject({
+        requestId: 'req_123',
+        message: 'Internal error. WorkOS may be having problems; check https://status.workos.com (request ID: req_123)',
+      });
+    });
+
+    it('sends an Idempotency-Key header when given a key', async () => {
+      mockFetch.mockResolvedValue(mockResponse(201, { id: 'org_1' }));
+      await workosRequest({ method: 'POST', path: '/organizations', apiKey: 'sk_test', idempotencyKey: 'key_1' });
+      expect(mockFetch).toHaveBeenCalledWith(
+        expect.any(String),
+        expect.objectContaining({ headers: expect.objectContaining({ 'Idempotency-Key': 'key_1' }) }),
+      );
+    });
+
     it('throws on network error', async () => {
       mockFetch.mockRejectedValue(new TypeError('fetch failed'));
       await expect(workosRequest({ method: 'GET', path: '/organizations', apiKey: 'sk_test' })).rejects.toThrow(
         'Failed to connect to WorkOS API',
       );
     });
+
+    it('says why the connection failed', async () => {
+      const failWith = async (code: string) => {
+        mockFetch.mockRejectedValueOnce(new TypeError('fetch failed', { cause: { code } }));
+        return workosRequest({ method: 'GET', path: '/organizations', apiKey: 'sk_test' }).catch((e) => e);
+      };
+
+      expect(await failWith('ENOTFOUND')).toMatchObject({
+        statusCode: 0,
+        code: 'dns',
+        message: expect.stringContaining('DNS lookup for api.workos.com failed'),
+      });
+      expect(await failWith('SELF_SIGNED_CERT_IN_CHAIN')).toMatchObject({
+        code: 'tls',
+        message: expect.stringContaining('NODE_EXTRA_CA_CERTS'),
+      });
+    });
+
+    describe('retries', () => {
+      beforeEach(() => {
+        setHttpRetries(2);
+        vi.spyOn(Math, 'random').mockReturnValue(0);
+      });
+
+      afterEach(() => {
+        vi.restoreAllMocks();
+      });
+
+      it('retries 5xx responses and returns the eventual success', async () => {
+        mockFetch
+          .mockResolvedValueOnce(mockTextResponse(502, 'Bad Gateway'))
+          .mockResolvedValueOnce(mockResponse(200, { id: 'org_123' }));
+
+        const result = await workosRequest({ method: 'GET', path: '/organizations/org_123', apiKey: 'sk_test' });
+
+        expect(result).toEqual({ id: 'org_123' });
+        expect(mockFetch).toHaveBeenCalledTimes(2);
+      });
+
+      it('does not retry 4xx responses', async () => {
+        mockFetch.mockResolvedValue(mockResponse(404, { message: 'Not Found' }, false));
+
+        await expect(workosRequest({ method: 'GET', path: '/organizations/x', apiKey: 'sk_test' })).rejects.toThrow(
+          'Not Found',
+        );
+        expect(mockFetch).toHaveBeenCalledTimes(1);
+      });
+
+      it('reports the number of attempts in the final error', async () => {
+        mockFetch.mockResolvedValue(mockResponse(503, { message: 'Service Unavailable' }, false));
+
+        await expect(workosRequest({ method: 'GET', path: '/organizations', apiKey: 'sk_test' })).rejects.toThrow(
+          'Service Unavailable after 3 attempts',
+        );
+      });
+
+      it('retries 429 responses', async () => {
+        mockFetch
+          .mockResolvedValueOnce(mockResponse(429, { message: 'Too many requests' }, false))
+          .mockResolvedValueOnce(mockResponse(200, { data: [] }));
+
+        await workosRequest({ method: 'GET', path: '/organizations', apiKey: 'sk_test' });
+
+        expect(mockFetch).toHaveBeenCalledTimes(2);
+      });
+
+      it('does not retry a POST without an idempotency key', async () => {
+        mockFetch.mockResolvedValue(mockResponse(503, { message: 'Service Unavailable' }, false));
+
+        await expect(workosRequest({ method: 'POST', path: '/organizations', apiKey: 'sk_test' })).rejects.toThrow(
+          'Service Unavailable',
+        );
+        expect(mockFetch).toHaveBeenCalledTimes(1);
+      });
+
+      it('retries connection resets', async () => {
+        mockFetch.mockRejectedValue(new TypeError('fetch failed', { cause: { code: 'ECONNRESET' } }));
+
+        await expect(workosRequest({ method: 'GET', path: '/organizations', apiKey: 'sk_test' })).rejects.toThrow(
+          'Failed to connect to WorkOS API (ECONNRESET after 3 attempts)',
+        );
+      });
+    });
+  });
+
+  describe('parseRetryAfter', () => {
+    it('accepts seconds and HTTP dates', () => {
+      const now = Date.parse('2026-01-01T00:00:00Z');
+      expect(parseRetryAfter('12', now)).toBe(12);
+      expect(parseRetryAfter('Thu, 01 Jan 2026 00:01:30 GMT', now)).toBe(90);
+      expect(parseRetryAfter('soon', now)).toBeUndefined();
+      expect(parseRetryAfter(null, now)).toBeUndefined();
+    });
   });
 });
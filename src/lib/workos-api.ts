@@ -3,15 +3,26 @@
  * Thin fetch wrapper with auth, error parsing, and query param support.
  */
 
+import { CassetteMismatchError } from './cassette.js';
+import { classifyNetworkError, describeNetworkProblem, STATUS_PAGE_URL } from './connectivity.js';
+import { attemptsSuffix, fetchWithRetry, getHttpTimeout, HttpRetryError, parseRetryAfter } from './http-retry.js';
+
+export { parseRetryAfter };
+
 const DEFAULT_BASE_URL = 'https://api.workos.com';
+const STATUS_HINT = `WorkOS may be having problems; check ${STATUS_PAGE_URL}`;
 
 export interface WorkOSRequestOptions {
   method: 'GET' | 'POST' | 'PUT' | 'DELETE';
   path: string;
   apiKey: string;
   baseUrl?: string;
-  body?: Record<string, unknown>;
-  params?: Record<string, string | number | undefined>;
+  /** Arrays are sent as-is for batch endpoints */
+  body?: Record<string, unknown> | unknown[];
+  /** Arrays become repeated keys (`events=a&events=b`) */
+  params?: Record<string, string | number | string[] | undefined>;
+  /** Sent as Idempotency-Key so a POST can be retried safely */
+  idempotencyKey?: string;
 }
 
 export interface WorkOSListResponse<T> {
@@ -28,20 +39,36 @@ export class WorkOSApiError extends Error {
     public readonly statusCode: number,
     public readonly code?: string,
     public readonly errors?: Array<{ message: string }>,
+    /** Seconds to wait before retrying, from the Retry-After header of a 429 */
+    public readonly retryAfter?: number,
+    /** X-Request-Id of the failed response, for support tickets */
+    public readonly requestId?: string,
   ) {
-    super(message);
+    super(requestId ? `${message} (request ID: ${requestId})` : message);
     this.name = 'WorkOSApiError';
   }
 }
 
+/** A request that got no response; `code` says why (see connectivity.ts) */
+export function isConnectionError(error: unknown): boolean {
+  return error instanceof WorkOSApiError && error.statusCode === 0;
+}
+
+/** 5xx responses point at the status page, since retrying locally won't help */
+function serverErrorHint(status: number): string {
+  return status >= 500 ? `. ${STATUS_HINT}` : '';
+}
+
 export async function workosRequest<T>(options: WorkOSRequestOptions): Promise<T> {
-  const { method, path, apiKey, baseUrl = DEFAULT_BASE_URL, body, params } = options;
+  const { method, path, apiKey, baseUrl = DEFAULT_BASE_URL, body, params, idempotencyKey } = options;
 
   let url = `${baseUrl}${path}`;
   if (params) {
     const searchParams = new URLSearchParams();
     for (const [key, value] of Object.entries(params)) {
-      if (value !== undefined && value !== '') {
+      if (Array.isArray(value)) {
+        for (const item of value) searchParams.append(key, item);
+      } else if (value !== undefined && value !== '') {
         searchParams.set(key, String(value));
       }
     }
@@ -52,6 +79,7 @@ export async function workosRequest<T>(options: WorkOSRequestOptions): Promise<T
   const headers: Record<string, string> = {
     Authorization: `Bearer ${apiKey}`,
   };
+  if (idempotencyKey) headers['Idempotency-Key'] = idempotencyKey;
 
   const fetchOptions: RequestInit = { method, headers };
 
@@ -61,16 +89,22 @@ export async function workosRequest<T>(options: WorkOSRequestOptions): Promise<T
   }
 
   let response: Response;
+  let attempts: number;
   try {
-    response = await fetch(url, fetchOptions);
-  } catch {
-    throw new WorkOSApiError('Failed to connect to WorkOS API. Check your internet connection.', 0);
+    ({ response, attempts } = await fetchWithRetry(url, fetchOptions));
+  } catch (error) {
+    if (error instanceof CassetteMismatchError) throw error;
+    const suffix = error instanceof HttpRetryError ? ` (${error.message}${attemptsSuffix(error.attempts)})` : '';
+    const kind = classifyNetworkError(error instanceof HttpRetryError ? error.cause : error);
+    const advice = describeNetworkProblem(kind, { host: new URL(baseUrl).host, timeoutMs: getHttpTimeout() });
+    throw new WorkOSApiError(`Failed to connect to WorkOS API${suffix}. ${advice}`, 0, kind);
   }
 
   if (response.status === 204 || response.status === 202) {
     return null as T;
   }
 
+  const requestId = response.headers?.get('x-request-id') ?? undefined;
   const text = await response.text();
   let data: unknown;
   try {
@@ -78,14 +112,19 @@ export async function workosRequest<T>(options: WorkOSRequestOptions): Promise<T
   } catch {
     // Non-JSON response — if ok, return null; otherwise throw
     if (response.ok) return null as T;
-    throw new WorkOSApiError(text || `HTTP ${response.status}`, response.status);
+    const message = (text || `HTTP ${response.status}`) + attemptsSuffix(attempts) + serverErrorHint(response.status);
+    throw new WorkOSApiError(message, response.status, undefined, undefined, undefined, requestId);
   }
 
   if (!response.ok) {
-    const message = (data as { message?: string }).message || `HTTP ${response.status}`;
+    const message =
+      ((data as { message?: string }).message || `HTTP ${response.status}`) +
+      attemptsSuffix(attempts) +
+      serverErrorHint(response.status);
     const code = (data as { code?: string }).code;
     const errors = (data as { errors?: Array<{ message: string }> }).errors;
-    throw new WorkOSApiError(message, response.status, code, errors);
+    const retryAfter = response.status === 429 ? parseRetryAfter(response.headers?.get('retry-after')) : undefined;
+    throw new WorkOSApiError(message, response.status, code, errors, retryAfter, requestId);
   }
 
   return data as T;
@@ -0,0 +1,97 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { createHmac } from 'node:crypto';
+import { setHttpRetries } from './http-retry.js';
+import { followEvents, parseRange, replayEvent, signWebhookPayload, type WorkOSEvent } from './workos-events.js';
+
+const api = { apiKey: 'sk_test_abc', baseUrl: 'https://api.workos.com' };
+
+function event(id: string): WorkOSEvent {
+  return { id, event: 'dsync.user.updated', data: { id: 'directory_user_01' }, created_at: '2026-01-01T00:00:00Z' };
+}
+
+describe('workos-events', () => {
+  describe('parseRange', () => {
+    const now = Date.parse('2026-01-02T00:00:00Z');
+
+    it('accepts lookbacks', () => {
+      expect(parseRange('24h', now)).toEqual({ rangeStart: '2026-01-01T00:00:00.000Z' });
+      expect(parseRange('30m', now)).toEqual({ rangeStart: '2026-01-01T23:30:00.000Z' });
+    });
+
+    it('accepts start..end with either side open', () => {
+      expect(parseRange('2026-01-01..2026-01-02', now)).toEqual({
+        rangeStart: '2026-01-01T00:00:00.000Z',
+        rangeEnd: '2026-01-02T00:00:00.000Z',
+      });
+      expect(parseRange('..2026-01-02', now)).toEqual({ rangeStart: undefined, rangeEnd: '2026-01-02T00:00:00.000Z' });
+    });
+
+    it('rejects anything else', () => {
+      expect(() => parseRange('yesterday', now)).toThrow('Invalid --range');
+      expect(() => parseRange('soon..later', now)).toThrow('Invalid date "soon"');
+    });
+  });
+
+  describe('followEvents', () => {
+    const mockFetch = vi.fn();
+    const originalFetch = globalThis.fetch;
+
+    function respond(data: WorkOSEvent[], after: string | null = null) {
+      mockFetch.mockResolvedValueOnce({
+        ok: true,
+        status: 200,
+        text: async () => JSON.stringify({ data, list_metadata: { before: null, after } }),
+      });
+    }
+
+    beforeEach(() => {
+      globalThis.fetch = mockFetch;
+      mockFetch.mockReset();
+      setHttpRetries(0);
+    });
+
+    afterEach(() => {
+      globalThis.fetch = originalFetch;
+    });
+
+    it('pages forward, then polls from the last event', async () => {
+      respond([event('event_01')], 'event_01');
+      respond([event('event_02')]);
+      respond([]);
+      const controller = new AbortController();
+      const sleep = vi.fn(async () => {
+        if (sleep.mock.calls.length === 2) controller.abort();
+      });
+
+      const seen: string[] = [];
+      const query = { types: ['dsync.user.updated'] };
+      for await (const e of followEvents(query, api, { signal: controller.signal, sleep })) seen.push(e.id);
+
+      expect(seen).toEqual(['event_01', 'event_02']);
+      const urls = mockFetch.mock.calls.map(([url]) => url as string);
+      expect(urls[0]).toBe('https://api.workos.com/events?events=dsync.user.updated');
+      expect(urls[1]).toContain('after=event_01');
+      expect(urls[2]).toContain('after=event_02');
+      // No wait between pages, one after each caught-up poll
+      expect(sleep).toHaveBeenCalledTimes(2);
+    });
+  });
+
+  it('signs replays like WorkOS webhooks', async () => {
+    const header = signWebhookPayload('{"id":"event_01"}', 'whsec', 1_700_000_000_000);
+    const expected = createHmac('sha256', 'whsec').update('1700000000000.{"id":"event_01"}').digest('hex');
+    expect(header).toBe(`t=1700000000000, v1=${expected}`);
+
+    const mockFetch = vi.fn().mockResolvedValue({ status: 204 });
+    vi.stubGlobal('fetch', mockFetch);
+    try {
+      expect(await replayEvent(event('event_01'), 'http://localhost:3000/webhooks', 'whsec')).toBe(204);
+      const [url, init] = mockFetch.mock.calls[0];
+      expect(url).toBe('http://localhost:3000/webhooks');
+      expect(JSON.parse(init.body).id).toBe('event_01');
+      expect(init.headers['WorkOS-Signature']).toMatch(/^t=\d+, v1=[0-9a-f]{64}$/);
+    } finally {
+      vi.unstubAllGlobals();
+    }
+  });
+});
@@ -0,0 +1,136 @@
+/**
+ * Events API explorer: list, follow and replay events.
+ *
+ * Replayed events are signed the way WorkOS signs webhooks
+ * (`WorkOS-Signature: t=<ms>, v1=<hex HMAC-SHA256 of "<t>.<body>">`), so a
+ * local webhook handler verifies them with its usual code.
+ */
+
+import { createHmac } from 'node:crypto';
+import { workosRequest, type WorkOSListResponse, type WorkOSRequestOptions } from './workos-api.js';
+
+interface ApiOptions {
+  apiKey: string;
+  baseUrl?: string;
+}
+
+export interface WorkOSEvent {
+  id: string;
+  event: string;
+  data: Record<string, unknown>;
+  created_at: string;
+}
+
+export interface EventsQuery {
+  types?: string[];
+  rangeStart?: string;
+  rangeEnd?: string;
+  organizationId?: string;
+  limit?: number;
+  after?: string;
+}
+
+const DURATION_UNITS: Record<string, number> = { m: 60_000, h: 3_600_000, d: 86_400_000 };
+
+/**
+ * `--range` is either a lookback (`30m`, `24h`, `7d`) or `<start>..<end>`
+ * with ISO dates, where either side may be left open.
+ */
+export function parseRange(range: string, now = Date.now()): { rangeStart?: string; rangeEnd?: string } {
+  const duration = /^(\d+)([mhd])$/.exec(range.trim());
+  if (duration) {
+    return { rangeStart: new Date(now - Number(duration[1]) * DURATION_UNITS[duration[2]]).toISOString() };
+  }
+
+  const [start, end, ...rest] = range.split('..');
+  if (end === undefined || rest.length > 0) {
+    throw new Error(`Invalid --range "${range}". Use a lookback like 24h or 7d, or <start>..<end> ISO dates.`);
+  }
+  const toIso = (value: string) => {
+    if (!value) return undefined;
+    const date = new Date(value);
+    if (Number.isNaN(date.getTime())) throw new Error(`Invalid date "${value}" in --range.`);
+    return date.toISOString();
+  };
+  return { rangeStart: toIso(start.trim()), rangeEnd: toIso(end.trim()) };
+}
+
+/** GET /events for a query; shared by single-page listing and pagination */
+export function eventsRequest(query: EventsQuery, api: ApiOptions): Omit<WorkOSRequestOptions, 'method'> {
+  return {
+    path: '/events',
+    params: {
+      events: query.types,
+      range_start: query.rangeStart,
+      range_end: query.rangeEnd,
+      organization_id: query.organizationId,
+      limit: query.limit,
+      after: query.after,
+    },
+    ...api,
+  };
+}
+
+export function listEvents(query: EventsQuery, api: ApiOptions): Promise<WorkOSListResponse<WorkOSEvent>> {
+  return workosRequest<WorkOSListResponse<WorkOSEvent>>({ method: 'GET', ...eventsRequest(query, api) });
+}
+
+export interface FollowOptions {
+  intervalMs?: number;
+  signal?: AbortSignal;
+  /** Injected by tests */
+  sleep?: (ms: number, signal?: AbortSignal) => Promise<void>;
+}
+
+function sleep(ms: number, signal?: AbortSignal): Promise<void> {
+  return new Promise((resolve) => {
+    const timer = setTimeout(resolve, ms);
+    signal?.addEventListener('abort', () => {
+      clearTimeout(timer);
+      resolve();
+    });
+  });
+}
+
+/**
+ * Yield events as they arrive, paging forward from the query's cursor and
+ * polling once caught up, until the signal aborts.
+ */
+export async function* followEvents(
+  query: EventsQuery,
+  api: ApiOptions,
+  options: FollowOptions = {},
+): AsyncGenerator<WorkOSEvent> {
+  const { intervalMs = 5_000, signal, sleep: wait = sleep } = options;
+  let after = query.after;
+
+  while (!signal?.aborted) {
+    const page = await listEvents({ ...query, after }, api);
+    for (const event of page.data) yield event;
+
+    const last = page.data.at(-1);
+    after = page.list_metadata.after ?? last?.id ?? after;
+    // More pages are waiting; only poll once caught up
+    if (!page.list_metadata.after) await wait(intervalMs, signal);
+  }
+}
+
+export function signWebhookPayload(body: string, secret: string, timestamp = Date.now()): string {
+  const signature = createHmac('sha256', secret).update(`${timestamp}.${body}`).digest('hex');
+  return `t=${timestamp}, v1=${signature}`;
+}
+
+/**
+ * POST an event to a local endpoint as a signed webhook. Returns the
+ * response status so the caller can report failures without stopping.
+ * Plain fetch: the endpoint is local, so the API proxy doesn't apply.
+ */
+export async function replayEvent(event: WorkOSEvent, url: string, secret: string): Promise<number> {
+  const body = JSON.stringify(event);
+  const response = await fetch(url, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', 'WorkOS-Signature': signWebhookPayload(body, secret) },
+    body,
+  });
+  return response.status;
+}
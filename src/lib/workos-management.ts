@@ -3,6 +3,8 @@ import { INSTALLER_INTERACTION_EVENT_NAME } from './constants.js';
 import { analytics } from '../utils/analytics.js';
 import clack from '../utils/clack.js';
 import { getCallbackPath } from './port-detection.js';
+import type { InstallerEventEmitter } from './events.js';
+import { cassetteFetch } from './cassette.js';
 
 const WORKOS_API_BASE = 'https://api.workos.com';
 
@@ -12,6 +14,14 @@ export interface AutoConfigResult {
   homepageUrl: { success: boolean };
 }
 
+/** One dashboard setting the installer created or updated, for the run report */
+export interface DashboardChange {
+  setting: 'Redirect URI' | 'CORS origin' | 'Homepage URL';
+  value: string;
+  /** false when it was already there */
+  created: boolean;
+}
+
 interface FetchError {
   status: number;
   message: string;
@@ -24,7 +34,7 @@ async function workosRequest(
   apiKey: string,
   body: Record<string, string>,
 ): Promise<Response> {
-  return fetch(`${WORKOS_API_BASE}${endpoint}`, {
+  return cassetteFetch(`${WORKOS_API_BASE}${endpoint}`, {
     method,
     headers: {
       Authorization: `Bearer ${apiKey}`,
@@ -107,6 +117,10 @@ export interface AutoConfigOptions {
   homepageUrl?: string;
   /** Custom redirect URI (defaults to framework convention) */
   redirectUri?: string;
+  /** Dev server origin to allow (defaults to http://localhost:{port}) */
+  corsOrigin?: string;
+  /** Reports what was configured as `dashboard:configured` */
+  emitter?: InstallerEventEmitter;
 }
 
 /**
@@ -130,16 +144,17 @@ export async function autoConfigureWorkOSEnvironment(
   const callbackPath = getCallbackPath(integration);
   const callbackUrl = options.redirectUri || `${baseUrl}${callbackPath}`;
   const homepageUrlValue = options.homepageUrl || baseUrl;
+  const corsOriginValue = options.corsOrigin || baseUrl;
 
   clack.log.step('Configuring WorkOS dashboard settings via API...');
   clack.log.info(`  Redirect URI: ${callbackUrl}`);
-  clack.log.info(`  CORS origin: ${baseUrl}`);
+  clack.log.info(`  CORS origin: ${corsOriginValue}`);
   clack.log.info(`  Homepage URL: ${homepageUrlValue}`);
 
   try {
     const [redirectUri, corsOrigin, homepageUrl] = await Promise.all([
       createRedirectUri(apiKey, callbackUrl),
-      createCorsOrigin(apiKey, baseUrl),
+      createCorsOrigin(apiKey, corsOriginValue),
       setHomepageUrl(apiKey, homepageUrlValue),
     ]);
 
@@ -161,11 +176,20 @@ export async function autoConfigureWorkOSEnvironment(
         : `Redirect URI: ${callbackUrl} (created)`,
     );
     messages.push(
-      corsOrigin.alreadyExists ? `CORS origin: ${baseUrl} (already existed)` : `CORS origin: ${baseUrl} (created)`,
+      corsOrigin.alreadyExists
+        ? `CORS origin: ${corsOriginValue} (already existed)`
+        : `CORS origin: ${corsOriginValue} (created)`,
     );
     messages.push(`Homepage URL: ${homepageUrlValue} (updated)`);
 
     clack.log.success('WorkOS dashboard configured:\n  ' + messages.join('\n  '));
+    options.emitter?.emit('dashboard:configured', {
+      changes: [
+        { setting: 'Redirect URI', value: callbackUrl, created: !redirectUri.alreadyExists },
+        { setting: 'CORS origin', value: corsOriginValue, created: !corsOrigin.alreadyExists },
+        { setting: 'Homepage URL', value: homepageUrlValue, created: true },
+      ],
+    });
 
     return results;
   } catch (error) {
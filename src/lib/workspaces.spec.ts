@@ -0,0 +1,114 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { findWorkspaceRoot, parsePnpmWorkspace, readWorkspaceGlobs, selectWorkspacePackage } from './workspaces.js';
+
+describe('workspaces', () => {
+  let dir: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(dir, relPath)), { recursive: true });
+    writeFileSync(join(dir, relPath), content);
+  }
+
+  function writePackage(relDir: string, packageJson: Record<string, unknown>) {
+    write(join(relDir, 'package.json'), JSON.stringify(packageJson));
+  }
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'workspaces-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  describe('parsePnpmWorkspace', () => {
+    it('reads the packages list', () => {
+      const yaml = "packages:\n  - 'apps/*'\n  - \"packages/*\" # shared\n  - '!**/test/**'\ncatalog:\n  react: ^19\n";
+
+      expect(parsePnpmWorkspace(yaml)).toEqual(['apps/*', 'packages/*', '!**/test/**']);
+    });
+  });
+
+  describe('readWorkspaceGlobs', () => {
+    it('reads package.json workspaces in array and object form', () => {
+      writePackage('.', { workspaces: ['apps/*'] });
+      expect(readWorkspaceGlobs(dir)).toEqual(['apps/*']);
+
+      writePackage('.', { workspaces: { packages: ['web'] } });
+      expect(readWorkspaceGlobs(dir)).toEqual(['web']);
+    });
+
+    it('defaults to apps/* and packages/* for turbo.json', () => {
+      write('turbo.json', '{}');
+
+      expect(readWorkspaceGlobs(dir)).toEqual(['apps/*', 'packages/*']);
+    });
+
+    it('returns null for single-package projects', () => {
+      writePackage('.', { dependencies: { next: '15.0.0' } });
+
+      expect(readWorkspaceGlobs(dir)).toBeNull();
+    });
+  });
+
+  describe('findWorkspaceRoot', () => {
+    it('walks up from a workspace package', () => {
+      write('pnpm-workspace.yaml', 'packages:\n  - apps/*\n');
+      writePackage('apps/web', { name: 'web' });
+
+      expect(findWorkspaceRoot(join(dir, 'apps/web'))).toBe(dir);
+    });
+  });
+
+  describe('selectWorkspacePackage', () => {
+    beforeEach(() => {
+      write('pnpm-workspace.yaml', 'packages:\n  - apps/*\n  - packages/*\n');
+      writePackage('.', { name: 'root', devDependencies: { turbo: '2.0.0' } });
+    });
+
+    it('selects the package that declares an auth library', () => {
+      writePackage('apps/marketing', { name: 'marketing', dependencies: { next: '15.0.0' } });
+      writePackage('apps/dashboard', { name: 'dashboard', dependencies: { next: '15.0.0', '@clerk/nextjs': '6.0.0' } });
+
+      const selection = selectWorkspacePackage(dir);
+
+      expect(selection?.reason).toBe('auth-library');
+      expect(selection?.candidates.map((c) => c.relativeDir)).toEqual(['apps/dashboard']);
+    });
+
+    it('selects the package that imports a hoisted auth library', () => {
+      writePackage('apps/marketing', { name: 'marketing', dependencies: { next: '15.0.0' } });
+      writePackage('apps/dashboard', { name: 'dashboard', dependencies: { next: '15.0.0' } });
+      write('apps/dashboard/middleware.ts', "import { authMiddleware } from '@clerk/nextjs/server';\n");
+
+      expect(selectWorkspacePackage(dir)?.candidates.map((c) => c.relativeDir)).toEqual(['apps/dashboard']);
+    });
+
+    it('prefers apps over shared libraries when no package uses auth', () => {
+      writePackage('apps/web', { name: 'web', dependencies: { next: '15.0.0' } });
+      writePackage('packages/ui', { name: 'ui', dependencies: { react: '19.0.0' } });
+
+      const selection = selectWorkspacePackage(dir);
+
+      expect(selection?.reason).toBe('framework');
+      expect(selection?.candidates.map((c) => c.relativeDir)).toEqual(['apps/web']);
+    });
+
+    it('returns every candidate when the choice is ambiguous', () => {
+      writePackage('apps/admin', { name: 'admin', dependencies: { next: '15.0.0' } });
+      writePackage('apps/web', { name: 'web', dependencies: { next: '15.0.0' } });
+
+      expect(selectWorkspacePackage(dir)?.candidates.map((c) => c.name)).toEqual(['admin', 'web']);
+    });
+
+    it('returns null when the root is the app', () => {
+      writePackage('.', { name: 'root', workspaces: ['packages/*'], dependencies: { next: '15.0.0' } });
+      writePackage('packages/ui', { name: 'ui', dependencies: { react: '19.0.0' } });
+
+      expect(selectWorkspacePackage(dir)).toBeNull();
+    });
+  });
+});
@@ -0,0 +1,196 @@
+/**
+ * JS monorepo support.
+ *
+ * Reads workspace globs from pnpm-workspace.yaml, package.json `workspaces`
+ * or lerna.json (turbo.json alone implies the conventional apps/* and
+ * packages/* layout) and picks the workspace package the installer should
+ * edit: the one that uses an auth library, else the one with a framework.
+ */
+
+import { existsSync, readFileSync } from 'node:fs';
+import { dirname, join } from 'node:path';
+import fg from 'fast-glob';
+import { IGNORE_PATTERNS } from './constants.js';
+import { readPackageJson, type PackageDotJson } from '../utils/package-json.js';
+import { relativePosix } from '../utils/paths.js';
+
+/** Auth libraries whose importer is the package to migrate (prefix match for scopes) */
+const AUTH_LIBRARIES = [
+  '@workos-inc/',
+  '@auth0/',
+  'next-auth',
+  '@auth/',
+  '@clerk/',
+  '@okta/',
+  '@supabase/auth-helpers-',
+  '@supabase/ssr',
+  'better-auth',
+  'lucia',
+  'passport',
+];
+
+/** Frameworks the installer has integrations for */
+const FRAMEWORK_PACKAGES = ['next', '@tanstack/react-start', 'react-router', '@sveltejs/kit', 'react', 'express'];
+
+const DEFAULT_TURBO_GLOBS = ['apps/*', 'packages/*'];
+const SOURCE_GLOB = '**/*.{ts,tsx,js,jsx,mjs,cjs}';
+const MAX_SOURCE_FILES = 500;
+
+export interface WorkspacePackage {
+  /** Absolute package directory */
+  dir: string;
+  /** Directory relative to the workspace root */
+  relativeDir: string;
+  name?: string;
+  packageJson: PackageDotJson & { name?: string };
+}
+
+export interface WorkspaceSelection {
+  root: string;
+  /** Best candidates, most likely first; more than one means the choice is ambiguous */
+  candidates: WorkspacePackage[];
+  reason: 'auth-library' | 'framework';
+}
+
+function readText(path: string): string | null {
+  try {
+    return readFileSync(path, 'utf-8');
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * Parse the `packages:` list from pnpm-workspace.yaml.
+ */
+export function parsePnpmWorkspace(content: string): string[] {
+  const globs: string[] = [];
+  let inPackages = false;
+  for (const line of content.split(/\r?\n/)) {
+    if (/^packages\s*:/.test(line)) {
+      inPackages = true;
+      continue;
+    }
+    if (inPackages && /^\S/.test(line) && !line.startsWith('#')) break;
+    const item = inPackages ? /^\s*-\s*['"]?([^'"#]+?)['"]?\s*(#.*)?$/.exec(line) : null;
+    if (item) globs.push(item[1]);
+  }
+  return globs;
+}
+
+/**
+ * Workspace globs declared at `root`, or null if it is not a workspace root.
+ */
+export function readWorkspaceGlobs(root: string): string[] | null {
+  const pnpmWorkspace = readText(join(root, 'pnpm-workspace.yaml'));
+  if (pnpmWorkspace !== null) return parsePnpmWorkspace(pnpmWorkspace);
+
+  const packageJson = readText(join(root, 'package.json'));
+  if (packageJson) {
+    try {
+      const { workspaces } = JSON.parse(packageJson) as { workspaces?: string[] | { packages?: string[] } };
+      const globs = Array.isArray(workspaces) ? workspaces : workspaces?.packages;
+      if (globs?.length) return globs;
+    } catch {
+      // Invalid package.json
+    }
+  }
+
+  const lerna = readText(join(root, 'lerna.json'));
+  if (lerna) {
+    try {
+      const { packages } = JSON.parse(lerna) as { packages?: string[] };
+      if (packages?.length) return packages;
+    } catch {
+      // Invalid lerna.json
+    }
+  }
+
+  return existsSync(join(root, 'turbo.json')) ? DEFAULT_TURBO_GLOBS : null;
+}
+
+/**
+ * Walk up from `dir` to the nearest workspace root, stopping at the git root.
+ */
+export function findWorkspaceRoot(dir: string): string | null {
+  let current = dir;
+  for (;;) {
+    if (readWorkspaceGlobs(current)) return current;
+    const parent = dirname(current);
+    if (parent === current || existsSync(join(current, '.git'))) return null;
+    current = parent;
+  }
+}
+
+export function listWorkspacePackages(root: string, globs: string[]): WorkspacePackage[] {
+  const include = globs.filter((g) => !g.startsWith('!')).map((g) => `${g.replace(/\/+$/, '')}/package.json`);
+  const exclude = globs.filter((g) => g.startsWith('!')).map((g) => `${g.slice(1).replace(/\/+$/, '')}/package.json`);
+  const manifests = fg.sync(include, { cwd: root, ignore: ['**/node_modules/**', ...exclude] }).sort();
+
+  return manifests.flatMap((manifest) => {
+    const dir = join(root, dirname(manifest));
+    const packageJson = readPackageJson(dir) as WorkspacePackage['packageJson'] | null;
+    if (!packageJson) return [];
+    return [{ dir, relativeDir: relativePosix(root, dir), name: packageJson.name, packageJson }];
+  });
+}
+
+function dependencyNames(packageJson: PackageDotJson): string[] {
+  return Object.keys({ ...packageJson.dependencies, ...packageJson.devDependencies });
+}
+
+function isAuthLibrary(name: string): boolean {
+  return AUTH_LIBRARIES.some((lib) => (lib.endsWith('/') || lib.endsWith('-') ? name.startsWith(lib) : name === lib));
+}
+
+/**
+ * Whether the package's sources import an auth library. Catches libraries
+ * hoisted to the workspace root instead of declared by the package.
+ */
+function importsAuthLibrary(dir: string): boolean {
+  const files = fg.sync(SOURCE_GLOB, { cwd: dir, ignore: IGNORE_PATTERNS, deep: 6 }).slice(0, MAX_SOURCE_FILES);
+  const importPattern = /(?:from\s+|require\(\s*|import\(\s*)['"]([^'"]+)['"]/g;
+  return files.some((file) => {
+    const content = readText(join(dir, file)) ?? '';
+    return [...content.matchAll(importPattern)].some((m) => isAuthLibrary(m[1]));
+  });
+}
+
+/**
+ * Rank workspace packages by how likely they are the app to install into.
+ */
+export function rankWorkspacePackages(packages: WorkspacePackage[]): Omit<WorkspaceSelection, 'root'> | null {
+  const declaresAuth = packages.filter((p) => dependencyNames(p.packageJson).some(isAuthLibrary));
+  if (declaresAuth.length > 0) return { candidates: declaresAuth, reason: 'auth-library' };
+
+  const frameworkApps = packages.filter((p) =>
+    dependencyNames(p.packageJson).some((dep) => FRAMEWORK_PACKAGES.includes(dep)),
+  );
+  const importsAuth = frameworkApps.filter((p) => importsAuthLibrary(p.dir));
+  if (importsAuth.length > 0) return { candidates: importsAuth, reason: 'auth-library' };
+  if (frameworkApps.length === 0) return null;
+
+  // Apps before shared libraries (packages/ui etc. often depend on react too)
+  const apps = frameworkApps.filter(
+    (p) => p.relativeDir.startsWith('apps/') || Boolean(p.packageJson.scripts?.dev ?? p.packageJson.scripts?.start),
+  );
+  return { candidates: apps.length > 0 ? apps : frameworkApps, reason: 'framework' };
+}
+
+/**
+ * When `installDir` is a workspace root without an app of its own, find the
+ * workspace package(s) the installer should target. Returns null for
+ * single-package projects and roots that are themselves the app.
+ */
+export function selectWorkspacePackage(installDir: string): WorkspaceSelection | null {
+  const globs = readWorkspaceGlobs(installDir);
+  if (!globs) return null;
+
+  const rootPackageJson = readPackageJson(installDir);
+  if (rootPackageJson && dependencyNames(rootPackageJson).some((dep) => FRAMEWORK_PACKAGES.includes(dep))) {
+    return null;
+  }
+
+  const ranked = rankWorkspacePackages(listWorkspacePackages(installDir, globs));
+  return ranked ? { root: installDir, ...ranked } : null;
+}
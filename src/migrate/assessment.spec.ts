@@ -0,0 +1,133 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { assessmentMarkdown, buildAssessment } from './assessment.js';
+import { detectProviders } from './detect.js';
+import type { DetectionResult, MigrationFinding } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+function finding(overrides: Partial<MigrationFinding>): MigrationFinding {
+  return makeFinding({ code: 'auth0-sdk', message: 'Auth0 SDK usage', line: 1, confidence: 0.5, ...overrides });
+}
+
+const AUTH0: DetectionResult = {
+  root: '/project',
+  matches: [
+    {
+      provider: 'auth0',
+      confidence: 0.8,
+      findings: [
+        finding({ line: 3, details: { helper: 'getSession' } }),
+        finding({ file: 'src/api.ts', line: 9, details: { helper: 'getSession' } }),
+        finding({ code: 'auth0-rule', file: 'rules/roles.js', manual: true, message: 'Auth0 Rule runs in the tenant' }),
+        finding({ code: 'auth0-env', file: '.env', details: { envVar: 'AUTH0_CLIENT_ID' } }),
+        finding({ code: 'auth0-env', file: '.env', line: 2, details: { envVar: 'AUTH0_DOMAIN' } }),
+        finding({
+          code: 'auth0-config',
+          line: 12,
+          details: {
+            redirectUri: 'http://localhost:3000/callback',
+            scopes: ['openid', 'read:reports', 'https://www.googleapis.com/auth/drive'],
+          },
+        }),
+        finding({ code: 'auth0-sdk', file: 'test/auth.spec.ts', details: { helper: 'getSession' } }),
+      ],
+    },
+  ],
+};
+
+describe('buildAssessment', () => {
+  it('counts call sites per API and classifies them', () => {
+    const { providers } = buildAssessment(AUTH0);
+    const [auth0] = providers;
+
+    expect(auth0).toMatchObject({ provider: 'auth0', displayName: 'Auth0', supported: true });
+    expect(auth0.apis.map((u) => [u.api, u.classification, u.count])).toEqual([
+      ['https://www.googleapis.com/auth/drive', 'no-equivalent', 1],
+      ['auth0-rule', 'manual', 1],
+      ['getSession', 'auto', 2],
+      ['AUTH0_CLIENT_ID', 'auto', 1],
+      ['AUTH0_DOMAIN', 'auto', 1],
+      ['auth0-config', 'auto', 1],
+    ]);
+    // The test file is counted as a test, not a call site
+    expect(auth0.totals).toEqual({ auto: 5, manual: 1, 'no-equivalent': 1 });
+    expect(auth0.files).toEqual(['.env', 'rules/roles.js', 'src/api.ts', 'src/auth.ts']);
+  });
+
+  it('estimates env vars and dashboard resources from the migration plan', () => {
+    const [auth0] = buildAssessment(AUTH0).providers;
+
+    expect(auth0.env).toEqual({
+      rename: [{ from: 'AUTH0_CLIENT_ID', to: 'WORKOS_CLIENT_ID' }],
+      remove: ['AUTH0_DOMAIN'],
+      review: [],
+      workos: ['WORKOS_API_KEY', 'WORKOS_CLIENT_ID', 'WORKOS_REDIRECT_URI'],
+    });
+    expect(auth0.dashboard).toEqual([
+      'Redirect URI http://localhost:3000/callback',
+      'Roles and permissions for the read:reports scopes',
+    ]);
+
+    const markdown = assessmentMarkdown(buildAssessment(AUTH0, { durationMs: 1200, now: new Date(0) }));
+    expect(markdown).toContain('`workos migrate assess`, 1970-01-01T00:00:00.000Z, scanned in 1.2s');
+    expect(markdown).toContain('| needs manual review | 1 |');
+    expect(markdown).toContain('| `getSession` (auth0-sdk) | 2 | auto-migratable | `src/auth.ts:3`, `src/api.ts:9` |');
+    expect(markdown).toContain('- `AUTH0_CLIENT_ID` becomes `WORKOS_CLIENT_ID`');
+    expect(markdown).toContain('- [ ] Redirect URI http://localhost:3000/callback');
+  });
+
+  it('lists providers without a migration by their call sites alone', () => {
+    const [other] = buildAssessment({
+      root: '/project',
+      matches: [{ provider: 'stytch', confidence: 0.5, findings: [finding({ provider: 'stytch', code: 'stytch-sdk' })] }],
+    }).providers;
+
+    expect(other).toMatchObject({ supported: false, dashboard: [], totals: { auto: 1, manual: 0, 'no-equivalent': 0 } });
+    expect(other.env.workos).toEqual([]);
+  });
+});
+
+describe('assessing a project', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'assessment-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('uses the same detectors as the migration', async () => {
+    write('package.json', JSON.stringify({ dependencies: { next: '15.0.0', '@supabase/supabase-js': '^2.45.0' } }));
+    write(
+      'lib/login.ts',
+      `import { supabase } from '@/lib/supabase';
+
+export async function login() {
+  await supabase.auth.signInWithOAuth({ provider: 'github' });
+  await supabase.auth.updateUser({ data: { plan: 'pro' } });
+}
+
+supabase.auth.onAuthStateChange((event) => console.log(event));
+`,
+    );
+
+    const [assessed] = buildAssessment(await detectProviders(root)).providers;
+
+    expect(assessed.provider).toBe('supabase');
+    const byApi = Object.fromEntries(assessed.apis.map((u) => [u.api, u.classification]));
+    expect(byApi).toMatchObject({
+      signInWithOAuth: 'auto',
+      updateUser: 'manual',
+      onAuthStateChange: 'no-equivalent',
+    });
+  });
+});
@@ -0,0 +1,312 @@
+/**
+ * `workos migrate assess`: how much work a migration would be, before
+ * starting one. Nothing is changed: no branch, no agent, no edits.
+ *
+ * The assessment runs the same detectors and builds the same migration
+ * context as `workos migrate` (detectProviders, then selectMigration for
+ * each detected provider), so what it reports is what the migration would
+ * set out to do. Each call site is counted under the SDK API it uses and
+ * classified as auto-migratable, needing manual review (`manual` findings)
+ * or having no WorkOS equivalent (`noEquivalent` findings and scopes AuthKit
+ * doesn't support). Env vars and dashboard resources come from the context's
+ * env mapping, redirect URIs, scopes, claims and tenancy signals.
+ */
+
+import { selectMigration } from './plan.js';
+import { getProvider } from './providers.js';
+import { terraformChecklist } from './terraform.js';
+import { isTestFile } from './test-references.js';
+import { ANY_PROVIDER, type DetectionResult, type MigrationContext, type MigrationFinding } from './types.js';
+
+export type CallSiteClass = 'auto' | 'manual' | 'no-equivalent';
+
+export const CALL_SITE_CLASSES: CallSiteClass[] = ['auto', 'manual', 'no-equivalent'];
+
+export const CALL_SITE_LABELS: Record<CallSiteClass, string> = {
+  auto: 'auto-migratable',
+  manual: 'needs manual review',
+  'no-equivalent': 'no WorkOS equivalent',
+};
+
+/** Detail keys naming the SDK API a finding is about, most specific first */
+const API_KEYS = ['method', 'helper', 'component', 'trigger', 'claim', 'setting', 'envVar', 'sdk', 'package', 'module'];
+
+/** WorkOS env vars every AuthKit integration sets */
+const BASE_WORKOS_ENV = ['WORKOS_API_KEY', 'WORKOS_CLIENT_ID'];
+
+/** Call sites of one SDK API with the same classification */
+export interface ApiUsage {
+  /** e.g. `getSession`, `<UserButton>`, `AUTH0_DOMAIN`; the finding code when nothing more specific is known */
+  api: string;
+  code: string;
+  classification: CallSiteClass;
+  count: number;
+  /** `file:line`, in scan order */
+  locations: string[];
+  /** What the first call site is, from its finding */
+  description: string;
+  remediation?: string;
+}
+
+export interface ProviderAssessment {
+  provider: string;
+  displayName: string;
+  confidence: number;
+  /** `workos migrate` has a migration for this provider */
+  supported: boolean;
+  apis: ApiUsage[];
+  /** Call sites per classification */
+  totals: Record<CallSiteClass, number>;
+  /** Files with at least one call site */
+  files: string[];
+  env: {
+    /** Provider env vars found, with the WorkOS env var each one becomes */
+    rename: Array<{ from: string; to: string }>;
+    /** Provider env vars found that AuthKit doesn't need */
+    remove: string[];
+    /** Provider env vars found that the migration has no mapping for */
+    review: string[];
+    /** WorkOS env vars the migrated app will need */
+    workos: string[];
+  };
+  /** WorkOS dashboard resources to set up, e.g. `Redirect URI http://localhost:3000/callback` */
+  dashboard: string[];
+  /** Provider usages unrelated to auth, which the migration leaves unchanged */
+  outOfScope: number;
+  templates: number;
+  tests: number;
+}
+
+export interface Assessment {
+  root: string;
+  generatedAt: string;
+  durationMs: number;
+  providers: ProviderAssessment[];
+  /** Warnings from planning each provider, e.g. SDK majors the manifests don't pin */
+  warnings: string[];
+}
+
+export function classifyFinding(finding: MigrationFinding): CallSiteClass {
+  if (finding.noEquivalent) return 'no-equivalent';
+  return finding.manual ? 'manual' : 'auto';
+}
+
+function findingApi(finding: MigrationFinding): string {
+  for (const key of API_KEYS) {
+    const value = finding.details?.[key];
+    if (typeof value === 'string' && value) return value;
+  }
+  return finding.code;
+}
+
+function location(entry: { file: string; line?: number }): string {
+  return entry.line ? `${entry.file}:${entry.line}` : entry.file;
+}
+
+/** Call sites of the provider itself; tests, templates and non-auth usage are counted on their own */
+function callSites(findings: MigrationFinding[]): MigrationFinding[] {
+  return findings.filter((f) => f.provider !== ANY_PROVIDER && !f.outOfScope && !isTestFile(f.file));
+}
+
+function apiUsages(findings: MigrationFinding[], context: MigrationContext | null): ApiUsage[] {
+  const usages = new Map<string, ApiUsage>();
+  for (const finding of callSites(findings)) {
+    const api = findingApi(finding);
+    const classification = classifyFinding(finding);
+    const key = `${finding.code}\0${api}\0${classification}`;
+    const usage = usages.get(key);
+    if (usage) {
+      usage.count++;
+      usage.locations.push(location(finding));
+      continue;
+    }
+    usages.set(key, {
+      api,
+      code: finding.code,
+      classification,
+      count: 1,
+      locations: [location(finding)],
+      description: finding.message,
+      ...(finding.remediation && { remediation: finding.remediation }),
+    });
+  }
+  // A requested scope AuthKit can't grant is a call site with nothing to migrate to
+  for (const scope of context?.scopes ?? []) {
+    if (scope.support !== 'unsupported') continue;
+    usages.set(`scope\0${scope.scope}`, {
+      api: scope.scope,
+      code: 'scope',
+      classification: 'no-equivalent',
+      count: 1,
+      locations: [location(scope)],
+      description: `Requests the ${scope.scope} scope`,
+      ...(scope.note && { remediation: scope.note }),
+    });
+  }
+  // The hardest first: no equivalent, then manual, then the rest by how often they're called
+  const rank = (usage: ApiUsage) => CALL_SITE_CLASSES.indexOf(usage.classification);
+  return [...usages.values()].sort((a, b) => rank(b) - rank(a) || b.count - a.count || a.api.localeCompare(b.api));
+}
+
+function envEstimate(findings: MigrationFinding[], context: MigrationContext | null): ProviderAssessment['env'] {
+  const found = new Set<string>();
+  for (const finding of findings) {
+    const envVar = finding.details?.envVar;
+    if (typeof envVar === 'string') found.add(envVar);
+  }
+  const env: ProviderAssessment['env'] = { rename: [], remove: [], review: [], workos: [] };
+  for (const name of [...found].sort()) {
+    const target = context?.envMapping[name];
+    if (typeof target === 'string') env.rename.push({ from: name, to: target });
+    else if (target === null) env.remove.push(name);
+    else env.review.push(name);
+  }
+  if (context) {
+    const workos = new Set([...BASE_WORKOS_ENV, ...env.rename.map((r) => r.to)]);
+    if ((context.redirectUris ?? []).length > 0) workos.add('WORKOS_REDIRECT_URI');
+    env.workos = [...workos].sort();
+  }
+  return env;
+}
+
+function dashboardEstimate(context: MigrationContext): string[] {
+  const resources = (context.redirectUris ?? []).map((r) => `Redirect URI ${r.uri}`);
+  const returnTo = new Set((context.logoutEndpoints ?? []).flatMap((e) => (e.returnTo ? [e.returnTo] : [])));
+  resources.push(...[...returnTo].map((uri) => `Sign-out redirect ${uri}`));
+  resources.push(...terraformChecklist(context.terraformClients ?? []).filter((item) => !resources.includes(item)));
+  if ((context.tenancy ?? []).length > 0) resources.push("Organizations for the app's tenants");
+  const roleScopes = (context.scopes ?? []).filter((s) => s.support === 'roles' || s.support === 'organization');
+  if (roleScopes.length > 0) {
+    resources.push(`Roles and permissions for the ${roleScopes.map((s) => s.scope).join(', ')} scopes`);
+  }
+  const customClaims = [...new Set((context.claims ?? []).filter((c) => c.authkit === null).map((c) => c.claim))];
+  if (customClaims.length > 0) resources.push(`JWT template for the ${customClaims.join(', ')} claims`);
+  return resources;
+}
+
+/**
+ * Assess every provider in `result`. Providers `workos migrate` supports
+ * are planned with selectMigration, exactly as the migration would plan
+ * them; the others only get their call sites counted.
+ */
+export function buildAssessment(
+  result: DetectionResult,
+  options: { assumedVersions?: Record<string, number>; durationMs?: number; now?: Date } = {},
+): Assessment {
+  const warnings: string[] = [];
+  const providers = result.matches.map((match): ProviderAssessment => {
+    const provider = getProvider(match.provider);
+    let context: MigrationContext | null = null;
+    if (provider) {
+      const selection = selectMigration(result, provider.name, { assumedVersions: options.assumedVersions });
+      context = selection.context;
+      // SDK majors the manifests don't pin are warned about as in the migration
+      warnings.push(...selection.warnings);
+    }
+    const apis = apiUsages(match.findings, context);
+    const totals = { auto: 0, manual: 0, 'no-equivalent': 0 };
+    for (const usage of apis) totals[usage.classification] += usage.count;
+    return {
+      provider: match.provider,
+      displayName: provider?.displayName ?? match.provider,
+      confidence: match.confidence,
+      supported: Boolean(provider),
+      apis,
+      totals,
+      files: [...new Set(callSites(match.findings).map((f) => f.file))].sort(),
+      env: envEstimate(match.findings, context),
+      dashboard: context ? dashboardEstimate(context) : [],
+      outOfScope: match.findings.filter((f) => f.outOfScope).length,
+      templates: (context?.templateReferences ?? []).length,
+      tests: (context?.testReferences ?? []).length,
+    };
+  });
+  return {
+    root: result.root,
+    generatedAt: (options.now ?? new Date()).toISOString(),
+    durationMs: options.durationMs ?? 0,
+    providers,
+    warnings: [...new Set(warnings)],
+  };
+}
+
+/** Enough locations per API to find them; the JSON report has all of them */
+const LOCATION_LIMIT = 5;
+
+function cell(text: string): string {
+  return text.replace(/\|/g, '\\|');
+}
+
+function providerMarkdown(assessment: ProviderAssessment): string[] {
+  const confidence = `${Math.round(assessment.confidence * 100)}%`;
+  const lines = [`## ${assessment.displayName}`, '', `Detected with ${confidence} confidence.`];
+  if (!assessment.supported) {
+    lines.push('', '`workos migrate` has no migration for this provider yet; its call sites are listed for reference.');
+  }
+
+  lines.push('', '| Classification | Call sites |', '| --- | --- |');
+  lines.push(...CALL_SITE_CLASSES.map((c) => `| ${CALL_SITE_LABELS[c]} | ${assessment.totals[c]} |`));
+  const files = assessment.files.length;
+  lines.push('', `Across ${files} ${files === 1 ? 'file' : 'files'}.`);
+
+  if (assessment.apis.length > 0) {
+    lines.push(
+      '',
+      '### Call sites by API',
+      '',
+      '| API | Call sites | Classification | Where |',
+      '| --- | --- | --- | --- |',
+    );
+    for (const usage of assessment.apis) {
+      const shown = usage.locations.slice(0, LOCATION_LIMIT).map((l) => `\`${l}\``);
+      if (usage.locations.length > LOCATION_LIMIT) shown.push(`and ${usage.locations.length - LOCATION_LIMIT} more`);
+      const api = `\`${cell(usage.api)}\`${usage.api === usage.code ? '' : ` (${usage.code})`}`;
+      lines.push(`| ${api} | ${usage.count} | ${CALL_SITE_LABELS[usage.classification]} | ${shown.join(', ')} |`);
+    }
+    const open = assessment.apis.filter((u) => u.classification !== 'auto' && u.remediation);
+    if (open.length > 0) {
+      lines.push('', 'What the manual and unsupported APIs need:', '');
+      lines.push(...open.map((u) => `- \`${u.api}\`: ${u.remediation}`));
+    }
+  }
+
+  const { env } = assessment;
+  const needed = env.workos.map((name) => `\`${name}\``).join(', ');
+  const envLines = [
+    ...env.rename.map(({ from, to }) => `- \`${from}\` becomes \`${to}\``),
+    ...env.remove.map((name) => `- \`${name}\` is no longer needed`),
+    ...env.review.map((name) => `- \`${name}\` has no mapping; check what reads it`),
+    ...(needed ? [`- The migrated app needs ${needed}`] : []),
+  ];
+  if (envLines.length > 0) lines.push('', '### Environment variables', '', ...envLines);
+  if (assessment.dashboard.length > 0) {
+    lines.push('', '### WorkOS dashboard', '', ...assessment.dashboard.map((r) => `- [ ] ${r}`));
+  }
+
+  const { templates, tests, outOfScope } = assessment;
+  const also = [
+    ...(templates > 0 ? [`- ${templates} sign-in references in templates to repoint`] : []),
+    ...(tests > 0 ? [`- ${tests} test files referencing ${assessment.displayName} to update`] : []),
+    ...(outOfScope > 0 ? [`- ${outOfScope} non-auth usages, left unchanged`] : []),
+  ];
+  if (also.length > 0) lines.push('', '### Also in the project', '', ...also);
+  return lines;
+}
+
+/** The assessment as a standalone markdown document */
+export function assessmentMarkdown(assessment: Assessment): string {
+  const seconds = (assessment.durationMs / 1000).toFixed(1);
+  const lines = [
+    '# WorkOS migration assessment',
+    '',
+    `<sub>\`workos migrate assess\`, ${assessment.generatedAt}, scanned in ${seconds}s</sub>`,
+  ];
+  if (assessment.providers.length === 0) {
+    lines.push('', 'No other auth providers detected.');
+  }
+  for (const provider of assessment.providers) lines.push('', ...providerMarkdown(provider));
+  if (assessment.warnings.length > 0) {
+    lines.push('', '## Warnings', '', ...assessment.warnings.map((w) => `- ${w}`));
+  }
+  return `${lines.join('\n')}\n`;
+}
@@ -0,0 +1,208 @@
+/**
+ * Env vars set in container config rather than env files: the
+ * `environment:` and `env_file:` sections of compose files, and `ENV`/`ARG`
+ * lines in Dockerfiles.
+ *
+ * Compose files are read line by line instead of through a YAML parser, so
+ * renaming a key leaves comments, anchors and formatting exactly as written.
+ * `environment:` takes both forms, a map (`AUTH0_DOMAIN: example.auth0.com`)
+ * and a list (`- AUTH0_DOMAIN=example.auth0.com`, or a bare `- AUTH0_DOMAIN`
+ * passed through from the host).
+ */
+
+import { dirname, posix } from 'node:path';
+import { isEnvReference, type EnvKeyRename } from '../utils/env-parser.js';
+import type { ContainerEnvFile, MigrationFinding } from './types.js';
+
+/** docker-compose.yml, docker-compose.prod.yaml, compose.yml, ... */
+export const COMPOSE_FILE_PATTERN = /(^|\/)(docker-)?compose(\.[\w-]+)*\.ya?ml$/;
+/** Dockerfile, Dockerfile.prod, api.Dockerfile */
+export const DOCKERFILE_PATTERN = /(^|\/)(Dockerfile(\.[\w-]+)?|[\w.-]+\.Dockerfile)$/;
+
+export type ContainerEnvSource = ContainerEnvFile['source'];
+
+export interface ContainerEnvVar {
+  name: string;
+  line: number;
+  /** Raw value as written, without list quoting; absent for a bare name passed through from the host */
+  value?: string;
+}
+
+const ENV_NAME = '[A-Za-z_][A-Za-z0-9_]*';
+
+/** `  - KEY=value`, `  - "KEY=value"`, `  - KEY` */
+const LIST_ENTRY_PATTERN = new RegExp(`^(\\s*-\\s*["']?)(${ENV_NAME})(?:(=)(.*?))?(["']?\\s*)$`);
+/** `  KEY: value`, `  "KEY": value` */
+const MAP_ENTRY_PATTERN = new RegExp(`^(\\s*["']?)(${ENV_NAME})(["']?\\s*:)(.*)$`);
+/** `  env_file: ./auth.env`, `  - ./auth.env`, `  - path: ./auth.env` */
+const ENV_FILE_KEY_PATTERN = /^(\s*)env_file\s*:\s*(.*)$/;
+
+/** `ENV KEY=value KEY2=value2`, `ENV KEY value`, `ARG KEY`, `ARG KEY=default` */
+const DOCKERFILE_ENV_PATTERN = /^(\s*(ENV|ARG)\s+)(.*)$/i;
+
+function indentOf(line: string): number {
+  return line.length - line.trimStart().length;
+}
+
+function unquote(value: string): string {
+  const trimmed = value.trim();
+  return /^(["']).*\1$/.test(trimmed) ? trimmed.slice(1, -1) : trimmed;
+}
+
+function isBlank(line: string): boolean {
+  const trimmed = line.trim();
+  return trimmed === '' || trimmed.startsWith('#');
+}
+
+/**
+ * Visit each entry of every block under `key:`; the callback gets the line
+ * index. A block ends at the first non-blank line indented no deeper than
+ * the key.
+ */
+function forEachBlockLine(lines: string[], key: string, visit: (index: number) => void): void {
+  const keyPattern = new RegExp(`^(\\s*)${key}\\s*:\\s*(#.*)?$`);
+  for (let i = 0; i < lines.length; i++) {
+    const match = keyPattern.exec(lines[i]);
+    if (!match) continue;
+    const keyIndent = match[1].length;
+    for (let j = i + 1; j < lines.length; j++) {
+      if (isBlank(lines[j])) continue;
+      if (indentOf(lines[j]) <= keyIndent && !lines[j].trimStart().startsWith('-')) break;
+      if (indentOf(lines[j]) < keyIndent) break;
+      visit(j);
+      i = j;
+    }
+  }
+}
+
+/** The vars a compose file sets and the env files its services load */
+export function parseComposeEnv(content: string): { vars: ContainerEnvVar[]; envFiles: string[] } {
+  const lines = content.split('\n');
+  const vars: ContainerEnvVar[] = [];
+  const envFiles: string[] = [];
+
+  forEachBlockLine(lines, 'environment', (index) => {
+    const line = lines[index];
+    const entry = LIST_ENTRY_PATTERN.exec(line) ?? MAP_ENTRY_PATTERN.exec(line);
+    if (!entry) return;
+    const value = entry[0].trimStart().startsWith('-') ? entry[4] : unquote(entry[4]);
+    vars.push({ name: entry[2], line: index + 1, ...(value !== undefined && value !== '' && { value }) });
+  });
+
+  for (const [index, line] of lines.entries()) {
+    const match = ENV_FILE_KEY_PATTERN.exec(line);
+    if (!match) continue;
+    const inline = match[2].replace(/\s+#.*$/, '').trim();
+    if (inline.startsWith('[')) {
+      envFiles.push(...inline.slice(1, -1).split(',').map(unquote).filter(Boolean));
+    } else if (inline) {
+      envFiles.push(unquote(inline));
+    } else {
+      for (let j = index + 1; j < lines.length; j++) {
+        if (isBlank(lines[j])) continue;
+        const item = /^\s*-\s*(?:path\s*:\s*)?(.+?)\s*(#.*)?$/.exec(lines[j]);
+        if (indentOf(lines[j]) < match[1].length || (!item && indentOf(lines[j]) === match[1].length)) break;
+        // `required: false` and the like under a `- path:` entry
+        if (item) envFiles.push(unquote(item[1]));
+      }
+    }
+  }
+  return { vars, envFiles };
+}
+
+/** The vars a Dockerfile sets with `ENV` or declares with `ARG` */
+export function parseDockerfileEnv(content: string): ContainerEnvVar[] {
+  const vars: ContainerEnvVar[] = [];
+  for (const [index, line] of content.split('\n').entries()) {
+    const match = DOCKERFILE_ENV_PATTERN.exec(line);
+    if (!match) continue;
+    // Legacy `ENV KEY value` form: one var, the rest of the line is its value
+    const legacy = match[2].toUpperCase() === 'ENV' && new RegExp(`^${ENV_NAME}\\s+[^=]*$`).test(match[3].trim());
+    if (legacy) {
+      const [name, ...value] = match[3].trim().split(/\s+/);
+      vars.push({ name, line: index + 1, value: value.join(' ') });
+      continue;
+    }
+    for (const pair of match[3].matchAll(new RegExp(`(?:^|\\s)(${ENV_NAME})(?:=(\\S*))?`, 'g'))) {
+      vars.push({ name: pair[1], line: index + 1, ...(pair[2] && { value: pair[2] }) });
+    }
+  }
+  return vars;
+}
+
+/**
+ * An env_file entry relative to the project root. Compose resolves it
+ * against the compose file's directory; null when it points outside the
+ * project.
+ */
+export function resolveEnvFile(composeFile: string, envFile: string): string | null {
+  const resolved = posix.normalize(posix.join(dirname(composeFile), envFile));
+  return resolved.startsWith('..') || posix.isAbsolute(resolved) ? null : resolved;
+}
+
+/**
+ * Rename keys in a compose file's `environment:` blocks, leaving values and
+ * everything outside those blocks as written. Like renameEnvKeys, a key
+ * whose new name is already set is left alone.
+ */
+export function renameComposeEnvKeys(
+  content: string,
+  mapping: Record<string, string | null>,
+): { content: string; renamed: EnvKeyRename[] } {
+  const lines = content.split('\n');
+  const entries = new Map<number, RegExpExecArray>();
+  forEachBlockLine(lines, 'environment', (index) => {
+    const entry = LIST_ENTRY_PATTERN.exec(lines[index]) ?? MAP_ENTRY_PATTERN.exec(lines[index]);
+    if (entry) entries.set(index, entry);
+  });
+
+  const present = new Set([...entries.values()].map((entry) => entry[2]));
+  const renamed: EnvKeyRename[] = [];
+  const output = lines.map((line, index) => {
+    const entry = entries.get(index);
+    if (!entry) return line;
+    const [, prefix, key] = entry;
+    const to = mapping[key];
+    if (!to || to === key || present.has(to)) return line;
+    present.add(to);
+    renamed.push({ from: key, to, reference: isEnvReference(entry[4] ?? '') });
+    return `${prefix}${to}${line.slice(prefix.length + key.length)}`;
+  });
+  return { content: output.join('\n'), renamed };
+}
+
+/** Rename keys on a Dockerfile's `ENV` and `ARG` lines, values as written */
+export function renameDockerfileEnvKeys(
+  content: string,
+  mapping: Record<string, string | null>,
+): { content: string; renamed: EnvKeyRename[] } {
+  const lines = content.split('\n');
+  const present = new Set(parseDockerfileEnv(content).map((v) => v.name));
+  const renamed: EnvKeyRename[] = [];
+  const output = lines.map((line) => {
+    const match = DOCKERFILE_ENV_PATTERN.exec(line);
+    if (!match) return line;
+    let args = match[3];
+    for (const { name, value } of parseDockerfileEnv(line)) {
+      const to = mapping[name];
+      if (!to || to === name || present.has(to)) continue;
+      present.add(to);
+      renamed.push({ from: name, to, reference: isEnvReference(value ?? '') });
+      // Only a name at the start of an argument: `KEY=...`, `KEY value` or a bare `KEY`
+      args = args.replace(new RegExp(`(^|\\s)${name}(?==|\\s|$)`), `$1${to}`);
+    }
+    return `${match[1]}${args}`;
+  });
+  return { content: output.join('\n'), renamed };
+}
+
+/** Container config files the findings point at (`details.container`), one entry per file */
+export function extractContainerEnvFiles(findings: MigrationFinding[]): ContainerEnvFile[] {
+  const files = new Map<string, ContainerEnvFile>();
+  for (const finding of findings) {
+    const source = finding.details?.container;
+    if (source !== 'compose' && source !== 'dockerfile' && source !== 'env_file') continue;
+    if (!files.has(finding.file)) files.set(finding.file, { file: finding.file, source });
+  }
+  return [...files.values()];
+}
@@ -0,0 +1,311 @@
+import { describe, it, expect, vi } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import {
+  applyConfidenceThreshold,
+  combineConfidence,
+  countFindings,
+  detectProviders,
+  groupByProvider,
+} from './detect.js';
+import type { MigrationFinding, ProviderDetector } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+vi.mock('../utils/debug.js', () => ({
+  logWarn: vi.fn(),
+}));
+
+function finding(provider: string, confidence: number): MigrationFinding {
+  return makeFinding({ provider, file: 'a.ts', confidence });
+}
+
+describe('detect', () => {
+  describe('combineConfidence', () => {
+    it('combines independent findings without reaching certainty', () => {
+      expect(combineConfidence([finding('auth0', 0.5), finding('auth0', 0.5)])).toBe(0.75);
+      expect(combineConfidence([finding('auth0', 0.3)])).toBe(0.3);
+    });
+  });
+
+  describe('groupByProvider', () => {
+    it('ranks providers by combined confidence', () => {
+      const matches = groupByProvider([finding('clerk', 0.2), finding('auth0', 0.6), finding('clerk', 0.1)]);
+
+      expect(matches.map((m) => m.provider)).toEqual(['auth0', 'clerk']);
+      expect(matches[1].findings).toHaveLength(2);
+    });
+
+    it('lists findings for any provider under every match', () => {
+      const tenant = { ...finding('*', 0), code: 'tenant-claim' };
+      const matches = groupByProvider([finding('auth0', 0.5), tenant, finding('clerk', 0.2)]);
+
+      expect(matches.map((m) => [m.provider, m.confidence, m.findings.at(-1)?.code])).toEqual([
+        ['auth0', 0.5, 'tenant-claim'],
+        ['clerk', 0.2, 'tenant-claim'],
+      ]);
+      expect(groupByProvider([tenant])).toEqual([]);
+    });
+  });
+
+  describe('applyConfidenceThreshold', () => {
+    const result = {
+      root: '/project',
+      matches: groupByProvider([finding('auth0', 0.6), finding('oidc', 0.2)]),
+    };
+
+    it('drops matches below the threshold and counts them', () => {
+      const filtered = applyConfidenceThreshold(result, 0.35);
+
+      expect(filtered.matches.map((m) => m.provider)).toEqual(['auth0']);
+      expect(filtered.suppressed).toBe(1);
+      expect(filtered.minConfidence).toBe(0.35);
+    });
+
+    it('keeps and flags weak matches with includeAll', () => {
+      const filtered = applyConfidenceThreshold(result, 0.35, { includeAll: true });
+
+      expect(filtered.matches.map((m) => [m.provider, m.belowThreshold])).toEqual([
+        ['auth0', undefined],
+        ['oidc', true],
+      ]);
+      expect(filtered.matches[1].findings[0].confidence).toBe(0.2);
+      expect(filtered.suppressed).toBe(0);
+    });
+  });
+
+  describe('countFindings', () => {
+    it('counts auth findings per provider above the threshold', () => {
+      const tenant = { ...finding('*', 0), code: 'tenant-claim' };
+      const query = { ...finding('supabase', 0.6), outOfScope: true };
+      const result = applyConfidenceThreshold(
+        {
+          root: '/project',
+          matches: groupByProvider([
+            finding('auth0', 0.6),
+            finding('auth0', 0.3),
+            finding('supabase', 0.5),
+            query,
+            tenant,
+            finding('oidc', 0.2),
+          ]),
+        },
+        0.35,
+        { includeAll: true },
+      );
+
+      expect(countFindings(result)).toEqual({ providers: { auth0: 2, supabase: 1 }, total: 3 });
+    });
+  });
+
+  describe('detectProviders', () => {
+    it('stops once until holds and marks the result partial', async () => {
+      const ran: string[] = [];
+      const detector = (name: string): ProviderDetector => ({
+        name,
+        description: name,
+        language: 'javascript',
+        files: /\.ts$/,
+        detect: async () => {
+          ran.push(name);
+          return [finding('clerk', 0.5)];
+        },
+      });
+
+      const result = await detectProviders('/tmp', [detector('a'), detector('b'), detector('c')], {
+        until: (partial) => countFindings(partial).total > 1,
+      });
+
+      expect(ran).toEqual(['a', 'b']);
+      expect(result.partial).toBe(true);
+      expect(countFindings(result).total).toBe(2);
+    });
+
+    it('keeps going when a detector throws', async () => {
+      const broken: ProviderDetector = {
+        name: 'broken',
+        description: 'broken',
+        language: 'javascript',
+        files: /\.ts$/,
+        detect: async () => {
+          throw new Error('boom');
+        },
+      };
+      const working: ProviderDetector = {
+        name: 'working',
+        description: 'working',
+        language: 'javascript',
+        files: /\.ts$/,
+        detect: async () => [finding('auth0', 0.5)],
+      };
+
+      const result = await detectProviders('/tmp', [broken, working]);
+
+      expect(result.matches.map((m) => m.provider)).toEqual(['auth0']);
+    });
+
+    describe('with since', () => {
+      /** Reports one finding per listed file */
+      const perFile: ProviderDetector = {
+        name: 'per-file',
+        description: 'per-file',
+        language: 'javascript',
+        files: /\.ts$/,
+        detect: async (ctx) => (await ctx.files()).map((file) => ({ ...finding('clerk', 0.5), file })),
+      };
+      const php = { ...perFile, name: 'php', files: /\.php$/, detect: vi.fn(async () => []) };
+
+      function withRepo(run: (root: string) => Promise<void>) {
+        return async () => {
+          const root = mkdtempSync(join(tmpdir(), 'detect-since-'));
+          const git = (...args: string[]) => execFileSync('git', args, { cwd: root, stdio: 'ignore' });
+          try {
+            git('init', '-q');
+            writeFileSync(join(root, 'old.ts'), 'old');
+            writeFileSync(join(root, 'README.md'), 'readme');
+            git('add', '.');
+            git('-c', 'user.name=test', '-c', 'user.email=test@example.com', 'commit', '-qm', 'init');
+            await run(root);
+          } finally {
+            rmSync(root, { recursive: true, force: true });
+          }
+        };
+      }
+
+      it(
+        'only reports files changed since the ref',
+        withRepo(async (root) => {
+          writeFileSync(join(root, 'new.ts'), 'new');
+          writeFileSync(join(root, 'README.md'), 'changed');
+
+          const result = await detectProviders(root, [perFile, php], { since: 'HEAD' });
+
+          expect(result.since).toEqual({ ref: 'HEAD', files: ['new.ts'] });
+          expect(result.matches[0].findings.map((f) => f.file)).toEqual(['new.ts']);
+          expect(php.detect).not.toHaveBeenCalled();
+        }),
+      );
+
+      it(
+        'is a no-op when no relevant files changed',
+        withRepo(async (root) => {
+          writeFileSync(join(root, 'README.md'), 'changed');
+
+          const result = await detectProviders(root, [perFile], { since: 'HEAD' });
+
+          expect(result).toMatchObject({ matches: [], since: { ref: 'HEAD', files: [] } });
+        }),
+      );
+
+      it(
+        'fails on an unknown ref',
+        withRepo(async (root) => {
+          await expect(detectProviders(root, [perFile], { since: 'no-such-ref' })).rejects.toThrow(
+            'Could not diff against no-such-ref',
+          );
+        }),
+      );
+    });
+
+    describe('with include and exclude', () => {
+      it('walks the include globs, then drops excluded paths', async () => {
+        const root = mkdtempSync(join(tmpdir(), 'detect-paths-'));
+        try {
+          for (const file of ['services/auth/login.ts', 'services/auth/legacy/old.ts', 'web/app.ts']) {
+            mkdirSync(join(root, file, '..'), { recursive: true });
+            writeFileSync(join(root, file), 'auth');
+          }
+          writeFileSync(join(root, 'package.json'), '{}');
+          const perFile: ProviderDetector = {
+            name: 'per-file',
+            description: 'per-file',
+            language: 'javascript',
+            files: /\.ts$/,
+            detect: async (ctx) => [
+              ...(await ctx.files()).map((file) => ({ ...finding('clerk', 0.5), file })),
+              // Manifests outside the walk can be read, but aren't reported
+              ...((await ctx.readFile('package.json')) ? [{ ...finding('clerk', 0.5), file: 'package.json' }] : []),
+            ],
+          };
+
+          const result = await detectProviders(root, [perFile], {
+            include: ['./services/auth/**'],
+            exclude: ['services/auth/legacy/**'],
+          });
+
+          expect(result.matches[0].findings.map((f) => f.file)).toEqual(['services/auth/login.ts']);
+          expect(result.paths).toEqual({
+            include: ['./services/auth/**'],
+            exclude: ['services/auth/legacy/**'],
+            files: 1,
+          });
+        } finally {
+          rmSync(root, { recursive: true, force: true });
+        }
+      });
+    });
+
+    describe('with a .workosignore', () => {
+      it('drops findings in ignored files, even ones read directly, and records the rule', async () => {
+        const root = mkdtempSync(join(tmpdir(), 'detect-ignore-'));
+        try {
+          mkdirSync(join(root, 'third_party'));
+          writeFileSync(join(root, 'app.ts'), 'auth');
+          writeFileSync(join(root, 'third_party/sdk.ts'), 'auth');
+          writeFileSync(join(root, '.workosignore'), 'third_party/\n');
+          const perFile: ProviderDetector = {
+            name: 'per-file',
+            description: 'per-file',
+            language: 'javascript',
+            files: /\.ts$/,
+            detect: async (ctx) => [
+              ...(await ctx.files())
+                .filter((f) => f.endsWith('.ts'))
+                .map((file) => ({ ...finding('clerk', 0.5), file })),
+              { ...finding('clerk', 0.5), file: 'third_party/sdk.ts' },
+            ],
+          };
+
+          const result = await detectProviders(root, [perFile]);
+
+          expect(result.matches[0].findings.map((f) => f.file)).toEqual(['app.ts']);
+          expect(result.ignored).toEqual([
+            { file: 'third_party/sdk.ts', rule: { source: '.workosignore', line: 1, pattern: 'third_party/' } },
+          ]);
+        } finally {
+          rmSync(root, { recursive: true, force: true });
+        }
+      });
+    });
+
+    describe('with a file list', () => {
+      it('scans only the listed files a detector looks at, without walking', async () => {
+        const root = mkdtempSync(join(tmpdir(), 'detect-files-'));
+        try {
+          for (const file of ['a.ts', 'b.ts', 'notes.md']) writeFileSync(join(root, file), 'auth');
+          const perFile: ProviderDetector = {
+            name: 'per-file',
+            description: 'per-file',
+            language: 'javascript',
+            files: /\.ts$/,
+            detect: async (ctx) => (await ctx.files()).map((file) => ({ ...finding('clerk', 0.5), file })),
+          };
+
+          const result = await detectProviders(root, [perFile], { files: ['./a.ts', 'notes.md', 'deleted.ts'] });
+
+          expect(result.listed).toEqual({ count: 3, files: ['a.ts'] });
+          expect(result.matches[0].findings.map((f) => f.file)).toEqual(['a.ts']);
+        } finally {
+          rmSync(root, { recursive: true, force: true });
+        }
+      });
+
+      it('refuses to combine with --since', async () => {
+        await expect(detectProviders('/tmp', [], { files: ['a.ts'], since: 'HEAD' })).rejects.toThrow(
+          '--files-from cannot be combined',
+        );
+      });
+    });
+  });
+});
@@ -0,0 +1,197 @@
+import { logWarn } from '../utils/debug.js';
+import { DETECTORS } from './detectors/index.js';
+import { terraform } from './detectors/terraform.js';
+import { strongestEvidence } from './evidence.js';
+import { loadIgnoreRules } from './ignore-rules.js';
+import type { OidcDiscoveryClient } from './oidc-discovery.js';
+import { changedFilesSince, createScanContext, resolveListedFiles } from './scan.js';
+import {
+  ANY_PROVIDER,
+  type DetectionResult,
+  type MigrationFinding,
+  type ProviderDetector,
+  type ProviderMatch,
+} from './types.js';
+
+/**
+ * Default --min-confidence. A single weak finding (a lone dependency, a
+ * generic OIDC registration) stays below it; two corroborating ones don't.
+ */
+export const DEFAULT_MIN_CONFIDENCE = 0.35;
+
+/**
+ * Combine independent pieces of evidence: 1 - Π(1 - c).
+ * Many weak findings add up, but never reach certainty on their own.
+ */
+export function combineConfidence(findings: MigrationFinding[]): number {
+  const miss = findings.reduce((acc, f) => acc * (1 - Math.min(Math.max(f.confidence, 0), 1)), 1);
+  return Math.round((1 - miss) * 100) / 100;
+}
+
+/**
+ * One match per provider, most confident first. ANY_PROVIDER findings are
+ * added to every match without counting toward its confidence, and are
+ * dropped when nothing else matched.
+ */
+export function groupByProvider(findings: MigrationFinding[]): ProviderMatch[] {
+  const byProvider = new Map<string, MigrationFinding[]>();
+  const shared = findings.filter((f) => f.provider === ANY_PROVIDER);
+  for (const finding of findings) {
+    if (finding.provider === ANY_PROVIDER) continue;
+    const list = byProvider.get(finding.provider) ?? [];
+    list.push(finding);
+    byProvider.set(finding.provider, list);
+  }
+
+  return [...byProvider.entries()]
+    .map(([provider, list]) => ({
+      provider,
+      confidence: combineConfidence(list),
+      findings: [...list, ...shared],
+      evidence: strongestEvidence(list),
+    }))
+    .sort((a, b) => b.confidence - a.confidence || a.provider.localeCompare(b.provider));
+}
+
+export interface DetectProvidersOptions {
+  /** Only report findings in files changed since this git ref */
+  since?: string;
+  /** Scan only these paths (relative to the repository root) instead of walking the project */
+  files?: string[];
+  /** Restrict the walk to paths matching these globs */
+  include?: string[];
+  /** Skip paths matching these globs, applied after include */
+  exclude?: string[];
+  /** Check generic OIDC issuers against their discovery documents */
+  discovery?: OidcDiscoveryClient;
+  /** Skip files larger than this many bytes */
+  maxFileSize?: number;
+  /** Also run the terraform detector over the project's .tf files */
+  terraform?: boolean;
+  /**
+   * Stop running detectors once this holds for what's been found so far.
+   * It must stay true as findings are added, like a count over a limit;
+   * the result is then marked `partial`.
+   */
+  until?: (result: DetectionResult) => boolean;
+}
+
+/**
+ * Scan a project for other auth providers and rank them by confidence.
+ */
+export async function detectProviders(
+  root: string,
+  detectors: ProviderDetector[] = DETECTORS,
+  options: DetectProvidersOptions = {},
+): Promise<DetectionResult> {
+  if (options.files && (options.since || options.include?.length || options.exclude?.length)) {
+    throw new Error('--files-from cannot be combined with --since, --include or --exclude.');
+  }
+  // Reading every .tf file is opt-in (--scan-terraform)
+  if (options.terraform) detectors = [...detectors, terraform];
+  // Only files some detector looks at; none means nothing to scan
+  const isScanned = (file: string) => detectors.some((d) => d.files.test(file));
+  let since: DetectionResult['since'];
+  if (options.since) {
+    const changed = (await changedFilesSince(root, options.since)).filter(isScanned);
+    detectors = detectors.filter((d) => changed.some((f) => d.files.test(f)));
+    since = { ref: options.since, files: changed };
+  }
+  let listed: DetectionResult['listed'];
+  if (options.files) {
+    const files = resolveListedFiles(root, options.files).filter(isScanned);
+    detectors = detectors.filter((d) => files.some((f) => d.files.test(f)));
+    listed = { count: options.files.length, files };
+  }
+
+  const { include = [], exclude = [] } = options;
+  const ctx = createScanContext(root, {
+    files: listed?.files,
+    only: since?.files,
+    include,
+    exclude,
+    ignoreRules: loadIgnoreRules(root),
+    discovery: options.discovery,
+    maxFileSize: options.maxFileSize,
+  });
+  const findings: MigrationFinding[] = [];
+
+  const report = async (): Promise<DetectionResult> => {
+    const walked = new Set(await ctx.files());
+    const scanSkipped = ctx.skipped();
+    const skipped = scanSkipped.size.length > 0 || scanSkipped.binary.length > 0 ? { skipped: scanSkipped } : {};
+    const ignored = ctx.ignored();
+    const ignoredFiles = new Set(ignored.map((i) => i.file));
+    const ignoredResult = ignored.length > 0 ? { ignored } : {};
+
+    const filtered = include.length > 0 || exclude.length > 0;
+    if (!since && !listed && !filtered) {
+      // Detectors read some files directly; ignored ones don't count
+      const kept = findings.filter((f) => !ignoredFiles.has(f.file));
+      return { root, matches: groupByProvider(kept), ...skipped, ...ignoredResult };
+    }
+
+    // Detectors still read unchanged manifests and files outside the walk for context;
+    // only findings in the scanned files are reported
+    const inDiff = new Set(since?.files);
+    const reported = findings.filter(
+      (f) =>
+        (!since || inDiff.has(f.file)) &&
+        ((!filtered && !listed) || walked.has(f.file)) &&
+        !ignoredFiles.has(f.file),
+    );
+    return {
+      root,
+      matches: groupByProvider(reported),
+      ...(since ? { since } : {}),
+      ...(listed ? { listed } : {}),
+      ...(filtered ? { paths: { include, exclude, files: walked.size } } : {}),
+      ...skipped,
+      ...ignoredResult,
+    };
+  };
+
+  for (const [index, detector] of detectors.entries()) {
+    try {
+      findings.push(...(await detector.detect(ctx)));
+    } catch (error) {
+      logWarn(`Detector ${detector.name} failed:`, error);
+    }
+    // Later detectors only add findings, so a count already over the limit stays over it
+    if (options.until && index < detectors.length - 1) {
+      const result = await report();
+      if (options.until(result)) return { ...result, partial: true };
+    }
+  }
+
+  return report();
+}
+
+/** Findings per provider with a match above the threshold, leaving out shared and non-auth findings */
+export function countFindings(result: DetectionResult): { providers: Record<string, number>; total: number } {
+  const providers: Record<string, number> = {};
+  for (const match of result.matches.filter((m) => !m.belowThreshold)) {
+    providers[match.provider] = match.findings.filter((f) => f.provider !== ANY_PROVIDER && !f.outOfScope).length;
+  }
+  return { providers, total: Object.values(providers).reduce((sum, count) => sum + count, 0) };
+}
+
+/**
+ * Drop provider matches whose combined confidence is below `minConfidence`.
+ * With `includeAll`, weak matches are kept but flagged, so tooling reading
+ * the JSON output can apply its own threshold.
+ */
+export function applyConfidenceThreshold(
+  result: DetectionResult,
+  minConfidence: number,
+  options: { includeAll?: boolean } = {},
+): DetectionResult {
+  const isWeak = (match: ProviderMatch) => match.confidence < minConfidence;
+  const suppressed = result.matches.filter(isWeak).length;
+
+  const matches = options.includeAll
+    ? result.matches.map((match) => (isWeak(match) ? { ...match, belowThreshold: true } : match))
+    : result.matches.filter((match) => !isWeak(match));
+
+  return { ...result, matches, minConfidence, suppressed: options.includeAll ? 0 : suppressed };
+}
@@ -0,0 +1,95 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { auth0Actions, auth0ActionsWarning, customClaimKeys } from './auth0-actions.js';
+
+const PACKAGE_JSON = JSON.stringify({ dependencies: { next: '15.0.0', '@auth0/nextjs-auth0': '^3.5.0' } }, null, 2);
+
+const POST_LOGIN_ACTION = `exports.onExecutePostLogin = async (event, api) => {
+  const namespace = 'https://myapp.example.com';
+  api.idToken.setCustomClaim('https://myapp.example.com/roles', event.authorization.roles);
+};
+`;
+
+const RULE = `function addTenant(user, context, callback) {
+  context.idToken['https://myapp.example.com/tenant'] = user.app_metadata.tenant;
+  callback(null, user, context);
+}
+`;
+
+const ROLES = `import { getSession } from '@auth0/nextjs-auth0';
+
+export async function roles() {
+  const session = await getSession();
+  return session.user['https://myapp.example.com/roles'] ?? [];
+}
+`;
+
+describe('auth0-actions detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'auth0-actions-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('returns nothing for projects without Auth0', async () => {
+    write('package.json', JSON.stringify({ dependencies: { next: '15.0.0' } }));
+    write('lib/claims.ts', `export const roles = (user) => user['https://myapp.example.com/roles'];\n`);
+
+    expect(await auth0Actions.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('finds Actions, Rules and the custom claims they set', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('auth0/actions/post-login.js', POST_LOGIN_ACTION);
+    write('auth0/rules/add-tenant.js', RULE);
+    write('lib/roles.ts', ROLES);
+
+    const findings = await auth0Actions.detect(createScanContext(root));
+
+    expect(findings.find((f) => f.code === 'auth0-action')).toMatchObject({
+      file: 'auth0/actions/post-login.js',
+      line: 1,
+      details: { trigger: 'onExecutePostLogin' },
+    });
+    expect(findings.find((f) => f.code === 'auth0-rule')).toMatchObject({ file: 'auth0/rules/add-tenant.js', line: 1 });
+    expect(findings.find((f) => f.code === 'auth0-custom-claim')).toMatchObject({ file: 'lib/roles.ts', line: 5 });
+    expect(findings.every((f) => f.manual)).toBe(true);
+    expect(customClaimKeys(findings)).toEqual(['https://myapp.example.com/roles', 'https://myapp.example.com/tenant']);
+  });
+
+  it('skips tests and standard claim URIs', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('lib/roles.test.ts', ROLES);
+    write(
+      'Auth/Claims.cs',
+      'var email = User.FindFirstValue("http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress");\n',
+    );
+
+    expect(await auth0Actions.detect(createScanContext(root))).toEqual([]);
+  });
+
+  describe('auth0ActionsWarning', () => {
+    it('lists the claims in use', async () => {
+      write('package.json', PACKAGE_JSON);
+      write('lib/roles.ts', ROLES);
+
+      const warning = auth0ActionsWarning(await auth0Actions.detect(createScanContext(root)));
+
+      expect(warning).toContain('no automatic AuthKit equivalent');
+      expect(warning).toContain('Custom claims used: https://myapp.example.com/roles');
+      expect(auth0ActionsWarning([])).toBeNull();
+    });
+  });
+});
@@ -0,0 +1,168 @@
+import { findLines } from '../scan.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/**
+ * Auth0 Actions and Rules run in the Auth0 tenant, so their logic never shows
+ * up in the app being migrated. What does show up is what they leave behind:
+ * namespaced custom claims (`https://myapp.com/roles`) read from the token,
+ * and Action/Rule sources kept in the repo for the Deploy CLI.
+ */
+
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?|go|py|rb|php|java|kt|cs)$/;
+const TEST_FILE_PATTERN = /(\.(test|spec)\.[^/]+$|_test\.go$|(^|\/)(__tests__|tests?|spec)\/)/;
+const MANIFESTS = [
+  'package.json',
+  'go.mod',
+  'composer.json',
+  'requirements.txt',
+  'pyproject.toml',
+  'Gemfile',
+  'pom.xml',
+  'build.gradle',
+];
+
+/** Claim lookups by URL key: `user['https://…']`, `.get(…)`, `getClaim(…)`, `FindFirst(…)` */
+const CLAIM_READ_PATTERN =
+  /(?:\[\s*|\.get\(\s*|getClaim\w*\(\s*|FindFirst(?:Value)?\(\s*)(['"`])(https?:\/\/[^'"`\s]+)\1/;
+/** Standard claim URIs (WS-Federation, .NET) that aren't Auth0 custom claims */
+const STANDARD_CLAIM_HOSTS = /^https?:\/\/schemas\.(xmlsoap\.org|microsoft\.com)\//;
+
+/** `api.idToken.setCustomClaim('https://…', …)` in an Action */
+const ACTION_CLAIM_PATTERN = /\bapi\.(?:idToken|accessToken)\.setCustomClaim\(\s*(['"`])([^'"`]+)\1/;
+/** `context.idToken['https://…'] = …` in a Rule */
+const RULE_CLAIM_PATTERN = /\bcontext\.(?:idToken|accessToken)\[\s*(['"`])([^'"`]+)\1\s*\]\s*=(?!=)/;
+/** Findings that mean the app depends on logic in the Auth0 tenant */
+const SERVER_SIDE_CODES = new Set(['auth0-action', 'auth0-rule', 'auth0-custom-claim-set', 'auth0-custom-claim']);
+
+const ACTION_EXPORT_PATTERN = /\bexports\.(onExecute\w+)\s*=/;
+const RULE_SIGNATURE_PATTERN = /^\s*(?:async\s+)?function\s*\w*\s*\(\s*user\s*,\s*context\s*,\s*callback\s*\)/;
+
+const REMEDIATION =
+  'Reimplement the Auth0 Action or Rule that sets it: WorkOS roles and permissions are in the session, ' +
+  'and other custom claims can come from a JWT template';
+
+async function usesAuth0(ctx: ScanContext, files: string[]): Promise<boolean> {
+  for (const file of MANIFESTS) {
+    if (/auth0/i.test((await ctx.readFile(file)) ?? '')) return true;
+  }
+  for (const file of files) {
+    if (/auth0/i.test((await ctx.readFile(file)) ?? '')) return true;
+  }
+  return false;
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => SOURCE_FILE_PATTERN.test(f) && !TEST_FILE_PATTERN.test(f));
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+
+    for (const { line, text, match } of findLines(content, ACTION_EXPORT_PATTERN)) {
+      findings.push({
+        provider: 'auth0',
+        code: 'auth0-action',
+        severity: 'warning',
+        message: `Auth0 Action (${match[1]}) runs in the Auth0 tenant, not in this app`,
+        file,
+        line,
+        evidence: text,
+        remediation: 'Move its logic into the app (after the AuthKit callback) or a WorkOS Actions endpoint',
+        manual: true,
+        confidence: 0.5,
+        details: { trigger: match[1] },
+      });
+    }
+
+    for (const { line, text } of findLines(content, RULE_SIGNATURE_PATTERN)) {
+      findings.push({
+        provider: 'auth0',
+        code: 'auth0-rule',
+        severity: 'warning',
+        message: 'Auth0 Rule runs in the Auth0 tenant, not in this app',
+        file,
+        line,
+        evidence: text,
+        remediation: 'Move its logic into the app (after the AuthKit callback) or a WorkOS Actions endpoint',
+        manual: true,
+        confidence: 0.4,
+      });
+    }
+
+    for (const [pattern, setBy] of [
+      [ACTION_CLAIM_PATTERN, 'an Action'],
+      [RULE_CLAIM_PATTERN, 'a Rule'],
+    ] as const) {
+      for (const { line, text, match } of findLines(content, pattern)) {
+        findings.push({
+          provider: 'auth0',
+          code: 'auth0-custom-claim-set',
+          severity: 'warning',
+          message: `Custom claim "${match[2]}" is set by ${setBy}`,
+          file,
+          line,
+          evidence: text,
+          remediation: REMEDIATION,
+          manual: true,
+          confidence: 0.3,
+          details: { claim: match[2] },
+        });
+      }
+    }
+
+    for (const { line, text, match } of findLines(content, CLAIM_READ_PATTERN)) {
+      const claim = match[2];
+      // Rules set claims with the same bracket syntax
+      if (STANDARD_CLAIM_HOSTS.test(claim) || RULE_CLAIM_PATTERN.test(text)) continue;
+      findings.push({
+        provider: 'auth0',
+        code: 'auth0-custom-claim',
+        severity: 'warning',
+        message: `Reads custom claim "${claim}", which an Auth0 Action or Rule adds to the token`,
+        file,
+        line,
+        evidence: text,
+        remediation: REMEDIATION,
+        manual: true,
+        confidence: 0.2,
+        details: { claim },
+      });
+    }
+  }
+
+  // Namespaced claims are an Auth0 convention, but only count them when Auth0 is in the project
+  if (findings.length === 0 || !(await usesAuth0(ctx, files))) return [];
+  return findings;
+}
+
+/** Custom claim keys found in code, in order of first appearance */
+export function customClaimKeys(findings: MigrationFinding[]): string[] {
+  const claims = findings
+    .filter((f) => SERVER_SIDE_CODES.has(f.code))
+    .map((f) => f.details?.claim)
+    .filter((c): c is string => typeof c === 'string');
+  return [...new Set(claims)];
+}
+
+/**
+ * Summary warning for migrations that depend on Auth0 Actions or Rules,
+ * or null when no finding points at them.
+ */
+export function auth0ActionsWarning(findings: MigrationFinding[]): string | null {
+  if (!findings.some((f) => SERVER_SIDE_CODES.has(f.code))) return null;
+  const claims = customClaimKeys(findings);
+  return (
+    "Auth0 Actions and Rules run on Auth0's servers and have no automatic AuthKit equivalent; " +
+    'reimplement them before switching over.' +
+    (claims.length > 0 ? ` Custom claims used: ${claims.join(', ')}` : '')
+  );
+}
+
+export const auth0Actions: ProviderDetector = {
+  name: 'auth0-actions',
+  description: 'Custom claims set by Auth0 Actions and Rules, and Action sources kept for the Deploy CLI',
+  language: 'javascript',
+  files: SOURCE_FILE_PATTERN,
+  detect,
+};
@@ -0,0 +1,52 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { auth0Sdks } from './auth0-sdks.js';
+
+describe('auth0-sdks detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'auth0-sdks-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('records the major each SDK is pinned to', async () => {
+    const dependencies = { next: '^14.2.0', '@auth0/nextjs-auth0': '^3.5.0' };
+    write('package.json', JSON.stringify({ dependencies }, null, 2));
+    write('apps/web/package.json', JSON.stringify({ dependencies: { '@auth0/auth0-react': 'latest' } }, null, 2));
+
+    const findings = await auth0Sdks.detect(createScanContext(root));
+
+    expect(findings).toMatchObject([
+      {
+        provider: 'auth0',
+        code: 'auth0-sdk-dependency',
+        message: '@auth0/nextjs-auth0 ^3.5.0 in package.json',
+        file: 'package.json',
+        line: 4,
+        details: { sdk: '@auth0/nextjs-auth0', sdkMajor: 3, sdkVersion: '^3.5.0' },
+      },
+      {
+        message: '@auth0/auth0-react latest in apps/web/package.json (major version not pinned)',
+        details: { sdk: '@auth0/auth0-react', sdkMajor: null },
+      },
+    ]);
+  });
+
+  it('ignores manifests without Auth0 SDKs', async () => {
+    write('package.json', JSON.stringify({ dependencies: { 'auth0-lock-shim': '^1.0.0', next: '^14.2.0' } }));
+
+    expect(await auth0Sdks.detect(createScanContext(root))).toEqual([]);
+  });
+});
@@ -0,0 +1,49 @@
+import { findLines, readPackageManifests } from '../scan.js';
+import { npmMajor } from '../sdk-versions.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/**
+ * Auth0 JavaScript SDKs in package.json, with the major version each is
+ * pinned to. The majors differ enough in shape (see sdk-versions.ts) that
+ * the migration needs to know which one it is replacing.
+ */
+
+const PACKAGES: Record<string, string> = {
+  '@auth0/nextjs-auth0': 'Replace with @workos-inc/authkit-nextjs',
+  '@auth0/auth0-react': 'Replace with @workos-inc/authkit-react',
+  '@auth0/auth0-spa-js': 'Replace with @workos-inc/authkit-js',
+  'express-openid-connect': 'Replace the auth() middleware with the AuthKit flow from @workos-inc/node',
+  auth0: 'Replace Management API calls with @workos-inc/node',
+};
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const findings: MigrationFinding[] = [];
+
+  for (const { file, raw, deps } of await readPackageManifests(ctx)) {
+    for (const name of Object.keys(PACKAGES).filter((p) => typeof deps[p] === 'string')) {
+      const spec = deps[name];
+      const major = npmMajor(spec);
+      findings.push({
+        provider: 'auth0',
+        code: 'auth0-sdk-dependency',
+        severity: 'info',
+        message: `${name} ${spec} in ${file}${major === null ? ' (major version not pinned)' : ''}`,
+        file,
+        line: findLines(raw, new RegExp(`"${name.replace('/', '\\/')}"\\s*:`))[0]?.line,
+        remediation: PACKAGES[name],
+        confidence: 0.4,
+        details: { package: name, sdk: name, sdkMajor: major, sdkVersion: spec },
+      });
+    }
+  }
+
+  return findings;
+}
+
+export const auth0Sdks: ProviderDetector = {
+  name: 'auth0-sdks',
+  description: 'Auth0 JavaScript SDK dependencies and the major version each is pinned to',
+  language: 'javascript',
+  files: /(^|\/)package\.json$/,
+  detect,
+};
@@ -0,0 +1,129 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { clerk } from './clerk.js';
+
+const PACKAGE_JSON = JSON.stringify(
+  { dependencies: { next: '15.0.0', '@clerk/nextjs': '^6.9.0', '@clerk/backend': '^1.21.0' } },
+  null,
+  2,
+);
+
+const LAYOUT = `import { ClerkProvider, SignedIn, SignedOut, SignInButton, UserButton } from '@clerk/nextjs';
+
+export default function RootLayout({ children }) {
+  return (
+    <ClerkProvider>
+      <SignedOut>
+        <SignInButton />
+      </SignedOut>
+      <SignedIn>
+        <UserButton />
+      </SignedIn>
+      {children}
+    </ClerkProvider>
+  );
+}
+`;
+
+const MIDDLEWARE = `import { clerkMiddleware, createRouteMatcher } from '@clerk/nextjs/server';
+
+export default clerkMiddleware();
+`;
+
+const PAGE = `import { auth, currentUser } from '@clerk/nextjs/server';
+
+export default async function Page() {
+  const { userId, orgId } = await auth();
+  const user = await currentUser();
+  return <p>{user?.firstName}</p>;
+}
+`;
+
+describe('clerk detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'clerk-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('returns nothing for projects without Clerk', async () => {
+    write('package.json', JSON.stringify({ dependencies: { next: '15.0.0' } }));
+    write('.env', 'CLERK_SECRET_KEY=sk_test_leftover\n');
+    write('app/page.tsx', 'export default function Page() { return auth(); }\n');
+
+    expect(await clerk.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('finds the SDK, provider and middleware', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('app/layout.tsx', LAYOUT);
+    write('middleware.ts', MIDDLEWARE);
+
+    const findings = await clerk.detect(createScanContext(root));
+
+    expect(findings.every((f) => f.provider === 'clerk')).toBe(true);
+    expect(findings.filter((f) => f.code === 'clerk-dependency').map((f) => f.details?.package)).toEqual([
+      '@clerk/nextjs',
+      '@clerk/backend',
+    ]);
+    expect(findings.find((f) => f.code === 'clerk-provider')).toMatchObject({ file: 'app/layout.tsx', line: 5 });
+    expect(findings.find((f) => f.code === 'clerk-middleware')).toMatchObject({
+      file: 'middleware.ts',
+      line: 3,
+      remediation: expect.stringContaining('authkitMiddleware()'),
+    });
+  });
+
+  it('flags components and helpers that need manual replacement', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('app/layout.tsx', LAYOUT);
+    write('app/page.tsx', PAGE);
+
+    const manual = (await clerk.detect(createScanContext(root))).filter((f) => f.manual);
+
+    expect(manual.filter((f) => f.code === 'clerk-ui-component').map((f) => f.details?.component)).toEqual([
+      'SignedOut',
+      'SignInButton',
+      'SignedIn',
+      'UserButton',
+    ]);
+    expect(manual.filter((f) => f.code === 'clerk-auth-helper').map((f) => f.details?.helper)).toEqual([
+      'auth',
+      'currentUser',
+    ]);
+    expect(manual.every((f) => f.severity === 'warning' && f.remediation)).toBe(true);
+  });
+
+  it('maps Clerk env vars to WorkOS', async () => {
+    write('package.json', PACKAGE_JSON);
+    write(
+      '.env.local',
+      [
+        'CLERK_SECRET_KEY=sk_test_abc',
+        'NEXT_PUBLIC_CLERK_PUBLISHABLE_KEY=pk_test_abc',
+        'NEXT_PUBLIC_CLERK_SIGN_IN_URL=/sign-in',
+        'DATABASE_URL=postgres://localhost',
+      ].join('\n'),
+    );
+
+    const env = (await clerk.detect(createScanContext(root))).filter((f) => f.code === 'clerk-env');
+
+    expect(env.map((f) => f.details)).toEqual([
+      { envVar: 'CLERK_SECRET_KEY', workosEnv: 'WORKOS_API_KEY' },
+      { envVar: 'NEXT_PUBLIC_CLERK_PUBLISHABLE_KEY', workosEnv: 'WORKOS_CLIENT_ID' },
+      { envVar: 'NEXT_PUBLIC_CLERK_SIGN_IN_URL', workosEnv: null },
+    ]);
+  });
+});
@@ -0,0 +1,197 @@
+import { findLines, readPackageManifests } from '../scan.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/** Clerk SDKs; Next.js apps map onto @workos-inc/authkit-nextjs */
+const CLERK_PACKAGES = [
+  '@clerk/nextjs',
+  '@clerk/clerk-react',
+  '@clerk/react',
+  '@clerk/remix',
+  '@clerk/express',
+  '@clerk/backend',
+  '@clerk/clerk-sdk-node',
+];
+
+/** Clerk env vars and the WorkOS env var that replaces each */
+const ENV_TO_WORKOS: Record<string, string | null> = {
+  CLERK_SECRET_KEY: 'WORKOS_API_KEY',
+  CLERK_PUBLISHABLE_KEY: 'WORKOS_CLIENT_ID',
+  NEXT_PUBLIC_CLERK_PUBLISHABLE_KEY: 'WORKOS_CLIENT_ID',
+  CLERK_WEBHOOK_SECRET: null,
+  CLERK_JWT_KEY: null,
+  NEXT_PUBLIC_CLERK_SIGN_IN_URL: null,
+  NEXT_PUBLIC_CLERK_SIGN_UP_URL: null,
+  NEXT_PUBLIC_CLERK_AFTER_SIGN_IN_URL: null,
+  NEXT_PUBLIC_CLERK_AFTER_SIGN_UP_URL: null,
+  NEXT_PUBLIC_CLERK_SIGN_IN_FALLBACK_REDIRECT_URL: null,
+  NEXT_PUBLIC_CLERK_SIGN_UP_FALLBACK_REDIRECT_URL: null,
+};
+
+const ENV_FILES = ['.env', '.env.local', '.env.example', '.env.development', '.env.production'];
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?)$/;
+const CLERK_IMPORT_PATTERN = /from\s+['"](@clerk\/[\w-]+)(?:\/[\w/-]+)?['"]|require\(\s*['"](@clerk\/[\w-]+)/;
+
+/** Prebuilt Clerk components; AuthKit has no drop-in equivalent, so these are rewritten by hand */
+const UI_COMPONENTS: Record<string, string> = {
+  UserButton: 'Build a user menu from useAuth() and signOut()',
+  SignIn: 'Remove the embedded form; sign-in happens on the hosted AuthKit page (getSignInUrl())',
+  SignUp: 'Remove the embedded form; link to getSignUpUrl() instead',
+  SignInButton: 'Link to getSignInUrl()',
+  SignUpButton: 'Link to getSignUpUrl()',
+  SignOutButton: 'Call signOut() from @workos-inc/authkit-nextjs',
+  SignedIn: 'Render conditionally on the user from withAuth()/useAuth()',
+  SignedOut: 'Render conditionally on the user from withAuth()/useAuth()',
+  UserProfile: 'Use the WorkOS UserProfile widget or build the page on the User Management API',
+  OrganizationSwitcher: 'Use the WorkOS OrganizationSwitcher widget or switchToOrganization()',
+  OrganizationProfile: 'Use the WorkOS widgets or the Organizations API',
+};
+
+/** Server and client helpers whose return shapes differ from AuthKit's */
+const HELPERS: Record<string, string> = {
+  auth: 'Replace auth() with withAuth(); userId → user.id, orgId → organizationId, has() → role/permissions',
+  currentUser: 'Replace currentUser() with withAuth() and map the user fields (firstName, lastName, email)',
+  useAuth: 'Replace with useAuth() from @workos-inc/authkit-nextjs/components; the returned fields differ',
+  useUser: 'Replace with useAuth() from @workos-inc/authkit-nextjs/components and map the user fields',
+  clerkClient: 'Replace Backend API calls with the WorkOS Node SDK (workos.userManagement)',
+};
+
+const COMPONENT_PATTERN = new RegExp(`<(${Object.keys(UI_COMPONENTS).join('|')})[\\s/>]`);
+// Not preceded by `.`, so `firebase.auth()` and the like don't match
+const HELPER_PATTERN = new RegExp(`(?<![.\\w])(${Object.keys(HELPERS).join('|')})\\s*\\(|\\b(clerkClient)\\b`);
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const findings: MigrationFinding[] = [];
+  const files = await ctx.files();
+
+  for (const { file, raw, deps } of await readPackageManifests(ctx)) {
+    for (const name of CLERK_PACKAGES.filter((p) => deps[p])) {
+      findings.push({
+        provider: 'clerk',
+        code: 'clerk-dependency',
+        severity: 'info',
+        message: `${name} ${deps[name]} in ${file}`,
+        file,
+        line: findLines(raw, new RegExp(`"${name.replace('/', '\\/')}"`))[0]?.line,
+        remediation:
+          name === '@clerk/nextjs'
+            ? 'Replace with @workos-inc/authkit-nextjs'
+            : `Remove ${name} once no code references it`,
+        confidence: 0.4,
+        details: { package: name, framework: 'nextjs' },
+      });
+    }
+  }
+
+  for (const file of files.filter((f) => SOURCE_FILE_PATTERN.test(f))) {
+    const content = await ctx.readFile(file);
+    if (!content?.includes('@clerk/')) continue;
+
+    for (const { line, text, match } of findLines(content, CLERK_IMPORT_PATTERN)) {
+      findings.push({
+        provider: 'clerk',
+        code: 'clerk-import',
+        severity: 'info',
+        message: `Imports ${match[1] ?? match[2]}`,
+        file,
+        line,
+        evidence: text,
+        remediation: 'Import from @workos-inc/authkit-nextjs instead',
+        confidence: 0.2,
+      });
+    }
+
+    for (const { line, text } of findLines(content, /<ClerkProvider[\s>]/)) {
+      findings.push({
+        provider: 'clerk',
+        code: 'clerk-provider',
+        severity: 'warning',
+        message: '<ClerkProvider> wraps the app',
+        file,
+        line,
+        evidence: text,
+        remediation: 'Replace with <AuthKitProvider> from @workos-inc/authkit-nextjs/components',
+        confidence: 0.4,
+      });
+    }
+
+    for (const { line, text, match } of findLines(content, /\b(clerkMiddleware|authMiddleware)\s*\(/)) {
+      findings.push({
+        provider: 'clerk',
+        code: 'clerk-middleware',
+        severity: 'warning',
+        message: `${match[1]}() protects routes`,
+        file,
+        line,
+        evidence: text,
+        remediation:
+          'Replace with authkitMiddleware() from @workos-inc/authkit-nextjs; ' +
+          'move createRouteMatcher() rules to middlewareAuth.unauthenticatedPaths',
+        confidence: 0.4,
+      });
+    }
+
+    for (const { line, text, match } of findLines(content, COMPONENT_PATTERN)) {
+      findings.push({
+        provider: 'clerk',
+        code: 'clerk-ui-component',
+        severity: 'warning',
+        message: `<${match[1]}> has no drop-in AuthKit equivalent`,
+        file,
+        line,
+        evidence: text,
+        remediation: UI_COMPONENTS[match[1]],
+        confidence: 0.1,
+        manual: true,
+        details: { component: match[1], framework: 'nextjs' },
+      });
+    }
+
+    for (const { line, text, match } of findLines(content, HELPER_PATTERN)) {
+      const helper = match[1] ?? match[2];
+      findings.push({
+        provider: 'clerk',
+        code: 'clerk-auth-helper',
+        severity: 'warning',
+        message: `${helper}() returns Clerk's session shape`,
+        file,
+        line,
+        evidence: text,
+        remediation: HELPERS[helper],
+        confidence: 0.1,
+        manual: true,
+        details: { helper, framework: 'nextjs' },
+      });
+    }
+  }
+
+  if (findings.length === 0) return [];
+
+  for (const file of ENV_FILES) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    for (const { line, match } of findLines(content, /^\s*(?:export\s+)?((?:NEXT_PUBLIC_)?CLERK_[A-Z0-9_]+)\s*=/)) {
+      const envVar = match[1];
+      const workosEnv = envVar in ENV_TO_WORKOS ? ENV_TO_WORKOS[envVar] : null;
+      findings.push({
+        provider: 'clerk',
+        code: 'clerk-env',
+        severity: 'info',
+        message: workosEnv ? `${envVar} maps to ${workosEnv}` : `${envVar} is not needed with AuthKit`,
+        file,
+        line,
+        confidence: 0.1,
+        details: { envVar, workosEnv },
+      });
+    }
+  }
+
+  return findings;
+}
+
+export const clerk: ProviderDetector = {
+  name: 'clerk',
+  description: 'Clerk SDKs, components, middleware and env vars',
+  language: 'javascript',
+  files: new RegExp(`(^|/)package\\.json$|${SOURCE_FILE_PATTERN.source}|^\\.env`),
+  detect,
+};
@@ -0,0 +1,93 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { extractContainerEnvFiles, renameComposeEnvKeys, renameDockerfileEnvKeys } from '../container-env.js';
+import { createScanContext } from '../scan.js';
+import { containerEnv } from './container-env.js';
+
+const COMPOSE = `services:
+  web:
+    build: .
+    env_file:
+      - ./config/auth.env
+    environment:
+      AUTH0_DOMAIN: example.us.auth0.com
+      "AUTH0_CLIENT_ID": \${AUTH0_CLIENT_ID}
+      NODE_ENV: production # not a provider key
+  worker:
+    environment:
+      - AUTH0_CLIENT_SECRET=\${AUTH0_CLIENT_SECRET}
+      - "AUTH0_AUDIENCE=https://api.example.com"
+      - AUTH0_SECRET
+    command: ["node", "worker.js"]
+`;
+
+const DOCKERFILE = `FROM node:20
+ARG AUTH0_CLIENT_ID
+ENV AUTH0_BASE_URL=https://app.example.com NODE_ENV=production
+ENV AUTH0_CALLBACK_URL https://app.example.com/callback
+`;
+
+describe('container-env detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'container-env-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('finds provider keys in compose map and list forms, env_file and Dockerfile ENV/ARG', async () => {
+    write('docker-compose.yml', COMPOSE);
+    write('config/auth.env', 'AUTH0_REDIRECT_URI=https://app.example.com/callback\nPORT=3000\n');
+    write('Dockerfile', DOCKERFILE);
+
+    const findings = await containerEnv.detect(createScanContext(root));
+
+    const rows = findings.map((f) => [f.file, f.line, f.details?.envVar, f.details?.workosEnv, f.details?.container]);
+    expect(rows).toEqual([
+      ['docker-compose.yml', 7, 'AUTH0_DOMAIN', null, 'compose'],
+      ['docker-compose.yml', 8, 'AUTH0_CLIENT_ID', 'WORKOS_CLIENT_ID', 'compose'],
+      ['docker-compose.yml', 12, 'AUTH0_CLIENT_SECRET', 'WORKOS_API_KEY', 'compose'],
+      ['docker-compose.yml', 13, 'AUTH0_AUDIENCE', null, 'compose'],
+      ['docker-compose.yml', 14, 'AUTH0_SECRET', 'WORKOS_COOKIE_PASSWORD', 'compose'],
+      ['config/auth.env', 1, 'AUTH0_REDIRECT_URI', 'WORKOS_REDIRECT_URI', 'env_file'],
+      ['Dockerfile', 2, 'AUTH0_CLIENT_ID', 'WORKOS_CLIENT_ID', 'dockerfile'],
+      ['Dockerfile', 3, 'AUTH0_BASE_URL', null, 'dockerfile'],
+      ['Dockerfile', 4, 'AUTH0_CALLBACK_URL', 'WORKOS_REDIRECT_URI', 'dockerfile'],
+    ]);
+    expect(findings.every((f) => f.provider === 'auth0')).toBe(true);
+    expect(extractContainerEnvFiles(findings)).toEqual([
+      { file: 'docker-compose.yml', source: 'compose' },
+      { file: 'config/auth.env', source: 'env_file' },
+      { file: 'Dockerfile', source: 'dockerfile' },
+    ]);
+  });
+
+  it('renames keys only inside environment blocks and on ENV/ARG lines', () => {
+    const mapping = { AUTH0_CLIENT_ID: 'WORKOS_CLIENT_ID', AUTH0_CLIENT_SECRET: 'WORKOS_API_KEY', AUTH0_DOMAIN: null };
+
+    const compose = renameComposeEnvKeys(COMPOSE, mapping);
+    expect(compose.content).toContain('      "WORKOS_CLIENT_ID": ${AUTH0_CLIENT_ID}\n');
+    expect(compose.content).toContain('      - WORKOS_API_KEY=${AUTH0_CLIENT_SECRET}\n');
+    expect(compose.content).toContain('      AUTH0_DOMAIN: example.us.auth0.com\n');
+    expect(compose.renamed).toEqual([
+      { from: 'AUTH0_CLIENT_ID', to: 'WORKOS_CLIENT_ID', reference: true },
+      { from: 'AUTH0_CLIENT_SECRET', to: 'WORKOS_API_KEY', reference: true },
+    ]);
+
+    const dockerfile = renameDockerfileEnvKeys(`${DOCKERFILE}ENV AUTH0_CLIENT_SECRET=\${AUTH0_CLIENT_ID}\n`, mapping);
+    expect(dockerfile.content).toBe(
+      'FROM node:20\nARG WORKOS_CLIENT_ID\nENV AUTH0_BASE_URL=https://app.example.com NODE_ENV=production\n' +
+        'ENV AUTH0_CALLBACK_URL https://app.example.com/callback\nENV WORKOS_API_KEY=${AUTH0_CLIENT_ID}\n',
+    );
+  });
+});
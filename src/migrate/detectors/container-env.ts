@@ -0,0 +1,101 @@
+import {
+  COMPOSE_FILE_PATTERN,
+  DOCKERFILE_PATTERN,
+  parseComposeEnv,
+  parseDockerfileEnv,
+  resolveEnvFile,
+  type ContainerEnvSource,
+  type ContainerEnvVar,
+} from '../container-env.js';
+import { ENV_FILE_PATTERN } from '../env-rename.js';
+import { PROVIDERS } from '../providers.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/**
+ * Provider env vars set in container config: compose `environment:` blocks,
+ * the env files compose loads through `env_file:`, and Dockerfile `ENV` and
+ * `ARG` lines. Findings carry the WorkOS name (`details.workosEnv`) so the
+ * keys join the env rename, and `details.container` so the plan can say the
+ * images and containers need rebuilding.
+ */
+
+/** An assignment in a dotenv-format env_file */
+const ENV_FILE_LINE = /^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*=/;
+
+/** Which provider sets a var, from the providers' env mappings */
+const PROVIDER_ENV_VARS = new Map(
+  Object.values(PROVIDERS).flatMap((provider) =>
+    Object.entries(provider.envMapping).map(([envVar, workosEnv]) => [envVar, { provider: provider.name, workosEnv }]),
+  ),
+);
+
+function envFileVars(content: string): ContainerEnvVar[] {
+  return content.split('\n').flatMap((line, index) => {
+    const match = ENV_FILE_LINE.exec(line);
+    return match ? [{ name: match[1], line: index + 1 }] : [];
+  });
+}
+
+function containerFinding(source: ContainerEnvSource, file: string, envVar: ContainerEnvVar): MigrationFinding | null {
+  const known = PROVIDER_ENV_VARS.get(envVar.name);
+  if (!known) return null;
+  const where = source === 'dockerfile' ? 'Dockerfile' : source === 'env_file' ? 'compose env_file' : 'compose file';
+  return {
+    provider: known.provider,
+    code: 'container-env',
+    severity: 'info',
+    message: known.workosEnv
+      ? `${envVar.name} is set in a ${where}; it maps to ${known.workosEnv}`
+      : `${envVar.name} is set in a ${where} and is not needed with AuthKit`,
+    file,
+    line: envVar.line,
+    remediation: 'Rebuild the images and recreate the containers after renaming the key',
+    confidence: 0.1,
+    details: { envVar: envVar.name, workosEnv: known.workosEnv, container: source },
+  };
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = await ctx.files();
+  const findings: MigrationFinding[] = [];
+  const add = (source: ContainerEnvSource, file: string, vars: ContainerEnvVar[]) => {
+    for (const envVar of vars) {
+      const finding = containerFinding(source, file, envVar);
+      if (finding) findings.push(finding);
+    }
+  };
+
+  const envFiles = new Set<string>();
+  for (const file of files.filter((f) => COMPOSE_FILE_PATTERN.test(f))) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    const parsed = parseComposeEnv(content);
+    add('compose', file, parsed.vars);
+    for (const envFile of parsed.envFiles) {
+      const resolved = resolveEnvFile(file, envFile);
+      if (resolved) envFiles.add(resolved);
+    }
+  }
+
+  // Root .env files are renamed anyway; the rest are only found through compose
+  for (const file of [...envFiles].sort()) {
+    if (ENV_FILE_PATTERN.test(file)) continue;
+    const content = await ctx.readFile(file);
+    if (content) add('env_file', file, envFileVars(content));
+  }
+
+  for (const file of files.filter((f) => DOCKERFILE_PATTERN.test(f))) {
+    const content = await ctx.readFile(file);
+    if (content) add('dockerfile', file, parseDockerfileEnv(content));
+  }
+
+  return findings;
+}
+
+export const containerEnv: ProviderDetector = {
+  name: 'container-env',
+  description: 'Provider env vars in docker-compose environment/env_file sections and Dockerfile ENV/ARG lines',
+  language: 'any',
+  files: new RegExp(`${COMPOSE_FILE_PATTERN.source}|${DOCKERFILE_PATTERN.source}|\\.env`),
+  detect,
+};
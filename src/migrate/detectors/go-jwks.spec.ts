@@ -0,0 +1,144 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { buildMigrationPrompt } from '../prompt.js';
+import { extractTokenVerifiers } from '../token-verification.js';
+import { goJwks } from './go-jwks.js';
+
+const GOLANG_JWT_MOD = `module example.com/api
+
+go 1.22
+
+require (
+	github.com/MicahParks/keyfunc/v3 v3.3.5
+	github.com/golang-jwt/jwt/v5 v5.2.1
+)
+`;
+
+const KEYFUNC_GO = `package middleware
+
+import (
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func NewVerifier() (*Verifier, error) {
+	jwks, err := keyfunc.NewDefault([]string{"https://acme.us.auth0.com/.well-known/jwks.json"})
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{jwks: jwks}, nil
+}
+
+func (v *Verifier) Verify(raw string) (*jwt.Token, error) {
+	return jwt.Parse(raw, v.jwks.Keyfunc, jwt.WithAudience("api"))
+}
+`;
+
+const JWX_MOD = `module example.com/api
+
+go 1.22
+
+require github.com/lestrrat-go/jwx/v2 v2.1.1
+`;
+
+const JWX_GO = `package auth
+
+func Verify(ctx context.Context, raw []byte) (jwt.Token, error) {
+	set, err := jwk.Fetch(ctx, os.Getenv("JWKS_URL"))
+	if err != nil {
+		return nil, err
+	}
+	return jwt.Parse(raw, jwt.WithKeySet(set), jwt.WithIssuer(os.Getenv("TOKEN_ISSUER")))
+}
+`;
+
+describe('go-jwks detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'go-jwks-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('returns nothing without a JWT library in go.mod', async () => {
+    write('go.mod', 'module example.com/api\n\ngo 1.22\n');
+    write('verify.go', KEYFUNC_GO);
+
+    expect(await goJwks.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('ignores token parsing without a key set, such as HMAC session tokens', async () => {
+    write('go.mod', GOLANG_JWT_MOD);
+    write('session.go', 'func parse(raw string) { jwt.Parse(raw, hmacKey) }\n');
+
+    expect(await goJwks.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('points at the verification call and extracts the key set URL and issuer', async () => {
+    write('go.mod', GOLANG_JWT_MOD);
+    write('internal/middleware/verify.go', KEYFUNC_GO);
+
+    const findings = await goJwks.detect(createScanContext(root));
+
+    expect(findings).toHaveLength(1);
+    expect(findings[0]).toMatchObject({
+      provider: 'auth0',
+      code: 'go-jwks-verification',
+      file: 'internal/middleware/verify.go',
+      line: 17,
+      details: {
+        library: 'github.com/golang-jwt/jwt',
+        jwksUrl: 'https://acme.us.auth0.com/.well-known/jwks.json',
+        issuer: 'https://acme.us.auth0.com/',
+      },
+    });
+  });
+
+  it('records env vars holding the key set URL and issuer with jwx', async () => {
+    write('go.mod', JWX_MOD);
+    write('auth.go', JWX_GO);
+    write('auth_test.go', JWX_GO);
+
+    const findings = await goJwks.detect(createScanContext(root));
+
+    expect(findings).toHaveLength(1);
+    expect(findings[0].provider).toBe('oidc');
+    expect(findings[0].details).toMatchObject({ jwksEnvVar: 'JWKS_URL', issuerEnvVar: 'TOKEN_ISSUER' });
+  });
+
+  it('maps each verifier to the AuthKit key set and issuer in the migration prompt', async () => {
+    write('go.mod', GOLANG_JWT_MOD);
+    write('verify.go', KEYFUNC_GO);
+    const findings = await goJwks.detect(createScanContext(root));
+
+    const tokenVerifiers = extractTokenVerifiers(findings);
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.6,
+      envMapping: {},
+      guidance: [],
+      findings,
+      tokenVerifiers,
+    });
+
+    expect(prompt).toContain('### Token verification');
+    expect(prompt).toContain(
+      '- verify.go:17: keys from https://acme.us.auth0.com/.well-known/jwks.json → ' +
+        'https://api.workos.com/sso/jwks/<WORKOS_CLIENT_ID>; issuer https://acme.us.auth0.com/ → ' +
+        'https://api.workos.com/user_management/<WORKOS_CLIENT_ID>',
+    );
+  });
+});
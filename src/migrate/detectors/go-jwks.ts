@@ -0,0 +1,99 @@
+import { providerFromIssuer } from '../providers.js';
+import { findLines } from '../scan.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/** JWT libraries services verify tokens with by hand, without an OIDC SDK */
+const MODULES = ['github.com/golang-jwt/jwt', 'github.com/lestrrat-go/jwx', 'github.com/MicahParks/keyfunc'];
+
+const SOURCE_FILE_PATTERN = /\.go$/;
+
+/** Key set URLs: `.well-known/jwks.json`, Keycloak's `/certs`, Okta's and Entra's `/keys` */
+const JWKS_URL_PATTERN = /["`](https?:\/\/[^"`\s]*(?:jwks[^"`\s]*|\/certs|\/keys))["`]/;
+/** Env vars holding the key set URL, e.g. os.Getenv("AUTH0_JWKS_URL") */
+const JWKS_ENV_PATTERN = /os\.Getenv\(\s*"([A-Z0-9_]*JWKS[A-Z0-9_]*)"\s*\)/;
+/** Fetching or caching the key set */
+const JWKS_FETCH_PATTERN = /\b(jwk\.(Fetch|NewCache|NewAutoRefresh)|keyfunc\.(Get|NewDefault|NewJWKSetJSON))\s*\(/;
+
+/** Calls that verify a token's signature, and the library each belongs to */
+const VERIFY_PATTERN = /\b(jwt\.(Parse|ParseWithClaims|ParseString|ParseRequest)|jws\.Verify)\s*\(/;
+
+/** Expected issuer: jwt.WithIssuer("...") (both libraries) or a literal compared with the iss claim */
+const ISSUER_PATTERN = /(?:WithIssuer\(\s*|\biss(?:uer)?\b[^"`\n]*(?:==|!=)\s*)["`](https?:\/\/[^"`\s]+)["`]/i;
+const ISSUER_ENV_PATTERN = /WithIssuer\(\s*os\.Getenv\(\s*"([A-Z0-9_]+)"\s*\)/;
+
+/** Suffixes of a key set URL that sit under the issuer */
+const JWKS_SUFFIX = /\/(\.well-known\/jwks\.json|protocol\/openid-connect\/certs)$/;
+
+/** Issuer implied by a key set URL, when the URL is under it */
+function issuerFromJwksUrl(url: string): string | undefined {
+  return JWKS_SUFFIX.test(url) ? url.replace(JWKS_SUFFIX, '/') : undefined;
+}
+
+function libraryFor(goMod: string): string | undefined {
+  return MODULES.find((module) => goMod.includes(module));
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const goMod = await ctx.readFile('go.mod');
+  if (!goMod) return [];
+  const library = libraryFor(goMod);
+  if (!library) return [];
+
+  const findings: MigrationFinding[] = [];
+  const files = await ctx.files();
+
+  for (const file of files.filter((f) => SOURCE_FILE_PATTERN.test(f) && !f.endsWith('_test.go'))) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+
+    // Manual verification needs both: the key set is fetched and a token is parsed against it
+    const urls = findLines(content, JWKS_URL_PATTERN);
+    const envRefs = findLines(content, JWKS_ENV_PATTERN);
+    const fetches = findLines(content, JWKS_FETCH_PATTERN);
+    if (urls.length === 0 && envRefs.length === 0 && fetches.length === 0) continue;
+    const verifies = findLines(content, VERIFY_PATTERN);
+    if (verifies.length === 0) continue;
+
+    const jwksUrl = urls[0]?.match[1];
+    const jwksEnvVar = envRefs[0]?.match[1];
+    const issuer = findLines(content, ISSUER_PATTERN)[0]?.match[1] ?? (jwksUrl && issuerFromJwksUrl(jwksUrl));
+    const issuerEnvVar = findLines(content, ISSUER_ENV_PATTERN)[0]?.match[1];
+    const provider = (jwksUrl && providerFromIssuer(jwksUrl)) || (issuer && providerFromIssuer(issuer)) || 'oidc';
+    const source = jwksUrl ?? (jwksEnvVar ? `os.Getenv("${jwksEnvVar}")` : 'a JWKS endpoint');
+
+    for (const { line, text, match } of verifies) {
+      findings.push({
+        provider,
+        code: 'go-jwks-verification',
+        severity: 'warning',
+        message: `${match[1]} verifies tokens by hand against ${source}`,
+        file,
+        line,
+        evidence: text,
+        remediation:
+          'Verify AuthKit access tokens against the WorkOS JWKS (https://api.workos.com/sso/jwks/<WORKOS_CLIENT_ID>) ' +
+          'with the AuthKit issuer, or authenticate the session with the WorkOS Go SDK',
+        // A hand-rolled verifier is the whole integration: the provider is known when the URL names it
+        confidence: provider === 'oidc' ? 0.4 : 0.6,
+        details: {
+          framework: 'go',
+          library,
+          ...(jwksUrl && { jwksUrl }),
+          ...(jwksEnvVar && { jwksEnvVar }),
+          ...(issuer && { issuer }),
+          ...(issuerEnvVar && { issuerEnvVar }),
+        },
+      });
+    }
+  }
+
+  return findings;
+}
+
+export const goJwks: ProviderDetector = {
+  name: 'go-jwks',
+  description: 'Tokens verified by hand against a JWKS endpoint with golang-jwt, jwx or keyfunc',
+  language: 'go',
+  files: /(^|\/)go\.mod$|\.go$/,
+  detect,
+};
@@ -0,0 +1,211 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { claimReads, configRedirect, configScopes, findOAuth2Configs, goOAuth2 } from './go-oauth2.js';
+import type { MigrationFinding } from '../types.js';
+
+const GO_MOD = `module example.com/app
+
+go 1.22
+
+require (
+	github.com/coreos/go-oidc/v3 v3.10.0
+	golang.org/x/oauth2 v0.21.0
+)
+`;
+
+const HARDCODED_AUTH_GO = `package auth
+
+import (
+	"context"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+func NewConfig(ctx context.Context) (*oauth2.Config, error) {
+	provider, err := oidc.NewProvider(ctx, "https://acme.us.auth0.com/")
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     "f3kTq9ZbXw2LpN8vR1sYd7Hc",
+		ClientSecret: "s3cr3t-value-from-the-dashboard",
+		RedirectURL:  "http://localhost:3000/callback",
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "profile"},
+	}, nil
+}
+`;
+
+const ENV_AUTH_GO = `package auth
+
+func NewConfig(ctx context.Context) (*oauth2.Config, error) {
+	provider, err := oidc.NewProvider(ctx, "https://"+os.Getenv("AUTH0_DOMAIN")+"/")
+	if err != nil {
+		return nil, err
+	}
+	return &oauth2.Config{
+		ClientID:     os.Getenv("AUTH0_CLIENT_ID"),
+		ClientSecret: os.Getenv("AUTH0_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("CALLBACK_URL"),
+		Endpoint:     provider.Endpoint(),
+	}, nil
+}
+`;
+
+describe('go-oauth2 detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'go-oauth2-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  describe('findOAuth2Configs', () => {
+    it('returns each composite literal with its starting line', () => {
+      const blocks = findOAuth2Configs(HARDCODED_AUTH_GO);
+
+      expect(blocks).toHaveLength(1);
+      expect(blocks[0].line).toBe(15);
+      expect(blocks[0].body).toContain('Scopes:');
+      expect(blocks[0].body.endsWith('}')).toBe(true);
+    });
+  });
+
+  it('reads scopes from the config, resolving go-oidc constants', () => {
+    expect(configScopes(findOAuth2Configs(HARDCODED_AUTH_GO)[0].body)).toEqual(['openid', 'profile']);
+    expect(configScopes('oauth2.Config{ClientID: id}')).toEqual([]);
+  });
+
+  it('reads how the config builds its redirect URL', () => {
+    const redirect = (expression: string) => configRedirect(`oauth2.Config{\n\tRedirectURL: ${expression},\n}`);
+
+    expect(configRedirect(findOAuth2Configs(HARDCODED_AUTH_GO)[0].body)).toEqual({
+      url: 'http://localhost:3000/callback',
+    });
+    expect(redirect('os.Getenv("CALLBACK_URL")')).toEqual({ envVar: 'CALLBACK_URL' });
+    expect(redirect('os.Getenv("APP_BASE_URL") + "/account/auth/callback" // behind the proxy')).toEqual({
+      envVar: 'APP_BASE_URL',
+      path: '/account/auth/callback',
+    });
+    expect(redirect('fmt.Sprintf("%s/auth/callback", os.Getenv("BASE_URL"))')).toEqual({
+      envVar: 'BASE_URL',
+      path: '/auth/callback',
+    });
+    expect(redirect('cfg.CallbackURL')).toBeUndefined();
+  });
+
+  it('finds claims read from a decoded ID token', () => {
+    const content = [
+      'func claimsOf(idToken *oidc.IDToken) {',
+      '\tvar claims struct {',
+      '\t\tEmail    string `json:"email"`',
+      '\t\tVerified bool   `json:"email_verified,omitempty"`',
+      '\t}',
+      '\tidToken.Claims(&claims)',
+      '\tvar idClaims map[string]any',
+      '\tidToken.Claims(&idClaims)',
+      '\torg := idClaims["org_id"]',
+      '}',
+    ].join('\n');
+
+    expect(claimReads(content).map(({ claim, line }) => [claim, line])).toEqual([
+      ['email', 3],
+      ['email_verified', 4],
+      ['org_id', 9],
+    ]);
+    expect(claimReads('var claims struct {\n\tEmail string `json:"email"`\n}\n')).toEqual([]);
+  });
+
+  it('returns nothing without an OAuth module in go.mod', async () => {
+    write('go.mod', 'module example.com/app\n\ngo 1.22\n');
+    write('auth.go', HARDCODED_AUTH_GO);
+
+    expect(await goOAuth2.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('flags a hardcoded issuer, client ID and client secret', async () => {
+    write('go.mod', GO_MOD);
+    write('internal/auth/auth.go', HARDCODED_AUTH_GO);
+
+    const findings = await goOAuth2.detect(createScanContext(root));
+
+    expect(findings.every((f) => f.provider === 'auth0')).toBe(true);
+
+    const issuer = findings.find((f) => f.code === 'go-hardcoded-issuer');
+    expect(issuer?.severity).toBe('warning');
+    expect(issuer?.line).toBe(11);
+    expect(issuer?.details?.url).toBe('https://acme.us.auth0.com/');
+
+    const clientId = findings.find((f) => f.details?.setting === 'ClientID');
+    expect(clientId?.code).toBe('go-hardcoded-setting');
+    expect(clientId?.severity).toBe('warning');
+    expect(clientId?.line).toBe(16);
+    expect(clientId?.details?.workosEnv).toBe('WORKOS_CLIENT_ID');
+
+    const redirect = findings.find((f) => f.details?.setting === 'RedirectURL');
+    expect(redirect?.details?.redirectUri).toBe('http://localhost:3000/callback');
+    expect(findings.find((f) => f.code === 'go-oauth2-config')?.details?.scopes).toEqual(['openid', 'profile']);
+
+    const secret = findings.find((f) => f.code === 'go-hardcoded-secret');
+    expect(secret?.severity).toBe('warning');
+    expect(secret?.message).toContain('embedded in source');
+    expect(secret?.evidence).toBeUndefined();
+    expect(JSON.stringify(findings)).not.toContain('s3cr3t-value-from-the-dashboard');
+  });
+
+  it('maps os.Getenv settings to WorkOS env vars', async () => {
+    write('go.mod', GO_MOD);
+    write('auth.go', ENV_AUTH_GO);
+
+    const findings = await goOAuth2.detect(createScanContext(root));
+
+    expect(findings.every((f) => f.provider === 'auth0')).toBe(true);
+    expect(findings.some((f) => f.details?.hardcoded)).toBe(false);
+
+    const secretRef = findings.find((f) => f.details?.envVar === 'AUTH0_CLIENT_SECRET');
+    expect(secretRef?.code).toBe('go-env-ref');
+    expect(secretRef?.details?.workosEnv).toBe('WORKOS_API_KEY');
+
+    const redirect = findings.find((f) => f.details?.envVar === 'CALLBACK_URL');
+    expect(redirect?.code).toBe('go-oauth2-setting');
+    expect(redirect?.details?.workosEnv).toBe('WORKOS_REDIRECT_URI');
+  });
+
+  it('records the go-oidc major from the module path or its version', async () => {
+    write('auth.go', ENV_AUTH_GO);
+    write('go.mod', GO_MOD);
+    const goOidc = (f: MigrationFinding) => f.details?.sdk === 'github.com/coreos/go-oidc';
+    const v3 = (await goOAuth2.detect(createScanContext(root))).find(goOidc);
+
+    expect(v3?.message).toBe('github.com/coreos/go-oidc v3 in go.mod');
+    expect(v3?.details).toMatchObject({ sdk: 'github.com/coreos/go-oidc', sdkMajor: 3, sdkVersion: 'v3.10.0' });
+
+    write('go.mod', 'module example.com/app\n\nrequire github.com/coreos/go-oidc v2.2.1+incompatible\n');
+    const v2 = (await goOAuth2.detect(createScanContext(root))).find(goOidc);
+
+    expect(v2?.details).toMatchObject({ sdkMajor: 2, sdkVersion: 'v2.2.1+incompatible' });
+  });
+
+  it('ignores placeholder client IDs and test files', async () => {
+    write('go.mod', GO_MOD);
+    write('auth.go', 'var conf = oauth2.Config{ClientID: "your-client-id-here-123"}\n');
+    write('auth_test.go', HARDCODED_AUTH_GO);
+
+    const findings = await goOAuth2.detect(createScanContext(root));
+
+    expect(findings.find((f) => f.details?.hardcoded)).toBeUndefined();
+    expect(findings.filter((f) => f.file === 'auth_test.go')).toEqual([]);
+  });
+});
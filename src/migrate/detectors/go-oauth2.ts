@@ -0,0 +1,300 @@
+import { getProvider, looksLikeClientId, providerFromIssuer, providerFromName } from '../providers.js';
+import { findLines } from '../scan.js';
+import { mapClaim } from '../scopes-claims.js';
+import { goMajor, SDK_VARIANTS } from '../sdk-versions.js';
+import type { MigrationFinding, ProviderDetector, RedirectSource, ScanContext } from '../types.js';
+
+/** Go modules that implement OAuth/OIDC login, and the provider a module implies */
+const MODULES: Record<string, string | undefined> = {
+  'golang.org/x/oauth2': undefined,
+  'github.com/coreos/go-oidc': undefined,
+  'github.com/auth0/go-auth0': 'auth0',
+  'github.com/auth0/go-jwt-middleware': 'auth0',
+  'github.com/okta/okta-jwt-verifier-golang': 'okta',
+  'github.com/okta/okta-sdk-golang': 'okta',
+};
+
+/** oauth2.Config fields and the WorkOS env var that replaces each */
+const FIELD_TO_WORKOS_ENV: Record<string, string> = {
+  ClientID: 'WORKOS_CLIENT_ID',
+  ClientSecret: 'WORKOS_API_KEY',
+  RedirectURL: 'WORKOS_REDIRECT_URI',
+};
+
+const SOURCE_FILE_PATTERN = /\.go$/;
+const CONFIG_PATTERN = /oauth2\.Config\s*\{/g;
+const FIELD_PATTERN = /\b(ClientID|ClientSecret|RedirectURL)\s*:\s*(os\.Getenv\(\s*"([^"]+)"\s*\)|"([^"]*)"|`([^`]*)`)/;
+const PROVIDER_ENV_PATTERN = /os\.Getenv\(\s*"((AUTH0|OKTA|COGNITO|KEYCLOAK|AZURE)_[A-Z0-9_]+)"\s*\)/;
+const URL_LITERAL_PATTERN = /["`](https:\/\/[^"`\s]+)["`]/;
+const SCOPES_PATTERN = /\bScopes\s*:\s*\[\]string\s*\{([^}]*)\}/;
+const REDIRECT_FIELD_PATTERN = /\bRedirectURL\s*:\s*([^\n]*?)\s*,?(?:\s+\/\/[^\n]*)?$/m;
+const GETENV = 'os\\.Getenv\\(\\s*"(\\w+)"\\s*\\)';
+/** The ways a callback URL is commonly built; anything else is left to the default */
+const REDIRECT_EXPRESSIONS: Array<{ pattern: RegExp; source: (m: RegExpExecArray) => RedirectSource }> = [
+  { pattern: /^"(https?:\/\/[^"]+)"$|^`(https?:\/\/[^`]+)`$/, source: (m) => ({ url: m[1] ?? m[2] }) },
+  { pattern: new RegExp(`^${GETENV}$`), source: (m) => ({ envVar: m[1] }) },
+  // os.Getenv("APP_URL") + "/auth/callback"
+  { pattern: new RegExp(`^${GETENV}\\s*\\+\\s*"(\\/[^"]*)"$`), source: (m) => ({ envVar: m[1], path: m[2] }) },
+  // fmt.Sprintf("%s/auth/callback", os.Getenv("APP_URL"))
+  {
+    pattern: new RegExp(`^fmt\\.Sprintf\\(\\s*"%s(\\/[^"%]*)"\\s*,\\s*${GETENV}\\s*\\)$`),
+    source: (m) => ({ envVar: m[2], path: m[1] }),
+  },
+];
+/** go-oidc's scope constants */
+const OIDC_SCOPE_CONSTANTS: Record<string, string> = { ScopeOpenID: 'openid', ScopeOfflineAccess: 'offline_access' };
+/** `claims["email"]` on a map the ID token was decoded into */
+const CLAIM_INDEX_PATTERN = /\b\w*[cC]laims\[\s*"([^"]+)"\s*\]/;
+/** A struct the ID token is decoded into: `type idClaims struct {` or `var claims struct {` */
+const CLAIMS_STRUCT_PATTERN = /\b\w*[cC]laims\w*\s+struct\s*\{/g;
+
+interface ConfigBlock {
+  /** 1-based line of `oauth2.Config{` */
+  line: number;
+  body: string;
+}
+
+/** `oauth2.Config{ ... }` composite literals, matched by brace depth */
+export function findOAuth2Configs(content: string): ConfigBlock[] {
+  const blocks: ConfigBlock[] = [];
+  for (const match of content.matchAll(CONFIG_PATTERN)) {
+    let depth = 1;
+    let i = match.index + match[0].length;
+    while (i < content.length && depth > 0) {
+      if (content[i] === '{') depth++;
+      else if (content[i] === '}') depth--;
+      i++;
+    }
+    blocks.push({
+      line: content.slice(0, match.index).split('\n').length,
+      body: content.slice(match.index, i),
+    });
+  }
+  return blocks;
+}
+
+/** Scopes listed in an oauth2.Config, with go-oidc constants resolved */
+export function configScopes(body: string): string[] {
+  const list = SCOPES_PATTERN.exec(body)?.[1];
+  if (!list) return [];
+  return list.split(',').flatMap((item) => {
+    const value = item.trim();
+    const literal = /^"([^"]+)"$/.exec(value)?.[1];
+    const constant = OIDC_SCOPE_CONSTANTS[value.replace(/^\w+\./, '')];
+    return literal ?? constant ?? [];
+  });
+}
+
+/** How an oauth2.Config builds its RedirectURL, when it's one of the common forms */
+export function configRedirect(body: string): RedirectSource | undefined {
+  const expression = REDIRECT_FIELD_PATTERN.exec(body)?.[1];
+  if (!expression) return undefined;
+  for (const { pattern, source } of REDIRECT_EXPRESSIONS) {
+    const match = pattern.exec(expression);
+    if (match) return source(match);
+  }
+  return undefined;
+}
+
+/** Claims read from decoded ID tokens: map lookups and `json` tags of claims structs */
+export function claimReads(content: string): Array<{ claim: string; line: number; text: string }> {
+  if (!/\.Claims\(/.test(content)) return [];
+  const reads = findLines(content, CLAIM_INDEX_PATTERN).map(({ line, text, match }) => ({
+    claim: match[1],
+    line,
+    text,
+  }));
+  for (const match of content.matchAll(CLAIMS_STRUCT_PATTERN)) {
+    const start = match.index + match[0].length;
+    const end = content.indexOf('}', start);
+    const startLine = content.slice(0, start).split('\n').length;
+    for (const { line, text, match: tag } of findLines(content.slice(start, end), /json:"([^",]+)/)) {
+      reads.push({ claim: tag[1], line: startLine + line - 1, text });
+    }
+  }
+  return reads.sort((a, b) => a.line - b.line);
+}
+
+/** Provider named by an env var prefix, e.g. AUTH0_DOMAIN -> auth0 */
+function providerFromEnvVar(envVar: string): string | undefined {
+  return providerFromName(envVar.split('_')[0]);
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const goMod = await ctx.readFile('go.mod');
+  if (!goMod) return [];
+
+  const modules = Object.keys(MODULES).flatMap((module) => {
+    const pattern = new RegExp(`^\\s*(require\\s+)?${module.replace(/\./g, '\\.')}(/v\\d+)?\\s+(v\\S+)?`);
+    const hit = findLines(goMod, pattern)[0];
+    if (!hit) return [];
+    const [, , suffix = '', version] = hit.match;
+    return [{ module, line: hit.line, version, major: goMajor(module + suffix, version) }];
+  });
+  if (modules.length === 0) return [];
+
+  const findings: MigrationFinding[] = [];
+  const moduleProvider = modules.map((m) => MODULES[m.module]).find(Boolean);
+  const files = await ctx.files();
+
+  for (const file of files.filter((f) => SOURCE_FILE_PATTERN.test(f) && !f.endsWith('_test.go'))) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+
+    const issuers = findLines(content, URL_LITERAL_PATTERN).flatMap(({ line, text, match }) => {
+      const provider = providerFromIssuer(match[1]);
+      return provider ? [{ line, text, url: match[1], provider }] : [];
+    });
+    const envRefs = findLines(content, PROVIDER_ENV_PATTERN);
+    const provider =
+      issuers[0]?.provider ??
+      envRefs.map(({ match }) => providerFromEnvVar(match[1])).find(Boolean) ??
+      moduleProvider ??
+      'oidc';
+
+    for (const { line, text, url } of issuers) {
+      findings.push({
+        provider,
+        code: 'go-hardcoded-issuer',
+        severity: 'warning',
+        message: `Issuer ${url} is hardcoded in ${file}`,
+        file,
+        line,
+        evidence: text,
+        remediation: 'Drop the tenant URL; AuthKit settings come from WORKOS_* env vars',
+        confidence: 0.5,
+        details: { url, hardcoded: true, framework: 'go' },
+      });
+    }
+
+    for (const { line, match } of envRefs) {
+      const envVar = match[1];
+      const workosEnv = getProvider(provider)?.envMapping[envVar] ?? null;
+      findings.push({
+        provider,
+        code: 'go-env-ref',
+        severity: 'info',
+        message: workosEnv
+          ? `os.Getenv("${envVar}") maps to ${workosEnv}`
+          : `os.Getenv("${envVar}") is not needed with AuthKit`,
+        file,
+        line,
+        confidence: 0.2,
+        details: { envVar, workosEnv, framework: 'go' },
+      });
+    }
+
+    for (const { claim, line, text } of claimReads(content)) {
+      const { authkit, note } = mapClaim(claim);
+      findings.push({
+        provider,
+        code: 'go-claim-read',
+        severity: 'info',
+        message: `Reads the "${claim}" claim from the ID token`,
+        file,
+        line,
+        evidence: text,
+        remediation: authkit ? `Read ${authkit} from the AuthKit session` : note,
+        // Reading claims says nothing about which provider issued them
+        confidence: 0,
+        details: { claim, framework: 'go' },
+      });
+    }
+
+    for (const block of findOAuth2Configs(content)) {
+      const scopes = configScopes(block.body);
+      const redirectSource = configRedirect(block.body);
+      findings.push({
+        provider,
+        code: 'go-oauth2-config',
+        severity: 'info',
+        message: `oauth2.Config in ${file}`,
+        file,
+        line: block.line,
+        remediation: 'Replace the OAuth2 flow with the WorkOS Go SDK (GetAuthorizationURL / AuthenticateWithCode)',
+        confidence: provider === 'oidc' ? 0.3 : 0.4,
+        details: { framework: 'go', ...(scopes.length > 0 && { scopes }), ...(redirectSource && { redirectSource }) },
+      });
+
+      for (const { line, text, match } of findLines(block.body, FIELD_PATTERN)) {
+        const [, field, , envVar] = match;
+        const literal = match[4] ?? match[5];
+        const workosEnv = FIELD_TO_WORKOS_ENV[field];
+        const fieldLine = block.line + line - 1;
+        // Provider env vars are reported above; a non-provider name still needs mapping
+        if (envVar) {
+          if (PROVIDER_ENV_PATTERN.test(text)) continue;
+          findings.push({
+            provider,
+            code: 'go-oauth2-setting',
+            severity: 'info',
+            message: `${field} reads os.Getenv("${envVar}"); map it to ${workosEnv}`,
+            file,
+            line: fieldLine,
+            confidence: 0.1,
+            details: { setting: field, envVar, workosEnv, framework: 'go' },
+          });
+        } else if (field === 'ClientSecret' && literal) {
+          findings.push({
+            provider,
+            code: 'go-hardcoded-secret',
+            severity: 'warning',
+            // No evidence: the line is the secret
+            message: `ClientSecret is hardcoded in ${file}; the secret is embedded in source`,
+            file,
+            line: fieldLine,
+            remediation: `Rotate the secret (it is in version control) and read ${workosEnv} from the environment`,
+            confidence: 0.2,
+            details: { setting: field, workosEnv, hardcoded: true, framework: 'go' },
+          });
+        } else if (literal && (field !== 'ClientID' || looksLikeClientId(literal))) {
+          findings.push({
+            provider,
+            code: 'go-hardcoded-setting',
+            severity: field === 'ClientID' ? 'warning' : 'info',
+            message: `${field} is hardcoded ("${literal}"); read ${workosEnv} from the environment`,
+            file,
+            line: fieldLine,
+            evidence: text,
+            confidence: 0.2,
+            details: {
+              setting: field,
+              workosEnv,
+              hardcoded: true,
+              framework: 'go',
+              ...(field === 'RedirectURL' ? { redirectUri: literal } : {}),
+            },
+          });
+        }
+      }
+    }
+  }
+
+  for (const { module, line, version, major } of modules) {
+    // Recorded so the migration can pick guidance for modules whose API changed between majors
+    const sdk = SDK_VARIANTS[module] ? { sdk: module, sdkMajor: major, ...(version && { sdkVersion: version }) } : {};
+    findings.push({
+      provider: MODULES[module] ?? findings[0]?.provider ?? 'oidc',
+      code: 'go-dependency',
+      severity: 'info',
+      message: SDK_VARIANTS[module] && major !== null ? `${module} v${major} in go.mod` : `${module} in go.mod`,
+      file: 'go.mod',
+      line,
+      remediation: MODULES[module]
+        ? `Remove ${module} once login goes through AuthKit (go get github.com/workos/workos-go/v4)`
+        : 'Keep it if other code uses it; AuthKit login goes through github.com/workos/workos-go/v4',
+      confidence: MODULES[module] ? 0.4 : 0.1,
+      details: { module, framework: 'go', ...sdk },
+    });
+  }
+
+  return findings;
+}
+
+export const goOAuth2: ProviderDetector = {
+  name: 'go-oauth2',
+  description: 'golang.org/x/oauth2 and go-oidc login flows, and the Auth0 and Okta Go SDKs',
+  language: 'go',
+  files: /(^|\/)go\.mod$|\.go$/,
+  detect,
+};
@@ -0,0 +1,38 @@
+import type { ProviderDetector } from '../types.js';
+import { auth0Actions } from './auth0-actions.js';
+import { auth0Sdks } from './auth0-sdks.js';
+import { clerk } from './clerk.js';
+import { containerEnv } from './container-env.js';
+import { goJwks } from './go-jwks.js';
+import { goOAuth2 } from './go-oauth2.js';
+import { laravelSocialite } from './laravel-socialite.js';
+import { logout } from './logout.js';
+import { oauthState } from './oauth-state.js';
+import { refreshTokens } from './refresh-tokens.js';
+import { springSecurity } from './spring-security.js';
+import { supabase } from './supabase.js';
+import { templateAuth } from './template-auth.js';
+import { tenancy } from './tenancy.js';
+import { testReferences } from './test-references.js';
+
+/**
+ * All provider detectors, run in order.
+ * Each detector returns early when its language/framework markers are absent.
+ */
+export const DETECTORS: ProviderDetector[] = [
+  laravelSocialite,
+  springSecurity,
+  goOAuth2,
+  goJwks,
+  clerk,
+  supabase,
+  auth0Sdks,
+  auth0Actions,
+  logout,
+  containerEnv,
+  tenancy,
+  refreshTokens,
+  oauthState,
+  templateAuth,
+  testReferences,
+];
@@ -0,0 +1,154 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { laravelSocialite, parseServicesConfig } from './laravel-socialite.js';
+
+const SERVICES_PHP = `<?php
+
+return [
+    'mailgun' => [
+        'domain' => env('MAILGUN_DOMAIN'),
+        'secret' => env('MAILGUN_SECRET'),
+    ],
+
+    'auth0' => [
+        'client_id' => env('AUTH0_CLIENT_ID'),
+        'client_secret' => env('AUTH0_CLIENT_SECRET'),
+        'redirect' => env('AUTH0_REDIRECT_URI', 'http://localhost:8000/auth/callback'),
+        'base_url' => env('AUTH0_BASE_URL'),
+    ],
+];
+`;
+
+const CONTROLLER_PHP = `<?php
+
+namespace App\\Http\\Controllers\\Auth;
+
+use Laravel\\Socialite\\Facades\\Socialite;
+
+class LoginController extends Controller
+{
+    public function redirect()
+    {
+        return Socialite::driver('auth0')->redirect();
+    }
+
+    public function callback()
+    {
+        $user = Socialite::driver('auth0')->user();
+    }
+}
+`;
+
+describe('laravel-socialite detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'socialite-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  describe('parseServicesConfig', () => {
+    it('extracts OAuth driver blocks with env() references and defaults', () => {
+      const drivers = parseServicesConfig(SERVICES_PHP);
+
+      expect(drivers).toHaveLength(1);
+      expect(drivers[0].driver).toBe('auth0');
+      expect(drivers[0].line).toBe(9);
+      expect(drivers[0].envRefs).toEqual({
+        client_id: 'AUTH0_CLIENT_ID',
+        client_secret: 'AUTH0_CLIENT_SECRET',
+        redirect: 'AUTH0_REDIRECT_URI',
+        base_url: 'AUTH0_BASE_URL',
+      });
+      expect(drivers[0].defaults.redirect).toBe('http://localhost:8000/auth/callback');
+    });
+  });
+
+  it('returns nothing without laravel/socialite', async () => {
+    write('composer.json', JSON.stringify({ require: { 'laravel/framework': '^11.0' } }));
+
+    expect(await laravelSocialite.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('reports dependency, config, env mappings and driver usage for Auth0', async () => {
+    write('composer.json', JSON.stringify({ require: { 'laravel/socialite': '^5.12' } }, null, 2));
+    write('config/services.php', SERVICES_PHP);
+    write('app/Http/Controllers/Auth/LoginController.php', CONTROLLER_PHP);
+    write('.env', 'AUTH0_CLIENT_ID=abc\nAUTH0_CLIENT_SECRET=shh\n');
+
+    const findings = await laravelSocialite.detect(createScanContext(root));
+
+    expect(findings.every((f) => f.provider === 'auth0')).toBe(true);
+    expect(findings.map((f) => f.code)).toEqual(
+      expect.arrayContaining([
+        'socialite-dependency',
+        'socialite-config',
+        'socialite-env-ref',
+        'socialite-driver-usage',
+        'socialite-callback',
+      ]),
+    );
+
+    const callback = findings.find((f) => f.code === 'socialite-callback');
+    expect(callback?.file).toBe('app/Http/Controllers/Auth/LoginController.php');
+    expect(callback?.line).toBe(16);
+
+    const secretRef = findings.find((f) => f.details?.envVar === 'AUTH0_CLIENT_SECRET');
+    expect(secretRef?.details?.workosEnv).toBe('WORKOS_API_KEY');
+    expect(secretRef?.severity).toBe('info');
+
+    // Not in .env and no default: flagged
+    const baseUrlRef = findings.find((f) => f.details?.envVar === 'AUTH0_BASE_URL');
+    expect(baseUrlRef?.severity).toBe('warning');
+    expect(baseUrlRef?.details?.workosEnv).toBeNull();
+  });
+
+  it('flags client settings hardcoded in config/services.php', async () => {
+    write('composer.json', JSON.stringify({ require: { 'laravel/socialite': '^5.12' } }));
+    write(
+      'config/services.php',
+      `<?php
+
+return [
+    'okta' => [
+        'client_id' => '0oa8f2k1XbQz7LpN4d5',
+        'client_secret' => env('OKTA_CLIENT_SECRET', 'not-a-real-secret'),
+        'redirect' => env('OKTA_REDIRECT_URI'),
+        'base_url' => 'https://acme.okta.com',
+    ],
+];
+`,
+    );
+
+    const findings = await laravelSocialite.detect(createScanContext(root));
+    const hardcoded = findings.filter((f) => f.code === 'socialite-hardcoded-setting');
+
+    expect(hardcoded.map((f) => f.details?.setting).sort()).toEqual(['base_url', 'client_id', 'client_secret']);
+    expect(hardcoded.every((f) => f.severity === 'warning')).toBe(true);
+
+    const secret = hardcoded.find((f) => f.details?.setting === 'client_secret');
+    expect(secret?.line).toBe(6);
+    expect(secret?.message).toContain('embedded');
+    expect(JSON.stringify(findings)).not.toContain('not-a-real-secret');
+  });
+
+  it('attributes social-login drivers to socialite', async () => {
+    write('composer.json', JSON.stringify({ require: { 'laravel/socialite': '^5.12' } }));
+    write('routes/web.php', "Route::get('/auth/github', fn () => Socialite::driver('github')->redirect());");
+
+    const findings = await laravelSocialite.detect(createScanContext(root));
+
+    expect(findings.every((f) => f.provider === 'socialite')).toBe(true);
+  });
+});
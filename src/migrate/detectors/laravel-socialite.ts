@@ -0,0 +1,254 @@
+import { looksLikeClientId, providerFromIssuer, providerFromName } from '../providers.js';
+import { findLines } from '../scan.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/** Socialite config keys and the WorkOS env var that replaces each */
+const SETTING_TO_WORKOS_ENV: Record<string, string | null> = {
+  client_id: 'WORKOS_CLIENT_ID',
+  client_secret: 'WORKOS_API_KEY',
+  redirect: 'WORKOS_REDIRECT_URI',
+  base_url: null,
+  domain: null,
+  tenant: null,
+};
+
+const DRIVER_USAGE_PATTERN = /Socialite::(?:driver|with)\(\s*['"]([\w-]+)['"]\s*\)/;
+const ENV_CALL_PATTERN = /'(\w+)'\s*=>\s*env\(\s*['"]([A-Z0-9_]+)['"](?:\s*,\s*([^)]+))?\)/g;
+const LITERAL_PATTERN = /'(\w+)'\s*=>\s*(['"])([^'"]*)\2/g;
+
+export interface SocialiteDriverConfig {
+  driver: string;
+  line: number;
+  /** Config key -> env var name (from `env('NAME')`) */
+  envRefs: Record<string, string>;
+  /** Config key -> literal default passed to env() */
+  defaults: Record<string, string>;
+  /** Config key -> value written directly into the config, without env() */
+  literals: Record<string, string>;
+}
+
+/** Drivers for full identity providers map to that provider; social logins stay 'socialite' */
+function providerForDriver(driver: string): string {
+  return providerFromName(driver) ?? 'socialite';
+}
+
+/**
+ * Extract driver blocks (`'auth0' => [ ... ]`) from config/services.php.
+ * Only blocks with OAuth client settings are treated as Socialite drivers.
+ */
+export function parseServicesConfig(content: string): SocialiteDriverConfig[] {
+  const drivers: SocialiteDriverConfig[] = [];
+  const blockStart = /['"]([\w-]+)['"]\s*=>\s*\[/g;
+  let match: RegExpExecArray | null;
+
+  while ((match = blockStart.exec(content)) !== null) {
+    let depth = 1;
+    let i = match.index + match[0].length;
+    while (i < content.length && depth > 0) {
+      if (content[i] === '[') depth++;
+      else if (content[i] === ']') depth--;
+      i++;
+    }
+    const body = content.slice(match.index + match[0].length, i - 1);
+    if (!/['"]client_id['"]/.test(body)) continue;
+
+    const envRefs: Record<string, string> = {};
+    const defaults: Record<string, string> = {};
+    for (const ref of body.matchAll(ENV_CALL_PATTERN)) {
+      envRefs[ref[1]] = ref[2];
+      if (ref[3]) defaults[ref[1]] = ref[3].trim().replace(/^['"]|['"]$/g, '');
+    }
+    const literals: Record<string, string> = {};
+    for (const literal of body.matchAll(LITERAL_PATTERN)) {
+      if (literal[3]) literals[literal[1]] = literal[3];
+    }
+
+    drivers.push({
+      driver: match[1],
+      line: content.slice(0, match.index).split('\n').length,
+      envRefs,
+      defaults,
+      literals,
+    });
+  }
+
+  return drivers;
+}
+
+function parseDotenvKeys(content: string | null): Set<string> {
+  const keys = new Set<string>();
+  for (const line of (content ?? '').split(/\r?\n/)) {
+    const m = /^\s*(?:export\s+)?([A-Z0-9_]+)\s*=/.exec(line);
+    if (m) keys.add(m[1]);
+  }
+  return keys;
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const composerRaw = await ctx.readFile('composer.json');
+  if (!composerRaw) return [];
+
+  let composer: { require?: Record<string, string>; 'require-dev'?: Record<string, string> };
+  try {
+    composer = JSON.parse(composerRaw);
+  } catch {
+    return [];
+  }
+  const deps = { ...composer.require, ...composer['require-dev'] };
+  if (!deps['laravel/socialite']) return [];
+
+  const findings: MigrationFinding[] = [];
+  const composerLine = (pkg: string) => findLines(composerRaw, new RegExp(`"${pkg.replace('/', '\\/')}"`))[0];
+
+  // Drivers referenced by config and code; used to attribute the dependency finding
+  const servicesConfig = await ctx.readFile('config/services.php');
+  const drivers = servicesConfig ? parseServicesConfig(servicesConfig) : [];
+  const driverNames = new Set(drivers.map((d) => d.driver));
+
+  const files = await ctx.files();
+  const codeFiles = files.filter((f) => /^(app|routes)\/.*\.php$/.test(f));
+  for (const file of codeFiles) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    for (const { line, text, match } of findLines(content, DRIVER_USAGE_PATTERN)) {
+      const driver = match[1];
+      driverNames.add(driver);
+      const isCallback = /->(?:stateless\(\)->)?user\(\)/.test(text);
+      findings.push({
+        provider: providerForDriver(driver),
+        code: isCallback ? 'socialite-callback' : 'socialite-driver-usage',
+        severity: 'warning',
+        message: isCallback
+          ? `Socialite ${driver} callback reads the user from the provider`
+          : `Socialite ${driver} driver used for login redirect`,
+        file,
+        line,
+        evidence: text,
+        remediation: isCallback
+          ? 'Replace with the AuthKit callback: exchange the code via WorkOS and read the AuthKit user'
+          : 'Redirect to the AuthKit authorization URL instead of the Socialite driver',
+        confidence: 0.6,
+        details: { driver, framework: 'laravel' },
+      });
+    }
+  }
+
+  const envKeys = new Set([
+    ...parseDotenvKeys(await ctx.readFile('.env')),
+    ...parseDotenvKeys(await ctx.readFile('.env.example')),
+  ]);
+
+  const envLine = (envVar: string) =>
+    findLines(servicesConfig ?? '', new RegExp(`env\\(\\s*['"]${envVar}['"]`))[0]?.line;
+
+  for (const config of drivers) {
+    // A social driver pointed at a known tenant URL is really that provider
+    const issuer = Object.values(config.literals).map(providerFromIssuer).find(Boolean);
+    const driverProvider = providerForDriver(config.driver);
+    const provider = driverProvider === 'socialite' && issuer ? issuer : driverProvider;
+    findings.push({
+      provider,
+      code: 'socialite-config',
+      severity: 'info',
+      message: `Socialite ${config.driver} driver configured in config/services.php`,
+      file: 'config/services.php',
+      line: config.line,
+      remediation: 'Replace the driver block with WorkOS settings read from WORKOS_* env vars',
+      confidence: driverNames.has(config.driver) ? 0.4 : 0.2,
+      details: {
+        driver: config.driver,
+        framework: 'laravel',
+        envRefs: config.envRefs,
+        redirectUri: config.literals.redirect ?? config.defaults.redirect,
+      },
+    });
+
+    for (const [setting, envVar] of Object.entries(config.envRefs)) {
+      const workosEnv = SETTING_TO_WORKOS_ENV[setting];
+      const defined = envKeys.has(envVar);
+      findings.push({
+        provider,
+        code: 'socialite-env-ref',
+        severity: defined || config.defaults[setting] ? 'info' : 'warning',
+        message: workosEnv
+          ? `${config.driver}.${setting} reads env('${envVar}'); map it to ${workosEnv}`
+          : `${config.driver}.${setting} reads env('${envVar}'); not needed with AuthKit`,
+        file: 'config/services.php',
+        line: envLine(envVar),
+        remediation: defined ? undefined : `${envVar} is not defined in .env or .env.example`,
+        confidence: 0.1,
+        details: { setting, envVar, workosEnv, definedInEnvFile: defined, hasDefault: setting in config.defaults },
+      });
+    }
+
+    // Values written into the config (or as env() defaults) live in version control
+    const hardcoded = { ...config.defaults, ...config.literals };
+    for (const [setting, value] of Object.entries(hardcoded)) {
+      if (!value || value === 'null') continue;
+      const isSecret = setting === 'client_secret';
+      const isIssuer = providerFromIssuer(value) !== undefined;
+      if (!isSecret && !isIssuer && !(setting === 'client_id' && looksLikeClientId(value))) continue;
+
+      const workosEnv = SETTING_TO_WORKOS_ENV[setting] ?? null;
+      const line = findLines(servicesConfig ?? '', new RegExp(`['"]${setting}['"]\\s*=>`)).find(
+        (m) => m.line >= config.line,
+      )?.line;
+      findings.push({
+        provider,
+        code: 'socialite-hardcoded-setting',
+        severity: 'warning',
+        message: isSecret
+          ? `${config.driver}.client_secret is embedded in config/services.php`
+          : `${config.driver}.${setting} is hardcoded ('${value}') in config/services.php`,
+        file: 'config/services.php',
+        line,
+        remediation: isSecret
+          ? 'Rotate the secret (it is in version control) and read WORKOS_API_KEY from the environment'
+          : workosEnv
+            ? `Read ${workosEnv} from the environment instead`
+            : 'Drop the tenant URL; AuthKit does not need it',
+        confidence: isIssuer ? 0.5 : 0.2,
+        details: { setting, workosEnv, hardcoded: true, framework: 'laravel' },
+      });
+    }
+  }
+
+  const providersSeen = new Set(findings.map((f) => f.provider));
+  const dependencyProvider = providersSeen.size === 1 ? [...providersSeen][0] : 'socialite';
+  findings.unshift({
+    provider: dependencyProvider,
+    code: 'socialite-dependency',
+    severity: 'info',
+    message: `laravel/socialite ${deps['laravel/socialite']} in composer.json`,
+    file: 'composer.json',
+    line: composerLine('laravel/socialite')?.line,
+    remediation: 'Remove laravel/socialite once login goes through AuthKit (composer require workos/workos-php-laravel)',
+    confidence: 0.3,
+    details: { framework: 'laravel', drivers: [...driverNames] },
+  });
+
+  for (const pkg of Object.keys(deps).filter((d) => d.startsWith('socialiteproviders/'))) {
+    const driver = pkg.slice('socialiteproviders/'.length);
+    findings.push({
+      provider: providerForDriver(driver),
+      code: 'socialite-provider-package',
+      severity: 'info',
+      message: `${pkg} adds the ${driver} Socialite driver`,
+      file: 'composer.json',
+      line: composerLine(pkg)?.line,
+      remediation: `Remove ${pkg} and its event listener registration after migrating`,
+      confidence: 0.3,
+      details: { driver, framework: 'laravel' },
+    });
+  }
+
+  return findings;
+}
+
+export const laravelSocialite: ProviderDetector = {
+  name: 'laravel-socialite',
+  description: 'Laravel Socialite drivers, their config/services.php settings and env vars',
+  language: 'php',
+  files: /(^|\/)composer\.json$|^config\/services\.php$|^(app|routes)\/.*\.php$|^\.env(\.example)?$/,
+  detect,
+};
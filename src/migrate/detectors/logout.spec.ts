@@ -0,0 +1,134 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { extractLogoutEndpoints } from '../logout.js';
+import { buildMigrationPrompt } from '../prompt.js';
+import { createScanContext } from '../scan.js';
+import { logout } from './logout.js';
+
+const PACKAGE_JSON = JSON.stringify({ dependencies: { express: '^4.19.0', '@auth0/auth0-react': '^2.2.0' } });
+
+const SERVER = `const express = require('express');
+const app = express();
+
+app.get('/logout', (req, res) => {
+  req.session.destroy(() => {
+    res.redirect(
+      \`https://\${process.env.AUTH0_DOMAIN}/v2/logout?client_id=\${process.env.AUTH0_CLIENT_ID}&returnTo=\${encodeURIComponent('http://localhost:3000/goodbye')}&federated\`,
+    );
+  });
+});
+
+app.post('/backchannel-logout', (req, res) => {
+  revokeSessions(req.body.logout_token);
+  res.sendStatus(200);
+});
+`;
+
+const SESSIONS = `const router = require('express').Router();
+
+router.post('/signout', (req, res) => {
+  res.clearCookie('app_session');
+  res.redirect('/login');
+});
+`;
+
+const NAV = `import { useAuth0 } from '@auth0/auth0-react';
+
+export function SignOutButton() {
+  const { logout } = useAuth0();
+  return <button onClick={() => logout({ logoutParams: { returnTo: window.location.origin } })}>Sign out</button>;
+}
+`;
+
+describe('logout detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'logout-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('finds federated logout redirects with their return URL and federated flag', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('server.js', SERVER);
+
+    const findings = await logout.detect(createScanContext(root));
+
+    expect(findings.find((f) => f.code === 'idp-logout')).toMatchObject({
+      provider: 'auth0',
+      file: 'server.js',
+      line: 7,
+      message: 'Logout redirects to the Auth0 logout (/v2/logout), returning to http://localhost:3000/goodbye',
+      manual: true,
+      details: { style: 'federated', endpoint: '/v2/logout', returnTo: 'http://localhost:3000/goodbye', federated: true },
+    });
+    // The route redirects to the provider, so it isn't also reported as a local logout
+    expect(findings.some((f) => f.code === 'local-logout')).toBe(false);
+    expect(findings.find((f) => f.code === 'idp-backchannel-logout')).toMatchObject({ line: 12, manual: true });
+  });
+
+  it('finds SDK logout calls and logouts that only clear the app session', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('src/Nav.tsx', NAV);
+    write('routes/sessions.js', SESSIONS);
+
+    const findings = await logout.detect(createScanContext(root));
+
+    expect(findings.find((f) => f.code === 'idp-logout')).toMatchObject({
+      file: 'src/Nav.tsx',
+      line: 5,
+      details: { endpoint: 'logout()' },
+    });
+    expect(findings.find((f) => f.code === 'local-logout')).toMatchObject({
+      provider: 'auth0',
+      file: 'routes/sessions.js',
+      line: 3,
+      severity: 'info',
+      confidence: 0,
+      details: { style: 'local', returnTo: '/login' },
+    });
+  });
+
+  it('ignores logouts when no provider is involved, and tests', async () => {
+    write('package.json', JSON.stringify({ dependencies: { express: '^4.19.0' } }));
+    write('routes/sessions.js', SESSIONS);
+    write('test/server.spec.js', SERVER);
+
+    expect(await logout.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('maps each sign-out to the AuthKit logout URL in the migration prompt', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('server.js', SERVER);
+    write('routes/sessions.js', SESSIONS);
+    const findings = await logout.detect(createScanContext(root));
+
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.3,
+      envMapping: {},
+      guidance: [],
+      findings,
+      logoutEndpoints: extractLogoutEndpoints(findings),
+    });
+
+    expect(prompt).toContain('### Logout');
+    expect(prompt).toContain('- routes/sessions.js:3: clears the app session only → AuthKit logout URL, returnTo /login');
+    expect(prompt).toContain(
+      '- server.js:7: redirects to /v2/logout → AuthKit logout URL, returnTo http://localhost:3000/goodbye; ' +
+        '`federated` upstream sign-out has no AuthKit equivalent, drop it',
+    );
+  });
+});
@@ -0,0 +1,195 @@
+import { findReturnTo, LOGOUT_ENDPOINT_PATTERN, logoutEndpointProvider, type ReturnTo } from '../logout.js';
+import { getProvider } from '../providers.js';
+import { findLines } from '../scan.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/**
+ * Sign-outs in any language: redirects to a provider's logout endpoint,
+ * SDK logout calls, logout routes that only clear the app's session, and
+ * back/front-channel logout receivers. See logout.ts for how each maps to
+ * AuthKit.
+ */
+
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?|go|py|rb|php|java|kt|cs)$/;
+const TEST_FILE_PATTERN = /(\.(test|spec)\.[^/]+$|_test\.go$|(^|\/)(__tests__|tests?|spec)\/)/;
+const MANIFESTS = [
+  'package.json',
+  'go.mod',
+  'composer.json',
+  'requirements.txt',
+  'pyproject.toml',
+  'Gemfile',
+  'pom.xml',
+  'build.gradle',
+];
+
+/** SDK packages naming the provider, for sign-outs that don't name it themselves */
+const MANIFEST_PROVIDERS: Array<{ pattern: RegExp; provider: string }> = [
+  { pattern: /auth0|express-openid-connect/i, provider: 'auth0' },
+  { pattern: /@okta\/|okta-/i, provider: 'okta' },
+  { pattern: /@azure\/msal|\bmsal\b/i, provider: 'azure-ad' },
+  { pattern: /keycloak/i, provider: 'keycloak' },
+  { pattern: /amazon-cognito|cognito/i, provider: 'cognito' },
+];
+
+/** SDK calls that redirect to the provider's logout, and the provider each belongs to */
+const SDK_LOGOUT_CALLS: Array<{ pattern: RegExp; provider: string }> = [
+  // express-openid-connect, nextjs-auth0
+  { pattern: /\b(?:res\.oidc\.logout|handleLogout)\s*\(/, provider: 'auth0' },
+  // auth0-spa-js, auth0-react
+  { pattern: /\blogout\(\s*\{\s*(?:returnTo|logoutParams)\b/, provider: 'auth0' },
+  // okta-auth-js
+  { pattern: /\bsignOut\(\s*\{\s*postLogoutRedirectUri\b/, provider: 'okta' },
+  // msal-browser
+  { pattern: /\blogout(?:Redirect|Popup)\(\s*\{\s*postLogoutRedirectUri\b/, provider: 'azure-ad' },
+];
+/** Auth0's upstream IdP sign-out, `?federated` or `federated: true` */
+const FEDERATED_PATTERN = /[?&]federated\b|\bfederated\s*[:=]\s*(?:true|["']?1)/;
+/** Endpoints the provider calls to log the user out of the app */
+const CHANNEL_LOGOUT_PATTERN =
+  /(['"`])\/?(?:[\w-]+\/)*(?:back|front)[-_]?channel[-_]?logout\/?\1|\blogout_token\b|\bbackchannel_logout_uri\b/i;
+
+/** Calls and annotations registering a route, e.g. `r.GET(`, `@app.route(`, `Route::post(`, `@GetMapping(` */
+const ROUTE_REGISTRATIONS = [
+  '\\.(?:get|post|delete|all|route|any|handle|handlefunc)\\(',
+  '@\\w+\\.(?:route|get|post)\\(',
+  '\\bRoute::\\w+\\(',
+  '@(?:get|post|request)Mapping\\(',
+  '\\bpath\\(',
+  '^\\s*(?:get|post|delete|match)\\s+',
+];
+/** A quoted logout path, e.g. `"/logout"`, `'/auth/sign-out'` */
+const LOGOUT_PATH = `(['"\`])\\/?(?:[\\w-]+\\/)*(?:log-?out|log_out|sign-?out|sign_out)\\/?\\1`;
+/** A logout route, e.g. `r.GET("/logout"`, `auth.Handle("GET", "/signout"`, `app.post('/auth/log-out'` */
+const LOGOUT_ROUTE_PATTERN = new RegExp(`(?:${ROUTE_REGISTRATIONS.join('|')})[^\\n]*?${LOGOUT_PATH}`, 'i');
+/** Lines after a route registration read as its handler */
+const HANDLER_WINDOW = 15;
+
+async function manifestProvider(ctx: ScanContext): Promise<string | undefined> {
+  for (const file of MANIFESTS) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    const match = MANIFEST_PROVIDERS.find(({ pattern }) => pattern.test(content));
+    if (match) return match.provider;
+  }
+  return undefined;
+}
+
+function returnToDetails(returnTo: ReturnTo | undefined): Record<string, string> {
+  if (returnTo?.url) return { returnTo: returnTo.url };
+  return returnTo?.envVar ? { returnToEnvVar: returnTo.envVar } : {};
+}
+
+function describeReturnTo(returnTo: ReturnTo | undefined): string {
+  const target = returnTo?.url ?? (returnTo?.envVar && `the URL in ${returnTo.envVar}`);
+  return target ? `, returning to ${target}` : '';
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => SOURCE_FILE_PATTERN.test(f) && !TEST_FILE_PATTERN.test(f));
+  const fromManifest = await manifestProvider(ctx);
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    const lines = content.split(/\r?\n/);
+    const windowFrom = (line: number) => lines.slice(line - 1, line - 1 + HANDLER_WINDOW).join('\n');
+
+    const federatedLines = new Set<number>();
+    const endpoints = findLines(content, LOGOUT_ENDPOINT_PATTERN).map(({ line, text, match }) => ({
+      line,
+      text,
+      endpoint: match[0],
+      provider: logoutEndpointProvider(text) ?? (match[0].startsWith('/') ? undefined : fromManifest),
+    }));
+    const sdkCalls = SDK_LOGOUT_CALLS.flatMap(({ pattern, provider }) =>
+      findLines(content, pattern).map(({ line, text, match }) => ({
+        line,
+        text,
+        endpoint: match[0].replace(/\(.*$/s, '()'),
+        provider: fromManifest === provider ? provider : undefined,
+      })),
+    );
+
+    for (const { line, text, endpoint, provider } of [...endpoints, ...sdkCalls]) {
+      if (!provider || federatedLines.has(line)) continue;
+      federatedLines.add(line);
+      const handler = windowFrom(line);
+      const returnTo = findReturnTo(handler);
+      const federated = FEDERATED_PATTERN.test(handler);
+      const displayName = getProvider(provider)?.displayName ?? 'OIDC provider';
+      findings.push({
+        provider,
+        code: 'idp-logout',
+        severity: 'warning',
+        message: `Logout redirects to the ${displayName} logout (${endpoint})${describeReturnTo(returnTo)}`,
+        file,
+        line,
+        evidence: text,
+        remediation:
+          'Redirect to the AuthKit logout URL for the session instead (getLogoutUrl; GetLogoutURL in Go), ' +
+          'with the same return URL listed as a sign-out redirect in the WorkOS dashboard',
+        ...(federated && { manual: true }),
+        confidence: 0.3,
+        details: { style: 'federated', endpoint, ...returnToDetails(returnTo), ...(federated && { federated: true }) },
+      });
+    }
+
+    const provider = endpoints.find((e) => e.provider)?.provider ?? fromManifest;
+    if (!provider) continue;
+
+    let channelLine = -HANDLER_WINDOW;
+    for (const { line, text } of findLines(content, CHANNEL_LOGOUT_PATTERN)) {
+      // One finding per receiver: its route and the logout_token it reads
+      if (line < channelLine + HANDLER_WINDOW) continue;
+      channelLine = line;
+      findings.push({
+        provider,
+        code: 'idp-backchannel-logout',
+        severity: 'warning',
+        message: 'Receives logout notifications from the provider (back/front-channel logout)',
+        file,
+        line,
+        evidence: text,
+        remediation: 'WorkOS does not call back on logout; revoke local sessions from session.revoked events',
+        manual: true,
+        noEquivalent: true,
+        confidence: 0.2,
+      });
+    }
+
+    for (const { line, text } of findLines(content, LOGOUT_ROUTE_PATTERN)) {
+      const handler = windowFrom(line);
+      // A route whose handler redirects to the provider was reported above
+      if ([...federatedLines].some((l) => l >= line && l < line + HANDLER_WINDOW)) continue;
+      if (CHANNEL_LOGOUT_PATTERN.test(text)) continue;
+      const returnTo = findReturnTo(handler);
+      findings.push({
+        provider,
+        code: 'local-logout',
+        severity: 'info',
+        message: `Logout clears the app's session only${describeReturnTo(returnTo)}`,
+        file,
+        line,
+        evidence: text,
+        remediation:
+          'Also redirect to the AuthKit logout URL, or the WorkOS session stays signed in and the next login ' +
+          'skips the sign-in page',
+        // Every app has a logout; it says nothing about the provider
+        confidence: 0,
+        details: { style: 'local', ...returnToDetails(returnTo) },
+      });
+    }
+  }
+
+  return findings;
+}
+
+export const logout: ProviderDetector = {
+  name: 'logout',
+  description: 'Local and federated sign-outs (provider logout URLs, SDK logout calls) to move to AuthKit logout',
+  language: 'any',
+  files: new RegExp(`^(${MANIFESTS.join('|').replace(/\./g, '\\.')})$|${SOURCE_FILE_PATTERN.source}`),
+  detect,
+};
@@ -0,0 +1,111 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { extractOAuthStateUses, oauthStateWarning } from '../oauth-state.js';
+import { buildMigrationPrompt } from '../prompt.js';
+import { createScanContext } from '../scan.js';
+import { oauthState } from './oauth-state.js';
+
+const CONSTANT_GO = `package main
+
+func main() {
+	r.GET("/login", func(c *gin.Context) {
+		c.Redirect(http.StatusTemporaryRedirect, oauth2Config.AuthCodeURL("state"))
+	})
+}
+`;
+
+const SESSION_JS = `import crypto from 'node:crypto';
+
+app.get('/login', (req, res) => {
+  const state = crypto.randomBytes(32).toString('hex');
+  const nonce = crypto.randomUUID();
+  req.session.oauthState = state;
+  req.session.nonce = nonce;
+  res.redirect(\`https://\${domain}/authorize?response_type=code&state=\${state}&nonce=\${nonce}\`);
+});
+
+app.get('/callback', async (req, res) => {
+  if (req.query.state !== req.session.oauthState) return res.status(400).send('Invalid state');
+  const claims = await verify(tokens.id_token);
+  if (claims.nonce !== req.session.nonce) return res.status(400).send('Invalid nonce');
+});
+`;
+
+describe('oauth-state detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'oauth-state-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('flags a constant state as having no CSRF protection', async () => {
+    write('main.go', CONSTANT_GO);
+
+    const findings = await oauthState.detect(createScanContext(root));
+
+    expect(findings.map((f) => [f.code, f.file, f.line, f.severity])).toEqual([
+      ['oauth-state-constant', 'main.go', 5, 'warning'],
+    ]);
+    expect(oauthStateWarning(findings)).toBe(
+      '1 login(s) used a constant OAuth state (main.go:5), which gives no CSRF protection; ' +
+        'the migrated login generates a random state per sign-in and checks it in the callback.',
+    );
+  });
+
+  it('finds generated and checked state and nonce, with where they are kept', async () => {
+    write('server/auth.js', SESSION_JS);
+
+    const findings = await oauthState.detect(createScanContext(root));
+
+    expect(findings.map((f) => [f.code, f.line, f.details?.storage])).toEqual([
+      ['oauth-state-generated', 4, 'session'],
+      ['oauth-nonce-generated', 5, 'session'],
+      ['oauth-state-validated', 12, undefined],
+      ['oauth-nonce-validated', 14, undefined],
+    ]);
+    expect(findings.every((f) => f.provider === '*' && f.confidence === 0 && !f.manual)).toBe(true);
+    expect(oauthStateWarning(findings)).toBeNull();
+  });
+
+  it('puts the state steps and locations in the migration prompt', async () => {
+    write('main.go', CONSTANT_GO);
+    write('server/auth.js', SESSION_JS);
+    const uses = extractOAuthStateUses(await oauthState.detect(createScanContext(root)));
+
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.9,
+      envMapping: {},
+      guidance: [],
+      findings: [],
+      oauthState: uses,
+    });
+    expect(prompt).toContain('### OAuth state');
+    expect(prompt).toContain('The old login used a constant OAuth state');
+    expect(prompt).toContain('- main.go:5: constant OAuth state (no CSRF protection)');
+    expect(prompt).toContain('- server/auth.js:4: generates a random state, kept in the session');
+  });
+
+  it('ignores UI state, comparisons with literals and tests', async () => {
+    write(
+      'src/Login.tsx',
+      "const [state, setState] = useState('idle');\nif (state === 'loading') return null;\nconst href = '/oauth/start';",
+    );
+    write('auth/login_test.go', CONSTANT_GO);
+
+    expect(await oauthState.detect(createScanContext(root))).toEqual([]);
+  });
+});
@@ -0,0 +1,183 @@
+import { findLines } from '../scan.js';
+import {
+  ANY_PROVIDER,
+  type MigrationFinding,
+  type OAuthStateUse,
+  type ProviderDetector,
+  type ScanContext,
+} from '../types.js';
+
+/**
+ * OAuth `state` and OIDC `nonce` handling in hand-written login flows,
+ * whichever provider they sign in with: a constant state (the quickstarts'
+ * `AuthCodeURL("state")`, which protects nothing), a random state or nonce
+ * generated per sign-in and where it's kept, and the callback's check of
+ * it. A migration that rewrites the login must not drop that check, so
+ * these are reported and the migrated flow keeps an equivalent one with
+ * AuthKit's state (see oauth-state.ts).
+ */
+
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?|go|py|rb|php|java|kt|cs)$/;
+const TEST_FILE_PATTERN = /(\.(test|spec)\.[^/]+$|_test\.go$|(^|\/)(__tests__|tests?|spec)\/)/;
+
+/** Files that build or handle an authorization request; other uses of "state" are left alone */
+const OAUTH_FILE_PATTERN = new RegExp(
+  [
+    String.raw`\bAuthCodeURL\b`,
+    String.raw`\bauthorization_url\b`,
+    String.raw`\bauthorize_redirect\b`,
+    String.raw`\/authorize\b`,
+    String.raw`\bredirect_uri\b`,
+    String.raw`\bresponse_type\b`,
+    String.raw`\boauth2?\b`,
+  ].join('|'),
+  'i',
+);
+
+/** `state`, `oauthState`, `oauth_state`, `expectedNonce`, ..., but not `useState` or `setState` */
+const VALUE_PATTERN = new RegExp(
+  String.raw`\b(?:(?:oauth|csrf|auth|login|expected|saved|stored|session)_?)?` +
+    String.raw`(?:state|State|STATE|nonce|Nonce|NONCE)\b`,
+);
+const NONCE_PATTERN = /nonce/i;
+
+/** A literal state: Go's AuthCodeURL("..."), `state=abc` in a URL, `state: "abc"` in options */
+const CONSTANT_PATTERN = new RegExp(
+  [
+    String.raw`\.AuthCodeURL\(\s*"[^"]*"`,
+    String.raw`[?&]state=[\w-]+(?:['"\x60&#]|$)`,
+    String.raw`\bstate['"]?\s*[:=]\s*['"][\w-]*['"]`,
+  ].join('|'),
+);
+/** Random sources a state or nonce is made from */
+const RANDOM_PATTERN = new RegExp(
+  [
+    String.raw`\brand\.(?:Read|Text)\(`,
+    String.raw`\brandomBytes\(`,
+    String.raw`\brandomUUID\(`,
+    String.raw`\bgetRandomValues\(`,
+    String.raw`\bsecrets\.token_(?:urlsafe|hex)\(`,
+    String.raw`\bSecureRandom\b`,
+    String.raw`\bgenerators\.(?:state|nonce)\(`,
+    String.raw`\bStr::random\(`,
+    String.raw`\brandom_bytes\(`,
+    String.raw`\buuid\.New(?:String)?\(`,
+    String.raw`\buuidv4\(`,
+    String.raw`\bnanoid\(`,
+  ].join('|'),
+);
+/** A comparison, but not against a literal (`state === 'loading'`) */
+const COMPARISON_PATTERN =
+  /(?:!==|!=|===|==)(?!=)\s*(?!['"`\s])|\bcompare_digest\(|\bConstantTimeCompare\(|\bhmac\.Equal\(|\btimingSafeEqual\(|\bhash_equals\(|\.equals\(/;
+const STORAGE_SINKS: Array<{ pattern: RegExp; storage: NonNullable<OAuthStateUse['storage']> }> = [
+  {
+    pattern: /\b(?:SetCookie|setCookie|set_cookie|setcookie)\(|\bcookies?\s*\.\s*set\(|\.cookie\(|\bhttp\.Cookie\s*\{/,
+    storage: 'cookie',
+  },
+  {
+    pattern: /\bsession\.(?:Values|Set|set|put)\b|\breq\.session\b|\bsession\[|\$_SESSION\b|\brequest\.session\b/,
+    storage: 'session',
+  },
+];
+/** Lines around a state or nonce searched for the random source it's made from */
+const RANDOM_WINDOW = 3;
+
+type StateKind = OAuthStateUse['kind'];
+type StateParam = OAuthStateUse['param'];
+
+const MESSAGES: Record<StateKind, (param: StateParam) => string> = {
+  constant: () => 'OAuth state is a constant, so the login has no CSRF protection',
+  generated: (param) =>
+    param === 'nonce' ? 'Generates a nonce per sign-in' : 'Generates a random OAuth state per sign-in',
+  validated: (param) =>
+    param === 'nonce' ? 'The callback checks the ID token nonce' : 'The callback checks the OAuth state it gets back',
+};
+
+const REMEDIATIONS: Record<StateParam, string> = {
+  state: 'Pass a random state to the AuthKit authorization URL and check it in the callback before exchanging the code',
+  nonce: 'AuthKit returns no ID token to check a nonce against; the state check protects the callback instead',
+};
+
+function stateFinding(
+  kind: StateKind,
+  param: StateParam,
+  file: string,
+  line: number,
+  evidence: string,
+  storage?: OAuthStateUse['storage'],
+): MigrationFinding {
+  return {
+    provider: ANY_PROVIDER,
+    code: kind === 'constant' ? 'oauth-state-constant' : `oauth-${param}-${kind}`,
+    severity: kind === 'constant' ? 'warning' : 'info',
+    message: MESSAGES[kind](param),
+    file,
+    line,
+    evidence,
+    remediation:
+      kind === 'constant'
+        ? 'The migrated login generates a random state per sign-in and checks it in the callback'
+        : REMEDIATIONS[param],
+    // CSRF protection says nothing about which provider the app signs in with
+    confidence: 0,
+    details: { oauthState: kind, param, ...(storage && { storage }) },
+  };
+}
+
+/** Where a state or nonce on these lines is kept */
+function storageIn(text: string): OAuthStateUse['storage'] {
+  return STORAGE_SINKS.find(({ pattern }) => pattern.test(text))?.storage;
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => SOURCE_FILE_PATTERN.test(f) && !TEST_FILE_PATTERN.test(f));
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    const content = await ctx.readFile(file);
+    if (!content || !OAUTH_FILE_PATTERN.test(content)) continue;
+
+    // The first line per kind and parameter in a file is enough to point at it
+    const seen = new Set<string>();
+    const add = (
+      kind: StateKind,
+      param: StateParam,
+      line: number,
+      text: string,
+      storage?: OAuthStateUse['storage'],
+    ) => {
+      if (seen.has(`${kind}:${param}`)) return;
+      seen.add(`${kind}:${param}`);
+      findings.push(stateFinding(kind, param, file, line, text, storage));
+    };
+
+    for (const { line, text } of findLines(content, CONSTANT_PATTERN)) add('constant', 'state', line, text);
+
+    const lines = content.split(/\r?\n/);
+    const values = findLines(content, VALUE_PATTERN).map((match) => ({
+      ...match,
+      param: (NONCE_PATTERN.test(match.text) ? 'nonce' : 'state') as StateParam,
+    }));
+    // Where each value is kept, reported with its generation wherever in the file it's stored
+    const stored = new Map<StateParam, OAuthStateUse['storage']>();
+    for (const { text, param } of values) {
+      const storage = storageIn(text);
+      if (storage && !stored.has(param)) stored.set(param, storage);
+    }
+    for (const { line, text, param } of values) {
+      const around = lines.slice(Math.max(0, line - 1 - RANDOM_WINDOW), line + RANDOM_WINDOW).join('\n');
+      if (RANDOM_PATTERN.test(around)) add('generated', param, line, text, stored.get(param));
+      if (COMPARISON_PATTERN.test(text)) add('validated', param, line, text);
+    }
+  }
+
+  return findings;
+}
+
+export const oauthState: ProviderDetector = {
+  name: 'oauth-state',
+  description: 'OAuth state and nonce generation and checks to keep as AuthKit state, and constant states to fix',
+  language: 'any',
+  files: SOURCE_FILE_PATTERN,
+  detect,
+};
@@ -0,0 +1,122 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { buildMigrationPrompt } from '../prompt.js';
+import { extractRefreshTokenUses } from '../refresh-tokens.js';
+import { createScanContext } from '../scan.js';
+import { refreshTokens } from './refresh-tokens.js';
+
+const AUTH_GO = `package auth
+
+func NewConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID: os.Getenv("AUTH0_CLIENT_ID"),
+		Scopes:   []string{oidc.ScopeOpenID, oidc.ScopeOfflineAccess},
+	}
+}
+
+func Client(ctx context.Context, token *oauth2.Token) *http.Client {
+	return oauth2.NewClient(ctx, config.TokenSource(ctx, token))
+}
+
+func storeTokens(c *gin.Context, token *oauth2.Token) {
+	c.SetCookie("refresh_token", token.RefreshToken, 30*24*3600, "/", "", true, true)
+}
+`;
+
+const TOKENS_JS = `async function refresh(user) {
+  const res = await fetch(\`https://\${process.env.AUTH0_DOMAIN}/oauth/token\`, {
+    method: 'POST',
+    body: new URLSearchParams({ grant_type: 'refresh_token', refresh_token: user.refreshToken }),
+  });
+  const tokens = await res.json();
+  await db.user.update({
+    where: { id: user.id },
+    data: { refreshToken: tokens.refresh_token },
+  });
+}
+`;
+
+const SCHEMA = `CREATE TABLE sessions (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL,
+  refresh_token TEXT NOT NULL
+);
+`;
+
+describe('refresh-tokens detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'refresh-tokens-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('finds offline access, refreshing clients and where refresh tokens are stored', async () => {
+    write('auth/auth.go', AUTH_GO);
+    write('lib/tokens.js', TOKENS_JS);
+    write('db/migrations/001_sessions.sql', SCHEMA);
+
+    const findings = await refreshTokens.detect(createScanContext(root));
+
+    expect(findings.map((f) => [f.code, f.file, f.line, f.details?.storage])).toEqual([
+      ['offline-access', 'auth/auth.go', 6, undefined],
+      ['refresh-token-source', 'auth/auth.go', 11, undefined],
+      ['refresh-token-stored', 'auth/auth.go', 15, 'cookie'],
+      ['refresh-token-stored', 'db/migrations/001_sessions.sql', 4, 'database'],
+      ['refresh-grant', 'lib/tokens.js', 4, undefined],
+      ['refresh-token-stored', 'lib/tokens.js', 9, 'database'],
+    ]);
+    expect(findings.every((f) => f.provider === '*' && f.confidence === 0)).toBe(true);
+    expect(findings.filter((f) => f.manual).map((f) => f.code)).not.toContain('offline-access');
+  });
+
+  it('puts the refresh steps and locations in the migration prompt', async () => {
+    write('auth/auth.go', AUTH_GO);
+    const findings = await refreshTokens.detect(createScanContext(root));
+    const uses = extractRefreshTokenUses(findings);
+
+    expect(uses.map((u) => [u.kind, u.storage])).toEqual([
+      ['scope', undefined],
+      ['token-source', undefined],
+      ['stored', 'cookie'],
+    ]);
+
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.9,
+      envMapping: {},
+      guidance: [],
+      findings: [],
+      refreshTokens: uses,
+    });
+    expect(prompt).toContain('### Session refresh');
+    expect(prompt).toContain('authenticateWithRefreshToken');
+    expect(prompt).toContain('- auth/auth.go:15: stores refresh tokens in a cookie');
+  });
+
+  it('ignores apps that only sign users in, tests and WorkOS refresh calls', async () => {
+    write(
+      'auth/login.go',
+      'token, _ := config.Exchange(ctx, code)\nc.SetCookie("user", claims, 3600, "/", "", true, true)',
+    );
+    write('auth/auth_test.go', AUTH_GO);
+    write(
+      'lib/session.js',
+      'const session = await workos.userManagement.authenticateWithRefreshToken({ refreshToken: res.cookie });',
+    );
+
+    expect(await refreshTokens.detect(createScanContext(root))).toEqual([]);
+  });
+});
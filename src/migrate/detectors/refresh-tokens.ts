@@ -0,0 +1,173 @@
+import { findLines } from '../scan.js';
+import {
+  ANY_PROVIDER,
+  type MigrationFinding,
+  type ProviderDetector,
+  type RefreshTokenStorage,
+  type RefreshTokenUse,
+  type ScanContext,
+} from '../types.js';
+
+/**
+ * Offline access and refresh-token handling, whichever provider issued the
+ * tokens: `offline_access` requests, OAuth clients that refresh on their
+ * own (Go's TokenSource), refresh_token grants, and refresh tokens the app
+ * keeps in a cookie, the session or the database. The default migration
+ * signs users in but doesn't rotate their sessions, so these are reported
+ * with where the tokens are kept (see refresh-tokens.ts).
+ */
+
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?|go|py|rb|php|java|kt|cs)$/;
+const SCHEMA_FILE_PATTERN = /(\.sql|\.prisma)$|(^|\/)migrations?\/[^/]+$/i;
+const TEST_FILE_PATTERN = /(\.(test|spec)\.[^/]+$|_test\.go$|(^|\/)(__tests__|tests?|spec)\/)/;
+
+/** `offline_access` as a scope or a go-oidc constant, or Google-style `access_type=offline` */
+const OFFLINE_PATTERN =
+  /\boffline_access\b|\bScopeOfflineAccess\b|\bAccessTypeOffline\b|\baccess_type\b['"`]?\s*[:=,]\s*['"`]offline\b/;
+/** Clients that refresh by themselves: oauth2 TokenSources, requests-oauthlib, Spring's client provider */
+const TOKEN_SOURCE_PATTERN = /\.TokenSource\(|\bReuseTokenSource\w*\(|\bauto_refresh_url\b|\.refreshToken\(\)\s*[.;]/;
+/** A refresh_token grant sent to the token endpoint */
+const GRANT_PATTERN = new RegExp(
+  [
+    '\\bgrant_type\\b[\'"`]?\\s*[:=,]\\s*[\'"`]refresh_token\\b',
+    '\\bgrant_type=refresh_token\\b',
+    '\\bAuthorizationGrantType\\.REFRESH_TOKEN\\b',
+  ].join('|'),
+);
+const REFRESH_TOKEN_PATTERN = /\b(?:refresh_token|refreshToken|RefreshToken)\b/;
+/** WorkOS's own refresh calls, in apps that already use AuthKit somewhere */
+const WORKOS_REFRESH_PATTERN = /\b[Aa]uthenticateWithRefreshToken\b/;
+
+/** Where a line puts a value, checked in order; a cookie write that goes through the session is a cookie */
+const STORAGE_SINKS: Array<{ pattern: RegExp; storage: RefreshTokenStorage }> = [
+  {
+    pattern: /\b(?:SetCookie|setCookie|set_cookie|setcookie)\(|\bcookies?\s*\.\s*set\(|\.cookie\(|\bhttp\.Cookie\s*\{/,
+    storage: 'cookie',
+  },
+  {
+    pattern: /\bsession\.(?:Values|Set|set|put)\b|\breq\.session\b|\bsession\[|\$_SESSION\b|\brequest\.session\b/,
+    storage: 'session',
+  },
+  {
+    // SQL, database/sql calls and ORM writes
+    pattern: new RegExp(
+      [
+        '\\b(?:INSERT\\s+INTO|UPDATE\\s+\\w+\\s+SET)\\b',
+        '\\.(?:Exec|ExecContext|QueryRow|Save|Create|Updates?)\\(',
+        '\\.(?:create|update|upsert|insert|save)\\(',
+        '\\bprisma\\.\\w+',
+      ].join('|'),
+    ),
+    storage: 'database',
+  },
+];
+/** Lines before a refresh token reference searched for the call it's an argument of */
+const SINK_WINDOW = 3;
+
+type RefreshKind = RefreshTokenUse['kind'];
+
+const STORAGE_PLACES: Record<RefreshTokenStorage, string> = {
+  cookie: 'a cookie',
+  session: 'the session',
+  database: 'the database',
+};
+
+const MESSAGES: Record<RefreshKind, (storage?: RefreshTokenStorage) => string> = {
+  scope: () => 'Requests offline access to get refresh tokens',
+  'token-source': () => 'An OAuth client refreshes access tokens on its own',
+  grant: () => 'Exchanges refresh tokens with the provider (refresh_token grant)',
+  stored: (storage) => `Stores refresh tokens in ${STORAGE_PLACES[storage!]}`,
+};
+
+const REFRESH_CALL = 'authenticateWithRefreshToken (usermanagement.AuthenticateWithRefreshToken in Go)';
+
+const REMEDIATIONS: Record<Exclude<RefreshKind, 'stored'>, string> = {
+  scope: 'AuthKit always returns a refresh token; drop offline_access from the requested scopes',
+  'token-source': `Refresh the AuthKit session with ${REFRESH_CALL} when its access token expires`,
+  grant: `Call ${REFRESH_CALL} with the session's refresh token instead`,
+};
+
+/** WorkOS rotates refresh tokens, so wherever they're kept has to take the new one after each refresh */
+const STORAGE_REMEDIATIONS: Record<RefreshTokenStorage, string> = {
+  cookie: 'Keep the refresh token in the sealed AuthKit session cookie and reseal the cookie after each refresh',
+  session: "Keep AuthKit's refresh token in the session and replace it after each refresh",
+  database: "Store AuthKit's refresh token instead, replacing it after each refresh; the old tokens stop working",
+};
+
+function refreshFinding(
+  kind: RefreshKind,
+  file: string,
+  line: number,
+  evidence: string,
+  storage?: RefreshTokenStorage,
+): MigrationFinding {
+  return {
+    provider: ANY_PROVIDER,
+    code: kind === 'scope' ? 'offline-access' : kind === 'stored' ? 'refresh-token-stored' : `refresh-${kind}`,
+    severity: kind === 'scope' ? 'info' : 'warning',
+    message: MESSAGES[kind](storage),
+    file,
+    line,
+    evidence,
+    remediation: kind === 'stored' ? STORAGE_REMEDIATIONS[storage!] : REMEDIATIONS[kind],
+    // The default migration doesn't refresh sessions; the rotation has to be wired up by hand
+    ...(kind !== 'scope' && { manual: true }),
+    // Refreshing sessions says nothing about which provider issued them
+    confidence: 0,
+    details: { refreshTokens: kind, ...(storage && { storage }) },
+  };
+}
+
+/** Where the refresh token on `line` (1-based) is written to, looking back over the call it's in */
+function storageAt(lines: string[], line: number): RefreshTokenStorage | undefined {
+  const text = lines.slice(Math.max(0, line - 1 - SINK_WINDOW), line).join('\n');
+  return STORAGE_SINKS.find(({ pattern }) => pattern.test(text))?.storage;
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => !TEST_FILE_PATTERN.test(f));
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    const schema = SCHEMA_FILE_PATTERN.test(file);
+    if (!schema && !SOURCE_FILE_PATTERN.test(file)) continue;
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+
+    // The first line per kind and storage in a file is enough to point at it
+    const seen = new Set<string>();
+    const add = (kind: RefreshKind, line: number, text: string, storage?: RefreshTokenStorage) => {
+      const key = `${kind}:${storage ?? ''}`;
+      if (seen.has(key)) return;
+      seen.add(key);
+      findings.push(refreshFinding(kind, file, line, text, storage));
+    };
+
+    if (schema) {
+      for (const { line, text } of findLines(content, REFRESH_TOKEN_PATTERN)) add('stored', line, text, 'database');
+      continue;
+    }
+
+    for (const { line, text } of findLines(content, OFFLINE_PATTERN)) add('scope', line, text);
+    for (const { line, text } of findLines(content, TOKEN_SOURCE_PATTERN)) add('token-source', line, text);
+    const grants = findLines(content, GRANT_PATTERN);
+    for (const { line, text } of grants) add('grant', line, text);
+
+    const lines = content.split(/\r?\n/);
+    for (const { line, text } of findLines(content, REFRESH_TOKEN_PATTERN)) {
+      if (WORKOS_REFRESH_PATTERN.test(text) || grants.some((g) => g.line === line)) continue;
+      const storage = storageAt(lines, line);
+      if (storage) add('stored', line, text, storage);
+    }
+  }
+
+  return findings;
+}
+
+export const refreshTokens: ProviderDetector = {
+  name: 'refresh-tokens',
+  description: 'Offline access, refresh grants and stored refresh tokens to move to AuthKit session refresh',
+  language: 'any',
+  files: new RegExp(`${SOURCE_FILE_PATTERN.source}|${SCHEMA_FILE_PATTERN.source}`, 'i'),
+  detect,
+};
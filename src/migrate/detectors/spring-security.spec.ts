@@ -0,0 +1,185 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { flattenSpringConfig, springSecurity } from './spring-security.js';
+
+const APPLICATION_YML = `server:
+  port: 8080
+
+spring:
+  security:
+    oauth2:
+      client:
+        registration:
+          login:
+            provider: corp
+            client-id: \${OIDC_CLIENT_ID}
+            client-secret: hunter2 # TODO move to env
+            scope: openid,profile,email
+        provider:
+          corp:
+            issuer-uri: https://example.us.auth0.com/
+`;
+
+const POM_XML = `<project>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-oauth2-client</artifactId>
+    </dependency>
+  </dependencies>
+</project>
+`;
+
+const SECURITY_CONFIG = `package com.example;
+
+@Configuration
+public class SecurityConfig {
+    @Bean
+    SecurityFilterChain filterChain(HttpSecurity http) throws Exception {
+        http.authorizeHttpRequests(a -> a.anyRequest().authenticated())
+            .oauth2Login(Customizer.withDefaults());
+        return http.build();
+    }
+}
+`;
+
+describe('flattenSpringConfig', () => {
+  it('flattens nested YAML keys with line numbers', () => {
+    const properties = flattenSpringConfig(APPLICATION_YML, 'yaml');
+
+    expect(properties.get('server.port')).toEqual({ value: '8080', line: 2 });
+    expect(properties.get('spring.security.oauth2.client.registration.login.client-secret')?.value).toBe('hunter2');
+    expect(properties.get('spring.security.oauth2.client.provider.corp.issuer-uri')?.line).toBe(16);
+  });
+
+  it('reads .properties files', () => {
+    const key = 'spring.security.oauth2.client.registration.okta.client-id';
+    const properties = flattenSpringConfig(`# comment\n${key}=abc\n`, 'properties');
+
+    expect(properties.get(key)).toEqual({ value: 'abc', line: 2 });
+  });
+});
+
+describe('spring-security detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'spring-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('returns nothing for projects without Spring OAuth2', async () => {
+    write('pom.xml', '<project><artifactId>spring-boot-starter-web</artifactId></project>');
+
+    expect(await springSecurity.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('maps registrations to the provider behind the issuer', async () => {
+    write('pom.xml', POM_XML);
+    write('src/main/resources/application.yml', APPLICATION_YML);
+    write('src/main/java/com/example/SecurityConfig.java', SECURITY_CONFIG);
+
+    const findings = await springSecurity.detect(createScanContext(root));
+
+    expect(new Set(findings.map((f) => f.provider))).toEqual(new Set(['auth0']));
+    expect(findings.find((f) => f.code === 'spring-oauth2-registration')).toMatchObject({
+      file: 'src/main/resources/application.yml',
+      line: 10,
+    });
+    expect(findings.find((f) => f.code === 'spring-dependency')?.line).toBe(5);
+    expect(findings.find((f) => f.code === 'spring-security-filter-chain')).toMatchObject({
+      file: 'src/main/java/com/example/SecurityConfig.java',
+      line: 8,
+    });
+  });
+
+  it('reports env mappings and literal secrets', async () => {
+    write('src/main/resources/application.yml', APPLICATION_YML);
+
+    const settings = (await springSecurity.detect(createScanContext(root))).filter(
+      (f) => f.code === 'spring-oauth2-setting',
+    );
+
+    expect(settings.find((f) => f.details?.setting === 'client-id')?.details).toMatchObject({
+      envVar: 'OIDC_CLIENT_ID',
+      workosEnv: 'WORKOS_CLIENT_ID',
+    });
+    expect(settings.find((f) => f.details?.setting === 'client-secret')?.severity).toBe('warning');
+    expect(settings.find((f) => f.details?.setting === 'issuer-uri')?.details?.workosEnv).toBeNull();
+  });
+
+  it('records the requested scopes and the claims the code reads', async () => {
+    write('src/main/resources/application.yml', APPLICATION_YML);
+    write(
+      'src/main/java/com/example/ProfileController.java',
+      [
+        'public class ProfileController {',
+        '    String show(@AuthenticationPrincipal OidcUser user) {',
+        '        String email = user.getClaimAsString("email");',
+        '        Object roles = user.getClaims().get("https://example.com/roles");',
+        '        return email;',
+        '    }',
+        '}',
+      ].join('\n'),
+    );
+
+    const findings = await springSecurity.detect(createScanContext(root));
+
+    expect(findings.find((f) => f.code === 'spring-oauth2-registration')?.details?.scopes).toEqual([
+      'openid',
+      'profile',
+      'email',
+    ]);
+    const claims = findings.filter((f) => f.code === 'spring-claim-read');
+    expect(claims.map((f) => [f.details?.claim, f.line])).toEqual([
+      ['email', 3],
+      ['https://example.com/roles', 4],
+    ]);
+    expect(claims[0]).toMatchObject({ confidence: 0, remediation: 'Read user.email from the AuthKit session' });
+  });
+
+  it('falls back to generic OIDC for unknown issuers', async () => {
+    write(
+      'src/main/resources/application.properties',
+      [
+        'spring.security.oauth2.client.registration.sso.client-id=${SSO_CLIENT_ID}',
+        'spring.security.oauth2.client.provider.sso.issuer-uri=https://id.example.com',
+      ].join('\n'),
+    );
+
+    const findings = await springSecurity.detect(createScanContext(root));
+
+    expect(findings.every((f) => f.provider === 'oidc')).toBe(true);
+  });
+
+  it('trusts a generic OIDC registration more when its issuer is live', async () => {
+    write(
+      'src/main/resources/application.properties',
+      [
+        'spring.security.oauth2.client.registration.sso.client-id=${SSO_CLIENT_ID}',
+        'spring.security.oauth2.client.provider.sso.issuer-uri=https://id.example.com',
+      ].join('\n'),
+    );
+    const registration = async (live: boolean | null) => {
+      const discovery = { resolves: vi.fn(async () => live) };
+      const findings = await springSecurity.detect(createScanContext(root, { discovery }));
+      return findings.find((f) => f.code === 'spring-oauth2-registration');
+    };
+
+    expect(await registration(true)).toMatchObject({ confidence: 0.45, details: { issuerLive: true } });
+    expect(await registration(false)).toMatchObject({ confidence: 0.3, details: { issuerLive: false } });
+    // Offline: same as without discovery
+    expect((await registration(null))?.details).not.toHaveProperty('issuerLive');
+  });
+});
@@ -0,0 +1,311 @@
+import { providerFromIssuer, providerFromName } from '../providers.js';
+import { findLines } from '../scan.js';
+import { mapClaim } from '../scopes-claims.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+const REGISTRATION_PREFIX = 'spring.security.oauth2.client.registration.';
+const PROVIDER_PREFIX = 'spring.security.oauth2.client.provider.';
+const RESOURCE_SERVER_ISSUER = 'spring.security.oauth2.resourceserver.jwt.issuer-uri';
+
+/** Spring client registration/provider settings and the WorkOS env var that replaces each */
+const SETTING_TO_WORKOS_ENV: Record<string, string | null> = {
+  'client-id': 'WORKOS_CLIENT_ID',
+  'client-secret': 'WORKOS_API_KEY',
+  'redirect-uri': 'WORKOS_REDIRECT_URI',
+  'issuer-uri': null,
+  'authorization-uri': null,
+  'token-uri': null,
+  'jwk-set-uri': null,
+  'user-info-uri': null,
+};
+
+const CONFIG_FILE_PATTERN = /(^|\/)application(-[\w-]+)?\.(ya?ml|properties)$/;
+const BUILD_FILE_PATTERN = /(^|\/)(pom\.xml|build\.gradle(\.kts)?)$/;
+const SOURCE_FILE_PATTERN = /\.(java|kt)$/;
+const OAUTH_STARTERS = ['spring-boot-starter-oauth2-client', 'spring-boot-starter-oauth2-resource-server'];
+const OKTA_STARTER = 'okta-spring-boot-starter';
+/** `${ENV_VAR}` or `${ENV_VAR:default}` property placeholders */
+const PLACEHOLDER_PATTERN = /^\$\{([A-Za-z0-9_.-]+)(?::([^}]*))?\}$/;
+/** Claims read from an OidcUser, OAuth2User or Jwt */
+const CLAIM_READ_PATTERN = /\.(?:getClaim\w*|getAttribute|getClaims\(\)\.get)\(\s*"([^"]+)"/;
+
+export interface SpringProperty {
+  value: string;
+  line: number;
+}
+
+/**
+ * Flatten Spring config into dotted keys. Handles `.properties` files and the
+ * block-mapping subset of YAML Spring configs use; lists and multi-document
+ * files are read key-by-key without attempting full YAML semantics.
+ */
+export function flattenSpringConfig(content: string, format: 'yaml' | 'properties'): Map<string, SpringProperty> {
+  const properties = new Map<string, SpringProperty>();
+  const lines = content.split(/\r?\n/);
+
+  if (format === 'properties') {
+    lines.forEach((text, index) => {
+      const m = /^\s*([^#!\s][^=:\s]*)\s*[=:]\s*(.*)$/.exec(text);
+      if (m) properties.set(m[1], { value: m[2].trim(), line: index + 1 });
+    });
+    return properties;
+  }
+
+  const stack: Array<{ indent: number; key: string }> = [];
+  lines.forEach((text, index) => {
+    if (/^\s*(#|$)/.test(text) || text.startsWith('---')) {
+      if (text.startsWith('---')) stack.length = 0;
+      return;
+    }
+    const m = /^(\s*)([^\s#:][^:#]*?)\s*:(?:\s+(.*))?$/.exec(text);
+    if (!m) return;
+
+    const indent = m[1].length;
+    while (stack.length > 0 && stack[stack.length - 1].indent >= indent) stack.pop();
+    stack.push({ indent, key: m[2].replace(/^['"]|['"]$/g, '') });
+
+    const value = (m[3] ?? '').replace(/\s+#.*$/, '').trim();
+    if (value) {
+      properties.set(stack.map((s) => s.key).join('.'), { value: value.replace(/^['"]|['"]$/g, ''), line: index + 1 });
+    }
+  });
+  return properties;
+}
+
+export interface SpringRegistration {
+  id: string;
+  file: string;
+  /** Line of the first property of the registration */
+  line: number;
+  /** Setting -> property (registration and matching provider block) */
+  settings: Record<string, SpringProperty>;
+}
+
+function collectRegistrations(file: string, properties: Map<string, SpringProperty>): SpringRegistration[] {
+  const registrations = new Map<string, SpringRegistration>();
+
+  for (const [key, property] of properties) {
+    if (!key.startsWith(REGISTRATION_PREFIX)) continue;
+    const [id, ...rest] = key.slice(REGISTRATION_PREFIX.length).split('.');
+    const registration = registrations.get(id) ?? { id, file, line: property.line, settings: {} };
+    registration.line = Math.min(registration.line, property.line);
+    registration.settings[rest.join('.')] = property;
+    registrations.set(id, registration);
+  }
+
+  // `registration.<id>.provider` points at a provider block; it defaults to the registration id
+  for (const registration of registrations.values()) {
+    const providerId = registration.settings.provider?.value ?? registration.id;
+    for (const [key, property] of properties) {
+      if (!key.startsWith(`${PROVIDER_PREFIX}${providerId}.`)) continue;
+      registration.settings[key.slice(PROVIDER_PREFIX.length + providerId.length + 1)] ??= property;
+    }
+  }
+
+  return [...registrations.values()];
+}
+
+/** `scope: openid,profile` as a list; placeholders can't be read */
+function registrationScopes(registration: SpringRegistration): string[] {
+  const value = registration.settings.scope?.value;
+  if (!value || value.includes('${')) return [];
+  return value
+    .replace(/^\[|\]$/g, '')
+    .split(/[\s,]+/)
+    .map((scope) => scope.replace(/^['"]|['"]$/g, ''))
+    .filter(Boolean);
+}
+
+function providerForRegistration(registration: SpringRegistration): string {
+  const issuer = registration.settings['issuer-uri']?.value ?? registration.settings['authorization-uri']?.value;
+  return (
+    providerFromName(registration.id) ??
+    providerFromName(registration.settings.provider?.value ?? '') ??
+    (issuer ? providerFromIssuer(issuer) : undefined) ??
+    'oidc'
+  );
+}
+
+/**
+ * Confidence for a generic OIDC finding: higher when its issuer serves a
+ * discovery document, since that's a login flow in use rather than stale config.
+ */
+async function oidcConfidence(ctx: ScanContext, issuer: string | undefined, base: number) {
+  const live = issuer && ctx.resolveIssuer ? await ctx.resolveIssuer(issuer) : null;
+  const confidence = live ? Math.round((base + 0.15) * 100) / 100 : base;
+  return { confidence, details: live === null ? {} : { issuerLive: live } };
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = await ctx.files();
+  const findings: MigrationFinding[] = [];
+
+  const configFiles = files.filter((f) => CONFIG_FILE_PATTERN.test(f) && !f.includes('src/test/'));
+  const registrations: SpringRegistration[] = [];
+  const resourceServers: Array<{ file: string; property: SpringProperty }> = [];
+  for (const file of configFiles) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    const properties = flattenSpringConfig(content, file.endsWith('.properties') ? 'properties' : 'yaml');
+    registrations.push(...collectRegistrations(file, properties));
+    const issuer = properties.get(RESOURCE_SERVER_ISSUER);
+    if (issuer) resourceServers.push({ file, property: issuer });
+  }
+
+  const dependencies: Array<{ artifact: string; file: string; line: number }> = [];
+  for (const file of files.filter((f) => BUILD_FILE_PATTERN.test(f))) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    for (const artifact of [...OAUTH_STARTERS, OKTA_STARTER]) {
+      const hit = findLines(content, new RegExp(`['":>]${artifact}['":<]`))[0];
+      if (hit) dependencies.push({ artifact, file, line: hit.line });
+    }
+  }
+
+  if (registrations.length === 0 && resourceServers.length === 0 && dependencies.length === 0) return [];
+
+  for (const registration of registrations) {
+    const provider = providerForRegistration(registration);
+    const issuer = registration.settings['issuer-uri']?.value;
+    const oidc = provider === 'oidc' ? await oidcConfidence(ctx, issuer, 0.3) : null;
+    const scopes = registrationScopes(registration);
+    findings.push({
+      provider,
+      code: 'spring-oauth2-registration',
+      severity: 'info',
+      message: `OAuth2 client registration "${registration.id}" in ${registration.file}`,
+      file: registration.file,
+      line: registration.line,
+      remediation: 'Replace the registration and provider blocks with AuthKit settings read from WORKOS_* env vars',
+      confidence: oidc?.confidence ?? 0.5,
+      details: {
+        registration: registration.id,
+        framework: 'spring',
+        ...(scopes.length > 0 && { scopes }),
+        ...oidc?.details,
+      },
+    });
+
+    for (const [setting, property] of Object.entries(registration.settings)) {
+      if (!(setting in SETTING_TO_WORKOS_ENV)) continue;
+      const workosEnv = SETTING_TO_WORKOS_ENV[setting];
+      const placeholder = PLACEHOLDER_PATTERN.exec(property.value);
+      const envVar = placeholder?.[1];
+      const source = envVar ? `\${${envVar}}` : 'a literal value';
+      const literalSecret = setting === 'client-secret' && !envVar;
+      const value = (envVar ? placeholder?.[2] : property.value)?.replace(/^['"]|['"]$/g, '');
+      findings.push({
+        provider,
+        code: 'spring-oauth2-setting',
+        // Literal secrets in config files are worth flagging on their own
+        severity: literalSecret ? 'warning' : 'info',
+        message: workosEnv
+          ? `${registration.id}.${setting} reads ${source}; map it to ${workosEnv}`
+          : `${registration.id}.${setting} reads ${source}; not needed with AuthKit`,
+        file: registration.file,
+        line: property.line,
+        remediation: literalSecret ? 'Move the secret out of the config file into WORKOS_API_KEY' : undefined,
+        confidence: 0.1,
+        details: {
+          setting,
+          envVar,
+          workosEnv,
+          hasDefault: placeholder?.[2] !== undefined,
+          ...(setting === 'redirect-uri' && value ? { redirectUri: value } : {}),
+        },
+      });
+    }
+  }
+
+  for (const { file, property } of resourceServers) {
+    const provider = providerFromIssuer(property.value) ?? 'oidc';
+    const oidc = provider === 'oidc' ? await oidcConfidence(ctx, property.value, 0.3) : null;
+    findings.push({
+      provider,
+      code: 'spring-resource-server',
+      severity: 'warning',
+      message: `Resource server validates JWTs from ${property.value}`,
+      file,
+      line: property.line,
+      remediation: "Validate AuthKit access tokens against WorkOS's JWKS (https://api.workos.com/sso/jwks/<client id>)",
+      confidence: oidc?.confidence ?? 0.3,
+      details: { framework: 'spring', ...oidc?.details },
+    });
+  }
+
+  const providersSeen = new Set(findings.map((f) => f.provider));
+  const defaultProvider = providersSeen.size === 1 ? [...providersSeen][0] : 'oidc';
+
+  for (const { artifact, file, line } of dependencies) {
+    findings.push({
+      provider: artifact === OKTA_STARTER ? 'okta' : defaultProvider,
+      code: 'spring-dependency',
+      severity: 'info',
+      message: `${artifact} in ${file}`,
+      file,
+      line,
+      remediation:
+        artifact === OKTA_STARTER
+          ? `Remove ${artifact} once login goes through AuthKit`
+          : 'Keep the Spring Security starter; only the provider configuration changes',
+      confidence: artifact === OKTA_STARTER ? 0.5 : 0.2,
+      details: { artifact, framework: 'spring' },
+    });
+  }
+
+  for (const file of files.filter((f) => SOURCE_FILE_PATTERN.test(f) && !f.includes('src/test/'))) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+
+    if (/\b(OidcUser|OAuth2User|OidcIdToken|Jwt)\b/.test(content)) {
+      for (const { line, text, match } of findLines(content, CLAIM_READ_PATTERN)) {
+        const claim = match[1];
+        const { authkit, note } = mapClaim(claim);
+        findings.push({
+          provider: defaultProvider,
+          code: 'spring-claim-read',
+          severity: 'info',
+          message: `Reads the "${claim}" claim`,
+          file,
+          line,
+          evidence: text,
+          remediation: authkit ? `Read ${authkit} from the AuthKit session` : note,
+          // Reading claims says nothing about which provider issued them
+          confidence: 0,
+          details: { claim, framework: 'spring' },
+        });
+      }
+    }
+
+    if (!content.includes('SecurityFilterChain')) continue;
+    const usages = findLines(content, /\.(oauth2Login|oauth2Client|oauth2ResourceServer)\s*[({]/);
+    for (const { line, text, match } of usages) {
+      const usage = match[1];
+      findings.push({
+        provider: defaultProvider,
+        code: 'spring-security-filter-chain',
+        severity: 'warning',
+        message: `SecurityFilterChain configures ${usage}()`,
+        file,
+        line,
+        evidence: text,
+        remediation:
+          usage === 'oauth2ResourceServer'
+            ? 'Point JWT validation at the WorkOS JWKS and issuer'
+            : 'Point oauth2Login at the AuthKit registration, or replace it with the WorkOS Java SDK callback flow',
+        confidence: 0.3,
+        details: { usage, framework: 'spring' },
+      });
+    }
+  }
+
+  return findings;
+}
+
+export const springSecurity: ProviderDetector = {
+  name: 'spring-security',
+  description: 'Spring Security OAuth2 client registrations and resource server issuers',
+  language: 'java',
+  files: new RegExp([CONFIG_FILE_PATTERN, BUILD_FILE_PATTERN, SOURCE_FILE_PATTERN].map((p) => p.source).join('|')),
+  detect,
+};
@@ -0,0 +1,84 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { createScanContext } from '../scan.js';
+import { supabase } from './supabase.js';
+
+const PACKAGE_JSON = JSON.stringify({ dependencies: { next: '15.0.0', '@supabase/supabase-js': '^2.45.0' } }, null, 2);
+
+const CLIENT = `import { createClient } from '@supabase/supabase-js';
+
+export const supabase = createClient(process.env.SUPABASE_URL!, process.env.SUPABASE_ANON_KEY!);
+`;
+
+const LOGIN = `import { supabase } from '@/lib/supabase';
+
+export async function login() {
+  await supabase.auth.signInWithOAuth({ provider: 'github' });
+}
+
+export async function loadPosts() {
+  const { data: { user } } = await supabase.auth.getUser();
+  const ids = Array.from(new Set(['a']));
+  return supabase.from('posts').select('*').eq('author', user.id);
+}
+
+supabase.auth.onAuthStateChange((event) => console.log(event));
+`;
+
+describe('supabase detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'supabase-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('returns nothing for projects without Supabase', async () => {
+    write('package.json', JSON.stringify({ dependencies: { next: '15.0.0' } }));
+    write('lib/login.ts', LOGIN);
+
+    expect(await supabase.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('finds the client and auth calls', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('lib/supabase.ts', CLIENT);
+    write('lib/login.ts', LOGIN);
+
+    const findings = (await supabase.detect(createScanContext(root))).filter((f) => !f.outOfScope);
+
+    expect(findings.find((f) => f.code === 'supabase-client')).toMatchObject({ file: 'lib/supabase.ts', line: 3 });
+    expect(findings.filter((f) => f.code === 'supabase-auth-call').map((f) => [f.details?.method, f.line])).toEqual([
+      ['signInWithOAuth', 4],
+      ['getUser', 8],
+      ['onAuthStateChange', 13],
+    ]);
+    expect(findings.find((f) => f.details?.method === 'onAuthStateChange')?.manual).toBe(true);
+  });
+
+  it('keeps data access and env vars out of the auth migration', async () => {
+    write('package.json', PACKAGE_JSON);
+    write('lib/login.ts', LOGIN);
+    write('.env.local', 'SUPABASE_URL=https://abc.supabase.co\nSUPABASE_ANON_KEY=anon\n');
+
+    const outOfScope = (await supabase.detect(createScanContext(root))).filter((f) => f.outOfScope);
+
+    expect(outOfScope.map((f) => [f.code, f.line])).toEqual([
+      ['supabase-data-access', 10],
+      ['supabase-env', 1],
+      ['supabase-env', 2],
+    ]);
+    expect(outOfScope.every((f) => f.confidence === 0)).toBe(true);
+    expect(outOfScope.some((f) => f.details?.workosEnv !== undefined)).toBe(false);
+  });
+});
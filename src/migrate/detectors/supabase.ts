@@ -0,0 +1,174 @@
+import { findLines, readPackageManifests } from '../scan.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/** Packages that only exist for Supabase Auth */
+const AUTH_PACKAGES = ['@supabase/auth-helpers-nextjs', '@supabase/auth-helpers-react', '@supabase/auth-ui-react'];
+/** Clients used for both auth and data */
+const CLIENT_PACKAGES = ['@supabase/supabase-js', '@supabase/ssr'];
+
+/** supabase.auth.* methods and their AuthKit replacement */
+const AUTH_METHODS: Record<string, { remediation: string; manual?: boolean; noEquivalent?: boolean }> = {
+  signInWithOAuth: { remediation: 'Redirect to getSignInUrl(); social providers are set up in the WorkOS dashboard' },
+  signInWithPassword: { remediation: 'Redirect to getSignInUrl(); the hosted AuthKit page handles passwords' },
+  signInWithOtp: { remediation: 'Use Magic Auth on the hosted AuthKit page' },
+  signInWithSSO: { remediation: 'Redirect to getSignInUrl() with the organization; SSO connections live in WorkOS' },
+  signUp: { remediation: 'Redirect to getSignUpUrl()' },
+  signOut: { remediation: 'Call signOut() from @workos-inc/authkit-nextjs' },
+  getUser: { remediation: 'Read the user from withAuth() (server) or useAuth() (client)' },
+  getSession: { remediation: 'Read the session from withAuth(); AuthKit keeps it in a sealed cookie' },
+  refreshSession: { remediation: 'Remove; authkitMiddleware() refreshes the session' },
+  exchangeCodeForSession: { remediation: 'Replace the callback route with handleAuth()' },
+  onAuthStateChange: {
+    remediation: 'Derive auth state from useAuth() under <AuthKitProvider>; there is no event subscription',
+    manual: true,
+    noEquivalent: true,
+  },
+  resetPasswordForEmail: {
+    remediation: 'Use the hosted AuthKit reset flow or workos.userManagement.createPasswordReset()',
+    manual: true,
+  },
+  updateUser: { remediation: 'Use workos.userManagement.updateUser()', manual: true },
+};
+
+const AUTH_CALL_PATTERN = new RegExp(`\\.auth\\.(${Object.keys(AUTH_METHODS).join('|')})\\s*\\(`);
+const CLIENT_PATTERN = /\b(createClient|createServerClient|createBrowserClient|createServerComponentClient)\s*\(/;
+/** Database, storage, RPC and realtime calls; `Array.from()` and friends excluded */
+const DATA_PATTERN =
+  /(?<!Array|Buffer|Object)\.(from)\(\s*['"`]|\.(rpc)\(\s*['"`]|\.(storage)\s*\.\s*from\(|\.(channel)\(\s*['"`]/;
+const DATA_KINDS: Record<string, string> = {
+  from: 'Database query',
+  rpc: 'RPC call',
+  storage: 'Storage access',
+  channel: 'Realtime channel',
+};
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?)$/;
+
+const ENV_FILES = ['.env', '.env.local', '.env.example'];
+const ENV_PATTERN = /^\s*(?:export\s+)?((?:NEXT_PUBLIC_|VITE_|PUBLIC_)?SUPABASE_[A-Z0-9_]+)\s*=/;
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = await ctx.files();
+  const findings: MigrationFinding[] = [];
+
+  for (const { file, raw, deps } of await readPackageManifests(ctx)) {
+    for (const name of [...AUTH_PACKAGES, ...CLIENT_PACKAGES].filter((p) => deps[p])) {
+      const authOnly = AUTH_PACKAGES.includes(name);
+      findings.push({
+        provider: 'supabase',
+        code: 'supabase-dependency',
+        severity: 'info',
+        message: `${name} ${deps[name]} in ${file}`,
+        file,
+        line: findLines(raw, new RegExp(`"${name.replace('/', '\\/')}"`))[0]?.line,
+        remediation: authOnly
+          ? `Remove ${name}; install @workos-inc/authkit-nextjs`
+          : `Keep ${name} for data access; only its auth calls move to AuthKit`,
+        confidence: authOnly ? 0.4 : 0.2,
+        details: { package: name },
+      });
+    }
+  }
+
+  if (findings.length === 0) return [];
+
+  for (const file of files.filter((f) => SOURCE_FILE_PATTERN.test(f))) {
+    const content = await ctx.readFile(file);
+    if (!content || !/supabase/i.test(content)) continue;
+
+    for (const { line, text, match } of findLines(content, AUTH_CALL_PATTERN)) {
+      const method = AUTH_METHODS[match[1]];
+      findings.push({
+        provider: 'supabase',
+        code: 'supabase-auth-call',
+        severity: 'warning',
+        message: `supabase.auth.${match[1]}()`,
+        file,
+        line,
+        evidence: text,
+        remediation: method.remediation,
+        confidence: 0.4,
+        manual: method.manual,
+        noEquivalent: method.noEquivalent,
+        details: { method: match[1] },
+      });
+    }
+
+    if (content.includes('@supabase/')) {
+      for (const { line, text, match } of findLines(content, CLIENT_PATTERN)) {
+        findings.push({
+          provider: 'supabase',
+          code: 'supabase-client',
+          severity: 'info',
+          message: `Supabase client created with ${match[1]}()`,
+          file,
+          line,
+          evidence: text,
+          remediation:
+            'Keep the client for data access; drop its auth/cookie session options once sign-in goes through AuthKit',
+          confidence: 0.1,
+        });
+      }
+    }
+
+    for (const { line, text } of findLines(content, /<Auth\b[^>]*supabaseClient=/)) {
+      findings.push({
+        provider: 'supabase',
+        code: 'supabase-auth-ui',
+        severity: 'warning',
+        message: '<Auth> from @supabase/auth-ui-react renders the sign-in form',
+        file,
+        line,
+        evidence: text,
+        remediation: 'Remove the embedded form and link to getSignInUrl(); AuthKit hosts the sign-in UI',
+        confidence: 0.3,
+        manual: true,
+      });
+    }
+
+    for (const { line, text, match } of findLines(content, DATA_PATTERN)) {
+      const kind = match[1] ?? match[2] ?? match[3] ?? match[4];
+      findings.push({
+        provider: 'supabase',
+        code: 'supabase-data-access',
+        severity: 'info',
+        message: `${DATA_KINDS[kind]} stays on Supabase`,
+        file,
+        line,
+        evidence: text,
+        remediation: 'If row level security reads auth.uid(), add WorkOS as a Supabase third-party auth provider',
+        confidence: 0,
+        outOfScope: true,
+        details: { kind },
+      });
+    }
+  }
+
+  for (const file of ENV_FILES) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    for (const { line, match } of findLines(content, ENV_PATTERN)) {
+      // Supabase env vars keep configuring the database client
+      findings.push({
+        provider: 'supabase',
+        code: 'supabase-env',
+        severity: 'info',
+        message: `${match[1]} is still needed for the database client`,
+        file,
+        line,
+        confidence: 0,
+        outOfScope: true,
+        details: { envVar: match[1] },
+      });
+    }
+  }
+
+  return findings;
+}
+
+export const supabase: ProviderDetector = {
+  name: 'supabase',
+  description: 'Supabase Auth calls and auth helper packages; database queries are left alone',
+  language: 'javascript',
+  files: new RegExp(`(^|/)package\\.json$|${SOURCE_FILE_PATTERN.source}|^\\.env`),
+  detect,
+};
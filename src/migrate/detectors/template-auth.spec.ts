@@ -0,0 +1,120 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { buildMigrationPrompt } from '../prompt.js';
+import { createScanContext } from '../scan.js';
+import { extractTemplateReferences } from '../template-auth.js';
+import { templateAuth } from './template-auth.js';
+
+const GO_LAYOUT = `<nav>
+  {{if .User}}
+    <a href="/logout">{{T "nav.sign_out"}}</a>
+  {{else}}
+    <a href="{{.LoginURL}}">{{T "nav.sign_in"}}</a>
+  {{end}}
+</nav>
+`;
+
+const JINJA_BASE = `{% if user.is_authenticated %}
+  <a href="{{ url_for('logout') }}">{% trans %}Abmelden{% endtrans %}</a>
+{% endif %}
+`;
+
+const NAV = `export function Nav({ user }) {
+  return (
+    <nav>
+      {user ? (
+        <button onClick={() => logout({ returnTo: window.location.origin })}>{t('nav.logout')}</button>
+      ) : (
+        <a href="https://example.auth0.com/authorize?client_id=abc">{t('nav.login')}</a>
+      )}
+    </nav>
+  );
+}
+`;
+
+const PAGE = `<script>
+  const signedIn = document.cookie.includes('logged_in=');
+  if (Cookies.get('appSession')) showMenu();
+</script>
+`;
+
+describe('template-auth detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'template-auth-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('finds login and logout links, sign-in conditionals and cookie reads', async () => {
+    write('templates/layout.gohtml', GO_LAYOUT);
+    write('templates/base.html', JINJA_BASE);
+    write('src/components/Nav.tsx', NAV);
+    write('views/index.html', PAGE);
+
+    const findings = await templateAuth.detect(createScanContext(root));
+
+    expect(findings.map((f) => [f.code, f.file, f.line, f.details?.target])).toEqual([
+      ['template-conditional', 'src/components/Nav.tsx', 4, 'user'],
+      ['template-logout', 'src/components/Nav.tsx', 5, 'logout'],
+      ['template-login', 'src/components/Nav.tsx', 7, 'https://example.auth0.com/authorize?client_id=abc'],
+      ['template-conditional', 'templates/base.html', 1, 'user.is_authenticated'],
+      ['template-logout', 'templates/base.html', 2, 'logout'],
+      ['template-conditional', 'templates/layout.gohtml', 2, '.User'],
+      ['template-logout', 'templates/layout.gohtml', 3, '/logout'],
+      ['template-login', 'templates/layout.gohtml', 5, '{{.LoginURL}}'],
+      ['template-cookie', 'views/index.html', 2, 'logged_in'],
+      ['template-cookie', 'views/index.html', 3, 'appSession'],
+    ]);
+    expect(findings.every((f) => f.provider === '*' && f.confidence === 0)).toBe(true);
+    // The old provider's hosted page and a cookie AuthKit doesn't set break without an error
+    expect(findings.filter((f) => f.severity === 'warning').map((f) => f.details?.target)).toEqual([
+      'https://example.auth0.com/authorize?client_id=abc',
+      'logged_in',
+      'appSession',
+    ]);
+  });
+
+  it('ignores link text, unrelated links and tests', async () => {
+    write('templates/help.html', '<p>Sign in with your email, then log out when you are done.</p>\n');
+    write('templates/blog.html', '<a href="/blog/index">Blog</a>\n<path d="M0 0h24v24H0z"/>\n');
+    write('src/components/Nav.test.tsx', NAV);
+
+    expect(await templateAuth.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('adds template steps to the migration prompt', async () => {
+    write('templates/layout.gohtml', GO_LAYOUT);
+    write('templates/base.html', JINJA_BASE);
+    const references = extractTemplateReferences(await templateAuth.detect(createScanContext(root)));
+
+    expect(references).toContainEqual({ kind: 'logout', file: 'templates/layout.gohtml', line: 3, target: '/logout' });
+
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.6,
+      envMapping: {},
+      guidance: [],
+      findings: [],
+      templateReferences: references,
+      excludedFiles: ['templates/base.html'],
+    });
+
+    expect(prompt).toContain('### Templates');
+    expect(prompt).toMatch(/^3\. Change only the URL attribute or the condition/m);
+    expect(prompt).toContain('- templates/layout.gohtml:3: logout link /logout');
+    expect(prompt).not.toContain('templates/base.html:2');
+  });
+});
@@ -0,0 +1,196 @@
+import {
+  ANY_PROVIDER,
+  type MigrationFinding,
+  type ProviderDetector,
+  type ScanContext,
+  type TemplateAuthKind,
+} from '../types.js';
+
+/**
+ * Login and logout links, sign-in conditionals and session-cookie reads in
+ * templates, whichever provider the app uses (see template-auth.ts). Only
+ * URLs, route names, handlers and conditions are matched, never link text,
+ * so templates in any language are found the same way.
+ */
+
+/** HTML, server-side templates and components */
+const TEMPLATE_FILE_PATTERN =
+  /\.(html?|x?html|tmpl|gohtml|tpl|ejs|hbs|handlebars|mustache|erb|haml|slim|jinja2?|j2|njk|twig|liquid|cshtml|razor|vue|svelte|astro|[jt]sx)$/i;
+const TEST_FILE_PATTERN = /(\.(test|spec|stories)\.[^/]+$|(^|\/)(__tests__|tests?|spec)\/)/;
+
+/** Last path segment of a login or logout URL, e.g. `/login`, `/api/auth/logout`, `/v2/logout?returnTo=` */
+const LOGIN_URL = /(?:^|[/=])(?:log-?in|log_in|sign-?in|sign_in|authorize)(?:$|[/?#.&])/i;
+const LOGOUT_URL = /(?:^|[/=])(?:log-?out|log_out|sign-?out|sign_out|end_?session)(?:$|[/?#.&])/i;
+/** Words of a route name or handler, as snake_case: `auth_sign_in`, `logout_url`, `destroy_user_session_path` */
+const LOGIN_NAME = /(?:^|_)(?:log_?in|sign_?in)(?:_|$)|(?:^|_)new_(?:\w+_)?session(?:_|$)/;
+const LOGOUT_NAME = /(?:^|_)(?:log_?out|sign_?out)(?:_|$)|(?:^|_)destroy_(?:\w+_)?session(?:_|$)/;
+/** An absolute URL on another host: the old provider's hosted page */
+const HOSTED_URL = /^(?:https?:)?\/\/(?!localhost\b|127\.0\.0\.1\b|\[::1\])/i;
+
+/** `href="/login"`, `action='/logout'`, `to="/sign-in"`, `:href="..."`, `href={logoutUrl}` */
+const URL_ATTRIBUTE =
+  /(?:^|[\s:])(?:href|action|formaction|to)\s*=\s*(?:\{?\s*(["'`])([^"'`]*)\1|\{\s*([\w$.]+)\s*\})/gi;
+/** A value the template fills in, e.g. `{{.LogoutURL}}`, `<%= login_path %>`, `${signInUrl}` */
+const TEMPLATE_EXPRESSION = /\{\{|\{%|<%|\$\{|^@/;
+/** `location.href = '/logout'`, `location.assign("/login")` */
+const NAVIGATION =
+  /\blocation(?:\.href)?\s*=\s*(["'`])([^"'`]*)\1|\blocation\.(?:assign|replace)\(\s*(["'`])([^"'`]*)\3/g;
+/**
+ * Named routes: `url_for('logout')`, `{% url 'login' %}`, `route('logout')`,
+ * `Url.Action("Login", ...)`, `link_to ..., destroy_user_session_path`
+ */
+const NAMED_ROUTE =
+  /\b(?:url_for|url|route|path|Url\.(?:Action|Page)|Html\.ActionLink|button_to)\s*\(\s*(["'])([\w.:/-]+)\1|\{%-?\s*url\s+(["'])([\w.:/-]+)\3|\b((?:new|destroy)_\w*session_(?:path|url)|\w*(?:log_?in|log_?out|sign_?in|sign_?out)_(?:path|url))\b/gi;
+/** Click handlers: `onClick={() => loginWithRedirect()}`, `@click="logout"`, `on:click={signOut}` */
+const CLICK_HANDLER =
+  /(?:\bonclick|@click|v-on:click|on:click|\(click\))\s*=\s*\{?\s*["'`]?[^"'`}\n]*?\b((?:\w*(?:login|log_in|signin|sign_in|logout|log_out|signout|sign_out)\w*))\b/gi;
+
+/** The user, or whether there is one: `user`, `.User`, `current_user`, `request.user.is_authenticated`, `$session` */
+const SUBJECT =
+  '(?<![\\w$])\\$?\\.?(?:[\\w$]+\\.)*(?:current_?user|user|is_?authenticated|(?:is_?)?(?:logged|signed)_?in|user_signed_in\\?|session|profile|Auth::(?:check|guest|user)\\(\\)|auth\\(\\)->(?:check|guest|user)\\(\\))(?:\\.[\\w$]+)*(?![\\w$])';
+/** Openers of a conditional block in each template language, up to its condition */
+const CONDITION_OPENERS = [
+  // Go templates, Handlebars, Mustache: `{{if .User}}`, `{{#if user}}`, `{{^user}}`
+  '\\{\\{[-~]?\\s*(?:#?\\s*(?:if|unless|with)\\s+|[#^])(?:not\\s+|!)?\\(?\\s*',
+  // Jinja, Django, Twig, Liquid, Nunjucks: `{% if user.is_authenticated %}`
+  '\\{%-?\\s*(?:if|elif|elsif|unless)\\s+(?:not\\s+)?',
+  // ERB, EJS: `<% if current_user %>`, `<% if (locals.user) { %>`
+  '<%[-=]?\\s*(?:if|unless|elsif)\\s*\\(?\\s*!?\\s*',
+  // Razor, Blade: `@if (User.Identity.IsAuthenticated)`, `@if(Auth::check())`
+  '@(?:if|elseif)\\s*\\(\\s*!?\\s*',
+  // Vue, Angular, Alpine: `v-if="user"`, `*ngIf="isAuthenticated"`
+  '(?:v-if|v-else-if|v-show|\\*ngIf|x-show|x-if)\\s*=\\s*["\']\\s*!?\\s*',
+  // Svelte: `{#if $user}`
+  '\\{[#:](?:if|else if)\\s+!?\\s*',
+];
+const CONDITIONAL = new RegExp(`(?:${CONDITION_OPENERS.join('|')})(${SUBJECT})`, 'gi');
+/** JSX: `{user && (`, `{isAuthenticated ? `, `{!session && ` */
+const JSX_CONDITIONAL = new RegExp(`\\{\\s*!?\\s*(${SUBJECT})\\s*(?:&&|\\?(?![.?]))`, 'gi');
+/** Blade's `@auth` and `@guest` directives */
+const BLADE_DIRECTIVE = /(?:^|\s)(@(?:auth|guest))\b/g;
+
+/** Cookie reads in script: `Cookies.get('session')`, `getCookie("id_token")`, a `name=` next to `document.cookie` */
+const COOKIE_READ = /\b(?:getCookie|Cookies\.get|cookies\.get|\$cookies\.get|useCookie)\(\s*['"`]([\w.-]+)['"`]/g;
+const DOCUMENT_COOKIE = /\bdocument\.cookie\b/;
+const COOKIE_NAME = /['"`;\s]([\w.-]+)=(?![=>])/g;
+/** Cookie names an old login would have used for the signed-in user */
+const AUTH_COOKIE = /user|session|token|auth|logged|profile/i;
+
+const MESSAGES: Record<TemplateAuthKind, (target: string, hosted: boolean) => string> = {
+  login: (target, hosted) => `Template links to ${hosted ? "the old provider's hosted login" : 'login'} (${target})`,
+  logout: (target, hosted) => `Template links to ${hosted ? "the old provider's hosted logout" : 'logout'} (${target})`,
+  conditional: (target) => `Template shows markup by sign-in state (${target})`,
+  cookie: (target) => `Template script reads the ${target} cookie`,
+};
+
+const REMEDIATIONS: Record<TemplateAuthKind, string> = {
+  login: "Point it at the app's AuthKit sign-in route; keep the link text as written",
+  logout: 'Point it at the route that clears the session and redirects to the AuthKit logout URL; keep the link text',
+  conditional: 'Base it on the AuthKit user the server passes to the template',
+  cookie: "AuthKit's session cookie is HttpOnly; render the signed-in state on the server or fetch it from a route",
+};
+
+function templateFinding(
+  kind: TemplateAuthKind,
+  target: string,
+  file: string,
+  line: number,
+  evidence: string,
+  hosted: boolean,
+): MigrationFinding {
+  return {
+    provider: ANY_PROVIDER,
+    code: `template-${kind}`,
+    severity: hosted || kind === 'cookie' ? 'warning' : 'info',
+    message: MESSAGES[kind](target, hosted),
+    file,
+    line,
+    evidence,
+    remediation: REMEDIATIONS[kind],
+    // Templates say nothing about which provider the app uses
+    confidence: 0,
+    details: { template: kind, target, ...(hosted && { hosted: true }) },
+  };
+}
+
+function urlKind(url: string): TemplateAuthKind | null {
+  if (LOGOUT_URL.test(url)) return 'logout';
+  return LOGIN_URL.test(url) ? 'login' : null;
+}
+
+/** `signInUrl`, `auth.logout`, `{{.LogoutURL}}` as `sign_in_url`, `auth_logout`, `logout_url` */
+function nameWords(name: string): string {
+  return name
+    .replace(/([a-z\d])([A-Z])/g, '$1_$2')
+    .toLowerCase()
+    .replace(/[^a-z\d]+/g, '_')
+    .replace(/^_|_$/g, '');
+}
+
+function nameKind(name: string): TemplateAuthKind | null {
+  const words = nameWords(name);
+  if (LOGOUT_NAME.test(words)) return 'logout';
+  return LOGIN_NAME.test(words) ? 'login' : null;
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => TEMPLATE_FILE_PATTERN.test(f) && !TEST_FILE_PATTERN.test(f));
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    // The first line per kind and target in a file is enough to point at it
+    const seen = new Set<string>();
+
+    content.split(/\r?\n/).forEach((raw, index) => {
+      const text = raw.trim();
+      let link = false;
+      const add = (kind: TemplateAuthKind | null, target: string | undefined, hosted = false) => {
+        if (!kind || !target) return;
+        if (kind === 'login' || kind === 'logout') link = true;
+        if (seen.has(`${kind}:${target}`)) return;
+        seen.add(`${kind}:${target}`);
+        findings.push(templateFinding(kind, target, file, index + 1, text, hosted));
+      };
+
+      for (const match of raw.matchAll(URL_ATTRIBUTE)) {
+        const value = match[2] ?? match[3];
+        // A value the template fills in is read by its name, e.g. {{.LogoutURL}}
+        const filled = match[3] !== undefined || TEMPLATE_EXPRESSION.test(value);
+        add(filled ? nameKind(value) : urlKind(value), value, !filled && HOSTED_URL.test(value));
+      }
+      for (const match of raw.matchAll(NAVIGATION)) {
+        const url = match[2] ?? match[4];
+        add(urlKind(url), url, HOSTED_URL.test(url));
+      }
+      // Route helpers inside a link already found would list the same link twice
+      if (!link) {
+        for (const match of raw.matchAll(NAMED_ROUTE)) {
+          const name = match[2] ?? match[4] ?? match[5];
+          // A quoted path is a URL; anything else is a route name
+          add(name.includes('/') ? urlKind(name) : nameKind(name), name, HOSTED_URL.test(name));
+        }
+      }
+      for (const match of raw.matchAll(CLICK_HANDLER)) add(nameKind(match[1]), match[1]);
+      for (const match of raw.matchAll(CONDITIONAL)) add('conditional', match[1]);
+      for (const match of raw.matchAll(JSX_CONDITIONAL)) add('conditional', match[1]);
+      for (const match of raw.matchAll(BLADE_DIRECTIVE)) add('conditional', match[1]);
+      const cookies = [...raw.matchAll(COOKIE_READ)].map((match) => match[1]);
+      if (DOCUMENT_COOKIE.test(raw)) cookies.push(...[...raw.matchAll(COOKIE_NAME)].map((match) => match[1]));
+      for (const name of cookies) {
+        if (AUTH_COOKIE.test(name)) add('cookie', name);
+      }
+    });
+  }
+
+  return findings;
+}
+
+export const templateAuth: ProviderDetector = {
+  name: 'template-auth',
+  description: 'Login and logout links, sign-in conditionals and session-cookie reads in templates',
+  language: 'any',
+  files: TEMPLATE_FILE_PATTERN,
+  detect,
+};
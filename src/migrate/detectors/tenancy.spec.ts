@@ -0,0 +1,103 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { buildMigrationPrompt } from '../prompt.js';
+import { createScanContext } from '../scan.js';
+import { extractTenancySignals } from '../tenancy.js';
+import { tenancy } from './tenancy.js';
+
+const ROUTES = `const router = require('express').Router();
+
+router.get('/orgs/:orgId/invoices', requireUser, async (req, res) => {
+  const claims = jwt.decode(req.cookies.id_token);
+  if (claims.tenant_id !== req.params.orgId) return res.sendStatus(403);
+  res.json(await listInvoices(req.params.orgId));
+});
+`;
+
+const SCHEMA = `CREATE TABLE invoices (
+  id UUID PRIMARY KEY,
+  tenant_id UUID NOT NULL REFERENCES tenants(id),
+  amount INTEGER NOT NULL
+);
+`;
+
+describe('tenancy detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'tenancy-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('finds tenant claims, route parameters and columns', async () => {
+    write('routes/invoices.js', ROUTES);
+    write('db/schema.sql', SCHEMA);
+    write('app/[tenant]/page.tsx', 'export default function Page() {}');
+
+    const findings = await tenancy.detect(createScanContext(root));
+
+    expect(findings.map((f) => [f.code, f.file, f.line, f.details?.name])).toEqual([
+      ['tenant-route', 'app/[tenant]/page.tsx', undefined, '[tenant]'],
+      ['tenant-column', 'db/schema.sql', 3, 'tenant_id'],
+      ['tenant-route', 'routes/invoices.js', 3, 'orgId'],
+      ['tenant-claim', 'routes/invoices.js', 5, 'tenant_id'],
+    ]);
+    expect(findings.every((f) => f.provider === '*' && f.confidence === 0)).toBe(true);
+  });
+
+  it('ignores single-tenant apps and tests', async () => {
+    write('routes/users.js', "router.get('/users/:userId', show);\nconst { sub } = claims;\n");
+    write('test/routes.spec.js', ROUTES);
+
+    expect(await tenancy.detect(createScanContext(root))).toEqual([]);
+  });
+
+  it('adds an organizations setup to the migration prompt', async () => {
+    write('routes/invoices.js', ROUTES);
+    const signals = extractTenancySignals([
+      ...(await tenancy.detect(createScanContext(root))),
+      // Reported by a provider detector, e.g. go-claim-read
+      {
+        provider: 'oidc',
+        code: 'go-claim-read',
+        severity: 'info',
+        message: 'Reads the tenant_id claim',
+        file: 'auth.go',
+        line: 9,
+        confidence: 0,
+        details: { claim: 'tenant_id' },
+      },
+    ]);
+
+    expect(signals).toEqual([
+      { kind: 'route', name: 'orgId', file: 'routes/invoices.js', line: 3 },
+      { kind: 'claim', name: 'tenant_id', file: 'routes/invoices.js', line: 5 },
+    ]);
+
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.6,
+      envMapping: {},
+      guidance: [],
+      findings: [],
+      tenancy: signals,
+    });
+
+    expect(prompt).toContain('### Organizations');
+    expect(prompt).toContain('do not reduce it to single-tenant code');
+    expect(prompt).toMatch(/^1\. Create a WorkOS organization for each tenant/m);
+    expect(prompt).toContain("- claim tenant_id (routes/invoices.js:5) → the session's organizationId");
+  });
+});
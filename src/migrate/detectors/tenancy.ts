@@ -0,0 +1,119 @@
+import { findLines } from '../scan.js';
+import { ANY_PROVIDER, type MigrationFinding, type ProviderDetector, type ScanContext } from '../types.js';
+
+/**
+ * Signs that the app is multi-tenant, whichever provider it uses: tenant or
+ * organization claims read from the token, tenant parameters in routes and
+ * tenant columns in the schema. AuthKit models tenants as organizations, so
+ * these turn into an organizations setup in the migration plan (see
+ * tenancy.ts) instead of single-tenant code.
+ */
+
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?|go|py|rb|php|java|kt|cs)$/;
+const SCHEMA_FILE_PATTERN =
+  /(\.sql|\.prisma)$|(^|\/)(migrations?|models?|entities|entity|schema)\/[^/]+$|(^|\/)models?\.(py|rb|go|[jt]s)$/i;
+const TEST_FILE_PATTERN = /(\.(test|spec)\.[^/]+$|_test\.go$|(^|\/)(__tests__|tests?|spec)\/)/;
+
+/** A tenant identifier name, e.g. `tenant`, `orgId`, `organization_slug`, `workspaceId` */
+const TENANT_NAME = '(?:tenant|org|organization|workspace)s?(?:[_-]?(?:id|slug|key))?';
+
+/** A path param naming the tenant: `/:tenantId`, `/{org_id}`, `/<int:organization_id>` */
+const ROUTE_PARAM_PATTERN = new RegExp(
+  `(['"\`])\\/[^'"\`\\n]*?(?::(${TENANT_NAME})\\b|\\{(${TENANT_NAME})\\}|<(?:\\w+:)?(${TENANT_NAME})>)`,
+  'i',
+);
+/** A Next.js or SvelteKit route directory naming the tenant, e.g. `app/[tenant]/page.tsx` */
+const ROUTE_DIR_PATTERN = new RegExp(`(?:^|\\/)\\[(${TENANT_NAME})\\]\\/`, 'i');
+
+/** A tenant foreign key: `tenant_id`, `organizationId`, `TenantID` */
+const COLUMN_PATTERN = /\b((?:tenant|org|organization|workspace)_?id)\b/i;
+
+/**
+ * A tenant claim read from a decoded token, e.g. `claims.org_id`,
+ * `payload["tenant_id"]`. Go and Java claim reads come from their own
+ * detectors (go-claim-read, spring-claim-read).
+ */
+const CLAIM_READ_PATTERN =
+  /\b(?:claims|payload|decoded|token|idToken|id_token|accessToken)(?:\??\.(org_id|organization_id|tenant_id|tenant|tid)\b|\[(['"])(org_id|organization_id|tenant_id|tenant|tid)\2\])/i;
+const CLAIM_LANGUAGES = /\.(m?[jt]sx?|py|rb|php)$/;
+
+type TenancyKind = 'claim' | 'route' | 'column';
+
+const MESSAGES: Record<TenancyKind, (name: string) => string> = {
+  claim: (name) => `Reads the tenant from the ${name} claim`,
+  route: (name) => `Route takes the tenant as a parameter (${name})`,
+  column: (name) => `Schema keys records by tenant (${name})`,
+};
+
+const REMEDIATIONS: Record<TenancyKind, string> = {
+  claim: "Read the organization from the AuthKit session's organizationId (the org_id claim)",
+  route: "Resolve the WorkOS organization from the parameter and check it matches the session's organization",
+  column: 'Keep the column; store the WorkOS organization ID of each tenant alongside it',
+};
+
+function tenancyFinding(
+  kind: TenancyKind,
+  name: string,
+  file: string,
+  line?: number,
+  evidence?: string,
+): MigrationFinding {
+  return {
+    provider: ANY_PROVIDER,
+    code: `tenant-${kind}`,
+    severity: 'info',
+    message: MESSAGES[kind](name),
+    file,
+    ...(line !== undefined && { line }),
+    ...(evidence && { evidence }),
+    remediation: REMEDIATIONS[kind],
+    // Multi-tenancy says nothing about which provider the app uses
+    confidence: 0,
+    details: { tenancy: kind, name, ...(kind === 'claim' && { claim: name }) },
+  };
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => !TEST_FILE_PATTERN.test(f));
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    // The first line per name in a file is enough to point at it
+    const seen = new Set<string>();
+    const add = (kind: TenancyKind, name: string, line?: number, evidence?: string) => {
+      if (seen.has(`${kind}:${name}`)) return;
+      seen.add(`${kind}:${name}`);
+      findings.push(tenancyFinding(kind, name, file, line, evidence));
+    };
+
+    const routeDir = ROUTE_DIR_PATTERN.exec(file);
+    if (routeDir) add('route', `[${routeDir[1]}]`);
+
+    const schema = SCHEMA_FILE_PATTERN.test(file);
+    if (!schema && !SOURCE_FILE_PATTERN.test(file)) continue;
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+
+    if (schema) {
+      for (const { line, text, match } of findLines(content, COLUMN_PATTERN)) add('column', match[1], line, text);
+      continue;
+    }
+    for (const { line, text, match } of findLines(content, ROUTE_PARAM_PATTERN)) {
+      add('route', match[2] ?? match[3] ?? match[4], line, text);
+    }
+    if (!CLAIM_LANGUAGES.test(file)) continue;
+    for (const { line, text, match } of findLines(content, CLAIM_READ_PATTERN)) {
+      add('claim', match[1] ?? match[3], line, text);
+    }
+  }
+
+  return findings;
+}
+
+export const tenancy: ProviderDetector = {
+  name: 'tenancy',
+  description: 'Tenant claims, route parameters and columns that map to AuthKit organizations',
+  language: 'any',
+  files: new RegExp(`${SOURCE_FILE_PATTERN.source}|${SCHEMA_FILE_PATTERN.source}|${ROUTE_DIR_PATTERN.source}`, 'i'),
+  detect,
+};
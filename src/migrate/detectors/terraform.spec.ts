@@ -0,0 +1,127 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { detectProviders } from '../detect.js';
+import { buildMigrationPrompt } from '../prompt.js';
+import { extractRedirectUris } from '../redirect-uris.js';
+import { createScanContext } from '../scan.js';
+import { extractTerraformClients, terraformChecklist } from '../terraform.js';
+import { terraform } from './terraform.js';
+
+const AUTH0_TF = `provider "auth0" {
+  domain    = "acme.us.auth0.com"
+  client_id = "tfDeployClient123"
+}
+
+resource "auth0_client" "web" {
+  name     = "Acme Web" # shown on the login page
+  app_type = "regular_web"
+  callbacks = [
+    "https://app.acme.com/callback",
+    "\${var.preview_url}/callback",
+  ]
+  allowed_logout_urls = ["https://app.acme.com"]
+  web_origins         = ["https://app.acme.com"]
+
+  jwt_configuration {
+    alg = "RS256"
+  }
+}
+
+output "auth0_client_id" {
+  value = auth0_client.web.client_id
+}
+
+resource "auth0_client_credentials" "web" {
+  client_id = auth0_client.web.id
+}
+`;
+
+describe('terraform detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'terraform-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('reads auth0_client resources, client IDs and references', async () => {
+    write('infra/auth0.tf', AUTH0_TF);
+
+    const findings = await terraform.detect(createScanContext(root));
+
+    expect(findings.map((f) => [f.code, f.line, f.manual ?? false])).toEqual([
+      ['auth0-terraform-client-id', 3, false],
+      ['auth0-terraform-client', 6, true],
+      ['auth0-terraform-callback', 10, false],
+      ['auth0-terraform-callback', 11, false],
+      ['auth0-terraform-reference', 22, true],
+    ]);
+    expect(findings[0].details).toMatchObject({ clientId: 'tfDeployClient123', domain: 'acme.us.auth0.com' });
+    expect(findings[1].message).toBe('Terraform manages the Auth0 application "Acme Web" (auth0_client.web)');
+    expect(findings[4].remediation).toContain('WORKOS_CLIENT_ID');
+    expect(extractRedirectUris(findings).map((r) => r.uri)).toEqual(['https://app.acme.com/callback']);
+  });
+
+  it('lists the dashboard settings the WorkOS app has to match', async () => {
+    write('infra/auth0.tf', AUTH0_TF);
+    const clients = extractTerraformClients(await terraform.detect(createScanContext(root)));
+
+    expect(clients).toEqual([
+      {
+        resource: 'auth0_client.web',
+        name: 'Acme Web',
+        file: 'infra/auth0.tf',
+        line: 6,
+        callbacks: ['https://app.acme.com/callback', '${var.preview_url}/callback'],
+        logoutUrls: ['https://app.acme.com'],
+        webOrigins: ['https://app.acme.com'],
+      },
+    ]);
+    expect(terraformChecklist(clients)).toEqual([
+      'Redirect URI ${var.preview_url}/callback (fill in the Terraform variables)',
+      'Sign-out redirect https://app.acme.com',
+      'Allowed web origin https://app.acme.com',
+    ]);
+  });
+
+  it('only runs with the terraform option and skips the provider cache', async () => {
+    write('infra/auth0.tf', AUTH0_TF);
+    write('infra/.terraform/modules/auth0/main.tf', AUTH0_TF);
+
+    expect((await detectProviders(root)).matches).toEqual([]);
+
+    const { matches } = await detectProviders(root, undefined, { terraform: true });
+    expect(matches.map((m) => m.provider)).toEqual(['auth0']);
+    expect(matches[0].findings.every((f) => f.file === 'infra/auth0.tf')).toBe(true);
+  });
+
+  it('tells the agent to leave the Terraform alone', async () => {
+    write('infra/auth0.tf', AUTH0_TF);
+    const findings = await terraform.detect(createScanContext(root));
+
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.6,
+      envMapping: {},
+      guidance: [],
+      findings,
+      terraformClients: extractTerraformClients(findings),
+    });
+    expect(prompt).toContain('### Terraform');
+    expect(prompt).toContain('Leave the .tf files unchanged');
+    expect(prompt).toContain('- infra/auth0.tf:6: auth0_client.web ("Acme Web")');
+    expect(prompt).not.toContain('### Detected usages');
+  });
+});
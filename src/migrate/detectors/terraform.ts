@@ -0,0 +1,255 @@
+import { findLines } from '../scan.js';
+import type { MigrationFinding, ProviderDetector, ScanContext } from '../types.js';
+
+/**
+ * Auth0 tenants managed in Terraform. The `auth0_client` resources hold what
+ * the app's login was configured with in the dashboard: the callback URLs,
+ * the sign-out redirects (`allowed_logout_urls`) and the CORS origins
+ * (`web_origins`). The migration can't change the infrastructure, but those
+ * settings are what the WorkOS app has to match, and client IDs and
+ * `auth0_client.*.client_id` references (outputs that template env values,
+ * Kubernetes secrets) say the app signs in with Auth0. Reading every `.tf`
+ * file is more than the other detectors do, so this one only runs with
+ * `--scan-terraform`.
+ */
+
+const TERRAFORM_FILE_PATTERN = /\.tf$/;
+/** Provider and module caches `terraform init` downloads */
+const CACHE_DIR_PATTERN = /(^|\/)\.terraform\//;
+
+/** `resource "auth0_client" "web" {`, `data "auth0_client" "web" {`, `provider "auth0" {` */
+const BLOCK_PATTERN =
+  /^\s*(resource|data)\s+"(auth0_client|auth0_client_callbacks)"\s+"([\w-]+)"\s*\{|^\s*provider\s+"(auth0)"\s*\{/;
+/** Where a client's ID or secret is passed on: `auth0_client.web.client_id` */
+const REFERENCE_PATTERN = /\b(?:data\.)?(auth0_client(?:_credentials)?)\.([\w-]+)\.(client_id|client_secret)\b/;
+/** Auth0 resources tying one client to another, which go with the tenant */
+const WIRING_PATTERN = /^client_id\s*=/;
+const ATTRIBUTE_PATTERN = /^\s*([\w-]+)\s*=\s*(.*)$/;
+const STRING_PATTERN = /"((?:[^"\\]|\\.)*)"/g;
+
+const REFERENCE_TARGETS: Record<string, string> = {
+  client_id: 'WORKOS_CLIENT_ID',
+  client_secret: 'WORKOS_API_KEY',
+};
+
+interface Attribute {
+  value: string;
+  line: number;
+}
+
+interface Block {
+  kind: 'resource' | 'data' | 'provider';
+  type: string;
+  label?: string;
+  line: number;
+  attributes: Map<string, Attribute>;
+}
+
+/** The line without comments and string contents, for counting braces */
+function structural(text: string): string {
+  return text
+    .replace(STRING_PATTERN, '""')
+    .replace(/\/\*.*?\*\//g, '')
+    .replace(/(#|\/\/).*$/, '');
+}
+
+/** The line up to a comment that isn't inside a string */
+function withoutComment(text: string): string {
+  let quoted = false;
+  for (let i = 0; i < text.length; i++) {
+    if (quoted && text[i] === '\\') i++;
+    else if (text[i] === '"') quoted = !quoted;
+    else if (!quoted && (text[i] === '#' || text.startsWith('//', i))) return text.slice(0, i);
+  }
+  return text;
+}
+
+function count(text: string, char: string): number {
+  return text.split(char).length - 1;
+}
+
+/**
+ * The Auth0 blocks in a file, with their top-level attributes. Nested
+ * blocks (`jwt_configuration`, `refresh_token`) are skipped, and a list
+ * that spans lines is read to its closing bracket.
+ */
+function readBlocks(content: string): Block[] {
+  const lines = content.split(/\r?\n/);
+  const blocks: Block[] = [];
+  for (let index = 0; index < lines.length; index++) {
+    const header = BLOCK_PATTERN.exec(lines[index]);
+    if (!header) continue;
+    const block: Block = header[4]
+      ? { kind: 'provider', type: header[4], line: index + 1, attributes: new Map() }
+      : {
+          kind: header[1] as Block['kind'],
+          type: header[2],
+          label: header[3],
+          line: index + 1,
+          attributes: new Map(),
+        };
+
+    let depth = 1;
+    let next = index + 1;
+    while (next < lines.length && depth > 0) {
+      const text = lines[next];
+      const attribute = depth === 1 ? ATTRIBUTE_PATTERN.exec(text) : null;
+      if (attribute) {
+        let value = attribute[2];
+        let brackets = count(structural(value), '[') - count(structural(value), ']');
+        while (brackets > 0 && next + 1 < lines.length) {
+          next++;
+          value += '\n' + lines[next];
+          brackets += count(structural(lines[next]), '[') - count(structural(lines[next]), ']');
+        }
+        block.attributes.set(attribute[1], { value, line: next + 1 - count(value, '\n') });
+        depth += count(structural(value), '{') - count(structural(value), '}');
+      } else {
+        depth += count(structural(text), '{') - count(structural(text), '}');
+      }
+      next++;
+    }
+    blocks.push(block);
+    index = next - 1;
+  }
+  return blocks;
+}
+
+/** The string literals in a value, each with the line it's on */
+function strings(attribute: Attribute | undefined): Array<{ value: string; line: number }> {
+  if (!attribute) return [];
+  return attribute.value.split('\n').flatMap((text, offset) =>
+    [...withoutComment(text).matchAll(STRING_PATTERN)].map((m) => ({
+      value: m[1],
+      line: attribute.line + offset,
+    })),
+  );
+}
+
+/** A literal attribute's value; references and expressions have none */
+function literal(attribute: Attribute | undefined): string | undefined {
+  const match = attribute && /^"((?:[^"\\]|\\.)*)"$/.exec(withoutComment(attribute.value).trim());
+  return match && !match[1].includes('${') ? match[1] : undefined;
+}
+
+/** Callbacks with a URL the dashboard can take, to add to the redirect URIs */
+function callbackFindings(file: string, address: string, callbacks: Array<{ value: string; line: number }>) {
+  return callbacks.map(
+    ({ value, line }): MigrationFinding => ({
+      provider: 'auth0',
+      code: 'auth0-terraform-callback',
+      severity: 'info',
+      message: `Callback URL ${value} on ${address}`,
+      file,
+      line,
+      evidence: `"${value}"`,
+      remediation: 'Add it to the WorkOS app as a redirect URI',
+      confidence: 0.2,
+      details: { redirectUri: value },
+    }),
+  );
+}
+
+function clientFinding(file: string, block: Block): MigrationFinding[] {
+  const address = `auth0_client.${block.label}`;
+  const name = literal(block.attributes.get('name'));
+  const appType = literal(block.attributes.get('app_type'));
+  const callbacks = strings(block.attributes.get('callbacks'));
+  const logoutUrls = strings(block.attributes.get('allowed_logout_urls')).map((s) => s.value);
+  const webOrigins = strings(block.attributes.get('web_origins')).map((s) => s.value);
+  return [
+    {
+      provider: 'auth0',
+      code: 'auth0-terraform-client',
+      severity: 'info',
+      message: `Terraform manages the Auth0 application ${name ? `"${name}" ` : ''}(${address})`,
+      file,
+      line: block.line,
+      evidence: `resource "auth0_client" "${block.label}"`,
+      remediation:
+        'Configure the WorkOS app to match its callbacks, sign-out redirects and web origins; ' +
+        'keep the resource until the Auth0 tenant is retired (`workos export terraform` can manage the WorkOS side)',
+      manual: true,
+      confidence: 0.4,
+      details: {
+        terraform: 'auth0_client',
+        resource: address,
+        ...(name && { name }),
+        ...(appType && { appType }),
+        callbacks: callbacks.map((c) => c.value),
+        logoutUrls,
+        webOrigins,
+      },
+    },
+    ...callbackFindings(file, address, callbacks),
+  ];
+}
+
+function blockFindings(file: string, block: Block): MigrationFinding[] {
+  if (block.kind === 'resource' && block.type === 'auth0_client') return clientFinding(file, block);
+  if (block.kind === 'resource') {
+    // auth0_client_callbacks sets a client's callbacks apart from the client
+    return callbackFindings(file, `auth0_client_callbacks.${block.label}`, strings(block.attributes.get('callbacks')));
+  }
+
+  const clientId = block.attributes.get('client_id');
+  const id = literal(clientId);
+  if (!clientId || !id) return [];
+  const domain = literal(block.attributes.get('domain'));
+  const source = block.kind === 'provider' ? 'The auth0 provider' : `data.auth0_client.${block.label}`;
+  return [
+    {
+      provider: 'auth0',
+      code: 'auth0-terraform-client-id',
+      severity: 'info',
+      message: `${source} uses the Auth0 client ${id}${domain ? ` on ${domain}` : ''}`,
+      file,
+      line: clientId.line,
+      evidence: `client_id = "${id}"`,
+      confidence: 0.3,
+      details: { terraform: block.kind === 'provider' ? 'provider' : 'data', clientId: id, ...(domain && { domain }) },
+    },
+  ];
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => TERRAFORM_FILE_PATTERN.test(f) && !CACHE_DIR_PATTERN.test(f));
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    const content = await ctx.readFile(file);
+    if (!content || !/\bauth0/.test(content)) continue;
+
+    for (const block of readBlocks(content)) findings.push(...blockFindings(file, block));
+
+    for (const { line, text, match } of findLines(content, REFERENCE_PATTERN)) {
+      if (WIRING_PATTERN.test(text)) continue;
+      const [reference, , label, attribute] = match;
+      findings.push({
+        provider: 'auth0',
+        code: 'auth0-terraform-reference',
+        severity: 'warning',
+        message: `Passes on the Auth0 ${attribute} of ${reference.replace(/\.\w+$/, '')}`,
+        file,
+        line,
+        evidence: text,
+        remediation:
+          `Whatever reads it (an output that templates env values, a secret) needs ${REFERENCE_TARGETS[attribute]} ` +
+          'from the WorkOS app instead',
+        manual: true,
+        confidence: 0.3,
+        details: { terraform: 'reference', resource: label, attribute },
+      });
+    }
+  }
+
+  return findings;
+}
+
+export const terraform: ProviderDetector = {
+  name: 'terraform',
+  description: 'Auth0 clients, callbacks and client IDs in Terraform (only with --scan-terraform)',
+  language: 'any',
+  files: TERRAFORM_FILE_PATTERN,
+  detect,
+};
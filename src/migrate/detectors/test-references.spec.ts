@@ -0,0 +1,119 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { buildMigrationPrompt } from '../prompt.js';
+import { createScanContext } from '../scan.js';
+import { extractTestReferences, testReferenceLines } from '../test-references.js';
+import type { MigrationFinding } from '../types.js';
+import { testReferences } from './test-references.js';
+
+const GO_TEST = `package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/openid-configuration" {
+			w.Write([]byte(\`{"issuer": "https://example.us.auth0.com/"}\`))
+		}
+	}))
+	defer srv.Close()
+	idToken := signFake(t, map[string]any{"sub": "auth0|123"})
+	_ = idToken
+}
+`;
+
+const TS_SPEC = `import { vi, it } from 'vitest';
+vi.mock('@auth0/nextjs-auth0', () => ({ getSession: vi.fn() }));
+
+it('renders the profile', () => {});
+`;
+
+describe('test-references detector', () => {
+  let root: string;
+
+  function write(relPath: string, content: string) {
+    mkdirSync(dirname(join(root, relPath)), { recursive: true });
+    writeFileSync(join(root, relPath), content);
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'test-references-test-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('flags test files referencing the provider with what they fake, and skips app code', async () => {
+    write('auth/callback_test.go', GO_TEST);
+    write('src/profile.spec.ts', TS_SPEC);
+    write('src/profile.ts', "import { getSession } from '@auth0/nextjs-auth0';\n");
+    write('src/unrelated.test.ts', "it('adds', () => expect(1 + 1).toBe(2));\n");
+
+    const findings = await testReferences.detect(createScanContext(root));
+
+    expect(findings.map((f) => [f.file, f.line, f.provider, f.details?.mocks])).toEqual([
+      ['auth/callback_test.go', 12, 'auth0', ['discovery', 'token', 'server']],
+      ['src/profile.spec.ts', 2, 'auth0', ['module']],
+    ]);
+    expect(findings[1].message).toBe('Test fakes Auth0 (module)');
+  });
+
+  it('lists tests separately in the prompt instead of as usages to rewrite', () => {
+    const findings: MigrationFinding[] = [
+      {
+        provider: 'auth0',
+        code: 'auth0-sdk',
+        severity: 'info',
+        message: 'Auth0 SDK import',
+        file: 'src/app.ts',
+        confidence: 0.5,
+      },
+      {
+        provider: 'auth0',
+        code: 'auth0-sdk',
+        severity: 'info',
+        message: 'Auth0 SDK import',
+        file: 'src/__tests__/app.ts',
+        line: 3,
+        confidence: 0.5,
+      },
+      {
+        provider: 'auth0',
+        code: 'test-reference',
+        severity: 'warning',
+        message: 'Test fakes Auth0 (token)',
+        file: 'src/__tests__/app.ts',
+        line: 1,
+        confidence: 0.05,
+        details: { testFile: true, mocks: ['token'] },
+      },
+    ];
+
+    const references = extractTestReferences(findings);
+    expect(references).toEqual([{ file: 'src/__tests__/app.ts', line: 3, mocks: ['token'] }]);
+    expect(testReferenceLines(references)).toEqual(['src/__tests__/app.ts:3 (fakes tokens)']);
+
+    const prompt = buildMigrationPrompt({
+      provider: 'auth0',
+      displayName: 'Auth0',
+      forced: false,
+      confidence: 0.9,
+      envMapping: {},
+      guidance: [],
+      findings,
+      testReferences: references,
+    });
+    expect(prompt).toContain('### Tests referencing the old provider');
+    expect(prompt).toContain('- src/__tests__/app.ts:3 (fakes tokens)');
+    expect(prompt).toContain('- src/app.ts: Auth0 SDK import');
+    expect(prompt).not.toContain('- src/__tests__/app.ts: ');
+    expect(prompt).not.toContain('- src/__tests__/app.ts:3: ');
+  });
+});
@@ -0,0 +1,82 @@
+import { getProvider } from '../providers.js';
+import { findLines } from '../scan.js';
+import { TEST_FILE_PATTERN } from '../test-references.js';
+import type { MigrationFinding, ProviderDetector, ScanContext, TestMock } from '../types.js';
+
+/**
+ * Test files that reference another auth provider, with what they fake:
+ * OIDC discovery or JWKS responses, tokens, SDK modules or HTTP servers.
+ * These keep passing after the migration while asserting nothing real, so
+ * they're listed separately for the user to update (see test-references.ts);
+ * the migration doesn't rewrite them.
+ */
+
+const SOURCE_FILE_PATTERN = /\.(m?[jt]sx?|go|py|rb|php|java|kt|cs)$/;
+
+/** Provider names, SDK packages, domains and env vars as they show up in tests */
+const PROVIDER_MARKERS: Array<{ provider: string; pattern: RegExp }> = [
+  { provider: 'auth0', pattern: /auth0/i },
+  { provider: 'okta', pattern: /\bokta/i },
+  { provider: 'cognito', pattern: /cognito/i },
+  { provider: 'keycloak', pattern: /keycloak/i },
+  { provider: 'azure-ad', pattern: /login\.microsoftonline\.com|@azure\/msal|\bmsal\b|\bAZURE_AD_/i },
+  { provider: 'clerk', pattern: /@clerk\/|clerk\.(?:com|dev|accounts\.dev)\b|clerk-sdk-go|\bCLERK_[A-Z_]+/ },
+  { provider: 'supabase', pattern: /supabase/i },
+  { provider: 'socialite', pattern: /socialite/i },
+];
+
+const MOCK_PATTERNS: Record<TestMock, RegExp> = {
+  discovery: /\.well-known\/(?:openid-configuration|jwks\.json)|\/jwks\b/i,
+  token: /\bid_?token\b|\b(?:fake|mock)_?token\b|\bjwt\.(?:sign|encode)\(|\.SignedString\(/i,
+  module: /\b(?:vi|jest)\.mock\(|\bmock\.patch\(|\bsinon\.stub\(|\bMockito\.|\bShouldReceive\(/,
+  server: /\bhttptest\.NewServer\(|\bnock\(|\bsetupServer\(|\bWireMock|\bresponses\.add\(|\bHttp::fake\(/,
+};
+
+function testFinding(
+  file: string,
+  provider: string,
+  line: number,
+  evidence: string,
+  mocks: TestMock[],
+): MigrationFinding {
+  const name = getProvider(provider)?.displayName ?? provider;
+  return {
+    provider,
+    code: 'test-reference',
+    severity: 'warning',
+    message: mocks.length > 0 ? `Test fakes ${name} (${mocks.join(', ')})` : `Test references ${name}`,
+    file,
+    line,
+    evidence,
+    remediation: 'Update the test for AuthKit; the migration leaves tests unchanged',
+    // Tests alone say little about what the app runs on
+    confidence: 0.05,
+    details: { testFile: true, mocks },
+  };
+}
+
+async function detect(ctx: ScanContext): Promise<MigrationFinding[]> {
+  const files = (await ctx.files()).filter((f) => SOURCE_FILE_PATTERN.test(f) && TEST_FILE_PATTERN.test(f));
+  const findings: MigrationFinding[] = [];
+
+  for (const file of files) {
+    const content = await ctx.readFile(file);
+    if (!content) continue;
+    const mocks = (Object.keys(MOCK_PATTERNS) as TestMock[]).filter((mock) => MOCK_PATTERNS[mock].test(content));
+    // One finding per provider a test mentions, at its first mention
+    for (const { provider, pattern } of PROVIDER_MARKERS) {
+      const [first] = findLines(content, pattern);
+      if (first) findings.push(testFinding(file, provider, first.line, first.text, mocks));
+    }
+  }
+
+  return findings;
+}
+
+export const testReferences: ProviderDetector = {
+  name: 'test-references',
+  description: 'Test files that mock or reference the old provider (fake discovery, tokens, SDK modules)',
+  language: 'any',
+  files: TEST_FILE_PATTERN,
+  detect,
+};
@@ -0,0 +1,55 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { existsSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { renameProviderEnvKeys } from './env-rename.js';
+
+describe('env-rename', () => {
+  let root: string;
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'env-rename-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('renames keys in every root env file except excluded ones', () => {
+    writeFileSync(join(root, '.env'), 'AUTH0_CLIENT_SECRET=${SSM:/prod/auth0/secret}\n');
+    writeFileSync(join(root, '.env.example'), 'AUTH0_CLIENT_SECRET=\n');
+    writeFileSync(join(root, '.env.production'), 'AUTH0_CLIENT_SECRET=keep\n');
+    writeFileSync(join(root, 'env.txt'), 'AUTH0_CLIENT_SECRET=not-an-env-file\n');
+
+    const renamed = renameProviderEnvKeys(root, { AUTH0_CLIENT_SECRET: 'WORKOS_API_KEY' }, ['.env.production']);
+
+    expect(renamed).toEqual([
+      { file: '.env', from: 'AUTH0_CLIENT_SECRET', to: 'WORKOS_API_KEY', reference: true },
+      { file: '.env.example', from: 'AUTH0_CLIENT_SECRET', to: 'WORKOS_API_KEY', reference: false },
+    ]);
+    expect(readFileSync(join(root, '.env'), 'utf-8')).toBe('WORKOS_API_KEY=${SSM:/prod/auth0/secret}\n');
+    expect(readFileSync(join(root, '.env.production'), 'utf-8')).toBe('AUTH0_CLIENT_SECRET=keep\n');
+    expect(readFileSync(join(root, 'env.txt'), 'utf-8')).toBe('AUTH0_CLIENT_SECRET=not-an-env-file\n');
+  });
+
+  it('renames keys in the container config detection found and marks them', () => {
+    const compose = 'services:\n  web:\n    environment:\n      - AUTH0_CLIENT_ID=abc\n';
+    writeFileSync(join(root, 'docker-compose.yml'), compose);
+    writeFileSync(join(root, 'Dockerfile'), 'FROM node:20\nARG AUTH0_CLIENT_ID\n');
+
+    const renamed = renameProviderEnvKeys(root, { AUTH0_CLIENT_ID: 'WORKOS_CLIENT_ID' }, [], [
+      { file: 'docker-compose.yml', source: 'compose' },
+      { file: 'Dockerfile', source: 'dockerfile' },
+      { file: 'deleted.env', source: 'env_file' },
+    ]);
+
+    const rename = { from: 'AUTH0_CLIENT_ID', to: 'WORKOS_CLIENT_ID', reference: false, container: true };
+    expect(renamed).toEqual([
+      { file: 'docker-compose.yml', ...rename },
+      { file: 'Dockerfile', ...rename },
+    ]);
+    expect(readFileSync(join(root, 'docker-compose.yml'), 'utf-8')).toContain('      - WORKOS_CLIENT_ID=abc\n');
+    expect(readFileSync(join(root, 'Dockerfile'), 'utf-8')).toBe('FROM node:20\nARG WORKOS_CLIENT_ID\n');
+    expect(existsSync(join(root, 'deleted.env'))).toBe(false);
+  });
+});
@@ -0,0 +1,65 @@
+/**
+ * Renaming the old provider's env keys before the agent runs.
+ *
+ * Done here rather than left to the agent so values are carried over byte
+ * for byte: an env file full of `${SSM:/prod/auth0/secret}` references must
+ * come out with the same references under the new key names, not expanded,
+ * re-quoted or replaced with placeholders. Compose files, Dockerfiles and
+ * the env files compose loads are renamed the same way when detection found
+ * provider keys in them.
+ */
+
+import { existsSync, readdirSync } from 'node:fs';
+import { join } from 'node:path';
+import { applyFileEdits } from '../lib/atomic-write.js';
+import { renameEnvKeys } from '../utils/env-parser.js';
+import { renameComposeEnvKeys, renameDockerfileEnvKeys } from './container-env.js';
+import type { ContainerEnvFile, RenamedEnvKey } from './types.js';
+
+/** .env, .env.local, .env.production, .env.example, ... */
+export const ENV_FILE_PATTERN = /^\.env(\.[\w.-]+)?$/;
+
+function envFiles(installDir: string): string[] {
+  try {
+    return readdirSync(installDir).filter((name) => ENV_FILE_PATTERN.test(name)).sort();
+  } catch {
+    return [];
+  }
+}
+
+const RENAMERS: Record<ContainerEnvFile['source'], typeof renameEnvKeys> = {
+  compose: renameComposeEnvKeys,
+  dockerfile: renameDockerfileEnvKeys,
+  env_file: renameEnvKeys,
+};
+
+/**
+ * Rename the mapped keys in every env file at the project root and in the
+ * container config detection found, leaving out files the user excluded
+ * from the migration. All files are written together or not at all.
+ */
+export function renameProviderEnvKeys(
+  installDir: string,
+  envMapping: Record<string, string | null>,
+  excludedFiles: string[] = [],
+  containerFiles: ContainerEnvFile[] = [],
+): RenamedEnvKey[] {
+  const results: RenamedEnvKey[] = [];
+  const targets = [
+    ...envFiles(installDir).map((file) => ({ file, rename: renameEnvKeys, container: false })),
+    ...containerFiles.map(({ file, source }) => ({ file, rename: RENAMERS[source], container: true })),
+  ];
+  const edits = targets
+    .filter(({ file }) => !excludedFiles.includes(file) && existsSync(join(installDir, file)))
+    .map(({ file, rename, container }) => ({
+      path: join(installDir, file),
+      content: (current: string | null) => {
+        if (current === null) return '';
+        const { content, renamed } = rename(current, envMapping);
+        results.push(...renamed.map((r) => ({ file, ...r, ...(container && { container }) })));
+        return content;
+      },
+    }));
+  applyFileEdits(edits);
+  return results;
+}
@@ -0,0 +1,44 @@
+import { describe, it, expect } from 'vitest';
+import { evidenceLines, strongestEvidence } from './evidence.js';
+import type { MigrationFinding } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+function finding(
+  file: string,
+  line: number,
+  confidence: number,
+  extra: Partial<MigrationFinding> = {},
+): MigrationFinding {
+  return makeFinding({ code: 'auth0-sdk', message: `found in ${file}`, file, line, confidence, ...extra });
+}
+
+describe('strongestEvidence', () => {
+  it('keeps the most confident finding per location, leaving out tests and out-of-scope usages', () => {
+    const evidence = strongestEvidence([
+      finding('src/a.ts', 1, 0.3),
+      finding('src/b.ts', 2, 0.9),
+      finding('src/b.ts', 2, 0.5),
+      finding('src/a.spec.ts', 1, 0.95),
+      finding('src/mgmt.ts', 4, 0.8, { outOfScope: true }),
+      finding('src/c.ts', 3, 0.6),
+    ]);
+
+    expect(evidenceLines(evidence)).toEqual([
+      'src/b.ts:2 found in src/b.ts',
+      'src/c.ts:3 found in src/c.ts',
+      'src/a.ts:1 found in src/a.ts',
+    ]);
+  });
+
+  it('makes room for the strongest env var', () => {
+    const evidence = strongestEvidence([
+      finding('src/a.ts', 1, 0.9),
+      finding('src/b.ts', 1, 0.8),
+      finding('src/c.ts', 1, 0.7),
+      finding('.env', 2, 0.1, { details: { envVar: 'AUTH0_SECRET', workosEnv: 'WORKOS_COOKIE_PASSWORD' } }),
+    ]);
+
+    expect(evidence.map((e) => e.file)).toEqual(['src/a.ts', 'src/b.ts', '.env']);
+    expect(evidence[2]).toMatchObject({ envVar: 'AUTH0_SECRET', line: 2, confidence: 0.1 });
+  });
+});
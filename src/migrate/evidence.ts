@@ -0,0 +1,56 @@
+/**
+ * Why a provider was detected: its strongest findings, so the confirmation
+ * prompt, `workos detect --json` and the run summary can show them instead
+ * of a bare confidence score.
+ */
+
+import { isTestFile } from './test-references.js';
+import type { DetectionEvidence, MigrationFinding } from './types.js';
+
+/** Enough to review at a glance; the full list is in `workos detect` */
+export const EVIDENCE_LIMIT = 3;
+
+function hasEnvVar(finding: MigrationFinding): boolean {
+  return typeof finding.details?.envVar === 'string';
+}
+
+/**
+ * The most confident findings, one per location. Tests and usages outside
+ * the migration say little about what the app runs on and are left out. An
+ * env var is the quickest thing to check, so the strongest one makes the
+ * cut when any was found.
+ */
+export function strongestEvidence(findings: MigrationFinding[], limit = EVIDENCE_LIMIT): DetectionEvidence[] {
+  const ranked = findings
+    .filter((f) => !f.outOfScope && !isTestFile(f.file))
+    .sort((a, b) => b.confidence - a.confidence);
+
+  const picked: MigrationFinding[] = [];
+  const locations = new Set<string>();
+  for (const finding of ranked) {
+    const location = `${finding.file}:${finding.line ?? ''}`;
+    if (locations.has(location)) continue;
+    locations.add(location);
+    picked.push(finding);
+    if (picked.length === limit) break;
+  }
+
+  const envVar = ranked.find(hasEnvVar);
+  if (envVar && !picked.some(hasEnvVar)) {
+    if (picked.length === limit) picked.pop();
+    picked.push(envVar);
+  }
+
+  return picked.map((f) => ({
+    file: f.file,
+    ...(f.line && { line: f.line }),
+    message: f.message,
+    ...(hasEnvVar(f) && { envVar: f.details!.envVar as string }),
+    confidence: f.confidence,
+  }));
+}
+
+/** e.g. "src/auth.ts:12 Auth0 SDK import" */
+export function evidenceLines(evidence: DetectionEvidence[]): string[] {
+  return evidence.map(({ file, line, message }) => `${line ? `${file}:${line}` : file} ${message}`);
+}
@@ -0,0 +1,17 @@
+import type { MigrationFinding } from './types.js';
+
+/**
+ * Creates a finding for tests. Every required field has a default, so a
+ * test only sets the fields it asserts on.
+ */
+export function makeFinding(overrides: Partial<MigrationFinding> = {}): MigrationFinding {
+  return {
+    provider: 'auth0',
+    code: 'test',
+    severity: 'info',
+    message: 'usage',
+    file: 'src/auth.ts',
+    confidence: 0.9,
+    ...overrides,
+  };
+}
@@ -0,0 +1,360 @@
+import { describe, it, expect } from 'vitest';
+import { mkdtempSync, readFileSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { applyFileEdits } from '../lib/atomic-write.js';
+import { normalizeLineEndings } from '../utils/line-endings.js';
+import {
+  authRouteRole,
+  ensureGoImports,
+  findSessionFollowUps,
+  parseGinRoutes,
+  parseHandlerTemplate,
+  rewriteGinAuthRoutes,
+} from './gin-routes.js';
+import { sessionHelperSource } from './gin-sessions.js';
+
+const AUTH0_GIN_MAIN = readFileSync(new URL('../../tests/fixtures/go/example-auth0/main.go', import.meta.url), 'utf-8');
+const BASE_URL_GIN_MAIN = readFileSync(
+  new URL('../../tests/fixtures/go/example-auth0-base-url/main.go', import.meta.url),
+  'utf-8',
+);
+
+const GROUPED = `package main
+
+import "github.com/gin-gonic/gin"
+
+func handleLogout(ctx *gin.Context) {
+	ctx.SetCookie("session", "", -1, "/", "", false, true)
+}
+
+func main() {
+	r := gin.New()
+	r.Use(gin.Logger())
+	auth := r.Group("/auth", cors.Default())
+	{
+		auth.GET("/callback", rateLimit(10), func(c *gin.Context) {
+			// "}" in a comment
+			c.String(200, "}")
+		})
+		auth.Handle("GET", "/logout", handleLogout)
+	}
+	r.Use(requestID())
+}
+`;
+
+describe('gin-routes', () => {
+  describe('parseGinRoutes', () => {
+    it('finds the routes in the Auth0 fixture', () => {
+      const routes = parseGinRoutes(AUTH0_GIN_MAIN);
+
+      expect(routes.map((r) => `${r.method} ${r.fullPath}`)).toEqual([
+        'GET /',
+        'GET /api/health',
+        'GET /login',
+        'GET /callback',
+        'GET /logout',
+      ]);
+      expect(routes[3]).toMatchObject({ router: 'r', groups: [], middleware: [], line: 53 });
+    });
+
+    it('resolves router groups and middleware', () => {
+      const [callback, logout] = parseGinRoutes(GROUPED);
+
+      expect(callback).toMatchObject({
+        path: '/callback',
+        fullPath: '/auth/callback',
+        router: 'auth',
+        groups: ['auth'],
+        middleware: ['rateLimit(10)'],
+        // requestID() is registered after the route, so it doesn't apply
+        inheritedMiddleware: ['gin.Logger()', 'cors.Default()'],
+      });
+      expect(logout).toMatchObject({ method: 'GET', fullPath: '/auth/logout', handler: 'handleLogout' });
+    });
+  });
+
+  it('classifies auth routes by their last path segment', () => {
+    expect(authRouteRole('/auth/login')).toBe('login');
+    expect(authRouteRole('/callback')).toBe('callback');
+    expect(authRouteRole('/sign-out')).toBe('logout');
+    expect(authRouteRole('/api/login-history')).toBeNull();
+  });
+
+  describe('rewriteGinAuthRoutes', () => {
+    it('migrates the fixture callback without losing its registration or neighbours', () => {
+      const { source, rewritten, skipped } = rewriteGinAuthRoutes(AUTH0_GIN_MAIN);
+
+      expect(rewritten.map((r) => r.role)).toEqual(['login', 'callback', 'logout']);
+      expect(skipped).toEqual([]);
+      expect(source).toContain('\tr.GET("/callback", func(c *gin.Context) {\n\t\tif !validAuthkitState(c.Request) {');
+      // The constant "state" becomes a random one the callback checks
+      expect(source).toContain('\t\t\tState:       state,');
+      expect(source).toContain('\t\tresponse, err := usermanagement.AuthenticateWithCode(');
+      // The plain claims cookie becomes a sealed session; the old one is cleared
+      expect(source).toContain('sealed, err := sealAuthkitSession(authkitSession{');
+      expect(source).toContain(
+        'c.SetCookie(authkitSessionCookie, sealed, authkitSessionMaxAge, "/", "", c.Request.TLS != nil, true)',
+      );
+      expect(source).toContain('c.SetCookie("user", "", -1, "/", "", false, true)');
+      expect(source).not.toContain('json.Marshal');
+      expect(source).not.toContain('oauth2Config.Exchange');
+      expect(source).not.toContain('oauth2Config.AuthCodeURL');
+      // Untouched routes and setup stay byte-for-byte
+      expect(source).toContain('r.GET("/api/health", func(c *gin.Context) {\n\t\tc.JSON(http.StatusOK');
+      expect(source).toContain('r.Run(":3000")');
+      expect(source).toContain('\t"github.com/workos/workos-go/v4/pkg/usermanagement"\n)');
+    });
+
+    it('keeps a non-root callback and builds the redirect URI the way the old config did', () => {
+      const redirect = { envVar: 'APP_BASE_URL', path: '/account/auth/callback' };
+      const { source, rewritten } = rewriteGinAuthRoutes(BASE_URL_GIN_MAIN, { redirect });
+
+      expect(rewritten.map((r) => [r.role, r.route.fullPath])).toEqual([
+        ['login', '/account/auth/login'],
+        ['callback', '/account/auth/callback'],
+      ]);
+      expect(source).toContain(
+        '\t\tauth.GET("/callback", func(c *gin.Context) {\n\t\t\tif !validAuthkitState(c.Request) {',
+      );
+      expect(source).toContain('\tRedirectURI: os.Getenv("APP_BASE_URL") + "/account/auth/callback",');
+      expect(source).not.toContain('WORKOS_REDIRECT_URI');
+
+      const literal = rewriteGinAuthRoutes(AUTH0_GIN_MAIN, { redirect: { url: 'http://localhost:3000/callback' } });
+      expect(literal.source).toContain('\tRedirectURI: "http://localhost:3000/callback",');
+      expect(rewriteGinAuthRoutes(AUTH0_GIN_MAIN).source).toContain('\tRedirectURI: os.Getenv("WORKOS_REDIRECT_URI"),');
+
+      const login = '{{ctx}}.Redirect(http.StatusFound, loginURL({{redirectUri}}))';
+      const templated = rewriteGinAuthRoutes(BASE_URL_GIN_MAIN, { redirect, handlerTemplates: { login } });
+      expect(templated.source).toContain(
+        'c.Redirect(http.StatusFound, loginURL(os.Getenv("APP_BASE_URL") + "/account/auth/callback"))',
+      );
+    });
+
+    it('keeps CRLF line endings when the rewrite is written back', () => {
+      const dir = mkdtempSync(join(tmpdir(), 'gin-crlf-'));
+      const path = join(dir, 'main.go');
+      try {
+        writeFileSync(path, normalizeLineEndings(AUTH0_GIN_MAIN, '\r\n'));
+        const { rewritten } = rewriteGinAuthRoutes(normalizeLineEndings(readFileSync(path, 'utf-8')));
+
+        applyFileEdits([{ path, content: (current) => rewriteGinAuthRoutes(current!).source }]);
+
+        const written = readFileSync(path, 'utf-8');
+        expect(rewritten).toHaveLength(3);
+        expect(written).toContain('usermanagement');
+        expect(written.match(/(?<!\r)\n/g)).toBeNull();
+      } finally {
+        rmSync(dir, { recursive: true, force: true });
+      }
+    });
+
+    it('keeps route middleware and groups and rewrites named handlers in place', () => {
+      const { source, rewritten } = rewriteGinAuthRoutes(GROUPED);
+
+      expect(rewritten.map((r) => `${r.role} ${r.route.fullPath}`)).toEqual([
+        'callback /auth/callback',
+        'logout /auth/logout',
+      ]);
+      expect(source).toContain('auth := r.Group("/auth", cors.Default())');
+      expect(source).toContain(
+        'auth.GET("/callback", rateLimit(10), func(c *gin.Context) {\n\t\t\tif !validAuthkitState(c.Request) {',
+      );
+      expect(source).toContain('auth.Handle("GET", "/logout", handleLogout)');
+      expect(source).toContain('\tctx.Redirect(http.StatusTemporaryRedirect, "/")\n}');
+      // The old cookie is cleared alongside the sealed session
+      expect(source).toContain('\tctx.SetCookie(authkitSessionCookie, "", -1, "/", "", ctx.Request.TLS != nil, true)');
+      expect(source).toContain('\tctx.SetCookie("session", "", -1, "/", "", false, true)');
+      expect(source).not.toContain('"}" in a comment');
+    });
+
+    it('uses project templates and their imports for the roles they cover', () => {
+      const callback = [
+        'import (',
+        '\t"log/slog"',
+        '\t"net/http"',
+        ')',
+        '',
+        'slog.Info("authkit callback")',
+        '{{clearLegacyCookie}}',
+        '{{ctx}}.Redirect(http.StatusFound, "/app")',
+      ].join('\n');
+      const { source } = rewriteGinAuthRoutes(GROUPED, { handlerTemplates: { callback } });
+
+      expect(source).toContain(
+        'func(c *gin.Context) {\n\t\t\tslog.Info("authkit callback")\n' +
+          '\t\t\tc.SetCookie("session", "", -1, "/", "", false, true)\n' +
+          '\t\t\tc.Redirect(http.StatusFound, "/app")\n\t\t})',
+      );
+      expect(source).toContain('\t"log/slog"');
+      // The logout has no template and keeps the built-in body
+      expect(source).toContain('\tctx.SetCookie(authkitSessionCookie, "", -1, "/", "", ctx.Request.TLS != nil, true)');
+    });
+
+    it('ends the AuthKit session on logout and returns where the old logout did', () => {
+      const federated = `package main
+
+func main() {
+	r.GET("/logout", func(c *gin.Context) {
+		c.SetCookie("user", "", -1, "/", "", false, true)
+		u := "https://" + os.Getenv("AUTH0_DOMAIN") + "/v2/logout?returnTo=" + url.QueryEscape("http://localhost:3000/bye")
+		c.Redirect(http.StatusTemporaryRedirect, u)
+	})
+}
+`;
+      const { source } = rewriteGinAuthRoutes(federated);
+
+      expect(source).toContain(
+        [
+          '\t\tsession, _ := authkitSessionFromRequest(c.Request)',
+          '\t\tc.SetCookie(authkitSessionCookie, "", -1, "/", "", c.Request.TLS != nil, true)',
+          '\t\tc.SetCookie("user", "", -1, "/", "", false, true)',
+          '\t\tif sessionID := session.sessionID(); sessionID != "" {',
+          '\t\t\tlogoutURL, err := usermanagement.GetLogoutURL(usermanagement.GetLogoutURLOpts{',
+          '\t\t\t\tSessionID: sessionID,',
+          '\t\t\t\tReturnTo:  "http://localhost:3000/bye",',
+          '\t\t\t})',
+          '\t\t\tif err == nil {',
+          '\t\t\t\tc.Redirect(http.StatusTemporaryRedirect, logoutURL.String())',
+          '\t\t\t\treturn',
+          '\t\t\t}',
+          '\t\t}',
+          '\t\tc.Redirect(http.StatusTemporaryRedirect, "http://localhost:3000/bye")',
+          '\t})',
+        ].join('\n'),
+      );
+      expect(source).toContain('\t"github.com/workos/workos-go/v4/pkg/usermanagement"');
+      expect(source).not.toContain('/v2/logout');
+
+      // A relative target can't be a logout return URL; WorkOS uses the app's default
+      const local = rewriteGinAuthRoutes(AUTH0_GIN_MAIN).source;
+      expect(local).toContain('\t\t\t\tSessionID: sessionID,\n\t\t\t})');
+      expect(local).toContain('\t\tc.Redirect(http.StatusTemporaryRedirect, "/")\n\t})');
+
+      const logout = '{{clearLegacyCookie}}\n{{ctx}}.Redirect(http.StatusFound, {{returnTo}})';
+      expect(rewriteGinAuthRoutes(federated, { handlerTemplates: { logout } }).source).toContain(
+        '\t\tc.Redirect(http.StatusFound, "http://localhost:3000/bye")\n\t})',
+      );
+    });
+
+    it('reports handlers it cannot rewrite', () => {
+      const source = `package main
+
+func main() {
+	r.GET("/login", handlers.Login)
+}
+`;
+      const result = rewriteGinAuthRoutes(source);
+
+      expect(result.source).toBe(source);
+      expect(result.skipped).toEqual([
+        expect.objectContaining({ reason: 'handler handlers.Login is not a function declared in this file' }),
+      ]);
+    });
+  });
+
+  describe('session migration', () => {
+    it('needs the session helper once the callback or logout is rewritten', () => {
+      const fixture = rewriteGinAuthRoutes(AUTH0_GIN_MAIN);
+
+      expect(fixture.needsSessionHelper).toBe(true);
+      expect(fixture.legacyCookie).toBe('user');
+      expect(fixture.sessionFollowUps).toEqual([]);
+
+      const loginOnly = rewriteGinAuthRoutes(`package main
+
+func main() {
+	r.GET("/login", func(c *gin.Context) {
+		c.Redirect(302, "/authorize")
+	})
+}
+`);
+      expect(loginOnly.needsSessionHelper).toBe(false);
+      expect(loginOnly.legacyCookie).toBeNull();
+    });
+
+    it('flags other code that still uses the old cookie or a session library', () => {
+      const source = `package main
+
+import (
+	"net/http"
+
+	"github.com/gin-contrib/sessions"
+)
+
+func requireUser(c *gin.Context) {
+	raw, err := c.Cookie("user")
+	// c.Cookie("user") in a comment
+	_ = raw
+}
+
+func remember(w http.ResponseWriter, claims string) {
+	http.SetCookie(w, &http.Cookie{Name: "user", Value: claims})
+}
+
+func refresh(c *gin.Context) {
+	c.SetCookie("user", "{}", 3600, "/", "", false, true)
+	c.SetCookie("user", "", -1, "/", "", false, true)
+}
+`;
+
+      expect(findSessionFollowUps(source, 'user')).toEqual([
+        { line: 6, reason: expect.stringContaining('uses github.com/gin-contrib/sessions') },
+        { line: 10, reason: expect.stringContaining('reads the old "user" cookie') },
+        { line: 16, reason: expect.stringContaining('builds an http.Cookie named "user"') },
+        { line: 20, reason: expect.stringContaining('still sets the old "user" cookie') },
+      ]);
+    });
+
+    it('writes the helper into the handlers package', () => {
+      const helper = sessionHelperSource('server');
+
+      expect(helper.startsWith('package server\n')).toBe(true);
+      expect(helper).toContain('authkitSessionCookie = "wos-session"');
+      expect(helper).toContain('func sealAuthkitSession(session authkitSession) (string, error)');
+      expect(helper).toContain('func authkitSessionFromRequest(r *http.Request) (*authkitSession, error)');
+      expect(helper).toContain('authkitStateCookie   = "wos-oauth-state"');
+      expect(helper).toContain('func validAuthkitState(r *http.Request) bool {');
+      expect(helper).not.toContain('refreshAuthkitSession');
+    });
+
+    it('adds session refresh for apps that refreshed tokens', () => {
+      const helper = sessionHelperSource('server', undefined, { refresh: true });
+
+      expect(helper).toContain('\t"strings"\n\t"time"\n');
+      expect(helper).toContain('func (s *authkitSession) accessTokenExpired() bool {');
+      expect(helper).toContain(
+        'func refreshAuthkitSession(w http.ResponseWriter, r *http.Request, session *authkitSession)',
+      );
+      expect(helper).toContain('usermanagement.AuthenticateWithRefreshToken(');
+      expect(sessionHelperSource('server', 'const x = 1\n', { refresh: true })).toBe('package server\n\nconst x = 1\n');
+    });
+
+    it('writes a project template after the package clause', () => {
+      const helper = sessionHelperSource('server', 'const authkitSessionCookie = "{{sessionCookie}}"\n');
+
+      expect(helper).toBe('package server\n\nconst authkitSessionCookie = "wos-session"\n');
+    });
+  });
+
+  describe('parseHandlerTemplate', () => {
+    it('splits off the import declaration and dedents the body', () => {
+      expect(parseHandlerTemplate('import "net/http"\n\n\t{{ctx}}.Status(http.StatusOK)\n')).toEqual({
+        imports: ['net/http'],
+        body: '{{ctx}}.Status(http.StatusOK)',
+      });
+      expect(parseHandlerTemplate('{{ctx}}.Status(204)')).toEqual({ imports: [], body: '{{ctx}}.Status(204)' });
+    });
+  });
+
+  describe('ensureGoImports', () => {
+    it('adds missing imports to a block or converts a single import', () => {
+      expect(ensureGoImports('package main\n\nimport (\n\t"os"\n)\n', ['os', 'net/http'])).toBe(
+        'package main\n\nimport (\n\t"os"\n\t"net/http"\n)\n',
+      );
+      expect(ensureGoImports('package main\n\nimport "fmt"\n', ['os'])).toBe(
+        'package main\n\nimport (\n\t"fmt"\n\t"os"\n)\n',
+      );
+    });
+  });
+});
@@ -0,0 +1,591 @@
+/**
+ * Gin route rewriting for migrations.
+ *
+ * Auth routes (`/login`, `/callback`, `/logout`) are found by parsing the
+ * route registrations, including router groups and middleware arguments.
+ * Only the handler body is replaced with the AuthKit flow, so the
+ * registration line, its middleware (logging, CORS, ...) and the group it
+ * belongs to stay exactly as they were.
+ *
+ * The hand-rolled session cookie the old handlers set is replaced with a
+ * sealed AuthKit session (see gin-sessions.ts). Other code that still reads
+ * or writes the old cookie is reported for manual follow-up rather than
+ * guessed at.
+ *
+ * Logout ends the AuthKit session through its logout URL and returns the
+ * user where the old handler did, whether that was a local redirect or the
+ * `returnTo` handed to the old provider's logout endpoint (see logout.ts).
+ *
+ * Login sends users back to the callback URL the old oauth2.Config used,
+ * built the same way (a literal, an env var, or a base-URL env var plus a
+ * path), so apps with a non-root callback keep working without re-routing.
+ * It passes a random state, kept in a cookie until the callback checks it,
+ * whatever state the old handler used (often the constant `"state"`).
+ *
+ * Projects can replace the handler bodies with their own templates (see
+ * templates.ts), e.g. to match their error handling and logging.
+ */
+
+import { SESSION_COOKIE } from './gin-sessions.js';
+import { findReturnTo, type ReturnTo } from './logout.js';
+import { renderTemplate } from './templates.js';
+import type { RedirectSource } from './types.js';
+
+export type AuthRouteRole = 'login' | 'callback' | 'logout';
+
+export interface GinRoute {
+  method: string;
+  /** Path as passed to the registration call */
+  path: string;
+  /** Path including router group prefixes */
+  fullPath: string;
+  /** Router or group variable the route is registered on */
+  router: string;
+  /** Group variables between the root router and this route, outermost first */
+  groups: string[];
+  /** Middleware arguments of the registration itself, in order */
+  middleware: string[];
+  /** Middleware inherited from enclosing groups and earlier Use() calls */
+  inheritedMiddleware: string[];
+  /** Source text of the handler argument */
+  handler: string;
+  /** 1-based line of the registration */
+  line: number;
+  /** Offset of the handler argument */
+  handlerStart: number;
+}
+
+export interface SessionFollowUp {
+  /** 1-based line */
+  line: number;
+  reason: string;
+}
+
+export interface GinRewriteResult {
+  source: string;
+  rewritten: Array<{ route: GinRoute; role: AuthRouteRole }>;
+  skipped: Array<{ route: GinRoute; reason: string }>;
+  /** True when a rewritten handler uses the session helper (callback or logout) */
+  needsSessionHelper: boolean;
+  /** Cookie the old handlers kept the session in, now cleared on login and logout */
+  legacyCookie: string | null;
+  /** Session handling outside the rewritten handlers that has to be migrated by hand */
+  sessionFollowUps: SessionFollowUp[];
+}
+
+const ROUTE_METHODS = 'GET|POST|PUT|PATCH|DELETE|HEAD|OPTIONS|Any|Handle';
+const IDENT = '[A-Za-z_]\\w*';
+const CLOSERS: Record<string, string> = { '(': ')', '[': ']', '{': '}' };
+const USERMANAGEMENT_IMPORT = 'github.com/workos/workos-go/v4/pkg/usermanagement';
+const AUTHENTICATE_OPTS = 'usermanagement.AuthenticateWithCodeOpts';
+
+/** End of the string, rune or comment starting at `i`, or -1 if there is none */
+function literalEnd(src: string, i: number): number {
+  const ch = src[i];
+  if (ch === '/' && src[i + 1] === '/') {
+    const newline = src.indexOf('\n', i);
+    return newline === -1 ? src.length : newline;
+  }
+  if (ch === '/' && src[i + 1] === '*') {
+    const end = src.indexOf('*/', i + 2);
+    return end === -1 ? src.length : end + 2;
+  }
+  if (ch === '`') {
+    const end = src.indexOf('`', i + 1);
+    return end === -1 ? src.length : end + 1;
+  }
+  if (ch === '"' || ch === "'") {
+    for (let j = i + 1; j < src.length; j++) {
+      if (src[j] === '\\') j++;
+      else if (src[j] === ch || src[j] === '\n') return j + 1;
+    }
+    return src.length;
+  }
+  return -1;
+}
+
+/**
+ * Blank out comments and string contents (keeping quotes, newlines and
+ * offsets) so brackets and calls can be matched without a Go parser.
+ */
+export function maskLiterals(src: string): string {
+  let out = '';
+  for (let i = 0; i < src.length; ) {
+    const end = literalEnd(src, i);
+    if (end === -1) {
+      out += src[i++];
+      continue;
+    }
+    const chunk = src.slice(i, end);
+    const blank = (s: string) => s.replace(/[^\n]/g, ' ');
+    if (src[i] === '/') out += blank(chunk);
+    else out += chunk.length < 2 ? chunk : chunk[0] + blank(chunk.slice(1, -1)) + chunk.slice(-1);
+    i = end;
+  }
+  return out;
+}
+
+/** Index of the bracket closing the one at `open` (in masked source), or -1 */
+export function matchingBracket(masked: string, open: number): number {
+  const stack: string[] = [];
+  for (let i = open; i < masked.length; i++) {
+    const ch = masked[i];
+    if (CLOSERS[ch]) {
+      stack.push(CLOSERS[ch]);
+    } else if (ch === ')' || ch === ']' || ch === '}') {
+      if (stack.pop() !== ch) return -1;
+      if (stack.length === 0) return i;
+    }
+  }
+  return -1;
+}
+
+interface Arg {
+  start: number;
+  text: string;
+}
+
+/** Top-level arguments between the parens at `open` and `close` */
+function splitArgs(src: string, masked: string, open: number, close: number): Arg[] {
+  const args: Arg[] = [];
+  let start = open + 1;
+  let depth = 0;
+  for (let i = open + 1; i <= close; i++) {
+    const ch = masked[i];
+    if (CLOSERS[ch]) {
+      depth++;
+    } else if (i < close && (ch === ')' || ch === ']' || ch === '}')) {
+      depth--;
+    } else if ((ch === ',' && depth === 0) || i === close) {
+      const raw = src.slice(start, i);
+      const text = raw.trim();
+      if (text) args.push({ start: start + raw.indexOf(text), text });
+      start = i + 1;
+    }
+  }
+  return args;
+}
+
+function stringValue(text: string): string | null {
+  const quoted = /^"((?:[^"\\\n]|\\.)*)"$/.exec(text) ?? /^`([^`]*)`$/.exec(text);
+  return quoted ? quoted[1] : null;
+}
+
+function joinPaths(...parts: string[]): string {
+  const joined = parts.join('/').replace(/\/+/g, '/');
+  return joined.length > 1 ? joined.replace(/\/$/, '') || '/' : '/';
+}
+
+export function lineAt(src: string, index: number): number {
+  return src.slice(0, index).split('\n').length;
+}
+
+interface Call {
+  receiver: string;
+  name: string;
+  index: number;
+  args: Arg[];
+}
+
+function findCalls(src: string, masked: string, pattern: RegExp): Call[] {
+  const calls: Call[] = [];
+  for (const match of masked.matchAll(new RegExp(pattern.source, 'g'))) {
+    const open = match.index + match[0].length - 1;
+    const close = matchingBracket(masked, open);
+    if (close === -1) continue;
+    calls.push({
+      receiver: match[1],
+      name: match[2],
+      index: match.index,
+      args: splitArgs(src, masked, open, close),
+    });
+  }
+  return calls;
+}
+
+/**
+ * Every Gin route registration in a Go source file, with its group chain
+ * and middleware resolved.
+ */
+export function parseGinRoutes(source: string): GinRoute[] {
+  const masked = maskLiterals(source);
+
+  const groups = new Map<string, { parent: string; prefix: string; middleware: string[] }>();
+  for (const match of masked.matchAll(new RegExp(`\\b(${IDENT})\\s*:?=\\s*(${IDENT})\\.Group\\(`, 'g'))) {
+    const open = match.index + match[0].length - 1;
+    const close = matchingBracket(masked, open);
+    if (close === -1) continue;
+    const [prefixArg, ...middleware] = splitArgs(source, masked, open, close);
+    groups.set(match[1], {
+      parent: match[2],
+      prefix: stringValue(prefixArg?.text ?? '') ?? '',
+      middleware: middleware.map((arg) => arg.text),
+    });
+  }
+
+  const uses = findCalls(source, masked, new RegExp(`\\b(${IDENT})\\.(Use)\\(`));
+
+  const routes: GinRoute[] = [];
+  for (const call of findCalls(source, masked, new RegExp(`\\b(${IDENT})\\.(${ROUTE_METHODS})\\(`))) {
+    const args = [...call.args];
+    const method = call.name === 'Handle' ? stringValue(args.shift()?.text ?? '') : call.name;
+    const path = stringValue(args.shift()?.text ?? '');
+    const handler = args.pop();
+    if (!method || path === null || !handler) continue;
+
+    // Walk up the group chain, guarding against reassigned variables
+    const chain: string[] = [call.receiver];
+    while (groups.has(chain[0]) && !chain.includes(groups.get(chain[0])!.parent)) {
+      chain.unshift(groups.get(chain[0])!.parent);
+    }
+
+    const prefixes: string[] = [];
+    const inherited: string[] = [];
+    for (const name of chain) {
+      const group = groups.get(name);
+      if (group && name !== chain[0]) {
+        prefixes.push(group.prefix);
+        inherited.push(...group.middleware);
+      }
+      for (const use of uses) {
+        if (use.receiver === name && use.index < call.index) inherited.push(...use.args.map((arg) => arg.text));
+      }
+    }
+
+    routes.push({
+      method,
+      path,
+      fullPath: joinPaths(...prefixes, path),
+      router: call.receiver,
+      groups: chain.slice(1),
+      middleware: args.map((arg) => arg.text),
+      inheritedMiddleware: inherited,
+      handler: handler.text,
+      line: lineAt(source, call.index),
+      handlerStart: handler.start,
+    });
+  }
+  return routes;
+}
+
+/**
+ * Which part of the auth flow a route path handles, if any.
+ */
+export function authRouteRole(path: string): AuthRouteRole | null {
+  const segment = path.split('/').filter(Boolean).pop()?.toLowerCase() ?? '';
+  if (/^(login|signin|sign-in|sign_in)$/.test(segment)) return 'login';
+  if (/^(callback|oauth-callback|oauth_callback)$/.test(segment)) return 'callback';
+  if (/^(logout|signout|sign-out|sign_out)$/.test(segment)) return 'logout';
+  return null;
+}
+
+interface HandlerBody {
+  /** Offsets of the body's braces */
+  open: number;
+  close: number;
+  contextName: string;
+}
+
+function locateHandlerBody(source: string, masked: string, route: GinRoute): HandlerBody | string {
+  const literal = /^func\s*\(\s*(\w+)\s+\*gin\.Context\s*\)\s*\{/.exec(masked.slice(route.handlerStart));
+  const named = new RegExp(`^${IDENT}$`).test(route.handler)
+    ? new RegExp(`\\bfunc\\s+${route.handler}\\s*\\(\\s*(\\w+)\\s+\\*gin\\.Context\\s*\\)\\s*\\{`).exec(masked)
+    : null;
+
+  const match = literal ?? named;
+  if (!match) {
+    return route.handler.startsWith('func')
+      ? 'handler does not take a *gin.Context'
+      : `handler ${route.handler} is not a function declared in this file`;
+  }
+  if (match[1] === '_') return 'handler ignores its *gin.Context';
+
+  const open = (literal ? route.handlerStart : match.index) + match[0].length - 1;
+  const close = matchingBracket(masked, open);
+  if (close === -1) return 'handler body is not closed';
+  return { open, close, contextName: match[1] };
+}
+
+/** Go expression for where a logout returns to; "/" when the old handler's target is unknown */
+function goReturnTo(returnTo: ReturnTo | undefined): string {
+  if (returnTo?.envVar) return `os.Getenv("${returnTo.envVar}")`;
+  return JSON.stringify(returnTo?.url ?? '/');
+}
+
+/** Go expression for the login's redirect URI; WORKOS_REDIRECT_URI when the old one couldn't be read */
+function goRedirectURI(redirect: RedirectSource | undefined): string {
+  if (redirect?.envVar) {
+    const env = `os.Getenv("${redirect.envVar}")`;
+    return redirect.path ? `${env} + ${JSON.stringify(redirect.path)}` : env;
+  }
+  return redirect?.url ? JSON.stringify(redirect.url) : 'os.Getenv("WORKOS_REDIRECT_URI")';
+}
+
+interface BodyValues {
+  legacyCookie: string | null;
+  returnTo?: ReturnTo;
+  redirect?: RedirectSource;
+}
+
+function authKitBody(role: AuthRouteRole, c: string, { legacyCookie, returnTo, redirect }: BodyValues): string[] {
+  // Drop the old plain cookie so browsers don't keep sending readable claims
+  const clearLegacy = legacyCookie ? [`${c}.SetCookie("${legacyCookie}", "", -1, "/", "", false, true)`] : [];
+  switch (role) {
+    case 'login':
+      return [
+        'state, err := newAuthkitState()',
+        'if err != nil {',
+        `\t${c}.String(http.StatusInternalServerError, "Failed to generate state: "+err.Error())`,
+        '\treturn',
+        '}',
+        `${c}.SetSameSite(http.SameSiteLaxMode)`,
+        `${c}.SetCookie(authkitStateCookie, state, authkitStateMaxAge, "/", "", ${c}.Request.TLS != nil, true)`,
+        '',
+        'authorizationURL, err := usermanagement.GetAuthorizationURL(usermanagement.GetAuthorizationURLOpts{',
+        '\tClientID:    os.Getenv("WORKOS_CLIENT_ID"),',
+        `\tRedirectURI: ${goRedirectURI(redirect)},`,
+        '\tProvider:    "authkit",',
+        '\tState:       state,',
+        '})',
+        'if err != nil {',
+        `\t${c}.String(http.StatusInternalServerError, "Failed to build authorization URL: "+err.Error())`,
+        '\treturn',
+        '}',
+        `${c}.Redirect(http.StatusTemporaryRedirect, authorizationURL.String())`,
+      ];
+    case 'callback':
+      return [
+        // The state the login set, so a callback started elsewhere is refused
+        `if !validAuthkitState(${c}.Request) {`,
+        `\t${c}.String(http.StatusBadRequest, "Invalid OAuth state")`,
+        '\treturn',
+        '}',
+        `${c}.SetCookie(authkitStateCookie, "", -1, "/", "", ${c}.Request.TLS != nil, true)`,
+        '',
+        `response, err := usermanagement.AuthenticateWithCode(${c}.Request.Context(), ${AUTHENTICATE_OPTS}{`,
+        '\tClientID: os.Getenv("WORKOS_CLIENT_ID"),',
+        `\tCode:     ${c}.Query("code"),`,
+        '})',
+        'if err != nil {',
+        `\t${c}.String(http.StatusInternalServerError, "Authentication failed: "+err.Error())`,
+        '\treturn',
+        '}',
+        '',
+        'sealed, err := sealAuthkitSession(authkitSession{',
+        '\tAccessToken:  response.AccessToken,',
+        '\tRefreshToken: response.RefreshToken,',
+        '\tUser:         response.User,',
+        '})',
+        'if err != nil {',
+        `\t${c}.String(http.StatusInternalServerError, "Failed to seal session: "+err.Error())`,
+        '\treturn',
+        '}',
+        `${c}.SetSameSite(http.SameSiteLaxMode)`,
+        `${c}.SetCookie(authkitSessionCookie, sealed, authkitSessionMaxAge, "/", "", ${c}.Request.TLS != nil, true)`,
+        ...clearLegacy,
+        `${c}.Redirect(http.StatusTemporaryRedirect, "/")`,
+      ];
+    case 'logout': {
+      const target = goReturnTo(returnTo);
+      // The logout URL only takes an absolute return URL; without one WorkOS uses the app's default
+      const absolute = Boolean(returnTo?.envVar || /^https?:\/\//.test(returnTo?.url ?? ''));
+      return [
+        `session, _ := authkitSessionFromRequest(${c}.Request)`,
+        `${c}.SetCookie(authkitSessionCookie, "", -1, "/", "", ${c}.Request.TLS != nil, true)`,
+        ...clearLegacy,
+        'if sessionID := session.sessionID(); sessionID != "" {',
+        '\tlogoutURL, err := usermanagement.GetLogoutURL(usermanagement.GetLogoutURLOpts{',
+        '\t\tSessionID: sessionID,',
+        ...(absolute ? [`\t\tReturnTo:  ${target},`] : []),
+        '\t})',
+        '\tif err == nil {',
+        `\t\t${c}.Redirect(http.StatusTemporaryRedirect, logoutURL.String())`,
+        '\t\treturn',
+        '\t}',
+        '}',
+        `${c}.Redirect(http.StatusTemporaryRedirect, ${target})`,
+      ];
+    }
+  }
+}
+
+const ROLE_IMPORTS: Record<AuthRouteRole, string[]> = {
+  login: ['net/http', 'os', USERMANAGEMENT_IMPORT],
+  callback: ['net/http', 'os', USERMANAGEMENT_IMPORT],
+  logout: ['net/http', USERMANAGEMENT_IMPORT],
+};
+
+/** A handler body from a project template, with the imports it declares */
+interface HandlerTemplate {
+  imports: string[];
+  lines: string[];
+}
+
+/**
+ * Split a handler template into its leading import declaration, if any,
+ * and the body, with the indentation common to all body lines removed.
+ */
+export function parseHandlerTemplate(template: string): { imports: string[]; body: string } {
+  const decl = /^\s*import\s*(\([^)]*\)|"[^"\n]*")[^\n]*\n?/.exec(template);
+  const imports = decl ? [...decl[1].matchAll(/"([^"\n]+)"/g)].map((m) => m[1]) : [];
+  const lines = (decl ? template.slice(decl[0].length) : template).replace(/^\n+|\s+$/g, '').split('\n');
+  const indent = Math.min(...lines.filter((l) => l.trim()).map((l) => /^[\t ]*/.exec(l)![0].length));
+  return { imports, body: lines.map((l) => l.slice(Number.isFinite(indent) ? indent : 0)).join('\n') };
+}
+
+function templateBody(template: string, c: string, { legacyCookie, returnTo, redirect }: BodyValues): HandlerTemplate {
+  const { imports, body } = parseHandlerTemplate(template);
+  const clearLegacyCookie = legacyCookie ? `${c}.SetCookie("${legacyCookie}", "", -1, "/", "", false, true)` : '';
+  const values = { ctx: c, clearLegacyCookie, redirectUri: goRedirectURI(redirect), returnTo: goReturnTo(returnTo) };
+  return { imports, lines: renderTemplate(body, values).split('\n') };
+}
+
+/** Imports a built-in handler body needs */
+function builtInImports(role: AuthRouteRole, returnTo: ReturnTo | undefined): string[] {
+  return role === 'logout' && returnTo?.envVar ? [...ROLE_IMPORTS[role], 'os'] : ROLE_IMPORTS[role];
+}
+
+export interface GinRewriteOptions {
+  /** Project templates replacing the built-in handler bodies, by role */
+  handlerTemplates?: Partial<Record<AuthRouteRole, string>>;
+  /** How the old config built its callback URL; login reads WORKOS_REDIRECT_URI when unset */
+  redirect?: RedirectSource;
+}
+
+/**
+ * Add missing imports to the file's import declaration.
+ */
+export function ensureGoImports(source: string, paths: string[]): string {
+  const masked = maskLiterals(source);
+  const missing = paths.filter((path) => !source.includes(`"${path}"`));
+  if (missing.length === 0) return source;
+  const lines = missing.map((path) => `\t"${path}"`).join('\n');
+
+  const block = /^import\s*\(/m.exec(masked);
+  if (block) {
+    const close = matchingBracket(masked, block.index + block[0].length - 1);
+    if (close !== -1) {
+      const insertAt = source.lastIndexOf('\n', close) + 1;
+      return `${source.slice(0, insertAt)}${lines}\n${source.slice(insertAt)}`;
+    }
+  }
+
+  const single = /^import\s+("[^"\n]*")[^\n]*$/m.exec(source);
+  if (single) {
+    const replacement = `import (\n\t${single[1]}\n${lines}\n)`;
+    return source.slice(0, single.index) + replacement + source.slice(single.index + single[0].length);
+  }
+
+  const pkg = /^package\s+\w+[^\n]*\n/m.exec(source);
+  const insertAt = pkg ? pkg.index + pkg[0].length : 0;
+  return `${source.slice(0, insertAt)}\nimport (\n${lines}\n)\n${source.slice(insertAt)}`;
+}
+
+const SESSION_LIBRARIES = ['github.com/gin-contrib/sessions', 'github.com/gorilla/sessions'];
+
+/**
+ * Session handling the rewrite can't convert safely: reads and writes of
+ * the old cookie, and server-side session libraries. Clearing the old
+ * cookie is harmless and not reported.
+ */
+export function findSessionFollowUps(source: string, legacyCookie: string | null): SessionFollowUp[] {
+  const masked = maskLiterals(source);
+  const followUps: SessionFollowUp[] = [];
+
+  if (legacyCookie) {
+    for (const call of findCalls(source, masked, new RegExp(`\\b(${IDENT})\\.(Cookie|SetCookie)\\(`))) {
+      const [name, value] = call.args.map((arg) => arg.text);
+      if (stringValue(name ?? '') !== legacyCookie || (call.name === 'SetCookie' && value === '""')) continue;
+      followUps.push({
+        line: lineAt(source, call.index),
+        reason:
+          call.name === 'Cookie'
+            ? `reads the old "${legacyCookie}" cookie; load the sealed session with authkitSessionFromRequest instead`
+            : `still sets the old "${legacyCookie}" cookie; the session is now sealed in ${SESSION_COOKIE}`,
+      });
+    }
+    const cookieField = new RegExp(`\\bName:\\s*"${legacyCookie.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')}"`, 'g');
+    for (const match of source.matchAll(cookieField)) {
+      // Skip matches inside comments
+      if (masked[match.index] !== 'N') continue;
+      followUps.push({
+        line: lineAt(source, match.index),
+        reason: `builds an http.Cookie named "${legacyCookie}"; the session is now sealed in ${SESSION_COOKIE}`,
+      });
+    }
+  }
+
+  for (const library of SESSION_LIBRARIES) {
+    const index = source.indexOf(`"${library}"`);
+    if (index === -1) continue;
+    followUps.push({
+      line: lineAt(source, index),
+      reason: `uses ${library}; move the user it stores into the sealed AuthKit session`,
+    });
+  }
+
+  return followUps.sort((a, b) => a.line - b.line);
+}
+
+/**
+ * Replace the bodies of the Gin login/callback/logout handlers with the
+ * AuthKit flow. The callback seals the session into the AuthKit session
+ * cookie; the cookie the old handlers used is cleared on callback and
+ * logout, and remaining uses of it are reported as follow-ups. A role with
+ * a project template gets that body and the imports it declares instead.
+ */
+export function rewriteGinAuthRoutes(source: string, options: GinRewriteOptions = {}): GinRewriteResult {
+  const masked = maskLiterals(source);
+  const result: GinRewriteResult = {
+    source,
+    rewritten: [],
+    skipped: [],
+    needsSessionHelper: false,
+    legacyCookie: null,
+    sessionFollowUps: [],
+  };
+
+  const targets: Array<{ route: GinRoute; role: AuthRouteRole; body: HandlerBody }> = [];
+  for (const route of parseGinRoutes(source)) {
+    const role = authRouteRole(route.fullPath);
+    if (!role) continue;
+    const body = locateHandlerBody(source, masked, route);
+    if (typeof body === 'string') {
+      result.skipped.push({ route, reason: body });
+    } else if (targets.some((t) => t.body.open === body.open)) {
+      result.skipped.push({ route, reason: `handler ${route.handler} is already rewritten for another route` });
+    } else {
+      targets.push({ route, role, body });
+    }
+  }
+  if (targets.length === 0) return result;
+
+  const bodies = targets.map((t) => source.slice(t.body.open, t.body.close));
+  const cookies = bodies.map((body) => /\.SetCookie\(\s*"([^"]+)"/.exec(body)?.[1]);
+  const legacyCookie = cookies.find((name) => name && name !== SESSION_COOKIE) ?? null;
+
+  const handlers = targets.map(({ body, role }, index): HandlerTemplate => {
+    const returnTo = role === 'logout' ? findReturnTo(bodies[index]) : undefined;
+    const template = options.handlerTemplates?.[role];
+    const values = { legacyCookie, returnTo, redirect: options.redirect };
+    if (template) return templateBody(template, body.contextName, values);
+    return { imports: builtInImports(role, returnTo), lines: authKitBody(role, body.contextName, values) };
+  });
+
+  let rewritten = source;
+  for (const index of targets.map((_, i) => i).sort((a, b) => targets[b].body.open - targets[a].body.open)) {
+    const { body } = targets[index];
+    const lineStart = source.lastIndexOf('\n', body.open) + 1;
+    const indent = /^[\t ]*/.exec(source.slice(lineStart))![0];
+    const lines = handlers[index].lines.map((line) => (line ? `${indent}\t${line}` : ''));
+    rewritten = `${rewritten.slice(0, body.open)}{\n${lines.join('\n')}\n${indent}}${rewritten.slice(body.close + 1)}`;
+  }
+
+  result.source = ensureGoImports(rewritten, [...new Set(handlers.flatMap((h) => h.imports))]);
+  result.rewritten = targets.map(({ route, role }) => ({ route, role }));
+  result.needsSessionHelper = targets.some((t) => t.role !== 'login');
+  result.legacyCookie = legacyCookie;
+  // Scanned after the rewrite so lines match the written file
+  result.sessionFollowUps = findSessionFollowUps(result.source, legacyCookie);
+  return result;
+}
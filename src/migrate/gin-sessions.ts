@@ -0,0 +1,319 @@
+/**
+ * Sealed AuthKit sessions for migrated Go apps.
+ *
+ * Hand-rolled session cookies (`c.SetCookie("user", string(claimsJSON), ...)`)
+ * are readable and forgeable by the client. Migrations replace them with a
+ * session sealed the way the WorkOS SDKs seal it: Iron (`Fe26.2`) keyed with
+ * WORKOS_COOKIE_PASSWORD, in the format `workos session` unseals (see
+ * lib/session-seal.ts). The Go SDK has no sealing helper, so the migration
+ * writes one into the app's package.
+ *
+ * The helper also keeps the login's OAuth state: a random value per sign-in
+ * in a short-lived cookie, which the callback checks before exchanging the
+ * code (see oauth-state.ts).
+ *
+ * Apps that refreshed tokens under the old provider also get
+ * refreshAuthkitSession, which rotates the session's refresh token and
+ * reseals the cookie (see refresh-tokens.ts).
+ */
+
+import { renderTemplate } from './templates.js';
+
+/** Cookie the AuthKit SDKs store the sealed session in */
+export const SESSION_COOKIE = 'wos-session';
+
+/** Cookie the login keeps its OAuth state in until the callback */
+export const STATE_COOKIE = 'wos-oauth-state';
+
+/** Go file written next to the rewritten handlers */
+export const SESSION_HELPER_FILE = 'authkit_session.go';
+
+const HELPER_BODY = `// Sealed AuthKit session, written by the WorkOS CLI migration. The cookie uses
+// the Iron format the WorkOS SDKs use, keyed by WORKOS_COOKIE_PASSWORD, so
+// \`workos session\` can inspect it. Access tokens are short-lived: refresh them
+// with usermanagement.AuthenticateWithRefreshToken before calling WorkOS APIs.
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/workos/workos-go/v4/pkg/usermanagement"
+)
+
+const (
+	authkitSessionCookie = "${SESSION_COOKIE}"
+	authkitSessionMaxAge = 400 * 24 * 60 * 60
+	authkitStateCookie   = "${STATE_COOKIE}"
+	authkitStateMaxAge   = 10 * 60
+	ironPrefix           = "Fe26.2"
+)
+
+type authkitSession struct {
+	AccessToken  string              \`json:"accessToken"\`
+	RefreshToken string              \`json:"refreshToken"\`
+	User         usermanagement.User \`json:"user"\`
+}
+
+// authkitSessionFromRequest unseals the session cookie, if there is one.
+func authkitSessionFromRequest(r *http.Request) (*authkitSession, error) {
+	cookie, err := r.Cookie(authkitSessionCookie)
+	if err != nil {
+		return nil, err
+	}
+	return unsealAuthkitSession(cookie.Value)
+}
+
+// newAuthkitState is a random OAuth state for one sign-in. The login keeps it
+// in authkitStateCookie and the callback checks it, so another site can't
+// complete a sign-in in the user's browser.
+func newAuthkitState() (string, error) {
+	state := make([]byte, 32)
+	if _, err := rand.Read(state); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(state), nil
+}
+
+// validAuthkitState reports whether the callback's state matches the one the
+// login set.
+func validAuthkitState(r *http.Request) bool {
+	cookie, err := r.Cookie(authkitStateCookie)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return hmac.Equal([]byte(cookie.Value), []byte(r.URL.Query().Get("state")))
+}
+
+// currentUser is the signed-in user, or the zero User without a session.
+func (s *authkitSession) currentUser() usermanagement.User {
+	if s == nil {
+		return usermanagement.User{}
+	}
+	return s.User
+}
+
+// sessionID is the sid claim of the access token, which the AuthKit logout URL
+// needs. The token came straight from WorkOS when the session was sealed.
+func (s *authkitSession) sessionID() string {
+	if s == nil {
+		return ""
+	}
+	parts := strings.Split(s.AccessToken, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		SessionID string \`json:"sid"\`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.SessionID
+}
+
+func cookiePassword() (string, error) {
+	password := os.Getenv("WORKOS_COOKIE_PASSWORD")
+	if len(password) < 32 {
+		return "", errors.New("WORKOS_COOKIE_PASSWORD must be at least 32 characters")
+	}
+	return password, nil
+}
+
+// ironKey is PBKDF2-SHA1 with one iteration, as Iron derives its keys.
+func ironKey(password, salt string) []byte {
+	var key []byte
+	for block := byte(1); len(key) < 32; block++ {
+		mac := hmac.New(sha1.New, []byte(password))
+		mac.Write([]byte(salt))
+		mac.Write([]byte{0, 0, 0, block})
+		key = mac.Sum(key)
+	}
+	return key[:32]
+}
+
+func ironSalt() (string, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}
+
+func sealAuthkitSession(session authkitSession) (string, error) {
+	password, err := cookiePassword()
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+	encryptionSalt, err := ironSalt()
+	if err != nil {
+		return "", err
+	}
+	hmacSalt, err := ironSalt()
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(ironKey(password, encryptionSalt))
+	if err != nil {
+		return "", err
+	}
+	padding := aes.BlockSize - len(payload)%aes.BlockSize
+	for i := 0; i < padding; i++ {
+		payload = append(payload, byte(padding))
+	}
+	encrypted := make([]byte, len(payload))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, payload)
+
+	encode := base64.RawURLEncoding.EncodeToString
+	macBase := strings.Join([]string{ironPrefix, "1", encryptionSalt, encode(iv), encode(encrypted), ""}, "*")
+	mac := hmac.New(sha256.New, ironKey(password, hmacSalt))
+	mac.Write([]byte(macBase))
+	return macBase + "*" + hmacSalt + "*" + encode(mac.Sum(nil)) + "~2", nil
+}
+
+func unsealAuthkitSession(sealed string) (*authkitSession, error) {
+	password, err := cookiePassword()
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(strings.SplitN(sealed, "~", 2)[0], "*")
+	if len(parts) != 8 || parts[0] != ironPrefix {
+		return nil, errors.New("session cookie is not sealed")
+	}
+
+	decode := base64.RawURLEncoding.DecodeString
+	mac := hmac.New(sha256.New, ironKey(password, parts[6]))
+	mac.Write([]byte(strings.Join(parts[:6], "*")))
+	expected, err := decode(parts[7])
+	if err != nil || !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, errors.New("session cookie failed its integrity check")
+	}
+
+	iv, err := decode(parts[3])
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, errors.New("session cookie has an invalid IV")
+	}
+	encrypted, err := decode(parts[4])
+	if err != nil || len(encrypted) == 0 || len(encrypted)%aes.BlockSize != 0 {
+		return nil, errors.New("session cookie has an invalid payload")
+	}
+	block, err := aes.NewCipher(ironKey(password, parts[2]))
+	if err != nil {
+		return nil, err
+	}
+	payload := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(payload, encrypted)
+	padding := int(payload[len(payload)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errors.New("session cookie has invalid padding")
+	}
+
+	var session authkitSession
+	if err := json.Unmarshal(payload[:len(payload)-padding], &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+`;
+
+const REFRESH_BODY = `
+// accessTokenExpired reports whether the access token's exp claim has passed,
+// or will within a minute.
+func (s *authkitSession) accessTokenExpired() bool {
+	parts := strings.Split(s.AccessToken, ".")
+	if len(parts) != 3 {
+		return true
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return true
+	}
+	var claims struct {
+		Expiry int64 \`json:"exp"\`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return true
+	}
+	return time.Now().Add(time.Minute).Unix() >= claims.Expiry
+}
+
+// refreshAuthkitSession trades the session's refresh token for new tokens and
+// reseals the cookie. WorkOS rotates refresh tokens, so the old one stops
+// working once this returns. Call it where the old provider's tokens were
+// refreshed, e.g. in auth middleware when accessTokenExpired reports true.
+func refreshAuthkitSession(w http.ResponseWriter, r *http.Request, session *authkitSession) (*authkitSession, error) {
+	response, err := usermanagement.AuthenticateWithRefreshToken(
+		r.Context(),
+		usermanagement.AuthenticateWithRefreshTokenOpts{
+			ClientID:     os.Getenv("WORKOS_CLIENT_ID"),
+			RefreshToken: session.RefreshToken,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	refreshed := authkitSession{
+		AccessToken:  response.AccessToken,
+		RefreshToken: response.RefreshToken,
+		User:         session.User,
+	}
+	sealed, err := sealAuthkitSession(refreshed)
+	if err != nil {
+		return nil, err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     authkitSessionCookie,
+		Value:    sealed,
+		Path:     "/",
+		MaxAge:   authkitSessionMaxAge,
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return &refreshed, nil
+}
+`;
+
+export interface SessionHelperOptions {
+  /** Add refreshAuthkitSession, for apps that refreshed tokens under the old provider */
+  refresh?: boolean;
+}
+
+/**
+ * Source of the session helper for a Go package. A project template
+ * replaces everything after the package clause.
+ */
+export function sessionHelperSource(
+  packageName: string,
+  template?: string,
+  options: SessionHelperOptions = {},
+): string {
+  if (template) return `package ${packageName}\n\n${renderTemplate(template, { sessionCookie: SESSION_COOKIE })}`;
+  const body = options.refresh
+    ? `${HELPER_BODY.replace('\t"strings"\n', '\t"strings"\n\t"time"\n')}${REFRESH_BODY}`
+    : HELPER_BODY;
+  return `package ${packageName}\n\n${body}`;
+}
@@ -0,0 +1,122 @@
+import { describe, it, expect } from 'vitest';
+import { rewriteClaimReads } from './go-claims.js';
+
+const MAP_CLAIMS = `package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func profile(c *gin.Context) {
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	email, ok := claims["email"].(string)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id":    claims["sub"],
+		"name":  claims["name"].(string),
+		"email": email,
+		"plan":  claims["https://acme.com/plan"],
+		"org":   claims["org_id"],
+	})
+}
+`;
+
+const STRUCT_CLAIMS = `package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type Profile struct {
+	Subject  string \`json:"sub"\`
+	Email    string \`json:"email"\`
+	Verified bool   \`json:"email_verified"\`
+	Tenant   string \`json:"tenant_id"\`
+}
+
+func me(w http.ResponseWriter, r *http.Request) {
+	var profile Profile
+	err := json.Unmarshal(payload, &profile)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	users.Upsert(profile.Subject, profile.Email, profile.Verified)
+	tenants.Join(profile.Tenant)
+}
+`;
+
+describe('go-claims', () => {
+  it('reads claims from a map off the AuthKit user', () => {
+    const result = rewriteClaimReads(MAP_CLAIMS);
+
+    expect(result.source).toContain(
+      [
+        '\tauthSession, err := authkitSessionFromRequest(c.Request)',
+        '\tclaims := authSession.currentUser()',
+        '\tif err != nil {',
+      ].join('\n'),
+    );
+    expect(result.source).not.toContain('map[string]interface{}');
+    expect(result.source).toContain('\temail, ok := claims.Email, claims.Email != ""\n');
+    expect(result.source).toContain('"id":    claims.ID,');
+    expect(result.source).toContain('"name":  strings.TrimSpace(claims.FirstName + " " + claims.LastName),');
+    expect(result.source).toContain('\t"github.com/gin-gonic/gin"\n\t"strings"\n)');
+    expect(result.needsSessionHelper).toBe(true);
+
+    const lines = result.source.split('\n');
+    expect(result.rewritten.map((r) => r.claim)).toEqual(['email', 'sub', 'name']);
+    expect(lines[result.rewritten[1].line - 1]).toContain('claims.ID');
+    expect(result.followUps.map((f) => f.claim)).toEqual(['https://acme.com/plan', 'org_id']);
+    expect(result.followUps[1].reason).toMatch(/access token/);
+    expect(lines[result.followUps[0].line - 1]).toContain('"plan"');
+  });
+
+  it('maps json-tagged struct fields by their claim', () => {
+    const result = rewriteClaimReads(STRUCT_CLAIMS);
+
+    expect(result.source).not.toContain('var profile Profile');
+    expect(result.source).toContain(
+      '\tauthSession, err := authkitSessionFromRequest(r)\n\tprofile := authSession.currentUser()\n',
+    );
+    expect(result.source).toContain('users.Upsert(profile.ID, profile.Email, profile.EmailVerified)');
+    expect(result.source).toContain('tenants.Join(profile.Tenant)');
+    expect(result.followUps).toEqual([expect.objectContaining({ claim: 'tenant_id', line: 23 })]);
+  });
+
+  it('leaves decodes without the request in scope for manual mapping', () => {
+    const source = `package main
+
+func claimsOf(idToken *oidc.IDToken) map[string]interface{} {
+	var claims map[string]interface{}
+	_ = idToken.Claims(&claims)
+	return claims
+}
+`;
+    const result = rewriteClaimReads(source);
+
+    expect(result.source).toBe(source);
+    expect(result.needsSessionHelper).toBe(false);
+    expect(result.followUps).toEqual([{ line: 5, reason: expect.stringMatching(/outside a handler/) }]);
+  });
+
+  it('ignores json decodes that are not claims', () => {
+    const source = `package main
+
+func save(r *http.Request) {
+	var order Order
+	json.Unmarshal(body, &order)
+}
+`;
+    expect(rewriteClaimReads(source)).toEqual({ source, rewritten: [], followUps: [], needsSessionHelper: false });
+  });
+});
@@ -0,0 +1,377 @@
+/**
+ * ID token claim reads in migrated Go apps.
+ *
+ * Apps decode the old provider's claims into a map (`var claims
+ * map[string]interface{}`) or a struct with json tags, then read `sub`,
+ * `email`, `name` and so on from it. After the migration the signed-in user
+ * comes from the sealed AuthKit session (see gin-sessions.ts), so the decode
+ * becomes a session lookup and each standard claim read becomes the matching
+ * usermanagement.User field. Custom and namespaced claims have no field to
+ * point at; they're left as written and reported for manual mapping (a JWT
+ * template or user metadata, see scopes-claims.ts).
+ *
+ * Only decodes that can be rewritten safely are touched: inside a function
+ * with the request in scope, into a variable declared on its own line in the
+ * same block. Anything else is reported instead of guessed at.
+ */
+
+import { ensureGoImports, lineAt, maskLiterals, matchingBracket } from './gin-routes.js';
+import { mapClaim } from './scopes-claims.js';
+
+const IDENT = '[A-Za-z_]\\w*';
+
+/** Variable the rewritten decode keeps the session in */
+const SESSION_VAR = 'authSession';
+
+interface UserField {
+  /** Go expression reading the field from the user variable */
+  expr: (user: string) => string;
+  type: 'string' | 'bool';
+}
+
+/** The usermanagement.User field for each standard claim */
+const USER_FIELDS: Record<string, UserField> = {
+  sub: { expr: (u) => `${u}.ID`, type: 'string' },
+  email: { expr: (u) => `${u}.Email`, type: 'string' },
+  email_verified: { expr: (u) => `${u}.EmailVerified`, type: 'bool' },
+  given_name: { expr: (u) => `${u}.FirstName`, type: 'string' },
+  family_name: { expr: (u) => `${u}.LastName`, type: 'string' },
+  name: { expr: (u) => `strings.TrimSpace(${u}.FirstName + " " + ${u}.LastName)`, type: 'string' },
+  picture: { expr: (u) => `${u}.ProfilePictureURL`, type: 'string' },
+};
+
+export interface ClaimRewrite {
+  /** 1-based line in the rewritten source */
+  line: number;
+  claim: string;
+  /** Go expression the read now uses */
+  field: string;
+}
+
+export interface ClaimFollowUp {
+  /** 1-based line in the rewritten source */
+  line: number;
+  claim?: string;
+  reason: string;
+}
+
+export interface ClaimRewriteResult {
+  source: string;
+  rewritten: ClaimRewrite[];
+  /** Claim reads and decodes left for manual mapping */
+  followUps: ClaimFollowUp[];
+  /** True when a decode now loads the sealed session, so the package needs the session helper */
+  needsSessionHelper: boolean;
+}
+
+/** Why a claim has to be mapped by hand */
+function manualReason(claim: string): string {
+  const { authkit, note } = mapClaim(claim);
+  if (authkit) {
+    return `AuthKit puts ${authkit} in the access token, not on the user; decode it from the session's token`;
+  }
+  return note ?? 'not issued by AuthKit';
+}
+
+/** Fields a struct body decodes: field name → claim key */
+function structClaims(source: string, open: number, close: number): Map<string, string> {
+  const fields = new Map<string, string>();
+  for (const line of source.slice(open + 1, close).split('\n')) {
+    const field = /^\s*([A-Z]\w*)\s+[^\s`]+(?:\s+`([^`]*)`)?/.exec(line);
+    if (!field) continue;
+    const tag = field[2] ? /\bjson:"([^",]*)/.exec(field[2])?.[1] : undefined;
+    if (tag === '-') continue;
+    // encoding/json matches untagged fields to keys case-insensitively
+    fields.set(field[1], tag || field[1].toLowerCase());
+  }
+  return fields;
+}
+
+function structTypes(source: string, masked: string): Map<string, Map<string, string>> {
+  const types = new Map<string, Map<string, string>>();
+  for (const match of masked.matchAll(new RegExp(`\\btype\\s+(${IDENT})\\s+struct\\s*\\{`, 'g'))) {
+    const open = match.index + match[0].length - 1;
+    const close = matchingBracket(masked, open);
+    if (close !== -1) types.set(match[1], structClaims(source, open, close));
+  }
+  return types;
+}
+
+interface FuncScope {
+  /** Offsets of the body's braces */
+  open: number;
+  close: number;
+  params: string;
+}
+
+function functionScopes(masked: string): FuncScope[] {
+  const scopes: FuncScope[] = [];
+  // The body opens at the end of the line, after result types like map[string]interface{}
+  const pattern = new RegExp(
+    `\\bfunc\\b(?:\\s*\\([^()]*\\))?\\s*(?:${IDENT})?\\s*\\(([^()]*)\\)[^\\n]*\\{[\\t ]*$`,
+    'gm',
+  );
+  for (const match of masked.matchAll(pattern)) {
+    const open = match.index + match[0].trimEnd().length - 1;
+    const close = matchingBracket(masked, open);
+    if (close !== -1) scopes.push({ open, close, params: match[1] });
+  }
+  return scopes;
+}
+
+/** The *http.Request a handler's parameters give access to */
+function requestExpr(params: string): string | null {
+  const gin = new RegExp(`(${IDENT})\\s+\\*gin\\.Context`).exec(params);
+  if (gin) return `${gin[1]}.Request`;
+  const echo = new RegExp(`(${IDENT})\\s+echo\\.Context`).exec(params);
+  if (echo) return `${echo[1]}.Request()`;
+  return new RegExp(`(${IDENT})\\s+\\*http\\.Request`).exec(params)?.[1] ?? null;
+}
+
+interface Declaration {
+  start: number;
+  /** Offset just past the declaration's line */
+  end: number;
+  fields: Map<string, string> | null;
+}
+
+/** Where `name` is declared as a claims map (fields null) or struct, last before `before` */
+function findDeclaration(
+  source: string,
+  masked: string,
+  name: string,
+  scope: FuncScope,
+  before: number,
+  types: Map<string, Map<string, string>>,
+): Declaration | null {
+  const body = masked.slice(0, before);
+  const pattern = new RegExp(
+    `\\n[\\t ]*(?:var\\s+${name}\\s+([^\\n=]+?)|${name}\\s*:=\\s*([^\\n]+?))[\\t ]*(?=\\n)`,
+    'g',
+  );
+  let found: Declaration | null = null;
+  for (const match of body.matchAll(pattern)) {
+    if (match.index < scope.open) continue;
+    // `var x T` declares; `x := T{}` only when it's an empty composite literal
+    if (match[2] !== undefined && !/\{\}$/.test(match[2])) continue;
+    const start = match.index + 1;
+    const type = match[1] ?? match[2].slice(0, -2).trim();
+    const end = start + match[0].length;
+    if (/^map\[string\](interface\{\}|any)$/.test(type)) {
+      found = { start, end, fields: null };
+    } else if (types.has(type)) {
+      found = { start, end, fields: types.get(type)! };
+    } else if (/^struct\s*\{/.test(type)) {
+      const open = start + masked.slice(start).indexOf('{');
+      const close = matchingBracket(masked, open);
+      if (close === -1) continue;
+      const lineEnd = masked.indexOf('\n', close);
+      found = { start, end: lineEnd === -1 ? masked.length : lineEnd + 1, fields: structClaims(source, open, close) };
+    }
+  }
+  return found;
+}
+
+/** Whether the braces between two offsets balance, so both are in the same block */
+function sameBlock(masked: string, from: number, to: number): boolean {
+  let depth = 0;
+  for (const ch of masked.slice(from, to)) {
+    if (ch === '{') depth++;
+    else if (ch === '}' && --depth < 0) return false;
+  }
+  return depth === 0;
+}
+
+interface Decode {
+  name: string;
+  /** Offsets of the decoding call */
+  start: number;
+  end: number;
+  /** Decoded with json.Unmarshal, which is only a claims decode for claim-shaped targets */
+  json: boolean;
+}
+
+function findDecodes(masked: string): Decode[] {
+  const decodes: Decode[] = [];
+  for (const match of masked.matchAll(new RegExp(`\\b(?:${IDENT}\\.)+Claims\\(\\s*&(${IDENT})\\s*\\)`, 'g'))) {
+    decodes.push({ name: match[1], start: match.index, end: match.index + match[0].length, json: false });
+  }
+  for (const match of masked.matchAll(/\bjson\.Unmarshal\(/g)) {
+    const close = matchingBracket(masked, match.index + match[0].length - 1);
+    if (close === -1) continue;
+    const target = new RegExp(`,\\s*&(${IDENT})\\s*\\)$`).exec(masked.slice(match.index, close + 1));
+    if (target) decodes.push({ name: target[1], start: match.index, end: close + 1, json: true });
+  }
+  return decodes.sort((a, b) => a.start - b.start);
+}
+
+interface Edit {
+  start: number;
+  end: number;
+  text: string;
+}
+
+/** Statement lines replacing the decode, or null when its form isn't one this handles */
+function sessionLookup(masked: string, decode: Decode, request: string, lineStart: number, lineEnd: number) {
+  const before = masked.slice(lineStart, decode.start);
+  const after = masked.slice(decode.end, lineEnd);
+  const lookup = (err: string) => `${SESSION_VAR}, ${err} := authkitSessionFromRequest(${request})`;
+  const user = `${decode.name} := ${SESSION_VAR}.currentUser()`;
+
+  if (/^[\t ]*$/.test(before) && /^[\t ]*$/.test(after)) return [lookup('_'), user];
+  const assigned = new RegExp(`^[\\t ]*(${IDENT})\\s*:?=\\s*$`).exec(before);
+  if (assigned && /^[\t ]*$/.test(after)) return [lookup(assigned[1]), user];
+  const guarded = new RegExp(`^[\\t ]*if\\s+(${IDENT})\\s*:?=\\s*$`).exec(before);
+  const condition = /^\s*;\s*([^{]+?)\s*\{[\t ]*$/.exec(after);
+  if (guarded && condition) return [lookup(guarded[1]), user, `if ${condition[1]} {`];
+  return null;
+}
+
+/**
+ * Point claim reads at the signed-in AuthKit user: each decode of the old
+ * token's claims becomes a lookup of the sealed session, and reads of
+ * standard claims from the map or struct become usermanagement.User
+ * fields. Reads of other claims, and uses of the whole value, are reported.
+ */
+export function rewriteClaimReads(source: string): ClaimRewriteResult {
+  const masked = maskLiterals(source);
+  const types = structTypes(source, masked);
+  const scopes = functionScopes(masked);
+  const edits: Edit[] = [];
+  // Reports by offset in the original source, turned into lines of the result at the end
+  const rewritten: Array<Omit<ClaimRewrite, 'line'> & { at: number }> = [];
+  const followUps: Array<Omit<ClaimFollowUp, 'line'> & { at: number }> = [];
+  const rewrittenScopes = new Set<number>();
+
+  for (const decode of findDecodes(masked)) {
+    const enclosing = scopes
+      .filter((s) => s.open < decode.start && decode.end < s.close)
+      .sort((a, b) => b.open - a.open);
+    const scope = enclosing[0];
+    if (!scope) continue;
+    const declaration = findDeclaration(source, masked, decode.name, scope, decode.start, types);
+    const standard = (claims: Iterable<string>) => [...claims].some((claim) => claim in USER_FIELDS);
+    if (decode.json) {
+      // Most Unmarshal calls have nothing to do with claims
+      const claimShaped = declaration?.fields
+        ? standard(declaration.fields.values())
+        : declaration && /claim|user|profile/i.test(decode.name);
+      if (!claimShaped) continue;
+    }
+
+    const report = (reason: string) => followUps.push({ at: decode.start, reason });
+    const request = enclosing.map((s) => requestExpr(s.params)).find(Boolean);
+    if (!declaration) {
+      report(
+        `decodes claims into ${decode.name}, declared where the rewrite can't follow it; read the AuthKit user by hand`,
+      );
+      continue;
+    }
+    if (!request) {
+      report('decodes claims outside a handler that has the request; load the AuthKit session where it is in scope');
+      continue;
+    }
+    if (!sameBlock(masked, declaration.end, decode.start)) {
+      report(`${decode.name} is declared in another block than it is decoded in; read the AuthKit user by hand`);
+      continue;
+    }
+    const sessionTaken = new RegExp(`\\b${SESSION_VAR}\\b`).test(masked.slice(scope.open, scope.close));
+    if (rewrittenScopes.has(scope.open) || sessionTaken) {
+      report('claims are decoded more than once in this function; load the AuthKit session once and read its user');
+      continue;
+    }
+
+    const lineStart = source.lastIndexOf('\n', decode.start - 1) + 1;
+    const newline = source.indexOf('\n', decode.end);
+    const lineEnd = newline === -1 ? source.length : newline;
+    const lines = sessionLookup(masked, decode, request, lineStart, lineEnd);
+    if (!lines) {
+      report('decodes claims in a form the rewrite does not handle; load the AuthKit session and read its user');
+      continue;
+    }
+    rewrittenScopes.add(scope.open);
+    const indent = /^[\t ]*/.exec(source.slice(lineStart))![0];
+    edits.push({ start: declaration.start, end: declaration.end, text: '' });
+    edits.push({ start: lineStart, end: lineEnd, text: lines.map((line) => `${indent}${line}`).join('\n') });
+
+    const { name } = decode;
+    const { fields } = declaration;
+    const uses = new RegExp(`(?<![\\w.])${name}\\b`, 'g');
+    uses.lastIndex = decode.end;
+    for (let match = uses.exec(masked); match && match.index < scope.close; match = uses.exec(masked)) {
+      const at = match.index;
+      const rest = source.slice(at + name.length);
+      const access = fields
+        ? new RegExp(`^\\.(${IDENT})\\b(?!\\s*\\()`).exec(rest)
+        : /^\[\s*"((?:[^"\\\n]|\\.)*)"\s*\]/.exec(rest);
+      if (!access) {
+        const reason = /^\s*\[/.test(rest)
+          ? `reads ${name} with a computed key; map it to the AuthKit user by hand`
+          : `uses ${name} as a whole; it is now a usermanagement.User`;
+        followUps.push({ at, reason });
+        continue;
+      }
+
+      const claim = fields ? fields.get(access[1]) : access[1];
+      let end = at + name.length + access[0].length;
+      if (!claim) {
+        followUps.push({ at, reason: `reads ${name}.${access[1]}, which the claims don't fill; check it by hand` });
+        continue;
+      }
+      if (/^\s*=(?!=)/.test(masked.slice(end))) {
+        followUps.push({ at, claim, reason: `writes the ${claim} claim; the AuthKit user comes from WorkOS` });
+        continue;
+      }
+      const field = USER_FIELDS[claim];
+      if (!field) {
+        followUps.push({ at, claim, reason: manualReason(claim) });
+        continue;
+      }
+
+      const assertion = fields ? null : /^\.\(\s*([^()]+?)\s*\)/.exec(source.slice(end));
+      if (assertion && !['interface{}', 'any', field.type].includes(assertion[1])) {
+        followUps.push({ at, claim, reason: `asserted as ${assertion[1]}, but the AuthKit field is a ${field.type}` });
+        continue;
+      }
+      if (assertion) end += assertion[0].length;
+      const expr = field.expr(name);
+      // `v, ok := claims["email"].(string)` still needs two values
+      const statement = masked.slice(masked.lastIndexOf('\n', at) + 1, at);
+      const commaOk = Boolean(assertion) && new RegExp(`,\\s*${IDENT}\\s*:?=\\s*$`).test(statement);
+      const present = field.type === 'bool' ? 'true' : `${expr} != ""`;
+      edits.push({ start: at, end, text: commaOk ? `${expr}, ${present}` : expr });
+      rewritten.push({ at, claim, field: expr });
+    }
+  }
+
+  if (edits.length === 0) {
+    return {
+      source,
+      rewritten: [],
+      followUps: followUps.map(({ at, ...rest }) => ({ line: lineAt(source, at), ...rest })),
+      needsSessionHelper: false,
+    };
+  }
+
+  edits.sort((a, b) => a.start - b.start);
+  let output = '';
+  let last = 0;
+  for (const edit of edits) {
+    output += source.slice(last, edit.start) + edit.text;
+    last = edit.end;
+  }
+  output += source.slice(last);
+  // Offsets after the edits before them, for line numbers in the result
+  const moved = (at: number) =>
+    at + edits.filter((edit) => edit.end <= at).reduce((delta, e) => delta + e.text.length - (e.end - e.start), 0);
+
+  const withImports = output.includes('strings.TrimSpace(') ? ensureGoImports(output, ['strings']) : output;
+  const addedLines = withImports.split('\n').length - output.split('\n').length;
+  const line = (at: number) => lineAt(output, moved(at)) + addedLines;
+  return {
+    source: withImports,
+    rewritten: rewritten.map(({ at, ...rest }) => ({ line: line(at), ...rest })),
+    followUps: followUps.map(({ at, ...rest }) => ({ line: line(at), ...rest })).sort((a, b) => a.line - b.line),
+    needsSessionHelper: true,
+  };
+}
@@ -0,0 +1,38 @@
+import { describe, expect, it } from 'vitest';
+import { matchIgnoreRules, parseIgnoreFile } from './ignore-rules.js';
+
+function ignores(content: string, path: string): boolean {
+  return matchIgnoreRules(parseIgnoreFile(content, '.workosignore'), path) !== null;
+}
+
+describe('ignore-rules', () => {
+  it('matches names at any depth unless a slash anchors them', () => {
+    expect(ignores('legacy.ts', 'src/auth/legacy.ts')).toBe(true);
+    expect(ignores('/legacy.ts', 'src/auth/legacy.ts')).toBe(false);
+    expect(ignores('src/*.ts', 'src/auth.ts')).toBe(true);
+    expect(ignores('src/*.ts', 'src/auth/index.ts')).toBe(false);
+    expect(ignores('src/**/*.ts', 'src/auth/index.ts')).toBe(true);
+    expect(ignores('**/fixtures', 'a/b/fixtures/auth.ts')).toBe(true);
+  });
+
+  it('applies directory rules to everything under them, and not to files', () => {
+    expect(ignores('vendor/', 'packages/vendor/auth0.js')).toBe(true);
+    expect(ignores('build/', 'build')).toBe(false);
+  });
+
+  it('lets the last matching rule decide', () => {
+    expect(ignores('third_party/\n!third_party/ours/', 'third_party/ours/auth.ts')).toBe(false);
+    expect(ignores('!third_party/ours/\nthird_party/', 'third_party/ours/auth.ts')).toBe(true);
+  });
+
+  it('skips comments and blank lines, and reports where a rule came from', () => {
+    const rules = parseIgnoreFile('# vendored\n\n\\#hash.ts\n*.gen.ts  \n', '.workosignore');
+
+    expect(rules.map((r) => [r.line, r.pattern])).toEqual([
+      [3, '\\#hash.ts'],
+      [4, '*.gen.ts'],
+    ]);
+    expect(matchIgnoreRules(rules, '#hash.ts')?.line).toBe(3);
+    expect(matchIgnoreRules(rules, 'api.gen.ts')?.line).toBe(4);
+  });
+});
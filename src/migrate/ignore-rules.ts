@@ -0,0 +1,141 @@
+/**
+ * Ignore files for the detection walk, in gitignore syntax.
+ *
+ * `.gitignore` isn't always the right exclusion set for a migration:
+ * vendored code is often committed but shouldn't be migrated, and build
+ * output is ignored either way. `.workosignore` at the project root is read
+ * after `.gitignore`, so its rules, `!` re-includes among them, override
+ * it. The last rule that matches a path decides, as in git; `--exclude`
+ * globs apply after both and always win.
+ */
+
+import { readFileSync } from 'node:fs';
+import { join } from 'node:path';
+import type { IgnoredPath } from './types.js';
+
+export const WORKOSIGNORE_FILE = '.workosignore';
+
+/** Read in this order; later files override earlier ones */
+const IGNORE_FILES = ['.gitignore', WORKOSIGNORE_FILE];
+
+export interface IgnoreRule {
+  /** The file the rule came from, e.g. .workosignore */
+  source: string;
+  /** 1-based line in that file */
+  line: number;
+  /** The rule as written */
+  pattern: string;
+  /** `!pattern`: brings back a path an earlier rule excluded */
+  negated: boolean;
+  /** `dir/`: only matches directories (and so everything under them) */
+  dirOnly: boolean;
+  regex: RegExp;
+}
+
+function escapeRegex(ch: string): string {
+  return /[.*+?^${}()|[\]\\/]/.test(ch) ? `\\${ch}` : ch;
+}
+
+/** gitignore glob to a regex source: `*` and `?` stop at `/`, `**` crosses directories */
+function globToRegex(glob: string): string {
+  let re = '';
+  for (let i = 0; i < glob.length; i++) {
+    const ch = glob[i];
+    if (ch === '*' && glob[i + 1] === '*') {
+      if (glob[i + 2] === '/' && (i === 0 || glob[i - 1] === '/')) {
+        // `**/` is zero or more directories
+        re += '(?:.*/)?';
+        i += 2;
+      } else {
+        re += '.*';
+        i += 1;
+      }
+    } else if (ch === '*') {
+      re += '[^/]*';
+    } else if (ch === '?') {
+      re += '[^/]';
+    } else if (ch === '[') {
+      const close = glob.indexOf(']', i + 2);
+      if (close === -1) {
+        re += '\\[';
+      } else {
+        const body = glob.slice(i + 1, close).replace(/\\/g, '\\\\');
+        re += `[${body.startsWith('!') ? `^${body.slice(1)}` : body}]`;
+        i = close;
+      }
+    } else if (ch === '\\' && i + 1 < glob.length) {
+      re += escapeRegex(glob[++i]);
+    } else {
+      re += escapeRegex(ch);
+    }
+  }
+  return re;
+}
+
+/** One line of an ignore file as a rule, or null for blanks and comments */
+function parseRule(text: string, source: string, line: number): IgnoreRule | null {
+  // Trailing spaces don't count unless escaped
+  let pattern = text.replace(/(?<!\\)\s+$/, '');
+  if (!pattern || pattern.startsWith('#')) return null;
+  const written = pattern;
+
+  const negated = pattern.startsWith('!');
+  if (negated) pattern = pattern.slice(1);
+  else if (pattern.startsWith('\\!') || pattern.startsWith('\\#')) pattern = pattern.slice(1);
+  const dirOnly = pattern.endsWith('/');
+  if (dirOnly) pattern = pattern.replace(/\/+$/, '');
+  if (!pattern) return null;
+
+  // A slash anywhere but the end anchors the rule to the root; otherwise it matches at any depth
+  const anchored = pattern.includes('/');
+  const body = globToRegex(pattern.replace(/^\//, ''));
+  return {
+    source,
+    line,
+    pattern: written,
+    negated,
+    dirOnly,
+    regex: new RegExp(`^${anchored ? '' : '(?:.*/)?'}${body}$`),
+  };
+}
+
+export function parseIgnoreFile(content: string, source: string): IgnoreRule[] {
+  return content
+    .split(/\r?\n/)
+    .map((text, index) => parseRule(text, source, index + 1))
+    .filter((rule): rule is IgnoreRule => rule !== null);
+}
+
+/** Rules from the root's .gitignore, then its .workosignore; missing files add none */
+export function loadIgnoreRules(root: string): IgnoreRule[] {
+  return IGNORE_FILES.flatMap((file) => {
+    try {
+      return parseIgnoreFile(readFileSync(join(root, file), 'utf-8'), file);
+    } catch {
+      return [];
+    }
+  });
+}
+
+function ruleMatches(rule: IgnoreRule, relPath: string): boolean {
+  const parts = relPath.split('/');
+  // A rule matching a parent directory covers everything under it
+  for (let depth = 1; depth < parts.length; depth++) {
+    if (rule.regex.test(parts.slice(0, depth).join('/'))) return true;
+  }
+  return !rule.dirOnly && rule.regex.test(relPath);
+}
+
+/** The rule that excludes a path (forward slashes, relative to root), or null when it's kept */
+export function matchIgnoreRules(rules: IgnoreRule[], relPath: string): IgnoreRule | null {
+  let decided: IgnoreRule | null = null;
+  for (const rule of rules) {
+    if (ruleMatches(rule, relPath)) decided = rule;
+  }
+  return decided && !decided.negated ? decided : null;
+}
+
+/** e.g. ".workosignore:3 third_party/" */
+export function formatIgnoreRule(rule: IgnoredPath['rule']): string {
+  return `${rule.source}:${rule.line} ${rule.pattern}`;
+}
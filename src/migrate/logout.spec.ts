@@ -0,0 +1,79 @@
+import { describe, it, expect } from 'vitest';
+import { extractLogoutEndpoints, findReturnTo, logoutEndpointProvider, logoutWarning } from './logout.js';
+import type { MigrationFinding } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+function finding(code: string, details?: Record<string, unknown>): MigrationFinding {
+  return makeFinding({
+    code,
+    severity: 'warning',
+    message: code,
+    file: 'server.js',
+    line: 4,
+    confidence: 0.3,
+    details,
+  });
+}
+
+describe('logout', () => {
+  describe('findReturnTo', () => {
+    it('reads the return URL handed to the provider', () => {
+      expect(
+        findReturnTo('"https://" + domain + "/v2/logout?returnTo=" + url.QueryEscape("http://localhost:3000")'),
+      ).toEqual({ url: 'http://localhost:3000' });
+      expect(findReturnTo("'https://acme.auth0.com/v2/logout?returnTo=http%3A%2F%2Flocalhost%3A3000'")).toEqual({
+        url: 'http://localhost:3000',
+      });
+      expect(findReturnTo('`${issuer}/logout?post_logout_redirect_uri=${process.env.APP_URL}`')).toEqual({
+        envVar: 'APP_URL',
+      });
+      expect(findReturnTo('params = {"returnTo": os.environ.get("APP_URL")}')).toEqual({ envVar: 'APP_URL' });
+    });
+
+    it('falls back to the last local redirect', () => {
+      expect(findReturnTo('c.SetCookie("user", "", -1, "/", "", false, true)\nc.Redirect(302, "/goodbye")')).toEqual({
+        url: '/goodbye',
+      });
+    });
+
+    it('leaves computed targets to the agent', () => {
+      expect(findReturnTo('parameters.Add("returnTo", returnTo.String())\nc.Redirect(302, u.String())')).toBeUndefined();
+    });
+  });
+
+  it('names the provider from the logout endpoint host or path', () => {
+    expect(logoutEndpointProvider('"https://acme.okta.com/oauth2/default/v1/logout"')).toBe('okta');
+    expect(logoutEndpointProvider('`${base}/realms/app/protocol/openid-connect/logout`')).toBe('keycloak');
+    expect(logoutEndpointProvider('res.redirect("/logout")')).toBeUndefined();
+  });
+
+  it('keeps the return URL and style of each sign-out', () => {
+    expect(
+      extractLogoutEndpoints([
+        finding('idp-logout', { endpoint: '/v2/logout', returnTo: 'http://localhost:3000', federated: true }),
+        finding('local-logout', { returnToEnvVar: 'APP_URL' }),
+        finding('auth0-action'),
+      ]),
+    ).toEqual([
+      {
+        style: 'federated',
+        file: 'server.js',
+        line: 4,
+        endpoint: '/v2/logout',
+        returnTo: 'http://localhost:3000',
+        federated: true,
+      },
+      { style: 'local', file: 'server.js', line: 4, returnToEnvVar: 'APP_URL' },
+    ]);
+  });
+
+  it('warns only when sign-out semantics change', () => {
+    expect(logoutWarning([finding('idp-logout'), finding('local-logout')])).toBeNull();
+    expect(logoutWarning([finding('idp-logout', { federated: true }), finding('idp-backchannel-logout')])).toBe(
+      '1 sign-out(s) also end the upstream IdP session through Auth0; ' +
+        "AuthKit's logout URL ends the WorkOS session only. " +
+        '1 back/front-channel logout endpoint(s) receive logouts from Auth0; ' +
+        'WorkOS does not call them, so revoke local sessions from session.revoked events instead.',
+    );
+  });
+});
@@ -0,0 +1,185 @@
+/**
+ * Sign-out a migrated app has to repoint at AuthKit.
+ *
+ * Apps sign users out in one of two ways: by clearing their own session
+ * ("local"), or by sending the browser to the provider's logout endpoint
+ * with a return URL ("federated": Auth0's `/v2/logout?returnTo=`, OIDC
+ * RP-initiated logout with `post_logout_redirect_uri`, or an SDK call that
+ * does the same). With AuthKit both become a redirect to the AuthKit logout
+ * URL for the session, which ends the WorkOS session and returns the user
+ * where the old logout did. Clearing the cookie alone leaves the WorkOS
+ * session signed in, so the next login skips the sign-in page.
+ *
+ * Some semantics don't carry over and are flagged instead: Auth0's
+ * `federated` also signs the user out of the upstream IdP, and back- or
+ * front-channel logout endpoints receive logouts from the provider.
+ */
+
+import { getProvider, providerFromIssuer } from './providers.js';
+import type { LogoutEndpoint, MigrationFinding } from './types.js';
+
+/** Logout endpoints by path, for URLs whose host is built at runtime */
+const LOGOUT_ENDPOINTS: Array<{ pattern: RegExp; provider: string }> = [
+  { pattern: /\/v2\/logout\b/, provider: 'auth0' },
+  { pattern: /\/oidc\/logout\b/, provider: 'auth0' },
+  { pattern: /\/protocol\/openid-connect\/logout\b/, provider: 'keycloak' },
+  { pattern: /\/oauth2\/v2\.0\/logout\b/, provider: 'azure-ad' },
+  { pattern: /\/oauth2\/(?:[\w-]+\/)?v1\/logout\b/, provider: 'okta' },
+  { pattern: /amazoncognito\.com\/logout\b/, provider: 'cognito' },
+];
+
+/** Where discovery-based clients read the endpoint from */
+const DISCOVERED_ENDPOINTS = ['\\bend_session_endpoint\\b', '\\bEndSessionEndpoint\\b'];
+/** Any provider logout endpoint */
+export const LOGOUT_ENDPOINT_PATTERN = new RegExp(
+  [...LOGOUT_ENDPOINTS.map(({ pattern }) => pattern.source), ...DISCOVERED_ENDPOINTS].join('|'),
+);
+
+const RETURN_TO_KEYS = [
+  'returnTo',
+  'ReturnTo',
+  'return_to',
+  'returnUrl',
+  'post_logout_redirect_uri',
+  'postLogoutRedirectUri',
+  'PostLogoutRedirectURI',
+  'logout_uri',
+];
+/** A key naming the return URL, in a query string, object, struct or call */
+const RETURN_TO_KEY = new RegExp(`\\b(?:${RETURN_TO_KEYS.join('|')})["'\`]?\\s*(?:=>|=|:|,)\\s*`, 'g');
+/** A URL written into the query string itself, possibly encoded */
+const INLINE_URL = /^(https?(?::|%3A)[^&'"`\s)]+|\/[^&'"`\s)]*)/i;
+/** What joins a query string to the value after it: a closing quote and `+`/`.`, or `${` */
+const JOIN_PREFIX = /^(?:['"`]\s*[+.]\s*|\$\{)/;
+/** A call wrapping the value, e.g. `url.QueryEscape(`, `encodeURIComponent(` */
+const WRAPPING_CALL = /^[\w.]+\(\s*/;
+const STRING_VALUE = /^(['"`])((?:https?:\/\/|\/)[^'"`$]*)\1/;
+const ENV_REFERENCES = [
+  /^os\.Getenv\(\s*"(\w+)"\s*\)/,
+  /^process\.env\.(\w+)/,
+  /^process\.env\[\s*['"](\w+)['"]\s*\]/,
+  /^os\.(?:environ\.get|getenv)\(\s*['"](\w+)['"]/,
+  /^os\.environ\[\s*['"](\w+)['"]\s*\]/,
+  /^ENV(?:\.fetch\(|\[)\s*['"](\w+)['"]/,
+  /^env\(\s*['"](\w+)['"]/,
+  /^System\.getenv\(\s*"(\w+)"/,
+];
+/** A redirect to a literal path or URL, e.g. `c.Redirect(http.StatusFound, "/")` or `res.redirect('/')` */
+const REDIRECT_PATTERN = /\b[Rr]edirect(?:_to|To)?\w*\(\s*(?:[\w.]+\s*,\s*)?(['"`])((?:https?:\/\/|\/)[^'"`$]*)\1/g;
+
+export interface ReturnTo {
+  url?: string;
+  envVar?: string;
+}
+
+/** Provider whose logout endpoint a URL or source line points at */
+export function logoutEndpointProvider(text: string): string | undefined {
+  const url = /https?:\/\/[^\s'"`]+/.exec(text)?.[0];
+  const fromHost = url && providerFromIssuer(url);
+  if (fromHost && /logout/i.test(url)) return fromHost;
+  return LOGOUT_ENDPOINTS.find(({ pattern }) => pattern.test(text))?.provider;
+}
+
+function decode(value: string): string {
+  try {
+    return decodeURIComponent(value);
+  } catch {
+    return value;
+  }
+}
+
+function literalOrEnv(value: string): ReturnTo | undefined {
+  const literal = STRING_VALUE.exec(value);
+  if (literal) return { url: literal[2] };
+  for (const pattern of ENV_REFERENCES) {
+    const env = pattern.exec(value);
+    if (env) return { envVar: env[1] };
+  }
+  return undefined;
+}
+
+function valueAt(text: string, index: number): ReturnTo | undefined {
+  const rest = text.slice(index, index + 200).split('\n')[0];
+  const inline = INLINE_URL.exec(rest);
+  if (inline) return { url: decode(inline[1]) };
+  const value = rest.replace(JOIN_PREFIX, '');
+  return literalOrEnv(value) ?? literalOrEnv(value.replace(WRAPPING_CALL, ''));
+}
+
+/**
+ * Where a sign-out sends the user: the return URL handed to the provider
+ * (`returnTo`, `post_logout_redirect_uri`, ...) when there is one, otherwise
+ * the last redirect to a literal that isn't a provider endpoint. Only
+ * literals and env vars are recognized; anything computed is left to the agent.
+ */
+export function findReturnTo(text: string): ReturnTo | undefined {
+  for (const match of text.matchAll(RETURN_TO_KEY)) {
+    const value = valueAt(text, match.index + match[0].length);
+    if (value) return value;
+  }
+  const redirects = [...text.matchAll(REDIRECT_PATTERN)].filter((m) => !logoutEndpointProvider(m[2]));
+  const last = redirects.at(-1);
+  return last ? { url: last[2] } : undefined;
+}
+
+function stringDetail(finding: MigrationFinding, key: string): string | undefined {
+  const value = finding.details?.[key];
+  return typeof value === 'string' ? value : undefined;
+}
+
+/** Sign-outs found by the detectors, in the order found */
+export function extractLogoutEndpoints(findings: MigrationFinding[]): LogoutEndpoint[] {
+  return findings
+    .filter((f) => f.code === 'idp-logout' || f.code === 'local-logout')
+    .map((f): LogoutEndpoint => {
+      const endpoint = stringDetail(f, 'endpoint');
+      const returnTo = stringDetail(f, 'returnTo');
+      const returnToEnvVar = stringDetail(f, 'returnToEnvVar');
+      return {
+        style: f.code === 'idp-logout' ? 'federated' : 'local',
+        file: f.file,
+        ...(f.line ? { line: f.line } : {}),
+        ...(endpoint && { endpoint }),
+        ...(returnTo && { returnTo }),
+        ...(returnToEnvVar && { returnToEnvVar }),
+        ...(f.details?.federated === true && { federated: true }),
+      };
+    });
+}
+
+function describeReturnTo(endpoint: LogoutEndpoint): string | undefined {
+  if (endpoint.returnTo) return endpoint.returnTo;
+  return endpoint.returnToEnvVar && `the URL in ${endpoint.returnToEnvVar}`;
+}
+
+/** Prompt lines mapping each sign-out to the AuthKit logout URL */
+export function logoutLines(endpoints: LogoutEndpoint[]): string[] {
+  return endpoints.map((e) => {
+    const location = e.line ? `${e.file}:${e.line}` : e.file;
+    const from =
+      e.style === 'federated' ? `redirects to ${e.endpoint ?? 'the provider logout'}` : 'clears the app session only';
+    const returnTo = describeReturnTo(e);
+    const federated = e.federated ? '; `federated` upstream sign-out has no AuthKit equivalent, drop it' : '';
+    return `- ${location}: ${from} → AuthKit logout URL${returnTo ? `, returnTo ${returnTo}` : ''}${federated}`;
+  });
+}
+
+/**
+ * Summary warning for sign-outs whose semantics change with AuthKit, or
+ * null when there are none.
+ */
+export function logoutWarning(findings: MigrationFinding[]): string | null {
+  const federated = findings.filter((f) => f.code === 'idp-logout' && f.details?.federated === true);
+  const channel = findings.filter((f) => f.code === 'idp-backchannel-logout');
+  if (federated.length === 0 && channel.length === 0) return null;
+  const provider = getProvider([...federated, ...channel][0].provider)?.displayName ?? 'the old provider';
+  const parts = [
+    federated.length > 0 &&
+      `${federated.length} sign-out(s) also end the upstream IdP session through ${provider}; ` +
+        "AuthKit's logout URL ends the WorkOS session only.",
+    channel.length > 0 &&
+      `${channel.length} back/front-channel logout endpoint(s) receive logouts from ${provider}; ` +
+        'WorkOS does not call them, so revoke local sessions from session.revoked events instead.',
+  ];
+  return parts.filter(Boolean).join(' ');
+}
@@ -0,0 +1,85 @@
+/**
+ * CSRF protection for the migrated login.
+ *
+ * A hand-written OAuth login protects its callback with a random `state`
+ * (and, with OIDC, a `nonce`) kept in the session or a cookie and checked
+ * when the provider redirects back. Rewriting the login must keep that
+ * check with AuthKit's state, or the callback accepts sign-ins another site
+ * started. Apps that used a constant state (the quickstarts'
+ * `AuthCodeURL("state")`) had no protection; the migration adds it and says
+ * so. AuthKit exchanges the code server-side and returns no ID token, so a
+ * nonce check has nothing to check and the state check covers it. The uses
+ * come from the oauth-state detector (`details.oauthState`).
+ */
+
+import type { MigrationFinding, OAuthStateUse } from './types.js';
+
+const KINDS = new Set(['constant', 'generated', 'validated']);
+const PARAMS = new Set(['state', 'nonce']);
+const STORAGES = new Set(['cookie', 'session']);
+
+/** State and nonce handling across the findings, one per kind, parameter and file, first location kept */
+export function extractOAuthStateUses(findings: MigrationFinding[]): OAuthStateUse[] {
+  const uses = new Map<string, OAuthStateUse>();
+  for (const finding of findings) {
+    const { oauthState, param, storage } = finding.details ?? {};
+    if (typeof oauthState !== 'string' || !KINDS.has(oauthState)) continue;
+    if (typeof param !== 'string' || !PARAMS.has(param)) continue;
+    const key = `${oauthState}:${param}:${finding.file}`;
+    if (uses.has(key)) continue;
+    uses.set(key, {
+      kind: oauthState as OAuthStateUse['kind'],
+      param: param as OAuthStateUse['param'],
+      ...(typeof storage === 'string' && STORAGES.has(storage) && { storage: storage as OAuthStateUse['storage'] }),
+      file: finding.file,
+      ...(finding.line !== undefined && { line: finding.line }),
+    });
+  }
+  return [...uses.values()];
+}
+
+/** What the migrated login does, in order */
+export const OAUTH_STATE_STEPS = [
+  'In the login, generate a random state for each sign-in (32 bytes from a cryptographic random source), keep it in an HttpOnly, SameSite=Lax cookie or the server-side session, and pass it as `state` to the AuthKit authorization URL (getAuthorizationUrl, usermanagement.GetAuthorizationURL in Go)',
+  'In the callback, before exchanging the code, compare the `state` query parameter with the stored value in constant time; reject a missing or different value with 400, then clear the stored value',
+  'Drop the old nonce: AuthKit exchanges the code server-side and returns no ID token to check it against, so the state check protects the callback',
+  'Where an AuthKit SDK handles the sign-in and callback (handleAuth, authkitMiddleware, authkit-remix loaders), it checks the state itself; keep the check above only in a callback you write by hand',
+];
+
+const STORAGE_PLACES: Record<NonNullable<OAuthStateUse['storage']>, string> = {
+  cookie: 'a cookie',
+  session: 'the session',
+};
+
+function describeUse(use: OAuthStateUse): string {
+  const location = use.line ? `${use.file}:${use.line}` : use.file;
+  const kept = use.storage ? `, kept in ${STORAGE_PLACES[use.storage]}` : '';
+  switch (use.kind) {
+    case 'constant':
+      return `${location}: constant OAuth state (no CSRF protection) → a random state per sign-in, checked in the callback`;
+    case 'generated':
+      return use.param === 'nonce'
+        ? `${location}: generates a nonce${kept} → not needed with AuthKit; the state check replaces it`
+        : `${location}: generates a random state${kept} → pass it to the AuthKit authorization URL`;
+    case 'validated':
+      return use.param === 'nonce'
+        ? `${location}: checks the ID token nonce → check the state instead`
+        : `${location}: checks the state in the callback → keep the check before the AuthKit code exchange`;
+  }
+}
+
+/** One line per use, for the prompt and the plan */
+export function oauthStateLines(uses: OAuthStateUse[]): string[] {
+  return uses.map(describeUse);
+}
+
+/** Logins that used a constant state, which the migration fixes */
+export function oauthStateWarning(findings: MigrationFinding[]): string | null {
+  const constant = findings.filter((f) => f.code === 'oauth-state-constant');
+  if (constant.length === 0) return null;
+  const locations = constant.map((f) => (f.line ? `${f.file}:${f.line}` : f.file)).join(', ');
+  return (
+    `${constant.length} login(s) used a constant OAuth state (${locations}), which gives no CSRF protection; ` +
+    'the migrated login generates a random state per sign-in and checks it in the callback.'
+  );
+}
@@ -0,0 +1,79 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { existsSync, mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { _setCachePath, createOidcDiscovery, normalizeIssuer } from './oidc-discovery.js';
+
+function discoveryResponse(issuer: string) {
+  return new Response(JSON.stringify({ issuer, authorization_endpoint: `${issuer}/authorize` }), { status: 200 });
+}
+
+describe('oidc-discovery', () => {
+  let dir: string;
+  let cachePath: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'oidc-discovery-test-'));
+    cachePath = join(dir, 'oidc-discovery.json');
+    _setCachePath(cachePath);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('normalizes issuers and ignores placeholders', () => {
+    expect(normalizeIssuer('https://id.example.com/realms/app/')).toBe('https://id.example.com/realms/app');
+    expect(normalizeIssuer('${OIDC_ISSUER}')).toBeNull();
+    expect(normalizeIssuer('ftp://id.example.com')).toBeNull();
+  });
+
+  it('fetches each issuer once and caches the answer', async () => {
+    const fetch = vi.fn(async () => discoveryResponse('https://id.example.com'));
+
+    const first = createOidcDiscovery({ fetch });
+    const results = [first.resolves('https://id.example.com'), first.resolves('https://id.example.com/')];
+    expect(await Promise.all(results)).toEqual([true, true]);
+    expect(fetch).toHaveBeenCalledTimes(1);
+    expect(fetch).toHaveBeenCalledWith('https://id.example.com/.well-known/openid-configuration', expect.anything());
+
+    expect(await createOidcDiscovery({ fetch }).resolves('https://id.example.com')).toBe(true);
+    expect(fetch).toHaveBeenCalledTimes(1);
+  });
+
+  it('treats an answer without a discovery document as not live', async () => {
+    const fetch = vi.fn(async () => new Response('Not found', { status: 404 }));
+
+    expect(await createOidcDiscovery({ fetch }).resolves('https://app.example.com')).toBe(false);
+  });
+
+  it('expires cached answers', async () => {
+    const fetch = vi.fn(async () => new Response('Not found', { status: 404 }));
+    const start = Date.now();
+    await createOidcDiscovery({ fetch, now: () => start }).resolves('https://app.example.com');
+
+    await createOidcDiscovery({ fetch, now: () => start + 2 * 60 * 60 * 1000 }).resolves('https://app.example.com');
+
+    expect(fetch).toHaveBeenCalledTimes(2);
+  });
+
+  it('skips the cache with --no-cache', async () => {
+    const fetch = vi.fn(async () => discoveryResponse('https://id.example.com'));
+
+    await createOidcDiscovery({ fetch, cache: false }).resolves('https://id.example.com');
+
+    expect(existsSync(cachePath)).toBe(false);
+  });
+
+  it('stops fetching once the network is unavailable', async () => {
+    const fetch = vi.fn(async () => {
+      throw new TypeError('fetch failed');
+    });
+    const discovery = createOidcDiscovery({ fetch });
+
+    expect(await discovery.resolves('https://a.example.com')).toBeNull();
+    expect(await discovery.resolves('https://b.example.com')).toBeNull();
+    expect(fetch).toHaveBeenCalledTimes(1);
+    expect(existsSync(cachePath) ? JSON.parse(readFileSync(cachePath, 'utf-8')) : {}).toEqual({});
+  });
+});
@@ -0,0 +1,142 @@
+/**
+ * OIDC discovery for detection: whether an issuer found in the project is
+ * live, i.e. serves `/.well-known/openid-configuration`. A live issuer makes
+ * a generic OIDC finding more likely to be a real login flow than leftover
+ * config.
+ *
+ * Lookups are shared across findings and cached in
+ * ~/.workos/cache/oidc-discovery.json, live issuers for a day and dead ones
+ * for an hour. Each fetch gets a short timeout, and the first connection
+ * failure marks the network as unavailable so the rest of the scan doesn't
+ * wait on it; those issuers come back unknown.
+ */
+
+import { mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { logInfo } from '../utils/debug.js';
+
+const DISCOVERY_PATH = '/.well-known/openid-configuration';
+const TIMEOUT_MS = 2000;
+const LIVE_TTL_MS = 24 * 60 * 60 * 1000;
+const DEAD_TTL_MS = 60 * 60 * 1000;
+
+let cachePath = join(homedir(), '.workos', 'cache', 'oidc-discovery.json');
+
+interface DiscoveryCacheEntry {
+  live: boolean;
+  checkedAt: number;
+}
+
+type DiscoveryCache = Record<string, DiscoveryCacheEntry>;
+
+export interface OidcDiscoveryClient {
+  /**
+   * True when the issuer serves a discovery document, false when it answered
+   * without one, null when it couldn't be checked.
+   */
+  resolves(issuer: string): Promise<boolean | null>;
+}
+
+export interface OidcDiscoveryOptions {
+  /** Read and write the on-disk cache (`--no-cache` turns it off). Default true */
+  cache?: boolean;
+  timeoutMs?: number;
+  fetch?: typeof fetch;
+  now?: () => number;
+}
+
+/**
+ * The issuer as a cache key: an http(s) URL without a trailing slash.
+ * Null for anything else, such as `${OIDC_ISSUER}` placeholders.
+ */
+export function normalizeIssuer(issuer: string): string | null {
+  let url: URL;
+  try {
+    url = new URL(issuer.trim());
+  } catch {
+    return null;
+  }
+  if (url.protocol !== 'https:' && url.protocol !== 'http:') return null;
+  return `${url.origin}${url.pathname.replace(/\/+$/, '')}`;
+}
+
+function isFresh(entry: DiscoveryCacheEntry | undefined, now: number): entry is DiscoveryCacheEntry {
+  return !!entry && now - entry.checkedAt < (entry.live ? LIVE_TTL_MS : DEAD_TTL_MS);
+}
+
+function readCache(): DiscoveryCache {
+  try {
+    return JSON.parse(readFileSync(cachePath, 'utf-8')) as DiscoveryCache;
+  } catch {
+    return {};
+  }
+}
+
+function writeCache(cache: DiscoveryCache, now: number): void {
+  const fresh = Object.fromEntries(Object.entries(cache).filter(([, entry]) => isFresh(entry, now)));
+  try {
+    mkdirSync(dirname(cachePath), { recursive: true });
+    writeFileSync(cachePath, JSON.stringify(fresh));
+  } catch {
+    // Caching is best effort
+  }
+}
+
+function isDiscoveryDocument(doc: unknown): boolean {
+  const { issuer, authorization_endpoint, jwks_uri } = (doc ?? {}) as Record<string, unknown>;
+  return typeof issuer === 'string' && (typeof authorization_endpoint === 'string' || typeof jwks_uri === 'string');
+}
+
+export function createOidcDiscovery(options: OidcDiscoveryOptions = {}): OidcDiscoveryClient {
+  const { cache: useCache = true, timeoutMs = TIMEOUT_MS, fetch: fetchFn = fetch, now = Date.now } = options;
+  const lookups = new Map<string, Promise<boolean | null>>();
+  let cache: DiscoveryCache | null = null;
+  let offline = false;
+
+  async function lookup(issuer: string): Promise<boolean | null> {
+    cache ??= useCache ? readCache() : {};
+    const cached = cache[issuer];
+    if (isFresh(cached, now())) return cached.live;
+    if (offline) return null;
+
+    let live: boolean;
+    try {
+      const response = await fetchFn(`${issuer}${DISCOVERY_PATH}`, {
+        headers: { Accept: 'application/json' },
+        signal: AbortSignal.timeout(timeoutMs),
+      });
+      live = response.ok && isDiscoveryDocument(await response.json().catch(() => null));
+    } catch (error) {
+      // No answer at all: don't cache it, and don't make the remaining issuers wait
+      offline = true;
+      logInfo(`OIDC discovery for ${issuer} failed, skipping the rest:`, error);
+      return null;
+    }
+
+    cache[issuer] = { live, checkedAt: now() };
+    if (useCache) writeCache(cache, now());
+    return live;
+  }
+
+  return {
+    resolves(issuer) {
+      const key = normalizeIssuer(issuer);
+      if (!key) return Promise.resolve(null);
+      let result = lookups.get(key);
+      if (!result) {
+        result = lookup(key);
+        lookups.set(key, result);
+      }
+      return result;
+    },
+  };
+}
+
+/**
+ * Override the cache location (for testing).
+ * @internal
+ */
+export function _setCachePath(path: string): void {
+  cachePath = path;
+}
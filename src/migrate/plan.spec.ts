@@ -0,0 +1,142 @@
+import { describe, it, expect } from 'vitest';
+import { selectMigration } from './plan.js';
+import { buildMigrationPrompt } from './prompt.js';
+import type { DetectionResult, MigrationFinding } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+function finding(provider: string, confidence: number, details?: Record<string, unknown>): MigrationFinding {
+  return makeFinding({ provider, message: `${provider} usage`, file: 'a.php', confidence, details });
+}
+
+function result(...matches: Array<[string, number]>): DetectionResult {
+  return {
+    root: '/project',
+    matches: matches.map(([provider, confidence]) => ({
+      provider,
+      confidence,
+      findings: [finding(provider, confidence)],
+    })),
+  };
+}
+
+describe('selectMigration', () => {
+  it('uses the most confident detected provider', () => {
+    const { context, warnings } = selectMigration(result(['auth0', 0.9], ['socialite', 0.3]));
+
+    expect(context.provider).toBe('auth0');
+    expect(context.forced).toBe(false);
+    expect(context.envMapping.AUTH0_CLIENT_ID).toBe('WORKOS_CLIENT_ID');
+    expect(warnings).toEqual([]);
+  });
+
+  it('warns when detection is ambiguous', () => {
+    const { warnings } = selectMigration(result(['auth0', 0.6], ['okta', 0.55]));
+
+    expect(warnings[0]).toContain('ambiguous between auth0 and okta');
+  });
+
+  it('lets --provider override the ranking', () => {
+    const { context, warnings } = selectMigration(result(['auth0', 0.9], ['okta', 0.2]), 'okta');
+
+    expect(context.provider).toBe('okta');
+    expect(context.forced).toBe(true);
+    expect(context.findings).toHaveLength(1);
+    expect(warnings).toEqual([]);
+  });
+
+  it('warns but proceeds when the forced provider has no markers', () => {
+    const { context, warnings } = selectMigration(result(['auth0', 0.9]), 'cognito');
+
+    expect(context.provider).toBe('cognito');
+    expect(context.findings).toEqual([]);
+    expect(warnings[0]).toContain('No Amazon Cognito markers were found');
+  });
+
+  it('rejects unknown providers', () => {
+    expect(() => selectMigration(result(), 'nope')).toThrow('Unknown provider "nope"');
+  });
+
+  it('asks for --provider when nothing is detected', () => {
+    expect(() => selectMigration(result())).toThrow('--provider');
+  });
+
+  it('merges env mappings discovered by detectors', () => {
+    const detection: DetectionResult = {
+      root: '/project',
+      matches: [
+        {
+          provider: 'auth0',
+          confidence: 0.8,
+          findings: [finding('auth0', 0.8, { envVar: 'LOGIN_CLIENT_ID', workosEnv: 'WORKOS_CLIENT_ID' })],
+        },
+      ],
+    };
+
+    expect(selectMigration(detection).context.envMapping.LOGIN_CLIENT_ID).toBe('WORKOS_CLIENT_ID');
+  });
+});
+
+describe('buildMigrationPrompt', () => {
+  it('includes env mapping, guidance and findings', () => {
+    const { context } = selectMigration(result(['auth0', 0.9]));
+    const prompt = buildMigrationPrompt(context);
+
+    expect(prompt).toContain('currently uses Auth0');
+    expect(prompt).toContain('AUTH0_CLIENT_SECRET → WORKOS_API_KEY');
+    expect(prompt).toContain('AUTH0_DOMAIN → remove');
+    expect(prompt).toContain('a.php: auth0 usage');
+  });
+
+  it('maps requested scopes and claims read to AuthKit', () => {
+    const { context } = selectMigration({
+      root: '/project',
+      matches: [
+        {
+          provider: 'auth0',
+          confidence: 0.8,
+          findings: [
+            finding('auth0', 0.8, { scopes: ['openid', 'read:org'] }),
+            finding('auth0', 0, { claim: 'email' }),
+          ],
+        },
+      ],
+    });
+    const prompt = buildMigrationPrompt(context);
+
+    expect(context.scopes?.map((s) => s.support)).toEqual(['supported', 'organization']);
+    expect(prompt).toContain('### Scopes and claims');
+    expect(prompt).toContain('- scope openid: supported by AuthKit');
+    expect(prompt).toContain('- claim email → user.email');
+  });
+
+  it('lists out-of-scope usages separately from the ones to migrate', () => {
+    const { context } = selectMigration(result(['supabase', 0.6]));
+    context.findings.push({ ...finding('supabase', 0), message: 'Database query stays on Supabase', outOfScope: true });
+    const prompt = buildMigrationPrompt(context);
+
+    const [migrate, outOfScope] = prompt.split('### Out of scope');
+    expect(migrate).toContain('a.php: supabase usage');
+    expect(migrate).not.toContain('Database query');
+    expect(outOfScope).toContain('Leave them unchanged');
+    expect(outOfScope).toContain('a.php: Database query stays on Supabase');
+  });
+
+  it('lists excluded files and drops their findings', () => {
+    const { context } = selectMigration(result(['clerk', 0.6]));
+    context.findings.push({ ...finding('clerk', 0.4), file: 'lab.tsx', message: 'experimental usage' });
+    context.excludedFiles = ['lab.tsx'];
+    const prompt = buildMigrationPrompt(context);
+
+    const [migrate, excluded] = prompt.split('### Excluded files');
+    expect(migrate).toContain('a.php: clerk usage');
+    expect(migrate).not.toContain('experimental usage');
+    expect(excluded).toContain('Do not modify them');
+    expect(excluded).toContain('- lab.tsx');
+  });
+
+  it('tells the agent to search when a forced provider was not detected', () => {
+    const { context } = selectMigration(result(), 'okta');
+
+    expect(buildMigrationPrompt(context)).toContain('no Okta code was detected automatically');
+  });
+});
@@ -0,0 +1,119 @@
+import { extractContainerEnvFiles } from './container-env.js';
+import { strongestEvidence } from './evidence.js';
+import { extractLogoutEndpoints } from './logout.js';
+import { getProvider, providerNames } from './providers.js';
+import { extractRedirectUris } from './redirect-uris.js';
+import { extractOAuthStateUses } from './oauth-state.js';
+import { extractRefreshTokenUses } from './refresh-tokens.js';
+import { extractClaims, extractScopes } from './scopes-claims.js';
+import { resolveSdkVersions } from './sdk-versions.js';
+import { extractTemplateReferences } from './template-auth.js';
+import { extractTenancySignals } from './tenancy.js';
+import { extractTerraformClients } from './terraform.js';
+import { extractTestReferences } from './test-references.js';
+import { extractTokenVerifiers } from './token-verification.js';
+import type { DetectionResult, MigrationContext, ProviderMatch } from './types.js';
+import { DetectionEmptyError } from '../utils/errors.js';
+
+/** Providers closer than this in confidence are reported as ambiguous */
+const AMBIGUITY_MARGIN = 0.15;
+
+export interface MigrationSelection {
+  context: MigrationContext;
+  warnings: string[];
+}
+
+/** Env mappings discovered by detectors (e.g. Socialite env() references) */
+function detectedEnvMapping(match: ProviderMatch | undefined): Record<string, string | null> {
+  const mapping: Record<string, string | null> = {};
+  for (const finding of match?.findings ?? []) {
+    const envVar = finding.details?.envVar;
+    if (typeof envVar === 'string' && 'workosEnv' in (finding.details ?? {})) {
+      mapping[envVar] = (finding.details?.workosEnv as string | null) ?? null;
+    }
+  }
+  return mapping;
+}
+
+export interface SelectMigrationOptions {
+  /** SDK majors from --assume-provider-version, keyed by SDK */
+  assumedVersions?: Record<string, number>;
+}
+
+/**
+ * Pick the provider to migrate from.
+ * A forced provider bypasses the detection ranking; otherwise the most
+ * confident match wins and close runners-up are surfaced as warnings.
+ * SDK majors that couldn't be read from the manifest are warned about too.
+ */
+export function selectMigration(
+  result: DetectionResult,
+  forcedProvider?: string,
+  options: SelectMigrationOptions = {},
+): MigrationSelection {
+  const warnings: string[] = [];
+  let match: ProviderMatch | undefined;
+  let providerName: string;
+
+  if (forcedProvider) {
+    const provider = getProvider(forcedProvider);
+    if (!provider) {
+      throw new Error(`Unknown provider "${forcedProvider}". Supported providers: ${providerNames().join(', ')}`);
+    }
+    providerName = provider.name;
+    match = result.matches.find((m) => m.provider === provider.name);
+    if (!match) {
+      warnings.push(
+        `No ${provider.displayName} markers were found in this project. Continuing because --provider was given.`,
+      );
+    }
+  } else {
+    const [top, runnerUp] = result.matches;
+    if (!top) {
+      throw new DetectionEmptyError(
+        `No supported auth provider detected. Use --provider to choose one: ${providerNames().join(', ')}`,
+      );
+    }
+    if (!getProvider(top.provider)) {
+      throw new Error(`Detected provider "${top.provider}" has no migration template yet.`);
+    }
+    if (runnerUp && top.confidence - runnerUp.confidence < AMBIGUITY_MARGIN) {
+      warnings.push(
+        `Detection is ambiguous between ${top.provider} and ${runnerUp.provider}. ` +
+          `Using ${top.provider}; pass --provider to choose explicitly.`,
+      );
+    }
+    match = top;
+    providerName = top.provider;
+  }
+
+  const provider = getProvider(providerName)!;
+  const sdkVersions = resolveSdkVersions(match?.findings ?? [], options.assumedVersions);
+  warnings.push(...sdkVersions.warnings);
+  return {
+    context: {
+      provider: provider.name,
+      displayName: provider.displayName,
+      forced: Boolean(forcedProvider),
+      confidence: match?.confidence ?? 0,
+      evidence: match?.evidence ?? strongestEvidence(match?.findings ?? []),
+      envMapping: { ...provider.envMapping, ...detectedEnvMapping(match) },
+      guidance: provider.guidance,
+      findings: match?.findings ?? [],
+      redirectUris: extractRedirectUris(match?.findings ?? []),
+      tokenVerifiers: extractTokenVerifiers(match?.findings ?? []),
+      logoutEndpoints: extractLogoutEndpoints(match?.findings ?? []),
+      sdkVersions: sdkVersions.versions,
+      scopes: extractScopes(match?.findings ?? []),
+      claims: extractClaims(match?.findings ?? []),
+      tenancy: extractTenancySignals(match?.findings ?? []),
+      refreshTokens: extractRefreshTokenUses(match?.findings ?? []),
+      oauthState: extractOAuthStateUses(match?.findings ?? []),
+      terraformClients: extractTerraformClients(match?.findings ?? []),
+      testReferences: extractTestReferences(match?.findings ?? []),
+      templateReferences: extractTemplateReferences(match?.findings ?? []),
+      containerEnvFiles: extractContainerEnvFiles(match?.findings ?? []),
+    },
+    warnings,
+  };
+}
@@ -0,0 +1,292 @@
+import { redactSecrets } from '../utils/redact.js';
+import { logoutLines } from './logout.js';
+import { OAUTH_STATE_STEPS, oauthStateLines } from './oauth-state.js';
+import { EDITED_SINCE } from './reconcile.js';
+import { refreshTokenLines, SESSION_REFRESH_STEPS } from './refresh-tokens.js';
+import { CATEGORY_LABELS, formatCategories, inScope, MIGRATION_CATEGORIES, type MigrationCategory } from './scope.js';
+import { scopeClaimLines } from './scopes-claims.js';
+import { sdkVersionLines } from './sdk-versions.js';
+import { TEMPLATE_STEPS, templateReferenceLines } from './template-auth.js';
+import { ORGANIZATION_STEPS, tenancyLines } from './tenancy.js';
+import { isTerraformFile, terraformClientLines } from './terraform.js';
+import { isTestFile, testReferenceLines } from './test-references.js';
+import { tokenVerificationLines } from './token-verification.js';
+import type { MigrationContext } from './types.js';
+
+/** Keep the prompt bounded on large projects */
+const MAX_PROMPT_FINDINGS = 50;
+
+/** What the agent leaves alone for each category a run doesn't apply */
+const DEFERRED_CHANGES: Record<MigrationCategory, string> = {
+  code: 'Auth code: do not change application code. Only the files of the categories above change in this run.',
+  env: 'Env files and container config: do not edit .env files, compose files or Dockerfiles. Write the code against the new WorkOS variable names; the env files are renamed later.',
+  deps: 'Dependencies: do not install, upgrade or remove packages, or edit manifests and lockfiles. Write the code against the AuthKit SDK as if it were installed.',
+  templates: 'Templates: do not edit the templates that link to the old login or show markup by sign-in state.',
+};
+
+/**
+ * Migration section appended to the integration prompt.
+ * Findings are redacted since evidence lines can contain hardcoded secrets.
+ */
+export function buildMigrationPrompt(ctx: MigrationContext): string {
+  const lines: string[] = [
+    '## Migration',
+    '',
+    `This project currently uses ${ctx.displayName} for authentication. Replace it with WorkOS AuthKit rather than adding AuthKit alongside it.`,
+  ];
+
+  if (ctx.forced && ctx.findings.length === 0) {
+    lines.push(
+      '',
+      `The user selected ${ctx.displayName} explicitly, but no ${ctx.displayName} code was detected automatically. Search the project for it before making changes.`,
+    );
+  }
+
+  if (ctx.only) {
+    const applied = ctx.only.map((c) => CATEGORY_LABELS[c]).join(', ');
+    lines.push(
+      '',
+      '### Scope',
+      '',
+      `This run applies only part of the migration (${formatCategories(ctx.only)}): ${applied}. Leave the rest for a later run:`,
+      '',
+      ...MIGRATION_CATEGORIES.filter((c) => !ctx.only!.includes(c)).map((c) => `- ${DEFERRED_CHANGES[c]}`),
+    );
+  }
+
+  const mappings = Object.entries(ctx.envMapping);
+  if (mappings.length > 0) {
+    lines.push('', '### Environment variables', '');
+    for (const [from, to] of mappings) {
+      lines.push(to ? `- ${from} → ${to}` : `- ${from} → remove (not needed with AuthKit)`);
+    }
+    const renamed = ctx.renamedEnv ?? [];
+    if (renamed.length > 0) {
+      const files = [...new Set(renamed.map((r) => r.file))].join(', ');
+      lines.push(
+        '',
+        `These keys are already renamed in ${files}. Update the code that reads them, not those env entries.`,
+      );
+    }
+    const containerFiles = ctx.containerEnvFiles ?? [];
+    if (containerFiles.length > 0) {
+      lines.push(
+        '',
+        `Container config also sets these keys: ${containerFiles.map((f) => f.file).join(', ')}. Keep compose files, Dockerfiles and CI build args consistent with the new names; the images and containers must be rebuilt to pick them up.`,
+      );
+    }
+    lines.push(
+      '',
+      'Env values written as references (`${...}`, `$VAR`) point to a secrets manager or another variable. Keep them exactly as written when renaming a key: never expand, quote or replace them.',
+    );
+  }
+
+  if (ctx.guidance.length > 0) {
+    lines.push('', '### Guidance', '');
+    lines.push(...ctx.guidance.map((g) => `- ${g}`));
+  }
+
+  const sdkVersions = ctx.sdkVersions ?? [];
+  if (sdkVersions.length > 0) {
+    lines.push(
+      '',
+      '### Provider SDK versions',
+      '',
+      'Code written against these SDK majors looks like this. Look for that shape when replacing it, not the shape of other majors:',
+      '',
+      ...sdkVersionLines(sdkVersions),
+    );
+  }
+
+  const verifiers = ctx.tokenVerifiers ?? [];
+  if (verifiers.length > 0) {
+    lines.push(
+      '',
+      '### Token verification',
+      '',
+      'These call sites verify JWTs by hand. Keep the verification, but check AuthKit access tokens against the WorkOS key set and issuer (use WORKOS_CLIENT_ID from the environment):',
+      '',
+      ...tokenVerificationLines(verifiers),
+    );
+  }
+
+  const scopeClaims = scopeClaimLines(ctx.scopes ?? [], ctx.claims ?? []);
+  if (scopeClaims.length > 0) {
+    lines.push(
+      '',
+      '### Scopes and claims',
+      '',
+      'The old login requested these scopes and the code reads these claims. Request only the scopes AuthKit supports, and read each claim from where AuthKit provides it:',
+      '',
+      ...scopeClaims.map((line) => `- ${line}`),
+    );
+  }
+
+  const tenancy = ctx.tenancy ?? [];
+  if (tenancy.length > 0) {
+    lines.push(
+      '',
+      '### Organizations',
+      '',
+      'This app is multi-tenant. Keep its tenant model and map each tenant to an AuthKit organization; do not reduce it to single-tenant code. Set it up in this order:',
+      '',
+      ...ORGANIZATION_STEPS.map((step, i) => `${i + 1}. ${step}`),
+      '',
+      'Where the tenant shows up in the code:',
+      '',
+      ...tenancyLines(tenancy).map((line) => `- ${line}`),
+    );
+  }
+
+  const refreshTokens = ctx.refreshTokens ?? [];
+  if (refreshTokens.length > 0) {
+    lines.push(
+      '',
+      '### Session refresh',
+      '',
+      'The old login kept users signed in with refresh tokens. The default AuthKit code only signs users in, so carry the refresh over:',
+      '',
+      ...SESSION_REFRESH_STEPS.map((step, i) => `${i + 1}. ${step}`),
+      '',
+      'Where the code requests or handles refresh tokens:',
+      '',
+      ...refreshTokenLines(refreshTokens).map((line) => `- ${line}`),
+    );
+  }
+
+  const oauthState = ctx.oauthState ?? [];
+  if (oauthState.length > 0) {
+    const constant = oauthState.some((use) => use.kind === 'constant');
+    lines.push(
+      '',
+      '### OAuth state',
+      '',
+      constant
+        ? 'The old login used a constant OAuth state, so its callback had no CSRF protection. Fix that in the migrated flow; do not carry the constant over:'
+        : 'The old login protected its callback with a random state or nonce. Keep equivalent protection with AuthKit; do not drop the check:',
+      '',
+      ...OAUTH_STATE_STEPS.map((step, i) => `${i + 1}. ${step}`),
+      '',
+      'Where the old login handles state and nonce:',
+      '',
+      ...oauthStateLines(oauthState).map((line) => `- ${line}`),
+    );
+  }
+
+  const excluded = ctx.excludedFiles ?? [];
+  const logouts = (ctx.logoutEndpoints ?? []).filter((e) => !excluded.includes(e.file));
+  if (logouts.length > 0) {
+    lines.push(
+      '',
+      '### Logout',
+      '',
+      'Sign-out has to end the AuthKit session as well as the app session: clear the session cookie, then redirect to the AuthKit logout URL for the session (getLogoutUrl in the AuthKit SDKs, usermanagement.GetLogoutURL in Go). Pass the URL the old logout returned to as returnTo, and list it as a sign-out redirect in the WorkOS dashboard.',
+      '',
+      ...logoutLines(logouts),
+    );
+  }
+
+  const templates = (ctx.templateReferences ?? []).filter((r) => !excluded.includes(r.file) && inScope(r.file, ctx));
+  if (templates.length > 0) {
+    lines.push(
+      '',
+      '### Templates',
+      '',
+      'These templates link to the old login and logout or show markup by sign-in state. Update each one for AuthKit:',
+      '',
+      ...TEMPLATE_STEPS.map((step, i) => `${i + 1}. ${step}`),
+      '',
+      'Where sign-in shows up in the templates:',
+      '',
+      ...templateReferenceLines(templates).map((line) => `- ${line}`),
+    );
+  }
+
+  const testReferences = ctx.testReferences ?? [];
+  if (testReferences.length > 0) {
+    lines.push(
+      '',
+      '### Tests referencing the old provider',
+      '',
+      `These tests mock or reference ${ctx.displayName}. Do not rewrite them: the user updates them after the migration. Only touch one if it stops the project from compiling, and then change no more than the import or type that broke.`,
+      '',
+      ...testReferenceLines(testReferences).map((line) => `- ${line}`),
+    );
+  }
+
+  const terraform = ctx.findings.filter((f) => isTerraformFile(f.file));
+  if (terraform.length > 0) {
+    const clients = ctx.terraformClients ?? [];
+    lines.push(
+      '',
+      '### Terraform',
+      '',
+      `Terraform manages the ${ctx.displayName} tenant. Leave the .tf files unchanged: the user configures the WorkOS app to match and retires the ${ctx.displayName} resources after the migration.`,
+      ...(clients.length > 0 ? ['', ...terraformClientLines(clients).map((line) => `- ${line}`)] : []),
+    );
+  }
+
+  const findings = ctx.findings.filter(
+    (f) =>
+      !f.outOfScope &&
+      !excluded.includes(f.file) &&
+      !isTestFile(f.file) &&
+      !isTerraformFile(f.file) &&
+      inScope(f.file, ctx),
+  );
+  if (findings.length > 0) {
+    lines.push('', '### Detected usages', '');
+    for (const f of findings.slice(0, MAX_PROMPT_FINDINGS)) {
+      const location = f.line ? `${f.file}:${f.line}` : f.file;
+      const manual = f.manual ? ' (no drop-in replacement; rewrite by hand)' : '';
+      lines.push(`- ${location}: ${f.message}${f.remediation ? ` — ${f.remediation}` : ''}${manual}`);
+    }
+    if (findings.length > MAX_PROMPT_FINDINGS) {
+      lines.push(`- ...and ${findings.length - MAX_PROMPT_FINDINGS} more`);
+    }
+  }
+
+  const outOfScope = ctx.findings.filter((f) => f.outOfScope);
+  if (outOfScope.length > 0) {
+    lines.push('', '### Out of scope', '', `These ${ctx.displayName} usages are not auth. Leave them unchanged:`, '');
+    for (const f of outOfScope.slice(0, MAX_PROMPT_FINDINGS)) {
+      lines.push(`- ${f.line ? `${f.file}:${f.line}` : f.file}: ${f.message}`);
+    }
+    if (outOfScope.length > MAX_PROMPT_FINDINGS) {
+      lines.push(`- ...and ${outOfScope.length - MAX_PROMPT_FINDINGS} more`);
+    }
+  }
+
+  const previous = (ctx.previousSteps ?? []).filter((step) => !excluded.includes(step.file));
+  const landed = previous.filter((step) => step.status === 'succeeded');
+  const unfinished = previous.filter((step) => step.status !== 'succeeded');
+  if (unfinished.length > 0) {
+    lines.push(
+      '',
+      '### Resuming',
+      '',
+      'An earlier run of this migration was only partly applied. Finish these files, avoiding what made them fail:',
+      '',
+      ...unfinished.map((step) => `- ${step.file}${step.reason ? ` (${step.reason})` : ''}`),
+    );
+    if (landed.length > 0) {
+      lines.push(
+        '',
+        'These were already migrated. Read them for the code they set up, and change them only to connect the rest:',
+        '',
+        ...landed.map((step) => `- ${step.file}${step.reason ? ` (${step.reason})` : ''}`),
+      );
+    }
+    if (previous.some((step) => step.reason?.includes(EDITED_SINCE))) {
+      lines.push('', `The user changed the files marked "${EDITED_SINCE}" after it stopped. Keep their changes.`);
+    }
+  }
+
+  if (excluded.length > 0) {
+    const note = 'The user excluded these files from the migration. Do not modify them:';
+    lines.push('', '### Excluded files', '', note, '');
+    lines.push(...excluded.map((file) => `- ${file}`));
+  }
+
+  return redactSecrets(lines.join('\n'));
+}
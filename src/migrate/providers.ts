@@ -0,0 +1,196 @@
+/**
+ * Auth providers that `workos migrate` knows how to move to AuthKit.
+ * Each provider has a default env mapping and guidance handed to the agent.
+ */
+
+export interface MigrationProvider {
+  name: string;
+  displayName: string;
+  /** Provider env var -> WorkOS env var (null = no longer needed) */
+  envMapping: Record<string, string | null>;
+  /** Provider-specific instructions for the migration prompt */
+  guidance: string[];
+}
+
+export const PROVIDERS: Record<string, MigrationProvider> = {
+  auth0: {
+    name: 'auth0',
+    displayName: 'Auth0',
+    envMapping: {
+      AUTH0_CLIENT_ID: 'WORKOS_CLIENT_ID',
+      AUTH0_CLIENT_SECRET: 'WORKOS_API_KEY',
+      AUTH0_SECRET: 'WORKOS_COOKIE_PASSWORD',
+      AUTH0_CALLBACK_URL: 'WORKOS_REDIRECT_URI',
+      AUTH0_REDIRECT_URI: 'WORKOS_REDIRECT_URI',
+      AUTH0_DOMAIN: null,
+      AUTH0_BASE_URL: null,
+      AUTH0_ISSUER_BASE_URL: null,
+      AUTH0_AUDIENCE: null,
+    },
+    guidance: [
+      'Replace Auth0 login/logout/callback routes with the AuthKit equivalents',
+      'Map the Auth0 user profile (sub, email, name, picture) to the AuthKit user (id, email, firstName, lastName, profilePictureUrl)',
+      'Remove the Auth0 SDK dependency once no code references it',
+    ],
+  },
+  okta: {
+    name: 'okta',
+    displayName: 'Okta',
+    envMapping: {
+      OKTA_CLIENT_ID: 'WORKOS_CLIENT_ID',
+      OKTA_CLIENT_SECRET: 'WORKOS_API_KEY',
+      OKTA_REDIRECT_URI: 'WORKOS_REDIRECT_URI',
+      OKTA_ISSUER: null,
+      OKTA_DOMAIN: null,
+    },
+    guidance: [
+      'Replace the Okta OIDC flow with AuthKit; enterprise Okta tenants can connect to WorkOS via SSO',
+      'Remove the Okta SDK dependency once no code references it',
+    ],
+  },
+  cognito: {
+    name: 'cognito',
+    displayName: 'Amazon Cognito',
+    envMapping: {
+      COGNITO_CLIENT_ID: 'WORKOS_CLIENT_ID',
+      COGNITO_CLIENT_SECRET: 'WORKOS_API_KEY',
+      COGNITO_REDIRECT_URI: 'WORKOS_REDIRECT_URI',
+      COGNITO_USER_POOL_ID: null,
+      COGNITO_REGION: null,
+      COGNITO_DOMAIN: null,
+    },
+    guidance: ['Replace Cognito hosted UI redirects and token handling with AuthKit'],
+  },
+  keycloak: {
+    name: 'keycloak',
+    displayName: 'Keycloak',
+    envMapping: {
+      KEYCLOAK_CLIENT_ID: 'WORKOS_CLIENT_ID',
+      KEYCLOAK_CLIENT_SECRET: 'WORKOS_API_KEY',
+      KEYCLOAK_REDIRECT_URI: 'WORKOS_REDIRECT_URI',
+      KEYCLOAK_BASE_URL: null,
+      KEYCLOAK_REALM: null,
+    },
+    guidance: ['Replace the Keycloak OIDC client with AuthKit; realm roles map to AuthKit roles'],
+  },
+  'azure-ad': {
+    name: 'azure-ad',
+    displayName: 'Microsoft Entra ID (Azure AD)',
+    envMapping: {
+      AZURE_CLIENT_ID: 'WORKOS_CLIENT_ID',
+      AZURE_CLIENT_SECRET: 'WORKOS_API_KEY',
+      AZURE_REDIRECT_URI: 'WORKOS_REDIRECT_URI',
+      AZURE_TENANT_ID: null,
+    },
+    guidance: ['Replace the direct Entra ID integration with AuthKit; customer tenants connect via WorkOS SSO'],
+  },
+  oidc: {
+    name: 'oidc',
+    displayName: 'Generic OpenID Connect',
+    envMapping: {},
+    guidance: [
+      'Replace the OIDC client configuration with AuthKit; the issuer/discovery settings are no longer needed',
+      'If an existing identity provider must stay in use for some customers, connect it to WorkOS as an SSO connection',
+    ],
+  },
+  clerk: {
+    name: 'clerk',
+    displayName: 'Clerk',
+    envMapping: {
+      CLERK_SECRET_KEY: 'WORKOS_API_KEY',
+      CLERK_PUBLISHABLE_KEY: 'WORKOS_CLIENT_ID',
+      NEXT_PUBLIC_CLERK_PUBLISHABLE_KEY: 'WORKOS_CLIENT_ID',
+      CLERK_WEBHOOK_SECRET: null,
+      NEXT_PUBLIC_CLERK_SIGN_IN_URL: null,
+      NEXT_PUBLIC_CLERK_SIGN_UP_URL: null,
+    },
+    guidance: [
+      'Replace @clerk/nextjs with @workos-inc/authkit-nextjs: <ClerkProvider> becomes <AuthKitProvider>, clerkMiddleware() becomes authkitMiddleware()',
+      'Add the AuthKit callback route (handleAuth()) at the path in WORKOS_REDIRECT_URI and set WORKOS_COOKIE_PASSWORD',
+      'Prebuilt components (<UserButton>, <SignIn>, <SignedIn>, ...) have no drop-in equivalent; rebuild them with withAuth()/useAuth(), getSignInUrl() and signOut()',
+      'auth() and currentUser() return a different shape than withAuth(): userId → user.id, orgId → organizationId',
+    ],
+  },
+  supabase: {
+    name: 'supabase',
+    displayName: 'Supabase Auth',
+    // SUPABASE_URL and the anon/service keys still configure the database client
+    envMapping: {},
+    guidance: [
+      'Migrate only the auth portion: supabase.auth.* sign-in, sign-out, session and callback handling move to AuthKit',
+      'Keep the Supabase client, its env vars and all database, storage, RPC and realtime calls unchanged',
+      'If row level security policies use auth.uid() or auth.jwt(), configure WorkOS as a third-party auth provider in Supabase and pass the AuthKit access token to the Supabase client',
+      'Supabase user IDs differ from WorkOS user IDs; tables keyed by the Supabase user ID need a mapping or backfill',
+    ],
+  },
+  socialite: {
+    name: 'socialite',
+    displayName: 'Laravel Socialite (social login)',
+    envMapping: {},
+    guidance: [
+      'Replace Socialite redirect/callback routes with the AuthKit flow from workos/workos-php-laravel',
+      'Social providers (Google, GitHub, ...) are configured in the WorkOS dashboard instead of config/services.php',
+    ],
+  },
+};
+
+/** Well-known registration/driver names and the provider they refer to */
+const PROVIDER_ALIASES: Record<string, string> = {
+  auth0: 'auth0',
+  okta: 'okta',
+  cognito: 'cognito',
+  keycloak: 'keycloak',
+  azure: 'azure-ad',
+  'azure-ad': 'azure-ad',
+  entra: 'azure-ad',
+};
+
+/**
+ * Provider named by a client registration or driver id (e.g. Socialite driver, Spring registration).
+ */
+export function providerFromName(name: string): string | undefined {
+  return PROVIDER_ALIASES[name.toLowerCase()];
+}
+
+/**
+ * Provider implied by an OIDC issuer URL.
+ */
+export function providerFromIssuer(issuer: string): string | undefined {
+  let host: string;
+  let pathname: string;
+  try {
+    ({ host, pathname } = new URL(issuer));
+  } catch {
+    return undefined;
+  }
+  if (host.endsWith('.auth0.com')) return 'auth0';
+  if (host.endsWith('.okta.com') || host.endsWith('.oktapreview.com')) return 'okta';
+  if (host.startsWith('cognito-idp.')) return 'cognito';
+  if (host === 'login.microsoftonline.com' || host.endsWith('.ciamlogin.com')) return 'azure-ad';
+  if (pathname.includes('/realms/')) return 'keycloak';
+  return undefined;
+}
+
+/** Values sample configs use instead of a real client ID */
+const PLACEHOLDER_PATTERN = /^(your|my|example|sample|changeme|replace|todo|xxx)|^<.*>$/i;
+
+/**
+ * Whether a literal looks like a real OAuth client ID rather than an empty
+ * value or a placeholder: one token of 12+ characters mixing letters and digits.
+ */
+export function looksLikeClientId(value: string): boolean {
+  return (
+    /^[A-Za-z0-9][A-Za-z0-9._-]{11,127}$/.test(value) &&
+    /\d/.test(value) &&
+    /[A-Za-z]/.test(value) &&
+    !PLACEHOLDER_PATTERN.test(value)
+  );
+}
+
+export function getProvider(name: string): MigrationProvider | undefined {
+  return PROVIDERS[name.toLowerCase()];
+}
+
+export function providerNames(): string[] {
+  return Object.keys(PROVIDERS);
+}
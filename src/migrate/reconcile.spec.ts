@@ -0,0 +1,137 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { buildMigrationPrompt } from './prompt.js';
+import { hasWorkLeft, planChanged, reconcilePlan, reconciliationLines } from './reconcile.js';
+import { snapshotPlan, type MigrationState } from './selection.js';
+import type { MigrationFinding, MigrationStep } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+function finding(file: string): MigrationFinding {
+  return makeFinding({ provider: 'clerk', file });
+}
+
+describe('reconcilePlan', () => {
+  let root: string;
+
+  function write(file: string, content: string) {
+    writeFileSync(join(root, file), content);
+  }
+
+  /** State as a cancelled run leaves it, hashed after the run's own changes */
+  function stoppedRun(steps: MigrationStep[], findings: MigrationFinding[]): MigrationState {
+    return { provider: 'clerk', excludedFiles: [], steps, plan: snapshotPlan(root, findings, steps), updatedAt: '' };
+  }
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'reconcile-test-'));
+    for (const file of ['auth.ts', 'routes.ts', 'admin.ts', 'api.ts']) write(file, `// ${file}\n`);
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  it('resumes the same plan when nothing changed', () => {
+    const steps: MigrationStep[] = [
+      { file: 'auth.ts', status: 'succeeded' },
+      { file: 'routes.ts', status: 'skipped', reason: 'not reached before the run was cancelled' },
+    ];
+    const state = stoppedRun(steps, [finding('auth.ts'), finding('routes.ts')]);
+
+    const reconciled = reconcilePlan(root, state, [finding('routes.ts')], []);
+
+    expect(reconciled).toMatchObject({ applied: ['auth.ts'], remaining: ['routes.ts'], added: [], removed: [] });
+    expect(reconciled.steps).toEqual(steps);
+    expect(planChanged(reconciled)).toBe(false);
+  });
+
+  it('adds new files, drops finished ones and marks edited ones', () => {
+    const state = stoppedRun(
+      [
+        { file: 'auth.ts', status: 'succeeded' },
+        { file: 'routes.ts', status: 'failed', reason: 'String not found' },
+        { file: 'admin.ts', status: 'skipped', reason: 'not reached before the run was cancelled' },
+      ],
+      [finding('auth.ts'), finding('routes.ts'), finding('admin.ts')],
+    );
+    // In the meantime: a tweak to a migrated file, admin.ts migrated by hand, and new Clerk code in api.ts
+    write('auth.ts', '// auth.ts, with a tweak\n');
+    write('admin.ts', '// admin.ts, on AuthKit\n');
+
+    const reconciled = reconcilePlan(root, state, [finding('routes.ts'), finding('api.ts')], []);
+
+    expect(reconciled).toMatchObject({
+      applied: ['auth.ts'],
+      remaining: ['routes.ts'],
+      added: ['api.ts'],
+      removed: ['admin.ts'],
+      edited: ['admin.ts', 'auth.ts'],
+    });
+    expect(reconciled.steps).toEqual([
+      { file: 'auth.ts', status: 'succeeded', reason: 'edited since the last run' },
+      { file: 'routes.ts', status: 'failed', reason: 'String not found' },
+      { file: 'api.ts', status: 'skipped', reason: 'new since the last run' },
+    ]);
+    expect(planChanged(reconciled)).toBe(true);
+    expect(reconciliationLines(reconciled)).toEqual([
+      'Already applied (1):',
+      '  auth.ts',
+      'Left to finish (1):',
+      '  routes.ts',
+      'New since the last run (1):',
+      '  api.ts',
+      'No longer need migrating (1):',
+      '  admin.ts',
+      'Edited since the last run (2):',
+      '  admin.ts',
+      '  auth.ts',
+    ]);
+
+    const prompt = buildMigrationPrompt({
+      provider: 'clerk',
+      displayName: 'Clerk',
+      forced: false,
+      confidence: 0.8,
+      envMapping: {},
+      guidance: [],
+      findings: [finding('routes.ts'), finding('api.ts')],
+      previousSteps: reconciled.steps,
+    });
+    expect(prompt).toContain('- api.ts (new since the last run)');
+    expect(prompt).toContain('- auth.ts (edited since the last run)');
+    expect(prompt).toContain('Keep their changes.');
+  });
+
+  it('has nothing left once the rest was migrated by hand', () => {
+    const state = stoppedRun(
+      [
+        { file: 'auth.ts', status: 'succeeded' },
+        { file: 'routes.ts', status: 'failed', reason: 'String not found' },
+      ],
+      [finding('auth.ts'), finding('routes.ts')],
+    );
+
+    const reconciled = reconcilePlan(root, state, [], []);
+
+    expect(reconciled.removed).toEqual(['routes.ts']);
+    expect(hasWorkLeft(reconciled)).toBe(false);
+  });
+
+  it('leaves out excluded files and reads state saved without a plan', () => {
+    const state: MigrationState = {
+      provider: 'clerk',
+      excludedFiles: [],
+      steps: [
+        { file: 'auth.ts', status: 'succeeded' },
+        { file: 'routes.ts', status: 'failed' },
+      ],
+      updatedAt: '',
+    };
+
+    const reconciled = reconcilePlan(root, state, [finding('routes.ts'), finding('api.ts')], ['api.ts']);
+
+    expect(reconciled).toMatchObject({ applied: ['auth.ts'], remaining: ['routes.ts'], added: [], edited: [] });
+  });
+});
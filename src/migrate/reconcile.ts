@@ -0,0 +1,100 @@
+/**
+ * Reconcile a partly applied or cancelled migration with the project before
+ * resuming it.
+ *
+ * Between the run that stopped and the rerun, the user may have migrated a
+ * file by hand, added code for the old provider, or edited a file the run
+ * already changed. Detection runs again, and its plan is compared with the
+ * one saved with the run's steps: files the run changed stay applied, files
+ * it left that still have findings stay to do, new files are added, and
+ * files that no longer have findings are dropped. Files edited since the
+ * run stopped are pointed out so the agent keeps the edits.
+ */
+
+import { migrationFiles, projectFileHash, type MigrationState } from './selection.js';
+import type { MigrationFinding, MigrationStep } from './types.js';
+
+export const EDITED_SINCE = 'edited since the last run';
+
+export interface PlanReconciliation {
+  /** Files the earlier run changed */
+  applied: string[];
+  /** Files the earlier run left that still have findings */
+  remaining: string[];
+  /** Files with findings the earlier plan didn't have */
+  added: string[];
+  /** Files the earlier run left that no longer have findings */
+  removed: string[];
+  /** Files of the earlier plan whose contents changed after it stopped */
+  edited: string[];
+  /** Steps to resume with: the applied and remaining files, then the added ones */
+  steps: MigrationStep[];
+}
+
+/**
+ * Compare the saved plan of an earlier run with this run's findings. State
+ * saved before plans were kept has its steps as the plan, without hashes.
+ */
+export function reconcilePlan(
+  root: string,
+  state: MigrationState,
+  findings: MigrationFinding[],
+  excludedFiles: string[],
+): PlanReconciliation {
+  const included = (file: string) => !excludedFiles.includes(file);
+  const current = new Set(migrationFiles(findings).map(({ file }) => file));
+  const steps = (state.steps ?? []).filter((step) => included(step.file));
+  const plan = state.plan ?? steps.map(({ file }) => ({ file, findings: 0, hash: null }));
+  const planned = new Set([...plan.map(({ file }) => file), ...steps.map(({ file }) => file)]);
+  const edited = plan
+    .filter(({ file, hash }) => included(file) && hash !== null && projectFileHash(root, file) !== hash)
+    .map(({ file }) => file);
+
+  const withEdits = (step: MigrationStep): MigrationStep => {
+    if (!edited.includes(step.file)) return step;
+    if (step.status === 'succeeded') return { file: step.file, status: 'succeeded', reason: EDITED_SINCE };
+    return { ...step, reason: step.reason ? `${step.reason}; ${EDITED_SINCE}` : EDITED_SINCE };
+  };
+  const applied = steps.filter((step) => step.status === 'succeeded');
+  const unfinished = steps.filter((step) => step.status !== 'succeeded');
+  const remaining = unfinished.filter((step) => current.has(step.file));
+  const added = [...current].filter((file) => included(file) && !planned.has(file)).sort();
+
+  return {
+    applied: applied.map(({ file }) => file),
+    remaining: remaining.map(({ file }) => file),
+    added,
+    removed: unfinished.filter((step) => !current.has(step.file)).map(({ file }) => file),
+    edited,
+    steps: [
+      ...[...applied, ...remaining].map(withEdits),
+      ...added.map((file): MigrationStep => ({ file, status: 'skipped', reason: 'new since the last run' })),
+    ],
+  };
+}
+
+/** Whether the project changed in a way that changes what the resumed run does */
+export function planChanged(reconciled: PlanReconciliation): boolean {
+  return reconciled.added.length > 0 || reconciled.removed.length > 0 || reconciled.edited.length > 0;
+}
+
+/** Whether the resumed run has anything left to do */
+export function hasWorkLeft(reconciled: PlanReconciliation): boolean {
+  return reconciled.remaining.length > 0 || reconciled.added.length > 0;
+}
+
+const SECTIONS: Array<[keyof Omit<PlanReconciliation, 'steps'>, string]> = [
+  ['applied', 'Already applied'],
+  ['remaining', 'Left to finish'],
+  ['added', 'New since the last run'],
+  ['removed', 'No longer need migrating'],
+  ['edited', 'Edited since the last run'],
+];
+
+/** The reconciled plan grouped by what became of each file, one file per line */
+export function reconciliationLines(reconciled: PlanReconciliation): string[] {
+  return SECTIONS.filter(([key]) => reconciled[key].length > 0).flatMap(([key, label]) => [
+    `${label} (${reconciled[key].length}):`,
+    ...reconciled[key].map((file) => `  ${file}`),
+  ]);
+}
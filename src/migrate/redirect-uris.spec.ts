@@ -0,0 +1,61 @@
+import { describe, it, expect, beforeEach, vi } from 'vitest';
+import type { MigrationFinding } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+vi.mock('../lib/workos-api.js', () => ({ workosRequest: vi.fn() }));
+
+const { workosRequest } = await import('../lib/workos-api.js');
+const mockRequest = vi.mocked(workosRequest);
+const { extractRedirectUris, listRegisteredRedirectUris, markRegistered } = await import('./redirect-uris.js');
+
+function page(uris: string[], after: string | null = null) {
+  return { data: uris.map((uri) => ({ uri })), list_metadata: { before: null, after } };
+}
+
+function finding(file: string, line: number, redirectUri?: unknown): MigrationFinding {
+  return makeFinding({ message: 'setting', file, line, confidence: 0.1, details: { redirectUri } });
+}
+
+describe('redirect-uris', () => {
+  beforeEach(() => {
+    mockRequest.mockReset();
+  });
+
+  it('collects callback URLs from findings once each', () => {
+    const uris = extractRedirectUris([
+      finding('main.go', 34, 'http://localhost:3000/callback'),
+      finding('auth.go', 12, 'http://localhost:3000/callback/'),
+      finding('application.yml', 8, 'https://app.example.com/login/oauth2/code/auth0'),
+      finding('application.yml', 9, '{baseUrl}/login/oauth2/code/{registrationId}'),
+      finding('auth.go', 20),
+    ]);
+
+    expect(uris).toEqual([
+      { uri: 'http://localhost:3000/callback', file: 'main.go', line: 34 },
+      { uri: 'https://app.example.com/login/oauth2/code/auth0', file: 'application.yml', line: 8 },
+    ]);
+  });
+
+  it('marks URIs the WorkOS app already has', () => {
+    const uris = [
+      { uri: 'http://localhost:3000/callback', file: 'main.go' },
+      { uri: 'https://app.example.com/callback', file: 'main.go' },
+    ];
+
+    expect(markRegistered(uris, ['http://localhost:3000/callback/']).map((u) => u.registered)).toEqual([true, false]);
+  });
+
+  it('lists registered redirect URIs across pages', async () => {
+    mockRequest
+      .mockResolvedValueOnce(page(['http://localhost:3000/callback'], 'a'))
+      .mockResolvedValueOnce(page(['https://app.example.com/callback']));
+
+    expect(await listRegisteredRedirectUris('sk_test')).toEqual([
+      'http://localhost:3000/callback',
+      'https://app.example.com/callback',
+    ]);
+    expect(mockRequest).toHaveBeenCalledWith(
+      expect.objectContaining({ method: 'GET', path: '/user_management/redirect_uris' }),
+    );
+  });
+});
@@ -0,0 +1,44 @@
+/**
+ * Redirect URIs a migrated app calls back on.
+ *
+ * Detectors record the callback URL the old provider was configured with
+ * (`details.redirectUri`). The same URL has to be registered with the WorkOS
+ * app before the first login after the migration, so it's listed in the
+ * summary and, when an API key is available, checked against the dashboard.
+ */
+
+import { paginate } from '../lib/pagination.js';
+import type { MigrationFinding, RedirectUri } from './types.js';
+
+/** Trailing slashes don't make a different URI for the comparison */
+function normalize(uri: string): string {
+  return uri.replace(/\/+$/, '');
+}
+
+/** Callback URLs found in the old provider's configuration, first location kept */
+export function extractRedirectUris(findings: MigrationFinding[]): RedirectUri[] {
+  const uris = new Map<string, RedirectUri>();
+  for (const finding of findings) {
+    const uri = finding.details?.redirectUri;
+    if (typeof uri !== 'string' || !/^https?:\/\/[^\s{}]+$/.test(uri)) continue;
+    if (!uris.has(normalize(uri))) {
+      uris.set(normalize(uri), { uri, file: finding.file, line: finding.line });
+    }
+  }
+  return [...uris.values()];
+}
+
+/** Every redirect URI registered with the WorkOS app */
+export async function listRegisteredRedirectUris(apiKey: string, baseUrl?: string): Promise<string[]> {
+  const uris: string[] = [];
+  for await (const page of paginate<{ uri: string }>({ path: '/user_management/redirect_uris', apiKey, baseUrl })) {
+    uris.push(...page.map((entry) => entry.uri));
+  }
+  return uris;
+}
+
+/** Mark each URI as registered or not */
+export function markRegistered(uris: RedirectUri[], registered: string[]): RedirectUri[] {
+  const known = new Set(registered.map(normalize));
+  return uris.map((entry) => ({ ...entry, registered: known.has(normalize(entry.uri)) }));
+}
@@ -0,0 +1,74 @@
+/**
+ * Session refresh for apps that kept users signed in with refresh tokens.
+ *
+ * Apps that requested `offline_access` and refreshed tokens themselves
+ * (a TokenSource, a refresh_token grant, a refresh token kept in a cookie
+ * or a table) need the same with AuthKit: every AuthKit authentication
+ * returns a refresh token, which authenticateWithRefreshToken trades for a
+ * new access token and a new refresh token. The default migration only
+ * signs users in, so this is listed in the plan and the prompt; on Gin the
+ * session helper also gets a refresh function (see gin-sessions.ts). The
+ * uses come from the refresh-tokens detector (`details.refreshTokens`).
+ */
+
+import type { MigrationFinding, RefreshTokenStorage, RefreshTokenUse } from './types.js';
+
+const KINDS = new Set(['scope', 'token-source', 'grant', 'stored']);
+const STORAGES = new Set(['cookie', 'session', 'database']);
+
+/** Refresh-token handling across the findings, one per kind, storage and file, first location kept */
+export function extractRefreshTokenUses(findings: MigrationFinding[]): RefreshTokenUse[] {
+  const uses = new Map<string, RefreshTokenUse>();
+  for (const finding of findings) {
+    const { refreshTokens, storage } = finding.details ?? {};
+    if (typeof refreshTokens !== 'string' || !KINDS.has(refreshTokens)) continue;
+    const stored = typeof storage === 'string' && STORAGES.has(storage) ? (storage as RefreshTokenStorage) : undefined;
+    const key = `${refreshTokens}:${stored ?? ''}:${finding.file}`;
+    if (uses.has(key)) continue;
+    uses.set(key, {
+      kind: refreshTokens as RefreshTokenUse['kind'],
+      ...(stored && { storage: stored }),
+      file: finding.file,
+      ...(finding.line !== undefined && { line: finding.line }),
+    });
+  }
+  return [...uses.values()];
+}
+
+/** Whether the app refreshes sessions rather than only asking for offline access */
+export function refreshesSessions(uses: RefreshTokenUse[]): boolean {
+  return uses.some((use) => use.kind !== 'scope');
+}
+
+/** What changes, in order */
+export const SESSION_REFRESH_STEPS = [
+  'Drop offline_access from the requested scopes: every AuthKit authentication returns a refresh token',
+  'When the access token in the session has expired, call authenticateWithRefreshToken (usermanagement.AuthenticateWithRefreshToken in Go) with the refresh token from the session; the AuthKit SDKs that manage sessions (withAuth, authkitMiddleware, loadSealedSession().refresh()) already do this',
+  'Store the new refresh token where the old code stored its refresh token: WorkOS rotates refresh tokens, so the previous one stops working after each refresh',
+  "Remove the old provider's refresh calls and its stored refresh tokens; users sign in again once after the migration",
+];
+
+const STORED_LINES: Record<RefreshTokenStorage, string> = {
+  cookie: 'stores refresh tokens in a cookie → keep them in the sealed AuthKit session, resealed on each refresh',
+  session: "stores refresh tokens in the session → keep AuthKit's refresh token there instead",
+  database: "stores refresh tokens in the database → store AuthKit's refresh token there and clear the old ones",
+};
+
+function describeUse(use: RefreshTokenUse): string {
+  const location = use.line ? `${use.file}:${use.line}` : use.file;
+  switch (use.kind) {
+    case 'scope':
+      return `${location}: requests offline_access → drop it; AuthKit always returns a refresh token`;
+    case 'token-source':
+      return `${location}: an OAuth client refreshes tokens on its own → refresh the AuthKit session instead`;
+    case 'grant':
+      return `${location}: refresh_token grant to the old provider → authenticateWithRefreshToken`;
+    case 'stored':
+      return `${location}: ${STORED_LINES[use.storage ?? 'database']}`;
+  }
+}
+
+/** One line per use, for the prompt and the plan */
+export function refreshTokenLines(uses: RefreshTokenUse[]): string[] {
+  return uses.map(describeUse);
+}
@@ -0,0 +1,155 @@
+import { afterEach, beforeEach, describe, expect, it } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { loadIgnoreRules } from './ignore-rules.js';
+import {
+  createScanContext,
+  isBinaryContent,
+  parseFileList,
+  parseFileSize,
+  readPackageManifests,
+  resolveListedFiles,
+} from './scan.js';
+
+describe('scan', () => {
+  describe('parseFileSize', () => {
+    it('reads byte counts and kb/mb/gb sizes', () => {
+      expect(parseFileSize('1048576')).toBe(1048576);
+      expect(parseFileSize('500kb')).toBe(500 * 1024);
+      expect(parseFileSize('2MB')).toBe(2 * 1024 * 1024);
+      expect(parseFileSize('1.5 mb')).toBe(1.5 * 1024 * 1024);
+    });
+
+    it('rejects anything else', () => {
+      expect(parseFileSize('big')).toBeNull();
+      expect(parseFileSize('-1')).toBeNull();
+      expect(parseFileSize('2tb')).toBeNull();
+    });
+  });
+
+  describe('isBinaryContent', () => {
+    it('treats a null byte as binary', () => {
+      expect(isBinaryContent(Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x00, 0x01]))).toBe(true);
+      expect(isBinaryContent(Buffer.from('const auth = "clerk";\n'))).toBe(false);
+    });
+
+    it('only looks at the start of the file', () => {
+      const late = Buffer.concat([Buffer.alloc(10000, 'a'), Buffer.from([0])]);
+      expect(isBinaryContent(late)).toBe(false);
+    });
+  });
+
+  describe('createScanContext', () => {
+    let root: string;
+
+    beforeEach(() => {
+      root = mkdtempSync(join(tmpdir(), 'scan-'));
+    });
+
+    afterEach(() => {
+      rmSync(root, { recursive: true, force: true });
+    });
+
+    it('skips files over the size limit and binary files, and counts them', async () => {
+      writeFileSync(join(root, 'app.ts'), 'import { ClerkProvider } from "@clerk/nextjs";');
+      writeFileSync(join(root, 'bundle.js'), 'x'.repeat(2048));
+      writeFileSync(join(root, 'logo.png'), Buffer.from([0x89, 0x50, 0x4e, 0x47, 0x00, 0x00]));
+      const ctx = createScanContext(root, { maxFileSize: 1024 });
+
+      expect(await ctx.readFile('app.ts')).toContain('ClerkProvider');
+      expect(await ctx.readFile('bundle.js')).toBeNull();
+      expect(await ctx.readFile('logo.png')).toBeNull();
+      // Cached reads aren't counted twice
+      await ctx.readFile('bundle.js');
+      expect(ctx.skipped()).toEqual({ size: ['bundle.js'], binary: ['logo.png'] });
+    });
+
+    it('reads missing files as null without counting them', async () => {
+      const ctx = createScanContext(root);
+
+      expect(await ctx.readFile('missing.ts')).toBeNull();
+      expect(ctx.skipped()).toEqual({ size: [], binary: [] });
+    });
+
+    it('leaves out what .workosignore excludes, on top of .gitignore', async () => {
+      mkdirSync(join(root, 'third_party/lib'), { recursive: true });
+      mkdirSync(join(root, 'generated'), { recursive: true });
+      writeFileSync(join(root, '.gitignore'), 'generated/\n');
+      writeFileSync(join(root, '.workosignore'), '# committed, not ours\nthird_party/\n!generated/auth.ts\n');
+      writeFileSync(join(root, 'app.ts'), '');
+      writeFileSync(join(root, 'third_party/lib/auth.js'), '');
+      writeFileSync(join(root, 'generated/auth.ts'), '');
+      writeFileSync(join(root, 'generated/schema.ts'), '');
+      const ctx = createScanContext(root, { ignoreRules: loadIgnoreRules(root) });
+
+      expect((await ctx.files()).filter((f) => !f.startsWith('.'))).toEqual(['app.ts', 'generated/auth.ts']);
+      expect(ctx.ignored()).toEqual([
+        { file: 'generated/schema.ts', rule: { source: '.gitignore', line: 1, pattern: 'generated/' } },
+        { file: 'third_party/lib/auth.js', rule: { source: '.workosignore', line: 2, pattern: 'third_party/' } },
+      ]);
+    });
+  });
+
+  describe('readPackageManifests', () => {
+    let root: string;
+
+    beforeEach(() => {
+      root = mkdtempSync(join(tmpdir(), 'scan-manifests-'));
+      mkdirSync(join(root, 'apps/web'), { recursive: true });
+      mkdirSync(join(root, 'apps/broken'), { recursive: true });
+      writeFileSync(join(root, 'package.json'), '{"devDependencies": {"@clerk/testing": "^1.0.0"}}');
+      writeFileSync(join(root, 'apps/web/package.json'), '{"dependencies": {"@clerk/nextjs": "^6.0.0"}}');
+      writeFileSync(join(root, 'apps/broken/package.json'), '{"dependencies": ');
+    });
+
+    afterEach(() => {
+      rmSync(root, { recursive: true, force: true });
+    });
+
+    it('merges dependencies and devDependencies and skips manifests that do not parse', async () => {
+      const manifests = await readPackageManifests(createScanContext(root));
+
+      expect(manifests.map(({ file, deps }) => ({ file, deps }))).toEqual([
+        { file: 'package.json', deps: { '@clerk/testing': '^1.0.0' } },
+        { file: 'apps/web/package.json', deps: { '@clerk/nextjs': '^6.0.0' } },
+      ]);
+    });
+
+    it('reads the root manifest when an incremental scan does not list it', async () => {
+      const manifests = await readPackageManifests(createScanContext(root, { only: ['apps/web/package.json'] }));
+
+      expect(manifests.map((m) => m.file)).toEqual(['package.json', 'apps/web/package.json']);
+    });
+  });
+
+  describe('parseFileList', () => {
+    it('splits on NUL when there is one, on newlines otherwise', () => {
+      expect(parseFileList('a.ts\0dir/b c.ts\0')).toEqual(['a.ts', 'dir/b c.ts']);
+      expect(parseFileList('a.ts\r\n\nb.ts\n')).toEqual(['a.ts', 'b.ts']);
+    });
+  });
+
+  describe('resolveListedFiles', () => {
+    let repo: string;
+
+    beforeEach(() => {
+      repo = mkdtempSync(join(tmpdir(), 'scan-list-'));
+      mkdirSync(join(repo, '.git'));
+      mkdirSync(join(repo, 'app/src'), { recursive: true });
+      writeFileSync(join(repo, 'app/src/auth.ts'), 'auth');
+      writeFileSync(join(repo, 'other.ts'), 'other');
+    });
+
+    afterEach(() => {
+      rmSync(repo, { recursive: true, force: true });
+    });
+
+    it('reads paths from the repository root and keeps existing files under the project', () => {
+      const root = join(repo, 'app');
+      const listed = ['app/src/auth.ts', join(repo, 'app/src/auth.ts'), 'other.ts', 'app/src', 'app/gone.ts'];
+
+      expect(resolveListedFiles(root, listed)).toEqual(['src/auth.ts']);
+    });
+  });
+});
@@ -0,0 +1,245 @@
+import { statSync } from 'node:fs';
+import { readFile, stat } from 'node:fs/promises';
+import { isAbsolute, join, relative, resolve, sep } from 'node:path';
+import fg from 'fast-glob';
+import { IGNORE_PATTERNS } from '../lib/constants.js';
+import { patchRoot } from '../lib/diff-only.js';
+import { BACKUP_GLOB, isBackupFile } from '../lib/file-backups.js';
+import { execFileNoThrow } from '../utils/exec-file.js';
+import { matchIgnoreRules, type IgnoreRule } from './ignore-rules.js';
+import type { OidcDiscoveryClient } from './oidc-discovery.js';
+import type { IgnoredPath, ScanContext } from './types.js';
+
+/** Files larger than this are skipped unless --max-file-size says otherwise */
+export const DEFAULT_MAX_FILE_SIZE = 2 * 1024 * 1024;
+
+/** How much of a file is checked for a null byte, the same amount git looks at */
+const BINARY_SNIFF_BYTES = 8000;
+
+const SIZE_UNITS: Record<string, number> = { '': 1, b: 1, kb: 1024, mb: 1024 ** 2, gb: 1024 ** 3 };
+
+/** `500kb`, `2MB`, `1048576`: a size in bytes, or null if it isn't one */
+export function parseFileSize(value: string): number | null {
+  const match = /^\s*(\d+(?:\.\d+)?)\s*([kmg]?b)?\s*$/i.exec(value);
+  if (!match) return null;
+  return Math.floor(Number(match[1]) * SIZE_UNITS[(match[2] ?? '').toLowerCase()]);
+}
+
+/** Whether content looks binary: a null byte early on, which text files never have */
+export function isBinaryContent(buffer: Buffer): boolean {
+  return buffer.subarray(0, BINARY_SNIFF_BYTES).includes(0);
+}
+
+/** Directories that never contain first-party auth code */
+// --keep-backups copies hold the old auth code, which must not be detected again
+const SCAN_IGNORE_PATTERNS = [
+  ...IGNORE_PATTERNS,
+  '**/.git/**',
+  '**/vendor/**',
+  '**/coverage/**',
+  '**/.turbo/**',
+  BACKUP_GLOB,
+];
+
+export interface ScanOptions {
+  /** Scan exactly these files (relative to root) instead of walking the tree */
+  files?: string[];
+  /** List only these files (relative to root), e.g. the ones changed in a PR */
+  only?: string[];
+  /** Globs the walk is restricted to; everything when empty */
+  include?: string[];
+  /** Globs dropped from what include selected */
+  exclude?: string[];
+  /** .gitignore and .workosignore rules, applied to the walk and to listed files */
+  ignoreRules?: IgnoreRule[];
+  /** Lets detectors check whether the issuers they find are live */
+  discovery?: OidcDiscoveryClient;
+  /** Files above this many bytes read as missing; defaults to DEFAULT_MAX_FILE_SIZE */
+  maxFileSize?: number;
+}
+
+/** `./src/**` and `src/**` mean the same to the walker */
+function normalizeGlob(pattern: string): string {
+  return pattern.trim().replace(/^\.\//, '');
+}
+
+/**
+ * Scan context over a project. The walk starts from the include globs (or
+ * everything), then drops the built-in ignores, the exclude globs and what
+ * the ignore files' rules exclude, so an exclude always wins over an
+ * include. Only the listing is restricted: detectors can still read
+ * manifests outside it for context.
+ *
+ * Files over the size limit and binary files read as null, so no detector
+ * runs its text patterns over a bundle or an image; each skip is counted.
+ */
+export function createScanContext(root: string, options: ScanOptions = {}): ScanContext {
+  let filesPromise: Promise<string[]> | null = null;
+  const cache = new Map<string, Promise<string | null>>();
+  const only = options.only ? new Set(options.only) : null;
+  const include = (options.include ?? []).map(normalizeGlob).filter(Boolean);
+  const ignore = [...SCAN_IGNORE_PATTERNS, ...(options.exclude ?? []).map(normalizeGlob).filter(Boolean)];
+  const maxFileSize = options.maxFileSize ?? DEFAULT_MAX_FILE_SIZE;
+  const skipped = { size: [] as string[], binary: [] as string[] };
+  const ignored: IgnoredPath[] = [];
+
+  const notIgnored = (files: string[]): string[] => {
+    if (!options.ignoreRules?.length) return files;
+    return files.filter((file) => {
+      const rule = matchIgnoreRules(options.ignoreRules!, file);
+      if (rule) ignored.push({ file, rule: { source: rule.source, line: rule.line, pattern: rule.pattern } });
+      return !rule;
+    });
+  };
+
+  const load = async (relPath: string): Promise<string | null> => {
+    const path = join(root, relPath);
+    try {
+      if ((await stat(path)).size > maxFileSize) {
+        skipped.size.push(relPath);
+        return null;
+      }
+      const buffer = await readFile(path);
+      if (isBinaryContent(buffer)) {
+        skipped.binary.push(relPath);
+        return null;
+      }
+      return buffer.toString('utf-8');
+    } catch {
+      return null;
+    }
+  };
+
+  return {
+    root,
+    files() {
+      if (options.files) {
+        filesPromise ??= Promise.resolve(notIgnored(options.files.filter((f) => !isBackupFile(f)).sort()));
+        return filesPromise;
+      }
+      const patterns = include.length > 0 ? include : ['**/*'];
+      filesPromise ??= fg(patterns, { cwd: root, dot: true, onlyFiles: true, ignore }).then((files) =>
+        notIgnored(files.filter((f) => !only || only.has(f)).sort()),
+      );
+      return filesPromise;
+    },
+    readFile(relPath) {
+      let content = cache.get(relPath);
+      if (!content) {
+        content = load(relPath);
+        cache.set(relPath, content);
+      }
+      return content;
+    },
+    skipped() {
+      return { size: [...skipped.size].sort(), binary: [...skipped.binary].sort() };
+    },
+    ignored() {
+      return [...ignored];
+    },
+    ...(options.discovery && { resolveIssuer: (issuer: string) => options.discovery!.resolves(issuer) }),
+  };
+}
+
+/**
+ * Files added or modified since a git ref, relative to root: the working
+ * tree diffed against the merge base with the ref (so changes made on a
+ * base branch since don't count), plus untracked files. Deleted files are
+ * left out.
+ */
+export async function changedFilesSince(root: string, ref: string): Promise<string[]> {
+  const mergeBase = await execFileNoThrow('git', ['merge-base', ref, 'HEAD'], { cwd: root });
+  const base = mergeBase.status === 0 ? mergeBase.stdout.trim() : ref;
+  const diffArgs = ['diff', '--name-only', '--relative', '--diff-filter=d', '-z', base, '--'];
+  const diff = await execFileNoThrow('git', diffArgs, { cwd: root });
+  if (diff.status !== 0) {
+    throw new Error(`Could not diff against ${ref}: ${diff.stderr.trim() || 'git diff failed'}`);
+  }
+  const untracked = await execFileNoThrow('git', ['ls-files', '--others', '--exclude-standard', '-z'], { cwd: root });
+
+  const files = new Set([...diff.stdout.split('\0'), ...(untracked.status === 0 ? untracked.stdout.split('\0') : [])]);
+  files.delete('');
+  return [...files].sort();
+}
+
+/**
+ * A file list handed over by CI or a hook: NUL-delimited when it contains a
+ * NUL (`git diff -z`), one path per line otherwise. Blank entries are dropped.
+ */
+export function parseFileList(text: string): string[] {
+  return text.split(text.includes('\0') ? '\0' : /\r?\n/).filter((entry) => entry.trim() !== '');
+}
+
+/**
+ * Listed paths relative to root. Relative paths are read from the
+ * repository root, where `git diff --name-only` prints them from; absolute
+ * paths are taken as is. Paths outside root and anything that isn't a file
+ * (deleted since, or a directory) are dropped.
+ */
+export function resolveListedFiles(root: string, paths: string[]): string[] {
+  const base = patchRoot(root);
+  const files = new Set<string>();
+  for (const path of paths) {
+    const absolute = isAbsolute(path) ? path : resolve(base, path);
+    const rel = relative(root, absolute);
+    if (!rel || rel.startsWith('..') || isAbsolute(rel)) continue;
+    try {
+      if (statSync(absolute).isFile()) files.add(rel.split(sep).join('/'));
+    } catch {
+      // Listed but gone
+    }
+  }
+  return [...files].sort();
+}
+
+export interface LineMatch {
+  line: number;
+  text: string;
+  match: RegExpExecArray;
+}
+
+/**
+ * Find every line matching a pattern. Line numbers are 1-based.
+ */
+export function findLines(content: string, pattern: RegExp): LineMatch[] {
+  const results: LineMatch[] = [];
+  const flags = pattern.flags.replace('g', '');
+  const lines = content.split(/\r?\n/);
+
+  lines.forEach((text, index) => {
+    const match = new RegExp(pattern.source, flags).exec(text);
+    if (match) results.push({ line: index + 1, text: text.trim(), match });
+  });
+
+  return results;
+}
+
+export interface PackageManifest {
+  /** Path relative to the scan root */
+  file: string;
+  /** Unparsed content, for finding the line a dependency is declared on */
+  raw: string;
+  /** `dependencies` and `devDependencies` merged */
+  deps: Record<string, string>;
+}
+
+/**
+ * Every package.json in the scan that parses. The root manifest is read
+ * even when an incremental scan doesn't list it.
+ */
+export async function readPackageManifests(ctx: ScanContext): Promise<PackageManifest[]> {
+  const files = await ctx.files();
+  const manifests: PackageManifest[] = [];
+  for (const file of new Set(['package.json', ...files.filter((f) => f.endsWith('/package.json'))])) {
+    const raw = await ctx.readFile(file);
+    if (!raw) continue;
+    let pkg: { dependencies?: Record<string, string>; devDependencies?: Record<string, string> };
+    try {
+      pkg = JSON.parse(raw);
+    } catch {
+      continue;
+    }
+    manifests.push({ file, raw, deps: { ...pkg.dependencies, ...pkg.devDependencies } });
+  }
+  return manifests;
+}
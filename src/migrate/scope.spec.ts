@@ -0,0 +1,94 @@
+import { describe, it, expect } from 'vitest';
+import { buildRunSummary } from '../utils/run-summary.js';
+import { buildMigrationPrompt } from './prompt.js';
+import {
+  deferredCategories,
+  finishedCategories,
+  inScope,
+  isDependencyCommand,
+  migrationCategory,
+  parseOnly,
+} from './scope.js';
+import type { MigrationContext, MigrationFinding } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+function finding(file: string, manual = false): MigrationFinding {
+  return makeFinding({ provider: 'clerk', code: 'clerk-sdk', message: 'Clerk SDK usage', file, manual });
+}
+
+const context: MigrationContext = {
+  provider: 'clerk',
+  displayName: 'Clerk',
+  forced: false,
+  confidence: 0.8,
+  envMapping: { CLERK_SECRET_KEY: 'WORKOS_API_KEY' },
+  guidance: [],
+  findings: [finding('src/auth.ts'), finding('package.json', true), finding('.env.local'), finding('views/nav.html')],
+  containerEnvFiles: [{ file: 'docker-compose.yml', source: 'compose' }],
+  templateReferences: [{ file: 'views/nav.html', line: 3, kind: 'login', target: '/sign-in' }],
+};
+
+describe('parseOnly', () => {
+  it('reads repeated and comma-separated categories in order', () => {
+    expect(parseOnly(['deps,env', 'Code'])).toEqual(['code', 'env', 'deps']);
+  });
+
+  it('treats none or all of them as a full run', () => {
+    expect(parseOnly(undefined)).toBeUndefined();
+    expect(parseOnly(['code,env,deps,templates'])).toBeUndefined();
+  });
+
+  it('rejects an unknown category', () => {
+    expect(() => parseOnly(['code,docs'])).toThrow('Unknown --only category: docs');
+  });
+});
+
+describe('migrationCategory', () => {
+  it('sorts files into categories', () => {
+    expect(migrationCategory('src/auth.ts', context)).toBe('code');
+    expect(migrationCategory('api/go.mod', context)).toBe('deps');
+    expect(migrationCategory('pnpm-lock.yaml', context)).toBe('deps');
+    expect(migrationCategory('.env.production', context)).toBe('env');
+    expect(migrationCategory('docker-compose.yml', context)).toBe('env');
+    expect(migrationCategory('views/nav.html', context)).toBe('templates');
+  });
+
+  it('recognizes dependency commands', () => {
+    expect(isDependencyCommand('pnpm add @workos-inc/authkit-nextjs')).toBe(true);
+    expect(isDependencyCommand('go get github.com/workos/workos-go/v4')).toBe(true);
+    expect(isDependencyCommand('npm run build 2>&1 | tail -20')).toBe(false);
+  });
+});
+
+describe('a run limited with --only', () => {
+  const only = { ...context, only: parseOnly(['code']) };
+
+  it('keeps other categories out of the prompt and tells the agent to leave them', () => {
+    const prompt = buildMigrationPrompt(only);
+
+    expect(prompt).toContain('### Scope');
+    expect(prompt).toContain('- Dependencies: do not install, upgrade or remove packages');
+    expect(prompt).toContain('- src/auth.ts: Clerk SDK usage');
+    expect(prompt).not.toContain('- package.json:');
+    expect(prompt).not.toContain('### Templates');
+    expect(inScope('package.json', only)).toBe(false);
+  });
+
+  it('summarizes what it left for the next run', () => {
+    const summary = buildRunSummary({ success: true, changedFiles: ['src/auth.ts'], migration: only });
+
+    expect(summary.title).toBe('Migrated from Clerk to WorkOS AuthKit (code)');
+    expect(summary.deferred).toEqual(['env', 'deps', 'templates']);
+    expect(summary.nextSteps[0]).toBe('Run `workos migrate` again to apply the rest: env, deps, templates');
+    expect(summary.manualChanges).toEqual([]);
+    expect(summary.templateReferences).toEqual([]);
+  });
+
+  it('saves what is done until the last categories land', () => {
+    expect(finishedCategories({ only: ['code'] })).toEqual(['code']);
+    expect(finishedCategories({ only: ['env'], completedCategories: ['code'] })).toEqual(['code', 'env']);
+    expect(finishedCategories({ only: ['env', 'deps', 'templates'], completedCategories: ['code'] })).toBeUndefined();
+    expect(finishedCategories({})).toBeUndefined();
+    expect(deferredCategories(['env', 'deps', 'templates'], ['code'])).toEqual([]);
+  });
+});
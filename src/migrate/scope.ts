@@ -0,0 +1,118 @@
+/**
+ * Which kinds of change a migration run applies (`workos migrate --only`).
+ *
+ * A migration changes four kinds of file: the auth code, the env files and
+ * container config that set the provider's keys, the dependency manifests
+ * and lockfiles, and the templates that link to the old login. `--only`
+ * limits a run to some of them, so the code rewrite can go in one PR and
+ * the dependency and env changes in another. The categories a run finishes
+ * are saved with the migration state, and the next run without `--only`
+ * applies the rest.
+ */
+
+import { basename } from 'node:path';
+import type { MigrationContext } from './types.js';
+
+export type MigrationCategory = 'code' | 'env' | 'deps' | 'templates';
+
+export const MIGRATION_CATEGORIES: readonly MigrationCategory[] = ['code', 'env', 'deps', 'templates'];
+
+export const CATEGORY_LABELS: Record<MigrationCategory, string> = {
+  code: 'auth code',
+  env: 'env files and container config',
+  deps: 'dependencies',
+  templates: 'templates',
+};
+
+/** Manifests and lockfiles, across the ecosystems the migration supports */
+const DEPENDENCY_FILE_PATTERN = new RegExp(
+  [
+    '^package\\.json$',
+    '^(package-lock\\.json|npm-shrinkwrap\\.json|yarn\\.lock|pnpm-lock\\.yaml|bun\\.lockb?)$',
+    '^go\\.(mod|sum)$',
+    '^requirements[\\w.-]*\\.txt$',
+    '^(pyproject\\.toml|Pipfile(\\.lock)?|poetry\\.lock|uv\\.lock)$',
+    '^Gemfile(\\.lock)?$',
+    '^composer\\.(json|lock)$',
+    '^(pom\\.xml|build\\.gradle(\\.kts)?)$',
+    '^Cargo\\.(toml|lock)$',
+    '\\.csproj$',
+  ].join('|'),
+);
+
+/** Package-manager commands that add, remove or upgrade dependencies */
+const DEPENDENCY_COMMAND_PATTERN =
+  /(^|[;&|]\s*)(npm|pnpm|yarn|bun|pip3?|poetry|uv|bundle|gem|composer|cargo|dotnet|go)\s+(i|install|add|remove|rm|uninstall|upgrade|update|get|require|mod\s+tidy|package)\b/;
+
+/**
+ * Categories from `--only`, repeated or comma-separated.
+ * @returns undefined when every category is selected (or none were given)
+ */
+export function parseOnly(values: string[] | undefined): MigrationCategory[] | undefined {
+  const names = (values ?? []).flatMap((value) => value.split(',')).map((name) => name.trim().toLowerCase());
+  const selected = new Set<MigrationCategory>();
+  for (const name of names.filter(Boolean)) {
+    if (!MIGRATION_CATEGORIES.includes(name as MigrationCategory)) {
+      throw new Error(`Unknown --only category: ${name} (expected code, env, deps or templates)`);
+    }
+    selected.add(name as MigrationCategory);
+  }
+  return normalizeCategories([...selected]);
+}
+
+/** In canonical order, or undefined when that's all of them */
+export function normalizeCategories(categories: MigrationCategory[]): MigrationCategory[] | undefined {
+  const sorted = MIGRATION_CATEGORIES.filter((c) => categories.includes(c));
+  return sorted.length === 0 || sorted.length === MIGRATION_CATEGORIES.length ? undefined : sorted;
+}
+
+/** Categories not covered by earlier runs or this one */
+export function deferredCategories(
+  only: MigrationCategory[] | undefined,
+  completed: MigrationCategory[] = [],
+): MigrationCategory[] {
+  if (!only) return [];
+  return MIGRATION_CATEGORIES.filter((c) => !only.includes(c) && !completed.includes(c));
+}
+
+/**
+ * Categories to save as done once this run lands, or undefined when none
+ * are left (a full run, or the run that finished the rest).
+ */
+export function finishedCategories(
+  migration: Pick<MigrationContext, 'only' | 'completedCategories'>,
+): MigrationCategory[] | undefined {
+  if (!migration.only) return undefined;
+  const done = [...(migration.completedCategories ?? []), ...migration.only];
+  return deferredCategories(migration.only, migration.completedCategories).length > 0
+    ? MIGRATION_CATEGORIES.filter((c) => done.includes(c))
+    : undefined;
+}
+
+/** The category a change to this file falls under */
+export function migrationCategory(
+  file: string,
+  migration: Pick<MigrationContext, 'containerEnvFiles' | 'templateReferences'>,
+): MigrationCategory {
+  const name = basename(file);
+  if (DEPENDENCY_FILE_PATTERN.test(name)) return 'deps';
+  if (name.startsWith('.env') || (migration.containerEnvFiles ?? []).some((f) => f.file === file)) return 'env';
+  if ((migration.templateReferences ?? []).some((r) => r.file === file)) return 'templates';
+  return 'code';
+}
+
+/** Whether the run may change this file; every file is in scope without --only */
+export function inScope(
+  file: string,
+  migration: Pick<MigrationContext, 'only' | 'containerEnvFiles' | 'templateReferences'>,
+): boolean {
+  return !migration.only || migration.only.includes(migrationCategory(file, migration));
+}
+
+export function isDependencyCommand(command: string): boolean {
+  return DEPENDENCY_COMMAND_PATTERN.test(command.trim());
+}
+
+export function formatCategories(categories: readonly MigrationCategory[]): string {
+  return categories.join(', ');
+}
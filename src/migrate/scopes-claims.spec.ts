@@ -0,0 +1,57 @@
+import { describe, it, expect } from 'vitest';
+import { classifyScope, extractClaims, extractScopes, mapClaim, scopeClaimLines } from './scopes-claims.js';
+import type { MigrationFinding } from './types.js';
+import { makeFinding } from './findings.test-utils.js';
+
+function finding(details: Record<string, unknown>, line: number): MigrationFinding {
+  return makeFinding({ file: 'app.yml', line, confidence: 0, details });
+}
+
+describe('scopes-claims', () => {
+  it('classifies scopes by how AuthKit covers them', () => {
+    expect(classifyScope('openid')).toEqual({ support: 'supported' });
+    expect(classifyScope('offline_access').support).toBe('supported');
+    expect(classifyScope('read:org').support).toBe('organization');
+    expect(classifyScope('invoices:read').support).toBe('roles');
+    expect(classifyScope('admin').support).toBe('roles');
+    expect(classifyScope('https://www.googleapis.com/auth/calendar')).toMatchObject({ support: 'unsupported' });
+  });
+
+  it('maps standard claims and sends custom ones to JWT templates', () => {
+    expect(mapClaim('email')).toEqual({ authkit: 'user.email' });
+    expect(mapClaim('roles')).toMatchObject({ authkit: 'role' });
+    expect(mapClaim('https://example.com/roles')).toMatchObject({
+      authkit: null,
+      note: expect.stringContaining('JWT template'),
+    });
+    expect(mapClaim('department').authkit).toBeNull();
+  });
+
+  it('collects each scope and claim once, at its first location', () => {
+    const findings = [
+      finding({ scopes: ['openid', 'email', 'billing:write'] }, 3),
+      finding({ scopes: ['openid'] }, 9),
+      finding({ claim: 'email' }, 12),
+      finding({ claim: 'email' }, 20),
+    ];
+
+    expect(extractScopes(findings).map((s) => [s.scope, s.support, s.line])).toEqual([
+      ['openid', 'supported', 3],
+      ['email', 'supported', 3],
+      ['billing:write', 'roles', 3],
+    ]);
+    expect(extractClaims(findings)).toEqual([{ claim: 'email', authkit: 'user.email', file: 'app.yml', line: 12 }]);
+  });
+
+  it('renders one line per scope and claim', () => {
+    const lines = scopeClaimLines(extractScopes([finding({ scopes: ['openid', 'read:org'] }, 1)]), [
+      { claim: 'picture', authkit: 'user.profilePictureUrl', file: 'a.go' },
+    ]);
+
+    expect(lines).toEqual([
+      'scope openid: supported by AuthKit',
+      expect.stringMatching(/^scope read:org: AuthKit puts the organization/),
+      'claim picture → user.profilePictureUrl',
+    ]);
+  });
+});
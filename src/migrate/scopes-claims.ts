@@ -0,0 +1,120 @@
+/**
+ * Scopes the old login requested and the token claims the code reads,
+ * mapped to what AuthKit provides.
+ *
+ * Detectors record requested scopes on the finding for the login
+ * configuration (`details.scopes`) and each claim read as its own finding
+ * (`details.claim`). AuthKit always returns the user's profile and email, so
+ * OIDC scopes carry over as is; authorization scopes become roles and
+ * permissions, which AuthKit adds to the access token from the user's
+ * organization membership. Claims AuthKit doesn't issue have to come from a
+ * JWT template.
+ */
+
+import type { ClaimMapping, MigrationFinding, ScopeMapping, ScopeSupport } from './types.js';
+
+/** OIDC scopes AuthKit honors without configuration */
+const SUPPORTED_SCOPES = new Set(['openid', 'profile', 'email', 'offline_access']);
+
+const ORGANIZATION_SCOPE = /(^|[:._-])(orgs?|organizations?|tenants?)([:._-]|$)/i;
+const ROLE_SCOPE = /role|permission|group|admin|:/i;
+
+const SCOPE_NOTES: Record<Exclude<ScopeSupport, 'supported'>, string> = {
+  organization: 'AuthKit puts the organization of the session in the org_id claim; add users to organizations instead',
+  roles: 'Model it as a role or permission; AuthKit adds role and permissions claims from the organization membership',
+  unsupported: 'No AuthKit equivalent; drop it from the request',
+};
+
+/** Where AuthKit provides the standard claims, by claim name */
+const CLAIM_EQUIVALENTS: Record<string, { authkit: string; note?: string }> = {
+  sub: { authkit: 'sub', note: 'WorkOS user ID (user.id)' },
+  email: { authkit: 'user.email' },
+  email_verified: { authkit: 'user.emailVerified' },
+  given_name: { authkit: 'user.firstName' },
+  family_name: { authkit: 'user.lastName' },
+  name: { authkit: 'user.firstName + user.lastName', note: 'AuthKit has no single name field' },
+  picture: { authkit: 'user.profilePictureUrl' },
+  org_id: { authkit: 'org_id' },
+  organization: { authkit: 'org_id' },
+  role: { authkit: 'role' },
+  roles: { authkit: 'role', note: 'AuthKit issues one role per organization membership' },
+  permissions: { authkit: 'permissions' },
+  sid: { authkit: 'sid' },
+  iss: { authkit: 'iss' },
+  aud: { authkit: 'aud' },
+  exp: { authkit: 'exp' },
+  iat: { authkit: 'iat' },
+};
+
+/** How AuthKit covers a requested scope */
+export function classifyScope(scope: string): Pick<ScopeMapping, 'support' | 'note'> {
+  if (SUPPORTED_SCOPES.has(scope)) return { support: 'supported' };
+  // URL scopes (Google, Azure APIs) grant access to another service's API
+  const api = /^[a-z]+:\/\//i.test(scope);
+  const support: ScopeSupport = api
+    ? 'unsupported'
+    : ORGANIZATION_SCOPE.test(scope)
+      ? 'organization'
+      : ROLE_SCOPE.test(scope)
+        ? 'roles'
+        : 'unsupported';
+  return { support, note: SCOPE_NOTES[support] };
+}
+
+/** Where AuthKit provides a claim; namespaced and other custom claims need a JWT template */
+export function mapClaim(claim: string): Pick<ClaimMapping, 'authkit' | 'note'> {
+  const known = CLAIM_EQUIVALENTS[claim];
+  if (known) return { authkit: known.authkit, ...(known.note && { note: known.note }) };
+  const namespaced = /^https?:\/\//.test(claim);
+  return {
+    authkit: null,
+    note: namespaced
+      ? 'Namespaced custom claim; add it to AuthKit access tokens with a JWT template'
+      : 'Not issued by AuthKit; add it with a JWT template or store it in user metadata',
+  };
+}
+
+/** Requested scopes across the findings, first location kept */
+export function extractScopes(findings: MigrationFinding[]): ScopeMapping[] {
+  const scopes = new Map<string, ScopeMapping>();
+  for (const finding of findings) {
+    const requested = finding.details?.scopes;
+    if (!Array.isArray(requested)) continue;
+    for (const scope of requested) {
+      if (typeof scope !== 'string' || scopes.has(scope)) continue;
+      scopes.set(scope, { scope, ...classifyScope(scope), file: finding.file, line: finding.line });
+    }
+  }
+  return [...scopes.values()];
+}
+
+/** Claims read across the findings, first location kept */
+export function extractClaims(findings: MigrationFinding[]): ClaimMapping[] {
+  const claims = new Map<string, ClaimMapping>();
+  for (const finding of findings) {
+    const claim = finding.details?.claim;
+    if (typeof claim !== 'string' || claims.has(claim)) continue;
+    claims.set(claim, { claim, ...mapClaim(claim), file: finding.file, line: finding.line });
+  }
+  return [...claims.values()];
+}
+
+/** One line per scope and claim, for the prompt and the summary */
+export function scopeClaimLines(
+  scopes: Array<Pick<ScopeMapping, 'scope' | 'support' | 'note'>>,
+  claims: Array<Pick<ClaimMapping, 'claim' | 'authkit' | 'note'>>,
+): string[] {
+  return [
+    ...scopes.map(({ scope, support, note }) =>
+      support === 'supported' ? `scope ${scope}: supported by AuthKit` : `scope ${scope}: ${note}`,
+    ),
+    ...claims.map(({ claim, authkit, note }) =>
+      authkit ? `claim ${claim} → ${authkit}${note ? ` (${note})` : ''}` : `claim ${claim}: ${note}`,
+    ),
+  ];
+}
+
+/** Scopes that don't carry over as is: organization or role setup, or dropped */
+export function scopesNeedingSetup<T extends Pick<ScopeMapping, 'support'>>(scopes: T[]): T[] {
+  return scopes.filter((s) => s.support !== 'supported');
+}
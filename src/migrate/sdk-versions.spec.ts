@@ -0,0 +1,83 @@
+import { describe, it, expect } from 'vitest';
+import { selectMigration } from './plan.js';
+import { buildMigrationPrompt } from './prompt.js';
+import { goMajor, npmMajor, parseAssumedVersions, resolveSdkVersions } from './sdk-versions.js';
+import type { DetectionResult, MigrationFinding } from './types.js';
+
+function dependency(sdk: string, sdkMajor: number | null, sdkVersion: string): MigrationFinding {
+  return {
+    provider: 'auth0',
+    code: 'go-dependency',
+    severity: 'info',
+    message: `${sdk} in go.mod`,
+    file: 'go.mod',
+    line: 6,
+    confidence: 0.1,
+    details: { sdk, sdkMajor, sdkVersion },
+  };
+}
+
+describe('sdk-versions', () => {
+  it('reads the major from npm specs that pin one', () => {
+    expect(npmMajor('^3.5.0')).toBe(3);
+    expect(npmMajor('~2.1')).toBe(2);
+    expect(npmMajor('>=4.0.0-beta.1')).toBe(4);
+    expect(npmMajor('2.x || ^2.4.0')).toBe(2);
+    expect(npmMajor('^2.0.0 || ^3.0.0')).toBeNull();
+    expect(npmMajor('latest')).toBeNull();
+    expect(npmMajor('*')).toBeNull();
+    expect(npmMajor('github:auth0/nextjs-auth0#main')).toBeNull();
+  });
+
+  it('reads the major from Go module paths and versions', () => {
+    expect(goMajor('github.com/coreos/go-oidc/v3', 'v3.9.0')).toBe(3);
+    expect(goMajor('github.com/coreos/go-oidc', 'v2.2.1+incompatible')).toBe(2);
+    expect(goMajor('github.com/coreos/go-oidc', undefined)).toBeNull();
+  });
+
+  it('parses --assume-provider-version by full or short SDK name', () => {
+    expect(parseAssumedVersions(['go-oidc@2', '@auth0/nextjs-auth0@v3'])).toEqual({
+      'github.com/coreos/go-oidc': 2,
+      '@auth0/nextjs-auth0': 3,
+    });
+    expect(() => parseAssumedVersions(['go-oidc'])).toThrow('expects <sdk>@<major>');
+    expect(() => parseAssumedVersions(['firebase@9'])).toThrow('Unknown SDK "firebase"');
+  });
+
+  it('selects the variant for the detected major', () => {
+    const { versions, warnings } = resolveSdkVersions([dependency('github.com/coreos/go-oidc', 2, 'v2.2.1')]);
+
+    expect(warnings).toEqual([]);
+    expect(versions).toMatchObject([{ sdk: 'github.com/coreos/go-oidc', major: 2, detectedMajor: 2, assumed: false }]);
+    expect(versions[0].guidance).toContain('imported as `github.com/coreos/go-oidc`');
+  });
+
+  it('assumes the latest major and warns when the manifest does not say', () => {
+    const { versions, warnings } = resolveSdkVersions([dependency('@auth0/nextjs-auth0', null, 'latest')]);
+
+    expect(versions[0]).toMatchObject({ major: 4, detectedMajor: null, assumed: true });
+    expect(warnings).toEqual([
+      'Could not determine the @auth0/nextjs-auth0 major version from go.mod ("latest"); assuming v4. ' +
+        "Pass --assume-provider-version nextjs-auth0@<major> if that's wrong.",
+    ]);
+  });
+
+  it('lets --assume-provider-version override the manifest', () => {
+    const findings = [dependency('github.com/coreos/go-oidc', 3, 'v3.9.0')];
+    const detection: DetectionResult = {
+      root: '/project',
+      matches: [{ provider: 'auth0', confidence: 0.9, findings }],
+    };
+
+    const { context, warnings } = selectMigration(detection, undefined, {
+      assumedVersions: { 'github.com/coreos/go-oidc': 2 },
+    });
+
+    expect(warnings).toEqual([]);
+    expect(context.sdkVersions).toMatchObject([{ major: 2, detectedMajor: 3, assumed: true }]);
+    expect(buildMigrationPrompt(context)).toContain(
+      '- go.mod:6: github.com/coreos/go-oidc v2 (assumed; the manifest says v3): ' +
+        'imported as `github.com/coreos/go-oidc` (package oidc at the module root',
+    );
+  });
+});
@@ -0,0 +1,197 @@
+/**
+ * Provider SDK major versions.
+ *
+ * Some SDKs changed shape between majors: go-oidc moved its import path in
+ * v3, nextjs-auth0 v4 replaced `handleAuth()` routes with an `Auth0Client`
+ * mounted in middleware, and the Auth0 SPA SDKs moved login options under
+ * `authorizationParams` in v2. Guidance written for one major misleads the
+ * agent on another, so detectors record the major they read from the
+ * manifest (`details.sdk`, `details.sdkMajor`) and the migration picks the
+ * variant for it. `--assume-provider-version` overrides a major that can't
+ * be read, or is read wrong; without either, the latest major is assumed
+ * and a warning says so.
+ */
+
+import type { MigrationFinding, SdkVersion } from './types.js';
+
+/** Guidance for an SDK from `major` up to the next variant */
+interface SdkVariant {
+  major: number;
+  guidance: string;
+}
+
+/** SDKs whose code differs between majors, variants in ascending order */
+export const SDK_VARIANTS: Record<string, SdkVariant[]> = {
+  'github.com/coreos/go-oidc': [
+    {
+      major: 2,
+      guidance:
+        'imported as `github.com/coreos/go-oidc` (package oidc at the module root, `+incompatible` in go.mod); ' +
+        'remove that import and the `oidc.NewProvider`/`provider.Verifier` setup',
+    },
+    {
+      major: 3,
+      guidance:
+        'imported as `github.com/coreos/go-oidc/v3/oidc`; remove that import, the `oidc.NewProvider`/' +
+        '`provider.Verifier` setup and any `oidc.NewRemoteKeySet`, then drop the /v3 module from go.mod',
+    },
+  ],
+  '@auth0/nextjs-auth0': [
+    {
+      major: 1,
+      guidance:
+        '`handleAuth()` under pages/api/auth/[...auth0] or app/api/auth/[auth0]/route, `getSession`, ' +
+        '`withApiAuthRequired`/`withPageAuthRequired`, and `UserProvider` from @auth0/nextjs-auth0/client; ' +
+        'routes live under /api/auth/*',
+    },
+    {
+      major: 4,
+      guidance:
+        '`new Auth0Client()` (usually lib/auth0.ts) mounted by `auth0.middleware()` in middleware.ts, ' +
+        '`auth0.getSession()`, and `Auth0Provider` from @auth0/nextjs-auth0; routes live under /auth/*',
+    },
+  ],
+  '@auth0/auth0-react': [
+    {
+      major: 1,
+      guidance: '`<Auth0Provider redirectUri>` with login options at the top level, and `logout({ returnTo })`',
+    },
+    {
+      major: 2,
+      guidance:
+        '`<Auth0Provider authorizationParams={{ redirect_uri }}>`, and `logout({ logoutParams: { returnTo } })`',
+    },
+  ],
+  '@auth0/auth0-spa-js': [
+    {
+      major: 1,
+      guidance: '`createAuth0Client({ redirect_uri })` with login options at the top level, and `logout({ returnTo })`',
+    },
+    {
+      major: 2,
+      guidance:
+        '`createAuth0Client({ authorizationParams: { redirect_uri } })` or `new Auth0Client()`, and ' +
+        '`logout({ logoutParams: { returnTo } })`',
+    },
+  ],
+  auth0: [
+    {
+      major: 2,
+      guidance: '`new ManagementClient()` with flat methods such as `getUser({ id })` returning the user directly',
+    },
+    {
+      major: 4,
+      guidance: '`new ManagementClient()` with grouped methods such as `users.get({ id })` returning `{ data }`',
+    },
+  ],
+};
+
+/**
+ * Major version of an npm dependency spec, or null when the spec doesn't
+ * pin one (`*`, `latest`, git/file/workspace specs, ranges over several
+ * majors).
+ */
+export function npmMajor(spec: string): number | null {
+  const majors = new Set<number>();
+  for (const part of spec.split('||')) {
+    const version = /^\s*(?:[~^]|[<>]=?|=)?\s*v?(\d+)(?:\.[\dxX*]+){0,2}(?:-[\w.]+)?\s*$/.exec(part);
+    if (!version) return null;
+    majors.add(Number(version[1]));
+  }
+  return majors.size === 1 ? [...majors][0] : null;
+}
+
+/**
+ * Major version of a Go module requirement: the `/vN` suffix of the path,
+ * otherwise the version (`v2.2.1+incompatible` is 2). Null when neither
+ * says.
+ */
+export function goMajor(modulePath: string, version: string | undefined): number | null {
+  const suffix = /\/v(\d+)$/.exec(modulePath);
+  if (suffix) return Number(suffix[1]);
+  const tag = version && /^v(\d+)\./.exec(version);
+  return tag ? Number(tag[1]) : null;
+}
+
+/** The name people use for an SDK, e.g. `go-oidc` or `nextjs-auth0` */
+function shortName(sdk: string): string {
+  return sdk.split('/').pop()!;
+}
+
+function sdkByName(name: string): string | undefined {
+  return Object.keys(SDK_VARIANTS).find((sdk) => sdk === name || shortName(sdk) === name);
+}
+
+/**
+ * Parse `--assume-provider-version` values, e.g. `go-oidc@2`,
+ * `@auth0/nextjs-auth0@v3`. Throws on unknown SDKs and malformed values.
+ */
+export function parseAssumedVersions(values: string[]): Record<string, number> {
+  const assumed: Record<string, number> = {};
+  for (const value of values) {
+    const at = value.lastIndexOf('@');
+    const major = at > 0 ? /^v?(\d+)$/.exec(value.slice(at + 1)) : null;
+    if (!major) throw new Error(`--assume-provider-version expects <sdk>@<major>, e.g. go-oidc@2 (got "${value}")`);
+    const sdk = sdkByName(value.slice(0, at));
+    if (!sdk) {
+      const known = Object.keys(SDK_VARIANTS).map(shortName).join(', ');
+      throw new Error(`Unknown SDK "${value.slice(0, at)}" in --assume-provider-version. Known: ${known}`);
+    }
+    assumed[sdk] = Number(major[1]);
+  }
+  return assumed;
+}
+
+function variantFor(sdk: string, major: number): SdkVariant {
+  const variants = SDK_VARIANTS[sdk];
+  return [...variants].reverse().find((v) => v.major <= major) ?? variants[0];
+}
+
+/**
+ * SDK versions recorded in findings, with the variant each selects and a
+ * warning for every major that had to be assumed.
+ */
+export function resolveSdkVersions(
+  findings: MigrationFinding[],
+  assumed: Record<string, number> = {},
+): { versions: SdkVersion[]; warnings: string[] } {
+  const versions: SdkVersion[] = [];
+  const warnings: string[] = [];
+  for (const finding of findings) {
+    const { sdk, sdkMajor, sdkVersion } = finding.details ?? {};
+    if (typeof sdk !== 'string' || !SDK_VARIANTS[sdk] || versions.some((v) => v.sdk === sdk)) continue;
+    const detectedMajor = typeof sdkMajor === 'number' ? sdkMajor : null;
+    const latest = SDK_VARIANTS[sdk].at(-1)!.major;
+    const major = assumed[sdk] ?? detectedMajor ?? latest;
+    if (detectedMajor === null && assumed[sdk] === undefined) {
+      const spec = typeof sdkVersion === 'string' ? ` ("${sdkVersion}")` : '';
+      warnings.push(
+        `Could not determine the ${sdk} major version from ${finding.file}${spec}; assuming v${latest}. ` +
+          `Pass --assume-provider-version ${shortName(sdk)}@<major> if that's wrong.`,
+      );
+    }
+    versions.push({
+      sdk,
+      file: finding.file,
+      ...(finding.line ? { line: finding.line } : {}),
+      major,
+      detectedMajor,
+      assumed: major !== detectedMajor,
+      guidance: variantFor(sdk, major).guidance,
+    });
+  }
+  return { versions, warnings };
+}
+
+/** Prompt lines describing the code each SDK version uses */
+export function sdkVersionLines(versions: SdkVersion[]): string[] {
+  return versions.map((v) => {
+    const location = v.line ? `${v.file}:${v.line}` : v.file;
+    const source = !v.assumed
+      ? ''
+      : v.detectedMajor === null
+        ? ' (assumed; not pinned in the manifest)'
+        : ` (assumed; the manifest says v${v.detectedMajor})`;
+    return `- ${location}: ${v.sdk} v${v.major}${source}: ${v.guidance}`;
+  });
+}
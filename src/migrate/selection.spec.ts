@@ -0,0 +1,75 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import {
+  _setStateDir,
+  isExcludedFile,
+  migrationFiles,
+  previousExclusions,
+  previousSteps,
+  readMigrationState,
+  writeMigrationState,
+} from './selection.js';
+import { makeFinding } from './findings.test-utils.js';
+
+describe('migration selection', () => {
+  let dir: string;
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'migrate-selection-'));
+    _setStateDir(dir);
+  });
+
+  afterEach(() => {
+    rmSync(dir, { recursive: true, force: true });
+  });
+
+  it('lists files with in-scope findings once, with counts', () => {
+    const files = migrationFiles([
+      makeFinding({ file: 'src/b.ts' }),
+      makeFinding({ file: 'src/a.ts' }),
+      makeFinding({ file: 'src/b.ts' }),
+      makeFinding({ file: 'src/db.ts', outOfScope: true }),
+    ]);
+
+    expect(files).toEqual([
+      { file: 'src/a.ts', findings: 1 },
+      { file: 'src/b.ts', findings: 2 },
+    ]);
+  });
+
+  it('keeps the selection per project across runs', () => {
+    writeMigrationState('/projects/app', { provider: 'clerk', excludedFiles: ['src/lab.ts'] });
+
+    expect(readMigrationState('/projects/app')).toMatchObject({ provider: 'clerk', excludedFiles: ['src/lab.ts'] });
+    expect(readMigrationState('/projects/other')).toBeNull();
+  });
+
+  it('reuses earlier exclusions only for the same provider and current files', () => {
+    const state = { provider: 'clerk', excludedFiles: ['src/lab.ts', 'src/gone.ts'], updatedAt: '' };
+
+    expect(previousExclusions(state, 'clerk', ['src/lab.ts', 'src/a.ts'])).toEqual(['src/lab.ts']);
+    expect(previousExclusions(state, 'supabase', ['src/lab.ts'])).toEqual([]);
+    expect(previousExclusions(null, 'clerk', ['src/lab.ts'])).toEqual([]);
+  });
+
+  it('resumes an earlier run of the same provider only while files are left to do', () => {
+    const steps = [
+      { file: 'src/a.ts', status: 'succeeded' as const },
+      { file: 'src/b.ts', status: 'failed' as const, reason: 'String not found' },
+    ];
+    const state = { provider: 'clerk', excludedFiles: [], steps, updatedAt: '2026-01-01T00:00:00.000Z' };
+
+    expect(previousSteps(state, 'clerk')).toEqual(steps);
+    expect(previousSteps(state, 'supabase')).toEqual([]);
+    expect(previousSteps({ ...state, steps: [steps[0]] }, 'clerk')).toEqual([]);
+    expect(previousSteps(null, 'clerk')).toEqual([]);
+  });
+
+  it('matches tool paths against excluded files', () => {
+    expect(isExcludedFile('/projects/app/src/lab.ts', '/projects/app', ['src/lab.ts'])).toBe(true);
+    expect(isExcludedFile('src/lab.ts', '/projects/app', ['src/lab.ts'])).toBe(true);
+    expect(isExcludedFile('/projects/app/src/a.ts', '/projects/app', ['src/lab.ts'])).toBe(false);
+  });
+});
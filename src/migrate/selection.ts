@@ -0,0 +1,133 @@
+/**
+ * Which detected files a migration may touch.
+ *
+ * The user can leave files out before the migration runs. The choice is
+ * saved per project (under ~/.workos/migrations, outside the repo) so a
+ * rerun of `workos migrate` starts from the same selection instead of
+ * putting excluded files back. A run that was only partly applied, or
+ * cancelled, saves what became of each file next to it, with the plan it
+ * worked from, so the rerun finishes the rest. Runs limited with `--only`
+ * save the categories they finished, so the rerun applies the others.
+ */
+
+import { createHash } from 'node:crypto';
+import { mkdirSync, readFileSync, writeFileSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, isAbsolute, join, relative, sep } from 'node:path';
+import type { MigrationCategory } from './scope.js';
+import type { MigrationFinding, MigrationStep } from './types.js';
+
+/** A file the migration planned to change, as it was when the run stopped */
+export interface PlannedFile {
+  file: string;
+  findings: number;
+  /** SHA-256 of the file's contents, or null if it couldn't be read */
+  hash: string | null;
+}
+
+export interface MigrationState {
+  provider: string;
+  /** Paths relative to the project root (POSIX separators) */
+  excludedFiles: string[];
+  /** Per-file outcome of the last run, when it was only partly applied */
+  steps?: MigrationStep[];
+  /** Files the last run planned to change, saved with its steps */
+  plan?: PlannedFile[];
+  /** Categories finished by earlier `--only` runs, until every one is done */
+  categories?: MigrationCategory[];
+  updatedAt: string;
+}
+
+let stateDir = join(homedir(), '.workos', 'migrations');
+
+/** @internal For testing only */
+export function _setStateDir(dir: string): void {
+  stateDir = dir;
+}
+
+/** Where a project's migration state is kept */
+export function migrationStatePath(root: string): string {
+  const key = createHash('sha256').update(root).digest('hex').slice(0, 16);
+  return join(stateDir, `${key}.json`);
+}
+
+export function readMigrationState(root: string): MigrationState | null {
+  try {
+    return JSON.parse(readFileSync(migrationStatePath(root), 'utf-8')) as MigrationState;
+  } catch {
+    return null;
+  }
+}
+
+export function writeMigrationState(root: string, state: Omit<MigrationState, 'updatedAt'>): void {
+  const path = migrationStatePath(root);
+  mkdirSync(dirname(path), { recursive: true });
+  writeFileSync(path, JSON.stringify({ ...state, updatedAt: new Date().toISOString() }, null, 2));
+}
+
+/**
+ * Files with findings to migrate, with their finding counts. Out-of-scope
+ * findings are left alone anyway, so they don't need a checkbox.
+ */
+export function migrationFiles(findings: MigrationFinding[]): Array<{ file: string; findings: number }> {
+  const counts = new Map<string, number>();
+  for (const finding of findings.filter((f) => !f.outOfScope)) {
+    counts.set(finding.file, (counts.get(finding.file) ?? 0) + 1);
+  }
+  return [...counts.entries()]
+    .map(([file, findings]) => ({ file, findings }))
+    .sort((a, b) => a.file.localeCompare(b.file));
+}
+
+/** SHA-256 of a project file's contents, or null if it can't be read */
+export function projectFileHash(root: string, file: string): string | null {
+  try {
+    return createHash('sha256').update(readFileSync(join(root, file))).digest('hex');
+  } catch {
+    return null;
+  }
+}
+
+/**
+ * The files a migration plans to change, plus the files its steps touched,
+ * hashed so a rerun can tell which were edited since.
+ */
+export function snapshotPlan(root: string, findings: MigrationFinding[], steps: MigrationStep[] = []): PlannedFile[] {
+  const files = migrationFiles(findings);
+  const planned = new Set(files.map(({ file }) => file));
+  for (const { file } of steps) {
+    if (!planned.has(file)) files.push({ file, findings: 0 });
+    planned.add(file);
+  }
+  return files.map(({ file, findings }) => ({ file, findings, hash: projectFileHash(root, file) }));
+}
+
+/**
+ * Exclusions from an earlier run that still apply: same provider, and the
+ * file still has findings.
+ */
+export function previousExclusions(state: MigrationState | null, provider: string, files: string[]): string[] {
+  if (!state || state.provider !== provider) return [];
+  return state.excludedFiles.filter((file) => files.includes(file));
+}
+
+/**
+ * Outcome of an earlier partly applied run of the same provider, when some
+ * of its files are still to do.
+ */
+export function previousSteps(state: MigrationState | null, provider: string): MigrationStep[] {
+  if (!state?.steps || state.provider !== provider) return [];
+  return state.steps.some((step) => step.status !== 'succeeded') ? state.steps : [];
+}
+
+/** Categories earlier `--only` runs of the same provider finished */
+export function completedCategories(state: MigrationState | null, provider: string): MigrationCategory[] {
+  if (!state?.categories || state.provider !== provider) return [];
+  return state.categories;
+}
+
+/** Whether an agent tool path (absolute or cwd-relative) is one of the excluded files */
+export function isExcludedFile(filePath: string, cwd: string, excludedFiles: string[]): boolean {
+  const rel = relative(cwd, isAbsolute(filePath) ? filePath : join(cwd, filePath)).split(sep).join('/');
+  return excludedFiles.includes(rel);
+}
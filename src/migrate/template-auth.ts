@@ -0,0 +1,61 @@
+/**
+ * Sign-in state in templates: login and logout links, markup that depends
+ * on whether the user is signed in, and script reading the old session
+ * cookie, in `.html`, server-side templates (`.tmpl`, Jinja, ERB, Razor, ...)
+ * and JSX, Vue and Svelte components.
+ *
+ * The migration rewrites handlers, but a template still linking to the old
+ * provider's hosted logout, or showing "Sign out" when a readable `user`
+ * cookie is set, breaks quietly: AuthKit's session cookie is sealed and
+ * HttpOnly. The template-auth detector finds these (`details.template`);
+ * the agent repoints them, and the summary lists the ones left unchanged.
+ *
+ * Link text is never what's matched or changed, so localized labels and
+ * translation calls (`t('nav.logout')`, `{% trans %}`) stay as written.
+ */
+
+import type { MigrationFinding, TemplateAuthKind, TemplateReference } from './types.js';
+
+const KINDS = new Set<TemplateAuthKind>(['login', 'logout', 'conditional', 'cookie']);
+
+/** One entry per kind and target in a file, first location kept */
+export function extractTemplateReferences(findings: MigrationFinding[]): TemplateReference[] {
+  const references = new Map<string, TemplateReference>();
+  for (const finding of findings) {
+    const { template, target, hosted } = finding.details ?? {};
+    if (typeof template !== 'string' || !KINDS.has(template as TemplateAuthKind)) continue;
+    if (typeof target !== 'string') continue;
+    const key = `${finding.file}:${template}:${target}`;
+    if (references.has(key)) continue;
+    references.set(key, {
+      kind: template as TemplateAuthKind,
+      file: finding.file,
+      line: finding.line,
+      target,
+      ...(hosted === true && { hosted: true }),
+    });
+  }
+  return [...references.values()];
+}
+
+/** How the agent repoints template references, in the prompt */
+export const TEMPLATE_STEPS = [
+  "Point login links, forms and buttons at the app's AuthKit sign-in route (the migrated login handler, or the URL from getSignInUrl in the AuthKit SDKs), and logout at the route that clears the session and redirects to the AuthKit logout URL. Links to the old provider's hosted pages must not survive",
+  "Base signed-in markup on the AuthKit user the server passes to the template (e.g. `{{if .User}}` with the user from the session), not on the old provider's user object. The AuthKit session cookie is sealed and HttpOnly, so script can't read it: where the page decided in the browser, render the state on the server or fetch it from a route that reads the session",
+  'Change only the URL attribute or the condition. Keep link text, translation calls and keys (`t(...)`, `{% trans %}`, `i18n`), classes and the rest of the markup exactly as written, and add no new user-facing strings',
+];
+
+const DESCRIPTIONS: Record<TemplateAuthKind, (reference: TemplateReference) => string> = {
+  login: ({ target, hosted }) => `login link ${target}${hosted ? " (the old provider's hosted page)" : ''}`,
+  logout: ({ target, hosted }) => `logout link ${target}${hosted ? " (the old provider's hosted page)" : ''}`,
+  conditional: ({ target }) => `shown by sign-in state (${target})`,
+  cookie: ({ target }) => `script reads the ${target} cookie, which AuthKit doesn't set`,
+};
+
+/** One line per reference, for the prompt, the plan and the summary */
+export function templateReferenceLines(references: TemplateReference[]): string[] {
+  return references.map((reference) => {
+    const location = reference.line ? `${reference.file}:${reference.line}` : reference.file;
+    return `${location}: ${DESCRIPTIONS[reference.kind](reference)}`;
+  });
+}
@@ -0,0 +1,88 @@
+import { afterEach, beforeEach, describe, expect, it } from 'vitest';
+import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { loadTemplateOverrides, renderTemplate, templateOverride } from './templates.js';
+
+describe('templates', () => {
+  let root: string;
+
+  beforeEach(() => {
+    root = mkdtempSync(join(tmpdir(), 'migration-templates-'));
+  });
+
+  afterEach(() => {
+    rmSync(root, { recursive: true, force: true });
+  });
+
+  function write(file: string, content: string) {
+    mkdirSync(dirname(join(root, file)), { recursive: true });
+    writeFileSync(join(root, file), content);
+  }
+
+  describe('loadTemplateOverrides', () => {
+    it('has none without a directory or config key', () => {
+      expect(loadTemplateOverrides(root)).toBeUndefined();
+    });
+
+    it('loads templates keyed by provider, framework and file', () => {
+      write('templates/auth0/gin/callback.go', '{{ctx}}.Status(204)\n');
+      const overrides = loadTemplateOverrides(root, { path: join(root, 'templates') });
+
+      expect(templateOverride(overrides, 'auth0', 'gin', 'callback.go')).toBe('{{ctx}}.Status(204)\n');
+      // Everything else falls back to the built-in template
+      expect(templateOverride(overrides, 'auth0', 'gin', 'login.go')).toBeUndefined();
+      expect(templateOverride(overrides, 'okta', 'gin', 'callback.go')).toBeUndefined();
+    });
+
+    it('reads templatesDir from .workos/config.json, relative to the project', () => {
+      write('.workos/config.json', JSON.stringify({ templatesDir: 'house/templates' }));
+      write('house/templates/okta/gin/logout.go', '{{ctx}}.Status(204)\n');
+
+      expect(Object.keys(loadTemplateOverrides(root)!.templates)).toEqual(['okta/gin/logout.go']);
+    });
+
+    it('reports every unknown provider, framework, file and placeholder at once', () => {
+      write('templates/auth0/gin/callback.go', '{{ctx}}.Redirect(302, "{{redirectUrl}}")\n');
+      write('templates/auth00/gin/login.go', '{{ctx}}.Status(204)\n');
+      write('templates/auth0/echo/login.go', '{{ctx}}.Status(204)\n');
+      write('templates/auth0/gin/signup.go', '{{ctx}}.Status(204)\n');
+
+      let message = '';
+      try {
+        loadTemplateOverrides(root, { path: join(root, 'templates') });
+      } catch (error) {
+        message = (error as Error).message;
+      }
+      expect(message.split('\n').slice(1)).toEqual([
+        expect.stringContaining('auth0/echo/login.go: no templates for framework "echo"'),
+        expect.stringContaining('auth0/gin/callback.go: unknown placeholder {{redirectUrl}}'),
+        expect.stringContaining('auth0/gin/signup.go: unknown template "signup.go"'),
+        expect.stringContaining('auth00/gin/login.go: unknown provider "auth00"'),
+      ]);
+    });
+
+    it('rejects a session helper with its own package clause', () => {
+      write('templates/auth0/gin/authkit_session.go', 'package main\n\nconst cookie = "{{sessionCookie}}"\n');
+
+      expect(() => loadTemplateOverrides(root, { path: join(root, 'templates') })).toThrow(
+        /leave out the package clause/,
+      );
+    });
+
+    it('fails on a missing directory', () => {
+      const load = () => loadTemplateOverrides(root, { path: join(root, 'missing') });
+      expect(load).toThrow('Templates directory not found');
+    });
+  });
+
+  describe('renderTemplate', () => {
+    it('fills placeholders and leaves other braces alone', () => {
+      expect(renderTemplate('{{ctx}}.HTML(200, "{{.Name}}")', { ctx: 'c' })).toBe('c.HTML(200, "{{.Name}}")');
+    });
+
+    it('drops lines that only hold an empty placeholder', () => {
+      expect(renderTemplate('a\n\t{{clearLegacyCookie}}\nb', { clearLegacyCookie: '' })).toBe('a\nb');
+    });
+  });
+});
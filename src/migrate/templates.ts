@@ -0,0 +1,168 @@
+/**
+ * Project overrides for the code migrations write into the app.
+ *
+ * `--templates-dir <path>` (or `"templatesDir"` in `.workos/config.json`)
+ * points at a directory of templates keyed `<provider>/<framework>/<file>`,
+ * e.g. `auth0/gin/callback.go`, that replace the built-in ones. Anything
+ * not overridden uses the built-in template. The whole directory is
+ * checked when it's loaded: an unknown provider, framework or file, or a
+ * placeholder the template can't be given, stops the run before anything
+ * changes rather than partway through the migration.
+ *
+ * Templates are plain source with `{{name}}` placeholders. Anything else in
+ * braces, such as `{{.Name}}` in a Go html/template string, is left alone.
+ */
+
+import { readFileSync, statSync } from 'node:fs';
+import { join, resolve } from 'node:path';
+import fg from 'fast-glob';
+import { providerNames } from './providers.js';
+
+/** Project config holding `"templatesDir"`, relative to the directory holding `.workos/` */
+const CONFIG_FILE = join('.workos', 'config.json');
+
+const PLACEHOLDER = /\{\{\s*([A-Za-z_]\w*)\s*\}\}/g;
+
+/** Placeholders shared by the Gin handler templates */
+const GIN_HANDLER_PLACEHOLDERS = [
+  // The handler's *gin.Context variable, e.g. `c`
+  'ctx',
+  // Statement clearing the old provider's session cookie; empty when there was none
+  'clearLegacyCookie',
+  // Go expression for the callback URL, built the way the old oauth2.Config built it
+  'redirectUri',
+];
+
+/** Templates each framework's migration writes, with the placeholders each one is given */
+export const TEMPLATE_FILES: Record<string, Record<string, string[]>> = {
+  gin: {
+    // Handler bodies; a leading import declaration lists the packages the body needs
+    'login.go': GIN_HANDLER_PLACEHOLDERS,
+    'callback.go': GIN_HANDLER_PLACEHOLDERS,
+    'logout.go': [
+      ...GIN_HANDLER_PLACEHOLDERS,
+      // Go expression for where the old logout sent the user, "/" when it couldn't be read
+      'returnTo',
+    ],
+    // Session sealing helper, without the package clause (it's added per package)
+    'authkit_session.go': ['sessionCookie'],
+  },
+};
+
+export interface TemplateOverrides {
+  /** Directory the overrides came from, as given */
+  source: string;
+  /** Template content by `<provider>/<framework>/<file>` */
+  templates: Record<string, string>;
+}
+
+/** Problems with one override template; empty when it can be used */
+function checkTemplate(key: string, content: string): string[] {
+  const [provider, framework, file, ...rest] = key.split('/');
+  if (rest.length > 0 || !file) {
+    return [`${key}: expected <provider>/<framework>/<file>`];
+  }
+  if (!providerNames().includes(provider)) {
+    return [`${key}: unknown provider "${provider}" (expected one of ${providerNames().join(', ')})`];
+  }
+  const files = TEMPLATE_FILES[framework];
+  if (!files) {
+    return [`${key}: no templates for framework "${framework}" (expected ${Object.keys(TEMPLATE_FILES).join(', ')})`];
+  }
+  const placeholders = files[file];
+  if (!placeholders) {
+    return [`${key}: unknown template "${file}" (expected one of ${Object.keys(files).join(', ')})`];
+  }
+
+  const problems: string[] = [];
+  if (!content.trim()) problems.push(`${key}: template is empty`);
+  const unknown = [...new Set([...content.matchAll(PLACEHOLDER)].map((m) => m[1]))].filter(
+    (name) => !placeholders.includes(name),
+  );
+  for (const name of unknown) {
+    const known = placeholders.map((p) => `{{${p}}}`).join(', ');
+    problems.push(`${key}: unknown placeholder {{${name}}} (available: ${known})`);
+  }
+  if (file === 'authkit_session.go' && /^\s*package\s+\w+/m.test(content)) {
+    problems.push(`${key}: leave out the package clause; it's added for each package the helper is written to`);
+  }
+  return problems;
+}
+
+/** `templatesDir` from the project's config, resolved against the directory holding `.workos/` */
+function configuredTemplatesDir(installDir: string): string | undefined {
+  const path = join(installDir, CONFIG_FILE);
+  let config: unknown;
+  try {
+    config = JSON.parse(readFileSync(path, 'utf-8'));
+  } catch (error) {
+    if ((error as NodeJS.ErrnoException).code === 'ENOENT') return undefined;
+    throw new Error(`${path} is not valid JSON`);
+  }
+  const dir = (config as { templatesDir?: unknown } | null)?.templatesDir;
+  if (dir === undefined) return undefined;
+  if (typeof dir !== 'string' || !dir.trim()) throw new Error(`"templatesDir" in ${path} must be a path`);
+  return resolve(installDir, dir);
+}
+
+/**
+ * Load and check the project's template overrides. An explicit directory
+ * (relative to the current directory) wins over the config key; without
+ * either there are none. Throws with every problem found, so a typo is
+ * fixed in one go.
+ */
+export function loadTemplateOverrides(
+  installDir: string,
+  options: { path?: string } = {},
+): TemplateOverrides | undefined {
+  const dir = options.path ? resolve(options.path) : configuredTemplatesDir(installDir);
+  if (!dir) return undefined;
+  const source = options.path ?? dir;
+
+  let isDirectory = false;
+  try {
+    isDirectory = statSync(dir).isDirectory();
+  } catch {
+    // Reported below
+  }
+  if (!isDirectory) throw new Error(`Templates directory not found: ${source}`);
+
+  const templates: Record<string, string> = {};
+  const problems: string[] = [];
+  for (const file of fg.sync('**/*', { cwd: dir, onlyFiles: true }).sort()) {
+    // fast-glob lists paths with forward slashes on every platform
+    const content = readFileSync(join(dir, file), 'utf-8');
+    const found = checkTemplate(file, content);
+    if (found.length > 0) problems.push(...found);
+    else templates[file] = content;
+  }
+  if (problems.length > 0) {
+    throw new Error(`Invalid templates in ${source}:\n${problems.map((p) => `  ${p}`).join('\n')}`);
+  }
+  return { source, templates };
+}
+
+/** The override for one template, if the project has one */
+export function templateOverride(
+  overrides: TemplateOverrides | undefined,
+  provider: string,
+  framework: string,
+  file: string,
+): string | undefined {
+  return overrides?.templates[`${provider}/${framework}/${file}`];
+}
+
+/**
+ * Fill in a template's placeholders. A line holding only a placeholder that
+ * renders empty is dropped, so optional statements leave no blank line.
+ */
+export function renderTemplate(template: string, values: Record<string, string>): string {
+  return template
+    .split('\n')
+    .filter((line) => {
+      const only = /^\s*\{\{\s*([A-Za-z_]\w*)\s*\}\}\s*$/.exec(line);
+      return !only || values[only[1]] !== '';
+    })
+    .map((line) => line.replace(PLACEHOLDER, (match, name: string) => values[name] ?? match))
+    .join('\n');
+}
@@ -0,0 +1,58 @@
+/**
+ * Organizations setup for multi-tenant apps.
+ *
+ * AuthKit is organization-centric: a user signs in to an organization and
+ * the session says which one. Apps that already have a tenant concept keep
+ * it, with each tenant mapped to a WorkOS organization, instead of being
+ * migrated to single-tenant code. The signals come from the tenancy
+ * detector (`details.tenancy`) and from tenant claims any detector reports
+ * (`details.claim`).
+ */
+
+import type { MigrationFinding, TenancySignal } from './types.js';
+
+/** Claims naming the tenant, plain or namespaced (`https://example.com/org_id`) */
+const TENANT_CLAIM = /^(?:https?:\/\/.+\/)?(org_id|org|organization|organization_id|tenant|tenant_id|tid)$/i;
+
+const KINDS = new Set(['claim', 'route', 'column']);
+
+/** Multi-tenancy signals across the findings, one per kind and name, first location kept */
+export function extractTenancySignals(findings: MigrationFinding[]): TenancySignal[] {
+  const signals = new Map<string, TenancySignal>();
+  const add = (kind: TenancySignal['kind'], name: string, finding: MigrationFinding) => {
+    const key = `${kind}:${name.toLowerCase()}`;
+    if (!signals.has(key)) signals.set(key, { kind, name, file: finding.file, line: finding.line });
+  };
+
+  for (const finding of findings) {
+    const { tenancy, name, claim } = finding.details ?? {};
+    if (typeof tenancy === 'string' && KINDS.has(tenancy) && typeof name === 'string') {
+      add(tenancy as TenancySignal['kind'], name, finding);
+    } else if (typeof claim === 'string' && TENANT_CLAIM.test(claim)) {
+      add('claim', claim, finding);
+    }
+  }
+  return [...signals.values()];
+}
+
+/** What to set up in WorkOS and change in the code, in order */
+export const ORGANIZATION_STEPS = [
+  'Create a WorkOS organization for each tenant (`workos organization create`, or the Organizations API in a backfill script) and store its ID with the tenant, e.g. in a workos_organization_id column',
+  "Add each user to their tenant's organization as an organization membership, with their role",
+  'When the tenant is known before sign-in (from the route or subdomain), pass its organization ID to the authorization URL so the user signs in to that organization',
+  "Take the current tenant from the session's organizationId (the org_id claim) instead of the old tenant claim, and check tenant route parameters against it",
+];
+
+function describeSignal(signal: TenancySignal): string {
+  const location = signal.line ? `${signal.file}:${signal.line}` : signal.file;
+  if (signal.kind === 'claim') return `claim ${signal.name} (${location}) → the session's organizationId`;
+  if (signal.kind === 'route') {
+    return `route parameter ${signal.name} (${location}) → look up its organization and check it against the session's`;
+  }
+  return `column ${signal.name} (${location}) → keep it, next to the tenant's WorkOS organization ID`;
+}
+
+/** One line per signal, for the prompt and the plan */
+export function tenancyLines(signals: TenancySignal[]): string[] {
+  return signals.map(describeSignal);
+}
@@ -0,0 +1,68 @@
+/**
+ * Auth0 applications managed in Terraform (`workos migrate --scan-terraform`).
+ *
+ * The migration leaves the infrastructure alone, but an `auth0_client`
+ * resource records the dashboard settings the WorkOS app has to match
+ * before the first sign-in: its callbacks, sign-out redirects and CORS
+ * origins. Callbacks with a literal URL join the redirect URIs through
+ * `details.redirectUri`; the rest become a checklist for the dashboard.
+ * The clients come from the terraform detector
+ * (`details.terraform: 'auth0_client'`).
+ */
+
+import type { MigrationFinding, TerraformClient } from './types.js';
+
+/** A URL the redirect URI list already takes (see redirect-uris.ts) */
+const LITERAL_URL_PATTERN = /^https?:\/\/[^\s{}]+$/;
+
+export function isTerraformFile(file: string): boolean {
+  return /\.tf$/.test(file);
+}
+
+function stringList(value: unknown): string[] {
+  return Array.isArray(value) ? value.filter((v): v is string => typeof v === 'string') : [];
+}
+
+/** The Terraform-managed Auth0 applications in the findings, one per resource */
+export function extractTerraformClients(findings: MigrationFinding[]): TerraformClient[] {
+  const clients = new Map<string, TerraformClient>();
+  for (const finding of findings) {
+    const { terraform, resource, name } = finding.details ?? {};
+    if (terraform !== 'auth0_client' || typeof resource !== 'string' || clients.has(resource)) continue;
+    clients.set(resource, {
+      resource,
+      ...(typeof name === 'string' && { name }),
+      file: finding.file,
+      ...(finding.line !== undefined && { line: finding.line }),
+      callbacks: stringList(finding.details?.callbacks),
+      logoutUrls: stringList(finding.details?.logoutUrls),
+      webOrigins: stringList(finding.details?.webOrigins),
+    });
+  }
+  return [...clients.values()];
+}
+
+/**
+ * What to set in the WorkOS dashboard to match the clients, beyond the
+ * redirect URIs listed already: callbacks built from Terraform variables,
+ * sign-out redirects and allowed web origins.
+ */
+export function terraformChecklist(clients: TerraformClient[]): string[] {
+  const items = new Set<string>();
+  for (const client of clients) {
+    for (const callback of client.callbacks.filter((c) => !LITERAL_URL_PATTERN.test(c))) {
+      items.add(`Redirect URI ${callback} (fill in the Terraform variables)`);
+    }
+    for (const url of client.logoutUrls) items.add(`Sign-out redirect ${url}`);
+    for (const origin of client.webOrigins) items.add(`Allowed web origin ${origin}`);
+  }
+  return [...items];
+}
+
+/** The clients' resource addresses and files, for the prompt and the logs */
+export function terraformClientLines(clients: TerraformClient[]): string[] {
+  return clients.map((client) => {
+    const location = client.line ? `${client.file}:${client.line}` : client.file;
+    return `${location}: ${client.resource}${client.name ? ` ("${client.name}")` : ''}`;
+  });
+}
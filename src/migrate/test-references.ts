@@ -0,0 +1,61 @@
+/**
+ * Tests that still reference the old provider.
+ *
+ * Test suites often fake the provider (an httptest server serving its OIDC
+ * discovery document, a hand-signed id_token, a mocked SDK module) and keep
+ * passing after the app code moves to AuthKit while asserting nothing real.
+ * The migration doesn't rewrite them; they're listed for the user to update.
+ * Any finding in a test file counts, whichever detector reported it, and the
+ * test-references detector adds what each one fakes (`details.mocks`).
+ */
+
+import type { MigrationFinding, TestMock, TestReference } from './types.js';
+
+/** `*.test.ts`, `*.spec.js`, `*_test.go`, `FooTest.java`, and files under test directories */
+export const TEST_FILE_PATTERN = new RegExp(
+  [
+    '\\.(test|spec)\\.[^/]+$',
+    '_test\\.(go|py)$',
+    '(^|/)test_[^/]+\\.py$',
+    '[A-Z]\\w*Tests?\\.(java|kt|cs)$',
+    '(^|/)(__tests__|tests?|spec)/',
+  ].join('|'),
+);
+
+export function isTestFile(file: string): boolean {
+  return TEST_FILE_PATTERN.test(file);
+}
+
+const MOCKS = new Set<TestMock>(['discovery', 'token', 'module', 'server']);
+
+const MOCK_LABELS: Record<TestMock, string> = {
+  discovery: 'OIDC discovery or JWKS',
+  token: 'tokens',
+  module: 'SDK module',
+  server: 'HTTP server',
+};
+
+/** One entry per test file, first location kept, fakes merged across findings */
+export function extractTestReferences(findings: MigrationFinding[]): TestReference[] {
+  const references = new Map<string, TestReference>();
+  for (const finding of findings) {
+    if (!isTestFile(finding.file)) continue;
+    const reference = references.get(finding.file) ?? { file: finding.file, line: finding.line, mocks: [] };
+    const mocks = finding.details?.mocks;
+    if (Array.isArray(mocks)) {
+      for (const mock of mocks) {
+        if (MOCKS.has(mock) && !reference.mocks.includes(mock)) reference.mocks.push(mock);
+      }
+    }
+    references.set(finding.file, reference);
+  }
+  return [...references.values()];
+}
+
+/** One line per test file, for the prompt, the plan and the summary */
+export function testReferenceLines(references: TestReference[]): string[] {
+  return references.map(({ file, line, mocks }) => {
+    const location = line ? `${file}:${line}` : file;
+    return mocks.length > 0 ? `${location} (fakes ${mocks.map((m) => MOCK_LABELS[m]).join(', ')})` : location;
+  });
+}
@@ -0,0 +1,65 @@
+/**
+ * Hand-rolled token verification a migrated service has to repoint.
+ *
+ * Detectors record the key set URL and issuer a service verifies tokens
+ * against (`details.jwksUrl`, `details.issuer`, or the env vars holding
+ * them). AuthKit access tokens are signed with the WorkOS app's key set, so
+ * each verifier maps to the WorkOS JWKS and the AuthKit issuer for the
+ * client ID, and the agent is told which call sites to change.
+ */
+
+import type { MigrationFinding, TokenVerifier } from './types.js';
+
+/** Key set AuthKit access tokens are signed with */
+export function authkitJwksUrl(clientId = '<WORKOS_CLIENT_ID>'): string {
+  return `https://api.workos.com/sso/jwks/${clientId}`;
+}
+
+/** Issuer (`iss`) of AuthKit access tokens, without a custom auth domain */
+export function authkitIssuer(clientId = '<WORKOS_CLIENT_ID>'): string {
+  return `https://api.workos.com/user_management/${clientId}`;
+}
+
+function stringDetail(finding: MigrationFinding, key: string): string | undefined {
+  const value = finding.details?.[key];
+  return typeof value === 'string' ? value : undefined;
+}
+
+/** Verifiers found by the detectors, one per key set, first location kept */
+export function extractTokenVerifiers(findings: MigrationFinding[]): TokenVerifier[] {
+  const verifiers = new Map<string, TokenVerifier>();
+  for (const finding of findings) {
+    if (finding.code !== 'go-jwks-verification') continue;
+    const jwksUrl = stringDetail(finding, 'jwksUrl');
+    const jwksEnvVar = stringDetail(finding, 'jwksEnvVar');
+    const issuer = stringDetail(finding, 'issuer');
+    const issuerEnvVar = stringDetail(finding, 'issuerEnvVar');
+    const key = jwksUrl ?? jwksEnvVar ?? `${finding.file}:${finding.line}`;
+    const existing = verifiers.get(key);
+    if (existing) {
+      existing.callSites.push({ file: finding.file, line: finding.line });
+      continue;
+    }
+    verifiers.set(key, {
+      ...(jwksUrl && { jwksUrl }),
+      ...(jwksEnvVar && { jwksEnvVar }),
+      ...(issuer && { issuer }),
+      ...(issuerEnvVar && { issuerEnvVar }),
+      callSites: [{ file: finding.file, line: finding.line }],
+    });
+  }
+  return [...verifiers.values()];
+}
+
+/** Prompt lines mapping each verifier to AuthKit's key set and issuer */
+export function tokenVerificationLines(verifiers: TokenVerifier[]): string[] {
+  return verifiers.map((v) => {
+    const from = v.jwksUrl ?? (v.jwksEnvVar ? `the URL in ${v.jwksEnvVar}` : 'a JWKS endpoint');
+    const issuer = v.issuer ?? (v.issuerEnvVar ? `the issuer in ${v.issuerEnvVar}` : undefined);
+    const sites = v.callSites.map((s) => (s.line ? `${s.file}:${s.line}` : s.file)).join(', ');
+    return (
+      `- ${sites}: keys from ${from} → ${authkitJwksUrl()}` +
+      (issuer ? `; issuer ${issuer} → ${authkitIssuer()}` : `; check the issuer is ${authkitIssuer()}`)
+    );
+  });
+}
@@ -0,0 +1,387 @@
+import type { MigrationCategory } from './scope.js';
+import type { TemplateOverrides } from './templates.js';
+
+export type FindingSeverity = 'info' | 'warning';
+
+/**
+ * Provider of findings that hold whichever provider the app uses, such as
+ * multi-tenancy signals. They're listed under every match instead of
+ * forming one of their own.
+ */
+export const ANY_PROVIDER = '*';
+
+/**
+ * A single piece of evidence that a project uses another auth provider,
+ * with a pointer to what has to change for AuthKit.
+ */
+export interface MigrationFinding {
+  /** Auth provider being migrated from, e.g. 'auth0', or ANY_PROVIDER */
+  provider: string;
+  /** Stable identifier for the kind of finding, e.g. 'socialite-driver-usage' */
+  code: string;
+  severity: FindingSeverity;
+  message: string;
+  /** Path relative to the scan root (POSIX separators) */
+  file: string;
+  line?: number;
+  /** Matching source line, trimmed */
+  evidence?: string;
+  remediation?: string;
+  /** No mechanical replacement exists; someone has to rewrite this by hand */
+  manual?: boolean;
+  /** AuthKit has nothing that does the same; the behaviour has to be redesigned or dropped */
+  noEquivalent?: boolean;
+  /** Provider usage unrelated to auth (e.g. database queries); reported so it's left alone */
+  outOfScope?: boolean;
+  /** How strongly this finding indicates the provider (0-1) */
+  confidence: number;
+  /** Detector-specific data, e.g. env var mappings */
+  details?: Record<string, unknown>;
+}
+
+/**
+ * Read-only view of the project being scanned.
+ * Files are listed once and reads are cached across detectors. An
+ * incremental scan lists only changed files, but any file can be read.
+ */
+export interface ScanSkipped {
+  /** Over the size limit */
+  size: string[];
+  /** Null bytes in the content */
+  binary: string[];
+}
+
+/** A file the walk left out because of a .gitignore or .workosignore rule */
+export interface IgnoredPath {
+  file: string;
+  /** e.g. { source: '.workosignore', line: 3, pattern: 'third_party/' } */
+  rule: { source: string; line: number; pattern: string };
+}
+
+export interface ScanContext {
+  root: string;
+  /** All scannable files, relative to root */
+  files(): Promise<string[]>;
+  /** File contents, or null if missing/unreadable */
+  readFile(relPath: string): Promise<string | null>;
+  /** Files read so far that were skipped as too large or binary */
+  skipped(): ScanSkipped;
+  /** Files listed so far that an ignore file's rule left out */
+  ignored(): IgnoredPath[];
+  /**
+   * Whether an OIDC issuer serves a discovery document; null when it couldn't
+   * be checked. Absent when discovery is off, which detectors treat as null.
+   */
+  resolveIssuer?(issuer: string): Promise<boolean | null>;
+}
+
+export interface ProviderDetector {
+  /** Unique detector name, e.g. 'laravel-socialite' */
+  name: string;
+  /** One line on what it looks for, for `workos providers` */
+  description: string;
+  /** Language of the projects this detector understands (matches SdkInfo.language) */
+  language: string;
+  /** Files the detector reads; `--since` skips it when none of them changed */
+  files: RegExp;
+  detect(ctx: ScanContext): Promise<MigrationFinding[]>;
+}
+
+/** A finding shown as a reason a provider was detected */
+export interface DetectionEvidence {
+  file: string;
+  line?: number;
+  message: string;
+  /** The provider env var the finding matched, if any */
+  envVar?: string;
+  confidence: number;
+}
+
+export interface ProviderMatch {
+  provider: string;
+  /** Combined confidence across all findings (0-1) */
+  confidence: number;
+  findings: MigrationFinding[];
+  /** The strongest few findings, for reviewing the detection */
+  evidence?: DetectionEvidence[];
+  /** Set when the match is kept despite falling below the confidence threshold (--include-all) */
+  belowThreshold?: boolean;
+}
+
+export interface DetectionResult {
+  root: string;
+  /** Providers with at least one finding, most likely first */
+  matches: ProviderMatch[];
+  /** Threshold applied to `matches`, if any */
+  minConfidence?: number;
+  /** Matches dropped by the threshold */
+  suppressed?: number;
+  /** With --since: the ref compared against and the changed files that were scanned */
+  since?: { ref: string; files: string[] };
+  /** With --files-from: how many paths were listed and the ones detectors look at, which were scanned */
+  listed?: { count: number; files: string[] };
+  /** With --include/--exclude: the globs applied and how many files the walk kept */
+  paths?: { include: string[]; exclude: string[]; files: number };
+  /** Files detectors tried to read but were skipped as too large or binary; absent when none were */
+  skipped?: ScanSkipped;
+  /** Files the .gitignore and .workosignore rules kept out of the walk; absent when none were */
+  ignored?: IgnoredPath[];
+  /** The scan stopped early once `until` held, so later detectors didn't run */
+  partial?: boolean;
+}
+
+/** A callback URL the migrated app needs registered with the WorkOS app */
+export interface RedirectUri {
+  uri: string;
+  /** Where the old provider was configured with it */
+  file: string;
+  line?: number;
+  /** Whether the WorkOS app already has it; unset when it couldn't be checked */
+  registered?: boolean;
+}
+
+/**
+ * How the old config built its callback URL, so the migrated login can
+ * build it the same way: a literal URL, an env var holding the URL, or an
+ * env var holding the base URL with `path` appended.
+ */
+export interface RedirectSource {
+  url?: string;
+  envVar?: string;
+  /** Appended to the env var's value, e.g. `/account/auth/callback` */
+  path?: string;
+}
+
+/** A service verifying tokens by hand against a provider's key set */
+export interface TokenVerifier {
+  /** Key set URL the tokens are checked against, when it's a literal */
+  jwksUrl?: string;
+  /** Env var holding the key set URL */
+  jwksEnvVar?: string;
+  /** Expected issuer, when it's a literal or implied by the key set URL */
+  issuer?: string;
+  issuerEnvVar?: string;
+  /** The verification calls to repoint */
+  callSites: { file: string; line?: number }[];
+}
+
+/** A sign-out the migrated app has to end the AuthKit session from */
+export interface LogoutEndpoint {
+  /** 'federated' sends the user to the old provider's logout endpoint; 'local' only clears the app's session */
+  style: 'federated' | 'local';
+  file: string;
+  line?: number;
+  /** The old provider's logout endpoint or SDK call */
+  endpoint?: string;
+  /** Where the user was sent after signing out, when it's a literal */
+  returnTo?: string;
+  /** Env var holding the return URL */
+  returnToEnvVar?: string;
+  /** Also signed the user out of the upstream identity provider (Auth0 `federated`) */
+  federated?: boolean;
+}
+
+/** A provider SDK dependency whose code shape differs between major versions */
+export interface SdkVersion {
+  /** Package or Go module path, e.g. '@auth0/nextjs-auth0', 'github.com/coreos/go-oidc' */
+  sdk: string;
+  /** Manifest it was found in */
+  file: string;
+  line?: number;
+  /** Major version the migration targets */
+  major: number;
+  /** Major read from the manifest; null when it couldn't be determined */
+  detectedMajor: number | null;
+  /** Taken from --assume-provider-version, or the latest major when undetermined */
+  assumed: boolean;
+  /** How that major's code looks, for the agent to find and replace */
+  guidance: string;
+}
+
+/**
+ * How AuthKit covers a scope the old provider requested: `supported` as is,
+ * `organization` through organization membership, `roles` through roles and
+ * permissions, `unsupported` not at all.
+ */
+export type ScopeSupport = 'supported' | 'organization' | 'roles' | 'unsupported';
+
+/** A scope the old login flow requested */
+export interface ScopeMapping {
+  scope: string;
+  support: ScopeSupport;
+  /** What to configure instead, when it isn't supported as is */
+  note?: string;
+  file: string;
+  line?: number;
+}
+
+/** A token claim the code reads, and where AuthKit provides it */
+export interface ClaimMapping {
+  claim: string;
+  /** AuthKit claim or user field; null when it has to come from a JWT template */
+  authkit: string | null;
+  note?: string;
+  file: string;
+  line?: number;
+}
+
+/** A sign that the app is multi-tenant, mapped to AuthKit organizations */
+export interface TenancySignal {
+  /** A tenant claim read, a tenant route parameter or a tenant column */
+  kind: 'claim' | 'route' | 'column';
+  name: string;
+  file: string;
+  line?: number;
+}
+
+/** Where the old code kept refresh tokens */
+export type RefreshTokenStorage = 'cookie' | 'session' | 'database';
+
+/** Offline access or refresh-token handling the default migration doesn't carry over */
+export interface RefreshTokenUse {
+  /**
+   * `offline_access` requested, a TokenSource refreshing on its own, a
+   * refresh_token grant, or a refresh token stored by the app
+   */
+  kind: 'scope' | 'token-source' | 'grant' | 'stored';
+  /** Set for stored refresh tokens */
+  storage?: RefreshTokenStorage;
+  file: string;
+  line?: number;
+}
+
+/** CSRF and replay protection in the old login flow, kept as AuthKit's state check */
+export interface OAuthStateUse {
+  /**
+   * A fixed state value (no CSRF protection), a random value generated per
+   * sign-in, or a callback that checks the value it gets back
+   */
+  kind: 'constant' | 'generated' | 'validated';
+  param: 'state' | 'nonce';
+  /** Where a generated value is kept until the callback */
+  storage?: 'cookie' | 'session';
+  file: string;
+  line?: number;
+}
+
+/** An Auth0 application managed in Terraform, whose settings the WorkOS app has to match */
+export interface TerraformClient {
+  /** The resource address, e.g. auth0_client.web */
+  resource: string;
+  /** The application's name in Auth0, when it's a literal */
+  name?: string;
+  file: string;
+  line?: number;
+  callbacks: string[];
+  /** Sign-out redirects (`allowed_logout_urls`) */
+  logoutUrls: string[];
+  /** CORS origins (`web_origins`) */
+  webOrigins: string[];
+}
+
+/** What a test fakes about the old provider */
+export type TestMock = 'discovery' | 'token' | 'module' | 'server';
+
+/** A test file that references the old provider; listed for the user, never rewritten */
+export interface TestReference {
+  file: string;
+  line?: number;
+  mocks: TestMock[];
+}
+
+/**
+ * Sign-in state in a template: a login or logout link, markup shown only
+ * to signed-in (or signed-out) users, or script reading the session cookie
+ */
+export type TemplateAuthKind = 'login' | 'logout' | 'conditional' | 'cookie';
+
+/** A place in an HTML, server-side or JSX template tied to the old sign-in */
+export interface TemplateReference {
+  kind: TemplateAuthKind;
+  file: string;
+  line?: number;
+  /** The URL, route name or condition as written, e.g. `/logout` or `.User` */
+  target: string;
+  /** Absolute URL of the old provider's hosted page, which has to be replaced */
+  hosted?: boolean;
+}
+
+/** A compose file, Dockerfile or compose env_file that sets provider env vars */
+export interface ContainerEnvFile {
+  file: string;
+  source: 'compose' | 'dockerfile' | 'env_file';
+}
+
+/** An env key renamed in the project's env files before the agent ran */
+export interface RenamedEnvKey {
+  file: string;
+  from: string;
+  to: string;
+  /** The value is a reference (`${...}`, `$VAR`), kept as written */
+  reference: boolean;
+  /** Set in container config, so images and containers have to be rebuilt */
+  container?: boolean;
+}
+
+/**
+ * Everything the installer needs to turn an install into a migration.
+ */
+export interface MigrationContext {
+  provider: string;
+  displayName: string;
+  /** Chosen with --provider instead of auto-detection */
+  forced: boolean;
+  confidence: number;
+  /** The strongest findings behind the detection */
+  evidence?: DetectionEvidence[];
+  /** Provider env var -> WorkOS env var (null = no longer needed) */
+  envMapping: Record<string, string | null>;
+  guidance: string[];
+  findings: MigrationFinding[];
+  /** Files the user left out of the migration; they must not be modified */
+  excludedFiles?: string[];
+  /** Callback URLs from the old configuration that must be registered with WorkOS */
+  redirectUris?: RedirectUri[];
+  /** Hand-rolled JWT verification to repoint at AuthKit's key set and issuer */
+  tokenVerifiers?: TokenVerifier[];
+  /** Sign-outs to repoint at the AuthKit logout URL, keeping where they return to */
+  logoutEndpoints?: LogoutEndpoint[];
+  /** Provider SDK majors, selecting which code shape the migration looks for */
+  sdkVersions?: SdkVersion[];
+  /** Scopes the old login requested, mapped to AuthKit */
+  scopes?: ScopeMapping[];
+  /** Claims the code reads from the old provider's tokens, mapped to AuthKit */
+  claims?: ClaimMapping[];
+  /** Multi-tenancy signals; when present, tenants become AuthKit organizations */
+  tenancy?: TenancySignal[];
+  /** Offline access and refresh-token handling to move to AuthKit session refresh */
+  refreshTokens?: RefreshTokenUse[];
+  /** OAuth state and nonce handling in the old login, to keep with AuthKit's state */
+  oauthState?: OAuthStateUse[];
+  /** Auth0 applications managed in Terraform (with --scan-terraform), for the dashboard checklist */
+  terraformClients?: TerraformClient[];
+  /** Test files that reference the old provider; the user updates them */
+  testReferences?: TestReference[];
+  /** Login and logout links and sign-in conditionals in templates, to repoint at AuthKit */
+  templateReferences?: TemplateReference[];
+  /** Container config setting provider env vars, renamed with the env files */
+  containerEnvFiles?: ContainerEnvFile[];
+  /** Env keys already renamed per envMapping */
+  renamedEnv?: RenamedEnvKey[];
+  /** Project templates replacing the code the migration writes */
+  templates?: TemplateOverrides;
+  /** Outcome of an earlier run that was only partly applied, to finish what it left */
+  previousSteps?: MigrationStep[];
+  /** Categories of change this run applies (`--only`); all of them when unset */
+  only?: MigrationCategory[];
+  /** Categories earlier `--only` runs already applied */
+  completedCategories?: MigrationCategory[];
+}
+
+/** What became of one file a migration run set out to change */
+export interface MigrationStep {
+  /** Relative to the project root (POSIX separators) */
+  file: string;
+  status: 'succeeded' | 'failed' | 'skipped';
+  reason?: string;
+}
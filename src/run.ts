@@ -2,9 +2,23 @@ import { readEnvironment } from './utils/environment.js';
 import { runWithCore } from './lib/run-with-core.js';
 import type { InstallerOptions } from './utils/types.js';
 import type { Integration } from './lib/constants.js';
+import type { MigrationContext } from './migrate/types.js';
+import type { SummaryFormat } from './utils/run-summary.js';
+import type { EventsFormat } from './lib/event-stream.js';
+import type { LocalSkill } from './lib/skill-authoring.js';
 import { createInstallerEventEmitter } from './lib/events.js';
 import path from 'path';
 import { EventEmitter } from 'events';
+import chalk from 'chalk';
+import clack from './utils/clack.js';
+import { findWorkspaceRoot, selectWorkspacePackage } from './lib/workspaces.js';
+import { detectExistingIntegration } from './lib/existing-integration.js';
+import { loadCustomInstructions } from './lib/custom-instructions.js';
+import { loadProjectHooks, unconfirmedHooksError, type ProjectHooks } from './lib/project-hooks.js';
+import { loadGitConventions } from './lib/git-conventions.js';
+import { findComposeProject, resolveComposeTarget } from './lib/compose.js';
+import { UserCancelledError } from './utils/errors.js';
+import { allowsProtectedBranchCommits } from './utils/git-utils.js';
 
 EventEmitter.defaultMaxListeners = 50;
 
@@ -12,6 +26,8 @@ type Args = {
   integration?: Integration;
   debug?: boolean;
   forceInstall?: boolean;
+  forceReinstall?: boolean;
+  instructions?: string;
   installDir?: string;
   default?: boolean;
   local?: boolean;
@@ -26,6 +42,28 @@ type Args = {
   noValidate?: boolean;
   noCommit?: boolean;
   direct?: boolean;
+  migration?: MigrationContext;
+  showDiffs?: boolean;
+  dryRun?: boolean;
+  diffOnly?: string;
+  keepBackups?: boolean;
+  summaryFormat?: SummaryFormat;
+  events?: EventsFormat;
+  eventsModule?: string;
+  json?: boolean;
+  reportPath?: string;
+  createPr?: boolean;
+  push?: boolean;
+  allowMain?: boolean;
+  maxRepairAttempts?: number;
+  skipChecks?: boolean;
+  noFormat?: boolean;
+  yes?: boolean;
+  timeout?: number;
+  idleTimeout?: number;
+  maxTokens?: number;
+  composeService?: string;
+  localSkill?: LocalSkill;
 };
 
 /**
@@ -34,9 +72,212 @@ type Args = {
  */
 export async function runInstaller(argv: Args): Promise<void> {
   const options = buildOptions(argv);
+  await scopeToWorkspacePackage(options);
+  await checkExistingIntegration(options);
+  loadProjectInstructions(options);
+  await resolveComposeService(options);
+  await confirmProjectHooks(options);
+  loadProjectGitConventions(options);
   await runWithCore(options);
 }
 
+/**
+ * Pick up the project's conventions for the agent, after the install
+ * directory is settled. A missing or oversized --instructions file stops
+ * the run before anything changes.
+ */
+function loadProjectInstructions(options: InstallerOptions): void {
+  let instructions;
+  try {
+    instructions = loadCustomInstructions(options.installDir, {
+      path: options.instructions,
+      workspaceRoot: options.workspaceRoot,
+    });
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+  if (!instructions) return;
+
+  const size = chalk.dim(`(${instructions.content.length} characters)`);
+  clack.log.info(`Using project instructions from ${chalk.cyan(instructions.source)} ${size}`);
+  options.customInstructions = instructions;
+}
+
+/**
+ * Apps that run in Docker Compose are reached on the port compose publishes
+ * and read their env from the service's env_file. An explicit
+ * --compose-service that can't be resolved stops the run; a detected
+ * compose file that can't be is reported and the run carries on as usual.
+ */
+async function resolveComposeService(options: InstallerOptions): Promise<void> {
+  let project;
+  try {
+    project = findComposeProject(options.installDir);
+  } catch (error) {
+    if (options.composeService) {
+      clack.log.error(error instanceof Error ? error.message : String(error));
+      process.exit(1);
+    }
+  }
+  if (!project) {
+    if (options.composeService) {
+      clack.log.error(`--compose-service was given, but there is no compose file in ${options.installDir}`);
+      process.exit(1);
+    }
+    return;
+  }
+
+  try {
+    options.compose = await resolveComposeTarget(options.installDir, project, options.composeService);
+  } catch (error) {
+    const message = error instanceof Error ? error.message : String(error);
+    if (options.composeService) {
+      clack.log.error(message);
+      process.exit(1);
+    }
+    clack.log.warn(`Found ${project.source}, but not using Docker Compose: ${message}`);
+    return;
+  }
+
+  const { service, containerPort, hostPort, envFile } = options.compose;
+  const env = envFile ? `env vars go to ${chalk.cyan(envFile)}` : chalk.yellow('no env_file, so using .env.local');
+  clack.log.info(
+    `Docker Compose service ${chalk.bold(service)}: port ${containerPort} is on localhost:${hostPort}, ${env}`,
+  );
+}
+
+function describeHooks(hooks: ProjectHooks): string {
+  return (['pre', 'post'] as const)
+    .flatMap((phase) =>
+      hooks[phase].map((h) => `  ${phase}: ${h.command}${h.required ? '' : chalk.dim(' (optional)')}`),
+    )
+    .join('\n');
+}
+
+/**
+ * Hooks from .workos/config.json run arbitrary commands, so they're shown
+ * and confirmed first unless --yes. CI can't confirm: it skips optional
+ * hooks and stops on required ones; dry runs don't run commands at all. A config that can't be parsed stops the
+ * run rather than silently dropping a required hook.
+ */
+async function confirmProjectHooks(options: InstallerOptions): Promise<void> {
+  let hooks;
+  try {
+    hooks = loadProjectHooks(options.installDir, { workspaceRoot: options.workspaceRoot });
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+  if (!hooks) return;
+
+  if (options.dryRun) {
+    clack.log.info('Dry run: skipping project hooks');
+    return;
+  }
+  const source = path.relative(options.installDir, hooks.source);
+  clack.log.info(`Project hooks from ${chalk.cyan(source)}:\n${describeHooks(hooks)}`);
+  if (!options.yes) {
+    if (options.ci) {
+      const error = unconfirmedHooksError(hooks);
+      if (error) {
+        clack.log.error(error);
+        process.exit(1);
+      }
+      clack.log.warn('Skipping optional project hooks in CI; pass --yes to run them');
+      return;
+    }
+    const confirmed = await clack.confirm({ message: 'Run these hooks?' });
+    if (clack.isCancel(confirmed)) throw new UserCancelledError();
+    if (!confirmed) {
+      clack.log.warn('Continuing without project hooks');
+      return;
+    }
+  }
+  options.projectHooks = hooks;
+}
+
+/**
+ * Branch and commit templates are checked now, so a branch name git would
+ * refuse stops the run before the agent changes anything.
+ */
+function loadProjectGitConventions(options: InstallerOptions): void {
+  try {
+    options.gitConventions = loadGitConventions(options.installDir, { workspaceRoot: options.workspaceRoot });
+  } catch (error) {
+    clack.log.error(error instanceof Error ? error.message : String(error));
+    process.exit(1);
+  }
+}
+
+/**
+ * A rerun in a project that already has AuthKit updates that integration
+ * instead of scaffolding a second one. Interactive runs can also reinstall
+ * or stop; CI always updates. `--force-reinstall` skips the check.
+ */
+async function checkExistingIntegration(options: InstallerOptions): Promise<void> {
+  if (options.forceReinstall || options.migration) return;
+  const existing = detectExistingIntegration(options.installDir);
+  if (!existing) return;
+
+  clack.log.info(
+    `WorkOS AuthKit is already set up here:\n${existing.markers.map((m) => chalk.dim(`  ${m}`)).join('\n')}`,
+  );
+
+  if (!options.ci) {
+    const choice = await clack.select({
+      message: 'What should the installer do?',
+      options: [
+        { value: 'update', label: 'Update and repair the existing integration', hint: 'recommended' },
+        { value: 'reinstall', label: 'Scaffold a new integration anyway' },
+        { value: 'exit', label: 'Exit' },
+      ],
+      flag: '--force-reinstall',
+    });
+    if (clack.isCancel(choice) || choice === 'exit') {
+      const skillsHint = `To refresh the skills your coding agent uses, run ${chalk.cyan('workos install-skill')}.`;
+      clack.outro(`Nothing changed. ${skillsHint}`);
+      process.exit(0);
+    }
+    if (choice === 'reinstall') return;
+  }
+
+  clack.log.info('Running in update mode: the agent reconciles the existing integration instead of adding another.');
+  options.existingIntegration = existing;
+}
+
+/**
+ * In a monorepo, point installDir at the workspace package that uses auth
+ * (or the framework) so dependency and env changes land there. Asks when
+ * several packages qualify.
+ */
+async function scopeToWorkspacePackage(options: InstallerOptions): Promise<void> {
+  const selection = selectWorkspacePackage(options.installDir);
+  if (!selection) {
+    const root = findWorkspaceRoot(options.installDir);
+    if (root && root !== options.installDir) options.workspaceRoot = root;
+    return;
+  }
+
+  const { candidates } = selection;
+  let target = candidates[0];
+  if (candidates.length > 1 && options.ci) {
+    clack.log.warn(`Several workspace packages qualify; using ${target.relativeDir}. Pass --install-dir to choose.`);
+  } else if (candidates.length > 1) {
+    const choice = await clack.select({
+      message: 'Which workspace package should AuthKit be added to?',
+      options: candidates.map((c) => ({ value: c.dir, label: c.relativeDir, hint: c.name })),
+      flag: '--install-dir',
+    });
+    if (clack.isCancel(choice)) throw new UserCancelledError();
+    target = candidates.find((c) => c.dir === choice) ?? target;
+  }
+
+  clack.log.info(`Monorepo detected: installing into ${chalk.cyan(target.relativeDir)}`);
+  options.workspaceRoot = selection.root;
+  options.installDir = target.dir;
+}
+
 /**
  * Build InstallerOptions from CLI args and environment.
  */
@@ -49,6 +290,8 @@ function buildOptions(argv: Args): InstallerOptions {
   return {
     debug: merged.debug ?? false,
     forceInstall: merged.forceInstall ?? false,
+    forceReinstall: merged.forceReinstall ?? false,
+    instructions: merged.instructions,
     installDir,
     local: merged.local ?? false,
     ci: merged.ci ?? false,
@@ -60,9 +303,31 @@ function buildOptions(argv: Args): InstallerOptions {
     dashboard: merged.dashboard ?? false,
     integration: merged.integration,
     inspect: merged.inspect ?? false,
-    noValidate: merged.noValidate ?? false,
-    noCommit: merged.noCommit ?? false,
+    // A dry run (a --diff-only run is one) has nothing to validate or commit
+    noValidate: merged.noValidate || merged.dryRun || Boolean(merged.diffOnly),
+    noCommit: merged.noCommit || merged.dryRun || Boolean(merged.diffOnly),
     direct: merged.direct ?? false,
+    migration: merged.migration,
+    showDiffs: merged.showDiffs ?? false,
+    dryRun: merged.dryRun || Boolean(merged.diffOnly),
+    diffOnly: merged.diffOnly,
+    keepBackups: merged.keepBackups ?? false,
+    summaryFormat: merged.json ? 'json' : merged.summaryFormat,
+    events: merged.events,
+    eventsModule: merged.eventsModule,
+    reportPath: merged.reportPath,
+    createPr: merged.createPr ?? false,
+    push: merged.push ?? false,
+    allowMain: merged.allowMain || allowsProtectedBranchCommits(installDir),
+    maxRepairAttempts: merged.maxRepairAttempts,
+    skipChecks: merged.skipChecks ?? false,
+    noFormat: merged.noFormat ?? false,
+    yes: merged.yes ?? false,
+    timeoutMs: merged.timeout,
+    idleTimeoutMs: merged.idleTimeout,
+    maxTokens: merged.maxTokens,
+    composeService: merged.composeService,
+    localSkill: merged.localSkill,
     emitter: createInstallerEventEmitter(), // Will be replaced in runWithCore
   };
 }
@@ -5,7 +5,7 @@ import clack from '../utils/clack.js';
 import { getPackageDotJson, getUncommittedOrUntrackedFiles, isInGitRepo } from '../utils/clack-utils.js';
 import { hasPackageInstalled } from '../utils/package-json.js';
 import type { InstallerOptions } from '../utils/types.js';
-import * as childProcess from 'node:child_process';
+import { execFileNoThrow } from '../utils/exec-file.js';
 
 export async function runPrettierStep({
   installDir,
@@ -20,11 +20,9 @@ export async function runPrettierStep({
       return;
     }
 
-    const changedOrUntrackedFiles = getUncommittedOrUntrackedFiles()
-      .map((filename) => {
-        return filename.startsWith('- ') ? filename.slice(2) : filename;
-      })
-      .join(' ');
+    const changedOrUntrackedFiles = getUncommittedOrUntrackedFiles().map((filename) => {
+      return filename.startsWith('- ') ? filename.slice(2) : filename;
+    });
 
     if (!changedOrUntrackedFiles.length) {
       // Likewise, if we can't find changed or untracked files, there's no point in running Prettier.
@@ -44,15 +42,10 @@ export async function runPrettierStep({
     prettierSpinner.start('Running Prettier on your files.');
 
     try {
-      await new Promise<void>((resolve, reject) => {
-        childProcess.exec(`npx prettier --ignore-unknown --write ${changedOrUntrackedFiles}`, (err) => {
-          if (err) {
-            reject(err);
-          } else {
-            resolve();
-          }
-        });
-      });
+      // File names are passed as arguments, not through a shell, so spaces and quotes survive
+      const args = ['prettier', '--ignore-unknown', '--write', ...changedOrUntrackedFiles];
+      const result = await execFileNoThrow('npx', args);
+      if (result.status !== 0) throw new Error(result.stderr);
     } catch (e) {
       prettierSpinner.stop('Prettier failed to run. You may want to format the changes manually.');
       return;
@@ -6,6 +6,7 @@ import type { InstallerOptions } from '../../../utils/types.js';
 import clack from '../../../utils/clack.js';
 import chalk from 'chalk';
 import { analytics } from '../../../utils/analytics.js';
+import { spawnTarget } from '../../../utils/windows.js';
 
 export class VercelEnvironmentProvider extends EnvironmentProvider {
   name = 'Vercel';
@@ -49,7 +50,9 @@ export class VercelEnvironmentProvider extends EnvironmentProvider {
   }
 
   isAuthenticated(): boolean {
-    const result = spawnSync('vercel', ['whoami'], {
+    const whoami = spawnTarget('vercel', ['whoami']);
+    const result = spawnSync(whoami.command, whoami.args, {
+      windowsVerbatimArguments: whoami.windowsVerbatimArguments,
       encoding: 'utf-8',
       stdio: ['pipe', 'pipe', 'pipe'], // suppress prompts
       env: {
@@ -73,8 +76,10 @@ export class VercelEnvironmentProvider extends EnvironmentProvider {
 
   async uploadEnvironmentVariable(key: string, value: string, environment: string): Promise<void> {
     await new Promise<void>((resolve, reject) => {
-      const proc = spawn('vercel', ['env', 'add', key, environment], {
+      const target = spawnTarget('vercel', ['env', 'add', key, environment]);
+      const proc = spawn(target.command, target.args, {
         stdio: ['pipe', 'pipe', 'pipe'],
+        windowsVerbatimArguments: target.windowsVerbatimArguments,
       });
 
       let stderr = '';
@@ -5,6 +5,7 @@ const mockSetGatewayUrl = vi.fn();
 const mockSetAccessToken = vi.fn();
 const mockQueueEvent = vi.fn();
 const mockFlush = vi.fn().mockResolvedValue(undefined);
+const mockSpool = vi.fn();
 
 vi.mock('./telemetry-client.js', () => ({
   telemetryClient: {
@@ -12,6 +13,7 @@ vi.mock('./telemetry-client.js', () => ({
     setAccessToken: mockSetAccessToken,
     queueEvent: mockQueueEvent,
     flush: mockFlush,
+    spool: mockSpool,
   },
 }));
 
@@ -26,16 +28,18 @@ vi.mock('uuid', () => ({
 }));
 
 describe('Analytics', () => {
-  // Need to handle WORKOS_TELEMETRY_ENABLED which is evaluated at import time
+  // WORKOS_TELEMETRY (and DO_NOT_TRACK) are read whenever an event is captured
   const originalEnv = process.env.WORKOS_TELEMETRY;
 
   beforeEach(() => {
     vi.clearAllMocks();
     // Ensure telemetry is enabled for tests
     delete process.env.WORKOS_TELEMETRY;
+    vi.stubEnv('DO_NOT_TRACK', '');
   });
 
   afterEach(() => {
+    vi.unstubAllEnvs();
     if (originalEnv !== undefined) {
       process.env.WORKOS_TELEMETRY = originalEnv;
     } else {
@@ -56,6 +60,7 @@ describe('Analytics', () => {
           setAccessToken: mockSetAccessToken,
           queueEvent: mockQueueEvent,
           flush: mockFlush,
+          spool: mockSpool,
         },
       }));
       const module = await import('./analytics.js');
@@ -63,21 +68,6 @@ describe('Analytics', () => {
       analytics = new Analytics();
     });
 
-    describe('setDistinctId', () => {
-      it('stores the distinct ID for later use', () => {
-        analytics.setDistinctId('user-123');
-        analytics.sessionStart('cli', '1.0.0');
-
-        expect(mockQueueEvent).toHaveBeenCalledWith(
-          expect.objectContaining({
-            attributes: expect.objectContaining({
-              'workos.user_id': 'user-123',
-            }),
-          }),
-        );
-      });
-    });
-
     describe('setAccessToken', () => {
       it('forwards to telemetry client', () => {
         analytics.setAccessToken('token-abc');
@@ -194,17 +184,13 @@ describe('Analytics', () => {
         );
       });
 
-      it('includes user ID if set', () => {
-        analytics.setDistinctId('user-456');
+      it('includes the OS and architecture but no user ID', () => {
         analytics.sessionStart('cli', '1.0.0');
 
-        expect(mockQueueEvent).toHaveBeenCalledWith(
-          expect.objectContaining({
-            attributes: expect.objectContaining({
-              'workos.user_id': 'user-456',
-            }),
-          }),
-        );
+        const event = mockQueueEvent.mock.calls.find((c) => c[0].type === 'session.start')[0];
+        expect(event.attributes['os.platform']).toBe(process.platform);
+        expect(event.attributes['os.arch']).toBe(process.arch);
+        expect(event.attributes['workos.user_id']).toBeUndefined();
       });
     });
 
@@ -232,9 +218,10 @@ describe('Analytics', () => {
         expect(event.attributes['installer.duration_ms']).toBeGreaterThanOrEqual(0);
       });
 
-      it('flushes events to telemetry client', async () => {
+      it('spools events for the background sender instead of sending them', async () => {
         await analytics.shutdown('success');
-        expect(mockFlush).toHaveBeenCalled();
+        expect(mockSpool).toHaveBeenCalled();
+        expect(mockFlush).not.toHaveBeenCalled();
       });
 
       it('supports error outcome', async () => {
@@ -387,6 +374,7 @@ describe('Analytics', () => {
           setAccessToken: mockSetAccessToken,
           queueEvent: mockQueueEvent,
           flush: mockFlush,
+          spool: mockSpool,
         },
       }));
     });
@@ -425,7 +413,7 @@ describe('Analytics', () => {
       await analytics.shutdown('success');
 
       expect(mockQueueEvent).not.toHaveBeenCalled();
-      expect(mockFlush).not.toHaveBeenCalled();
+      expect(mockSpool).not.toHaveBeenCalled();
     });
 
     it('stepCompleted does nothing', async () => {
@@ -1,3 +1,4 @@
+import { arch, platform } from 'node:os';
 import { v4 as uuidv4 } from 'uuid';
 import { debug } from './debug.js';
 import { telemetryClient } from './telemetry-client.js';
@@ -8,13 +9,12 @@ import type {
   AgentToolEvent,
   AgentLLMEvent,
 } from './telemetry-types.js';
-import { WORKOS_TELEMETRY_ENABLED } from '../lib/constants.js';
+import { isTelemetryEnabled } from './telemetry-consent.js';
 
 export class Analytics {
   private tags: Record<string, string | boolean | number | null | undefined> = {};
   private sessionId: string;
   private sessionStartTime: Date;
-  private distinctId?: string;
 
   // Agent metrics tracking
   private totalInputTokens = 0;
@@ -27,10 +27,6 @@ export class Analytics {
     this.tags = { $app_name: 'authkit-installer' };
   }
 
-  setDistinctId(distinctId: string) {
-    this.distinctId = distinctId;
-  }
-
   setAccessToken(token: string) {
     telemetryClient.setAccessToken(token);
   }
@@ -44,7 +40,7 @@ export class Analytics {
   }
 
   capture(eventName: string, properties?: Record<string, unknown>) {
-    if (!WORKOS_TELEMETRY_ENABLED) return;
+    if (!isTelemetryEnabled()) return;
 
     debug(`[Analytics] capture: ${eventName}`, properties);
 
@@ -59,7 +55,7 @@ export class Analytics {
   }
 
   captureException(error: Error, properties: Record<string, unknown> = {}) {
-    if (!WORKOS_TELEMETRY_ENABLED) return;
+    if (!isTelemetryEnabled()) return;
 
     debug('[Analytics] captureException:', error.message, properties);
     this.tags['error.type'] = error.name;
@@ -72,7 +68,7 @@ export class Analytics {
   }
 
   sessionStart(mode: 'cli' | 'tui', version: string) {
-    if (!WORKOS_TELEMETRY_ENABLED) return;
+    if (!isTelemetryEnabled()) return;
 
     const event: SessionStartEvent = {
       type: 'session.start',
@@ -81,7 +77,8 @@ export class Analytics {
       attributes: {
         'installer.version': version,
         'installer.mode': mode,
-        'workos.user_id': this.distinctId,
+        'os.platform': platform(),
+        'os.arch': arch(),
       },
     };
 
@@ -89,7 +86,7 @@ export class Analytics {
   }
 
   stepCompleted(name: string, durationMs: number, success: boolean, error?: Error) {
-    if (!WORKOS_TELEMETRY_ENABLED) return;
+    if (!isTelemetryEnabled()) return;
 
     const event: StepEvent = {
       type: 'step',
@@ -105,7 +102,7 @@ export class Analytics {
   }
 
   toolCalled(toolName: string, durationMs: number, success: boolean) {
-    if (!WORKOS_TELEMETRY_ENABLED) return;
+    if (!isTelemetryEnabled()) return;
 
     const event: AgentToolEvent = {
       type: 'agent.tool',
@@ -120,7 +117,7 @@ export class Analytics {
   }
 
   llmRequest(model: string, inputTokens: number, outputTokens: number) {
-    if (!WORKOS_TELEMETRY_ENABLED) return;
+    if (!isTelemetryEnabled()) return;
 
     this.totalInputTokens += inputTokens;
     this.totalOutputTokens += outputTokens;
@@ -142,7 +139,7 @@ export class Analytics {
   }
 
   async shutdown(status: 'success' | 'error' | 'cancelled') {
-    if (!WORKOS_TELEMETRY_ENABLED) return;
+    if (!isTelemetryEnabled()) return;
 
     const duration = Date.now() - this.sessionStartTime.getTime();
 
@@ -167,7 +164,8 @@ export class Analytics {
     };
 
     telemetryClient.queueEvent(event);
-    await telemetryClient.flush();
+    // Sent by the background sender once the command exits, never inline
+    telemetryClient.spool();
   }
 }
 
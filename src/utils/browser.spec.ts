@@ -0,0 +1,50 @@
+import { describe, it, expect } from 'vitest';
+import { isWsl, loopbackHost, windowsOpenCommand } from './browser.js';
+
+const WSL2_VERSION = 'Linux version 5.15.153.1-microsoft-standard-WSL2 (root@941d701f84f1) (gcc (GCC) 11.2.0) #1 SMP';
+const WSL1_VERSION = 'Linux version 4.4.0-19041-Microsoft (Microsoft@Microsoft.com) (gcc version 5.4.0 (GCC) ) #1237';
+const UBUNTU_VERSION = 'Linux version 6.8.0-45-generic (buildd@lcy02-amd64-075) (x86_64-linux-gnu-gcc-13)';
+
+describe('browser', () => {
+  describe('isWsl', () => {
+    it('recognizes WSL 1 and 2 from /proc/version', () => {
+      expect(isWsl({ platform: 'linux', env: {}, procVersion: WSL2_VERSION })).toBe(true);
+      expect(isWsl({ platform: 'linux', env: {}, procVersion: WSL1_VERSION })).toBe(true);
+      expect(isWsl({ platform: 'linux', env: {}, procVersion: UBUNTU_VERSION })).toBe(false);
+    });
+
+    it('recognizes WSL from its environment, e.g. inside a container without the WSL kernel string', () => {
+      expect(isWsl({ platform: 'linux', env: { WSL_DISTRO_NAME: 'Ubuntu' }, procVersion: '' })).toBe(true);
+      expect(isWsl({ platform: 'linux', env: { WSL_INTEROP: '/run/WSL/8_interop' }, procVersion: '' })).toBe(true);
+    });
+
+    it('is never WSL off Linux', () => {
+      expect(isWsl({ platform: 'win32', env: { WSL_DISTRO_NAME: 'Ubuntu' }, procVersion: WSL2_VERSION })).toBe(false);
+      expect(isWsl({ platform: 'darwin', env: {}, procVersion: '' })).toBe(false);
+    });
+  });
+
+  describe('loopbackHost', () => {
+    it('listens on every interface only under WSL', () => {
+      expect(loopbackHost({ platform: 'linux', env: {}, procVersion: WSL2_VERSION })).toBe('0.0.0.0');
+      expect(loopbackHost({ platform: 'linux', env: {}, procVersion: UBUNTU_VERSION })).toBe('127.0.0.1');
+      expect(loopbackHost({ platform: 'darwin', env: {} })).toBe('127.0.0.1');
+    });
+  });
+
+  describe('windowsOpenCommand', () => {
+    it('opens the URL in the default browser', () => {
+      expect(windowsOpenCommand('https://example.com/?a=1&b=2')).toBe("Start-Process 'https://example.com/?a=1&b=2'");
+    });
+
+    it('doubles single quotes in the URL', () => {
+      expect(windowsOpenCommand("https://example.com/it's")).toBe("Start-Process 'https://example.com/it''s'");
+    });
+
+    it('passes app arguments before the URL', () => {
+      expect(windowsOpenCommand('https://example.com', ['chrome', '--profile-directory=Profile 2'])).toBe(
+        "Start-Process -FilePath 'chrome' -ArgumentList '--profile-directory=Profile 2','https://example.com'",
+      );
+    });
+  });
+});
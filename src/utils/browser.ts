@@ -0,0 +1,95 @@
+/**
+ * Opening URLs in the user's browser, including from inside WSL.
+ *
+ * Under WSL the browser runs on Windows: there is usually no `xdg-open`,
+ * and a server listening on 127.0.0.1 inside the Linux VM isn't always
+ * forwarded to the Windows `localhost`. URLs open through `wslview` (from
+ * wslu) or PowerShell's `Start-Process`, and local servers the browser
+ * has to reach listen on every interface so WSL's localhost forwarding
+ * picks them up.
+ */
+
+import { spawn } from 'node:child_process';
+import { readFileSync } from 'node:fs';
+import open from 'opn';
+import type { PlatformOptions } from './windows.js';
+
+export interface WslOptions extends PlatformOptions {
+  /** Contents of /proc/version; read from disk when omitted */
+  procVersion?: string;
+}
+
+function readProcVersion(): string {
+  try {
+    return readFileSync('/proc/version', 'utf-8');
+  } catch {
+    return '';
+  }
+}
+
+/** Whether the CLI runs inside the Windows Subsystem for Linux (1 or 2) */
+export function isWsl({ platform = process.platform, env = process.env, procVersion }: WslOptions = {}): boolean {
+  if (platform !== 'linux') return false;
+  if (env.WSL_DISTRO_NAME || env.WSL_INTEROP) return true;
+  return /microsoft/i.test(procVersion ?? readProcVersion());
+}
+
+/**
+ * Address for a local server a browser has to reach. Loopback only, except
+ * under WSL, where the browser is outside the VM.
+ */
+export function loopbackHost(options: WslOptions = {}): string {
+  return isWsl(options) ? '0.0.0.0' : '127.0.0.1';
+}
+
+/** Single-quoted PowerShell string; a quote inside is doubled */
+function powershellString(value: string): string {
+  return `'${value.replace(/'/g, "''")}'`;
+}
+
+/**
+ * PowerShell command opening a URL on the Windows side, in the default
+ * browser or, with `app`, in that program with extra arguments before the URL.
+ */
+export function windowsOpenCommand(url: string, app?: string[]): string {
+  if (!app || app.length === 0) return `Start-Process ${powershellString(url)}`;
+  const [program, ...args] = app;
+  const argumentList = [...args, url].map(powershellString).join(',');
+  return `Start-Process -FilePath ${powershellString(program)} -ArgumentList ${argumentList}`;
+}
+
+/** Start a program without waiting for it; false when it can't be started */
+function launch(command: string, args: string[]): Promise<boolean> {
+  return new Promise((resolve) => {
+    const child = spawn(command, args, { stdio: 'ignore', detached: true });
+    child.once('error', () => resolve(false));
+    child.once('spawn', () => {
+      child.unref();
+      resolve(true);
+    });
+  });
+}
+
+export interface OpenBrowserOptions extends WslOptions {
+  /** Program (and its arguments) to open the URL with instead of the default browser */
+  app?: string[];
+}
+
+/**
+ * Open a URL in the browser. Never throws: resolves to false when no
+ * browser could be started, so callers can tell the user to open it.
+ */
+export async function openBrowser(url: string, options: OpenBrowserOptions = {}): Promise<boolean> {
+  if (isWsl(options)) {
+    if (!options.app && (await launch('wslview', [url]))) return true;
+    const command = windowsOpenCommand(url, options.app);
+    return launch('powershell.exe', ['-NoProfile', '-NonInteractive', '-Command', command]);
+  }
+
+  try {
+    await open(url, { wait: false, ...(options.app && { app: options.app }) });
+    return true;
+  } catch {
+    return false;
+  }
+}
@@ -1,6 +1,5 @@
 import * as childProcess from 'node:child_process';
 import * as fs from 'node:fs';
-import * as os from 'node:os';
 import { basename, isAbsolute, join, relative } from 'node:path';
 
 import chalk from 'chalk';
@@ -100,6 +99,7 @@ export async function confirmContinueIfNoOrDirtyGitRepo(options: Pick<InstallerO
             clack.confirm({
               message:
                 'You are not inside a git repository. The installer will create and update files. Do you want to continue anyway?',
+              flag: '--ci',
             }),
           );
 
@@ -131,6 +131,7 @@ The installer will create and update files.`,
       const continueWithDirtyRepo = await abortIfCancelled(
         clack.confirm({
           message: 'Do you want to continue anyway?',
+          flag: '--ci',
         }),
       );
 
@@ -163,8 +164,9 @@ export function getUncommittedOrUntrackedFiles(): string[] {
       })
       .toString();
 
+    // git ends lines with \n on every platform, not os.EOL
     const files = gitStatus
-      .split(os.EOL)
+      .split(/\r?\n/)
       .map((line) => line.trim())
       .filter(Boolean)
       .map((f) => `- ${f.split(/\s+/)[1]}`);
@@ -491,6 +493,7 @@ export async function getPackageManager(
         value: packageManager,
         label: packageManager.label,
       })),
+      flag: '--ci',
     }),
   );
 
@@ -563,6 +566,7 @@ export async function getOrAskForWorkOSCredentials(
     apiKey = (await abortIfCancelled(
       clack.password({
         message: 'Enter your WorkOS API Key',
+        flag: '--api-key',
         validate: (value: string) => {
           if (!value) return 'API Key is required';
           if (!value.startsWith('sk_')) {
@@ -581,6 +585,7 @@ export async function getOrAskForWorkOSCredentials(
       clack.text({
         message: 'Enter your WorkOS Client ID',
         placeholder: 'client_...',
+        flag: '--client-id',
         validate: (value: string) => {
           if (!value) return 'Client ID is required';
           if (!value.startsWith('client_')) {
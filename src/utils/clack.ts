@@ -1,4 +1,16 @@
 import * as clack from '@clack/prompts';
+import {
+  PLAIN_CANCEL,
+  plainConfirm,
+  plainMultiselect,
+  plainPassword,
+  plainSelect,
+  plainSpinner,
+  plainText,
+} from './plain-prompts.js';
+import { supportsVirtualTerminal } from './windows.js';
+import { isMachineMode, machinePrompt } from './machine-prompts.js';
+import type { MachinePromptKind } from '../lib/installer-proto.js';
 
 // Dashboard mode flag - when true, suppress console output
 let dashboardMode = false;
@@ -11,11 +23,146 @@ export function isDashboardMode(): boolean {
   return dashboardMode;
 }
 
+// Quiet mode (-q): only errors, warnings and the final result are printed
+let quietMode = false;
+
+export function setQuietMode(enabled: boolean): void {
+  quietMode = enabled;
+}
+
+export function isQuietMode(): boolean {
+  return quietMode;
+}
+
+/**
+ * Logging in quiet mode. Warnings stay, since they can mean the result is
+ * wrong or incomplete; they go to stderr with errors so stdout only carries
+ * the result.
+ */
+const quietLog = {
+  info: () => {},
+  success: () => {},
+  step: () => {},
+  message: () => {},
+  warn: (message: string) => console.error(`Warning: ${message}`),
+  warning: (message: string) => console.error(`Warning: ${message}`),
+  error: (message: string) => console.error(message),
+};
+
+const quietSpinner = () => ({ start: () => {}, message: () => {}, stop: () => {} });
+
+// Which environment commands are pointed at, shown before every prompt's question
+let promptPrefix: string | null = null;
+
+export function setPromptPrefix(prefix: string | null): void {
+  promptPrefix = prefix;
+}
+
+/** A prompt's message with the environment prefix, for prompts outside this wrapper */
+export function withPromptPrefix(message: string): string {
+  return promptPrefix ? `${promptPrefix} ${message}` : message;
+}
+
+/**
+ * How prompts can be shown: clack's interactive prompts, line-based
+ * fallbacks when the terminal has no raw mode or cursor control, JSON
+ * messages under `--machine`, or not at all when stdin is piped or closed.
+ */
+export type PromptMode = 'rich' | 'plain' | 'machine' | 'none';
+
+/** Whether stdout takes cursor movement: a TTY that isn't dumb and understands ANSI (old Windows consoles don't) */
+export function hasCursorControl(): boolean {
+  return Boolean(process.stdout.isTTY) && process.env.TERM !== 'dumb' && supportsVirtualTerminal();
+}
+
+export function getPromptMode(): PromptMode {
+  if (isMachineMode()) return 'machine';
+  if (!process.stdin.isTTY) return 'none';
+  if (typeof process.stdin.setRawMode !== 'function' || !hasCursorControl()) {
+    return 'plain';
+  }
+  return 'rich';
+}
+
+/**
+ * A prompt was needed but stdin isn't interactive, e.g. `echo y | workos install`.
+ */
+export class NonInteractiveError extends Error {
+  constructor(
+    question: string,
+    readonly flag?: string,
+  ) {
+    super(
+      `"${question}" needs an answer, but stdin is not interactive. ` +
+        (flag ? `Use ${flag} instead.` : 'Run this command in a terminal.'),
+    );
+    this.name = 'NonInteractiveError';
+  }
+}
+
+interface FlagHint {
+  /** Flag (or argument) that supplies this answer, named when stdin isn't interactive */
+  flag?: string;
+  /** Stable id of the question under `--machine`; the flag or message is used when unset */
+  id?: string;
+}
+
+type Prompts = Omit<typeof clack, 'text' | 'password' | 'confirm' | 'select' | 'multiselect'> & {
+  text(opts: clack.TextOptions & FlagHint): Promise<string | symbol>;
+  password(opts: clack.PasswordOptions & FlagHint): Promise<string | symbol>;
+  confirm(opts: clack.ConfirmOptions & FlagHint): Promise<boolean | symbol>;
+  select<Value>(opts: clack.SelectOptions<Value> & FlagHint): Promise<Value | symbol>;
+  multiselect<Value>(opts: clack.MultiSelectOptions<Value> & FlagHint): Promise<Value[] | symbol>;
+};
+
+type AnyPrompt = (opts: { message: string } & FlagHint) => Promise<unknown>;
+
+const plainPrompts: Record<string, AnyPrompt> = {
+  text: plainText,
+  password: plainPassword,
+  confirm: plainConfirm,
+  select: plainSelect as AnyPrompt,
+  multiselect: plainMultiselect as AnyPrompt,
+};
+
+/** Every prompt goes through here, so none assumes a capable terminal */
+function guardPrompt(name: string, rich: AnyPrompt): AnyPrompt {
+  return ({ flag, id, ...opts }) => {
+    const mode = getPromptMode();
+    if (mode === 'none') return Promise.reject(new NonInteractiveError(opts.message, flag));
+    const shown = { ...opts, message: withPromptPrefix(opts.message) };
+    if (mode === 'machine') return machinePrompt(name as MachinePromptKind, { ...shown, id, flag });
+    return mode === 'plain' ? plainPrompts[name](shown) : rich(shown);
+  };
+}
+
 // Create a proxy that suppresses log output in dashboard mode
 const clackProxy = new Proxy(clack, {
   get(target, prop) {
     const value = target[prop as keyof typeof clack];
 
+    if (typeof prop === 'string' && prop in plainPrompts) {
+      return guardPrompt(prop, value as AnyPrompt);
+    }
+
+    if (quietMode) {
+      if (prop === 'log') return quietLog;
+      if (prop === 'spinner') return quietSpinner;
+      if (prop === 'intro' || prop === 'note') return () => {};
+      // The outro is the command's result line
+      if (prop === 'outro') return (message?: string) => message && console.log(message);
+    }
+
+    // Spinners redraw with cursor control; dumb terminals get one line per update
+    if (prop === 'spinner' && !hasCursorControl()) {
+      return plainSpinner;
+    }
+
+    // Plain prompts cancel with their own symbol
+    if (prop === 'isCancel') {
+      return (result: unknown) => result === PLAIN_CANCEL || clack.isCancel(result);
+    }
+
     // Suppress log methods in dashboard mode
     if (prop === 'log' && dashboardMode) {
       return {
@@ -38,4 +185,4 @@ const clackProxy = new Proxy(clack, {
   },
 });
 
-export default clackProxy;
+export default clackProxy as unknown as Prompts;
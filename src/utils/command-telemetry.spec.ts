@@ -0,0 +1,26 @@
+import { describe, it, expect } from 'vitest';
+import { buildCommandEvent, commandOutcome } from './command-telemetry.js';
+import { sanitizeEvent } from './telemetry-sanitize.js';
+
+describe('command telemetry', () => {
+  it('classifies exit codes', () => {
+    expect(commandOutcome(0)).toEqual({ 'cli.outcome': 'success' });
+    expect(commandOutcome(130)).toEqual({ 'cli.outcome': 'cancelled' });
+    expect(commandOutcome(3)).toEqual({ 'cli.outcome': 'error', 'cli.error_class': 'detection_empty' });
+    expect(commandOutcome(42)).toEqual({ 'cli.outcome': 'error', 'cli.error_class': 'exit_42' });
+  });
+
+  it('sends the command, version, duration, outcome and OS, and survives sanitizing', () => {
+    const event = buildCommandEvent('organization', 850, 1);
+
+    expect(event.attributes).toMatchObject({
+      'cli.command': 'organization',
+      'cli.duration_ms': 850,
+      'cli.outcome': 'error',
+      'cli.error_class': 'unknown_error',
+      'os.platform': process.platform,
+      'os.arch': process.arch,
+    });
+    expect(sanitizeEvent(event)).toEqual(event);
+  });
+});
@@ -0,0 +1,59 @@
+/**
+ * The `command` telemetry event: which top-level command ran, how long it
+ * took and how it ended, with the CLI version and OS. It is recorded from
+ * the exit code when the process exits, so it covers every way a command
+ * finishes, and is spooled synchronously there for the background sender.
+ */
+
+import { arch, platform } from 'node:os';
+import { v4 as uuidv4 } from 'uuid';
+import { ERROR_EXIT_CODES } from './errors.js';
+import { EXIT_CODE_CANCELLED } from '../lib/interrupt.js';
+import { getLlmGatewayUrl, getVersion } from '../lib/settings.js';
+import { isTelemetryEnabled } from './telemetry-consent.js';
+import { sanitizeEvent } from './telemetry-sanitize.js';
+import { appendToSpool, startSpoolDelivery, TELEMETRY_SEND_COMMAND } from './telemetry-spool.js';
+import type { CommandEvent } from './telemetry-types.js';
+
+/** Commands that don't report themselves: telemetry's own, and the sender */
+const UNTRACKED_COMMANDS = ['telemetry', TELEMETRY_SEND_COMMAND, 'completion'];
+
+/** `success`, `cancelled`, or `error` with the exit code's name (`detection_empty`, `exit_2`) */
+export function commandOutcome(exitCode: number): Pick<CommandEvent['attributes'], 'cli.outcome' | 'cli.error_class'> {
+  if (exitCode === 0) return { 'cli.outcome': 'success' };
+  if (exitCode === EXIT_CODE_CANCELLED) return { 'cli.outcome': 'cancelled' };
+  const name = Object.entries(ERROR_EXIT_CODES).find(([, code]) => code === exitCode)?.[0];
+  return { 'cli.outcome': 'error', 'cli.error_class': name ? name.toLowerCase() : `exit_${exitCode}` };
+}
+
+export function buildCommandEvent(command: string, durationMs: number, exitCode: number): CommandEvent {
+  return {
+    type: 'command',
+    sessionId: uuidv4(),
+    timestamp: new Date().toISOString(),
+    attributes: {
+      'cli.command': command,
+      'cli.version': getVersion(),
+      'cli.duration_ms': durationMs,
+      ...commandOutcome(exitCode),
+      'os.platform': platform(),
+      'os.arch': arch(),
+    },
+  };
+}
+
+/**
+ * Record the command once the process exits. `command` is the top-level
+ * command only (`install` for the default command); arguments never are.
+ */
+export function trackCommand(command: string): void {
+  if (UNTRACKED_COMMANDS.includes(command)) return;
+  const startedAt = Date.now();
+  process.once('exit', (code) => {
+    // Checked now rather than at startup: the first-run answer may have been given during the command
+    if (!isTelemetryEnabled()) return;
+    const event = sanitizeEvent(buildCommandEvent(command, Date.now() - startedAt, code));
+    appendToSpool({ gatewayUrl: getLlmGatewayUrl(), events: [event] });
+    startSpoolDelivery();
+  });
+}
@@ -3,7 +3,7 @@ import { join } from 'path';
 import { homedir } from 'os';
 import chalk from 'chalk';
 import { prepareMessage } from './logging.js';
-import { redactCredentials } from './redact.js';
+import { redactCredentials, redactSecrets } from './redact.js';
 import clack from './clack.js';
 
 let debugEnabled = false;
@@ -63,7 +63,7 @@ export function getLogFilePath(): string | null {
 
 function writeLog(level: 'INFO' | 'WARN' | 'ERROR', emoji: string, args: unknown[]): void {
   const redactedArgs = args.map((a) => (typeof a === 'object' && a !== null ? redactCredentials(a) : a));
-  const msg = redactedArgs.map((a) => prepareMessage(a)).join(' ');
+  const msg = redactSecrets(redactedArgs.map((a) => prepareMessage(a)).join(' '));
 
   // Write to console if debug enabled
   if (debugEnabled) {
@@ -96,7 +96,7 @@ export function logError(...args: unknown[]): void {
 
 export function debug(...args: unknown[]): void {
   if (!debugEnabled) return;
-  const msg = args.map((a) => prepareMessage(a)).join(' ');
+  const msg = redactSecrets(args.map((a) => prepareMessage(a)).join(' '));
   clack.log.info(chalk.dim(msg));
 }
 
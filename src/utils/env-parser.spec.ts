@@ -0,0 +1,64 @@
+import { describe, it, expect } from 'vitest';
+import { isEnvReference, parseEnvFile, renameEnvKeys } from './env-parser.js';
+
+const AUTH0_MAPPING = {
+  AUTH0_CLIENT_ID: 'WORKOS_CLIENT_ID',
+  AUTH0_CLIENT_SECRET: 'WORKOS_API_KEY',
+  AUTH0_SECRET: null,
+};
+
+describe('env-parser', () => {
+  it('parses values containing =', () => {
+    expect(parseEnvFile('# comment\nA=1\nB=x=y\n')).toEqual({ A: '1', B: 'x=y' });
+  });
+
+  describe('isEnvReference', () => {
+    it.each([
+      '${AUTH0_CLIENT_SECRET}',
+      '${AUTH0_CLIENT_SECRET:-fallback}',
+      '"${SSM:/prod/auth0/secret}"',
+      '$AUTH0_CLIENT_SECRET',
+      'https://${AUTH0_DOMAIN}/callback',
+    ])('treats %s as a reference', (value) => {
+      expect(isEnvReference(value)).toBe(true);
+    });
+
+    it.each(['abc123', "'${LITERAL}'", 'pa\\$word', 'cost: $5'])('treats %s as a literal', (value) => {
+      expect(isEnvReference(value)).toBe(false);
+    });
+  });
+
+  describe('renameEnvKeys', () => {
+    it('renames keys and keeps references byte for byte', () => {
+      const content = [
+        '# Auth0',
+        'AUTH0_CLIENT_ID=abc123',
+        'export AUTH0_CLIENT_SECRET = "${SSM:/prod/auth0/secret}" # from SSM',
+        'AUTH0_SECRET=${SESSION_SECRET}',
+        '',
+      ].join('\n');
+
+      const result = renameEnvKeys(content, AUTH0_MAPPING);
+
+      expect(result.content).toBe(
+        [
+          '# Auth0',
+          'WORKOS_CLIENT_ID=abc123',
+          'export WORKOS_API_KEY = "${SSM:/prod/auth0/secret}" # from SSM',
+          'AUTH0_SECRET=${SESSION_SECRET}',
+          '',
+        ].join('\n'),
+      );
+      expect(result.renamed).toEqual([
+        { from: 'AUTH0_CLIENT_ID', to: 'WORKOS_CLIENT_ID', reference: false },
+        { from: 'AUTH0_CLIENT_SECRET', to: 'WORKOS_API_KEY', reference: true },
+      ]);
+    });
+
+    it('leaves a key alone when its new name is already set', () => {
+      const content = 'WORKOS_CLIENT_ID=client_01\nAUTH0_CLIENT_ID=$LEGACY_ID\n';
+
+      expect(renameEnvKeys(content, AUTH0_MAPPING)).toEqual({ content, renamed: [] });
+    });
+  });
+});
@@ -15,3 +15,55 @@ export function parseEnvFile(content: string): Record<string, string> {
   }
   return result;
 }
+
+/** `KEY=value`, optionally `export`ed, with the key and the raw value split out */
+const ASSIGNMENT_PATTERN = /^(\s*(?:export\s+)?)([A-Za-z_][A-Za-z0-9_.-]*)(\s*=)(.*)$/;
+
+/** `${NAME}`, `${SSM:/path}`, `${VAR:-default}` and `$NAME`, unless the `$` is escaped */
+const REFERENCE_PATTERN = /(?<!\\)\$(?:\{[^}]*\}|[A-Za-z_][A-Za-z0-9_]*)/;
+
+/**
+ * Whether a raw env value refers to another value instead of holding it:
+ * dotenv/shell interpolation or a secrets manager reference. Single-quoted
+ * values are literal, as in the shell and dotenv-expand.
+ */
+export function isEnvReference(rawValue: string): boolean {
+  const value = rawValue.trim();
+  if (value.startsWith("'")) return false;
+  return REFERENCE_PATTERN.test(value);
+}
+
+export interface EnvKeyRename {
+  from: string;
+  to: string;
+  /** The value is a reference, kept as written; whatever it points to still holds the old provider's value */
+  reference: boolean;
+}
+
+/**
+ * Rename keys in env file content without touching anything else: values,
+ * quoting, `export`, comments and line order stay exactly as written, so
+ * references such as `${SSM:/prod/auth0/secret}` come through intact. Keys
+ * mapped to null are left alone, and so is a key whose new name is already
+ * in the file.
+ */
+export function renameEnvKeys(
+  content: string,
+  mapping: Record<string, string | null>,
+): { content: string; renamed: EnvKeyRename[] } {
+  const lines = content.split('\n');
+  const present = new Set(lines.map((line) => ASSIGNMENT_PATTERN.exec(line)?.[2]).filter(Boolean));
+  const renamed: EnvKeyRename[] = [];
+
+  const output = lines.map((line) => {
+    const match = ASSIGNMENT_PATTERN.exec(line);
+    if (!match) return line;
+    const [, prefix, key, separator, value] = match;
+    const to = mapping[key];
+    if (!to || to === key || present.has(to)) return line;
+    present.add(to);
+    renamed.push({ from: key, to, reference: isEnvReference(value) });
+    return `${prefix}${to}${separator}${value}`;
+  });
+  return { content: output.join('\n'), renamed };
+}
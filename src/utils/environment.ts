@@ -8,7 +8,8 @@ export function isNonInteractiveEnvironment(): boolean {
     return false;
   }
 
-  if (!process.stdout.isTTY || !process.stderr.isTTY) {
+  // Piped stdin (`echo y | workos install`) can't answer prompts either
+  if (!process.stdin.isTTY || !process.stdout.isTTY || !process.stderr.isTTY) {
     return true;
   }
 
@@ -0,0 +1,30 @@
+import { describe, it, expect } from 'vitest';
+import { ERROR_CATALOG, formatErrorExplanation, lookupError, similarErrorIds } from './error-catalog.js';
+
+describe('error-catalog', () => {
+  it('uses the WOS-<AREA>-<NNN> form and explains every ID', () => {
+    for (const [id, entry] of Object.entries(ERROR_CATALOG)) {
+      expect(id).toMatch(/^WOS-[A-Z]+-\d{3}$/);
+      expect(entry.causes.length).toBeGreaterThan(0);
+      expect(entry.remediation.length).toBeGreaterThan(0);
+    }
+  });
+
+  it('looks IDs up regardless of case', () => {
+    expect(lookupError(' wos-api-001 ')?.id).toBe('WOS-API-001');
+    expect(lookupError('WOS-API-999')).toBeNull();
+  });
+
+  it('suggests IDs in the same area or with the same number', () => {
+    expect(similarErrorIds('WOS-AGENT-9')).toContain('WOS-AGENT-001');
+    expect(similarErrorIds('WOS-NOPE-005')).toEqual(['WOS-INSTALL-005', 'WOS-AGENT-005', 'WOS-API-005']);
+  });
+
+  it('prints the description, causes and remediation', () => {
+    const text = formatErrorExplanation('WOS-API-001');
+
+    expect(text.split('\n')[0]).toBe('WOS-API-001: API key rejected (401)');
+    expect(text).toContain('Common causes:\n  - A mistyped or revoked key');
+    expect(text).toContain('What to do:\n  - Check which key is used with `workos whoami`');
+  });
+});
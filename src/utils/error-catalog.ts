@@ -0,0 +1,226 @@
+/**
+ * Stable IDs for the failures the CLI reports, with the explanation
+ * `workos explain <id>` prints. IDs are `WOS-<AREA>-<NNN>` and, like exit
+ * codes, are part of the CLI's interface: they are never renumbered or
+ * reused, only added. The catalog ships with the CLI so explaining an
+ * error works offline.
+ */
+
+export interface ErrorCatalogEntry {
+  title: string;
+  description: string;
+  causes: string[];
+  remediation: string[];
+}
+
+export const ERROR_CATALOG = {
+  'WOS-GEN-001': {
+    title: 'Unexpected error',
+    description: "The command failed in a way the CLI doesn't have a specific code for.",
+    causes: ['A bug in the CLI', 'A failure in a tool or service the command depends on'],
+    remediation: [
+      'Run again with --debug and check the debug log it names',
+      'Report it at https://github.com/workos/cli/issues with the log attached',
+    ],
+  },
+  'WOS-INSTALL-001': {
+    title: 'No framework or auth provider detected',
+    description: 'Nothing in the project matched a framework integration or an auth provider to migrate from.',
+    causes: [
+      'The command ran outside the project root',
+      "The project's framework isn't supported yet",
+      'Dependencies are declared somewhere detection does not read',
+    ],
+    remediation: [
+      'Run from the project root, or pass --install-dir',
+      'Pick the framework with --integration',
+      'Run `workos providers` to see what detection supports',
+    ],
+  },
+  'WOS-INSTALL-002': {
+    title: 'Skill registry unreachable',
+    description: "The server hosting the installer's skill bundle couldn't be reached, or didn't serve the bundle.",
+    causes: ['No network connection', 'A proxy or firewall blocking the registry', 'A bundle URL that is wrong'],
+    remediation: [
+      'Check the connection with `workos doctor`',
+      'Set HTTPS_PROXY when behind a proxy',
+      'Install from a local copy of the bundle with `workos install-skill --from <dir>`',
+    ],
+  },
+  'WOS-INSTALL-003': {
+    title: 'Skill not found',
+    description: "A requested skill isn't in the skill bundle.",
+    causes: ['A misspelled skill name', 'A skill that was renamed or removed in a newer bundle'],
+    remediation: [
+      'List the available skills with `workos install-skill --list`',
+      'Update the CLI with `workos upgrade`',
+    ],
+  },
+  'WOS-INSTALL-004': {
+    title: 'Uncommitted changes in the working tree',
+    description: 'The project has uncommitted or untracked files, and the run would mix its changes in with them.',
+    causes: ['Work in progress that has not been committed', 'Build output that is not in .gitignore'],
+    remediation: ['Commit or stash the changes, then run again', 'Or confirm at the prompt to continue with them'],
+  },
+  'WOS-INSTALL-005': {
+    title: 'Verification failed',
+    description: 'The install finished, but the checks run afterwards (typecheck, build, lint) still fail.',
+    causes: [
+      'The agent could not fix every error within its retries',
+      'Errors that were in the project before the run',
+    ],
+    remediation: [
+      'Read the failing checks in the summary and fix them, or run `workos verify` to see them again',
+      'Run the install again to give the agent another pass',
+    ],
+  },
+  'WOS-INSTALL-006': {
+    title: 'Migration partly applied',
+    description: "Some of the migration's file changes landed and others didn't, so the project is half migrated.",
+    causes: ['An edit the agent could not apply', 'The run stopping partway through'],
+    remediation: [
+      'Run `workos migrate` again to resume with the files that are left',
+      'Or roll back: the run offers to, or restore the changed files from git',
+    ],
+  },
+  'WOS-INSTALL-007': {
+    title: 'Cancelled',
+    description: 'The run was stopped: Ctrl-C, or a cancelled prompt.',
+    causes: ['Stopped on purpose'],
+    remediation: ['Run the command again when ready'],
+  },
+  'WOS-INSTALL-008': {
+    title: 'Terminated',
+    description: 'The run received SIGTERM and stopped.',
+    causes: ['A CI job that hit its time limit or was cancelled', 'A process manager stopping the CLI'],
+    remediation: ["Raise the job's time limit, or set --timeout below it so the CLI stops cleanly"],
+  },
+  'WOS-AGENT-001': {
+    title: 'Not logged in',
+    description: 'The agent needs a WorkOS login, and there is none or it has expired.',
+    causes: ['Never logged in on this machine', 'A login that expired or was revoked', 'A different --profile'],
+    remediation: ['Run `workos login`', 'In CI, pass --api-key instead'],
+  },
+  'WOS-AGENT-002': {
+    title: 'Agent rate limited',
+    description: 'The LLM gateway turned the agent away because too many requests were made.',
+    causes: ['Several runs at once from the same account', "The account's installer usage limit"],
+    remediation: ['Wait a few minutes and run again', 'Run fewer installs in parallel'],
+  },
+  'WOS-AGENT-003': {
+    title: 'Agent declined the task',
+    description: 'The model refused to continue with the run.',
+    causes: [
+      "Custom instructions asking for something outside the installer's job",
+      'Project content the model would not work with',
+    ],
+    remediation: ['Remove or reword custom instructions (--instructions)', 'Exclude the files involved and retry'],
+  },
+  'WOS-AGENT-004': {
+    title: 'Agent timed out',
+    description: 'The agent went idle or ran past its time limit and was stopped.',
+    causes: ['A large project', 'A command the agent started that never finished', 'A slow network'],
+    remediation: ['Raise --timeout or --idle-timeout', 'Run again; the work already done is kept'],
+  },
+  'WOS-AGENT-005': {
+    title: 'Agent failed',
+    description: 'The agent run ended with an error from the agent SDK.',
+    causes: ['The LLM gateway returning an error', 'The agent process crashing'],
+    remediation: ['Run again', 'Run with --debug and check the debug log'],
+  },
+  'WOS-AGENT-006': {
+    title: 'WorkOS resources unavailable to the agent',
+    description: 'The agent could not reach the WorkOS MCP server or the setup resource it reads instructions from.',
+    causes: ['No network connection', 'A proxy blocking the MCP server'],
+    remediation: ['Check the connection with `workos doctor`', 'Run again'],
+  },
+  'WOS-API-001': {
+    title: 'API key rejected (401)',
+    description: 'The WorkOS API did not accept the API key.',
+    causes: ['A mistyped or revoked key', 'A key for another environment', 'WORKOS_API_KEY set to an old key'],
+    remediation: [
+      'Check which key is used with `workos whoami`',
+      'Switch environments with `workos env switch`, or pass --api-key',
+    ],
+  },
+  'WOS-API-002': {
+    title: 'Not permitted (403)',
+    description: 'The API key is valid but not allowed to do this.',
+    causes: ['A feature not enabled for the environment', 'A restricted key, such as a CI token'],
+    remediation: ['Enable the feature in the WorkOS dashboard', "Use the environment's API key"],
+  },
+  'WOS-API-003': {
+    title: 'Not found (404)',
+    description: 'The resource the command refers to does not exist in this environment.',
+    causes: ['A mistyped ID', 'An ID from another environment', 'A resource that was deleted'],
+    remediation: ['Check the ID with the matching `list` command', 'Check the environment with `workos env list`'],
+  },
+  'WOS-API-004': {
+    title: 'Invalid request (422)',
+    description: 'The WorkOS API rejected the values sent.',
+    causes: ['A missing or malformed field', 'A value that conflicts with an existing resource'],
+    remediation: ['Fix the values named in the message and retry'],
+  },
+  'WOS-API-005': {
+    title: 'Rate limited (429)',
+    description: 'The WorkOS API is limiting requests from this key.',
+    causes: ['Many requests in a short time, such as a bulk import', 'Other clients using the same key'],
+    remediation: ['Wait the time in the message and retry', 'Spread bulk work out over time'],
+  },
+  'WOS-API-006': {
+    title: 'WorkOS server error (5xx)',
+    description: 'The WorkOS API failed on its side.',
+    causes: ['An incident at WorkOS'],
+    remediation: ['Check https://status.workos.com', 'Retry later; include the request ID when contacting support'],
+  },
+  'WOS-API-007': {
+    title: 'WorkOS unreachable',
+    description: 'The request got no response: no network, DNS, TLS or a timeout.',
+    causes: ['No network connection', 'A proxy intercepting HTTPS', 'A firewall or VPN blocking api.workos.com'],
+    remediation: [
+      'Run `workos doctor` to find which step fails',
+      'Set NODE_EXTRA_CA_CERTS behind an intercepting proxy',
+      'Allow longer with --http-timeout',
+    ],
+  },
+  'WOS-API-008': {
+    title: 'Request rejected',
+    description: 'The WorkOS API rejected the request with a client error.',
+    causes: ['A request the API does not support for this resource'],
+    remediation: ['Check the message and the request ID from it', 'Run with --debug to see the request'],
+  },
+} satisfies Record<string, ErrorCatalogEntry>;
+
+export type ErrorId = keyof typeof ERROR_CATALOG;
+
+/** The catalog entry for an ID, matched case-insensitively */
+export function lookupError(id: string): { id: ErrorId; entry: ErrorCatalogEntry } | null {
+  const normalized = id.trim().toUpperCase() as ErrorId;
+  return normalized in ERROR_CATALOG ? { id: normalized, entry: ERROR_CATALOG[normalized] } : null;
+}
+
+/** IDs close to a mistyped one: same area, or the same number */
+export function similarErrorIds(id: string): ErrorId[] {
+  const [, area, number] = /^WOS-([A-Z]+)-?(\d*)$/i.exec(id.trim()) ?? [];
+  return (Object.keys(ERROR_CATALOG) as ErrorId[]).filter((candidate) => {
+    const [, candidateArea, candidateNumber] = candidate.split('-');
+    return candidateArea === area?.toUpperCase() || (number && candidateNumber === number.padStart(3, '0'));
+  });
+}
+
+/** The full explanation `workos explain` prints for one ID */
+export function formatErrorExplanation(id: ErrorId): string {
+  const { title, description, causes, remediation } = ERROR_CATALOG[id];
+  const list = (items: string[]) => items.map((item) => `  - ${item}`).join('\n');
+  return [
+    `${id}: ${title}`,
+    '',
+    description,
+    '',
+    'Common causes:',
+    list(causes),
+    '',
+    'What to do:',
+    list(remediation),
+  ].join('\n');
+}
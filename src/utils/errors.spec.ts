@@ -0,0 +1,111 @@
+import { describe, it, expect, afterEach, vi } from 'vitest';
+import { WorkOSApiError } from '../lib/workos-api.js';
+import { ERROR_CATALOG } from './error-catalog.js';
+import {
+  AgentAuthRequiredError,
+  DirtyWorkingTreeError,
+  ERROR_EXIT_CODES,
+  InstallerError,
+  UserCancelledError,
+  errorJson,
+  exitWithError,
+  stoppedRunError,
+  toInstallerError,
+  type ErrorCode,
+} from './errors.js';
+
+describe('errors', () => {
+  it('gives every error code its own exit code', () => {
+    const codes = Object.values(ERROR_EXIT_CODES);
+    expect(new Set(codes).size).toBe(codes.length);
+  });
+
+  it('tells an auth failure from a cancel', () => {
+    expect(new AgentAuthRequiredError().exitCode).toBe(6);
+    expect(new UserCancelledError().exitCode).toBe(130);
+    expect(new DirtyWorkingTreeError(['a.ts']).exitCode).toBe(7);
+  });
+
+  it('maps stopped runs to their signal and timeout codes', () => {
+    expect(stoppedRunError('interrupt')).toBeInstanceOf(UserCancelledError);
+    expect(stoppedRunError('terminate').exitCode).toBe(143);
+    expect(stoppedRunError('timeout').code).toBe('AGENT_TIMEOUT');
+    expect(stoppedRunError('idle-timeout').exitCode).toBe(124);
+  });
+
+  it('treats plain errors as unknown', () => {
+    const cause = new Error('boom');
+    const error = toInstallerError(cause);
+
+    expect(error).toBeInstanceOf(InstallerError);
+    expect(error.code).toBe('UNKNOWN_ERROR');
+    expect(error.exitCode).toBe(1);
+    expect(error.cause).toBe(cause);
+    expect(toInstallerError('nope').message).toBe('nope');
+  });
+
+  it('gives every error code an ID in the catalog', () => {
+    for (const code of Object.keys(ERROR_EXIT_CODES) as ErrorCode[]) {
+      expect(ERROR_CATALOG[new InstallerError(code, 'x').id]).toBeDefined();
+    }
+  });
+
+  it('gives API failures an ID for each status', () => {
+    const ids = [401, 403, 404, 422, 429, 503, 0, 409].map(
+      (status) => toInstallerError(new WorkOSApiError('failed', status)).id,
+    );
+
+    expect(ids).toEqual([
+      'WOS-API-001',
+      'WOS-API-002',
+      'WOS-API-003',
+      'WOS-API-004',
+      'WOS-API-005',
+      'WOS-API-006',
+      'WOS-API-007',
+      'WOS-API-008',
+    ]);
+    expect(toInstallerError(new WorkOSApiError('Not found', 404)).exitCode).toBe(12);
+  });
+
+  it('serializes the code, ID, exit code, message and hints', () => {
+    expect(errorJson(new AgentAuthRequiredError('Session expired'))).toEqual({
+      schemaVersion: 1,
+      error: {
+        code: 'AGENT_AUTH_REQUIRED',
+        id: 'WOS-AGENT-001',
+        exitCode: 6,
+        message: 'Session expired',
+        hints: ERROR_CATALOG['WOS-AGENT-001'].remediation,
+      },
+    });
+  });
+
+  describe('exitWithError', () => {
+    afterEach(() => {
+      vi.restoreAllMocks();
+    });
+
+    it('prints the error object last with --json and exits with its code', () => {
+      const log = vi.spyOn(console, 'log').mockImplementation(() => undefined);
+      const exit = vi.spyOn(process, 'exit').mockImplementation((() => undefined) as never);
+
+      exitWithError(new DirtyWorkingTreeError(['a.ts', 'b.ts']), { json: true });
+
+      expect(exit).toHaveBeenCalledWith(7);
+      expect(JSON.parse(log.mock.calls[0][0] as string).error.code).toBe('DIRTY_WORKING_TREE');
+    });
+
+    it('prints only the error ID, on stderr, without --json', () => {
+      const log = vi.spyOn(console, 'log').mockImplementation(() => undefined);
+      const error = vi.spyOn(console, 'error').mockImplementation(() => undefined);
+      const exit = vi.spyOn(process, 'exit').mockImplementation((() => undefined) as never);
+
+      exitWithError(new Error('boom'));
+
+      expect(exit).toHaveBeenCalledWith(1);
+      expect(log).not.toHaveBeenCalled();
+      expect(error.mock.calls[0][0]).toContain('workos explain WOS-GEN-001');
+    });
+  });
+});
@@ -1,6 +1,234 @@
-export class RateLimitError extends Error {
-  constructor() {
-    super('Installer usage limit reached.');
+import chalk from 'chalk';
+import { EXIT_CODE_CANCELLED, EXIT_CODE_TERMINATED, EXIT_CODE_TIMEOUT, type StopReason } from '../lib/interrupt.js';
+import { OUTPUT_SCHEMA_VERSION } from '../lib/output-schema.js';
+import { WorkOSApiError } from '../lib/workos-api.js';
+import { ERROR_CATALOG, type ErrorId } from './error-catalog.js';
+
+/**
+ * Why a run failed, with the exit code for each. The codes and numbers are
+ * part of the CLI's interface (see "Exit codes" in the README): automation
+ * branches on them, so they only ever get added to.
+ */
+export const ERROR_EXIT_CODES = {
+  UNKNOWN_ERROR: 1,
+  DETECTION_EMPTY: 3,
+  REGISTRY_UNREACHABLE: 4,
+  SKILL_NOT_FOUND: 5,
+  AGENT_AUTH_REQUIRED: 6,
+  DIRTY_WORKING_TREE: 7,
+  VERIFICATION_FAILED: 8,
+  PARTIAL_MIGRATION: 9,
+  AGENT_RATE_LIMITED: 10,
+  AGENT_REFUSED: 11,
+  API_ERROR: 12,
+  AGENT_TIMEOUT: EXIT_CODE_TIMEOUT,
+  USER_CANCELLED: EXIT_CODE_CANCELLED,
+  TERMINATED: EXIT_CODE_TERMINATED,
+} as const;
+
+export type ErrorCode = keyof typeof ERROR_EXIT_CODES;
+
+/** The catalog ID for each code; an error can name a more specific one */
+const ERROR_IDS: Record<ErrorCode, ErrorId> = {
+  UNKNOWN_ERROR: 'WOS-GEN-001',
+  DETECTION_EMPTY: 'WOS-INSTALL-001',
+  REGISTRY_UNREACHABLE: 'WOS-INSTALL-002',
+  SKILL_NOT_FOUND: 'WOS-INSTALL-003',
+  AGENT_AUTH_REQUIRED: 'WOS-AGENT-001',
+  DIRTY_WORKING_TREE: 'WOS-INSTALL-004',
+  VERIFICATION_FAILED: 'WOS-INSTALL-005',
+  PARTIAL_MIGRATION: 'WOS-INSTALL-006',
+  AGENT_RATE_LIMITED: 'WOS-AGENT-002',
+  AGENT_REFUSED: 'WOS-AGENT-003',
+  API_ERROR: 'WOS-API-008',
+  AGENT_TIMEOUT: 'WOS-AGENT-004',
+  USER_CANCELLED: 'WOS-INSTALL-007',
+  TERMINATED: 'WOS-INSTALL-008',
+};
+
+export class InstallerError extends Error {
+  /** Catalog ID, explained by `workos explain` */
+  readonly id: ErrorId;
+
+  constructor(
+    readonly code: ErrorCode,
+    message: string,
+    options?: ErrorOptions & { id?: ErrorId },
+  ) {
+    super(message, options);
+    this.name = 'InstallerError';
+    this.id = options?.id ?? ERROR_IDS[code];
+  }
+
+  get exitCode(): number {
+    return ERROR_EXIT_CODES[this.code];
+  }
+
+  /** What to try, from the catalog */
+  get hints(): string[] {
+    return ERROR_CATALOG[this.id].remediation;
+  }
+}
+
+/** Nothing in the project matched a supported framework or auth provider */
+export class DetectionEmptyError extends InstallerError {
+  constructor(message = 'Could not detect framework integration') {
+    super('DETECTION_EMPTY', message);
+    this.name = 'DetectionEmptyError';
+  }
+}
+
+/** A skill bundle's server couldn't be reached or didn't serve the bundle */
+export class RegistryUnreachableError extends InstallerError {
+  constructor(message: string, options?: ErrorOptions) {
+    super('REGISTRY_UNREACHABLE', message, options);
+    this.name = 'RegistryUnreachableError';
+  }
+}
+
+/** A requested skill isn't in the bundle */
+export class SkillNotFoundError extends InstallerError {
+  constructor(message: string) {
+    super('SKILL_NOT_FOUND', message);
+    this.name = 'SkillNotFoundError';
+  }
+}
+
+/** The agent can't run without logging in (again) */
+export class AgentAuthRequiredError extends InstallerError {
+  constructor(message = 'Not authenticated. Run `workos login` to authenticate.') {
+    super('AGENT_AUTH_REQUIRED', message);
+    this.name = 'AgentAuthRequiredError';
+  }
+}
+
+/** The working tree has uncommitted changes and the run wasn't allowed to continue */
+export class DirtyWorkingTreeError extends InstallerError {
+  constructor(files: string[]) {
+    super(
+      'DIRTY_WORKING_TREE',
+      `${files.length} uncommitted or untracked file(s) in the working tree; commit or stash them first`,
+    );
+    this.name = 'DirtyWorkingTreeError';
+  }
+}
+
+/** The install finished but verification checks still fail */
+export class VerificationFailedError extends InstallerError {
+  constructor(readonly checks: string[]) {
+    super('VERIFICATION_FAILED', `Verification check(s) still failing: ${checks.join(', ')}`);
+    this.name = 'VerificationFailedError';
+  }
+}
+
+/** Some of a migration's file changes landed and others didn't */
+export class PartialMigrationError extends InstallerError {
+  constructor(
+    readonly succeeded: number,
+    readonly failed: number,
+    options?: ErrorOptions,
+  ) {
+    const rest = failed > 0 ? `${failed} failed` : 'then the run failed';
+    super('PARTIAL_MIGRATION', `Migration partly applied: ${succeeded} file(s) changed, ${rest}`, options);
+    this.name = 'PartialMigrationError';
+  }
+}
+
+/** The LLM gateway is limiting the agent's requests */
+export class RateLimitError extends InstallerError {
+  constructor(message = 'Installer usage limit reached.') {
+    super('AGENT_RATE_LIMITED', message);
     this.name = 'RateLimitError';
   }
 }
+
+/** The model declined to carry on with the run */
+export class AgentRefusedError extends InstallerError {
+  constructor(message = 'Agent declined to continue.') {
+    super('AGENT_REFUSED', message);
+    this.name = 'AgentRefusedError';
+  }
+}
+
+/** The catalog ID for a failed WorkOS API request, by status; 0 means no response */
+export function apiErrorId(status: number): ErrorId {
+  if (status === 0) return 'WOS-API-007';
+  if (status === 401) return 'WOS-API-001';
+  if (status === 403) return 'WOS-API-002';
+  if (status === 404) return 'WOS-API-003';
+  if (status === 422) return 'WOS-API-004';
+  if (status === 429) return 'WOS-API-005';
+  if (status >= 500) return 'WOS-API-006';
+  return 'WOS-API-008';
+}
+
+/** The user stopped the run: Ctrl-C, or cancelling a prompt */
+export class UserCancelledError extends InstallerError {
+  constructor(message = 'Cancelled by user') {
+    super('USER_CANCELLED', message);
+    this.name = 'UserCancelledError';
+  }
+}
+
+/** The error for a run that stopped before finishing */
+export function stoppedRunError(reason: StopReason): InstallerError {
+  switch (reason) {
+    case 'interrupt':
+      return new UserCancelledError();
+    case 'terminate':
+      return new InstallerError('TERMINATED', 'Run terminated (SIGTERM)');
+    case 'idle-timeout':
+      return new InstallerError('AGENT_TIMEOUT', 'Agent stopped after going idle (--idle-timeout)');
+    case 'timeout':
+      return new InstallerError('AGENT_TIMEOUT', 'Agent stopped at its time limit (--timeout)');
+  }
+}
+
+/** Any error as an InstallerError; API failures are API_ERROR, other unrecognized ones UNKNOWN_ERROR */
+export function toInstallerError(error: unknown): InstallerError {
+  if (error instanceof InstallerError) return error;
+  if (error instanceof WorkOSApiError) {
+    return new InstallerError('API_ERROR', error.message, { cause: error, id: apiErrorId(error.statusCode) });
+  }
+  const message = error instanceof Error ? error.message : String(error);
+  return new InstallerError('UNKNOWN_ERROR', message, { cause: error });
+}
+
+interface ErrorJson {
+  code: ErrorCode;
+  /** Catalog ID, e.g. WOS-API-001 */
+  id: ErrorId;
+  exitCode: number;
+  message: string;
+  hints: string[];
+}
+
+/** The `--json` form of a failed run; `code` and `id` are stable, `message` and `hints` are for people */
+export function errorJson(error: unknown): { schemaVersion: number; error: ErrorJson } {
+  const { code, id, exitCode, message, hints } = toInstallerError(error);
+  return { schemaVersion: OUTPUT_SCHEMA_VERSION, error: { code, id, exitCode, message, hints } };
+}
+
+let exitError: InstallerError | null = null;
+
+/** The error exitWithError() is exiting with, for process exit handlers */
+export function exitingWith(): InstallerError | null {
+  return exitError;
+}
+
+/**
+ * Exit with the error's code, printing it as the final JSON object first
+ * with `--json`. Otherwise the caller has shown the message, and the
+ * catalog ID follows it on stderr.
+ */
+export function exitWithError(error: unknown, options: { json?: boolean } = {}): never {
+  const installerError = toInstallerError(error);
+  exitError = installerError;
+  if (options.json) {
+    console.log(JSON.stringify(errorJson(installerError)));
+  } else {
+    const { id } = installerError;
+    console.error(chalk.dim(`${id} · run \`workos explain ${id}\` for causes and fixes`));
+  }
+  process.exit(installerError.exitCode);
+}
@@ -1,4 +1,5 @@
 import { spawn } from 'node:child_process';
+import { spawnTarget } from './windows.js';
 
 export interface ExecResult {
   status: number;
@@ -18,11 +19,13 @@ export interface ExecOptions {
  */
 export function execFileNoThrow(command: string, args: string[], options: ExecOptions = {}): Promise<ExecResult> {
   return new Promise((resolve) => {
-    const child = spawn(command, args, {
+    const target = spawnTarget(command, args);
+    const child = spawn(target.command, target.args, {
       cwd: options.cwd,
       env: options.env ?? process.env,
       timeout: options.timeout,
       shell: false,
+      windowsVerbatimArguments: target.windowsVerbatimArguments,
     });
 
     let stdout = '';
@@ -0,0 +1,93 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { PassThrough } from 'node:stream';
+import { _setPromptIO } from './plain-prompts.js';
+import { NonInteractiveError } from './clack.js';
+import { filterPickerItems, fuzzyScore, pick, pickMany } from './fuzzy-picker.js';
+
+const orgs = [
+  { value: 'org_1', label: 'Acme Inc', keywords: ['acme.com'] },
+  { value: 'org_2', label: 'Globex', keywords: ['globex.io'] },
+  { value: 'org_3', label: 'Initech', keywords: ['initech.example'] },
+];
+
+describe('fuzzy-picker', () => {
+  it('ranks prefixes over substrings over scattered letters', () => {
+    expect(fuzzyScore('acm', 'Acme Inc')!).toBeGreaterThan(fuzzyScore('inc', 'Acme Inc')!);
+    expect(fuzzyScore('inc', 'Acme Inc')!).toBeGreaterThan(fuzzyScore('aminc', 'Acme Inc')!);
+    expect(fuzzyScore('xyz', 'Acme Inc')).toBeNull();
+  });
+
+  it('filters on labels, values and keywords, every word matching', () => {
+    expect(filterPickerItems(orgs, 'globex.io').map((o) => o.value)).toEqual(['org_2']);
+    expect(filterPickerItems(orgs, 'org_3').map((o) => o.value)).toEqual(['org_3']);
+    expect(filterPickerItems(orgs, 'acme inc').map((o) => o.value)).toEqual(['org_1']);
+    expect(filterPickerItems(orgs, '  ')).toBe(orgs);
+  });
+
+  describe('prompts', () => {
+    const isTTY = process.stdin.isTTY;
+    const term = process.env.TERM;
+    let input: PassThrough;
+    let output: PassThrough;
+    let written: string;
+
+    /** Answer each question as it's asked; questions end in a space, other output in a newline */
+    const answer = (...lines: string[]) => {
+      output.on('data', (chunk: Buffer) => {
+        if (chunk.toString().endsWith('\n')) return;
+        const line = lines.shift();
+        if (line === undefined) input.end();
+        else setImmediate(() => input.write(`${line}\n`));
+      });
+    };
+
+    beforeEach(() => {
+      input = new PassThrough();
+      output = new PassThrough();
+      written = '';
+      output.on('data', (chunk: Buffer) => (written += chunk.toString()));
+      _setPromptIO({ input, output });
+      process.stdin.isTTY = true;
+      process.env.TERM = 'dumb';
+    });
+
+    afterEach(() => {
+      _setPromptIO({ input: process.stdin, output: process.stdout });
+      process.stdin.isTTY = isTTY;
+      process.env.TERM = term;
+    });
+
+    it('filters on typed text and picks by number in plain terminals', async () => {
+      answer('glob', '1');
+
+      expect(await pick({ message: 'Select an organization', items: orgs })).toBe('org_2');
+      expect(written).toContain('(matching "glob")');
+      expect(written).toContain('1 match · 3 of 3');
+    });
+
+    it('searches pages not loaded yet when a filter runs short', async () => {
+      const loadMore = vi.fn().mockResolvedValue({
+        items: [{ value: 'org_4', label: 'Umbrella', keywords: ['umbrella.corp'] }],
+        done: true,
+      });
+      answer('umbrella', '1');
+
+      expect(await pick({ message: 'Select an organization', items: orgs, loadMore })).toBe('org_4');
+      expect(loadMore).toHaveBeenCalledTimes(1);
+    });
+
+    it('picks several at once', async () => {
+      answer('1, 3');
+
+      expect(await pickMany({ message: 'Select organizations', items: orgs })).toEqual(['org_1', 'org_3']);
+    });
+
+    it('names the flag when stdin is not interactive', async () => {
+      process.stdin.isTTY = false;
+
+      await expect(pick({ message: 'Select an organization', items: orgs, flag: '--org' })).rejects.toThrow(
+        NonInteractiveError,
+      );
+    });
+  });
+});
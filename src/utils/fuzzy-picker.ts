@@ -0,0 +1,411 @@
+/**
+ * Type-to-filter picker for lists too long to arrow through.
+ *
+ * Every command that picks a resource (organizations, users, environments,
+ * skills) goes through here so they behave the same: typing filters on the
+ * label, value, hint and keywords (an organization's domains, a user's
+ * name), further pages load as the cursor nears the end or a filter runs
+ * out of matches, and a count shows how much of the list is loaded. Dumb
+ * terminals get a numbered list that filters on typed text instead, and
+ * without a TTY the picker throws NonInteractiveError naming the flag.
+ */
+
+import chalk from 'chalk';
+import { emitKeypressEvents } from 'node:readline';
+import { getPromptMode, NonInteractiveError, withPromptPrefix } from './clack.js';
+import { PLAIN_CANCEL, readLine, say } from './plain-prompts.js';
+import { machinePrompt } from './machine-prompts.js';
+import { isUnicodeSupported } from './vendor/is-unicorn-supported.js';
+
+export interface PickerItem<Value> {
+  value: Value;
+  label: string;
+  /** Shown dimmed after the label, e.g. the ID */
+  hint?: string;
+  /** Also matched by the filter but not shown, e.g. domains */
+  keywords?: string[];
+}
+
+export interface PickerPage<Value> {
+  items: PickerItem<Value>[];
+  /** No pages after this one */
+  done: boolean;
+}
+
+export interface PickerOptions<Value> {
+  message: string;
+  /** What's loaded up front; with no `loadMore` it's the whole list */
+  items: PickerItem<Value>[];
+  /** Fetch the next page */
+  loadMore?: () => Promise<PickerPage<Value>>;
+  /** Flag (or argument) that supplies this answer, named when stdin isn't interactive */
+  flag?: string;
+}
+
+export interface MultiPickerOptions<Value> extends PickerOptions<Value> {
+  initialValues?: Value[];
+  /** Whether an empty selection is accepted; defaults to false */
+  allowEmpty?: boolean;
+}
+
+const unicode = isUnicodeSupported();
+const S = {
+  active: unicode ? '◆' : '*',
+  submit: unicode ? '◇' : 'o',
+  cancel: unicode ? '■' : 'x',
+  bar: unicode ? '│' : '|',
+  end: unicode ? '└' : '—',
+  pointer: unicode ? '›' : '>',
+  radio: unicode ? '○' : '( )',
+  radioOn: unicode ? '●' : '(*)',
+  checkbox: unicode ? '◻' : '[ ]',
+  checkboxOn: unicode ? '◼' : '[+]',
+};
+
+/** Rows shown at once in the rich picker and per listing in the plain one */
+const VISIBLE_ROWS = 8;
+const PLAIN_ROWS = 20;
+/** Start loading the next page this many rows before the end */
+const LOAD_AHEAD = 3;
+
+/**
+ * How well `query` matches `text`, higher is better, or null for no match.
+ * Substrings beat scattered letters, and a prefix beats both; scattered
+ * letters score more when they run together or start words.
+ */
+export function fuzzyScore(query: string, text: string): number | null {
+  const q = query.toLowerCase();
+  const t = text.toLowerCase();
+  if (!q) return 0;
+  const index = t.indexOf(q);
+  if (index === 0) return 300;
+  if (index > 0) return 200 - Math.min(index, 99);
+
+  let score = 0;
+  let from = 0;
+  let run = 0;
+  for (const ch of q) {
+    const found = t.indexOf(ch, from);
+    if (found === -1) return null;
+    run = found === from && from > 0 ? run + 1 : 0;
+    const wordStart = found === 0 || !/[a-z0-9]/.test(t[found - 1]);
+    score += 1 + run * 2 + (wordStart ? 3 : 0);
+    from = found + 1;
+  }
+  return Math.min(score, 99);
+}
+
+function itemScore<Value>(item: PickerItem<Value>, terms: string[]): number | null {
+  const fields = [item.label, String(item.value), item.hint ?? '', ...(item.keywords ?? [])];
+  let total = 0;
+  // Every word of the query has to match some field
+  for (const term of terms) {
+    let best: number | null = null;
+    for (const field of fields) {
+      const score = fuzzyScore(term, field);
+      if (score !== null && (best === null || score > best)) best = score;
+    }
+    if (best === null) return null;
+    total += best;
+  }
+  return total;
+}
+
+/** Items matching `query`, best first; ties keep list order */
+export function filterPickerItems<Value>(items: PickerItem<Value>[], query: string): PickerItem<Value>[] {
+  const terms = query.trim().split(/\s+/).filter(Boolean);
+  if (terms.length === 0) return items;
+  return items
+    .map((item, index) => ({ item, index, score: itemScore(item, terms) }))
+    .filter((entry): entry is { item: PickerItem<Value>; index: number; score: number } => entry.score !== null)
+    .sort((a, b) => b.score - a.score || a.index - b.index)
+    .map((entry) => entry.item);
+}
+
+/** The loaded items and whether more pages remain */
+class PickerList<Value> {
+  items: PickerItem<Value>[];
+  done: boolean;
+  loading = false;
+  error: string | null = null;
+
+  constructor(private readonly options: PickerOptions<Value>) {
+    this.items = [...options.items];
+    this.done = !options.loadMore;
+  }
+
+  async loadMore(): Promise<void> {
+    if (this.done || this.loading) return;
+    this.loading = true;
+    try {
+      const page = await this.options.loadMore!();
+      this.items.push(...page.items);
+      this.done = page.done || page.items.length === 0;
+    } catch (error) {
+      // Keep what's loaded; picking from it still works
+      this.error = error instanceof Error ? error.message : String(error);
+      this.done = true;
+    } finally {
+      this.loading = false;
+    }
+  }
+
+  /** e.g. "3 matches · 100 loaded, more as you scroll" */
+  count(query: string, matches: number): string {
+    const loaded = this.done
+      ? `${this.items.length} of ${this.items.length}`
+      : `${this.items.length} loaded, ${this.loading ? 'loading more…' : 'more as you scroll'}`;
+    const parts = [query.trim() ? `${matches} ${matches === 1 ? 'match' : 'matches'}` : null, loaded];
+    if (this.error) parts.push(`couldn't load more: ${this.error}`);
+    return parts.filter(Boolean).join(' · ');
+  }
+}
+
+function label<Value>(item: PickerItem<Value>, width: number): string {
+  const hint = item.hint && item.hint !== item.label ? item.hint : '';
+  const room = Math.max(width - hint.length - 1, 10);
+  const text = item.label.length > room ? `${item.label.slice(0, room - 1)}…` : item.label;
+  return hint ? `${text} ${chalk.dim(hint)}` : text;
+}
+
+/**
+ * The rich picker: raw keypresses, redrawn in place. Resolves with the
+ * picked values, or PLAIN_CANCEL on Esc or Ctrl+C.
+ */
+function richPick<Value>(
+  options: MultiPickerOptions<Value>,
+  multiple: boolean,
+): Promise<Value[] | typeof PLAIN_CANCEL> {
+  const input = process.stdin;
+  const output = process.stdout;
+  const list = new PickerList(options);
+  const selected = new Set<Value>(options.initialValues ?? []);
+  const message = withPromptPrefix(options.message);
+  let query = '';
+  let cursor = 0;
+  let offset = 0;
+  let drawn = 0;
+  let notice: string | null = null;
+  let finished = false;
+
+  return new Promise((resolve) => {
+    const matches = () => filterPickerItems(list.items, query);
+
+    const draw = (lines: string[]) => {
+      const clear = drawn > 0 ? `\x1b[${drawn}A\r\x1b[J` : '\r\x1b[J';
+      output.write(`${clear}${lines.join('\n')}\n`);
+      drawn = lines.length;
+    };
+
+    const render = () => {
+      if (finished) return;
+      const shown = matches();
+      cursor = Math.min(cursor, Math.max(shown.length - 1, 0));
+      if (cursor < offset) offset = cursor;
+      if (cursor >= offset + VISIBLE_ROWS) offset = cursor - VISIBLE_ROWS + 1;
+      const width = (output.columns || 80) - 8;
+      const bar = chalk.cyan(S.bar);
+
+      const lines = [`${chalk.gray(S.bar)}`, `${chalk.cyan(S.active)}  ${message}`];
+      lines.push(`${bar}  ${chalk.dim('Search:')} ${query || chalk.dim('type to filter')}`);
+      if (shown.length === 0) {
+        lines.push(`${bar}  ${chalk.dim(list.loading ? 'Searching…' : 'No matches')}`);
+      }
+      shown.slice(offset, offset + VISIBLE_ROWS).forEach((item, i) => {
+        const active = offset + i === cursor;
+        const mark = multiple
+          ? selected.has(item.value)
+            ? chalk.green(S.checkboxOn)
+            : chalk.dim(S.checkbox)
+          : active
+            ? chalk.green(S.radioOn)
+            : chalk.dim(S.radio);
+        const text = label(item, width);
+        lines.push(`${bar}  ${active ? chalk.cyan(S.pointer) : ' '} ${mark} ${active ? text : chalk.dim(text)}`);
+      });
+      const count = list.count(query, shown.length) + (multiple ? ` · ${selected.size} selected` : '');
+      lines.push(`${bar}  ${chalk.dim(count)}`);
+      const keys = multiple
+        ? '↑/↓ move · tab select · enter confirm · esc cancel'
+        : '↑/↓ move · enter select · esc cancel';
+      lines.push(
+        notice ? `${chalk.yellow(S.end)}  ${chalk.yellow(notice)}` : `${chalk.cyan(S.end)}  ${chalk.dim(keys)}`,
+      );
+      draw(lines);
+    };
+
+    // Near the end of the list, or a filter with too few matches: fetch the next page
+    const maybeLoad = () => {
+      if (list.done || list.loading) return;
+      const count = matches().length;
+      if (cursor >= count - LOAD_AHEAD || (query.trim() && count < VISIBLE_ROWS)) {
+        void list.loadMore().then(() => {
+          render();
+          maybeLoad();
+        });
+        render();
+      }
+    };
+
+    const finish = (result: Value[] | typeof PLAIN_CANCEL, summary: string) => {
+      finished = true;
+      input.off('keypress', onKeypress);
+      input.setRawMode(false);
+      input.pause();
+      const symbol = result === PLAIN_CANCEL ? chalk.red(S.cancel) : chalk.green(S.submit);
+      const clear = drawn > 0 ? `\x1b[${drawn}A\r\x1b[J` : '';
+      output.write(`${clear}${chalk.gray(S.bar)}\n${symbol}  ${message}\n${chalk.gray(S.bar)}  ${summary}\n\x1b[?25h`);
+      resolve(result);
+    };
+
+    const onKeypress = (ch: string | undefined, key: { name?: string; ctrl?: boolean; meta?: boolean } = {}) => {
+      notice = null;
+      const shown = matches();
+      if (key.name === 'escape' || (key.ctrl && key.name === 'c')) {
+        finish(PLAIN_CANCEL, chalk.strikethrough.dim(query || 'Cancelled'));
+        return;
+      }
+      if (key.name === 'return') {
+        const current = shown[cursor];
+        if (!multiple) {
+          if (current) finish([current.value], chalk.dim(current.label));
+          return;
+        }
+        // Nothing ticked: Enter takes the highlighted item
+        if (selected.size === 0 && current && !options.allowEmpty) selected.add(current.value);
+        if (selected.size === 0 && !options.allowEmpty) {
+          notice = 'Select at least one item';
+          render();
+          return;
+        }
+        const picked = list.items.filter((item) => selected.has(item.value));
+        finish([...selected], chalk.dim(picked.map((item) => item.label).join(', ') || 'None'));
+        return;
+      }
+      if (key.name === 'up') cursor = Math.max(cursor - 1, 0);
+      else if (key.name === 'down') cursor = Math.min(cursor + 1, Math.max(shown.length - 1, 0));
+      else if (key.name === 'pageup') cursor = Math.max(cursor - VISIBLE_ROWS, 0);
+      else if (key.name === 'pagedown') cursor = Math.min(cursor + VISIBLE_ROWS, Math.max(shown.length - 1, 0));
+      else if (key.name === 'tab' && multiple) {
+        const current = shown[cursor];
+        if (current && !selected.delete(current.value)) selected.add(current.value);
+      } else if (key.name === 'backspace') {
+        query = query.slice(0, -1);
+        cursor = 0;
+      } else if (key.ctrl && key.name === 'u') {
+        query = '';
+        cursor = 0;
+      } else if (ch && !key.ctrl && !key.meta && ch >= ' ' && ch.length === 1) {
+        query += ch;
+        cursor = 0;
+      }
+      render();
+      maybeLoad();
+    };
+
+    emitKeypressEvents(input);
+    input.setRawMode(true);
+    input.resume();
+    input.on('keypress', onKeypress);
+    output.write('\x1b[?25l');
+    render();
+    maybeLoad();
+  });
+}
+
+/**
+ * The numbered fallback: lists the matches, takes numbers to pick, other
+ * text to filter, "more" for the next page, or an empty line to clear the
+ * filter (or, unfiltered, take the first item or keep the current picks).
+ */
+async function plainPick<Value>(
+  options: MultiPickerOptions<Value>,
+  multiple: boolean,
+): Promise<Value[] | typeof PLAIN_CANCEL> {
+  const list = new PickerList(options);
+  let query = '';
+
+  for (;;) {
+    let shown = filterPickerItems(list.items, query);
+    // A filter that runs short searches the pages not loaded yet
+    while (query && shown.length < PLAIN_ROWS && !list.done) {
+      await list.loadMore();
+      shown = filterPickerItems(list.items, query);
+    }
+    const page = shown.slice(0, PLAIN_ROWS);
+
+    say(withPromptPrefix(options.message) + (query ? ` (matching "${query}")` : ''));
+    page.forEach((item, i) => {
+      const mark = multiple ? (options.initialValues?.includes(item.value) ? '[x] ' : '[ ] ') : '';
+      say(`  ${i + 1}) ${mark}${item.label}${item.hint && item.hint !== item.label ? ` (${item.hint})` : ''}`);
+    });
+    if (page.length === 0) say('  No matches');
+    say(`  ${list.count(query, shown.length)}${shown.length > page.length ? `, first ${page.length} shown` : ''}`);
+
+    const answer = await readLine(
+      multiple ? 'Numbers separated by commas, text to filter, or "more": ' : 'A number, text to filter, or "more": ',
+    );
+    if (typeof answer === 'symbol') return PLAIN_CANCEL;
+
+    if (answer === '') {
+      if (query) {
+        query = '';
+        continue;
+      }
+      if (!multiple && page[0]) return [page[0].value];
+      if (multiple && (options.initialValues?.length || options.allowEmpty)) return options.initialValues ?? [];
+      say(multiple ? 'Select at least one item' : 'Nothing to pick');
+      continue;
+    }
+    if (/^more$/i.test(answer)) {
+      if (list.done) say('Everything is loaded');
+      else await list.loadMore();
+      continue;
+    }
+    if (/^[\d\s,]+$/.test(answer)) {
+      const indexes = answer.split(/[\s,]+/).filter(Boolean).map((n) => Number(n) - 1);
+      if (indexes.length > 0 && indexes.every((i) => page[i]) && (multiple || indexes.length === 1)) {
+        return [...new Set(indexes.map((i) => page[i].value))];
+      }
+      say(`Enter ${multiple ? 'numbers' : 'a number'} from 1 to ${page.length}`);
+      continue;
+    }
+    query = answer;
+  }
+}
+
+/** Under `--machine` the driver filters for itself, so it gets every item in one prompt */
+async function machinePick<Value>(
+  options: MultiPickerOptions<Value>,
+  multiple: boolean,
+): Promise<Value[] | typeof PLAIN_CANCEL> {
+  const list = new PickerList(options);
+  while (!list.done) await list.loadMore();
+  const result = await machinePrompt(multiple ? 'multiselect' : 'select', {
+    message: withPromptPrefix(options.message),
+    flag: options.flag,
+    options: list.items,
+    initialValues: options.initialValues,
+    required: !options.allowEmpty,
+  });
+  if (result === PLAIN_CANCEL) return PLAIN_CANCEL;
+  return multiple ? (result as Value[]) : [result as Value];
+}
+
+function run<Value>(options: MultiPickerOptions<Value>, multiple: boolean): Promise<Value[] | typeof PLAIN_CANCEL> {
+  const mode = getPromptMode();
+  if (mode === 'none') return Promise.reject(new NonInteractiveError(options.message, options.flag));
+  if (mode === 'machine') return machinePick(options, multiple);
+  return mode === 'plain' ? plainPick(options, multiple) : richPick(options, multiple);
+}
+
+/** Pick one item; `clack.isCancel` recognizes the cancel result */
+export async function pick<Value>(options: PickerOptions<Value>): Promise<Value | symbol> {
+  const result = await run(options, false);
+  return result === PLAIN_CANCEL ? result : result[0];
+}
+
+/** Pick any number of items; Enter with none ticked takes the highlighted one */
+export async function pickMany<Value>(options: MultiPickerOptions<Value>): Promise<Value[] | symbol> {
+  return run(options, true);
+}
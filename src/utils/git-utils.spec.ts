@@ -0,0 +1,65 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { execFileSync } from 'node:child_process';
+import { mkdtempSync, rmSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { join } from 'node:path';
+import { allowsProtectedBranchCommits, isProtectedBranch, protectedBranchReason } from './git-utils.js';
+
+function git(args: string[], cwd: string): string {
+  return execFileSync('git', args, { cwd, stdio: ['ignore', 'pipe', 'ignore'] })
+    .toString()
+    .trim();
+}
+
+describe('git-utils', () => {
+  let repo: string;
+  const globalConfig = process.env.GIT_CONFIG_GLOBAL;
+
+  beforeEach(() => {
+    // Keep the machine's own git config out of the checks
+    process.env.GIT_CONFIG_GLOBAL = '/dev/null';
+    repo = mkdtempSync(join(tmpdir(), 'git-utils-'));
+    git(['init', '-b', 'main'], repo);
+  });
+
+  afterEach(() => {
+    rmSync(repo, { recursive: true, force: true });
+    if (globalConfig === undefined) delete process.env.GIT_CONFIG_GLOBAL;
+    else process.env.GIT_CONFIG_GLOBAL = globalConfig;
+  });
+
+  it('treats usual trunk and release names as protected', () => {
+    expect(isProtectedBranch('main')).toBe(true);
+    expect(isProtectedBranch('release/2.1')).toBe(true);
+    expect(isProtectedBranch('feat/add-workos-authkit')).toBe(false);
+  });
+
+  it('explains why a branch is protected', () => {
+    git(['config', '--add', 'workos.protectedBranch', 'staging'], repo);
+    git(['config', '--add', 'workos.protectedBranch', 'hotfix/*'], repo);
+    git(['config', 'init.defaultBranch', 'stable'], repo);
+
+    expect(protectedBranchReason('hotfix/login', repo)).toBe(
+      'it matches workos.protectedBranch "hotfix/*" in git config',
+    );
+    expect(protectedBranchReason('stable', repo)).toBe('it is init.defaultBranch in git config');
+    expect(protectedBranchReason('main', repo)).toBe('it is usually a protected branch');
+    expect(protectedBranchReason('hotfix-login', repo)).toBeNull();
+  });
+
+  it("protects origin's default branch whatever its name", () => {
+    git(['config', 'user.email', 'dev@example.com'], repo);
+    git(['config', 'user.name', 'Dev'], repo);
+    git(['commit', '--allow-empty', '-m', 'init'], repo);
+    git(['update-ref', 'refs/remotes/origin/edge', 'HEAD'], repo);
+    git(['symbolic-ref', 'refs/remotes/origin/HEAD', 'refs/remotes/origin/edge'], repo);
+
+    expect(protectedBranchReason('edge', repo)).toBe("it is origin's default branch");
+  });
+
+  it('reads workos.allowMain as a git boolean', () => {
+    expect(allowsProtectedBranchCommits(repo)).toBe(false);
+    git(['config', 'workos.allowMain', 'yes'], repo);
+    expect(allowsProtectedBranchCommits(repo)).toBe(true);
+  });
+});
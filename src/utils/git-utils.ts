@@ -1,14 +1,16 @@
 import { execSync, execFileSync } from 'node:child_process';
 
-const PROTECTED_BRANCHES = ['main', 'master', 'develop'];
+const PROTECTED_BRANCHES = ['main', 'master', 'develop', 'trunk', 'production'];
+const PROTECTED_PREFIXES = ['release/'];
 
 /**
  * Get the current git branch name.
  * Returns null if not in a git repo or if the command fails.
  */
-export function getCurrentBranch(): string | null {
+export function getCurrentBranch(cwd?: string): string | null {
   try {
     return execSync('git rev-parse --abbrev-ref HEAD', {
+      cwd,
       stdio: ['ignore', 'pipe', 'ignore'],
     })
       .toString()
@@ -19,16 +21,80 @@ export function getCurrentBranch(): string | null {
 }
 
 /**
- * Check if a branch name is considered protected (main, master, develop).
+ * Check if a branch name is considered protected (main, master, develop,
+ * trunk, production, release/*).
  */
 export function isProtectedBranch(branch: string): boolean {
-  return PROTECTED_BRANCHES.includes(branch);
+  return PROTECTED_BRANCHES.includes(branch) || PROTECTED_PREFIXES.some((prefix) => branch.startsWith(prefix));
+}
+
+/** Every value of a git config key, from any scope; empty when unset */
+function gitConfigValues(key: string, cwd?: string, { bool = false } = {}): string[] {
+  try {
+    return execFileSync('git', ['config', ...(bool ? ['--bool'] : []), '--get-all', key], {
+      cwd,
+      stdio: ['ignore', 'pipe', 'ignore'],
+    })
+      .toString()
+      .split('\n')
+      .map((value) => value.trim())
+      .filter(Boolean);
+  } catch {
+    return [];
+  }
+}
+
+/** Match a branch against a `workos.protectedBranch` pattern, where `*` is any run of characters */
+function matchesBranchPattern(branch: string, pattern: string): boolean {
+  const source = pattern
+    .split('*')
+    .map((part) => part.replace(/[.+?^${}()|[\]\\]/g, '\\$&'))
+    .join('.*');
+  return new RegExp(`^${source}$`).test(branch);
+}
+
+/**
+ * Why committing to `branch` should be avoided, or null when it's a feature
+ * branch. A branch is protected when it matches a `workos.protectedBranch`
+ * value in git config (set it globally for every repo, once per pattern),
+ * is origin's default branch or `init.defaultBranch`, or has a protected
+ * name.
+ */
+export function protectedBranchReason(branch: string, cwd?: string): string | null {
+  const pattern = gitConfigValues('workos.protectedBranch', cwd).find((p) => matchesBranchPattern(branch, p));
+  if (pattern) return `it matches workos.protectedBranch "${pattern}" in git config`;
+  try {
+    const ref = execFileSync('git', ['symbolic-ref', 'refs/remotes/origin/HEAD'], {
+      cwd,
+      stdio: ['ignore', 'pipe', 'ignore'],
+    })
+      .toString()
+      .trim();
+    if (ref.replace('refs/remotes/origin/', '') === branch) return "it is origin's default branch";
+  } catch {
+    // No origin, or origin/HEAD isn't set
+  }
+  if (gitConfigValues('init.defaultBranch', cwd)[0] === branch) return 'it is init.defaultBranch in git config';
+  if (isProtectedBranch(branch)) return 'it is usually a protected branch';
+  return null;
+}
+
+/**
+ * Whether `workos.allowMain` is set in git config, the lasting form of
+ * --allow-main.
+ */
+export function allowsProtectedBranchCommits(cwd?: string): boolean {
+  return gitConfigValues('workos.allowMain', cwd, { bool: true }).at(-1) === 'true';
 }
 
 export function createBranch(name: string): void {
   execFileSync('git', ['checkout', '-b', name], { stdio: 'ignore' });
 }
 
+export function checkoutBranch(name: string): void {
+  execFileSync('git', ['checkout', name], { stdio: 'ignore' });
+}
+
 export function branchExists(name: string): boolean {
   try {
     execFileSync('git', ['rev-parse', '--verify', name], { stdio: 'ignore' });
@@ -84,3 +150,57 @@ export function getUncommittedFiles(): string[] {
     return [];
   }
 }
+
+/**
+ * Current commit, or null outside a git repo or before the first commit.
+ */
+export function getHeadCommit(): string | null {
+  try {
+    return execFileSync('git', ['rev-parse', '--verify', 'HEAD'], { stdio: ['ignore', 'pipe', 'ignore'] })
+      .toString()
+      .trim();
+  } catch {
+    return null;
+  }
+}
+
+export interface FileChanges {
+  created: string[];
+  modified: string[];
+  deleted: string[];
+}
+
+function git(args: string[]): string {
+  return execFileSync('git', args, { stdio: ['ignore', 'pipe', 'ignore'] }).toString();
+}
+
+/**
+ * Files created, modified or deleted since `ref`, committed or not. Without a
+ * ref (no commits yet) it's the working tree's status. Returns null outside a
+ * git repo.
+ */
+export function getChangesSince(ref: string | null): FileChanges | null {
+  const changes: FileChanges = { created: [], modified: [], deleted: [] };
+  try {
+    if (ref) {
+      for (const line of git(['diff', '--name-status', '--no-renames', ref]).split('\n').filter(Boolean)) {
+        const [status, path] = line.split('\t');
+        if (status === 'A') changes.created.push(path);
+        else if (status === 'D') changes.deleted.push(path);
+        else changes.modified.push(path);
+      }
+      changes.created.push(...git(['ls-files', '--others', '--exclude-standard']).split('\n').filter(Boolean));
+    } else {
+      for (const line of git(['status', '--porcelain', '--untracked-files=all']).split('\n').filter(Boolean)) {
+        const status = line.slice(0, 2);
+        const path = line.slice(3);
+        if (status === '??' || status.includes('A')) changes.created.push(path);
+        else if (status.includes('D')) changes.deleted.push(path);
+        else changes.modified.push(path);
+      }
+    }
+  } catch {
+    return null;
+  }
+  return changes;
+}
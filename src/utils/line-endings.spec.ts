@@ -0,0 +1,15 @@
+import { describe, it, expect } from 'vitest';
+import { detectLineEnding, normalizeLineEndings } from './line-endings.js';
+
+describe('line-endings', () => {
+  it('detects the dominant line ending', () => {
+    expect(detectLineEnding('a\r\nb\r\nc\n')).toBe('\r\n');
+    expect(detectLineEnding('a\nb\nc\r\n')).toBe('\n');
+    expect(detectLineEnding('single line')).toBe('\n');
+  });
+
+  it('converts mixed line endings either way', () => {
+    expect(normalizeLineEndings('a\r\nb\nc')).toBe('a\nb\nc');
+    expect(normalizeLineEndings('a\r\nb\nc', '\r\n')).toBe('a\r\nb\r\nc');
+  });
+});
@@ -0,0 +1,19 @@
+/**
+ * Line-ending helpers, so edits generated with LF match the file they land in
+ * (e.g. CRLF files checked out on Windows).
+ */
+
+export type LineEnding = '\r\n' | '\n';
+
+/** The dominant line ending; LF for text without line breaks */
+export function detectLineEnding(text: string): LineEnding {
+  const crlf = text.match(/\r\n/g)?.length ?? 0;
+  const lf = (text.match(/\n/g)?.length ?? 0) - crlf;
+  return crlf > lf ? '\r\n' : '\n';
+}
+
+/** Convert every line break to `eol` (LF by default) */
+export function normalizeLineEndings(text: string, eol: LineEnding = '\n'): string {
+  const lf = text.replace(/\r\n/g, '\n');
+  return eol === '\n' ? lf : lf.replace(/\n/g, '\r\n');
+}
@@ -0,0 +1,208 @@
+/**
+ * Prompts under `--machine`: each question goes out as a `prompt` message
+ * and its answer comes back on stdin as a JSON line naming the prompt's id.
+ * See lib/installer-proto.ts for the protocol.
+ *
+ * Answers are matched by id, so a driver can answer prompts that are open
+ * at the same time in any order, and an answer that arrives before its
+ * prompt is kept until the prompt is asked.
+ */
+
+import { createInterface } from 'node:readline';
+import type { Readable } from 'node:stream';
+import {
+  writeMachineMessage,
+  type MachineAnswer,
+  type MachinePromptKind,
+  type MachinePromptOption,
+} from '../lib/installer-proto.js';
+import { PLAIN_CANCEL } from './plain-prompts.js';
+
+let machineMode = false;
+
+export function setMachineMode(enabled: boolean): void {
+  machineMode = enabled;
+}
+
+export function isMachineMode(): boolean {
+  return machineMode;
+}
+
+type Validate = (value: string) => string | Error | undefined;
+
+interface Option<Value> {
+  value: Value;
+  label?: string;
+  hint?: string;
+}
+
+export interface MachinePromptOptions {
+  message: string;
+  /** Stable id for the answer to name; derived from the flag or message when unset */
+  id?: string;
+  flag?: string;
+  options?: Option<unknown>[];
+  initialValue?: unknown;
+  initialValues?: unknown[];
+  defaultValue?: string;
+  required?: boolean;
+  validate?: Validate;
+}
+
+interface Pending {
+  answer: (answer: MachineAnswer) => void;
+}
+
+let input: Readable = process.stdin;
+let reader: ReturnType<typeof createInterface> | null = null;
+let closed = false;
+const pending = new Map<string, Pending>();
+/** Answers that came in before their prompt was asked */
+const early = new Map<string, MachineAnswer[]>();
+
+/** @internal For testing only */
+export function _setMachineInput(stream: Readable): void {
+  reader?.close();
+  reader = null;
+  closed = false;
+  pending.clear();
+  early.clear();
+  input = stream;
+}
+
+function parseAnswer(line: string): MachineAnswer | string {
+  let answer: unknown;
+  try {
+    answer = JSON.parse(line);
+  } catch {
+    return 'Answers must be JSON, e.g. {"id":"commit","value":true}';
+  }
+  if (!answer || typeof answer !== 'object' || typeof (answer as MachineAnswer).id !== 'string') {
+    return 'Answers must be objects with a string "id"';
+  }
+  return answer as MachineAnswer;
+}
+
+function startReading(): void {
+  if (reader || closed) return;
+  reader = createInterface({ input, terminal: false });
+  reader.on('line', (line) => {
+    if (!line.trim()) return;
+    const answer = parseAnswer(line);
+    if (typeof answer === 'string') {
+      writeMachineMessage({ type: 'prompt:invalid', id: null, message: answer });
+      return;
+    }
+    const waiting = pending.get(answer.id);
+    if (waiting) waiting.answer(answer);
+    else early.set(answer.id, [...(early.get(answer.id) ?? []), answer]);
+  });
+  reader.on('close', () => {
+    closed = true;
+    reader = null;
+    for (const waiting of pending.values()) waiting.answer({ id: '', cancel: true });
+  });
+}
+
+function stripAnsi(text: string): string {
+  // eslint-disable-next-line no-control-regex
+  return text.replace(/\x1b\[[0-9;]*m/g, '');
+}
+
+/** e.g. "Enter your WorkOS Client ID:" → "enter-your-workos-client-id" */
+function slug(text: string): string {
+  return (
+    stripAnsi(text)
+      .toLowerCase()
+      .replace(/[^a-z0-9]+/g, '-')
+      .replace(/^-+|-+$/g, '')
+      .slice(0, 60) || 'prompt'
+  );
+}
+
+export function promptId(opts: { id?: string; flag?: string; message: string }): string {
+  return opts.id ?? (opts.flag ? opts.flag.replace(/^-+/, '') : slug(opts.message));
+}
+
+/** Option values go out as they are when JSON can carry them, otherwise as their index */
+function wireValue(value: unknown, index: number): MachinePromptOption['value'] {
+  return typeof value === 'string' || typeof value === 'number' || typeof value === 'boolean' ? value : index;
+}
+
+/** The prompt's result for an answer, or why the answer doesn't fit */
+function accept(kind: MachinePromptKind, opts: MachinePromptOptions, value: unknown): { value: unknown } | string {
+  const options = opts.options ?? [];
+  const find = (wire: unknown) => options.find((o, i) => wireValue(o.value, i) === wire);
+  switch (kind) {
+    case 'confirm':
+      return typeof value === 'boolean' ? { value } : 'Answer true or false';
+    case 'select': {
+      const option = find(value);
+      return option ? { value: option.value } : 'Answer with one of the option values';
+    }
+    case 'multiselect': {
+      if (!Array.isArray(value) || !value.every((v) => find(v))) return 'Answer with an array of option values';
+      if (value.length === 0 && opts.required !== false) return 'Select at least one option';
+      return { value: [...new Set(value.map((v) => find(v)!.value))] };
+    }
+    case 'text':
+    case 'password': {
+      if (typeof value !== 'string') return 'Answer with a string';
+      const text = value === '' && opts.defaultValue !== undefined ? opts.defaultValue : value;
+      const error = opts.validate?.(text);
+      return error ? (error instanceof Error ? error.message : error) : { value: text };
+    }
+  }
+}
+
+/**
+ * Ask over the protocol and wait for a fitting answer. Resolves to
+ * PLAIN_CANCEL, which `clack.isCancel` recognizes, on a cancel answer or
+ * when stdin closes.
+ */
+export function machinePrompt(kind: MachinePromptKind, opts: MachinePromptOptions): Promise<unknown> {
+  const id = promptId(opts);
+  const initial = kind === 'multiselect' ? opts.initialValues : (opts.initialValue ?? opts.defaultValue);
+  writeMachineMessage({
+    type: 'prompt',
+    id,
+    kind,
+    message: stripAnsi(opts.message),
+    ...(opts.options && {
+      options: opts.options.map((o, i) => ({
+        value: wireValue(o.value, i),
+        label: o.label ?? String(o.value),
+        ...(o.hint && { hint: o.hint }),
+      })),
+    }),
+    ...(initial !== undefined && kind !== 'password' && { initial }),
+    ...(opts.flag && { flag: opts.flag }),
+  });
+
+  return new Promise((resolve) => {
+    const answer = (reply: MachineAnswer) => {
+      if (reply.cancel) {
+        pending.delete(id);
+        resolve(PLAIN_CANCEL);
+        return;
+      }
+      const result = accept(kind, opts, reply.value);
+      if (typeof result === 'string') {
+        writeMachineMessage({ type: 'prompt:invalid', id, message: result });
+        return;
+      }
+      pending.delete(id);
+      resolve(result.value);
+    };
+
+    const queued = early.get(id);
+    if (closed && !queued?.length) {
+      resolve(PLAIN_CANCEL);
+      return;
+    }
+    pending.set(id, { answer });
+    startReading();
+    while (queued?.length && pending.has(id)) answer(queued.shift()!);
+    if (queued && queued.length === 0) early.delete(id);
+  });
+}
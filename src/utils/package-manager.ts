@@ -5,6 +5,18 @@ import { traceStep } from '../telemetry.js';
 import { getPackageDotJson, updatePackageDotJson } from './clack-utils.js';
 import { analytics } from './analytics.js';
 import type { InstallerOptions } from './types.js';
+import { findWorkspaceRoot } from '../lib/workspaces.js';
+
+/**
+ * Path to a lockfile for the project. In monorepos the lockfile lives at the
+ * workspace root rather than in the package being installed into.
+ */
+function lockfilePath(installDir: string, lockFile: string): string {
+  const local = path.join(installDir, lockFile);
+  if (fs.existsSync(local)) return local;
+  const root = findWorkspaceRoot(installDir);
+  return root ? path.join(root, lockFile) : local;
+}
 
 export interface PackageManager {
   name: string;
@@ -32,7 +44,7 @@ export const BUN: PackageManager = {
   flags: '',
   forceInstallFlag: '--force',
   detect: ({ installDir }: Pick<InstallerOptions, 'installDir'>) =>
-    ['bun.lockb', 'bun.lock'].some((lockFile) => fs.existsSync(path.join(installDir, lockFile))),
+    ['bun.lockb', 'bun.lock'].some((lockFile) => fs.existsSync(lockfilePath(installDir, lockFile))),
   addOverride: async (pkgName, pkgVersion, { installDir }: Pick<InstallerOptions, 'installDir'>): Promise<void> => {
     const packageDotJson = await getPackageDotJson({ installDir });
     const overrides = packageDotJson.overrides || {};
@@ -59,7 +71,7 @@ export const YARN_V1: PackageManager = {
   forceInstallFlag: '--force',
   detect: ({ installDir }: Pick<InstallerOptions, 'installDir'>) => {
     try {
-      return fs.readFileSync(path.join(installDir, 'yarn.lock'), 'utf-8').slice(0, 500).includes('yarn lockfile v1');
+      return fs.readFileSync(lockfilePath(installDir, 'yarn.lock'), 'utf-8').slice(0, 500).includes('yarn lockfile v1');
     } catch (e) {
       return false;
     }
@@ -91,7 +103,7 @@ export const YARN_V2: PackageManager = {
   forceInstallFlag: '--force',
   detect: ({ installDir }: Pick<InstallerOptions, 'installDir'>) => {
     try {
-      return fs.readFileSync(path.join(installDir, 'yarn.lock'), 'utf-8').slice(0, 500).includes('__metadata');
+      return fs.readFileSync(lockfilePath(installDir, 'yarn.lock'), 'utf-8').slice(0, 500).includes('__metadata');
     } catch (e) {
       return false;
     }
@@ -121,7 +133,7 @@ export const PNPM: PackageManager = {
   flags: '--ignore-workspace-root-check',
   forceInstallFlag: '--force',
   detect: ({ installDir }: Pick<InstallerOptions, 'installDir'>) =>
-    fs.existsSync(path.join(installDir, 'pnpm-lock.yaml')),
+    fs.existsSync(lockfilePath(installDir, 'pnpm-lock.yaml')),
   addOverride: async (pkgName, pkgVersion, { installDir }: Pick<InstallerOptions, 'installDir'>): Promise<void> => {
     const packageDotJson = await getPackageDotJson({ installDir });
     const pnpm = packageDotJson.pnpm || {};
@@ -151,7 +163,7 @@ export const NPM: PackageManager = {
   flags: '',
   forceInstallFlag: '--force',
   detect: ({ installDir }: Pick<InstallerOptions, 'installDir'>) =>
-    fs.existsSync(path.join(installDir, 'package-lock.json')),
+    fs.existsSync(lockfilePath(installDir, 'package-lock.json')),
   addOverride: async (pkgName, pkgVersion, { installDir }: Pick<InstallerOptions, 'installDir'>): Promise<void> => {
     const packageDotJson = await getPackageDotJson({ installDir });
     const overrides = packageDotJson.overrides || {};
@@ -0,0 +1,12 @@
+import { describe, it, expect } from 'vitest';
+import { toPosixPath } from './paths.js';
+
+describe('paths', () => {
+  it('writes Windows paths with forward slashes', () => {
+    expect(toPosixPath('apps\\web\\.env.local', '\\')).toBe('apps/web/.env.local');
+  });
+
+  it('leaves POSIX paths alone', () => {
+    expect(toPosixPath('apps/web/.env.local', '/')).toBe('apps/web/.env.local');
+  });
+});
@@ -0,0 +1,22 @@
+/**
+ * Project-relative paths as the installer stores and shows them.
+ *
+ * Paths written into files (migration state, reports, diff headers,
+ * .gitignore entries, the agent prompt) and compared against git's output
+ * always use forward slashes, whatever the platform: git and .gitignore
+ * require them, and state written on Windows stays valid elsewhere. Paths
+ * only convert to the native form when they touch the filesystem, through
+ * `path.join`/`path.resolve`.
+ */
+
+import { relative, sep } from 'node:path';
+
+/** Forward slashes, for a path relative to the project */
+export function toPosixPath(path: string, separator: string = sep): string {
+  return separator === '/' ? path : path.split(separator).join('/');
+}
+
+/** `path.relative` with forward slashes */
+export function relativePosix(from: string, to: string): string {
+  return toPosixPath(relative(from, to));
+}
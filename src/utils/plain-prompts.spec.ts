@@ -0,0 +1,112 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { PassThrough } from 'node:stream';
+import {
+  PLAIN_CANCEL,
+  _setPromptIO,
+  plainConfirm,
+  plainMultiselect,
+  plainSelect,
+  plainText,
+} from './plain-prompts.js';
+import clack, { NonInteractiveError, getPromptMode } from './clack.js';
+
+describe('plain prompts', () => {
+  let input: PassThrough;
+  let output: PassThrough;
+  let written: string;
+
+  /** Answer each question as it's asked; questions end in a space, other output in a newline */
+  const answer = (...lines: string[]) => {
+    output.on('data', (chunk: Buffer) => {
+      if (chunk.toString().endsWith('\n')) return;
+      const line = lines.shift();
+      if (line === undefined) input.end();
+      else setImmediate(() => input.write(`${line}\n`));
+    });
+  };
+
+  beforeEach(() => {
+    input = new PassThrough();
+    output = new PassThrough();
+    written = '';
+    output.on('data', (chunk: Buffer) => (written += chunk.toString()));
+    _setPromptIO({ input, output });
+  });
+
+  afterEach(() => {
+    _setPromptIO({ input: process.stdin, output: process.stdout });
+  });
+
+  it('confirm takes y/n and falls back to the initial value', async () => {
+    answer('maybe', 'y');
+    expect(await plainConfirm({ message: 'Continue?', initialValue: false })).toBe(true);
+    expect(written).toContain('Continue? (y/N)');
+    expect(written).toContain('Answer y or n');
+  });
+
+  it('select lists numbered options and returns the chosen value', async () => {
+    answer('3', '2');
+    const choice = await plainSelect({
+      message: 'Pick one',
+      options: [
+        { value: 'a', label: 'First' },
+        { value: 'b', label: 'Second', hint: 'recommended' },
+      ],
+    });
+
+    expect(choice).toBe('b');
+    expect(written).toContain('  2) Second (recommended)');
+    expect(written).toContain('Enter a number from 1 to 2');
+  });
+
+  it('multiselect keeps the initial values on an empty answer', async () => {
+    answer('');
+    const selected = await plainMultiselect({
+      message: 'Files',
+      options: [{ value: 'a.ts' }, { value: 'b.ts' }],
+      initialValues: ['b.ts'],
+    });
+
+    expect(selected).toEqual(['b.ts']);
+    expect(written).toContain('[x] b.ts');
+  });
+
+  it('text re-asks until the answer validates', async () => {
+    answer('abc', 'client_123');
+    const value = await plainText({
+      message: 'Client ID',
+      validate: (v) => (v.startsWith('client_') ? undefined : 'Client ID should start with client_'),
+    });
+
+    expect(value).toBe('client_123');
+    expect(written).toContain('Client ID should start with client_');
+  });
+
+  it('cancels when input ends', async () => {
+    const result = plainConfirm({ message: 'Continue?' });
+    input.end();
+
+    expect(await result).toBe(PLAIN_CANCEL);
+    expect(clack.isCancel(PLAIN_CANCEL)).toBe(true);
+  });
+});
+
+describe('prompts without an interactive stdin', () => {
+  const isTTY = process.stdin.isTTY;
+
+  beforeEach(() => {
+    process.stdin.isTTY = false;
+  });
+
+  afterEach(() => {
+    process.stdin.isTTY = isTTY;
+  });
+
+  it('fail with the flag that answers the prompt', async () => {
+    expect(getPromptMode()).toBe('none');
+    const prompt = clack.text({ message: 'Enter your WorkOS Client ID', flag: '--client-id' });
+
+    await expect(prompt).rejects.toBeInstanceOf(NonInteractiveError);
+    await expect(prompt).rejects.toThrow('Use --client-id instead');
+  });
+});
@@ -0,0 +1,155 @@
+/**
+ * Line-based prompts for terminals without raw mode.
+ *
+ * clack's prompts need raw keypresses and ANSI cursor control. Dumb
+ * terminals (TERM=dumb, some IDE consoles, `script`) only deliver whole
+ * lines, so these ask the same questions as numbered lists and y/n answers
+ * and read one line per answer. They return PLAIN_CANCEL, which
+ * `clack.isCancel` recognizes, when input ends.
+ */
+
+import { createInterface } from 'node:readline';
+import type { Readable, Writable } from 'node:stream';
+
+export const PLAIN_CANCEL = Symbol('plain-prompt:cancel');
+
+type Validate = (value: string) => string | Error | undefined;
+
+interface PlainOption<Value> {
+  value: Value;
+  label?: string;
+  hint?: string;
+}
+
+let io: { input: Readable; output: Writable } = { input: process.stdin, output: process.stdout };
+
+/** @internal For testing only */
+export function _setPromptIO(streams: { input: Readable; output: Writable }): void {
+  io = streams;
+}
+
+/** Ask and read one line; shared with the fuzzy picker's fallback */
+export function readLine(question: string): Promise<string | symbol> {
+  return new Promise((resolve) => {
+    const rl = createInterface({ input: io.input, terminal: false });
+    let answered = false;
+    rl.once('line', (line) => {
+      answered = true;
+      rl.close();
+      resolve(line.trim());
+    });
+    rl.once('close', () => {
+      if (!answered) resolve(PLAIN_CANCEL);
+    });
+    io.output.write(question);
+  });
+}
+
+export function say(text: string): void {
+  io.output.write(`${text}\n`);
+}
+
+function validationMessage(result: string | Error | undefined): string | undefined {
+  return result instanceof Error ? result.message : result;
+}
+
+function optionLabel<Value>(option: PlainOption<Value>): string {
+  const label = option.label ?? String(option.value);
+  return option.hint ? `${label} (${option.hint})` : label;
+}
+
+export async function plainText(opts: {
+  message: string;
+  defaultValue?: string;
+  initialValue?: string;
+  validate?: Validate;
+}): Promise<string | symbol> {
+  const fallback = opts.defaultValue ?? opts.initialValue;
+  for (;;) {
+    const answer = await readLine(`${opts.message}${fallback ? ` [${fallback}]` : ''} `);
+    if (typeof answer === 'symbol') return answer;
+    const value = answer === '' && fallback !== undefined ? fallback : answer;
+    const error = validationMessage(opts.validate?.(value));
+    if (!error) return value;
+    say(error);
+  }
+}
+
+/** Without raw mode the terminal echoes input, so say so up front */
+export function plainPassword(opts: { message: string; validate?: Validate }): Promise<string | symbol> {
+  return plainText({ message: `${opts.message} (input is visible)`, validate: opts.validate });
+}
+
+export async function plainConfirm(opts: { message: string; initialValue?: boolean }): Promise<boolean | symbol> {
+  const initial = opts.initialValue ?? true;
+  for (;;) {
+    const answer = await readLine(`${opts.message} ${initial ? '(Y/n)' : '(y/N)'} `);
+    if (typeof answer === 'symbol') return answer;
+    if (answer === '') return initial;
+    if (/^y(es)?$/i.test(answer)) return true;
+    if (/^no?$/i.test(answer)) return false;
+    say('Answer y or n');
+  }
+}
+
+export async function plainSelect<Value>(opts: {
+  message: string;
+  options: PlainOption<Value>[];
+  initialValue?: Value;
+}): Promise<Value | symbol> {
+  const initial = Math.max(0, opts.options.findIndex((o) => o.value === opts.initialValue));
+  say(opts.message);
+  opts.options.forEach((option, i) => say(`  ${i + 1}) ${optionLabel(option)}`));
+  for (;;) {
+    const answer = await readLine(`Enter a number [${initial + 1}]: `);
+    if (typeof answer === 'symbol') return answer;
+    const index = answer === '' ? initial : Number(answer) - 1;
+    if (Number.isInteger(index) && opts.options[index]) return opts.options[index].value;
+    say(`Enter a number from 1 to ${opts.options.length}`);
+  }
+}
+
+export async function plainMultiselect<Value>(opts: {
+  message: string;
+  options: PlainOption<Value>[];
+  initialValues?: Value[];
+  required?: boolean;
+}): Promise<Value[] | symbol> {
+  const initial = opts.options.filter((o) => opts.initialValues?.includes(o.value));
+  say(opts.message);
+  opts.options.forEach((option, i) =>
+    say(`  ${i + 1}) ${opts.initialValues?.includes(option.value) ? '[x]' : '[ ]'} ${optionLabel(option)}`),
+  );
+  for (;;) {
+    const answer = await readLine('Enter numbers separated by commas, "none", or nothing to keep the [x] items: ');
+    if (typeof answer === 'symbol') return answer;
+    let picked: PlainOption<Value>[] | null;
+    if (answer === '') {
+      picked = initial;
+    } else if (/^none$/i.test(answer)) {
+      picked = [];
+    } else {
+      const indexes = answer.split(/[\s,]+/).map((n) => Number(n) - 1);
+      picked = indexes.every((i) => opts.options[i]) ? indexes.map((i) => opts.options[i]) : null;
+    }
+
+    if (!picked) {
+      say(`Enter numbers from 1 to ${opts.options.length}`);
+    } else if (picked.length === 0 && opts.required !== false) {
+      say('Select at least one item');
+    } else {
+      return [...new Set(picked.map((o) => o.value))];
+    }
+  }
+}
+
+/** Spinner stand-in that prints start and stop messages as lines */
+export function plainSpinner() {
+  return {
+    start: (message = '') => say(message),
+    message: () => {},
+    stop: (message = '') => {
+      if (message) say(message);
+    },
+  };
+}
@@ -0,0 +1,23 @@
+import { describe, it, expect } from 'vitest';
+import { formatProgress } from './progress-bar.js';
+import { stripAnsii as plain } from './string.js';
+
+describe('formatProgress', () => {
+  it('shows done/total, errors and an ETA from the rate so far', () => {
+    const line = plain(formatProgress({ total: 100, done: 25, errors: 2, startedAt: 0 }, 10_000));
+
+    expect(line).toBe(`[${'█'.repeat(6)}${'░'.repeat(18)}] 25/100 · 2 errors · ETA 30s`);
+  });
+
+  it('counts resumed tasks as done without using them for the rate', () => {
+    const line = plain(formatProgress({ total: 100, done: 10, errors: 0, skipped: 50, startedAt: 0 }, 60_000));
+
+    expect(line).toMatch(/ 60\/100 · ETA 4m 0s$/);
+  });
+
+  it('ends with the detail, such as the bytes of a download', () => {
+    const line = plain(formatProgress({ total: 4, done: 4, errors: 0, startedAt: 0, detail: 'a/SKILL.md 1.0 MB' }));
+
+    expect(line).toMatch(/ 4\/4 · a\/SKILL\.md 1\.0 MB$/);
+  });
+});
@@ -0,0 +1,76 @@
+import chalk from 'chalk';
+import { isQuietMode } from './clack.js';
+
+export interface ProgressCounts {
+  total: number;
+  done: number;
+  errors: number;
+  /** Counted as done from the start, e.g. finished before a resume */
+  skipped?: number;
+  startedAt: number;
+  /** Shown last, e.g. the bytes of the file being downloaded */
+  detail?: string;
+}
+
+const BAR_WIDTH = 24;
+
+function formatEta(ms: number): string {
+  const seconds = Math.ceil(ms / 1000);
+  const hours = Math.floor(seconds / 3600);
+  const minutes = Math.floor((seconds % 3600) / 60);
+  if (hours > 0) return `${hours}h ${minutes}m`;
+  return minutes > 0 ? `${minutes}m ${seconds % 60}s` : `${seconds}s`;
+}
+
+/** e.g. "[██████░░░░░░] 1200/5000 · 3 errors · ETA 4m 10s" */
+export function formatProgress(progress: ProgressCounts, now = Date.now()): string {
+  const skipped = progress.skipped ?? 0;
+  const finished = progress.done + skipped;
+  const ratio = progress.total > 0 ? finished / progress.total : 1;
+  const filled = Math.round(ratio * BAR_WIDTH);
+  const bar = `[${'█'.repeat(filled)}${chalk.dim('░'.repeat(BAR_WIDTH - filled))}]`;
+
+  const parts = [`${bar} ${finished}/${progress.total}`];
+  if (progress.errors > 0) {
+    parts.push(chalk.red(`${progress.errors} error${progress.errors === 1 ? '' : 's'}`));
+  }
+  // The rate only counts this run's tasks, so a resume doesn't look instant
+  const elapsed = now - progress.startedAt;
+  if (progress.done > 0 && finished < progress.total) {
+    parts.push(`ETA ${formatEta((elapsed / progress.done) * (progress.total - finished))}`);
+  }
+  if (progress.detail) parts.push(progress.detail);
+  return parts.join(chalk.dim(' · '));
+}
+
+/**
+ * Progress bar redrawn in place on stderr, at most every 100ms. Off a
+ * terminal, or with --quiet, it prints nothing, so logs and pipes stay clean.
+ */
+export function createProgressBar(stream: NodeJS.WriteStream = process.stderr): {
+  update(progress: ProgressCounts): void;
+  done(): void;
+} {
+  const enabled = Boolean(stream.isTTY) && process.env.TERM !== 'dumb' && !isQuietMode();
+  let last: ProgressCounts | null = null;
+  let drawnAt = 0;
+
+  const draw = () => {
+    if (!last) return;
+    stream.write(`\r\x1b[2K${formatProgress(last)}`);
+    drawnAt = Date.now();
+  };
+
+  return {
+    update(progress) {
+      last = progress;
+      const finished = progress.done + (progress.skipped ?? 0) >= progress.total;
+      if (enabled && (finished || Date.now() - drawnAt >= 100)) draw();
+    },
+    done() {
+      if (!enabled || !last) return;
+      draw();
+      stream.write('\n');
+    },
+  };
+}
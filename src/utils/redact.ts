@@ -2,6 +2,39 @@
  * Redact sensitive values from logs
  */
 
+export const REDACTED = '****';
+
+/**
+ * Env keys that always hold secrets, regardless of their value shape.
+ */
+const SECRET_ENV_KEYS = new Set([
+  'WORKOS_API_KEY',
+  'WORKOS_COOKIE_PASSWORD',
+  'AUTH0_CLIENT_SECRET',
+  'AUTH0_SECRET',
+  'CLERK_SECRET_KEY',
+  'NEXTAUTH_SECRET',
+  'AUTH_SECRET',
+  'SUPABASE_SERVICE_ROLE_KEY',
+  'ANTHROPIC_API_KEY',
+]);
+
+/** Key-name heuristics for secrets not listed explicitly */
+const SECRET_KEY_PATTERN = /(SECRET|PASSWORD|PASSWD|API_?KEY|PRIVATE_?KEY|ACCESS_?TOKEN|REFRESH_?TOKEN|SERVICE_?ROLE)/i;
+
+/** Value shapes that are secrets wherever they appear */
+const SECRET_VALUE_PATTERNS: RegExp[] = [
+  /\bsk_(?:test|live)_[A-Za-z0-9]{8,}\b/g,
+  /\bsk-ant-[A-Za-z0-9_-]{16,}\b/g,
+  /\bBearer\s+[A-Za-z0-9._~+/-]{16,}=*/g,
+];
+
+/** KEY=value assignments (dotenv, shell, logged config) */
+const ENV_ASSIGNMENT_PATTERN = /\b([A-Z][A-Z0-9_]*)(\s*[=:]\s*)(["']?)([^\s"']+)\3/g;
+
+const HIGH_ENTROPY_CANDIDATE = /[A-Za-z0-9+/_-]{32,}={0,2}/g;
+const MIN_ENTROPY_BITS_PER_CHAR = 4;
+
 export function redactApiKey(apiKey: string): string {
   if (!apiKey || apiKey.length < 12) return '[REDACTED]';
 
@@ -12,6 +45,54 @@ export function redactApiKey(apiKey: string): string {
   return `${prefix}...${suffix}`;
 }
 
+/**
+ * Whether an env/config key is known to hold a secret value.
+ */
+export function isSecretKey(key: string): boolean {
+  return SECRET_ENV_KEYS.has(key.toUpperCase()) || SECRET_KEY_PATTERN.test(key);
+}
+
+function shannonEntropy(value: string): number {
+  const counts = new Map<string, number>();
+  for (const char of value) {
+    counts.set(char, (counts.get(char) ?? 0) + 1);
+  }
+  let entropy = 0;
+  for (const count of counts.values()) {
+    const p = count / value.length;
+    entropy -= p * Math.log2(p);
+  }
+  return entropy;
+}
+
+/**
+ * Heuristic for random-looking tokens (JWT segments, generated passwords, hex secrets).
+ * Requires mixed character classes so long identifiers and paths are left alone.
+ */
+export function isHighEntropyToken(value: string): boolean {
+  if (value.length < 32) return false;
+  const hasDigit = /\d/.test(value);
+  const hasLetter = /[A-Za-z]/.test(value);
+  if (!hasDigit || !hasLetter) return false;
+  return shannonEntropy(value) >= MIN_ENTROPY_BITS_PER_CHAR;
+}
+
+/**
+ * Mask secrets inside free-form text: values assigned to secret keys,
+ * known token formats, and high-entropy tokens.
+ */
+export function redactSecrets(text: string): string {
+  let result = text.replace(ENV_ASSIGNMENT_PATTERN, (match, key: string, sep: string, quote: string) =>
+    isSecretKey(key) ? `${key}${sep}${quote}${REDACTED}${quote}` : match,
+  );
+
+  for (const pattern of SECRET_VALUE_PATTERNS) {
+    result = result.replace(pattern, (match) => (match.startsWith('Bearer') ? `Bearer ${REDACTED}` : REDACTED));
+  }
+
+  return result.replace(HIGH_ENTROPY_CANDIDATE, (match) => (isHighEntropyToken(match) ? REDACTED : match));
+}
+
 export function redactCredentials(obj: any): any {
   if (typeof obj !== 'object' || obj === null) return obj;
 
@@ -24,6 +105,14 @@ export function redactCredentials(obj: any): any {
     if (typeof value === 'string' && value.startsWith('sk_')) {
       redacted[key] = redactApiKey(value);
     }
+    // Redact values stored under secret-looking keys
+    else if (typeof value === 'string' && value && !Array.isArray(redacted) && isSecretKey(key)) {
+      redacted[key] = REDACTED;
+    }
+    // Mask secrets embedded in other strings
+    else if (typeof value === 'string') {
+      redacted[key] = redactSecrets(value);
+    }
     // Recursively redact nested objects
     else if (typeof value === 'object' && value !== null) {
       redacted[key] = redactCredentials(value);
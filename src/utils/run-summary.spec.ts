@@ -0,0 +1,216 @@
+import { describe, it, expect } from 'vitest';
+import { buildRunSummary, formatResultLine, formatRunSummary, summaryWarnings } from './run-summary.js';
+import type { MigrationContext } from '../migrate/types.js';
+
+function strip(str: string): string {
+  // eslint-disable-next-line no-control-regex
+  return str.replace(/\x1B\[[0-9;]*m/g, '');
+}
+
+const migration: MigrationContext = {
+  provider: 'auth0',
+  displayName: 'Auth0',
+  forced: false,
+  confidence: 0.9,
+  envMapping: {},
+  guidance: [],
+  excludedFiles: ['src/legacy.ts'],
+  redirectUris: [{ uri: 'http://localhost:3000/callback', file: 'main.go', line: 34, registered: false }],
+  findings: [
+    {
+      provider: 'auth0',
+      code: 'auth0-rules',
+      severity: 'warning',
+      message: 'Auth0 Rule has no AuthKit equivalent',
+      file: 'rules/enrich.js',
+      line: 4,
+      manual: true,
+      confidence: 0.8,
+    },
+    {
+      provider: 'auth0',
+      code: 'auth0-rules',
+      severity: 'warning',
+      message: 'Auth0 Rule has no AuthKit equivalent',
+      file: 'src/legacy.ts',
+      manual: true,
+      confidence: 0.8,
+    },
+    {
+      provider: 'auth0',
+      code: 'auth0-sdk-import',
+      severity: 'info',
+      message: 'Auth0 SDK import',
+      file: 'src/auth.ts',
+      confidence: 0.9,
+    },
+  ],
+};
+
+describe('run-summary', () => {
+  describe('buildRunSummary', () => {
+    it('describes a plain install', () => {
+      const summary = buildRunSummary({ success: true, summary: 'Done!' });
+      expect(summary.title).toBe('WorkOS AuthKit Installed');
+      expect(summary.changedFiles).toEqual([]);
+      expect(summary.nextSteps.length).toBeGreaterThan(0);
+    });
+
+    it('lists manual changes outside excluded files', () => {
+      const summary = buildRunSummary({ success: true, changedFiles: ['src/auth.ts'], migration });
+      expect(summary.title).toBe('Migrated from Auth0 to WorkOS AuthKit');
+      expect(summary.migratedFrom).toBe('Auth0');
+      expect(summary.excludedFiles).toEqual(['src/legacy.ts']);
+      expect(summary.manualChanges).toEqual(['rules/enrich.js:4 Auth0 Rule has no AuthKit equivalent']);
+      expect(summary.redirectUris).toEqual([{ uri: 'http://localhost:3000/callback', registered: false }]);
+    });
+
+    it('warns about Auth0 Actions and the claims they set', () => {
+      expect(buildRunSummary({ success: true, migration }).warnings).toEqual([]);
+
+      const claim = {
+        provider: 'auth0',
+        code: 'auth0-custom-claim',
+        severity: 'warning' as const,
+        message: 'Reads custom claim',
+        file: 'src/roles.ts',
+        manual: true,
+        confidence: 0.2,
+        details: { claim: 'https://example.com/roles' },
+      };
+      const summary = buildRunSummary({ success: true, migration: { ...migration, findings: [claim] } });
+
+      expect(summary.warnings).toHaveLength(1);
+      expect(summary.warnings[0]).toContain('Custom claims used: https://example.com/roles');
+      expect(formatRunSummary(summary, 'markdown')).toContain('### Warnings\n\n- Auth0 Actions and Rules');
+    });
+
+    it('has no next steps when the run failed', () => {
+      const summary = buildRunSummary({ success: false, summary: 'Build failed', migration });
+      expect(summary.title).toBe('Migration Failed');
+      expect(summary.nextSteps).toEqual([]);
+      expect(summary.docsUrl).toContain('github.com');
+    });
+
+    it('redacts secrets in the message', () => {
+      const summary = buildRunSummary({ success: false, summary: 'Rejected sk_test_abcdefghijklmnop1234' });
+      expect(summary.message).not.toContain('sk_test_abcdefghijklmnop1234');
+    });
+  });
+
+  describe('formatRunSummary', () => {
+    const summary = buildRunSummary({ success: true, changedFiles: ['src/auth.ts'], migration });
+
+    it('renders the box by default', () => {
+      const result = strip(formatRunSummary(summary));
+      expect(result).toMatch(/[┌+]/);
+      expect(result).toContain('1 file changed');
+      expect(result).toContain('Finish 1 manual change');
+      expect(result).toContain('Add redirect URI http://localhost:3000/callback to your WorkOS app');
+    });
+
+    it('renders plain text without box drawing or color', () => {
+      const result = formatRunSummary(summary, 'plain');
+      expect(result).toBe(strip(result));
+      expect(result).not.toMatch(/[┌│└]/);
+      expect(result).toContain('Files changed:\n  src/auth.ts');
+      expect(result).toContain('Excluded files:\n  src/legacy.ts');
+      expect(result).toContain(
+        'Add these redirect URIs to your WorkOS app:\n  http://localhost:3000/callback (not registered yet)',
+      );
+    });
+
+    it('renders markdown for a PR description', () => {
+      const result = formatRunSummary(summary, 'markdown');
+      expect(result.startsWith('## Migrated from Auth0 to WorkOS AuthKit')).toBe(true);
+      expect(result).toContain('### Files changed\n\n- `src/auth.ts`');
+      expect(result).toContain('### Excluded from the migration\n\n- `src/legacy.ts`');
+      expect(result).toContain('- [ ] rules/enrich.js:4 Auth0 Rule has no AuthKit equivalent');
+      expect(result).toContain(
+        '### Add these redirect URIs to your WorkOS app\n\n- [ ] `http://localhost:3000/callback` (not registered yet)',
+      );
+      expect(result).toContain('[AuthKit docs](https://workos.com/docs/authkit)');
+    });
+
+    it('maps scopes and claims, flagging scopes that need setup', () => {
+      const withScopes = buildRunSummary({
+        success: true,
+        migration: {
+          ...migration,
+          scopes: [
+            { scope: 'openid', support: 'supported', file: 'app.yml', line: 3 },
+            { scope: 'invoices:read', support: 'roles', note: 'Model it as a permission', file: 'app.yml', line: 3 },
+          ],
+          claims: [{ claim: 'email', authkit: 'user.email', file: 'Profile.java', line: 12 }],
+        },
+      });
+
+      expect(strip(formatRunSummary(withScopes))).toContain('Set up scopes in AuthKit: invoices:read');
+      expect(formatRunSummary(withScopes, 'markdown')).toContain(
+        '### Scopes and claims in AuthKit\n\n- scope openid: supported by AuthKit\n' +
+          '- scope invoices:read: Model it as a permission\n- claim email → user.email',
+      );
+    });
+
+    it('shows the evidence behind the detected provider', () => {
+      const detected = buildRunSummary({
+        success: true,
+        migration: {
+          ...migration,
+          evidence: [{ file: 'src/auth.ts', line: 3, message: 'Auth0 SDK import', confidence: 0.9 }],
+        },
+      });
+
+      expect(detected.detection).toEqual({
+        confidence: 0.9,
+        forced: false,
+        evidence: [{ file: 'src/auth.ts', line: 3, message: 'Auth0 SDK import', confidence: 0.9 }],
+      });
+      expect(formatRunSummary(detected, 'markdown')).toContain(
+        '### Why Auth0 was detected (90% confidence)\n\n- src/auth.ts:3 Auth0 SDK import',
+      );
+      expect(buildRunSummary({ success: true }).detection).toBeUndefined();
+    });
+
+    it('leaves out empty markdown sections', () => {
+      const result = formatRunSummary(buildRunSummary({ success: true }), 'markdown');
+      expect(result).not.toContain('### Files changed');
+      expect(result).toContain('### Next steps');
+    });
+
+    it('reports agent usage, or that it was unavailable', () => {
+      const usage = {
+        tokens: { input: 1200, output: 300, cacheRead: 0, cacheWrite: 0 },
+        costUsd: 0.25,
+        costEstimated: false,
+        durationMs: 90_000,
+        agentRuns: 1,
+        repairAttempts: 0,
+      };
+      const withUsage = buildRunSummary({ success: true, usage });
+      expect(formatRunSummary(withUsage, 'plain')).toContain('Agent: 1.2k tokens in, 300 out, $0.25, 1m 30s');
+      expect(JSON.parse(formatRunSummary(withUsage, 'json')).usage).toEqual(usage);
+
+      const unavailable = buildRunSummary({ success: true, usage: { ...usage, tokens: null, costUsd: null } });
+      expect(strip(formatRunSummary(unavailable))).toContain('Agent: usage unavailable, 1m 30s');
+    });
+
+    it('renders the model as JSON', () => {
+      expect(JSON.parse(formatRunSummary(summary, 'json'))).toEqual({ schemaVersion: 1, ...summary });
+    });
+  });
+
+  describe('quiet output', () => {
+    it('reduces the summary to one line and the warnings that affect the result', () => {
+      const summary = buildRunSummary({ success: true, changedFiles: ['src/auth.ts', 'middleware.ts'], migration });
+      expect(formatResultLine(summary)).toBe('Migrated from Auth0 to WorkOS AuthKit: 2 files changed');
+      expect(summaryWarnings(summary)).toEqual([
+        'Finish manually: rules/enrich.js:4 Auth0 Rule has no AuthKit equivalent',
+        'Add redirect URI http://localhost:3000/callback to your WorkOS app',
+      ]);
+
+      const failed = buildRunSummary({ success: false, summary: 'Build failed\n\nsrc/auth.ts:3 error' });
+      expect(formatResultLine(failed)).toBe('Installation Failed: Build failed');
+    });
+  });
+});
@@ -0,0 +1,358 @@
+import chalk from 'chalk';
+import { formatUsage, type RunUsage } from '../lib/agent-usage.js';
+import { OUTPUT_SCHEMA_VERSION } from '../lib/output-schema.js';
+import type { VerificationCheckResult } from '../lib/validation/verification.js';
+import type { DashboardChange } from '../lib/workos-management.js';
+import { auth0ActionsWarning } from '../migrate/detectors/auth0-actions.js';
+import { logoutWarning } from '../migrate/logout.js';
+import { oauthStateWarning } from '../migrate/oauth-state.js';
+import { deferredCategories, formatCategories, inScope } from '../migrate/scope.js';
+import { scopeClaimLines, scopesNeedingSetup } from '../migrate/scopes-claims.js';
+import { evidenceLines } from '../migrate/evidence.js';
+import { templateReferenceLines } from '../migrate/template-auth.js';
+import { terraformChecklist } from '../migrate/terraform.js';
+import { testReferenceLines } from '../migrate/test-references.js';
+import type {
+  ClaimMapping,
+  DetectionEvidence,
+  MigrationContext,
+  RedirectUri,
+  ScopeMapping,
+} from '../migrate/types.js';
+import { renderSummaryBox, type SummaryBoxItem } from './summary-box.js';
+import { redactSecrets } from './redact.js';
+import type { FileChanges } from './git-utils.js';
+
+export const SUMMARY_FORMATS = ['table', 'plain', 'markdown', 'json'] as const;
+export type SummaryFormat = (typeof SUMMARY_FORMATS)[number];
+
+/**
+ * Outcome of an install or migration run. Every summary format renders
+ * from this, so the box, the PR-ready markdown and the JSON agree.
+ */
+export interface RunSummary {
+  success: boolean;
+  title: string;
+  /** Closing message from the agent, or why the run failed */
+  message?: string;
+  /** Display name of the provider migrated from */
+  migratedFrom?: string;
+  /** How the provider was picked and the strongest findings behind it; unset for installs */
+  detection?: { confidence: number; forced: boolean; evidence: DetectionEvidence[] };
+  /** Paths relative to the project root */
+  changedFiles: string[];
+  /** Files the user left out of the migration */
+  excludedFiles: string[];
+  /** Places with no mechanical replacement that someone has to rewrite */
+  manualChanges: string[];
+  /** Callback URLs the WorkOS app needs, from the old provider's configuration */
+  redirectUris: Array<Pick<RedirectUri, 'uri' | 'registered'>>;
+  /** Scopes the old login requested and how AuthKit covers each */
+  scopes: Array<Pick<ScopeMapping, 'scope' | 'support' | 'note'>>;
+  /** Claims the code read and where AuthKit provides them */
+  claims: Array<Pick<ClaimMapping, 'claim' | 'authkit' | 'note'>>;
+  /** Test files that still mock or reference the old provider, with what they fake */
+  testReferences: string[];
+  /** Template login and logout links and sign-in conditionals in files the run left unchanged */
+  templateReferences: string[];
+  /** Things the migration can't carry over, e.g. logic that runs in the old provider */
+  warnings: string[];
+  /** Categories of change `--only` left for a later run */
+  deferred?: string[];
+  nextSteps: string[];
+  docsUrl: string;
+  /** Tokens, cost and time of the agent runs; unset when no agent ran */
+  usage?: RunUsage;
+  /** The rest is only known to the run report */
+  files?: FileChanges;
+  /** Keys added to each env file; values are never included */
+  envVars?: EnvVarChange[];
+  dashboard?: DashboardChange[];
+  /** Latest result of each verification check */
+  checks?: VerificationCheckResult[];
+}
+
+export interface EnvVarChange {
+  file: string;
+  keys: string[];
+}
+
+export interface RunSummaryInput {
+  success: boolean;
+  summary?: string;
+  changedFiles?: string[];
+  migration?: MigrationContext;
+  usage?: RunUsage;
+  files?: FileChanges;
+  envVars?: EnvVarChange[];
+  dashboard?: DashboardChange[];
+  checks?: VerificationCheckResult[];
+}
+
+const NEXT_STEPS = ['Start dev server to test authentication', 'Visit WorkOS Dashboard to manage users'];
+
+const REDIRECT_URIS_HEADING = 'Add these redirect URIs to your WorkOS app';
+const SCOPES_CLAIMS_HEADING = 'Scopes and claims in AuthKit';
+const TEST_REFERENCES_HEADING = 'Tests referencing the old provider — update these';
+const TEMPLATE_REFERENCES_HEADING = 'Templates still pointing at the old sign-in — check these';
+
+function detectionHeading(summary: RunSummary): string {
+  const { confidence, forced } = summary.detection!;
+  const score = `${Math.round(confidence * 100)}% confidence`;
+  return forced
+    ? `${summary.migratedFrom} evidence (chosen by hand, ${score})`
+    : `Why ${summary.migratedFrom} was detected (${score})`;
+}
+
+function redirectUriStatus({ registered }: Pick<RedirectUri, 'registered'>): string {
+  if (registered === undefined) return '';
+  return registered ? ' (already registered)' : ' (not registered yet)';
+}
+
+export function buildRunSummary({
+  success,
+  summary,
+  changedFiles = [],
+  migration,
+  usage,
+  ...report
+}: RunSummaryInput): RunSummary {
+  const excludedFiles = migration?.excludedFiles ?? [];
+  // Findings in categories --only left out are the later run's to report
+  const migrating = (migration?.findings ?? []).filter(
+    (f) => !f.outOfScope && !excludedFiles.includes(f.file) && inScope(f.file, migration!),
+  );
+  const manualChanges = migrating
+    .filter((f) => f.manual)
+    .map((f) => `${f.file}${f.line ? `:${f.line}` : ''} ${f.message}`);
+
+  // A template the run changed was repointed; one it didn't still needs a look
+  const untouchedTemplates = (migration?.templateReferences ?? []).filter(
+    (r) => !changedFiles.includes(r.file) && !excludedFiles.includes(r.file) && inScope(r.file, migration!),
+  );
+  const deferred = deferredCategories(migration?.only, migration?.completedCategories);
+  const nextSteps = success ? [...NEXT_STEPS] : [];
+  const terraformItems = terraformChecklist(migration?.terraformClients ?? []);
+  if (success && terraformItems.length > 0) {
+    nextSteps.unshift(
+      `Set these from the Terraform-managed Auth0 app in the WorkOS dashboard: ${terraformItems.join('; ')}`,
+    );
+  }
+  if (success && deferred.length > 0) {
+    nextSteps.unshift(`Run \`workos migrate\` again to apply the rest: ${formatCategories(deferred)}`);
+  }
+
+  let title: string;
+  if (migration) {
+    const part = migration.only ? ` (${formatCategories(migration.only)})` : '';
+    title = success ? `Migrated from ${migration.displayName} to WorkOS AuthKit${part}` : 'Migration Failed';
+  } else {
+    title = success ? 'WorkOS AuthKit Installed' : 'Installation Failed';
+  }
+
+  return {
+    success,
+    title,
+    message: summary ? redactSecrets(summary) : undefined,
+    migratedFrom: migration?.displayName,
+    ...(migration && {
+      detection: {
+        confidence: migration.confidence,
+        forced: migration.forced,
+        // Evidence quotes the project's code, which can hold a hardcoded secret
+        evidence: (migration.evidence ?? []).map((e) => ({ ...e, message: redactSecrets(e.message) })),
+      },
+    }),
+    changedFiles,
+    excludedFiles,
+    manualChanges,
+    redirectUris: (migration?.redirectUris ?? []).map(({ uri, registered }) => ({ uri, registered })),
+    scopes: (migration?.scopes ?? []).map(({ scope, support, note }) => ({ scope, support, note })),
+    claims: (migration?.claims ?? []).map(({ claim, authkit, note }) => ({ claim, authkit, note })),
+    testReferences: testReferenceLines(migration?.testReferences ?? []),
+    templateReferences: templateReferenceLines(untouchedTemplates),
+    warnings: [auth0ActionsWarning(migrating), logoutWarning(migrating), oauthStateWarning(migrating)].filter(
+      (w): w is string => w !== null,
+    ),
+    ...(deferred.length > 0 && { deferred }),
+    nextSteps,
+    docsUrl: success ? 'https://workos.com/docs/authkit' : 'https://github.com/workos/cli/issues',
+    usage,
+    ...report,
+  };
+}
+
+function formatEnvVars({ file, keys }: EnvVarChange, code: (text: string) => string): string {
+  return `${code(file)}: ${keys.map(code).join(', ')}`;
+}
+
+function formatDashboardChange({ setting, value, created }: DashboardChange, code: (text: string) => string): string {
+  return `${setting} ${code(value)}${created ? '' : ' (already set)'}`;
+}
+
+function formatCheck(check: VerificationCheckResult): string {
+  const firstLine = check.output?.split('\n').find((line) => line.trim())?.trim();
+  const detail = firstLine && redactSecrets(firstLine);
+  if (check.skipped) return `${check.name}: skipped${detail ? ` (${detail})` : ''}`;
+  const duration = `${(check.durationMs / 1000).toFixed(1)}s`;
+  return check.passed ? `${check.name}: passed (${duration})` : `${check.name}: failed${detail ? `, ${detail}` : ''}`;
+}
+
+function renderTable(summary: RunSummary): string {
+  const items: SummaryBoxItem[] = [];
+  if (!summary.success) {
+    if (summary.message) items.push({ type: 'error', text: summary.message });
+  } else {
+    const count = summary.changedFiles.length;
+    if (count > 0) items.push({ type: 'done', text: `${count} ${count === 1 ? 'file' : 'files'} changed` });
+    const manual = summary.manualChanges.length;
+    if (manual > 0) {
+      items.push({ type: 'pending', text: `Finish ${manual} manual ${manual === 1 ? 'change' : 'changes'}` });
+    }
+    for (const { uri, registered } of summary.redirectUris) {
+      items.push(
+        registered
+          ? { type: 'done', text: `Redirect URI ${uri} registered` }
+          : { type: 'pending', text: `Add redirect URI ${uri} to your WorkOS app` },
+      );
+    }
+    const setup = scopesNeedingSetup(summary.scopes);
+    if (setup.length > 0) {
+      items.push({ type: 'pending', text: `Set up scopes in AuthKit: ${setup.map((s) => s.scope).join(', ')}` });
+    }
+    const tests = summary.testReferences.length;
+    if (tests > 0) {
+      const name = summary.migratedFrom ?? 'the old provider';
+      const noun = tests === 1 ? 'test' : 'tests';
+      items.push({ type: 'pending', text: `Update ${tests} ${noun} still referencing ${name}` });
+    }
+    const templates = summary.templateReferences.length;
+    if (templates > 0) {
+      const noun = templates === 1 ? 'template reference' : 'template references';
+      items.push({ type: 'pending', text: `Check ${templates} ${noun} to the old sign-in` });
+    }
+    items.push(...summary.warnings.map((text) => ({ type: 'pending' as const, text })));
+    items.push(...summary.nextSteps.map((text) => ({ type: 'pending' as const, text })));
+  }
+  const box = renderSummaryBox({
+    expression: summary.success ? 'success' : 'error',
+    title: summary.title,
+    items,
+    footer: summary.docsUrl,
+  });
+  return summary.usage ? `${box}\n${chalk.dim(`  Agent: ${formatUsage(summary.usage)}`)}` : box;
+}
+
+function renderPlain(summary: RunSummary): string {
+  const lines = [summary.title];
+  if (summary.message) lines.push('', summary.message);
+  const section = (heading: string, entries: string[]) => {
+    if (entries.length > 0) lines.push('', `${heading}:`, ...entries.map((e) => `  ${e}`));
+  };
+  if (summary.files) {
+    section('Files created', summary.files.created);
+    section('Files modified', summary.files.modified);
+    section('Files deleted', summary.files.deleted);
+  } else {
+    section('Files changed', summary.changedFiles);
+  }
+  section('Excluded files', summary.excludedFiles);
+  if (summary.detection) section(detectionHeading(summary), evidenceLines(summary.detection.evidence));
+  section('Environment variables added', (summary.envVars ?? []).map((e) => formatEnvVars(e, (t) => t)));
+  section('WorkOS dashboard', (summary.dashboard ?? []).map((d) => formatDashboardChange(d, (t) => t)));
+  section('Verification checks', (summary.checks ?? []).map(formatCheck));
+  section('Manual changes', summary.manualChanges);
+  section(REDIRECT_URIS_HEADING, summary.redirectUris.map((entry) => `${entry.uri}${redirectUriStatus(entry)}`));
+  section(SCOPES_CLAIMS_HEADING, scopeClaimLines(summary.scopes, summary.claims));
+  section(TEST_REFERENCES_HEADING, summary.testReferences);
+  section(TEMPLATE_REFERENCES_HEADING, summary.templateReferences);
+  section('Warnings', summary.warnings);
+  section('Next steps', summary.nextSteps);
+  if (summary.usage) lines.push('', `Agent: ${formatUsage(summary.usage)}`);
+  lines.push('', summary.docsUrl);
+  return lines.join('\n');
+}
+
+function renderMarkdown(summary: RunSummary): string {
+  const lines = [`## ${summary.title}`];
+  if (summary.message) lines.push('', summary.message);
+  const section = (heading: string, entries: string[]) => {
+    if (entries.length > 0) lines.push('', `### ${heading}`, '', ...entries);
+  };
+  const fileList = (files: string[]) => files.map((f) => `- \`${f}\``);
+  if (summary.files) {
+    section('Files created', fileList(summary.files.created));
+    section('Files modified', fileList(summary.files.modified));
+    section('Files deleted', fileList(summary.files.deleted));
+  } else {
+    section('Files changed', fileList(summary.changedFiles));
+  }
+  section('Excluded from the migration', fileList(summary.excludedFiles));
+  if (summary.detection) {
+    section(detectionHeading(summary), evidenceLines(summary.detection.evidence).map((line) => `- ${line}`));
+  }
+  const code = (text: string) => `\`${text}\``;
+  section('Environment variables added', (summary.envVars ?? []).map((e) => `- ${formatEnvVars(e, code)}`));
+  section('WorkOS dashboard', (summary.dashboard ?? []).map((d) => `- ${formatDashboardChange(d, code)}`));
+  section(
+    'Verification checks',
+    (summary.checks ?? []).map((c) => `- [${c.passed && !c.skipped ? 'x' : ' '}] ${formatCheck(c)}`),
+  );
+  section('Manual changes', summary.manualChanges.map((c) => `- [ ] ${c}`));
+  section(
+    REDIRECT_URIS_HEADING,
+    summary.redirectUris.map((entry) => {
+      const box = entry.registered ? 'x' : ' ';
+      return `- [${box}] \`${entry.uri}\`${redirectUriStatus(entry)}`;
+    }),
+  );
+  section(SCOPES_CLAIMS_HEADING, scopeClaimLines(summary.scopes, summary.claims).map((line) => `- ${line}`));
+  section(TEST_REFERENCES_HEADING, summary.testReferences.map((line) => `- [ ] ${line}`));
+  section(TEMPLATE_REFERENCES_HEADING, summary.templateReferences.map((line) => `- [ ] ${line}`));
+  section('Warnings', summary.warnings.map((w) => `- ${w}`));
+  section('Next steps', summary.nextSteps.map((s) => `- [ ] ${s}`));
+  if (summary.usage) lines.push('', `<sub>Agent: ${formatUsage(summary.usage)}</sub>`);
+  lines.push('', summary.success ? `[AuthKit docs](${summary.docsUrl})` : `[Report an issue](${summary.docsUrl})`);
+  return lines.join('\n');
+}
+
+/**
+ * What still needs doing for the result to be correct: manual changes,
+ * redirect URIs and scopes to set up, and migration warnings. Quiet mode
+ * prints these even though it drops the rest of the summary.
+ */
+export function summaryWarnings(summary: RunSummary): string[] {
+  const manual = summary.manualChanges.map((change) => `Finish manually: ${change}`);
+  const redirects = summary.redirectUris
+    .filter((entry) => !entry.registered)
+    .map(({ uri }) => `Add redirect URI ${uri} to your WorkOS app`);
+  const setup = scopesNeedingSetup(summary.scopes);
+  const scopes = setup.length > 0 ? [`Set up scopes in AuthKit: ${setup.map((s) => s.scope).join(', ')}`] : [];
+  const tests = summary.testReferences.map((line) => `Update test: ${line}`);
+  const templates = summary.templateReferences.map((line) => `Check template: ${line}`);
+  return [...manual, ...redirects, ...scopes, ...tests, ...templates, ...summary.warnings];
+}
+
+/** The summary as one line, e.g. "WorkOS AuthKit Installed: 4 files changed", for quiet mode */
+export function formatResultLine(summary: RunSummary): string {
+  if (!summary.success) {
+    const reason = summary.message?.split('\n').find((line) => line.trim());
+    return reason ? `${summary.title}: ${reason.trim()}` : summary.title;
+  }
+  const count = summary.changedFiles.length;
+  return `${summary.title}: ${count} ${count === 1 ? 'file' : 'files'} changed`;
+}
+
+/** Render a run summary; `table` is the boxed summary shown in the terminal */
+export function formatRunSummary(summary: RunSummary, format: SummaryFormat = 'table'): string {
+  switch (format) {
+    case 'plain':
+      return renderPlain(summary);
+    case 'markdown':
+      return renderMarkdown(summary);
+    case 'json':
+      return JSON.stringify({ schemaVersion: OUTPUT_SCHEMA_VERSION, ...summary }, null, 2);
+    default:
+      return renderTable(summary);
+  }
+}
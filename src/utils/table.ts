@@ -26,3 +26,23 @@ export function formatTable(columns: TableColumn[], rows: string[][]): string {
 
   return lines.join('\n');
 }
+
+/**
+ * Print a table in chunks as rows arrive (e.g. one API page at a time).
+ * Column widths are fixed by the first chunk and the header is printed once.
+ */
+export function createTableStream(columns: TableColumn[], write: (text: string) => void = console.log) {
+  let fixed: TableColumn[] | undefined;
+  return (rows: string[][]): void => {
+    if (rows.length === 0) return;
+    if (fixed) {
+      write(formatTable(fixed, rows).split('\n').slice(2).join('\n'));
+      return;
+    }
+    fixed = columns.map((col, i) => ({
+      ...col,
+      width: col.width ?? Math.max(col.header.length, ...rows.map((row) => (row[i] || '').length)),
+    }));
+    write(formatTable(fixed, rows));
+  };
+}
@@ -1,4 +1,7 @@
 import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { existsSync, mkdtempSync, readFileSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
 import type { TelemetryEvent } from './telemetry-types.js';
 
 // Mock fetch globally
@@ -21,9 +24,11 @@ const { TelemetryClient } = await import('./telemetry-client.js');
 
 describe('TelemetryClient', () => {
   let client: InstanceType<typeof TelemetryClient>;
+  let testDir: string;
 
   beforeEach(() => {
-    client = new TelemetryClient();
+    testDir = mkdtempSync(join(tmpdir(), 'telemetry-client-test-'));
+    client = new TelemetryClient(join(testDir, 'telemetry.log'), join(testDir, 'spool.jsonl'));
     mockFetch.mockReset();
     mockFetch.mockResolvedValue({ ok: true });
     mockGetCredentials.mockReset();
@@ -32,6 +37,7 @@ describe('TelemetryClient', () => {
 
   afterEach(() => {
     vi.clearAllMocks();
+    rmSync(testDir, { recursive: true, force: true });
   });
 
   describe('setGatewayUrl', () => {
@@ -182,5 +188,75 @@ describe('TelemetryClient', () => {
         }),
       );
     });
+
+    it('appends what it sends to the local telemetry log', async () => {
+      client.setGatewayUrl('http://localhost:8000');
+      client.setAccessToken('secret-token');
+      client.queueEvent({ type: 'session.start', sessionId: '123', timestamp: '2026-01-01T00:00:00.000Z' });
+      await client.flush();
+      client.queueEvent({ type: 'session.end', sessionId: '123', timestamp: '2026-01-01T00:01:00.000Z' });
+      await client.flush();
+
+      const log = readFileSync(join(testDir, 'telemetry.log'), 'utf-8');
+      const lines = log.trim().split('\n').map((line) => JSON.parse(line));
+      expect(lines).toHaveLength(2);
+      expect(lines[0]).toMatchObject({
+        url: 'http://localhost:8000/telemetry',
+        events: [{ type: 'session.start', sessionId: '123' }],
+      });
+      expect(JSON.parse(mockFetch.mock.calls[0][1].body)).toEqual({ events: lines[0].events });
+      expect(log).not.toContain('secret-token');
+    });
+  });
+
+  describe('queueEvent sanitizing', () => {
+    it('drops identifying attributes before anything is sent', async () => {
+      client.setGatewayUrl('http://localhost:8000');
+      client.queueEvent({
+        type: 'session.start',
+        sessionId: '123',
+        timestamp: '2026-01-01T00:00:00.000Z',
+        attributes: { 'installer.mode': 'cli', 'workos.user_id': 'user_01HXYZABCDEFGHJKMNPQRSTVWX', dir: '/repo' },
+      });
+
+      await client.flush();
+
+      const body = JSON.parse(mockFetch.mock.calls[0][1].body);
+      expect(body.events[0].attributes).toEqual({ 'installer.mode': 'cli' });
+    });
+  });
+
+  describe('spool', () => {
+    it('writes queued events to the spool without sending them', () => {
+      client.setGatewayUrl('http://localhost:8000');
+      client.queueEvent({ type: 'session.end', sessionId: '123', timestamp: '2026-01-01T00:01:00.000Z' });
+
+      client.spool();
+
+      expect(mockFetch).not.toHaveBeenCalled();
+      const [line] = readFileSync(join(testDir, 'spool.jsonl'), 'utf-8').trim().split('\n');
+      expect(JSON.parse(line)).toEqual({
+        gatewayUrl: 'http://localhost:8000',
+        events: [{ type: 'session.end', sessionId: '123', timestamp: '2026-01-01T00:01:00.000Z' }],
+      });
+    });
+
+    it('skips if no gateway URL configured', () => {
+      client.queueEvent({ type: 'session.end', sessionId: '123', timestamp: new Date().toISOString() });
+
+      client.spool();
+
+      expect(existsSync(join(testDir, 'spool.jsonl'))).toBe(false);
+    });
+  });
+
+  describe('send', () => {
+    it('resolves false when the batch did not get through', async () => {
+      mockFetch.mockRejectedValueOnce(new Error('Network error'));
+      await expect(client.send('http://localhost:8000', [])).resolves.toBe(false);
+
+      mockFetch.mockResolvedValueOnce({ ok: false, status: 503 });
+      await expect(client.send('http://localhost:8000', [])).resolves.toBe(false);
+    });
   });
 });
@@ -1,16 +1,32 @@
+import { appendFileSync, mkdirSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
 import { debug } from './debug.js';
 import type { TelemetryEvent, TelemetryRequest } from './telemetry-types.js';
+import { sanitizeEvent } from './telemetry-sanitize.js';
+import { appendToSpool, TELEMETRY_SPOOL_PATH } from './telemetry-spool.js';
 import { getCredentials } from '../lib/credentials.js';
+import { proxiedFetch } from '../lib/proxy.js';
+
+/** Every payload sent is also appended here, one JSON line per request, so it can be audited */
+export const TELEMETRY_LOG_PATH = join(homedir(), '.workos', 'telemetry.log');
 
 /**
- * HTTP client that queues telemetry events and flushes them to the gateway.
- * Failures are silent—telemetry should never crash the wizard.
+ * HTTP client that queues telemetry events and flushes them to the gateway,
+ * or spools them for the background sender (see telemetry-spool.ts).
+ * Events are sanitized as they are queued. Failures are silent—telemetry
+ * should never crash the wizard.
  */
 export class TelemetryClient {
   private events: TelemetryEvent[] = [];
   private accessToken: string | null = null;
   private gatewayUrl: string | null = null;
 
+  constructor(
+    private logPath: string | null = TELEMETRY_LOG_PATH,
+    private spoolPath: string = TELEMETRY_SPOOL_PATH,
+  ) {}
+
   setGatewayUrl(url: string) {
     this.gatewayUrl = url;
   }
@@ -20,7 +36,18 @@ export class TelemetryClient {
   }
 
   queueEvent(event: TelemetryEvent) {
-    this.events.push(event);
+    this.events.push(sanitizeEvent(event));
+  }
+
+  /** Move the queued events to the spool without touching the network */
+  spool(): void {
+    if (this.events.length === 0) return;
+    if (!this.gatewayUrl) {
+      debug('[Telemetry] No gateway URL configured, skipping spool');
+      return;
+    }
+    appendToSpool({ gatewayUrl: this.gatewayUrl, events: this.events }, this.spoolPath);
+    this.events = [];
   }
 
   async flush(): Promise<void> {
@@ -30,8 +57,14 @@ export class TelemetryClient {
       return;
     }
 
-    const payload: TelemetryRequest = { events: [...this.events] };
+    const events = [...this.events];
     this.events = [];
+    await this.send(this.gatewayUrl, events);
+  }
+
+  /** POST one batch; resolves false instead of throwing when it didn't get through */
+  async send(gatewayUrl: string, events: TelemetryEvent[]): Promise<boolean> {
+    const payload: TelemetryRequest = { events };
 
     const headers: Record<string, string> = {
       'Content-Type': 'application/json',
@@ -43,13 +76,15 @@ export class TelemetryClient {
       headers['Authorization'] = `Bearer ${token}`;
     }
 
+    this.logPayload(`${gatewayUrl}/telemetry`, payload);
+
     const controller = new AbortController();
     const timeout = setTimeout(() => controller.abort(), 3000);
 
     try {
-      debug(`[Telemetry] Sending ${payload.events.length} events to ${this.gatewayUrl}/telemetry`);
+      debug(`[Telemetry] Sending ${payload.events.length} events to ${gatewayUrl}/telemetry`);
 
-      const response = await fetch(`${this.gatewayUrl}/telemetry`, {
+      const response = await proxiedFetch(`${gatewayUrl}/telemetry`, {
         method: 'POST',
         headers,
         body: JSON.stringify(payload),
@@ -59,12 +94,24 @@ export class TelemetryClient {
       if (!response.ok) {
         debug(`[Telemetry] Failed to send: ${response.status}`);
       }
+      return response.ok;
     } catch (error) {
       debug(`[Telemetry] Error sending events: ${error}`);
+      return false;
     } finally {
       clearTimeout(timeout);
     }
   }
+
+  private logPayload(url: string, payload: TelemetryRequest): void {
+    if (!this.logPath) return;
+    try {
+      mkdirSync(dirname(this.logPath), { recursive: true, mode: 0o700 });
+      appendFileSync(this.logPath, `${JSON.stringify({ sentAt: new Date().toISOString(), url, ...payload })}\n`);
+    } catch (error) {
+      debug(`[Telemetry] Could not write ${this.logPath}: ${error}`);
+    }
+  }
 }
 
 export const telemetryClient = new TelemetryClient();
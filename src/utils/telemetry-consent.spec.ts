@@ -0,0 +1,73 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+
+describe('telemetry consent', () => {
+  let consent: typeof import('./telemetry-consent.js');
+
+  beforeEach(async () => {
+    vi.resetModules();
+    vi.stubEnv('DO_NOT_TRACK', '');
+    vi.stubEnv('WORKOS_TELEMETRY', '');
+    vi.stubEnv('WORKOS_CLI_TELEMETRY', '');
+    consent = await import('./telemetry-consent.js');
+  });
+
+  afterEach(() => {
+    vi.unstubAllEnvs();
+  });
+
+  it('is on by default', () => {
+    expect(consent.isTelemetryEnabled()).toBe(true);
+    expect(consent.telemetrySource()).toBe('default');
+  });
+
+  it('follows the saved choice', () => {
+    consent.setSavedTelemetryChoice(false);
+
+    expect(consent.isTelemetryEnabled()).toBe(false);
+    expect(consent.telemetrySource()).toBe('config');
+  });
+
+  it.each(['off', 'false', '0', 'OFF'])('turns off with WORKOS_TELEMETRY=%s', (value) => {
+    vi.stubEnv('WORKOS_TELEMETRY', value);
+
+    expect(consent.isTelemetryEnabled()).toBe(false);
+  });
+
+  it('turns off with WORKOS_CLI_TELEMETRY=0, over an older WORKOS_TELEMETRY=on', () => {
+    vi.stubEnv('WORKOS_CLI_TELEMETRY', '0');
+    vi.stubEnv('WORKOS_TELEMETRY', 'on');
+
+    expect(consent.isTelemetryEnabled()).toBe(false);
+    expect(consent.telemetrySource()).toBe('env');
+  });
+
+  it('lets WORKOS_TELEMETRY=on override a saved opt-out', () => {
+    consent.setSavedTelemetryChoice(false);
+    vi.stubEnv('WORKOS_TELEMETRY', 'on');
+
+    expect(consent.isTelemetryEnabled()).toBe(true);
+    expect(consent.telemetrySource()).toBe('env');
+  });
+
+  it('honors DO_NOT_TRACK over everything but the flag', () => {
+    consent.setSavedTelemetryChoice(true);
+    vi.stubEnv('WORKOS_TELEMETRY', 'on');
+    vi.stubEnv('DO_NOT_TRACK', '1');
+
+    expect(consent.isTelemetryEnabled()).toBe(false);
+  });
+
+  it('ignores DO_NOT_TRACK=0', () => {
+    vi.stubEnv('DO_NOT_TRACK', '0');
+
+    expect(consent.isTelemetryEnabled()).toBe(true);
+  });
+
+  it('is off for the run with --no-telemetry', () => {
+    vi.stubEnv('WORKOS_TELEMETRY', 'on');
+    consent.disableTelemetryForRun();
+
+    expect(consent.isTelemetryEnabled()).toBe(false);
+    expect(consent.telemetrySource()).toBe('flag');
+  });
+});
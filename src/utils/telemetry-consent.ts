@@ -0,0 +1,48 @@
+/**
+ * Whether usage telemetry may be sent. The strongest opt-out wins:
+ * `--no-telemetry`, then DO_NOT_TRACK, then WORKOS_CLI_TELEMETRY (or the
+ * older WORKOS_TELEMETRY), then the choice saved in the CLI config. With
+ * none of them set, telemetry is on.
+ */
+
+let flagOptOut = false;
+let savedChoice: boolean | undefined;
+
+const OFF_VALUES = ['off', 'false', '0', 'no'];
+const ON_VALUES = ['on', 'true', '1', 'yes'];
+
+/** `--no-telemetry` for this run */
+export function disableTelemetryForRun(): void {
+  flagOptOut = true;
+}
+
+/** The choice from the config file, loaded at startup */
+export function setSavedTelemetryChoice(enabled: boolean | undefined): void {
+  savedChoice = enabled;
+}
+
+/**
+ * The environment's say, if any. DO_NOT_TRACK (https://consoledonottrack.com)
+ * can only opt out; WORKOS_CLI_TELEMETRY can also opt back in over a saved "off".
+ */
+export function envTelemetrySetting(env: NodeJS.ProcessEnv = process.env): boolean | undefined {
+  const doNotTrack = env.DO_NOT_TRACK?.trim().toLowerCase();
+  if (doNotTrack && !OFF_VALUES.includes(doNotTrack)) return false;
+
+  const value = (env.WORKOS_CLI_TELEMETRY || env.WORKOS_TELEMETRY)?.trim().toLowerCase();
+  if (value && OFF_VALUES.includes(value)) return false;
+  if (value && ON_VALUES.includes(value)) return true;
+  return undefined;
+}
+
+export function isTelemetryEnabled(): boolean {
+  if (flagOptOut) return false;
+  return envTelemetrySetting() ?? savedChoice ?? true;
+}
+
+/** Why telemetry is on or off, for `workos telemetry status` */
+export function telemetrySource(): 'flag' | 'env' | 'config' | 'default' {
+  if (flagOptOut) return 'flag';
+  if (envTelemetrySetting() !== undefined) return 'env';
+  return savedChoice === undefined ? 'default' : 'config';
+}
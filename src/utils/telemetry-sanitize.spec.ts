@@ -0,0 +1,72 @@
+import { describe, it, expect } from 'vitest';
+import { sanitizeEvent } from './telemetry-sanitize.js';
+import type { StepEvent, TelemetryEvent } from './telemetry-types.js';
+
+describe('sanitizeEvent', () => {
+  const base = { sessionId: '0b6f7c9e-2d7a-4f0e-9a51-6a3f1b2c4d5e', timestamp: '2026-01-01T00:00:00.000Z' };
+
+  it('keeps plain tokens, numbers and booleans', () => {
+    const event: TelemetryEvent = {
+      type: 'session.end',
+      ...base,
+      attributes: { 'installer.outcome': 'success', framework: 'nextjs', 'installer.duration_ms': 1200, hasAuth: true },
+    };
+
+    expect(sanitizeEvent(event)).toEqual(event);
+  });
+
+  it('drops attributes named like identifiers, paths or free text', () => {
+    const event = sanitizeEvent({
+      type: 'session.start',
+      ...base,
+      attributes: {
+        'installer.version': '1.2.3',
+        'workos.user_id': 'user-123',
+        'error.message': 'boom',
+        cwd: 'app',
+        'api-key': 'abc',
+      },
+    });
+
+    expect(event.attributes).toEqual({ 'installer.version': '1.2.3' });
+  });
+
+  it('drops values that look like paths, emails, URLs or WorkOS IDs', () => {
+    const event = sanitizeEvent({
+      type: 'session.end',
+      ...base,
+      attributes: {
+        a: '/Users/jane/acme',
+        b: 'C:\\code\\acme',
+        c: 'jane@example.com',
+        d: 'https://acme.test',
+        e: 'org_01HXYZABCDEFGHJKMNPQRSTVWX',
+        f: 'sk_test_abc',
+        g: 'two words',
+        kept: 'tui',
+      },
+    });
+
+    expect(event.attributes).toEqual({ kept: 'tui' });
+  });
+
+  it('keeps only the class of an error', () => {
+    const step: StepEvent = {
+      type: 'step',
+      ...base,
+      name: 'detect_framework',
+      durationMs: 50,
+      success: false,
+      error: { type: 'TypeError', message: 'Cannot read /Users/jane/acme/package.json' },
+    };
+
+    expect(sanitizeEvent(step).error).toEqual({ type: 'TypeError' });
+    expect(JSON.stringify(sanitizeEvent(step))).not.toContain('jane');
+  });
+
+  it('drops fields it does not know', () => {
+    const event = sanitizeEvent({ type: 'command', ...base, cwd: '/repo' } as TelemetryEvent);
+
+    expect(event).toEqual({ type: 'command', ...base });
+  });
+});
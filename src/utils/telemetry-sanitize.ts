@@ -0,0 +1,90 @@
+/**
+ * What may leave the machine in a telemetry event. Every event passes
+ * through here before it is queued, so a careless `analytics.capture()`
+ * can't send a repo path, an email or a WorkOS ID: only known fields are
+ * kept, error messages are dropped (their class stays), attributes named
+ * like identifiers are dropped, and string values must look like a plain
+ * token such as a framework name, a mode or a version.
+ */
+
+import type { TelemetryEvent } from './telemetry-types.js';
+
+/** Fields an event may carry besides its attributes */
+const EVENT_FIELDS = [
+  'type',
+  'sessionId',
+  'timestamp',
+  'name',
+  'durationMs',
+  'success',
+  'toolName',
+  'model',
+  'inputTokens',
+  'outputTokens',
+] as const;
+
+/** Last segment of an attribute name that marks it as identifying or free text */
+const IDENTIFYING_KEYS = new Set([
+  'id',
+  'ids',
+  'email',
+  'name',
+  'path',
+  'dir',
+  'cwd',
+  'file',
+  'url',
+  'host',
+  'message',
+  'stack',
+  'key',
+  'token',
+  'secret',
+  'org',
+  'user',
+]);
+
+/** Framework names, modes, outcomes, versions: no separators a path, URL or email needs */
+const SAFE_VALUE = /^[\w.+-]{1,64}$/;
+
+/** WorkOS IDs (`org_01H…`), API keys and long hex strings */
+const IDENTIFIER_VALUE = /^(?:[a-z]+_[0-9A-Za-z]{16,}|sk_\w+|[0-9a-f]{24,})$/;
+
+function safeKey(key: string): boolean {
+  const last = key.split(/[._-]/).pop()?.toLowerCase() ?? '';
+  return !IDENTIFYING_KEYS.has(last);
+}
+
+export function safeValue(value: unknown): value is string | number | boolean {
+  if (typeof value === 'number') return Number.isFinite(value);
+  if (typeof value === 'boolean') return true;
+  return typeof value === 'string' && SAFE_VALUE.test(value) && !IDENTIFIER_VALUE.test(value);
+}
+
+export function sanitizeAttributes(
+  attributes: Record<string, unknown> | undefined,
+): Record<string, string | number | boolean> | undefined {
+  if (!attributes) return undefined;
+  const kept = Object.entries(attributes).filter(([key, value]) => safeKey(key) && safeValue(value));
+  return Object.fromEntries(kept) as Record<string, string | number | boolean>;
+}
+
+/** A copy of the event with only what telemetry is allowed to send */
+export function sanitizeEvent<T extends TelemetryEvent>(event: T): T {
+  const source = event as unknown as Record<string, unknown>;
+  const sanitized: Record<string, unknown> = {};
+  for (const field of EVENT_FIELDS) {
+    const value = source[field];
+    if (value === undefined) continue;
+    // The step name and model come from the CLI itself, but still have to look like a token
+    if (typeof value === 'string' && field !== 'timestamp' && !SAFE_VALUE.test(value)) continue;
+    sanitized[field] = value;
+  }
+  const error = source.error as { type?: unknown } | undefined;
+  if (error && typeof error.type === 'string' && SAFE_VALUE.test(error.type)) {
+    sanitized.error = { type: error.type };
+  }
+  const attributes = sanitizeAttributes(event.attributes);
+  if (attributes) sanitized.attributes = attributes;
+  return sanitized as unknown as T;
+}
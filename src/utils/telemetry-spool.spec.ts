@@ -0,0 +1,86 @@
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { appendFileSync, existsSync, mkdtempSync, rmSync } from 'node:fs';
+import { join } from 'node:path';
+import { tmpdir } from 'node:os';
+import type { TelemetryEvent } from './telemetry-types.js';
+
+vi.mock('./debug.js', () => ({
+  debug: vi.fn(),
+}));
+
+const { appendToSpool, clearSpool, deliverSpool, readSpool } = await import('./telemetry-spool.js');
+
+describe('telemetry spool', () => {
+  let testDir: string;
+  let spoolPath: string;
+
+  const event = (type: TelemetryEvent['type']): TelemetryEvent => ({
+    type,
+    sessionId: '123',
+    timestamp: '2026-01-01T00:00:00.000Z',
+  });
+
+  beforeEach(() => {
+    testDir = mkdtempSync(join(tmpdir(), 'telemetry-spool-test-'));
+    spoolPath = join(testDir, 'telemetry', 'spool.jsonl');
+  });
+
+  afterEach(() => {
+    rmSync(testDir, { recursive: true, force: true });
+  });
+
+  it('reads back what was appended, one batch per line', () => {
+    appendToSpool({ gatewayUrl: 'http://localhost:8000', events: [event('command')] }, spoolPath);
+    appendToSpool(
+      { gatewayUrl: 'http://localhost:8000', events: [event('session.start'), event('session.end')] },
+      spoolPath,
+    );
+
+    const entries = readSpool(spoolPath);
+    expect(entries).toHaveLength(2);
+    expect(entries[1].events.map((e) => e.type)).toEqual(['session.start', 'session.end']);
+  });
+
+  it('skips lines that do not parse', () => {
+    appendToSpool({ gatewayUrl: 'http://localhost:8000', events: [event('command')] }, spoolPath);
+    appendFileSync(spoolPath, '{"gatewayUrl": "http://loc');
+
+    expect(readSpool(spoolPath)).toHaveLength(1);
+  });
+
+  it('delivers each batch and empties the spool', async () => {
+    appendToSpool({ gatewayUrl: 'http://localhost:8000', events: [event('command')] }, spoolPath);
+    const send = vi.fn().mockResolvedValue(true);
+
+    await deliverSpool(send, spoolPath);
+
+    expect(send).toHaveBeenCalledWith('http://localhost:8000', [event('command')]);
+    expect(readSpool(spoolPath)).toEqual([]);
+  });
+
+  it('keeps batches that could not be sent for the next delivery', async () => {
+    appendToSpool({ gatewayUrl: 'http://a', events: [event('command')] }, spoolPath);
+    appendToSpool({ gatewayUrl: 'http://b', events: [event('session.end')] }, spoolPath);
+    const send = vi.fn(async (url: string) => url === 'http://a');
+
+    await deliverSpool(send, spoolPath);
+
+    expect(readSpool(spoolPath)).toEqual([{ gatewayUrl: 'http://b', events: [event('session.end')] }]);
+  });
+
+  it('does nothing without a spool', async () => {
+    const send = vi.fn();
+
+    await deliverSpool(send, spoolPath);
+
+    expect(send).not.toHaveBeenCalled();
+  });
+
+  it('is removed by clearSpool', () => {
+    appendToSpool({ gatewayUrl: 'http://localhost:8000', events: [event('command')] }, spoolPath);
+
+    clearSpool(spoolPath);
+
+    expect(existsSync(spoolPath)).toBe(false);
+  });
+});
@@ -0,0 +1,124 @@
+/**
+ * Local spool for telemetry (`~/.workos/telemetry/spool.jsonl`).
+ *
+ * Commands never talk to the telemetry endpoint themselves: they append
+ * their events here, synchronously and without network, and start a
+ * detached `workos __telemetry-send` that delivers them after the command
+ * has exited. A dead or slow endpoint can only delay that background
+ * process. Batches it couldn't send stay in the spool for the next run, up
+ * to a size cap past which new events are dropped. `workos telemetry show`
+ * prints the spool as is.
+ */
+
+import { spawn } from 'node:child_process';
+import { appendFileSync, mkdirSync, readFileSync, renameSync, rmSync, statSync } from 'node:fs';
+import { homedir } from 'node:os';
+import { dirname, join } from 'node:path';
+import { debug } from './debug.js';
+import type { TelemetryEvent } from './telemetry-types.js';
+
+export const TELEMETRY_SPOOL_PATH = join(homedir(), '.workos', 'telemetry', 'spool.jsonl');
+
+/** Past this the spool stops growing until a delivery gets through */
+const MAX_SPOOL_BYTES = 512 * 1024;
+
+/** Command the background sender runs as */
+export const TELEMETRY_SEND_COMMAND = '__telemetry-send';
+
+/** One batch waiting to be sent */
+export interface SpoolEntry {
+  gatewayUrl: string;
+  events: TelemetryEvent[];
+}
+
+export function appendToSpool(entry: SpoolEntry, spoolPath = TELEMETRY_SPOOL_PATH): void {
+  if (entry.events.length === 0) return;
+  try {
+    mkdirSync(dirname(spoolPath), { recursive: true, mode: 0o700 });
+    let size = 0;
+    try {
+      size = statSync(spoolPath).size;
+    } catch {
+      // No spool yet
+    }
+    if (size > MAX_SPOOL_BYTES) {
+      debug(`[Telemetry] Spool is over ${MAX_SPOOL_BYTES} bytes, dropping ${entry.events.length} events`);
+      return;
+    }
+    appendFileSync(spoolPath, `${JSON.stringify(entry)}\n`, { mode: 0o600 });
+  } catch (error) {
+    debug(`[Telemetry] Could not write ${spoolPath}: ${error}`);
+  }
+}
+
+/** Batches waiting in the spool; lines that don't parse are skipped */
+export function readSpool(spoolPath = TELEMETRY_SPOOL_PATH): SpoolEntry[] {
+  let raw: string;
+  try {
+    raw = readFileSync(spoolPath, 'utf-8');
+  } catch {
+    return [];
+  }
+  const entries: SpoolEntry[] = [];
+  for (const line of raw.split('\n')) {
+    if (!line.trim()) continue;
+    try {
+      const entry = JSON.parse(line) as SpoolEntry;
+      if (typeof entry?.gatewayUrl === 'string' && Array.isArray(entry.events)) entries.push(entry);
+    } catch {
+      // A line cut short by a crash; nothing to send
+    }
+  }
+  return entries;
+}
+
+/** `workos telemetry off`: pending events are discarded, not sent later */
+export function clearSpool(spoolPath = TELEMETRY_SPOOL_PATH): void {
+  rmSync(spoolPath, { force: true });
+}
+
+/**
+ * Send everything in the spool. The spool is claimed first (renamed aside)
+ * so concurrent senders don't send a batch twice; batches that fail go back.
+ */
+export async function deliverSpool(
+  send: (gatewayUrl: string, events: TelemetryEvent[]) => Promise<boolean>,
+  spoolPath = TELEMETRY_SPOOL_PATH,
+): Promise<void> {
+  const claimed = `${spoolPath}.${process.pid}.sending`;
+  try {
+    renameSync(spoolPath, claimed);
+  } catch {
+    return; // Nothing spooled, or another sender has it
+  }
+  try {
+    for (const entry of readSpool(claimed)) {
+      const sent = await send(entry.gatewayUrl, entry.events);
+      if (!sent) appendToSpool(entry, spoolPath);
+    }
+  } finally {
+    rmSync(claimed, { force: true });
+  }
+}
+
+/** Start the detached sender; the current process doesn't wait for it */
+export function startSpoolDelivery(spoolPath = TELEMETRY_SPOOL_PATH): void {
+  try {
+    statSync(spoolPath);
+  } catch {
+    return;
+  }
+  const script = process.argv[1];
+  if (!script) return;
+  try {
+    const child = spawn(process.execPath, [...process.execArgv, script, TELEMETRY_SEND_COMMAND], {
+      detached: true,
+      stdio: 'ignore',
+      windowsHide: true,
+    });
+    child.on('error', (error) => debug(`[Telemetry] Could not start sender: ${error}`));
+    child.unref();
+  } catch (error) {
+    debug(`[Telemetry] Could not start sender: ${error}`);
+  }
+}
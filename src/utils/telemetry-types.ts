@@ -4,7 +4,7 @@
  */
 
 export interface TelemetryEvent {
-  type: 'session.start' | 'session.end' | 'step' | 'agent.tool' | 'agent.llm';
+  type: 'session.start' | 'session.end' | 'step' | 'agent.tool' | 'agent.llm' | 'command';
   sessionId: string;
   timestamp: string;
   attributes?: Record<string, string | number | boolean>;
@@ -15,8 +15,8 @@ export interface SessionStartEvent extends TelemetryEvent {
   attributes: {
     'installer.version': string;
     'installer.mode': 'cli' | 'tui';
-    'workos.user_id'?: string;
-    'workos.org_id'?: string;
+    'os.platform'?: string;
+    'os.arch'?: string;
   };
 }
 
@@ -35,7 +35,8 @@ export interface StepEvent extends TelemetryEvent {
   success: boolean;
   error?: {
     type: string;
-    message: string;
+    /** Never sent; see telemetry-sanitize.ts */
+    message?: string;
   };
 }
 
@@ -53,6 +54,21 @@ export interface AgentLLMEvent extends TelemetryEvent {
   outputTokens: number;
 }
 
+/** One `workos` invocation: which command, how long, how it ended */
+export interface CommandEvent extends TelemetryEvent {
+  type: 'command';
+  attributes: {
+    'cli.command': string;
+    'cli.version': string;
+    'cli.duration_ms': number;
+    'cli.outcome': 'success' | 'error' | 'cancelled';
+    /** Exit code class, e.g. `detection_empty` or `exit_2` */
+    'cli.error_class'?: string;
+    'os.platform': string;
+    'os.arch': string;
+  };
+}
+
 export interface TelemetryRequest {
   events: TelemetryEvent[];
 }
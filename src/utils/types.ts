@@ -98,6 +98,174 @@ export type InstallerOptions = {
    * Default: 2. Set to 0 to disable retries entirely.
    */
   maxRetries?: number;
+
+  /**
+   * Agent turns to fix failed checks from the skill's verify.json.
+   * Default: 1. Set to 0 to only report failures.
+   */
+  maxRepairAttempts?: number;
+
+  /**
+   * Leave the agent's output as written: no formatters or linters on the
+   * files it touched (typecheck and build still run)
+   */
+  skipChecks?: boolean;
+
+  /**
+   * Don't format the files the agent or installer wrote (`--no-format`); linters still run
+   */
+  noFormat?: boolean;
+
+  /**
+   * Aborted when the user presses Ctrl-C; stops the running agent
+   */
+  abortSignal?: AbortSignal;
+
+  /**
+   * Migrate from another auth provider instead of a fresh install (set by `workos migrate`)
+   */
+  migration?: import('../migrate/types.js').MigrationContext;
+
+  /**
+   * Scaffold a fresh integration even when the project already has one (`--force-reinstall`)
+   */
+  forceReinstall?: boolean;
+
+  /**
+   * AuthKit integration found from an earlier install; the agent updates it instead of adding another
+   */
+  existingIntegration?: import('../lib/existing-integration.js').ExistingIntegration;
+
+  /**
+   * Path to a file of project conventions for the agent (`--instructions`); defaults to .workos/instructions.md
+   */
+  instructions?: string;
+
+  /**
+   * Project conventions appended to the integration prompt, loaded from `instructions`
+   */
+  customInstructions?: import('../lib/custom-instructions.js').CustomInstructions;
+
+  /**
+   * Monorepo root when installDir is a workspace package
+   */
+  workspaceRoot?: string;
+
+  /**
+   * Show a unified diff for each file change the agent proposes (interactive: view, apply or skip per change)
+   */
+  showDiffs?: boolean;
+
+  /**
+   * Record the agent's file changes without writing anything (no env files, commands, commits or API changes)
+   */
+  dryRun?: boolean;
+
+  /**
+   * Write every change to this patch file instead of the working tree (`--diff-only`); implies dryRun
+   */
+  diffOnly?: string;
+
+  /**
+   * Changes recorded for --diff-only, shared by the env writers and the agent's change reviewer
+   */
+  changePlan?: import('../lib/change-preview.js').ChangePlan;
+
+  /**
+   * Keep the original of each edited file as `<file>.workos.bak` (`--keep-backups`)
+   */
+  keepBackups?: boolean;
+
+  /**
+   * Backups made for --keep-backups, shared by the file edits and the agent's write hook
+   */
+  fileBackups?: import('../lib/file-backups.js').FileBackups;
+
+  /**
+   * How to print the end-of-run summary (table, plain, markdown or json)
+   */
+  summaryFormat?: import('./run-summary.js').SummaryFormat;
+
+  /**
+   * Stream installer events to stdout (`--events ndjson`); other output moves to stderr
+   */
+  events?: import('../lib/event-stream.js').EventsFormat;
+
+  /**
+   * Module name on streamed events; defaults to installDir relative to the working directory
+   */
+  eventsModule?: string;
+
+  /**
+   * Speak the installer protocol (`--machine`): messages on stdout, prompt answers on stdin
+   */
+  machine?: boolean;
+
+  /**
+   * Where to write the markdown run report (`--report-path`); defaults to .workos/reports/<command>-<timestamp>.md
+   */
+  reportPath?: string;
+
+  /**
+   * Commit, push and open or update the branch's GitHub PR without asking (`--create-pr`)
+   */
+  createPr?: boolean;
+
+  /**
+   * Commit and push the branch without asking or opening a PR (`migrate --repo --push`)
+   */
+  push?: boolean;
+
+  /**
+   * Commit even when the current branch is protected or the default one
+   * (`--allow-main`, or `workos.allowMain` in git config)
+   */
+  allowMain?: boolean;
+
+  /**
+   * Longest an agent run may take, in ms (`--timeout`); defaults to 30 minutes
+   */
+  timeoutMs?: number;
+
+  /**
+   * Longest the agent may go without sending anything, in ms (`--idle-timeout`); defaults to 10 minutes
+   */
+  idleTimeoutMs?: number;
+
+  /**
+   * Token budget for the agent (`--max-tokens`); the pre-flight estimate warns when it's over
+   */
+  maxTokens?: number;
+
+  /**
+   * Skip confirmations (`--yes`), e.g. for running project hooks
+   */
+  yes?: boolean;
+
+  /**
+   * Confirmed pre/post hooks from .workos/config.json; unset when there are none or they were declined
+   */
+  projectHooks?: import('../lib/project-hooks.js').ProjectHooks;
+
+  /**
+   * Branch and commit templates from .workos/config.json, validated before the run
+   */
+  gitConventions?: import('../lib/git-conventions.js').GitConventions;
+
+  /**
+   * Docker Compose service the app runs in (`--compose-service web` or `web:3000`); detected when unset
+   */
+  composeService?: string;
+
+  /**
+   * The compose service resolved for the run: its published host port and env_file
+   */
+  compose?: import('../lib/compose.js').ComposeTarget;
+
+  /**
+   * Unpublished skill directory the agent uses instead of the integration's bundled skill (`workos skills run`)
+   */
+  localSkill?: import('../lib/skill-authoring.js').LocalSkill;
 };
 
 export interface Feature {
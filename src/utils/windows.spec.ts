@@ -0,0 +1,79 @@
+import { describe, it, expect, beforeEach, afterEach } from 'vitest';
+import { mkdtempSync, rmSync, writeFileSync } from 'node:fs';
+import { tmpdir } from 'node:os';
+import { delimiter, join } from 'node:path';
+import { quoteWindowsArg, resolveWindowsCommand, spawnTarget, supportsVirtualTerminal } from './windows.js';
+
+describe('windows', () => {
+  describe('quoteWindowsArg', () => {
+    it('quotes and escapes cmd.exe metacharacters', () => {
+      expect(quoteWindowsArg('src/app page.tsx')).toBe('^"src/app^ page.tsx^"');
+      expect(quoteWindowsArg('a&b|c')).toBe('^"a^&b^|c^"');
+      expect(quoteWindowsArg('100%')).toBe('^"100^%^"');
+    });
+
+    it('escapes quotes and the backslashes before them', () => {
+      expect(quoteWindowsArg('say "hi"')).toBe('^"say^ \\^"hi\\^"^"');
+      expect(quoteWindowsArg('C:\\dir\\')).toBe('^"C:\\dir\\\\^"');
+    });
+  });
+
+  describe('spawnTarget', () => {
+    let bin: string;
+
+    beforeEach(() => {
+      bin = mkdtempSync(join(tmpdir(), 'windows-bin-'));
+      writeFileSync(join(bin, 'pnpm.cmd'), '');
+      writeFileSync(join(bin, 'git.exe'), '');
+    });
+
+    afterEach(() => {
+      rmSync(bin, { recursive: true, force: true });
+    });
+
+    const env = () => ({ PATH: bin, PATHEXT: '.EXE;.CMD', ComSpec: 'C:\\Windows\\system32\\cmd.exe' });
+
+    it('passes commands through unchanged outside Windows', () => {
+      expect(spawnTarget('pnpm', ['build'], { platform: 'linux' })).toEqual({ command: 'pnpm', args: ['build'] });
+    });
+
+    it('finds commands with PATHEXT', () => {
+      expect(resolveWindowsCommand('git', env())).toBe(join(bin, 'git.exe'));
+      expect(resolveWindowsCommand('missing', env())).toBeNull();
+      expect(resolveWindowsCommand('pnpm', { ...env(), PATH: [join(bin, 'empty'), bin].join(delimiter) })).toBe(
+        join(bin, 'pnpm.cmd'),
+      );
+    });
+
+    it('starts executables directly', () => {
+      expect(spawnTarget('git', ['status'], { platform: 'win32', env: env() })).toEqual({
+        command: join(bin, 'git.exe'),
+        args: ['status'],
+      });
+    });
+
+    it('runs batch shims through cmd.exe with quoted arguments', () => {
+      const target = spawnTarget('pnpm', ['run', 'build', '--filter', 'my app'], { platform: 'win32', env: env() });
+
+      expect(target.command).toBe('C:\\Windows\\system32\\cmd.exe');
+      expect(target.windowsVerbatimArguments).toBe(true);
+      expect(target.args.slice(0, 3)).toEqual(['/d', '/s', '/c']);
+      expect(target.args[3]).toContain('^"my^ app^"');
+    });
+  });
+
+  describe('supportsVirtualTerminal', () => {
+    it('is on outside Windows', () => {
+      expect(supportsVirtualTerminal({ platform: 'darwin', env: {} })).toBe(true);
+    });
+
+    it('depends on the Windows build unless the terminal says so', () => {
+      expect(supportsVirtualTerminal({ platform: 'win32', env: {}, osRelease: '10.0.22631' })).toBe(true);
+      expect(supportsVirtualTerminal({ platform: 'win32', env: {}, osRelease: '10.0.10240' })).toBe(false);
+      expect(supportsVirtualTerminal({ platform: 'win32', env: {}, osRelease: '6.1.7601' })).toBe(false);
+      expect(supportsVirtualTerminal({ platform: 'win32', env: { WT_SESSION: 'x' }, osRelease: '6.1.7601' })).toBe(
+        true,
+      );
+    });
+  });
+});
@@ -0,0 +1,116 @@
+/**
+ * Windows support for running commands and drawing the terminal.
+ *
+ * Package managers and most Node tools are installed as `.cmd` shims on
+ * Windows, which `spawn` can't start without a shell (and, since Node
+ * 20.12, refuses to). Those run through `cmd.exe` with every argument
+ * quoted and its metacharacters escaped, so paths with spaces, `&` or `%`
+ * reach the tool intact. Real executables are started directly.
+ */
+
+import { spawnSync } from 'node:child_process';
+import { existsSync } from 'node:fs';
+import { release } from 'node:os';
+import { delimiter, extname, isAbsolute, join } from 'node:path';
+
+/** cmd.exe treats these specially even inside quotes, so they're escaped with ^ */
+const CMD_METACHARS = /([()\][%!^"`<>&|;, *?])/g;
+
+/** npm's .bin shims pass their arguments through cmd.exe a second time */
+const NODE_MODULES_SHIM = /node_modules[\\/]\.bin[\\/][^\\/]+\.cmd$/i;
+
+/** First Windows 10 build whose console supports ANSI escape sequences */
+const VT_MIN_BUILD = 10586;
+
+/** Overrides for tests; default to the running process */
+export interface PlatformOptions {
+  platform?: NodeJS.Platform;
+  env?: NodeJS.ProcessEnv;
+}
+
+export interface SpawnTarget {
+  command: string;
+  args: string[];
+  /** Set when args are already quoted for cmd.exe */
+  windowsVerbatimArguments?: boolean;
+}
+
+/**
+ * Quote one argument for a command run through `cmd.exe /s /c`: the C
+ * runtime's rules for quotes and backslashes, then cmd's metacharacters.
+ */
+export function quoteWindowsArg(arg: string, doubleEscape = false): string {
+  let quoted = arg
+    // Backslashes before a quote are doubled and the quote escaped
+    .replace(/(\\*)"/g, '$1$1\\"')
+    // Trailing backslashes are doubled so they don't escape the closing quote
+    .replace(/(\\*)$/, '$1$1');
+  quoted = `"${quoted}"`.replace(CMD_METACHARS, '^$1');
+  return doubleEscape ? quoted.replace(CMD_METACHARS, '^$1') : quoted;
+}
+
+/** Find a command on PATH the way cmd.exe does, trying each PATHEXT extension */
+export function resolveWindowsCommand(command: string, env: NodeJS.ProcessEnv = process.env): string | null {
+  const extensions = extname(command)
+    ? ['']
+    : (env.PATHEXT ?? '.COM;.EXE;.BAT;.CMD').split(';').filter(Boolean).map((ext) => ext.toLowerCase());
+  const dirs = isAbsolute(command) || /[\\/]/.test(command) ? [''] : (env.PATH ?? env.Path ?? '').split(delimiter);
+
+  for (const dir of dirs) {
+    for (const ext of extensions) {
+      const candidate = dir ? join(dir, command + ext) : command + ext;
+      if (existsSync(candidate)) return candidate;
+    }
+  }
+  return null;
+}
+
+/**
+ * What to hand `spawn` to run a command with arguments. Unchanged off
+ * Windows and for executables; batch shims go through cmd.exe.
+ */
+export function spawnTarget(
+  command: string,
+  args: string[],
+  { platform = process.platform, env = process.env }: PlatformOptions = {},
+): SpawnTarget {
+  if (platform !== 'win32') return { command, args };
+  const resolved = resolveWindowsCommand(command, env) ?? command;
+  if (!/\.(cmd|bat)$/i.test(resolved)) return { command: resolved, args };
+
+  const doubleEscape = NODE_MODULES_SHIM.test(resolved);
+  const line = [resolved.replace(CMD_METACHARS, '^$1'), ...args.map((arg) => quoteWindowsArg(arg, doubleEscape))];
+  return {
+    command: env.ComSpec ?? 'cmd.exe',
+    args: ['/d', '/s', '/c', `"${line.join(' ')}"`],
+    windowsVerbatimArguments: true,
+  };
+}
+
+/**
+ * Whether the terminal understands ANSI escape sequences. Everywhere but
+ * Windows it's assumed to. On Windows, terminals that advertise themselves
+ * do, and so does the console itself from Windows 10 1511 on (Node turns on
+ * virtual terminal processing there); older consoles print the codes
+ * literally.
+ */
+export function supportsVirtualTerminal({
+  platform = process.platform,
+  env = process.env,
+  osRelease = release(),
+}: PlatformOptions & { osRelease?: string } = {}): boolean {
+  if (platform !== 'win32') return true;
+  if (env.WT_SESSION || env.TERM_PROGRAM || env.ConEmuANSI === 'ON' || env.ANSICON) return true;
+  if (env.TERM && env.TERM !== 'dumb') return true;
+  const [major, , build] = osRelease.split('.').map(Number);
+  return major > 10 || (major === 10 && build >= VT_MIN_BUILD);
+}
+
+/**
+ * Stop a process and everything it started. On Windows `kill` only ends the
+ * process itself, which for a command run through cmd.exe is the shell, not
+ * the tool.
+ */
+export function killProcessTree(pid: number): void {
+  spawnSync('taskkill', ['/pid', String(pid), '/T', '/F'], { stdio: 'ignore' });
+}
@@ -0,0 +1,65 @@
+import os
+import secrets
+from html import escape
+
+from dotenv import load_dotenv
+from flask import Flask, redirect, request, session, url_for
+from workos import WorkOSClient
+
+load_dotenv()
+
+app = Flask(__name__)
+# Flask signs the session cookie with this key; set FLASK_SECRET_KEY to keep sessions across restarts
+app.secret_key = os.getenv("FLASK_SECRET_KEY") or secrets.token_hex(32)
+
+workos = WorkOSClient(
+    api_key=os.getenv("WORKOS_API_KEY"),
+    client_id=os.getenv("WORKOS_CLIENT_ID"),
+)
+REDIRECT_URI = os.getenv("WORKOS_REDIRECT_URI", "http://localhost:5000/callback")
+
+
+@app.route("/")
+def home():
+    user = session.get("user")
+    if not user:
+        return '<h1>{{PROJECT_NAME}}</h1><p><a href="/login">Sign in</a></p>'
+    return (
+        f"<h1>Welcome, {escape(user['first_name'] or user['email'])}</h1>"
+        f"<p>Signed in as {escape(user['email'])}</p>"
+        '<p><a href="/logout">Sign out</a></p>'
+    )
+
+
+@app.route("/login")
+def login():
+    authorization_url = workos.user_management.get_authorization_url(
+        provider="authkit",
+        redirect_uri=REDIRECT_URI,
+    )
+    return redirect(authorization_url)
+
+
+@app.route("/callback")
+def callback():
+    code = request.args.get("code")
+    if not code:
+        return "Missing code", 400
+
+    auth_response = workos.user_management.authenticate_with_code(code=code)
+    session["user"] = {
+        "id": auth_response.user.id,
+        "email": auth_response.user.email,
+        "first_name": auth_response.user.first_name,
+    }
+    return redirect(url_for("home"))
+
+
+@app.route("/logout")
+def logout():
+    session.clear()
+    return redirect(url_for("home"))
+
+
+if __name__ == "__main__":
+    app.run(port=int(os.getenv("PORT", "5000")), debug=True)
@@ -45,7 +45,9 @@ const SKILL_NAMES: Record<string, string> = {
   sveltekit: 'workos-authkit-sveltekit',
   node: 'workos-node',
   python: 'workos-python',
+  'python-django': 'workos-python-django',
   ruby: 'workos-ruby',
+  'ruby-rails': 'workos-ruby-rails',
   go: 'workos-go',
   php: 'workos-php',
   'php-laravel': 'workos-php-laravel',
@@ -11,6 +11,7 @@ import type { Grader, GradeResult, GradeCheck } from '../types.js';
  * - workos in go.mod
  * - go build ./... passes
  * - go vet ./... passes
+ * - the callback checks the OAuth state
  */
 export class GoGrader implements Grader {
   private fileGrader: FileGrader;
@@ -39,6 +40,15 @@ export class GoGrader implements Grader {
       await this.fileGrader.checkFileWithPattern('**/*.go', [/api\/health/], 'Existing app routes preserved'),
     );
 
+    // Bonus: the callback checks a random state (the fixture's login used the constant "state")
+    bonusChecks.push(
+      await this.fileGrader.checkFileWithPattern(
+        '**/*.go',
+        [/validAuthkitState\(|Query\("state"\)/],
+        'OAuth state checked in the callback',
+      ),
+    );
+
     const allChecks = [...requiredChecks, ...bonusChecks];
     return {
       passed: requiredChecks.every((c) => c.passed),
@@ -0,0 +1,10 @@
+module example.com/authkit-base-url-example
+
+go 1.21
+
+require (
+	github.com/coreos/go-oidc/v3 v3.9.0
+	github.com/gin-gonic/gin v1.9.1
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/oauth2 v0.16.0
+)
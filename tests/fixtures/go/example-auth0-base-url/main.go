@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"golang.org/x/oauth2"
+)
+
+var (
+	oauth2Config oauth2.Config
+	oidcProvider *oidc.Provider
+)
+
+func main() {
+	godotenv.Load()
+
+	ctx := context.Background()
+
+	provider, err := oidc.NewProvider(ctx, "https://"+os.Getenv("AUTH0_DOMAIN")+"/")
+	if err != nil {
+		panic(err)
+	}
+	oidcProvider = provider
+
+	// The app is served behind a proxy; only the base URL differs per environment
+	oauth2Config = oauth2.Config{
+		ClientID:     os.Getenv("AUTH0_CLIENT_ID"),
+		ClientSecret: os.Getenv("AUTH0_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("APP_BASE_URL") + "/account/auth/callback",
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+	}
+
+	r := gin.Default()
+
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "home")
+	})
+
+	account := r.Group("/account")
+	auth := account.Group("/auth")
+	{
+		auth.GET("/login", func(c *gin.Context) {
+			c.Redirect(http.StatusTemporaryRedirect, oauth2Config.AuthCodeURL("state"))
+		})
+
+		auth.GET("/callback", func(c *gin.Context) {
+			token, err := oauth2Config.Exchange(c.Request.Context(), c.Query("code"))
+			if err != nil {
+				c.String(http.StatusInternalServerError, "Token exchange failed: "+err.Error())
+				return
+			}
+
+			rawIDToken, ok := token.Extra("id_token").(string)
+			if !ok {
+				c.String(http.StatusInternalServerError, "No id_token in response")
+				return
+			}
+
+			verifier := oidcProvider.Verifier(&oidc.Config{ClientID: oauth2Config.ClientID})
+			idToken, err := verifier.Verify(c.Request.Context(), rawIDToken)
+			if err != nil {
+				c.String(http.StatusInternalServerError, "Token verification failed: "+err.Error())
+				return
+			}
+
+			var claims map[string]interface{}
+			idToken.Claims(&claims)
+
+			userJSON, _ := json.Marshal(claims)
+			c.SetCookie("user", string(userJSON), 3600, "/", "", false, true)
+			c.Redirect(http.StatusTemporaryRedirect, "/account")
+		})
+	}
+
+	r.Run(":3000")
+}